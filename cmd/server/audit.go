@@ -0,0 +1,36 @@
+package main
+
+import (
+	"time"
+
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/config"
+)
+
+// newAuditDispatcher builds the audit pipeline from the configured sinks. It
+// returns nil when no sink is configured, which disables auditing.
+func newAuditDispatcher(cfg *config.Config) (*audit.Dispatcher, error) {
+	var sinks []audit.Sink
+	if cfg.AuditSyslogAddr != "" {
+		s, err := audit.NewSyslogSink(cfg.AuditSyslogAddr)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	if cfg.AuditHTTPURL != "" {
+		sinks = append(sinks, audit.NewHTTPSink(cfg.AuditHTTPURL, 10*time.Second))
+	}
+	if cfg.AuditKafkaRESTURL != "" {
+		sinks = append(sinks, audit.NewKafkaSink(cfg.AuditKafkaRESTURL, cfg.AuditKafkaTopic, 10*time.Second))
+	}
+	if len(sinks) == 0 {
+		return nil, nil
+	}
+
+	return audit.New(sinks,
+		audit.WithQueueSize(cfg.AuditQueueSize),
+		audit.WithBatchSize(cfg.AuditBatchSize),
+		audit.WithEnqueueTimeout(time.Duration(cfg.AuditEnqueueTimeout)*time.Second),
+	), nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"time"
+
+	"github.com/daap14/daap/internal/config"
+	"github.com/daap14/daap/internal/events"
+)
+
+// newEventBus builds the lifecycle event bus from the configured transports.
+// It returns nil when no transport is configured, which disables publishing.
+func newEventBus(cfg *config.Config) (*events.Bus, error) {
+	var transports []events.Transport
+	if cfg.EventsNATSURL != "" {
+		t, err := events.NewNATSTransport(cfg.EventsNATSURL, cfg.EventsNATSSubject, 10*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		transports = append(transports, t)
+	}
+	if cfg.EventsKafkaRESTURL != "" {
+		transports = append(transports, events.NewKafkaTransport(cfg.EventsKafkaRESTURL, cfg.EventsKafkaTopic, 10*time.Second))
+	}
+	if len(transports) == 0 {
+		return nil, nil
+	}
+
+	return events.NewBus(transports, events.WithQueueSize(cfg.EventsQueueSize)), nil
+}
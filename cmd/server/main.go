@@ -13,16 +13,45 @@ import (
 	specpkg "github.com/daap14/daap/api"
 	"github.com/daap14/daap/internal/api"
 	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/archive"
 	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/autoscale"
 	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/breaker"
+	"github.com/daap14/daap/internal/budget"
+	"github.com/daap14/daap/internal/buildinfo"
+	"github.com/daap14/daap/internal/catalog"
 	"github.com/daap14/daap/internal/config"
 	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/events"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/gitops"
+	"github.com/daap14/daap/internal/imagepolicy"
+	"github.com/daap14/daap/internal/insights"
+	"github.com/daap14/daap/internal/jobs"
 	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/logging"
+	"github.com/daap14/daap/internal/mail"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/placement"
+	"github.com/daap14/daap/internal/preflight"
 	"github.com/daap14/daap/internal/provider"
 	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+	providerplugin "github.com/daap14/daap/internal/provider/plugin"
+	"github.com/daap14/daap/internal/provision"
+	"github.com/daap14/daap/internal/readiness"
+	"github.com/daap14/daap/internal/recommend"
 	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/retention"
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/store"
+	"github.com/daap14/daap/internal/support"
 	"github.com/daap14/daap/internal/team"
 	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/migrations"
 )
 
 func main() {
@@ -32,43 +61,110 @@ func main() {
 		os.Exit(1)
 	}
 
-	setupLogger(cfg.LogLevel)
+	logLevels, logSampler := setupLogger(cfg)
+
+	// VERSION overrides the stamped version; otherwise report what the
+	// binary was built as.
+	info := buildinfo.Get()
+	if cfg.Version == "dev" {
+		cfg.Version = info.Version
+	} else {
+		info.Version = cfg.Version
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "preflight" {
+		os.Exit(runPreflight(cfg, os.Args[2:], os.Stdout))
+	}
 
 	ctx := context.Background()
 
-	db, err := database.New(ctx, cfg.DatabaseURL)
+	st, err := store.Open(ctx, cfg.DatabaseURL)
 	if err != nil {
 		slog.Warn("platform database initialization failed; health will report degraded", "error", err)
+	} else if st.Backend() == store.BackendMemory {
+		slog.Warn("using in-memory storage backend; all data will be lost on restart")
 	}
 
 	k8sClient, err := initK8sClient(cfg)
 	if err != nil {
 		slog.Warn("kubernetes client initialization failed; health will report degraded", "error", err)
+	} else {
+		go checkK8sAccess(ctx, k8sClient, managedNamespaces(cfg, k8sClient))
 	}
 
+	// A single breaker guards every call to the API server so that an outage
+	// detected by health checks also fails provider calls fast, and vice versa.
+	k8sBreaker := breaker.New(breaker.Config{
+		Name:             "kubernetes",
+		FailureThreshold: cfg.BreakerFailureThreshold,
+		Cooldown:         time.Duration(cfg.BreakerCooldown) * time.Second,
+		IsFailure:        k8s.IsTransient,
+	})
+
 	var checker k8s.HealthChecker
 	if k8sClient != nil {
-		checker = k8sClient
+		checker = breaker.WrapHealthChecker(k8sClient, k8sBreaker)
 	} else {
 		checker = &noopChecker{}
 	}
 
 	var dbPinger handler.DBPinger
-	if db != nil {
-		dbPinger = db
+	if st != nil {
+		dbPinger = st
 	}
 
 	var repo database.Repository
-	if db != nil {
-		repo = database.NewRepository(db.Pool())
+	var statsReader database.StatsReader
+	var resizeEvents database.ResizeEventRepository
+	var revisions revision.Repository
+	if st != nil {
+		repo = st.Databases
+		statsReader = st.Stats
+		resizeEvents = st.ResizeEvents
+		revisions = st.Revisions
+	}
+
+	// Lifecycle events are published from the repository, so every writer
+	// (API handlers, reconciler, autoscaler, rollouts) is covered.
+	eventBus, err := newEventBus(cfg)
+	if err != nil {
+		slog.Error("invalid event bus configuration", "error", err)
+		os.Exit(1)
+	}
+	if eventBus != nil && repo != nil {
+		repo = events.WrapDatabaseRepository(repo, eventBus)
+	}
+
+	secretStore, err := newSecretStore(cfg, k8sClient)
+	if err != nil {
+		slog.Error("invalid secret store configuration", "error", err)
+		os.Exit(1)
 	}
 
 	// Create provider registry and register CNPG provider
 	registry := provider.NewRegistry()
+	var cnpgOperator handler.OperatorDetector
 	if k8sClient != nil {
-		cnpg := cnpgprovider.New(k8sClient.DynamicClient())
-		registry.Register("cnpg", cnpg)
+		cnpg := cnpgprovider.New(k8sClient.DynamicClient(), cnpgprovider.WithVolumeStats(k8sClient), cnpgprovider.WithReplicationStats(k8sClient), cnpgprovider.WithPoolerStats(k8sClient), cnpgprovider.WithPodLogs(k8sClient), cnpgprovider.WithSecrets(secretStore), cnpgprovider.WithInternalLoadBalancerAnnotations(cfg.ExposureInternalLBAnnotations))
+		registry.Register("cnpg", breaker.WrapProvider(cnpg, k8sBreaker))
 		slog.Info("registered provider", "name", "cnpg")
+		cnpgOperator = cnpgprovider.NewOperatorDetector(k8sClient.DynamicClient(), cfg.CNPGOperatorNamespace)
+	}
+
+	plugins, err := providerplugin.Load(ctx, cfg.ProviderPluginDir, cfg.ProviderPluginAddrs)
+	if err != nil {
+		slog.Error("failed to load provider plugins", "error", err)
+		os.Exit(1)
+	}
+	defer func() { _ = plugins.Close() }()
+	for _, p := range plugins {
+		if registry.Has(p.Name()) {
+			slog.Error("provider plugin conflicts with a registered provider", "name", p.Name())
+			_ = plugins.Close()
+			os.Exit(1)
+		}
+		registry.Register(p.Name(), p)
+		slog.Info("registered provider plugin", "name", p.Name())
 	}
 
 	var authService *auth.Service
@@ -76,11 +172,32 @@ func main() {
 	var tierRepo tier.Repository
 	var blueprintRepo blueprint.Repository
 	var userRepo auth.UserRepository
-	if db != nil {
-		teamRepo = team.NewRepository(db.Pool())
-		tierRepo = tier.NewPostgresRepository(db.Pool())
-		blueprintRepo = blueprint.NewPostgresRepository(db.Pool())
-		userRepo = auth.NewRepository(db.Pool())
+	var invitations auth.InvitationRepository
+	var freezes freeze.Repository
+	var freezeGate freeze.Gate
+	var locker *database.Locker
+	var ops *operation.Tracker
+	var specs database.SpecRepository
+	var renames database.RenameRepository
+	var archives database.ArchiveRepository
+	if st != nil {
+		teamRepo = st.Teams
+		tierRepo = st.Tiers
+		if cfg.LookupCacheTTL > 0 {
+			ttl := time.Duration(cfg.LookupCacheTTL) * time.Second
+			teamRepo = team.NewCachedRepository(teamRepo, ttl)
+			tierRepo = tier.NewCachedRepository(tierRepo, ttl)
+		}
+		blueprintRepo = st.Blueprints
+		userRepo = st.Users
+		invitations = st.Invitations
+		freezes = st.Freezes
+		freezeGate = freeze.NewChecker(freezes)
+		locker = database.NewLocker(st.Locks, time.Duration(cfg.MutationLockTTL)*time.Second, instanceName())
+		ops = operation.NewTracker(st.Operations, instanceName())
+		specs = st.Specs
+		renames = st.Renames
+		archives = st.Archives
 		authService = auth.NewService(userRepo, teamRepo, cfg.BcryptCost)
 
 		rawKey, err := authService.BootstrapSuperuser(ctx)
@@ -91,12 +208,284 @@ func main() {
 		}
 	}
 
+	var notifier notify.Notifier = notify.LogNotifier{}
+	if cfg.NotifyWebhookURL != "" {
+		notifier = notify.NewWebhookNotifier(cfg.NotifyWebhookURL, 10*time.Second)
+	}
+	if repo != nil {
+		notifier = notify.NewSilencingNotifier(notifier, database.NewAckSilencer(repo))
+	}
+	if eventBus != nil {
+		notifier = events.WrapNotifier(notifier, eventBus)
+	}
+
+	// With a signing key, blueprints are signed when saved and only
+	// provisioned from while they match their signature.
+	signer := blueprint.NewSigner([]byte(cfg.BlueprintSigningKey))
+
+	// The recommender is built before the router, which serves its
+	// recommendations, and started with the other background loops.
+	var recommender *recommend.Recommender
+	var recommenderDep handler.Recommender
+	var tierChanges database.TierChangeRepository
+	if repo != nil && tierRepo != nil && blueprintRepo != nil && cfg.RecommenderInterval > 0 {
+		var opts []recommend.Option
+		if cfg.RecommenderAutoApply {
+			window, err := recommend.ParseWindow(cfg.RecommenderApplyWindow)
+			if err != nil {
+				slog.Error("invalid RECOMMENDER_APPLY_WINDOW", "error", err)
+				os.Exit(1)
+			}
+			opts = append(opts, recommend.WithAutoApply(window))
+		}
+		opts = append(opts, recommend.WithFreezes(freezeGate), recommend.WithLocker(locker), recommend.WithSpecs(specs), recommend.WithSigner(signer))
+		if teamRepo != nil {
+			opts = append(opts, recommend.WithBudgets(budget.New(teamRepo, repo, tierRepo, blueprintRepo, registry)))
+		}
+		tierChanges = st.TierChanges
+		recommender = recommend.New(repo, tierRepo, blueprintRepo, registry, st.UsageSamples, tierChanges,
+			time.Duration(cfg.RecommenderInterval)*time.Second, time.Duration(cfg.RecommenderLookback)*time.Hour, opts...)
+		recommenderDep = recommender
+	}
+
+	// The query insights collector needs the cluster to read credentials
+	// from; the endpoint serves its snapshots only while it runs.
+	var queryCollector *insights.Collector
+	var queries database.QuerySnapshotRepository
+	if repo != nil && k8sClient != nil && cfg.InsightsInterval > 0 {
+		queries = st.Queries
+		reader := insights.NewPostgresReader(k8sClient.DynamicClient(), time.Duration(cfg.ReadinessGateTimeout)*time.Second)
+		queryCollector = insights.New(repo, queries, reader,
+			time.Duration(cfg.InsightsInterval)*time.Second, time.Duration(cfg.InsightsRetention)*time.Hour,
+			insights.WithTop(cfg.InsightsTop))
+	}
+
+	// Likewise the rollout controller: the tier handler starts rollouts and
+	// the rollout endpoints steer them.
+	var rollouts *rollout.Controller
+	var rolloutsDep handler.RolloutController
+	var rolloutRepo rollout.Repository
+	if repo != nil && tierRepo != nil && blueprintRepo != nil && cfg.RolloutInterval > 0 {
+		rolloutRepo = st.Rollouts
+		rollouts = rollout.New(rolloutRepo, repo, tierRepo, blueprintRepo, registry,
+			time.Duration(cfg.RolloutInterval)*time.Second,
+			rollout.WithCanarySize(cfg.RolloutCanarySize),
+			rollout.WithBatchSize(cfg.RolloutBatchSize),
+			rollout.WithVerifyTimeout(time.Duration(cfg.RolloutVerifyTimeout)*time.Second),
+			rollout.WithNotifier(notifier),
+			rollout.WithFreezes(freezeGate),
+			rollout.WithLocker(locker),
+			rollout.WithSpecs(specs),
+			rollout.WithSigner(signer))
+		rolloutsDep = rollouts
+	}
+
+	environments, err := database.ParseEnvironments(cfg.Environments)
+	if err != nil {
+		slog.Error("invalid ENVIRONMENTS", "error", err)
+		os.Exit(1)
+	}
+	dnsZone, err := database.ParseDNSZone(cfg.DNSZone)
+	if err != nil {
+		slog.Error("invalid DNS_ZONE", "error", err)
+		os.Exit(1)
+	}
+	var promotions database.PromotionRepository
+	var dependents database.DependentRepository
+	if st != nil {
+		promotions = st.Promotions
+		dependents = st.Dependents
+	}
+
+	preflightRunner, err := newPreflightRunner(cfg, st, k8sClient)
+	if err != nil {
+		slog.Error("failed to set up preflight checks", "error", err)
+		os.Exit(1)
+	}
+
+	var mailer mail.Sender = mail.LogSender{}
+	if cfg.SMTPAddr != "" {
+		mailer = mail.NewSMTPSender(cfg.SMTPAddr, cfg.SMTPFrom, cfg.SMTPUsername, cfg.SMTPPassword)
+	}
+
+	auditor, err := newAuditDispatcher(cfg)
+	if err != nil {
+		slog.Error("invalid audit sink configuration", "error", err)
+		os.Exit(1)
+	}
+	var auditDep middleware.AuditRecorder
+	if auditor != nil {
+		auditDep = auditor
+	}
+
+	var gitopsExporter handler.GitOpsExporter
+	if repo != nil && tierRepo != nil && blueprintRepo != nil {
+		gitopsExporter = gitops.New(repo, tierRepo, blueprintRepo, registry)
+	}
+
+	var supportBundler handler.SupportBundler
+	if repo != nil && tierRepo != nil && blueprintRepo != nil && statsReader != nil {
+		supportBundler = support.New(tierRepo, blueprintRepo, registry, statsReader)
+	}
+
+	var catalogSource handler.CatalogSource
+	if repo != nil {
+		catalogSource = catalog.New(repo, catalog.Config{
+			Namespace: cfg.CatalogNamespace,
+			System:    cfg.CatalogSystem,
+			Owners:    cfg.CatalogOwners,
+		})
+	}
+
+	var placer placement.Placer
+	if repo != nil && len(cfg.PlacementNamespaces) > 0 {
+		engine, err := placement.New(repo, placement.Config{
+			Capacities:   cfg.PlacementNamespaces,
+			TierAffinity: placement.ParseConstraints(cfg.PlacementTierAffinity),
+			TeamPins:     placement.ParseConstraints(cfg.PlacementTeamPins),
+		})
+		if err != nil {
+			slog.Error("invalid placement configuration", "error", err)
+			os.Exit(1)
+		}
+		placer = engine
+	}
+
+	var images imagepolicy.Pinner
+	if cfg.ImageMirror != "" || cfg.ImagePinDigests {
+		var resolver imagepolicy.Resolver
+		if cfg.ImagePinDigests {
+			resolver = imagepolicy.NewRegistryResolver(&http.Client{Timeout: 30 * time.Second})
+		}
+		policy, err := imagepolicy.New(cfg.ImageMirror, resolver)
+		if err != nil {
+			slog.Error("invalid IMAGE_MIRROR", "error", err)
+			os.Exit(1)
+		}
+		images = policy
+	}
+
+	lintSeverities, err := blueprint.ParseSeverities(cfg.BlueprintLintRules)
+	if err != nil {
+		slog.Error("invalid BLUEPRINT_LINT_RULES", "error", err)
+		os.Exit(1)
+	}
+
+	// POST /databases queues a provision job for each new database, which
+	// the job worker runs; without it, blueprints are applied inline.
+	var jobQueue jobs.Repository
+	var jobWorker *jobs.Worker
+	if repo != nil && tierRepo != nil && blueprintRepo != nil && cfg.JobWorkerInterval > 0 {
+		jobQueue = st.Jobs
+		jobWorker = jobs.NewWorker(jobQueue, time.Duration(cfg.JobWorkerInterval)*time.Second,
+			jobs.WithBackoff(time.Duration(cfg.JobRetryBackoff)*time.Second))
+		provisioner := provision.New(repo, tierRepo, blueprintRepo, registry,
+			provision.WithSpecs(specs), provision.WithOperations(ops), provision.WithSigner(signer))
+		jobWorker.Handle(jobs.TypeProvision, provisioner.Run)
+	}
+
+	// The retention purger removes deleted databases past RETENTION_DAYS;
+	// /admin/retention reports on it while it runs.
+	var purger *retention.Purger
+	var retentionDep handler.RetentionReporter
+	if st != nil && cfg.RetentionDays > 0 && cfg.RetentionInterval > 0 {
+		var opts []retention.Option
+		if auditor != nil {
+			opts = append(opts, retention.WithAudit(auditor))
+		}
+		purger = retention.New(st.Purges, time.Duration(cfg.RetentionInterval)*time.Second,
+			time.Duration(cfg.RetentionDays)*24*time.Hour, opts...)
+		retentionDep = purger
+	}
+
+	// The history rotator partitions the history tables by month and
+	// removes the months past HISTORY_RETENTION_MONTHS.
+	var historyRotator *retention.HistoryRotator
+	if st != nil && cfg.HistoryRotationInterval > 0 {
+		var opts []retention.HistoryOption
+		if cfg.HistoryArchive {
+			opts = append(opts, retention.WithArchive())
+		}
+		historyRotator = retention.NewHistoryRotator(st.History, time.Duration(cfg.HistoryRotationInterval)*time.Second,
+			cfg.HistoryRetentionMonths, opts...)
+	}
+
+	var rec *reconciler.Reconciler
+	var reconcilerDep handler.ReconcilerController
+	if repo != nil && tierRepo != nil && blueprintRepo != nil {
+		interval := time.Duration(cfg.ReconcilerInterval) * time.Second
+		opts := []reconciler.Option{
+			reconciler.WithProvisioningSLO(time.Duration(cfg.ProvisioningSLO) * time.Second),
+			reconciler.WithProvisioningTimeout(time.Duration(cfg.ProvisioningTimeout) * time.Second),
+			reconciler.WithNotifier(notifier),
+			reconciler.WithOperations(ops),
+			reconciler.WithWriteBatchSize(cfg.ReconcilerWriteBatchSize),
+			reconciler.WithWriteRate(float64(cfg.ReconcilerWriteRate)),
+		}
+		if cfg.ReadinessGateConnections > 0 && k8sClient != nil {
+			gate := readiness.New(k8sClient.DynamicClient(), cfg.ReadinessGateConnections,
+				cfg.ReadinessGateQuery, time.Duration(cfg.ReadinessGateTimeout)*time.Second)
+			opts = append(opts, reconciler.WithReadinessGate(gate))
+		}
+		if auditor != nil {
+			opts = append(opts, reconciler.WithAudit(auditor))
+		}
+		if teamRepo != nil {
+			opts = append(opts, reconciler.WithArchiver(archive.New(teamRepo, repo, archives)))
+		}
+		if renames != nil {
+			opts = append(opts, reconciler.WithRenames(renames))
+		}
+		rec = reconciler.New(repo, tierRepo, blueprintRepo, registry, interval, opts...)
+		reconcilerDep = rec
+	}
+
+	var schema preflight.SchemaVersioner
+	if st != nil {
+		schema = st
+	}
+	expectedSchema, err := migrations.Latest()
+	if err != nil {
+		slog.Error("failed to read embedded migrations", "error", err)
+		os.Exit(1)
+	}
+
+	// Echo what this instance actually runs with, secrets redacted; the
+	// same report is served at /admin/config.
+	runInfo := info.WithFeatures(runtimeFeatures(cfg, st)...)
+	settings := cfg.Effective()
+	logEffectiveConfig(runInfo, registry.Names(), settings)
+	reloader := config.NewReloader(*cfg, applyReload(logLevels, logSampler, rec))
+
 	router := api.NewRouter(api.RouterDeps{
 		K8sChecker:       checker,
 		DBPinger:         dbPinger,
 		Version:          cfg.Version,
+		BuildInfo:        runInfo,
 		Repo:             repo,
+		Stats:            statsReader,
+		ResizeEvents:     resizeEvents,
+		Revisions:        revisions,
+		Recommender:      recommenderDep,
+		TierChanges:      tierChanges,
+		Queries:          queries,
+		Promotions:       promotions,
+		Dependents:       dependents,
+		Specs:            specs,
+		Locker:           locker,
+		Operations:       ops,
+		Jobs:             jobQueue,
+		Renames:          renames,
+		Archives:         archives,
+		Environments:     environments,
+		Rollouts:         rolloutsDep,
+		RolloutRepo:      rolloutRepo,
+		Freezes:          freezes,
+		ProvisioningSLO:  time.Duration(cfg.ProvisioningSLO) * time.Second,
+		DeprovisionWait:  time.Duration(cfg.DeprovisionWait) * time.Second,
 		Namespace:        cfg.Namespace,
+		Placement:        placer,
+		Images:           images,
 		OpenAPISpec:      specpkg.OpenAPISpec,
 		AuthService:      authService,
 		TeamRepo:         teamRepo,
@@ -104,16 +493,86 @@ func main() {
 		BlueprintRepo:    blueprintRepo,
 		ProviderRegistry: registry,
 		UserRepo:         userRepo,
+		Organizations:    st.Organizations,
+		UsageSamples:     st.UsageSamples,
+		Invitations:      invitations,
+		Mailer:           mailer,
+		Notifier:         notifier,
+		PublicURL:        cfg.PublicURL,
+		InvitationTTL:    time.Duration(cfg.InvitationTTL) * time.Hour,
+		PprofEnabled:     cfg.PprofEnabled,
+		Preflight:        preflightRunner,
+		GitOps:           gitopsExporter,
+		SupportBundles:   supportBundler,
+		Catalog:          catalogSource,
+		CNPGOperator:     cnpgOperator,
+		BlueprintLint:    blueprint.LintConfig{Severities: lintSeverities, RequiredLabels: cfg.BlueprintLintRequiredLabels},
+		Audit:            auditDep,
+
+		BlueprintMaxManifestBytes: cfg.BlueprintMaxManifestBytes,
+		BlueprintSigner:           signer,
+		DNSZone:                   dnsZone,
+
+		Reconciler:            reconcilerDep,
+		Schema:                schema,
+		ExpectedSchemaVersion: expectedSchema,
+
+		Config:    reloader,
+		Retention: retentionDep,
+
+		RequestTimeoutMax: time.Duration(cfg.RequestTimeoutMax) * time.Second,
 	})
 
+	if cfg.PprofEnabled {
+		slog.Warn("pprof endpoints enabled at /debug/pprof (superuser-only)")
+	}
+
 	// Start reconciler if both repo and k8s manager are available.
 	reconcilerCtx, reconcilerCancel := context.WithCancel(context.Background())
 	defer reconcilerCancel()
 
-	if repo != nil && tierRepo != nil && blueprintRepo != nil {
-		interval := time.Duration(cfg.ReconcilerInterval) * time.Second
-		rec := reconciler.New(repo, tierRepo, blueprintRepo, registry, interval)
+	go reloadOnHangup(reconcilerCtx, reloader, cfg.ReloadFile)
+
+	if locker != nil {
+		go locker.Start(reconcilerCtx)
+	}
+	if ops != nil {
+		go ops.KeepAlive(reconcilerCtx)
+	}
+
+	if jobWorker != nil {
+		go jobWorker.Start(reconcilerCtx)
+	}
+
+	if purger != nil {
+		go purger.Start(reconcilerCtx)
+	}
+
+	if historyRotator != nil {
+		go historyRotator.Start(reconcilerCtx)
+	}
+
+	if rec != nil {
 		go rec.Start(reconcilerCtx)
+
+		if cfg.StorageAutoscaleInterval > 0 {
+			collector := autoscale.New(repo, tierRepo, blueprintRepo, registry, resizeEvents,
+				time.Duration(cfg.StorageAutoscaleInterval)*time.Second,
+				autoscale.WithNotifier(notifier), autoscale.WithFreezes(freezeGate), autoscale.WithLocker(locker))
+			go collector.Start(reconcilerCtx)
+		}
+
+		if recommender != nil {
+			go recommender.Start(reconcilerCtx)
+		}
+
+		if queryCollector != nil {
+			go queryCollector.Start(reconcilerCtx)
+		}
+
+		if rollouts != nil {
+			go rollouts.Start(reconcilerCtx)
+		}
 	}
 
 	srv := &http.Server{
@@ -151,31 +610,108 @@ func main() {
 		os.Exit(1)
 	}
 
-	if db != nil {
-		db.Close()
-		slog.Info("database connection pool closed")
+	if err := ops.Wait(shutdownCtx); err != nil {
+		slog.Error("background operations still running at shutdown", "error", err)
+	}
+
+	if auditor != nil {
+		if err := auditor.Close(shutdownCtx); err != nil {
+			slog.Error("audit events not delivered before shutdown", "error", err)
+		}
+	}
+
+	if eventBus != nil {
+		if err := eventBus.Close(shutdownCtx); err != nil {
+			slog.Error("lifecycle events not published before shutdown", "error", err)
+		}
+	}
+
+	if st != nil {
+		st.Close()
+		slog.Info("storage backend closed", "backend", st.Backend())
 	}
 
 	slog.Info("server stopped gracefully")
 }
 
-func setupLogger(level string) {
-	var logLevel slog.Level
-	switch level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
+// setupLogger installs the default logger with the configured levels and
+// debug sampling, and returns them so they can change at runtime.
+func setupLogger(cfg *config.Config) (*logging.Levels, *logging.Sampler) {
+	levels := logging.NewLevels(levelsOf(cfg))
+	sampler := logging.NewSampler(cfg.LogDebugSampling)
 
+	// The logging handler applies the levels; the JSON handler takes all.
 	handler := slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
+		Level: slog.LevelDebug,
 	})
-	slog.SetDefault(slog.New(handler))
+	slog.SetDefault(slog.New(logging.NewHandler(handler, levels, sampler)))
+	return levels, sampler
+}
+
+// levelsOf returns the default level and module levels of cfg. Invalid
+// levels fall back to info; LOG_MODULE_LEVELS is ignored if invalid.
+func levelsOf(cfg *config.Config) (slog.Level, map[string]slog.Level) {
+	level, err := config.ParseLogLevel(cfg.LogLevel)
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	modules, err := config.ParseModuleLevels(cfg.LogModuleLevels)
+	if err != nil {
+		modules = nil
+	}
+	return level, modules
+}
+
+// applyReload applies a change of the reloadable settings to the running
+// logger and reconciler, which keeps its loop and in-flight pass.
+func applyReload(levels *logging.Levels, sampler *logging.Sampler, rec *reconciler.Reconciler) func(prev, next config.Config) {
+	return func(prev, next config.Config) {
+		levels.Set(levelsOf(&next))
+		sampler.SetRate(next.LogDebugSampling)
+		if rec != nil {
+			if next.ReconcilerInterval != prev.ReconcilerInterval {
+				_ = rec.SetInterval(time.Duration(next.ReconcilerInterval) * time.Second) // validated positive
+			}
+			if next.ReconcilerWriteRate != prev.ReconcilerWriteRate {
+				rec.SetWriteRate(float64(next.ReconcilerWriteRate))
+			}
+		}
+
+		changed := config.Changed(&prev, &next)
+		attrs := make([]any, len(changed))
+		for i, s := range changed {
+			attrs[i] = slog.String(s.Name, s.Value)
+		}
+		slog.Info("configuration reloaded", attrs...)
+	}
+}
+
+// reloadOnHangup re-reads RELOAD_FILE on every SIGHUP until ctx is done.
+func reloadOnHangup(ctx context.Context, reloader *config.Reloader, path string) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if path == "" {
+				slog.Warn("SIGHUP ignored: RELOAD_FILE is not set")
+				continue
+			}
+			ignored, err := reloader.ReloadFile(path)
+			if err != nil {
+				slog.Error("failed to reload configuration", "file", path, "error", err)
+				continue
+			}
+			if len(ignored) > 0 {
+				slog.Warn("settings in reload file require a restart and were ignored",
+					"file", path, "settings", ignored, "reloadable", config.ReloadableSettings())
+			}
+		}
+	}
 }
 
 func initK8sClient(cfg *config.Config) (*k8s.Client, error) {
@@ -183,12 +719,138 @@ func initK8sClient(cfg *config.Config) (*k8s.Client, error) {
 	if cfg.KubeconfigPath != "" {
 		opts = append(opts, k8s.WithKubeconfig(cfg.KubeconfigPath))
 	}
+	if cfg.K8sImpersonateUser != "" {
+		opts = append(opts, k8s.WithImpersonation(cfg.K8sImpersonateUser, cfg.K8sImpersonateGroups))
+	}
+	if len(cfg.K8sNamespaceServiceAccounts) > 0 {
+		opts = append(opts, k8s.WithNamespaceServiceAccounts(cfg.K8sNamespaceServiceAccounts))
+	}
 	return k8s.NewClient(opts...)
 }
 
+// checkK8sAccess reviews the RBAC permissions DAAP needs in namespaces, and
+// logs the missing ones so that misconfigured RBAC shows up at startup
+// instead of at the first provisioning request.
+func checkK8sAccess(ctx context.Context, client *k8s.Client, namespaces []string) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	results, err := client.CheckAccess(ctx, namespaces)
+	if err != nil {
+		slog.Warn("kubernetes access self-check failed", "error", err)
+		return
+	}
+
+	missing := k8s.MissingAccess(results)
+	if len(missing) == 0 {
+		slog.Info("kubernetes access self-check passed", "namespaces", namespaces)
+		return
+	}
+	for _, m := range missing {
+		slog.Warn("kubernetes permission missing", "permission", m.String(), "reason", m.Reason)
+	}
+	slog.Warn("kubernetes access self-check found missing permissions; affected operations will fail",
+		"missing", len(missing), "checked", len(results))
+}
+
+// logEffectiveConfig logs the startup banner: the build, the registered
+// providers, the enabled features and every setting.
+func logEffectiveConfig(info buildinfo.Info, providers []string, settings []config.Setting) {
+	attrs := make([]any, len(settings))
+	for i, s := range settings {
+		attrs[i] = slog.String(s.Name, s.Value)
+	}
+	slog.Info("effective configuration",
+		"version", info.Version,
+		"commit", info.GitCommit,
+		"providers", providers,
+		"features", info.Features,
+		slog.Group("config", attrs...))
+}
+
+// runtimeFeatures lists the optional features enabled by configuration, for
+// reporting alongside build-time features in GET /version.
+func runtimeFeatures(cfg *config.Config, st *store.Store) []string {
+	var features []string
+	if st != nil {
+		features = append(features, "storage-"+st.Backend())
+	}
+	if cfg.PprofEnabled {
+		features = append(features, "pprof")
+	}
+	if cfg.K8sImpersonateUser != "" || len(cfg.K8sNamespaceServiceAccounts) > 0 {
+		features = append(features, "k8s-impersonation")
+	}
+	if len(cfg.PlacementNamespaces) > 0 {
+		features = append(features, "namespace-placement")
+	}
+	if cfg.ImageMirror != "" || cfg.ImagePinDigests {
+		features = append(features, "image-policy")
+	}
+	if cfg.BlueprintSigningKey != "" {
+		features = append(features, "blueprint-signing")
+	}
+	if len(cfg.ExposureInternalLBAnnotations) > 0 {
+		features = append(features, "internal-exposure")
+	}
+	if cfg.DNSZone != "" {
+		features = append(features, "dns-names")
+	}
+	if cfg.ProviderPluginDir != "" || len(cfg.ProviderPluginAddrs) > 0 {
+		features = append(features, "provider-plugins")
+	}
+	if cfg.ReadinessGateConnections > 0 {
+		features = append(features, "readiness-gate")
+	}
+	if cfg.StorageAutoscaleInterval > 0 {
+		features = append(features, "storage-autoscale")
+	}
+	if cfg.RecommenderInterval > 0 {
+		features = append(features, "tier-recommender")
+		if cfg.RecommenderAutoApply {
+			features = append(features, "tier-auto-apply")
+		}
+	}
+	if cfg.InsightsInterval > 0 {
+		features = append(features, "query-insights")
+	}
+	if cfg.RolloutInterval > 0 {
+		features = append(features, "tier-rollouts")
+	}
+	if cfg.JobWorkerInterval > 0 {
+		features = append(features, "job-queue")
+	}
+	if cfg.RetentionDays > 0 && cfg.RetentionInterval > 0 {
+		features = append(features, "retention-purge")
+	}
+	if cfg.HistoryRetentionMonths > 0 && cfg.HistoryRotationInterval > 0 {
+		features = append(features, "history-rotation")
+	}
+	if len(cfg.Environments) > 1 {
+		features = append(features, "environment-promotion")
+	}
+	if cfg.AuditSyslogAddr != "" || cfg.AuditHTTPURL != "" || cfg.AuditKafkaRESTURL != "" {
+		features = append(features, "audit-sinks")
+	}
+	if cfg.EventsNATSURL != "" || cfg.EventsKafkaRESTURL != "" {
+		features = append(features, "event-bus")
+	}
+	return features
+}
+
 // noopChecker returns a degraded status when no K8s client is available.
 type noopChecker struct{}
 
 func (n *noopChecker) CheckConnectivity(_ context.Context) k8s.ConnectivityStatus {
 	return k8s.ConnectivityStatus{Connected: false}
 }
+
+// instanceName identifies this process in database mutation locks and in
+// the operations it runs.
+func instanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
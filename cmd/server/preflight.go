@@ -0,0 +1,129 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/daap14/daap/internal/config"
+	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/preflight"
+	"github.com/daap14/daap/internal/store"
+	"github.com/daap14/daap/migrations"
+)
+
+// newPreflightRunner wires the preflight checks to whichever backends are
+// available. A nil store or client leaves the corresponding checks failing.
+func newPreflightRunner(cfg *config.Config, st *store.Store, client *k8s.Client) (*preflight.Runner, error) {
+	expected, err := migrations.Latest()
+	if err != nil {
+		return nil, err
+	}
+
+	r := &preflight.Runner{ExpectedSchemaVersion: expected}
+	if st != nil {
+		r.Schema = st
+		r.Blueprints = st.Blueprints
+	}
+	if client != nil {
+		r.Discovery = client.Discovery()
+		r.Access = client
+		r.Dynamic = client.DynamicClient()
+		r.Namespaces = managedNamespaces(cfg, client)
+	}
+	return r, nil
+}
+
+// managedNamespaces lists the namespaces DAAP provisions into: the default
+// namespace, then every ServiceAccount-mapped and placement namespace, each
+// once.
+func managedNamespaces(cfg *config.Config, client *k8s.Client) []string {
+	namespaces := []string{cfg.Namespace}
+	seen := map[string]bool{cfg.Namespace: true}
+	add := func(ns string) {
+		if !seen[ns] {
+			seen[ns] = true
+			namespaces = append(namespaces, ns)
+		}
+	}
+	for _, ns := range client.Namespaces() {
+		add(ns)
+	}
+	placed := make([]string, 0, len(cfg.PlacementNamespaces))
+	for ns := range cfg.PlacementNamespaces {
+		placed = append(placed, ns)
+	}
+	sort.Strings(placed)
+	for _, ns := range placed {
+		add(ns)
+	}
+	return namespaces
+}
+
+// runPreflight implements `daap preflight`. It prints the report and returns
+// the process exit code: 0 when every check passes, 1 otherwise.
+func runPreflight(cfg *config.Config, args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("preflight", flag.ContinueOnError)
+	fs.SetOutput(out)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	timeout := fs.Duration("timeout", time.Minute, "overall time limit for the checks")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	st, err := store.Open(ctx, cfg.DatabaseURL)
+	if err != nil {
+		fmt.Fprintf(out, "platform database: %v\n", err)
+		st = nil
+	} else {
+		defer st.Close()
+	}
+
+	client, err := initK8sClient(cfg)
+	if err != nil {
+		fmt.Fprintf(out, "kubernetes client: %v\n", err)
+		client = nil
+	}
+
+	runner, err := newPreflightRunner(cfg, st, client)
+	if err != nil {
+		fmt.Fprintf(out, "preflight: %v\n", err)
+		return 1
+	}
+	report := runner.Run(ctx)
+
+	if *asJSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		printReport(out, report)
+	}
+
+	if !report.Passed {
+		return 1
+	}
+	return 0
+}
+
+func printReport(out io.Writer, report preflight.Report) {
+	for _, c := range report.Checks {
+		fmt.Fprintf(out, "[%s] %-16s %s\n", strings.ToUpper(string(c.Status)), c.Name, c.Message)
+		for _, d := range c.Details {
+			fmt.Fprintf(out, "       - %s\n", d)
+		}
+	}
+	if report.Passed {
+		fmt.Fprintln(out, "\npreflight passed")
+	} else {
+		fmt.Fprintln(out, "\npreflight FAILED")
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/daap14/daap/internal/config"
+	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/secrets"
+)
+
+// newSecretStore builds the store blueprint secret references are resolved
+// from. It returns nil when none is configured.
+func newSecretStore(cfg *config.Config, k8sClient *k8s.Client) (secrets.Store, error) {
+	switch {
+	case cfg.SecretsK8sSecret != "" && cfg.SecretsVaultAddr != "":
+		return nil, errors.New("SECRETS_K8S_SECRET and SECRETS_VAULT_ADDR are mutually exclusive")
+	case cfg.SecretsK8sSecret != "":
+		namespace, name, ok := strings.Cut(cfg.SecretsK8sSecret, "/")
+		if !ok || namespace == "" || name == "" {
+			return nil, fmt.Errorf("SECRETS_K8S_SECRET must be <namespace>/<name>, got %q", cfg.SecretsK8sSecret)
+		}
+		if k8sClient == nil {
+			return nil, errors.New("SECRETS_K8S_SECRET needs a Kubernetes connection")
+		}
+		return secrets.NewKubernetesStore(k8sClient, namespace, name), nil
+	case cfg.SecretsVaultAddr != "":
+		if cfg.SecretsVaultPath == "" {
+			return nil, errors.New("SECRETS_VAULT_PATH is required with SECRETS_VAULT_ADDR")
+		}
+		return secrets.NewVaultStore(cfg.SecretsVaultAddr, cfg.SecretsVaultToken, cfg.SecretsVaultPath, 10*time.Second), nil
+	}
+	return nil, nil
+}
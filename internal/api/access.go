@@ -0,0 +1,115 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/daap14/daap/internal/api/middleware"
+)
+
+var (
+	superuserOnly       = middleware.Access{Superuser: true}
+	superuserOrOrgAdmin = middleware.Access{Superuser: true, OrgAdmin: true}
+	platformOnly        = middleware.Access{Roles: []string{"platform"}}
+	platformOrProduct   = middleware.Access{Roles: []string{"platform", "product"}}
+)
+
+// accessByRoute is who may call each authenticated route, by method and
+// route pattern. It mirrors the middleware of the route groups in NewRouter,
+// which enforce it; POST /auth/check answers from it.
+var accessByRoute = map[string]middleware.Access{
+	"GET /me":                                         {}, // any authenticated identity
+	"POST /auth/check":                                {},
+	"POST /organizations":                             superuserOnly,
+	"GET /organizations":                              superuserOrOrgAdmin,
+	"GET /organizations/{id}":                         superuserOrOrgAdmin,
+	"PATCH /organizations/{id}":                       superuserOnly,
+	"DELETE /organizations/{id}":                      superuserOnly,
+	"GET /organizations/{id}/usage":                   superuserOrOrgAdmin,
+	"POST /teams":                                     superuserOrOrgAdmin,
+	"GET /teams":                                      superuserOrOrgAdmin,
+	"GET /teams/by-name/{name}":                       superuserOrOrgAdmin,
+	"PATCH /teams/{id}":                               superuserOrOrgAdmin,
+	"DELETE /teams/{id}":                              superuserOrOrgAdmin,
+	"POST /users":                                     superuserOrOrgAdmin,
+	"GET /users":                                      superuserOrOrgAdmin,
+	"DELETE /users/{id}":                              superuserOrOrgAdmin,
+	"POST /users/invite":                              superuserOnly,
+	"POST /freezes":                                   superuserOnly,
+	"GET /freezes":                                    superuserOnly,
+	"DELETE /freezes/{id}":                            superuserOnly,
+	"GET /admin/preflight":                            superuserOnly,
+	"GET /admin/config":                               superuserOnly,
+	"PUT /admin/config":                               superuserOnly,
+	"GET /admin/reconciler":                           superuserOnly,
+	"PATCH /admin/reconciler":                         superuserOnly,
+	"GET /admin/retention":                            superuserOnly,
+	"GET /admin/gitops-export":                        superuserOnly,
+	"POST /admin/databases/{id}/unlock":               superuserOnly,
+	"POST /databases":                                 platformOrProduct,
+	"GET /databases":                                  platformOrProduct,
+	"GET /databases/{id}":                             platformOrProduct,
+	"GET /databases/by-name/{name}":                   platformOrProduct,
+	"PATCH /databases/{id}":                           platformOrProduct,
+	"DELETE /databases/{id}":                          platformOrProduct,
+	"POST /databases/{id}/restart":                    platformOrProduct,
+	"POST /databases/{id}/restore":                    platformOrProduct,
+	"POST /databases/{id}/rotate-credentials":         platformOrProduct,
+	"POST /databases/{id}/unfreeze":                   platformOrProduct,
+	"POST /databases/{id}/rename":                     platformOrProduct,
+	"GET /databases/{id}/usage":                       platformOrProduct,
+	"POST /databases/{id}/ack":                        platformOrProduct,
+	"DELETE /databases/{id}/ack":                      platformOrProduct,
+	"GET /databases/{id}/resize-events":               platformOrProduct,
+	"GET /databases/{id}/revisions":                   platformOrProduct,
+	"GET /databases/{id}/recommendations":             platformOrProduct,
+	"GET /databases/{id}/insights/queries":            platformOrProduct,
+	"GET /databases/{id}/spec-diff":                   platformOrProduct,
+	"POST /databases/{id}/dependents":                 platformOrProduct,
+	"GET /databases/{id}/dependents":                  platformOrProduct,
+	"DELETE /databases/{id}/dependents/{dependentId}": platformOrProduct,
+	"GET /databases/{id}/operations":                  platformOrProduct,
+	"GET /databases/{id}/jobs":                        platformOrProduct,
+	"GET /operations/{id}":                            platformOrProduct,
+	"POST /databases/{id}/promote":                    platformOrProduct,
+	"GET /databases/{id}/promotions":                  platformOrProduct,
+	"POST /databases/{id}/failover":                   platformOnly,
+	"POST /databases/{id}/review":                     platformOnly,
+	"GET /databases/{id}/support-bundle":              platformOnly,
+	"GET /stats":                                      platformOrProduct,
+	"GET /stats/provisioning-durations":               platformOrProduct,
+	"GET /catalog/entities":                           platformOrProduct,
+	"GET /tiers":                                      platformOrProduct,
+	"GET /tiers/{id}":                                 platformOrProduct,
+	"GET /tiers/by-name/{name}":                       platformOrProduct,
+	"POST /tiers":                                     platformOnly,
+	"POST /tiers/{id}/clone":                          platformOnly,
+	"PATCH /tiers/{id}":                               platformOnly,
+	"DELETE /tiers/{id}":                              platformOnly,
+	"GET /tiers/{id}/revisions":                       platformOnly,
+	"GET /rollouts":                                   platformOnly,
+	"GET /rollouts/{id}":                              platformOnly,
+	"POST /rollouts/{id}/pause":                       platformOnly,
+	"POST /rollouts/{id}/resume":                      platformOnly,
+	"POST /rollouts/{id}/rollback":                    platformOnly,
+	"GET /blueprints":                                 platformOrProduct,
+	"GET /blueprints/{id}":                            platformOrProduct,
+	"GET /blueprints/{id}/versions":                   platformOrProduct,
+	"GET /blueprints/{id}/documents":                  platformOrProduct,
+	"POST /blueprints":                                platformOnly,
+	"PATCH /blueprints/{id}":                          platformOnly,
+	"DELETE /blueprints/{id}":                         platformOnly,
+	"GET /blueprints/{id}/usage":                      platformOnly,
+	"POST /blueprints/{id}/sign":                      platformOnly,
+	"GET /providers/cnpg/requirements":                platformOnly,
+}
+
+// routeAccess returns who may call the route with method and pattern, or
+// false for public routes.
+func routeAccess(method, pattern string) (middleware.Access, bool) {
+	// The profiler mounted at /debug serves its own routes.
+	if strings.HasPrefix(pattern, "/debug/") {
+		return superuserOnly, true
+	}
+	access, ok := accessByRoute[method+" "+pattern]
+	return access, ok
+}
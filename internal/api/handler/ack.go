@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/database"
+)
+
+type ackRequest struct {
+	Comment string `json:"comment"`
+	Until   string `json:"until"`
+}
+
+type ackResponse struct {
+	By      string  `json:"by"`
+	Comment string  `json:"comment,omitempty"`
+	At      string  `json:"at"`
+	Until   *string `json:"until"`
+}
+
+func toAckResponse(ack *database.Acknowledgement) *ackResponse {
+	resp := &ackResponse{
+		By:      ack.By,
+		Comment: ack.Comment,
+		At:      ack.At.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if ack.Until != nil {
+		until := ack.Until.UTC().Format(time.RFC3339)
+		resp.Until = &until
+	}
+	return resp
+}
+
+// AckHandler handles the /databases/{id}/ack endpoints.
+type AckHandler struct {
+	repo database.Repository
+}
+
+// NewAckHandler creates a new AckHandler.
+func NewAckHandler(repo database.Repository) *AckHandler {
+	return &AckHandler{repo: repo}
+}
+
+// Create handles POST /databases/{id}/ack. It acknowledges a database in
+// error until the given time, or until its status changes, suppressing
+// notifications about it meanwhile.
+func (h *AckHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	var req ackRequest
+	if r.ContentLength != 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+			return
+		}
+	}
+	req.Comment = strings.TrimSpace(req.Comment)
+
+	now := time.Now()
+	fieldErrors := validation.ValidateAckRequest(validation.AckDatabaseRequest{Comment: req.Comment, Until: req.Until, Now: now})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	if db.Status != "error" {
+		response.Err(w, http.StatusConflict, "NOT_IN_ERROR", "Only a database in error can be acknowledged", requestID)
+		return
+	}
+
+	ack := &database.Acknowledgement{Comment: req.Comment, At: now.UTC()}
+	if req.Until != "" {
+		until, _ := time.Parse(time.RFC3339, req.Until) // already validated
+		ack.Until = &until
+	}
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		ack.By = identity.UserName
+	}
+
+	updated, err := h.repo.Acknowledge(r.Context(), db.ID, ack)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to acknowledge database", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to acknowledge database", requestID)
+		return
+	}
+
+	slog.Info("database error acknowledged", "database", db.Name, "by", ack.By, "until", ack.Until)
+	response.Success(w, http.StatusOK, toDatabaseResponse(updated), requestID)
+}
+
+// Delete handles DELETE /databases/{id}/ack, lifting an acknowledgement so
+// notifications resume.
+func (h *AckHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.Acknowledge(r.Context(), db.ID, nil); err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to clear acknowledgement", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to clear acknowledgement", requestID)
+		return
+	}
+
+	slog.Info("database acknowledgement cleared", "database", db.Name)
+	response.NoContent(w)
+}
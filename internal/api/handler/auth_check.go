@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/database"
+)
+
+// RouteAccess returns who may call the route with method and pattern, or
+// false for public routes.
+type RouteAccess func(method, pattern string) (middleware.Access, bool)
+
+// checkActions are the accepted actions: the methods of routes, and HEAD.
+var checkActions = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// AuthCheckHandler handles POST /auth/check.
+type AuthCheckHandler struct {
+	routes chi.Routes
+	access RouteAccess
+	repo   database.Repository
+}
+
+// NewAuthCheckHandler creates a new AuthCheckHandler answering for the routes
+// of routes, whose access is given by access. With repo non-nil, product
+// users are also checked for owning the database a resource names.
+func NewAuthCheckHandler(routes chi.Routes, access RouteAccess, repo database.Repository) *AuthCheckHandler {
+	return &AuthCheckHandler{routes: routes, access: access, repo: repo}
+}
+
+// authCheckRequest is the request body for POST /auth/check.
+type authCheckRequest struct {
+	Action   string `json:"action"`
+	Resource string `json:"resource"`
+}
+
+type authCheckResponse struct {
+	Allowed bool   `json:"allowed"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// ServeHTTP reports whether the calling identity may send a request with the
+// method in action to the API path in resource: whether its key's scopes
+// allow it, whether its role may call the route and, for product users,
+// whether their team owns the database the path names. Denials carry the
+// message of the error the request would get. Request bodies and change
+// freezes are not checked.
+func (h *AuthCheckHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var req authCheckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+
+	action := strings.ToUpper(strings.TrimSpace(req.Action))
+	var fieldErrors []validation.FieldError
+	if !slices.Contains(checkActions, action) {
+		fieldErrors = append(fieldErrors, validation.FieldError{Field: "action", Message: "action must be one of " + strings.Join(checkActions, ", ")})
+	}
+	path := ""
+	if u, err := url.Parse(strings.TrimSpace(req.Resource)); err != nil || !strings.HasPrefix(u.Path, "/") {
+		fieldErrors = append(fieldErrors, validation.FieldError{Field: "resource", Message: "resource must be an API path, such as /databases/{id}"})
+	} else {
+		path = u.Path
+	}
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+	if action == http.MethodHead {
+		action = http.MethodGet
+	}
+
+	rctx := chi.NewRouteContext()
+	pattern := h.routes.Find(rctx, action, path)
+	if pattern == "" {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed",
+			[]validation.FieldError{{Field: "resource", Message: "no route serves " + action + " " + path}}, requestID)
+		return
+	}
+
+	access, ok := h.access(action, pattern)
+	if !ok {
+		response.Success(w, http.StatusOK, authCheckResponse{Allowed: true}, requestID)
+		return
+	}
+	identity := middleware.GetIdentity(r.Context())
+	if denial := middleware.ScopeDenial(identity, action, path); denial != "" {
+		response.Success(w, http.StatusOK, authCheckResponse{Reason: denial}, requestID)
+		return
+	}
+	if denial := access.Denial(identity); denial != "" {
+		response.Success(w, http.StatusOK, authCheckResponse{Reason: denial}, requestID)
+		return
+	}
+
+	owned, err := h.ownsDatabase(r, pattern, rctx)
+	if err != nil {
+		slog.Error("failed to check database ownership", "error", err, "resource", path)
+		response.ServerErr(w, err, "Failed to check authorization", requestID)
+		return
+	}
+	if !owned {
+		response.Success(w, http.StatusOK, authCheckResponse{Reason: "Database not found"}, requestID)
+		return
+	}
+	response.Success(w, http.StatusOK, authCheckResponse{Allowed: true}, requestID)
+}
+
+// ownsDatabase reports whether a product user's team owns the database a
+// /databases/{id} or /databases/by-name/{name} route names. Other callers
+// and routes are not restricted. Like the routes themselves, a database of
+// another team is reported as not found.
+func (h *AuthCheckHandler) ownsDatabase(r *http.Request, pattern string, rctx *chi.Context) (bool, error) {
+	teamID, ok := isProductUser(r)
+	if !ok || h.repo == nil {
+		return true, nil
+	}
+
+	var db *database.Database
+	var err error
+	switch {
+	case strings.HasPrefix(pattern, "/databases/by-name/{name}"):
+		db, err = h.repo.GetByName(r.Context(), rctx.URLParam("name"))
+	case strings.HasPrefix(pattern, "/databases/{id}"):
+		id, parseErr := uuid.Parse(rctx.URLParam("id"))
+		if parseErr != nil {
+			return false, nil
+		}
+		db, err = h.repo.GetByID(r.Context(), id)
+	default:
+		return true, nil
+	}
+	if errors.Is(err, database.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return db.OwnerTeamID == *teamID, nil
+}
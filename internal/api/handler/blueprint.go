@@ -20,48 +20,110 @@ import (
 
 // createBlueprintRequest is the request body for POST /blueprints.
 type createBlueprintRequest struct {
-	Name      string `json:"name"`
-	Provider  string `json:"provider"`
-	Manifests string `json:"manifests"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Provider    string `json:"provider"`
+	Manifests   string `json:"manifests"`
+
+	TeamID         string `json:"teamId"`
+	OrganizationID string `json:"organizationId"`
+}
+
+// updateBlueprintRequest is the request body for PATCH /blueprints/{id}.
+// Name, Provider and the scope are only decoded to reject changes to them.
+type updateBlueprintRequest struct {
+	Name           *string `json:"name"`
+	Provider       *string `json:"provider"`
+	TeamID         *string `json:"teamId"`
+	OrganizationID *string `json:"organizationId"`
+	Description    *string `json:"description"`
+	Manifests      *string `json:"manifests"`
 }
 
 // blueprintResponse is the API representation of a blueprint.
 type blueprintResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Provider  string `json:"provider"`
-	Manifests string `json:"manifests"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Provider    string `json:"provider"`
+	Manifests   string `json:"manifests"`
+	Version     int    `json:"version"`
+	ContentHash string `json:"contentHash"`
+	Signature   string `json:"signature,omitempty"`
+	// TeamID or OrganizationID is set for blueprints visible only to the
+	// members of a team or an organization.
+	TeamID         *string `json:"teamId,omitempty"`
+	OrganizationID *string `json:"organizationId,omitempty"`
+	CreatedBy      string  `json:"createdBy"`
+	UpdatedBy      string  `json:"updatedBy"`
+	CreatedAt      string  `json:"createdAt"`
+	UpdatedAt      string  `json:"updatedAt"`
 }
 
 func toBlueprintResponse(bp *blueprint.Blueprint) blueprintResponse {
-	return blueprintResponse{
-		ID:        bp.ID.String(),
-		Name:      bp.Name,
-		Provider:  bp.Provider,
-		Manifests: bp.Manifests,
-		CreatedAt: bp.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: bp.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	resp := blueprintResponse{
+		ID:          bp.ID.String(),
+		Name:        bp.Name,
+		Description: bp.Description,
+		Provider:    bp.Provider,
+		Manifests:   bp.Manifests,
+		Version:     bp.Version,
+		ContentHash: blueprint.ContentHash(bp),
+		Signature:   bp.Signature,
+		CreatedBy:   bp.CreatedBy,
+		UpdatedBy:   bp.UpdatedBy,
+		CreatedAt:   bp.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:   bp.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
 	}
+	if bp.TeamID != nil {
+		teamID := bp.TeamID.String()
+		resp.TeamID = &teamID
+	}
+	if bp.OrganizationID != nil {
+		orgID := bp.OrganizationID.String()
+		resp.OrganizationID = &orgID
+	}
+	return resp
+}
+
+// blueprintVersionResponse is the API representation of a blueprint version.
+type blueprintVersionResponse struct {
+	Version   int    `json:"version"`
+	Manifests string `json:"manifests"`
+	CreatedAt string `json:"createdAt"`
 }
 
 // BlueprintHandler handles blueprint CRUD endpoints.
 type BlueprintHandler struct {
 	repo     blueprint.Repository
 	registry *provider.Registry
+	operator OperatorDetector
+	lint     blueprint.LintConfig
+	maxBytes int
+	signer   *blueprint.Signer
+}
+
+// NewBlueprintHandler creates a new BlueprintHandler. When operator is
+// non-nil, cnpg blueprints are rejected if the CloudNativePG operator in the
+// cluster cannot run them. Manifests are linted with lint, and rejected when
+// longer than maxManifestBytes, unless it is zero. When signer is non-nil,
+// blueprints are signed whenever their content is saved.
+func NewBlueprintHandler(repo blueprint.Repository, registry *provider.Registry, operator OperatorDetector, lint blueprint.LintConfig, maxManifestBytes int, signer *blueprint.Signer) *BlueprintHandler {
+	return &BlueprintHandler{repo: repo, registry: registry, operator: operator, lint: lint, maxBytes: maxManifestBytes, signer: signer}
 }
 
-// NewBlueprintHandler creates a new BlueprintHandler.
-func NewBlueprintHandler(repo blueprint.Repository, registry *provider.Registry) *BlueprintHandler {
-	return &BlueprintHandler{repo: repo, registry: registry}
+// bodyLimit is the largest request body read on create and update: 1 MiB, or
+// room for manifests of the maximum size with JSON escaping if that is more,
+// so oversized manifests get the validation error rather than INVALID_JSON.
+func (h *BlueprintHandler) bodyLimit() int64 {
+	return max(1<<20, 2*int64(h.maxBytes)+1<<16)
 }
 
 // Create handles POST /blueprints.
 func (h *BlueprintHandler) Create(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
-	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	r.Body = http.MaxBytesReader(w, r.Body, h.bodyLimit())
 	var req createBlueprintRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
@@ -72,21 +134,51 @@ func (h *BlueprintHandler) Create(w http.ResponseWriter, r *http.Request) {
 	req.Provider = strings.TrimSpace(req.Provider)
 
 	fieldErrors := validation.ValidateCreateBlueprintRequest(validation.CreateBlueprintRequest{
-		Name:      req.Name,
-		Provider:  req.Provider,
-		Manifests: req.Manifests,
-		Registry:  h.registry,
+		Name:        req.Name,
+		Description: req.Description,
+		Provider:    req.Provider,
+		Manifests:   req.Manifests,
+		Registry:    h.registry,
+
+		TeamID:         req.TeamID,
+		OrganizationID: req.OrganizationID,
+
+		MaxManifestBytes: h.maxBytes,
 	})
 	if len(fieldErrors) > 0 {
 		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
 		return
 	}
+	teamID, orgID, ok := blueprintScope(w, r, req.TeamID, req.OrganizationID, requestID)
+	if !ok {
+		return
+	}
+	if !h.manifestsRender(w, req.Provider, req.Manifests, requestID) {
+		return
+	}
+	if !lintManifests(w, req.Manifests, h.lint, requestID) {
+		return
+	}
+
+	if req.Provider == cnpgProviderName && h.operator != nil {
+		if issues := checkOperatorCompatibility(r.Context(), h.operator, req.Manifests); len(issues) > 0 {
+			response.ErrWithDetails(w, http.StatusUnprocessableEntity, "UNSUPPORTED_OPERATOR_VERSION",
+				"The CloudNativePG operator in the cluster cannot run this blueprint", issues, requestID)
+			return
+		}
+	}
 
 	bp := &blueprint.Blueprint{
-		Name:      req.Name,
-		Provider:  req.Provider,
-		Manifests: req.Manifests,
+		Name:        req.Name,
+		Description: req.Description,
+		Provider:    req.Provider,
+		Manifests:   req.Manifests,
+		CreatedBy:   actorName(r),
+
+		TeamID:         teamID,
+		OrganizationID: orgID,
 	}
+	bp.Signature = h.signer.Sign(bp)
 
 	if err := h.repo.Create(r.Context(), bp); err != nil {
 		if errors.Is(err, blueprint.ErrDuplicateBlueprintName) {
@@ -94,30 +186,34 @@ func (h *BlueprintHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to create blueprint", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create blueprint", requestID)
+		response.ServerErr(w, err, "Failed to create blueprint", requestID)
 		return
 	}
 
 	response.Success(w, http.StatusCreated, toBlueprintResponse(bp), requestID)
 }
 
-// List handles GET /blueprints.
+// List handles GET /blueprints, listing the global blueprints and those of
+// the caller's team and organization.
 func (h *BlueprintHandler) List(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
-	blueprints, err := h.repo.List(r.Context())
+	all, err := h.repo.List(r.Context())
 	if err != nil {
 		slog.Error("failed to list blueprints", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list blueprints", requestID)
+		response.ServerErr(w, err, "Failed to list blueprints", requestID)
 		return
 	}
-
-	items := make([]blueprintResponse, 0, len(blueprints))
-	for i := range blueprints {
-		items = append(items, toBlueprintResponse(&blueprints[i]))
+	blueprints := all[:0]
+	for i := range all {
+		if blueprintVisible(r, &all[i]) {
+			blueprints = append(blueprints, all[i])
+		}
 	}
 
-	response.SuccessList(w, http.StatusOK, items, len(items), 1, 100, requestID)
+	response.StreamList(w, http.StatusOK, len(blueprints), func(i int) blueprintResponse {
+		return toBlueprintResponse(&blueprints[i])
+	}, len(blueprints), 1, 100, requestID)
 }
 
 // GetByID handles GET /blueprints/{id}.
@@ -131,18 +227,212 @@ func (h *BlueprintHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	bp, err := h.repo.GetByID(r.Context(), id)
+	bp, ok := visibleBlueprint(w, r, h.repo, id, "Failed to get blueprint", requestID)
+	if !ok {
+		return
+	}
+
+	response.Success(w, http.StatusOK, toBlueprintResponse(bp), requestID)
+}
+
+// Update handles PATCH /blueprints/{id}. New manifests go through the same
+// checks as on create, and must render against a sample database, since
+// they will be re-applied to the databases of the tiers using the
+// blueprint. Changed manifests get a new version, and a new signature.
+func (h *BlueprintHandler) Update(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.bodyLimit())
+	var req updateBlueprintRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+
+	if req.Name != nil {
+		response.Err(w, http.StatusBadRequest, "IMMUTABLE_FIELD", "name cannot be changed", requestID)
+		return
+	}
+	if req.Provider != nil {
+		response.Err(w, http.StatusBadRequest, "IMMUTABLE_FIELD", "provider cannot be changed", requestID)
+		return
+	}
+	if req.TeamID != nil || req.OrganizationID != nil {
+		response.Err(w, http.StatusBadRequest, "IMMUTABLE_FIELD", "teamId and organizationId cannot be changed", requestID)
+		return
+	}
+
+	fieldErrors := validation.ValidateUpdateBlueprintRequest(validation.UpdateBlueprintRequest{
+		Description: req.Description,
+		Manifests:   req.Manifests,
+
+		MaxManifestBytes: h.maxBytes,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	current, ok := visibleBlueprint(w, r, h.repo, id, "Failed to update blueprint", requestID)
+	if !ok {
+		return
+	}
+
+	if req.Manifests != nil && *req.Manifests != current.Manifests {
+		if !h.manifestsRender(w, current.Provider, *req.Manifests, requestID) {
+			return
+		}
+		if !lintManifests(w, *req.Manifests, h.lint, requestID) {
+			return
+		}
+		if current.Provider == cnpgProviderName && h.operator != nil {
+			if issues := checkOperatorCompatibility(r.Context(), h.operator, *req.Manifests); len(issues) > 0 {
+				response.ErrWithDetails(w, http.StatusUnprocessableEntity, "UNSUPPORTED_OPERATOR_VERSION",
+					"The CloudNativePG operator in the cluster cannot run this blueprint", issues, requestID)
+				return
+			}
+		}
+	}
+
+	fields := blueprint.UpdateFields{
+		Description: req.Description,
+		Manifests:   req.Manifests,
+		UpdatedBy:   actorName(r),
+	}
+	// Only new manifests are signed: re-signing unchanged ones would vouch
+	// for content that may have been changed outside the API.
+	if h.signer != nil && req.Manifests != nil && *req.Manifests != current.Manifests {
+		signed := *current
+		signed.Manifests = *req.Manifests
+		signature := h.signer.Sign(&signed)
+		fields.Signature = &signature
+	}
+
+	updated, err := h.repo.Update(r.Context(), id, fields)
 	if err != nil {
 		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
 			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Blueprint not found", requestID)
 			return
 		}
-		slog.Error("failed to get blueprint", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get blueprint", requestID)
+		slog.Error("failed to update blueprint", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to update blueprint", requestID)
 		return
 	}
+	if updated.Version != current.Version {
+		slog.Info("blueprint manifests updated", "blueprint", updated.Name, "version", updated.Version)
+	}
 
-	response.Success(w, http.StatusOK, toBlueprintResponse(bp), requestID)
+	response.Success(w, http.StatusOK, toBlueprintResponse(updated), requestID)
+}
+
+// Sign handles POST /blueprints/{id}/sign, signing the blueprint's current
+// content, such as that of a blueprint saved before signing was enabled.
+// The caller vouches for the content: it should be reviewed first, since
+// content changed outside the API would be signed as well.
+func (h *BlueprintHandler) Sign(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+	if h.signer == nil {
+		response.Err(w, http.StatusConflict, "SIGNING_DISABLED", "Blueprint signing is not enabled", requestID)
+		return
+	}
+
+	current, ok := visibleBlueprint(w, r, h.repo, id, "Failed to sign blueprint", requestID)
+	if !ok {
+		return
+	}
+	signature := h.signer.Sign(current)
+	updated, err := h.repo.Update(r.Context(), id, blueprint.UpdateFields{Signature: &signature, UpdatedBy: actorName(r)})
+	if err != nil {
+		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Blueprint not found", requestID)
+			return
+		}
+		slog.Error("failed to sign blueprint", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to sign blueprint", requestID)
+		return
+	}
+	slog.Info("blueprint signed", "blueprint", updated.Name, "version", updated.Version, "contentHash", blueprint.ContentHash(updated))
+
+	response.Success(w, http.StatusOK, toBlueprintResponse(updated), requestID)
+}
+
+// Versions handles GET /blueprints/{id}/versions.
+func (h *BlueprintHandler) Versions(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	if _, ok := visibleBlueprint(w, r, h.repo, id, "Failed to list blueprint versions", requestID); !ok {
+		return
+	}
+
+	versions, err := h.repo.ListVersions(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Blueprint not found", requestID)
+			return
+		}
+		slog.Error("failed to list blueprint versions", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to list blueprint versions", requestID)
+		return
+	}
+
+	response.StreamList(w, http.StatusOK, len(versions), func(i int) blueprintVersionResponse {
+		v := versions[i]
+		return blueprintVersionResponse{
+			Version:   v.Version,
+			Manifests: v.Manifests,
+			CreatedAt: v.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+	}, len(versions), 1, len(versions), requestID)
+}
+
+// blueprintDocumentResponse is the API representation of one document of a
+// blueprint's manifests.
+type blueprintDocumentResponse struct {
+	Index      int    `json:"index"`
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+}
+
+// Documents handles GET /blueprints/{id}/documents, listing the documents of
+// the blueprint's current manifests in order.
+func (h *BlueprintHandler) Documents(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	bp, ok := visibleBlueprint(w, r, h.repo, id, "Failed to get blueprint", requestID)
+	if !ok {
+		return
+	}
+
+	docs := bp.Documents
+	response.StreamList(w, http.StatusOK, len(docs), func(i int) blueprintDocumentResponse {
+		return blueprintDocumentResponse(docs[i])
+	}, len(docs), 1, len(docs), requestID)
 }
 
 // Delete handles DELETE /blueprints/{id}.
@@ -156,6 +446,10 @@ func (h *BlueprintHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, ok := visibleBlueprint(w, r, h.repo, id, "Failed to delete blueprint", requestID); !ok {
+		return
+	}
+
 	if err := h.repo.Delete(r.Context(), id); err != nil {
 		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
 			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Blueprint not found", requestID)
@@ -166,9 +460,121 @@ func (h *BlueprintHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to delete blueprint", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to delete blueprint", requestID)
+		response.ServerErr(w, err, "Failed to delete blueprint", requestID)
 		return
 	}
 
 	response.NoContent(w)
 }
+
+// blueprintVisible reports whether the caller sees blueprint bp: global
+// blueprints are visible to everyone, scoped ones only to the members of
+// their team or organization.
+func blueprintVisible(r *http.Request, bp *blueprint.Blueprint) bool {
+	identity := middleware.GetIdentity(r.Context())
+	if identity == nil {
+		return bp.VisibleTo(nil, nil)
+	}
+	return bp.VisibleTo(identity.TeamID, identity.OrganizationID)
+}
+
+// visibleBlueprint returns the blueprint with id, or writes an error response
+// and returns false. Blueprints the caller does not see are not found.
+func visibleBlueprint(w http.ResponseWriter, r *http.Request, repo blueprint.Repository, id uuid.UUID, failure, requestID string) (*blueprint.Blueprint, bool) {
+	bp, err := repo.GetByID(r.Context(), id)
+	if err == nil && !blueprintVisible(r, bp) {
+		err = blueprint.ErrBlueprintNotFound
+	}
+	if err != nil {
+		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Blueprint not found", requestID)
+			return nil, false
+		}
+		slog.Error("failed to get blueprint", "error", err, "id", id)
+		response.ServerErr(w, err, failure, requestID)
+		return nil, false
+	}
+	return bp, true
+}
+
+// blueprintScope resolves the teamId and organizationId of a new blueprint,
+// already validated, or writes a 403 response and returns false: callers may
+// only scope blueprints to their own team or organization.
+func blueprintScope(w http.ResponseWriter, r *http.Request, teamIDStr, orgIDStr, requestID string) (*uuid.UUID, *uuid.UUID, bool) {
+	identity := middleware.GetIdentity(r.Context())
+	var teamID, orgID *uuid.UUID
+	if teamIDStr != "" {
+		id, _ := uuid.Parse(teamIDStr)
+		if identity == nil || identity.TeamID == nil || *identity.TeamID != id {
+			response.Err(w, http.StatusForbidden, "FORBIDDEN", "Blueprints can only be scoped to your own team or organization", requestID)
+			return nil, nil, false
+		}
+		teamID = &id
+	}
+	if orgIDStr != "" {
+		id, _ := uuid.Parse(orgIDStr)
+		if identity == nil || identity.OrganizationID == nil || *identity.OrganizationID != id {
+			response.Err(w, http.StatusForbidden, "FORBIDDEN", "Blueprints can only be scoped to your own team or organization", requestID)
+			return nil, nil, false
+		}
+		orgID = &id
+	}
+	return teamID, orgID, true
+}
+
+// lintManifests lints blueprint manifests. Error findings are written as a
+// 400 VALIDATION_ERROR with one field error each, and false is returned;
+// warnings are added as Warning headers and the request goes on.
+func lintManifests(w http.ResponseWriter, manifests string, cfg blueprint.LintConfig, requestID string) bool {
+	var errs []validation.FieldError
+	for _, f := range blueprint.Lint(manifests, cfg) {
+		if f.Severity == blueprint.SeverityError {
+			errs = append(errs, validation.FieldError{Field: "manifests", Message: f.String()})
+			continue
+		}
+		w.Header().Add("Warning", fmt.Sprintf("299 daap %q", f.String()))
+	}
+	if len(errs) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Blueprint manifests failed lint rules", errs, requestID)
+		return false
+	}
+	return true
+}
+
+// manifestsRender renders manifests against a sample database with the
+// provider's renderer, writing a 400 VALIDATION_ERROR and returning false if
+// they do not render. Providers that cannot render are not checked.
+func (h *BlueprintHandler) manifestsRender(w http.ResponseWriter, providerName, manifests, requestID string) bool {
+	p, ok := h.registry.Get(providerName)
+	if !ok {
+		return true
+	}
+	renderer, ok := p.(provider.ManifestRenderer)
+	if !ok {
+		return true
+	}
+	_, err := renderer.RenderManifests(sampleProviderDatabase(providerName), manifests)
+	if err == nil || errors.Is(err, provider.ErrNotSupported) {
+		return true
+	}
+	response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed",
+		[]validation.FieldError{{Field: "manifests", Message: "manifests do not render for a sample database: " + err.Error()}}, requestID)
+	return false
+}
+
+// sampleProviderDatabase is the database manifests are test-rendered for.
+func sampleProviderDatabase(providerName string) provider.ProviderDatabase {
+	return provider.ProviderDatabase{
+		ID:          uuid.Nil,
+		Name:        "example",
+		Namespace:   "default",
+		ClusterName: "daap-example",
+		PoolerName:  "daap-example-pooler",
+		OwnerTeam:   "example-team",
+		OwnerTeamID: uuid.Nil,
+		Tier:        "example-tier",
+		TierID:      uuid.Nil,
+		Blueprint:   "example-blueprint",
+		Provider:    providerName,
+	}
+}
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// BlueprintUsageHandler handles the GET /blueprints/{id}/usage endpoint.
+type BlueprintUsageHandler struct {
+	bpRepo   blueprint.Repository
+	tierRepo tier.Repository
+	repo     database.Repository
+}
+
+// NewBlueprintUsageHandler creates a new BlueprintUsageHandler.
+func NewBlueprintUsageHandler(bpRepo blueprint.Repository, tierRepo tier.Repository, repo database.Repository) *BlueprintUsageHandler {
+	return &BlueprintUsageHandler{bpRepo: bpRepo, tierRepo: tierRepo, repo: repo}
+}
+
+type blueprintUsageTierResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Databases int    `json:"databases"`
+}
+
+type blueprintUsageResponse struct {
+	BlueprintID    string                       `json:"blueprintId"`
+	BlueprintName  string                       `json:"blueprintName"`
+	Tiers          []blueprintUsageTierResponse `json:"tiers"`
+	TotalDatabases int                          `json:"totalDatabases"`
+}
+
+// ServeHTTP lists the tiers referencing a blueprint, in creation order, with
+// the number of active databases on each: what deleting or changing the
+// blueprint would affect.
+func (h *BlueprintUsageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	bp, ok := visibleBlueprint(w, r, h.bpRepo, id, "Failed to get blueprint usage", requestID)
+	if !ok {
+		return
+	}
+
+	tiers, err := h.tierRepo.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list tiers", "error", err)
+		response.ServerErr(w, err, "Failed to get blueprint usage", requestID)
+		return
+	}
+
+	resp := blueprintUsageResponse{BlueprintID: bp.ID.String(), BlueprintName: bp.Name, Tiers: []blueprintUsageTierResponse{}}
+	for _, t := range tiers {
+		if t.BlueprintID == nil || *t.BlueprintID != bp.ID {
+			continue
+		}
+		// Only the total is needed, so fetch a single row.
+		result, err := h.repo.List(r.Context(), database.ListFilter{TierID: &t.ID, Page: 1, Limit: 1})
+		if err != nil {
+			slog.Error("failed to count tier databases", "error", err, "tier", t.Name)
+			response.ServerErr(w, err, "Failed to get blueprint usage", requestID)
+			return
+		}
+		resp.Tiers = append(resp.Tiers, blueprintUsageTierResponse{ID: t.ID.String(), Name: t.Name, Databases: result.Total})
+		resp.TotalDatabases += result.Total
+	}
+
+	response.Success(w, http.StatusOK, resp, requestID)
+}
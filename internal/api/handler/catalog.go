@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"sigs.k8s.io/yaml"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/catalog"
+)
+
+// CatalogSource lists the catalog entities describing managed databases.
+type CatalogSource interface {
+	Entities(ctx context.Context, filter catalog.Filter) ([]catalog.Entity, error)
+}
+
+// CatalogHandler handles the GET /catalog/entities endpoint.
+type CatalogHandler struct {
+	source CatalogSource
+}
+
+// NewCatalogHandler creates a new CatalogHandler.
+func NewCatalogHandler(source CatalogSource) *CatalogHandler {
+	return &CatalogHandler{source: source}
+}
+
+// ServeHTTP returns every database as a Backstage Resource entity. By default
+// the entities are the data of a standard envelope; with ?format=yaml they
+// are written as a multi-document YAML catalog file that a Backstage url
+// location can ingest directly. Product users only see their own team's
+// databases.
+func (h *CatalogHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "yaml" {
+		response.Err(w, http.StatusBadRequest, "INVALID_PARAM", "format must be json or yaml", requestID)
+		return
+	}
+
+	var filter catalog.Filter
+	if teamID, ok := isProductUser(r); ok {
+		filter.OwnerTeamID = teamID
+	}
+
+	entities, err := h.source.Entities(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to build catalog entities", "error", err)
+		response.ServerErr(w, err, "Failed to build catalog entities", requestID)
+		return
+	}
+	if entities == nil {
+		entities = []catalog.Entity{}
+	}
+
+	if format != "yaml" {
+		response.Success(w, http.StatusOK, entities, requestID)
+		return
+	}
+
+	var buf bytes.Buffer
+	for i := range entities {
+		doc, err := yaml.Marshal(&entities[i])
+		if err != nil {
+			slog.Error("failed to encode catalog entity", "error", err)
+			response.ServerErr(w, err, "Failed to build catalog entities", requestID)
+			return
+		}
+		buf.WriteString("---\n")
+		buf.Write(doc)
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
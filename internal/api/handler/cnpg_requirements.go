@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/provider/cnpg"
+)
+
+// cnpgProviderName is the registry name of the built-in CNPG provider.
+const cnpgProviderName = "cnpg"
+
+var errNoOperatorVersion = errors.New("no version label or image tag on the operator deployment")
+
+// OperatorDetector finds the CloudNativePG operator installed in the cluster.
+type OperatorDetector interface {
+	Detect(ctx context.Context) (cnpg.Operator, error)
+}
+
+// CNPGRequirementsHandler handles the GET /providers/cnpg/requirements
+// endpoint.
+type CNPGRequirementsHandler struct {
+	detector OperatorDetector
+	bpRepo   blueprint.Repository
+}
+
+// NewCNPGRequirementsHandler creates a new CNPGRequirementsHandler. bpRepo
+// may be nil, in which case no blueprint compatibility is reported.
+func NewCNPGRequirementsHandler(detector OperatorDetector, bpRepo blueprint.Repository) *CNPGRequirementsHandler {
+	return &CNPGRequirementsHandler{detector: detector, bpRepo: bpRepo}
+}
+
+type cnpgOperatorResponse struct {
+	Detected   bool   `json:"detected"`
+	Namespace  string `json:"namespace"`
+	Deployment string `json:"deployment,omitempty"`
+	Version    string `json:"version,omitempty"`
+	Chart      string `json:"chart,omitempty"`
+}
+
+type blueprintCompatibilityResponse struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Kinds      []string `json:"kinds"`
+	Compatible bool     `json:"compatible"`
+	Issues     []string `json:"issues"`
+}
+
+type cnpgRequirementsResponse struct {
+	Operator          cnpgOperatorResponse             `json:"operator"`
+	SupportedVersions string                           `json:"supportedVersions"`
+	Supported         bool                             `json:"supported"`
+	Issues            []string                         `json:"issues"`
+	Blueprints        []blueprintCompatibilityResponse `json:"blueprints"`
+}
+
+// ServeHTTP reports the detected operator version and whether it, and every
+// cnpg blueprint, is supported. The status code is 200 whether or not the
+// operator is supported; clients read data.supported.
+func (h *CNPGRequirementsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	op, err := h.detector.Detect(r.Context())
+	if err != nil {
+		slog.Error("failed to detect cnpg operator", "error", err)
+		response.ServerErr(w, err, "Failed to detect the CloudNativePG operator", requestID)
+		return
+	}
+
+	resp := cnpgRequirementsResponse{
+		Operator: cnpgOperatorResponse{
+			Detected:   op.Detected,
+			Namespace:  op.Namespace,
+			Deployment: op.Deployment,
+			Version:    op.Version,
+			Chart:      op.Chart,
+		},
+		SupportedVersions: cnpg.SupportedRange(),
+		Issues:            []string{},
+		Blueprints:        []blueprintCompatibilityResponse{},
+	}
+
+	version, versionErr := operatorVersion(op)
+	switch {
+	case !op.Detected:
+		resp.Issues = append(resp.Issues, "CloudNativePG operator not found in namespace "+op.Namespace)
+	case versionErr != nil:
+		resp.Issues = append(resp.Issues, "cannot determine the operator version: "+versionErr.Error())
+	case !version.Supported():
+		resp.Issues = append(resp.Issues, cnpg.CheckManifests(version, "")...)
+	default:
+		resp.Supported = true
+	}
+
+	if h.bpRepo != nil {
+		blueprints, err := h.bpRepo.List(r.Context())
+		if err != nil {
+			slog.Error("failed to list blueprints", "error", err)
+			response.ServerErr(w, err, "Failed to list blueprints", requestID)
+			return
+		}
+		for i := range blueprints {
+			bp := &blueprints[i]
+			if bp.Provider != cnpgProviderName {
+				continue
+			}
+			item := blueprintCompatibilityResponse{
+				ID:     bp.ID.String(),
+				Name:   bp.Name,
+				Kinds:  cnpg.ManifestKinds(bp.Manifests),
+				Issues: []string{},
+			}
+			if item.Kinds == nil {
+				item.Kinds = []string{}
+			}
+			// Compatibility is only known once the operator version is.
+			if op.Detected && versionErr == nil {
+				item.Issues = append(item.Issues, cnpg.CheckManifests(version, bp.Manifests)...)
+				item.Compatible = len(item.Issues) == 0
+			}
+			resp.Blueprints = append(resp.Blueprints, item)
+		}
+	}
+
+	response.Success(w, http.StatusOK, resp, requestID)
+}
+
+// operatorVersion parses the version of a detected operator.
+func operatorVersion(op cnpg.Operator) (cnpg.Version, error) {
+	if op.Version == "" {
+		return cnpg.Version{}, errNoOperatorVersion
+	}
+	return cnpg.ParseVersion(op.Version)
+}
+
+// checkOperatorCompatibility returns the reasons cnpg manifests cannot run on
+// the operator in the cluster. It only blocks on what it can see: a missing
+// operator, an unknown version or a failed lookup yield no issues.
+func checkOperatorCompatibility(ctx context.Context, detector OperatorDetector, manifests string) []string {
+	op, err := detector.Detect(ctx)
+	if err != nil {
+		slog.Warn("skipping cnpg operator version check", "error", err)
+		return nil
+	}
+	if !op.Detected {
+		return nil
+	}
+	version, err := operatorVersion(op)
+	if err != nil {
+		slog.Warn("skipping cnpg operator version check", "deployment", op.Deployment, "error", err)
+		return nil
+	}
+	return cnpg.CheckManifests(version, manifests)
+}
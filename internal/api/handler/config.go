@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/buildinfo"
+	"github.com/daap14/daap/internal/config"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// ConfigSource holds the running configuration and changes its reloadable
+// settings.
+type ConfigSource interface {
+	// Effective returns the current settings, already redacted.
+	Effective() []config.Setting
+	// Set changes the settings in values, keyed by environment variable,
+	// all or none; it returns config.SettingErrors for rejected ones.
+	Set(values map[string]string) error
+}
+
+// ConfigHandler handles the /admin/config endpoints.
+type ConfigHandler struct {
+	source   ConfigSource
+	info     buildinfo.Info
+	registry *provider.Registry
+}
+
+// NewConfigHandler creates a new ConfigHandler reporting the settings of
+// source alongside the build info and registered providers.
+func NewConfigHandler(source ConfigSource, info buildinfo.Info, registry *provider.Registry) *ConfigHandler {
+	return &ConfigHandler{source: source, info: info, registry: registry}
+}
+
+type configResponse struct {
+	Version   string            `json:"version"`
+	Providers []string          `json:"providers"`
+	Features  []string          `json:"features"`
+	Settings  map[string]string `json:"settings"`
+}
+
+type updateConfigRequest struct {
+	Settings map[string]string `json:"settings"`
+}
+
+// Get handles GET /admin/config, returning the effective configuration.
+func (h *ConfigHandler) Get(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+	response.Success(w, http.StatusOK, h.toResponse(), requestID)
+}
+
+// Update handles PUT /admin/config. Only the reloadable settings can be
+// changed; they apply right away, without a restart, and last until the
+// next one.
+func (h *ConfigHandler) Update(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req updateConfigRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	if len(req.Settings) == 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed",
+			[]validation.FieldError{{Field: "settings", Message: "settings must name at least one setting"}}, requestID)
+		return
+	}
+
+	if err := h.source.Set(req.Settings); err != nil {
+		var settingErrs config.SettingErrors
+		if !errors.As(err, &settingErrs) {
+			response.ServerErr(w, err, "Failed to update the configuration", requestID)
+			return
+		}
+		fieldErrors := make([]validation.FieldError, len(settingErrs))
+		for i, se := range settingErrs {
+			fieldErrors[i] = validation.FieldError{Field: "settings." + se.Name, Message: se.Name + " " + se.Message}
+		}
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, h.toResponse(), requestID)
+}
+
+func (h *ConfigHandler) toResponse() configResponse {
+	settings := h.source.Effective()
+	resp := configResponse{
+		Version:   h.info.Version,
+		Providers: []string{},
+		Features:  h.info.Features,
+		Settings:  make(map[string]string, len(settings)),
+	}
+	if h.registry != nil {
+		resp.Providers = h.registry.Names()
+	}
+	if resp.Features == nil {
+		resp.Features = []string{}
+	}
+	for _, s := range settings {
+		resp.Settings[s.Name] = s.Value
+	}
+	return resp
+}
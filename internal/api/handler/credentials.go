@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// RotateCredentials handles POST /databases/{id}/rotate-credentials. It asks
+// the provider to regenerate the password of the database's application
+// user in the secret applications read it from, and records the time in
+// credentialsRotatedAt. Change freezes do not apply: leaked credentials must
+// be rotatable at any time.
+func (h *DatabaseHandler) RotateCredentials(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "database.rotate_credentials", db.ID.String(), "")
+
+	if db.Status != "ready" {
+		response.Err(w, http.StatusConflict, "ROTATION_NOT_POSSIBLE",
+			fmt.Sprintf("Database must be ready to rotate its credentials (status is %s)", db.Status), requestID)
+		return
+	}
+
+	release, ok := lockDatabase(w, r, h.locker, db.ID, "rotate-credentials", requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	p, pdb, ok := h.databaseProvider(w, r, db, "ROTATION_NOT_POSSIBLE", requestID)
+	if !ok {
+		return
+	}
+	rotator, ok := p.(provider.CredentialRotator)
+	if !ok {
+		response.Err(w, http.StatusConflict, "ROTATION_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support credential rotation", pdb.Provider), requestID)
+		return
+	}
+
+	if err := rotator.RotateCredentials(r.Context(), pdb); err != nil {
+		if errors.Is(err, provider.ErrNotSupported) {
+			response.Err(w, http.StatusConflict, "ROTATION_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support credential rotation", pdb.Provider), requestID)
+			return
+		}
+		slog.Error("provider.RotateCredentials failed", "error", err, "database", db.Name, "provider", pdb.Provider)
+		response.ServerErr(w, err, "Failed to rotate database credentials", requestID)
+		return
+	}
+
+	now := time.Now()
+	updated, err := h.repo.Update(r.Context(), db.ID, database.UpdateFields{CredentialsRotatedAt: &now, UpdatedBy: actorName(r)})
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to record credential rotation", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to rotate database credentials", requestID)
+		return
+	}
+	slog.Info("database credentials rotated", "database", db.Name)
+
+	response.Success(w, http.StatusOK, toDatabaseResponse(updated), requestID)
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -16,54 +17,263 @@ import (
 	"github.com/daap14/daap/internal/api/middleware"
 	"github.com/daap14/daap/internal/api/response"
 	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/archive"
 	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/budget"
 	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/imagepolicy"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/placement"
 	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/provision"
 	"github.com/daap14/daap/internal/team"
 	"github.com/daap14/daap/internal/tier"
 )
 
 // createDatabaseRequest is the request body for POST /databases.
 type createDatabaseRequest struct {
-	Name      string `json:"name"`
-	OwnerTeam string `json:"ownerTeam"`
-	Tier      string `json:"tier"`
-	Purpose   string `json:"purpose"`
-	Namespace string `json:"namespace"`
+	Name        string `json:"name"`
+	OwnerTeam   string `json:"ownerTeam"`
+	Tier        string `json:"tier"`
+	Purpose     string `json:"purpose"`
+	Namespace   string `json:"namespace"`
+	Environment string `json:"environment"`
+
+	DataClassification string           `json:"dataClassification"`
+	Exposure           *exposureRequest `json:"exposure"`
+	QueryInsights      bool             `json:"queryInsights"`
+}
+
+// exposureRequest is the exposure object of create database requests.
+type exposureRequest struct {
+	Type                string   `json:"type"`
+	AllowedSourceRanges []string `json:"allowedSourceRanges"`
+}
+
+// toExposure converts the request into an exposure. A nil request keeps the
+// database inside the cluster.
+func (r *exposureRequest) toExposure() database.Exposure {
+	if r == nil {
+		return database.Exposure{}
+	}
+	ranges := make([]string, 0, len(r.AllowedSourceRanges))
+	for _, cidr := range r.AllowedSourceRanges {
+		ranges = append(ranges, strings.TrimSpace(cidr))
+	}
+	return database.Exposure{Type: strings.TrimSpace(r.Type), AllowedSourceRanges: ranges}
 }
 
 // databaseResponse is the API representation of a database record.
 type databaseResponse struct {
-	ID          string  `json:"id"`
-	Name        string  `json:"name"`
-	OwnerTeam   string  `json:"ownerTeam"`
-	Tier        string  `json:"tier,omitempty"`
-	Purpose     string  `json:"purpose"`
-	Namespace   string  `json:"namespace"`
-	ClusterName string  `json:"clusterName"`
-	PoolerName  string  `json:"poolerName"`
-	Status      string  `json:"status"`
-	Host        *string `json:"host,omitempty"`
-	Port        *int    `json:"port,omitempty"`
-	SecretName  *string `json:"secretName,omitempty"`
-	CreatedAt   string  `json:"createdAt"`
-	UpdatedAt   string  `json:"updatedAt"`
+	ID                   string              `json:"id"`
+	Name                 string              `json:"name"`
+	OwnerTeam            string              `json:"ownerTeam"`
+	Tier                 string              `json:"tier,omitempty"`
+	Purpose              string              `json:"purpose"`
+	DataClassification   string              `json:"dataClassification"`
+	Namespace            string              `json:"namespace"`
+	Placement            *placementResponse  `json:"placement,omitempty"`
+	Environment          string              `json:"environment,omitempty"`
+	PromotedFromID       *string             `json:"promotedFromId,omitempty"`
+	SourceDatabaseID     *string             `json:"sourceDatabaseId,omitempty"`
+	ClusterName          string              `json:"clusterName"`
+	PoolerName           string              `json:"poolerName"`
+	Status               string              `json:"status"`
+	StatusReason         *string             `json:"statusReason,omitempty"`
+	StatusMessage        *string             `json:"statusMessage,omitempty"`
+	Host                 *string             `json:"host,omitempty"`
+	Port                 *int                `json:"port,omitempty"`
+	SecretName           *string             `json:"secretName,omitempty"`
+	CredentialsRotatedAt *string             `json:"credentialsRotatedAt,omitempty"`
+	Exposure             exposureResponse    `json:"exposure"`
+	ExternalHost         *string             `json:"externalHost,omitempty"`
+	DNSName              string              `json:"dnsName,omitempty"`
+	QueryInsights        bool                `json:"queryInsights"`
+	LegalHold            bool                `json:"legalHold"`
+	ArchiveURL           *string             `json:"archiveUrl,omitempty"`
+	Generation           int64               `json:"generation"`
+	ObservedGeneration   int64               `json:"observedGeneration"`
+	Acknowledgement      *ackResponse        `json:"acknowledgement,omitempty"`
+	ReconciliationPause  *pauseResponse      `json:"reconciliationPause,omitempty"`
+	Rename               *renameResponse     `json:"rename,omitempty"`
+	Instances            *instancesResponse  `json:"instances,omitempty"`
+	OperatorVersion      string              `json:"operatorVersion,omitempty"`
+	Images               []imagePinResponse  `json:"images,omitempty"`
+	Conditions           []conditionResponse `json:"conditions,omitempty"`
+	Labels               map[string]string   `json:"labels,omitempty"`
+	Annotations          map[string]string   `json:"annotations,omitempty"`
+	CreatedBy            string              `json:"createdBy"`
+	UpdatedBy            string              `json:"updatedBy"`
+	CreatedAt            string              `json:"createdAt"`
+	UpdatedAt            string              `json:"updatedAt"`
+}
+
+// instancesResponse is the JSON representation of a database's instances.
+type instancesResponse struct {
+	Total                 int      `json:"total"`
+	Ready                 int      `json:"ready"`
+	Primary               string   `json:"primary,omitempty"`
+	ReplicationLagSeconds *float64 `json:"replicationLagSeconds,omitempty"`
+}
+
+func toInstancesResponse(in *database.Instances) *instancesResponse {
+	resp := &instancesResponse{Total: in.Total, Ready: in.Ready, Primary: in.Primary}
+	if in.ReplicationLag != nil {
+		lag := in.ReplicationLag.Seconds()
+		resp.ReplicationLagSeconds = &lag
+	}
+	return resp
+}
+
+// placementResponse is the JSON representation of a placement decision.
+type placementResponse struct {
+	Namespace  string                       `json:"namespace"`
+	Reason     string                       `json:"reason"`
+	Candidates []placementCandidateResponse `json:"candidates"`
+	DecidedAt  string                       `json:"decidedAt"`
+}
+
+// placementCandidateResponse is the JSON representation of a namespace
+// considered by a placement decision.
+type placementCandidateResponse struct {
+	Namespace string `json:"namespace"`
+	Databases int    `json:"databases"`
+	Capacity  int    `json:"capacity"`
+	Excluded  string `json:"excluded,omitempty"`
+}
+
+func toPlacementResponse(p *database.Placement) *placementResponse {
+	resp := &placementResponse{
+		Namespace:  p.Namespace,
+		Reason:     p.Reason,
+		Candidates: make([]placementCandidateResponse, len(p.Candidates)),
+		DecidedAt:  p.DecidedAt.UTC().Format(time.RFC3339),
+	}
+	for i, c := range p.Candidates {
+		resp.Candidates[i] = placementCandidateResponse(c)
+	}
+	return resp
+}
+
+// imagePinResponse is the JSON representation of an image pinned when the
+// database was provisioned.
+type imagePinResponse struct {
+	Reference string `json:"reference"`
+	Image     string `json:"image"`
+	Digest    string `json:"digest,omitempty"`
+}
+
+// exposureResponse is the JSON representation of a database's exposure.
+type exposureResponse struct {
+	Type                string   `json:"type"`
+	AllowedSourceRanges []string `json:"allowedSourceRanges"`
+}
+
+func toExposureResponse(e database.Exposure) exposureResponse {
+	resp := exposureResponse{Type: e.Type, AllowedSourceRanges: e.AllowedSourceRanges}
+	if resp.Type == "" {
+		resp.Type = database.ExposureCluster
+	}
+	if resp.AllowedSourceRanges == nil {
+		resp.AllowedSourceRanges = []string{}
+	}
+	return resp
+}
+
+// pauseResponse is the JSON representation of a reconciliation pause.
+type pauseResponse struct {
+	By    string `json:"by"`
+	Until string `json:"until"`
+}
+
+// conditionResponse is the JSON representation of a database condition.
+type conditionResponse struct {
+	Type    string `json:"type"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+	Since   string `json:"since"`
 }
 
 // toDatabaseResponse converts a database model to its API response representation.
 func toDatabaseResponse(db *database.Database) databaseResponse {
 	resp := databaseResponse{
-		ID:          db.ID.String(),
-		Name:        db.Name,
-		OwnerTeam:   db.OwnerTeamName,
-		Tier:        db.TierName,
-		Purpose:     db.Purpose,
-		Namespace:   db.Namespace,
-		ClusterName: db.ClusterName,
-		PoolerName:  db.PoolerName,
-		Status:      db.Status,
-		CreatedAt:   db.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:   db.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		ID:                 db.ID.String(),
+		Name:               db.Name,
+		OwnerTeam:          db.OwnerTeamName,
+		Tier:               db.TierName,
+		Purpose:            db.Purpose,
+		DataClassification: db.DataClassification,
+		Namespace:          db.Namespace,
+		Environment:        db.Environment,
+		ClusterName:        db.ClusterName,
+		PoolerName:         db.PoolerName,
+		Status:             db.Status,
+		StatusReason:       db.StatusReason,
+		StatusMessage:      db.StatusMessage,
+		Generation:         db.Generation,
+		ObservedGeneration: db.ObservedGeneration,
+		OperatorVersion:    db.OperatorVersion,
+		Exposure:           toExposureResponse(db.Exposure),
+		ExternalHost:       db.ExternalHost,
+		DNSName:            db.DNSName,
+		QueryInsights:      db.QueryInsights,
+		LegalHold:          db.LegalHold,
+		Labels:             db.OwnerTeamLabels,
+		Annotations:        db.OwnerTeamAnnotations,
+		CreatedBy:          db.CreatedBy,
+		UpdatedBy:          db.UpdatedBy,
+		CreatedAt:          db.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:          db.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if db.PromotedFromID != nil {
+		id := db.PromotedFromID.String()
+		resp.PromotedFromID = &id
+	}
+	if db.SourceDatabaseID != nil {
+		id := db.SourceDatabaseID.String()
+		resp.SourceDatabaseID = &id
+	}
+	if db.CredentialsRotatedAt != nil {
+		at := db.CredentialsRotatedAt.UTC().Format(time.RFC3339)
+		resp.CredentialsRotatedAt = &at
+	}
+	if db.Acknowledged(time.Now()) {
+		resp.Acknowledgement = toAckResponse(db.Ack)
+	}
+	if db.ReconciliationPaused(time.Now()) {
+		resp.ReconciliationPause = &pauseResponse{
+			By:    db.ReconciliationPause.By,
+			Until: db.ReconciliationPause.Until.UTC().Format(time.RFC3339),
+		}
+	}
+	if db.Placement != nil {
+		resp.Placement = toPlacementResponse(db.Placement)
+	}
+	if db.Rename != nil {
+		resp.Rename = &renameResponse{
+			From:        db.Rename.From,
+			To:          db.Rename.To,
+			Phase:       db.Rename.Phase,
+			RequestedBy: db.Rename.RequestedBy,
+			StartedAt:   db.Rename.StartedAt.UTC().Format(time.RFC3339),
+		}
+	}
+	if db.Instances != nil {
+		resp.Instances = toInstancesResponse(db.Instances)
+	}
+	for _, pin := range db.Images {
+		resp.Images = append(resp.Images, imagePinResponse(pin))
+	}
+	for _, c := range db.Conditions {
+		resp.Conditions = append(resp.Conditions, conditionResponse{
+			Type:    c.Type,
+			Reason:  c.Reason,
+			Message: c.Message,
+			Since:   c.Since.UTC().Format("2006-01-02T15:04:05Z"),
+		})
 	}
 	if db.Status == "ready" {
 		resp.Host = db.Host
@@ -78,6 +288,18 @@ type updateDatabaseRequest struct {
 	Name      *string `json:"name,omitempty"`
 	OwnerTeam *string `json:"ownerTeam,omitempty"`
 	Purpose   *string `json:"purpose,omitempty"`
+
+	DataClassification *string `json:"dataClassification,omitempty"`
+	QueryInsights      *bool   `json:"queryInsights,omitempty"`
+
+	// ReconciliationPaused pauses (true) or resumes (false) reconciliation,
+	// until ReconciliationPausedUntil or for the default pause duration.
+	ReconciliationPaused      *bool   `json:"reconciliationPaused,omitempty"`
+	ReconciliationPausedUntil *string `json:"reconciliationPausedUntil,omitempty"`
+
+	// LegalHold places (true) or clears (false) a legal hold, which keeps
+	// the database from being deleted until it is cleared.
+	LegalHold *bool `json:"legalHold,omitempty"`
 }
 
 // DatabaseHandler handles database CRUD endpoints.
@@ -88,18 +310,132 @@ type DatabaseHandler struct {
 	bpRepo   blueprint.Repository
 	registry *provider.Registry
 	ns       string
+	freezes  freeze.Gate
+	envs     database.Environments
+	deps     database.DependentRepository
+	locker   *database.Locker
+	ops      *operation.Tracker
+	// deleteWait bounds how long a teardown waits for the provider to
+	// confirm the resources are gone.
+	deleteWait time.Duration
+	specs      database.SpecRepository
+	quotas     organization.QuotaGate
+	placer     placement.Placer
+	images     imagepolicy.Pinner
+	signer     *blueprint.Signer
+	dnsZone    database.DNSZone
+	budgets    budget.Gate
+	archives   *archive.Archiver
+	// queue receives the provision jobs of new databases. Without one, the
+	// blueprint is applied before the create responds.
+	queue jobs.Repository
+	// renames records the renames of databases. Without one, databases
+	// cannot be renamed.
+	renames database.RenameRepository
 }
 
 // NewDatabaseHandler creates a new DatabaseHandler.
-func NewDatabaseHandler(repo database.Repository, teamRepo team.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, ns string) *DatabaseHandler {
+// A nil freezes gate disables change freeze checks. New databases are created
+// in the first of envs unless the request names another; with no envs,
+// databases have no environment. A nil dependents repository disables the
+// dependents check on delete. Updates and deletes take the database's
+// mutation lock unless locker is nil. Creations and infrastructure teardowns
+// are tracked as operations unless ops is nil; without ops, deletes wait for
+// the teardown. A teardown waits up to deleteWait for the provider to confirm
+// the resources are gone before leaving the rest to the reconciler. The spec
+// each database is provisioned with is recorded in specs unless it is nil.
+// A nil quotas gate disables organization quota checks. Databases whose
+// request and tier name no namespace are placed by placer, or created in ns
+// when it is nil. The images of new databases are pinned by images, and
+// their blueprints verified by signer, unless they are nil. New databases
+// get a friendly hostname in dnsZone unless it is empty. Creations and owner
+// team changes are checked against team connection budgets unless budgets is
+// nil. Databases of tiers that archive them are archived by archives before
+// their teardown; without archives, their teardown fails. Renames are
+// recorded in renames; without it, databases cannot be renamed.
+func NewDatabaseHandler(repo database.Repository, teamRepo team.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, ns string, freezes freeze.Gate, envs database.Environments, dependents database.DependentRepository, locker *database.Locker, ops *operation.Tracker, deleteWait time.Duration, specs database.SpecRepository, quotas organization.QuotaGate, placer placement.Placer, images imagepolicy.Pinner, signer *blueprint.Signer, dnsZone database.DNSZone, budgets budget.Gate, archives *archive.Archiver, queue jobs.Repository, renames database.RenameRepository) *DatabaseHandler {
 	return &DatabaseHandler{
-		repo:     repo,
-		teamRepo: teamRepo,
-		tierRepo: tierRepo,
-		bpRepo:   bpRepo,
-		registry: registry,
-		ns:       ns,
+		repo:       repo,
+		teamRepo:   teamRepo,
+		tierRepo:   tierRepo,
+		bpRepo:     bpRepo,
+		registry:   registry,
+		ns:         ns,
+		freezes:    freezes,
+		envs:       envs,
+		deps:       dependents,
+		locker:     locker,
+		ops:        ops,
+		deleteWait: deleteWait,
+		specs:      specs,
+		quotas:     quotas,
+		placer:     placer,
+		images:     images,
+		signer:     signer,
+		dnsZone:    dnsZone,
+		budgets:    budgets,
+		archives:   archives,
+		queue:      queue,
+		renames:    renames,
+	}
+}
+
+// placeDatabase returns the namespace of a new database of team on tierName
+// that neither its request nor its tier places: the placer's choice, with
+// the decision to record, or fallback when placer is nil. It writes a
+// response and returns false if the database cannot be placed.
+func placeDatabase(w http.ResponseWriter, r *http.Request, placer placement.Placer, fallback, team, tierName, requestID string) (string, *database.Placement, bool) {
+	if placer == nil {
+		return fallback, nil, true
+	}
+	placed, err := placer.Place(r.Context(), team, tierName)
+	if err == nil {
+		return placed.Namespace, placed, true
+	}
+	if errors.Is(err, placement.ErrNoCapacity) {
+		response.Err(w, http.StatusConflict, "NO_CAPACITY", "Cannot place database: "+err.Error(), requestID)
+		return "", nil, false
 	}
+	slog.Error("failed to place database", "error", err)
+	response.ServerErr(w, err, "Failed to create database", requestID)
+	return "", nil, false
+}
+
+// tierBlueprint returns the blueprint of tier t, which must have one, once
+// signer verifies it. It writes a 409 BLUEPRINT_SIGNATURE_INVALID response
+// and returns false if the blueprint is unsigned or does not match its
+// signature, and a 500 with failure if it cannot be looked up.
+func tierBlueprint(w http.ResponseWriter, r *http.Request, bpRepo blueprint.Repository, signer *blueprint.Signer, t *tier.Tier, failure, requestID string) (*blueprint.Blueprint, bool) {
+	bp, err := bpRepo.GetByID(r.Context(), *t.BlueprintID)
+	if err != nil {
+		slog.Error("failed to look up tier blueprint", "error", err, "blueprintID", t.BlueprintID)
+		response.ServerErr(w, err, failure, requestID)
+		return nil, false
+	}
+	if err := signer.Verify(bp); err != nil {
+		slog.Error("refusing to provision from blueprint", "error", err, "blueprint", bp.Name, "contentHash", blueprint.ContentHash(bp))
+		response.Err(w, http.StatusConflict, "BLUEPRINT_SIGNATURE_INVALID",
+			fmt.Sprintf("Refusing to provision from blueprint %s: %v", bp.Name, errors.Unwrap(err)), requestID)
+		return nil, false
+	}
+	return bp, true
+}
+
+// pinImages returns the images a new database from blueprint bp runs, as
+// pinner resolves them, or nil when pinner or bp is nil. It writes a response
+// and returns false if they cannot be resolved.
+func pinImages(w http.ResponseWriter, r *http.Request, pinner imagepolicy.Pinner, bp *blueprint.Blueprint, requestID string) ([]database.ImagePin, bool) {
+	if pinner == nil || bp == nil {
+		return nil, true
+	}
+	pins, err := pinner.Pin(r.Context(), blueprint.Images(bp.Manifests))
+	if err != nil {
+		slog.Error("failed to pin images", "error", err, "blueprint", bp.Name)
+		response.Err(w, http.StatusBadGateway, "IMAGE_RESOLUTION_FAILED",
+			fmt.Sprintf("Cannot resolve the images of blueprint %s: %v", bp.Name, err), requestID)
+		return nil, false
+	}
+	return pins, true
 }
 
 // isProductUser returns true if the identity is a product-role user.
@@ -112,7 +448,60 @@ func isProductUser(r *http.Request) (*uuid.UUID, bool) {
 	return nil, false
 }
 
-// Create handles POST /databases.
+// actorName returns the user name of the request's identity, recorded as
+// who created or last changed a resource; empty if unauthenticated.
+func actorName(r *http.Request) string {
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		return identity.UserName
+	}
+	return ""
+}
+
+// legalHoldDetail describes a request to place (true) or clear (false) a
+// legal hold in its audit event.
+func legalHoldDetail(hold bool) string {
+	if hold {
+		return "placed"
+	}
+	return "cleared"
+}
+
+// ownedDatabase loads the database named by the {id} URL parameter for a
+// /databases/{id}/... sub-resource, writing an error response and returning
+// false if the ID is invalid, the database is missing or, for product users,
+// it is owned by another team.
+func ownedDatabase(w http.ResponseWriter, r *http.Request, repo database.Repository, requestID string) (*database.Database, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return nil, false
+	}
+
+	db, err := repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return nil, false
+		}
+		slog.Error("failed to get database", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to get database", requestID)
+		return nil, false
+	}
+
+	// Product users: return 404 for non-owned databases (no info leakage)
+	if teamID, ok := isProductUser(r); ok && db.OwnerTeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return nil, false
+	}
+	middleware.SetAuditClassification(r.Context(), db.DataClassification)
+	return db, true
+}
+
+// Create handles POST /databases. The database is provisioned by a provision
+// job, which the response does not wait for; its progress is reported on
+// the create operation and GET /databases/{id}/jobs. When provisioning fails
+// for good, ?onFailure=rollback undoes the create instead of leaving the
+// database in status error; see provision.Fail.
 func (h *DatabaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
@@ -125,6 +514,9 @@ func (h *DatabaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	req.Name = strings.TrimSpace(req.Name)
 	req.OwnerTeam = strings.TrimSpace(req.OwnerTeam)
+	req.Environment = strings.TrimSpace(req.Environment)
+	req.DataClassification = strings.TrimSpace(req.DataClassification)
+	exposure := req.Exposure.toExposure()
 
 	// Ownership scoping for product users
 	identity := middleware.GetIdentity(r.Context())
@@ -138,16 +530,36 @@ func (h *DatabaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fieldErrors := validation.ValidateCreateRequest(validation.CreateDatabaseRequest{
-		Name:      req.Name,
-		OwnerTeam: req.OwnerTeam,
-		Tier:      req.Tier,
+		Name:         req.Name,
+		OwnerTeam:    req.OwnerTeam,
+		Tier:         req.Tier,
+		Environment:  req.Environment,
+		Environments: h.envs,
+
+		DataClassification: req.DataClassification,
+		Exposure:           exposure,
 	})
+	onFailure := r.URL.Query().Get("onFailure")
+	if onFailure == "" {
+		onFailure = provision.OnFailureError
+	}
+	if onFailure != provision.OnFailureError && onFailure != provision.OnFailureRollback {
+		fieldErrors = append(fieldErrors, validation.FieldError{Field: "onFailure", Message: "must be one of: error, rollback"})
+	}
 	if len(fieldErrors) > 0 {
 		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
 		return
 	}
+	rollback := onFailure == provision.OnFailureRollback
 
 	req.Purpose = strings.TrimSpace(req.Purpose)
+	if req.Environment == "" {
+		req.Environment = h.envs.Default()
+	}
+	if req.DataClassification == "" {
+		req.DataClassification = database.DefaultClassification
+	}
+	middleware.SetAuditClassification(r.Context(), req.DataClassification)
 
 	// Resolve ownerTeam name to team ID
 	ownerTeam, err := h.teamRepo.GetByName(r.Context(), req.OwnerTeam)
@@ -157,7 +569,15 @@ func (h *DatabaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to look up owner team", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create database", requestID)
+		response.ServerErr(w, err, "Failed to create database", requestID)
+		return
+	}
+
+	if frozen(w, r, h.freezes, ownerTeam.ID, "create", requestID) {
+		return
+	}
+	warnings, over := overQuota(w, r, h.quotas, ownerTeam.ID, requestID)
+	if over {
 		return
 	}
 
@@ -170,13 +590,46 @@ func (h *DatabaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to look up tier", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create database", requestID)
+		response.ServerErr(w, err, "Failed to create database", requestID)
+		return
+	}
+	if !classificationAllowed(w, resolvedTier, req.DataClassification, requestID) {
+		return
+	}
+	if overBudget(w, r, h.budgets, ownerTeam.ID, &resolvedTier.ID, uuid.Nil, "Failed to create database", requestID) {
 		return
 	}
 
+	// Namespace precedence: explicit request value, then the tier's namespace
+	// (template), then the placement engine, then the global default.
 	namespace := req.Namespace
+	var placed *database.Placement
 	if namespace == "" {
-		namespace = h.ns
+		namespace, err = resolvedTier.ResolveNamespace(tier.NamespaceData{Team: ownerTeam.Name, Database: req.Name, Environment: req.Environment}, "")
+		if err != nil {
+			slog.Error("failed to resolve tier namespace", "error", err, "tier", resolvedTier.Name)
+			response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Tier namespace does not produce a valid namespace for this database",
+				[]validation.FieldError{{Field: "tier", Message: err.Error()}}, requestID)
+			return
+		}
+	}
+	if namespace == "" {
+		var ok bool
+		if namespace, placed, ok = placeDatabase(w, r, h.placer, h.ns, ownerTeam.Name, resolvedTier.Name, requestID); !ok {
+			return
+		}
+	}
+
+	var bp *blueprint.Blueprint
+	if resolvedTier.BlueprintID != nil {
+		var ok bool
+		if bp, ok = tierBlueprint(w, r, h.bpRepo, h.signer, resolvedTier, "Failed to create database", requestID); !ok {
+			return
+		}
+	}
+	images, ok := pinImages(w, r, h.images, bp, requestID)
+	if !ok {
+		return
 	}
 
 	db := &database.Database{
@@ -187,6 +640,15 @@ func (h *DatabaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 		TierName:      resolvedTier.Name,
 		Purpose:       req.Purpose,
 		Namespace:     namespace,
+		Environment:   req.Environment,
+		Placement:     placed,
+		Images:        images,
+		Exposure:      exposure,
+		DNSName:       h.dnsZone.Hostname(req.Name),
+		QueryInsights: req.QueryInsights,
+		CreatedBy:     actorName(r),
+
+		DataClassification: req.DataClassification,
 	}
 
 	if err := h.repo.Create(r.Context(), db); err != nil {
@@ -195,39 +657,65 @@ func (h *DatabaseHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to create database record", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create database", requestID)
+		response.ServerErr(w, err, "Failed to create database", requestID)
 		return
 	}
 
-	// Provision infrastructure via provider abstraction
-	if resolvedTier.BlueprintID != nil && h.registry != nil {
-		bp, err := h.bpRepo.GetByID(r.Context(), *resolvedTier.BlueprintID)
-		if err != nil {
-			slog.Error("failed to look up tier blueprint", "error", err, "blueprintID", resolvedTier.BlueprintID)
-			h.markCreateError(r.Context(), db)
-			response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create database", requestID)
+	op := startOperation(w, r, h.ops, operation.TypeCreate, db, "Provisioning the database")
+
+	if bp != nil && h.queue != nil {
+		job := &jobs.Job{
+			Type:       jobs.TypeProvision,
+			DatabaseID: db.ID,
+			Params:     map[string]string{"onFailure": onFailure},
+			RequestID:  requestID,
+			CreatedBy:  actorName(r),
+		}
+		if op != nil {
+			job.OperationID = &op.ID
+		}
+		if err := h.queue.Enqueue(r.Context(), job); err != nil {
+			// Nothing will provision the record, so it is removed whatever
+			// onFailure says.
+			slog.Error("failed to enqueue provision job", "error", err, "database", db.Name)
+			provision.Fail(r.Context(), h.repo, db, nil, provider.ProviderDatabase{}, true)
+			h.ops.Fail(r.Context(), op, "INTERNAL_ERROR", "Failed to queue the provisioning of the database")
+			response.ServerErr(w, err, "Failed to create database", requestID)
 			return
 		}
+		response.SuccessWithWarnings(w, http.StatusCreated, toDatabaseResponse(db), warnings, requestID)
+		return
+	}
 
+	// Without a queue, the blueprint is applied inline.
+	if bp != nil && h.registry != nil {
 		p, ok := h.registry.Get(bp.Provider)
 		if !ok {
 			slog.Error("provider not registered", "provider", bp.Provider)
-			h.markCreateError(r.Context(), db)
+			provision.Fail(r.Context(), h.repo, db, nil, provider.ProviderDatabase{}, rollback)
+			h.ops.Fail(r.Context(), op, "PROVIDER_NOT_REGISTERED", fmt.Sprintf("Provider %q is not registered", bp.Provider))
 			response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create database", requestID)
 			return
 		}
 
-		pdb := toProviderDatabase(db, resolvedTier, bp)
+		pdb := db.ProviderDatabase(resolvedTier, bp)
 
 		if err := p.Apply(r.Context(), pdb, bp.Manifests); err != nil {
 			slog.Error("provider.Apply failed", "error", err, "database", db.Name, "provider", bp.Provider)
-			h.markCreateError(r.Context(), db)
-			response.Success(w, http.StatusCreated, toDatabaseResponse(db), requestID)
+			if provision.Fail(r.Context(), h.repo, db, p, pdb, rollback) {
+				h.ops.Fail(r.Context(), op, "APPLY_FAILED", fmt.Sprintf("Applying blueprint %s failed: %v; the database was rolled back", bp.Name, err))
+				response.Err(w, http.StatusBadGateway, "APPLY_FAILED", fmt.Sprintf("Applying blueprint %s failed; the database was rolled back", bp.Name), requestID)
+				return
+			}
+			h.ops.Fail(r.Context(), op, "APPLY_FAILED", fmt.Sprintf("Applying blueprint %s failed: %v", bp.Name, err))
+			response.SuccessWithWarnings(w, http.StatusCreated, toDatabaseResponse(db), warnings, requestID)
 			return
 		}
+		database.RecordSpec(r.Context(), h.specs, db, resolvedTier, bp)
+		h.ops.Progress(r.Context(), op, 50, "Waiting for the database to become ready")
 	}
 
-	response.Success(w, http.StatusCreated, toDatabaseResponse(db), requestID)
+	response.SuccessWithWarnings(w, http.StatusCreated, toDatabaseResponse(db), warnings, requestID)
 }
 
 // List handles GET /databases.
@@ -252,7 +740,7 @@ func (h *DatabaseHandler) List(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			slog.Error("failed to look up team for filter", "error", err)
-			response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list databases", requestID)
+			response.ServerErr(w, err, "Failed to list databases", requestID)
 			return
 		}
 		filter.OwnerTeamID = &t.ID
@@ -264,6 +752,9 @@ func (h *DatabaseHandler) List(w http.ResponseWriter, r *http.Request) {
 	if v := r.URL.Query().Get("name"); v != "" {
 		filter.Name = &v
 	}
+	if v := r.URL.Query().Get("environment"); v != "" {
+		filter.Environment = &v
+	}
 	if v := r.URL.Query().Get("page"); v != "" {
 		page, err := strconv.Atoi(v)
 		if err != nil || page < 1 {
@@ -284,19 +775,17 @@ func (h *DatabaseHandler) List(w http.ResponseWriter, r *http.Request) {
 	result, err := h.repo.List(r.Context(), filter)
 	if err != nil {
 		slog.Error("failed to list databases", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list databases", requestID)
+		response.ServerErr(w, err, "Failed to list databases", requestID)
 		return
 	}
 
-	items := make([]databaseResponse, 0, len(result.Databases))
-	for i := range result.Databases {
-		items = append(items, toDatabaseResponse(&result.Databases[i]))
-	}
-
-	response.SuccessList(w, http.StatusOK, items, result.Total, result.Page, result.Limit, requestID)
+	response.StreamList(w, http.StatusOK, len(result.Databases), func(i int) databaseResponse {
+		return toDatabaseResponse(&result.Databases[i])
+	}, result.Total, result.Page, result.Limit, requestID)
 }
 
-// GetByID handles GET /databases/{id}.
+// GetByID handles GET /databases/{id}. Platform users see where the final
+// backup of an archived database is stored, including once it is deleted.
 func (h *DatabaseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
@@ -307,29 +796,82 @@ func (h *DatabaseHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	want, err := parseExpand(r.URL.Query().Get("expand"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_PARAM", err.Error(), requestID)
+		return
+	}
+
 	db, err := h.repo.GetByID(r.Context(), id)
+	if errors.Is(err, database.ErrNotFound) {
+		// A database deleted once archived stays visible to platform users,
+		// so they can find its final backup.
+		if _, product := isProductUser(r); !product {
+			db, err = h.archives.Archived(r.Context(), id)
+		}
+	}
+	h.writeDatabase(w, r, db, err, want, requestID)
+}
+
+// GetByName handles GET /databases/by-name/{name}, like GetByID for the
+// non-deleted database with exactly this name.
+func (h *DatabaseHandler) GetByName(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	want, err := parseExpand(r.URL.Query().Get("expand"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_PARAM", err.Error(), requestID)
+		return
+	}
+
+	db, err := h.repo.GetByName(r.Context(), chi.URLParam(r, "name"))
+	h.writeDatabase(w, r, db, err, want, requestID)
+}
+
+// writeDatabase writes the database a lookup returned, with the relations
+// in want embedded, or the lookup's error.
+func (h *DatabaseHandler) writeDatabase(w http.ResponseWriter, r *http.Request, db *database.Database, err error, want map[string]bool, requestID string) {
 	if err != nil {
 		if errors.Is(err, database.ErrNotFound) {
 			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
 			return
 		}
-		slog.Error("failed to get database", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get database", requestID)
+		slog.Error("failed to get database", "error", err)
+		response.ServerErr(w, err, "Failed to get database", requestID)
 		return
 	}
 
 	// Product users: return 404 for non-owned databases (no info leakage)
-	if teamID, ok := isProductUser(r); ok {
-		if db.OwnerTeamID != *teamID {
-			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
-			return
-		}
+	teamID, product := isProductUser(r)
+	if product && db.OwnerTeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return
 	}
 
-	response.Success(w, http.StatusOK, toDatabaseResponse(db), requestID)
+	resp := toDatabaseResponse(db)
+	if !product {
+		resp.ArchiveURL = db.ArchiveURL
+	}
+	if len(want) == 0 {
+		response.Success(w, http.StatusOK, resp, requestID)
+		return
+	}
+
+	expanded, err := h.expand(r.Context(), db, want, product)
+	if err != nil {
+		slog.Error("failed to expand database relations", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to get database", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, databaseDetailResponse{databaseResponse: resp, Expanded: expanded}, requestID)
 }
 
-// Update handles PATCH /databases/{id}.
+// Update handles PATCH /databases/{id}. Platform users may also pause the
+// reconciliation of the database, e.g. while its cluster is adjusted by hand
+// during an incident, until a given time or for the default pause duration,
+// and place or clear a legal hold. Every attempt to change a legal hold is
+// named in the request's audit event, including refused ones.
 func (h *DatabaseHandler) Update(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
@@ -352,25 +894,65 @@ func (h *DatabaseHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	now := time.Now()
+	if fieldErrors := validation.ValidateUpdateRequest(validation.UpdateDatabaseRequest{
+		DataClassification:        req.DataClassification,
+		ReconciliationPaused:      req.ReconciliationPaused,
+		ReconciliationPausedUntil: req.ReconciliationPausedUntil,
+		Now:                       now,
+	}); len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	if req.LegalHold != nil {
+		middleware.SetAuditAction(r.Context(), "database.legal_hold", id.String(), legalHoldDetail(*req.LegalHold))
+	}
+
 	// Product users: check ownership and cannot change ownerTeam
-	if teamID, ok := isProductUser(r); ok {
-		if req.OwnerTeam != nil {
-			response.Err(w, http.StatusForbidden, "FORBIDDEN", "Product users cannot change ownerTeam", requestID)
+	teamID, product := isProductUser(r)
+	if product && req.OwnerTeam != nil {
+		response.Err(w, http.StatusForbidden, "FORBIDDEN", "Product users cannot change ownerTeam", requestID)
+		return
+	}
+	if product && req.ReconciliationPaused != nil {
+		response.Err(w, http.StatusForbidden, "FORBIDDEN", "Product users cannot pause reconciliation", requestID)
+		return
+	}
+	if product && req.LegalHold != nil {
+		response.Err(w, http.StatusForbidden, "FORBIDDEN", "Product users cannot place or clear legal holds", requestID)
+		return
+	}
+	// The database is needed to verify ownership, to refuse updates of a
+	// database being torn down, and to check a new classification against
+	// its tier and a new owner's connection budget.
+	existing, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
 			return
 		}
-		// Verify ownership
-		existing, err := h.repo.GetByID(r.Context(), id)
-		if err != nil {
-			if errors.Is(err, database.ErrNotFound) {
-				response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
-				return
-			}
-			slog.Error("failed to get database for ownership check", "error", err, "id", id)
-			response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update database", requestID)
+		slog.Error("failed to get database", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to update database", requestID)
+		return
+	}
+	if product && existing.OwnerTeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return
+	}
+	middleware.SetAuditClassification(r.Context(), existing.DataClassification)
+	if existing.Status == "deprovisioning" {
+		response.Err(w, http.StatusConflict, "DEPROVISIONING", "Database is being deprovisioned", requestID)
+		return
+	}
+	if req.DataClassification != nil && existing.TierID != nil {
+		t, err := h.tierRepo.GetByID(r.Context(), *existing.TierID)
+		if err != nil && !errors.Is(err, tier.ErrTierNotFound) {
+			slog.Error("failed to look up tier", "error", err, "tierID", existing.TierID)
+			response.ServerErr(w, err, "Failed to update database", requestID)
 			return
 		}
-		if existing.OwnerTeamID != *teamID {
-			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		if t != nil && !classificationAllowed(w, t, *req.DataClassification, requestID) {
 			return
 		}
 	}
@@ -385,12 +967,40 @@ func (h *DatabaseHandler) Update(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 			slog.Error("failed to look up owner team", "error", err)
-			response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update database", requestID)
+			response.ServerErr(w, err, "Failed to update database", requestID)
+			return
+		}
+		if existing.OwnerTeamID != t.ID &&
+			overBudget(w, r, h.budgets, t.ID, existing.TierID, existing.ID, "Failed to update database", requestID) {
 			return
 		}
 		updateFields.OwnerTeamID = &t.ID
 	}
 	updateFields.Purpose = req.Purpose
+	updateFields.DataClassification = req.DataClassification
+	updateFields.QueryInsights = req.QueryInsights
+	updateFields.LegalHold = req.LegalHold
+	updateFields.UpdatedBy = actorName(r)
+	if req.ReconciliationPaused != nil {
+		if *req.ReconciliationPaused {
+			pause := &database.ReconciliationPause{Until: now.Add(database.DefaultReconciliationPause).UTC()}
+			if req.ReconciliationPausedUntil != nil {
+				pause.Until, _ = time.Parse(time.RFC3339, *req.ReconciliationPausedUntil) // already validated
+			}
+			if identity := middleware.GetIdentity(r.Context()); identity != nil {
+				pause.By = identity.UserName
+			}
+			updateFields.ReconciliationPause = pause
+		} else {
+			updateFields.ResumeReconciliation = true
+		}
+	}
+
+	release, ok := lockDatabase(w, r, h.locker, id, "update", requestID)
+	if !ok {
+		return
+	}
+	defer release()
 
 	db, err := h.repo.Update(r.Context(), id, updateFields)
 	if err != nil {
@@ -399,14 +1009,33 @@ func (h *DatabaseHandler) Update(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to update database", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update database", requestID)
+		response.ServerErr(w, err, "Failed to update database", requestID)
 		return
 	}
+	middleware.SetAuditClassification(r.Context(), db.DataClassification)
+	if p := updateFields.ReconciliationPause; p != nil {
+		slog.Info("database reconciliation paused", "database", db.Name, "by", p.By, "until", p.Until)
+	} else if updateFields.ResumeReconciliation {
+		slog.Info("database reconciliation resumed", "database", db.Name)
+	}
+	if req.LegalHold != nil && *req.LegalHold {
+		slog.Info("database legal hold placed", "database", db.Name, "by", actorName(r))
+	} else if req.LegalHold != nil {
+		slog.Info("database legal hold cleared", "database", db.Name, "by", actorName(r))
+	}
 
 	response.Success(w, http.StatusOK, toDatabaseResponse(db), requestID)
 }
 
-// Delete handles DELETE /databases/{id}.
+// Delete handles DELETE /databases/{id}. A database with declared dependents
+// is only deleted with ?force=true, and the response then carries a Warning
+// header naming them. A provisioned database is marked deprovisioning and the
+// response is sent right away; the provider deletes the infrastructure
+// afterwards, as an operation linked from the Operation-Location header, and
+// the record is deleted once the provider confirms the resources are gone.
+// A database whose tier has the freeze destruction strategy is frozen
+// instead: its instances are stopped, the record is kept, and the frozen
+// database is returned.
 func (h *DatabaseHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
@@ -424,7 +1053,7 @@ func (h *DatabaseHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to get database for deletion", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to delete database", requestID)
+		response.ServerErr(w, err, "Failed to delete database", requestID)
 		return
 	}
 
@@ -435,59 +1064,315 @@ func (h *DatabaseHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	middleware.SetAuditClassification(r.Context(), db.DataClassification)
 
-	// Delete infrastructure via provider abstraction
-	if db.TierID != nil && h.registry != nil {
-		resolvedTier, err := h.tierRepo.GetByID(r.Context(), *db.TierID)
-		if err == nil && resolvedTier.BlueprintID != nil {
-			bp, err := h.bpRepo.GetByID(r.Context(), *resolvedTier.BlueprintID)
-			if err == nil {
-				p, ok := h.registry.Get(bp.Provider)
-				if ok {
-					pdb := toProviderDatabase(db, resolvedTier, bp)
-					if err := p.Delete(r.Context(), pdb); err != nil {
-						slog.Error("provider.Delete failed", "error", err, "database", db.Name, "provider", bp.Provider)
-					}
+	if db.Status == "deprovisioning" {
+		response.Err(w, http.StatusConflict, "DEPROVISIONING", "Database is already being deprovisioned", requestID)
+		return
+	}
+	if db.Status == "frozen" {
+		response.Err(w, http.StatusConflict, "FROZEN", "Database is already frozen; unfreeze it to use it again", requestID)
+		return
+	}
+
+	if db.LegalHold {
+		middleware.SetAuditAction(r.Context(), "database.delete", db.ID.String(), "refused: legal hold")
+		response.Err(w, http.StatusConflict, "LEGAL_HOLD",
+			fmt.Sprintf("Database %s is under legal hold; a platform user must clear it before it can be deleted", db.Name), requestID)
+		return
+	}
+
+	if frozen(w, r, h.freezes, db.OwnerTeamID, "delete", requestID) {
+		return
+	}
+
+	// The lock is held until the teardown ends, so no other mutation runs
+	// on a database being torn down. It is released when the request
+	// returns unless it was handed to the teardown.
+	release, ok := lockDatabase(w, r, h.locker, id, "delete", requestID)
+	if !ok {
+		return
+	}
+	handedOff := false
+	defer func() {
+		if !handedOff {
+			release()
+		}
+	}()
+
+	var warning string
+	if h.deps != nil {
+		dependents, err := h.deps.ListByDatabase(r.Context(), id)
+		if err != nil {
+			slog.Error("failed to list dependents for deletion", "error", err, "id", id)
+			response.ServerErr(w, err, "Failed to delete database", requestID)
+			return
+		}
+		if len(dependents) > 0 {
+			services := make([]string, len(dependents))
+			for i, d := range dependents {
+				services[i] = d.Service
+			}
+			if r.URL.Query().Get("force") != "true" {
+				items := make([]dependentResponse, len(dependents))
+				for i := range dependents {
+					items[i] = toDependentResponse(&dependents[i])
 				}
+				response.ErrWithDetails(w, http.StatusConflict, "HAS_DEPENDENTS",
+					fmt.Sprintf("Database has %d dependent(s): %s; remove them or retry with ?force=true", len(dependents), strings.Join(services, ", ")),
+					items, requestID)
+				return
+			}
+			slog.Warn("deleting database with dependents", "database", db.Name, "dependents", services)
+			warning = fmt.Sprintf(`299 daap "deleted database had dependents: %s"`, strings.Join(services, ", "))
+		}
+	}
+
+	// The tier decides whether the database is archived, frozen or
+	// deprovisioned; it is resolved once for all three.
+	var resolvedTier *tier.Tier
+	if db.TierID != nil && h.registry != nil {
+		resolvedTier, err = h.tierRepo.GetByID(r.Context(), *db.TierID)
+		if err != nil {
+			slog.Error("failed to resolve tier for deletion", "error", err, "database", db.Name)
+			response.ServerErr(w, err, "Failed to delete database", requestID)
+			return
+		}
+	}
+
+	if !h.archivable(w, r, db, resolvedTier, requestID) {
+		return
+	}
+
+	if freezesOnDelete(resolvedTier) {
+		h.freezeOnDelete(w, r, db, resolvedTier, warning, requestID)
+		return
+	}
+
+	if resolvedTier == nil {
+		// Nothing was provisioned through a provider: the record is all there
+		// is to delete.
+		if err := h.repo.SoftDelete(r.Context(), id); err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+				return
+			}
+			slog.Error("failed to soft-delete database", "error", err, "id", id)
+			response.ServerErr(w, err, "Failed to delete database", requestID)
+			return
+		}
+	} else {
+		// The request only marks the database as deprovisioning; the provider
+		// tears the infrastructure down in the background, tracked as an
+		// operation, and the record is deleted once the resources are gone.
+		su := database.StatusUpdate{Status: "deprovisioning", UpdatedBy: actorName(r)}
+		if _, err := h.repo.UpdateStatus(r.Context(), id, su); err != nil {
+			if errors.Is(err, database.ErrNotFound) {
+				response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+				return
 			}
+			slog.Error("failed to mark database as deprovisioning", "error", err, "id", id)
+			response.ServerErr(w, err, "Failed to delete database", requestID)
+			return
 		}
+		op := startOperation(w, r, h.ops, operation.TypeDelete, db, "Deprovisioning the database")
+		handedOff = true
+		h.ops.Go(r.Context(), op, func(ctx context.Context) (map[string]any, *operation.Error) {
+			defer release()
+			return h.deprovision(ctx, op, db, resolvedTier)
+		})
+	}
+
+	if warning != "" {
+		w.Header().Set("Warning", warning)
 	}
+	response.NoContent(w)
+}
 
-	if err := h.repo.SoftDelete(r.Context(), id); err != nil {
+// deprovision deletes the infrastructure of a deprovisioning database through
+// the provider of its tier resolvedTier, waiting up to the handler's delete
+// wait for it to disappear, and then deletes the record. A database whose
+// tier archives it is archived first. A backup still running, or resources
+// still present after the wait, leave op running; the reconciler completes
+// it once they are done.
+func (h *DatabaseHandler) deprovision(ctx context.Context, op *operation.Operation, db *database.Database, resolvedTier *tier.Tier) (map[string]any, *operation.Error) {
+	if resolvedTier.BlueprintID != nil {
+		bp, err := h.bpRepo.GetByID(ctx, *resolvedTier.BlueprintID)
+		if err != nil {
+			slog.Error("failed to resolve blueprint for deprovisioning", "error", err, "database", db.Name)
+			return nil, &operation.Error{Code: "INTERNAL_ERROR", Message: "Failed to resolve the blueprint"}
+		}
+		p, ok := h.registry.Get(bp.Provider)
+		if !ok {
+			return nil, &operation.Error{Code: "PROVIDER_NOT_REGISTERED", Message: fmt.Sprintf("Provider %q is not registered", bp.Provider)}
+		}
+		pdb := db.ProviderDatabase(resolvedTier, bp)
+		archived, err := h.archives.Archive(ctx, db, resolvedTier, p, pdb)
+		if err != nil {
+			slog.Error("failed to archive database", "error", err, "database", db.Name, "provider", bp.Provider)
+			return nil, &operation.Error{Code: "ARCHIVE_FAILED", Message: err.Error()}
+		}
+		if !archived {
+			h.ops.Progress(ctx, op, 25, "Waiting for the final backup to complete")
+			return nil, nil
+		}
+		state, err := provider.ConfirmDeletion(ctx, p, pdb, h.deleteWait)
+		if err != nil {
+			slog.Error("provider.Delete failed", "error", err, "database", db.Name, "provider", bp.Provider)
+			return nil, &operation.Error{Code: "DELETE_FAILED", Message: err.Error()}
+		}
+		if state != provider.DeletionGone {
+			h.ops.Progress(ctx, op, 50, "Waiting for the provider to finish deleting the resources")
+			return nil, nil
+		}
+	}
+	if err := h.repo.SoftDelete(ctx, db.ID); err != nil && !errors.Is(err, database.ErrNotFound) {
+		slog.Error("failed to soft-delete deprovisioned database", "error", err, "database", db.Name)
+		return nil, &operation.Error{Code: "INTERNAL_ERROR", Message: "Failed to delete the database record"}
+	}
+	result := map[string]any{"databaseId": db.ID.String()}
+	if db.ArchiveURL != nil {
+		result["archiveUrl"] = *db.ArchiveURL
+	}
+	return result, nil
+}
+
+// freezeOnDelete deletes db under the freeze destruction strategy of its
+// tier resolvedTier: it asks the tier's provider to stop db's instances,
+// keeping its data, marks it frozen and writes the frozen database as the
+// response to the deletion. The record stays active so the database can be
+// unfrozen.
+func (h *DatabaseHandler) freezeOnDelete(w http.ResponseWriter, r *http.Request, db *database.Database, resolvedTier *tier.Tier, warning, requestID string) {
+	p, pdb, ok := h.tierProvider(w, r, db, resolvedTier, "FREEZE_NOT_POSSIBLE", requestID)
+	if !ok {
+		return
+	}
+	freezer, ok := p.(provider.Freezer)
+	if !ok {
+		response.Err(w, http.StatusConflict, "FREEZE_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support freezing databases", pdb.Provider), requestID)
+		return
+	}
+
+	if err := freezer.Freeze(r.Context(), pdb); err != nil {
+		if errors.Is(err, provider.ErrNotSupported) {
+			response.Err(w, http.StatusConflict, "FREEZE_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support freezing databases", pdb.Provider), requestID)
+			return
+		}
+		slog.Error("provider.Freeze failed", "error", err, "database", db.Name, "provider", pdb.Provider)
+		response.ServerErr(w, err, "Failed to freeze database", requestID)
+		return
+	}
+
+	updated, err := h.repo.UpdateStatus(r.Context(), db.ID, database.StatusUpdate{Status: "frozen", UpdatedBy: actorName(r)})
+	if err != nil {
 		if errors.Is(err, database.ErrNotFound) {
 			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
 			return
 		}
-		slog.Error("failed to soft-delete database", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to delete database", requestID)
+		slog.Error("failed to mark database as frozen", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to freeze database", requestID)
 		return
 	}
+	middleware.SetAuditAction(r.Context(), "database.freeze", db.ID.String(), "")
+	slog.Info("database frozen", "database", db.Name)
 
-	response.NoContent(w)
+	if warning != "" {
+		w.Header().Set("Warning", warning)
+	}
+	response.Success(w, http.StatusOK, toDatabaseResponse(updated), requestID)
+}
+
+// archivable reports whether db can be deleted as its tier resolvedTier
+// requires, writing 409 ARCHIVE_LOCATION_REQUIRED when the tier archives its
+// databases and the owner team has nowhere to archive them to, and 409
+// ARCHIVE_NOT_POSSIBLE when the provider cannot archive them. Both are
+// checked before the database is marked deprovisioning, where it would
+// otherwise stay. A nil tier archives nothing.
+func (h *DatabaseHandler) archivable(w http.ResponseWriter, r *http.Request, db *database.Database, resolvedTier *tier.Tier, requestID string) bool {
+	if resolvedTier == nil || h.archives == nil || !archive.Required(resolvedTier) {
+		return true
+	}
+	_, err := h.archives.Location(r.Context(), db)
+	if errors.Is(err, archive.ErrNoLocation) {
+		response.Err(w, http.StatusConflict, "ARCHIVE_LOCATION_REQUIRED",
+			fmt.Sprintf("Tier %s archives databases before deleting them; set an archiveLocation on team %s first", resolvedTier.Name, db.OwnerTeamName), requestID)
+		return false
+	}
+	if err != nil {
+		slog.Error("failed to resolve archive location", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to delete database", requestID)
+		return false
+	}
+	if resolvedTier.BlueprintID == nil {
+		return true
+	}
+	p, pdb, ok := h.tierProvider(w, r, db, resolvedTier, "ARCHIVE_NOT_POSSIBLE", requestID)
+	if !ok {
+		return false
+	}
+	if !provider.Supports[provider.Archiver](p) {
+		response.Err(w, http.StatusConflict, "ARCHIVE_NOT_POSSIBLE",
+			fmt.Sprintf("Tier %s archives databases before deleting them, but provider %q does not support archiving", resolvedTier.Name, pdb.Provider), requestID)
+		return false
+	}
+	return true
+}
+
+// classificationAllowed reports whether t may host data of classification c,
+// writing 422 CLASSIFICATION_NOT_ALLOWED when it may not.
+func classificationAllowed(w http.ResponseWriter, t *tier.Tier, c, requestID string) bool {
+	if t.AllowsClassification(c) {
+		return true
+	}
+	response.ErrWithDetails(w, http.StatusUnprocessableEntity, "CLASSIFICATION_NOT_ALLOWED",
+		fmt.Sprintf("Tier %s does not host %s data", t.Name, c),
+		[]validation.FieldError{{Field: "dataClassification", Message: "tier " + t.Name + " hosts only: " + strings.Join(t.DataClassifications, ", ")}}, requestID)
+	return false
 }
 
 // markCreateError sets the database status to "error" when provisioning fails.
-func (h *DatabaseHandler) markCreateError(ctx context.Context, db *database.Database) {
+func markCreateError(ctx context.Context, repo database.Repository, db *database.Database) {
 	su := database.StatusUpdate{Status: "error"}
-	if _, err := h.repo.UpdateStatus(ctx, db.ID, su); err != nil {
+	if _, err := repo.UpdateStatus(ctx, db.ID, su); err != nil {
 		slog.Error("failed to mark database as error", "error", err, "database", db.Name)
 	}
 	db.Status = "error"
 }
 
-// toProviderDatabase builds a ProviderDatabase from domain models.
-func toProviderDatabase(db *database.Database, t *tier.Tier, bp *blueprint.Blueprint) provider.ProviderDatabase {
-	return provider.ProviderDatabase{
-		ID:          db.ID,
-		Name:        db.Name,
-		Namespace:   db.Namespace,
-		ClusterName: db.ClusterName,
-		PoolerName:  db.PoolerName,
-		OwnerTeam:   db.OwnerTeamName,
-		OwnerTeamID: db.OwnerTeamID,
-		Tier:        t.Name,
-		TierID:      t.ID,
-		Blueprint:   bp.Name,
-		Provider:    bp.Provider,
+// databaseProvider resolves the provider of a database through its tier and
+// blueprint. A database no registered provider manages gets a 409 with the
+// given code; lookup failures get a 500.
+func (h *DatabaseHandler) databaseProvider(w http.ResponseWriter, r *http.Request, db *database.Database, code, requestID string) (provider.Provider, provider.ProviderDatabase, bool) {
+	if db.TierID == nil || h.registry == nil {
+		response.Err(w, http.StatusConflict, code, "Database is not managed by a provider", requestID)
+		return nil, provider.ProviderDatabase{}, false
+	}
+	resolvedTier, err := h.tierRepo.GetByID(r.Context(), *db.TierID)
+	if err != nil {
+		slog.Error("failed to resolve tier", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to resolve the database's provider", requestID)
+		return nil, provider.ProviderDatabase{}, false
+	}
+	return h.tierProvider(w, r, db, resolvedTier, code, requestID)
+}
+
+// tierProvider resolves the provider of a database on the already resolved
+// tier resolvedTier, like databaseProvider.
+func (h *DatabaseHandler) tierProvider(w http.ResponseWriter, r *http.Request, db *database.Database, resolvedTier *tier.Tier, code, requestID string) (provider.Provider, provider.ProviderDatabase, bool) {
+	if resolvedTier.BlueprintID == nil {
+		response.Err(w, http.StatusConflict, code, "Database is not managed by a provider", requestID)
+		return nil, provider.ProviderDatabase{}, false
+	}
+	bp, err := h.bpRepo.GetByID(r.Context(), *resolvedTier.BlueprintID)
+	if err != nil {
+		slog.Error("failed to resolve blueprint", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to resolve the database's provider", requestID)
+		return nil, provider.ProviderDatabase{}, false
+	}
+	p, ok := h.registry.Get(bp.Provider)
+	if !ok {
+		response.Err(w, http.StatusConflict, code, fmt.Sprintf("Provider %q is not registered", bp.Provider), requestID)
+		return nil, provider.ProviderDatabase{}, false
 	}
+	return p, db.ProviderDatabase(resolvedTier, bp), true
 }
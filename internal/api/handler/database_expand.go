@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// Relations accepted by ?expand= on GET /databases/{id}.
+const (
+	expandTier      = "tier"
+	expandBlueprint = "blueprint"
+	expandOwnerTeam = "ownerTeam"
+)
+
+var validExpansions = map[string]bool{expandTier: true, expandBlueprint: true, expandOwnerTeam: true}
+
+// databaseDetailResponse is a database with its requested relations embedded.
+type databaseDetailResponse struct {
+	databaseResponse
+	Expanded *expandedRelations `json:"expanded,omitempty"`
+}
+
+// expandedRelations holds the related objects requested via ?expand=. A
+// requested relation that does not exist (e.g. a database without a tier) is
+// omitted. Tier and blueprint are redacted for product users.
+type expandedRelations struct {
+	Tier      any           `json:"tier,omitempty"`
+	Blueprint any           `json:"blueprint,omitempty"`
+	OwnerTeam *teamResponse `json:"ownerTeam,omitempty"`
+}
+
+// blueprintSummaryResponse is the redacted blueprint representation embedded
+// for product users; it omits the manifests.
+type blueprintSummaryResponse struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+}
+
+// parseExpand parses a comma-separated ?expand= value into a set of relations.
+func parseExpand(raw string) (map[string]bool, error) {
+	set := make(map[string]bool)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if !validExpansions[part] {
+			return nil, fmt.Errorf("expand must be a comma-separated list of: %s", strings.Join([]string{expandTier, expandBlueprint, expandOwnerTeam}, ", "))
+		}
+		set[part] = true
+	}
+	return set, nil
+}
+
+// expand loads the requested relations of db. Missing relations are left nil;
+// any other lookup error is returned.
+func (h *DatabaseHandler) expand(ctx context.Context, db *database.Database, want map[string]bool, redacted bool) (*expandedRelations, error) {
+	out := &expandedRelations{}
+
+	if want[expandOwnerTeam] {
+		t, err := h.teamRepo.GetByID(ctx, db.OwnerTeamID)
+		switch {
+		case err == nil:
+			resp := toTeamResponse(t)
+			out.OwnerTeam = &resp
+		case !errors.Is(err, team.ErrTeamNotFound):
+			return nil, fmt.Errorf("loading owner team: %w", err)
+		}
+	}
+
+	if !want[expandTier] && !want[expandBlueprint] {
+		return out, nil
+	}
+	if db.TierID == nil {
+		return out, nil
+	}
+
+	t, err := h.tierRepo.GetByID(ctx, *db.TierID)
+	if errors.Is(err, tier.ErrTierNotFound) {
+		return out, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("loading tier: %w", err)
+	}
+
+	if want[expandTier] {
+		if redacted {
+			out.Tier = toTierSummaryResponse(t)
+		} else {
+			out.Tier = toTierResponse(t)
+		}
+	}
+
+	if want[expandBlueprint] && t.BlueprintID != nil {
+		bp, err := h.bpRepo.GetByID(ctx, *t.BlueprintID)
+		switch {
+		case err == nil:
+			if redacted {
+				out.Blueprint = blueprintSummaryResponse{ID: bp.ID.String(), Name: bp.Name, Provider: bp.Provider}
+			} else {
+				out.Blueprint = toBlueprintResponse(bp)
+			}
+		case !errors.Is(err, blueprint.ErrBlueprintNotFound):
+			return nil, fmt.Errorf("loading blueprint: %w", err)
+		}
+	}
+
+	return out, nil
+}
@@ -0,0 +1,154 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/database"
+)
+
+type createDependentRequest struct {
+	Service     string `json:"service"`
+	Description string `json:"description"`
+}
+
+type dependentResponse struct {
+	ID          string `json:"id"`
+	Service     string `json:"service"`
+	Description string `json:"description"`
+	CreatedBy   string `json:"createdBy"`
+	CreatedAt   string `json:"createdAt"`
+}
+
+func toDependentResponse(d *database.Dependent) dependentResponse {
+	return dependentResponse{
+		ID:          d.ID.String(),
+		Service:     d.Service,
+		Description: d.Description,
+		CreatedBy:   d.CreatedBy,
+		CreatedAt:   d.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// DependentHandler handles the /databases/{id}/dependents endpoints.
+type DependentHandler struct {
+	repo       database.Repository
+	dependents database.DependentRepository
+}
+
+// NewDependentHandler creates a new DependentHandler.
+func NewDependentHandler(repo database.Repository, dependents database.DependentRepository) *DependentHandler {
+	return &DependentHandler{repo: repo, dependents: dependents}
+}
+
+// Create handles POST /databases/{id}/dependents, declaring that a service
+// depends on the database.
+func (h *DependentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req createDependentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	req.Service = strings.TrimSpace(req.Service)
+	req.Description = strings.TrimSpace(req.Description)
+
+	fieldErrors := validation.ValidateCreateDependentRequest(validation.CreateDependentRequest{
+		Service:     req.Service,
+		Description: req.Description,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	dep := &database.Dependent{DatabaseID: db.ID, Service: req.Service, Description: req.Description}
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		dep.CreatedBy = identity.UserName
+	}
+
+	if err := h.dependents.Create(r.Context(), dep); err != nil {
+		if errors.Is(err, database.ErrDuplicateDependent) {
+			response.Err(w, http.StatusConflict, "DUPLICATE_DEPENDENT", "Service is already declared as a dependent of this database", requestID)
+			return
+		}
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to create dependent", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to create dependent", requestID)
+		return
+	}
+
+	slog.Info("database dependent declared", "database", db.Name, "service", dep.Service)
+	response.Success(w, http.StatusCreated, toDependentResponse(dep), requestID)
+}
+
+// List handles GET /databases/{id}/dependents, ordered by service.
+func (h *DependentHandler) List(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	dependents, err := h.dependents.ListByDatabase(r.Context(), db.ID)
+	if err != nil {
+		slog.Error("failed to list dependents", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to list dependents", requestID)
+		return
+	}
+
+	items := make([]dependentResponse, len(dependents))
+	for i := range dependents {
+		items[i] = toDependentResponse(&dependents[i])
+	}
+	response.Success(w, http.StatusOK, items, requestID)
+}
+
+// Delete handles DELETE /databases/{id}/dependents/{dependentId}.
+func (h *DependentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	depID, err := uuid.Parse(chi.URLParam(r, "dependentId"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "dependentId must be a valid UUID", requestID)
+		return
+	}
+
+	if err := h.dependents.Delete(r.Context(), db.ID, depID); err != nil {
+		if errors.Is(err, database.ErrDependentNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Dependent not found", requestID)
+			return
+		}
+		slog.Error("failed to delete dependent", "error", err, "database", db.Name, "id", depID)
+		response.ServerErr(w, err, "Failed to delete dependent", requestID)
+		return
+	}
+
+	slog.Info("database dependent removed", "database", db.Name, "id", depID)
+	response.NoContent(w)
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+)
+
+type failoverRequest struct {
+	TargetInstance string `json:"targetInstance"`
+}
+
+// Failover handles POST /databases/{id}/failover. It asks the provider for a
+// supervised switchover to the given replica, or to a healthy replica of its
+// choosing, and marks the database as failing_over; the reconciler returns it
+// to ready once the new primary has taken over.
+func (h *DatabaseHandler) Failover(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req failoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	req.TargetInstance = strings.TrimSpace(req.TargetInstance)
+
+	db, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to get database for failover", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to fail over database", requestID)
+		return
+	}
+	middleware.SetAuditClassification(r.Context(), db.DataClassification)
+
+	if db.Status != "ready" {
+		response.Err(w, http.StatusConflict, "FAILOVER_NOT_POSSIBLE",
+			fmt.Sprintf("Database must be ready to fail over (status is %s)", db.Status), requestID)
+		return
+	}
+
+	release, ok := lockDatabase(w, r, h.locker, id, "failover", requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	p, pdb, ok := h.databaseProvider(w, r, db, "FAILOVER_NOT_POSSIBLE", requestID)
+	if !ok {
+		return
+	}
+	switcher, ok := p.(provider.PrimarySwitcher)
+	if !ok {
+		response.Err(w, http.StatusConflict, "FAILOVER_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support failover", pdb.Provider), requestID)
+		return
+	}
+
+	target, err := switcher.Switchover(r.Context(), pdb, req.TargetInstance)
+	if err != nil {
+		switch {
+		case errors.Is(err, provider.ErrNotSupported):
+			response.Err(w, http.StatusConflict, "FAILOVER_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support failover", pdb.Provider), requestID)
+		case errors.Is(err, provider.ErrInvalidTarget):
+			response.Err(w, http.StatusConflict, "FAILOVER_NOT_POSSIBLE", err.Error(), requestID)
+		default:
+			slog.Error("provider.Switchover failed", "error", err, "database", db.Name, "provider", pdb.Provider)
+			response.ServerErr(w, err, "Failed to fail over database", requestID)
+		}
+		return
+	}
+
+	updated, err := h.repo.UpdateStatus(r.Context(), id, database.StatusUpdate{Status: "failing_over", UpdatedBy: actorName(r)})
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to mark database as failing over", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to fail over database", requestID)
+		return
+	}
+
+	var from, actor string
+	if db.Instances != nil {
+		from = db.Instances.Primary
+	}
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		actor = identity.UserName
+	}
+	slog.Info("database failover triggered", "database", db.Name, "from", from, "to", target, "actor", actor)
+
+	startOperation(w, r, h.ops, operation.TypeFailover, updated, "Switching over to "+target)
+	response.Success(w, http.StatusAccepted, toDatabaseResponse(updated), requestID)
+}
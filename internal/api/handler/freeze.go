@@ -0,0 +1,203 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/team"
+)
+
+type createFreezeRequest struct {
+	Team     string `json:"team"`
+	Reason   string `json:"reason"`
+	StartsAt string `json:"startsAt"`
+	EndsAt   string `json:"endsAt"`
+}
+
+type freezeResponse struct {
+	ID        string  `json:"id"`
+	Team      *string `json:"team"`
+	Reason    string  `json:"reason"`
+	StartsAt  string  `json:"startsAt"`
+	EndsAt    string  `json:"endsAt"`
+	Active    bool    `json:"active"`
+	CreatedBy string  `json:"createdBy"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+func toFreezeResponse(w *freeze.Window, now time.Time) freezeResponse {
+	return freezeResponse{
+		ID:        w.ID.String(),
+		Team:      w.TeamName,
+		Reason:    w.Reason,
+		StartsAt:  w.StartsAt.UTC().Format(time.RFC3339),
+		EndsAt:    w.EndsAt.UTC().Format(time.RFC3339),
+		Active:    w.Covers(now),
+		CreatedBy: w.CreatedBy,
+		CreatedAt: w.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// FreezeHandler handles the change freeze endpoints.
+type FreezeHandler struct {
+	repo     freeze.Repository
+	teamRepo team.Repository
+}
+
+// NewFreezeHandler creates a new FreezeHandler.
+func NewFreezeHandler(repo freeze.Repository, teamRepo team.Repository) *FreezeHandler {
+	return &FreezeHandler{repo: repo, teamRepo: teamRepo}
+}
+
+// Create handles POST /freezes.
+func (h *FreezeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req createFreezeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+
+	now := time.Now()
+	fieldErrors := validation.ValidateCreateFreezeRequest(validation.CreateFreezeRequest{
+		Reason:   req.Reason,
+		StartsAt: req.StartsAt,
+		EndsAt:   req.EndsAt,
+		Now:      now,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	window := &freeze.Window{
+		Reason:   strings.TrimSpace(req.Reason),
+		StartsAt: now.UTC().Truncate(time.Second),
+	}
+	if req.StartsAt != "" {
+		window.StartsAt, _ = time.Parse(time.RFC3339, req.StartsAt) // already validated
+	}
+	window.EndsAt, _ = time.Parse(time.RFC3339, req.EndsAt) // already validated
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		window.CreatedBy = identity.UserName
+	}
+
+	if name := strings.TrimSpace(req.Team); name != "" {
+		t, err := h.teamRepo.GetByName(r.Context(), name)
+		if err != nil {
+			if errors.Is(err, team.ErrTeamNotFound) {
+				response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+				return
+			}
+			slog.Error("failed to look up team", "error", err)
+			response.ServerErr(w, err, "Failed to create freeze", requestID)
+			return
+		}
+		window.TeamID = &t.ID
+	}
+
+	if err := h.repo.Create(r.Context(), window); err != nil {
+		slog.Error("failed to create freeze", "error", err)
+		response.ServerErr(w, err, "Failed to create freeze", requestID)
+		return
+	}
+
+	slog.Info("change freeze created", "id", window.ID, "team", req.Team, "startsAt", window.StartsAt, "endsAt", window.EndsAt)
+	response.Success(w, http.StatusCreated, toFreezeResponse(window, now), requestID)
+}
+
+// List handles GET /freezes. ?active=true lists only the windows in effect
+// now; ended windows are listed until they are deleted.
+func (h *FreezeHandler) List(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	now := time.Now()
+	var windows []freeze.Window
+	var err error
+	if r.URL.Query().Get("active") == "true" {
+		windows, err = h.repo.ListActive(r.Context(), now)
+	} else {
+		windows, err = h.repo.List(r.Context())
+	}
+	if err != nil {
+		slog.Error("failed to list freezes", "error", err)
+		response.ServerErr(w, err, "Failed to list freezes", requestID)
+		return
+	}
+
+	items := make([]freezeResponse, len(windows))
+	for i := range windows {
+		items[i] = toFreezeResponse(&windows[i], now)
+	}
+	response.Success(w, http.StatusOK, items, requestID)
+}
+
+// Delete handles DELETE /freezes/{id}. Deleting an active window lifts the
+// freeze immediately.
+func (h *FreezeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, freeze.ErrWindowNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Freeze not found", requestID)
+			return
+		}
+		slog.Error("failed to delete freeze", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to delete freeze", requestID)
+		return
+	}
+
+	slog.Info("change freeze deleted", "id", id)
+	response.NoContent(w)
+}
+
+// frozen writes a CHANGE_FREEZE error and returns true when a freeze window
+// blocks changes to the team's databases and the caller may not override
+// it. action names the rejected operation in the error message.
+func frozen(w http.ResponseWriter, r *http.Request, gate freeze.Gate, teamID uuid.UUID, action, requestID string) bool {
+	if gate == nil {
+		return false
+	}
+	if identity := middleware.GetIdentity(r.Context()); identity != nil && identity.FreezeOverride {
+		return false
+	}
+
+	window, err := gate.Frozen(r.Context(), teamID)
+	if err != nil {
+		slog.Error("failed to check change freezes", "error", err)
+		response.ServerErr(w, err, fmt.Sprintf("Failed to %s database", action), requestID)
+		return true
+	}
+	if window == nil {
+		return false
+	}
+
+	scope := "all teams"
+	if window.TeamName != nil {
+		scope = "team " + *window.TeamName
+	}
+	response.Err(w, http.StatusConflict, "CHANGE_FREEZE",
+		fmt.Sprintf("Cannot %s database: changes are frozen for %s until %s (%s)",
+			action, scope, window.EndsAt.UTC().Format(time.RFC3339), window.Reason), requestID)
+	return true
+}
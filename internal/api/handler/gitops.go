@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/gitops"
+	"github.com/daap14/daap/internal/team"
+)
+
+// GitOpsExporter writes a bundle of rendered manifests.
+type GitOpsExporter interface {
+	Export(ctx context.Context, w io.Writer, filter gitops.Filter) (gitops.Summary, error)
+}
+
+// GitOpsHandler handles the GET /admin/gitops-export endpoint.
+type GitOpsHandler struct {
+	exporter GitOpsExporter
+	teamRepo team.Repository
+}
+
+// NewGitOpsHandler creates a new GitOpsHandler.
+func NewGitOpsHandler(exporter GitOpsExporter, teamRepo team.Repository) *GitOpsHandler {
+	return &GitOpsHandler{exporter: exporter, teamRepo: teamRepo}
+}
+
+// ServeHTTP streams a gzip-compressed tarball of rendered manifests, one file
+// per database under a directory per team. The optional ?team= query
+// parameter restricts the bundle to one team. The bundle is built in memory
+// first so a failure still returns a JSON error envelope.
+func (h *GitOpsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var filter gitops.Filter
+	filename := "daap-gitops.tar.gz"
+	if name := r.URL.Query().Get("team"); name != "" {
+		t, err := h.teamRepo.GetByName(r.Context(), name)
+		if err != nil {
+			if errors.Is(err, team.ErrTeamNotFound) {
+				response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+				return
+			}
+			slog.Error("failed to look up team", "error", err)
+			response.ServerErr(w, err, "Failed to export manifests", requestID)
+			return
+		}
+		filter.OwnerTeamID = &t.ID
+		filename = fmt.Sprintf("daap-gitops-%s.tar.gz", t.Name)
+	}
+
+	var buf bytes.Buffer
+	summary, err := h.exporter.Export(r.Context(), &buf, filter)
+	if err != nil {
+		slog.Error("failed to export manifests", "error", err)
+		response.ServerErr(w, err, "Failed to export manifests", requestID)
+		return
+	}
+	slog.Info("gitops export", "databases", summary.Databases, "skipped", len(summary.Skipped), "bytes", buf.Len())
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
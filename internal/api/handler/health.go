@@ -2,11 +2,14 @@ package handler
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/daap14/daap/internal/api/middleware"
 	"github.com/daap14/daap/internal/api/response"
 	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/preflight"
 )
 
 // DBPinger checks platform database connectivity.
@@ -14,19 +17,33 @@ type DBPinger interface {
 	Ping(ctx context.Context) error
 }
 
+// ReconcilerStatus reports how recently the reconciler completed a pass.
+type ReconcilerStatus interface {
+	LastSuccess() time.Time
+	Stale(now time.Time) bool
+}
+
 // HealthHandler handles the GET /health endpoint.
 type HealthHandler struct {
-	k8sChecker k8s.HealthChecker
-	dbPinger   DBPinger
-	version    string
+	k8sChecker     k8s.HealthChecker
+	dbPinger       DBPinger
+	version        string
+	reconciler     ReconcilerStatus
+	schema         preflight.SchemaVersioner
+	expectedSchema uint
 }
 
-// NewHealthHandler creates a new HealthHandler.
-func NewHealthHandler(checker k8s.HealthChecker, dbPinger DBPinger, version string) *HealthHandler {
+// NewHealthHandler creates a new HealthHandler. The reconciler and
+// migrations components are skipped when reconciler or schema is nil;
+// expectedSchema is the latest migration shipped with this build.
+func NewHealthHandler(checker k8s.HealthChecker, dbPinger DBPinger, version string, reconciler ReconcilerStatus, schema preflight.SchemaVersioner, expectedSchema uint) *HealthHandler {
 	return &HealthHandler{
-		k8sChecker: checker,
-		dbPinger:   dbPinger,
-		version:    version,
+		k8sChecker:     checker,
+		dbPinger:       dbPinger,
+		version:        version,
+		reconciler:     reconciler,
+		schema:         schema,
+		expectedSchema: expectedSchema,
 	}
 }
 
@@ -39,37 +56,62 @@ type databaseStatus struct {
 	Connected bool `json:"connected"`
 }
 
+// componentCheck is the outcome of checking one component, with the same
+// statuses as preflight checks. Only a failing component degrades DAAP.
+type componentCheck struct {
+	Name          string           `json:"name"`
+	Status        preflight.Status `json:"status"`
+	DurationMs    float64          `json:"durationMs"`
+	Message       string           `json:"message,omitempty"`
+	LastSuccessAt *string          `json:"lastSuccessAt,omitempty"` // reconciler only
+}
+
 type healthData struct {
 	Status     string           `json:"status"`
 	Version    string           `json:"version"`
 	Kubernetes kubernetesStatus `json:"kubernetes"`
 	Database   databaseStatus   `json:"database"`
+	Checks     []componentCheck `json:"checks"`
 }
 
-// ServeHTTP handles the health check request.
+// ServeHTTP handles the health check request. Besides the connectivity
+// summary, it reports a check per component (platform database, Kubernetes
+// API, reconciler, migrations) with how long it took, so a probe or a human
+// can tell which one degrades DAAP.
 func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
-	connectivity := h.k8sChecker.CheckConnectivity(r.Context())
-
 	status := "healthy"
 	var k8sVersion *string
 
-	if connectivity.Connected {
-		k8sVersion = &connectivity.Version
-	} else {
-		status = "degraded"
-	}
-
+	start := time.Now()
 	dbConnected := true
+	dbCheck := componentCheck{Name: "database", Status: preflight.StatusPass}
 	if h.dbPinger != nil {
 		if err := h.dbPinger.Ping(r.Context()); err != nil {
 			dbConnected = false
-			status = "degraded"
+			dbCheck.Status, dbCheck.Message = preflight.StatusFail, err.Error()
 		}
 	} else {
 		dbConnected = false
-		status = "degraded"
+		dbCheck.Status, dbCheck.Message = preflight.StatusFail, "platform database is not configured"
+	}
+	dbCheck.DurationMs = msSince(start)
+
+	start = time.Now()
+	connectivity := h.k8sChecker.CheckConnectivity(r.Context())
+	k8sCheck := componentCheck{Name: "kubernetes", Status: preflight.StatusPass, DurationMs: msSince(start)}
+	if connectivity.Connected {
+		k8sVersion = &connectivity.Version
+	} else {
+		k8sCheck.Status, k8sCheck.Message = preflight.StatusFail, "Kubernetes API is unreachable"
+	}
+
+	checks := []componentCheck{dbCheck, k8sCheck, h.checkReconciler(), h.checkMigrations(r.Context())}
+	for _, c := range checks {
+		if c.Status == preflight.StatusFail {
+			status = "degraded"
+		}
 	}
 
 	data := healthData{
@@ -82,7 +124,51 @@ func (h *HealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Database: databaseStatus{
 			Connected: dbConnected,
 		},
+		Checks: checks,
 	}
 
 	response.Success(w, http.StatusOK, data, requestID)
 }
+
+// checkReconciler fails when the reconciler has not completed a pass for
+// three intervals.
+func (h *HealthHandler) checkReconciler() componentCheck {
+	start := time.Now()
+	c := componentCheck{Name: "reconciler", Status: preflight.StatusSkip, Message: "reconciler is not running"}
+	if h.reconciler == nil {
+		return c
+	}
+
+	last := h.reconciler.LastSuccess()
+	c.Status, c.Message = preflight.StatusPass, ""
+	if !last.IsZero() {
+		at := last.UTC().Format(time.RFC3339)
+		c.LastSuccessAt = &at
+	}
+	if h.reconciler.Stale(start) {
+		c.Status = preflight.StatusFail
+		if last.IsZero() {
+			c.Message = "no reconciliation pass has succeeded yet"
+		} else {
+			c.Message = fmt.Sprintf("no reconciliation pass has succeeded for %s", start.Sub(last).Round(time.Second))
+		}
+	}
+	c.DurationMs = msSince(start)
+	return c
+}
+
+// checkMigrations compares the platform database schema with the latest
+// migration of this build, like `daap preflight`.
+func (h *HealthHandler) checkMigrations(ctx context.Context) componentCheck {
+	if h.schema == nil {
+		return componentCheck{Name: "migrations", Status: preflight.StatusSkip, Message: "platform database is not configured"}
+	}
+	start := time.Now()
+	check := preflight.CheckMigrations(ctx, h.schema, h.expectedSchema)
+	return componentCheck{Name: check.Name, Status: check.Status, Message: check.Message, DurationMs: msSince(start)}
+}
+
+// msSince returns the milliseconds elapsed since start.
+func msSince(start time.Time) float64 {
+	return float64(time.Since(start).Microseconds()) / 1000
+}
@@ -0,0 +1,75 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+)
+
+// InsightHandler handles the GET /databases/{id}/insights/queries endpoint.
+type InsightHandler struct {
+	repo      database.Repository
+	snapshots database.QuerySnapshotRepository
+}
+
+// NewInsightHandler creates a new InsightHandler.
+func NewInsightHandler(repo database.Repository, snapshots database.QuerySnapshotRepository) *InsightHandler {
+	return &InsightHandler{repo: repo, snapshots: snapshots}
+}
+
+type queryInsightsResponse struct {
+	Enabled     bool                `json:"enabled"`
+	CollectedAt *string             `json:"collectedAt"`
+	Queries     []queryStatResponse `json:"queries"`
+}
+
+type queryStatResponse struct {
+	// QueryID is a string because pg_stat_statements query IDs are 64-bit
+	// and do not survive a JSON number.
+	QueryID     string  `json:"queryId"`
+	Query       string  `json:"query"`
+	Calls       int64   `json:"calls"`
+	TotalTimeMs float64 `json:"totalTimeMs"`
+	MeanTimeMs  float64 `json:"meanTimeMs"`
+	Rows        int64   `json:"rows"`
+}
+
+// ServeHTTP returns the top statements of a database from its latest query
+// snapshot, with their literals redacted. A database with no snapshot yet
+// returns no queries and a null collectedAt.
+func (h *InsightHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	resp := queryInsightsResponse{Enabled: db.QueryInsights, Queries: []queryStatResponse{}}
+	snapshot, err := h.snapshots.Latest(r.Context(), db.ID)
+	if err != nil && !errors.Is(err, database.ErrQuerySnapshotNotFound) {
+		slog.Error("failed to get query snapshot", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to get query insights", requestID)
+		return
+	}
+	if snapshot != nil {
+		collectedAt := snapshot.CollectedAt.UTC().Format("2006-01-02T15:04:05Z")
+		resp.CollectedAt = &collectedAt
+		for _, s := range snapshot.Statements {
+			resp.Queries = append(resp.Queries, queryStatResponse{
+				QueryID:     strconv.FormatInt(s.QueryID, 10),
+				Query:       s.Query,
+				Calls:       s.Calls,
+				TotalTimeMs: s.TotalTimeMs,
+				MeanTimeMs:  s.MeanTimeMs,
+				Rows:        s.Rows,
+			})
+		}
+	}
+	response.Success(w, http.StatusOK, resp, requestID)
+}
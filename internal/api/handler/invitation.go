@@ -0,0 +1,294 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/mail"
+	"github.com/daap14/daap/internal/team"
+)
+
+type inviteUserRequest struct {
+	Name           string `json:"name"`
+	TeamID         string `json:"teamId"`
+	Email          string `json:"email"`
+	FreezeOverride bool   `json:"freezeOverride"`
+}
+
+type invitationResponse struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	TeamID         string `json:"teamId"`
+	TeamName       string `json:"teamName"`
+	Email          string `json:"email"`
+	FreezeOverride bool   `json:"freezeOverride"`
+	ExpiresAt      string `json:"expiresAt"`
+	CreatedBy      string `json:"createdBy"`
+	CreatedAt      string `json:"createdAt"`
+}
+
+type invitationDetailsResponse struct {
+	Name      string `json:"name"`
+	TeamName  string `json:"teamName"`
+	Email     string `json:"email"`
+	ExpiresAt string `json:"expiresAt"`
+	AcceptURL string `json:"acceptUrl"`
+}
+
+// InvitationHandler handles the user invitation endpoints.
+type InvitationHandler struct {
+	authService *auth.Service
+	invitations auth.InvitationRepository
+	teamRepo    team.Repository
+	mailer      mail.Sender
+	publicURL   string
+	ttl         time.Duration
+}
+
+// NewInvitationHandler creates a new InvitationHandler. Activation links are
+// built from publicURL, the externally reachable base URL of the API, and
+// expire after ttl. A nil mailer logs invitation emails instead of sending them.
+func NewInvitationHandler(authService *auth.Service, invitations auth.InvitationRepository, teamRepo team.Repository, mailer mail.Sender, publicURL string, ttl time.Duration) *InvitationHandler {
+	if mailer == nil {
+		mailer = mail.LogSender{}
+	}
+	return &InvitationHandler{
+		authService: authService,
+		invitations: invitations,
+		teamRepo:    teamRepo,
+		mailer:      mailer,
+		publicURL:   strings.TrimRight(publicURL, "/"),
+		ttl:         ttl,
+	}
+}
+
+// Invite handles POST /users/invite. It records a pending user and emails a
+// one-time activation link; the API key is only generated when the invitee
+// accepts, so it is never seen by the superuser.
+func (h *InvitationHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req inviteUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Email = strings.TrimSpace(req.Email)
+
+	fieldErrors := validation.ValidateInviteUserRequest(validation.InviteUserRequest{
+		Name:   req.Name,
+		TeamID: req.TeamID,
+		Email:  req.Email,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	teamID, _ := uuid.Parse(req.TeamID) // already validated
+
+	t, err := h.teamRepo.GetByID(r.Context(), teamID)
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+			return
+		}
+		slog.Error("failed to get team", "error", err)
+		response.ServerErr(w, err, "Failed to invite user", requestID)
+		return
+	}
+
+	token, tokenHash, err := auth.NewInvitationToken()
+	if err != nil {
+		slog.Error("failed to generate invitation token", "error", err)
+		response.ServerErr(w, err, "Failed to invite user", requestID)
+		return
+	}
+
+	inv := &auth.Invitation{
+		Name:           req.Name,
+		TeamID:         teamID,
+		Email:          req.Email,
+		FreezeOverride: req.FreezeOverride,
+		TokenHash:      tokenHash,
+		ExpiresAt:      time.Now().Add(h.ttl).UTC().Truncate(time.Second),
+	}
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		inv.CreatedBy = identity.UserName
+	}
+
+	if err := h.invitations.Create(r.Context(), inv); err != nil {
+		slog.Error("failed to create invitation", "error", err)
+		response.ServerErr(w, err, "Failed to invite user", requestID)
+		return
+	}
+
+	if err := h.mailer.Send(r.Context(), h.invitationEmail(inv, t, token)); err != nil {
+		// The token only exists in the email, so the invitation is unusable
+		// and simply expires; the superuser can invite again.
+		slog.Error("failed to send invitation email", "error", err, "invitation", inv.ID)
+		response.Err(w, http.StatusBadGateway, "EMAIL_FAILED", "The invitation email could not be sent", requestID)
+		return
+	}
+
+	slog.Info("user invited", "invitation", inv.ID, "name", inv.Name, "team", t.Name)
+	response.Success(w, http.StatusCreated, invitationResponse{
+		ID:             inv.ID.String(),
+		Name:           inv.Name,
+		TeamID:         teamID.String(),
+		TeamName:       t.Name,
+		Email:          inv.Email,
+		FreezeOverride: inv.FreezeOverride,
+		ExpiresAt:      inv.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"),
+		CreatedBy:      inv.CreatedBy,
+		CreatedAt:      inv.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}, requestID)
+}
+
+// Get handles GET /invitations/{token}, the activation link. It only
+// describes the invitation: mail scanners prefetch links, so following it
+// must not consume the token.
+func (h *InvitationHandler) Get(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	inv, ok := h.pendingInvitation(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	teamName := ""
+	if t, err := h.teamRepo.GetByID(r.Context(), inv.TeamID); err == nil {
+		teamName = t.Name
+	}
+
+	response.Success(w, http.StatusOK, invitationDetailsResponse{
+		Name:      inv.Name,
+		TeamName:  teamName,
+		Email:     inv.Email,
+		ExpiresAt: inv.ExpiresAt.UTC().Format("2006-01-02T15:04:05Z"),
+		AcceptURL: h.activationURL(chi.URLParam(r, "token")) + "/accept",
+	}, requestID)
+}
+
+// Accept handles POST /invitations/{token}/accept. It creates the user and
+// returns their API key, which is shown only this once.
+func (h *InvitationHandler) Accept(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	inv, ok := h.pendingInvitation(w, r, requestID)
+	if !ok {
+		return
+	}
+
+	t, err := h.teamRepo.GetByID(r.Context(), inv.TeamID)
+	if err != nil {
+		slog.Error("failed to get team", "error", err, "invitation", inv.ID)
+		response.ServerErr(w, err, "Failed to accept invitation", requestID)
+		return
+	}
+
+	rawKey, prefix, hash, err := h.authService.GenerateKey()
+	if err != nil {
+		slog.Error("failed to generate API key", "error", err)
+		response.ServerErr(w, err, "Failed to accept invitation", requestID)
+		return
+	}
+
+	u := &auth.User{
+		Name:           inv.Name,
+		TeamID:         &inv.TeamID,
+		FreezeOverride: inv.FreezeOverride,
+		ApiKeyPrefix:   prefix,
+		ApiKeyHash:     hash,
+	}
+	if err := h.invitations.Accept(r.Context(), inv.ID, u); err != nil {
+		if writeInvitationErr(w, err, requestID) {
+			return
+		}
+		slog.Error("failed to accept invitation", "error", err, "invitation", inv.ID)
+		response.ServerErr(w, err, "Failed to accept invitation", requestID)
+		return
+	}
+
+	slog.Info("invitation accepted", "invitation", inv.ID, "user", u.ID, "team", t.Name)
+	teamID := t.ID.String()
+	response.Success(w, http.StatusCreated, userWithKeyResponse{
+		ID:             u.ID.String(),
+		Name:           u.Name,
+		TeamID:         &teamID,
+		TeamName:       &t.Name,
+		Role:           &t.Role,
+		ApiKey:         rawKey,
+		FreezeOverride: u.FreezeOverride,
+		Scopes:         responseScopes(u.Scopes),
+		CreatedAt:      u.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}, requestID)
+}
+
+// pendingInvitation resolves the {token} URL parameter to an invitation that
+// can still be accepted, writing the error response when there is none.
+func (h *InvitationHandler) pendingInvitation(w http.ResponseWriter, r *http.Request, requestID string) (*auth.Invitation, bool) {
+	inv, err := h.invitations.GetByTokenHash(r.Context(), auth.HashInvitationToken(chi.URLParam(r, "token")))
+	if err != nil {
+		if !writeInvitationErr(w, err, requestID) {
+			slog.Error("failed to get invitation", "error", err)
+			response.ServerErr(w, err, "Failed to get invitation", requestID)
+		}
+		return nil, false
+	}
+	switch {
+	case inv.AcceptedAt != nil:
+		writeInvitationErr(w, auth.ErrInvitationUsed, requestID)
+		return nil, false
+	case inv.Expired(time.Now()):
+		writeInvitationErr(w, auth.ErrInvitationExpired, requestID)
+		return nil, false
+	}
+	return inv, true
+}
+
+// writeInvitationErr writes the response for the invitation sentinel errors
+// and reports whether err was one of them.
+func writeInvitationErr(w http.ResponseWriter, err error, requestID string) bool {
+	switch {
+	case errors.Is(err, auth.ErrInvitationNotFound):
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Invitation not found", requestID)
+	case errors.Is(err, auth.ErrInvitationUsed):
+		response.Err(w, http.StatusGone, "INVITATION_USED", "Invitation has already been used; ask a superuser for a new one", requestID)
+	case errors.Is(err, auth.ErrInvitationExpired):
+		response.Err(w, http.StatusGone, "INVITATION_EXPIRED", "Invitation has expired; ask a superuser for a new one", requestID)
+	default:
+		return false
+	}
+	return true
+}
+
+func (h *InvitationHandler) activationURL(token string) string {
+	return h.publicURL + "/invitations/" + token
+}
+
+func (h *InvitationHandler) invitationEmail(inv *auth.Invitation, t *team.Team, token string) mail.Message {
+	link := h.activationURL(token)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Hello %s,\n\n", inv.Name)
+	fmt.Fprintf(&b, "You have been invited to DAAP as a member of team %s.\n\n", t.Name)
+	fmt.Fprintf(&b, "Review the invitation at:\n\n  %s\n\n", link)
+	fmt.Fprintf(&b, "and activate it to receive your API key:\n\n  curl -X POST %s/accept\n\n", link)
+	b.WriteString("The key is shown only once, so store it somewhere safe. ")
+	fmt.Fprintf(&b, "This link can be used once and expires on %s.\n", inv.ExpiresAt.UTC().Format(time.RFC1123))
+	return mail.Message{To: inv.Email, Subject: "Your DAAP invitation", Body: b.String()}
+}
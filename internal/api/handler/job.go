@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/jobs"
+)
+
+type jobResponse struct {
+	ID          string  `json:"id"`
+	Type        string  `json:"type"`
+	Status      string  `json:"status"`
+	DatabaseID  string  `json:"databaseId"`
+	OperationID *string `json:"operationId"`
+	Attempts    int     `json:"attempts"`
+	MaxAttempts int     `json:"maxAttempts"`
+	LastError   string  `json:"lastError,omitempty"`
+	RunAfter    string  `json:"runAfter"`
+	RequestID   string  `json:"requestId,omitempty"`
+	CreatedBy   string  `json:"createdBy"`
+	CreatedAt   string  `json:"createdAt"`
+	UpdatedAt   string  `json:"updatedAt"`
+	StartedAt   *string `json:"startedAt"`
+	CompletedAt *string `json:"completedAt"`
+}
+
+func toJobResponse(j *jobs.Job) jobResponse {
+	resp := jobResponse{
+		ID:          j.ID.String(),
+		Type:        j.Type,
+		Status:      j.Status,
+		DatabaseID:  j.DatabaseID.String(),
+		Attempts:    j.Attempts,
+		MaxAttempts: j.MaxAttempts,
+		LastError:   j.LastError,
+		RunAfter:    j.RunAfter.UTC().Format("2006-01-02T15:04:05Z"),
+		RequestID:   j.RequestID,
+		CreatedBy:   j.CreatedBy,
+		CreatedAt:   j.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:   j.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if j.OperationID != nil {
+		s := j.OperationID.String()
+		resp.OperationID = &s
+	}
+	if j.StartedAt != nil {
+		s := j.StartedAt.UTC().Format("2006-01-02T15:04:05Z")
+		resp.StartedAt = &s
+	}
+	if j.CompletedAt != nil {
+		s := j.CompletedAt.UTC().Format("2006-01-02T15:04:05Z")
+		resp.CompletedAt = &s
+	}
+	return resp
+}
+
+// JobHandler handles GET /databases/{id}/jobs.
+type JobHandler struct {
+	repo database.Repository
+	jobs jobs.Repository
+}
+
+// NewJobHandler creates a new JobHandler.
+func NewJobHandler(repo database.Repository, jobs jobs.Repository) *JobHandler {
+	return &JobHandler{repo: repo, jobs: jobs}
+}
+
+// ListByDatabase handles GET /databases/{id}/jobs: the background jobs run
+// for a database, newest first, with their attempts.
+func (h *JobHandler) ListByDatabase(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	list, err := h.jobs.ListByDatabase(r.Context(), db.ID)
+	if err != nil {
+		slog.Error("failed to list jobs", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to list jobs", requestID)
+		return
+	}
+
+	items := make([]jobResponse, len(list))
+	for i := range list {
+		items[i] = toJobResponse(&list[i])
+	}
+	response.Success(w, http.StatusOK, items, requestID)
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+)
+
+type lockResponse struct {
+	Operation  string `json:"operation"`
+	Holder     string `json:"holder"`
+	Instance   string `json:"instance"`
+	RequestID  string `json:"requestId,omitempty"`
+	AcquiredAt string `json:"acquiredAt"`
+	ExpiresAt  string `json:"expiresAt"`
+	RenewedAt  string `json:"renewedAt"`
+}
+
+func toLockResponse(l *database.Lock) lockResponse {
+	return lockResponse{
+		Operation:  l.Operation,
+		Holder:     l.Holder,
+		Instance:   l.Instance,
+		RequestID:  l.RequestID,
+		AcquiredAt: l.AcquiredAt.UTC().Format(time.RFC3339),
+		ExpiresAt:  l.ExpiresAt.UTC().Format(time.RFC3339),
+		RenewedAt:  l.RenewedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// lockDatabase takes the mutation lock on a database for operation, writing
+// 409 OPERATION_IN_PROGRESS, with the in-flight operation as details, and
+// returning false when another operation holds it. The returned release
+// function must be called once the mutation is done. A nil locker disables
+// locking.
+func lockDatabase(w http.ResponseWriter, r *http.Request, locker *database.Locker, databaseID uuid.UUID, operation, requestID string) (release func(), ok bool) {
+	if locker == nil {
+		return func() {}, true
+	}
+
+	holder := "anonymous"
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		holder = identity.UserName
+	}
+
+	lock, err := locker.Acquire(r.Context(), databaseID, operation, holder, requestID)
+	if err != nil {
+		var locked *database.LockedError
+		switch {
+		case errors.As(err, &locked):
+			response.ErrWithDetails(w, http.StatusConflict, "OPERATION_IN_PROGRESS",
+				fmt.Sprintf("Cannot %s database: operation %q by %s is in progress; retry once it completes",
+					operation, locked.Lock.Operation, locked.Lock.Holder),
+				toLockResponse(&locked.Lock), requestID)
+		case errors.Is(err, database.ErrNotFound):
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		default:
+			slog.Error("failed to lock database", "error", err, "id", databaseID)
+			response.ServerErr(w, err, fmt.Sprintf("Failed to %s database", operation), requestID)
+		}
+		return nil, false
+	}
+
+	return func() {
+		if err := locker.Release(r.Context(), lock); err != nil {
+			slog.Error("failed to release database lock", "error", err, "id", databaseID, "operation", operation)
+		}
+	}, true
+}
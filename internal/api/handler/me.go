@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+)
+
+// MeHandler handles the GET /me endpoint.
+type MeHandler struct{}
+
+// NewMeHandler creates a new MeHandler.
+func NewMeHandler() *MeHandler {
+	return &MeHandler{}
+}
+
+// meResponse is the identity of the caller, with the fields of a user.
+type meResponse struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	TeamID          *string  `json:"teamId,omitempty"`
+	TeamName        *string  `json:"teamName,omitempty"`
+	Role            *string  `json:"role,omitempty"`
+	OrganizationID  *string  `json:"organizationId,omitempty"`
+	ApiKeyPrefix    string   `json:"apiKeyPrefix"`
+	ApiKeyExpiresAt *string  `json:"apiKeyExpiresAt"` // API keys do not expire: always null
+	IsSuperuser     bool     `json:"isSuperuser"`
+	FreezeOverride  bool     `json:"freezeOverride"`
+	Scopes          []string `json:"scopes"`
+}
+
+// ServeHTTP returns the identity the request's API key resolved to.
+func (h *MeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	identity := middleware.GetIdentity(r.Context())
+	if identity == nil {
+		response.Err(w, http.StatusUnauthorized, "UNAUTHORIZED", "API key is required", requestID)
+		return
+	}
+
+	resp := meResponse{
+		ID:             identity.UserID.String(),
+		Name:           identity.UserName,
+		TeamName:       identity.TeamName,
+		Role:           identity.Role,
+		ApiKeyPrefix:   identity.KeyPrefix,
+		IsSuperuser:    identity.IsSuperuser,
+		FreezeOverride: identity.FreezeOverride,
+		Scopes:         responseScopes(identity.Scopes),
+	}
+	if identity.TeamID != nil {
+		teamID := identity.TeamID.String()
+		resp.TeamID = &teamID
+	}
+	if identity.OrganizationID != nil {
+		orgID := identity.OrganizationID.String()
+		resp.OrganizationID = &orgID
+	}
+	response.Success(w, http.StatusOK, resp, requestID)
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+)
+
+type operationErrorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+type operationResponse struct {
+	ID          string                  `json:"id"`
+	Type        string                  `json:"type"`
+	Status      string                  `json:"status"`
+	Done        bool                    `json:"done"`
+	DatabaseID  string                  `json:"databaseId"`
+	Progress    int                     `json:"progress"`
+	Message     string                  `json:"message"`
+	Result      map[string]any          `json:"result"`
+	Error       *operationErrorResponse `json:"error"`
+	RequestID   string                  `json:"requestId,omitempty"`
+	CreatedBy   string                  `json:"createdBy"`
+	CreatedAt   string                  `json:"createdAt"`
+	UpdatedAt   string                  `json:"updatedAt"`
+	CompletedAt *string                 `json:"completedAt"`
+}
+
+func toOperationResponse(op *operation.Operation) operationResponse {
+	resp := operationResponse{
+		ID:         op.ID.String(),
+		Type:       op.Type,
+		Status:     op.Status,
+		Done:       op.Done(),
+		DatabaseID: op.DatabaseID.String(),
+		Progress:   op.Progress,
+		Message:    op.Message,
+		Result:     op.Result,
+		RequestID:  op.RequestID,
+		CreatedBy:  op.CreatedBy,
+		CreatedAt:  op.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:  op.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if op.Error != nil {
+		resp.Error = &operationErrorResponse{Code: op.Error.Code, Message: op.Error.Message}
+	}
+	if op.CompletedAt != nil {
+		s := op.CompletedAt.UTC().Format("2006-01-02T15:04:05Z")
+		resp.CompletedAt = &s
+	}
+	return resp
+}
+
+// startOperation records a running operation on db for the current request
+// and points the client at it with an Operation-Location header. It returns
+// nil when ops is nil or the operation could not be recorded.
+func startOperation(w http.ResponseWriter, r *http.Request, ops *operation.Tracker, opType string, db *database.Database, message string) *operation.Operation {
+	createdBy := "anonymous"
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		createdBy = identity.UserName
+	}
+	op := ops.Start(r.Context(), operation.Operation{
+		Type:       opType,
+		DatabaseID: db.ID,
+		TeamID:     db.OwnerTeamID,
+		Message:    message,
+		RequestID:  middleware.GetRequestID(r.Context()),
+		CreatedBy:  createdBy,
+	})
+	if op != nil {
+		w.Header().Set("Operation-Location", "/operations/"+op.ID.String())
+	}
+	return op
+}
+
+// OperationHandler handles the /operations endpoints and
+// GET /databases/{id}/operations.
+type OperationHandler struct {
+	repo database.Repository
+	ops  *operation.Tracker
+}
+
+// NewOperationHandler creates a new OperationHandler.
+func NewOperationHandler(repo database.Repository, ops *operation.Tracker) *OperationHandler {
+	return &OperationHandler{repo: repo, ops: ops}
+}
+
+// GetByID handles GET /operations/{id}. Product users only see operations on
+// their own team's databases.
+func (h *OperationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	op, err := h.ops.Get(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, operation.ErrOperationNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Operation not found", requestID)
+			return
+		}
+		slog.Error("failed to get operation", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to get operation", requestID)
+		return
+	}
+	if teamID, ok := isProductUser(r); ok && op.TeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Operation not found", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, toOperationResponse(op), requestID)
+}
+
+// ListByDatabase handles GET /databases/{id}/operations: the operations on a
+// database, newest first.
+func (h *OperationHandler) ListByDatabase(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	ops, err := h.ops.List(r.Context(), operation.ListFilter{DatabaseID: &db.ID})
+	if err != nil {
+		slog.Error("failed to list operations", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to list operations", requestID)
+		return
+	}
+
+	items := make([]operationResponse, len(ops))
+	for i := range ops {
+		items[i] = toOperationResponse(&ops[i])
+	}
+	response.Success(w, http.StatusOK, items, requestID)
+}
@@ -0,0 +1,390 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/team"
+)
+
+// usageWindow is how far back the usage of an organization looks for the
+// latest compute usage sample of each database.
+const usageWindow = 24 * time.Hour
+
+type createOrganizationRequest struct {
+	Name                string `json:"name"`
+	MaxDatabases        int    `json:"maxDatabases"`
+	QuotaWarningPercent *int   `json:"quotaWarningPercent"`
+}
+
+type updateOrganizationRequest struct {
+	MaxDatabases        *int `json:"maxDatabases"`
+	QuotaWarningPercent *int `json:"quotaWarningPercent"`
+}
+
+type organizationResponse struct {
+	ID                  string `json:"id"`
+	Name                string `json:"name"`
+	MaxDatabases        int    `json:"maxDatabases"`
+	QuotaWarningPercent int    `json:"quotaWarningPercent"`
+	CreatedBy           string `json:"createdBy"`
+	UpdatedBy           string `json:"updatedBy"`
+	CreatedAt           string `json:"createdAt"`
+	UpdatedAt           string `json:"updatedAt"`
+}
+
+func toOrganizationResponse(o *organization.Organization) organizationResponse {
+	return organizationResponse{
+		ID:                  o.ID.String(),
+		Name:                o.Name,
+		MaxDatabases:        o.MaxDatabases,
+		QuotaWarningPercent: o.QuotaWarningPercent,
+		CreatedBy:           o.CreatedBy,
+		UpdatedBy:           o.UpdatedBy,
+		CreatedAt:           o.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:           o.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+type organizationTeamUsage struct {
+	ID                 string         `json:"id"`
+	Name               string         `json:"name"`
+	Databases          int            `json:"databases"`
+	DatabasesByTier    map[string]int `json:"databasesByTier"`
+	CPURequestMillis   int64          `json:"cpuRequestMillis"`
+	MemoryRequestBytes int64          `json:"memoryRequestBytes"`
+}
+
+type organizationUsageResponse struct {
+	OrganizationID     string                  `json:"organizationId"`
+	OrganizationName   string                  `json:"organizationName"`
+	MaxDatabases       int                     `json:"maxDatabases"`
+	Teams              []organizationTeamUsage `json:"teams"`
+	TotalDatabases     int                     `json:"totalDatabases"`
+	CPURequestMillis   int64                   `json:"cpuRequestMillis"`
+	MemoryRequestBytes int64                   `json:"memoryRequestBytes"`
+}
+
+// OrganizationHandler handles organization endpoints.
+type OrganizationHandler struct {
+	repo     organization.Repository
+	teamRepo team.Repository
+	dbRepo   database.Repository
+	samples  database.UsageSampleRepository
+}
+
+// NewOrganizationHandler creates a new OrganizationHandler. The usage of an
+// organization counts the databases in dbRepo of the teams in teamRepo, and
+// sums their compute requests from samples unless it is nil.
+func NewOrganizationHandler(repo organization.Repository, teamRepo team.Repository, dbRepo database.Repository, samples database.UsageSampleRepository) *OrganizationHandler {
+	return &OrganizationHandler{repo: repo, teamRepo: teamRepo, dbRepo: dbRepo, samples: samples}
+}
+
+// Create handles POST /organizations.
+func (h *OrganizationHandler) Create(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req createOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+
+	fieldErrors := validation.ValidateCreateOrganizationRequest(validation.CreateOrganizationRequest{
+		Name:                req.Name,
+		MaxDatabases:        req.MaxDatabases,
+		QuotaWarningPercent: req.QuotaWarningPercent,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	o := &organization.Organization{
+		Name:                strings.TrimSpace(req.Name),
+		MaxDatabases:        req.MaxDatabases,
+		QuotaWarningPercent: organization.DefaultQuotaWarningPercent,
+		CreatedBy:           actorName(r),
+	}
+	if req.QuotaWarningPercent != nil {
+		o.QuotaWarningPercent = *req.QuotaWarningPercent
+	}
+	if err := h.repo.Create(r.Context(), o); err != nil {
+		if errors.Is(err, organization.ErrDuplicateName) {
+			response.Err(w, http.StatusConflict, "DUPLICATE_NAME", fmt.Sprintf("An organization named %q already exists", o.Name), requestID)
+			return
+		}
+		slog.Error("failed to create organization", "error", err)
+		response.ServerErr(w, err, "Failed to create organization", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusCreated, toOrganizationResponse(o), requestID)
+}
+
+// List handles GET /organizations. Organization superadmins see only their
+// own organization.
+func (h *OrganizationHandler) List(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	orgs, err := h.repo.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list organizations", "error", err)
+		response.ServerErr(w, err, "Failed to list organizations", requestID)
+		return
+	}
+
+	adminOf, isAdmin := orgAdminOf(r)
+	items := make([]organizationResponse, 0, len(orgs))
+	for i := range orgs {
+		if isAdmin && orgs[i].ID != adminOf {
+			continue
+		}
+		items = append(items, toOrganizationResponse(&orgs[i]))
+	}
+
+	response.SuccessList(w, http.StatusOK, items, len(items), 1, 100, requestID)
+}
+
+// GetByID handles GET /organizations/{id}.
+func (h *OrganizationHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	o, ok := h.visibleOrganization(w, r, "Failed to get organization", requestID)
+	if !ok {
+		return
+	}
+	response.Success(w, http.StatusOK, toOrganizationResponse(o), requestID)
+}
+
+// Update handles PATCH /organizations/{id}, setting the database quota and
+// its warning threshold. Lowering the quota below the current count blocks
+// new databases only.
+func (h *OrganizationHandler) Update(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req updateOrganizationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	fieldErrors := validation.ValidateUpdateOrganizationRequest(validation.UpdateOrganizationRequest{
+		MaxDatabases:        req.MaxDatabases,
+		QuotaWarningPercent: req.QuotaWarningPercent,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	o, err := h.repo.Update(r.Context(), id, organization.UpdateFields{
+		MaxDatabases:        req.MaxDatabases,
+		QuotaWarningPercent: req.QuotaWarningPercent,
+		UpdatedBy:           actorName(r),
+	})
+	if err != nil {
+		if errors.Is(err, organization.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+			return
+		}
+		slog.Error("failed to update organization", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to update organization", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, toOrganizationResponse(o), requestID)
+}
+
+// Delete handles DELETE /organizations/{id}. Its superadmins are deleted
+// with it; an organization that still has teams or blueprints cannot be
+// deleted.
+func (h *OrganizationHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	if err := h.repo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, organization.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+			return
+		}
+		if errors.Is(err, organization.ErrHasTeams) {
+			response.Err(w, http.StatusConflict, "ORGANIZATION_HAS_TEAMS", "Cannot delete an organization with teams or blueprints", requestID)
+			return
+		}
+		slog.Error("failed to delete organization", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to delete organization", requestID)
+		return
+	}
+
+	response.NoContent(w)
+}
+
+// Usage handles GET /organizations/{id}/usage: the active databases of each
+// team of the organization, by tier, and their compute requests from the
+// latest usage sample of each database in the past day, for billing.
+func (h *OrganizationHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	o, ok := h.visibleOrganization(w, r, "Failed to get organization usage", requestID)
+	if !ok {
+		return
+	}
+
+	teams, err := h.teamRepo.List(r.Context())
+	if err != nil {
+		slog.Error("failed to list teams", "error", err)
+		response.ServerErr(w, err, "Failed to get organization usage", requestID)
+		return
+	}
+
+	resp := organizationUsageResponse{
+		OrganizationID:   o.ID.String(),
+		OrganizationName: o.Name,
+		MaxDatabases:     o.MaxDatabases,
+		Teams:            []organizationTeamUsage{},
+	}
+	for _, t := range teams {
+		if t.OrganizationID == nil || *t.OrganizationID != o.ID {
+			continue
+		}
+		usage, err := h.teamUsage(r.Context(), &t)
+		if err != nil {
+			slog.Error("failed to get team usage", "error", err, "team", t.Name)
+			response.ServerErr(w, err, "Failed to get organization usage", requestID)
+			return
+		}
+		resp.Teams = append(resp.Teams, usage)
+		resp.TotalDatabases += usage.Databases
+		resp.CPURequestMillis += usage.CPURequestMillis
+		resp.MemoryRequestBytes += usage.MemoryRequestBytes
+	}
+	sort.Slice(resp.Teams, func(i, j int) bool { return resp.Teams[i].Name < resp.Teams[j].Name })
+
+	response.Success(w, http.StatusOK, resp, requestID)
+}
+
+// teamUsage sums the databases and compute requests of a team.
+func (h *OrganizationHandler) teamUsage(ctx context.Context, t *team.Team) (organizationTeamUsage, error) {
+	usage := organizationTeamUsage{ID: t.ID.String(), Name: t.Name, DatabasesByTier: map[string]int{}}
+	since := time.Now().Add(-usageWindow)
+	for page := 1; ; page++ {
+		result, err := h.dbRepo.List(ctx, database.ListFilter{OwnerTeamID: &t.ID, Page: page, Limit: 100})
+		if err != nil {
+			return usage, fmt.Errorf("listing databases: %w", err)
+		}
+		for _, db := range result.Databases {
+			usage.Databases++
+			usage.DatabasesByTier[db.TierName]++
+			if h.samples == nil {
+				continue
+			}
+			samples, err := h.samples.ListByDatabase(ctx, db.ID, since)
+			if err != nil {
+				return usage, fmt.Errorf("listing usage samples of %s: %w", db.Name, err)
+			}
+			if len(samples) > 0 {
+				latest := samples[len(samples)-1]
+				usage.CPURequestMillis += latest.CPURequestMillis
+				usage.MemoryRequestBytes += latest.MemoryRequestBytes
+			}
+		}
+		if len(result.Databases) == 0 || page*100 >= result.Total {
+			return usage, nil
+		}
+	}
+}
+
+// visibleOrganization returns the organization of the id URL parameter, or
+// writes an error response and returns false. Organization superadmins get
+// 404 for organizations other than their own.
+func (h *OrganizationHandler) visibleOrganization(w http.ResponseWriter, r *http.Request, failure, requestID string) (*organization.Organization, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return nil, false
+	}
+	if adminOf, ok := orgAdminOf(r); ok && adminOf != id {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+		return nil, false
+	}
+
+	o, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, organization.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+			return nil, false
+		}
+		slog.Error("failed to get organization", "error", err, "id", id)
+		response.ServerErr(w, err, failure, requestID)
+		return nil, false
+	}
+	return o, true
+}
+
+// orgAdminOf returns the organization the caller administers when it is an
+// organization superadmin.
+func orgAdminOf(r *http.Request) (uuid.UUID, bool) {
+	identity := middleware.GetIdentity(r.Context())
+	if identity != nil && identity.IsOrgAdmin() {
+		return *identity.OrganizationID, true
+	}
+	return uuid.Nil, false
+}
+
+// manages reports whether the caller may manage team t: the superuser any
+// team, an organization superadmin only the teams of its organization.
+func manages(r *http.Request, t *team.Team) bool {
+	adminOf, ok := orgAdminOf(r)
+	return !ok || (t.OrganizationID != nil && *t.OrganizationID == adminOf)
+}
+
+// overQuota writes a 409 response and returns true if the organization of
+// teamID may not create another database. Otherwise it returns the quota
+// warnings to add to the response's meta. A nil gate disables the check.
+func overQuota(w http.ResponseWriter, r *http.Request, gate organization.QuotaGate, teamID uuid.UUID, requestID string) ([]string, bool) {
+	if gate == nil {
+		return nil, false
+	}
+	warning, err := gate.CheckDatabase(r.Context(), teamID)
+	if err == nil {
+		if warning == "" {
+			return nil, false
+		}
+		return []string{warning}, false
+	}
+	if errors.Is(err, organization.ErrQuotaExceeded) {
+		response.Err(w, http.StatusConflict, "QUOTA_EXCEEDED", "Cannot create database: "+err.Error(), requestID)
+		return nil, true
+	}
+	slog.Error("failed to check organization quota", "error", err)
+	response.ServerErr(w, err, "Failed to create database", requestID)
+	return nil, true
+}
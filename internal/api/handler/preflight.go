@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/preflight"
+)
+
+// PreflightRunner runs the preflight checks.
+type PreflightRunner interface {
+	Run(ctx context.Context) preflight.Report
+}
+
+// PreflightHandler handles the GET /admin/preflight endpoint.
+type PreflightHandler struct {
+	runner PreflightRunner
+}
+
+// NewPreflightHandler creates a new PreflightHandler.
+func NewPreflightHandler(runner PreflightRunner) *PreflightHandler {
+	return &PreflightHandler{runner: runner}
+}
+
+type preflightCheckResponse struct {
+	Name    string   `json:"name"`
+	Status  string   `json:"status"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+type preflightResponse struct {
+	Passed bool                     `json:"passed"`
+	Checks []preflightCheckResponse `json:"checks"`
+}
+
+// ServeHTTP runs the checks and returns the report. The status code is 200
+// whether or not the checks pass; clients read data.passed.
+func (h *PreflightHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	report := h.runner.Run(r.Context())
+
+	resp := preflightResponse{
+		Passed: report.Passed,
+		Checks: make([]preflightCheckResponse, 0, len(report.Checks)),
+	}
+	for _, c := range report.Checks {
+		resp.Checks = append(resp.Checks, preflightCheckResponse{
+			Name:    c.Name,
+			Status:  string(c.Status),
+			Message: c.Message,
+			Details: c.Details,
+		})
+	}
+
+	response.Success(w, http.StatusOK, resp, requestID)
+}
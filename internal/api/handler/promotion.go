@@ -0,0 +1,433 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/budget"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/placement"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+type promoteDatabaseRequest struct {
+	Name string `json:"name"`
+}
+
+type promotionResponse struct {
+	ID               int64  `json:"id"`
+	SourceDatabaseID string `json:"sourceDatabaseId"`
+	SourceName       string `json:"sourceName"`
+	TargetDatabaseID string `json:"targetDatabaseId"`
+	TargetName       string `json:"targetName"`
+	FromEnvironment  string `json:"fromEnvironment"`
+	ToEnvironment    string `json:"toEnvironment"`
+	Tier             string `json:"tier"`
+	Blueprint        string `json:"blueprint,omitempty"`
+	Action           string `json:"action"`
+	Actor            string `json:"actor"`
+	CreatedAt        string `json:"createdAt"`
+}
+
+func toPromotionResponse(p *database.Promotion) promotionResponse {
+	return promotionResponse{
+		ID:               p.ID,
+		SourceDatabaseID: p.SourceDatabaseID.String(),
+		SourceName:       p.SourceName,
+		TargetDatabaseID: p.TargetDatabaseID.String(),
+		TargetName:       p.TargetName,
+		FromEnvironment:  p.FromEnvironment,
+		ToEnvironment:    p.ToEnvironment,
+		Tier:             p.Tier,
+		Blueprint:        p.Blueprint,
+		Action:           p.Action,
+		Actor:            p.Actor,
+		CreatedAt:        p.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+}
+
+// PromotionHandler promotes databases along the environment chain and serves
+// the promotion audit trail.
+type PromotionHandler struct {
+	repo       database.Repository
+	tierRepo   tier.Repository
+	bpRepo     blueprint.Repository
+	registry   *provider.Registry
+	promotions database.PromotionRepository
+	envs       database.Environments
+	ns         string
+	freezes    freeze.Gate
+	locker     *database.Locker
+	ops        *operation.Tracker
+	specs      database.SpecRepository
+	quotas     organization.QuotaGate
+	placer     placement.Placer
+	signer     *blueprint.Signer
+	dnsZone    database.DNSZone
+	budgets    budget.Gate
+}
+
+// NewPromotionHandler creates a new PromotionHandler. A nil freezes gate
+// disables change freeze checks. Promotions take the mutation lock of the
+// source and of an existing target database unless locker is nil, and are
+// tracked as operations on the target unless ops is nil. The spec applied to
+// the target is recorded in specs unless it is nil. Promotions creating a
+// database are checked against organization quotas unless quotas is nil, and
+// are placed by placer when their tier names no namespace. The tier's
+// blueprint is verified by signer unless it is nil. A database the promotion
+// creates gets a friendly hostname in dnsZone unless it is empty. The
+// database the promotion creates or moves to the source's tier is checked
+// against the team's connection budget unless budgets is nil.
+func NewPromotionHandler(repo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry,
+	promotions database.PromotionRepository, envs database.Environments, ns string, freezes freeze.Gate, locker *database.Locker, ops *operation.Tracker, specs database.SpecRepository,
+	quotas organization.QuotaGate, placer placement.Placer, signer *blueprint.Signer, dnsZone database.DNSZone, budgets budget.Gate) *PromotionHandler {
+	return &PromotionHandler{
+		repo:       repo,
+		tierRepo:   tierRepo,
+		bpRepo:     bpRepo,
+		registry:   registry,
+		promotions: promotions,
+		envs:       envs,
+		ns:         ns,
+		freezes:    freezes,
+		locker:     locker,
+		ops:        ops,
+		specs:      specs,
+		quotas:     quotas,
+		placer:     placer,
+		signer:     signer,
+		dnsZone:    dnsZone,
+		budgets:    budgets,
+	}
+}
+
+// Promote handles POST /databases/{id}/promote. It creates the equivalent
+// database in the next environment, or updates the one created by an earlier
+// promotion, on the source's tier and blueprint, and records the promotion.
+// The promoted database runs the images pinned for the source, so the next
+// environment gets exactly what was tested in the previous one. A database
+// created by the promotion is exposed like the source.
+func (h *PromotionHandler) Promote(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req promoteDatabaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if fieldErrors := validation.ValidatePromoteRequest(validation.PromoteDatabaseRequest{Name: req.Name}); len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	source, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to get database for promotion", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to promote database", requestID)
+		return
+	}
+	if teamID, ok := isProductUser(r); ok && source.OwnerTeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return
+	}
+	middleware.SetAuditClassification(r.Context(), source.DataClassification)
+
+	next, reason := h.nextEnvironment(source)
+	if reason != "" {
+		response.Err(w, http.StatusConflict, "PROMOTION_NOT_POSSIBLE", reason, requestID)
+		return
+	}
+
+	if frozen(w, r, h.freezes, source.OwnerTeamID, "promote", requestID) {
+		return
+	}
+
+	release, ok := lockDatabase(w, r, h.locker, source.ID, "promote", requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	resolvedTier, err := h.tierRepo.GetByID(r.Context(), *source.TierID)
+	if err != nil {
+		slog.Error("failed to look up tier for promotion", "error", err, "tierID", source.TierID)
+		response.ServerErr(w, err, "Failed to promote database", requestID)
+		return
+	}
+	var bp *blueprint.Blueprint
+	if resolvedTier.BlueprintID != nil {
+		if bp, ok = tierBlueprint(w, r, h.bpRepo, h.signer, resolvedTier, "Failed to promote database", requestID); !ok {
+			return
+		}
+	}
+
+	existing, err := h.repo.List(r.Context(), database.ListFilter{PromotedFromID: &source.ID, Environment: &next, Limit: 1})
+	if err != nil {
+		slog.Error("failed to look up promoted database", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to promote database", requestID)
+		return
+	}
+
+	var target *database.Database
+	var warnings []string
+	action := database.PromotionCreated
+	if len(existing.Databases) > 0 {
+		action = database.PromotionUpdated
+		releaseTarget, ok := lockDatabase(w, r, h.locker, existing.Databases[0].ID, "promote", requestID)
+		if !ok {
+			return
+		}
+		defer releaseTarget()
+		promoted := &existing.Databases[0]
+		if overBudget(w, r, h.budgets, promoted.OwnerTeamID, &resolvedTier.ID, promoted.ID, "Failed to promote database", requestID) {
+			return
+		}
+		if len(source.Images) > 0 {
+			promoted.Images = source.Images
+		}
+		target, err = h.update(w, r, promoted, resolvedTier, bp)
+	} else {
+		var over bool
+		if warnings, over = overQuota(w, r, h.quotas, source.OwnerTeamID, requestID); over {
+			return
+		}
+		if overBudget(w, r, h.budgets, source.OwnerTeamID, &resolvedTier.ID, uuid.Nil, "Failed to promote database", requestID) {
+			return
+		}
+		name := req.Name
+		if name == "" {
+			name = database.PromotedName(source.Name, source.Environment, next)
+			if fieldErrors := validation.ValidatePromoteRequest(validation.PromoteDatabaseRequest{Name: name}); len(fieldErrors) > 0 {
+				response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR",
+					fmt.Sprintf("Derived name %q is invalid; pass a name", name), fieldErrors, requestID)
+				return
+			}
+		}
+		namespace, nsErr := resolvedTier.ResolveNamespace(tier.NamespaceData{Team: source.OwnerTeamName, Database: name, Environment: next}, "")
+		if nsErr != nil {
+			slog.Error("failed to resolve tier namespace", "error", nsErr, "tier", resolvedTier.Name)
+			response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Tier namespace does not produce a valid namespace for this database",
+				[]validation.FieldError{{Field: "tier", Message: nsErr.Error()}}, requestID)
+			return
+		}
+		var placed *database.Placement
+		if namespace == "" {
+			var ok bool
+			if namespace, placed, ok = placeDatabase(w, r, h.placer, h.ns, source.OwnerTeamName, resolvedTier.Name, requestID); !ok {
+				return
+			}
+		}
+		target = &database.Database{
+			Name:           name,
+			OwnerTeamID:    source.OwnerTeamID,
+			OwnerTeamName:  source.OwnerTeamName,
+			TierID:         &resolvedTier.ID,
+			TierName:       resolvedTier.Name,
+			Purpose:        source.Purpose,
+			Namespace:      namespace,
+			Environment:    next,
+			PromotedFromID: &source.ID,
+			Placement:      placed,
+			Images:         source.Images,
+			Exposure:       source.Exposure,
+			DNSName:        h.dnsZone.Hostname(name),
+			QueryInsights:  source.QueryInsights,
+			CreatedBy:      actorName(r),
+
+			DataClassification: source.DataClassification,
+		}
+		err = h.create(w, r, target, resolvedTier, bp)
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrDuplicateName) {
+			response.Err(w, http.StatusConflict, "DUPLICATE_NAME",
+				fmt.Sprintf("A database named %q already exists; pass a name to promote under another name", target.Name), requestID)
+			return
+		}
+		slog.Error("failed to promote database", "error", err, "database", source.Name, "environment", next)
+		response.ServerErr(w, err, "Failed to promote database", requestID)
+		return
+	}
+
+	promotion := &database.Promotion{
+		SourceDatabaseID: source.ID,
+		SourceName:       source.Name,
+		TargetDatabaseID: target.ID,
+		TargetName:       target.Name,
+		FromEnvironment:  source.Environment,
+		ToEnvironment:    next,
+		Tier:             resolvedTier.Name,
+		Action:           action,
+	}
+	if bp != nil {
+		promotion.Blueprint = bp.Name
+	}
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		promotion.Actor = identity.UserName
+	}
+	if err := h.promotions.Record(r.Context(), promotion); err != nil {
+		slog.Error("failed to record promotion", "error", err, "source", source.Name, "target", target.Name)
+	}
+
+	slog.Info("database promoted", "source", source.Name, "target", target.Name,
+		"from", source.Environment, "to", next, "action", action)
+	w.Header().Set("Location", "/databases/"+target.ID.String())
+	status := http.StatusOK
+	if action == database.PromotionCreated {
+		status = http.StatusCreated
+	}
+	response.SuccessWithWarnings(w, status, toDatabaseResponse(target), warnings, requestID)
+}
+
+// nextEnvironment returns the environment the database is promoted to, or a
+// reason why it cannot be promoted.
+func (h *PromotionHandler) nextEnvironment(db *database.Database) (string, string) {
+	if db.Environment == "" {
+		return "", "Database has no environment"
+	}
+	if db.TierID == nil {
+		return "", "Database has no tier"
+	}
+	if db.Status != "ready" {
+		return "", fmt.Sprintf("Database must be ready to be promoted (status is %s)", db.Status)
+	}
+	next, ok := h.envs.Next(db.Environment)
+	if !ok {
+		if h.envs.Contains(db.Environment) {
+			return "", fmt.Sprintf("Database is in the last environment (%s)", db.Environment)
+		}
+		return "", fmt.Sprintf("Environment %s is not part of the promotion chain", db.Environment)
+	}
+	return next, ""
+}
+
+// create inserts the promoted database and provisions it, tracking the
+// provisioning as an operation. A failed apply leaves the new database in
+// error, as on creation.
+func (h *PromotionHandler) create(w http.ResponseWriter, r *http.Request, target *database.Database, t *tier.Tier, bp *blueprint.Blueprint) error {
+	ctx := r.Context()
+	if err := h.repo.Create(ctx, target); err != nil {
+		return err
+	}
+	op := startOperation(w, r, h.ops, operation.TypePromote, target, "Provisioning the database")
+
+	if bp != nil && h.registry != nil {
+		p, ok := h.registry.Get(bp.Provider)
+		if !ok {
+			slog.Error("provider not registered", "provider", bp.Provider)
+			markCreateError(ctx, h.repo, target)
+			h.ops.Fail(ctx, op, "PROVIDER_NOT_REGISTERED", fmt.Sprintf("Provider %q is not registered", bp.Provider))
+			return nil
+		}
+		if err := p.Apply(ctx, target.ProviderDatabase(t, bp), bp.Manifests); err != nil {
+			slog.Error("provider.Apply failed", "error", err, "database", target.Name, "provider", bp.Provider)
+			markCreateError(ctx, h.repo, target)
+			h.ops.Fail(ctx, op, "APPLY_FAILED", fmt.Sprintf("Applying blueprint %s failed: %v", bp.Name, err))
+			return nil
+		}
+		database.RecordSpec(ctx, h.specs, target, t, bp)
+		h.ops.Progress(ctx, op, 50, "Waiting for the database to become ready")
+	}
+	return nil
+}
+
+// update re-applies the source's blueprint to a database created by an
+// earlier promotion, with the image pins target carries, and moves it to the
+// source's tier. The operation tracking
+// it completes once the reconciler has observed the change, or right away
+// when the database's spec did not change.
+func (h *PromotionHandler) update(w http.ResponseWriter, r *http.Request, target *database.Database, t *tier.Tier, bp *blueprint.Blueprint) (*database.Database, error) {
+	ctx := r.Context()
+	op := startOperation(w, r, h.ops, operation.TypePromote, target, "Applying the blueprint")
+	if bp != nil && h.registry != nil {
+		p, ok := h.registry.Get(bp.Provider)
+		if !ok {
+			h.ops.Fail(ctx, op, "PROVIDER_NOT_REGISTERED", fmt.Sprintf("Provider %q is not registered", bp.Provider))
+			return target, fmt.Errorf("provider %q not registered", bp.Provider)
+		}
+		if err := p.Apply(ctx, target.ProviderDatabase(t, bp), bp.Manifests); err != nil {
+			h.ops.Fail(ctx, op, "APPLY_FAILED", fmt.Sprintf("Applying blueprint %s failed: %v", bp.Name, err))
+			return target, fmt.Errorf("applying blueprint %s: %w", bp.Name, err)
+		}
+		database.RecordSpec(ctx, h.specs, target, t, bp)
+	}
+	updated, err := h.repo.Update(ctx, target.ID, database.UpdateFields{TierID: &t.ID, Images: target.Images, UpdatedBy: actorName(r)})
+	if err != nil {
+		h.ops.Fail(ctx, op, "INTERNAL_ERROR", "Failed to move the database to the source's tier")
+		return nil, err
+	}
+	if updated.ObservedGeneration < updated.Generation {
+		h.ops.Progress(ctx, op, 50, "Waiting for the database to become ready")
+	} else {
+		h.ops.Succeed(ctx, op, map[string]any{"databaseId": updated.ID.String()})
+	}
+	return updated, nil
+}
+
+// List handles GET /databases/{id}/promotions: the promotions the database
+// was the source or the target of, oldest first.
+func (h *PromotionHandler) List(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	db, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to get database", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to list promotions", requestID)
+		return
+	}
+	if teamID, ok := isProductUser(r); ok && db.OwnerTeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return
+	}
+
+	promotions, err := h.promotions.ListByDatabase(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to list promotions", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to list promotions", requestID)
+		return
+	}
+
+	items := make([]promotionResponse, len(promotions))
+	for i := range promotions {
+		items[i] = toPromotionResponse(&promotions[i])
+	}
+	response.Success(w, http.StatusOK, items, requestID)
+}
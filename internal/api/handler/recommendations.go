@@ -0,0 +1,152 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/recommend"
+)
+
+// Recommender assesses a database's compute usage against the available tiers.
+type Recommender interface {
+	Recommend(ctx context.Context, db *database.Database) (*recommend.Result, error)
+}
+
+// RecommendationHandler handles the GET /databases/{id}/recommendations endpoint.
+type RecommendationHandler struct {
+	repo        database.Repository
+	recommender Recommender
+	changes     database.TierChangeRepository
+}
+
+// NewRecommendationHandler creates a new RecommendationHandler.
+func NewRecommendationHandler(repo database.Repository, recommender Recommender, changes database.TierChangeRepository) *RecommendationHandler {
+	return &RecommendationHandler{repo: repo, recommender: recommender, changes: changes}
+}
+
+type recommendationUsageResponse struct {
+	CPUP95Millis       int64 `json:"cpuP95Millis"`
+	CPURequestMillis   int64 `json:"cpuRequestMillis"`
+	MemoryMaxBytes     int64 `json:"memoryMaxBytes"`
+	MemoryRequestBytes int64 `json:"memoryRequestBytes"`
+}
+
+type recommendationItemResponse struct {
+	Action string `json:"action"`
+	Tier   string `json:"tier"`
+	Reason string `json:"reason"`
+}
+
+type autoApplyResponse struct {
+	Enabled bool    `json:"enabled"`
+	Window  *string `json:"window"`
+}
+
+type tierChangeResponse struct {
+	FromTier  string  `json:"fromTier"`
+	ToTier    string  `json:"toTier"`
+	Actor     string  `json:"actor"`
+	Reason    string  `json:"reason"`
+	Status    string  `json:"status"`
+	Error     *string `json:"error"`
+	CreatedAt string  `json:"createdAt"`
+}
+
+type recommendationResponse struct {
+	CurrentTier     *string                      `json:"currentTier"`
+	SampleCount     int                          `json:"sampleCount"`
+	MinSamples      int                          `json:"minSamples"`
+	Usage           recommendationUsageResponse  `json:"usage"`
+	Recommendations []recommendationItemResponse `json:"recommendations"`
+	AutoApply       autoApplyResponse            `json:"autoApply"`
+	History         []tierChangeResponse         `json:"history"`
+}
+
+// ServeHTTP returns the tier recommendations and tier change history of a
+// database.
+func (h *RecommendationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	db, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to get database", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to get database", requestID)
+		return
+	}
+
+	// Product users: return 404 for non-owned databases (no info leakage)
+	if teamID, ok := isProductUser(r); ok && db.OwnerTeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return
+	}
+
+	result, err := h.recommender.Recommend(r.Context(), db)
+	if err != nil {
+		slog.Error("failed to compute recommendations", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to compute recommendations", requestID)
+		return
+	}
+
+	changes, err := h.changes.ListByDatabase(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to list tier changes", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to list tier changes", requestID)
+		return
+	}
+
+	resp := recommendationResponse{
+		SampleCount: result.SampleCount,
+		MinSamples:  result.MinSamples,
+		Usage: recommendationUsageResponse{
+			CPUP95Millis:       result.Usage.CPUP95Millis,
+			CPURequestMillis:   result.Usage.CPURequestMillis,
+			MemoryMaxBytes:     result.Usage.MemoryMaxBytes,
+			MemoryRequestBytes: result.Usage.MemoryRequestBytes,
+		},
+		Recommendations: make([]recommendationItemResponse, len(result.Recommendations)),
+		AutoApply:       autoApplyResponse{Enabled: result.AutoApply},
+		History:         make([]tierChangeResponse, len(changes)),
+	}
+	if result.CurrentTier != "" {
+		resp.CurrentTier = &result.CurrentTier
+	}
+	if result.AutoApply {
+		resp.AutoApply.Window = &result.Window
+	}
+	for i, rec := range result.Recommendations {
+		resp.Recommendations[i] = recommendationItemResponse{Action: rec.Action, Tier: rec.Tier, Reason: rec.Reason}
+	}
+	for i, c := range changes {
+		item := tierChangeResponse{
+			FromTier:  c.FromTier,
+			ToTier:    c.ToTier,
+			Actor:     c.Actor,
+			Reason:    c.Reason,
+			Status:    c.Status,
+			CreatedAt: c.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+		if c.Error != "" {
+			item.Error = &c.Error
+		}
+		resp.History[i] = item
+	}
+	response.Success(w, http.StatusOK, resp, requestID)
+}
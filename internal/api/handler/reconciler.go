@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/reconciler"
+)
+
+// ReconcilerController reports the reconciler's state and adjusts its
+// interval at runtime.
+type ReconcilerController interface {
+	ReconcilerStatus
+	State() reconciler.State
+	SetInterval(d time.Duration) error
+}
+
+// ReconcilerHandler handles the /admin/reconciler endpoints.
+type ReconcilerHandler struct {
+	controller ReconcilerController
+}
+
+// NewReconcilerHandler creates a new ReconcilerHandler.
+func NewReconcilerHandler(controller ReconcilerController) *ReconcilerHandler {
+	return &ReconcilerHandler{controller: controller}
+}
+
+type reconcilerResponse struct {
+	IntervalSeconds   float64        `json:"intervalSeconds"`
+	LastRunAt         *string        `json:"lastRunAt"`
+	LastRunDurationMs float64        `json:"lastRunDurationMs"`
+	LastSuccessAt     *string        `json:"lastSuccessAt"`
+	Processed         int            `json:"processed"`
+	Errors            map[string]int `json:"errors"`
+	Backlog           int            `json:"backlog"`
+}
+
+type updateReconcilerRequest struct {
+	IntervalSeconds *int `json:"intervalSeconds"`
+}
+
+// Get handles GET /admin/reconciler.
+func (h *ReconcilerHandler) Get(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+	response.Success(w, http.StatusOK, toReconcilerResponse(h.controller.State()), requestID)
+}
+
+// Update handles PATCH /admin/reconciler. The new interval applies right
+// away and lasts until the next restart, which goes back to
+// RECONCILER_INTERVAL.
+func (h *ReconcilerHandler) Update(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req updateReconcilerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+
+	fieldErrors := validation.ValidateUpdateReconcilerRequest(validation.UpdateReconcilerRequest{
+		IntervalSeconds: req.IntervalSeconds,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	if err := h.controller.SetInterval(time.Duration(*req.IntervalSeconds) * time.Second); err != nil {
+		response.ServerErr(w, err, "Failed to set the reconciler interval", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, toReconcilerResponse(h.controller.State()), requestID)
+}
+
+func toReconcilerResponse(s reconciler.State) reconcilerResponse {
+	resp := reconcilerResponse{
+		IntervalSeconds:   s.Interval.Seconds(),
+		LastRunDurationMs: float64(s.LastRunDuration.Microseconds()) / 1000,
+		Processed:         s.Processed,
+		Errors:            s.Errors,
+		Backlog:           s.Backlog,
+	}
+	if resp.Errors == nil {
+		resp.Errors = map[string]int{}
+	}
+	if !s.LastRunAt.IsZero() {
+		at := s.LastRunAt.UTC().Format(time.RFC3339)
+		resp.LastRunAt = &at
+	}
+	if !s.LastSuccessAt.IsZero() {
+		at := s.LastSuccessAt.UTC().Format(time.RFC3339)
+		resp.LastSuccessAt = &at
+	}
+	return resp
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// renameDatabaseRequest is the request body for POST /databases/{id}/rename.
+type renameDatabaseRequest struct {
+	Name string `json:"name"`
+}
+
+// renameResponse is the JSON representation of a rename in progress.
+type renameResponse struct {
+	From        string `json:"from"`
+	To          string `json:"to"`
+	Phase       string `json:"phase"`
+	RequestedBy string `json:"requestedBy"`
+	StartedAt   string `json:"startedAt"`
+}
+
+// Rename handles POST /databases/{id}/rename. Names are baked into the
+// database's resources, so a rename copies the database: the provider clones
+// it under the new name as a replica, and the database is marked renaming.
+// The reconciler promotes the copy once it has caught up, which stops writes
+// under the old name for the cutover, then gives the database the new name
+// and deletes the resources under the old one. The new name is reserved from
+// the start; the database keeps its ID, history and everything else.
+func (h *DatabaseHandler) Rename(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	var req renameDatabaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	fieldErrors := validation.ValidateRenameRequest(validation.RenameDatabaseRequest{Name: req.Name})
+	if len(fieldErrors) == 0 && req.Name == db.Name {
+		fieldErrors = append(fieldErrors, validation.FieldError{Field: "name", Message: "name must differ from the current name"})
+	}
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	if h.renames == nil {
+		response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE", "Renames are not enabled", requestID)
+		return
+	}
+	if db.Status != "ready" {
+		response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE",
+			fmt.Sprintf("Database must be ready to rename (status is %s)", db.Status), requestID)
+		return
+	}
+
+	if frozen(w, r, h.freezes, db.OwnerTeamID, "rename", requestID) {
+		return
+	}
+
+	release, ok := lockDatabase(w, r, h.locker, db.ID, "rename", requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if db.TierID == nil || h.registry == nil {
+		response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE", "Database is not managed by a provider", requestID)
+		return
+	}
+	t, err := h.tierRepo.GetByID(r.Context(), *db.TierID)
+	if err != nil {
+		slog.Error("failed to resolve tier", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to rename database", requestID)
+		return
+	}
+	if t.BlueprintID == nil {
+		response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE", "Database is not managed by a provider", requestID)
+		return
+	}
+	bp, ok := tierBlueprint(w, r, h.bpRepo, h.signer, t, "Failed to rename database", requestID)
+	if !ok {
+		return
+	}
+	p, ok := h.registry.Get(bp.Provider)
+	if !ok {
+		response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE", fmt.Sprintf("Provider %q is not registered", bp.Provider), requestID)
+		return
+	}
+	cloner, ok := p.(provider.Cloner)
+	if !ok {
+		response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support renames", bp.Provider), requestID)
+		return
+	}
+
+	rename := database.Rename{
+		From:        db.Name,
+		To:          req.Name,
+		Phase:       database.RenameCopying,
+		RequestedBy: actorName(r),
+		StartedAt:   time.Now(),
+	}
+	if db.DNSName != "" {
+		rename.DNSName = h.dnsZone.Hostname(req.Name)
+	}
+	if _, err := h.renames.StartRename(r.Context(), db.ID, rename); err != nil {
+		switch {
+		case errors.Is(err, database.ErrDuplicateName):
+			response.Err(w, http.StatusConflict, "DUPLICATE_NAME", fmt.Sprintf("A database named %q already exists", req.Name), requestID)
+		case errors.Is(err, database.ErrRenameInProgress):
+			response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE", "Database is already being renamed", requestID)
+		case errors.Is(err, database.ErrNotFound):
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		default:
+			slog.Error("failed to record rename", "error", err, "id", db.ID)
+			response.ServerErr(w, err, "Failed to rename database", requestID)
+		}
+		return
+	}
+
+	source := db.ProviderDatabase(t, bp)
+	target := source
+	target.Name = rename.To
+	target.ClusterName = database.ClusterName(rename.To)
+	target.PoolerName = database.PoolerName(rename.To)
+	target.DNSName = rename.DNSName
+	if err := cloner.Clone(r.Context(), target, source, bp.Manifests); err != nil {
+		h.abandonRename(r, db, p, target)
+		if errors.Is(err, provider.ErrNotSupported) {
+			response.Err(w, http.StatusConflict, "RENAME_NOT_POSSIBLE", fmt.Sprintf("Provider %q cannot rename this database", bp.Provider), requestID)
+			return
+		}
+		slog.Error("provider.Clone failed", "error", err, "database", db.Name, "to", rename.To, "provider", bp.Provider)
+		response.ServerErr(w, err, "Failed to rename database", requestID)
+		return
+	}
+
+	updated, err := h.repo.UpdateStatus(r.Context(), db.ID, database.StatusUpdate{Status: "renaming", UpdatedBy: actorName(r)})
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to mark database as renaming", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to rename database", requestID)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "database.rename", db.ID.String(), rename.From+" -> "+rename.To)
+	slog.Info("database rename requested", "database", db.Name, "to", rename.To)
+
+	startOperation(w, r, h.ops, operation.TypeRename, updated, "Copying the database under its new name")
+	response.Success(w, http.StatusAccepted, toDatabaseResponse(updated), requestID)
+}
+
+// abandonRename deletes what was applied of a copy that could not be
+// requested, and releases the name its rename reserved.
+func (h *DatabaseHandler) abandonRename(r *http.Request, db *database.Database, p provider.Provider, target provider.ProviderDatabase) {
+	if err := p.Delete(r.Context(), target); err != nil {
+		slog.Error("failed to delete abandoned copy", "error", err, "database", db.Name, "to", target.Name)
+	}
+	if _, err := h.renames.ClearRename(r.Context(), db.ID); err != nil {
+		slog.Error("failed to clear rename", "error", err, "database", db.Name)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+)
+
+// ResizeEventHandler handles the GET /databases/{id}/resize-events endpoint.
+type ResizeEventHandler struct {
+	repo   database.Repository
+	events database.ResizeEventRepository
+}
+
+// NewResizeEventHandler creates a new ResizeEventHandler.
+func NewResizeEventHandler(repo database.Repository, events database.ResizeEventRepository) *ResizeEventHandler {
+	return &ResizeEventHandler{repo: repo, events: events}
+}
+
+type resizeEventResponse struct {
+	FromBytes int64  `json:"fromBytes"`
+	ToBytes   int64  `json:"toBytes"`
+	UsedBytes int64  `json:"usedBytes"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// ServeHTTP lists the storage resize events of a database, oldest first.
+func (h *ResizeEventHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	db, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to get database", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to get database", requestID)
+		return
+	}
+
+	// Product users: return 404 for non-owned databases (no info leakage)
+	if teamID, ok := isProductUser(r); ok && db.OwnerTeamID != *teamID {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return
+	}
+
+	events, err := h.events.ListByDatabase(r.Context(), id)
+	if err != nil {
+		slog.Error("failed to list resize events", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to list resize events", requestID)
+		return
+	}
+
+	items := make([]resizeEventResponse, len(events))
+	for i, e := range events {
+		items[i] = resizeEventResponse{
+			FromBytes: e.FromBytes,
+			ToBytes:   e.ToBytes,
+			UsedBytes: e.UsedBytes,
+			CreatedAt: e.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+	}
+	response.Success(w, http.StatusOK, items, requestID)
+}
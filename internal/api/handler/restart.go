@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// Restart handles POST /databases/{id}/restart. It asks the provider for a
+// rolling restart of the database's instances, e.g. to pick up parameters
+// that only apply on restart, and marks the database as restarting; the
+// reconciler returns it to ready once every instance has restarted.
+func (h *DatabaseHandler) Restart(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	if db.Status != "ready" {
+		response.Err(w, http.StatusConflict, "RESTART_NOT_POSSIBLE",
+			fmt.Sprintf("Database must be ready to restart (status is %s)", db.Status), requestID)
+		return
+	}
+
+	if frozen(w, r, h.freezes, db.OwnerTeamID, "restart", requestID) {
+		return
+	}
+
+	release, ok := lockDatabase(w, r, h.locker, db.ID, "restart", requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	p, pdb, ok := h.databaseProvider(w, r, db, "RESTART_NOT_POSSIBLE", requestID)
+	if !ok {
+		return
+	}
+	restarter, ok := p.(provider.Restarter)
+	if !ok {
+		response.Err(w, http.StatusConflict, "RESTART_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support restarts", pdb.Provider), requestID)
+		return
+	}
+
+	if err := restarter.Restart(r.Context(), pdb); err != nil {
+		if errors.Is(err, provider.ErrNotSupported) {
+			response.Err(w, http.StatusConflict, "RESTART_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support restarts", pdb.Provider), requestID)
+			return
+		}
+		slog.Error("provider.Restart failed", "error", err, "database", db.Name, "provider", pdb.Provider)
+		response.ServerErr(w, err, "Failed to restart database", requestID)
+		return
+	}
+
+	updated, err := h.repo.UpdateStatus(r.Context(), db.ID, database.StatusUpdate{Status: "restarting", UpdatedBy: actorName(r)})
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to mark database as restarting", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to restart database", requestID)
+		return
+	}
+	slog.Info("database restart requested", "database", db.Name)
+
+	startOperation(w, r, h.ops, operation.TypeRestart, updated, "Restarting the instances one at a time")
+	response.Success(w, http.StatusAccepted, toDatabaseResponse(updated), requestID)
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/provision"
+)
+
+// restoreDatabaseRequest is the request body for POST /databases/{id}/restore.
+type restoreDatabaseRequest struct {
+	Name       string  `json:"name"`
+	Backup     string  `json:"backup"`
+	TargetTime *string `json:"targetTime"`
+}
+
+// Restore handles POST /databases/{id}/restore. It creates a new database
+// from the backups of an existing one: the named backup, or the latest one,
+// recovered up to targetTime, or as far as the archived changes go. The new
+// database belongs to the same team, tier, namespace and environment as its
+// source, runs its source's images, and references it in sourceDatabaseId.
+// The provider recovers it inline; the reconciler completes its restore
+// operation once it is ready. A restore that cannot be requested is rolled
+// back, freeing the name.
+func (h *DatabaseHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	source, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req restoreDatabaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Backup = strings.TrimSpace(req.Backup)
+	fieldErrors := validation.ValidateRestoreRequest(validation.RestoreDatabaseRequest{
+		Name:       req.Name,
+		Backup:     req.Backup,
+		TargetTime: req.TargetTime,
+		Now:        time.Now(),
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+	target := provider.RestoreTarget{Backup: req.Backup}
+	if req.TargetTime != nil {
+		at, _ := time.Parse(time.RFC3339, *req.TargetTime)
+		target.Time = &at
+	}
+
+	switch source.Status {
+	case "provisioning", "renaming", "deprovisioning":
+		response.Err(w, http.StatusConflict, "RESTORE_NOT_POSSIBLE",
+			fmt.Sprintf("Database cannot be restored while it is %s", source.Status), requestID)
+		return
+	}
+
+	if frozen(w, r, h.freezes, source.OwnerTeamID, "restore", requestID) {
+		return
+	}
+	warnings, over := overQuota(w, r, h.quotas, source.OwnerTeamID, requestID)
+	if over {
+		return
+	}
+
+	if source.TierID == nil || h.registry == nil {
+		response.Err(w, http.StatusConflict, "RESTORE_NOT_POSSIBLE", "Database is not managed by a provider", requestID)
+		return
+	}
+	t, err := h.tierRepo.GetByID(r.Context(), *source.TierID)
+	if err != nil {
+		slog.Error("failed to resolve tier", "error", err, "database", source.Name)
+		response.ServerErr(w, err, "Failed to restore database", requestID)
+		return
+	}
+	if t.BlueprintID == nil {
+		response.Err(w, http.StatusConflict, "RESTORE_NOT_POSSIBLE", "Database is not managed by a provider", requestID)
+		return
+	}
+	if overBudget(w, r, h.budgets, source.OwnerTeamID, &t.ID, uuid.Nil, "Failed to restore database", requestID) {
+		return
+	}
+	bp, ok := tierBlueprint(w, r, h.bpRepo, h.signer, t, "Failed to restore database", requestID)
+	if !ok {
+		return
+	}
+	p, ok := h.registry.Get(bp.Provider)
+	if !ok {
+		response.Err(w, http.StatusConflict, "RESTORE_NOT_POSSIBLE", fmt.Sprintf("Provider %q is not registered", bp.Provider), requestID)
+		return
+	}
+	restorer, ok := p.(provider.Restorer)
+	if !ok {
+		response.Err(w, http.StatusConflict, "RESTORE_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support restores", bp.Provider), requestID)
+		return
+	}
+
+	db := &database.Database{
+		Name:             req.Name,
+		OwnerTeamID:      source.OwnerTeamID,
+		OwnerTeamName:    source.OwnerTeamName,
+		TierID:           &t.ID,
+		TierName:         t.Name,
+		Purpose:          source.Purpose,
+		Namespace:        source.Namespace,
+		Environment:      source.Environment,
+		SourceDatabaseID: &source.ID,
+		Images:           source.Images,
+		Exposure:         source.Exposure,
+		DNSName:          h.dnsZone.Hostname(req.Name),
+		QueryInsights:    source.QueryInsights,
+		CreatedBy:        actorName(r),
+
+		DataClassification: source.DataClassification,
+	}
+	if err := h.repo.Create(r.Context(), db); err != nil {
+		if errors.Is(err, database.ErrDuplicateName) {
+			response.Err(w, http.StatusConflict, "DUPLICATE_NAME", fmt.Sprintf("A database named %q already exists", req.Name), requestID)
+			return
+		}
+		slog.Error("failed to create database record", "error", err)
+		response.ServerErr(w, err, "Failed to restore database", requestID)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "database.restore", source.ID.String(), source.Name+" -> "+db.Name)
+
+	op := startOperation(w, r, h.ops, operation.TypeRestore, db, "Restoring the database")
+
+	pdb := db.ProviderDatabase(t, bp)
+	if err := restorer.Restore(r.Context(), pdb, source.ProviderDatabase(t, bp), bp.Manifests, target); err != nil {
+		outcome := "the database was rolled back"
+		if !provision.Fail(r.Context(), h.repo, db, p, pdb, true) {
+			outcome = "the database was kept in error"
+		}
+		switch {
+		case errors.Is(err, provider.ErrBackupNotFound):
+			h.ops.Fail(r.Context(), op, "BACKUP_NOT_FOUND", fmt.Sprintf("No backup of %s to restore from: %v", source.Name, err))
+			response.Err(w, http.StatusNotFound, "BACKUP_NOT_FOUND", fmt.Sprintf("No backup of %s to restore from", source.Name), requestID)
+		case errors.Is(err, provider.ErrNotSupported):
+			h.ops.Fail(r.Context(), op, "RESTORE_NOT_POSSIBLE", fmt.Sprintf("Provider %q cannot restore this database", bp.Provider))
+			response.Err(w, http.StatusConflict, "RESTORE_NOT_POSSIBLE", fmt.Sprintf("Provider %q cannot restore this database", bp.Provider), requestID)
+		default:
+			slog.Error("provider.Restore failed", "error", err, "database", db.Name, "source", source.Name, "provider", bp.Provider)
+			h.ops.Fail(r.Context(), op, "RESTORE_FAILED", fmt.Sprintf("Restoring %s failed: %v; %s", source.Name, err, outcome))
+			response.Err(w, http.StatusBadGateway, "RESTORE_FAILED", fmt.Sprintf("Restoring %s failed; %s", source.Name, outcome), requestID)
+		}
+		return
+	}
+	database.RecordSpec(r.Context(), h.specs, db, t, bp)
+	h.ops.Progress(r.Context(), op, 50, "Waiting for the database to become ready")
+	slog.Info("database restore requested", "database", db.Name, "source", source.Name,
+		"backup", target.Backup, "targetTime", req.TargetTime)
+
+	w.Header().Set("Location", "/databases/"+db.ID.String())
+	response.SuccessWithWarnings(w, http.StatusCreated, toDatabaseResponse(db), warnings, requestID)
+}
@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/retention"
+)
+
+// RetentionReporter reports what the next purge of soft-deleted databases
+// will remove; *retention.Purger implements it.
+type RetentionReporter interface {
+	Report(ctx context.Context) (*retention.Report, error)
+}
+
+// RetentionHandler handles GET /admin/retention.
+type RetentionHandler struct {
+	reporter RetentionReporter
+}
+
+// NewRetentionHandler creates a new RetentionHandler.
+func NewRetentionHandler(reporter RetentionReporter) *RetentionHandler {
+	return &RetentionHandler{reporter: reporter}
+}
+
+type retentionResponse struct {
+	RetentionDays   float64                   `json:"retentionDays"`
+	IntervalSeconds float64                   `json:"intervalSeconds"`
+	NextRunAt       string                    `json:"nextRunAt"`
+	Cutoff          string                    `json:"cutoff"`
+	LastRunAt       *string                   `json:"lastRunAt"`
+	LastPurged      int                       `json:"lastPurged"`
+	Purgeable       []deletedDatabaseResponse `json:"purgeable"`
+	Held            []deletedDatabaseResponse `json:"held"`
+}
+
+type deletedDatabaseResponse struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	OwnerTeam          string `json:"ownerTeam"`
+	DataClassification string `json:"dataClassification"`
+	DeletedAt          string `json:"deletedAt"`
+}
+
+// Get handles GET /admin/retention.
+func (h *RetentionHandler) Get(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	report, err := h.reporter.Report(r.Context())
+	if err != nil {
+		response.ServerErr(w, err, "Failed to report the retention purge", requestID)
+		return
+	}
+
+	resp := retentionResponse{
+		RetentionDays:   report.Retention.Hours() / 24,
+		IntervalSeconds: report.Interval.Seconds(),
+		NextRunAt:       report.NextRunAt.UTC().Format(time.RFC3339),
+		Cutoff:          report.Cutoff.UTC().Format(time.RFC3339),
+		LastPurged:      report.LastPurged,
+		Purgeable:       toDeletedDatabaseResponses(report.Purgeable),
+		Held:            toDeletedDatabaseResponses(report.Held),
+	}
+	if report.LastRunAt != nil {
+		at := report.LastRunAt.UTC().Format(time.RFC3339)
+		resp.LastRunAt = &at
+	}
+	response.Success(w, http.StatusOK, resp, requestID)
+}
+
+func toDeletedDatabaseResponses(dbs []database.DeletedDatabase) []deletedDatabaseResponse {
+	out := make([]deletedDatabaseResponse, 0, len(dbs))
+	for _, d := range dbs {
+		out = append(out, deletedDatabaseResponse{
+			ID:                 d.ID.String(),
+			Name:               d.Name,
+			OwnerTeam:          d.OwnerTeamName,
+			DataClassification: d.DataClassification,
+			DeletedAt:          d.DeletedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	return out
+}
@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+)
+
+// Review handles POST /databases/{id}/review. It clears the needsReview
+// condition of a database once a platform engineer has checked it. For a
+// database flagged because its operator version changed, the recorded version
+// is cleared too, so the reconciler records the current one on its next pass.
+func (h *DatabaseHandler) Review(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	db, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to get database for review", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to review database", requestID)
+		return
+	}
+	middleware.SetAuditClassification(r.Context(), db.DataClassification)
+
+	flagged := db.Condition(database.ConditionNeedsReview)
+	if flagged == nil {
+		response.Err(w, http.StatusConflict, "REVIEW_NOT_NEEDED", "Database does not need review", requestID)
+		return
+	}
+
+	su := database.StatusUpdate{
+		Status:     db.Status,
+		Conditions: db.WithoutCondition(database.ConditionNeedsReview),
+		UpdatedBy:  actorName(r),
+	}
+	if db.StatusReason != nil {
+		su.Reason = *db.StatusReason
+	}
+	if db.StatusMessage != nil {
+		su.Message = *db.StatusMessage
+	}
+	if flagged.Reason == database.ReasonOperatorVersionChanged {
+		none := ""
+		su.OperatorVersion = &none
+	}
+	updated, err := h.repo.UpdateStatus(r.Context(), id, su)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to mark database as reviewed", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to review database", requestID)
+		return
+	}
+
+	var actor string
+	if identity := middleware.GetIdentity(r.Context()); identity != nil {
+		actor = identity.UserName
+	}
+	slog.Info("database reviewed", "database", db.Name, "reason", flagged.Reason, "actor", actor)
+
+	response.Success(w, http.StatusOK, toDatabaseResponse(updated), requestID)
+}
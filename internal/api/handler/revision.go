@@ -0,0 +1,138 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/revision"
+)
+
+// RevisionHandler handles the GET /databases/{id}/revisions and
+// GET /tiers/{id}/revisions endpoints.
+type RevisionHandler struct {
+	revisions revision.Repository
+}
+
+// NewRevisionHandler creates a new RevisionHandler.
+func NewRevisionHandler(revisions revision.Repository) *RevisionHandler {
+	return &RevisionHandler{revisions: revisions}
+}
+
+type revisionChangeResponse struct {
+	Field string `json:"field"`
+	From  any    `json:"from"`
+	To    any    `json:"to"`
+}
+
+type revisionResponse struct {
+	Revision  int                      `json:"revision"`
+	Operation string                   `json:"operation"`
+	ChangedBy string                   `json:"changedBy"`
+	ChangedAt string                   `json:"changedAt"`
+	Snapshot  map[string]any           `json:"snapshot"`
+	Changes   []revisionChangeResponse `json:"changes"`
+}
+
+// Database lists the revisions of a database, oldest first, including those
+// of a deleted database. Product users only see the revisions of databases
+// their team owns, or last owned if deleted.
+func (h *RevisionHandler) Database(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	revisions, ok := h.list(w, r, revision.KindDatabase, "Database not found", requestID)
+	if !ok {
+		return
+	}
+
+	// Product users: return 404 for non-owned databases (no info leakage)
+	latest := revisions[len(revisions)-1].Snapshot
+	if teamID, ok := isProductUser(r); ok && latest["owner_team_id"] != teamID.String() {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+		return
+	}
+	if classification, ok := latest["data_classification"].(string); ok {
+		middleware.SetAuditClassification(r.Context(), classification)
+	}
+
+	response.Success(w, http.StatusOK, toRevisionResponses(revisions), requestID)
+}
+
+// Tier lists the revisions of a tier, oldest first, including those of a
+// deleted tier.
+func (h *RevisionHandler) Tier(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	revisions, ok := h.list(w, r, revision.KindTier, "Tier not found", requestID)
+	if !ok {
+		return
+	}
+	response.Success(w, http.StatusOK, toRevisionResponses(revisions), requestID)
+}
+
+// list reads the revisions of the resource in the id URL parameter. A
+// resource without revisions never existed, and is reported as not found.
+func (h *RevisionHandler) list(w http.ResponseWriter, r *http.Request, kind, notFound, requestID string) ([]revision.Revision, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return nil, false
+	}
+
+	revisions, err := h.revisions.List(r.Context(), kind, id)
+	if err != nil {
+		slog.Error("failed to list revisions", "error", err, "kind", kind, "id", id)
+		response.ServerErr(w, err, "Failed to list revisions", requestID)
+		return nil, false
+	}
+	if len(revisions) == 0 {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", notFound, requestID)
+		return nil, false
+	}
+	return revisions, true
+}
+
+// toRevisionResponses converts revisions for the API, each with its changes
+// from the one before. Column names become camelCase, like the fields of the
+// resources themselves.
+func toRevisionResponses(revisions []revision.Revision) []revisionResponse {
+	items := make([]revisionResponse, len(revisions))
+	var prev map[string]any
+	for i, rev := range revisions {
+		snapshot := make(map[string]any, len(rev.Snapshot))
+		for column, value := range rev.Snapshot {
+			snapshot[camelCase(column)] = value
+		}
+		changes := []revisionChangeResponse{}
+		for _, c := range revision.Diff(prev, rev.Snapshot) {
+			changes = append(changes, revisionChangeResponse{Field: camelCase(c.Field), From: c.From, To: c.To})
+		}
+		items[i] = revisionResponse{
+			Revision:  rev.Number,
+			Operation: rev.Operation,
+			ChangedBy: rev.ChangedBy,
+			ChangedAt: rev.ChangedAt.UTC().Format("2006-01-02T15:04:05Z"),
+			Snapshot:  snapshot,
+			Changes:   changes,
+		}
+		prev = rev.Snapshot
+	}
+	return items
+}
+
+// camelCase converts a snake_case column name, such as owner_team_id, to
+// camelCase: ownerTeamId.
+func camelCase(column string) string {
+	parts := strings.Split(column, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// RolloutController starts and steers blueprint rollouts.
+type RolloutController interface {
+	Active(ctx context.Context, tierID uuid.UUID) (*rollout.Rollout, error)
+	Begin(ctx context.Context, t *tier.Tier, from uuid.UUID) (*rollout.Rollout, error)
+	Pause(ctx context.Context, id uuid.UUID) (*rollout.Rollout, error)
+	Resume(ctx context.Context, id uuid.UUID) (*rollout.Rollout, error)
+	Rollback(ctx context.Context, id uuid.UUID) (*rollout.Rollout, error)
+}
+
+// RolloutHandler handles the /rollouts endpoints.
+type RolloutHandler struct {
+	repo       rollout.Repository
+	tierRepo   tier.Repository
+	controller RolloutController
+}
+
+// NewRolloutHandler creates a new RolloutHandler.
+func NewRolloutHandler(repo rollout.Repository, tierRepo tier.Repository, controller RolloutController) *RolloutHandler {
+	return &RolloutHandler{repo: repo, tierRepo: tierRepo, controller: controller}
+}
+
+type rolloutTargetResponse struct {
+	DatabaseID string  `json:"databaseId"`
+	Database   string  `json:"database"`
+	Batch      int     `json:"batch"`
+	Status     string  `json:"status"`
+	Error      *string `json:"error"`
+	AppliedAt  *string `json:"appliedAt"`
+}
+
+type rolloutResponse struct {
+	ID            string                   `json:"id"`
+	TierID        string                   `json:"tierId"`
+	Tier          string                   `json:"tier"`
+	FromBlueprint string                   `json:"fromBlueprint"`
+	ToBlueprint   string                   `json:"toBlueprint"`
+	Status        string                   `json:"status"`
+	CurrentBatch  int                      `json:"currentBatch"`
+	BatchCount    int                      `json:"batchCount"`
+	Error         *string                  `json:"error"`
+	Targets       *[]rolloutTargetResponse `json:"targets,omitempty"`
+	CreatedAt     string                   `json:"createdAt"`
+	UpdatedAt     string                   `json:"updatedAt"`
+}
+
+func toRolloutResponse(r *rollout.Rollout) rolloutResponse {
+	resp := rolloutResponse{
+		ID:            r.ID.String(),
+		TierID:        r.TierID.String(),
+		Tier:          r.TierName,
+		FromBlueprint: r.FromBlueprintName,
+		ToBlueprint:   r.ToBlueprintName,
+		Status:        r.Status,
+		CurrentBatch:  r.CurrentBatch,
+		BatchCount:    r.BatchCount,
+		CreatedAt:     r.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:     r.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if r.Error != "" {
+		resp.Error = &r.Error
+	}
+	return resp
+}
+
+func toRolloutTargetResponse(t rollout.Target) rolloutTargetResponse {
+	resp := rolloutTargetResponse{
+		DatabaseID: t.DatabaseID.String(),
+		Database:   t.DatabaseName,
+		Batch:      t.Batch,
+		Status:     t.Status,
+	}
+	if t.Error != "" {
+		resp.Error = &t.Error
+	}
+	if t.AppliedAt != nil {
+		s := t.AppliedAt.UTC().Format(time.RFC3339)
+		resp.AppliedAt = &s
+	}
+	return resp
+}
+
+// List handles GET /rollouts.
+func (h *RolloutHandler) List(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var filter rollout.ListFilter
+	if v := r.URL.Query().Get("tier"); v != "" {
+		t, err := h.tierRepo.GetByName(r.Context(), v)
+		if err != nil {
+			if errors.Is(err, tier.ErrTierNotFound) {
+				response.Success(w, http.StatusOK, []rolloutResponse{}, requestID)
+				return
+			}
+			slog.Error("failed to look up tier for filter", "error", err)
+			response.ServerErr(w, err, "Failed to list rollouts", requestID)
+			return
+		}
+		filter.TierID = &t.ID
+	}
+	if v := r.URL.Query().Get("status"); v != "" {
+		filter.Statuses = []string{v}
+	}
+
+	rollouts, err := h.repo.List(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list rollouts", "error", err)
+		response.ServerErr(w, err, "Failed to list rollouts", requestID)
+		return
+	}
+
+	items := make([]rolloutResponse, len(rollouts))
+	for i := range rollouts {
+		items[i] = toRolloutResponse(&rollouts[i])
+	}
+	response.Success(w, http.StatusOK, items, requestID)
+}
+
+// GetByID handles GET /rollouts/{id}.
+func (h *RolloutHandler) GetByID(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	ro, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, rollout.ErrRolloutNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Rollout not found", requestID)
+			return
+		}
+		slog.Error("failed to get rollout", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to get rollout", requestID)
+		return
+	}
+	h.respondWithTargets(w, r, ro, requestID)
+}
+
+// Pause handles POST /rollouts/{id}/pause.
+func (h *RolloutHandler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "pause", h.controller.Pause)
+}
+
+// Resume handles POST /rollouts/{id}/resume.
+func (h *RolloutHandler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "resume", h.controller.Resume)
+}
+
+// Rollback handles POST /rollouts/{id}/rollback.
+func (h *RolloutHandler) Rollback(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "roll back", h.controller.Rollback)
+}
+
+func (h *RolloutHandler) transition(w http.ResponseWriter, r *http.Request, action string, fn func(context.Context, uuid.UUID) (*rollout.Rollout, error)) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	ro, err := fn(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, rollout.ErrRolloutNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Rollout not found", requestID)
+			return
+		}
+		if errors.Is(err, rollout.ErrInvalidTransition) {
+			msg := fmt.Sprintf("Cannot %s this rollout in its current status", action)
+			if current, err := h.repo.GetByID(r.Context(), id); err == nil {
+				msg = fmt.Sprintf("Cannot %s a rollout that is %s", action, current.Status)
+			}
+			response.Err(w, http.StatusConflict, "INVALID_TRANSITION", msg, requestID)
+			return
+		}
+		slog.Error("failed to update rollout", "error", err, "id", id, "action", action)
+		response.ServerErr(w, err, "Failed to update rollout", requestID)
+		return
+	}
+	h.respondWithTargets(w, r, ro, requestID)
+}
+
+func (h *RolloutHandler) respondWithTargets(w http.ResponseWriter, r *http.Request, ro *rollout.Rollout, requestID string) {
+	targets, err := h.repo.ListTargets(r.Context(), ro.ID)
+	if err != nil {
+		slog.Error("failed to list rollout targets", "error", err, "id", ro.ID)
+		response.ServerErr(w, err, "Failed to get rollout", requestID)
+		return
+	}
+	resp := toRolloutResponse(ro)
+	items := make([]rolloutTargetResponse, len(targets))
+	for i, t := range targets {
+		items[i] = toRolloutTargetResponse(t)
+	}
+	resp.Targets = &items
+	response.Success(w, http.StatusOK, resp, requestID)
+}
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// SpecHandler handles the GET /databases/{id}/spec-diff endpoint.
+type SpecHandler struct {
+	repo     database.Repository
+	tierRepo tier.Repository
+	bpRepo   blueprint.Repository
+	specs    database.SpecRepository
+}
+
+// NewSpecHandler creates a new SpecHandler.
+func NewSpecHandler(repo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, specs database.SpecRepository) *SpecHandler {
+	return &SpecHandler{repo: repo, tierRepo: tierRepo, bpRepo: bpRepo, specs: specs}
+}
+
+type specTierResponse struct {
+	ID                  string                      `json:"id"`
+	Name                string                      `json:"name"`
+	DestructionStrategy string                      `json:"destructionStrategy,omitempty"`
+	BackupEnabled       *bool                       `json:"backupEnabled,omitempty"`
+	StorageAutoscaling  *storageAutoscalingResponse `json:"storageAutoscaling,omitempty"`
+	Topology            *topologyResponse           `json:"topology,omitempty"`
+	Disruption          *disruptionResponse         `json:"disruption,omitempty"`
+}
+
+type specBlueprintResponse struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	Manifests string `json:"manifests,omitempty"`
+}
+
+type specResponse struct {
+	Tier      specTierResponse      `json:"tier"`
+	Blueprint specBlueprintResponse `json:"blueprint"`
+	AppliedAt string                `json:"appliedAt,omitempty"`
+}
+
+type specChangeResponse struct {
+	Field   string `json:"field"`
+	Applied any    `json:"applied,omitempty"`
+	Current any    `json:"current,omitempty"`
+}
+
+type specDiffResponse struct {
+	Applied       *specResponse        `json:"applied"`
+	Current       *specResponse        `json:"current"`
+	Pending       bool                 `json:"pending"`
+	Changes       []specChangeResponse `json:"changes"`
+	ManifestsDiff string               `json:"manifestsDiff,omitempty"`
+}
+
+// toSpecResponse converts a spec for the API. Redacted specs, for product
+// users, keep only the names of the tier and blueprint, like the redacted
+// tier and blueprint representations.
+func toSpecResponse(s *database.Spec, redacted bool) *specResponse {
+	resp := &specResponse{
+		Tier:      specTierResponse{ID: s.TierID.String(), Name: s.TierName},
+		Blueprint: specBlueprintResponse{ID: s.BlueprintID.String(), Name: s.BlueprintName, Provider: s.Provider},
+	}
+	if !s.AppliedAt.IsZero() {
+		resp.AppliedAt = s.AppliedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if redacted {
+		return resp
+	}
+	backup := s.BackupEnabled
+	resp.Tier.DestructionStrategy = s.DestructionStrategy
+	resp.Tier.BackupEnabled = &backup
+	resp.Tier.StorageAutoscaling = &storageAutoscalingResponse{
+		Enabled:          s.StorageAutoscaling.Enabled,
+		ThresholdPercent: s.StorageAutoscaling.ThresholdPercent,
+		IncrementPercent: s.StorageAutoscaling.IncrementPercent,
+		MaxSize:          s.StorageAutoscaling.MaxSize,
+	}
+	topology := topologyResponse(s.Topology)
+	resp.Tier.Topology = &topology
+	disruption := disruptionResponse(s.Disruption)
+	resp.Tier.Disruption = &disruption
+	resp.Blueprint.Manifests = s.Manifests
+	return resp
+}
+
+// ServeHTTP compares the spec a database was last applied with to the one
+// its tier and the tier's blueprint resolve to now, showing what re-applying
+// would change. A database applied before specs were recorded has no applied
+// spec; one without a tier or blueprint has no current spec.
+func (h *SpecHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	applied, err := h.specs.Get(r.Context(), db.ID)
+	if err != nil && !errors.Is(err, database.ErrSpecNotFound) {
+		slog.Error("failed to get database spec", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to get database spec", requestID)
+		return
+	}
+	current, err := h.currentSpec(r.Context(), db)
+	if err != nil {
+		slog.Error("failed to resolve current database spec", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to resolve database spec", requestID)
+		return
+	}
+
+	_, redacted := isProductUser(r)
+	resp := specDiffResponse{Changes: []specChangeResponse{}}
+	if applied != nil {
+		resp.Applied = toSpecResponse(applied, redacted)
+	}
+	if current != nil {
+		resp.Current = toSpecResponse(current, redacted)
+	}
+	if applied != nil && current != nil {
+		for _, c := range applied.Diff(current) {
+			change := specChangeResponse{Field: c.Field}
+			if !redacted {
+				change.Applied, change.Current = c.Applied, c.Current
+			}
+			resp.Changes = append(resp.Changes, change)
+		}
+		resp.Pending = len(resp.Changes) > 0
+		if !redacted {
+			resp.ManifestsDiff = applied.ManifestsDiff(current)
+		}
+	}
+	response.Success(w, http.StatusOK, resp, requestID)
+}
+
+// currentSpec resolves the spec db would be applied with now, or nil if it
+// has no tier or the tier has no blueprint.
+func (h *SpecHandler) currentSpec(ctx context.Context, db *database.Database) (*database.Spec, error) {
+	if db.TierID == nil {
+		return nil, nil
+	}
+	t, err := h.tierRepo.GetByID(ctx, *db.TierID)
+	if err != nil {
+		if errors.Is(err, tier.ErrTierNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if t.BlueprintID == nil {
+		return nil, nil
+	}
+	bp, err := h.bpRepo.GetByID(ctx, *t.BlueprintID)
+	if err != nil {
+		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return database.NewSpec(db.ID, t, bp), nil
+}
@@ -0,0 +1,150 @@
+package handler
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+)
+
+// StatsHandler handles the GET /stats endpoints.
+type StatsHandler struct {
+	stats           database.StatsReader
+	provisioningSLO time.Duration
+}
+
+// NewStatsHandler creates a new StatsHandler. provisioningSLO is used to flag
+// provisioning durations that exceeded it; zero disables the flag.
+func NewStatsHandler(stats database.StatsReader, provisioningSLO time.Duration) *StatsHandler {
+	return &StatsHandler{stats: stats, provisioningSLO: provisioningSLO}
+}
+
+type durationStatsResponse struct {
+	Count      int      `json:"count"`
+	P50Seconds *float64 `json:"p50Seconds"`
+	P95Seconds *float64 `json:"p95Seconds"`
+}
+
+type statsResponse struct {
+	Total                int                   `json:"total"`
+	ByStatus             map[string]int        `json:"byStatus"`
+	ByTier               map[string]int        `json:"byTier"`
+	ByTeam               map[string]int        `json:"byTeam"`
+	ProvisioningDuration durationStatsResponse `json:"provisioningDuration"`
+}
+
+type provisioningDurationResponse struct {
+	DatabaseID      string  `json:"databaseId"`
+	Name            string  `json:"name"`
+	OwnerTeam       string  `json:"ownerTeam"`
+	Tier            string  `json:"tier,omitempty"`
+	CreatedAt       string  `json:"createdAt"`
+	ReadyAt         string  `json:"readyAt"`
+	DurationSeconds float64 `json:"durationSeconds"`
+	ExceededSLO     *bool   `json:"exceededSlo,omitempty"`
+}
+
+func (h *StatsHandler) toProvisioningDurationResponse(pd *database.ProvisioningDuration) provisioningDurationResponse {
+	resp := provisioningDurationResponse{
+		DatabaseID:      pd.DatabaseID.String(),
+		Name:            pd.Name,
+		OwnerTeam:       pd.OwnerTeamName,
+		Tier:            pd.TierName,
+		CreatedAt:       pd.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		ReadyAt:         pd.ReadyAt.UTC().Format("2006-01-02T15:04:05Z"),
+		DurationSeconds: roundSeconds(pd.Duration),
+	}
+	if h.provisioningSLO > 0 {
+		exceeded := pd.Duration > h.provisioningSLO
+		resp.ExceededSLO = &exceeded
+	}
+	return resp
+}
+
+func toDurationStatsResponse(d database.DurationStats) durationStatsResponse {
+	resp := durationStatsResponse{Count: d.Count}
+	if d.Count > 0 {
+		p50 := roundSeconds(d.P50)
+		p95 := roundSeconds(d.P95)
+		resp.P50Seconds = &p50
+		resp.P95Seconds = &p95
+	}
+	return resp
+}
+
+// roundSeconds converts d to seconds with millisecond precision.
+func roundSeconds(d time.Duration) float64 {
+	return float64(d.Round(time.Millisecond).Milliseconds()) / 1000
+}
+
+// ServeHTTP returns aggregate database statistics. Product users only see
+// their own team's databases.
+func (h *StatsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	var filter database.StatsFilter
+	if teamID, ok := isProductUser(r); ok {
+		filter.OwnerTeamID = teamID
+	}
+
+	stats, err := h.stats.Stats(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to compute database stats", "error", err)
+		response.ServerErr(w, err, "Failed to compute stats", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, statsResponse{
+		Total:                stats.Total,
+		ByStatus:             stats.ByStatus,
+		ByTier:               stats.ByTier,
+		ByTeam:               stats.ByTeam,
+		ProvisioningDuration: toDurationStatsResponse(stats.Provisioning),
+	}, requestID)
+}
+
+// ProvisioningDurations handles GET /stats/provisioning-durations. It lists
+// the time each database took from creation to first becoming ready, most
+// recently ready first. Product users only see their own team's databases.
+func (h *StatsHandler) ProvisioningDurations(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	filter := database.ProvisioningDurationFilter{Page: 1, Limit: 20}
+	if teamID, ok := isProductUser(r); ok {
+		filter.OwnerTeamID = teamID
+	}
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			response.Err(w, http.StatusBadRequest, "INVALID_PARAM", "page must be a positive integer", requestID)
+			return
+		}
+		filter.Page = page
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			response.Err(w, http.StatusBadRequest, "INVALID_PARAM", "limit must be a positive integer", requestID)
+			return
+		}
+		filter.Limit = limit
+	}
+
+	result, err := h.stats.ProvisioningDurations(r.Context(), filter)
+	if err != nil {
+		slog.Error("failed to list provisioning durations", "error", err)
+		response.ServerErr(w, err, "Failed to list provisioning durations", requestID)
+		return
+	}
+
+	items := make([]provisioningDurationResponse, 0, len(result.Items))
+	for i := range result.Items {
+		items = append(items, h.toProvisioningDurationResponse(&result.Items[i]))
+	}
+
+	response.SuccessList(w, http.StatusOK, items, result.Total, result.Page, result.Limit, requestID)
+}
@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+)
+
+// SupportBundler writes a support bundle about a database.
+type SupportBundler interface {
+	Write(ctx context.Context, w io.Writer, db *database.Database) error
+}
+
+// SupportBundleHandler handles the GET /databases/{id}/support-bundle endpoint.
+type SupportBundleHandler struct {
+	repo    database.Repository
+	bundler SupportBundler
+}
+
+// NewSupportBundleHandler creates a new SupportBundleHandler.
+func NewSupportBundleHandler(repo database.Repository, bundler SupportBundler) *SupportBundleHandler {
+	return &SupportBundleHandler{repo: repo, bundler: bundler}
+}
+
+// ServeHTTP streams a gzip-compressed tarball gathering what is needed to
+// investigate a database, to attach to vendor tickets. What could not be
+// gathered is listed in the bundle rather than failing the request. The bundle
+// is built in memory first so a failure still returns a JSON error envelope.
+func (h *SupportBundleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	db, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to get database for support bundle", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to build support bundle", requestID)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := h.bundler.Write(r.Context(), &buf, db); err != nil {
+		slog.Error("failed to build support bundle", "error", err, "database", db.Name)
+		response.ServerErr(w, err, "Failed to build support bundle", requestID)
+		return
+	}
+	slog.Info("support bundle", "database", db.Name, "bytes", buf.Len())
+
+	filename := fmt.Sprintf("daap-support-%s-%s.tar.gz", db.Name, time.Now().UTC().Format("20060102T150405Z"))
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.Header().Set("Content-Length", strconv.Itoa(buf.Len()))
+	w.WriteHeader(http.StatusOK)
+	_, _ = buf.WriteTo(w)
+}
@@ -14,40 +14,82 @@ import (
 	"github.com/daap14/daap/internal/api/middleware"
 	"github.com/daap14/daap/internal/api/response"
 	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/budget"
+	"github.com/daap14/daap/internal/organization"
 	"github.com/daap14/daap/internal/team"
 )
 
 type createTeamRequest struct {
-	Name string `json:"name"`
-	Role string `json:"role"`
+	Name            string            `json:"name"`
+	Role            string            `json:"role"`
+	OrganizationID  string            `json:"organizationId"`
+	Labels          map[string]string `json:"labels"`
+	Annotations     map[string]string `json:"annotations"`
+	MaxConnections  int               `json:"maxConnections"`
+	ArchiveLocation string            `json:"archiveLocation"`
+}
+
+type updateTeamRequest struct {
+	OrganizationID  string            `json:"organizationId"`
+	Labels          map[string]string `json:"labels"`
+	Annotations     map[string]string `json:"annotations"`
+	MaxConnections  *int              `json:"maxConnections"`
+	ArchiveLocation *string           `json:"archiveLocation"`
 }
 
 type teamResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	Role      string `json:"role"`
-	CreatedAt string `json:"createdAt"`
-	UpdatedAt string `json:"updatedAt"`
+	ID              string            `json:"id"`
+	Name            string            `json:"name"`
+	Role            string            `json:"role"`
+	OrganizationID  *string           `json:"organizationId,omitempty"`
+	Labels          map[string]string `json:"labels"`
+	Annotations     map[string]string `json:"annotations"`
+	MaxConnections  int               `json:"maxConnections"`
+	ArchiveLocation string            `json:"archiveLocation"`
+	CreatedBy       string            `json:"createdBy"`
+	UpdatedBy       string            `json:"updatedBy"`
+	CreatedAt       string            `json:"createdAt"`
+	UpdatedAt       string            `json:"updatedAt"`
 }
 
 func toTeamResponse(t *team.Team) teamResponse {
-	return teamResponse{
-		ID:        t.ID.String(),
-		Name:      t.Name,
-		Role:      t.Role,
-		CreatedAt: t.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
-		UpdatedAt: t.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	resp := teamResponse{
+		ID:              t.ID.String(),
+		Name:            t.Name,
+		Role:            t.Role,
+		Labels:          t.Labels,
+		Annotations:     t.Annotations,
+		MaxConnections:  t.MaxConnections,
+		ArchiveLocation: t.ArchiveLocation,
+		CreatedBy:       t.CreatedBy,
+		UpdatedBy:       t.UpdatedBy,
+		CreatedAt:       t.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:       t.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+	}
+	if t.OrganizationID != nil {
+		orgID := t.OrganizationID.String()
+		resp.OrganizationID = &orgID
 	}
+	if resp.Labels == nil {
+		resp.Labels = map[string]string{}
+	}
+	if resp.Annotations == nil {
+		resp.Annotations = map[string]string{}
+	}
+	return resp
 }
 
-// TeamHandler handles team CRUD endpoints.
+// TeamHandler handles team CRUD endpoints. Organization superadmins manage
+// only the teams of their organization; other teams are not found.
 type TeamHandler struct {
 	repo team.Repository
+	orgs organization.Repository
 }
 
-// NewTeamHandler creates a new TeamHandler.
-func NewTeamHandler(repo team.Repository) *TeamHandler {
-	return &TeamHandler{repo: repo}
+// NewTeamHandler creates a new TeamHandler. Teams can be placed in the
+// organizations of orgs; a nil orgs rejects organizationId.
+func NewTeamHandler(repo team.Repository, orgs organization.Repository) *TeamHandler {
+	return &TeamHandler{repo: repo, orgs: orgs}
 }
 
 // Create handles POST /teams.
@@ -62,8 +104,13 @@ func (h *TeamHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fieldErrors := validation.ValidateCreateTeamRequest(validation.CreateTeamRequest{
-		Name: req.Name,
-		Role: req.Role,
+		Name:            req.Name,
+		Role:            req.Role,
+		OrganizationID:  req.OrganizationID,
+		Labels:          req.Labels,
+		Annotations:     req.Annotations,
+		MaxConnections:  &req.MaxConnections,
+		ArchiveLocation: &req.ArchiveLocation,
 	})
 	if len(fieldErrors) > 0 {
 		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
@@ -72,9 +119,31 @@ func (h *TeamHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	req.Name = strings.TrimSpace(req.Name)
 
+	// Organization superadmins create teams in their organization, without
+	// a connection budget: budgets protect the whole cluster.
+	if adminOf, ok := orgAdminOf(r); ok {
+		if req.MaxConnections != 0 {
+			response.Err(w, http.StatusForbidden, "FORBIDDEN", "Superuser access required to set connection budgets", requestID)
+			return
+		}
+		if req.OrganizationID == "" {
+			req.OrganizationID = adminOf.String()
+		}
+	}
+	orgID, ok := h.organization(w, r, req.OrganizationID, "Failed to create team", requestID)
+	if !ok {
+		return
+	}
+
 	t := &team.Team{
-		Name: req.Name,
-		Role: req.Role,
+		Name:            req.Name,
+		Role:            req.Role,
+		OrganizationID:  orgID,
+		Labels:          req.Labels,
+		Annotations:     req.Annotations,
+		MaxConnections:  req.MaxConnections,
+		ArchiveLocation: req.ArchiveLocation,
+		CreatedBy:       actorName(r),
 	}
 
 	if err := h.repo.Create(r.Context(), t); err != nil {
@@ -83,7 +152,7 @@ func (h *TeamHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to create team", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create team", requestID)
+		response.ServerErr(w, err, "Failed to create team", requestID)
 		return
 	}
 
@@ -97,18 +166,112 @@ func (h *TeamHandler) List(w http.ResponseWriter, r *http.Request) {
 	teams, err := h.repo.List(r.Context())
 	if err != nil {
 		slog.Error("failed to list teams", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list teams", requestID)
+		response.ServerErr(w, err, "Failed to list teams", requestID)
 		return
 	}
 
 	items := make([]teamResponse, 0, len(teams))
 	for i := range teams {
-		items = append(items, toTeamResponse(&teams[i]))
+		if manages(r, &teams[i]) {
+			items = append(items, toTeamResponse(&teams[i]))
+		}
 	}
 
 	response.SuccessList(w, http.StatusOK, items, len(items), 1, 100, requestID)
 }
 
+// GetByName handles GET /teams/by-name/{name}.
+func (h *TeamHandler) GetByName(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	t, err := h.repo.GetByName(r.Context(), chi.URLParam(r, "name"))
+	if err == nil && !manages(r, t) {
+		err = team.ErrTeamNotFound
+	}
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+			return
+		}
+		slog.Error("failed to get team", "error", err)
+		response.ServerErr(w, err, "Failed to get team", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, toTeamResponse(t), requestID)
+}
+
+// Update handles PATCH /teams/{id}. It replaces the team's default labels
+// and annotations, each only when present in the body; the team's databases
+// get them the next time their resources are applied. organizationId moves
+// the team into an organization; only the superuser may move teams.
+func (h *TeamHandler) Update(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req updateTeamRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+
+	fieldErrors := validation.ValidateUpdateTeamRequest(validation.UpdateTeamRequest{
+		OrganizationID:  req.OrganizationID,
+		Labels:          req.Labels,
+		Annotations:     req.Annotations,
+		MaxConnections:  req.MaxConnections,
+		ArchiveLocation: req.ArchiveLocation,
+	})
+	if len(fieldErrors) > 0 {
+		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
+		return
+	}
+
+	if _, isAdmin := orgAdminOf(r); isAdmin {
+		if req.OrganizationID != "" {
+			response.Err(w, http.StatusForbidden, "FORBIDDEN", "Superuser access required to move teams between organizations", requestID)
+			return
+		}
+		if req.MaxConnections != nil {
+			response.Err(w, http.StatusForbidden, "FORBIDDEN", "Superuser access required to set connection budgets", requestID)
+			return
+		}
+		if !h.managedTeam(w, r, id, "Failed to update team", requestID) {
+			return
+		}
+	}
+	orgID, ok := h.organization(w, r, req.OrganizationID, "Failed to update team", requestID)
+	if !ok {
+		return
+	}
+
+	t, err := h.repo.Update(r.Context(), id, team.UpdateFields{
+		OrganizationID:  orgID,
+		Labels:          req.Labels,
+		Annotations:     req.Annotations,
+		MaxConnections:  req.MaxConnections,
+		ArchiveLocation: req.ArchiveLocation,
+		UpdatedBy:       actorName(r),
+	})
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+			return
+		}
+		slog.Error("failed to update team", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to update team", requestID)
+		return
+	}
+
+	response.Success(w, http.StatusOK, toTeamResponse(t), requestID)
+}
+
 // Delete handles DELETE /teams/{id}.
 func (h *TeamHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
@@ -120,6 +283,10 @@ func (h *TeamHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if _, isAdmin := orgAdminOf(r); isAdmin && !h.managedTeam(w, r, id, "Failed to delete team", requestID) {
+		return
+	}
+
 	if err := h.repo.Delete(r.Context(), id); err != nil {
 		if errors.Is(err, team.ErrTeamNotFound) {
 			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
@@ -130,9 +297,77 @@ func (h *TeamHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to delete team", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to delete team", requestID)
+		response.ServerErr(w, err, "Failed to delete team", requestID)
 		return
 	}
 
 	response.NoContent(w)
 }
+
+// organization resolves the organizationId of a request, nil when empty, or
+// writes an error response and returns false. Organization superadmins may
+// only name their own organization.
+func (h *TeamHandler) organization(w http.ResponseWriter, r *http.Request, orgIDStr, failure, requestID string) (*uuid.UUID, bool) {
+	if orgIDStr == "" {
+		return nil, true
+	}
+	orgID, _ := uuid.Parse(orgIDStr) // already validated
+	if adminOf, ok := orgAdminOf(r); ok && adminOf != orgID {
+		response.Err(w, http.StatusForbidden, "FORBIDDEN", "Cannot manage teams of another organization", requestID)
+		return nil, false
+	}
+	if h.orgs == nil {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+		return nil, false
+	}
+	if _, err := h.orgs.GetByID(r.Context(), orgID); err != nil {
+		if errors.Is(err, organization.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+			return nil, false
+		}
+		slog.Error("failed to get organization", "error", err, "id", orgID)
+		response.ServerErr(w, err, failure, requestID)
+		return nil, false
+	}
+	return &orgID, true
+}
+
+// managedTeam reports whether the caller manages the team with id, or writes
+// a 404 response, as for a missing team, and returns false.
+func (h *TeamHandler) managedTeam(w http.ResponseWriter, r *http.Request, id uuid.UUID, failure, requestID string) bool {
+	t, err := h.repo.GetByID(r.Context(), id)
+	if err == nil && !manages(r, t) {
+		err = team.ErrTeamNotFound
+	}
+	if err != nil {
+		if errors.Is(err, team.ErrTeamNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+			return false
+		}
+		slog.Error("failed to get team", "error", err, "id", id)
+		response.ServerErr(w, err, failure, requestID)
+		return false
+	}
+	return true
+}
+
+// overBudget writes a 409 response and returns true if the connection budget
+// of teamID leaves no room for a database on the tier with tierID, replacing
+// the team's database replacing unless it is uuid.Nil. failure is the
+// message of a failed check. A nil gate disables the check.
+func overBudget(w http.ResponseWriter, r *http.Request, gate budget.Gate, teamID uuid.UUID, tierID *uuid.UUID, replacing uuid.UUID, failure, requestID string) bool {
+	if gate == nil {
+		return false
+	}
+	err := gate.CheckConnections(r.Context(), teamID, tierID, replacing)
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, budget.ErrExceeded) {
+		response.Err(w, http.StatusConflict, "CONNECTION_BUDGET_EXCEEDED", err.Error(), requestID)
+		return true
+	}
+	slog.Error("failed to check team connection budget", "error", err)
+	response.ServerErr(w, err, failure, requestID)
+	return true
+}
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/go-chi/chi/v5"
@@ -25,6 +27,72 @@ type createTierRequest struct {
 	BlueprintName       string `json:"blueprintName"`
 	DestructionStrategy string `json:"destructionStrategy"`
 	BackupEnabled       bool   `json:"backupEnabled"`
+	Namespace           string `json:"namespace"`
+
+	StorageAutoscaling  *storageAutoscalingRequest `json:"storageAutoscaling"`
+	Topology            *topologyRequest           `json:"topology"`
+	Disruption          *disruptionRequest         `json:"disruption"`
+	DataClassifications []string                   `json:"dataClassifications"`
+}
+
+// storageAutoscalingRequest is the storageAutoscaling object of tier
+// requests. Omitted percentages take the tier defaults.
+type storageAutoscalingRequest struct {
+	Enabled          bool   `json:"enabled"`
+	ThresholdPercent *int   `json:"thresholdPercent"`
+	IncrementPercent *int   `json:"incrementPercent"`
+	MaxSize          string `json:"maxSize"`
+}
+
+// toPolicy converts the request into a policy. A nil request yields the
+// default, disabled policy.
+func (r *storageAutoscalingRequest) toPolicy() tier.StorageAutoscaling {
+	policy := tier.StorageAutoscaling{
+		ThresholdPercent: tier.DefaultStorageThresholdPercent,
+		IncrementPercent: tier.DefaultStorageIncrementPercent,
+	}
+	if r == nil {
+		return policy
+	}
+	policy.Enabled = r.Enabled
+	policy.MaxSize = strings.TrimSpace(r.MaxSize)
+	if r.ThresholdPercent != nil {
+		policy.ThresholdPercent = *r.ThresholdPercent
+	}
+	if r.IncrementPercent != nil {
+		policy.IncrementPercent = *r.IncrementPercent
+	}
+	return policy
+}
+
+// topologyRequest is the topology object of tier requests.
+type topologyRequest struct {
+	ZoneSpread   string            `json:"zoneSpread"`
+	NodeSelector map[string]string `json:"nodeSelector"`
+}
+
+// toTopology converts the request into a topology. A nil request yields the
+// zero topology.
+func (r *topologyRequest) toTopology() tier.Topology {
+	if r == nil {
+		return tier.Topology{}
+	}
+	return tier.Topology{ZoneSpread: strings.TrimSpace(r.ZoneSpread), NodeSelector: r.NodeSelector}
+}
+
+// disruptionRequest is the disruption object of tier requests.
+type disruptionRequest struct {
+	MinAvailable      int    `json:"minAvailable"`
+	PriorityClassName string `json:"priorityClassName"`
+}
+
+// toDisruption converts the request into a disruption policy. A nil request
+// yields the zero policy.
+func (r *disruptionRequest) toDisruption() tier.Disruption {
+	if r == nil {
+		return tier.Disruption{}
+	}
+	return tier.Disruption{MinAvailable: r.MinAvailable, PriorityClassName: strings.TrimSpace(r.PriorityClassName)}
 }
 
 // updateTierRequest is the request body for PATCH /tiers/{id}.
@@ -34,6 +102,86 @@ type updateTierRequest struct {
 	BlueprintID         *uuid.UUID `json:"blueprintId"`
 	DestructionStrategy *string    `json:"destructionStrategy"`
 	BackupEnabled       *bool      `json:"backupEnabled"`
+	Namespace           *string    `json:"namespace"`
+
+	StorageAutoscaling  *storageAutoscalingRequest `json:"storageAutoscaling"`
+	Topology            *topologyRequest           `json:"topology"`
+	Disruption          *disruptionRequest         `json:"disruption"`
+	DataClassifications []string                   `json:"dataClassifications"`
+}
+
+// cloneTierRequest is the request body for POST /tiers/{id}/clone. Fields
+// other than Name default to the source tier's.
+type cloneTierRequest struct {
+	Name                string  `json:"name"`
+	Description         *string `json:"description"`
+	BlueprintName       *string `json:"blueprintName"`
+	DestructionStrategy *string `json:"destructionStrategy"`
+	BackupEnabled       *bool   `json:"backupEnabled"`
+	Namespace           *string `json:"namespace"`
+
+	StorageAutoscaling  *storageAutoscalingRequest `json:"storageAutoscaling"`
+	Topology            *topologyRequest           `json:"topology"`
+	Disruption          *disruptionRequest         `json:"disruption"`
+	DataClassifications []string                   `json:"dataClassifications"`
+}
+
+// createRequest returns the create request that clones src with the
+// overrides of r applied.
+func (r *cloneTierRequest) createRequest(src *tier.Tier) createTierRequest {
+	threshold := src.StorageAutoscaling.ThresholdPercent
+	increment := src.StorageAutoscaling.IncrementPercent
+	req := createTierRequest{
+		Name:                r.Name,
+		Description:         src.Description,
+		BlueprintName:       src.BlueprintName,
+		DestructionStrategy: src.DestructionStrategy,
+		BackupEnabled:       src.BackupEnabled,
+		Namespace:           src.Namespace,
+		StorageAutoscaling: &storageAutoscalingRequest{
+			Enabled:          src.StorageAutoscaling.Enabled,
+			ThresholdPercent: &threshold,
+			IncrementPercent: &increment,
+			MaxSize:          src.StorageAutoscaling.MaxSize,
+		},
+		Topology: &topologyRequest{
+			ZoneSpread:   src.Topology.ZoneSpread,
+			NodeSelector: maps.Clone(src.Topology.NodeSelector),
+		},
+		Disruption: &disruptionRequest{
+			MinAvailable:      src.Disruption.MinAvailable,
+			PriorityClassName: src.Disruption.PriorityClassName,
+		},
+		DataClassifications: slices.Clone(src.DataClassifications),
+	}
+	if r.Description != nil {
+		req.Description = *r.Description
+	}
+	if r.BlueprintName != nil {
+		req.BlueprintName = *r.BlueprintName
+	}
+	if r.DestructionStrategy != nil {
+		req.DestructionStrategy = *r.DestructionStrategy
+	}
+	if r.BackupEnabled != nil {
+		req.BackupEnabled = *r.BackupEnabled
+	}
+	if r.Namespace != nil {
+		req.Namespace = *r.Namespace
+	}
+	if r.StorageAutoscaling != nil {
+		req.StorageAutoscaling = r.StorageAutoscaling
+	}
+	if r.Topology != nil {
+		req.Topology = r.Topology
+	}
+	if r.Disruption != nil {
+		req.Disruption = r.Disruption
+	}
+	if r.DataClassifications != nil {
+		req.DataClassifications = r.DataClassifications
+	}
+	return req
 }
 
 // tierResponse is the full API representation (platform users).
@@ -45,8 +193,34 @@ type tierResponse struct {
 	BlueprintName       string  `json:"blueprintName,omitempty"`
 	DestructionStrategy string  `json:"destructionStrategy"`
 	BackupEnabled       bool    `json:"backupEnabled"`
-	CreatedAt           string  `json:"createdAt"`
-	UpdatedAt           string  `json:"updatedAt"`
+	Namespace           string  `json:"namespace,omitempty"`
+
+	StorageAutoscaling  storageAutoscalingResponse `json:"storageAutoscaling"`
+	Topology            topologyResponse           `json:"topology"`
+	Disruption          disruptionResponse         `json:"disruption"`
+	DataClassifications []string                   `json:"dataClassifications"`
+
+	CreatedBy string `json:"createdBy"`
+	UpdatedBy string `json:"updatedBy"`
+	CreatedAt string `json:"createdAt"`
+	UpdatedAt string `json:"updatedAt"`
+}
+
+type storageAutoscalingResponse struct {
+	Enabled          bool   `json:"enabled"`
+	ThresholdPercent int    `json:"thresholdPercent"`
+	IncrementPercent int    `json:"incrementPercent"`
+	MaxSize          string `json:"maxSize,omitempty"`
+}
+
+type topologyResponse struct {
+	ZoneSpread   string            `json:"zoneSpread,omitempty"`
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+}
+
+type disruptionResponse struct {
+	MinAvailable      int    `json:"minAvailable,omitempty"`
+	PriorityClassName string `json:"priorityClassName,omitempty"`
 }
 
 // tierSummaryResponse is the redacted API representation (product users).
@@ -64,13 +238,28 @@ func toTierResponse(t *tier.Tier) tierResponse {
 		BlueprintName:       t.BlueprintName,
 		DestructionStrategy: t.DestructionStrategy,
 		BackupEnabled:       t.BackupEnabled,
-		CreatedAt:           t.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
-		UpdatedAt:           t.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		Namespace:           t.Namespace,
+		StorageAutoscaling: storageAutoscalingResponse{
+			Enabled:          t.StorageAutoscaling.Enabled,
+			ThresholdPercent: t.StorageAutoscaling.ThresholdPercent,
+			IncrementPercent: t.StorageAutoscaling.IncrementPercent,
+			MaxSize:          t.StorageAutoscaling.MaxSize,
+		},
+		Topology:   topologyResponse(t.Topology),
+		Disruption: disruptionResponse(t.Disruption),
+		CreatedBy:  t.CreatedBy,
+		UpdatedBy:  t.UpdatedBy,
+		CreatedAt:  t.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		UpdatedAt:  t.UpdatedAt.UTC().Format("2006-01-02T15:04:05Z"),
 	}
 	if t.BlueprintID != nil {
 		s := t.BlueprintID.String()
 		resp.BlueprintID = &s
 	}
+	resp.DataClassifications = t.DataClassifications
+	if resp.DataClassifications == nil {
+		resp.DataClassifications = []string{}
+	}
 	return resp
 }
 
@@ -84,13 +273,15 @@ func toTierSummaryResponse(t *tier.Tier) tierSummaryResponse {
 
 // TierHandler handles tier CRUD endpoints.
 type TierHandler struct {
-	repo   tier.Repository
-	bpRepo blueprint.Repository
+	repo     tier.Repository
+	bpRepo   blueprint.Repository
+	rollouts RolloutController
 }
 
-// NewTierHandler creates a new TierHandler.
-func NewTierHandler(repo tier.Repository, bpRepo blueprint.Repository) *TierHandler {
-	return &TierHandler{repo: repo, bpRepo: bpRepo}
+// NewTierHandler creates a new TierHandler. When rollouts is non-nil,
+// changing a tier's blueprint starts a rollout to its existing databases.
+func NewTierHandler(repo tier.Repository, bpRepo blueprint.Repository, rollouts RolloutController) *TierHandler {
+	return &TierHandler{repo: repo, bpRepo: bpRepo, rollouts: rollouts}
 }
 
 // Create handles POST /tiers.
@@ -104,7 +295,47 @@ func (h *TierHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.create(w, r, req, requestID)
+}
+
+// Clone handles POST /tiers/{id}/clone: it creates a tier under a new name
+// with the source tier's settings, overridden by those in the request.
+func (h *TierHandler) Clone(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_ID", "id must be a valid UUID", requestID)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, 1<<20)
+	var req cloneTierRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		response.Err(w, http.StatusBadRequest, "INVALID_JSON", "Request body must be valid JSON", requestID)
+		return
+	}
+
+	src, err := h.repo.GetByID(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, tier.ErrTierNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Tier not found", requestID)
+			return
+		}
+		slog.Error("failed to get tier", "error", err, "id", id)
+		response.ServerErr(w, err, "Failed to clone tier", requestID)
+		return
+	}
+
+	h.create(w, r, req.createRequest(src), requestID)
+}
+
+// create validates req and creates the tier it describes.
+func (h *TierHandler) create(w http.ResponseWriter, r *http.Request, req createTierRequest, requestID string) {
 	req.Name = strings.TrimSpace(req.Name)
+	storageAutoscaling := req.StorageAutoscaling.toPolicy()
+	topology := req.Topology.toTopology()
+	disruption := req.Disruption.toDisruption()
 
 	fieldErrors := validation.ValidateCreateTierRequest(validation.CreateTierRequest{
 		Name:                req.Name,
@@ -112,6 +343,11 @@ func (h *TierHandler) Create(w http.ResponseWriter, r *http.Request) {
 		BlueprintName:       req.BlueprintName,
 		DestructionStrategy: req.DestructionStrategy,
 		BackupEnabled:       req.BackupEnabled,
+		Namespace:           req.Namespace,
+		StorageAutoscaling:  &storageAutoscaling,
+		Topology:            &topology,
+		Disruption:          &disruption,
+		DataClassifications: req.DataClassifications,
 	})
 	if len(fieldErrors) > 0 {
 		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
@@ -120,13 +356,16 @@ func (h *TierHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	// Resolve blueprint by name (required per ADR 008)
 	bp, err := h.bpRepo.GetByName(r.Context(), strings.TrimSpace(req.BlueprintName))
+	if err == nil && !blueprintVisible(r, bp) {
+		err = blueprint.ErrBlueprintNotFound
+	}
 	if err != nil {
 		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
 			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Blueprint not found", requestID)
 			return
 		}
 		slog.Error("failed to look up blueprint", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create tier", requestID)
+		response.ServerErr(w, err, "Failed to create tier", requestID)
 		return
 	}
 	blueprintID := &bp.ID
@@ -137,6 +376,12 @@ func (h *TierHandler) Create(w http.ResponseWriter, r *http.Request) {
 		BlueprintID:         blueprintID,
 		DestructionStrategy: req.DestructionStrategy,
 		BackupEnabled:       req.BackupEnabled,
+		Namespace:           strings.TrimSpace(req.Namespace),
+		StorageAutoscaling:  storageAutoscaling,
+		Topology:            topology,
+		Disruption:          disruption,
+		DataClassifications: req.DataClassifications,
+		CreatedBy:           actorName(r),
 	}
 
 	if err := h.repo.Create(r.Context(), t); err != nil {
@@ -145,7 +390,7 @@ func (h *TierHandler) Create(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to create tier", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create tier", requestID)
+		response.ServerErr(w, err, "Failed to create tier", requestID)
 		return
 	}
 
@@ -159,25 +404,21 @@ func (h *TierHandler) List(w http.ResponseWriter, r *http.Request) {
 	tiers, err := h.repo.List(r.Context())
 	if err != nil {
 		slog.Error("failed to list tiers", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list tiers", requestID)
+		response.ServerErr(w, err, "Failed to list tiers", requestID)
 		return
 	}
 
 	identity := middleware.GetIdentity(r.Context())
 	if identity != nil && identity.Role != nil && *identity.Role == "product" {
-		items := make([]tierSummaryResponse, 0, len(tiers))
-		for i := range tiers {
-			items = append(items, toTierSummaryResponse(&tiers[i]))
-		}
-		response.SuccessList(w, http.StatusOK, items, len(items), 1, 100, requestID)
+		response.StreamList(w, http.StatusOK, len(tiers), func(i int) tierSummaryResponse {
+			return toTierSummaryResponse(&tiers[i])
+		}, len(tiers), 1, 100, requestID)
 		return
 	}
 
-	items := make([]tierResponse, 0, len(tiers))
-	for i := range tiers {
-		items = append(items, toTierResponse(&tiers[i]))
-	}
-	response.SuccessList(w, http.StatusOK, items, len(items), 1, 100, requestID)
+	response.StreamList(w, http.StatusOK, len(tiers), func(i int) tierResponse {
+		return toTierResponse(&tiers[i])
+	}, len(tiers), 1, 100, requestID)
 }
 
 // GetByID handles GET /tiers/{id}.
@@ -192,13 +433,28 @@ func (h *TierHandler) GetByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	t, err := h.repo.GetByID(r.Context(), id)
+	writeTier(w, r, t, err, requestID)
+}
+
+// GetByName handles GET /tiers/by-name/{name}, like GetByID for the tier
+// with exactly this name.
+func (h *TierHandler) GetByName(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	t, err := h.repo.GetByName(r.Context(), chi.URLParam(r, "name"))
+	writeTier(w, r, t, err, requestID)
+}
+
+// writeTier writes the tier a lookup returned, summarized for product
+// users, or the lookup's error.
+func writeTier(w http.ResponseWriter, r *http.Request, t *tier.Tier, err error, requestID string) {
 	if err != nil {
 		if errors.Is(err, tier.ErrTierNotFound) {
 			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Tier not found", requestID)
 			return
 		}
-		slog.Error("failed to get tier", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to get tier", requestID)
+		slog.Error("failed to get tier", "error", err)
+		response.ServerErr(w, err, "Failed to get tier", requestID)
 		return
 	}
 
@@ -234,21 +490,78 @@ func (h *TierHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var storageAutoscaling *tier.StorageAutoscaling
+	if req.StorageAutoscaling != nil {
+		policy := req.StorageAutoscaling.toPolicy()
+		storageAutoscaling = &policy
+	}
+	var topology *tier.Topology
+	if req.Topology != nil {
+		parsed := req.Topology.toTopology()
+		topology = &parsed
+	}
+	var disruption *tier.Disruption
+	if req.Disruption != nil {
+		parsed := req.Disruption.toDisruption()
+		disruption = &parsed
+	}
+
 	fieldErrors := validation.ValidateUpdateTierRequest(validation.UpdateTierRequest{
 		Description:         req.Description,
 		DestructionStrategy: req.DestructionStrategy,
 		BackupEnabled:       req.BackupEnabled,
+		Namespace:           req.Namespace,
+		StorageAutoscaling:  storageAutoscaling,
+		Topology:            topology,
+		Disruption:          disruption,
+		DataClassifications: req.DataClassifications,
 	})
 	if len(fieldErrors) > 0 {
 		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
 		return
 	}
 
+	// A blueprint change is rolled out to existing databases; only one
+	// rollout per tier may be active at a time.
+	var previousBlueprint *uuid.UUID
+	if h.rollouts != nil && req.BlueprintID != nil {
+		current, err := h.repo.GetByID(r.Context(), id)
+		if err != nil {
+			if errors.Is(err, tier.ErrTierNotFound) {
+				response.Err(w, http.StatusNotFound, "NOT_FOUND", "Tier not found", requestID)
+				return
+			}
+			slog.Error("failed to get tier", "error", err, "id", id)
+			response.ServerErr(w, err, "Failed to update tier", requestID)
+			return
+		}
+		if current.BlueprintID != nil && *current.BlueprintID != *req.BlueprintID {
+			active, err := h.rollouts.Active(r.Context(), id)
+			if err != nil {
+				slog.Error("failed to check active rollouts", "error", err, "id", id)
+				response.ServerErr(w, err, "Failed to update tier", requestID)
+				return
+			}
+			if active != nil {
+				response.Err(w, http.StatusConflict, "ROLLOUT_IN_PROGRESS",
+					fmt.Sprintf("Tier has an active rollout (%s); wait for it to complete or roll it back", active.ID), requestID)
+				return
+			}
+			previousBlueprint = current.BlueprintID
+		}
+	}
+
 	fields := tier.UpdateFields{
 		Description:         req.Description,
 		BlueprintID:         req.BlueprintID,
 		DestructionStrategy: req.DestructionStrategy,
 		BackupEnabled:       req.BackupEnabled,
+		Namespace:           req.Namespace,
+		StorageAutoscaling:  storageAutoscaling,
+		Topology:            topology,
+		Disruption:          disruption,
+		DataClassifications: req.DataClassifications,
+		UpdatedBy:           actorName(r),
 	}
 
 	t, err := h.repo.Update(r.Context(), id, fields)
@@ -258,10 +571,19 @@ func (h *TierHandler) Update(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to update tier", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to update tier", requestID)
+		response.ServerErr(w, err, "Failed to update tier", requestID)
 		return
 	}
 
+	if previousBlueprint != nil {
+		ro, err := h.rollouts.Begin(r.Context(), t, *previousBlueprint)
+		if err != nil {
+			slog.Error("failed to start rollout", "error", err, "tier", t.Name)
+		} else {
+			w.Header().Set("Location", "/rollouts/"+ro.ID.String())
+		}
+	}
+
 	response.Success(w, http.StatusOK, toTierResponse(t), requestID)
 }
 
@@ -286,7 +608,7 @@ func (h *TierHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to delete tier", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to delete tier", requestID)
+		response.ServerErr(w, err, "Failed to delete tier", requestID)
 		return
 	}
 
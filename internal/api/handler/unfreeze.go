@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// freezesOnDelete reports whether a database's tier t has the freeze
+// destruction strategy, under which deleting a database only stops its
+// instances. A nil tier does not.
+func freezesOnDelete(t *tier.Tier) bool {
+	return t != nil && t.DestructionStrategy == tier.DestructionFreeze
+}
+
+// Unfreeze handles POST /databases/{id}/unfreeze. It asks the provider to
+// start the instances of a database frozen by its deletion again and marks
+// the database as unfreezing; the reconciler returns it to ready once its
+// instances run.
+func (h *DatabaseHandler) Unfreeze(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	if db.Status != "frozen" {
+		response.Err(w, http.StatusConflict, "UNFREEZE_NOT_POSSIBLE",
+			fmt.Sprintf("Database must be frozen to unfreeze (status is %s)", db.Status), requestID)
+		return
+	}
+
+	if frozen(w, r, h.freezes, db.OwnerTeamID, "unfreeze", requestID) {
+		return
+	}
+
+	release, ok := lockDatabase(w, r, h.locker, db.ID, "unfreeze", requestID)
+	if !ok {
+		return
+	}
+	defer release()
+
+	p, pdb, ok := h.databaseProvider(w, r, db, "UNFREEZE_NOT_POSSIBLE", requestID)
+	if !ok {
+		return
+	}
+	freezer, ok := p.(provider.Freezer)
+	if !ok {
+		response.Err(w, http.StatusConflict, "UNFREEZE_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support freezing databases", pdb.Provider), requestID)
+		return
+	}
+
+	if err := freezer.Unfreeze(r.Context(), pdb); err != nil {
+		if errors.Is(err, provider.ErrNotSupported) {
+			response.Err(w, http.StatusConflict, "UNFREEZE_NOT_POSSIBLE", fmt.Sprintf("Provider %q does not support freezing databases", pdb.Provider), requestID)
+			return
+		}
+		slog.Error("provider.Unfreeze failed", "error", err, "database", db.Name, "provider", pdb.Provider)
+		response.ServerErr(w, err, "Failed to unfreeze database", requestID)
+		return
+	}
+
+	updated, err := h.repo.UpdateStatus(r.Context(), db.ID, database.StatusUpdate{Status: "unfreezing", UpdatedBy: actorName(r)})
+	if err != nil {
+		if errors.Is(err, database.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Database not found", requestID)
+			return
+		}
+		slog.Error("failed to mark database as unfreezing", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to unfreeze database", requestID)
+		return
+	}
+	slog.Info("database unfreeze requested", "database", db.Name)
+
+	startOperation(w, r, h.ops, operation.TypeUnfreeze, updated, "Starting the instances again")
+	response.Success(w, http.StatusAccepted, toDatabaseResponse(updated), requestID)
+}
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+)
+
+// UnlockHandler handles POST /admin/databases/{id}/unlock.
+type UnlockHandler struct {
+	repo   database.Repository
+	locker *database.Locker
+	ops    *operation.Tracker
+	queue  jobs.Repository
+}
+
+// NewUnlockHandler creates a new UnlockHandler. ops and queue may be nil
+// when operations or the job queue are not enabled.
+func NewUnlockHandler(repo database.Repository, locker *database.Locker, ops *operation.Tracker, queue jobs.Repository) *UnlockHandler {
+	return &UnlockHandler{repo: repo, locker: locker, ops: ops, queue: queue}
+}
+
+type unlockResponse struct {
+	DatabaseID string        `json:"databaseId"`
+	Lock       *lockResponse `json:"lock"`
+	Operations []string      `json:"operations"`
+}
+
+// Unlock handles POST /admin/databases/{id}/unlock. It clears what a crashed
+// DAAP process left behind on a database: its mutation lock, and the
+// operations it was running, which are failed with ABANDONED. It refuses with
+// 409 while a live process holds the lock or runs an operation on the
+// database, or a job worker is running a job on it, as clearing them would
+// let a second operation run alongside.
+func (h *UnlockHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	if h.queue != nil {
+		list, err := h.queue.ListByDatabase(r.Context(), db.ID)
+		if err != nil {
+			slog.Error("failed to list jobs", "error", err, "id", db.ID)
+			response.ServerErr(w, err, "Failed to unlock database", requestID)
+			return
+		}
+		for _, j := range list {
+			if j.Status == jobs.StatusRunning {
+				middleware.SetAuditAction(r.Context(), "database.unlock", db.ID.String(), "refused: job running")
+				response.Err(w, http.StatusConflict, "JOB_RUNNING",
+					fmt.Sprintf("A worker is running %s job %s on the database; retry once it finishes or is requeued", j.Type, j.ID), requestID)
+				return
+			}
+		}
+	}
+
+	// Operations are checked before the lock is released, so a refusal
+	// leaves everything as it was.
+	var abandoned []operation.Operation
+	if h.ops != nil {
+		running := operation.StatusRunning
+		list, err := h.ops.List(r.Context(), operation.ListFilter{DatabaseID: &db.ID, Status: &running})
+		if err != nil {
+			slog.Error("failed to list running operations", "error", err, "id", db.ID)
+			response.ServerErr(w, err, "Failed to unlock database", requestID)
+			return
+		}
+		now := time.Now()
+		for _, op := range list {
+			if h.ops.Live(&op, now) {
+				middleware.SetAuditAction(r.Context(), "database.unlock", db.ID.String(), "refused: operation running on "+op.Instance)
+				response.Err(w, http.StatusConflict, "OPERATION_RUNNING",
+					fmt.Sprintf("Operation %s (%s) is still running on %s; it cannot be force-unlocked", op.ID, op.Type, op.Instance), requestID)
+				return
+			}
+			abandoned = append(abandoned, op)
+		}
+	}
+
+	lock, err := h.locker.ForceRelease(r.Context(), db.ID)
+	if errors.Is(err, database.ErrLockLive) {
+		middleware.SetAuditAction(r.Context(), "database.unlock", db.ID.String(), "refused: lock held by "+lock.Instance)
+		response.ErrWithDetails(w, http.StatusConflict, "LOCK_HELD",
+			fmt.Sprintf("Operation %q by %s is still running on %s; it cannot be force-unlocked",
+				lock.Operation, lock.Holder, lock.Instance),
+			toLockResponse(lock), requestID)
+		return
+	}
+	if err != nil {
+		slog.Error("failed to release database lock", "error", err, "id", db.ID)
+		response.ServerErr(w, err, "Failed to unlock database", requestID)
+		return
+	}
+
+	resp := unlockResponse{DatabaseID: db.ID.String(), Operations: []string{}}
+	var detail []string
+	if lock != nil {
+		l := toLockResponse(lock)
+		resp.Lock = &l
+		detail = append(detail, fmt.Sprintf("released lock %q held by %s on %s", lock.Operation, lock.Holder, lock.Instance))
+	}
+
+	for i := range abandoned {
+		h.ops.Fail(r.Context(), &abandoned[i], "ABANDONED",
+			fmt.Sprintf("Abandoned by the process running it; force-unlocked by %s", actorName(r)))
+		resp.Operations = append(resp.Operations, abandoned[i].ID.String())
+	}
+	if len(abandoned) > 0 {
+		detail = append(detail, fmt.Sprintf("failed %d running operation(s)", len(abandoned)))
+	}
+
+	if len(detail) == 0 {
+		detail = append(detail, "nothing to clear")
+	}
+	middleware.SetAuditAction(r.Context(), "database.unlock", db.ID.String(), strings.Join(detail, "; "))
+	slog.Warn("database force-unlocked", "database", db.Name, "by", actorName(r),
+		"lock", resp.Lock != nil, "operations", len(resp.Operations))
+	response.Success(w, http.StatusOK, resp, requestID)
+}
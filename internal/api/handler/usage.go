@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/provider"
+)
+
+type storageUsageResponse struct {
+	CapacityBytes int64 `json:"capacityBytes"`
+	UsedBytes     int64 `json:"usedBytes"`
+}
+
+type computeUsageResponse struct {
+	CPURequestMillis   int64 `json:"cpuRequestMillis"`
+	CPUUsedMillis      int64 `json:"cpuUsedMillis"`
+	MemoryRequestBytes int64 `json:"memoryRequestBytes"`
+	MemoryUsedBytes    int64 `json:"memoryUsedBytes"`
+}
+
+type poolerUsageResponse struct {
+	Instances             int   `json:"instances"`
+	ActiveClients         int64 `json:"activeClients"`
+	WaitingClients        int64 `json:"waitingClients"`
+	MaxClients            int64 `json:"maxClients"`
+	ActiveServers         int64 `json:"activeServers"`
+	IdleServers           int64 `json:"idleServers"`
+	PoolSize              int64 `json:"poolSize"`
+	PoolSaturationPercent int   `json:"poolSaturationPercent"`
+}
+
+type databaseUsageResponse struct {
+	Storage *storageUsageResponse `json:"storage"`
+	Compute *computeUsageResponse `json:"compute"`
+	Pooler  *poolerUsageResponse  `json:"pooler"`
+}
+
+func toPoolerUsageResponse(u provider.PoolerUsage) *poolerUsageResponse {
+	resp := &poolerUsageResponse{
+		Instances:      u.Instances,
+		ActiveClients:  u.ActiveClients,
+		WaitingClients: u.WaitingClients,
+		MaxClients:     u.MaxClients,
+		ActiveServers:  u.ActiveServers,
+		IdleServers:    u.IdleServers,
+		PoolSize:       u.PoolSize,
+	}
+	if u.PoolSize > 0 {
+		resp.PoolSaturationPercent = int(u.ActiveServers * 100 / u.PoolSize)
+	}
+	return resp
+}
+
+// Usage handles GET /databases/{id}/usage. It reports the database's
+// current storage, compute and pooler usage as read from its provider. Each
+// is null if the provider cannot report it, e.g. the pooler of a database
+// without one, or if reading it fails, so one unavailable source does not
+// hide the others.
+func (h *DatabaseHandler) Usage(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+
+	db, ok := ownedDatabase(w, r, h.repo, requestID)
+	if !ok {
+		return
+	}
+
+	p, pdb, ok := h.databaseProvider(w, r, db, "USAGE_NOT_AVAILABLE", requestID)
+	if !ok {
+		return
+	}
+
+	var resp databaseUsageResponse
+	if scaler, ok := p.(provider.StorageScaler); ok {
+		usage, err := scaler.StorageUsage(r.Context(), pdb)
+		if err == nil {
+			resp.Storage = &storageUsageResponse{CapacityBytes: usage.CapacityBytes, UsedBytes: usage.UsedBytes}
+		} else if !errors.Is(err, provider.ErrNotSupported) {
+			slog.Warn("failed to read storage usage", "error", err, "database", db.Name)
+		}
+	}
+	if reporter, ok := p.(provider.ComputeReporter); ok {
+		usage, err := reporter.ComputeUsage(r.Context(), pdb)
+		if err == nil {
+			resp.Compute = &computeUsageResponse{
+				CPURequestMillis:   usage.Requests.CPUMillis,
+				CPUUsedMillis:      usage.Used.CPUMillis,
+				MemoryRequestBytes: usage.Requests.MemoryBytes,
+				MemoryUsedBytes:    usage.Used.MemoryBytes,
+			}
+		} else if !errors.Is(err, provider.ErrNotSupported) {
+			slog.Warn("failed to read compute usage", "error", err, "database", db.Name)
+		}
+	}
+	if reporter, ok := p.(provider.PoolerReporter); ok {
+		usage, err := reporter.PoolerUsage(r.Context(), pdb)
+		if err == nil {
+			resp.Pooler = toPoolerUsageResponse(usage)
+		} else if !errors.Is(err, provider.ErrNotSupported) {
+			slog.Warn("failed to read pooler usage", "error", err, "database", db.Name)
+		}
+	}
+
+	response.Success(w, http.StatusOK, resp, requestID)
+}
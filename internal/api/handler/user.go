@@ -14,53 +14,69 @@ import (
 	"github.com/daap14/daap/internal/api/response"
 	"github.com/daap14/daap/internal/api/validation"
 	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/organization"
 	"github.com/daap14/daap/internal/team"
 )
 
 type createUserRequest struct {
-	Name   string `json:"name"`
-	TeamID string `json:"teamId"`
+	Name           string   `json:"name"`
+	TeamID         string   `json:"teamId"`
+	OrganizationID string   `json:"organizationId"`
+	FreezeOverride bool     `json:"freezeOverride"`
+	Scopes         []string `json:"scopes"`
 }
 
 type userResponse struct {
-	ID           string  `json:"id"`
-	Name         string  `json:"name"`
-	TeamID       *string `json:"teamId,omitempty"`
-	TeamName     *string `json:"teamName,omitempty"`
-	Role         *string `json:"role,omitempty"`
-	ApiKeyPrefix string  `json:"apiKeyPrefix"`
-	IsSuperuser  bool    `json:"isSuperuser"`
-	CreatedAt    string  `json:"createdAt"`
-	RevokedAt    *string `json:"revokedAt,omitempty"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	TeamID         *string  `json:"teamId,omitempty"`
+	TeamName       *string  `json:"teamName,omitempty"`
+	Role           *string  `json:"role,omitempty"`
+	OrganizationID *string  `json:"organizationId,omitempty"`
+	ApiKeyPrefix   string   `json:"apiKeyPrefix"`
+	IsSuperuser    bool     `json:"isSuperuser"`
+	FreezeOverride bool     `json:"freezeOverride"`
+	Scopes         []string `json:"scopes"`
+	CreatedAt      string   `json:"createdAt"`
+	RevokedAt      *string  `json:"revokedAt,omitempty"`
 }
 
 type userWithKeyResponse struct {
-	ID        string `json:"id"`
-	Name      string `json:"name"`
-	TeamID    string `json:"teamId"`
-	TeamName  string `json:"teamName"`
-	Role      string `json:"role"`
-	ApiKey    string `json:"apiKey"`
-	CreatedAt string `json:"createdAt"`
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	TeamID         *string  `json:"teamId,omitempty"`
+	TeamName       *string  `json:"teamName,omitempty"`
+	Role           *string  `json:"role,omitempty"`
+	OrganizationID *string  `json:"organizationId,omitempty"`
+	ApiKey         string   `json:"apiKey"`
+	FreezeOverride bool     `json:"freezeOverride"`
+	Scopes         []string `json:"scopes"`
+	CreatedAt      string   `json:"createdAt"`
 }
 
-// UserHandler handles user CRUD endpoints.
+// UserHandler handles user CRUD endpoints. Organization superadmins manage
+// only the users of the teams of their organization, and its superadmins.
 type UserHandler struct {
 	authService *auth.Service
 	userRepo    auth.UserRepository
 	teamRepo    team.Repository
+	orgs        organization.Repository
 }
 
-// NewUserHandler creates a new UserHandler.
-func NewUserHandler(authService *auth.Service, userRepo auth.UserRepository, teamRepo team.Repository) *UserHandler {
+// NewUserHandler creates a new UserHandler. Organization superadmins can be
+// created for the organizations of orgs; a nil orgs rejects organizationId.
+func NewUserHandler(authService *auth.Service, userRepo auth.UserRepository, teamRepo team.Repository, orgs organization.Repository) *UserHandler {
 	return &UserHandler{
 		authService: authService,
 		userRepo:    userRepo,
 		teamRepo:    teamRepo,
+		orgs:        orgs,
 	}
 }
 
-// Create handles POST /users.
+// Create handles POST /users. With organizationId instead of teamId it
+// creates an organization superadmin, which only the superuser may do.
+// Organization superadmins create users in the teams of their organization.
 func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	requestID := middleware.GetRequestID(r.Context())
 
@@ -72,8 +88,10 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	fieldErrors := validation.ValidateCreateUserRequest(validation.CreateUserRequest{
-		Name:   req.Name,
-		TeamID: req.TeamID,
+		Name:           req.Name,
+		TeamID:         req.TeamID,
+		OrganizationID: req.OrganizationID,
+		Scopes:         req.Scopes,
 	})
 	if len(fieldErrors) > 0 {
 		response.ErrWithDetails(w, http.StatusBadRequest, "VALIDATION_ERROR", "Input validation failed", fieldErrors, requestID)
@@ -82,49 +100,91 @@ func (h *UserHandler) Create(w http.ResponseWriter, r *http.Request) {
 
 	req.Name = strings.TrimSpace(req.Name)
 
-	teamID, _ := uuid.Parse(req.TeamID) // already validated
+	u := &auth.User{
+		Name:           req.Name,
+		IsSuperuser:    false,
+		FreezeOverride: req.FreezeOverride,
+		Scopes:         req.Scopes,
+	}
+	var resp userWithKeyResponse
 
-	t, err := h.teamRepo.GetByID(r.Context(), teamID)
-	if err != nil {
-		if errors.Is(err, team.ErrTeamNotFound) {
-			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+	if req.OrganizationID != "" {
+		orgID, ok := h.superadminOrganization(w, r, req.OrganizationID, requestID)
+		if !ok {
 			return
 		}
-		slog.Error("failed to get team", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create user", requestID)
-		return
+		u.OrganizationID = &orgID
+		orgIDStr := orgID.String()
+		resp.OrganizationID = &orgIDStr
+	} else {
+		teamID, _ := uuid.Parse(req.TeamID) // already validated
+
+		t, err := h.teamRepo.GetByID(r.Context(), teamID)
+		if err == nil && !manages(r, t) {
+			err = team.ErrTeamNotFound
+		}
+		if err != nil {
+			if errors.Is(err, team.ErrTeamNotFound) {
+				response.Err(w, http.StatusNotFound, "NOT_FOUND", "Team not found", requestID)
+				return
+			}
+			slog.Error("failed to get team", "error", err)
+			response.ServerErr(w, err, "Failed to create user", requestID)
+			return
+		}
+		u.TeamID = &teamID
+		teamIDStr := teamID.String()
+		resp.TeamID = &teamIDStr
+		resp.TeamName = &t.Name
+		resp.Role = &t.Role
 	}
 
 	rawKey, prefix, hash, err := h.authService.GenerateKey()
 	if err != nil {
 		slog.Error("failed to generate API key", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create user", requestID)
+		response.ServerErr(w, err, "Failed to create user", requestID)
 		return
 	}
-
-	u := &auth.User{
-		Name:         req.Name,
-		TeamID:       &teamID,
-		IsSuperuser:  false,
-		ApiKeyPrefix: prefix,
-		ApiKeyHash:   hash,
-	}
+	u.ApiKeyPrefix = prefix
+	u.ApiKeyHash = hash
 
 	if err := h.userRepo.Create(r.Context(), u); err != nil {
 		slog.Error("failed to create user", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to create user", requestID)
+		response.ServerErr(w, err, "Failed to create user", requestID)
 		return
 	}
 
-	response.Success(w, http.StatusCreated, userWithKeyResponse{
-		ID:        u.ID.String(),
-		Name:      u.Name,
-		TeamID:    teamID.String(),
-		TeamName:  t.Name,
-		Role:      t.Role,
-		ApiKey:    rawKey,
-		CreatedAt: u.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
-	}, requestID)
+	resp.ID = u.ID.String()
+	resp.Name = u.Name
+	resp.ApiKey = rawKey
+	resp.FreezeOverride = u.FreezeOverride
+	resp.Scopes = responseScopes(u.Scopes)
+	resp.CreatedAt = u.CreatedAt.UTC().Format("2006-01-02T15:04:05Z")
+	response.Success(w, http.StatusCreated, resp, requestID)
+}
+
+// superadminOrganization resolves the organization a new organization
+// superadmin administers, or writes an error response and returns false.
+func (h *UserHandler) superadminOrganization(w http.ResponseWriter, r *http.Request, orgIDStr, requestID string) (uuid.UUID, bool) {
+	if identity := middleware.GetIdentity(r.Context()); identity == nil || !identity.IsSuperuser {
+		response.Err(w, http.StatusForbidden, "FORBIDDEN", "Superuser access required to create organization superadmins", requestID)
+		return uuid.Nil, false
+	}
+	orgID, _ := uuid.Parse(orgIDStr) // already validated
+	if h.orgs == nil {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+		return uuid.Nil, false
+	}
+	if _, err := h.orgs.GetByID(r.Context(), orgID); err != nil {
+		if errors.Is(err, organization.ErrNotFound) {
+			response.Err(w, http.StatusNotFound, "NOT_FOUND", "Organization not found", requestID)
+			return uuid.Nil, false
+		}
+		slog.Error("failed to get organization", "error", err, "id", orgID)
+		response.ServerErr(w, err, "Failed to create user", requestID)
+		return uuid.Nil, false
+	}
+	return orgID, true
 }
 
 // List handles GET /users.
@@ -134,19 +194,30 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 	users, err := h.userRepo.List(r.Context())
 	if err != nil {
 		slog.Error("failed to list users", "error", err)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to list users", requestID)
+		response.ServerErr(w, err, "Failed to list users", requestID)
+		return
+	}
+	managed, err := h.managedTeams(r)
+	if err != nil {
+		slog.Error("failed to list teams", "error", err)
+		response.ServerErr(w, err, "Failed to list users", requestID)
 		return
 	}
 
 	items := make([]userResponse, 0, len(users))
 	for i := range users {
 		u := &users[i]
+		if managed != nil && !managesUser(r, u, managed) {
+			continue
+		}
 		resp := userResponse{
-			ID:           u.ID.String(),
-			Name:         u.Name,
-			ApiKeyPrefix: u.ApiKeyPrefix,
-			IsSuperuser:  u.IsSuperuser,
-			CreatedAt:    u.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
+			ID:             u.ID.String(),
+			Name:           u.Name,
+			ApiKeyPrefix:   u.ApiKeyPrefix,
+			IsSuperuser:    u.IsSuperuser,
+			FreezeOverride: u.FreezeOverride,
+			Scopes:         responseScopes(u.Scopes),
+			CreatedAt:      u.CreatedAt.UTC().Format("2006-01-02T15:04:05Z"),
 		}
 		if u.TeamID != nil {
 			tid := u.TeamID.String()
@@ -154,6 +225,10 @@ func (h *UserHandler) List(w http.ResponseWriter, r *http.Request) {
 			resp.TeamName = u.TeamName
 			resp.Role = u.TeamRole
 		}
+		if u.OrganizationID != nil {
+			oid := u.OrganizationID.String()
+			resp.OrganizationID = &oid
+		}
 		if u.RevokedAt != nil {
 			revoked := u.RevokedAt.UTC().Format("2006-01-02T15:04:05Z")
 			resp.RevokedAt = &revoked
@@ -183,7 +258,18 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to get user", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke user", requestID)
+		response.ServerErr(w, err, "Failed to revoke user", requestID)
+		return
+	}
+
+	managed, err := h.managedTeams(r)
+	if err != nil {
+		slog.Error("failed to list teams", "error", err)
+		response.ServerErr(w, err, "Failed to revoke user", requestID)
+		return
+	}
+	if managed != nil && !managesUser(r, u, managed) {
+		response.Err(w, http.StatusNotFound, "NOT_FOUND", "User not found", requestID)
 		return
 	}
 
@@ -203,9 +289,49 @@ func (h *UserHandler) Delete(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 		slog.Error("failed to revoke user", "error", err, "id", id)
-		response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to revoke user", requestID)
+		response.ServerErr(w, err, "Failed to revoke user", requestID)
 		return
 	}
 
 	response.NoContent(w)
 }
+
+// managedTeams returns the IDs of the teams of the organization an
+// organization superadmin administers, or nil for the superuser, who
+// manages all users.
+func (h *UserHandler) managedTeams(r *http.Request) (map[uuid.UUID]bool, error) {
+	adminOf, ok := orgAdminOf(r)
+	if !ok {
+		return nil, nil
+	}
+	teams, err := h.teamRepo.List(r.Context())
+	if err != nil {
+		return nil, err
+	}
+	managed := map[uuid.UUID]bool{}
+	for _, t := range teams {
+		if t.OrganizationID != nil && *t.OrganizationID == adminOf {
+			managed[t.ID] = true
+		}
+	}
+	return managed, nil
+}
+
+// managesUser reports whether an organization superadmin manages user u: a
+// member of one of the managed teams, or a superadmin of its organization.
+func managesUser(r *http.Request, u *auth.User, managed map[uuid.UUID]bool) bool {
+	if u.TeamID != nil {
+		return managed[*u.TeamID]
+	}
+	adminOf, _ := orgAdminOf(r)
+	return u.OrganizationID != nil && *u.OrganizationID == adminOf
+}
+
+// responseScopes returns the scopes of a key for a response, an empty list
+// rather than null for keys without scopes.
+func responseScopes(scopes []string) []string {
+	if scopes == nil {
+		return []string{}
+	}
+	return scopes
+}
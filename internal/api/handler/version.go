@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/buildinfo"
+)
+
+// VersionHandler handles the GET /version endpoint.
+type VersionHandler struct {
+	info buildinfo.Info
+}
+
+// NewVersionHandler creates a new VersionHandler reporting the given build info.
+func NewVersionHandler(info buildinfo.Info) *VersionHandler {
+	if info.Features == nil {
+		info.Features = []string{}
+	}
+	return &VersionHandler{info: info}
+}
+
+// ServeHTTP returns the build info of the running server.
+func (h *VersionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := middleware.GetRequestID(r.Context())
+	response.Success(w, http.StatusOK, h.info, requestID)
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+
+	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/audit"
+)
+
+// AuditRecorder receives audit events; *audit.Dispatcher implements it.
+type AuditRecorder interface {
+	Saturated() bool
+	Record(ctx context.Context, e audit.Event) error
+}
+
+type auditNoteKey struct{}
+
+// auditNote collects details handlers add to their request's audit event.
+type auditNote struct {
+	dataClassification string
+	action             string
+	databaseID         string
+	detail             string
+}
+
+// SetAuditClassification records the data classification of the database a
+// request acts on, so its audit event carries it. It does nothing for
+// requests that are not audited.
+func SetAuditClassification(ctx context.Context, classification string) {
+	if n, ok := ctx.Value(auditNoteKey{}).(*auditNote); ok {
+		n.dataClassification = classification
+	}
+}
+
+// SetAuditAction names what a request did, or was refused, to a database, such
+// as "database.legal_hold", so its audit event carries it along with detail.
+// It does nothing for requests that are not audited.
+func SetAuditAction(ctx context.Context, action, databaseID, detail string) {
+	if n, ok := ctx.Value(auditNoteKey{}).(*auditNote); ok {
+		n.action = action
+		n.databaseID = databaseID
+		n.detail = detail
+	}
+}
+
+// Audit returns middleware that records an audit event for every mutating
+// request (POST, PUT, PATCH, DELETE), including ones the handler rejects.
+// It must run after Auth so the actor is known; unauthenticated requests are
+// recorded with actor "anonymous". While an audit sink cannot keep up, new
+// mutating requests are refused with 503 so no change goes unaudited.
+func Audit(rec AuditRecorder) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			requestID := GetRequestID(r.Context())
+			if rec.Saturated() {
+				w.Header().Set("Retry-After", strconv.Itoa(response.RetryAfterSeconds))
+				response.Err(w, http.StatusServiceUnavailable, "AUDIT_UNAVAILABLE", "Audit log is not accepting events; retry later", requestID)
+				return
+			}
+
+			note := &auditNote{}
+			r = r.WithContext(context.WithValue(r.Context(), auditNoteKey{}, note))
+			ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+			next.ServeHTTP(ww, r)
+
+			e := audit.Event{
+				Time:       time.Now().UTC(),
+				RequestID:  requestID,
+				Method:     r.Method,
+				Path:       r.URL.Path,
+				Status:     ww.Status(),
+				RemoteAddr: r.RemoteAddr,
+
+				DataClassification: note.dataClassification,
+				Action:             note.action,
+				DatabaseID:         note.databaseID,
+				Detail:             note.detail,
+			}
+			if e.Status == 0 {
+				e.Status = http.StatusOK // nothing written; net/http sends 200
+			}
+			if rctx := chi.RouteContext(r.Context()); rctx != nil {
+				e.Route = rctx.RoutePattern()
+			}
+			e.Actor = "anonymous"
+			if identity := GetIdentity(r.Context()); identity != nil {
+				e.Actor = identity.UserName
+				e.Superuser = identity.IsSuperuser
+				if identity.TeamName != nil {
+					e.Team = *identity.TeamName
+				}
+			}
+			// The response is already written, so a failure can only be logged.
+			if err := rec.Record(context.WithoutCancel(r.Context()), e); err != nil {
+				slog.Error("failed to record audit event", "error", err, "requestId", requestID, "method", e.Method, "path", e.Path)
+			}
+		})
+	}
+}
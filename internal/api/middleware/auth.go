@@ -9,6 +9,8 @@ import (
 	"github.com/daap14/daap/internal/auth"
 )
 
+type contextKey string
+
 const identityKey contextKey = "identity"
 
 // Auth is middleware that extracts the X-API-Key header and resolves it
@@ -30,7 +32,7 @@ func Auth(authService *auth.Service) func(http.Handler) http.Handler {
 					response.Err(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid or revoked API key", requestID)
 					return
 				}
-				response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Authentication failed", requestID)
+				response.ServerErr(w, err, "Authentication failed", requestID)
 				return
 			}
 
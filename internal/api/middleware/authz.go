@@ -1,13 +1,56 @@
 package middleware
 
 import (
+	"fmt"
 	"net/http"
+	"slices"
 
 	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/auth"
 )
 
+// Access says who may call a route: the superuser only, or identities whose
+// team role is one of Roles. OrgAdmin also admits organization superadmins
+// to a Superuser route; its handlers confine them to their organization.
+// The zero Access admits any authenticated identity.
+type Access struct {
+	Superuser bool
+	OrgAdmin  bool
+	Roles     []string
+}
+
+// Denial returns why identity may not call a route with access a, the
+// message of the 403 response it gets, or "" if it may.
+func (a Access) Denial(identity *auth.Identity) string {
+	if a.Superuser && !identity.IsSuperuser && !(a.OrgAdmin && identity.IsOrgAdmin()) {
+		return "Superuser access required"
+	}
+	if len(a.Roles) > 0 && (identity.Role == nil || !slices.Contains(a.Roles, *identity.Role)) {
+		return "Insufficient permissions"
+	}
+	return ""
+}
+
 // RequireSuperuser returns middleware that rejects non-superuser identities with 403.
 func RequireSuperuser() func(http.Handler) http.Handler {
+	return RequireAccess(Access{Superuser: true})
+}
+
+// RequireSuperuserOrOrgAdmin returns middleware that rejects identities other
+// than the superuser and organization superadmins with 403.
+func RequireSuperuserOrOrgAdmin() func(http.Handler) http.Handler {
+	return RequireAccess(Access{Superuser: true, OrgAdmin: true})
+}
+
+// RequireRole returns middleware that rejects identities whose team role is not
+// in the allowed list. The superuser (who has no role) is also rejected.
+func RequireRole(roles ...string) func(http.Handler) http.Handler {
+	return RequireAccess(Access{Roles: roles})
+}
+
+// RequireAccess returns middleware that rejects unauthenticated requests with
+// 401, and identities access denies with 403.
+func RequireAccess(access Access) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			requestID := GetRequestID(r.Context())
@@ -18,8 +61,8 @@ func RequireSuperuser() func(http.Handler) http.Handler {
 				return
 			}
 
-			if !identity.IsSuperuser {
-				response.Err(w, http.StatusForbidden, "FORBIDDEN", "Superuser access required", requestID)
+			if denial := access.Denial(identity); denial != "" {
+				response.Err(w, http.StatusForbidden, "FORBIDDEN", denial, requestID)
 				return
 			}
 
@@ -28,29 +71,32 @@ func RequireSuperuser() func(http.Handler) http.Handler {
 	}
 }
 
-// RequireRole returns middleware that rejects identities whose team role is not
-// in the allowed list. The superuser (who has no role) is also rejected.
-func RequireRole(roles ...string) func(http.Handler) http.Handler {
-	allowed := make(map[string]bool, len(roles))
-	for _, r := range roles {
-		allowed[r] = true
+// ScopeDenial returns why the scopes of identity's key do not allow a
+// request with method to path, the message of the 403 response it gets, or
+// "" if they do.
+func ScopeDenial(identity *auth.Identity, method, path string) string {
+	if auth.ScopesAllow(identity.Scopes, method, path) {
+		return ""
 	}
+	resource, verb := auth.RequestScope(method, path)
+	return fmt.Sprintf("API key scopes do not allow %s on %s", verb, resource)
+}
 
+// RequireScopes returns middleware that rejects requests the scopes of the
+// caller's key do not allow with 403. Requests without an identity are
+// passed on for the route's access check to reject.
+func RequireScopes() func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			requestID := GetRequestID(r.Context())
-
 			identity := GetIdentity(r.Context())
 			if identity == nil {
-				response.Err(w, http.StatusUnauthorized, "UNAUTHORIZED", "API key is required", requestID)
+				next.ServeHTTP(w, r)
 				return
 			}
-
-			if identity.Role == nil || !allowed[*identity.Role] {
-				response.Err(w, http.StatusForbidden, "FORBIDDEN", "Insufficient permissions", requestID)
+			if denial := ScopeDenial(identity, r.Method, r.URL.Path); denial != "" {
+				response.Err(w, http.StatusForbidden, "FORBIDDEN", denial, GetRequestID(r.Context()))
 				return
 			}
-
 			next.ServeHTTP(w, r)
 		})
 	}
@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/daap14/daap/internal/api/response"
+)
+
+// RequestTimeoutHeader is the request header a client bounds its request
+// with.
+const RequestTimeoutHeader = "X-Request-Timeout"
+
+// Deadline is middleware bounding the context of a request by the timeout
+// its X-Request-Timeout header asks for: a Go duration such as "500ms" or
+// "2m", or a number of seconds. Timeouts over maxTimeout are cut to it. A
+// malformed or non-positive timeout fails with 400 INVALID_HEADER; requests
+// without the header are not bounded. A zero maxTimeout ignores the header.
+func Deadline(maxTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			value := r.Header.Get(RequestTimeoutHeader)
+			if value == "" || maxTimeout <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			timeout, ok := parseTimeout(value)
+			if !ok {
+				response.Err(w, http.StatusBadRequest, "INVALID_HEADER",
+					RequestTimeoutHeader+` must be a positive duration, such as "30s", or number of seconds`, GetRequestID(r.Context()))
+				return
+			}
+			timeout = min(timeout, maxTimeout)
+
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// parseTimeout parses a Go duration or a number of seconds.
+func parseTimeout(value string) (time.Duration, bool) {
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, err := strconv.ParseFloat(value, 64)
+		if err != nil || seconds > float64(1<<62)/float64(time.Second) {
+			return 0, false
+		}
+		timeout = time.Duration(seconds * float64(time.Second))
+	}
+	return timeout, timeout > 0
+}
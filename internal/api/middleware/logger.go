@@ -0,0 +1,33 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	chimiddleware "github.com/go-chi/chi/v5/middleware"
+)
+
+// Logger is middleware that logs every request once it has been served. The
+// records belong to the "http" logging module, so their level can be set
+// apart from the rest of the server's logs.
+func Logger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := chimiddleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK // nothing written; net/http sends 200
+		}
+		slog.InfoContext(r.Context(), "request served",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"status", status,
+			"bytes", ww.BytesWritten(),
+			"durationMs", float64(time.Since(start).Microseconds())/1000,
+			"remoteAddr", r.RemoteAddr,
+			"requestId", GetRequestID(r.Context()))
+	})
+}
@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+
+	"github.com/daap14/daap/internal/api/response"
+)
+
+// routedMethods are the methods routes are registered for. HEAD is served
+// by every GET route and OPTIONS by every route, so neither is listed.
+var routedMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Options is middleware answering OPTIONS requests for any routed path with
+// 204 and an Allow header listing the methods of its route, read from the
+// route tree of routes. It runs before authentication, so API gateways can
+// send preflight requests without credentials. Paths without a route fall
+// through to the 404 of the router.
+func Options(routes chi.Routes) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method != http.MethodOptions {
+				next.ServeHTTP(w, r)
+				return
+			}
+			allowed := AllowedMethods(routes, r)
+			if len(allowed) == 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// MethodNotAllowed returns the handler for requests whose path is routed but
+// not for their method: a 405 METHOD_NOT_ALLOWED error with the same Allow
+// header as Options.
+func MethodNotAllowed(routes chi.Routes) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Allow", strings.Join(AllowedMethods(routes, r), ", "))
+		response.Err(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED",
+			r.Method+" is not allowed on this path", GetRequestID(r.Context()))
+	}
+}
+
+// AllowedMethods lists the methods routes serves for the path of r, with
+// HEAD after GET and OPTIONS last, or none if the path has no route.
+func AllowedMethods(routes chi.Routes, r *http.Request) []string {
+	path := r.URL.RawPath
+	if path == "" {
+		path = r.URL.Path
+	}
+	var allowed []string
+	for _, method := range routedMethods {
+		if !routes.Match(chi.NewRouteContext(), method, path) {
+			continue
+		}
+		allowed = append(allowed, method)
+		if method == http.MethodGet {
+			allowed = append(allowed, http.MethodHead)
+		}
+	}
+	if len(allowed) > 0 {
+		allowed = append(allowed, http.MethodOptions)
+	}
+	return allowed
+}
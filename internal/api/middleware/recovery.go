@@ -1,21 +1,52 @@
 package middleware
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
+	"runtime/debug"
+
+	"github.com/google/uuid"
 
 	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/metrics"
+)
+
+var panicsTotal = metrics.NewCounter(
+	"daap_http_panics_total",
+	"Total number of handler panics recovered by the HTTP recovery middleware.",
 )
 
 // Recovery is middleware that recovers from panics and returns a 500 error.
+// Each panic gets an error ID that is returned in the response details and
+// logged together with the request ID and stack trace, so a user-reported
+// error can be matched to its log entry.
 func Recovery(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
-			if err := recover(); err != nil {
-				requestID := GetRequestID(r.Context())
-				slog.Error("panic recovered", "error", err, "requestId", requestID)
-				response.Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", "An unexpected error occurred", requestID)
+			rec := recover()
+			if rec == nil {
+				return
 			}
+			// http.ErrAbortHandler is the sanctioned way to abort a response;
+			// let net/http handle it without logging a stack.
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+
+			panicsTotal.Inc()
+			requestID := GetRequestID(r.Context())
+			errorID := uuid.New().String()
+			slog.Error("panic recovered",
+				"error", rec,
+				"errorId", errorID,
+				"requestId", requestID,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"stack", string(debug.Stack()),
+			)
+			response.ErrWithDetails(w, http.StatusInternalServerError, "INTERNAL_ERROR",
+				"An unexpected error occurred", map[string]string{"errorId": errorID}, requestID)
 		}()
 		next.ServeHTTP(w, r)
 	})
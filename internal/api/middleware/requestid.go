@@ -5,14 +5,13 @@ import (
 	"net/http"
 
 	"github.com/google/uuid"
-)
-
-type contextKey string
 
-const requestIDKey contextKey = "requestID"
+	"github.com/daap14/daap/internal/requestid"
+)
 
 // RequestID is middleware that injects a unique request ID into the context
-// and sets it as a response header.
+// and sets it as a response header. Providers read it from the context to
+// annotate the resources they apply.
 func RequestID(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		id := r.Header.Get("X-Request-ID")
@@ -20,7 +19,7 @@ func RequestID(next http.Handler) http.Handler {
 			id = uuid.New().String()
 		}
 
-		ctx := context.WithValue(r.Context(), requestIDKey, id)
+		ctx := requestid.With(r.Context(), id)
 		w.Header().Set("X-Request-ID", id)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -28,8 +27,5 @@ func RequestID(next http.Handler) http.Handler {
 
 // GetRequestID retrieves the request ID from the context.
 func GetRequestID(ctx context.Context) string {
-	if id, ok := ctx.Value(requestIDKey).(string); ok {
-		return id
-	}
-	return ""
+	return requestid.From(ctx)
 }
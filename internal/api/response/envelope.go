@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"io"
 	"log/slog"
 	"net/http"
 	"time"
@@ -9,10 +10,12 @@ import (
 	"github.com/google/uuid"
 )
 
-// Meta holds metadata for every API response.
+// Meta holds metadata for every API response. Warnings tell the client
+// about something that did not fail the request but needs attention.
 type Meta struct {
-	RequestID string `json:"requestId"`
-	Timestamp string `json:"timestamp"`
+	RequestID string   `json:"requestId"`
+	Timestamp string   `json:"timestamp"`
+	Warnings  []string `json:"warnings,omitempty"`
 }
 
 // ListMeta extends Meta with pagination information.
@@ -23,11 +26,13 @@ type ListMeta struct {
 	Limit int `json:"limit"`
 }
 
-// Error represents a structured API error.
+// Error represents a structured API error. Retryable tells clients whether
+// the same request may be retried; it is derived from the status code.
 type Error struct {
-	Code    string `json:"code"`
-	Message string `json:"message"`
-	Details any    `json:"details,omitempty"`
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Retryable bool   `json:"retryable"`
+	Details   any    `json:"details,omitempty"`
 }
 
 // Envelope is the standard API response wrapper.
@@ -74,6 +79,18 @@ func Success(w http.ResponseWriter, status int, data any, requestID string) {
 	})
 }
 
+// SuccessWithWarnings writes a successful JSON response like Success, with
+// warnings in meta.warnings.
+func SuccessWithWarnings(w http.ResponseWriter, status int, data any, warnings []string, requestID string) {
+	meta := NewMeta(requestID)
+	meta.Warnings = warnings
+	JSON(w, status, Envelope{
+		Data:  data,
+		Error: nil,
+		Meta:  meta,
+	})
+}
+
 // SuccessList writes a successful list JSON response with pagination metadata.
 func SuccessList(w http.ResponseWriter, status int, data any, total, page, limit int, requestID string) {
 	w.Header().Set("Content-Type", "application/json")
@@ -93,6 +110,55 @@ func SuccessList(w http.ResponseWriter, status int, data any, total, page, limit
 	}
 }
 
+var listSeparator = []byte(",")
+
+// StreamList writes the same response as SuccessList for n items, but encodes
+// them to w one at a time via item instead of marshalling a prebuilt slice.
+// Large pages then never hold every response struct in memory at once, and
+// the first rows are sent before the last ones are rendered. Once the status
+// is written a failure can only be logged, leaving a truncated body.
+func StreamList[T any](w http.ResponseWriter, status, n int, item func(i int) T, total, page, limit int, requestID string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	enc := json.NewEncoder(w)
+	if _, err := io.WriteString(w, `{"data":[`); err != nil {
+		slog.Error("failed to encode response", "error", err)
+		return
+	}
+	for i := range n {
+		if i > 0 {
+			if _, err := w.Write(listSeparator); err != nil {
+				slog.Error("failed to encode response", "error", err)
+				return
+			}
+		}
+		// Encoding through a pointer spares the encoder copying every item.
+		v := item(i)
+		if err := enc.Encode(&v); err != nil {
+			slog.Error("failed to encode response", "error", err, "item", i)
+			return
+		}
+	}
+	if _, err := io.WriteString(w, `],"error":null,"meta":`); err != nil {
+		slog.Error("failed to encode response", "error", err)
+		return
+	}
+	meta := ListMeta{
+		Meta:  NewMeta(requestID),
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	}
+	if err := enc.Encode(meta); err != nil {
+		slog.Error("failed to encode response", "error", err)
+		return
+	}
+	if _, err := io.WriteString(w, "}\n"); err != nil {
+		slog.Error("failed to encode response", "error", err)
+	}
+}
+
 // NoContent writes a 204 No Content response.
 func NoContent(w http.ResponseWriter) {
 	w.WriteHeader(http.StatusNoContent)
@@ -103,8 +169,9 @@ func Err(w http.ResponseWriter, status int, code string, message string, request
 	JSON(w, status, Envelope{
 		Data: nil,
 		Error: &Error{
-			Code:    code,
-			Message: message,
+			Code:      code,
+			Message:   message,
+			Retryable: isRetryableStatus(status),
 		},
 		Meta: NewMeta(requestID),
 	})
@@ -115,9 +182,10 @@ func ErrWithDetails(w http.ResponseWriter, status int, code string, message stri
 	JSON(w, status, Envelope{
 		Data: nil,
 		Error: &Error{
-			Code:    code,
-			Message: message,
-			Details: details,
+			Code:      code,
+			Message:   message,
+			Retryable: isRetryableStatus(status),
+			Details:   details,
 		},
 		Meta: NewMeta(requestID),
 	})
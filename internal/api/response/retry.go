@@ -0,0 +1,91 @@
+package response
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/daap14/daap/internal/breaker"
+	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// RetryAfterSeconds is the Retry-After value sent with transient errors. It is
+// long enough for a reconnect or a breaker probe to happen, short enough that
+// a bounded client retry loop (e.g. 3 attempts) completes within a request
+// timeout budget.
+const RetryAfterSeconds = 5
+
+// IsTransient reports whether err is caused by a dependency that is
+// temporarily unavailable (platform database connection loss, Kubernetes API
+// timeouts, an open circuit breaker, an unreachable provider plugin) and the
+// same request is likely to succeed if retried later. Programming errors,
+// constraint violations and anything unrecognized are permanent.
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, breaker.ErrOpen) || errors.Is(err, context.DeadlineExceeded) || errors.Is(err, provider.ErrUnavailable) {
+		return true
+	}
+	if k8s.IsTransient(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return isTransientSQLState(pgErr.Code)
+	}
+	var connErr *pgconn.ConnectError
+	if errors.As(err, &connErr) {
+		return true
+	}
+	var netErr net.Error
+	return pgconn.Timeout(err) || errors.As(err, &netErr)
+}
+
+// isTransientSQLState reports whether a PostgreSQL error code indicates a
+// condition that clears on retry: connection exceptions (08), serialization
+// failures and deadlocks (40001, 40P01), insufficient resources (53) and
+// operator intervention such as shutdown or failover (57P01-57P03).
+func isTransientSQLState(code string) bool {
+	switch {
+	case strings.HasPrefix(code, "08"), strings.HasPrefix(code, "53"):
+		return true
+	case code == "40001", code == "40P01":
+		return true
+	case code == "57P01", code == "57P02", code == "57P03":
+		return true
+	default:
+		return false
+	}
+}
+
+// isRetryableStatus reports whether clients may safely retry a request that
+// failed with the given status code.
+func isRetryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// ServerErr writes the error response for an unexpected failure caused by err.
+// Transient causes produce 503 SERVICE_UNAVAILABLE with a Retry-After header;
+// everything else produces 500 INTERNAL_ERROR, which clients must not retry.
+func ServerErr(w http.ResponseWriter, err error, message string, requestID string) {
+	if IsTransient(err) {
+		w.Header().Set("Retry-After", strconv.Itoa(RetryAfterSeconds))
+		Err(w, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE", message, requestID)
+		return
+	}
+	Err(w, http.StatusInternalServerError, "INTERNAL_ERROR", message, requestID)
+}
@@ -1,17 +1,35 @@
 package api
 
 import (
+	"net/http"
+	"time"
+
 	chimiddleware "github.com/go-chi/chi/v5/middleware"
 
 	"github.com/go-chi/chi/v5"
 
 	"github.com/daap14/daap/internal/api/handler"
 	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/archive"
 	"github.com/daap14/daap/internal/auth"
 	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/budget"
+	"github.com/daap14/daap/internal/buildinfo"
 	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/imagepolicy"
+	"github.com/daap14/daap/internal/jobs"
 	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/mail"
+	"github.com/daap14/daap/internal/metrics"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/placement"
+	"github.com/daap14/daap/internal/preflight"
 	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/rollout"
 	"github.com/daap14/daap/internal/team"
 	"github.com/daap14/daap/internal/tier"
 )
@@ -21,15 +39,85 @@ type RouterDeps struct {
 	K8sChecker       k8s.HealthChecker
 	DBPinger         handler.DBPinger
 	Version          string
+	BuildInfo        buildinfo.Info
 	Repo             database.Repository
+	Stats            database.StatsReader
+	ResizeEvents     database.ResizeEventRepository
+	Revisions        revision.Repository
+	Recommender      handler.Recommender
+	TierChanges      database.TierChangeRepository
+	Queries          database.QuerySnapshotRepository
+	Promotions       database.PromotionRepository
+	Dependents       database.DependentRepository
+	Specs            database.SpecRepository
+	Locker           *database.Locker
+	Operations       *operation.Tracker
+	Jobs             jobs.Repository
+	Renames          database.RenameRepository
+	Archives         database.ArchiveRepository
+	Environments     database.Environments
+	Rollouts         handler.RolloutController
+	RolloutRepo      rollout.Repository
+	Freezes          freeze.Repository
+	ProvisioningSLO  time.Duration
+	DeprovisionWait  time.Duration
 	Namespace        string
+	Placement        placement.Placer
+	Images           imagepolicy.Pinner
 	OpenAPISpec      []byte
 	AuthService      *auth.Service
 	TeamRepo         team.Repository
+	Organizations    organization.Repository
+	UsageSamples     database.UsageSampleRepository
 	TierRepo         tier.Repository
 	BlueprintRepo    blueprint.Repository
+	BlueprintLint    blueprint.LintConfig
 	ProviderRegistry *provider.Registry
 	UserRepo         auth.UserRepository
+	Invitations      auth.InvitationRepository
+	Mailer           mail.Sender
+	Notifier         notify.Notifier
+	PublicURL        string
+	InvitationTTL    time.Duration
+	PprofEnabled     bool
+	Preflight        handler.PreflightRunner
+	GitOps           handler.GitOpsExporter
+	SupportBundles   handler.SupportBundler
+	CNPGOperator     handler.OperatorDetector
+	Audit            middleware.AuditRecorder
+	Catalog          handler.CatalogSource
+
+	// BlueprintMaxManifestBytes caps the size of blueprint manifests on
+	// create and update; zero means no limit.
+	BlueprintMaxManifestBytes int
+
+	// BlueprintSigner signs blueprints when they are saved, and databases
+	// are only provisioned from blueprints matching their signature; nil
+	// disables signing.
+	BlueprintSigner *blueprint.Signer
+
+	// DNSZone is the zone new databases get a friendly hostname in; empty
+	// gives none.
+	DNSZone database.DNSZone
+
+	// Reconciler, Schema and ExpectedSchemaVersion back the reconciler and
+	// migrations components of GET /health; nil skips them. Reconciler also
+	// backs /admin/reconciler.
+	Reconciler            handler.ReconcilerController
+	Schema                preflight.SchemaVersioner
+	ExpectedSchemaVersion uint
+
+	// Config backs /admin/config, reporting the redacted effective
+	// configuration and changing its reloadable settings; nil disables it.
+	Config handler.ConfigSource
+
+	// Retention backs /admin/retention, reporting what the next purge of
+	// soft-deleted databases will remove; nil disables it.
+	Retention handler.RetentionReporter
+
+	// RequestTimeoutMax caps the timeout a client may ask for in the
+	// X-Request-Timeout header; zero ignores the header.
+	RequestTimeoutMax time.Duration
 }
 
 // NewRouter creates and configures a Chi router with all middleware and routes.
@@ -38,100 +126,365 @@ func NewRouter(deps RouterDeps) *chi.Mux {
 
 	r.Use(middleware.RequestID)
 	r.Use(middleware.Recovery)
-	r.Use(chimiddleware.Logger)
+	r.Use(middleware.Logger)
+	r.Use(middleware.Deadline(deps.RequestTimeoutMax))
+
+	// Every GET route answers HEAD, and every route OPTIONS, with the
+	// methods of the route in an Allow header.
+	r.Use(chimiddleware.GetHead)
+	r.Use(middleware.Options(r))
+	r.MethodNotAllowed(middleware.MethodNotAllowed(r))
 
 	// Public routes (no auth)
-	healthHandler := handler.NewHealthHandler(deps.K8sChecker, deps.DBPinger, deps.Version)
+	healthHandler := handler.NewHealthHandler(deps.K8sChecker, deps.DBPinger, deps.Version, deps.Reconciler, deps.Schema, deps.ExpectedSchemaVersion)
 	r.Get("/health", healthHandler.ServeHTTP)
+	r.Get("/version", handler.NewVersionHandler(deps.BuildInfo).ServeHTTP)
+	r.Get("/metrics", metrics.Handler().ServeHTTP)
 
 	if len(deps.OpenAPISpec) > 0 {
 		openapiHandler := handler.NewOpenAPIHandler(deps.OpenAPISpec)
 		r.Get("/openapi.json", openapiHandler.ServeHTTP)
 	}
 
+	// Mutating requests are audited once the caller is known.
+	var audited []func(http.Handler) http.Handler
+	if deps.Audit != nil {
+		audited = append(audited, middleware.Audit(deps.Audit))
+	}
+
+	// Invitation links are opened by people who do not have an API key yet.
+	var invitationHandler *handler.InvitationHandler
+	if deps.AuthService != nil && deps.TeamRepo != nil && deps.Invitations != nil {
+		invitationHandler = handler.NewInvitationHandler(deps.AuthService, deps.Invitations, deps.TeamRepo, deps.Mailer, deps.PublicURL, deps.InvitationTTL)
+		r.Get("/invitations/{token}", invitationHandler.Get)
+		r.With(audited...).Post("/invitations/{token}/accept", invitationHandler.Accept)
+	}
+
+	var freezeGate freeze.Gate
+	if deps.Freezes != nil {
+		freezeGate = freeze.NewChecker(deps.Freezes)
+	}
+
+	var quotaGate organization.QuotaGate
+	if deps.Organizations != nil && deps.TeamRepo != nil && deps.Repo != nil {
+		quotaGate = organization.NewQuotas(deps.Organizations, deps.TeamRepo, deps.Repo, deps.Notifier)
+	}
+
+	var budgetGate budget.Gate
+	if deps.TeamRepo != nil && deps.Repo != nil && deps.TierRepo != nil && deps.BlueprintRepo != nil && deps.ProviderRegistry != nil {
+		budgetGate = budget.New(deps.TeamRepo, deps.Repo, deps.TierRepo, deps.BlueprintRepo, deps.ProviderRegistry)
+	}
+	var archiver *archive.Archiver
+	if deps.TeamRepo != nil && deps.Repo != nil {
+		archiver = archive.New(deps.TeamRepo, deps.Repo, deps.Archives)
+	}
+
 	// Authenticated routes
 	if deps.AuthService != nil {
 		r.Group(func(r chi.Router) {
 			r.Use(middleware.Auth(deps.AuthService))
+			r.Use(audited...)
+			r.Use(middleware.RequireScopes())
 
-			// Superuser-only routes
-			if deps.TeamRepo != nil {
-				teamHandler := handler.NewTeamHandler(deps.TeamRepo)
+			// The caller's identity and authorization checks (any
+			// authenticated identity); r shares the route tree of the whole
+			// router.
+			r.Get("/me", handler.NewMeHandler().ServeHTTP)
+			r.Post("/auth/check", handler.NewAuthCheckHandler(r, routeAccess, deps.Repo).ServeHTTP)
+
+			// Organizations (superuser-only changes; organization
+			// superadmins read their own)
+			if deps.Organizations != nil && deps.TeamRepo != nil && deps.Repo != nil {
+				orgHandler := handler.NewOrganizationHandler(deps.Organizations, deps.TeamRepo, deps.Repo, deps.UsageSamples)
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.RequireSuperuser())
+					r.Post("/organizations", orgHandler.Create)
+					r.Patch("/organizations/{id}", orgHandler.Update)
+					r.Delete("/organizations/{id}", orgHandler.Delete)
+				})
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuserOrOrgAdmin())
+					r.Get("/organizations", orgHandler.List)
+					r.Get("/organizations/{id}", orgHandler.GetByID)
+					r.Get("/organizations/{id}/usage", orgHandler.Usage)
+				})
+			}
+
+			if deps.TeamRepo != nil {
+				// Teams and users (superuser, and organization superadmins
+				// for their organization)
+				teamHandler := handler.NewTeamHandler(deps.TeamRepo, deps.Organizations)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuserOrOrgAdmin())
 					r.Post("/teams", teamHandler.Create)
 					r.Get("/teams", teamHandler.List)
+					r.Get("/teams/by-name/{name}", teamHandler.GetByName)
+					r.Patch("/teams/{id}", teamHandler.Update)
 					r.Delete("/teams/{id}", teamHandler.Delete)
 
 					if deps.UserRepo != nil {
-						userHandler := handler.NewUserHandler(deps.AuthService, deps.UserRepo, deps.TeamRepo)
+						userHandler := handler.NewUserHandler(deps.AuthService, deps.UserRepo, deps.TeamRepo, deps.Organizations)
 						r.Post("/users", userHandler.Create)
 						r.Get("/users", userHandler.List)
 						r.Delete("/users/{id}", userHandler.Delete)
 					}
 				})
+
+				// Superuser-only routes
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					if deps.UserRepo != nil && invitationHandler != nil {
+						r.Post("/users/invite", invitationHandler.Invite)
+					}
+
+					if deps.Freezes != nil {
+						freezeHandler := handler.NewFreezeHandler(deps.Freezes, deps.TeamRepo)
+						r.Post("/freezes", freezeHandler.Create)
+						r.Get("/freezes", freezeHandler.List)
+						r.Delete("/freezes/{id}", freezeHandler.Delete)
+					}
+				})
+			}
+
+			// Preflight report (superuser-only)
+			if deps.Preflight != nil {
+				preflightHandler := handler.NewPreflightHandler(deps.Preflight)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					r.Get("/admin/preflight", preflightHandler.ServeHTTP)
+				})
+			}
+
+			// Effective configuration (superuser-only)
+			if deps.Config != nil {
+				configHandler := handler.NewConfigHandler(deps.Config, deps.BuildInfo, deps.ProviderRegistry)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					r.Get("/admin/config", configHandler.Get)
+					r.Put("/admin/config", configHandler.Update)
+				})
+			}
+
+			// Reconciler state and interval (superuser-only)
+			if deps.Reconciler != nil {
+				reconcilerHandler := handler.NewReconcilerHandler(deps.Reconciler)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					r.Get("/admin/reconciler", reconcilerHandler.Get)
+					r.Patch("/admin/reconciler", reconcilerHandler.Update)
+				})
+			}
+
+			// Retention purge report (superuser-only)
+			if deps.Retention != nil {
+				retentionHandler := handler.NewRetentionHandler(deps.Retention)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					r.Get("/admin/retention", retentionHandler.Get)
+				})
+			}
+
+			// Force-unlock of databases left locked by a crashed process
+			// (superuser-only)
+			if deps.Repo != nil && deps.Locker != nil {
+				unlockHandler := handler.NewUnlockHandler(deps.Repo, deps.Locker, deps.Operations, deps.Jobs)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					r.Post("/admin/databases/{id}/unlock", unlockHandler.Unlock)
+				})
+			}
+
+			// GitOps export (superuser-only)
+			if deps.GitOps != nil && deps.TeamRepo != nil {
+				gitopsHandler := handler.NewGitOpsHandler(deps.GitOps, deps.TeamRepo)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					r.Get("/admin/gitops-export", gitopsHandler.ServeHTTP)
+				})
+			}
+
+			// Profiling endpoints (superuser-only, opt-in via PPROF_ENABLED)
+			if deps.PprofEnabled {
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireSuperuser())
+					r.Mount("/debug", chimiddleware.Profiler())
+				})
 			}
 
 			// Business routes (platform + product)
 			if deps.Repo != nil {
-				dbHandler := handler.NewDatabaseHandler(deps.Repo, deps.TeamRepo, deps.TierRepo, deps.BlueprintRepo, deps.ProviderRegistry, deps.Namespace)
+				dbHandler := handler.NewDatabaseHandler(deps.Repo, deps.TeamRepo, deps.TierRepo, deps.BlueprintRepo, deps.ProviderRegistry, deps.Namespace, freezeGate, deps.Environments, deps.Dependents, deps.Locker, deps.Operations, deps.DeprovisionWait, deps.Specs, quotaGate, deps.Placement, deps.Images, deps.BlueprintSigner, deps.DNSZone, budgetGate, archiver, deps.Jobs, deps.Renames)
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.RequireRole("platform", "product"))
 					r.Post("/databases", dbHandler.Create)
 					r.Get("/databases", dbHandler.List)
 					r.Get("/databases/{id}", dbHandler.GetByID)
+					r.Get("/databases/by-name/{name}", dbHandler.GetByName)
 					r.Patch("/databases/{id}", dbHandler.Update)
 					r.Delete("/databases/{id}", dbHandler.Delete)
+					r.Post("/databases/{id}/restart", dbHandler.Restart)
+					r.Post("/databases/{id}/restore", dbHandler.Restore)
+					r.Post("/databases/{id}/rotate-credentials", dbHandler.RotateCredentials)
+					r.Post("/databases/{id}/unfreeze", dbHandler.Unfreeze)
+					r.Get("/databases/{id}/usage", dbHandler.Usage)
+					if deps.Renames != nil {
+						r.Post("/databases/{id}/rename", dbHandler.Rename)
+					}
+
+					ackHandler := handler.NewAckHandler(deps.Repo)
+					r.Post("/databases/{id}/ack", ackHandler.Create)
+					r.Delete("/databases/{id}/ack", ackHandler.Delete)
+
+					if deps.ResizeEvents != nil {
+						r.Get("/databases/{id}/resize-events", handler.NewResizeEventHandler(deps.Repo, deps.ResizeEvents).ServeHTTP)
+					}
+					if deps.Revisions != nil {
+						r.Get("/databases/{id}/revisions", handler.NewRevisionHandler(deps.Revisions).Database)
+					}
+					if deps.Recommender != nil && deps.TierChanges != nil {
+						r.Get("/databases/{id}/recommendations", handler.NewRecommendationHandler(deps.Repo, deps.Recommender, deps.TierChanges).ServeHTTP)
+					}
+					if deps.Queries != nil {
+						r.Get("/databases/{id}/insights/queries", handler.NewInsightHandler(deps.Repo, deps.Queries).ServeHTTP)
+					}
+					if deps.Specs != nil && deps.TierRepo != nil && deps.BlueprintRepo != nil {
+						r.Get("/databases/{id}/spec-diff", handler.NewSpecHandler(deps.Repo, deps.TierRepo, deps.BlueprintRepo, deps.Specs).ServeHTTP)
+					}
+					if deps.Dependents != nil {
+						dependentHandler := handler.NewDependentHandler(deps.Repo, deps.Dependents)
+						r.Post("/databases/{id}/dependents", dependentHandler.Create)
+						r.Get("/databases/{id}/dependents", dependentHandler.List)
+						r.Delete("/databases/{id}/dependents/{dependentId}", dependentHandler.Delete)
+					}
+					if deps.Operations != nil {
+						operationHandler := handler.NewOperationHandler(deps.Repo, deps.Operations)
+						r.Get("/databases/{id}/operations", operationHandler.ListByDatabase)
+						r.Get("/operations/{id}", operationHandler.GetByID)
+					}
+					if deps.Jobs != nil {
+						jobHandler := handler.NewJobHandler(deps.Repo, deps.Jobs)
+						r.Get("/databases/{id}/jobs", jobHandler.ListByDatabase)
+					}
+					if deps.Promotions != nil && len(deps.Environments) > 1 {
+						promotionHandler := handler.NewPromotionHandler(deps.Repo, deps.TierRepo, deps.BlueprintRepo, deps.ProviderRegistry,
+							deps.Promotions, deps.Environments, deps.Namespace, freezeGate, deps.Locker, deps.Operations, deps.Specs, quotaGate, deps.Placement, deps.BlueprintSigner, deps.DNSZone, budgetGate)
+						r.Post("/databases/{id}/promote", promotionHandler.Promote)
+						r.Get("/databases/{id}/promotions", promotionHandler.List)
+					}
+				})
+
+				// Database failover, review and support bundles (platform only)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireRole("platform"))
+					r.Post("/databases/{id}/failover", dbHandler.Failover)
+					r.Post("/databases/{id}/review", dbHandler.Review)
+					if deps.SupportBundles != nil {
+						supportHandler := handler.NewSupportBundleHandler(deps.Repo, deps.SupportBundles)
+						r.Get("/databases/{id}/support-bundle", supportHandler.ServeHTTP)
+					}
+				})
+			}
+
+			if deps.Stats != nil {
+				statsHandler := handler.NewStatsHandler(deps.Stats, deps.ProvisioningSLO)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireRole("platform", "product"))
+					r.Get("/stats", statsHandler.ServeHTTP)
+					r.Get("/stats/provisioning-durations", statsHandler.ProvisioningDurations)
+				})
+			}
+
+			// Backstage catalog (platform + product)
+			if deps.Catalog != nil {
+				catalogHandler := handler.NewCatalogHandler(deps.Catalog)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireRole("platform", "product"))
+					r.Get("/catalog/entities", catalogHandler.ServeHTTP)
 				})
 			}
 
 			// Tier routes
 			if deps.TierRepo != nil {
-				tierHandler := handler.NewTierHandler(deps.TierRepo, deps.BlueprintRepo)
+				tierHandler := handler.NewTierHandler(deps.TierRepo, deps.BlueprintRepo, deps.Rollouts)
 
 				// Read-only tier routes (platform + product)
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.RequireRole("platform", "product"))
 					r.Get("/tiers", tierHandler.List)
 					r.Get("/tiers/{id}", tierHandler.GetByID)
+					r.Get("/tiers/by-name/{name}", tierHandler.GetByName)
 				})
 
 				// Tier management routes (platform only)
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.RequireRole("platform"))
 					r.Post("/tiers", tierHandler.Create)
+					r.Post("/tiers/{id}/clone", tierHandler.Clone)
 					r.Patch("/tiers/{id}", tierHandler.Update)
 					r.Delete("/tiers/{id}", tierHandler.Delete)
+					if deps.Revisions != nil {
+						r.Get("/tiers/{id}/revisions", handler.NewRevisionHandler(deps.Revisions).Tier)
+					}
+				})
+			}
+
+			// Rollout routes (platform only)
+			if deps.Rollouts != nil && deps.RolloutRepo != nil && deps.TierRepo != nil {
+				rolloutHandler := handler.NewRolloutHandler(deps.RolloutRepo, deps.TierRepo, deps.Rollouts)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireRole("platform"))
+					r.Get("/rollouts", rolloutHandler.List)
+					r.Get("/rollouts/{id}", rolloutHandler.GetByID)
+					r.Post("/rollouts/{id}/pause", rolloutHandler.Pause)
+					r.Post("/rollouts/{id}/resume", rolloutHandler.Resume)
+					r.Post("/rollouts/{id}/rollback", rolloutHandler.Rollback)
 				})
 			}
 
 			// Blueprint routes
 			if deps.BlueprintRepo != nil {
-				bpHandler := handler.NewBlueprintHandler(deps.BlueprintRepo, deps.ProviderRegistry)
+				bpHandler := handler.NewBlueprintHandler(deps.BlueprintRepo, deps.ProviderRegistry, deps.CNPGOperator, deps.BlueprintLint, deps.BlueprintMaxManifestBytes, deps.BlueprintSigner)
 
 				// Read-only blueprint routes (platform + product)
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.RequireRole("platform", "product"))
 					r.Get("/blueprints", bpHandler.List)
 					r.Get("/blueprints/{id}", bpHandler.GetByID)
+					r.Get("/blueprints/{id}/versions", bpHandler.Versions)
+					r.Get("/blueprints/{id}/documents", bpHandler.Documents)
 				})
 
 				// Blueprint management routes (platform only)
 				r.Group(func(r chi.Router) {
 					r.Use(middleware.RequireRole("platform"))
 					r.Post("/blueprints", bpHandler.Create)
+					r.Patch("/blueprints/{id}", bpHandler.Update)
+					r.Post("/blueprints/{id}/sign", bpHandler.Sign)
 					r.Delete("/blueprints/{id}", bpHandler.Delete)
+					if deps.TierRepo != nil && deps.Repo != nil {
+						r.Get("/blueprints/{id}/usage", handler.NewBlueprintUsageHandler(deps.BlueprintRepo, deps.TierRepo, deps.Repo).ServeHTTP)
+					}
+				})
+			}
+
+			// Provider requirements (platform only)
+			if deps.CNPGOperator != nil {
+				reqHandler := handler.NewCNPGRequirementsHandler(deps.CNPGOperator, deps.BlueprintRepo)
+				r.Group(func(r chi.Router) {
+					r.Use(middleware.RequireRole("platform"))
+					r.Get("/providers/cnpg/requirements", reqHandler.ServeHTTP)
 				})
 			}
 		})
 	} else {
 		// Fallback: no auth service — register database routes without auth (graceful degradation)
 		if deps.Repo != nil {
-			dbHandler := handler.NewDatabaseHandler(deps.Repo, deps.TeamRepo, deps.TierRepo, deps.BlueprintRepo, deps.ProviderRegistry, deps.Namespace)
+			dbHandler := handler.NewDatabaseHandler(deps.Repo, deps.TeamRepo, deps.TierRepo, deps.BlueprintRepo, deps.ProviderRegistry, deps.Namespace, freezeGate, deps.Environments, deps.Dependents, deps.Locker, deps.Operations, deps.DeprovisionWait, deps.Specs, quotaGate, deps.Placement, deps.Images, deps.BlueprintSigner, deps.DNSZone, budgetGate, archiver, deps.Jobs, deps.Renames)
 			r.Route("/databases", func(r chi.Router) {
 				r.Post("/", dbHandler.Create)
 				r.Get("/", dbHandler.List)
 				r.Get("/{id}", dbHandler.GetByID)
+				r.Get("/by-name/{name}", dbHandler.GetByName)
 				r.Patch("/{id}", dbHandler.Update)
 				r.Delete("/{id}", dbHandler.Delete)
 			})
@@ -0,0 +1,29 @@
+package validation
+
+import "time"
+
+// AckDatabaseRequest mirrors the fields needed for acknowledge validation.
+type AckDatabaseRequest struct {
+	Comment string
+	Until   string // optional; RFC 3339, must be in the future
+	Now     time.Time
+}
+
+// ValidateAckRequest validates the fields of an acknowledge database request.
+func ValidateAckRequest(req AckDatabaseRequest) []FieldError {
+	var errs []FieldError
+
+	if len(req.Comment) > 1000 {
+		errs = append(errs, FieldError{Field: "comment", Message: "comment must be at most 1000 characters"})
+	}
+
+	if req.Until != "" {
+		if until, err := time.Parse(time.RFC3339, req.Until); err != nil {
+			errs = append(errs, FieldError{Field: "until", Message: "until must be an RFC 3339 timestamp"})
+		} else if !until.After(req.Now) {
+			errs = append(errs, FieldError{Field: "until", Message: "until must be in the future"})
+		}
+	}
+
+	return errs
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 	"text/template"
 
+	"github.com/google/uuid"
 	sigsyaml "sigs.k8s.io/yaml"
 
 	"github.com/daap14/daap/internal/provider"
@@ -12,10 +13,16 @@ import (
 
 // CreateBlueprintRequest mirrors the fields needed for create blueprint validation.
 type CreateBlueprintRequest struct {
-	Name      string
-	Provider  string
-	Manifests string
-	Registry  *provider.Registry
+	Name        string
+	Description string
+	Provider    string
+	Manifests   string
+	Registry    *provider.Registry
+	// MaxManifestBytes caps the size of Manifests; zero means no limit.
+	MaxManifestBytes int
+
+	TeamID         string
+	OrganizationID string
 }
 
 // ValidateCreateBlueprintRequest validates the fields of a create blueprint request.
@@ -31,6 +38,10 @@ func ValidateCreateBlueprintRequest(req CreateBlueprintRequest) []FieldError {
 		errs = append(errs, FieldError{Field: "name", Message: "name must not contain consecutive hyphens"})
 	}
 
+	if len(req.Description) > 1000 {
+		errs = append(errs, FieldError{Field: "description", Message: "description must be at most 1000 characters"})
+	}
+
 	providerName := strings.TrimSpace(req.Provider)
 	if providerName == "" {
 		errs = append(errs, FieldError{Field: "provider", Message: "provider is required"})
@@ -41,13 +52,70 @@ func ValidateCreateBlueprintRequest(req CreateBlueprintRequest) []FieldError {
 	manifests := strings.TrimSpace(req.Manifests)
 	if manifests == "" {
 		errs = append(errs, FieldError{Field: "manifests", Message: "manifests is required"})
+	} else if fe, ok := manifestsTooLarge(req.Manifests, req.MaxManifestBytes); ok {
+		errs = append(errs, fe)
 	} else {
 		errs = append(errs, validateManifests(manifests)...)
 	}
 
+	switch {
+	case req.TeamID != "" && req.OrganizationID != "":
+		errs = append(errs, FieldError{Field: "organizationId", Message: "organizationId cannot be combined with teamId"})
+	case req.TeamID != "":
+		if _, err := uuid.Parse(req.TeamID); err != nil {
+			errs = append(errs, FieldError{Field: "teamId", Message: "teamId must be a valid UUID"})
+		}
+	default:
+		errs = append(errs, validateOrganizationID(req.OrganizationID)...)
+	}
+
+	return errs
+}
+
+// UpdateBlueprintRequest mirrors the fields needed for update blueprint
+// validation. Nil fields are not being updated.
+type UpdateBlueprintRequest struct {
+	Description *string
+	Manifests   *string
+	// MaxManifestBytes caps the size of Manifests; zero means no limit.
+	MaxManifestBytes int
+}
+
+// ValidateUpdateBlueprintRequest validates the fields of an update blueprint
+// request.
+func ValidateUpdateBlueprintRequest(req UpdateBlueprintRequest) []FieldError {
+	var errs []FieldError
+
+	if req.Description != nil && len(*req.Description) > 1000 {
+		errs = append(errs, FieldError{Field: "description", Message: "description must be at most 1000 characters"})
+	}
+
+	if req.Manifests != nil {
+		manifests := strings.TrimSpace(*req.Manifests)
+		if manifests == "" {
+			errs = append(errs, FieldError{Field: "manifests", Message: "manifests must not be empty"})
+		} else if fe, ok := manifestsTooLarge(*req.Manifests, req.MaxManifestBytes); ok {
+			errs = append(errs, fe)
+		} else {
+			errs = append(errs, validateManifests(manifests)...)
+		}
+	}
+
 	return errs
 }
 
+// manifestsTooLarge reports whether manifests exceed max bytes, with the
+// field error to return. A limit of zero means no limit.
+func manifestsTooLarge(manifests string, limit int) (FieldError, bool) {
+	if limit <= 0 || len(manifests) <= limit {
+		return FieldError{}, false
+	}
+	return FieldError{
+		Field:   "manifests",
+		Message: fmt.Sprintf("manifests must be at most %d bytes, got %d", limit, len(manifests)),
+	}, true
+}
+
 // validateManifests checks that the manifests string is valid multi-doc YAML,
 // each document has apiVersion/kind/metadata.name, and Go templates parse.
 func validateManifests(manifests string) []FieldError {
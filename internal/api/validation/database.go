@@ -1,8 +1,16 @@
 package validation
 
 import (
+	"fmt"
+	"net"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/daap14/daap/internal/database"
 )
 
 // NameRegex matches DNS-compatible names: lowercase alphanumeric with hyphens, 3-63 characters,
@@ -17,9 +25,14 @@ type FieldError struct {
 
 // CreateDatabaseRequest mirrors the fields needed for create validation.
 type CreateDatabaseRequest struct {
-	Name      string
-	OwnerTeam string
-	Tier      string
+	Name         string
+	OwnerTeam    string
+	Tier         string
+	Environment  string   // optional
+	Environments []string // configured environments the request may name
+
+	DataClassification string            // optional
+	Exposure           database.Exposure // optional
 }
 
 // ValidateCreateRequest validates the fields of a create database request.
@@ -29,10 +42,8 @@ func ValidateCreateRequest(req CreateDatabaseRequest) []FieldError {
 
 	if req.Name == "" {
 		errs = append(errs, FieldError{Field: "name", Message: "name is required"})
-	} else if !NameRegex.MatchString(req.Name) {
-		errs = append(errs, FieldError{Field: "name", Message: "name must be lowercase alphanumeric with hyphens, 3-63 characters, starting with a letter"})
-	} else if strings.Contains(req.Name, "--") {
-		errs = append(errs, FieldError{Field: "name", Message: "name must not contain consecutive hyphens"})
+	} else if fe := validateDatabaseName(req.Name); fe != nil {
+		errs = append(errs, *fe)
 	}
 
 	if req.OwnerTeam == "" {
@@ -43,5 +54,159 @@ func ValidateCreateRequest(req CreateDatabaseRequest) []FieldError {
 		errs = append(errs, FieldError{Field: "tier", Message: "tier is required"})
 	}
 
+	if req.Environment != "" && !slices.Contains(req.Environments, req.Environment) {
+		if len(req.Environments) == 0 {
+			errs = append(errs, FieldError{Field: "environment", Message: "environments are not configured on this server"})
+		} else {
+			errs = append(errs, FieldError{Field: "environment", Message: "environment must be one of: " + strings.Join(req.Environments, ", ")})
+		}
+	}
+
+	if req.DataClassification != "" {
+		if fe := validateClassification("dataClassification", req.DataClassification); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+
+	errs = append(errs, validateExposure(req.Exposure)...)
+
+	return errs
+}
+
+// validateExposure checks a database's exposure: its type, and the source
+// ranges its load balancer accepts, which an external load balancer must
+// have and a database kept inside the cluster cannot.
+func validateExposure(e database.Exposure) []FieldError {
+	if e.Type != "" && !slices.Contains(database.ExposureTypes, e.Type) {
+		return []FieldError{{Field: "exposure.type", Message: "type must be one of: " + strings.Join(database.ExposureTypes, ", ")}}
+	}
+	var errs []FieldError
+	switch {
+	case e.Type == database.ExposureExternal && len(e.AllowedSourceRanges) == 0:
+		errs = append(errs, FieldError{Field: "exposure.allowedSourceRanges",
+			Message: "allowedSourceRanges is required for an external exposure"})
+	case !e.LoadBalanced() && len(e.AllowedSourceRanges) > 0:
+		errs = append(errs, FieldError{Field: "exposure.allowedSourceRanges",
+			Message: "allowedSourceRanges requires an internal or external exposure"})
+	}
+	for i, cidr := range e.AllowedSourceRanges {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			errs = append(errs, FieldError{Field: fmt.Sprintf("exposure.allowedSourceRanges[%d]", i),
+				Message: fmt.Sprintf("%q is not a CIDR, e.g. 203.0.113.0/24", cidr)})
+		}
+	}
+	return errs
+}
+
+// UpdateDatabaseRequest mirrors the fields needed for update validation.
+// Nil fields are not validated.
+type UpdateDatabaseRequest struct {
+	DataClassification *string
+
+	ReconciliationPaused      *bool
+	ReconciliationPausedUntil *string // RFC 3339; only with ReconciliationPaused true
+	Now                       time.Time
+}
+
+// ValidateUpdateRequest validates only non-nil fields on an update database
+// request.
+func ValidateUpdateRequest(req UpdateDatabaseRequest) []FieldError {
+	var errs []FieldError
+	if req.DataClassification != nil {
+		if fe := validateClassification("dataClassification", *req.DataClassification); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	if req.ReconciliationPausedUntil != nil {
+		if req.ReconciliationPaused == nil || !*req.ReconciliationPaused {
+			errs = append(errs, FieldError{Field: "reconciliationPausedUntil", Message: "reconciliationPausedUntil requires reconciliationPaused to be true"})
+		} else if until, err := time.Parse(time.RFC3339, *req.ReconciliationPausedUntil); err != nil {
+			errs = append(errs, FieldError{Field: "reconciliationPausedUntil", Message: "reconciliationPausedUntil must be an RFC 3339 timestamp"})
+		} else if !until.After(req.Now) {
+			errs = append(errs, FieldError{Field: "reconciliationPausedUntil", Message: "reconciliationPausedUntil must be in the future"})
+		} else if until.Sub(req.Now) > database.MaxReconciliationPause {
+			errs = append(errs, FieldError{Field: "reconciliationPausedUntil", Message: "reconciliationPausedUntil must be at most 7 days from now"})
+		}
+	}
+	return errs
+}
+
+// PromoteDatabaseRequest mirrors the fields needed for promote validation.
+type PromoteDatabaseRequest struct {
+	Name string // optional; derived from the source database when empty
+}
+
+// ValidatePromoteRequest validates the fields of a promote database request.
+func ValidatePromoteRequest(req PromoteDatabaseRequest) []FieldError {
+	var errs []FieldError
+	if req.Name != "" {
+		if fe := validateDatabaseName(req.Name); fe != nil {
+			errs = append(errs, *fe)
+		}
+	}
+	return errs
+}
+
+// RenameDatabaseRequest mirrors the fields needed for rename validation.
+type RenameDatabaseRequest struct {
+	Name string
+}
+
+// ValidateRenameRequest validates the fields of a rename database request.
+func ValidateRenameRequest(req RenameDatabaseRequest) []FieldError {
+	if req.Name == "" {
+		return []FieldError{{Field: "name", Message: "name is required"}}
+	}
+	if fe := validateDatabaseName(req.Name); fe != nil {
+		return []FieldError{*fe}
+	}
+	return nil
+}
+
+// RestoreDatabaseRequest mirrors the fields needed for restore validation.
+type RestoreDatabaseRequest struct {
+	Name       string
+	Backup     string
+	TargetTime *string // RFC 3339
+	Now        time.Time
+}
+
+// ValidateRestoreRequest validates the fields of a restore database request.
+func ValidateRestoreRequest(req RestoreDatabaseRequest) []FieldError {
+	var errs []FieldError
+	if req.Name == "" {
+		errs = append(errs, FieldError{Field: "name", Message: "name is required"})
+	} else if fe := validateDatabaseName(req.Name); fe != nil {
+		errs = append(errs, *fe)
+	}
+	if req.Backup != "" {
+		if msgs := k8svalidation.IsDNS1123Subdomain(req.Backup); len(msgs) > 0 {
+			errs = append(errs, FieldError{Field: "backup", Message: "backup must be a valid resource name: " + strings.Join(msgs, "; ")})
+		}
+	}
+	if req.TargetTime != nil {
+		if at, err := time.Parse(time.RFC3339, *req.TargetTime); err != nil {
+			errs = append(errs, FieldError{Field: "targetTime", Message: "targetTime must be an RFC 3339 timestamp"})
+		} else if at.After(req.Now) {
+			errs = append(errs, FieldError{Field: "targetTime", Message: "targetTime must not be in the future"})
+		}
+	}
 	return errs
 }
+
+func validateDatabaseName(name string) *FieldError {
+	if !NameRegex.MatchString(name) {
+		return &FieldError{Field: "name", Message: "name must be lowercase alphanumeric with hyphens, 3-63 characters, starting with a letter"}
+	}
+	if strings.Contains(name, "--") {
+		return &FieldError{Field: "name", Message: "name must not contain consecutive hyphens"}
+	}
+	return nil
+}
+
+func validateClassification(field, c string) *FieldError {
+	if !database.ValidClassification(c) {
+		return &FieldError{Field: field, Message: field + " must be one of: " + strings.Join(database.Classifications, ", ")}
+	}
+	return nil
+}
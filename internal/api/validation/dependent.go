@@ -0,0 +1,35 @@
+package validation
+
+import (
+	"strings"
+	"unicode"
+)
+
+// CreateDependentRequest mirrors the fields needed for create dependent
+// validation.
+type CreateDependentRequest struct {
+	Service     string
+	Description string
+}
+
+// ValidateCreateDependentRequest validates the fields of a create dependent
+// request. The service is a free-form identifier, so only its length and the
+// absence of whitespace are checked.
+func ValidateCreateDependentRequest(req CreateDependentRequest) []FieldError {
+	var errs []FieldError
+
+	switch {
+	case req.Service == "":
+		errs = append(errs, FieldError{Field: "service", Message: "service is required"})
+	case len(req.Service) > 255:
+		errs = append(errs, FieldError{Field: "service", Message: "service must be at most 255 characters"})
+	case strings.IndexFunc(req.Service, unicode.IsSpace) >= 0:
+		errs = append(errs, FieldError{Field: "service", Message: "service must not contain whitespace"})
+	}
+
+	if len(req.Description) > 1000 {
+		errs = append(errs, FieldError{Field: "description", Message: "description must be at most 1000 characters"})
+	}
+
+	return errs
+}
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"strings"
+	"time"
+)
+
+// CreateFreezeRequest mirrors the fields needed for create freeze validation.
+type CreateFreezeRequest struct {
+	Reason   string
+	StartsAt string // optional; defaults to now
+	EndsAt   string
+	Now      time.Time
+}
+
+// ValidateCreateFreezeRequest validates the fields of a create freeze request.
+func ValidateCreateFreezeRequest(req CreateFreezeRequest) []FieldError {
+	var errs []FieldError
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		errs = append(errs, FieldError{Field: "reason", Message: "reason is required"})
+	} else if len(reason) > 1000 {
+		errs = append(errs, FieldError{Field: "reason", Message: "reason must be at most 1000 characters"})
+	}
+
+	startsAt := req.Now
+	if req.StartsAt != "" {
+		t, err := time.Parse(time.RFC3339, req.StartsAt)
+		if err != nil {
+			errs = append(errs, FieldError{Field: "startsAt", Message: "startsAt must be an RFC 3339 timestamp"})
+		} else {
+			startsAt = t
+		}
+	}
+
+	if req.EndsAt == "" {
+		errs = append(errs, FieldError{Field: "endsAt", Message: "endsAt is required"})
+	} else if endsAt, err := time.Parse(time.RFC3339, req.EndsAt); err != nil {
+		errs = append(errs, FieldError{Field: "endsAt", Message: "endsAt must be an RFC 3339 timestamp"})
+	} else if !endsAt.After(startsAt) {
+		errs = append(errs, FieldError{Field: "endsAt", Message: "endsAt must be after startsAt"})
+	} else if !endsAt.After(req.Now) {
+		errs = append(errs, FieldError{Field: "endsAt", Message: "endsAt must be in the future"})
+	}
+
+	return errs
+}
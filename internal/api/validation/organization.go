@@ -0,0 +1,70 @@
+package validation
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// CreateOrganizationRequest mirrors the fields needed for create organization
+// validation.
+type CreateOrganizationRequest struct {
+	Name                string
+	MaxDatabases        int
+	QuotaWarningPercent *int
+}
+
+// ValidateCreateOrganizationRequest validates the fields of a create
+// organization request.
+func ValidateCreateOrganizationRequest(req CreateOrganizationRequest) []FieldError {
+	var errs []FieldError
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		errs = append(errs, FieldError{Field: "name", Message: "name is required"})
+	} else if len(name) > 255 {
+		errs = append(errs, FieldError{Field: "name", Message: "name must be at most 255 characters"})
+	}
+
+	if req.MaxDatabases < 0 {
+		errs = append(errs, FieldError{Field: "maxDatabases", Message: "maxDatabases must be 0 (no quota) or more"})
+	}
+	errs = append(errs, validateQuotaWarningPercent(req.QuotaWarningPercent)...)
+	return errs
+}
+
+// UpdateOrganizationRequest mirrors the fields needed for update organization
+// validation.
+type UpdateOrganizationRequest struct {
+	MaxDatabases        *int
+	QuotaWarningPercent *int
+}
+
+// ValidateUpdateOrganizationRequest validates the fields of an update
+// organization request.
+func ValidateUpdateOrganizationRequest(req UpdateOrganizationRequest) []FieldError {
+	var errs []FieldError
+	if req.MaxDatabases != nil && *req.MaxDatabases < 0 {
+		errs = append(errs, FieldError{Field: "maxDatabases", Message: "maxDatabases must be 0 (no quota) or more"})
+	}
+	return append(errs, validateQuotaWarningPercent(req.QuotaWarningPercent)...)
+}
+
+// validateQuotaWarningPercent checks an optional quotaWarningPercent field.
+func validateQuotaWarningPercent(percent *int) []FieldError {
+	if percent != nil && (*percent < 0 || *percent > 100) {
+		return []FieldError{{Field: "quotaWarningPercent", Message: "quotaWarningPercent must be between 0 (no warning) and 100"}}
+	}
+	return nil
+}
+
+// validateOrganizationID checks an optional organizationId field.
+func validateOrganizationID(id string) []FieldError {
+	if id == "" {
+		return nil
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return []FieldError{{Field: "organizationId", Message: "organizationId must be a valid UUID"}}
+	}
+	return nil
+}
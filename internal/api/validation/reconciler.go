@@ -0,0 +1,23 @@
+package validation
+
+import "github.com/daap14/daap/internal/config"
+
+// UpdateReconcilerRequest mirrors the fields needed for reconciler settings
+// validation.
+type UpdateReconcilerRequest struct {
+	IntervalSeconds *int
+}
+
+// ValidateUpdateReconcilerRequest validates the fields of an update
+// reconciler request.
+func ValidateUpdateReconcilerRequest(req UpdateReconcilerRequest) []FieldError {
+	var errs []FieldError
+
+	if req.IntervalSeconds == nil {
+		errs = append(errs, FieldError{Field: "intervalSeconds", Message: "intervalSeconds is required"})
+	} else if *req.IntervalSeconds < 1 || *req.IntervalSeconds > config.MaxReconcilerInterval {
+		errs = append(errs, FieldError{Field: "intervalSeconds", Message: "intervalSeconds must be between 1 and 3600"})
+	}
+
+	return errs
+}
@@ -1,11 +1,34 @@
 package validation
 
-import "strings"
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/daap14/daap/internal/provider"
+)
 
 // CreateTeamRequest mirrors the fields needed for create team validation.
 type CreateTeamRequest struct {
-	Name string
-	Role string
+	Name            string
+	Role            string
+	OrganizationID  string
+	Labels          map[string]string
+	Annotations     map[string]string
+	MaxConnections  *int
+	ArchiveLocation *string
+}
+
+// UpdateTeamRequest mirrors the fields needed for update team validation.
+type UpdateTeamRequest struct {
+	OrganizationID  string
+	Labels          map[string]string
+	Annotations     map[string]string
+	MaxConnections  *int
+	ArchiveLocation *string
 }
 
 // ValidateCreateTeamRequest validates the fields of a create team request.
@@ -25,5 +48,76 @@ func ValidateCreateTeamRequest(req CreateTeamRequest) []FieldError {
 		errs = append(errs, FieldError{Field: "role", Message: "role must be \"platform\" or \"product\""})
 	}
 
+	errs = append(errs, validateOrganizationID(req.OrganizationID)...)
+	errs = append(errs, validateMetadata("labels", req.Labels, true)...)
+	errs = append(errs, validateMetadata("annotations", req.Annotations, false)...)
+	errs = append(errs, validateMaxConnections(req.MaxConnections)...)
+	errs = append(errs, validateArchiveLocation(req.ArchiveLocation)...)
+	return errs
+}
+
+// ValidateUpdateTeamRequest validates the fields of an update team request.
+func ValidateUpdateTeamRequest(req UpdateTeamRequest) []FieldError {
+	var errs []FieldError
+	errs = append(errs, validateOrganizationID(req.OrganizationID)...)
+	errs = append(errs, validateMetadata("labels", req.Labels, true)...)
+	errs = append(errs, validateMetadata("annotations", req.Annotations, false)...)
+	errs = append(errs, validateMaxConnections(req.MaxConnections)...)
+	errs = append(errs, validateArchiveLocation(req.ArchiveLocation)...)
+	return errs
+}
+
+func validateMaxConnections(n *int) []FieldError {
+	if n != nil && *n < 0 {
+		return []FieldError{{Field: "maxConnections", Message: "maxConnections must be 0 (no budget) or more"}}
+	}
+	return nil
+}
+
+// archiveSchemes are the object stores databases can be archived to: S3,
+// Google Cloud Storage and, over https, Azure Blob Storage.
+var archiveSchemes = map[string]bool{"s3": true, "gs": true, "https": true}
+
+func validateArchiveLocation(location *string) []FieldError {
+	if location == nil || *location == "" {
+		return nil
+	}
+	u, err := url.Parse(*location)
+	if err != nil || !archiveSchemes[u.Scheme] || u.Host == "" {
+		return []FieldError{{Field: "archiveLocation", Message: fmt.Sprintf("archiveLocation must be an object store URL with scheme %s", joinKeys(archiveSchemes))}}
+	}
+	return nil
+}
+
+// reservedLabels are set by DAAP on every resource and cannot be defaulted.
+var reservedLabels = map[string]bool{provider.LabelDatabase: true, provider.LabelManagedBy: true}
+
+// validateMetadata checks that the keys of m are Kubernetes qualified names
+// and, for labels, that the values are valid label values.
+func validateMetadata(field string, m map[string]string, labels bool) []FieldError {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var errs []FieldError
+	for _, k := range keys {
+		f := fmt.Sprintf("%s.%s", field, k)
+		if msgs := k8svalidation.IsQualifiedName(k); len(msgs) > 0 {
+			errs = append(errs, FieldError{Field: f, Message: "invalid key: " + strings.Join(msgs, "; ")})
+			continue
+		}
+		if reservedLabels[k] {
+			errs = append(errs, FieldError{Field: f, Message: fmt.Sprintf("%s is set by DAAP", k)})
+			continue
+		}
+		if !labels {
+			continue
+		}
+		if msgs := k8svalidation.IsValidLabelValue(m[k]); len(msgs) > 0 {
+			errs = append(errs, FieldError{Field: f, Message: "invalid value: " + strings.Join(msgs, "; ")})
+		}
+	}
 	return errs
 }
@@ -2,10 +2,16 @@ package validation
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/daap14/daap/internal/tier"
 )
 
-var validDestructionStrategies = map[string]bool{"freeze": true, "archive": true, "hard_delete": true}
+var validDestructionStrategies = map[string]bool{tier.DestructionFreeze: true, tier.DestructionArchive: true, tier.DestructionHardDelete: true}
 
 // CreateTierRequest mirrors the fields needed for create tier validation.
 type CreateTierRequest struct {
@@ -14,6 +20,11 @@ type CreateTierRequest struct {
 	BlueprintName       string
 	DestructionStrategy string
 	BackupEnabled       bool
+	Namespace           string
+	StorageAutoscaling  *tier.StorageAutoscaling // nil when not given
+	Topology            *tier.Topology           // nil when not given
+	Disruption          *tier.Disruption         // nil when not given
+	DataClassifications []string
 }
 
 // ValidateCreateTierRequest validates the fields of a create tier request.
@@ -43,6 +54,22 @@ func ValidateCreateTierRequest(req CreateTierRequest) []FieldError {
 		errs = append(errs, FieldError{Field: "destructionStrategy", Message: fmt.Sprintf("destructionStrategy must be one of: %s", joinKeys(validDestructionStrategies))})
 	}
 
+	errs = append(errs, validateTierNamespace(req.Namespace)...)
+
+	if req.StorageAutoscaling != nil {
+		errs = append(errs, validateStorageAutoscaling(*req.StorageAutoscaling)...)
+	}
+
+	if req.Topology != nil {
+		errs = append(errs, validateTopology(*req.Topology)...)
+	}
+
+	if req.Disruption != nil {
+		errs = append(errs, validateDisruption(*req.Disruption)...)
+	}
+
+	errs = append(errs, validateTierClassifications(req.DataClassifications)...)
+
 	return errs
 }
 
@@ -52,6 +79,11 @@ type UpdateTierRequest struct {
 	Description         *string
 	DestructionStrategy *string
 	BackupEnabled       *bool
+	Namespace           *string
+	StorageAutoscaling  *tier.StorageAutoscaling
+	Topology            *tier.Topology
+	Disruption          *tier.Disruption
+	DataClassifications []string
 }
 
 // ValidateUpdateTierRequest validates only non-nil fields on an update request.
@@ -70,9 +102,124 @@ func ValidateUpdateTierRequest(req UpdateTierRequest) []FieldError {
 		}
 	}
 
+	if req.Namespace != nil {
+		errs = append(errs, validateTierNamespace(*req.Namespace)...)
+	}
+
+	if req.StorageAutoscaling != nil {
+		errs = append(errs, validateStorageAutoscaling(*req.StorageAutoscaling)...)
+	}
+
+	if req.Topology != nil {
+		errs = append(errs, validateTopology(*req.Topology)...)
+	}
+
+	if req.Disruption != nil {
+		errs = append(errs, validateDisruption(*req.Disruption)...)
+	}
+
+	errs = append(errs, validateTierClassifications(req.DataClassifications)...)
+
+	return errs
+}
+
+// validateStorageAutoscaling checks a tier's storage autoscaling policy. The
+// policy is validated even when disabled so that enabling it later cannot
+// activate bad values.
+func validateStorageAutoscaling(p tier.StorageAutoscaling) []FieldError {
+	var errs []FieldError
+	if p.ThresholdPercent < 1 || p.ThresholdPercent > 99 {
+		errs = append(errs, FieldError{Field: "storageAutoscaling.thresholdPercent", Message: "thresholdPercent must be between 1 and 99"})
+	}
+	if p.IncrementPercent < 1 || p.IncrementPercent > 1000 {
+		errs = append(errs, FieldError{Field: "storageAutoscaling.incrementPercent", Message: "incrementPercent must be between 1 and 1000"})
+	}
+	if p.MaxSize != "" {
+		q, err := resource.ParseQuantity(p.MaxSize)
+		if err != nil || q.Sign() <= 0 {
+			errs = append(errs, FieldError{Field: "storageAutoscaling.maxSize", Message: `maxSize must be a positive Kubernetes quantity, e.g. "500Gi"`})
+		}
+	}
+	return errs
+}
+
+// validateTopology checks a tier's topology: the zone spread mode, and that
+// the node selector is made of valid Kubernetes labels.
+func validateTopology(t tier.Topology) []FieldError {
+	var errs []FieldError
+	switch t.ZoneSpread {
+	case "", tier.ZoneSpreadPreferred, tier.ZoneSpreadRequired:
+	default:
+		errs = append(errs, FieldError{Field: "topology.zoneSpread",
+			Message: fmt.Sprintf("zoneSpread must be one of: %q, %q", tier.ZoneSpreadPreferred, tier.ZoneSpreadRequired)})
+	}
+
+	keys := make([]string, 0, len(t.NodeSelector))
+	for k := range t.NodeSelector {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		f := "topology.nodeSelector." + k
+		if msgs := k8svalidation.IsQualifiedName(k); len(msgs) > 0 {
+			errs = append(errs, FieldError{Field: f, Message: "invalid key: " + strings.Join(msgs, "; ")})
+		} else if msgs := k8svalidation.IsValidLabelValue(t.NodeSelector[k]); len(msgs) > 0 {
+			errs = append(errs, FieldError{Field: f, Message: "invalid value: " + strings.Join(msgs, "; ")})
+		}
+	}
 	return errs
 }
 
+// validateDisruption checks a tier's disruption policy: a non-negative
+// number of instances to keep, and a valid priority class name.
+func validateDisruption(d tier.Disruption) []FieldError {
+	var errs []FieldError
+	if d.MinAvailable < 0 {
+		errs = append(errs, FieldError{Field: "disruption.minAvailable", Message: "minAvailable must not be negative"})
+	}
+	if d.PriorityClassName != "" {
+		if msgs := k8svalidation.IsDNS1123Subdomain(d.PriorityClassName); len(msgs) > 0 {
+			errs = append(errs, FieldError{Field: "disruption.priorityClassName",
+				Message: "invalid priority class name: " + strings.Join(msgs, "; ")})
+		}
+	}
+	return errs
+}
+
+// validateTierClassifications checks the data classifications a tier may
+// host.
+func validateTierClassifications(list []string) []FieldError {
+	var errs []FieldError
+	seen := make(map[string]bool, len(list))
+	for i, c := range list {
+		field := fmt.Sprintf("dataClassifications[%d]", i)
+		if fe := validateClassification(field, c); fe != nil {
+			errs = append(errs, *fe)
+		} else if seen[c] {
+			errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf("%s is listed twice", c)})
+		}
+		seen[c] = true
+	}
+	return errs
+}
+
+// validateTierNamespace checks that a tier namespace (or namespace template)
+// renders to a valid Kubernetes namespace name. An empty value is valid and
+// means the global default namespace.
+func validateTierNamespace(ns string) []FieldError {
+	if strings.TrimSpace(ns) == "" {
+		return nil
+	}
+	if len(ns) > 255 {
+		return []FieldError{{Field: "namespace", Message: "namespace must be at most 255 characters"}}
+	}
+	sample := tier.NamespaceData{Team: "example-team", Tier: "example-tier", Database: "example-db", Environment: "example-env"}
+	if _, err := tier.RenderNamespace(ns, sample); err != nil {
+		return []FieldError{{Field: "namespace", Message: err.Error()}}
+	}
+	return nil
+}
+
 // joinKeys returns a sorted, comma-separated string of map keys.
 func joinKeys(m map[string]bool) string {
 	keys := make([]string, 0, len(m))
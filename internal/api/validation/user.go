@@ -1,15 +1,20 @@
 package validation
 
 import (
+	"net/mail"
 	"strings"
 
 	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/auth"
 )
 
 // CreateUserRequest mirrors the fields needed for create user validation.
 type CreateUserRequest struct {
-	Name   string
-	TeamID string
+	Name           string
+	TeamID         string
+	OrganizationID string // set instead of TeamID for organization superadmins
+	Scopes         []string
 }
 
 // ValidateCreateUserRequest validates the fields of a create user request.
@@ -23,10 +28,48 @@ func ValidateCreateUserRequest(req CreateUserRequest) []FieldError {
 		errs = append(errs, FieldError{Field: "name", Message: "name must be at most 255 characters"})
 	}
 
-	if req.TeamID == "" {
+	switch {
+	case req.OrganizationID != "" && req.TeamID != "":
+		errs = append(errs, FieldError{Field: "organizationId", Message: "organizationId cannot be combined with teamId"})
+	case req.OrganizationID != "":
+		errs = append(errs, validateOrganizationID(req.OrganizationID)...)
+	case req.TeamID == "":
 		errs = append(errs, FieldError{Field: "teamId", Message: "teamId is required"})
-	} else if _, err := uuid.Parse(req.TeamID); err != nil {
-		errs = append(errs, FieldError{Field: "teamId", Message: "teamId must be a valid UUID"})
+	default:
+		if _, err := uuid.Parse(req.TeamID); err != nil {
+			errs = append(errs, FieldError{Field: "teamId", Message: "teamId must be a valid UUID"})
+		}
+	}
+
+	for _, scope := range req.Scopes {
+		if err := auth.ValidateScope(scope); err != nil {
+			errs = append(errs, FieldError{Field: "scopes", Message: err.Error()})
+		}
+	}
+
+	return errs
+}
+
+// InviteUserRequest mirrors the fields needed for invite user validation.
+type InviteUserRequest struct {
+	Name   string
+	TeamID string
+	Email  string
+}
+
+// ValidateInviteUserRequest validates the fields of an invite user request.
+// The email must be a bare address (no display name), since it is written
+// into the To header of the invitation email.
+func ValidateInviteUserRequest(req InviteUserRequest) []FieldError {
+	errs := ValidateCreateUserRequest(CreateUserRequest{Name: req.Name, TeamID: req.TeamID})
+
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		errs = append(errs, FieldError{Field: "email", Message: "email is required"})
+	} else if len(email) > 320 {
+		errs = append(errs, FieldError{Field: "email", Message: "email must be at most 320 characters"})
+	} else if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		errs = append(errs, FieldError{Field: "email", Message: "email must be a valid email address"})
 	}
 
 	return errs
@@ -0,0 +1,99 @@
+// Package archive takes the final backups of databases whose tier's
+// destruction strategy is "archive": the backup goes to the owner team's
+// archive location and must complete, and its URL be recorded on the
+// database, before the database's resources are deleted.
+package archive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// ErrNoLocation is returned when the owner team of a database to archive has
+// no archive location.
+var ErrNoLocation = errors.New("team has no archive location")
+
+// Archiver archives databases before they are deleted.
+type Archiver struct {
+	teams    team.Repository
+	repo     database.Repository
+	archived database.ArchiveRepository
+}
+
+// New creates an Archiver that reads archive locations from teams, records
+// archive URLs in repo and finds the databases deleted once archived in
+// archived. archived may be nil, in which case none are found.
+func New(teams team.Repository, repo database.Repository, archived database.ArchiveRepository) *Archiver {
+	return &Archiver{teams: teams, repo: repo, archived: archived}
+}
+
+// Required reports whether databases of t are archived before deletion.
+func Required(t *tier.Tier) bool {
+	return t.DestructionStrategy == tier.DestructionArchive
+}
+
+// Location returns the archive location of db's owner team, or an error
+// wrapping ErrNoLocation if it has none.
+func (a *Archiver) Location(ctx context.Context, db *database.Database) (string, error) {
+	owner, err := a.teams.GetByID(ctx, db.OwnerTeamID)
+	if err != nil {
+		return "", fmt.Errorf("getting owner team: %w", err)
+	}
+	if owner.ArchiveLocation == "" {
+		return "", fmt.Errorf("%w: team %s", ErrNoLocation, owner.Name)
+	}
+	return owner.ArchiveLocation, nil
+}
+
+// Archive starts or checks the final backup of db through p and reports
+// whether db's resources may be deleted: always for tiers that do not
+// archive, and otherwise once the backup has completed and its URL is
+// recorded on db. It is called again until it reports true; the provider
+// keeps track of the backup in between.
+func (a *Archiver) Archive(ctx context.Context, db *database.Database, t *tier.Tier, p provider.Provider, pdb provider.ProviderDatabase) (bool, error) {
+	if !Required(t) || db.ArchiveURL != nil {
+		return true, nil
+	}
+	if a == nil {
+		return false, errors.New("archiving is not configured")
+	}
+	archiver, ok := p.(provider.Archiver)
+	if !ok {
+		return false, fmt.Errorf("provider %s: %w", pdb.Provider, provider.ErrNotSupported)
+	}
+	location, err := a.Location(ctx, db)
+	if err != nil {
+		return false, err
+	}
+	result, err := archiver.Archive(ctx, pdb, location)
+	if err != nil {
+		return false, err
+	}
+	if !result.Done {
+		return false, nil
+	}
+	updated, err := a.repo.Update(ctx, db.ID, database.UpdateFields{ArchiveURL: &result.URL})
+	if err != nil {
+		return false, fmt.Errorf("recording archive URL: %w", err)
+	}
+	db.ArchiveURL = updated.ArchiveURL
+	return true, nil
+}
+
+// Archived returns the database with the given id if it was deleted after
+// its final backup was archived, so its archive URL can still be looked up
+// until the retention purges it. It returns database.ErrNotFound otherwise.
+func (a *Archiver) Archived(ctx context.Context, id uuid.UUID) (*database.Database, error) {
+	if a == nil || a.archived == nil {
+		return nil, database.ErrNotFound
+	}
+	return a.archived.GetArchived(ctx, id)
+}
@@ -0,0 +1,284 @@
+// Package audit streams audit events (who changed what through the API) to
+// external sinks such as a SIEM. Each sink gets its own bounded queue and
+// delivery goroutine: batches are retried until the sink accepts them, so
+// delivery is at-least-once for as long as the process runs, and a sink that
+// stays down fills its queue and pushes back on the API instead of silently
+// dropping events.
+package audit
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/metrics"
+)
+
+var (
+	eventsDelivered = metrics.NewCounter(
+		"daap_audit_events_delivered_total",
+		"Audit events accepted by an audit sink, counted once per sink.",
+	)
+	deliveryFailures = metrics.NewCounter(
+		"daap_audit_delivery_failures_total",
+		"Failed attempts to deliver a batch of audit events to a sink; the batch is retried.",
+	)
+	eventsRejected = metrics.NewCounter(
+		"daap_audit_events_rejected_total",
+		"Audit events that could not be queued because a sink queue stayed full.",
+	)
+	eventsLost = metrics.NewCounter(
+		"daap_audit_events_lost_total",
+		"Queued audit events abandoned at shutdown because a sink did not accept them in time.",
+	)
+)
+
+// ErrBackpressure is returned by Record when a sink queue stays full for the
+// enqueue timeout.
+var ErrBackpressure = errors.New("audit sink queue is full")
+
+// ActorReconciler is the actor of the changes the reconciler makes on its
+// own, such as recording a database's health, so history views can tell
+// them from changes people made.
+const ActorReconciler = "system:reconciler"
+
+// ActorRetention is the actor of the purges of soft-deleted databases past
+// their retention.
+const ActorRetention = "system:retention"
+
+// Event describes one change, made through the API or by DAAP itself. ID is
+// unique per event, so collectors can discard the duplicates at-least-once
+// delivery may cause.
+//
+// Changes made by DAAP itself have a "system:" actor, such as
+// ActorReconciler, and describe the change with Action, DatabaseID and
+// Detail instead of an HTTP request.
+type Event struct {
+	ID         uuid.UUID `json:"id"`
+	Time       time.Time `json:"time"`
+	RequestID  string    `json:"requestId,omitempty"`
+	Actor      string    `json:"actor"`
+	Team       string    `json:"team,omitempty"`
+	Superuser  bool      `json:"superuser"`
+	Method     string    `json:"method,omitempty"`
+	Path       string    `json:"path,omitempty"`
+	Route      string    `json:"route,omitempty"`
+	Status     int       `json:"status,omitempty"`
+	RemoteAddr string    `json:"remoteAddr,omitempty"`
+	// DataClassification is the classification of the database the request
+	// acted on, when known.
+	DataClassification string `json:"dataClassification,omitempty"`
+
+	// Action names a change made by DAAP itself, such as
+	// "database.status_update" or "database.delete", or one a request made or
+	// was refused, such as "database.legal_hold".
+	Action     string `json:"action,omitempty"`
+	DatabaseID string `json:"databaseId,omitempty"`
+	// Detail is what the action changed, such as the new status.
+	Detail string `json:"detail,omitempty"`
+}
+
+// Sink delivers batches of events. Write must return nil only once the whole
+// batch has been accepted.
+type Sink interface {
+	Name() string
+	Write(ctx context.Context, events []Event) error
+}
+
+// Option configures a Dispatcher.
+type Option func(*Dispatcher)
+
+// WithQueueSize sets the number of events buffered per sink (default 10000).
+func WithQueueSize(n int) Option {
+	return func(d *Dispatcher) { d.queueSize = n }
+}
+
+// WithBatchSize sets the maximum number of events per Write (default 100).
+func WithBatchSize(n int) Option {
+	return func(d *Dispatcher) { d.batchSize = n }
+}
+
+// WithEnqueueTimeout sets how long Record waits for room in a full queue
+// before returning ErrBackpressure (default 5s).
+func WithEnqueueTimeout(t time.Duration) Option {
+	return func(d *Dispatcher) { d.enqueueTimeout = t }
+}
+
+// WithRetryBackoff sets the delay before the first retry of a failed batch
+// and the cap it doubles up to (default 1s and 30s).
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(d *Dispatcher) { d.minBackoff, d.maxBackoff = initial, max }
+}
+
+// Dispatcher fans events out to sinks.
+type Dispatcher struct {
+	queueSize      int
+	batchSize      int
+	enqueueTimeout time.Duration
+	minBackoff     time.Duration
+	maxBackoff     time.Duration
+
+	queues []*queue
+
+	mu     sync.RWMutex // guards closed against sends on closed channels
+	closed bool
+
+	abort context.Context // cancelled when Close gives up on draining
+	stop  context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+type queue struct {
+	sink Sink
+	ch   chan Event
+}
+
+// New creates a Dispatcher and starts one delivery goroutine per sink.
+func New(sinks []Sink, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		queueSize:      10000,
+		batchSize:      100,
+		enqueueTimeout: 5 * time.Second,
+		minBackoff:     time.Second,
+		maxBackoff:     30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.abort, d.stop = context.WithCancel(context.Background())
+
+	for _, s := range sinks {
+		q := &queue{sink: s, ch: make(chan Event, d.queueSize)}
+		d.queues = append(d.queues, q)
+		d.wg.Add(1)
+		go d.run(q)
+	}
+	return d
+}
+
+// Saturated reports whether any sink queue is full, i.e. recording another
+// event would block.
+func (d *Dispatcher) Saturated() bool {
+	for _, q := range d.queues {
+		if len(q.ch) == cap(q.ch) {
+			return true
+		}
+	}
+	return false
+}
+
+// Record queues e for every sink, filling in ID and Time when unset. It
+// blocks while a queue is full, up to the enqueue timeout, and then returns
+// ErrBackpressure; sinks that already queued the event keep it.
+func (d *Dispatcher) Record(ctx context.Context, e Event) error {
+	if e.ID == uuid.Nil {
+		e.ID = uuid.New()
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return errors.New("audit dispatcher is closed")
+	}
+
+	timer := time.NewTimer(d.enqueueTimeout)
+	defer timer.Stop()
+	for _, q := range d.queues {
+		select {
+		case q.ch <- e:
+		case <-timer.C:
+			eventsRejected.Inc()
+			return ErrBackpressure
+		case <-ctx.Done():
+			eventsRejected.Inc()
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// delivered. When ctx ends first, remaining events are abandoned, logged and
+// counted in daap_audit_events_lost_total.
+func (d *Dispatcher) Close(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		for _, q := range d.queues {
+			close(q.ch)
+		}
+	}
+	d.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		d.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		d.stop()
+		return nil
+	case <-ctx.Done():
+		d.stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (d *Dispatcher) run(q *queue) {
+	defer d.wg.Done()
+	for e := range q.ch {
+		batch := []Event{e}
+	fill:
+		for len(batch) < d.batchSize {
+			select {
+			case next, ok := <-q.ch:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, next)
+			default:
+				break fill
+			}
+		}
+
+		if !d.deliver(q.sink, batch) {
+			lost := len(batch) + len(q.ch)
+			for range q.ch {
+			}
+			eventsLost.Add(uint64(lost))
+			slog.Error("audit events lost at shutdown", "sink", q.sink.Name(), "events", lost)
+			return
+		}
+	}
+}
+
+// deliver writes batch to sink, retrying with exponential backoff until it
+// succeeds. It returns false only when the dispatcher is aborted.
+func (d *Dispatcher) deliver(sink Sink, batch []Event) bool {
+	backoff := d.minBackoff
+	for {
+		err := sink.Write(d.abort, batch)
+		if err == nil {
+			eventsDelivered.Add(uint64(len(batch)))
+			return true
+		}
+		deliveryFailures.Inc()
+		slog.Warn("audit delivery failed, retrying", "sink", sink.Name(), "events", len(batch), "retryIn", backoff, "error", err)
+
+		select {
+		case <-d.abort.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, d.maxBackoff)
+	}
+}
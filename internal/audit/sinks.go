@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/syslog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTPSink POSTs each batch as a JSON array to a collector URL, such as a
+// Splunk, Elastic or Vector HTTP input.
+type HTTPSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPSink creates an HTTPSink posting to url.
+func NewHTTPSink(url string, timeout time.Duration) *HTTPSink {
+	return &HTTPSink{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Name returns "http".
+func (s *HTTPSink) Name() string { return "http" }
+
+// Write posts events. Any non-2xx response is an error.
+func (s *HTTPSink) Write(ctx context.Context, events []Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return fmt.Errorf("encoding audit events: %w", err)
+	}
+	return post(ctx, s.client, s.url, "application/json", body)
+}
+
+// KafkaSink produces each event to a Kafka topic through a Kafka REST Proxy
+// (v2 API), keyed by event ID. Talking to the proxy over HTTP keeps DAAP free
+// of a native Kafka client.
+type KafkaSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaSink creates a KafkaSink producing to topic through the REST proxy
+// at proxyURL.
+func NewKafkaSink(proxyURL, topic string, timeout time.Duration) *KafkaSink {
+	return &KafkaSink{
+		url:    strings.TrimRight(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns "kafka".
+func (s *KafkaSink) Name() string { return "kafka" }
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+// Write produces events in one request. The proxy only answers 200 once
+// every record is acknowledged by the brokers.
+func (s *KafkaSink) Write(ctx context.Context, events []Event) error {
+	records := make([]kafkaRecord, len(events))
+	for i, e := range events {
+		records[i] = kafkaRecord{Key: e.ID.String(), Value: e}
+	}
+	body, err := json.Marshal(map[string][]kafkaRecord{"records": records})
+	if err != nil {
+		return fmt.Errorf("encoding audit events: %w", err)
+	}
+	return post(ctx, s.client, s.url, "application/vnd.kafka.json.v2+json", body)
+}
+
+func post(ctx context.Context, client *http.Client, url, contentType string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building audit request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting audit events: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("audit collector returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// SyslogSink sends each event as a JSON message to a syslog server with
+// facility AUTHPRIV and tag "daap-audit".
+type SyslogSink struct {
+	network string
+	addr    string
+
+	mu sync.Mutex
+	w  *syslog.Writer
+}
+
+// NewSyslogSink creates a SyslogSink for an address of the form
+// udp://host:port, tcp://host:port or unix:///path. The connection is
+// opened on first use and re-opened after errors.
+func NewSyslogSink(addr string) (*SyslogSink, error) {
+	u, err := url.Parse(addr)
+	if err != nil {
+		return nil, fmt.Errorf("parsing syslog address: %w", err)
+	}
+	switch u.Scheme {
+	case "udp", "tcp":
+		if u.Host == "" {
+			return nil, fmt.Errorf("syslog address %q has no host", addr)
+		}
+		return &SyslogSink{network: u.Scheme, addr: u.Host}, nil
+	case "unix", "unixgram":
+		if u.Path == "" {
+			return nil, fmt.Errorf("syslog address %q has no path", addr)
+		}
+		return &SyslogSink{network: u.Scheme, addr: u.Path}, nil
+	default:
+		return nil, fmt.Errorf("unsupported syslog scheme %q (expected udp, tcp, unix or unixgram)", u.Scheme)
+	}
+}
+
+// Name returns "syslog".
+func (s *SyslogSink) Name() string { return "syslog" }
+
+// Write sends events one message at a time. After a failure the connection
+// is dropped, and the whole batch is resent on retry.
+func (s *SyslogSink) Write(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.w == nil {
+		w, err := syslog.Dial(s.network, s.addr, syslog.LOG_AUTHPRIV|syslog.LOG_INFO, "daap-audit")
+		if err != nil {
+			return fmt.Errorf("connecting to syslog: %w", err)
+		}
+		s.w = w
+	}
+
+	for _, e := range events {
+		msg, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encoding audit event: %w", err)
+		}
+		if err := s.w.Info(string(msg)); err != nil {
+			_ = s.w.Close()
+			s.w = nil
+			return fmt.Errorf("writing to syslog: %w", err)
+		}
+	}
+	return nil
+}
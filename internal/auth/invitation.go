@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvitationNotFound is returned when no invitation matches a token.
+var ErrInvitationNotFound = errors.New("invitation not found")
+
+// ErrInvitationUsed is returned when accepting an invitation that was already accepted.
+var ErrInvitationUsed = errors.New("invitation has already been used")
+
+// ErrInvitationExpired is returned when accepting an invitation past its expiry.
+var ErrInvitationExpired = errors.New("invitation has expired")
+
+// Invitation represents a row in the user_invitations table: a pending user
+// who receives their API key by following an emailed one-time link.
+type Invitation struct {
+	ID             uuid.UUID
+	Name           string
+	TeamID         uuid.UUID
+	Email          string
+	FreezeOverride bool
+	TokenHash      string // hex SHA-256 of the token; the token itself is never stored
+	ExpiresAt      time.Time
+	AcceptedAt     *time.Time
+	UserID         *uuid.UUID // set once accepted
+	CreatedBy      string
+	CreatedAt      time.Time
+}
+
+// Expired reports whether the invitation can no longer be accepted at now.
+func (i *Invitation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// InvitationRepository provides operations on the user_invitations table.
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *Invitation) error
+	GetByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error)
+	// Accept creates user and marks the invitation accepted atomically, so a
+	// token can be redeemed at most once. It returns ErrInvitationUsed or
+	// ErrInvitationExpired when the invitation can no longer be accepted.
+	Accept(ctx context.Context, id uuid.UUID, user *User) error
+}
+
+// NewInvitationToken creates a one-time invitation token and the hash stored
+// in its place. Tokens carry 256 bits of entropy, so an unsalted SHA-256 is
+// enough and lets the token be looked up directly.
+func NewInvitationToken() (token, hash string, err error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", fmt.Errorf("generating random bytes: %w", err)
+	}
+	token = base64.RawURLEncoding.EncodeToString(b)
+	return token, HashInvitationToken(token), nil
+}
+
+// HashInvitationToken returns the stored form of an invitation token.
+func HashInvitationToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -8,24 +8,37 @@ import (
 
 // User represents a row in the users table.
 type User struct {
-	ID           uuid.UUID
-	Name         string
-	TeamID       *uuid.UUID // nil for superuser
-	IsSuperuser  bool
-	ApiKeyPrefix string
-	ApiKeyHash   string
-	CreatedAt    time.Time
-	RevokedAt    *time.Time
-	TeamName     *string // transient, populated via JOIN in List query
-	TeamRole     *string // transient, populated via JOIN in List query
+	ID             uuid.UUID
+	Name           string
+	TeamID         *uuid.UUID // nil for superuser and organization superadmins
+	OrganizationID *uuid.UUID // organization a superadmin administers; nil for other users
+	IsSuperuser    bool
+	FreezeOverride bool     // may change databases during a change freeze
+	Scopes         []string // restrict what the API key may do; empty for no restriction
+	ApiKeyPrefix   string
+	ApiKeyHash     string
+	CreatedAt      time.Time
+	RevokedAt      *time.Time
+	TeamName       *string // transient, populated via JOIN in List query
+	TeamRole       *string // transient, populated via JOIN in List query
 }
 
 // Identity is stored in the request context after authentication.
 type Identity struct {
-	UserID      uuid.UUID
-	UserName    string
-	TeamID      *uuid.UUID // nil for superuser
-	TeamName    *string    // nil for superuser
-	Role        *string    // nil for superuser; "platform" or "product"
-	IsSuperuser bool
+	UserID         uuid.UUID
+	UserName       string
+	TeamID         *uuid.UUID // nil for superuser and organization superadmins
+	TeamName       *string    // nil for superuser and organization superadmins
+	Role           *string    // nil for superuser and organization superadmins; "platform" or "product"
+	OrganizationID *uuid.UUID // organization of the team, or the one a superadmin administers
+	IsSuperuser    bool
+	FreezeOverride bool
+	KeyPrefix      string   // prefix of the API key the request authenticated with
+	Scopes         []string // scopes of the API key; empty for no restriction
+}
+
+// IsOrgAdmin reports whether the identity is an organization superadmin: a
+// user administering the teams and users of OrganizationID.
+func (i *Identity) IsOrgAdmin() bool {
+	return !i.IsSuperuser && i.TeamID == nil && i.OrganizationID != nil
 }
@@ -0,0 +1,109 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresInvitationRepository implements InvitationRepository using pgxpool.
+type PostgresInvitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewInvitationRepository creates a new InvitationRepository backed by the given connection pool.
+func NewInvitationRepository(pool *pgxpool.Pool) InvitationRepository {
+	return &PostgresInvitationRepository{pool: pool}
+}
+
+// Create inserts a new invitation.
+func (r *PostgresInvitationRepository) Create(ctx context.Context, inv *Invitation) error {
+	query := `
+		INSERT INTO user_invitations (name, team_id, email, freeze_override, token_hash, expires_at, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query,
+		inv.Name, inv.TeamID, inv.Email, inv.FreezeOverride, inv.TokenHash, inv.ExpiresAt, inv.CreatedBy,
+	).Scan(&inv.ID, &inv.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting invitation: %w", err)
+	}
+	return nil
+}
+
+// GetByTokenHash retrieves the invitation whose token hashes to tokenHash.
+func (r *PostgresInvitationRepository) GetByTokenHash(ctx context.Context, tokenHash string) (*Invitation, error) {
+	query := `
+		SELECT id, name, team_id, email, freeze_override, token_hash, expires_at,
+		       accepted_at, user_id, created_by, created_at
+		FROM user_invitations
+		WHERE token_hash = $1`
+
+	var inv Invitation
+	err := r.pool.QueryRow(ctx, query, tokenHash).Scan(
+		&inv.ID, &inv.Name, &inv.TeamID, &inv.Email, &inv.FreezeOverride, &inv.TokenHash, &inv.ExpiresAt,
+		&inv.AcceptedAt, &inv.UserID, &inv.CreatedBy, &inv.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, fmt.Errorf("querying invitation: %w", err)
+	}
+	return &inv, nil
+}
+
+// Accept locks the invitation row, inserts the user and records the
+// acceptance in one transaction.
+func (r *PostgresInvitationRepository) Accept(ctx context.Context, id uuid.UUID, u *User) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var expiresAt time.Time
+	var acceptedAt *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT expires_at, accepted_at FROM user_invitations WHERE id = $1 FOR UPDATE`, id,
+	).Scan(&expiresAt, &acceptedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrInvitationNotFound
+		}
+		return fmt.Errorf("locking invitation: %w", err)
+	}
+	if acceptedAt != nil {
+		return ErrInvitationUsed
+	}
+	if !time.Now().Before(expiresAt) {
+		return ErrInvitationExpired
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO users (name, team_id, is_superuser, freeze_override, api_key_prefix, api_key_hash)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at`,
+		u.Name, u.TeamID, u.IsSuperuser, u.FreezeOverride, u.ApiKeyPrefix, u.ApiKeyHash,
+	).Scan(&u.ID, &u.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting user: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE user_invitations SET accepted_at = NOW(), user_id = $2 WHERE id = $1`, id, u.ID)
+	if err != nil {
+		return fmt.Errorf("marking invitation accepted: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing invitation: %w", err)
+	}
+	return nil
+}
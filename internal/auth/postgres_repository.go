@@ -23,14 +23,21 @@ func NewRepository(pool *pgxpool.Pool) UserRepository {
 // Create inserts a new user record.
 func (r *PostgresRepository) Create(ctx context.Context, u *User) error {
 	query := `
-		INSERT INTO users (name, team_id, is_superuser, api_key_prefix, api_key_hash)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (name, team_id, organization_id, is_superuser, freeze_override, scopes, api_key_prefix, api_key_hash)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, created_at`
 
+	scopes := u.Scopes
+	if scopes == nil {
+		scopes = []string{}
+	}
 	err := r.pool.QueryRow(ctx, query,
 		u.Name,
 		u.TeamID,
+		u.OrganizationID,
 		u.IsSuperuser,
+		u.FreezeOverride,
+		scopes,
 		u.ApiKeyPrefix,
 		u.ApiKeyHash,
 	).Scan(&u.ID, &u.CreatedAt)
@@ -44,14 +51,14 @@ func (r *PostgresRepository) Create(ctx context.Context, u *User) error {
 // GetByID retrieves a single user by its UUID.
 func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*User, error) {
 	query := `
-		SELECT id, name, team_id, is_superuser, api_key_prefix, api_key_hash,
+		SELECT id, name, team_id, organization_id, is_superuser, freeze_override, scopes, api_key_prefix, api_key_hash,
 		       created_at, revoked_at
 		FROM users
 		WHERE id = $1`
 
 	var u User
 	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&u.ID, &u.Name, &u.TeamID, &u.IsSuperuser,
+		&u.ID, &u.Name, &u.TeamID, &u.OrganizationID, &u.IsSuperuser, &u.FreezeOverride, &u.Scopes,
 		&u.ApiKeyPrefix, &u.ApiKeyHash,
 		&u.CreatedAt, &u.RevokedAt,
 	)
@@ -68,7 +75,7 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*User,
 // FindByPrefix returns active (non-revoked) users matching the given API key prefix.
 func (r *PostgresRepository) FindByPrefix(ctx context.Context, prefix string) ([]User, error) {
 	query := `
-		SELECT id, name, team_id, is_superuser, api_key_prefix, api_key_hash,
+		SELECT id, name, team_id, organization_id, is_superuser, freeze_override, scopes, api_key_prefix, api_key_hash,
 		       created_at, revoked_at
 		FROM users
 		WHERE api_key_prefix = $1 AND revoked_at IS NULL`
@@ -83,7 +90,7 @@ func (r *PostgresRepository) FindByPrefix(ctx context.Context, prefix string) ([
 	for rows.Next() {
 		var u User
 		err := rows.Scan(
-			&u.ID, &u.Name, &u.TeamID, &u.IsSuperuser,
+			&u.ID, &u.Name, &u.TeamID, &u.OrganizationID, &u.IsSuperuser, &u.FreezeOverride, &u.Scopes,
 			&u.ApiKeyPrefix, &u.ApiKeyHash,
 			&u.CreatedAt, &u.RevokedAt,
 		)
@@ -107,7 +114,7 @@ func (r *PostgresRepository) FindByPrefix(ctx context.Context, prefix string) ([
 // Joins with teams to include team name and role in the result.
 func (r *PostgresRepository) List(ctx context.Context) ([]User, error) {
 	query := `
-		SELECT u.id, u.name, u.team_id, u.is_superuser, u.api_key_prefix,
+		SELECT u.id, u.name, u.team_id, u.organization_id, u.is_superuser, u.freeze_override, u.scopes, u.api_key_prefix,
 		       u.api_key_hash, u.created_at, u.revoked_at,
 		       t.name, t.role
 		FROM users u
@@ -124,7 +131,7 @@ func (r *PostgresRepository) List(ctx context.Context) ([]User, error) {
 	for rows.Next() {
 		var u User
 		err := rows.Scan(
-			&u.ID, &u.Name, &u.TeamID, &u.IsSuperuser,
+			&u.ID, &u.Name, &u.TeamID, &u.OrganizationID, &u.IsSuperuser, &u.FreezeOverride, &u.Scopes,
 			&u.ApiKeyPrefix, &u.ApiKeyHash,
 			&u.CreatedAt, &u.RevokedAt,
 			&u.TeamName, &u.TeamRole,
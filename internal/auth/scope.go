@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// Scope verbs. A request reads with GET, HEAD or OPTIONS, creates with POST
+// to a collection such as /databases or with an action of createActions,
+// deletes with DELETE, and updates otherwise, including actions such as
+// POST /databases/{id}/restart.
+const (
+	VerbRead   = "read"
+	VerbCreate = "create"
+	VerbUpdate = "update"
+	VerbDelete = "delete"
+)
+
+// ScopeRead is the scope of read-only keys: it allows reading any resource.
+const ScopeRead = "read"
+
+// ScopeResources are the resources scopes name: the first segment of the
+// paths of the API.
+var ScopeResources = []string{
+	"databases", "operations", "stats", "catalog", "tiers", "rollouts", "blueprints",
+	"providers", "organizations", "teams", "users", "freezes", "admin", "debug",
+}
+
+// createActions are the actions, POST /{resource}/{action} or
+// POST /{resource}/{id}/{action}, that create a resource rather than change
+// the one they are called on.
+var createActions = []string{
+	"clone",      // POST /tiers/{id}/clone
+	"dependents", // POST /databases/{id}/dependents
+	"invite",     // POST /users/invite
+	"promote",    // POST /databases/{id}/promote
+	"restore",    // POST /databases/{id}/restore
+}
+
+var scopeVerbs = []string{VerbRead, VerbCreate, VerbUpdate, VerbDelete, "*"}
+
+// ValidateScope checks that scope is ScopeRead or resource:verb, with a
+// resource of ScopeResources and a verb of read, create, update, delete or *.
+func ValidateScope(scope string) error {
+	if scope == ScopeRead {
+		return nil
+	}
+	resource, verb, ok := strings.Cut(scope, ":")
+	if !ok {
+		return fmt.Errorf("scope %q must be %q or resource:verb", scope, ScopeRead)
+	}
+	if !slices.Contains(ScopeResources, resource) {
+		return fmt.Errorf("scope %q names unknown resource %q", scope, resource)
+	}
+	if !slices.Contains(scopeVerbs, verb) {
+		return fmt.Errorf("scope %q has unknown verb %q; verbs are read, create, update, delete and *", scope, verb)
+	}
+	return nil
+}
+
+// RequestScope returns the resource and verb of a request with method to
+// path, as scopes name them.
+func RequestScope(method, path string) (resource, verb string) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	resource = segments[0]
+	switch {
+	case method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions:
+		verb = VerbRead
+	case method == http.MethodDelete:
+		verb = VerbDelete
+	case method == http.MethodPost && len(segments) == 1:
+		verb = VerbCreate
+	case method == http.MethodPost && len(segments) <= 3 && slices.Contains(createActions, segments[len(segments)-1]):
+		verb = VerbCreate
+	default:
+		verb = VerbUpdate
+	}
+	return resource, verb
+}
+
+// ScopesAllow reports whether a key with scopes may make a request with
+// method to path. A key without scopes is not restricted; scopes never
+// grant more than the role of the key's team. GET /me and POST /auth/check
+// change nothing and are always allowed.
+func ScopesAllow(scopes []string, method, path string) bool {
+	if len(scopes) == 0 {
+		return true
+	}
+	resource, verb := RequestScope(method, path)
+	if resource == "me" || resource == "auth" {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope == ScopeRead && verb == VerbRead {
+			return true
+		}
+		r, v, _ := strings.Cut(scope, ":")
+		if r == resource && (v == verb || v == "*") {
+			return true
+		}
+	}
+	return false
+}
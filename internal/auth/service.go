@@ -112,10 +112,14 @@ func (s *Service) BootstrapSuperuser(ctx context.Context) (string, error) {
 // buildIdentity constructs an Identity from a User, fetching team info if applicable.
 func (s *Service) buildIdentity(ctx context.Context, u *User) (*Identity, error) {
 	identity := &Identity{
-		UserID:      u.ID,
-		UserName:    u.Name,
-		TeamID:      u.TeamID,
-		IsSuperuser: u.IsSuperuser,
+		UserID:         u.ID,
+		UserName:       u.Name,
+		TeamID:         u.TeamID,
+		OrganizationID: u.OrganizationID,
+		IsSuperuser:    u.IsSuperuser,
+		FreezeOverride: u.FreezeOverride,
+		KeyPrefix:      u.ApiKeyPrefix,
+		Scopes:         u.Scopes,
 	}
 
 	if u.TeamID != nil {
@@ -125,6 +129,7 @@ func (s *Service) buildIdentity(ctx context.Context, u *User) (*Identity, error)
 		}
 		identity.TeamName = &t.Name
 		identity.Role = &t.Role
+		identity.OrganizationID = t.OrganizationID
 	}
 
 	return identity, nil
@@ -0,0 +1,309 @@
+// Package autoscale grows database storage according to the tier's storage
+// autoscaling policy. It polls storage usage through the provider and asks
+// the provider to expand the volume when usage crosses the policy threshold.
+package autoscale
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/metrics"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// gib is the granularity of new storage sizes.
+const gib = 1 << 30
+
+// Actor holds the database mutation lock during resizes.
+const Actor = "system:autoscaler"
+
+var (
+	storageResizes = metrics.NewCounter(
+		"daap_database_storage_resizes_total",
+		"Number of storage expansions requested by the storage autoscaler.",
+	)
+	storageResizeFailures = metrics.NewCounter(
+		"daap_database_storage_resize_failures_total",
+		"Number of storage expansions the provider rejected.",
+	)
+	storageLimitReached = metrics.NewCounter(
+		"daap_database_storage_limit_reached_total",
+		"Number of databases over their storage threshold that cannot grow past the tier's maximum size.",
+	)
+)
+
+// Collector polls storage usage of ready databases and expands their storage
+// when their tier's policy says so.
+type Collector struct {
+	repo     database.Repository
+	tierRepo tier.Repository
+	bpRepo   blueprint.Repository
+	registry *provider.Registry
+	events   database.ResizeEventRepository
+	interval time.Duration
+	notifier notify.Notifier
+	freezes  freeze.Gate
+	locker   *database.Locker
+
+	// limitWarned records databases already reported as stuck at their
+	// maximum size, keyed by capacity, so each limit is reported once.
+	mu          sync.Mutex
+	limitWarned map[uuid.UUID]int64
+}
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithNotifier sets the notifier used when a database reaches its tier's
+// maximum storage size. The default logs notifications.
+func WithNotifier(n notify.Notifier) Option {
+	return func(c *Collector) {
+		c.notifier = n
+	}
+}
+
+// WithFreezes defers resizes of databases whose team is under a change
+// freeze until the freeze ends.
+func WithFreezes(g freeze.Gate) Option {
+	return func(c *Collector) {
+		c.freezes = g
+	}
+}
+
+// WithLocker takes the database's mutation lock around each resize. A
+// database locked by another operation is resized on a later pass.
+func WithLocker(l *database.Locker) Option {
+	return func(c *Collector) {
+		c.locker = l
+	}
+}
+
+// New creates a new Collector.
+func New(repo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, events database.ResizeEventRepository, interval time.Duration, opts ...Option) *Collector {
+	c := &Collector{
+		repo:        repo,
+		tierRepo:    tierRepo,
+		bpRepo:      bpRepo,
+		registry:    registry,
+		events:      events,
+		interval:    interval,
+		notifier:    notify.LogNotifier{},
+		limitWarned: make(map[uuid.UUID]int64),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Start begins the collection loop. It blocks until ctx is cancelled.
+func (c *Collector) Start(ctx context.Context) {
+	slog.Info("storage autoscaler started", "interval", c.interval.String())
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("storage autoscaler stopped")
+			return
+		case <-ticker.C:
+			c.collect(ctx)
+		}
+	}
+}
+
+// RunOnce performs a single pass over all ready databases.
+func (c *Collector) RunOnce(ctx context.Context) {
+	c.collect(ctx)
+}
+
+func (c *Collector) collect(ctx context.Context) {
+	status := "ready"
+	result, err := c.repo.List(ctx, database.ListFilter{
+		Status: &status,
+		Page:   1,
+		Limit:  100,
+	})
+	if err != nil {
+		slog.Error("autoscale: failed to list databases", "error", err)
+		return
+	}
+
+	// Tiers and blueprints are shared by many databases; look each up once
+	// per pass.
+	tiers := map[uuid.UUID]*tier.Tier{}
+	for _, db := range result.Databases {
+		if ctx.Err() != nil {
+			return
+		}
+		c.checkOne(ctx, &db, tiers)
+	}
+}
+
+func (c *Collector) checkOne(ctx context.Context, db *database.Database, tiers map[uuid.UUID]*tier.Tier) {
+	if db.TierID == nil || db.ReconciliationPaused(time.Now()) {
+		return
+	}
+	t, ok := tiers[*db.TierID]
+	if !ok {
+		var err error
+		t, err = c.tierRepo.GetByID(ctx, *db.TierID)
+		if err != nil {
+			slog.Warn("autoscale: failed to get tier", "database", db.Name, "tierID", db.TierID, "error", err)
+			return
+		}
+		tiers[*db.TierID] = t
+	}
+	policy := t.StorageAutoscaling
+	if !policy.Enabled || t.BlueprintID == nil {
+		return
+	}
+
+	bp, err := c.bpRepo.GetByID(ctx, *t.BlueprintID)
+	if err != nil {
+		slog.Warn("autoscale: failed to get blueprint", "database", db.Name, "blueprintID", t.BlueprintID, "error", err)
+		return
+	}
+	p, ok := c.registry.Get(bp.Provider)
+	if !ok {
+		slog.Warn("autoscale: provider not registered", "database", db.Name, "provider", bp.Provider)
+		return
+	}
+	scaler, ok := p.(provider.StorageScaler)
+	if !ok {
+		return
+	}
+
+	pdb := db.ProviderDatabase(t, bp)
+	usage, err := scaler.StorageUsage(ctx, pdb)
+	if errors.Is(err, provider.ErrNotSupported) {
+		return
+	}
+	if err != nil {
+		slog.Warn("autoscale: failed to read storage usage", "database", db.Name, "provider", bp.Provider, "error", err)
+		return
+	}
+	if usage.CapacityBytes <= 0 || usage.UsedBytes*100 < int64(policy.ThresholdPercent)*usage.CapacityBytes {
+		return
+	}
+
+	maxBytes, err := maxSizeBytes(policy.MaxSize)
+	if err != nil {
+		slog.Warn("autoscale: invalid maximum storage size", "tier", t.Name, "maxSize", policy.MaxSize, "error", err)
+		return
+	}
+	target := NextSize(usage.CapacityBytes, policy.IncrementPercent, maxBytes)
+	if target <= usage.CapacityBytes {
+		c.reportLimit(ctx, db, usage, policy)
+		return
+	}
+
+	if c.freezes != nil {
+		window, err := c.freezes.Frozen(ctx, db.OwnerTeamID)
+		if err != nil {
+			slog.Warn("autoscale: failed to check change freezes", "database", db.Name, "error", err)
+			return
+		}
+		if window != nil {
+			slog.Info("autoscale: resize deferred by change freeze", "database", db.Name, "freeze", window.ID, "until", window.EndsAt)
+			return
+		}
+	}
+
+	if c.locker != nil {
+		lock, err := c.locker.Acquire(ctx, db.ID, "resize", Actor, "")
+		if err != nil {
+			slog.Info("autoscale: resize deferred", "database", db.Name, "reason", err)
+			return
+		}
+		defer func() {
+			if err := c.locker.Release(ctx, lock); err != nil {
+				slog.Warn("autoscale: failed to release database lock", "database", db.Name, "error", err)
+			}
+		}()
+	}
+
+	if err := scaler.ResizeStorage(ctx, pdb, target); err != nil {
+		storageResizeFailures.Inc()
+		slog.Error("autoscale: failed to resize storage", "database", db.Name, "from", usage.CapacityBytes, "to", target, "error", err)
+		return
+	}
+	storageResizes.Inc()
+	slog.Info("autoscale: storage resized",
+		"database", db.Name,
+		"from", resource.NewQuantity(usage.CapacityBytes, resource.BinarySI).String(),
+		"to", resource.NewQuantity(target, resource.BinarySI).String(),
+		"usedBytes", usage.UsedBytes,
+	)
+
+	event := &database.ResizeEvent{
+		DatabaseID: db.ID,
+		FromBytes:  usage.CapacityBytes,
+		ToBytes:    target,
+		UsedBytes:  usage.UsedBytes,
+	}
+	if err := c.events.Record(ctx, event); err != nil {
+		slog.Error("autoscale: failed to record resize event", "database", db.Name, "error", err)
+	}
+}
+
+// reportLimit notifies once per capacity that a database over its threshold
+// cannot grow further.
+func (c *Collector) reportLimit(ctx context.Context, db *database.Database, usage provider.StorageUsage, policy tier.StorageAutoscaling) {
+	c.mu.Lock()
+	warned := c.limitWarned[db.ID] == usage.CapacityBytes
+	c.limitWarned[db.ID] = usage.CapacityBytes
+	c.mu.Unlock()
+	if warned {
+		return
+	}
+
+	storageLimitReached.Inc()
+	n := notify.Notification{
+		Event: "StorageLimitReached",
+		Message: fmt.Sprintf("database %s uses %d%% of its storage and is at its tier's maximum size %s",
+			db.Name, usage.UsedBytes*100/usage.CapacityBytes, policy.MaxSize),
+		DatabaseID: db.ID,
+		Database:   db.Name,
+		OwnerTeam:  db.OwnerTeamName,
+		Time:       time.Now().UTC(),
+	}
+	if err := c.notifier.Notify(ctx, n); err != nil {
+		slog.Error("autoscale: failed to send storage limit notification", "database", db.Name, "error", err)
+	}
+}
+
+// NextSize returns the storage size after growing capacity by incrementPercent,
+// rounded up to a whole GiB and capped at maxBytes when maxBytes is positive.
+func NextSize(capacity int64, incrementPercent int, maxBytes int64) int64 {
+	grown := capacity * int64(100+incrementPercent) / 100
+	grown = (grown + gib - 1) / gib * gib
+	if maxBytes > 0 && grown > maxBytes {
+		grown = maxBytes
+	}
+	return grown
+}
+
+func maxSizeBytes(maxSize string) (int64, error) {
+	if maxSize == "" {
+		return 0, nil
+	}
+	q, err := resource.ParseQuantity(maxSize)
+	if err != nil {
+		return 0, err
+	}
+	return q.Value(), nil
+}
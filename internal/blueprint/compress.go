@@ -0,0 +1,42 @@
+package blueprint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+)
+
+// CompressManifests gzips manifests for storage. Blueprint manifests are
+// repetitive YAML, so this keeps blueprint rows, their versions and database
+// backups a fraction of the size of the text.
+func CompressManifests(manifests string) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write([]byte(manifests)); err != nil {
+		return nil, fmt.Errorf("compressing manifests: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("compressing manifests: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// DecompressManifests returns the manifests of a stored row: compressed
+// decompressed, or text when compressed is nil, as in rows written before
+// manifests were compressed.
+func DecompressManifests(text string, compressed []byte) (string, error) {
+	if compressed == nil {
+		return text, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return "", fmt.Errorf("decompressing manifests: %w", err)
+	}
+	defer zr.Close()
+	manifests, err := io.ReadAll(zr)
+	if err != nil {
+		return "", fmt.Errorf("decompressing manifests: %w", err)
+	}
+	return string(manifests), nil
+}
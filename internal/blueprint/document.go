@@ -0,0 +1,61 @@
+package blueprint
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Document describes one YAML document of a blueprint's manifests: what it
+// creates. Values set by template actions keep the action text, e.g. a name
+// of "{{ .ClusterName }}".
+type Document struct {
+	Index      int    `json:"index"` // position in the manifests, from 0
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Namespace  string `json:"namespace"`
+}
+
+// templateToken matches the tokens ParseDocuments stands in for template
+// actions, capturing the index of the action.
+var templateToken = regexp.MustCompile(`__template_(\d+)__`)
+
+// ParseDocuments splits manifests into their documents, in order, with the
+// apiVersion, kind, metadata.name and metadata.namespace of each. Documents
+// that are not valid YAML with template actions replaced, e.g. because of
+// template control structures, are listed with only their index.
+func ParseDocuments(manifests string) []Document {
+	docs := []Document{}
+	for i, text := range splitDocuments(manifests) {
+		var actions []string
+		text = templateAction.ReplaceAllStringFunc(text, func(action string) string {
+			actions = append(actions, action)
+			return fmt.Sprintf("__template_%d__", len(actions)-1)
+		})
+		restore := func(v any) string {
+			s, _ := v.(string)
+			return templateToken.ReplaceAllStringFunc(s, func(token string) string {
+				n, _ := strconv.Atoi(templateToken.FindStringSubmatch(token)[1])
+				if n >= len(actions) {
+					return token // in the manifests themselves
+				}
+				return actions[n]
+			})
+		}
+
+		doc := Document{Index: i}
+		var obj map[string]any
+		if err := sigsyaml.Unmarshal([]byte(text), &obj); err == nil && obj != nil {
+			metadata := nested(obj, "metadata")
+			doc.APIVersion = restore(obj["apiVersion"])
+			doc.Kind = restore(obj["kind"])
+			doc.Name = restore(metadata["name"])
+			doc.Namespace = restore(metadata["namespace"])
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
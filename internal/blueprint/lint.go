@@ -0,0 +1,234 @@
+package blueprint
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	sigsyaml "sigs.k8s.io/yaml"
+)
+
+// Lint rules checked on blueprint manifests.
+const (
+	// RuleResourceRequests requires CPU and memory requests on CNPG
+	// Clusters and on the containers of pod templates.
+	RuleResourceRequests = "resource-requests"
+	// RuleNoLatestTag rejects images tagged latest or not tagged at all.
+	RuleNoLatestTag = "no-latest-tag"
+	// RuleRequiredLabels requires LintConfig.RequiredLabels on every
+	// document.
+	RuleRequiredLabels = "required-labels"
+)
+
+// LintRules lists the lint rules.
+var LintRules = []string{RuleResourceRequests, RuleNoLatestTag, RuleRequiredLabels}
+
+// Severity says what a lint rule's findings do.
+type Severity string
+
+// Severities.
+const (
+	SeverityOff     Severity = "off"
+	SeverityWarning Severity = "warning"
+	SeverityError   Severity = "error"
+)
+
+// LintConfig configures Lint.
+type LintConfig struct {
+	// Severities maps rules to their severity. Rules not listed are
+	// warnings.
+	Severities map[string]Severity
+	// RequiredLabels are the label keys RuleRequiredLabels requires.
+	RequiredLabels []string
+}
+
+// ParseSeverities parses rule:severity pairs, such as the
+// BLUEPRINT_LINT_RULES setting, rejecting unknown rules and severities.
+func ParseSeverities(pairs map[string]string) (map[string]Severity, error) {
+	severities := make(map[string]Severity, len(pairs))
+	for rule, s := range pairs {
+		if !slices.Contains(LintRules, rule) {
+			return nil, fmt.Errorf("unknown lint rule %q (want one of: %s)", rule, strings.Join(LintRules, ", "))
+		}
+		switch sev := Severity(s); sev {
+		case SeverityOff, SeverityWarning, SeverityError:
+			severities[rule] = sev
+		default:
+			return nil, fmt.Errorf("lint rule %s: unknown severity %q (want off, warning or error)", rule, s)
+		}
+	}
+	return severities, nil
+}
+
+func (c LintConfig) severity(rule string) Severity {
+	if s, ok := c.Severities[rule]; ok {
+		return s
+	}
+	return SeverityWarning
+}
+
+// Finding is a lint rule violation in one manifest document.
+type Finding struct {
+	Rule     string
+	Severity Severity
+	Document int
+	Message  string
+}
+
+// String formats f as "[rule] document N: message".
+func (f Finding) String() string {
+	return fmt.Sprintf("[%s] document %d: %s", f.Rule, f.Document, f.Message)
+}
+
+// templatePlaceholder stands in for template actions so that manifests can
+// be parsed as YAML before rendering.
+const templatePlaceholder = "__template__"
+
+var templateAction = regexp.MustCompile(`\{\{.*?\}\}`)
+
+// Lint checks manifests against the enabled rules. Template actions are
+// replaced by a placeholder first; documents that are still not valid YAML,
+// e.g. because of template control structures, are skipped, and so are
+// values that come from templates.
+func Lint(manifests string, cfg LintConfig) []Finding {
+	var findings []Finding
+	text := templateAction.ReplaceAllString(manifests, templatePlaceholder)
+	for i, doc := range splitDocuments(text) {
+		var obj map[string]any
+		if err := sigsyaml.Unmarshal([]byte(doc), &obj); err != nil || obj == nil {
+			continue
+		}
+		kind, _ := obj["kind"].(string)
+
+		report := func(rule, format string, args ...any) {
+			sev := cfg.severity(rule)
+			if sev == SeverityOff {
+				return
+			}
+			findings = append(findings, Finding{
+				Rule:     rule,
+				Severity: sev,
+				Document: i,
+				Message:  kind + ": " + fmt.Sprintf(format, args...),
+			})
+		}
+
+		for _, path := range missingRequests(obj) {
+			report(RuleResourceRequests, "%s is not set", path)
+		}
+		refs := images(obj)
+		slices.Sort(refs)
+		for _, image := range refs {
+			if !pinned(image) {
+				report(RuleNoLatestTag, "image %s must be pinned to a tag other than latest or a digest", image)
+			}
+		}
+		labels := nested(obj, "metadata", "labels")
+		for _, key := range cfg.RequiredLabels {
+			if _, ok := labels[key]; !ok {
+				report(RuleRequiredLabels, "metadata.labels is missing %s", key)
+			}
+		}
+	}
+	return findings
+}
+
+// missingRequests returns the paths of the CPU and memory requests obj
+// should set but does not.
+func missingRequests(obj map[string]any) []string {
+	var missing []string
+	check := func(resources map[string]any, prefix string) {
+		requests, _ := resources["requests"].(map[string]any)
+		for _, r := range []string{"cpu", "memory"} {
+			if _, ok := requests[r]; !ok {
+				missing = append(missing, prefix+".requests."+r)
+			}
+		}
+	}
+
+	apiVersion, _ := obj["apiVersion"].(string)
+	if obj["kind"] == "Cluster" && strings.HasPrefix(apiVersion, "postgresql.cnpg.io/") {
+		check(nested(obj, "spec", "resources"), "spec.resources")
+	}
+	containers, _ := nested(obj, "spec", "template", "spec")["containers"].([]any)
+	for i, c := range containers {
+		container, _ := c.(map[string]any)
+		resources, _ := container["resources"].(map[string]any)
+		check(resources, fmt.Sprintf("spec.template.spec.containers[%d].resources", i))
+	}
+	return missing
+}
+
+// Images returns the image references manifests set, under "image" or
+// "imageName" keys, sorted and without duplicates. References that come from
+// templates are skipped, like documents that are not valid YAML.
+func Images(manifests string) []string {
+	var refs []string
+	text := templateAction.ReplaceAllString(manifests, templatePlaceholder)
+	for _, doc := range splitDocuments(text) {
+		var obj map[string]any
+		if err := sigsyaml.Unmarshal([]byte(doc), &obj); err != nil {
+			continue
+		}
+		refs = append(refs, images(obj)...)
+	}
+	slices.Sort(refs)
+	return slices.Compact(refs)
+}
+
+// images returns the image references set anywhere in v, under "image" or
+// "imageName" keys, skipping those that come from templates.
+func images(v any) []string {
+	var refs []string
+	switch v := v.(type) {
+	case map[string]any:
+		for k, child := range v {
+			if s, ok := child.(string); ok && (k == "image" || k == "imageName") {
+				if !strings.Contains(s, templatePlaceholder) {
+					refs = append(refs, s)
+				}
+				continue
+			}
+			refs = append(refs, images(child)...)
+		}
+	case []any:
+		for _, child := range v {
+			refs = append(refs, images(child)...)
+		}
+	}
+	return refs
+}
+
+// pinned reports whether image names a digest or a tag other than latest.
+func pinned(image string) bool {
+	if strings.Contains(image, "@") {
+		return true
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	_, tag, ok := strings.Cut(name, ":")
+	return ok && tag != "" && tag != "latest"
+}
+
+// nested returns the map at path in obj, or nil.
+func nested(obj map[string]any, path ...string) map[string]any {
+	m := obj
+	for _, key := range path {
+		m, _ = m[key].(map[string]any)
+	}
+	return m
+}
+
+// splitDocuments splits a multi-document YAML string on "---" separators,
+// discarding empty documents.
+func splitDocuments(yaml string) []string {
+	var docs []string
+	for _, part := range strings.Split(yaml, "\n---") {
+		trimmed := strings.TrimSpace(part)
+		if trimmed == "" || trimmed == "---" {
+			continue
+		}
+		docs = append(docs, trimmed)
+	}
+	return docs
+}
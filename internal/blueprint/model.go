@@ -8,10 +8,56 @@ import (
 
 // Blueprint represents a row in the blueprints table.
 type Blueprint struct {
-	ID        uuid.UUID
-	Name      string
-	Provider  string
-	Manifests string
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Provider    string
+	Manifests   string
+	Documents   []Document // parsed from Manifests when they are saved
+	Version     int        // starts at 1, incremented whenever the manifests change
+	Signature   string     // made by a Signer when the content was last saved; empty if unsigned
+	CreatedBy   string     // user name of the creator; empty for blueprints created before it was recorded
+	UpdatedBy   string     // user name of the last change
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+
+	// TeamID or OrganizationID, at most one of them, limits who sees the
+	// blueprint to the members of a team or an organization. Blueprints
+	// with neither are global.
+	TeamID         *uuid.UUID
+	OrganizationID *uuid.UUID
+}
+
+// VisibleTo reports whether the blueprint is visible to a member of the team
+// teamID in the organization organizationID, either of which may be nil.
+func (bp *Blueprint) VisibleTo(teamID, organizationID *uuid.UUID) bool {
+	switch {
+	case bp.TeamID != nil:
+		return teamID != nil && *teamID == *bp.TeamID
+	case bp.OrganizationID != nil:
+		return organizationID != nil && *organizationID == *bp.OrganizationID
+	default:
+		return true
+	}
+}
+
+// UpdateFields holds the optional fields for a blueprint update. Nil fields
+// are left unchanged.
+type UpdateFields struct {
+	Description *string
+	Manifests   *string
+	Signature   *string // replaces the signature; it is not versioned
+
+	// UpdatedBy, when set, records who made the update. It is not an update
+	// on its own.
+	UpdatedBy string
+}
+
+// Version is a past or current revision of a blueprint's manifests, a row of
+// the blueprint_versions table.
+type Version struct {
+	BlueprintID uuid.UUID
+	Version     int
+	Manifests   string
+	CreatedAt   time.Time
 }
@@ -22,14 +22,17 @@ func NewPostgresRepository(pool *pgxpool.Pool) Repository {
 }
 
 // allColumns is the ordered list of columns scanned from the blueprints table.
-const allColumns = `id, name, provider, manifests, created_at, updated_at`
+const allColumns = `id, name, description, provider, manifests, manifests_gz, documents, team_id, organization_id, version, signature, created_by, updated_by, created_at, updated_at`
 
-// scanBlueprint scans a single Blueprint from a row.
+// scanBlueprint scans a single Blueprint from a row, decompressing its
+// manifests. Documents are parsed from the manifests of rows saved before
+// they were stored.
 func scanBlueprint(row pgx.Row) (*Blueprint, error) {
 	var bp Blueprint
+	var compressed []byte
 	err := row.Scan(
-		&bp.ID, &bp.Name, &bp.Provider, &bp.Manifests,
-		&bp.CreatedAt, &bp.UpdatedAt,
+		&bp.ID, &bp.Name, &bp.Description, &bp.Provider, &bp.Manifests, &compressed, &bp.Documents,
+		&bp.TeamID, &bp.OrganizationID, &bp.Version, &bp.Signature, &bp.CreatedBy, &bp.UpdatedBy, &bp.CreatedAt, &bp.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -37,17 +40,35 @@ func scanBlueprint(row pgx.Row) (*Blueprint, error) {
 		}
 		return nil, fmt.Errorf("scanning blueprint row: %w", err)
 	}
+	if bp.Manifests, err = DecompressManifests(bp.Manifests, compressed); err != nil {
+		return nil, fmt.Errorf("blueprint %s: %w", bp.ID, err)
+	}
+	if bp.Documents == nil {
+		bp.Documents = ParseDocuments(bp.Manifests)
+	}
 	return &bp, nil
 }
 
-// Create inserts a new blueprint record.
+// Create inserts a new blueprint record and its first version.
 func (r *PostgresRepository) Create(ctx context.Context, bp *Blueprint) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	compressed, err := CompressManifests(bp.Manifests)
+	if err != nil {
+		return err
+	}
+
 	query := fmt.Sprintf(`
-		INSERT INTO blueprints (name, provider, manifests)
-		VALUES ($1, $2, $3)
+		INSERT INTO blueprints (name, description, provider, manifests, manifests_gz, documents, team_id, organization_id, signature, created_by, updated_by)
+		VALUES ($1, $2, $3, '', $4, $5, $6, $7, $8, $9, $9)
 		RETURNING %s`, allColumns)
 
-	row := r.pool.QueryRow(ctx, query, bp.Name, bp.Provider, bp.Manifests)
+	row := tx.QueryRow(ctx, query, bp.Name, bp.Description, bp.Provider, compressed, ParseDocuments(bp.Manifests),
+		bp.TeamID, bp.OrganizationID, bp.Signature, bp.CreatedBy)
 
 	created, err := scanBlueprint(row)
 	if err != nil {
@@ -57,11 +78,33 @@ func (r *PostgresRepository) Create(ctx context.Context, bp *Blueprint) error {
 		}
 		return fmt.Errorf("inserting blueprint: %w", err)
 	}
+	if err := insertVersion(ctx, tx, created); err != nil {
+		return err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing blueprint: %w", err)
+	}
 
 	*bp = *created
 	return nil
 }
 
+// insertVersion records bp's current manifests as its current version.
+func insertVersion(ctx context.Context, tx pgx.Tx, bp *Blueprint) error {
+	compressed, err := CompressManifests(bp.Manifests)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO blueprint_versions (blueprint_id, version, manifests, manifests_gz, created_at)
+		VALUES ($1, $2, '', $3, $4)`,
+		bp.ID, bp.Version, compressed, bp.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting blueprint version: %w", err)
+	}
+	return nil
+}
+
 // GetByID retrieves a single blueprint by its UUID.
 func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Blueprint, error) {
 	query := fmt.Sprintf(`SELECT %s FROM blueprints WHERE id = $1`, allColumns)
@@ -86,15 +129,11 @@ func (r *PostgresRepository) List(ctx context.Context) ([]Blueprint, error) {
 
 	var blueprints []Blueprint
 	for rows.Next() {
-		var bp Blueprint
-		err := rows.Scan(
-			&bp.ID, &bp.Name, &bp.Provider, &bp.Manifests,
-			&bp.CreatedAt, &bp.UpdatedAt,
-		)
+		bp, err := scanBlueprint(rows)
 		if err != nil {
-			return nil, fmt.Errorf("scanning blueprint row: %w", err)
+			return nil, err
 		}
-		blueprints = append(blueprints, bp)
+		blueprints = append(blueprints, *bp)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating blueprint rows: %w", err)
@@ -107,6 +146,100 @@ func (r *PostgresRepository) List(ctx context.Context) ([]Blueprint, error) {
 	return blueprints, nil
 }
 
+// Update applies the non-nil fields in one transaction. When the manifests
+// change, the version is incremented and the new manifests recorded; a new
+// signature alone is an update, but not a new version.
+func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Blueprint, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	current, err := scanBlueprint(tx.QueryRow(ctx,
+		fmt.Sprintf(`SELECT %s FROM blueprints WHERE id = $1 FOR UPDATE`, allColumns), id))
+	if err != nil {
+		return nil, err
+	}
+
+	description, manifests, version, signature := current.Description, current.Manifests, current.Version, current.Signature
+	if fields.Description != nil {
+		description = *fields.Description
+	}
+	if fields.Signature != nil {
+		signature = *fields.Signature
+	}
+	if fields.Manifests != nil && *fields.Manifests != current.Manifests {
+		manifests = *fields.Manifests
+		version++
+	}
+	if description == current.Description && version == current.Version && signature == current.Signature {
+		return current, nil
+	}
+	updatedBy := current.UpdatedBy
+	if fields.UpdatedBy != "" {
+		updatedBy = fields.UpdatedBy
+	}
+
+	compressed, err := CompressManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := scanBlueprint(tx.QueryRow(ctx, fmt.Sprintf(`
+		UPDATE blueprints
+		SET description = $2, manifests = '', manifests_gz = $3, documents = $4, version = $5, signature = $6, updated_by = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING %s`, allColumns),
+		id, description, compressed, ParseDocuments(manifests), version, signature, updatedBy))
+	if err != nil {
+		return nil, fmt.Errorf("updating blueprint: %w", err)
+	}
+	if updated.Version != current.Version {
+		if err := insertVersion(ctx, tx, updated); err != nil {
+			return nil, err
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing blueprint update: %w", err)
+	}
+	return updated, nil
+}
+
+// ListVersions returns the versions of a blueprint, newest first.
+func (r *PostgresRepository) ListVersions(ctx context.Context, id uuid.UUID) ([]Version, error) {
+	if _, err := r.GetByID(ctx, id); err != nil {
+		return nil, err
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT blueprint_id, version, manifests, manifests_gz, created_at
+		FROM blueprint_versions
+		WHERE blueprint_id = $1
+		ORDER BY version DESC`, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing blueprint versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := []Version{}
+	for rows.Next() {
+		var v Version
+		var compressed []byte
+		if err := rows.Scan(&v.BlueprintID, &v.Version, &v.Manifests, &compressed, &v.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning blueprint version row: %w", err)
+		}
+		if v.Manifests, err = DecompressManifests(v.Manifests, compressed); err != nil {
+			return nil, fmt.Errorf("blueprint %s version %d: %w", v.BlueprintID, v.Version, err)
+		}
+		versions = append(versions, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating blueprint version rows: %w", err)
+	}
+	return versions, nil
+}
+
 // Delete removes a blueprint by its UUID. Returns ErrBlueprintHasTiers if the
 // blueprint is referenced by any tier (FK violation).
 func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
@@ -16,12 +16,19 @@ var ErrDuplicateBlueprintName = errors.New("blueprint name already exists")
 // ErrBlueprintHasTiers is returned when attempting to delete a blueprint that is referenced by tiers.
 var ErrBlueprintHasTiers = errors.New("blueprint has tiers")
 
-// Repository provides CRUD operations on the blueprints table.
-// Blueprints are immutable — there is no Update method.
+// Repository provides CRUD operations on the blueprints table. Every
+// revision of a blueprint's manifests is kept as a Version.
 type Repository interface {
+	// Create inserts a blueprint and records its manifests as version 1.
 	Create(ctx context.Context, bp *Blueprint) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Blueprint, error)
 	GetByName(ctx context.Context, name string) (*Blueprint, error)
 	List(ctx context.Context) ([]Blueprint, error)
+	// Update applies the non-nil fields. Changed manifests get the next
+	// version number and are recorded as a new Version; a changed signature
+	// does not.
+	Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Blueprint, error)
+	// ListVersions returns the versions of a blueprint, newest first.
+	ListVersions(ctx context.Context, id uuid.UUID) ([]Version, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
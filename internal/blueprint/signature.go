@@ -0,0 +1,73 @@
+package blueprint
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrUnsigned is returned by Verify for a blueprint that has no signature.
+var ErrUnsigned = errors.New("blueprint is not signed")
+
+// ErrSignatureMismatch is returned by Verify for a blueprint whose content no
+// longer matches its signature.
+var ErrSignatureMismatch = errors.New("blueprint content does not match its signature")
+
+// signaturePrefix names the algorithm of the signatures a Signer makes.
+const signaturePrefix = "hmac-sha256:"
+
+// ContentHash returns the SHA-256 hash of what a blueprint provisions: its
+// name, provider and manifests, as "sha256:<hex>".
+func ContentHash(bp *Blueprint) string {
+	h := sha256.New()
+	for _, part := range []string{bp.Name, bp.Provider, bp.Manifests} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// Signer signs blueprints with an HMAC of their content hash under a key only
+// DAAP holds, so that content changed in the platform database, rather than
+// through the API, no longer matches its signature. A nil Signer signs
+// nothing and verifies every blueprint.
+type Signer struct {
+	key []byte
+}
+
+// NewSigner returns a Signer with key, or nil if key is empty.
+func NewSigner(key []byte) *Signer {
+	if len(key) == 0 {
+		return nil
+	}
+	return &Signer{key: key}
+}
+
+// Sign returns the signature of bp's current content, or "" for a nil Signer.
+func (s *Signer) Sign(bp *Blueprint) string {
+	if s == nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(ContentHash(bp)))
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify returns ErrUnsigned if bp has no signature and ErrSignatureMismatch
+// if its content does not match the signature, wrapped with its name. A nil
+// Signer verifies every blueprint.
+func (s *Signer) Verify(bp *Blueprint) error {
+	if s == nil {
+		return nil
+	}
+	if bp.Signature == "" {
+		return fmt.Errorf("blueprint %s: %w", bp.Name, ErrUnsigned)
+	}
+	if !strings.HasPrefix(bp.Signature, signaturePrefix) || !hmac.Equal([]byte(bp.Signature), []byte(s.Sign(bp))) {
+		return fmt.Errorf("blueprint %s: %w", bp.Name, ErrSignatureMismatch)
+	}
+	return nil
+}
@@ -0,0 +1,179 @@
+// Package breaker implements a circuit breaker for calls to external systems
+// (the Kubernetes API server, providers). After a run of consecutive failures
+// the breaker opens and rejects calls immediately with ErrOpen; after a
+// cooldown it lets a single probe through (half-open) and closes again if the
+// probe succeeds.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned (wrapped) when a call is rejected by an open breaker.
+var ErrOpen = errors.New("circuit breaker is open")
+
+// State is the state of a circuit breaker.
+type State int
+
+const (
+	// Closed lets every call through and counts consecutive failures.
+	Closed State = iota
+	// Open rejects every call until the cooldown elapses.
+	Open
+	// HalfOpen lets a single probe call through.
+	HalfOpen
+)
+
+// String returns the lowercase name of the state.
+func (s State) String() string {
+	switch s {
+	case Closed:
+		return "closed"
+	case Open:
+		return "open"
+	case HalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// Config configures a Breaker.
+type Config struct {
+	// Name identifies the breaker in errors and logs.
+	Name string
+	// FailureThreshold is the number of consecutive failures that opens the
+	// breaker. Defaults to 5.
+	FailureThreshold int
+	// Cooldown is how long the breaker stays open before probing. Defaults to 30s.
+	Cooldown time.Duration
+	// IsFailure decides whether an error returned by Do counts as a failure.
+	// Defaults to any non-nil error except context.Canceled.
+	IsFailure func(error) bool
+}
+
+// Breaker is a consecutive-failure circuit breaker. It is safe for concurrent use.
+type Breaker struct {
+	cfg Config
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// New creates a closed Breaker.
+func New(cfg Config) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(err error) bool {
+			return err != nil && !errors.Is(err, context.Canceled)
+		}
+	}
+	return &Breaker{cfg: cfg}
+}
+
+// Name returns the breaker's name.
+func (b *Breaker) Name() string {
+	return b.cfg.Name
+}
+
+// State returns the current state, moving from open to half-open if the
+// cooldown has elapsed.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == Open && time.Since(b.openedAt) >= b.cfg.Cooldown {
+		return HalfOpen
+	}
+	return b.state
+}
+
+// Allow reports whether a call may proceed. Callers that get nil must report
+// the outcome with Success or Failure.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < b.cfg.Cooldown {
+			return fmt.Errorf("%s: %w", b.cfg.Name, ErrOpen)
+		}
+		b.setState(HalfOpen)
+		b.probing = true
+		return nil
+	case HalfOpen:
+		if b.probing {
+			return fmt.Errorf("%s: %w", b.cfg.Name, ErrOpen)
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// Success records a successful call.
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	if b.state != Closed {
+		b.setState(Closed)
+	}
+}
+
+// Failure records a failed call.
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	b.failures++
+	if b.state == HalfOpen || b.failures >= b.cfg.FailureThreshold {
+		b.openedAt = time.Now()
+		if b.state != Open {
+			b.setState(Open)
+		}
+	}
+}
+
+// Do runs fn if the breaker allows it and records the outcome using
+// Config.IsFailure. Errors that are not failures (e.g. not found) count as
+// successes, since they prove the backend is reachable.
+func (b *Breaker) Do(fn func() error) error {
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn()
+	if b.cfg.IsFailure(err) {
+		b.Failure()
+	} else {
+		b.Success()
+	}
+	return err
+}
+
+// setState transitions and logs. Callers must hold b.mu.
+func (b *Breaker) setState(s State) {
+	prev := b.state
+	b.state = s
+	level := slog.LevelInfo
+	if s == Open {
+		level = slog.LevelWarn
+	}
+	slog.Log(context.Background(), level, "circuit breaker state changed",
+		"breaker", b.cfg.Name, "from", prev.String(), "to", s.String(), "failures", b.failures)
+}
@@ -0,0 +1,368 @@
+package breaker
+
+import (
+	"context"
+	"time"
+
+	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// Provider wraps a provider.Provider so that its calls go through a breaker.
+// It implements every optional provider interface, whatever the wrapped
+// provider implements: those the wrapped provider lacks return
+// provider.ErrNotSupported without going through the breaker. Use
+// provider.Supports to tell which it does implement.
+type Provider struct {
+	provider.Provider
+	b *Breaker
+}
+
+// WrapProvider returns p guarded by b.
+func WrapProvider(p provider.Provider, b *Breaker) *Provider {
+	return &Provider{Provider: p, b: b}
+}
+
+// Unwrap returns the wrapped provider.
+func (p *Provider) Unwrap() provider.Provider {
+	return p.Provider
+}
+
+// Apply runs the wrapped Apply through the breaker.
+func (p *Provider) Apply(ctx context.Context, db provider.ProviderDatabase, manifests string) error {
+	return p.b.Do(func() error { return p.Provider.Apply(ctx, db, manifests) })
+}
+
+// Delete runs the wrapped Delete through the breaker.
+func (p *Provider) Delete(ctx context.Context, db provider.ProviderDatabase) error {
+	return p.b.Do(func() error { return p.Provider.Delete(ctx, db) })
+}
+
+// CheckHealth runs the wrapped CheckHealth through the breaker.
+func (p *Provider) CheckHealth(ctx context.Context, db provider.ProviderDatabase) (provider.HealthResult, error) {
+	var result provider.HealthResult
+	err := p.b.Do(func() error {
+		var err error
+		result, err = p.Provider.CheckHealth(ctx, db)
+		return err
+	})
+	return result, err
+}
+
+// DeleteForeground runs the wrapped provider's DeleteForeground through the
+// breaker. It returns provider.ErrNotSupported if the wrapped provider cannot
+// confirm deletions.
+func (p *Provider) DeleteForeground(ctx context.Context, db provider.ProviderDatabase, wait time.Duration) (provider.DeletionState, error) {
+	confirmer, ok := p.Provider.(provider.DeletionConfirmer)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	var state provider.DeletionState
+	err := p.b.Do(func() error {
+		var err error
+		state, err = confirmer.DeleteForeground(ctx, db, wait)
+		return err
+	})
+	return state, err
+}
+
+// Switchover runs the wrapped provider's Switchover through the breaker. It
+// returns provider.ErrNotSupported if the wrapped provider cannot switch
+// primaries.
+func (p *Provider) Switchover(ctx context.Context, db provider.ProviderDatabase, target string) (string, error) {
+	switcher, ok := p.Provider.(provider.PrimarySwitcher)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	var promoted string
+	err := p.b.Do(func() error {
+		var err error
+		promoted, err = switcher.Switchover(ctx, db, target)
+		return err
+	})
+	return promoted, err
+}
+
+// Restart runs the wrapped provider's Restart through the breaker. It returns
+// provider.ErrNotSupported if the wrapped provider cannot restart databases.
+func (p *Provider) Restart(ctx context.Context, db provider.ProviderDatabase) error {
+	restarter, ok := p.Provider.(provider.Restarter)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error {
+		return restarter.Restart(ctx, db)
+	})
+}
+
+// Restarted runs the wrapped provider's Restarted through the breaker. It
+// returns provider.ErrNotSupported if the wrapped provider cannot restart
+// databases.
+func (p *Provider) Restarted(ctx context.Context, db provider.ProviderDatabase) (bool, error) {
+	restarter, ok := p.Provider.(provider.Restarter)
+	if !ok {
+		return false, provider.ErrNotSupported
+	}
+	var done bool
+	err := p.b.Do(func() error {
+		var err error
+		done, err = restarter.Restarted(ctx, db)
+		return err
+	})
+	return done, err
+}
+
+// Freeze runs the wrapped provider's Freeze through the breaker. It returns
+// provider.ErrNotSupported if the wrapped provider cannot freeze databases.
+func (p *Provider) Freeze(ctx context.Context, db provider.ProviderDatabase) error {
+	freezer, ok := p.Provider.(provider.Freezer)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error {
+		return freezer.Freeze(ctx, db)
+	})
+}
+
+// Unfreeze runs the wrapped provider's Unfreeze through the breaker. It
+// returns provider.ErrNotSupported if the wrapped provider cannot freeze
+// databases.
+func (p *Provider) Unfreeze(ctx context.Context, db provider.ProviderDatabase) error {
+	freezer, ok := p.Provider.(provider.Freezer)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error {
+		return freezer.Unfreeze(ctx, db)
+	})
+}
+
+// OperatorVersion runs the wrapped provider's OperatorVersion through the
+// breaker. It returns provider.ErrNotSupported if the wrapped provider does
+// not report operator versions.
+func (p *Provider) OperatorVersion(ctx context.Context, db provider.ProviderDatabase) (string, error) {
+	versioner, ok := p.Provider.(provider.OperatorVersioner)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	var version string
+	err := p.b.Do(func() error {
+		var err error
+		version, err = versioner.OperatorVersion(ctx, db)
+		return err
+	})
+	return version, err
+}
+
+// Diagnostics runs the wrapped provider's Diagnostics through the breaker. It
+// returns provider.ErrNotSupported if the wrapped provider cannot gather
+// diagnostics. Only a call that gathered nothing counts as a failure, so a
+// partial bundle does not trip the breaker.
+func (p *Provider) Diagnostics(ctx context.Context, db provider.ProviderDatabase) ([]provider.DiagnosticFile, error) {
+	diagnoser, ok := p.Provider.(provider.Diagnoser)
+	if !ok {
+		return nil, provider.ErrNotSupported
+	}
+	var files []provider.DiagnosticFile
+	var partial error
+	err := p.b.Do(func() error {
+		var err error
+		files, err = diagnoser.Diagnostics(ctx, db)
+		if err != nil && len(files) > 0 {
+			partial = err
+			return nil
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, partial
+}
+
+// Archive runs the wrapped provider's Archive through the breaker. It returns
+// provider.ErrNotSupported if the wrapped provider cannot archive databases.
+func (p *Provider) Archive(ctx context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error) {
+	archiver, ok := p.Provider.(provider.Archiver)
+	if !ok {
+		return provider.Archive{}, provider.ErrNotSupported
+	}
+	var archive provider.Archive
+	err := p.b.Do(func() error {
+		var err error
+		archive, err = archiver.Archive(ctx, db, location)
+		return err
+	})
+	return archive, err
+}
+
+// Clone runs the wrapped provider's Clone through the breaker. It returns
+// provider.ErrNotSupported if the wrapped provider cannot clone databases.
+func (p *Provider) Clone(ctx context.Context, db, source provider.ProviderDatabase, manifests string) error {
+	cloner, ok := p.Provider.(provider.Cloner)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error { return cloner.Clone(ctx, db, source, manifests) })
+}
+
+// Promote runs the wrapped provider's Promote through the breaker. It returns
+// provider.ErrNotSupported if the wrapped provider cannot clone databases.
+func (p *Provider) Promote(ctx context.Context, db, source provider.ProviderDatabase) error {
+	cloner, ok := p.Provider.(provider.Cloner)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error { return cloner.Promote(ctx, db, source) })
+}
+
+// Unfence runs the wrapped provider's Unfence through the breaker. It returns
+// provider.ErrNotSupported if the wrapped provider cannot clone databases.
+func (p *Provider) Unfence(ctx context.Context, source provider.ProviderDatabase) error {
+	cloner, ok := p.Provider.(provider.Cloner)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error { return cloner.Unfence(ctx, source) })
+}
+
+// Restore runs the wrapped provider's Restore through the breaker. It
+// returns provider.ErrNotSupported if the wrapped provider cannot restore
+// databases.
+func (p *Provider) Restore(ctx context.Context, db, source provider.ProviderDatabase, manifests string, target provider.RestoreTarget) error {
+	restorer, ok := p.Provider.(provider.Restorer)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error { return restorer.Restore(ctx, db, source, manifests, target) })
+}
+
+// RotateCredentials runs the wrapped provider's RotateCredentials through
+// the breaker. It returns provider.ErrNotSupported if the wrapped provider
+// cannot rotate credentials.
+func (p *Provider) RotateCredentials(ctx context.Context, db provider.ProviderDatabase) error {
+	rotator, ok := p.Provider.(provider.CredentialRotator)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error { return rotator.RotateCredentials(ctx, db) })
+}
+
+// StorageUsage runs the wrapped provider's StorageUsage through the breaker.
+// It returns provider.ErrNotSupported if the wrapped provider cannot scale
+// storage.
+func (p *Provider) StorageUsage(ctx context.Context, db provider.ProviderDatabase) (provider.StorageUsage, error) {
+	scaler, ok := p.Provider.(provider.StorageScaler)
+	if !ok {
+		return provider.StorageUsage{}, provider.ErrNotSupported
+	}
+	var usage provider.StorageUsage
+	err := p.b.Do(func() error {
+		var err error
+		usage, err = scaler.StorageUsage(ctx, db)
+		return err
+	})
+	return usage, err
+}
+
+// ResizeStorage runs the wrapped provider's ResizeStorage through the
+// breaker. It returns provider.ErrNotSupported if the wrapped provider cannot
+// scale storage.
+func (p *Provider) ResizeStorage(ctx context.Context, db provider.ProviderDatabase, sizeBytes int64) error {
+	scaler, ok := p.Provider.(provider.StorageScaler)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	return p.b.Do(func() error { return scaler.ResizeStorage(ctx, db, sizeBytes) })
+}
+
+// ComputeUsage runs the wrapped provider's ComputeUsage through the breaker.
+// It returns provider.ErrNotSupported if the wrapped provider cannot report
+// compute usage.
+func (p *Provider) ComputeUsage(ctx context.Context, db provider.ProviderDatabase) (provider.ComputeUsage, error) {
+	reporter, ok := p.Provider.(provider.ComputeReporter)
+	if !ok {
+		return provider.ComputeUsage{}, provider.ErrNotSupported
+	}
+	var usage provider.ComputeUsage
+	err := p.b.Do(func() error {
+		var err error
+		usage, err = reporter.ComputeUsage(ctx, db)
+		return err
+	})
+	return usage, err
+}
+
+// PoolerUsage runs the wrapped provider's PoolerUsage through the breaker.
+// It returns provider.ErrNotSupported if the wrapped provider cannot report
+// pooler usage.
+func (p *Provider) PoolerUsage(ctx context.Context, db provider.ProviderDatabase) (provider.PoolerUsage, error) {
+	reporter, ok := p.Provider.(provider.PoolerReporter)
+	if !ok {
+		return provider.PoolerUsage{}, provider.ErrNotSupported
+	}
+	var usage provider.PoolerUsage
+	err := p.b.Do(func() error {
+		var err error
+		usage, err = reporter.PoolerUsage(ctx, db)
+		return err
+	})
+	return usage, err
+}
+
+// ManifestConnections delegates to the wrapped provider without the breaker;
+// it does not call the API server.
+func (p *Provider) ManifestConnections(manifests string) (int, error) {
+	reporter, ok := p.Provider.(provider.ConnectionReporter)
+	if !ok {
+		return 0, provider.ErrNotSupported
+	}
+	return reporter.ManifestConnections(manifests)
+}
+
+// ManifestCompute delegates to the wrapped provider without the breaker; it
+// does not call the API server.
+func (p *Provider) ManifestCompute(manifests string) (provider.ComputeResources, error) {
+	reporter, ok := p.Provider.(provider.ComputeReporter)
+	if !ok {
+		return provider.ComputeResources{}, provider.ErrNotSupported
+	}
+	return reporter.ManifestCompute(manifests)
+}
+
+// RenderManifests delegates to the wrapped provider without the breaker; it
+// does not call the API server.
+func (p *Provider) RenderManifests(db provider.ProviderDatabase, manifests string) (string, error) {
+	renderer, ok := p.Provider.(provider.ManifestRenderer)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	return renderer.RenderManifests(db, manifests)
+}
+
+// HealthChecker wraps a k8s.HealthChecker so that a disconnected result
+// counts as a breaker failure and an open breaker reports disconnected
+// without contacting the API server.
+type HealthChecker struct {
+	checker k8s.HealthChecker
+	b       *Breaker
+}
+
+// WrapHealthChecker returns c guarded by b.
+func WrapHealthChecker(c k8s.HealthChecker, b *Breaker) *HealthChecker {
+	return &HealthChecker{checker: c, b: b}
+}
+
+// CheckConnectivity checks connectivity through the breaker.
+func (h *HealthChecker) CheckConnectivity(ctx context.Context) k8s.ConnectivityStatus {
+	if err := h.b.Allow(); err != nil {
+		return k8s.ConnectivityStatus{Connected: false}
+	}
+	status := h.checker.CheckConnectivity(ctx)
+	if status.Connected {
+		h.b.Success()
+	} else {
+		h.b.Failure()
+	}
+	return status
+}
@@ -0,0 +1,157 @@
+// Package budget enforces the connection budgets of teams: the sum of the
+// max_connections of a team's active databases may not exceed the team's
+// MaxConnections, so that no team exhausts the cluster with many databases.
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// ErrExceeded is returned when a database would take a team past its
+// connection budget.
+var ErrExceeded = errors.New("team connection budget exceeded")
+
+// Gate reports whether a team's connection budget leaves room for a database.
+type Gate interface {
+	// CheckConnections returns an error wrapping ErrExceeded if the team
+	// cannot own one more database on the tier with tierID, or, when
+	// replacing is not uuid.Nil, move its database replacing to that tier.
+	CheckConnections(ctx context.Context, teamID uuid.UUID, tierID *uuid.UUID, replacing uuid.UUID) error
+}
+
+// Budgets implements Gate by summing the connections the blueprints of the
+// tiers of a team's databases accept, as their providers report them.
+type Budgets struct {
+	teams      team.Repository
+	databases  database.Repository
+	tiers      tier.Repository
+	blueprints blueprint.Repository
+	registry   *provider.Registry
+}
+
+// New creates a Budgets counting the databases in databases of the teams in
+// teams.
+func New(teams team.Repository, databases database.Repository, tiers tier.Repository, blueprints blueprint.Repository, registry *provider.Registry) *Budgets {
+	return &Budgets{teams: teams, databases: databases, tiers: tiers, blueprints: blueprints, registry: registry}
+}
+
+// CheckConnections returns an error wrapping ErrExceeded if the team has a
+// budget and its active databases, other than replacing, plus one on the
+// tier with tierID would accept more connections than it allows. Databases
+// without a tier, or whose provider cannot report connections, count as
+// none. The check and the change are not atomic: concurrent requests may
+// overshoot the budget.
+func (b *Budgets) CheckConnections(ctx context.Context, teamID uuid.UUID, tierID *uuid.UUID, replacing uuid.UUID) error {
+	t, err := b.teams.GetByID(ctx, teamID)
+	if err != nil {
+		return fmt.Errorf("getting team: %w", err)
+	}
+	if t.MaxConnections == 0 {
+		return nil
+	}
+
+	c := counter{b: b, byTier: map[uuid.UUID]int{}}
+	used, err := c.team(ctx, teamID, replacing)
+	if err != nil {
+		return err
+	}
+	requested, err := c.tier(ctx, tierID)
+	if err != nil {
+		return err
+	}
+	if used+requested > t.MaxConnections {
+		return fmt.Errorf("%w: team %s uses %d of %d connections and the database needs %d", ErrExceeded, t.Name, used, t.MaxConnections, requested)
+	}
+	return nil
+}
+
+// counter counts connections, reading each tier's blueprint once.
+type counter struct {
+	b      *Budgets
+	byTier map[uuid.UUID]int
+}
+
+func (c *counter) team(ctx context.Context, teamID, excluding uuid.UUID) (int, error) {
+	used := 0
+	for page := 1; ; page++ {
+		result, err := c.b.databases.List(ctx, database.ListFilter{OwnerTeamID: &teamID, Page: page, Limit: 100})
+		if err != nil {
+			return 0, fmt.Errorf("listing databases: %w", err)
+		}
+		for _, db := range result.Databases {
+			if db.ID == excluding {
+				continue
+			}
+			n, err := c.tier(ctx, db.TierID)
+			if err != nil {
+				return 0, fmt.Errorf("counting connections of database %s: %w", db.Name, err)
+			}
+			used += n
+		}
+		if page*result.Limit >= result.Total {
+			return used, nil
+		}
+	}
+}
+
+func (c *counter) tier(ctx context.Context, tierID *uuid.UUID) (int, error) {
+	if tierID == nil {
+		return 0, nil
+	}
+	if n, ok := c.byTier[*tierID]; ok {
+		return n, nil
+	}
+	n, err := c.b.tierConnections(ctx, *tierID)
+	if err != nil {
+		return 0, err
+	}
+	c.byTier[*tierID] = n
+	return n, nil
+}
+
+// tierConnections returns the connections a database on the tier accepts.
+func (b *Budgets) tierConnections(ctx context.Context, tierID uuid.UUID) (int, error) {
+	t, err := b.tiers.GetByID(ctx, tierID)
+	if err != nil {
+		if errors.Is(err, tier.ErrTierNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("getting tier: %w", err)
+	}
+	if t.BlueprintID == nil {
+		return 0, nil
+	}
+	bp, err := b.blueprints.GetByID(ctx, *t.BlueprintID)
+	if err != nil {
+		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("getting blueprint: %w", err)
+	}
+	p, ok := b.registry.Get(bp.Provider)
+	if !ok {
+		return 0, nil
+	}
+	reporter, ok := p.(provider.ConnectionReporter)
+	if !ok {
+		return 0, nil
+	}
+	n, err := reporter.ManifestConnections(bp.Manifests)
+	if errors.Is(err, provider.ErrNotSupported) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading connections of blueprint %s: %w", bp.Name, err)
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+// Package buildinfo exposes the version metadata stamped into the binary at
+// build time. The variables are set with -ldflags, e.g.
+//
+//	go build -ldflags "-X github.com/daap14/daap/internal/buildinfo.Version=1.4.0 \
+//	  -X github.com/daap14/daap/internal/buildinfo.Commit=$(git rev-parse HEAD)" ./cmd/server
+//
+// When a variable is not stamped, Get falls back to the VCS metadata that the
+// Go toolchain embeds in module builds.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// Values injected with -ldflags "-X". Features is a comma-separated list.
+var (
+	Version  = "dev"
+	Commit   = ""
+	Date     = ""
+	Features = ""
+)
+
+// Info describes the running binary.
+type Info struct {
+	Version   string   `json:"version"`
+	GitCommit string   `json:"gitCommit"`
+	BuildDate string   `json:"buildDate"`
+	GoVersion string   `json:"goVersion"`
+	Features  []string `json:"features"`
+}
+
+// Get returns the build info for the running binary. Features contains the
+// stamped features plus any build tags the binary was compiled with.
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		GitCommit: Commit,
+		BuildDate: Date,
+		GoVersion: runtime.Version(),
+	}
+
+	var tags string
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			case "-tags":
+				tags = s.Value
+			}
+		}
+	}
+
+	info.Features = mergeFeatures(splitList(Features), splitList(tags))
+	return info
+}
+
+// WithFeatures returns a copy of i with the given runtime features added.
+func (i Info) WithFeatures(features ...string) Info {
+	i.Features = mergeFeatures(i.Features, features)
+	return i
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, f := range strings.Split(s, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// mergeFeatures returns the sorted, de-duplicated union of the given lists.
+// The result is never nil so that it serializes as [] rather than null.
+func mergeFeatures(lists ...[]string) []string {
+	seen := make(map[string]bool)
+	out := []string{}
+	for _, list := range lists {
+		for _, f := range list {
+			if f != "" && !seen[f] {
+				seen[f] = true
+				out = append(out, f)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
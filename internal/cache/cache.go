@@ -0,0 +1,84 @@
+// Package cache provides a small in-process cache with per-entry expiry,
+// used to spare the platform database identical lookups of rarely changing
+// records such as teams and tiers.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// TTL caches values for a fixed duration. It has no size bound: it is meant
+// for small tables whose rows all fit in memory. The zero value is not
+// usable; create one with New.
+type TTL[K comparable, V any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[K]entry[V]
+	// gen is incremented by Purge, so a lookup that started before a purge
+	// does not store the value it read.
+	gen uint64
+}
+
+// New creates a cache whose entries expire ttl after they are stored.
+func New[K comparable, V any](ttl time.Duration) *TTL[K, V] {
+	return &TTL[K, V]{ttl: ttl, entries: make(map[K]entry[V])}
+}
+
+// Get returns the unexpired value cached for key.
+func (c *TTL[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	if !time.Now().Before(e.expires) {
+		delete(c.entries, key)
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Load returns the value cached for key, or calls load and caches its value
+// when it succeeds. Errors are not cached. A value loaded while Purge runs is
+// returned but not cached, since it may predate the write that caused the
+// purge.
+func (c *TTL[K, V]) Load(key K, load func() (V, error)) (V, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+
+	c.mu.Lock()
+	gen := c.gen
+	c.mu.Unlock()
+
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+
+	c.mu.Lock()
+	if c.gen == gen {
+		c.entries[key] = entry[V]{value: v, expires: time.Now().Add(c.ttl)}
+	}
+	c.mu.Unlock()
+	return v, nil
+}
+
+// Purge removes every entry.
+func (c *TTL[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[K]entry[V])
+	c.gen++
+}
@@ -0,0 +1,165 @@
+// Package catalog describes managed databases as Backstage catalog entities,
+// so they appear in a developer portal with their owning team. It backs
+// GET /catalog/entities.
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// pageSize is the number of databases fetched per List call.
+const pageSize = 100
+
+// Entity annotations. Tier, environment, data classification, host and port
+// are only set when known.
+const (
+	AnnotationDatabaseID  = "daap.io/database-id"
+	AnnotationStatus      = "daap.io/status"
+	AnnotationTier        = "daap.io/tier"
+	AnnotationEnvironment = "daap.io/environment"
+	AnnotationNamespace   = "daap.io/namespace"
+	AnnotationHost        = "daap.io/host"
+	AnnotationPort        = "daap.io/port"
+
+	AnnotationDataClassification = "daap.io/data-classification"
+)
+
+// Entity is a Backstage catalog entity (backstage.io/v1alpha1).
+type Entity struct {
+	APIVersion string         `json:"apiVersion"`
+	Kind       string         `json:"kind"`
+	Metadata   EntityMetadata `json:"metadata"`
+	Spec       ResourceSpec   `json:"spec"`
+}
+
+// EntityMetadata is the metadata block of an Entity.
+type EntityMetadata struct {
+	Name        string            `json:"name"`
+	Namespace   string            `json:"namespace"`
+	Description string            `json:"description,omitempty"`
+	Annotations map[string]string `json:"annotations"`
+	Tags        []string          `json:"tags,omitempty"`
+}
+
+// ResourceSpec is the spec of a Resource entity.
+type ResourceSpec struct {
+	Type   string `json:"type"`
+	Owner  string `json:"owner"`
+	System string `json:"system,omitempty"`
+}
+
+// Config controls how databases map onto entities.
+type Config struct {
+	// Namespace is the Backstage namespace of the entities and of the owner
+	// groups that are not mapped explicitly (default "default").
+	Namespace string
+	// System, when set, is the Backstage system every database belongs to.
+	System string
+	// Owners maps DAAP team names to Backstage group names, optionally
+	// qualified as "<namespace>/<name>". Unmapped teams are owned by the
+	// group of the same name in Namespace.
+	Owners map[string]string
+}
+
+// Catalog builds entities from the database repository.
+type Catalog struct {
+	repo database.Repository
+	cfg  Config
+}
+
+// New creates a Catalog.
+func New(repo database.Repository, cfg Config) *Catalog {
+	if cfg.Namespace == "" {
+		cfg.Namespace = "default"
+	}
+	return &Catalog{repo: repo, cfg: cfg}
+}
+
+// Filter restricts the entities returned.
+type Filter struct {
+	OwnerTeamID *uuid.UUID
+}
+
+// Entities returns one Resource entity of type "database" per database, in
+// the repository's list order.
+func (c *Catalog) Entities(ctx context.Context, filter Filter) ([]Entity, error) {
+	var entities []Entity
+	seen := 0
+	for page := 1; ; page++ {
+		result, err := c.repo.List(ctx, database.ListFilter{OwnerTeamID: filter.OwnerTeamID, Page: page, Limit: pageSize})
+		if err != nil {
+			return nil, fmt.Errorf("listing databases: %w", err)
+		}
+		for i := range result.Databases {
+			entities = append(entities, c.entity(&result.Databases[i]))
+		}
+		seen += len(result.Databases)
+		if len(result.Databases) < pageSize || seen >= result.Total {
+			return entities, nil
+		}
+	}
+}
+
+func (c *Catalog) entity(db *database.Database) Entity {
+	annotations := map[string]string{
+		AnnotationDatabaseID: db.ID.String(),
+		AnnotationStatus:     db.Status,
+		AnnotationNamespace:  db.Namespace,
+	}
+	if db.TierName != "" {
+		annotations[AnnotationTier] = db.TierName
+	}
+	if db.Environment != "" {
+		annotations[AnnotationEnvironment] = db.Environment
+	}
+	if db.DataClassification != "" {
+		annotations[AnnotationDataClassification] = db.DataClassification
+	}
+	if db.Host != nil {
+		annotations[AnnotationHost] = *db.Host
+	}
+	if db.Port != nil {
+		annotations[AnnotationPort] = strconv.Itoa(*db.Port)
+	}
+
+	tags := []string{"postgresql"}
+	if db.Environment != "" {
+		tags = append(tags, db.Environment)
+	}
+
+	return Entity{
+		APIVersion: "backstage.io/v1alpha1",
+		Kind:       "Resource",
+		Metadata: EntityMetadata{
+			Name:        db.Name,
+			Namespace:   c.cfg.Namespace,
+			Description: db.Purpose,
+			Annotations: annotations,
+			Tags:        tags,
+		},
+		Spec: ResourceSpec{
+			Type:   "database",
+			Owner:  c.owner(db.OwnerTeamName),
+			System: c.cfg.System,
+		},
+	}
+}
+
+// owner returns the entity ref of the Backstage group owning team's databases.
+func (c *Catalog) owner(team string) string {
+	group := team
+	if mapped, ok := c.cfg.Owners[team]; ok {
+		group = mapped
+	}
+	if !strings.Contains(group, "/") {
+		group = c.cfg.Namespace + "/" + group
+	}
+	return "group:" + group
+}
@@ -0,0 +1,115 @@
+// Package chaos wraps providers and repositories with configurable latency
+// and failure injection for resilience testing of the reconciler and API
+// handlers under partial outage. It is intended for tests only and is never
+// wired into the production server.
+package chaos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+)
+
+// ErrInjected is returned (wrapped) by every injected failure.
+var ErrInjected = errors.New("chaos: injected failure")
+
+// Config controls what the injector does. It can be populated from
+// CHAOS_* environment variables via ConfigFromEnv.
+type Config struct {
+	// Latency is added before every matching operation.
+	Latency time.Duration `envconfig:"LATENCY" default:"0s"`
+	// Jitter adds a uniformly random extra delay in [0, Jitter).
+	Jitter time.Duration `envconfig:"JITTER" default:"0s"`
+	// FailureRate is the probability (0.0–1.0) that a matching operation fails.
+	FailureRate float64 `envconfig:"FAILURE_RATE" default:"0"`
+	// Operations restricts injection to operations whose name starts with one
+	// of these prefixes (e.g. "provider.", "database.UpdateStatus").
+	// Empty means every operation.
+	Operations []string `envconfig:"OPERATIONS"`
+	// Seed makes injected failures reproducible. Zero picks a random seed.
+	Seed uint64 `envconfig:"SEED" default:"0"`
+}
+
+// ConfigFromEnv reads CHAOS_LATENCY, CHAOS_JITTER, CHAOS_FAILURE_RATE,
+// CHAOS_OPERATIONS (comma-separated) and CHAOS_SEED.
+func ConfigFromEnv() (Config, error) {
+	var cfg Config
+	if err := envconfig.Process("CHAOS", &cfg); err != nil {
+		return Config{}, fmt.Errorf("loading chaos config: %w", err)
+	}
+	if cfg.FailureRate < 0 || cfg.FailureRate > 1 {
+		return Config{}, fmt.Errorf("CHAOS_FAILURE_RATE must be between 0 and 1, got %v", cfg.FailureRate)
+	}
+	return cfg, nil
+}
+
+// Injector decides, per operation, whether to delay and whether to fail.
+// It is safe for concurrent use.
+type Injector struct {
+	cfg Config
+
+	mu  sync.Mutex
+	rnd *rand.Rand
+}
+
+// NewInjector creates an Injector from cfg.
+func NewInjector(cfg Config) *Injector {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = rand.Uint64()
+	}
+	return &Injector{
+		cfg: cfg,
+		rnd: rand.New(rand.NewPCG(seed, seed)),
+	}
+}
+
+// Inject applies the configured latency and failure to the named operation.
+// It returns ctx.Err() if the context ends while waiting, or an error
+// wrapping ErrInjected if the operation was chosen to fail.
+func (i *Injector) Inject(ctx context.Context, op string) error {
+	if !i.matches(op) {
+		return nil
+	}
+
+	i.mu.Lock()
+	delay := i.cfg.Latency
+	if i.cfg.Jitter > 0 {
+		delay += time.Duration(i.rnd.Int64N(int64(i.cfg.Jitter)))
+	}
+	fail := i.cfg.FailureRate > 0 && i.rnd.Float64() < i.cfg.FailureRate
+	i.mu.Unlock()
+
+	if delay > 0 {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	if fail {
+		return fmt.Errorf("%s: %w", op, ErrInjected)
+	}
+	return nil
+}
+
+func (i *Injector) matches(op string) bool {
+	if len(i.cfg.Operations) == 0 {
+		return true
+	}
+	for _, prefix := range i.cfg.Operations {
+		if strings.HasPrefix(op, strings.TrimSpace(prefix)) {
+			return true
+		}
+	}
+	return false
+}
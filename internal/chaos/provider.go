@@ -0,0 +1,324 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// Provider wraps a provider.Provider with fault injection. Operations are
+// named "provider.Apply", "provider.Delete", "provider.CheckHealth",
+// "provider.DeleteForeground", "provider.Switchover", "provider.Restart",
+// "provider.Restarted", "provider.Freeze", "provider.Unfreeze",
+// "provider.OperatorVersion", "provider.Diagnostics", "provider.Archive",
+// "provider.Clone", "provider.Promote", "provider.Restore",
+// "provider.RotateCredentials", "provider.StorageUsage" and
+// "provider.ResizeStorage".
+//
+// Provider implements every optional provider interface, whatever the
+// wrapped provider implements: those the wrapped provider lacks return
+// provider.ErrNotSupported without injecting faults. Use provider.Supports
+// to tell which it does implement.
+type Provider struct {
+	provider.Provider
+	inj *Injector
+}
+
+// WrapProvider returns p with faults injected by inj.
+func WrapProvider(p provider.Provider, inj *Injector) *Provider {
+	return &Provider{Provider: p, inj: inj}
+}
+
+// Unwrap returns the wrapped provider.
+func (p *Provider) Unwrap() provider.Provider {
+	return p.Provider
+}
+
+// Apply injects faults, then delegates to the wrapped provider.
+func (p *Provider) Apply(ctx context.Context, db provider.ProviderDatabase, manifests string) error {
+	if err := p.inj.Inject(ctx, "provider.Apply"); err != nil {
+		return err
+	}
+	return p.Provider.Apply(ctx, db, manifests)
+}
+
+// Delete injects faults, then delegates to the wrapped provider.
+func (p *Provider) Delete(ctx context.Context, db provider.ProviderDatabase) error {
+	if err := p.inj.Inject(ctx, "provider.Delete"); err != nil {
+		return err
+	}
+	return p.Provider.Delete(ctx, db)
+}
+
+// CheckHealth injects faults, then delegates to the wrapped provider.
+func (p *Provider) CheckHealth(ctx context.Context, db provider.ProviderDatabase) (provider.HealthResult, error) {
+	if err := p.inj.Inject(ctx, "provider.CheckHealth"); err != nil {
+		return provider.HealthResult{}, err
+	}
+	return p.Provider.CheckHealth(ctx, db)
+}
+
+// DeleteForeground injects faults, then delegates to the wrapped provider if
+// it can confirm deletions.
+func (p *Provider) DeleteForeground(ctx context.Context, db provider.ProviderDatabase, wait time.Duration) (provider.DeletionState, error) {
+	confirmer, ok := p.Provider.(provider.DeletionConfirmer)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.DeleteForeground"); err != nil {
+		return "", err
+	}
+	return confirmer.DeleteForeground(ctx, db, wait)
+}
+
+// Switchover injects faults, then delegates to the wrapped provider if it can
+// switch primaries.
+func (p *Provider) Switchover(ctx context.Context, db provider.ProviderDatabase, target string) (string, error) {
+	switcher, ok := p.Provider.(provider.PrimarySwitcher)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Switchover"); err != nil {
+		return "", err
+	}
+	return switcher.Switchover(ctx, db, target)
+}
+
+// Restart injects faults, then delegates to the wrapped provider if it can
+// restart databases.
+func (p *Provider) Restart(ctx context.Context, db provider.ProviderDatabase) error {
+	restarter, ok := p.Provider.(provider.Restarter)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Restart"); err != nil {
+		return err
+	}
+	return restarter.Restart(ctx, db)
+}
+
+// Restarted injects faults, then delegates to the wrapped provider if it can
+// restart databases.
+func (p *Provider) Restarted(ctx context.Context, db provider.ProviderDatabase) (bool, error) {
+	restarter, ok := p.Provider.(provider.Restarter)
+	if !ok {
+		return false, provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Restarted"); err != nil {
+		return false, err
+	}
+	return restarter.Restarted(ctx, db)
+}
+
+// Freeze injects faults, then delegates to the wrapped provider if it can
+// freeze databases.
+func (p *Provider) Freeze(ctx context.Context, db provider.ProviderDatabase) error {
+	freezer, ok := p.Provider.(provider.Freezer)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Freeze"); err != nil {
+		return err
+	}
+	return freezer.Freeze(ctx, db)
+}
+
+// Unfreeze injects faults, then delegates to the wrapped provider if it can
+// freeze databases.
+func (p *Provider) Unfreeze(ctx context.Context, db provider.ProviderDatabase) error {
+	freezer, ok := p.Provider.(provider.Freezer)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Unfreeze"); err != nil {
+		return err
+	}
+	return freezer.Unfreeze(ctx, db)
+}
+
+// OperatorVersion injects faults, then delegates to the wrapped provider if
+// it reports operator versions.
+func (p *Provider) OperatorVersion(ctx context.Context, db provider.ProviderDatabase) (string, error) {
+	versioner, ok := p.Provider.(provider.OperatorVersioner)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.OperatorVersion"); err != nil {
+		return "", err
+	}
+	return versioner.OperatorVersion(ctx, db)
+}
+
+// Diagnostics injects faults, then delegates to the wrapped provider if it
+// can gather diagnostics.
+func (p *Provider) Diagnostics(ctx context.Context, db provider.ProviderDatabase) ([]provider.DiagnosticFile, error) {
+	diagnoser, ok := p.Provider.(provider.Diagnoser)
+	if !ok {
+		return nil, provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Diagnostics"); err != nil {
+		return nil, err
+	}
+	return diagnoser.Diagnostics(ctx, db)
+}
+
+// Archive injects faults, then delegates to the wrapped provider if it can
+// archive databases.
+func (p *Provider) Archive(ctx context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error) {
+	archiver, ok := p.Provider.(provider.Archiver)
+	if !ok {
+		return provider.Archive{}, provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Archive"); err != nil {
+		return provider.Archive{}, err
+	}
+	return archiver.Archive(ctx, db, location)
+}
+
+// Clone injects faults, then delegates to the wrapped provider if it can
+// clone databases.
+func (p *Provider) Clone(ctx context.Context, db, source provider.ProviderDatabase, manifests string) error {
+	cloner, ok := p.Provider.(provider.Cloner)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Clone"); err != nil {
+		return err
+	}
+	return cloner.Clone(ctx, db, source, manifests)
+}
+
+// Promote injects faults, then delegates to the wrapped provider if it can
+// clone databases.
+func (p *Provider) Promote(ctx context.Context, db, source provider.ProviderDatabase) error {
+	cloner, ok := p.Provider.(provider.Cloner)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Promote"); err != nil {
+		return err
+	}
+	return cloner.Promote(ctx, db, source)
+}
+
+// Unfence injects faults, then delegates to the wrapped provider if it can
+// clone databases.
+func (p *Provider) Unfence(ctx context.Context, source provider.ProviderDatabase) error {
+	cloner, ok := p.Provider.(provider.Cloner)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Unfence"); err != nil {
+		return err
+	}
+	return cloner.Unfence(ctx, source)
+}
+
+// Restore injects faults, then delegates to the wrapped provider if it can
+// restore databases.
+func (p *Provider) Restore(ctx context.Context, db, source provider.ProviderDatabase, manifests string, target provider.RestoreTarget) error {
+	restorer, ok := p.Provider.(provider.Restorer)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.Restore"); err != nil {
+		return err
+	}
+	return restorer.Restore(ctx, db, source, manifests, target)
+}
+
+// RotateCredentials injects faults, then delegates to the wrapped provider
+// if it can rotate credentials.
+func (p *Provider) RotateCredentials(ctx context.Context, db provider.ProviderDatabase) error {
+	rotator, ok := p.Provider.(provider.CredentialRotator)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.RotateCredentials"); err != nil {
+		return err
+	}
+	return rotator.RotateCredentials(ctx, db)
+}
+
+// StorageUsage injects faults, then delegates to the wrapped provider if it
+// can scale storage.
+func (p *Provider) StorageUsage(ctx context.Context, db provider.ProviderDatabase) (provider.StorageUsage, error) {
+	scaler, ok := p.Provider.(provider.StorageScaler)
+	if !ok {
+		return provider.StorageUsage{}, provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.StorageUsage"); err != nil {
+		return provider.StorageUsage{}, err
+	}
+	return scaler.StorageUsage(ctx, db)
+}
+
+// ResizeStorage injects faults, then delegates to the wrapped provider if it
+// can scale storage.
+func (p *Provider) ResizeStorage(ctx context.Context, db provider.ProviderDatabase, sizeBytes int64) error {
+	scaler, ok := p.Provider.(provider.StorageScaler)
+	if !ok {
+		return provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.ResizeStorage"); err != nil {
+		return err
+	}
+	return scaler.ResizeStorage(ctx, db, sizeBytes)
+}
+
+// ComputeUsage injects faults, then delegates to the wrapped provider if it
+// can report compute usage.
+func (p *Provider) ComputeUsage(ctx context.Context, db provider.ProviderDatabase) (provider.ComputeUsage, error) {
+	reporter, ok := p.Provider.(provider.ComputeReporter)
+	if !ok {
+		return provider.ComputeUsage{}, provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.ComputeUsage"); err != nil {
+		return provider.ComputeUsage{}, err
+	}
+	return reporter.ComputeUsage(ctx, db)
+}
+
+// PoolerUsage injects faults, then delegates to the wrapped provider if it
+// can report pooler usage.
+func (p *Provider) PoolerUsage(ctx context.Context, db provider.ProviderDatabase) (provider.PoolerUsage, error) {
+	reporter, ok := p.Provider.(provider.PoolerReporter)
+	if !ok {
+		return provider.PoolerUsage{}, provider.ErrNotSupported
+	}
+	if err := p.inj.Inject(ctx, "provider.PoolerUsage"); err != nil {
+		return provider.PoolerUsage{}, err
+	}
+	return reporter.PoolerUsage(ctx, db)
+}
+
+// ManifestConnections delegates to the wrapped provider if it can report
+// connections. It makes no external call, so no fault is injected.
+func (p *Provider) ManifestConnections(manifests string) (int, error) {
+	reporter, ok := p.Provider.(provider.ConnectionReporter)
+	if !ok {
+		return 0, provider.ErrNotSupported
+	}
+	return reporter.ManifestConnections(manifests)
+}
+
+// ManifestCompute delegates to the wrapped provider if it can report compute
+// usage. It makes no external call, so no fault is injected.
+func (p *Provider) ManifestCompute(manifests string) (provider.ComputeResources, error) {
+	reporter, ok := p.Provider.(provider.ComputeReporter)
+	if !ok {
+		return provider.ComputeResources{}, provider.ErrNotSupported
+	}
+	return reporter.ManifestCompute(manifests)
+}
+
+// RenderManifests delegates to the wrapped provider if it can render
+// manifests. It makes no external call, so no fault is injected.
+func (p *Provider) RenderManifests(db provider.ProviderDatabase, manifests string) (string, error) {
+	renderer, ok := p.Provider.(provider.ManifestRenderer)
+	if !ok {
+		return "", provider.ErrNotSupported
+	}
+	return renderer.RenderManifests(db, manifests)
+}
@@ -0,0 +1,286 @@
+package chaos
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// DatabaseRepository wraps a database.Repository with fault injection.
+// Operations are named "database.<Method>".
+type DatabaseRepository struct {
+	database.Repository
+	inj *Injector
+}
+
+// WrapDatabaseRepository returns repo with faults injected by inj.
+func WrapDatabaseRepository(repo database.Repository, inj *Injector) *DatabaseRepository {
+	return &DatabaseRepository{Repository: repo, inj: inj}
+}
+
+// Create injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) Create(ctx context.Context, db *database.Database) error {
+	if err := r.inj.Inject(ctx, "database.Create"); err != nil {
+		return err
+	}
+	return r.Repository.Create(ctx, db)
+}
+
+// GetByID injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) GetByID(ctx context.Context, id uuid.UUID) (*database.Database, error) {
+	if err := r.inj.Inject(ctx, "database.GetByID"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByID(ctx, id)
+}
+
+// GetByName injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) GetByName(ctx context.Context, name string) (*database.Database, error) {
+	if err := r.inj.Inject(ctx, "database.GetByName"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByName(ctx, name)
+}
+
+// List injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) List(ctx context.Context, filter database.ListFilter) (*database.ListResult, error) {
+	if err := r.inj.Inject(ctx, "database.List"); err != nil {
+		return nil, err
+	}
+	return r.Repository.List(ctx, filter)
+}
+
+// Update injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) Update(ctx context.Context, id uuid.UUID, fields database.UpdateFields) (*database.Database, error) {
+	if err := r.inj.Inject(ctx, "database.Update"); err != nil {
+		return nil, err
+	}
+	return r.Repository.Update(ctx, id, fields)
+}
+
+// UpdateStatus injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) UpdateStatus(ctx context.Context, id uuid.UUID, su database.StatusUpdate) (*database.Database, error) {
+	if err := r.inj.Inject(ctx, "database.UpdateStatus"); err != nil {
+		return nil, err
+	}
+	return r.Repository.UpdateStatus(ctx, id, su)
+}
+
+// UpdateStatuses injects faults once for the whole batch, then applies the
+// writes through the wrapped repository as database.UpdateStatuses does:
+// in one call when it is a database.StatusBatchUpdater.
+func (r *DatabaseRepository) UpdateStatuses(ctx context.Context, writes []database.StatusWrite) ([]uuid.UUID, error) {
+	if err := r.inj.Inject(ctx, "database.UpdateStatuses"); err != nil {
+		return nil, err
+	}
+	return database.UpdateStatuses(ctx, r.Repository, writes)
+}
+
+// SoftDelete injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	if err := r.inj.Inject(ctx, "database.SoftDelete"); err != nil {
+		return err
+	}
+	return r.Repository.SoftDelete(ctx, id)
+}
+
+// Acknowledge injects faults, then delegates to the wrapped repository.
+func (r *DatabaseRepository) Acknowledge(ctx context.Context, id uuid.UUID, ack *database.Acknowledgement) (*database.Database, error) {
+	if err := r.inj.Inject(ctx, "database.Acknowledge"); err != nil {
+		return nil, err
+	}
+	return r.Repository.Acknowledge(ctx, id, ack)
+}
+
+// TierRepository wraps a tier.Repository with fault injection.
+// Operations are named "tier.<Method>".
+type TierRepository struct {
+	tier.Repository
+	inj *Injector
+}
+
+// WrapTierRepository returns repo with faults injected by inj.
+func WrapTierRepository(repo tier.Repository, inj *Injector) *TierRepository {
+	return &TierRepository{Repository: repo, inj: inj}
+}
+
+// Create injects faults, then delegates to the wrapped repository.
+func (r *TierRepository) Create(ctx context.Context, t *tier.Tier) error {
+	if err := r.inj.Inject(ctx, "tier.Create"); err != nil {
+		return err
+	}
+	return r.Repository.Create(ctx, t)
+}
+
+// GetByID injects faults, then delegates to the wrapped repository.
+func (r *TierRepository) GetByID(ctx context.Context, id uuid.UUID) (*tier.Tier, error) {
+	if err := r.inj.Inject(ctx, "tier.GetByID"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByID(ctx, id)
+}
+
+// GetByName injects faults, then delegates to the wrapped repository.
+func (r *TierRepository) GetByName(ctx context.Context, name string) (*tier.Tier, error) {
+	if err := r.inj.Inject(ctx, "tier.GetByName"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByName(ctx, name)
+}
+
+// List injects faults, then delegates to the wrapped repository.
+func (r *TierRepository) List(ctx context.Context) ([]tier.Tier, error) {
+	if err := r.inj.Inject(ctx, "tier.List"); err != nil {
+		return nil, err
+	}
+	return r.Repository.List(ctx)
+}
+
+// Update injects faults, then delegates to the wrapped repository.
+func (r *TierRepository) Update(ctx context.Context, id uuid.UUID, fields tier.UpdateFields) (*tier.Tier, error) {
+	if err := r.inj.Inject(ctx, "tier.Update"); err != nil {
+		return nil, err
+	}
+	return r.Repository.Update(ctx, id, fields)
+}
+
+// Delete injects faults, then delegates to the wrapped repository.
+func (r *TierRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.inj.Inject(ctx, "tier.Delete"); err != nil {
+		return err
+	}
+	return r.Repository.Delete(ctx, id)
+}
+
+// BlueprintRepository wraps a blueprint.Repository with fault injection.
+// Operations are named "blueprint.<Method>".
+type BlueprintRepository struct {
+	blueprint.Repository
+	inj *Injector
+}
+
+// WrapBlueprintRepository returns repo with faults injected by inj.
+func WrapBlueprintRepository(repo blueprint.Repository, inj *Injector) *BlueprintRepository {
+	return &BlueprintRepository{Repository: repo, inj: inj}
+}
+
+// Create injects faults, then delegates to the wrapped repository.
+func (r *BlueprintRepository) Create(ctx context.Context, bp *blueprint.Blueprint) error {
+	if err := r.inj.Inject(ctx, "blueprint.Create"); err != nil {
+		return err
+	}
+	return r.Repository.Create(ctx, bp)
+}
+
+// GetByID injects faults, then delegates to the wrapped repository.
+func (r *BlueprintRepository) GetByID(ctx context.Context, id uuid.UUID) (*blueprint.Blueprint, error) {
+	if err := r.inj.Inject(ctx, "blueprint.GetByID"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByID(ctx, id)
+}
+
+// GetByName injects faults, then delegates to the wrapped repository.
+func (r *BlueprintRepository) GetByName(ctx context.Context, name string) (*blueprint.Blueprint, error) {
+	if err := r.inj.Inject(ctx, "blueprint.GetByName"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByName(ctx, name)
+}
+
+// List injects faults, then delegates to the wrapped repository.
+func (r *BlueprintRepository) List(ctx context.Context) ([]blueprint.Blueprint, error) {
+	if err := r.inj.Inject(ctx, "blueprint.List"); err != nil {
+		return nil, err
+	}
+	return r.Repository.List(ctx)
+}
+
+// Update injects faults, then delegates to the wrapped repository.
+func (r *BlueprintRepository) Update(ctx context.Context, id uuid.UUID, fields blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+	if err := r.inj.Inject(ctx, "blueprint.Update"); err != nil {
+		return nil, err
+	}
+	return r.Repository.Update(ctx, id, fields)
+}
+
+// ListVersions injects faults, then delegates to the wrapped repository.
+func (r *BlueprintRepository) ListVersions(ctx context.Context, id uuid.UUID) ([]blueprint.Version, error) {
+	if err := r.inj.Inject(ctx, "blueprint.ListVersions"); err != nil {
+		return nil, err
+	}
+	return r.Repository.ListVersions(ctx, id)
+}
+
+// Delete injects faults, then delegates to the wrapped repository.
+func (r *BlueprintRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.inj.Inject(ctx, "blueprint.Delete"); err != nil {
+		return err
+	}
+	return r.Repository.Delete(ctx, id)
+}
+
+// TeamRepository wraps a team.Repository with fault injection.
+// Operations are named "team.<Method>".
+type TeamRepository struct {
+	team.Repository
+	inj *Injector
+}
+
+// WrapTeamRepository returns repo with faults injected by inj.
+func WrapTeamRepository(repo team.Repository, inj *Injector) *TeamRepository {
+	return &TeamRepository{Repository: repo, inj: inj}
+}
+
+// Create injects faults, then delegates to the wrapped repository.
+func (r *TeamRepository) Create(ctx context.Context, t *team.Team) error {
+	if err := r.inj.Inject(ctx, "team.Create"); err != nil {
+		return err
+	}
+	return r.Repository.Create(ctx, t)
+}
+
+// GetByID injects faults, then delegates to the wrapped repository.
+func (r *TeamRepository) GetByID(ctx context.Context, id uuid.UUID) (*team.Team, error) {
+	if err := r.inj.Inject(ctx, "team.GetByID"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByID(ctx, id)
+}
+
+// GetByName injects faults, then delegates to the wrapped repository.
+func (r *TeamRepository) GetByName(ctx context.Context, name string) (*team.Team, error) {
+	if err := r.inj.Inject(ctx, "team.GetByName"); err != nil {
+		return nil, err
+	}
+	return r.Repository.GetByName(ctx, name)
+}
+
+// List injects faults, then delegates to the wrapped repository.
+func (r *TeamRepository) List(ctx context.Context) ([]team.Team, error) {
+	if err := r.inj.Inject(ctx, "team.List"); err != nil {
+		return nil, err
+	}
+	return r.Repository.List(ctx)
+}
+
+// Update injects faults, then delegates to the wrapped repository.
+func (r *TeamRepository) Update(ctx context.Context, id uuid.UUID, fields team.UpdateFields) (*team.Team, error) {
+	if err := r.inj.Inject(ctx, "team.Update"); err != nil {
+		return nil, err
+	}
+	return r.Repository.Update(ctx, id, fields)
+}
+
+// Delete injects faults, then delegates to the wrapped repository.
+func (r *TeamRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if err := r.inj.Inject(ctx, "team.Delete"); err != nil {
+		return err
+	}
+	return r.Repository.Delete(ctx, id)
+}
@@ -3,15 +3,97 @@ package config
 import "github.com/kelseyhightower/envconfig"
 
 // Config holds application configuration loaded from environment variables.
+// Settings tagged redact:"secret" are never echoed; those tagged redact:"url"
+// are echoed as scheme and host only (see Effective).
 type Config struct {
-	Port               int    `envconfig:"PORT" default:"8080"`
-	LogLevel           string `envconfig:"LOG_LEVEL" default:"info"`
-	DatabaseURL        string `envconfig:"DATABASE_URL" required:"true"`
-	KubeconfigPath     string `envconfig:"KUBECONFIG_PATH" default:""`
-	Namespace          string `envconfig:"NAMESPACE" default:"default"`
-	Version            string `envconfig:"VERSION" default:"dev"`
-	ReconcilerInterval int    `envconfig:"RECONCILER_INTERVAL" default:"10"`
-	BcryptCost         int    `envconfig:"BCRYPT_COST" default:"12"`
+	Port                          int               `envconfig:"PORT" default:"8080"`
+	RequestTimeoutMax             int               `envconfig:"REQUEST_TIMEOUT_MAX" default:"300"`
+	LogLevel                      string            `envconfig:"LOG_LEVEL" default:"info"`
+	LogModuleLevels               map[string]string `envconfig:"LOG_MODULE_LEVELS" default:""`
+	LogDebugSampling              int               `envconfig:"LOG_DEBUG_SAMPLING" default:"20"`
+	DatabaseURL                   string            `envconfig:"DATABASE_URL" required:"true" redact:"url"`
+	LookupCacheTTL                int               `envconfig:"LOOKUP_CACHE_TTL" default:"30"`
+	KubeconfigPath                string            `envconfig:"KUBECONFIG_PATH" default:""`
+	Namespace                     string            `envconfig:"NAMESPACE" default:"default"`
+	PlacementNamespaces           map[string]int    `envconfig:"PLACEMENT_NAMESPACES" default:""`
+	PlacementTierAffinity         map[string]string `envconfig:"PLACEMENT_TIER_AFFINITY" default:""`
+	PlacementTeamPins             map[string]string `envconfig:"PLACEMENT_TEAM_PINS" default:""`
+	ImageMirror                   string            `envconfig:"IMAGE_MIRROR" default:""`
+	ImagePinDigests               bool              `envconfig:"IMAGE_PIN_DIGESTS" default:"false"`
+	CNPGOperatorNamespace         string            `envconfig:"CNPG_OPERATOR_NAMESPACE" default:"cnpg-system"`
+	Version                       string            `envconfig:"VERSION" default:"dev"`
+	ReconcilerInterval            int               `envconfig:"RECONCILER_INTERVAL" default:"10"`
+	ReconcilerWriteBatchSize      int               `envconfig:"RECONCILER_WRITE_BATCH_SIZE" default:"50"`
+	ReconcilerWriteRate           int               `envconfig:"RECONCILER_WRITE_RATE" default:"0"`
+	ProvisioningSLO               int               `envconfig:"PROVISIONING_SLO" default:"900"`
+	ProvisioningTimeout           int               `envconfig:"PROVISIONING_TIMEOUT" default:"3600"`
+	DeprovisionWait               int               `envconfig:"DEPROVISION_WAIT" default:"60"`
+	NotifyWebhookURL              string            `envconfig:"NOTIFY_WEBHOOK_URL" default:"" redact:"url"`
+	ReadinessGateConnections      int               `envconfig:"READINESS_GATE_CONNECTIONS" default:"0"`
+	ReadinessGateQuery            string            `envconfig:"READINESS_GATE_QUERY" default:"SELECT 1"`
+	ReadinessGateTimeout          int               `envconfig:"READINESS_GATE_TIMEOUT" default:"10"`
+	StorageAutoscaleInterval      int               `envconfig:"STORAGE_AUTOSCALE_INTERVAL" default:"60"`
+	RecommenderInterval           int               `envconfig:"RECOMMENDER_INTERVAL" default:"300"`
+	RecommenderLookback           int               `envconfig:"RECOMMENDER_LOOKBACK" default:"168"`
+	RecommenderAutoApply          bool              `envconfig:"RECOMMENDER_AUTO_APPLY" default:"false"`
+	RecommenderApplyWindow        string            `envconfig:"RECOMMENDER_APPLY_WINDOW" default:"Sun 02:00-04:00"`
+	InsightsInterval              int               `envconfig:"INSIGHTS_INTERVAL" default:"0"`
+	InsightsTop                   int               `envconfig:"INSIGHTS_TOP" default:"20"`
+	InsightsRetention             int               `envconfig:"INSIGHTS_RETENTION" default:"168"`
+	RolloutInterval               int               `envconfig:"ROLLOUT_INTERVAL" default:"30"`
+	RolloutCanarySize             int               `envconfig:"ROLLOUT_CANARY_SIZE" default:"1"`
+	RolloutBatchSize              int               `envconfig:"ROLLOUT_BATCH_SIZE" default:"5"`
+	RolloutVerifyTimeout          int               `envconfig:"ROLLOUT_VERIFY_TIMEOUT" default:"600"`
+	JobWorkerInterval             int               `envconfig:"JOB_WORKER_INTERVAL" default:"2"`
+	JobRetryBackoff               int               `envconfig:"JOB_RETRY_BACKOFF" default:"10"`
+	RetentionDays                 int               `envconfig:"RETENTION_DAYS" default:"0"`
+	RetentionInterval             int               `envconfig:"RETENTION_INTERVAL" default:"3600"`
+	HistoryRetentionMonths        int               `envconfig:"HISTORY_RETENTION_MONTHS" default:"0"`
+	HistoryRotationInterval       int               `envconfig:"HISTORY_ROTATION_INTERVAL" default:"3600"`
+	HistoryArchive                bool              `envconfig:"HISTORY_ARCHIVE" default:"false"`
+	Environments                  []string          `envconfig:"ENVIRONMENTS" default:"dev,staging,prod"`
+	BcryptCost                    int               `envconfig:"BCRYPT_COST" default:"12"`
+	PprofEnabled                  bool              `envconfig:"PPROF_ENABLED" default:"false"`
+	BreakerFailureThreshold       int               `envconfig:"BREAKER_FAILURE_THRESHOLD" default:"5"`
+	BreakerCooldown               int               `envconfig:"BREAKER_COOLDOWN" default:"30"`
+	K8sImpersonateUser            string            `envconfig:"K8S_IMPERSONATE_USER" default:""`
+	K8sImpersonateGroups          []string          `envconfig:"K8S_IMPERSONATE_GROUPS" default:""`
+	K8sNamespaceServiceAccounts   map[string]string `envconfig:"K8S_NAMESPACE_SERVICE_ACCOUNTS" default:""`
+	ExposureInternalLBAnnotations map[string]string `envconfig:"EXPOSURE_INTERNAL_LB_ANNOTATIONS" default:""`
+	DNSZone                       string            `envconfig:"DNS_ZONE" default:""`
+	ProviderPluginDir             string            `envconfig:"PROVIDER_PLUGIN_DIR" default:""`
+	ProviderPluginAddrs           []string          `envconfig:"PROVIDER_PLUGIN_ADDRS" default:""`
+	PublicURL                     string            `envconfig:"PUBLIC_URL" default:"http://localhost:8080"`
+	InvitationTTL                 int               `envconfig:"INVITATION_TTL" default:"72"`
+	SMTPAddr                      string            `envconfig:"SMTP_ADDR" default:""`
+	SMTPFrom                      string            `envconfig:"SMTP_FROM" default:"daap@localhost"`
+	SMTPUsername                  string            `envconfig:"SMTP_USERNAME" default:""`
+	SMTPPassword                  string            `envconfig:"SMTP_PASSWORD" default:"" redact:"secret"`
+	AuditSyslogAddr               string            `envconfig:"AUDIT_SYSLOG_ADDR" default:""`
+	AuditHTTPURL                  string            `envconfig:"AUDIT_HTTP_URL" default:"" redact:"url"`
+	AuditKafkaRESTURL             string            `envconfig:"AUDIT_KAFKA_REST_URL" default:"" redact:"url"`
+	AuditKafkaTopic               string            `envconfig:"AUDIT_KAFKA_TOPIC" default:"daap-audit"`
+	AuditQueueSize                int               `envconfig:"AUDIT_QUEUE_SIZE" default:"10000"`
+	AuditBatchSize                int               `envconfig:"AUDIT_BATCH_SIZE" default:"100"`
+	AuditEnqueueTimeout           int               `envconfig:"AUDIT_ENQUEUE_TIMEOUT" default:"5"`
+	EventsNATSURL                 string            `envconfig:"EVENTS_NATS_URL" default:"" redact:"url"`
+	EventsNATSSubject             string            `envconfig:"EVENTS_NATS_SUBJECT" default:"daap.events"`
+	EventsKafkaRESTURL            string            `envconfig:"EVENTS_KAFKA_REST_URL" default:"" redact:"url"`
+	EventsKafkaTopic              string            `envconfig:"EVENTS_KAFKA_TOPIC" default:"daap.events"`
+	EventsQueueSize               int               `envconfig:"EVENTS_QUEUE_SIZE" default:"10000"`
+	CatalogNamespace              string            `envconfig:"CATALOG_NAMESPACE" default:"default"`
+	CatalogSystem                 string            `envconfig:"CATALOG_SYSTEM" default:""`
+	CatalogOwners                 map[string]string `envconfig:"CATALOG_OWNERS" default:""`
+	MutationLockTTL               int               `envconfig:"MUTATION_LOCK_TTL" default:"900"`
+	BlueprintLintRules            map[string]string `envconfig:"BLUEPRINT_LINT_RULES" default:""`
+	BlueprintLintRequiredLabels   []string          `envconfig:"BLUEPRINT_LINT_REQUIRED_LABELS" default:""`
+	BlueprintMaxManifestBytes     int               `envconfig:"BLUEPRINT_MAX_MANIFEST_BYTES" default:"262144"`
+	BlueprintSigningKey           string            `envconfig:"BLUEPRINT_SIGNING_KEY" default:"" redact:"secret"`
+	SecretsK8sSecret              string            `envconfig:"SECRETS_K8S_SECRET" default:""`
+	SecretsVaultAddr              string            `envconfig:"SECRETS_VAULT_ADDR" default:"" redact:"url"`
+	SecretsVaultToken             string            `envconfig:"SECRETS_VAULT_TOKEN" default:"" redact:"secret"`
+	SecretsVaultPath              string            `envconfig:"SECRETS_VAULT_PATH" default:""`
+	ReloadFile                    string            `envconfig:"RELOAD_FILE" default:""`
 }
 
 // Load reads configuration from environment variables into a Config struct.
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the value of a secret setting in Effective.
+const Redacted = "[REDACTED]"
+
+// Setting is one configuration setting as the server runs with it.
+type Setting struct {
+	Name  string // environment variable
+	Value string
+}
+
+// Effective returns every setting, in declaration order, with secrets
+// redacted: those tagged redact:"secret" are replaced with Redacted when
+// set, and those tagged redact:"url" are reduced to their scheme and host,
+// dropping credentials, paths and query strings that may carry tokens.
+func (c *Config) Effective() []Setting {
+	v := reflect.ValueOf(c).Elem()
+	t := v.Type()
+	settings := make([]Setting, 0, t.NumField())
+	for i := range t.NumField() {
+		field := t.Field(i)
+		name := field.Tag.Get("envconfig")
+		if name == "" {
+			continue
+		}
+		value := formatValue(v.Field(i))
+		switch field.Tag.Get("redact") {
+		case "secret":
+			if value != "" {
+				value = Redacted
+			}
+		case "url":
+			value = redactURL(value)
+		}
+		settings = append(settings, Setting{Name: name, Value: value})
+	}
+	return settings
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(v.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		items := make([]string, 0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			items = append(items, fmt.Sprintf("%v:%v", iter.Key().Interface(), iter.Value().Interface()))
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// redactURL returns the scheme and host of s, or Redacted if s is set but
+// not a URL.
+func redactURL(s string) string {
+	if s == "" {
+		return ""
+	}
+	u, err := url.Parse(s)
+	if err != nil || u.Scheme == "" {
+		return Redacted
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+// Changed returns the settings of next, redacted, whose value differs from
+// prev.
+func Changed(prev, next *Config) []Setting {
+	before := prev.Effective()
+	var changed []Setting
+	for i, s := range next.Effective() {
+		if s != before[i] {
+			changed = append(changed, s)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,249 @@
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// MaxReconcilerInterval is the longest RECONCILER_INTERVAL, in seconds, that
+// can be set at runtime.
+const MaxReconcilerInterval = 3600
+
+// reloadable maps the settings that can change while the server runs to how
+// they are set on a Config. Every other setting requires a restart.
+var reloadable = map[string]func(c *Config, value string) error{
+	"LOG_LEVEL": func(c *Config, value string) error {
+		if _, err := ParseLogLevel(value); err != nil {
+			return err
+		}
+		c.LogLevel = strings.ToLower(strings.TrimSpace(value))
+		return nil
+	},
+	"LOG_MODULE_LEVELS": func(c *Config, value string) error {
+		levels, err := parseMap(value)
+		if err != nil {
+			return err
+		}
+		if _, err := ParseModuleLevels(levels); err != nil {
+			return err
+		}
+		c.LogModuleLevels = levels
+		return nil
+	},
+	"LOG_DEBUG_SAMPLING":    intSetting(func(c *Config) *int { return &c.LogDebugSampling }, 0, 100000),
+	"RECONCILER_INTERVAL":   intSetting(func(c *Config) *int { return &c.ReconcilerInterval }, 1, MaxReconcilerInterval),
+	"RECONCILER_WRITE_RATE": intSetting(func(c *Config) *int { return &c.ReconcilerWriteRate }, 0, 100000),
+}
+
+func intSetting(field func(c *Config) *int, minValue, maxValue int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		if n < minValue || n > maxValue {
+			return fmt.Errorf("must be between %d and %d", minValue, maxValue)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// Reloadable reports whether the setting named name can change at runtime.
+func Reloadable(name string) bool {
+	_, ok := reloadable[name]
+	return ok
+}
+
+// ReloadableSettings returns the names of the settings that can change at
+// runtime, sorted.
+func ReloadableSettings() []string {
+	names := make([]string, 0, len(reloadable))
+	for name := range reloadable {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseLogLevel parses a LOG_LEVEL value: debug, info, warn or error.
+func ParseLogLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info":
+		return slog.LevelInfo, nil
+	case "warn":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	default:
+		return 0, errors.New("must be one of debug, info, warn, error")
+	}
+}
+
+// ParseModuleLevels parses LOG_MODULE_LEVELS, module names mapped to levels
+// as accepted by ParseLogLevel.
+func ParseModuleLevels(levels map[string]string) (map[string]slog.Level, error) {
+	parsed := make(map[string]slog.Level, len(levels))
+	for module, level := range levels {
+		l, err := ParseLogLevel(level)
+		if err != nil {
+			return nil, fmt.Errorf("level of module %s %w", module, err)
+		}
+		parsed[module] = l
+	}
+	return parsed, nil
+}
+
+// parseMap parses a map setting in the syntax of the environment:
+// comma-separated key:value pairs.
+func parseMap(value string) (map[string]string, error) {
+	m := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, ":")
+		if !ok || strings.TrimSpace(k) == "" {
+			return nil, errors.New("must be comma-separated key:value pairs")
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m, nil
+}
+
+// SettingError reports a setting that cannot be changed to the value given.
+type SettingError struct {
+	Name    string
+	Message string
+}
+
+// SettingErrors is returned by Reloader.Set when any setting is rejected;
+// none are then applied.
+type SettingErrors []SettingError
+
+func (e SettingErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, se := range e {
+		msgs[i] = se.Name + " " + se.Message
+	}
+	return "invalid settings: " + strings.Join(msgs, "; ")
+}
+
+// Reloader holds the configuration of a running server and applies changes
+// to its reloadable settings, through PUT /admin/config or a SIGHUP.
+type Reloader struct {
+	mu       sync.Mutex
+	cfg      Config
+	onChange []func(prev, next Config)
+}
+
+// NewReloader creates a Reloader starting from cfg. Each onChange function
+// is called, in order, with the previous and new configuration after every
+// change.
+func NewReloader(cfg Config, onChange ...func(prev, next Config)) *Reloader {
+	return &Reloader{cfg: cfg, onChange: onChange}
+}
+
+// Effective returns the current settings, redacted as by Config.Effective.
+func (r *Reloader) Effective() []Setting {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cfg.Effective()
+}
+
+// Set changes the settings in values, keyed by environment variable. Either
+// all are applied or, if any is not reloadable or not valid, none is and
+// the error is SettingErrors.
+func (r *Reloader) Set(values map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := r.cfg
+	var errs SettingErrors
+	for _, name := range sortedKeys(values) {
+		set, ok := reloadable[name]
+		if !ok {
+			errs = append(errs, SettingError{Name: name, Message: "cannot be changed without a restart"})
+			continue
+		}
+		if err := set(&next, values[name]); err != nil {
+			errs = append(errs, SettingError{Name: name, Message: err.Error()})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+
+	prev := r.cfg
+	r.cfg = next
+	for _, fn := range r.onChange {
+		fn(prev, next)
+	}
+	return nil
+}
+
+// ReloadFile applies the reloadable settings found in the KEY=VALUE file at
+// path, such as a mounted ConfigMap. It returns the names of the other
+// settings in the file, which are ignored until the next restart.
+func (r *Reloader) ReloadFile(path string) (ignored []string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening %s: %w", path, err)
+	}
+	defer f.Close()
+
+	values, err := ParseEnvFile(f)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	for name := range values {
+		if !Reloadable(name) {
+			ignored = append(ignored, name)
+			delete(values, name)
+		}
+	}
+	sort.Strings(ignored)
+	return ignored, r.Set(values)
+}
+
+// ParseEnvFile parses KEY=VALUE lines. Blank lines and lines starting with
+// # are skipped, and values may be wrapped in double quotes.
+func ParseEnvFile(rd io.Reader) (map[string]string, error) {
+	values := map[string]string{}
+	scanner := bufio.NewScanner(rd)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil && strings.HasPrefix(value, `"`) {
+			value = unquoted
+		}
+		values[strings.TrimSpace(name)] = value
+	}
+	return values, scanner.Err()
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Acknowledgement records that a user is aware of a database's current
+// status, typically an error during a known incident. While it is in effect,
+// notifications about the database are suppressed.
+type Acknowledgement struct {
+	By      string
+	Comment string
+	At      time.Time
+	Until   *time.Time // nil: until the status changes
+}
+
+// Acknowledged reports whether the database's current status is acknowledged
+// at now. Repositories clear the acknowledgement when the status changes, so
+// only its expiry needs checking here.
+func (d *Database) Acknowledged(now time.Time) bool {
+	return d.Ack != nil && (d.Ack.Until == nil || now.Before(*d.Ack.Until))
+}
+
+// Acknowledge records an acknowledgement of the database's current status,
+// or clears it when ack is nil.
+func (r *PostgresRepository) Acknowledge(ctx context.Context, id uuid.UUID, ack *Acknowledgement) (*Database, error) {
+	var by, comment *string
+	var at, until *time.Time
+	if ack != nil {
+		by, comment, until = &ack.By, &ack.Comment, ack.Until
+		at = &ack.At
+	}
+
+	query := `
+		UPDATE databases d
+		SET ack_by = $1, ack_comment = $2, acked_at = $3, ack_until = $4, updated_at = NOW()
+		WHERE d.id = $5 AND d.deleted_at IS NULL
+		RETURNING d.id, d.name, d.owner_team_id,
+		          d.owner_team_name, d.tier_id, d.tier_name,
+		          d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		          d.cluster_name, d.pooler_name,
+		          d.status, d.status_reason, d.status_message, d.host, d.port, d.secret_name,
+		          d.generation, d.observed_generation,
+		          d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		          d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		          d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		          d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		          d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		          d.created_by, d.updated_by,
+		          d.created_at, d.updated_at, d.deleted_at`
+
+	db, err := r.scanOne(ctx, query, by, comment, at, until, id)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("acknowledging database: %w", err)
+	}
+	return db, nil
+}
+
+// AckSilencer reports databases whose status is acknowledged as silenced. It
+// implements notify.Silencer.
+type AckSilencer struct {
+	repo Repository
+}
+
+// NewAckSilencer creates an AckSilencer reading databases from repo.
+func NewAckSilencer(repo Repository) *AckSilencer {
+	return &AckSilencer{repo: repo}
+}
+
+// Silenced reports whether the database's current status is acknowledged. A
+// deleted database is not silenced.
+func (s *AckSilencer) Silenced(ctx context.Context, id uuid.UUID) (bool, error) {
+	db, err := s.repo.GetByID(ctx, id)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return db.Acknowledged(time.Now()), nil
+}
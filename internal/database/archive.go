@@ -0,0 +1,44 @@
+package database
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ArchiveRepository reads the databases deleted after a final backup was
+// archived, which keep their record until the retention purges it.
+type ArchiveRepository interface {
+	// GetArchived retrieves a soft-deleted database with an archive URL. It
+	// returns ErrNotFound if there is no such database, e.g. because it was
+	// deleted without being archived, or not deleted at all.
+	GetArchived(ctx context.Context, id uuid.UUID) (*Database, error)
+}
+
+// NewArchiveRepository creates an ArchiveRepository backed by the given
+// connection pool.
+func NewArchiveRepository(pool *pgxpool.Pool) ArchiveRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// GetArchived retrieves a soft-deleted database with an archive URL.
+func (r *PostgresRepository) GetArchived(ctx context.Context, id uuid.UUID) (*Database, error) {
+	query := `
+		SELECT d.id, d.name, d.owner_team_id, d.owner_team_name, d.tier_id, d.tier_name,
+		       d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		       d.cluster_name, d.pooler_name, d.status, d.status_reason, d.status_message,
+		       d.host, d.port, d.secret_name,
+		       d.generation, d.observed_generation,
+		       d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		       d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		       d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		       d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		       d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		       d.created_by, d.updated_by,
+		       d.created_at, d.updated_at, d.deleted_at
+		FROM databases d
+		WHERE d.id = $1 AND d.deleted_at IS NOT NULL AND d.archive_url IS NOT NULL`
+
+	return r.scanOne(ctx, query, id)
+}
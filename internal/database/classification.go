@@ -0,0 +1,31 @@
+package database
+
+import "slices"
+
+// Data classifications, from least to most sensitive. A database's
+// classification says what kind of data it holds; tiers can limit the
+// classifications they host.
+const (
+	ClassificationPublic       = "public"
+	ClassificationInternal     = "internal"
+	ClassificationConfidential = "confidential"
+	ClassificationRestricted   = "restricted"
+)
+
+// Classifications lists the valid data classifications, from least to most
+// sensitive.
+var Classifications = []string{
+	ClassificationPublic,
+	ClassificationInternal,
+	ClassificationConfidential,
+	ClassificationRestricted,
+}
+
+// DefaultClassification is the classification of a database created
+// without one.
+const DefaultClassification = ClassificationInternal
+
+// ValidClassification reports whether c is one of Classifications.
+func ValidClassification(c string) bool {
+	return slices.Contains(Classifications, c)
+}
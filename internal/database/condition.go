@@ -0,0 +1,53 @@
+package database
+
+import "time"
+
+// ConditionNeedsReview flags a database whose resources changed underneath it
+// in a way a platform engineer should look at, such as its clusters now
+// running under a different operator version than the one it was provisioned
+// with.
+const ConditionNeedsReview = "needsReview"
+
+// ReasonOperatorVersionChanged is the reason of a needsReview condition
+// raised because the operator managing a database's resources changed
+// version.
+const ReasonOperatorVersionChanged = "OPERATOR_VERSION_CHANGED"
+
+// Condition is an observation about a database that holds independently of
+// its status, like the conditions of a Kubernetes object.
+type Condition struct {
+	Type    string    `json:"type"`
+	Reason  string    `json:"reason"`
+	Message string    `json:"message"`
+	Since   time.Time `json:"since"`
+}
+
+// Condition returns the database's condition of type t, or nil if it has
+// none.
+func (d *Database) Condition(t string) *Condition {
+	for i := range d.Conditions {
+		if d.Conditions[i].Type == t {
+			return &d.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// WithCondition returns the database's conditions with c replacing any
+// condition of the same type.
+func (d *Database) WithCondition(c Condition) []Condition {
+	return append(d.WithoutCondition(c.Type), c)
+}
+
+// WithoutCondition returns the database's conditions without the one of type
+// t. The result is never nil, so it can be used in a StatusUpdate to clear
+// the last condition.
+func (d *Database) WithoutCondition(t string) []Condition {
+	out := []Condition{}
+	for _, c := range d.Conditions {
+		if c.Type != t {
+			out = append(out, c)
+		}
+	}
+	return out
+}
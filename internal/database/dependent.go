@@ -0,0 +1,110 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrDependentNotFound is returned when a dependent record is not found.
+var ErrDependentNotFound = errors.New("dependent not found")
+
+// ErrDuplicateDependent is returned when a service is already declared as a
+// dependent of the database.
+var ErrDuplicateDependent = errors.New("dependent already declared")
+
+// Dependent declares that an application depends on a database. Service is a
+// free-form identifier chosen by the declaring team, e.g. "checkout-api" or
+// "github.com/acme/orders".
+type Dependent struct {
+	ID          uuid.UUID
+	DatabaseID  uuid.UUID
+	Service     string
+	Description string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// DependentRepository stores database dependency links.
+type DependentRepository interface {
+	Create(ctx context.Context, d *Dependent) error
+	// ListByDatabase returns the dependents of a database ordered by service.
+	ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]Dependent, error)
+	Delete(ctx context.Context, databaseID, id uuid.UUID) error
+}
+
+// PostgresDependentRepository implements DependentRepository using PostgreSQL.
+type PostgresDependentRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewDependentRepository creates a new PostgreSQL-backed DependentRepository.
+func NewDependentRepository(pool *pgxpool.Pool) DependentRepository {
+	return &PostgresDependentRepository{pool: pool}
+}
+
+// Create inserts a dependent and sets its ID and CreatedAt.
+func (r *PostgresDependentRepository) Create(ctx context.Context, d *Dependent) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO database_dependents (database_id, service, description, created_by)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		d.DatabaseID, d.Service, d.Description, d.CreatedBy,
+	).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23505" {
+				return ErrDuplicateDependent
+			}
+			if pgErr.Code == "23503" {
+				return ErrNotFound
+			}
+		}
+		return fmt.Errorf("inserting dependent: %w", err)
+	}
+	return nil
+}
+
+// ListByDatabase returns the dependents of a database ordered by service.
+func (r *PostgresDependentRepository) ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]Dependent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, database_id, service, description, created_by, created_at
+		FROM database_dependents
+		WHERE database_id = $1
+		ORDER BY service`, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("querying dependents: %w", err)
+	}
+	defer rows.Close()
+
+	dependents := []Dependent{}
+	for rows.Next() {
+		var d Dependent
+		if err := rows.Scan(&d.ID, &d.DatabaseID, &d.Service, &d.Description, &d.CreatedBy, &d.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning dependent: %w", err)
+		}
+		dependents = append(dependents, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating dependents: %w", err)
+	}
+	return dependents, nil
+}
+
+// Delete removes a dependent of the given database.
+func (r *PostgresDependentRepository) Delete(ctx context.Context, databaseID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM database_dependents WHERE id = $1 AND database_id = $2`, id, databaseID)
+	if err != nil {
+		return fmt.Errorf("deleting dependent: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrDependentNotFound
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// DNSZone is the DNS zone databases get friendly hostnames in, e.g.
+// "db.example.com" gives database orders-db the hostname
+// orders-db.db.example.com. The empty zone gives none.
+type DNSZone string
+
+// ParseDNSZone validates a DNS zone: a lowercase DNS subdomain, with an
+// optional trailing dot, short enough to prefix it with any database name.
+func ParseDNSZone(zone string) (DNSZone, error) {
+	zone = strings.TrimSuffix(strings.TrimSpace(zone), ".")
+	if zone == "" {
+		return "", nil
+	}
+	if msgs := k8svalidation.IsDNS1123Subdomain(zone); len(msgs) > 0 {
+		return "", fmt.Errorf("invalid DNS zone %q: %s", zone, strings.Join(msgs, "; "))
+	}
+	// Database names are DNS labels of up to 63 characters.
+	if len(zone) > k8svalidation.DNS1123SubdomainMaxLength-64 {
+		return "", fmt.Errorf("invalid DNS zone %q: must be at most %d characters", zone, k8svalidation.DNS1123SubdomainMaxLength-64)
+	}
+	return DNSZone(zone), nil
+}
+
+// Hostname returns the friendly hostname of the database named name in the
+// zone, or "" for the empty zone.
+func (z DNSZone) Hostname(name string) string {
+	if z == "" {
+		return ""
+	}
+	return name + "." + string(z)
+}
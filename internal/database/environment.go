@@ -0,0 +1,74 @@
+package database
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var environmentNameRe = regexp.MustCompile(`^[a-z][a-z0-9-]{0,29}[a-z0-9]$|^[a-z]$`)
+
+// Environments is the ordered promotion chain of deployment environments,
+// e.g. dev, staging, prod: a database in one environment is promoted to the
+// next.
+type Environments []string
+
+// ParseEnvironments validates an ordered list of environment names. Names
+// must be lowercase DNS labels of at most 31 characters and unique.
+func ParseEnvironments(names []string) (Environments, error) {
+	envs := make(Environments, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !environmentNameRe.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment %q: must be a lowercase DNS label of at most 31 characters", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("environment %q is listed twice", name)
+		}
+		seen[name] = true
+		envs = append(envs, name)
+	}
+	return envs, nil
+}
+
+// Default returns the environment new databases are created in: the first
+// of the chain, or "" when no environments are configured.
+func (e Environments) Default() string {
+	if len(e) == 0 {
+		return ""
+	}
+	return e[0]
+}
+
+// Contains reports whether name is one of the environments.
+func (e Environments) Contains(name string) bool {
+	for _, env := range e {
+		if env == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Next returns the environment after name in the chain, and false if name is
+// the last environment or not part of the chain.
+func (e Environments) Next(name string) (string, bool) {
+	for i, env := range e {
+		if env == name && i+1 < len(e) {
+			return e[i+1], true
+		}
+	}
+	return "", false
+}
+
+// PromotedName derives the name of a database promoted from environment from
+// to environment to: a trailing "-<from>" suffix is replaced, otherwise
+// "-<to>" is appended. For example "orders-dev" becomes "orders-staging" and
+// "orders" becomes "orders-staging".
+func PromotedName(name, from, to string) string {
+	return strings.TrimSuffix(name, "-"+from) + "-" + to
+}
@@ -0,0 +1,26 @@
+package database
+
+// Exposure types: how a database is reachable.
+const (
+	ExposureCluster  = "cluster"  // only from inside the Kubernetes cluster
+	ExposureInternal = "internal" // through a load balancer on the internal network
+	ExposureExternal = "external" // through an internet-facing load balancer
+)
+
+// ExposureTypes lists the valid exposure types.
+var ExposureTypes = []string{ExposureCluster, ExposureInternal, ExposureExternal}
+
+// Exposure is how a database is reachable from outside the Kubernetes
+// cluster, if at all.
+type Exposure struct {
+	Type string // one of ExposureTypes; empty means ExposureCluster
+	// AllowedSourceRanges are the CIDRs the load balancer accepts
+	// connections from; empty accepts any. Required for ExposureExternal.
+	AllowedSourceRanges []string
+}
+
+// LoadBalanced reports whether the exposure puts the database behind a load
+// balancer.
+func (e Exposure) LoadBalanced() bool {
+	return e.Type == ExposureInternal || e.Type == ExposureExternal
+}
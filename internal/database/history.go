@@ -0,0 +1,289 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// History tables: the append-only tables recording what happened to
+// databases. They are partitioned by month so that whole months can be
+// dropped or archived once past their retention.
+const (
+	HistoryStatus         = "database_status_history"
+	HistoryResize         = "database_resize_events"
+	HistoryTierChanges    = "database_tier_changes"
+	HistoryPromotions     = "database_promotions"
+	HistoryUsageSamples   = "database_usage_samples"
+	HistoryQuerySnapshots = "database_query_snapshots"
+	HistoryOperations     = "operations"
+	HistoryRevisions      = "revisions"
+)
+
+// HistoryTables lists the history tables with the column each is
+// partitioned by.
+var HistoryTables = map[string]string{
+	HistoryStatus:         "changed_at",
+	HistoryResize:         "created_at",
+	HistoryTierChanges:    "created_at",
+	HistoryPromotions:     "created_at",
+	HistoryUsageSamples:   "collected_at",
+	HistoryQuerySnapshots: "collected_at",
+	HistoryOperations:     "created_at",
+	HistoryRevisions:      "changed_at",
+}
+
+// HistoryArchiveSchema is the schema archived history partitions are moved
+// to, out of the way of the history tables but still in the platform
+// database for export.
+const HistoryArchiveSchema = "history_archive"
+
+// HistoryPartition is the partition of a history table holding one month of
+// rows.
+type HistoryPartition struct {
+	Table string
+	Name  string    // e.g. database_status_history_2026_01
+	Month time.Time // first instant of the month, UTC
+}
+
+// HistoryMonth returns the first instant, in UTC, of the month of t.
+func HistoryMonth(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// HistoryPartitionName returns the name of the partition of table holding
+// the rows of month.
+func HistoryPartitionName(table string, month time.Time) string {
+	return table + "_" + month.UTC().Format("2006_01")
+}
+
+// HistoryRepository manages the monthly partitions of the history tables.
+type HistoryRepository interface {
+	// EnsurePartition creates the partition of table for the month of the
+	// given time if it does not exist, moving in the rows of that month
+	// held by the table's default partition. It reports whether it created
+	// the partition.
+	EnsurePartition(ctx context.Context, table string, month time.Time) (bool, error)
+	// DefaultMonths lists the months of the rows held by the default
+	// partition of table, oldest first: rows written to months that had no
+	// partition yet.
+	DefaultMonths(ctx context.Context, table string) ([]time.Time, error)
+	// ListPartitions lists the monthly partitions of every history table,
+	// oldest month first.
+	ListPartitions(ctx context.Context) ([]HistoryPartition, error)
+	// DropPartition drops a partition with its rows.
+	DropPartition(ctx context.Context, p HistoryPartition) error
+	// ArchivePartition detaches a partition from its table and moves it to
+	// HistoryArchiveSchema.
+	ArchivePartition(ctx context.Context, p HistoryPartition) error
+}
+
+// PostgresHistoryRepository implements HistoryRepository using PostgreSQL
+// declarative partitioning.
+type PostgresHistoryRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewHistoryRepository creates a new PostgreSQL-backed HistoryRepository.
+func NewHistoryRepository(pool *pgxpool.Pool) HistoryRepository {
+	return &PostgresHistoryRepository{pool: pool}
+}
+
+// EnsurePartition creates the partition as a plain table, moves the month's
+// rows into it from the default partition and attaches it: attaching a
+// partition whose range the default partition still holds rows of fails.
+// Moving rows is the one deletion the revisions trigger lets through, when
+// daap.moving_history is on.
+func (r *PostgresHistoryRepository) EnsurePartition(ctx context.Context, table string, month time.Time) (bool, error) {
+	column, ok := HistoryTables[table]
+	if !ok {
+		return false, fmt.Errorf("%s is not a history table", table)
+	}
+	month = HistoryMonth(month)
+	name := HistoryPartitionName(table, month)
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, name).Scan(&exists); err != nil {
+		return false, fmt.Errorf("checking partition %s: %w", name, err)
+	}
+	if exists {
+		return false, nil
+	}
+
+	ident := pgx.Identifier{name}.Sanitize()
+	parent := pgx.Identifier{table}.Sanitize()
+	def := pgx.Identifier{table + "_default"}.Sanitize()
+	col := pgx.Identifier{column}.Sanitize()
+	from, to := month.Format(time.RFC3339), month.AddDate(0, 1, 0).Format(time.RFC3339)
+
+	stmts := []string{
+		`SET LOCAL daap.moving_history = 'on'`,
+		fmt.Sprintf(`CREATE TABLE %s (LIKE %s INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`, ident, parent),
+		fmt.Sprintf(`WITH moved AS (DELETE FROM %s WHERE %s >= '%s' AND %s < '%s' RETURNING *) INSERT INTO %s SELECT * FROM moved`,
+			def, col, from, col, to, ident),
+		fmt.Sprintf(`ALTER TABLE %s ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`, parent, ident, from, to),
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return false, fmt.Errorf("creating partition %s: %w", name, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("committing partition %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// DefaultMonths lists the months of the rows in the default partition.
+func (r *PostgresHistoryRepository) DefaultMonths(ctx context.Context, table string) ([]time.Time, error) {
+	column, ok := HistoryTables[table]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a history table", table)
+	}
+	rows, err := r.pool.Query(ctx, fmt.Sprintf(`
+		SELECT DISTINCT date_trunc('month', %s AT TIME ZONE 'UTC') AS month
+		FROM %s
+		ORDER BY month`,
+		pgx.Identifier{column}.Sanitize(), pgx.Identifier{table + "_default"}.Sanitize()))
+	if err != nil {
+		return nil, fmt.Errorf("listing months of %s_default: %w", table, err)
+	}
+	defer rows.Close()
+
+	var months []time.Time
+	for rows.Next() {
+		var month time.Time
+		if err := rows.Scan(&month); err != nil {
+			return nil, fmt.Errorf("scanning month: %w", err)
+		}
+		months = append(months, HistoryMonth(month))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating months: %w", err)
+	}
+	return months, nil
+}
+
+// ListPartitions lists the partitions attached to the history tables, except
+// their default partitions.
+func (r *PostgresHistoryRepository) ListPartitions(ctx context.Context) ([]HistoryPartition, error) {
+	tables := slices.Collect(maps.Keys(HistoryTables))
+	rows, err := r.pool.Query(ctx, `
+		SELECT parent.relname, child.relname
+		FROM pg_inherits i
+		JOIN pg_class parent ON parent.oid = i.inhparent
+		JOIN pg_class child ON child.oid = i.inhrelid
+		WHERE parent.relnamespace = current_schema()::regnamespace
+		  AND parent.relname = ANY($1)`, tables)
+	if err != nil {
+		return nil, fmt.Errorf("listing history partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []HistoryPartition
+	for rows.Next() {
+		var p HistoryPartition
+		if err := rows.Scan(&p.Table, &p.Name); err != nil {
+			return nil, fmt.Errorf("scanning history partition: %w", err)
+		}
+		month, err := time.Parse("2006_01", strings.TrimPrefix(p.Name, p.Table+"_"))
+		if err != nil {
+			// The default partition, or one not created by DAAP.
+			continue
+		}
+		p.Month = month
+		partitions = append(partitions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating history partitions: %w", err)
+	}
+	sortHistoryPartitions(partitions)
+	return partitions, nil
+}
+
+// DropPartition drops the partition table.
+func (r *PostgresHistoryRepository) DropPartition(ctx context.Context, p HistoryPartition) error {
+	if _, err := r.pool.Exec(ctx, `DROP TABLE IF EXISTS `+pgx.Identifier{p.Name}.Sanitize()); err != nil {
+		return fmt.Errorf("dropping partition %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// ArchivePartition detaches the partition and moves it to
+// HistoryArchiveSchema. A month can be archived twice, when rows written to
+// it after its partition was archived land in the default partition and get
+// a new partition: the rows of the second are then moved into the table
+// already archived, which keeps one archived table per month. Archived
+// partitions keep no foreign keys, so deleting a database leaves its
+// archived history alone.
+func (r *PostgresHistoryRepository) ArchivePartition(ctx context.Context, p HistoryPartition) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck
+
+	ident := pgx.Identifier{p.Name}.Sanitize()
+	archived := pgx.Identifier{HistoryArchiveSchema, p.Name}.Sanitize()
+	var exists bool
+	if err := tx.QueryRow(ctx, `SELECT to_regclass($1) IS NOT NULL`, archived).Scan(&exists); err != nil {
+		return fmt.Errorf("checking archived partition %s: %w", p.Name, err)
+	}
+
+	if _, err := tx.Exec(ctx, fmt.Sprintf(`ALTER TABLE %s DETACH PARTITION %s`, pgx.Identifier{p.Table}.Sanitize(), ident)); err != nil {
+		return fmt.Errorf("detaching partition %s: %w", p.Name, err)
+	}
+
+	var stmts []string
+	if exists {
+		stmts = append(stmts,
+			fmt.Sprintf(`INSERT INTO %s SELECT * FROM %s`, archived, ident),
+			fmt.Sprintf(`DROP TABLE %s`, ident))
+	} else {
+		// A detached partition keeps the foreign keys of its table.
+		rows, err := tx.Query(ctx, `SELECT conname FROM pg_constraint WHERE conrelid = $1::regclass AND contype = 'f'`, ident)
+		if err != nil {
+			return fmt.Errorf("listing foreign keys of partition %s: %w", p.Name, err)
+		}
+		fks, err := pgx.CollectRows(rows, pgx.RowTo[string])
+		if err != nil {
+			return fmt.Errorf("listing foreign keys of partition %s: %w", p.Name, err)
+		}
+		for _, fk := range fks {
+			stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %s DROP CONSTRAINT %s`, ident, pgx.Identifier{fk}.Sanitize()))
+		}
+		stmts = append(stmts, fmt.Sprintf(`ALTER TABLE %s SET SCHEMA %s`, ident, pgx.Identifier{HistoryArchiveSchema}.Sanitize()))
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(ctx, stmt); err != nil {
+			return fmt.Errorf("archiving partition %s: %w", p.Name, err)
+		}
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing archive of partition %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// sortHistoryPartitions sorts partitions by month, then table.
+func sortHistoryPartitions(partitions []HistoryPartition) {
+	slices.SortFunc(partitions, func(a, b HistoryPartition) int {
+		if c := a.Month.Compare(b.Month); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Table, b.Table)
+	})
+}
@@ -0,0 +1,23 @@
+package database
+
+// ImagePin records the image a reference in a database's blueprint was
+// resolved to when the database was provisioned, under the platform's image
+// policy.
+type ImagePin struct {
+	Reference string `json:"reference"`        // as the blueprint writes it
+	Image     string `json:"image"`            // what runs instead: mirrored and, if resolved, pinned to Digest
+	Digest    string `json:"digest,omitempty"` // e.g. "sha256:..."; empty when digests are not pinned
+}
+
+// ImageReplacements maps the references of the database's image pins to the
+// images that replace them, or returns nil if it has none.
+func (d *Database) ImageReplacements() map[string]string {
+	if len(d.Images) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(d.Images))
+	for _, pin := range d.Images {
+		m[pin.Reference] = pin.Image
+	}
+	return m
+}
@@ -0,0 +1,105 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrQuerySnapshotNotFound is returned when no query snapshot was collected
+// for a database.
+var ErrQuerySnapshotNotFound = errors.New("query snapshot not found")
+
+// QueryStat is the cumulative statistics of one statement, as reported by
+// pg_stat_statements since its statistics were last reset.
+type QueryStat struct {
+	QueryID     int64   `json:"query_id"`
+	Query       string  `json:"query"` // with its literals redacted
+	Calls       int64   `json:"calls"`
+	TotalTimeMs float64 `json:"total_time_ms"`
+	MeanTimeMs  float64 `json:"mean_time_ms"`
+	Rows        int64   `json:"rows"`
+}
+
+// QuerySnapshot is the top statements of a database, by total execution
+// time, at one time.
+type QuerySnapshot struct {
+	ID          int64
+	DatabaseID  uuid.UUID
+	Statements  []QueryStat
+	CollectedAt time.Time
+}
+
+// QuerySnapshotRepository stores query snapshots.
+type QuerySnapshotRepository interface {
+	Record(ctx context.Context, s *QuerySnapshot) error
+	// Latest returns a database's most recent snapshot, or
+	// ErrQuerySnapshotNotFound if it has none.
+	Latest(ctx context.Context, databaseID uuid.UUID) (*QuerySnapshot, error)
+	// DeleteBefore removes snapshots collected before the given time and
+	// returns how many were removed.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PostgresQuerySnapshotRepository implements QuerySnapshotRepository using
+// PostgreSQL.
+type PostgresQuerySnapshotRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewQuerySnapshotRepository creates a new PostgreSQL-backed
+// QuerySnapshotRepository.
+func NewQuerySnapshotRepository(pool *pgxpool.Pool) QuerySnapshotRepository {
+	return &PostgresQuerySnapshotRepository{pool: pool}
+}
+
+// Record inserts a snapshot and sets its ID and CollectedAt.
+func (r *PostgresQuerySnapshotRepository) Record(ctx context.Context, s *QuerySnapshot) error {
+	statements := s.Statements
+	if statements == nil {
+		statements = []QueryStat{}
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO database_query_snapshots (database_id, statements)
+		VALUES ($1, $2)
+		RETURNING id, collected_at`,
+		s.DatabaseID, statements,
+	).Scan(&s.ID, &s.CollectedAt)
+	if err != nil {
+		return fmt.Errorf("inserting query snapshot: %w", err)
+	}
+	return nil
+}
+
+// Latest returns a database's most recent snapshot.
+func (r *PostgresQuerySnapshotRepository) Latest(ctx context.Context, databaseID uuid.UUID) (*QuerySnapshot, error) {
+	var s QuerySnapshot
+	err := r.pool.QueryRow(ctx, `
+		SELECT id, database_id, statements, collected_at
+		FROM database_query_snapshots
+		WHERE database_id = $1
+		ORDER BY collected_at DESC, id DESC
+		LIMIT 1`, databaseID,
+	).Scan(&s.ID, &s.DatabaseID, &s.Statements, &s.CollectedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrQuerySnapshotNotFound
+		}
+		return nil, fmt.Errorf("querying query snapshot: %w", err)
+	}
+	return &s, nil
+}
+
+// DeleteBefore removes snapshots collected before the given time.
+func (r *PostgresQuerySnapshotRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM database_query_snapshots WHERE collected_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting query snapshots: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
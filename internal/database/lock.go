@@ -0,0 +1,286 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrLocked is returned when a database is locked by another in-flight
+// operation. The concrete error is a *LockedError describing that operation.
+var ErrLocked = errors.New("database is locked by another operation")
+
+// ErrLockLive is returned by Locker.ForceRelease when a running DAAP process
+// still holds the lock.
+var ErrLockLive = errors.New("database lock is held by a live process")
+
+// LockHeartbeatInterval is how often a Locker records that the locks it
+// holds are still in use. A lock whose holder missed three heartbeats is
+// considered orphaned, even before it expires.
+const LockHeartbeatInterval = 30 * time.Second
+
+// Lock is a held per-database mutation lock. Like a Terraform state lock it
+// records who holds it and why, so a refused caller can point at the
+// in-flight operation. A lock that outlives ExpiresAt is considered abandoned
+// (its holder crashed) and may be taken over.
+type Lock struct {
+	ID         uuid.UUID // identifies this acquisition; required to release it
+	DatabaseID uuid.UUID
+	Operation  string // e.g. "delete", "resize", "rollout"
+	Holder     string // user name, or "system:<component>" for background loops
+	Instance   string // DAAP process holding the lock, as hostname:pid
+	RequestID  string // request that took the lock, empty for background loops
+	AcquiredAt time.Time
+	ExpiresAt  time.Time
+	RenewedAt  time.Time // last heartbeat of its holder
+}
+
+// LockedError is returned by Acquire when the database is already locked.
+type LockedError struct {
+	Lock Lock // the lock currently held
+}
+
+func (e *LockedError) Error() string {
+	return fmt.Sprintf("database is locked by operation %q held by %s since %s",
+		e.Lock.Operation, e.Lock.Holder, e.Lock.AcquiredAt.UTC().Format(time.RFC3339))
+}
+
+// Is makes errors.Is(err, ErrLocked) match.
+func (e *LockedError) Is(target error) bool { return target == ErrLocked }
+
+// LockRepository stores per-database mutation locks.
+type LockRepository interface {
+	// Acquire takes the lock on l.DatabaseID for ttl, setting l.ID,
+	// l.AcquiredAt and l.ExpiresAt. It returns a *LockedError when an
+	// unexpired lock is held, and ErrNotFound when the database does not
+	// exist.
+	Acquire(ctx context.Context, l *Lock, ttl time.Duration) error
+	// Release drops the lock if it is still the acquisition identified by
+	// lockID; releasing a lock that expired and was taken over is a no-op.
+	Release(ctx context.Context, databaseID, lockID uuid.UUID) error
+	// Renew records a heartbeat on the acquisitions identified by lockIDs,
+	// setting their RenewedAt. Unknown IDs are ignored.
+	Renew(ctx context.Context, lockIDs []uuid.UUID) error
+	// Get returns the unexpired lock on a database, or nil.
+	Get(ctx context.Context, databaseID uuid.UUID) (*Lock, error)
+}
+
+// PostgresLockRepository implements LockRepository with one row per locked
+// database. A row rather than pg_advisory_lock is used because session-level
+// advisory locks are tied to a pooled connection and carry no description of
+// the operation holding them.
+type PostgresLockRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewLockRepository creates a new PostgreSQL-backed LockRepository.
+func NewLockRepository(pool *pgxpool.Pool) LockRepository {
+	return &PostgresLockRepository{pool: pool}
+}
+
+const lockColumns = `id, database_id, operation, holder, instance, request_id, acquired_at, expires_at, renewed_at`
+
+func scanLock(row pgx.Row) (*Lock, error) {
+	var l Lock
+	if err := row.Scan(&l.ID, &l.DatabaseID, &l.Operation, &l.Holder, &l.Instance, &l.RequestID, &l.AcquiredAt, &l.ExpiresAt, &l.RenewedAt); err != nil {
+		return nil, err
+	}
+	return &l, nil
+}
+
+// Acquire inserts the lock row, taking over an expired one in the same
+// statement so two callers can never both succeed.
+func (r *PostgresLockRepository) Acquire(ctx context.Context, l *Lock, ttl time.Duration) error {
+	for {
+		err := r.pool.QueryRow(ctx, `
+			INSERT INTO database_locks (database_id, operation, holder, instance, request_id, expires_at)
+			VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(secs => $6))
+			ON CONFLICT (database_id) DO UPDATE SET
+				id = gen_random_uuid(),
+				operation = EXCLUDED.operation,
+				holder = EXCLUDED.holder,
+				instance = EXCLUDED.instance,
+				request_id = EXCLUDED.request_id,
+				acquired_at = NOW(),
+				expires_at = EXCLUDED.expires_at,
+				renewed_at = NOW()
+			WHERE database_locks.expires_at <= NOW()
+			RETURNING id, acquired_at, expires_at, renewed_at`,
+			l.DatabaseID, l.Operation, l.Holder, l.Instance, l.RequestID, ttl.Seconds(),
+		).Scan(&l.ID, &l.AcquiredAt, &l.ExpiresAt, &l.RenewedAt)
+		if err == nil {
+			return nil
+		}
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNotFound
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("acquiring database lock: %w", err)
+		}
+
+		held, err := r.Get(ctx, l.DatabaseID)
+		if err != nil {
+			return err
+		}
+		if held != nil {
+			return &LockedError{Lock: *held}
+		}
+		// Released between the two statements: try again.
+	}
+}
+
+// Release deletes the lock row if it still belongs to lockID.
+func (r *PostgresLockRepository) Release(ctx context.Context, databaseID, lockID uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM database_locks WHERE database_id = $1 AND id = $2`, databaseID, lockID); err != nil {
+		return fmt.Errorf("releasing database lock: %w", err)
+	}
+	return nil
+}
+
+// Renew sets renewed_at on the lock rows of lockIDs.
+func (r *PostgresLockRepository) Renew(ctx context.Context, lockIDs []uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `UPDATE database_locks SET renewed_at = NOW() WHERE id = ANY($1)`, lockIDs); err != nil {
+		return fmt.Errorf("renewing database locks: %w", err)
+	}
+	return nil
+}
+
+// Get returns the unexpired lock on a database, or nil.
+func (r *PostgresLockRepository) Get(ctx context.Context, databaseID uuid.UUID) (*Lock, error) {
+	l, err := scanLock(r.pool.QueryRow(ctx, `
+		SELECT `+lockColumns+`
+		FROM database_locks
+		WHERE database_id = $1 AND expires_at > NOW()`, databaseID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("getting database lock: %w", err)
+	}
+	return l, nil
+}
+
+// Locker takes mutation locks on behalf of one DAAP process. It remembers
+// the locks it holds, so Start can heartbeat them and ForceRelease can tell
+// a lock still in use from one orphaned by a crashed process.
+type Locker struct {
+	repo     LockRepository
+	ttl      time.Duration
+	instance string
+
+	mu   sync.Mutex
+	held map[uuid.UUID]struct{}
+}
+
+// NewLocker creates a Locker whose locks expire after ttl unless released.
+// instance identifies the process, e.g. "hostname:pid".
+func NewLocker(repo LockRepository, ttl time.Duration, instance string) *Locker {
+	return &Locker{repo: repo, ttl: ttl, instance: instance, held: make(map[uuid.UUID]struct{})}
+}
+
+// Acquire locks a database for operation. It fails fast with a *LockedError
+// when another operation holds the lock.
+func (l *Locker) Acquire(ctx context.Context, databaseID uuid.UUID, operation, holder, requestID string) (*Lock, error) {
+	lock := &Lock{
+		DatabaseID: databaseID,
+		Operation:  operation,
+		Holder:     holder,
+		Instance:   l.instance,
+		RequestID:  requestID,
+	}
+	if err := l.repo.Acquire(ctx, lock, l.ttl); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	l.held[lock.ID] = struct{}{}
+	l.mu.Unlock()
+	return lock, nil
+}
+
+// Release releases lock. It uses a context detached from ctx's cancellation
+// so a cancelled request still releases what it took.
+func (l *Locker) Release(ctx context.Context, lock *Lock) error {
+	l.mu.Lock()
+	delete(l.held, lock.ID)
+	l.mu.Unlock()
+	return l.repo.Release(context.WithoutCancel(ctx), lock.DatabaseID, lock.ID)
+}
+
+// Get returns the unexpired lock on a database, or nil.
+func (l *Locker) Get(ctx context.Context, databaseID uuid.UUID) (*Lock, error) {
+	return l.repo.Get(ctx, databaseID)
+}
+
+// Live reports whether a running DAAP process still holds lock. This
+// process knows which locks it holds; another process is live while it
+// heartbeats the lock. A lock taken by an earlier process with the same
+// instance name, e.g. a restarted container, is not held by this one.
+func (l *Locker) Live(lock *Lock, now time.Time) bool {
+	if lock.Instance == l.instance {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		_, ok := l.held[lock.ID]
+		return ok
+	}
+	return now.Sub(lock.RenewedAt) < 3*LockHeartbeatInterval
+}
+
+// ForceRelease releases the lock on a database on behalf of an
+// administrator, returning the lock it released, or nil when the database
+// is not locked. It fails with ErrLockLive while a running process holds
+// the lock.
+func (l *Locker) ForceRelease(ctx context.Context, databaseID uuid.UUID) (*Lock, error) {
+	lock, err := l.repo.Get(ctx, databaseID)
+	if err != nil || lock == nil {
+		return nil, err
+	}
+	if l.Live(lock, time.Now()) {
+		return lock, ErrLockLive
+	}
+	if err := l.repo.Release(context.WithoutCancel(ctx), databaseID, lock.ID); err != nil {
+		return nil, err
+	}
+	return lock, nil
+}
+
+// Heartbeat records that the locks this process holds are still in use.
+func (l *Locker) Heartbeat(ctx context.Context) error {
+	l.mu.Lock()
+	ids := make([]uuid.UUID, 0, len(l.held))
+	for id := range l.held {
+		ids = append(ids, id)
+	}
+	l.mu.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+	return l.repo.Renew(ctx, ids)
+}
+
+// Start heartbeats the locks this process holds every
+// LockHeartbeatInterval. It blocks until ctx is cancelled.
+func (l *Locker) Start(ctx context.Context) {
+	ticker := time.NewTicker(LockHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := l.Heartbeat(ctx); err != nil {
+				slog.Error("failed to heartbeat database locks", "error", err)
+			}
+		}
+	}
+}
@@ -8,32 +8,72 @@ import (
 
 // Database represents a row in the databases table.
 type Database struct {
-	ID            uuid.UUID
-	Name          string
-	OwnerTeamID   uuid.UUID
-	OwnerTeamName string     // transient, populated via JOIN
-	TierID        *uuid.UUID // nullable for pre-v0.5 databases
-	TierName      string     // transient, populated via JOIN
-	Purpose       string
-	Namespace     string
-	ClusterName   string
-	PoolerName    string
-	Status        string
-	Host          *string
-	Port          *int
-	SecretName    *string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	DeletedAt     *time.Time
+	ID                   uuid.UUID
+	Name                 string
+	OwnerTeamID          uuid.UUID
+	OwnerTeamName        string            // transient, populated via JOIN
+	OwnerTeamLabels      map[string]string // owner team's default labels, copied like its name
+	OwnerTeamAnnotations map[string]string // owner team's default annotations, copied like its name
+	TierID               *uuid.UUID        // nullable for pre-v0.5 databases
+	TierName             string            // transient, populated via JOIN
+	Purpose              string
+	DataClassification   string // one of Classifications
+	Namespace            string
+	Environment          string     // deployment environment, e.g. "staging"; empty if unassigned
+	PromotedFromID       *uuid.UUID // database this one was promoted from, if any
+	SourceDatabaseID     *uuid.UUID // database whose backups this one was restored from, if any
+	ClusterName          string
+	PoolerName           string
+	Status               string
+	StatusReason         *string // machine-readable cause of the current status, e.g. PROVISIONING_TIMEOUT
+	StatusMessage        *string // human-readable detail of the current status, as reported by the provider
+	Host                 *string
+	Port                 *int
+	SecretName           *string
+	Generation           int64            // incremented on spec-affecting updates
+	ObservedGeneration   int64            // generation last acted upon by the reconciler
+	Ack                  *Acknowledgement // set while a user has acknowledged the current status
+	Instances            *Instances       // as last observed by the reconciler; nil until reported
+	OperatorVersion      string           // version of the operator its resources were provisioned under; empty if unknown
+	Conditions           []Condition
+	ReconciliationPause  *ReconciliationPause // set while a platform user has paused its reconciliation
+	Placement            *Placement           // how the placement engine chose Namespace; nil if it did not
+	Images               []ImagePin           // images pinned when it was provisioned; empty without an image policy
+	Exposure             Exposure             // how it is reachable from outside the cluster
+	ExternalHost         *string              // load balancer hostname or address, once its provider reports one
+	DNSName              string               // friendly hostname published for it; empty if none
+	QueryInsights        bool                 // whether the insights collector snapshots its top statements
+	ArchiveURL           *string              // where its final backup was stored, once archived before deletion
+	LegalHold            bool                 // while set, it is neither deleted, expired nor purged
+	Rename               *Rename              // set while it is being renamed
+	CredentialsRotatedAt *time.Time           // when its application credentials were last rotated; nil if never
+	CreatedBy            string               // user name of the creator; empty for databases created before it was recorded
+	UpdatedBy            string               // user name, or system actor such as "system:reconciler", of the last change
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
+	DeletedAt            *time.Time
+}
+
+// Instances reports the instances backing a database, as reported by its
+// provider.
+type Instances struct {
+	Total          int
+	Ready          int
+	Primary        string         // current primary instance; empty if none is elected
+	ReplicationLag *time.Duration // largest replica lag; nil if unknown
 }
 
 // ListFilter holds optional filters and pagination for listing databases.
 type ListFilter struct {
-	OwnerTeamID *uuid.UUID
-	Status      *string
-	Name        *string // partial match (ILIKE)
-	Page        int     // default 1
-	Limit       int     // default 20
+	OwnerTeamID    *uuid.UUID
+	TierID         *uuid.UUID
+	Status         *string
+	Name           *string // partial match (ILIKE)
+	Environment    *string
+	Namespace      *string
+	PromotedFromID *uuid.UUID
+	Page           int // default 1
+	Limit          int // default 20
 }
 
 // ListResult holds the result of a paginated list query.
@@ -47,14 +87,62 @@ type ListResult struct {
 // UpdateFields holds user-updatable fields on a database record.
 // Nil fields are not updated.
 type UpdateFields struct {
-	OwnerTeamID *uuid.UUID
-	Purpose     *string
+	OwnerTeamID        *uuid.UUID
+	TierID             *uuid.UUID
+	Purpose            *string
+	DataClassification *string
+	Images             []ImagePin // non-nil replaces the image pins
+	QueryInsights      *bool
+	ArchiveURL         *string // records where the final backup of an archived database was stored
+	LegalHold          *bool
+
+	// CredentialsRotatedAt records when the application credentials were
+	// last rotated.
+	CredentialsRotatedAt *time.Time
+
+	// ReconciliationPause, when set, pauses the reconciliation of the
+	// database; ResumeReconciliation clears any pause.
+	ReconciliationPause  *ReconciliationPause
+	ResumeReconciliation bool
+
+	// UpdatedBy, when set, records who made the update. It is not an update
+	// on its own.
+	UpdatedBy string
 }
 
+// ReasonProvisioningTimeout is the status reason of a database moved to error
+// because it stayed in provisioning longer than the provisioning timeout.
+const ReasonProvisioningTimeout = "PROVISIONING_TIMEOUT"
+
+// ReasonReadinessGateFailed is the status reason of a database whose provider
+// reports it healthy but which failed the readiness gate, so it is not yet
+// marked ready.
+const ReasonReadinessGateFailed = "READINESS_GATE_FAILED"
+
 // StatusUpdate holds fields updated during reconciliation.
 type StatusUpdate struct {
 	Status     string
+	Reason     string // cause of the new status; empty clears any previous reason
+	Message    string // detail of the new status; empty clears any previous message
 	Host       *string
 	Port       *int
 	SecretName *string
+	// ObservedGeneration, when set, records the generation the reconciler
+	// acted upon.
+	ObservedGeneration *int64
+	// Instances, when set, replaces the recorded instances.
+	Instances *Instances
+	// OperatorVersion, when set, replaces the recorded operator version; an
+	// empty string clears it.
+	OperatorVersion *string
+	// ExternalHost, when set, replaces the recorded external host; an empty
+	// string clears it.
+	ExternalHost *string
+	// Conditions, when non-nil, replaces the database's conditions.
+	Conditions []Condition
+	// UpdatedBy, when set, records who made the update.
+	UpdatedBy string
+	// IfStatus, when set, applies the update only if the database still
+	// has that status; UpdateStatus returns ErrNotFound otherwise.
+	IfStatus string
 }
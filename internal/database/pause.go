@@ -0,0 +1,25 @@
+package database
+
+import "time"
+
+// DefaultReconciliationPause is how long a pause lasts when no expiry is
+// given, and MaxReconciliationPause the longest a pause may last, so that a
+// forgotten pause does not leave a database unreconciled for good.
+const (
+	DefaultReconciliationPause = 4 * time.Hour
+	MaxReconciliationPause     = 7 * 24 * time.Hour
+)
+
+// ReconciliationPause records that a platform user paused the reconciliation
+// of a database, e.g. while its cluster is adjusted by hand during an
+// incident. The reconciler leaves the database alone until the pause expires.
+type ReconciliationPause struct {
+	By    string
+	Until time.Time
+}
+
+// ReconciliationPaused reports whether the reconciliation of the database is
+// paused at now.
+func (d *Database) ReconciliationPaused(now time.Time) bool {
+	return d.ReconciliationPause != nil && now.Before(d.ReconciliationPause.Until)
+}
@@ -0,0 +1,21 @@
+package database
+
+import "time"
+
+// Placement records how the placement engine chose the namespace of a
+// database: the namespaces it considered and why it picked one.
+type Placement struct {
+	Namespace  string               `json:"namespace"`
+	Reason     string               `json:"reason"`
+	Candidates []PlacementCandidate `json:"candidates"`
+	DecidedAt  time.Time            `json:"decidedAt"`
+}
+
+// PlacementCandidate is a namespace considered for a database, with its load
+// when the decision was made.
+type PlacementCandidate struct {
+	Namespace string `json:"namespace"`
+	Databases int    `json:"databases"` // active databases in the namespace
+	Capacity  int    `json:"capacity"`  // most active databases the namespace takes
+	Excluded  string `json:"excluded,omitempty"`
+}
@@ -0,0 +1,98 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Promotion actions.
+const (
+	PromotionCreated = "created"
+	PromotionUpdated = "updated"
+)
+
+// Promotion is an audit record of a database being promoted to the next
+// environment. Names, the tier and the blueprint are recorded as they were at
+// the time of the promotion, so the record shows which definition each
+// environment received.
+type Promotion struct {
+	ID               int64
+	SourceDatabaseID uuid.UUID
+	SourceName       string
+	TargetDatabaseID uuid.UUID
+	TargetName       string
+	FromEnvironment  string
+	ToEnvironment    string
+	Tier             string
+	Blueprint        string // empty if the tier has no blueprint
+	Action           string // PromotionCreated or PromotionUpdated
+	Actor            string
+	CreatedAt        time.Time
+}
+
+// PromotionRepository stores the promotion audit trail.
+type PromotionRepository interface {
+	Record(ctx context.Context, p *Promotion) error
+	// ListByDatabase returns the promotions a database was the source or the
+	// target of, oldest first.
+	ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]Promotion, error)
+}
+
+// PostgresPromotionRepository implements PromotionRepository using PostgreSQL.
+type PostgresPromotionRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPromotionRepository creates a new PostgreSQL-backed PromotionRepository.
+func NewPromotionRepository(pool *pgxpool.Pool) PromotionRepository {
+	return &PostgresPromotionRepository{pool: pool}
+}
+
+// Record inserts a promotion and sets its ID and CreatedAt.
+func (r *PostgresPromotionRepository) Record(ctx context.Context, p *Promotion) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO database_promotions (source_database_id, source_name, target_database_id, target_name,
+		                                 from_environment, to_environment, tier, blueprint, action, actor)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at`,
+		p.SourceDatabaseID, p.SourceName, p.TargetDatabaseID, p.TargetName,
+		p.FromEnvironment, p.ToEnvironment, p.Tier, p.Blueprint, p.Action, p.Actor,
+	).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting promotion: %w", err)
+	}
+	return nil
+}
+
+// ListByDatabase returns the promotions a database was the source or the
+// target of, oldest first.
+func (r *PostgresPromotionRepository) ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]Promotion, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, source_database_id, source_name, target_database_id, target_name,
+		       from_environment, to_environment, tier, blueprint, action, actor, created_at
+		FROM database_promotions
+		WHERE source_database_id = $1 OR target_database_id = $1
+		ORDER BY created_at, id`, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("querying promotions: %w", err)
+	}
+	defer rows.Close()
+
+	promotions := []Promotion{}
+	for rows.Next() {
+		var p Promotion
+		if err := rows.Scan(&p.ID, &p.SourceDatabaseID, &p.SourceName, &p.TargetDatabaseID, &p.TargetName,
+			&p.FromEnvironment, &p.ToEnvironment, &p.Tier, &p.Blueprint, &p.Action, &p.Actor, &p.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning promotion: %w", err)
+		}
+		promotions = append(promotions, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating promotions: %w", err)
+	}
+	return promotions, nil
+}
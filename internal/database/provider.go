@@ -0,0 +1,32 @@
+package database
+
+import (
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// ProviderDatabase builds what a provider needs to manage the database on
+// tier t with blueprint bp.
+func (d *Database) ProviderDatabase(t *tier.Tier, bp *blueprint.Blueprint) provider.ProviderDatabase {
+	return provider.ProviderDatabase{
+		ID:          d.ID,
+		Name:        d.Name,
+		Namespace:   d.Namespace,
+		ClusterName: d.ClusterName,
+		PoolerName:  d.PoolerName,
+		OwnerTeam:   d.OwnerTeamName,
+		OwnerTeamID: d.OwnerTeamID,
+		Tier:        t.Name,
+		TierID:      t.ID,
+		Blueprint:   bp.Name,
+		Provider:    bp.Provider,
+		Labels:      d.OwnerTeamLabels,
+		Annotations: d.OwnerTeamAnnotations,
+		Topology:    provider.Topology(t.Topology),
+		Disruption:  provider.Disruption(t.Disruption),
+		Images:      d.ImageReplacements(),
+		Exposure:    provider.Exposure(d.Exposure),
+		DNSName:     d.DNSName,
+	}
+}
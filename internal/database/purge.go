@@ -0,0 +1,80 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// DeletedDatabase is a soft-deleted database, as listed for purging.
+type DeletedDatabase struct {
+	ID                 uuid.UUID
+	Name               string
+	OwnerTeamName      string
+	DataClassification string
+	LegalHold          bool
+	DeletedAt          time.Time
+}
+
+// PurgeRepository permanently removes soft-deleted databases. Their status
+// history, operations, jobs and every other row that belongs to them go with
+// them; their revisions are kept.
+type PurgeRepository interface {
+	// ListDeletedBefore lists the databases soft-deleted before the given
+	// time, those under legal hold included, oldest deletion first.
+	ListDeletedBefore(ctx context.Context, before time.Time) ([]DeletedDatabase, error)
+	// Purge permanently removes a database soft-deleted before the given
+	// time. It returns ErrNotFound if there is no such database, or it is
+	// under legal hold.
+	Purge(ctx context.Context, id uuid.UUID, deletedBefore time.Time) error
+}
+
+// NewPurgeRepository creates a PurgeRepository backed by the given connection
+// pool.
+func NewPurgeRepository(pool *pgxpool.Pool) PurgeRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// ListDeletedBefore lists the databases soft-deleted before the given time.
+func (r *PostgresRepository) ListDeletedBefore(ctx context.Context, before time.Time) ([]DeletedDatabase, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, name, owner_team_name, data_classification, legal_hold, deleted_at
+		FROM databases
+		WHERE deleted_at IS NOT NULL AND deleted_at < $1
+		ORDER BY deleted_at, id`, before)
+	if err != nil {
+		return nil, fmt.Errorf("listing deleted databases: %w", err)
+	}
+	defer rows.Close()
+
+	var out []DeletedDatabase
+	for rows.Next() {
+		var d DeletedDatabase
+		if err := rows.Scan(&d.ID, &d.Name, &d.OwnerTeamName, &d.DataClassification, &d.LegalHold, &d.DeletedAt); err != nil {
+			return nil, fmt.Errorf("scanning deleted database: %w", err)
+		}
+		out = append(out, d)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating deleted databases: %w", err)
+	}
+	return out, nil
+}
+
+// Purge deletes the database row; the foreign keys of its history cascade.
+func (r *PostgresRepository) Purge(ctx context.Context, id uuid.UUID, deletedBefore time.Time) error {
+	result, err := r.pool.Exec(ctx, `
+		DELETE FROM databases
+		WHERE id = $1 AND deleted_at IS NOT NULL AND deleted_at < $2 AND NOT legal_hold`,
+		id, deletedBefore)
+	if err != nil {
+		return fmt.Errorf("purging database: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ErrRenameInProgress is returned when a database that is already being
+// renamed is renamed again.
+var ErrRenameInProgress = errors.New("database is already being renamed")
+
+// Phases of a Rename.
+const (
+	// RenameCopying: a copy of the database is provisioned under the new
+	// name and catches up, while the database keeps serving under the old
+	// one.
+	RenameCopying = "copying"
+	// RenameCutover: writes have stopped under the old name while the copy
+	// is promoted.
+	RenameCutover = "cutover"
+	// RenameRetiring: the database has taken the new name, and the
+	// resources under the old one are being deleted.
+	RenameRetiring = "retiring"
+	// RenameAbandoning: the copy failed and is being deleted; the database
+	// keeps its old name.
+	RenameAbandoning = "abandoning"
+)
+
+// Rename is a rename of a database in progress. Its new name is reserved
+// for the database until the rename is cleared.
+type Rename struct {
+	From        string    `json:"from"`
+	To          string    `json:"to"`
+	DNSName     string    `json:"dnsName,omitempty"` // friendly hostname under the new name; empty for none
+	Phase       string    `json:"phase"`             // one of the Rename* phases
+	RequestedBy string    `json:"requestedBy"`
+	StartedAt   time.Time `json:"startedAt"`
+}
+
+// ClusterName returns the cluster name of a database named name.
+func ClusterName(name string) string {
+	return fmt.Sprintf("daap-%s", name)
+}
+
+// PoolerName returns the pooler name of a database named name.
+func PoolerName(name string) string {
+	return fmt.Sprintf("daap-%s-pooler", name)
+}
+
+// RenameRepository records the progress of database renames.
+type RenameRepository interface {
+	// StartRename records rename on the database id. It returns ErrNotFound
+	// if there is no such database, ErrRenameInProgress if it is already
+	// being renamed, and ErrDuplicateName if another database has, or is
+	// being renamed to, rename.To.
+	StartRename(ctx context.Context, id uuid.UUID, rename Rename) (*Database, error)
+	// SetRenamePhase moves the rename of the database id to phase. It
+	// returns ErrNotFound if the database is not being renamed.
+	SetRenamePhase(ctx context.Context, id uuid.UUID, phase string) (*Database, error)
+	// CompleteRename gives the database id the name, cluster, pooler and
+	// DNS name of its rename, and the given connection details where set,
+	// and moves the rename to RenameRetiring. It returns ErrNotFound if the
+	// database is not being renamed, and ErrDuplicateName if another
+	// database took the new name meanwhile.
+	CompleteRename(ctx context.Context, id uuid.UUID, host *string, port *int, secretName *string, by string) (*Database, error)
+	// ClearRename forgets the rename of the database id, if any.
+	ClearRename(ctx context.Context, id uuid.UUID) (*Database, error)
+}
+
+// NewRenameRepository creates a RenameRepository backed by the given
+// connection pool.
+func NewRenameRepository(pool *pgxpool.Pool) RenameRepository {
+	return &PostgresRepository{pool: pool}
+}
+
+// renameReturning is the RETURNING clause of the rename updates.
+const renameReturning = `
+		RETURNING d.id, d.name, d.owner_team_id,
+		          d.owner_team_name, d.tier_id, d.tier_name,
+		          d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		          d.cluster_name, d.pooler_name,
+		          d.status, d.status_reason, d.status_message, d.host, d.port, d.secret_name,
+		          d.generation, d.observed_generation,
+		          d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		          d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		          d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		          d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		          d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		          d.created_by, d.updated_by,
+		          d.created_at, d.updated_at, d.deleted_at`
+
+// StartRename records rename unless the new name is taken or reserved. The
+// unique index on the reserved names settles concurrent renames to the same
+// name.
+func (r *PostgresRepository) StartRename(ctx context.Context, id uuid.UUID, rename Rename) (*Database, error) {
+	query := `
+		UPDATE databases d
+		SET rename = $2, updated_by = $3, updated_at = NOW()
+		WHERE d.id = $1 AND d.deleted_at IS NULL AND d.rename IS NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM databases o
+		      WHERE o.deleted_at IS NULL AND (o.name = $4 OR o.rename->>'to' = $4)
+		  )` + renameReturning
+
+	db, err := r.scanOne(ctx, query, id, rename, rename.RequestedBy, rename.To)
+	if err == nil {
+		return db, nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return nil, ErrDuplicateName
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("starting rename: %w", err)
+	}
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Rename != nil {
+		return nil, ErrRenameInProgress
+	}
+	return nil, ErrDuplicateName
+}
+
+// SetRenamePhase updates the phase in the recorded rename.
+func (r *PostgresRepository) SetRenamePhase(ctx context.Context, id uuid.UUID, phase string) (*Database, error) {
+	query := `
+		UPDATE databases d
+		SET rename = jsonb_set(d.rename, '{phase}', to_jsonb($2::text)), updated_at = NOW()
+		WHERE d.id = $1 AND d.deleted_at IS NULL AND d.rename IS NOT NULL` + renameReturning
+
+	db, err := r.scanOne(ctx, query, id, phase)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("setting rename phase: %w", err)
+	}
+	return db, err
+}
+
+// CompleteRename switches the database to the names of its rename.
+func (r *PostgresRepository) CompleteRename(ctx context.Context, id uuid.UUID, host *string, port *int, secretName *string, by string) (*Database, error) {
+	current, err := r.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if current.Rename == nil {
+		return nil, ErrNotFound
+	}
+	to := current.Rename.To
+
+	query := `
+		UPDATE databases d
+		SET name = $2, cluster_name = $3, pooler_name = $4, dns_name = $5,
+		    host = COALESCE($6, d.host), port = COALESCE($7, d.port), secret_name = COALESCE($8, d.secret_name),
+		    rename = jsonb_set(d.rename, '{phase}', to_jsonb($9::text)),
+		    updated_by = $10, updated_at = NOW()
+		WHERE d.id = $1 AND d.deleted_at IS NULL AND d.rename->>'to' = $2` + renameReturning
+
+	db, err := r.scanOne(ctx, query, id, to, ClusterName(to), PoolerName(to), current.Rename.DNSName,
+		host, port, secretName, RenameRetiring, by)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, ErrDuplicateName
+		}
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("completing rename: %w", err)
+	}
+	return db, nil
+}
+
+// ClearRename sets the rename to NULL.
+func (r *PostgresRepository) ClearRename(ctx context.Context, id uuid.UUID) (*Database, error) {
+	query := `
+		UPDATE databases d
+		SET rename = NULL, updated_at = NOW()
+		WHERE d.id = $1 AND d.deleted_at IS NULL` + renameReturning
+
+	db, err := r.scanOne(ctx, query, id)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("clearing rename: %w", err)
+	}
+	return db, err
+}
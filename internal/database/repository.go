@@ -29,10 +29,15 @@ var ErrInvalidTier = errors.New("invalid tier")
 type Repository interface {
 	Create(ctx context.Context, db *Database) error
 	GetByID(ctx context.Context, id uuid.UUID) (*Database, error)
+	// GetByName retrieves the non-deleted database with exactly this name.
+	GetByName(ctx context.Context, name string) (*Database, error)
 	List(ctx context.Context, filter ListFilter) (*ListResult, error)
 	Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Database, error)
 	UpdateStatus(ctx context.Context, id uuid.UUID, su StatusUpdate) (*Database, error)
 	SoftDelete(ctx context.Context, id uuid.UUID) error
+	// Acknowledge records an acknowledgement of the database's current
+	// status, or clears it when ack is nil. A status change clears it too.
+	Acknowledge(ctx context.Context, id uuid.UUID, ack *Acknowledgement) (*Database, error)
 }
 
 // PostgresRepository implements Repository using pgxpool.
@@ -48,27 +53,63 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 // Create inserts a new database record. It auto-generates cluster_name and pooler_name
 // from the database name, and sets status to "provisioning".
 func (r *PostgresRepository) Create(ctx context.Context, db *Database) error {
-	db.ClusterName = fmt.Sprintf("daap-%s", db.Name)
-	db.PoolerName = fmt.Sprintf("daap-%s-pooler", db.Name)
+	db.ClusterName = ClusterName(db.Name)
+	db.PoolerName = PoolerName(db.Name)
 	if db.Status == "" {
 		db.Status = "provisioning"
 	}
+	if db.DataClassification == "" {
+		db.DataClassification = DefaultClassification
+	}
+	if db.Exposure.Type == "" {
+		db.Exposure.Type = ExposureCluster
+	}
+
+	// A name being renamed to is taken as much as one in use.
+	var reserved bool
+	err := r.pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM databases WHERE rename->>'to' = $1 AND deleted_at IS NULL)`,
+		db.Name).Scan(&reserved)
+	if err != nil {
+		return fmt.Errorf("checking reserved names: %w", err)
+	}
+	if reserved {
+		return ErrDuplicateName
+	}
 
+	// The initial status is recorded in the status history in the same statement.
 	query := `
-		INSERT INTO databases (name, owner_team_id, tier_id, purpose, namespace, cluster_name, pooler_name, status)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, created_at, updated_at`
+		WITH ins AS (
+			INSERT INTO databases (name, owner_team_id, tier_id, purpose, data_classification, namespace, environment, promoted_from_id, cluster_name, pooler_name, status, created_by, updated_by, placement, images, exposure, exposure_allowed_ranges, dns_name, query_insights, source_database_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $12, $13, $14, $15, $16, $17, $18, $19)
+			RETURNING id, status, owner_team_labels, owner_team_annotations, generation, observed_generation, created_at, updated_at
+		), hist AS (
+			INSERT INTO database_status_history (database_id, from_status, to_status, changed_at)
+			SELECT id, NULL, status, created_at FROM ins
+		)
+		SELECT id, owner_team_labels, owner_team_annotations, generation, observed_generation, created_at, updated_at FROM ins`
 
-	err := r.pool.QueryRow(ctx, query,
+	err = r.pool.QueryRow(ctx, query,
 		db.Name,
 		db.OwnerTeamID,
 		db.TierID,
 		db.Purpose,
+		db.DataClassification,
 		db.Namespace,
+		db.Environment,
+		db.PromotedFromID,
 		db.ClusterName,
 		db.PoolerName,
 		db.Status,
-	).Scan(&db.ID, &db.CreatedAt, &db.UpdatedAt)
+		db.CreatedBy,
+		db.Placement,
+		imagePins(db.Images),
+		db.Exposure.Type,
+		exposureRanges(db.Exposure.AllowedSourceRanges),
+		db.DNSName,
+		db.QueryInsights,
+		db.SourceDatabaseID,
+	).Scan(&db.ID, &db.OwnerTeamLabels, &db.OwnerTeamAnnotations, &db.Generation, &db.ObservedGeneration, &db.CreatedAt, &db.UpdatedAt)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
@@ -91,19 +132,46 @@ func (r *PostgresRepository) Create(ctx context.Context, db *Database) error {
 // GetByID retrieves a single non-deleted database by its UUID.
 func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Database, error) {
 	query := `
-		SELECT d.id, d.name, d.owner_team_id, t.name, d.tier_id, COALESCE(tr.name, ''),
-		       d.purpose, d.namespace,
-		       d.cluster_name, d.pooler_name, d.status,
+		SELECT d.id, d.name, d.owner_team_id, d.owner_team_name, d.tier_id, d.tier_name,
+		       d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		       d.cluster_name, d.pooler_name, d.status, d.status_reason, d.status_message,
 		       d.host, d.port, d.secret_name,
+		       d.generation, d.observed_generation,
+		       d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		       d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		       d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		       d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		       d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		       d.created_by, d.updated_by,
 		       d.created_at, d.updated_at, d.deleted_at
 		FROM databases d
-		LEFT JOIN teams t ON d.owner_team_id = t.id
-		LEFT JOIN tiers tr ON d.tier_id = tr.id
 		WHERE d.id = $1 AND d.deleted_at IS NULL`
 
 	return r.scanOne(ctx, query, id)
 }
 
+// GetByName retrieves the non-deleted database with exactly this name.
+// Active names are unique, so there is at most one.
+func (r *PostgresRepository) GetByName(ctx context.Context, name string) (*Database, error) {
+	query := `
+		SELECT d.id, d.name, d.owner_team_id, d.owner_team_name, d.tier_id, d.tier_name,
+		       d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		       d.cluster_name, d.pooler_name, d.status, d.status_reason, d.status_message,
+		       d.host, d.port, d.secret_name,
+		       d.generation, d.observed_generation,
+		       d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		       d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		       d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		       d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		       d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		       d.created_by, d.updated_by,
+		       d.created_at, d.updated_at, d.deleted_at
+		FROM databases d
+		WHERE d.name = $1 AND d.deleted_at IS NULL`
+
+	return r.scanOne(ctx, query, name)
+}
+
 // List retrieves a paginated, filtered list of non-deleted databases.
 func (r *PostgresRepository) List(ctx context.Context, filter ListFilter) (*ListResult, error) {
 	if filter.Page < 1 {
@@ -127,6 +195,11 @@ func (r *PostgresRepository) List(ctx context.Context, filter ListFilter) (*List
 		args = append(args, *filter.OwnerTeamID)
 		argIdx++
 	}
+	if filter.TierID != nil {
+		conditions = append(conditions, fmt.Sprintf("d.tier_id = $%d", argIdx))
+		args = append(args, *filter.TierID)
+		argIdx++
+	}
 	if filter.Status != nil {
 		conditions = append(conditions, fmt.Sprintf("d.status = $%d", argIdx))
 		args = append(args, *filter.Status)
@@ -137,6 +210,21 @@ func (r *PostgresRepository) List(ctx context.Context, filter ListFilter) (*List
 		args = append(args, "%"+*filter.Name+"%")
 		argIdx++
 	}
+	if filter.Environment != nil {
+		conditions = append(conditions, fmt.Sprintf("d.environment = $%d", argIdx))
+		args = append(args, *filter.Environment)
+		argIdx++
+	}
+	if filter.Namespace != nil {
+		conditions = append(conditions, fmt.Sprintf("d.namespace = $%d", argIdx))
+		args = append(args, *filter.Namespace)
+		argIdx++
+	}
+	if filter.PromotedFromID != nil {
+		conditions = append(conditions, fmt.Sprintf("d.promoted_from_id = $%d", argIdx))
+		args = append(args, *filter.PromotedFromID)
+		argIdx++
+	}
 
 	whereClause := "WHERE " + strings.Join(conditions, " AND ")
 
@@ -150,14 +238,19 @@ func (r *PostgresRepository) List(ctx context.Context, filter ListFilter) (*List
 	offset := (filter.Page - 1) * filter.Limit
 
 	dataQuery := fmt.Sprintf(`
-		SELECT d.id, d.name, d.owner_team_id, t.name, d.tier_id, COALESCE(tr.name, ''),
-		       d.purpose, d.namespace,
-		       d.cluster_name, d.pooler_name, d.status,
+		SELECT d.id, d.name, d.owner_team_id, d.owner_team_name, d.tier_id, d.tier_name,
+		       d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		       d.cluster_name, d.pooler_name, d.status, d.status_reason, d.status_message,
 		       d.host, d.port, d.secret_name,
+		       d.generation, d.observed_generation,
+		       d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		       d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		       d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		       d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		       d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		       d.created_by, d.updated_by,
 		       d.created_at, d.updated_at, d.deleted_at
 		FROM databases d
-		LEFT JOIN teams t ON d.owner_team_id = t.id
-		LEFT JOIN tiers tr ON d.tier_id = tr.id
 		%s
 		ORDER BY d.created_at DESC
 		LIMIT $%d OFFSET $%d`, whereClause, argIdx, argIdx+1)
@@ -172,18 +265,11 @@ func (r *PostgresRepository) List(ctx context.Context, filter ListFilter) (*List
 
 	var databases []Database
 	for rows.Next() {
-		var db Database
-		err := rows.Scan(
-			&db.ID, &db.Name, &db.OwnerTeamID, &db.OwnerTeamName, &db.TierID, &db.TierName,
-			&db.Purpose, &db.Namespace,
-			&db.ClusterName, &db.PoolerName, &db.Status,
-			&db.Host, &db.Port, &db.SecretName,
-			&db.CreatedAt, &db.UpdatedAt, &db.DeletedAt,
-		)
+		db, err := scanDatabase(rows)
 		if err != nil {
 			return nil, fmt.Errorf("scanning database row: %w", err)
 		}
-		databases = append(databases, db)
+		databases = append(databases, *db)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("iterating database rows: %w", err)
@@ -201,27 +287,86 @@ func (r *PostgresRepository) List(ctx context.Context, filter ListFilter) (*List
 	}, nil
 }
 
-// Update modifies user-updatable fields (owner_team_id, purpose) on a non-deleted database.
+// Update modifies updatable fields (owner_team_id, tier_id, purpose, data_classification,
+// image pins, credential rotation time, reconciliation pause) on a non-deleted database.
+// Changing the owner team or tier changes the rendered manifests, so it increments generation.
 func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Database, error) {
 	var setClauses []string
 	var args []any
 	argIdx := 1
 
+	// Spec-affecting changes bump the generation once per update.
+	var specChanged []string
 	if fields.OwnerTeamID != nil {
+		specChanged = append(specChanged, fmt.Sprintf("owner_team_id IS DISTINCT FROM $%d", argIdx))
 		setClauses = append(setClauses, fmt.Sprintf("owner_team_id = $%d", argIdx))
 		args = append(args, *fields.OwnerTeamID)
 		argIdx++
 	}
+	if fields.TierID != nil {
+		specChanged = append(specChanged, fmt.Sprintf("tier_id IS DISTINCT FROM $%d", argIdx))
+		setClauses = append(setClauses, fmt.Sprintf("tier_id = $%d", argIdx))
+		args = append(args, *fields.TierID)
+		argIdx++
+	}
+	if len(specChanged) > 0 {
+		setClauses = append(setClauses, fmt.Sprintf(
+			"generation = generation + CASE WHEN %s THEN 1 ELSE 0 END", strings.Join(specChanged, " OR ")))
+	}
 	if fields.Purpose != nil {
 		setClauses = append(setClauses, fmt.Sprintf("purpose = $%d", argIdx))
 		args = append(args, *fields.Purpose)
 		argIdx++
 	}
+	if fields.DataClassification != nil {
+		setClauses = append(setClauses, fmt.Sprintf("data_classification = $%d", argIdx))
+		args = append(args, *fields.DataClassification)
+		argIdx++
+	}
+	if fields.Images != nil {
+		setClauses = append(setClauses, fmt.Sprintf("images = $%d", argIdx))
+		args = append(args, fields.Images)
+		argIdx++
+	}
+	if fields.QueryInsights != nil {
+		setClauses = append(setClauses, fmt.Sprintf("query_insights = $%d", argIdx))
+		args = append(args, *fields.QueryInsights)
+		argIdx++
+	}
+	if fields.ArchiveURL != nil {
+		setClauses = append(setClauses, fmt.Sprintf("archive_url = $%d", argIdx))
+		args = append(args, *fields.ArchiveURL)
+		argIdx++
+	}
+	if fields.LegalHold != nil {
+		setClauses = append(setClauses, fmt.Sprintf("legal_hold = $%d", argIdx))
+		args = append(args, *fields.LegalHold)
+		argIdx++
+	}
+	if fields.CredentialsRotatedAt != nil {
+		setClauses = append(setClauses, fmt.Sprintf("credentials_rotated_at = $%d", argIdx))
+		args = append(args, *fields.CredentialsRotatedAt)
+		argIdx++
+	}
+	if fields.ReconciliationPause != nil {
+		setClauses = append(setClauses,
+			fmt.Sprintf("reconciliation_paused_by = $%d", argIdx),
+			fmt.Sprintf("reconciliation_paused_until = $%d", argIdx+1))
+		args = append(args, fields.ReconciliationPause.By, fields.ReconciliationPause.Until)
+		argIdx += 2
+	} else if fields.ResumeReconciliation {
+		setClauses = append(setClauses, "reconciliation_paused_by = NULL", "reconciliation_paused_until = NULL")
+	}
 
 	if len(setClauses) == 0 {
 		return r.GetByID(ctx, id)
 	}
 
+	if fields.UpdatedBy != "" {
+		setClauses = append(setClauses, fmt.Sprintf("updated_by = $%d", argIdx))
+		args = append(args, fields.UpdatedBy)
+		argIdx++
+	}
 	setClauses = append(setClauses, "updated_at = NOW()")
 
 	args = append(args, id)
@@ -231,10 +376,17 @@ func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields Up
 		SET %s
 		WHERE d.id = $%d AND d.deleted_at IS NULL
 		RETURNING d.id, d.name, d.owner_team_id,
-		          (SELECT t.name FROM teams t WHERE t.id = d.owner_team_id),
-		          d.tier_id, COALESCE((SELECT tr.name FROM tiers tr WHERE tr.id = d.tier_id), ''),
-		          d.purpose, d.namespace, d.cluster_name, d.pooler_name,
-		          d.status, d.host, d.port, d.secret_name,
+		          d.owner_team_name, d.tier_id, d.tier_name,
+		          d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		          d.cluster_name, d.pooler_name,
+		          d.status, d.status_reason, d.status_message, d.host, d.port, d.secret_name,
+		          d.generation, d.observed_generation,
+		          d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		          d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		          d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		          d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		          d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		          d.created_by, d.updated_by,
 		          d.created_at, d.updated_at, d.deleted_at`,
 		strings.Join(setClauses, ", "), argIdx)
 
@@ -242,6 +394,9 @@ func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields Up
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			if strings.Contains(pgErr.ConstraintName, "tier") {
+				return nil, ErrInvalidTier
+			}
 			return nil, ErrInvalidOwnerTeam
 		}
 		return nil, err
@@ -250,6 +405,7 @@ func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields Up
 }
 
 // UpdateStatus updates the status and connection details of a database record (used by the reconciler).
+// A status change is appended to the status history in the same statement.
 func (r *PostgresRepository) UpdateStatus(ctx context.Context, id uuid.UUID, su StatusUpdate) (*Database, error) {
 	var setClauses []string
 	var args []any
@@ -259,6 +415,20 @@ func (r *PostgresRepository) UpdateStatus(ctx context.Context, id uuid.UUID, su
 	args = append(args, su.Status)
 	argIdx++
 
+	// An acknowledgement only covers the status it was given for. The
+	// right-hand sides see the row before the update.
+	for _, col := range []string{"ack_by", "ack_comment", "acked_at", "ack_until"} {
+		setClauses = append(setClauses, fmt.Sprintf("%[1]s = CASE WHEN d.status = $1 THEN d.%[1]s END", col))
+	}
+
+	setClauses = append(setClauses, fmt.Sprintf("status_reason = NULLIF($%d, '')", argIdx))
+	args = append(args, su.Reason)
+	argIdx++
+
+	setClauses = append(setClauses, fmt.Sprintf("status_message = NULLIF($%d, '')", argIdx))
+	args = append(args, su.Message)
+	argIdx++
+
 	if su.Host != nil {
 		setClauses = append(setClauses, fmt.Sprintf("host = $%d", argIdx))
 		args = append(args, *su.Host)
@@ -274,22 +444,77 @@ func (r *PostgresRepository) UpdateStatus(ctx context.Context, id uuid.UUID, su
 		args = append(args, *su.SecretName)
 		argIdx++
 	}
+	if su.ObservedGeneration != nil {
+		setClauses = append(setClauses, fmt.Sprintf("observed_generation = $%d", argIdx))
+		args = append(args, *su.ObservedGeneration)
+		argIdx++
+	}
+	if su.Instances != nil {
+		var lagMs *int64
+		if su.Instances.ReplicationLag != nil {
+			ms := su.Instances.ReplicationLag.Milliseconds()
+			lagMs = &ms
+		}
+		setClauses = append(setClauses,
+			fmt.Sprintf("instances_total = $%d", argIdx),
+			fmt.Sprintf("instances_ready = $%d", argIdx+1),
+			fmt.Sprintf("current_primary = NULLIF($%d, '')", argIdx+2),
+			fmt.Sprintf("replication_lag_ms = $%d", argIdx+3))
+		args = append(args, su.Instances.Total, su.Instances.Ready, su.Instances.Primary, lagMs)
+		argIdx += 4
+	}
+	if su.OperatorVersion != nil {
+		setClauses = append(setClauses, fmt.Sprintf("operator_version = NULLIF($%d, '')", argIdx))
+		args = append(args, *su.OperatorVersion)
+		argIdx++
+	}
+	if su.ExternalHost != nil {
+		setClauses = append(setClauses, fmt.Sprintf("external_host = NULLIF($%d, '')", argIdx))
+		args = append(args, *su.ExternalHost)
+		argIdx++
+	}
+	if su.Conditions != nil {
+		setClauses = append(setClauses, fmt.Sprintf("conditions = $%d", argIdx))
+		args = append(args, su.Conditions)
+		argIdx++
+	}
+	if su.UpdatedBy != "" {
+		setClauses = append(setClauses, fmt.Sprintf("updated_by = $%d", argIdx))
+		args = append(args, su.UpdatedBy)
+		argIdx++
+	}
 
 	setClauses = append(setClauses, "updated_at = NOW()")
 
-	args = append(args, id)
+	args = append(args, id, su.IfStatus)
 
+	// $1 is always the new status.
 	query := fmt.Sprintf(`
+		WITH prev AS (
+			SELECT status FROM databases
+			WHERE id = $%[2]d AND deleted_at IS NULL AND ($%[3]d = '' OR status = $%[3]d)
+			FOR UPDATE
+		), hist AS (
+			INSERT INTO database_status_history (database_id, from_status, to_status)
+			SELECT $%[2]d, prev.status, $1 FROM prev WHERE prev.status <> $1
+		)
 		UPDATE databases d
-		SET %s
-		WHERE d.id = $%d AND d.deleted_at IS NULL
+		SET %[1]s
+		WHERE d.id = $%[2]d AND d.deleted_at IS NULL AND ($%[3]d = '' OR d.status = $%[3]d)
 		RETURNING d.id, d.name, d.owner_team_id,
-		          (SELECT t.name FROM teams t WHERE t.id = d.owner_team_id),
-		          d.tier_id, COALESCE((SELECT tr.name FROM tiers tr WHERE tr.id = d.tier_id), ''),
-		          d.purpose, d.namespace, d.cluster_name, d.pooler_name,
-		          d.status, d.host, d.port, d.secret_name,
+		          d.owner_team_name, d.tier_id, d.tier_name,
+		          d.purpose, d.data_classification, d.namespace, d.environment, d.promoted_from_id, d.source_database_id,
+		          d.cluster_name, d.pooler_name,
+		          d.status, d.status_reason, d.status_message, d.host, d.port, d.secret_name,
+		          d.generation, d.observed_generation,
+		          d.ack_by, d.ack_comment, d.acked_at, d.ack_until,
+		          d.instances_total, d.instances_ready, d.current_primary, d.replication_lag_ms,
+		          d.operator_version, d.conditions, d.owner_team_labels, d.owner_team_annotations,
+		          d.reconciliation_paused_by, d.reconciliation_paused_until, d.placement, d.images,
+		          d.exposure, d.exposure_allowed_ranges, d.external_host, d.dns_name, d.query_insights, d.archive_url, d.legal_hold, d.rename, d.credentials_rotated_at,
+		          d.created_by, d.updated_by,
 		          d.created_at, d.updated_at, d.deleted_at`,
-		strings.Join(setClauses, ", "), argIdx)
+		strings.Join(setClauses, ", "), argIdx, argIdx+1)
 
 	return r.scanOne(ctx, query, args...)
 }
@@ -297,6 +522,12 @@ func (r *PostgresRepository) UpdateStatus(ctx context.Context, id uuid.UUID, su
 // SoftDelete marks a database as deleted by setting deleted_at and status to 'deleted'.
 func (r *PostgresRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	query := `
+		WITH prev AS (
+			SELECT status FROM databases WHERE id = $2 AND deleted_at IS NULL FOR UPDATE
+		), hist AS (
+			INSERT INTO database_status_history (database_id, from_status, to_status, changed_at)
+			SELECT $2, prev.status, 'deleted', $1 FROM prev WHERE prev.status <> 'deleted'
+		)
 		UPDATE databases
 		SET deleted_at = $1, status = 'deleted', updated_at = $1
 		WHERE id = $2 AND deleted_at IS NULL`
@@ -314,21 +545,260 @@ func (r *PostgresRepository) SoftDelete(ctx context.Context, id uuid.UUID) error
 	return nil
 }
 
+// NewStatsReader creates a StatsReader backed by the given connection pool.
+func NewStatsReader(pool *pgxpool.Pool) StatsReader {
+	return &PostgresRepository{pool: pool}
+}
+
+// Stats returns counts of non-deleted databases by status, tier and team, and
+// the p50/p95 time from creation to first becoming ready. Durations include
+// databases deleted since, so the percentiles reflect provisioning history.
+func (r *PostgresRepository) Stats(ctx context.Context, filter StatsFilter) (*Stats, error) {
+	stats := &Stats{
+		ByStatus: map[string]int{},
+		ByTier:   map[string]int{},
+		ByTeam:   map[string]int{},
+	}
+
+	var args []any
+	teamCond := ""
+	if filter.OwnerTeamID != nil {
+		teamCond = "AND d.owner_team_id = $1"
+		args = append(args, *filter.OwnerTeamID)
+	}
+
+	countQuery := fmt.Sprintf(`
+		SELECT d.status, d.tier_name, d.owner_team_name, COUNT(*)
+		FROM databases d
+		WHERE d.deleted_at IS NULL %s
+		GROUP BY d.status, d.tier_name, d.owner_team_name`, teamCond)
+
+	rows, err := r.pool.Query(ctx, countQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("counting databases: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status, tierName, teamName string
+		var n int
+		if err := rows.Scan(&status, &tierName, &teamName, &n); err != nil {
+			return nil, fmt.Errorf("scanning database counts: %w", err)
+		}
+		stats.Total += n
+		stats.ByStatus[status] += n
+		if tierName != "" {
+			stats.ByTier[tierName] += n
+		}
+		stats.ByTeam[teamName] += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating database counts: %w", err)
+	}
+
+	durationQuery := fmt.Sprintf(`
+		SELECT COUNT(*),
+		       COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY secs), 0),
+		       COALESCE(percentile_cont(0.95) WITHIN GROUP (ORDER BY secs), 0)
+		FROM (
+			SELECT EXTRACT(EPOCH FROM (MIN(h.changed_at) - d.created_at))::float8 AS secs
+			FROM databases d
+			JOIN database_status_history h ON h.database_id = d.id AND h.to_status = 'ready'
+			WHERE TRUE %s
+			GROUP BY d.id, d.created_at
+		) durations`, teamCond)
+
+	var p50, p95 float64
+	if err := r.pool.QueryRow(ctx, durationQuery, args...).Scan(&stats.Provisioning.Count, &p50, &p95); err != nil {
+		return nil, fmt.Errorf("computing provisioning durations: %w", err)
+	}
+	stats.Provisioning.P50 = time.Duration(p50 * float64(time.Second))
+	stats.Provisioning.P95 = time.Duration(p95 * float64(time.Second))
+
+	return stats, nil
+}
+
+// ProvisioningDurations returns the time from creation to first becoming ready
+// for every database that has reached ready (including databases deleted
+// since), most recently ready first.
+func (r *PostgresRepository) ProvisioningDurations(ctx context.Context, filter ProvisioningDurationFilter) (*ProvisioningDurationResult, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	var args []any
+	argIdx := 1
+	teamCond := ""
+	if filter.OwnerTeamID != nil {
+		teamCond = fmt.Sprintf("WHERE d.owner_team_id = $%d", argIdx)
+		args = append(args, *filter.OwnerTeamID)
+		argIdx++
+	}
+
+	from := fmt.Sprintf(`
+		FROM (
+			SELECT database_id, MIN(changed_at) AS ready_at
+			FROM database_status_history
+			WHERE to_status = 'ready'
+			GROUP BY database_id
+		) r
+		JOIN databases d ON d.id = r.database_id
+		%s`, teamCond)
+
+	var total int
+	if err := r.pool.QueryRow(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("counting provisioning durations: %w", err)
+	}
+
+	dataQuery := fmt.Sprintf(`
+		SELECT d.id, d.name, d.owner_team_name, d.tier_name, d.created_at, r.ready_at
+		%s
+		ORDER BY r.ready_at DESC
+		LIMIT $%d OFFSET $%d`, from, argIdx, argIdx+1)
+	args = append(args, filter.Limit, (filter.Page-1)*filter.Limit)
+
+	rows, err := r.pool.Query(ctx, dataQuery, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing provisioning durations: %w", err)
+	}
+	defer rows.Close()
+
+	items := []ProvisioningDuration{}
+	for rows.Next() {
+		var pd ProvisioningDuration
+		if err := rows.Scan(&pd.DatabaseID, &pd.Name, &pd.OwnerTeamName, &pd.TierName, &pd.CreatedAt, &pd.ReadyAt); err != nil {
+			return nil, fmt.Errorf("scanning provisioning duration row: %w", err)
+		}
+		pd.Duration = pd.ReadyAt.Sub(pd.CreatedAt)
+		items = append(items, pd)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating provisioning duration rows: %w", err)
+	}
+
+	return &ProvisioningDurationResult{
+		Items: items,
+		Total: total,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// StatusHistory returns the status changes of a database, oldest first.
+func (r *PostgresRepository) StatusHistory(ctx context.Context, databaseID uuid.UUID) ([]StatusChange, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT database_id, COALESCE(from_status, ''), to_status, changed_at
+		FROM database_status_history
+		WHERE database_id = $1
+		ORDER BY changed_at, id`, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("listing status history: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []StatusChange{}
+	for rows.Next() {
+		var c StatusChange
+		if err := rows.Scan(&c.DatabaseID, &c.FromStatus, &c.ToStatus, &c.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scanning status history row: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating status history rows: %w", err)
+	}
+	return changes, nil
+}
+
 // scanOne scans a single Database row from a query. Returns ErrNotFound if no rows.
 func (r *PostgresRepository) scanOne(ctx context.Context, query string, args ...any) (*Database, error) {
+	db, err := scanDatabase(r.pool.QueryRow(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("scanning database row: %w", err)
+	}
+	return db, nil
+}
+
+// scanDatabase scans a row selected with the column list used throughout
+// this file.
+func scanDatabase(row pgx.Row) (*Database, error) {
 	var db Database
-	err := r.pool.QueryRow(ctx, query, args...).Scan(
+	var ackBy, ackComment *string
+	var ackedAt, ackUntil *time.Time
+	var instancesTotal, instancesReady *int
+	var currentPrimary *string
+	var replicationLagMs *int64
+	var operatorVersion *string
+	var pausedBy *string
+	var pausedUntil *time.Time
+	err := row.Scan(
 		&db.ID, &db.Name, &db.OwnerTeamID, &db.OwnerTeamName, &db.TierID, &db.TierName,
-		&db.Purpose, &db.Namespace,
-		&db.ClusterName, &db.PoolerName, &db.Status,
+		&db.Purpose, &db.DataClassification, &db.Namespace, &db.Environment, &db.PromotedFromID, &db.SourceDatabaseID,
+		&db.ClusterName, &db.PoolerName, &db.Status, &db.StatusReason, &db.StatusMessage,
 		&db.Host, &db.Port, &db.SecretName,
+		&db.Generation, &db.ObservedGeneration,
+		&ackBy, &ackComment, &ackedAt, &ackUntil,
+		&instancesTotal, &instancesReady, &currentPrimary, &replicationLagMs,
+		&operatorVersion, &db.Conditions, &db.OwnerTeamLabels, &db.OwnerTeamAnnotations,
+		&pausedBy, &pausedUntil, &db.Placement, &db.Images,
+		&db.Exposure.Type, &db.Exposure.AllowedSourceRanges, &db.ExternalHost, &db.DNSName, &db.QueryInsights, &db.ArchiveURL, &db.LegalHold, &db.Rename, &db.CredentialsRotatedAt,
+		&db.CreatedBy, &db.UpdatedBy,
 		&db.CreatedAt, &db.UpdatedAt, &db.DeletedAt,
 	)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, ErrNotFound
+		return nil, err
+	}
+	if ackBy != nil && ackedAt != nil {
+		db.Ack = &Acknowledgement{By: *ackBy, At: *ackedAt, Until: ackUntil}
+		if ackComment != nil {
+			db.Ack.Comment = *ackComment
 		}
-		return nil, fmt.Errorf("scanning database row: %w", err)
+	}
+	if instancesTotal != nil {
+		db.Instances = &Instances{Total: *instancesTotal}
+		if instancesReady != nil {
+			db.Instances.Ready = *instancesReady
+		}
+		if currentPrimary != nil {
+			db.Instances.Primary = *currentPrimary
+		}
+		if replicationLagMs != nil {
+			lag := time.Duration(*replicationLagMs) * time.Millisecond
+			db.Instances.ReplicationLag = &lag
+		}
+	}
+	if operatorVersion != nil {
+		db.OperatorVersion = *operatorVersion
+	}
+	if pausedBy != nil && pausedUntil != nil {
+		db.ReconciliationPause = &ReconciliationPause{By: *pausedBy, Until: *pausedUntil}
 	}
 	return &db, nil
 }
+
+// imagePins returns pins, or an empty list when it is nil, so the NOT NULL
+// images column gets '[]' rather than null.
+func imagePins(pins []ImagePin) []ImagePin {
+	if pins == nil {
+		return []ImagePin{}
+	}
+	return pins
+}
+
+// exposureRanges returns ranges, or an empty list when it is nil, so the NOT
+// NULL exposure_allowed_ranges column gets '{}' rather than null.
+func exposureRanges(ranges []string) []string {
+	if ranges == nil {
+		return []string{}
+	}
+	return ranges
+}
@@ -0,0 +1,77 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ResizeEvent records one storage expansion of a database.
+type ResizeEvent struct {
+	ID         int64
+	DatabaseID uuid.UUID
+	FromBytes  int64
+	ToBytes    int64
+	UsedBytes  int64 // usage that triggered the resize
+	CreatedAt  time.Time
+}
+
+// ResizeEventRepository stores storage resize events.
+type ResizeEventRepository interface {
+	Record(ctx context.Context, e *ResizeEvent) error
+	// ListByDatabase returns a database's resize events, oldest first.
+	ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]ResizeEvent, error)
+}
+
+// PostgresResizeEventRepository implements ResizeEventRepository using PostgreSQL.
+type PostgresResizeEventRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewResizeEventRepository creates a new PostgreSQL-backed ResizeEventRepository.
+func NewResizeEventRepository(pool *pgxpool.Pool) ResizeEventRepository {
+	return &PostgresResizeEventRepository{pool: pool}
+}
+
+// Record inserts a resize event and sets its ID and CreatedAt.
+func (r *PostgresResizeEventRepository) Record(ctx context.Context, e *ResizeEvent) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO database_resize_events (database_id, from_bytes, to_bytes, used_bytes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at`,
+		e.DatabaseID, e.FromBytes, e.ToBytes, e.UsedBytes,
+	).Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting resize event: %w", err)
+	}
+	return nil
+}
+
+// ListByDatabase returns a database's resize events, oldest first.
+func (r *PostgresResizeEventRepository) ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]ResizeEvent, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, database_id, from_bytes, to_bytes, used_bytes, created_at
+		FROM database_resize_events
+		WHERE database_id = $1
+		ORDER BY created_at, id`, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("querying resize events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []ResizeEvent{}
+	for rows.Next() {
+		var e ResizeEvent
+		if err := rows.Scan(&e.ID, &e.DatabaseID, &e.FromBytes, &e.ToBytes, &e.UsedBytes, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning resize event: %w", err)
+		}
+		events = append(events, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating resize events: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,257 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// ErrSpecNotFound is returned when no spec was recorded for a database.
+var ErrSpecNotFound = errors.New("spec not found")
+
+// Spec is the resolved specification a database's resources were applied
+// from: the settings of its tier and the blueprint the tier pointed at,
+// copied at the time of the apply so later changes to either show up as a
+// difference.
+type Spec struct {
+	DatabaseID          uuid.UUID
+	TierID              uuid.UUID
+	TierName            string
+	DestructionStrategy string
+	BackupEnabled       bool
+	StorageAutoscaling  tier.StorageAutoscaling
+	Topology            tier.Topology
+	Disruption          tier.Disruption
+	BlueprintID         uuid.UUID
+	BlueprintName       string
+	Provider            string
+	Manifests           string
+	AppliedAt           time.Time
+}
+
+// NewSpec resolves the spec of database id on tier t with blueprint bp.
+func NewSpec(id uuid.UUID, t *tier.Tier, bp *blueprint.Blueprint) *Spec {
+	return &Spec{
+		DatabaseID:          id,
+		TierID:              t.ID,
+		TierName:            t.Name,
+		DestructionStrategy: t.DestructionStrategy,
+		BackupEnabled:       t.BackupEnabled,
+		StorageAutoscaling:  t.StorageAutoscaling,
+		Topology:            t.Topology,
+		Disruption:          t.Disruption,
+		BlueprintID:         bp.ID,
+		BlueprintName:       bp.Name,
+		Provider:            bp.Provider,
+		Manifests:           bp.Manifests,
+	}
+}
+
+// SpecChange is a field whose value differs between two specs.
+type SpecChange struct {
+	Field   string // e.g. "tier.backupEnabled"
+	Applied any
+	Current any
+}
+
+// Diff lists the fields of current that differ from s, in a fixed order.
+// Manifests are compared as a whole; ManifestsDiff shows how they differ.
+func (s *Spec) Diff(current *Spec) []SpecChange {
+	changes := []SpecChange{}
+	add := func(field string, applied, now any) {
+		if applied != now {
+			changes = append(changes, SpecChange{Field: field, Applied: applied, Current: now})
+		}
+	}
+	add("tier.name", s.TierName, current.TierName)
+	add("tier.destructionStrategy", s.DestructionStrategy, current.DestructionStrategy)
+	add("tier.backupEnabled", s.BackupEnabled, current.BackupEnabled)
+	add("tier.storageAutoscaling.enabled", s.StorageAutoscaling.Enabled, current.StorageAutoscaling.Enabled)
+	add("tier.storageAutoscaling.thresholdPercent", s.StorageAutoscaling.ThresholdPercent, current.StorageAutoscaling.ThresholdPercent)
+	add("tier.storageAutoscaling.incrementPercent", s.StorageAutoscaling.IncrementPercent, current.StorageAutoscaling.IncrementPercent)
+	add("tier.storageAutoscaling.maxSize", s.StorageAutoscaling.MaxSize, current.StorageAutoscaling.MaxSize)
+	add("tier.topology.zoneSpread", s.Topology.ZoneSpread, current.Topology.ZoneSpread)
+	add("tier.topology.nodeSelector", formatSelector(s.Topology.NodeSelector), formatSelector(current.Topology.NodeSelector))
+	add("tier.disruption.minAvailable", s.Disruption.MinAvailable, current.Disruption.MinAvailable)
+	add("tier.disruption.priorityClassName", s.Disruption.PriorityClassName, current.Disruption.PriorityClassName)
+	add("blueprint.name", s.BlueprintName, current.BlueprintName)
+	add("blueprint.provider", s.Provider, current.Provider)
+	if s.Manifests != current.Manifests {
+		changes = append(changes, SpecChange{Field: "blueprint.manifests"})
+	}
+	return changes
+}
+
+// formatSelector formats a node selector as sorted key=value pairs, so
+// selectors compare as values.
+func formatSelector(selector map[string]string) string {
+	pairs := make([]string, 0, len(selector))
+	for k, v := range selector {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// ManifestsDiff returns a line diff from the manifests of s to those of
+// current, each line prefixed with "-" (removed), "+" (added) or " "
+// (unchanged), or "" if they are the same.
+func (s *Spec) ManifestsDiff(current *Spec) string {
+	if s.Manifests == current.Manifests {
+		return ""
+	}
+	a := strings.Split(strings.TrimSuffix(s.Manifests, "\n"), "\n")
+	b := strings.Split(strings.TrimSuffix(current.Manifests, "\n"), "\n")
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("--- applied\n+++ current\n")
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			out.WriteString(" " + a[i] + "\n")
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			out.WriteString("-" + a[i] + "\n")
+			i++
+		default:
+			out.WriteString("+" + b[j] + "\n")
+			j++
+		}
+	}
+	return out.String()
+}
+
+// SpecRepository stores the spec each database was last applied with.
+type SpecRepository interface {
+	// Put records spec as the one its database was last applied with,
+	// replacing any earlier one, and sets AppliedAt.
+	Put(ctx context.Context, spec *Spec) error
+	Get(ctx context.Context, databaseID uuid.UUID) (*Spec, error)
+}
+
+// RecordSpec records that db was just applied from tier t and blueprint bp.
+// A failure is only logged: the apply itself succeeded, and a stale spec
+// only makes the spec diff show changes that are already applied. specs may
+// be nil.
+func RecordSpec(ctx context.Context, specs SpecRepository, db *Database, t *tier.Tier, bp *blueprint.Blueprint) {
+	if specs == nil {
+		return
+	}
+	if err := specs.Put(ctx, NewSpec(db.ID, t, bp)); err != nil {
+		slog.Error("failed to record database spec", "database", db.Name, "error", err)
+	}
+}
+
+// PostgresSpecRepository implements SpecRepository using PostgreSQL.
+type PostgresSpecRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSpecRepository creates a new PostgreSQL-backed SpecRepository.
+func NewSpecRepository(pool *pgxpool.Pool) SpecRepository {
+	return &PostgresSpecRepository{pool: pool}
+}
+
+// Put upserts the spec of its database.
+func (r *PostgresSpecRepository) Put(ctx context.Context, s *Spec) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO database_specs (database_id, tier_id, tier_name, destruction_strategy, backup_enabled,
+		                            storage_autoscale_enabled, storage_autoscale_threshold, storage_autoscale_increment,
+		                            storage_autoscale_max_size, zone_spread, node_selector, pdb_min_available,
+		                            priority_class_name, blueprint_id, blueprint_name, provider, manifests)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+		ON CONFLICT (database_id) DO UPDATE SET
+			tier_id = EXCLUDED.tier_id,
+			tier_name = EXCLUDED.tier_name,
+			destruction_strategy = EXCLUDED.destruction_strategy,
+			backup_enabled = EXCLUDED.backup_enabled,
+			storage_autoscale_enabled = EXCLUDED.storage_autoscale_enabled,
+			storage_autoscale_threshold = EXCLUDED.storage_autoscale_threshold,
+			storage_autoscale_increment = EXCLUDED.storage_autoscale_increment,
+			storage_autoscale_max_size = EXCLUDED.storage_autoscale_max_size,
+			zone_spread = EXCLUDED.zone_spread,
+			node_selector = EXCLUDED.node_selector,
+			pdb_min_available = EXCLUDED.pdb_min_available,
+			priority_class_name = EXCLUDED.priority_class_name,
+			blueprint_id = EXCLUDED.blueprint_id,
+			blueprint_name = EXCLUDED.blueprint_name,
+			provider = EXCLUDED.provider,
+			manifests = EXCLUDED.manifests,
+			applied_at = NOW()
+		RETURNING applied_at`,
+		s.DatabaseID, s.TierID, s.TierName, s.DestructionStrategy, s.BackupEnabled,
+		s.StorageAutoscaling.Enabled, s.StorageAutoscaling.ThresholdPercent, s.StorageAutoscaling.IncrementPercent,
+		s.StorageAutoscaling.MaxSize, s.Topology.ZoneSpread, nodeSelector(s.Topology.NodeSelector),
+		s.Disruption.MinAvailable, s.Disruption.PriorityClassName, s.BlueprintID, s.BlueprintName, s.Provider, s.Manifests,
+	).Scan(&s.AppliedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrNotFound
+		}
+		return fmt.Errorf("upserting spec: %w", err)
+	}
+	return nil
+}
+
+// Get returns the spec a database was last applied with.
+func (r *PostgresSpecRepository) Get(ctx context.Context, databaseID uuid.UUID) (*Spec, error) {
+	var s Spec
+	err := r.pool.QueryRow(ctx, `
+		SELECT database_id, tier_id, tier_name, destruction_strategy, backup_enabled,
+		       storage_autoscale_enabled, storage_autoscale_threshold, storage_autoscale_increment,
+		       storage_autoscale_max_size, zone_spread, node_selector, pdb_min_available, priority_class_name,
+		       blueprint_id, blueprint_name, provider, manifests, applied_at
+		FROM database_specs
+		WHERE database_id = $1`, databaseID,
+	).Scan(&s.DatabaseID, &s.TierID, &s.TierName, &s.DestructionStrategy, &s.BackupEnabled,
+		&s.StorageAutoscaling.Enabled, &s.StorageAutoscaling.ThresholdPercent, &s.StorageAutoscaling.IncrementPercent,
+		&s.StorageAutoscaling.MaxSize, &s.Topology.ZoneSpread, &s.Topology.NodeSelector,
+		&s.Disruption.MinAvailable, &s.Disruption.PriorityClassName, &s.BlueprintID, &s.BlueprintName, &s.Provider, &s.Manifests, &s.AppliedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrSpecNotFound
+		}
+		return nil, fmt.Errorf("querying spec: %w", err)
+	}
+	return &s, nil
+}
+
+// nodeSelector returns selector, or an empty map when it is nil, so the NOT
+// NULL node_selector column gets '{}' rather than null.
+func nodeSelector(selector map[string]string) map[string]string {
+	if selector == nil {
+		return map[string]string{}
+	}
+	return selector
+}
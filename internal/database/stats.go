@@ -0,0 +1,107 @@
+package database
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// StatusChange is one row of a database's status history.
+type StatusChange struct {
+	DatabaseID uuid.UUID
+	FromStatus string // empty for the initial transition on create
+	ToStatus   string
+	ChangedAt  time.Time
+}
+
+// StatsFilter scopes aggregate statistics.
+type StatsFilter struct {
+	OwnerTeamID *uuid.UUID
+}
+
+// Stats holds aggregate counts over non-deleted databases and provisioning
+// durations computed from status history.
+type Stats struct {
+	Total        int
+	ByStatus     map[string]int
+	ByTier       map[string]int // databases without a tier are not counted
+	ByTeam       map[string]int
+	Provisioning DurationStats
+}
+
+// DurationStats summarizes a set of durations. P50 and P95 are zero when
+// Count is zero.
+type DurationStats struct {
+	Count int
+	P50   time.Duration
+	P95   time.Duration
+}
+
+// ProvisioningDuration is the time a database took from creation to first
+// becoming ready.
+type ProvisioningDuration struct {
+	DatabaseID    uuid.UUID
+	Name          string
+	OwnerTeamName string
+	TierName      string
+	CreatedAt     time.Time
+	ReadyAt       time.Time
+	Duration      time.Duration
+}
+
+// ProvisioningDurationFilter scopes and paginates provisioning durations.
+type ProvisioningDurationFilter struct {
+	OwnerTeamID *uuid.UUID
+	Page        int // default 1
+	Limit       int // default 20
+}
+
+// ProvisioningDurationResult holds a page of provisioning durations, most
+// recently ready first.
+type ProvisioningDurationResult struct {
+	Items []ProvisioningDuration
+	Total int
+	Page  int
+	Limit int
+}
+
+// StatsReader computes aggregate database statistics and reads the status
+// history they are based on.
+type StatsReader interface {
+	Stats(ctx context.Context, filter StatsFilter) (*Stats, error)
+	ProvisioningDurations(ctx context.Context, filter ProvisioningDurationFilter) (*ProvisioningDurationResult, error)
+	// StatusHistory returns the status changes of a database, deleted or
+	// not, oldest first.
+	StatusHistory(ctx context.Context, databaseID uuid.UUID) ([]StatusChange, error)
+}
+
+// NewDurationStats summarizes durations using linear interpolation between
+// closest ranks, matching PostgreSQL's percentile_cont.
+func NewDurationStats(durations []time.Duration) DurationStats {
+	if len(durations) == 0 {
+		return DurationStats{}
+	}
+	sorted := make([]time.Duration, len(durations))
+	copy(sorted, durations)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	return DurationStats{
+		Count: len(sorted),
+		P50:   percentile(sorted, 0.50),
+		P95:   percentile(sorted, 0.95),
+	}
+}
+
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := p * float64(len(sorted)-1)
+	lo := int(math.Floor(rank))
+	hi := int(math.Ceil(rank))
+	if lo == hi {
+		return sorted[lo]
+	}
+	frac := rank - float64(lo)
+	return sorted[lo] + time.Duration(math.Round(frac*float64(sorted[hi]-sorted[lo])))
+}
@@ -0,0 +1,148 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// StatusWrite is one status update of a batch. Expected, when set, is the
+// status the database was read with: the update is skipped if the status
+// changed since, so a write decided on a stale read cannot overwrite a
+// change made in between, such as a deletion marking it deprovisioning.
+type StatusWrite struct {
+	ID       uuid.UUID
+	Expected string
+	Update   StatusUpdate
+}
+
+// StatusBatchUpdater is implemented by repositories that can apply several
+// status updates at once, such as the reconciler's writes of one pass.
+type StatusBatchUpdater interface {
+	// UpdateStatuses applies writes, whose IDs must be distinct, and
+	// returns the IDs of the databases it updated. Writes to databases that
+	// no longer exist, or whose status is no longer the expected one, are
+	// skipped.
+	UpdateStatuses(ctx context.Context, writes []StatusWrite) ([]uuid.UUID, error)
+}
+
+// UpdateStatuses applies writes with repo's UpdateStatuses when it is a
+// StatusBatchUpdater, and one UpdateStatus call at a time otherwise,
+// stopping at the first error. It returns the IDs of the databases it
+// updated.
+func UpdateStatuses(ctx context.Context, repo Repository, writes []StatusWrite) ([]uuid.UUID, error) {
+	if b, ok := repo.(StatusBatchUpdater); ok {
+		return b.UpdateStatuses(ctx, writes)
+	}
+	var updated []uuid.UUID
+	for _, w := range writes {
+		su := w.Update
+		su.IfStatus = w.Expected
+		_, err := repo.UpdateStatus(ctx, w.ID, su)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return updated, err
+		}
+		updated = append(updated, w.ID)
+	}
+	return updated, nil
+}
+
+// UpdateStatuses applies writes in a single statement, so either all or
+// none are applied. Fields behave as in UpdateStatus.
+func (r *PostgresRepository) UpdateStatuses(ctx context.Context, writes []StatusWrite) ([]uuid.UUID, error) {
+	if len(writes) == 0 {
+		return nil, nil
+	}
+
+	// Optional fields are passed as NULL, and the optional fields that may
+	// be set to NULL come with a flag telling whether to set them.
+	const columns = 21
+	rows := make([]string, len(writes))
+	args := make([]any, 0, len(writes)*columns)
+	for i, c := range writes {
+		su := c.Update
+		var (
+			instancesTotal, instancesReady *int
+			primary                        *string
+			lagMs                          *int64
+		)
+		if su.Instances != nil {
+			instancesTotal, instancesReady, primary = &su.Instances.Total, &su.Instances.Ready, &su.Instances.Primary
+			if su.Instances.ReplicationLag != nil {
+				ms := su.Instances.ReplicationLag.Milliseconds()
+				lagMs = &ms
+			}
+		}
+
+		n := i * columns
+		rows[i] = fmt.Sprintf("($%d::uuid, $%d::text, $%d::text, $%d::text, $%d::text, $%d::integer, $%d::text, $%d::bigint, "+
+			"$%d::boolean, $%d::integer, $%d::integer, $%d::text, $%d::bigint, $%d::boolean, $%d::text, $%d::boolean, $%d::text, $%d::boolean, $%d::jsonb, $%d::text, $%d::text)",
+			n+1, n+2, n+3, n+4, n+5, n+6, n+7, n+8, n+9, n+10, n+11, n+12, n+13, n+14, n+15, n+16, n+17, n+18, n+19, n+20, n+21)
+		args = append(args,
+			c.ID, c.Expected, su.Status, su.Reason, su.Message, su.Host, su.Port, su.SecretName, su.ObservedGeneration,
+			su.Instances != nil, instancesTotal, instancesReady, primary, lagMs,
+			su.OperatorVersion != nil, su.OperatorVersion, su.ExternalHost != nil, su.ExternalHost,
+			su.Conditions != nil, su.Conditions, su.UpdatedBy)
+	}
+
+	// As in UpdateStatus, the right-hand sides see the rows before the
+	// update.
+	query := fmt.Sprintf(`
+		WITH v (id, expected_status, status, reason, message, host, port, secret_name, observed_generation,
+		        set_instances, instances_total, instances_ready, current_primary, replication_lag_ms,
+		        set_operator_version, operator_version, set_external_host, external_host,
+		        set_conditions, conditions, updated_by) AS (
+			VALUES %s
+		), prev AS (
+			SELECT d.id, d.status FROM databases d JOIN v ON v.id = d.id
+			WHERE d.deleted_at IS NULL AND (v.expected_status = '' OR d.status = v.expected_status)
+			FOR UPDATE OF d
+		), hist AS (
+			INSERT INTO database_status_history (database_id, from_status, to_status)
+			SELECT prev.id, prev.status, v.status FROM prev JOIN v ON v.id = prev.id
+			WHERE prev.status <> v.status
+		)
+		UPDATE databases d
+		SET status = v.status,
+		    ack_by = CASE WHEN d.status = v.status THEN d.ack_by END,
+		    ack_comment = CASE WHEN d.status = v.status THEN d.ack_comment END,
+		    acked_at = CASE WHEN d.status = v.status THEN d.acked_at END,
+		    ack_until = CASE WHEN d.status = v.status THEN d.ack_until END,
+		    status_reason = NULLIF(v.reason, ''),
+		    status_message = NULLIF(v.message, ''),
+		    host = COALESCE(v.host, d.host),
+		    port = COALESCE(v.port, d.port),
+		    secret_name = COALESCE(v.secret_name, d.secret_name),
+		    observed_generation = COALESCE(v.observed_generation, d.observed_generation),
+		    instances_total = CASE WHEN v.set_instances THEN v.instances_total ELSE d.instances_total END,
+		    instances_ready = CASE WHEN v.set_instances THEN v.instances_ready ELSE d.instances_ready END,
+		    current_primary = CASE WHEN v.set_instances THEN NULLIF(v.current_primary, '') ELSE d.current_primary END,
+		    replication_lag_ms = CASE WHEN v.set_instances THEN v.replication_lag_ms ELSE d.replication_lag_ms END,
+		    operator_version = CASE WHEN v.set_operator_version THEN NULLIF(v.operator_version, '') ELSE d.operator_version END,
+		    external_host = CASE WHEN v.set_external_host THEN NULLIF(v.external_host, '') ELSE d.external_host END,
+		    conditions = CASE WHEN v.set_conditions THEN v.conditions ELSE d.conditions END,
+		    updated_by = COALESCE(NULLIF(v.updated_by, ''), d.updated_by),
+		    updated_at = NOW()
+		FROM v
+		WHERE d.id = v.id AND d.deleted_at IS NULL
+		  AND (v.expected_status = '' OR d.status = v.expected_status)
+		RETURNING d.id`,
+		strings.Join(rows, ", "))
+
+	rs, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("updating database statuses: %w", err)
+	}
+	updated, err := pgx.CollectRows(rs, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("updating database statuses: %w", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,171 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// UsageSample is one compute usage observation of a database.
+type UsageSample struct {
+	DatabaseID         uuid.UUID
+	CPURequestMillis   int64
+	CPUUsedMillis      int64
+	MemoryRequestBytes int64
+	MemoryUsedBytes    int64
+	CollectedAt        time.Time
+}
+
+// UsageSampleRepository stores compute usage samples.
+type UsageSampleRepository interface {
+	Record(ctx context.Context, s *UsageSample) error
+	// ListByDatabase returns a database's samples collected at or after
+	// since, oldest first.
+	ListByDatabase(ctx context.Context, databaseID uuid.UUID, since time.Time) ([]UsageSample, error)
+	// DeleteBefore removes samples collected before the given time and
+	// returns how many were removed.
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// PostgresUsageSampleRepository implements UsageSampleRepository using PostgreSQL.
+type PostgresUsageSampleRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewUsageSampleRepository creates a new PostgreSQL-backed UsageSampleRepository.
+func NewUsageSampleRepository(pool *pgxpool.Pool) UsageSampleRepository {
+	return &PostgresUsageSampleRepository{pool: pool}
+}
+
+// Record inserts a sample and sets its CollectedAt.
+func (r *PostgresUsageSampleRepository) Record(ctx context.Context, s *UsageSample) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO database_usage_samples
+			(database_id, cpu_request_millis, cpu_used_millis, memory_request_bytes, memory_used_bytes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING collected_at`,
+		s.DatabaseID, s.CPURequestMillis, s.CPUUsedMillis, s.MemoryRequestBytes, s.MemoryUsedBytes,
+	).Scan(&s.CollectedAt)
+	if err != nil {
+		return fmt.Errorf("inserting usage sample: %w", err)
+	}
+	return nil
+}
+
+// ListByDatabase returns a database's samples collected at or after since,
+// oldest first.
+func (r *PostgresUsageSampleRepository) ListByDatabase(ctx context.Context, databaseID uuid.UUID, since time.Time) ([]UsageSample, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT database_id, cpu_request_millis, cpu_used_millis, memory_request_bytes, memory_used_bytes, collected_at
+		FROM database_usage_samples
+		WHERE database_id = $1 AND collected_at >= $2
+		ORDER BY collected_at, id`, databaseID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying usage samples: %w", err)
+	}
+	defer rows.Close()
+
+	samples := []UsageSample{}
+	for rows.Next() {
+		var s UsageSample
+		if err := rows.Scan(&s.DatabaseID, &s.CPURequestMillis, &s.CPUUsedMillis,
+			&s.MemoryRequestBytes, &s.MemoryUsedBytes, &s.CollectedAt); err != nil {
+			return nil, fmt.Errorf("scanning usage sample: %w", err)
+		}
+		samples = append(samples, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating usage samples: %w", err)
+	}
+	return samples, nil
+}
+
+// DeleteBefore removes samples collected before the given time.
+func (r *PostgresUsageSampleRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM database_usage_samples WHERE collected_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("deleting usage samples: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// Tier change statuses.
+const (
+	TierChangeApplied = "applied"
+	TierChangeFailed  = "failed"
+)
+
+// TierChange is an audit record of an attempt to move a database to another
+// tier. Tier names are recorded as they were at the time of the change.
+type TierChange struct {
+	ID         int64
+	DatabaseID uuid.UUID
+	FromTier   string
+	ToTier     string
+	Actor      string // e.g. "system:recommender"
+	Reason     string
+	Status     string // TierChangeApplied or TierChangeFailed
+	Error      string // set when Status is TierChangeFailed
+	CreatedAt  time.Time
+}
+
+// TierChangeRepository stores the tier change audit trail.
+type TierChangeRepository interface {
+	Record(ctx context.Context, c *TierChange) error
+	// ListByDatabase returns a database's tier changes, oldest first.
+	ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]TierChange, error)
+}
+
+// PostgresTierChangeRepository implements TierChangeRepository using PostgreSQL.
+type PostgresTierChangeRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewTierChangeRepository creates a new PostgreSQL-backed TierChangeRepository.
+func NewTierChangeRepository(pool *pgxpool.Pool) TierChangeRepository {
+	return &PostgresTierChangeRepository{pool: pool}
+}
+
+// Record inserts a tier change and sets its ID and CreatedAt.
+func (r *PostgresTierChangeRepository) Record(ctx context.Context, c *TierChange) error {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO database_tier_changes (database_id, from_tier, to_tier, actor, reason, status, error)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at`,
+		c.DatabaseID, c.FromTier, c.ToTier, c.Actor, c.Reason, c.Status, c.Error,
+	).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting tier change: %w", err)
+	}
+	return nil
+}
+
+// ListByDatabase returns a database's tier changes, oldest first.
+func (r *PostgresTierChangeRepository) ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]TierChange, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, database_id, from_tier, to_tier, actor, reason, status, error, created_at
+		FROM database_tier_changes
+		WHERE database_id = $1
+		ORDER BY created_at, id`, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("querying tier changes: %w", err)
+	}
+	defer rows.Close()
+
+	changes := []TierChange{}
+	for rows.Next() {
+		var c TierChange
+		if err := rows.Scan(&c.ID, &c.DatabaseID, &c.FromTier, &c.ToTier, &c.Actor,
+			&c.Reason, &c.Status, &c.Error, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning tier change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating tier changes: %w", err)
+	}
+	return changes, nil
+}
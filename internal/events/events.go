@@ -0,0 +1,256 @@
+// Package events publishes database lifecycle events to a message bus (NATS
+// or Kafka) so downstream automation such as CMDB sync or billing can react
+// to changes without polling the API.
+//
+// Events use the CloudEvents 1.0 JSON format. The envelope and the fields of
+// DatabaseData are a stable contract: fields may be added but are never
+// renamed or removed.
+package events
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/metrics"
+)
+
+// Event types.
+const (
+	TypeDatabaseCreated       = "daap.database.created"
+	TypeDatabaseUpdated       = "daap.database.updated"
+	TypeDatabaseStatusChanged = "daap.database.status_changed"
+	TypeDatabaseDeleted       = "daap.database.deleted"
+	TypeDatabaseNotification  = "daap.database.notification"
+)
+
+var (
+	eventsPublished = metrics.NewCounter(
+		"daap_events_published_total",
+		"Lifecycle events accepted by a message bus transport, counted once per transport.",
+	)
+	publishFailures = metrics.NewCounter(
+		"daap_events_publish_failures_total",
+		"Failed attempts to publish a batch of lifecycle events; the batch is retried.",
+	)
+	eventsDropped = metrics.NewCounter(
+		"daap_events_dropped_total",
+		"Lifecycle events dropped because a transport queue was full or the bus shut down.",
+	)
+)
+
+// Event is a CloudEvents 1.0 envelope.
+type Event struct {
+	SpecVersion     string       `json:"specversion"`
+	ID              string       `json:"id"`
+	Source          string       `json:"source"`
+	Type            string       `json:"type"`
+	Subject         string       `json:"subject,omitempty"`
+	Time            time.Time    `json:"time"`
+	DataContentType string       `json:"datacontenttype"`
+	Data            DatabaseData `json:"data"`
+}
+
+// DatabaseData describes the database an event is about.
+type DatabaseData struct {
+	ID             uuid.UUID `json:"id"`
+	Name           string    `json:"name"`
+	OwnerTeam      string    `json:"ownerTeam"`
+	OwnerTeamID    uuid.UUID `json:"ownerTeamId"`
+	Tier           string    `json:"tier,omitempty"`
+	Environment    string    `json:"environment,omitempty"`
+	Namespace      string    `json:"namespace,omitempty"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previousStatus,omitempty"` // status_changed only
+	Reason         string    `json:"reason,omitempty"`
+	Notification   string    `json:"notification,omitempty"` // notification only, e.g. ProvisioningTimeout
+	Message        string    `json:"message,omitempty"`      // notification text, or detail of the status
+
+	DataClassification string `json:"dataClassification,omitempty"`
+}
+
+// Publisher accepts events for publication.
+type Publisher interface {
+	Publish(e Event)
+}
+
+// Transport sends batches of events to a message bus. Send must return nil
+// only once the bus has accepted every event in the batch.
+type Transport interface {
+	Name() string
+	Send(ctx context.Context, events []Event) error
+}
+
+// Option configures a Bus.
+type Option func(*Bus)
+
+// WithQueueSize sets the number of events buffered per transport (default 10000).
+func WithQueueSize(n int) Option {
+	return func(b *Bus) { b.queueSize = n }
+}
+
+// WithRetryBackoff sets the delay before the first retry of a failed batch
+// and the cap it doubles up to (default 1s and 30s).
+func WithRetryBackoff(initial, max time.Duration) Option {
+	return func(b *Bus) { b.minBackoff, b.maxBackoff = initial, max }
+}
+
+// Bus publishes events to transports asynchronously, so a slow or
+// unavailable bus never delays API requests or the reconciler. Each
+// transport has its own queue; failed batches are retried until accepted,
+// and events that arrive while a queue is full are dropped and counted.
+type Bus struct {
+	queueSize  int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	queues []*queue
+
+	mu     sync.RWMutex // guards closed against sends on closed channels
+	closed bool
+
+	abort context.Context // cancelled when Close gives up on draining
+	stop  context.CancelFunc
+	wg    sync.WaitGroup
+}
+
+type queue struct {
+	transport Transport
+	ch        chan Event
+}
+
+// batchSize is the maximum number of events per Send.
+const batchSize = 100
+
+// NewBus creates a Bus and starts one delivery goroutine per transport.
+func NewBus(transports []Transport, opts ...Option) *Bus {
+	b := &Bus{queueSize: 10000, minBackoff: time.Second, maxBackoff: 30 * time.Second}
+	for _, opt := range opts {
+		opt(b)
+	}
+	b.abort, b.stop = context.WithCancel(context.Background())
+
+	for _, t := range transports {
+		q := &queue{transport: t, ch: make(chan Event, b.queueSize)}
+		b.queues = append(b.queues, q)
+		b.wg.Add(1)
+		go b.run(q)
+	}
+	return b
+}
+
+// Publish queues e for every transport, filling in the envelope attributes
+// that are unset. It never blocks.
+func (b *Bus) Publish(e Event) {
+	if e.SpecVersion == "" {
+		e.SpecVersion = "1.0"
+	}
+	if e.ID == "" {
+		e.ID = uuid.NewString()
+	}
+	if e.Source == "" {
+		e.Source = "daap"
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	if e.DataContentType == "" {
+		e.DataContentType = "application/json"
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		eventsDropped.Add(uint64(len(b.queues)))
+		return
+	}
+	for _, q := range b.queues {
+		select {
+		case q.ch <- e:
+		default:
+			eventsDropped.Inc()
+			slog.Warn("event queue full, dropping event", "transport", q.transport.Name(), "type", e.Type, "subject", e.Subject)
+		}
+	}
+}
+
+// Close stops accepting events and waits for the queued ones to be
+// published. When ctx ends first, remaining events are dropped.
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		for _, q := range b.queues {
+			close(q.ch)
+		}
+	}
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		b.stop()
+		return nil
+	case <-ctx.Done():
+		b.stop()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (b *Bus) run(q *queue) {
+	defer b.wg.Done()
+	for e := range q.ch {
+		batch := []Event{e}
+	fill:
+		for len(batch) < batchSize {
+			select {
+			case next, ok := <-q.ch:
+				if !ok {
+					break fill
+				}
+				batch = append(batch, next)
+			default:
+				break fill
+			}
+		}
+
+		if !b.send(q.transport, batch) {
+			dropped := len(batch) + len(q.ch)
+			for range q.ch {
+			}
+			eventsDropped.Add(uint64(dropped))
+			slog.Error("events dropped at shutdown", "transport", q.transport.Name(), "events", dropped)
+			return
+		}
+	}
+}
+
+// send publishes batch, retrying with exponential backoff until it
+// succeeds. It returns false only when the bus is aborted.
+func (b *Bus) send(t Transport, batch []Event) bool {
+	backoff := b.minBackoff
+	for {
+		err := t.Send(b.abort, batch)
+		if err == nil {
+			eventsPublished.Add(uint64(len(batch)))
+			return true
+		}
+		publishFailures.Inc()
+		slog.Warn("event publish failed, retrying", "transport", t.Name(), "events", len(batch), "retryIn", backoff, "error", err)
+
+		select {
+		case <-b.abort.Done():
+			return false
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, b.maxBackoff)
+	}
+}
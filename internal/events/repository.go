@@ -0,0 +1,166 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/notify"
+)
+
+// DatabaseRepository wraps a database.Repository and publishes a lifecycle
+// event after every successful write. Because the reconciler and API share
+// the repository, both status transitions and user changes are covered.
+type DatabaseRepository struct {
+	database.Repository
+	pub Publisher
+}
+
+// WrapDatabaseRepository returns repo publishing lifecycle events to pub.
+func WrapDatabaseRepository(repo database.Repository, pub Publisher) *DatabaseRepository {
+	return &DatabaseRepository{Repository: repo, pub: pub}
+}
+
+func (r *DatabaseRepository) Create(ctx context.Context, db *database.Database) error {
+	if err := r.Repository.Create(ctx, db); err != nil {
+		return err
+	}
+	// Re-read for the joined team and tier names, which Create does not set.
+	created := r.current(ctx, db.ID)
+	if created == nil {
+		created = db
+	}
+	r.pub.Publish(newEvent(TypeDatabaseCreated, created))
+	return nil
+}
+
+func (r *DatabaseRepository) Update(ctx context.Context, id uuid.UUID, fields database.UpdateFields) (*database.Database, error) {
+	updated, err := r.Repository.Update(ctx, id, fields)
+	if err != nil {
+		return nil, err
+	}
+	r.pub.Publish(newEvent(TypeDatabaseUpdated, updated))
+	return updated, nil
+}
+
+// UpdateStatus publishes status_changed only when the status actually
+// changes; updates that merely refresh connection details are not events.
+func (r *DatabaseRepository) UpdateStatus(ctx context.Context, id uuid.UUID, su database.StatusUpdate) (*database.Database, error) {
+	before := r.current(ctx, id)
+	updated, err := r.Repository.UpdateStatus(ctx, id, su)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil || before.Status != updated.Status {
+		e := newEvent(TypeDatabaseStatusChanged, updated)
+		if before != nil {
+			e.Data.PreviousStatus = before.Status
+		}
+		r.pub.Publish(e)
+	}
+	return updated, nil
+}
+
+// UpdateStatuses keeps the inner repository's batching and publishes
+// status_changed for each applied write that changed the status, like
+// UpdateStatus.
+func (r *DatabaseRepository) UpdateStatuses(ctx context.Context, writes []database.StatusWrite) ([]uuid.UUID, error) {
+	before := make(map[uuid.UUID]*database.Database, len(writes))
+	for _, c := range writes {
+		before[c.ID] = r.current(ctx, c.ID)
+	}
+	updated, err := database.UpdateStatuses(ctx, r.Repository, writes)
+	for _, id := range updated {
+		after := r.current(ctx, id)
+		if after == nil || (before[id] != nil && before[id].Status == after.Status) {
+			continue
+		}
+		e := newEvent(TypeDatabaseStatusChanged, after)
+		if before[id] != nil {
+			e.Data.PreviousStatus = before[id].Status
+		}
+		r.pub.Publish(e)
+	}
+	return updated, err
+}
+
+func (r *DatabaseRepository) SoftDelete(ctx context.Context, id uuid.UUID) error {
+	before := r.current(ctx, id)
+	if err := r.Repository.SoftDelete(ctx, id); err != nil {
+		return err
+	}
+	e := Event{Type: TypeDatabaseDeleted, Subject: id.String(), Data: DatabaseData{ID: id, Status: "deleted"}}
+	if before != nil {
+		e = newEvent(TypeDatabaseDeleted, before)
+		e.Data.PreviousStatus = before.Status
+		e.Data.Status = "deleted"
+	}
+	r.pub.Publish(e)
+	return nil
+}
+
+// current returns the database as stored, or nil if it cannot be read. A
+// failed read only makes the event less detailed, so it is not an error.
+func (r *DatabaseRepository) current(ctx context.Context, id uuid.UUID) *database.Database {
+	db, err := r.Repository.GetByID(ctx, id)
+	if err != nil {
+		slog.Debug("reading database for lifecycle event", "databaseId", id, "error", err)
+		return nil
+	}
+	return db
+}
+
+func newEvent(typ string, db *database.Database) Event {
+	data := DatabaseData{
+		ID:          db.ID,
+		Name:        db.Name,
+		OwnerTeam:   db.OwnerTeamName,
+		OwnerTeamID: db.OwnerTeamID,
+		Tier:        db.TierName,
+		Environment: db.Environment,
+		Namespace:   db.Namespace,
+		Status:      db.Status,
+
+		DataClassification: db.DataClassification,
+	}
+	if db.StatusReason != nil {
+		data.Reason = *db.StatusReason
+	}
+	if db.StatusMessage != nil {
+		data.Message = *db.StatusMessage
+	}
+	return Event{Type: typ, Subject: db.ID.String(), Data: data}
+}
+
+// Notifier publishes every notification as a notification event before
+// passing it on to next. Publishing happens even when next silences the
+// notification, since automation consuming the bus is not paged by it.
+type Notifier struct {
+	next notify.Notifier
+	pub  Publisher
+}
+
+// WrapNotifier returns next publishing notifications to pub.
+func WrapNotifier(next notify.Notifier, pub Publisher) *Notifier {
+	return &Notifier{next: next, pub: pub}
+}
+
+// Notify publishes n and delivers it through the wrapped notifier.
+func (n *Notifier) Notify(ctx context.Context, note notify.Notification) error {
+	n.pub.Publish(Event{
+		Type:    TypeDatabaseNotification,
+		Subject: note.DatabaseID.String(),
+		Time:    note.Time,
+		Data: DatabaseData{
+			ID:           note.DatabaseID,
+			Name:         note.Database,
+			OwnerTeam:    note.OwnerTeam,
+			Reason:       note.Reason,
+			Notification: note.Event,
+			Message:      note.Message,
+		},
+	})
+	return n.next.Notify(ctx, note)
+}
@@ -0,0 +1,213 @@
+package events
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// NATSTransport publishes each event to the NATS subject "<prefix>.<kind>",
+// where kind is the event type without its "daap." namespace, e.g.
+// "daap.events.database.created". Consumers can subscribe to "<prefix>.>"
+// or to single event types. It speaks the NATS text protocol directly over
+// TCP, which keeps DAAP free of a NATS client dependency; TLS is not
+// supported.
+type NATSTransport struct {
+	addr    string
+	user    string
+	pass    string
+	prefix  string
+	timeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATSTransport creates a NATSTransport for a URL of the form
+// nats://[user:pass@]host:port. The connection is opened on first use and
+// re-opened after errors.
+func NewNATSTransport(rawURL, prefix string, timeout time.Duration) (*NATSTransport, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" {
+		return nil, fmt.Errorf("unsupported NATS scheme %q (expected nats)", u.Scheme)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("NATS URL %q has no host", rawURL)
+	}
+	t := &NATSTransport{addr: u.Host, prefix: prefix, timeout: timeout}
+	if u.Port() == "" {
+		t.addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	if u.User != nil {
+		t.user = u.User.Username()
+		t.pass, _ = u.User.Password()
+	}
+	return t, nil
+}
+
+// Name returns "nats".
+func (t *NATSTransport) Name() string { return "nats" }
+
+// Send publishes events and then round-trips a PING, so it returns nil only
+// once the server has processed every PUB.
+func (t *NATSTransport) Send(ctx context.Context, events []Event) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := t.send(ctx, events); err != nil {
+		if t.conn != nil {
+			_ = t.conn.Close()
+			t.conn = nil
+		}
+		return err
+	}
+	return nil
+}
+
+func (t *NATSTransport) send(ctx context.Context, events []Event) error {
+	if t.conn == nil {
+		if err := t.connect(ctx); err != nil {
+			return err
+		}
+	}
+	_ = t.conn.SetDeadline(time.Now().Add(t.timeout))
+
+	var buf bytes.Buffer
+	for _, e := range events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encoding event: %w", err)
+		}
+		fmt.Fprintf(&buf, "PUB %s.%s %d\r\n", t.prefix, strings.TrimPrefix(e.Type, "daap."), len(payload))
+		buf.Write(payload)
+		buf.WriteString("\r\n")
+	}
+	buf.WriteString("PING\r\n")
+	if _, err := t.conn.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("writing to NATS: %w", err)
+	}
+	return t.awaitPong()
+}
+
+func (t *NATSTransport) connect(ctx context.Context) error {
+	d := net.Dialer{Timeout: t.timeout}
+	conn, err := d.DialContext(ctx, "tcp", t.addr)
+	if err != nil {
+		return fmt.Errorf("connecting to NATS: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(t.timeout))
+	r := bufio.NewReader(conn)
+
+	line, err := r.ReadString('\n')
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("reading NATS INFO: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		_ = conn.Close()
+		return fmt.Errorf("unexpected NATS greeting %q", strings.TrimSpace(line))
+	}
+
+	opts := map[string]interface{}{"verbose": false, "pedantic": false, "name": "daap", "lang": "go"}
+	if t.user != "" {
+		opts["user"], opts["pass"] = t.user, t.pass
+	}
+	connect, _ := json.Marshal(opts)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", connect); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("writing NATS CONNECT: %w", err)
+	}
+	t.conn, t.r = conn, r
+	return t.awaitPong()
+}
+
+// awaitPong reads server messages until PONG, answering server PINGs and
+// failing on -ERR (e.g. authorization or permission violations).
+func (t *NATSTransport) awaitPong() error {
+	for {
+		line, err := t.r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("reading from NATS: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := io.WriteString(t.conn, "PONG\r\n"); err != nil {
+				return fmt.Errorf("writing to NATS: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+// KafkaTransport produces events to a Kafka topic through a Kafka REST Proxy
+// (v2 API), keyed by database ID so each database's events stay ordered
+// within one partition.
+type KafkaTransport struct {
+	url    string
+	client *http.Client
+}
+
+// NewKafkaTransport creates a KafkaTransport producing to topic through the
+// REST proxy at proxyURL.
+func NewKafkaTransport(proxyURL, topic string, timeout time.Duration) *KafkaTransport {
+	return &KafkaTransport{
+		url:    strings.TrimRight(proxyURL, "/") + "/topics/" + url.PathEscape(topic),
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Name returns "kafka".
+func (t *KafkaTransport) Name() string { return "kafka" }
+
+type kafkaRecord struct {
+	Key   string `json:"key"`
+	Value Event  `json:"value"`
+}
+
+// Send produces events in one request. The proxy only answers 200 once
+// every record is acknowledged by the brokers.
+func (t *KafkaTransport) Send(ctx context.Context, events []Event) error {
+	records := make([]kafkaRecord, len(events))
+	for i, e := range events {
+		records[i] = kafkaRecord{Key: e.Subject, Value: e}
+	}
+	body, err := json.Marshal(map[string][]kafkaRecord{"records": records})
+	if err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building Kafka REST request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("producing events: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("kafka REST proxy returned status %d", resp.StatusCode)
+	}
+	return nil
+}
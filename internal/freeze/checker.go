@@ -0,0 +1,51 @@
+// Package freeze manages change freeze windows: org-wide or per-team periods
+// during which creating, deleting and resizing databases is rejected.
+package freeze
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Gate reports whether changes to a team's databases are frozen.
+type Gate interface {
+	// Frozen returns the window that currently freezes the team's
+	// databases, or nil if there is none.
+	Frozen(ctx context.Context, teamID uuid.UUID) (*Window, error)
+}
+
+// Checker implements Gate on top of a Repository.
+type Checker struct {
+	repo Repository
+	now  func() time.Time
+}
+
+// NewChecker creates a Checker that reads windows from repo.
+func NewChecker(repo Repository) *Checker {
+	return &Checker{repo: repo, now: time.Now}
+}
+
+// Frozen returns the active window that applies to the team, preferring an
+// org-wide one, or nil if the team's databases may change.
+func (c *Checker) Frozen(ctx context.Context, teamID uuid.UUID) (*Window, error) {
+	windows, err := c.repo.ListActive(ctx, c.now())
+	if err != nil {
+		return nil, err
+	}
+	var found *Window
+	for i := range windows {
+		w := &windows[i]
+		if !w.AppliesTo(teamID) {
+			continue
+		}
+		if w.TeamID == nil {
+			return w, nil
+		}
+		if found == nil {
+			found = w
+		}
+	}
+	return found, nil
+}
@@ -0,0 +1,30 @@
+package freeze
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Window is a period during which mutating operations on databases are
+// rejected. A window without a team applies to every team.
+type Window struct {
+	ID        uuid.UUID
+	TeamID    *uuid.UUID // nil for an org-wide freeze
+	TeamName  *string    // transient, populated via JOIN
+	Reason    string
+	StartsAt  time.Time
+	EndsAt    time.Time
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// Covers reports whether t falls within the window.
+func (w *Window) Covers(t time.Time) bool {
+	return !t.Before(w.StartsAt) && t.Before(w.EndsAt)
+}
+
+// AppliesTo reports whether the window freezes the given team's databases.
+func (w *Window) AppliesTo(teamID uuid.UUID) bool {
+	return w.TeamID == nil || *w.TeamID == teamID
+}
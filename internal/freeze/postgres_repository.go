@@ -0,0 +1,106 @@
+package freeze
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRepository implements Repository using pgxpool.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new Repository backed by the given connection pool.
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &PostgresRepository{pool: pool}
+}
+
+// allColumns is the ordered list of columns scanned from change_freezes with
+// a LEFT JOIN on teams for the transient TeamName field.
+const allColumns = `f.id, f.team_id, t.name, f.reason, f.starts_at, f.ends_at, f.created_by, f.created_at`
+
+const fromClause = `FROM change_freezes f LEFT JOIN teams t ON f.team_id = t.id`
+
+func scanWindow(row pgx.Row) (*Window, error) {
+	var w Window
+	err := row.Scan(&w.ID, &w.TeamID, &w.TeamName, &w.Reason, &w.StartsAt, &w.EndsAt, &w.CreatedBy, &w.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrWindowNotFound
+		}
+		return nil, fmt.Errorf("scanning freeze window row: %w", err)
+	}
+	return &w, nil
+}
+
+// Create inserts a new freeze window.
+func (r *PostgresRepository) Create(ctx context.Context, w *Window) error {
+	query := `
+		INSERT INTO change_freezes (team_id, reason, starts_at, ends_at, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at`
+
+	err := r.pool.QueryRow(ctx, query, w.TeamID, w.Reason, w.StartsAt, w.EndsAt, w.CreatedBy).Scan(&w.ID, &w.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting freeze window: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a single freeze window by its UUID.
+func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Window, error) {
+	query := fmt.Sprintf(`SELECT %s %s WHERE f.id = $1`, allColumns, fromClause)
+	return scanWindow(r.pool.QueryRow(ctx, query, id))
+}
+
+// List returns all freeze windows ordered by start time.
+func (r *PostgresRepository) List(ctx context.Context) ([]Window, error) {
+	query := fmt.Sprintf(`SELECT %s %s ORDER BY f.starts_at ASC, f.created_at ASC`, allColumns, fromClause)
+	return r.query(ctx, query)
+}
+
+// ListActive returns the freeze windows that cover at.
+func (r *PostgresRepository) ListActive(ctx context.Context, at time.Time) ([]Window, error) {
+	query := fmt.Sprintf(`SELECT %s %s WHERE f.starts_at <= $1 AND f.ends_at > $1
+		ORDER BY f.starts_at ASC, f.created_at ASC`, allColumns, fromClause)
+	return r.query(ctx, query, at)
+}
+
+func (r *PostgresRepository) query(ctx context.Context, query string, args ...any) ([]Window, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing freeze windows: %w", err)
+	}
+	defer rows.Close()
+
+	windows := []Window{}
+	for rows.Next() {
+		w, err := scanWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, *w)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating freeze window rows: %w", err)
+	}
+	return windows, nil
+}
+
+// Delete removes a freeze window.
+func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM change_freezes WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting freeze window: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWindowNotFound
+	}
+	return nil
+}
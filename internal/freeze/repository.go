@@ -0,0 +1,23 @@
+package freeze
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrWindowNotFound is returned when a freeze window record is not found.
+var ErrWindowNotFound = errors.New("freeze window not found")
+
+// Repository provides CRUD operations on the change_freezes table.
+type Repository interface {
+	Create(ctx context.Context, w *Window) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Window, error)
+	// List returns all windows ordered by start time.
+	List(ctx context.Context) ([]Window, error)
+	// ListActive returns the windows that cover at.
+	ListActive(ctx context.Context, at time.Time) ([]Window, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
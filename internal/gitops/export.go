@@ -0,0 +1,224 @@
+// Package gitops renders the desired state of every managed database into a
+// directory-structured tarball, so platform teams can commit DAAP's rendered
+// manifests to Git, diff them between releases and apply them in air-gapped
+// clusters. It backs GET /admin/gitops-export.
+package gitops
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// pageSize is the number of databases fetched per List call.
+const pageSize = 100
+
+// SkippedFile is the name of the bundle entry listing the databases whose
+// manifests could not be rendered.
+const SkippedFile = "skipped.txt"
+
+// Filter restricts an export.
+type Filter struct {
+	OwnerTeamID *uuid.UUID
+}
+
+// Summary describes a written bundle.
+type Summary struct {
+	Databases int      // number of databases rendered into the bundle
+	Skipped   []string // "<team>/<database>: <reason>" for each skipped database
+}
+
+// Exporter renders database manifests into a bundle.
+type Exporter struct {
+	repo     database.Repository
+	tierRepo tier.Repository
+	bpRepo   blueprint.Repository
+	registry *provider.Registry
+}
+
+// New creates an Exporter.
+func New(repo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry) *Exporter {
+	return &Exporter{repo: repo, tierRepo: tierRepo, bpRepo: bpRepo, registry: registry}
+}
+
+type entry struct {
+	path    string
+	content string
+	modTime time.Time
+}
+
+// Export writes a gzip-compressed tarball to w with one file per database at
+// <team>/<database>.yaml. Entries are sorted by path and carry no export
+// timestamp, so two exports of the same state are identical. Databases that
+// cannot be rendered (no tier, no blueprint, or a provider that does not
+// support rendering) are listed in skipped.txt instead.
+func (e *Exporter) Export(ctx context.Context, w io.Writer, filter Filter) (Summary, error) {
+	dbs, err := e.listDatabases(ctx, filter)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	tiers := make(map[uuid.UUID]*tier.Tier)
+	blueprints := make(map[uuid.UUID]*blueprint.Blueprint)
+
+	var summary Summary
+	entries := make([]entry, 0, len(dbs))
+	for i := range dbs {
+		db := &dbs[i]
+		content, reason, err := e.render(ctx, db, tiers, blueprints)
+		if err != nil {
+			return Summary{}, err
+		}
+		if reason != "" {
+			summary.Skipped = append(summary.Skipped, fmt.Sprintf("%s/%s: %s", db.OwnerTeamName, db.Name, reason))
+			continue
+		}
+		entries = append(entries, entry{
+			path:    db.OwnerTeamName + "/" + db.Name + ".yaml",
+			content: content,
+			modTime: db.UpdatedAt,
+		})
+	}
+	summary.Databases = len(entries)
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].path < entries[j].path })
+	if len(summary.Skipped) > 0 {
+		sort.Strings(summary.Skipped)
+		entries = append(entries, entry{path: SkippedFile, content: strings.Join(summary.Skipped, "\n") + "\n"})
+	}
+
+	if err := writeTarball(w, entries); err != nil {
+		return Summary{}, err
+	}
+	return summary, nil
+}
+
+// listDatabases pages through every database matching the filter.
+func (e *Exporter) listDatabases(ctx context.Context, filter Filter) ([]database.Database, error) {
+	var dbs []database.Database
+	for page := 1; ; page++ {
+		result, err := e.repo.List(ctx, database.ListFilter{OwnerTeamID: filter.OwnerTeamID, Page: page, Limit: pageSize})
+		if err != nil {
+			return nil, fmt.Errorf("listing databases: %w", err)
+		}
+		dbs = append(dbs, result.Databases...)
+		if len(result.Databases) < pageSize || len(dbs) >= result.Total {
+			return dbs, nil
+		}
+	}
+}
+
+// render returns the file content for db, or a non-empty reason when the
+// database has to be skipped. Tiers and blueprints are cached across calls.
+func (e *Exporter) render(ctx context.Context, db *database.Database, tiers map[uuid.UUID]*tier.Tier, blueprints map[uuid.UUID]*blueprint.Blueprint) (string, string, error) {
+	if db.TierID == nil {
+		return "", "no tier", nil
+	}
+	t, ok := tiers[*db.TierID]
+	if !ok {
+		var err error
+		t, err = e.tierRepo.GetByID(ctx, *db.TierID)
+		if errors.Is(err, tier.ErrTierNotFound) {
+			return "", "tier not found", nil
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("fetching tier for %s: %w", db.Name, err)
+		}
+		tiers[*db.TierID] = t
+	}
+	if t.BlueprintID == nil {
+		return "", fmt.Sprintf("tier %s has no blueprint", t.Name), nil
+	}
+	bp, ok := blueprints[*t.BlueprintID]
+	if !ok {
+		var err error
+		bp, err = e.bpRepo.GetByID(ctx, *t.BlueprintID)
+		if errors.Is(err, blueprint.ErrBlueprintNotFound) {
+			return "", "blueprint not found", nil
+		}
+		if err != nil {
+			return "", "", fmt.Errorf("fetching blueprint for %s: %w", db.Name, err)
+		}
+		blueprints[*t.BlueprintID] = bp
+	}
+
+	p, ok := e.registry.Get(bp.Provider)
+	if !ok {
+		return "", fmt.Sprintf("provider %s is not registered", bp.Provider), nil
+	}
+	renderer, ok := p.(provider.ManifestRenderer)
+	if !ok {
+		return "", fmt.Sprintf("provider %s does not support rendering", bp.Provider), nil
+	}
+	rendered, err := renderer.RenderManifests(db.ProviderDatabase(t, bp), bp.Manifests)
+	if errors.Is(err, provider.ErrNotSupported) {
+		return "", fmt.Sprintf("provider %s does not support rendering", bp.Provider), nil
+	}
+	if err != nil {
+		return "", "render failed: " + err.Error(), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# database: %s\n", db.Name)
+	fmt.Fprintf(&b, "# team: %s\n", db.OwnerTeamName)
+	if db.Environment != "" {
+		fmt.Fprintf(&b, "# environment: %s\n", db.Environment)
+	}
+	if db.DataClassification != "" {
+		fmt.Fprintf(&b, "# dataClassification: %s\n", db.DataClassification)
+	}
+	fmt.Fprintf(&b, "# tier: %s\n", t.Name)
+	fmt.Fprintf(&b, "# blueprint: %s (provider %s)\n", bp.Name, bp.Provider)
+	b.WriteString(strings.TrimRight(rendered, "\n"))
+	b.WriteString("\n")
+	return b.String(), "", nil
+}
+
+func writeTarball(w io.Writer, entries []entry) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	dirs := make(map[string]bool)
+	for _, en := range entries {
+		if dir, _, ok := strings.Cut(en.path, "/"); ok && !dirs[dir] {
+			dirs[dir] = true
+			if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0o755, ModTime: en.modTime}); err != nil {
+				return fmt.Errorf("writing %s: %w", dir, err)
+			}
+		}
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     en.path,
+			Mode:     0o644,
+			Size:     int64(len(en.content)),
+			ModTime:  en.modTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("writing %s: %w", en.path, err)
+		}
+		if _, err := io.WriteString(tw, en.content); err != nil {
+			return fmt.Errorf("writing %s: %w", en.path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing tarball: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("closing gzip stream: %w", err)
+	}
+	return nil
+}
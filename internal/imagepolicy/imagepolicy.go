@@ -0,0 +1,137 @@
+// Package imagepolicy applies the platform's image policy to the images a
+// database runs: it rewrites their references to pull through an internal
+// registry mirror and pins their tags to the digests they resolve to when the
+// database is provisioned.
+package imagepolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// Pinner resolves the images a new database runs. The database handlers
+// depend on it; a nil Pinner leaves images as the blueprint writes them.
+type Pinner interface {
+	Pin(ctx context.Context, refs []string) ([]database.ImagePin, error)
+}
+
+// Resolver resolves an image reference to the digest of the manifest it
+// names.
+type Resolver interface {
+	Digest(ctx context.Context, ref string) (string, error)
+}
+
+// Policy is the platform's image policy.
+type Policy struct {
+	mirror   string
+	resolver Resolver
+}
+
+// New returns the policy that pulls images through mirror, a registry host
+// with an optional path, e.g. "registry.internal/mirror", and pins their tags
+// to the digests resolver reports. An empty mirror keeps images on their
+// registries; a nil resolver leaves tags unpinned.
+func New(mirror string, resolver Resolver) (*Policy, error) {
+	mirror = strings.TrimSuffix(mirror, "/")
+	if strings.Contains(mirror, "://") || strings.ContainsAny(mirror, "@ ") {
+		return nil, fmt.Errorf("image mirror %q must be a registry host with an optional path, without a scheme", mirror)
+	}
+	return &Policy{mirror: mirror, resolver: resolver}, nil
+}
+
+// Pin returns the pins of refs: each reference mirrored and, unless it names
+// a digest already, pinned to the digest its tag resolves to. It fails if any
+// reference is invalid or cannot be resolved, so that a database never runs
+// an image the policy did not vet.
+func (p *Policy) Pin(ctx context.Context, refs []string) ([]database.ImagePin, error) {
+	pins := make([]database.ImagePin, 0, len(refs))
+	for _, ref := range refs {
+		parsed, err := parse(ref)
+		if err != nil {
+			return nil, err
+		}
+		parsed = parsed.mirrored(p.mirror)
+		if parsed.digest == "" && p.resolver != nil {
+			digest, err := p.resolver.Digest(ctx, parsed.String())
+			if err != nil {
+				return nil, fmt.Errorf("resolving image %s: %w", ref, err)
+			}
+			parsed.digest = digest
+		}
+		pins = append(pins, database.ImagePin{Reference: ref, Image: parsed.String(), Digest: parsed.digest})
+	}
+	return pins, nil
+}
+
+// dockerHub is the registry of references that name none.
+const dockerHub = "docker.io"
+
+// reference is a parsed image reference.
+type reference struct {
+	registry   string // e.g. "ghcr.io"; dockerHub for references that name none
+	repository string // e.g. "cloudnative-pg/postgresql"
+	tag        string // empty if the reference names none
+	digest     string // e.g. "sha256:..."; empty if the reference names none
+}
+
+// parse parses an image reference, [registry/]repository[:tag][@digest],
+// normalizing Docker Hub references the way the container runtime does:
+// "postgres:16" is docker.io/library/postgres:16.
+func parse(ref string) (reference, error) {
+	var r reference
+	name := ref
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, r.digest = name[:i], name[i+1:]
+		if !strings.Contains(r.digest, ":") {
+			return reference{}, fmt.Errorf("image %s has an invalid digest", ref)
+		}
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i:], "/") {
+		name, r.tag = name[:i], name[i+1:]
+	}
+	first, rest, ok := strings.Cut(name, "/")
+	if ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		r.registry, r.repository = first, rest
+	} else {
+		r.registry, r.repository = dockerHub, name
+		if !ok {
+			r.repository = "library/" + name
+		}
+	}
+	if r.repository == "" || strings.ContainsAny(r.repository, " \t") {
+		return reference{}, fmt.Errorf("image %s is not a valid reference", ref)
+	}
+	return r, nil
+}
+
+// mirrored returns r pulled through mirror: the mirror's host is the
+// registry and its path, then r's registry, prefix the repository, e.g.
+// registry.internal/mirror/ghcr.io/cloudnative-pg/postgresql. References
+// already on the mirror, and any with an empty mirror, are returned as is.
+func (r reference) mirrored(mirror string) reference {
+	if mirror == "" || strings.HasPrefix(r.registry+"/"+r.repository, mirror+"/") {
+		return r
+	}
+	host, path, _ := strings.Cut(mirror, "/")
+	repository := r.registry + "/" + r.repository
+	if path != "" {
+		repository = path + "/" + repository
+	}
+	r.registry, r.repository = host, repository
+	return r
+}
+
+// String formats r as registry/repository[:tag][@digest].
+func (r reference) String() string {
+	s := r.registry + "/" + r.repository
+	if r.tag != "" {
+		s += ":" + r.tag
+	}
+	if r.digest != "" {
+		s += "@" + r.digest
+	}
+	return s
+}
@@ -0,0 +1,168 @@
+package imagepolicy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// manifestTypes are the manifest media types the registry may answer with.
+// Indexes come first, so multi-platform images pin to their index rather
+// than to the manifest of one platform.
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// RegistryResolver resolves image tags to digests with the OCI distribution
+// API of the registry that hosts them, over HTTPS. Registries that require a
+// token get an anonymous one from the realm they name.
+type RegistryResolver struct {
+	client *http.Client
+}
+
+// NewRegistryResolver returns a RegistryResolver that makes its requests
+// with client, or http.DefaultClient if client is nil.
+func NewRegistryResolver(client *http.Client) *RegistryResolver {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &RegistryResolver{client: client}
+}
+
+// Digest returns the digest of the manifest ref names, as the registry
+// reports it in Docker-Content-Digest. A reference without a tag names
+// "latest".
+func (r *RegistryResolver) Digest(ctx context.Context, ref string) (string, error) {
+	parsed, err := parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if parsed.digest != "" {
+		return parsed.digest, nil
+	}
+	tag := parsed.tag
+	if tag == "" {
+		tag = "latest"
+	}
+	host := parsed.registry
+	if host == dockerHub {
+		host = "registry-1.docker.io"
+	}
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, parsed.repository, tag)
+
+	resp, err := r.head(ctx, manifestURL, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := r.token(ctx, resp.Header.Get("WWW-Authenticate"))
+		if err != nil {
+			return "", err
+		}
+		if resp, err = r.head(ctx, manifestURL, token); err != nil {
+			return "", err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s answered %s for %s:%s", parsed.registry, resp.Status, parsed.repository, tag)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("registry %s reported no digest for %s:%s", parsed.registry, parsed.repository, tag)
+	}
+	return digest, nil
+}
+
+// head sends a HEAD request for a manifest, with token as its bearer token
+// if set.
+func (r *RegistryResolver) head(ctx context.Context, manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("requesting %s: %w", manifestURL, err)
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// token gets an anonymous bearer token from the realm of a Bearer
+// WWW-Authenticate challenge, for the service and scope it names.
+func (r *RegistryResolver) token(ctx context.Context, challenge string) (string, error) {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return "", fmt.Errorf("registry requires unsupported authentication %q", scheme)
+	}
+	attrs := parseChallenge(params)
+	realm, err := url.Parse(attrs["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("registry named an invalid token realm %q", attrs["realm"])
+	}
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if v := attrs[k]; v != "" {
+			q.Set(k, v)
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("requesting a registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token realm %s answered %s", realm.Host, resp.Status)
+	}
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decoding the registry token: %w", err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
+
+// parseChallenge parses the comma-separated key="value" parameters of a
+// WWW-Authenticate challenge.
+func parseChallenge(params string) map[string]string {
+	attrs := map[string]string{}
+	for params != "" {
+		var value string
+		key, rest, ok := strings.Cut(strings.TrimLeft(params, ", "), "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				break
+			}
+			value, params = rest[1:end+1], rest[end+2:]
+		} else {
+			value, params, _ = strings.Cut(rest, ",")
+		}
+		attrs[strings.ToLower(strings.TrimSpace(key))] = value
+	}
+	return attrs
+}
@@ -0,0 +1,135 @@
+// Package insights snapshots the top statements of the databases opted in
+// to query insights from pg_stat_statements, with their literals redacted,
+// so teams can find their slow queries without access to the database.
+package insights
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// DefaultTop is the number of statements kept per snapshot when none is
+// configured.
+const DefaultTop = 20
+
+// StatementReader reads the top statements of a database by total execution
+// time.
+type StatementReader interface {
+	TopStatements(ctx context.Context, db *database.Database, limit int) ([]database.QueryStat, error)
+}
+
+// Collector snapshots the top statements of ready, opted-in databases on an
+// interval and discards snapshots older than its retention.
+type Collector struct {
+	repo      database.Repository
+	snapshots database.QuerySnapshotRepository
+	reader    StatementReader
+	interval  time.Duration
+	retention time.Duration
+
+	top int
+	now func() time.Time
+}
+
+// Option configures a Collector.
+type Option func(*Collector)
+
+// WithTop sets the number of statements kept per snapshot. The default is
+// DefaultTop.
+func WithTop(n int) Option {
+	return func(c *Collector) {
+		if n > 0 {
+			c.top = n
+		}
+	}
+}
+
+// WithClock sets the function used to read the current time.
+func WithClock(now func() time.Time) Option {
+	return func(c *Collector) {
+		c.now = now
+	}
+}
+
+// New creates a new Collector. Snapshots older than retention are discarded.
+func New(repo database.Repository, snapshots database.QuerySnapshotRepository, reader StatementReader, interval, retention time.Duration, opts ...Option) *Collector {
+	c := &Collector{
+		repo:      repo,
+		snapshots: snapshots,
+		reader:    reader,
+		interval:  interval,
+		retention: retention,
+		top:       DefaultTop,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Start begins the collection loop. It blocks until ctx is cancelled.
+func (c *Collector) Start(ctx context.Context) {
+	slog.Info("query insights collector started", "interval", c.interval.String(),
+		"retention", c.retention.String(), "top", c.top)
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("query insights collector stopped")
+			return
+		case <-ticker.C:
+			c.collect(ctx)
+		}
+	}
+}
+
+// RunOnce performs a single pass over all ready databases.
+func (c *Collector) RunOnce(ctx context.Context) {
+	c.collect(ctx)
+}
+
+func (c *Collector) collect(ctx context.Context) {
+	status := "ready"
+	for page := 1; ; page++ {
+		result, err := c.repo.List(ctx, database.ListFilter{Status: &status, Page: page, Limit: 100})
+		if err != nil {
+			slog.Error("insights: failed to list databases", "error", err)
+			return
+		}
+		for _, db := range result.Databases {
+			if ctx.Err() != nil {
+				return
+			}
+			if db.QueryInsights {
+				c.snapshot(ctx, &db)
+			}
+		}
+		if page*result.Limit >= result.Total {
+			break
+		}
+	}
+
+	if _, err := c.snapshots.DeleteBefore(ctx, c.now().Add(-c.retention)); err != nil {
+		slog.Error("insights: failed to prune query snapshots", "error", err)
+	}
+}
+
+func (c *Collector) snapshot(ctx context.Context, db *database.Database) {
+	stats, err := c.reader.TopStatements(ctx, db, c.top)
+	if err != nil {
+		slog.Warn("insights: failed to read top statements", "database", db.Name, "error", err)
+		return
+	}
+	for i := range stats {
+		stats[i].Query = Redact(stats[i].Query)
+	}
+	if err := c.snapshots.Record(ctx, &database.QuerySnapshot{DatabaseID: db.ID, Statements: stats}); err != nil {
+		slog.Error("insights: failed to record query snapshot", "database", db.Name, "error", err)
+	}
+}
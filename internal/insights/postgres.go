@@ -0,0 +1,83 @@
+package insights
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/readiness"
+)
+
+// topStatementsQuery reads the statements run in the connected database. The
+// application user only sees the text of its own statements; those of other
+// users are reported as "<insufficient privilege>" and have no query ID.
+const topStatementsQuery = `
+	SELECT queryid, query, calls, total_exec_time, mean_exec_time, rows
+	FROM pg_stat_statements
+	WHERE dbid = (SELECT oid FROM pg_database WHERE datname = current_database())
+	  AND queryid IS NOT NULL
+	ORDER BY total_exec_time DESC
+	LIMIT $1`
+
+// PostgresReader reads top statements by connecting to a database through
+// its pooler with the application credentials, like the readiness gate.
+type PostgresReader struct {
+	client  dynamic.Interface
+	timeout time.Duration
+}
+
+// NewPostgresReader creates a PostgresReader that reads credentials with
+// client. A timeout of 0 means no timeout.
+func NewPostgresReader(client dynamic.Interface, timeout time.Duration) *PostgresReader {
+	return &PostgresReader{client: client, timeout: timeout}
+}
+
+// TopStatements returns up to limit statements of db by total execution time.
+// It fails if pg_stat_statements is not installed in the database.
+func (r *PostgresReader) TopStatements(ctx context.Context, db *database.Database, limit int) ([]database.QueryStat, error) {
+	if db.Host == nil || db.Port == nil || db.SecretName == nil {
+		return nil, errors.New("database has no connection details")
+	}
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	cfg, err := readiness.ConnConfig(ctx, r.client, db.Namespace, *db.SecretName, *db.Host, *db.Port)
+	if err != nil {
+		return nil, err
+	}
+	// The pooler runs in transaction mode, where prepared statements do not
+	// survive between transactions.
+	cfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+	conn, err := pgx.ConnectConfig(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("connecting: %w", err)
+	}
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	rows, err := conn.Query(ctx, topStatementsQuery, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying pg_stat_statements: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []database.QueryStat
+	for rows.Next() {
+		var s database.QueryStat
+		if err := rows.Scan(&s.QueryID, &s.Query, &s.Calls, &s.TotalTimeMs, &s.MeanTimeMs, &s.Rows); err != nil {
+			return nil, fmt.Errorf("scanning statement: %w", err)
+		}
+		stats = append(stats, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating statements: %w", err)
+	}
+	return stats, nil
+}
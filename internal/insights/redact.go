@@ -0,0 +1,164 @@
+package insights
+
+import "strings"
+
+// Redacted replaces each literal in a redacted statement.
+const Redacted = "?"
+
+// Redact replaces the string, bit-string and numeric literals of a SQL
+// statement with Redacted. pg_stat_statements already replaces the constants
+// of most statements with parameters ($1, $2, ...), which are kept, but not
+// those of utility statements such as SET or CREATE, nor of statements
+// recorded before normalization. Identifiers, quoted or not, keywords and
+// comments are kept as written.
+func Redact(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	// prefixed reports whether the last thing written is a one-letter
+	// identifier that prefixes a string literal, as in E'...' or X'...'.
+	prefixed := func() bool {
+		out := b.String()
+		if len(out) == 0 || !strings.ContainsRune("eEbBxXnN", rune(out[len(out)-1])) {
+			return false
+		}
+		return len(out) == 1 || !isIdentChar(out[len(out)-2])
+	}
+
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'':
+			escapes := false
+			if prefixed() {
+				out := b.String()
+				escapes = out[len(out)-1] == 'e' || out[len(out)-1] == 'E'
+				b.Reset()
+				b.WriteString(out[:len(out)-1])
+			}
+			i = skipQuoted(query, i, escapes)
+			b.WriteString(Redacted)
+		case c == '"':
+			end := skipQuoted(query, i, false)
+			b.WriteString(query[i:end])
+			i = end
+		case c == '-' && strings.HasPrefix(query[i:], "--"):
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				end = len(query) - i
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				end = len(query) - i
+			} else {
+				end += 4
+			}
+			b.WriteString(query[i : i+end])
+			i += end
+		case c == '$':
+			if tag, ok := dollarTag(query[i:]); ok {
+				end := strings.Index(query[i+len(tag):], tag)
+				if end < 0 {
+					i = len(query)
+				} else {
+					i += 2*len(tag) + end
+				}
+				b.WriteString(Redacted)
+				continue
+			}
+			// A parameter such as $1 is kept.
+			j := i + 1
+			for j < len(query) && isDigit(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j
+		case isDigit(c) || (c == '.' && i+1 < len(query) && isDigit(query[i+1])):
+			i = skipNumber(query, i)
+			b.WriteString(Redacted)
+		case isIdentChar(c):
+			j := i
+			for j < len(query) && isIdentChar(query[j]) {
+				j++
+			}
+			b.WriteString(query[i:j])
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+// skipQuoted returns the index after the quoted string or identifier that
+// starts at query[start], where a doubled quote stands for the quote itself
+// and, with escapes, a backslash escapes the next character. An unterminated
+// one runs to the end of query.
+func skipQuoted(query string, start int, escapes bool) int {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch {
+		case escapes && query[i] == '\\':
+			i++
+		case query[i] == quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1
+		}
+	}
+	return len(query)
+}
+
+// dollarTag returns the tag, e.g. "$$" or "$body$", that opens the
+// dollar-quoted string s starts with.
+func dollarTag(s string) (string, bool) {
+	if len(s) < 2 || isDigit(s[1]) {
+		return "", false
+	}
+	for i := 1; i < len(s); i++ {
+		if s[i] == '$' {
+			return s[:i+1], true
+		}
+		if !isIdentChar(s[i]) {
+			return "", false
+		}
+	}
+	return "", false
+}
+
+// skipNumber returns the index after the numeric literal that starts at
+// query[start], such as 42, 3.14, .5 or 1e-3.
+func skipNumber(query string, start int) int {
+	i := start
+	for i < len(query) && (isDigit(query[i]) || query[i] == '.' || query[i] == '_') {
+		i++
+	}
+	if i < len(query) && (query[i] == 'e' || query[i] == 'E') {
+		j := i + 1
+		if j < len(query) && (query[j] == '+' || query[j] == '-') {
+			j++
+		}
+		if j < len(query) && isDigit(query[j]) {
+			i = j
+			for i < len(query) && isDigit(query[i]) {
+				i++
+			}
+		}
+	}
+	return i
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// isIdentChar reports whether c may continue an unquoted identifier. Bytes
+// of multi-byte UTF-8 characters count as letters.
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '$' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
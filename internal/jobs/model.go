@@ -0,0 +1,62 @@
+// Package jobs is a persistent queue of work DAAP does in the background on
+// behalf of a request, such as provisioning a new database. Jobs are stored,
+// so they survive a restart: a Worker claims queued jobs, runs them, and
+// retries failed attempts with backoff until a job runs out of attempts.
+package jobs
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Job statuses.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Job types.
+const (
+	TypeProvision = "provision"
+)
+
+// DefaultMaxAttempts is how many times a job is attempted when it is
+// enqueued without a limit.
+const DefaultMaxAttempts = 3
+
+// Job represents a row in the jobs table: one unit of background work on a
+// database. A job is queued until a worker claims it, running while the
+// worker runs it, and queued again after a failed attempt that may be
+// retried. Succeeded and failed jobs never change again.
+type Job struct {
+	ID          uuid.UUID
+	Type        string
+	Status      string
+	DatabaseID  uuid.UUID
+	OperationID *uuid.UUID        // operation the job reports to; nil when operations are not recorded
+	Params      map[string]string // type-specific parameters, e.g. "onFailure" for provision jobs
+	Attempts    int               // attempts started so far
+	MaxAttempts int
+	LastError   string    // error of the last failed attempt; empty if none failed
+	RunAfter    time.Time // earliest time a queued job is claimed
+	RequestID   string
+	CreatedBy   string
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	StartedAt   *time.Time // when the current or last attempt started
+	CompletedAt *time.Time // set once the job succeeds or fails
+}
+
+// Done reports whether the job has completed.
+func (j *Job) Done() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// LastAttempt reports whether the running attempt is the job's last: if it
+// fails, the job fails.
+func (j *Job) LastAttempt() bool {
+	return j.Attempts >= j.MaxAttempts
+}
@@ -0,0 +1,149 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRepository implements Repository using pgxpool.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new Repository backed by the given connection pool.
+func NewPostgresRepository(pool *pgxpool.Pool) Repository {
+	return &PostgresRepository{pool: pool}
+}
+
+const allColumns = `id, type, status, database_id, operation_id, params, attempts, max_attempts,
+	last_error, run_after, request_id, created_by, created_at, updated_at, started_at, completed_at`
+
+func scanJob(row pgx.Row) (*Job, error) {
+	var j Job
+	err := row.Scan(&j.ID, &j.Type, &j.Status, &j.DatabaseID, &j.OperationID, &j.Params, &j.Attempts, &j.MaxAttempts,
+		&j.LastError, &j.RunAfter, &j.RequestID, &j.CreatedBy, &j.CreatedAt, &j.UpdatedAt, &j.StartedAt, &j.CompletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoJob
+		}
+		return nil, fmt.Errorf("scanning job row: %w", err)
+	}
+	return &j, nil
+}
+
+// Enqueue inserts a queued job.
+func (p *PostgresRepository) Enqueue(ctx context.Context, job *Job) error {
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = DefaultMaxAttempts
+	}
+	if job.Params == nil {
+		job.Params = map[string]string{}
+	}
+	err := p.pool.QueryRow(ctx, `
+		INSERT INTO jobs (type, status, database_id, operation_id, params, max_attempts, run_after, request_id, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, COALESCE($7, NOW()), $8, $9)
+		RETURNING id, run_after, created_at, updated_at`,
+		job.Type, StatusQueued, job.DatabaseID, job.OperationID, job.Params, job.MaxAttempts,
+		nullTime(job.RunAfter), job.RequestID, job.CreatedBy,
+	).Scan(&job.ID, &job.RunAfter, &job.CreatedAt, &job.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting job: %w", err)
+	}
+	job.Status = StatusQueued
+	return nil
+}
+
+// Claim claims the job that has been due the longest. SKIP LOCKED lets
+// several instances claim different jobs concurrently.
+func (p *PostgresRepository) Claim(ctx context.Context, now time.Time) (*Job, error) {
+	return scanJob(p.pool.QueryRow(ctx, `
+		UPDATE jobs SET status = $1, attempts = attempts + 1, started_at = NOW(), updated_at = NOW()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE status = $2 AND run_after <= $3
+			ORDER BY run_after, created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+allColumns, StatusRunning, StatusQueued, now))
+}
+
+// Succeed completes a running job successfully.
+func (p *PostgresRepository) Succeed(ctx context.Context, id uuid.UUID) error {
+	return p.finish(ctx, `
+		UPDATE jobs SET status = $2, updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1 AND status = $3`, id, StatusSucceeded, StatusRunning)
+}
+
+// Retry requeues a running job after a failed attempt.
+func (p *PostgresRepository) Retry(ctx context.Context, id uuid.UUID, lastErr string, runAfter time.Time) error {
+	return p.finish(ctx, `
+		UPDATE jobs SET status = $2, last_error = $4, run_after = $5, updated_at = NOW()
+		WHERE id = $1 AND status = $3`, id, StatusQueued, StatusRunning, lastErr, runAfter)
+}
+
+// Fail completes a running job as failed.
+func (p *PostgresRepository) Fail(ctx context.Context, id uuid.UUID, lastErr string) error {
+	return p.finish(ctx, `
+		UPDATE jobs SET status = $2, last_error = $4, updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1 AND status = $3`, id, StatusFailed, StatusRunning, lastErr)
+}
+
+func (p *PostgresRepository) finish(ctx context.Context, query string, args ...any) error {
+	tag, err := p.pool.Exec(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("updating job: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrJobNotRunning
+	}
+	return nil
+}
+
+// Requeue moves jobs that started running before startedBefore back to
+// queued.
+func (p *PostgresRepository) Requeue(ctx context.Context, startedBefore time.Time) (int, error) {
+	tag, err := p.pool.Exec(ctx, `
+		UPDATE jobs SET status = $1, run_after = NOW(), updated_at = NOW()
+		WHERE status = $2 AND started_at < $3`, StatusQueued, StatusRunning, startedBefore)
+	if err != nil {
+		return 0, fmt.Errorf("requeueing jobs: %w", err)
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+// ListByDatabase returns the jobs on a database, newest first.
+func (p *PostgresRepository) ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]Job, error) {
+	rows, err := p.pool.Query(ctx, `SELECT `+allColumns+` FROM jobs WHERE database_id = $1 ORDER BY created_at DESC, id`, databaseID)
+	if err != nil {
+		return nil, fmt.Errorf("listing jobs: %w", err)
+	}
+	defer rows.Close()
+
+	list := []Job{}
+	for rows.Next() {
+		j, err := scanJob(rows)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *j)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating job rows: %w", err)
+	}
+	return list, nil
+}
+
+// nullTime returns nil for the zero time, so the column default applies.
+func nullTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,40 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrNoJob is returned by Claim when no queued job is due.
+var ErrNoJob = errors.New("no job is due")
+
+// ErrJobNotRunning is returned when completing or retrying a job that is not
+// running, e.g. because it was requeued after its worker was presumed dead.
+var ErrJobNotRunning = errors.New("job is not running")
+
+// Repository provides persistence for jobs.
+type Repository interface {
+	// Enqueue inserts a queued job, setting its ID, status and timestamps.
+	// MaxAttempts defaults to DefaultMaxAttempts and RunAfter to now.
+	Enqueue(ctx context.Context, job *Job) error
+	// Claim moves the queued job that has been due the longest to running,
+	// counting an attempt, and returns it. It returns ErrNoJob when no job
+	// is due at now. A job is claimed by one caller only.
+	Claim(ctx context.Context, now time.Time) (*Job, error)
+	// Succeed completes a running job successfully.
+	Succeed(ctx context.Context, id uuid.UUID) error
+	// Retry requeues a running job after a failed attempt, to be claimed
+	// again from runAfter.
+	Retry(ctx context.Context, id uuid.UUID, lastErr string, runAfter time.Time) error
+	// Fail completes a running job as failed.
+	Fail(ctx context.Context, id uuid.UUID, lastErr string) error
+	// Requeue moves jobs that started running before startedBefore back to
+	// queued, so that jobs whose worker died are run again, and returns how
+	// many it moved. Their attempt still counts.
+	Requeue(ctx context.Context, startedBefore time.Time) (int, error)
+	// ListByDatabase returns the jobs on a database, newest first.
+	ListByDatabase(ctx context.Context, databaseID uuid.UUID) ([]Job, error)
+}
@@ -0,0 +1,178 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// DefaultBackoff is how long a job waits before its first retry; each further
+// retry waits twice as long as the one before. DefaultTimeout bounds a
+// single attempt. A job still running RequeueGrace after its attempt timed
+// out is presumed abandoned by a dead worker and requeued; the grace leaves
+// a live worker time to record the outcome of an attempt that ran to its
+// timeout, so the job is never run twice at once.
+const (
+	DefaultBackoff = 10 * time.Second
+	DefaultTimeout = 10 * time.Minute
+	RequeueGrace   = time.Minute
+)
+
+// Handler runs one attempt of a job. An error fails the attempt: the job is
+// retried after a backoff, unless the attempt was its last or the error is
+// Permanent, in which case the job fails.
+type Handler func(ctx context.Context, job *Job) error
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one that retrying cannot fix, such as the job's
+// database having been deleted, so that the job fails at once.
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// IsPermanent reports whether err was marked with Permanent.
+func IsPermanent(err error) bool {
+	var p *permanentError
+	return errors.As(err, &p)
+}
+
+// Worker claims due jobs on an interval and runs them with the Handler
+// registered for their type. Workers of several instances may share a
+// repository: each job is claimed by one of them.
+type Worker struct {
+	repo     Repository
+	handlers map[string]Handler
+	interval time.Duration
+	backoff  time.Duration
+	timeout  time.Duration
+	now      func() time.Time
+}
+
+// Option configures a Worker.
+type Option func(*Worker)
+
+// WithBackoff sets the wait before a job's first retry. The default is
+// DefaultBackoff.
+func WithBackoff(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.backoff = d
+		}
+	}
+}
+
+// WithTimeout sets how long a single attempt may run. The default is
+// DefaultTimeout.
+func WithTimeout(d time.Duration) Option {
+	return func(w *Worker) {
+		if d > 0 {
+			w.timeout = d
+		}
+	}
+}
+
+// WithClock sets the function used to read the current time.
+func WithClock(now func() time.Time) Option {
+	return func(w *Worker) {
+		w.now = now
+	}
+}
+
+// NewWorker creates a Worker that claims jobs from repo every interval.
+func NewWorker(repo Repository, interval time.Duration, opts ...Option) *Worker {
+	w := &Worker{
+		repo:     repo,
+		handlers: map[string]Handler{},
+		interval: interval,
+		backoff:  DefaultBackoff,
+		timeout:  DefaultTimeout,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	return w
+}
+
+// Handle registers h to run jobs of jobType. It must be called before Start.
+func (w *Worker) Handle(jobType string, h Handler) {
+	w.handlers[jobType] = h
+}
+
+// Start begins the worker loop. It blocks until ctx is cancelled.
+func (w *Worker) Start(ctx context.Context) {
+	slog.Info("job worker started", "interval", w.interval.String(), "timeout", w.timeout.String())
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("job worker stopped")
+			return
+		case <-ticker.C:
+			w.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce requeues abandoned jobs, then runs every job that is due, one at a
+// time, until none is left or ctx is done.
+func (w *Worker) RunOnce(ctx context.Context) {
+	n, err := w.repo.Requeue(ctx, w.now().Add(-(w.timeout + RequeueGrace)))
+	if err != nil {
+		slog.Error("failed to requeue abandoned jobs", "error", err)
+	} else if n > 0 {
+		slog.Warn("requeued abandoned jobs", "count", n)
+	}
+
+	for ctx.Err() == nil {
+		job, err := w.repo.Claim(ctx, w.now())
+		if errors.Is(err, ErrNoJob) {
+			return
+		}
+		if err != nil {
+			slog.Error("failed to claim job", "error", err)
+			return
+		}
+		w.run(ctx, job)
+	}
+}
+
+func (w *Worker) run(ctx context.Context, job *Job) {
+	log := slog.With("job", job.ID, "type", job.Type, "database", job.DatabaseID, "attempt", job.Attempts)
+
+	h, ok := w.handlers[job.Type]
+	var err error
+	if ok {
+		attemptCtx, cancel := context.WithTimeout(ctx, w.timeout)
+		err = h(attemptCtx, job)
+		cancel()
+	} else {
+		err = Permanent(fmt.Errorf("no handler for job type %q", job.Type))
+	}
+
+	// The outcome is recorded even when ctx was cancelled mid-attempt.
+	ctx = context.WithoutCancel(ctx)
+	switch {
+	case err == nil:
+		log.Info("job succeeded")
+		err = w.repo.Succeed(ctx, job.ID)
+	case IsPermanent(err) || job.LastAttempt():
+		log.Error("job failed", "error", err)
+		err = w.repo.Fail(ctx, job.ID, err.Error())
+	default:
+		runAfter := w.now().Add(w.backoff << (job.Attempts - 1))
+		log.Warn("job attempt failed; retrying", "error", err, "runAfter", runAfter)
+		err = w.repo.Retry(ctx, job.ID, err.Error(), runAfter)
+	}
+	if err != nil {
+		log.Error("failed to record job outcome", "error", err)
+	}
+}
@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+)
+
+// AccessCheck is a single permission DAAP needs in a namespace.
+type AccessCheck struct {
+	Namespace string
+	Group     string
+	Resource  string
+	Verb      string
+}
+
+// String formats the check as "verb group/resource in namespace".
+func (a AccessCheck) String() string {
+	resource := a.Resource
+	if a.Group != "" {
+		resource = a.Group + "/" + a.Resource
+	}
+	return fmt.Sprintf("%s %s in %s", a.Verb, resource, a.Namespace)
+}
+
+// AccessResult is the outcome of an AccessCheck.
+type AccessResult struct {
+	AccessCheck
+	Allowed bool
+	Reason  string
+}
+
+// managedResources are the resources the CNPG provider creates, updates and
+// deletes on behalf of databases.
+var managedResources = []struct{ group, resource string }{
+	{"postgresql.cnpg.io", "clusters"},
+	{"postgresql.cnpg.io", "poolers"},
+	{"postgresql.cnpg.io", "scheduledbackups"},
+	{"postgresql.cnpg.io", "backups"},
+	{"", "configmaps"},
+	{"policy", "poddisruptionbudgets"},
+}
+
+// managedVerbs are the verbs the provider uses on managedResources. Manifests
+// are server-side applied, which takes patch, plus create for new resources.
+var managedVerbs = []string{"get", "list", "create", "patch", "delete"}
+
+// RequiredAccess returns the permissions DAAP needs in each namespace.
+func RequiredAccess(namespaces []string) []AccessCheck {
+	var checks []AccessCheck
+	for _, ns := range namespaces {
+		for _, r := range managedResources {
+			for _, verb := range managedVerbs {
+				checks = append(checks, AccessCheck{Namespace: ns, Group: r.group, Resource: r.resource, Verb: verb})
+			}
+		}
+		// Credential rotation rewrites the application secrets CNPG
+		// generates.
+		checks = append(checks,
+			AccessCheck{Namespace: ns, Resource: "secrets", Verb: "get"},
+			AccessCheck{Namespace: ns, Resource: "secrets", Verb: "update"})
+		// The load balancer of an exposed database is read from its Pooler's
+		// Service.
+		checks = append(checks, AccessCheck{Namespace: ns, Resource: "services", Verb: "get"})
+	}
+	return checks
+}
+
+// ReviewAccess asks the API server, via SelfSubjectAccessReview, whether the
+// calling identity is allowed to perform each check.
+func ReviewAccess(ctx context.Context, reviews authorizationv1client.SelfSubjectAccessReviewsGetter, checks []AccessCheck) ([]AccessResult, error) {
+	results := make([]AccessResult, 0, len(checks))
+	for _, check := range checks {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: check.Namespace,
+					Group:     check.Group,
+					Resource:  check.Resource,
+					Verb:      check.Verb,
+				},
+			},
+		}
+		resp, err := reviews.SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("reviewing access for %s: %w", check, err)
+		}
+		results = append(results, AccessResult{
+			AccessCheck: check,
+			Allowed:     resp.Status.Allowed,
+			Reason:      resp.Status.Reason,
+		})
+	}
+	return results, nil
+}
+
+// MissingAccess returns the results that were denied.
+func MissingAccess(results []AccessResult) []AccessResult {
+	var missing []AccessResult
+	for _, r := range results {
+		if !r.Allowed {
+			missing = append(missing, r)
+		}
+	}
+	return missing
+}
@@ -3,9 +3,12 @@ package k8s
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
+	authorizationv1client "k8s.io/client-go/kubernetes/typed/authorization/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
@@ -14,7 +17,11 @@ import (
 type Client struct {
 	dynamic   dynamic.Interface
 	discovery discovery.DiscoveryInterface
+	core      corev1client.CoreV1Interface
 	config    *rest.Config
+	// nsConfigs holds the impersonating config for namespaces that are
+	// mapped to a scoped ServiceAccount.
+	nsConfigs map[string]*rest.Config
 }
 
 // ConnectivityStatus represents the result of a Kubernetes connectivity check.
@@ -27,7 +34,10 @@ type ConnectivityStatus struct {
 type ClientOption func(*clientOptions)
 
 type clientOptions struct {
-	kubeconfigPath string
+	kubeconfigPath    string
+	impersonateUser   string
+	impersonateGroups []string
+	namespaceAccounts map[string]string
 }
 
 // WithKubeconfig sets the kubeconfig file path for out-of-cluster access.
@@ -37,6 +47,24 @@ func WithKubeconfig(path string) ClientOption {
 	}
 }
 
+// WithImpersonation makes every request act as the given user and groups
+// instead of the client's own identity.
+func WithImpersonation(user string, groups []string) ClientOption {
+	return func(o *clientOptions) {
+		o.impersonateUser = user
+		o.impersonateGroups = groups
+	}
+}
+
+// WithNamespaceServiceAccounts maps namespaces to ServiceAccount names.
+// Requests in a mapped namespace impersonate that namespace's ServiceAccount,
+// so each namespace only needs RBAC granted to its own account.
+func WithNamespaceServiceAccounts(accounts map[string]string) ClientOption {
+	return func(o *clientOptions) {
+		o.namespaceAccounts = accounts
+	}
+}
+
 // NewClient creates a new Kubernetes client.
 // It attempts in-cluster configuration first, falling back to kubeconfig if provided.
 func NewClient(opts ...ClientOption) (*Client, error) {
@@ -49,21 +77,50 @@ func NewClient(opts ...ClientOption) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("building kubernetes config: %w", err)
 	}
+	if o.impersonateUser != "" {
+		cfg.Impersonate = rest.ImpersonationConfig{
+			UserName: o.impersonateUser,
+			Groups:   o.impersonateGroups,
+		}
+	}
 
-	dynClient, err := dynamic.NewForConfig(cfg)
+	var dynClient dynamic.Interface
+	dynClient, err = dynamic.NewForConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating dynamic client: %w", err)
 	}
 
+	nsConfigs := make(map[string]*rest.Config, len(o.namespaceAccounts))
+	if len(o.namespaceAccounts) > 0 {
+		byNamespace := make(map[string]dynamic.Interface, len(o.namespaceAccounts))
+		for ns, sa := range o.namespaceAccounts {
+			scoped := impersonateServiceAccount(cfg, ns, sa)
+			c, err := dynamic.NewForConfig(scoped)
+			if err != nil {
+				return nil, fmt.Errorf("creating dynamic client for namespace %s: %w", ns, err)
+			}
+			nsConfigs[ns] = scoped
+			byNamespace[ns] = c
+		}
+		dynClient = NewNamespaceRouter(dynClient, byNamespace)
+	}
+
 	disc, err := discovery.NewDiscoveryClientForConfig(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("creating discovery client: %w", err)
 	}
 
+	core, err := corev1client.NewForConfig(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("creating core client: %w", err)
+	}
+
 	return &Client{
 		dynamic:   dynClient,
 		discovery: disc,
+		core:      core,
 		config:    cfg,
+		nsConfigs: nsConfigs,
 	}, nil
 }
 
@@ -72,6 +129,11 @@ func (c *Client) DynamicClient() dynamic.Interface {
 	return c.dynamic
 }
 
+// Discovery returns the underlying discovery client.
+func (c *Client) Discovery() discovery.DiscoveryInterface {
+	return c.discovery
+}
+
 // CheckConnectivity verifies that the Kubernetes API server is reachable
 // and returns the server version. The ctx parameter is accepted to satisfy the
 // HealthChecker interface and for future use when the discovery client supports
@@ -87,6 +149,38 @@ func (c *Client) CheckConnectivity(ctx context.Context) ConnectivityStatus {
 	}
 }
 
+// CheckAccess reviews the permissions DAAP needs in each namespace, using the
+// identity that requests in that namespace are sent as.
+func (c *Client) CheckAccess(ctx context.Context, namespaces []string) ([]AccessResult, error) {
+	var results []AccessResult
+	for _, ns := range namespaces {
+		cfg := c.config
+		if scoped, ok := c.nsConfigs[ns]; ok {
+			cfg = scoped
+		}
+		reviews, err := authorizationv1client.NewForConfig(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("creating authorization client: %w", err)
+		}
+		nsResults, err := ReviewAccess(ctx, reviews, RequiredAccess([]string{ns}))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, nsResults...)
+	}
+	return results, nil
+}
+
+// Namespaces returns the namespaces mapped to a scoped ServiceAccount.
+func (c *Client) Namespaces() []string {
+	namespaces := make([]string, 0, len(c.nsConfigs))
+	for ns := range c.nsConfigs {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
 // buildConfig creates a rest.Config, trying in-cluster first, then kubeconfig.
 func buildConfig(kubeconfigPath string) (*rest.Config, error) {
 	if kubeconfigPath != "" {
@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"net"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// IsTransient reports whether err indicates that the Kubernetes API server is
+// unreachable or overloaded, as opposed to a permanent problem with the
+// request itself (not found, invalid, forbidden, conflict).
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	return k8serrors.IsServerTimeout(err) ||
+		k8serrors.IsTimeout(err) ||
+		k8serrors.IsServiceUnavailable(err) ||
+		k8serrors.IsInternalError(err) ||
+		k8serrors.IsTooManyRequests(err) ||
+		k8serrors.IsUnexpectedServerError(err)
+}
@@ -0,0 +1,25 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// PodLogs returns the last tailLines lines logged by a container of pod. It
+// needs get on pods/log in the namespace.
+func PodLogs(ctx context.Context, core corev1client.CoreV1Interface, namespace, pod, container string, tailLines int64) ([]byte, error) {
+	raw, err := core.Pods(namespace).GetLogs(pod, &corev1.PodLogOptions{Container: container, TailLines: &tailLines}).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading logs of pod %s/%s: %w", namespace, pod, err)
+	}
+	return raw, nil
+}
+
+// PodLogs returns the last lines logged by a container of a pod. See the
+// package-level PodLogs.
+func (c *Client) PodLogs(ctx context.Context, namespace, pod, container string, tailLines int64) ([]byte, error) {
+	return PodLogs(ctx, c.core, namespace, pod, container, tailLines)
+}
@@ -0,0 +1,71 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// pgBouncerPoolsMetric prefixes the metrics in which the CNPG pgbouncer
+// exporter reports the columns of SHOW POOLS, one sample per pool.
+const pgBouncerPoolsMetric = "cnpg_pgbouncer_pools_"
+
+// PgBouncerPools returns the columns of pgbouncer's SHOW POOLS (cl_active,
+// cl_waiting, sv_active, sv_idle, ...) for the CNPG pooler running in pod,
+// each summed over the pools of the databases it serves; pgbouncer's own
+// admin pool is left out. They are read from the pooler's metrics endpoint
+// (port 9127) through the API server pod proxy, which needs get on
+// pods/proxy in the namespace.
+func PgBouncerPools(ctx context.Context, core corev1client.CoreV1Interface, namespace, pod string) (map[string]float64, error) {
+	raw, err := core.Pods(namespace).ProxyGet("http", pod, "9127", "/metrics", nil).DoRaw(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("reading metrics of pod %s/%s: %w", namespace, pod, err)
+	}
+
+	pools := map[string]float64{}
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, pgBouncerPoolsMetric) {
+			continue
+		}
+		// Label values may contain spaces: the value follows the labels.
+		name, rest := line, ""
+		if i := strings.LastIndex(line, "}"); i >= 0 {
+			name, rest = line[:i], line[i+1:]
+		} else if i := strings.IndexByte(line, ' '); i >= 0 {
+			name, rest = line[:i], line[i:]
+		}
+		fields := strings.Fields(rest)
+		if len(fields) == 0 {
+			continue
+		}
+		name, labels, _ := strings.Cut(name, "{")
+		if strings.Contains(labels, `database="pgbouncer"`) {
+			continue
+		}
+		v, err := strconv.ParseFloat(fields[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s of pod %s/%s: %w", name, namespace, pod, err)
+		}
+		pools[strings.TrimPrefix(name, pgBouncerPoolsMetric)] += v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading metrics of pod %s/%s: %w", namespace, pod, err)
+	}
+	if len(pools) == 0 {
+		return nil, fmt.Errorf("pod %s/%s does not export %s* metrics", namespace, pod, pgBouncerPoolsMetric)
+	}
+	return pools, nil
+}
+
+// PgBouncerPools returns the SHOW POOLS columns of a CNPG pooler. See the
+// package-level PgBouncerPools.
+func (c *Client) PgBouncerPools(ctx context.Context, namespace, pod string) (map[string]float64, error) {
+	return PgBouncerPools(ctx, c.core, namespace, pod)
+}
@@ -0,0 +1,55 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// replicationLagMetric is the metric in which the CNPG instance manager
+// exports, in seconds, how far a replica is behind its primary.
+const replicationLagMetric = "cnpg_pg_replication_lag"
+
+// ReplicationLag returns how far the CNPG instance running in pod lags behind
+// its primary, read from the instance manager's metrics endpoint (port 9187)
+// through the API server pod proxy. It needs get on pods/proxy in the
+// namespace.
+func ReplicationLag(ctx context.Context, core corev1client.CoreV1Interface, namespace, pod string) (time.Duration, error) {
+	raw, err := core.Pods(namespace).ProxyGet("http", pod, "9187", "/metrics", nil).DoRaw(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("reading metrics of pod %s/%s: %w", namespace, pod, err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(raw))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		name, _, _ := strings.Cut(fields[0], "{")
+		if name != replicationLagMetric {
+			continue
+		}
+		seconds, err := strconv.ParseFloat(fields[1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("parsing %s of pod %s/%s: %w", replicationLagMetric, namespace, pod, err)
+		}
+		return time.Duration(seconds * float64(time.Second)), nil
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("reading metrics of pod %s/%s: %w", namespace, pod, err)
+	}
+	return 0, fmt.Errorf("pod %s/%s does not export %s", namespace, pod, replicationLagMetric)
+}
+
+// ReplicationLag returns how far a CNPG instance lags behind its primary. See
+// the package-level ReplicationLag.
+func (c *Client) ReplicationLag(ctx context.Context, namespace, pod string) (time.Duration, error) {
+	return ReplicationLag(ctx, c.core, namespace, pod)
+}
@@ -0,0 +1,18 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretData returns the data of the Secret namespace/name. It needs get on
+// secrets in the namespace.
+func (c *Client) SecretData(ctx context.Context, namespace, name string) (map[string][]byte, error) {
+	s, err := c.core.Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("getting secret %s/%s: %w", namespace, name, err)
+	}
+	return s.Data, nil
+}
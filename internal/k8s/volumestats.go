@@ -0,0 +1,72 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// statsSummary is the subset of the kubelet /stats/summary response needed to
+// read volume usage.
+type statsSummary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Volume []struct {
+			UsedBytes *int64 `json:"usedBytes"`
+			PVCRef    *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+	} `json:"pods"`
+}
+
+// PVCUsedBytes returns the bytes used on the PersistentVolumeClaim pvc
+// mounted by pod, as reported by the kubelet of the pod's node through the
+// API server node proxy. It needs get on pods in the namespace and on
+// nodes/proxy.
+func PVCUsedBytes(ctx context.Context, core corev1client.CoreV1Interface, namespace, pod, pvc string) (int64, error) {
+	p, err := core.Pods(namespace).Get(ctx, pod, metav1.GetOptions{})
+	if err != nil {
+		return 0, fmt.Errorf("getting pod %s/%s: %w", namespace, pod, err)
+	}
+	node := p.Spec.NodeName
+	if node == "" {
+		return 0, fmt.Errorf("pod %s/%s is not scheduled", namespace, pod)
+	}
+
+	raw, err := core.RESTClient().Get().
+		AbsPath("/api/v1/nodes", node, "proxy", "stats", "summary").
+		DoRaw(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("reading stats summary of node %s: %w", node, err)
+	}
+
+	var summary statsSummary
+	if err := json.Unmarshal(raw, &summary); err != nil {
+		return 0, fmt.Errorf("decoding stats summary of node %s: %w", node, err)
+	}
+	for _, ps := range summary.Pods {
+		if ps.PodRef.Name != pod || ps.PodRef.Namespace != namespace {
+			continue
+		}
+		for _, v := range ps.Volume {
+			if v.PVCRef != nil && v.PVCRef.Name == pvc && v.UsedBytes != nil {
+				return *v.UsedBytes, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("no usage reported for claim %s of pod %s/%s", pvc, namespace, pod)
+}
+
+// PVCUsedBytes returns the bytes used on a PersistentVolumeClaim mounted by a
+// pod. See the package-level PVCUsedBytes.
+func (c *Client) PVCUsedBytes(ctx context.Context, namespace, pod, pvc string) (int64, error) {
+	return PVCUsedBytes(ctx, c.core, namespace, pod, pvc)
+}
@@ -0,0 +1,218 @@
+// Package logging provides the server's slog handler. It applies a log level
+// per module, where a record's module is the package that logged it (see
+// Module), and samples repeated debug records, so debugging one module does
+// not flood the logs of the others.
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+// modulePath is the import path prefix of this repository's packages.
+const modulePath = "github.com/daap14/daap/"
+
+// Module returns the module of the code at pc: "http" for the API packages,
+// "provider" for providers and their plugins, "server" for the server
+// binary, and the package name for any other internal package, such as
+// "reconciler" or "auth". Code outside this repository has no module.
+func Module(pc uintptr) string {
+	if pc == 0 {
+		return ""
+	}
+	if m, ok := modules.Load(pc); ok {
+		return m.(string)
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	m := moduleOf(fn.Name())
+	modules.Store(pc, m)
+	return m
+}
+
+var modules sync.Map // pc → module
+
+func moduleOf(funcName string) string {
+	path, ok := strings.CutPrefix(funcName, modulePath)
+	if !ok {
+		return ""
+	}
+	parts := strings.Split(path, "/")
+	// The last element is "pkg.Func" or "pkg.(*T).Method".
+	parts[len(parts)-1], _, _ = strings.Cut(parts[len(parts)-1], ".")
+	switch {
+	case parts[0] == "cmd":
+		return "server"
+	case parts[0] != "internal" || len(parts) < 2:
+		return ""
+	case parts[1] == "api":
+		return "http"
+	default:
+		return parts[1]
+	}
+}
+
+// Levels holds the default log level and the per-module overrides. It is
+// safe to change while logging.
+type Levels struct {
+	mu      sync.RWMutex
+	def     slog.Level
+	modules map[string]slog.Level
+	min     slog.Level
+}
+
+// NewLevels creates Levels with the given default and module overrides.
+func NewLevels(def slog.Level, modules map[string]slog.Level) *Levels {
+	l := &Levels{}
+	l.Set(def, modules)
+	return l
+}
+
+// Set replaces the default level and the module overrides.
+func (l *Levels) Set(def slog.Level, modules map[string]slog.Level) {
+	overrides := make(map[string]slog.Level, len(modules))
+	minLevel := def
+	for m, level := range modules {
+		overrides[m] = level
+		minLevel = min(minLevel, level)
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.def, l.modules, l.min = def, overrides, minLevel
+}
+
+// Level returns the level of module.
+func (l *Levels) Level(module string) slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	if level, ok := l.modules[module]; ok {
+		return level
+	}
+	return l.def
+}
+
+func (l *Levels) lowest() slog.Level {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.min
+}
+
+// Sampler limits how many debug records with the same module and message
+// are logged per second. It is safe to change while logging.
+type Sampler struct {
+	mu      sync.Mutex
+	perSec  int
+	windows map[string]*window
+}
+
+type window struct {
+	start   time.Time
+	logged  int
+	dropped int
+}
+
+// NewSampler creates a Sampler logging at most perSecond debug records of
+// each message per second; zero or less logs them all.
+func NewSampler(perSecond int) *Sampler {
+	return &Sampler{perSec: perSecond, windows: map[string]*window{}}
+}
+
+// SetRate changes the number of debug records of each message logged per
+// second; zero or less logs them all.
+func (s *Sampler) SetRate(perSecond int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.perSec = perSecond
+}
+
+// allow reports whether a record with key logged at t fits within the rate,
+// and, if so, how many with the same key were dropped since the last logged.
+func (s *Sampler) allow(key string, t time.Time) (bool, int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.perSec <= 0 {
+		return true, 0
+	}
+	w, ok := s.windows[key]
+	if !ok {
+		w = &window{start: t}
+		s.windows[key] = w
+	}
+	if t.Sub(w.start) >= time.Second {
+		w.start, w.logged = t, 0
+	}
+	if w.logged >= s.perSec {
+		w.dropped++
+		return false, 0
+	}
+	w.logged++
+	dropped := w.dropped
+	w.dropped = 0
+	return true, dropped
+}
+
+// Handler is a slog.Handler that adds each record's module, drops records
+// below their module's level, and samples debug records, before passing
+// the rest to the next handler. The next handler must accept every level.
+type Handler struct {
+	next    slog.Handler
+	levels  *Levels
+	sampler *Sampler
+}
+
+// NewHandler creates a Handler passing records to next. sampler may be nil
+// to log every debug record.
+func NewHandler(next slog.Handler, levels *Levels, sampler *Sampler) *Handler {
+	return &Handler{next: next, levels: levels, sampler: sampler}
+}
+
+// Enabled reports whether any module logs at level; Handle then applies
+// the level of the record's module.
+func (h *Handler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.levels.lowest()
+}
+
+// Handle logs r if its module's level allows it and, for debug records, if
+// it fits within the sampling rate. A logged record carries its module and,
+// when earlier ones with the same message were sampled out, their number
+// as "sampledOut".
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	module := Module(r.PC)
+	if r.Level < h.levels.Level(module) {
+		return nil
+	}
+	var extra []slog.Attr
+	if r.Level < slog.LevelInfo && h.sampler != nil {
+		ok, dropped := h.sampler.allow(module+"\x00"+r.Message, r.Time)
+		if !ok {
+			return nil
+		}
+		if dropped > 0 {
+			extra = append(extra, slog.Int("sampledOut", dropped))
+		}
+	}
+	if module != "" {
+		extra = append(extra, slog.String("module", module))
+	}
+	if len(extra) > 0 {
+		r = r.Clone()
+		r.AddAttrs(extra...)
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a Handler whose next handler has attrs.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{next: h.next.WithAttrs(attrs), levels: h.levels, sampler: h.sampler}
+}
+
+// WithGroup returns a Handler whose next handler has the group name.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), levels: h.levels, sampler: h.sampler}
+}
@@ -0,0 +1,82 @@
+// Package mail sends plain-text emails to users, such as the activation link
+// of a user invitation.
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender delivers emails.
+type Sender interface {
+	Send(ctx context.Context, m Message) error
+}
+
+// LogSender writes emails to the structured log. It is the default when no
+// SMTP server is configured, so invitations still work in development.
+type LogSender struct{}
+
+// Send logs m.
+func (LogSender) Send(_ context.Context, m Message) error {
+	slog.Info("email", "to", m.To, "subject", m.Subject, "body", m.Body)
+	return nil
+}
+
+// SMTPSender delivers emails through an SMTP relay.
+type SMTPSender struct {
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewSMTPSender creates an SMTPSender relaying through addr (host:port) as
+// from. PLAIN authentication is used when username is set; net/smtp only
+// sends credentials over TLS or to localhost.
+func NewSMTPSender(addr, from, username, password string) *SMTPSender {
+	s := &SMTPSender{addr: addr, from: from}
+	if username != "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			host = addr
+		}
+		s.auth = smtp.PlainAuth("", username, password, host)
+	}
+	return s
+}
+
+// Send delivers m. net/smtp has no context support, so ctx is only checked
+// before connecting.
+func (s *SMTPSender) Send(ctx context.Context, m Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{m.To}, s.format(m)); err != nil {
+		return fmt.Errorf("sending email to %s: %w", m.To, err)
+	}
+	return nil
+}
+
+func (s *SMTPSender) format(m Message) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", s.from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
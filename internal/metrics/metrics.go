@@ -0,0 +1,182 @@
+// Package metrics is a minimal, dependency-free metrics registry that renders
+// the Prometheus text exposition format. It covers the handful of metric
+// types DAAP needs without pulling in the full Prometheus client.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// collector is implemented by every metric type.
+type collector interface {
+	name() string
+	write(w io.Writer)
+}
+
+// Registry holds a set of metrics and renders them in text format.
+type Registry struct {
+	mu         sync.Mutex
+	collectors map[string]collector
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
+
+// Default is the process-wide registry served by Handler.
+var Default = NewRegistry()
+
+func (r *Registry) register(c collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.collectors[c.name()]; exists {
+		panic(fmt.Sprintf("metrics: duplicate metric %q", c.name()))
+	}
+	r.collectors[c.name()] = c
+}
+
+// WriteText writes every registered metric, sorted by name.
+func (r *Registry) WriteText(w io.Writer) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	cs := make([]collector, len(names))
+	for i, name := range names {
+		cs[i] = r.collectors[name]
+	}
+	r.mu.Unlock()
+
+	for _, c := range cs {
+		c.write(w)
+	}
+}
+
+// Handler serves the registry in Prometheus text format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
+
+// Handler serves the Default registry.
+func Handler() http.Handler {
+	return Default.Handler()
+}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	n, help string
+	v       atomic.Uint64
+}
+
+// NewCounter creates a counter and registers it in the Default registry.
+func NewCounter(name, help string) *Counter {
+	return Default.NewCounter(name, help)
+}
+
+// NewCounter creates a counter and registers it in r.
+func (r *Registry) NewCounter(name, help string) *Counter {
+	c := &Counter{n: name, help: help}
+	r.register(c)
+	return c
+}
+
+// Inc increments the counter by one.
+func (c *Counter) Inc() { c.v.Add(1) }
+
+// Add increments the counter by n.
+func (c *Counter) Add(n uint64) { c.v.Add(n) }
+
+// Value returns the current count.
+func (c *Counter) Value() uint64 { return c.v.Load() }
+
+func (c *Counter) name() string { return c.n }
+
+func (c *Counter) write(w io.Writer) {
+	writeHeader(w, c.n, c.help, "counter")
+	fmt.Fprintf(w, "%s %d\n", c.n, c.Value())
+}
+
+// Histogram counts observations in cumulative buckets.
+type Histogram struct {
+	n, help string
+	upper   []float64
+
+	mu     sync.Mutex
+	counts []uint64 // per bucket, non-cumulative; the last entry is +Inf
+	sum    float64
+	count  uint64
+}
+
+// NewHistogram creates a histogram and registers it in the Default registry.
+func NewHistogram(name, help string, buckets []float64) *Histogram {
+	return Default.NewHistogram(name, help, buckets)
+}
+
+// NewHistogram creates a histogram with the given upper bounds (which must be
+// sorted ascending; +Inf is implicit) and registers it in r.
+func (r *Registry) NewHistogram(name, help string, buckets []float64) *Histogram {
+	upper := make([]float64, len(buckets))
+	copy(upper, buckets)
+	h := &Histogram{n: name, help: help, upper: upper, counts: make([]uint64, len(upper)+1)}
+	r.register(h)
+	return h
+}
+
+// Observe records a single value.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.upper, v)
+	h.mu.Lock()
+	h.counts[i]++
+	h.sum += v
+	h.count++
+	h.mu.Unlock()
+}
+
+// Count returns the number of observations.
+func (h *Histogram) Count() uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.count
+}
+
+func (h *Histogram) name() string { return h.n }
+
+func (h *Histogram) write(w io.Writer) {
+	h.mu.Lock()
+	counts := make([]uint64, len(h.counts))
+	copy(counts, h.counts)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	writeHeader(w, h.n, h.help, "histogram")
+	var cumulative uint64
+	for i, le := range h.upper {
+		cumulative += counts[i]
+		fmt.Fprintf(w, "%s_bucket{le=\"%s\"} %d\n", h.n, strconv.FormatFloat(le, 'g', -1, 64), cumulative)
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", h.n, count)
+	fmt.Fprintf(w, "%s_sum %s\n", h.n, strconv.FormatFloat(sum, 'g', -1, 64))
+	fmt.Fprintf(w, "%s_count %d\n", h.n, count)
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, escapeHelp(help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
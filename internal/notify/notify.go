@@ -0,0 +1,118 @@
+// Package notify delivers operator notifications about database lifecycle
+// problems, such as a database stuck in provisioning.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification describes an event operators should act on.
+type Notification struct {
+	Event      string    `json:"event"`
+	Message    string    `json:"message"`
+	DatabaseID uuid.UUID `json:"databaseId"`
+	Database   string    `json:"database"`
+	OwnerTeam  string    `json:"ownerTeam"`
+	Reason     string    `json:"reason,omitempty"`
+	Time       time.Time `json:"time"`
+}
+
+// Notifier delivers notifications.
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// LogNotifier writes notifications to the structured log. It is the default
+// when no webhook is configured.
+type LogNotifier struct{}
+
+// Notify logs n as a warning.
+func (LogNotifier) Notify(_ context.Context, n Notification) error {
+	slog.Warn("notification",
+		"event", n.Event,
+		"message", n.Message,
+		"database", n.Database,
+		"id", n.DatabaseID,
+		"ownerTeam", n.OwnerTeam,
+		"reason", n.Reason,
+	)
+	return nil
+}
+
+// WebhookNotifier POSTs notifications as JSON to a URL.
+type WebhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a WebhookNotifier posting to url.
+func NewWebhookNotifier(url string, timeout time.Duration) *WebhookNotifier {
+	return &WebhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Notify posts n to the webhook. Any non-2xx response is an error.
+func (w *WebhookNotifier) Notify(ctx context.Context, n Notification) error {
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("encoding notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("building webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Silencer reports whether notifications about a database are silenced, for
+// example because a user acknowledged its error.
+type Silencer interface {
+	Silenced(ctx context.Context, databaseID uuid.UUID) (bool, error)
+}
+
+// SilencingNotifier drops notifications about silenced databases and passes
+// the rest to the wrapped Notifier. Notifications without a database are
+// always delivered, as are all notifications when the silencer fails.
+type SilencingNotifier struct {
+	next     Notifier
+	silencer Silencer
+}
+
+// NewSilencingNotifier wraps next so that notifications about databases
+// silenced by silencer are dropped.
+func NewSilencingNotifier(next Notifier, silencer Silencer) *SilencingNotifier {
+	return &SilencingNotifier{next: next, silencer: silencer}
+}
+
+// Notify delivers n unless its database is silenced.
+func (s *SilencingNotifier) Notify(ctx context.Context, n Notification) error {
+	if n.DatabaseID != uuid.Nil {
+		silenced, err := s.silencer.Silenced(ctx, n.DatabaseID)
+		if err != nil {
+			slog.Error("failed to check notification silence", "database", n.Database, "error", err)
+		} else if silenced {
+			slog.Info("notification silenced by acknowledgement", "event", n.Event, "database", n.Database, "id", n.DatabaseID)
+			return nil
+		}
+	}
+	return s.next.Notify(ctx, n)
+}
@@ -0,0 +1,70 @@
+// Package operation tracks long-running actions on databases, such as
+// provisioning a new database or promoting one, so callers can poll an
+// operation handle for progress, result and error instead of inferring the
+// outcome from the database's status.
+package operation
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Operation statuses.
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Operation types.
+const (
+	TypeCreate   = "create"
+	TypePromote  = "promote"
+	TypeDelete   = "delete"
+	TypeFailover = "failover"
+	TypeRestart  = "restart"
+	TypeRename   = "rename"
+	TypeRestore  = "restore"
+	TypeUnfreeze = "unfreeze"
+)
+
+// Operation represents a row in the operations table: one long-running
+// action on a database. An operation is running until it succeeds or fails;
+// completed operations never change again.
+type Operation struct {
+	ID         uuid.UUID
+	Type       string
+	Status     string
+	DatabaseID uuid.UUID // database the action changes
+	TeamID     uuid.UUID // owner team of the database when the operation started
+	Progress   int       // percent complete, 0-100
+	Message    string    // current step, e.g. "Waiting for the database to become ready"
+	Result     map[string]any
+	Error      *Error
+	RequestID  string
+	CreatedBy  string
+	Instance   string    // DAAP process running the operation, as hostname:pid
+	RenewedAt  time.Time // last heartbeat of that process
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+	// CompletedAt is set once the operation succeeds or fails.
+	CompletedAt *time.Time
+}
+
+// Error describes why an operation failed.
+type Error struct {
+	Code    string
+	Message string
+}
+
+// Done reports whether the operation has completed.
+func (o *Operation) Done() bool {
+	return o.Status != StatusRunning
+}
+
+// ListFilter holds optional filters for listing operations.
+type ListFilter struct {
+	DatabaseID *uuid.UUID
+	Status     *string
+}
@@ -0,0 +1,160 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRepository implements Repository using pgxpool.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new Repository backed by the given connection pool.
+func NewPostgresRepository(pool *pgxpool.Pool) Repository {
+	return &PostgresRepository{pool: pool}
+}
+
+const allColumns = `id, type, status, database_id, team_id, progress, message, result,
+	error_code, error_message, request_id, created_by, instance, renewed_at, created_at, updated_at, completed_at`
+
+func scanOperation(row pgx.Row) (*Operation, error) {
+	var op Operation
+	var errCode, errMessage *string
+	err := row.Scan(&op.ID, &op.Type, &op.Status, &op.DatabaseID, &op.TeamID, &op.Progress, &op.Message, &op.Result,
+		&errCode, &errMessage, &op.RequestID, &op.CreatedBy, &op.Instance, &op.RenewedAt, &op.CreatedAt, &op.UpdatedAt, &op.CompletedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrOperationNotFound
+		}
+		return nil, fmt.Errorf("scanning operation row: %w", err)
+	}
+	if errCode != nil {
+		op.Error = &Error{Code: *errCode}
+		if errMessage != nil {
+			op.Error.Message = *errMessage
+		}
+	}
+	return &op, nil
+}
+
+// Create inserts a running operation.
+func (p *PostgresRepository) Create(ctx context.Context, op *Operation) error {
+	err := p.pool.QueryRow(ctx, `
+		INSERT INTO operations (type, status, database_id, team_id, progress, message, request_id, created_by, instance)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, renewed_at, created_at, updated_at`,
+		op.Type, StatusRunning, op.DatabaseID, op.TeamID, op.Progress, op.Message, op.RequestID, op.CreatedBy, op.Instance,
+	).Scan(&op.ID, &op.RenewedAt, &op.CreatedAt, &op.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting operation: %w", err)
+	}
+	op.Status = StatusRunning
+	return nil
+}
+
+// GetByID retrieves a single operation by its UUID.
+func (p *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Operation, error) {
+	return scanOperation(p.pool.QueryRow(ctx, `SELECT `+allColumns+` FROM operations WHERE id = $1`, id))
+}
+
+// List returns operations matching the filter, newest first.
+func (p *PostgresRepository) List(ctx context.Context, filter ListFilter) ([]Operation, error) {
+	var conditions []string
+	var args []any
+	if filter.DatabaseID != nil {
+		args = append(args, *filter.DatabaseID)
+		conditions = append(conditions, fmt.Sprintf("database_id = $%d", len(args)))
+	}
+	if filter.Status != nil {
+		args = append(args, *filter.Status)
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+	query := `SELECT ` + allColumns + ` FROM operations`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY created_at DESC, id"
+
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing operations: %w", err)
+	}
+	defer rows.Close()
+
+	ops := []Operation{}
+	for rows.Next() {
+		op, err := scanOperation(rows)
+		if err != nil {
+			return nil, err
+		}
+		ops = append(ops, *op)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating operation rows: %w", err)
+	}
+	return ops, nil
+}
+
+// SetProgress records the progress of a running operation.
+func (p *PostgresRepository) SetProgress(ctx context.Context, id uuid.UUID, progress int, message string) error {
+	tag, err := p.pool.Exec(ctx, `
+		UPDATE operations SET progress = $2, message = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $4`, id, progress, message, StatusRunning)
+	if err != nil {
+		return fmt.Errorf("updating operation progress: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return p.notRunning(ctx, id)
+	}
+	return nil
+}
+
+// Complete moves a running operation to a final status atomically.
+func (p *PostgresRepository) Complete(ctx context.Context, id uuid.UUID, status string, result map[string]any, opErr *Error) (*Operation, error) {
+	var errCode, errMessage *string
+	if opErr != nil {
+		errCode, errMessage = &opErr.Code, &opErr.Message
+	}
+	op, err := scanOperation(p.pool.QueryRow(ctx, `
+		UPDATE operations SET status = $2, result = $3, error_code = $4, error_message = $5,
+			progress = CASE WHEN $2 = '`+StatusSucceeded+`' THEN 100 ELSE progress END,
+			updated_at = NOW(), completed_at = NOW()
+		WHERE id = $1 AND status = $6
+		RETURNING `+allColumns, id, status, result, errCode, errMessage, StatusRunning))
+	if errors.Is(err, ErrOperationNotFound) {
+		return nil, p.notRunning(ctx, id)
+	}
+	return op, err
+}
+
+// Renew sets renewed_at on the running operations among ids.
+func (p *PostgresRepository) Renew(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := p.pool.Query(ctx, `
+		UPDATE operations SET renewed_at = NOW()
+		WHERE id = ANY($1) AND status = $2
+		RETURNING id`, ids, StatusRunning)
+	if err != nil {
+		return nil, fmt.Errorf("renewing operations: %w", err)
+	}
+	renewed, err := pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+	if err != nil {
+		return nil, fmt.Errorf("renewing operations: %w", err)
+	}
+	return renewed, nil
+}
+
+// notRunning tells apart a missing operation from a completed one after a
+// conditional update matched no row.
+func (p *PostgresRepository) notRunning(ctx context.Context, id uuid.UUID) error {
+	if _, err := p.GetByID(ctx, id); err != nil {
+		return err
+	}
+	return ErrOperationDone
+}
@@ -0,0 +1,35 @@
+package operation
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrOperationNotFound is returned when an operation record is not found.
+var ErrOperationNotFound = errors.New("operation not found")
+
+// ErrOperationDone is returned when updating an operation that has already
+// completed.
+var ErrOperationDone = errors.New("operation already completed")
+
+// Repository provides persistence for operations.
+type Repository interface {
+	// Create inserts a running operation, setting its ID and timestamps.
+	Create(ctx context.Context, op *Operation) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Operation, error)
+	// List returns operations matching the filter, newest first.
+	List(ctx context.Context, filter ListFilter) ([]Operation, error)
+	// SetProgress records the progress and current step of a running
+	// operation. It returns ErrOperationDone if the operation has completed.
+	SetProgress(ctx context.Context, id uuid.UUID, progress int, message string) error
+	// Complete moves a running operation to status, StatusSucceeded with
+	// result or StatusFailed with opErr. It returns ErrOperationDone if the
+	// operation has already completed.
+	Complete(ctx context.Context, id uuid.UUID, status string, result map[string]any, opErr *Error) (*Operation, error)
+	// Renew records a heartbeat on the running operations among ids,
+	// setting their RenewedAt, and returns their IDs. Unknown and completed
+	// operations are skipped.
+	Renew(ctx context.Context, ids []uuid.UUID) ([]uuid.UUID, error)
+}
@@ -0,0 +1,238 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HeartbeatInterval is how often a Tracker records that the operations it
+// started are still running. An operation whose process missed three
+// heartbeats is considered abandoned.
+const HeartbeatInterval = 30 * time.Second
+
+// Tracker records operations on behalf of the code performing them. Its
+// bookkeeping never fails the action being tracked: errors are logged. A nil
+// *Tracker is valid and tracks nothing, so callers need not check whether
+// operations are enabled.
+//
+// Like a database.Locker, a Tracker remembers the running operations it
+// started, so KeepAlive can heartbeat them and Live can tell an operation
+// still in progress from one abandoned by a crashed process.
+type Tracker struct {
+	repo     Repository
+	instance string
+	wg       sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[uuid.UUID]struct{}
+}
+
+// NewTracker creates a Tracker that stores operations in repo. instance
+// identifies the process, e.g. "hostname:pid".
+func NewTracker(repo Repository, instance string) *Tracker {
+	return &Tracker{repo: repo, instance: instance, running: make(map[uuid.UUID]struct{})}
+}
+
+// Start records op as running and returns it with its ID set, or nil if it
+// could not be recorded.
+func (t *Tracker) Start(ctx context.Context, op Operation) *Operation {
+	if t == nil {
+		return nil
+	}
+	op.Instance = t.instance
+	if err := t.repo.Create(ctx, &op); err != nil {
+		slog.Error("failed to record operation", "error", err, "type", op.Type, "database", op.DatabaseID)
+		return nil
+	}
+	t.mu.Lock()
+	t.running[op.ID] = struct{}{}
+	t.mu.Unlock()
+	return &op
+}
+
+// Progress records the progress and current step of op.
+func (t *Tracker) Progress(ctx context.Context, op *Operation, progress int, message string) {
+	if t == nil || op == nil {
+		return
+	}
+	if err := t.repo.SetProgress(ctx, op.ID, progress, message); err != nil {
+		slog.Error("failed to record operation progress", "error", err, "operation", op.ID)
+		return
+	}
+	op.Progress, op.Message = progress, message
+}
+
+// Succeed completes op successfully with result.
+func (t *Tracker) Succeed(ctx context.Context, op *Operation, result map[string]any) {
+	t.complete(ctx, op, StatusSucceeded, result, nil)
+}
+
+// Fail completes op with an error.
+func (t *Tracker) Fail(ctx context.Context, op *Operation, code, message string) {
+	t.complete(ctx, op, StatusFailed, nil, &Error{Code: code, Message: message})
+}
+
+func (t *Tracker) complete(ctx context.Context, op *Operation, status string, result map[string]any, opErr *Error) {
+	if t == nil || op == nil {
+		return
+	}
+	done, err := t.repo.Complete(context.WithoutCancel(ctx), op.ID, status, result, opErr)
+	if err == nil || errors.Is(err, ErrOperationDone) {
+		t.mu.Lock()
+		delete(t.running, op.ID)
+		t.mu.Unlock()
+	}
+	if err != nil {
+		slog.Error("failed to complete operation", "error", err, "operation", op.ID, "status", status)
+		return
+	}
+	*op = *done
+}
+
+// Go runs fn in the background on behalf of op, which the caller started, and
+// completes op with fn's result, or as failed when fn returns an error. When fn
+// returns neither, op is left running for Settle to complete once the
+// database settles. fn outlives the request that started it: ctx is detached
+// from its cancellation. With a nil Tracker there is nowhere to report the
+// outcome, so fn runs before Go returns.
+func (t *Tracker) Go(ctx context.Context, op *Operation, fn func(ctx context.Context) (map[string]any, *Error)) {
+	ctx = context.WithoutCancel(ctx)
+	if t == nil {
+		fn(ctx)
+		return
+	}
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		result, opErr := fn(ctx)
+		if opErr != nil {
+			t.complete(ctx, op, StatusFailed, nil, opErr)
+			return
+		}
+		if result != nil {
+			t.complete(ctx, op, StatusSucceeded, result, nil)
+		}
+	}()
+}
+
+// Wait blocks until everything started with Go has finished, or until ctx is
+// done. Operations still running then stay recorded as running.
+func (t *Tracker) Wait(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Settle completes every running operation on a database once the database
+// has settled: successfully with result when opErr is nil, as failed
+// otherwise. Operations that wait for the database to become ready, such as
+// provisioning, are completed this way by the reconciler.
+func (t *Tracker) Settle(ctx context.Context, databaseID uuid.UUID, result map[string]any, opErr *Error) {
+	if t == nil {
+		return
+	}
+	running := StatusRunning
+	ops, err := t.repo.List(ctx, ListFilter{DatabaseID: &databaseID, Status: &running})
+	if err != nil {
+		slog.Error("failed to list running operations", "error", err, "database", databaseID)
+		return
+	}
+	for i := range ops {
+		if opErr != nil {
+			t.complete(ctx, &ops[i], StatusFailed, nil, opErr)
+		} else {
+			t.complete(ctx, &ops[i], StatusSucceeded, result, nil)
+		}
+	}
+}
+
+// Live reports whether a running DAAP process is still running op. This
+// process knows which operations it started; another process is live while
+// it heartbeats them. An operation started by an earlier process with the
+// same instance name, e.g. a restarted container, is not run by this one.
+// Operations that wait for the reconciler to settle them stay live while the
+// process that started them runs.
+func (t *Tracker) Live(op *Operation, now time.Time) bool {
+	if op.Instance == t.instance {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		_, ok := t.running[op.ID]
+		return ok
+	}
+	return now.Sub(op.RenewedAt) < 3*HeartbeatInterval
+}
+
+// Heartbeat records that the operations this process started are still
+// running, and forgets those that completed elsewhere, e.g. settled by the
+// reconciler of another process.
+func (t *Tracker) Heartbeat(ctx context.Context) error {
+	t.mu.Lock()
+	ids := make([]uuid.UUID, 0, len(t.running))
+	for id := range t.running {
+		ids = append(ids, id)
+	}
+	t.mu.Unlock()
+	if len(ids) == 0 {
+		return nil
+	}
+	renewed, err := t.repo.Renew(ctx, ids)
+	if err != nil {
+		return err
+	}
+	still := make(map[uuid.UUID]bool, len(renewed))
+	for _, id := range renewed {
+		still[id] = true
+	}
+	t.mu.Lock()
+	for _, id := range ids {
+		if !still[id] {
+			delete(t.running, id)
+		}
+	}
+	t.mu.Unlock()
+	return nil
+}
+
+// KeepAlive heartbeats the operations this process started every
+// HeartbeatInterval. It blocks until ctx is cancelled.
+func (t *Tracker) KeepAlive(ctx context.Context) {
+	ticker := time.NewTicker(HeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Heartbeat(ctx); err != nil {
+				slog.Error("failed to heartbeat operations", "error", err)
+			}
+		}
+	}
+}
+
+// Get returns an operation by ID.
+func (t *Tracker) Get(ctx context.Context, id uuid.UUID) (*Operation, error) {
+	return t.repo.GetByID(ctx, id)
+}
+
+// List returns operations matching the filter, newest first.
+func (t *Tracker) List(ctx context.Context, filter ListFilter) ([]Operation, error) {
+	return t.repo.List(ctx, filter)
+}
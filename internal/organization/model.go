@@ -0,0 +1,47 @@
+// Package organization groups teams into organizations, business units with
+// their own superadmins and database quota sharing a DAAP deployment.
+package organization
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Organization represents a row in the organizations table: a business unit
+// grouping teams, administered by its own superadmins.
+type Organization struct {
+	ID                  uuid.UUID
+	Name                string
+	MaxDatabases        int    // quota on the active databases of the organization's teams; 0 for no quota
+	QuotaWarningPercent int    // share of MaxDatabases from which new databases warn; 0 for no warning
+	CreatedBy           string // user name of the creator
+	UpdatedBy           string // user name of the last change
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// DefaultQuotaWarningPercent is the QuotaWarningPercent of organizations
+// created without one.
+const DefaultQuotaWarningPercent = 80
+
+// WarningThreshold returns the number of active databases from which the
+// organization is warned that it nears its quota, or 0 if it has no quota or
+// no warning.
+func (o *Organization) WarningThreshold() int {
+	if o.MaxDatabases == 0 || o.QuotaWarningPercent == 0 {
+		return 0
+	}
+	return (o.MaxDatabases*o.QuotaWarningPercent + 99) / 100
+}
+
+// UpdateFields holds updatable fields on an organization record. Nil fields
+// are not updated.
+type UpdateFields struct {
+	MaxDatabases        *int
+	QuotaWarningPercent *int
+
+	// UpdatedBy, when set, records who made the update. It is not an update
+	// on its own.
+	UpdatedBy string
+}
@@ -0,0 +1,139 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const columns = `id, name, max_databases, quota_warning_percent, created_by, updated_by, created_at, updated_at`
+
+// PostgresRepository implements Repository using pgxpool.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new Repository backed by the given connection pool.
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &PostgresRepository{pool: pool}
+}
+
+func scan(row pgx.Row) (*Organization, error) {
+	var o Organization
+	if err := row.Scan(&o.ID, &o.Name, &o.MaxDatabases, &o.QuotaWarningPercent, &o.CreatedBy, &o.UpdatedBy, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+// Create inserts a new organization record.
+func (r *PostgresRepository) Create(ctx context.Context, o *Organization) error {
+	query := `
+		INSERT INTO organizations (name, max_databases, quota_warning_percent, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $4)
+		RETURNING id, updated_by, created_at, updated_at`
+
+	err := r.pool.QueryRow(ctx, query, o.Name, o.MaxDatabases, o.QuotaWarningPercent, o.CreatedBy).Scan(&o.ID, &o.UpdatedBy, &o.CreatedAt, &o.UpdatedAt)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return ErrDuplicateName
+		}
+		return fmt.Errorf("inserting organization: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a single organization by its UUID.
+func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Organization, error) {
+	o, err := scan(r.pool.QueryRow(ctx, `SELECT `+columns+` FROM organizations WHERE id = $1`, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("querying organization: %w", err)
+	}
+	return o, nil
+}
+
+// GetByName retrieves a single organization by its name.
+func (r *PostgresRepository) GetByName(ctx context.Context, name string) (*Organization, error) {
+	o, err := scan(r.pool.QueryRow(ctx, `SELECT `+columns+` FROM organizations WHERE name = $1`, name))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("querying organization by name: %w", err)
+	}
+	return o, nil
+}
+
+// List retrieves all organizations ordered by creation time.
+func (r *PostgresRepository) List(ctx context.Context) ([]Organization, error) {
+	rows, err := r.pool.Query(ctx, `SELECT `+columns+` FROM organizations ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, fmt.Errorf("listing organizations: %w", err)
+	}
+	defer rows.Close()
+
+	orgs := []Organization{}
+	for rows.Next() {
+		o, err := scan(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning organization row: %w", err)
+		}
+		orgs = append(orgs, *o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating organization rows: %w", err)
+	}
+	return orgs, nil
+}
+
+// Update applies the non-nil fields to an organization and returns the
+// updated record.
+func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Organization, error) {
+	if fields.MaxDatabases == nil && fields.QuotaWarningPercent == nil {
+		return r.GetByID(ctx, id)
+	}
+
+	query := `
+		UPDATE organizations
+		SET max_databases = COALESCE($1, max_databases),
+		    quota_warning_percent = COALESCE($2, quota_warning_percent),
+		    updated_by = CASE WHEN $3 = '' THEN updated_by ELSE $3 END,
+		    updated_at = NOW()
+		WHERE id = $4
+		RETURNING ` + columns
+
+	o, err := scan(r.pool.QueryRow(ctx, query, fields.MaxDatabases, fields.QuotaWarningPercent, fields.UpdatedBy, id))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("updating organization: %w", err)
+	}
+	return o, nil
+}
+
+// Delete removes an organization by its UUID, and its superadmins with it.
+// Returns ErrHasTeams if teams or blueprints still belong to it (FK RESTRICT).
+func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, `DELETE FROM organizations WHERE id = $1`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return ErrHasTeams
+		}
+		return fmt.Errorf("deleting organization: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package organization
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/team"
+)
+
+// ErrQuotaExceeded is returned when a database would take an organization
+// past its MaxDatabases quota.
+var ErrQuotaExceeded = errors.New("organization database quota exceeded")
+
+// QuotaGate reports whether a team may create another database.
+type QuotaGate interface {
+	// CheckDatabase returns an error wrapping ErrQuotaExceeded if the team
+	// may not create another database. Otherwise it returns a warning,
+	// empty unless the database brings its organization near the quota.
+	CheckDatabase(ctx context.Context, teamID uuid.UUID) (string, error)
+}
+
+// Quotas implements QuotaGate with the database quotas of organizations.
+type Quotas struct {
+	orgs      Repository
+	teams     team.Repository
+	databases database.Repository
+	notifier  notify.Notifier
+}
+
+// NewQuotas creates a Quotas counting the databases in databases of the
+// teams in teams. Organizations reaching their warning threshold are
+// reported to notifier unless it is nil.
+func NewQuotas(orgs Repository, teams team.Repository, databases database.Repository, notifier notify.Notifier) *Quotas {
+	return &Quotas{orgs: orgs, teams: teams, databases: databases, notifier: notifier}
+}
+
+// CheckDatabase returns an error wrapping ErrQuotaExceeded if the
+// organization of teamID has a quota and its teams' active databases already
+// reach it, so one more may not be created. Teams outside organizations have
+// no quota. When one more database reaches the organization's warning
+// threshold, a warning is returned, and a QuotaWarning notification is sent
+// for the database crossing it. The check and the creation are not atomic:
+// concurrent creations may overshoot the quota by the number of requests
+// racing.
+func (q *Quotas) CheckDatabase(ctx context.Context, teamID uuid.UUID) (string, error) {
+	t, err := q.teams.GetByID(ctx, teamID)
+	if err != nil {
+		return "", fmt.Errorf("getting team: %w", err)
+	}
+	if t.OrganizationID == nil {
+		return "", nil
+	}
+	org, err := q.orgs.GetByID(ctx, *t.OrganizationID)
+	if err != nil {
+		return "", fmt.Errorf("getting organization: %w", err)
+	}
+	if org.MaxDatabases == 0 {
+		return "", nil
+	}
+
+	count, err := q.CountDatabases(ctx, org.ID)
+	if err != nil {
+		return "", err
+	}
+	if count >= org.MaxDatabases {
+		return "", fmt.Errorf("%w: organization %s has %d of %d databases", ErrQuotaExceeded, org.Name, count, org.MaxDatabases)
+	}
+
+	threshold := org.WarningThreshold()
+	if threshold == 0 || count+1 < threshold {
+		return "", nil
+	}
+	warning := fmt.Sprintf("organization %s will have %d of %d databases; creating databases fails once the quota is reached", org.Name, count+1, org.MaxDatabases)
+	if count+1 == threshold && q.notifier != nil {
+		n := notify.Notification{
+			Event:     "QuotaWarning",
+			Message:   warning,
+			OwnerTeam: t.Name,
+			Reason:    fmt.Sprintf("%d%% of the database quota of organization %s reached", org.QuotaWarningPercent, org.Name),
+			Time:      time.Now().UTC(),
+		}
+		if err := q.notifier.Notify(ctx, n); err != nil {
+			slog.Error("failed to send quota warning notification", "organization", org.Name, "error", err)
+		}
+	}
+	return warning, nil
+}
+
+// CountDatabases returns the number of active databases owned by the teams
+// of the organization with id.
+func (q *Quotas) CountDatabases(ctx context.Context, id uuid.UUID) (int, error) {
+	teams, err := q.teams.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing teams: %w", err)
+	}
+	count := 0
+	for _, t := range teams {
+		if t.OrganizationID == nil || *t.OrganizationID != id {
+			continue
+		}
+		// Only the total is needed, so fetch a single row.
+		result, err := q.databases.List(ctx, database.ListFilter{OwnerTeamID: &t.ID, Page: 1, Limit: 1})
+		if err != nil {
+			return 0, fmt.Errorf("counting databases of team %s: %w", t.Name, err)
+		}
+		count += result.Total
+	}
+	return count, nil
+}
@@ -0,0 +1,30 @@
+package organization
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrNotFound is returned when an organization record is not found.
+var ErrNotFound = errors.New("organization not found")
+
+// ErrDuplicateName is returned when an organization with the same name
+// already exists.
+var ErrDuplicateName = errors.New("organization name already exists")
+
+// ErrHasTeams is returned when attempting to delete an organization that
+// still has teams or blueprints.
+var ErrHasTeams = errors.New("organization has teams")
+
+// Repository provides CRUD operations on the organizations table. Deleting an
+// organization also deletes its superadmins.
+type Repository interface {
+	Create(ctx context.Context, org *Organization) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Organization, error)
+	GetByName(ctx context.Context, name string) (*Organization, error)
+	List(ctx context.Context) ([]Organization, error)
+	Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Organization, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
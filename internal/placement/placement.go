@@ -0,0 +1,187 @@
+// Package placement chooses the namespace of new databases among several
+// configured targets: the least loaded one relative to its capacity, under
+// tier affinity and team pinning constraints.
+package placement
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// ErrNoCapacity is returned when no namespace may take a database: every
+// namespace its constraints allow is full.
+var ErrNoCapacity = errors.New("no namespace can take the database")
+
+// Exclusion reasons of candidates.
+const (
+	ExcludedTierAffinity = "tier affinity"
+	ExcludedTeamPin      = "team pinning"
+	ExcludedFull         = "at capacity"
+)
+
+// Placer chooses the namespace of new databases.
+type Placer interface {
+	// Place returns the placement of a new database of team on tier, or an
+	// error wrapping ErrNoCapacity if no namespace may take it.
+	Place(ctx context.Context, team, tier string) (*database.Placement, error)
+}
+
+// Config lists the namespaces databases are placed in and the constraints on
+// them.
+type Config struct {
+	// Capacities maps each namespace to the most active databases it takes.
+	Capacities map[string]int
+	// TierAffinity restricts the databases of a tier, by name, to some of
+	// the namespaces. Tiers not listed may use any namespace.
+	TierAffinity map[string][]string
+	// TeamPins restricts the databases of a team, by name, to some of the
+	// namespaces. Teams not listed may use any namespace.
+	TeamPins map[string][]string
+}
+
+// Engine implements Placer, placing databases by the load of the namespaces in its Config.
+type Engine struct {
+	namespaces   []string
+	capacities   map[string]int
+	tierAffinity map[string][]string
+	teamPins     map[string][]string
+	databases    database.Repository
+}
+
+// New creates an Engine counting the active databases of each namespace in
+// databases. It returns an error if a namespace is not a valid name or has no
+// capacity, or a constraint names a namespace that is not configured.
+func New(databases database.Repository, cfg Config) (*Engine, error) {
+	e := &Engine{
+		capacities:   make(map[string]int, len(cfg.Capacities)),
+		tierAffinity: cfg.TierAffinity,
+		teamPins:     cfg.TeamPins,
+		databases:    databases,
+	}
+	for ns, capacity := range cfg.Capacities {
+		if errs := k8svalidation.IsDNS1123Label(ns); len(errs) > 0 {
+			return nil, fmt.Errorf("namespace %q is invalid: %s", ns, strings.Join(errs, "; "))
+		}
+		if capacity < 1 {
+			return nil, fmt.Errorf("namespace %s must take at least one database, not %d", ns, capacity)
+		}
+		e.namespaces = append(e.namespaces, ns)
+		e.capacities[ns] = capacity
+	}
+	sort.Strings(e.namespaces)
+
+	for kind, constraints := range map[string]map[string][]string{"tier": cfg.TierAffinity, "team": cfg.TeamPins} {
+		for name, namespaces := range constraints {
+			for _, ns := range namespaces {
+				if _, ok := e.capacities[ns]; !ok {
+					return nil, fmt.Errorf("%s %s is constrained to namespace %s, which is not a placement namespace", kind, name, ns)
+				}
+			}
+		}
+	}
+	return e, nil
+}
+
+// Place chooses the namespace of a new database of team on tier: among the
+// namespaces allowed by the tier's affinity and the team's pins that are not
+// full, the one with the lowest share of its capacity in use, the first by
+// name on a tie. The decision lists every namespace with its load and, for
+// those not eligible, why. It returns an error wrapping ErrNoCapacity when no
+// namespace is eligible.
+func (e *Engine) Place(ctx context.Context, team, tier string) (*database.Placement, error) {
+	p := &database.Placement{DecidedAt: time.Now().UTC()}
+	best := -1
+	for _, ns := range e.namespaces {
+		count, err := e.count(ctx, ns)
+		if err != nil {
+			return nil, err
+		}
+		c := database.PlacementCandidate{Namespace: ns, Databases: count, Capacity: e.capacities[ns]}
+		switch {
+		case !allows(e.tierAffinity, tier, ns):
+			c.Excluded = ExcludedTierAffinity
+		case !allows(e.teamPins, team, ns):
+			c.Excluded = ExcludedTeamPin
+		case count >= c.Capacity:
+			c.Excluded = ExcludedFull
+		case best < 0 || lessLoaded(c, p.Candidates[best]):
+			best = len(p.Candidates)
+		}
+		p.Candidates = append(p.Candidates, c)
+	}
+
+	if best < 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoCapacity, summarize(p.Candidates))
+	}
+	chosen := p.Candidates[best]
+	p.Namespace = chosen.Namespace
+	p.Reason = fmt.Sprintf("least loaded eligible namespace, with %d of %d databases", chosen.Databases, chosen.Capacity)
+	return p, nil
+}
+
+// count returns the number of active databases in namespace ns.
+func (e *Engine) count(ctx context.Context, ns string) (int, error) {
+	// Only the total is needed, so fetch a single row.
+	result, err := e.databases.List(ctx, database.ListFilter{Namespace: &ns, Page: 1, Limit: 1})
+	if err != nil {
+		return 0, fmt.Errorf("counting databases in namespace %s: %w", ns, err)
+	}
+	return result.Total, nil
+}
+
+// allows reports whether constraints let name use namespace ns: names
+// without constraints may use any namespace.
+func allows(constraints map[string][]string, name, ns string) bool {
+	namespaces, ok := constraints[name]
+	if !ok {
+		return true
+	}
+	for _, allowed := range namespaces {
+		if allowed == ns {
+			return true
+		}
+	}
+	return false
+}
+
+// lessLoaded reports whether a has a lower share of its capacity in use
+// than b.
+func lessLoaded(a, b database.PlacementCandidate) bool {
+	return a.Databases*b.Capacity < b.Databases*a.Capacity
+}
+
+// summarize describes why each candidate was excluded, for errors.
+func summarize(candidates []database.PlacementCandidate) string {
+	if len(candidates) == 0 {
+		return "no placement namespaces are configured"
+	}
+	parts := make([]string, len(candidates))
+	for i, c := range candidates {
+		parts[i] = fmt.Sprintf("%s: %s", c.Namespace, c.Excluded)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ParseConstraints splits the values of constraints into namespaces
+// separated by "|", as in PLACEMENT_TIER_AFFINITY=premium:db-fast-a|db-fast-b.
+func ParseConstraints(constraints map[string]string) map[string][]string {
+	parsed := make(map[string][]string, len(constraints))
+	for name, value := range constraints {
+		var namespaces []string
+		for _, ns := range strings.Split(value, "|") {
+			if ns = strings.TrimSpace(ns); ns != "" {
+				namespaces = append(namespaces, ns)
+			}
+		}
+		parsed[name] = namespaces
+	}
+	return parsed
+}
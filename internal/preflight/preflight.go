@@ -0,0 +1,249 @@
+// Package preflight verifies that the environment DAAP runs in is ready for
+// production use: CloudNativePG is installed, RBAC is sufficient, storage
+// classes exist and the platform database schema is current. It backs both
+// the `daap preflight` command and GET /admin/preflight.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/store"
+)
+
+// Status is the outcome of a single check.
+type Status string
+
+// Check statuses. Only StatusFail makes the report fail.
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+	StatusSkip Status = "skip"
+)
+
+// Check is the result of one preflight check.
+type Check struct {
+	Name    string   `json:"name"`
+	Status  Status   `json:"status"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+// Report is the result of a preflight run.
+type Report struct {
+	Passed bool    `json:"passed"`
+	Checks []Check `json:"checks"`
+}
+
+// ResourceDiscoverer lists the resources served for an API group version.
+type ResourceDiscoverer interface {
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+}
+
+// AccessChecker reviews DAAP's RBAC permissions per namespace.
+type AccessChecker interface {
+	CheckAccess(ctx context.Context, namespaces []string) ([]k8s.AccessResult, error)
+}
+
+// SchemaVersioner reports the applied platform database migration version.
+type SchemaVersioner interface {
+	SchemaVersion(ctx context.Context) (uint, bool, error)
+}
+
+// Runner runs the preflight checks. Nil Kubernetes dependencies make the
+// Kubernetes checks fail; a nil Schema makes the migration check fail.
+type Runner struct {
+	Discovery  ResourceDiscoverer
+	Access     AccessChecker
+	Dynamic    dynamic.Interface
+	Blueprints blueprint.Repository
+	Schema     SchemaVersioner
+	Namespaces []string
+	// ExpectedSchemaVersion is the latest migration shipped with this binary.
+	ExpectedSchemaVersion uint
+}
+
+// cnpgGroupVersion is the API group version CloudNativePG serves its CRDs under.
+const cnpgGroupVersion = "postgresql.cnpg.io/v1"
+
+// requiredCNPGResources are the CRDs DAAP's provider manages.
+var requiredCNPGResources = []string{"clusters", "poolers", "scheduledbackups"}
+
+var storageClassGVR = schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+
+// defaultStorageClassAnnotation marks the cluster's default StorageClass.
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// storageClassRef matches literal storageClass values in blueprint manifests.
+// Templated values ({{ ... }}) are not matched and cannot be checked.
+var storageClassRef = regexp.MustCompile(`(?m)^\s*storageClass:\s*["']?([a-z0-9][a-z0-9.-]*)["']?\s*$`)
+
+// Run executes every check and returns the report.
+func (r *Runner) Run(ctx context.Context) Report {
+	checks := []Check{
+		r.checkCRDs(),
+		r.checkRBAC(ctx),
+		r.checkStorageClasses(ctx),
+		CheckMigrations(ctx, r.Schema, r.ExpectedSchemaVersion),
+	}
+
+	passed := true
+	for _, c := range checks {
+		if c.Status == StatusFail {
+			passed = false
+		}
+	}
+	return Report{Passed: passed, Checks: checks}
+}
+
+func (r *Runner) checkCRDs() Check {
+	c := Check{Name: "cnpg-crds"}
+	if r.Discovery == nil {
+		return fail(c, "Kubernetes client is not configured")
+	}
+
+	list, err := r.Discovery.ServerResourcesForGroupVersion(cnpgGroupVersion)
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return fail(c, "CloudNativePG CRDs are not installed ("+cnpgGroupVersion+" is not served)")
+		}
+		return fail(c, fmt.Sprintf("discovering %s: %v", cnpgGroupVersion, err))
+	}
+
+	served := make(map[string]bool, len(list.APIResources))
+	for _, res := range list.APIResources {
+		served[res.Name] = true
+	}
+	for _, name := range requiredCNPGResources {
+		if !served[name] {
+			c.Details = append(c.Details, name+".postgresql.cnpg.io")
+		}
+	}
+	if len(c.Details) > 0 {
+		return fail(c, "CloudNativePG is installed but some CRDs are missing")
+	}
+	return pass(c, "CloudNativePG CRDs are installed")
+}
+
+func (r *Runner) checkRBAC(ctx context.Context) Check {
+	c := Check{Name: "rbac"}
+	if r.Access == nil {
+		return fail(c, "Kubernetes client is not configured")
+	}
+
+	results, err := r.Access.CheckAccess(ctx, r.Namespaces)
+	if err != nil {
+		return fail(c, fmt.Sprintf("reviewing access: %v", err))
+	}
+	for _, m := range k8s.MissingAccess(results) {
+		c.Details = append(c.Details, m.String())
+	}
+	if len(c.Details) > 0 {
+		return fail(c, fmt.Sprintf("%d of %d required permissions are missing", len(c.Details), len(results)))
+	}
+	return pass(c, fmt.Sprintf("all %d required permissions granted", len(results)))
+}
+
+func (r *Runner) checkStorageClasses(ctx context.Context) Check {
+	c := Check{Name: "storage-classes"}
+	if r.Dynamic == nil {
+		return fail(c, "Kubernetes client is not configured")
+	}
+
+	list, err := r.Dynamic.Resource(storageClassGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fail(c, fmt.Sprintf("listing storage classes: %v", err))
+	}
+	if len(list.Items) == 0 {
+		return fail(c, "no StorageClass exists in the cluster")
+	}
+
+	existing := make(map[string]bool, len(list.Items))
+	hasDefault := false
+	for _, item := range list.Items {
+		existing[item.GetName()] = true
+		if item.GetAnnotations()[defaultStorageClassAnnotation] == "true" {
+			hasDefault = true
+		}
+	}
+
+	if r.Blueprints != nil {
+		bps, err := r.Blueprints.List(ctx)
+		if err != nil {
+			return fail(c, fmt.Sprintf("listing blueprints: %v", err))
+		}
+		for _, bp := range bps {
+			for _, m := range storageClassRef.FindAllStringSubmatch(bp.Manifests, -1) {
+				if !existing[m[1]] {
+					c.Details = append(c.Details, fmt.Sprintf("blueprint %s references missing storage class %s", bp.Name, m[1]))
+				}
+			}
+		}
+		sort.Strings(c.Details)
+	}
+
+	if len(c.Details) > 0 {
+		return fail(c, "blueprints reference storage classes that do not exist")
+	}
+	if !hasDefault {
+		return warn(c, fmt.Sprintf("%d storage classes exist but none is the default; blueprints must set storageClass", len(existing)))
+	}
+	return pass(c, fmt.Sprintf("%d storage classes exist, including a default", len(existing)))
+}
+
+// CheckMigrations checks that the schema reported by schema is at the
+// expected version. A nil schema fails the check. It also backs the
+// migrations component of GET /health.
+func CheckMigrations(ctx context.Context, schema SchemaVersioner, expected uint) Check {
+	c := Check{Name: "migrations"}
+	if schema == nil {
+		return fail(c, "platform database is not configured")
+	}
+
+	version, dirty, err := schema.SchemaVersion(ctx)
+	if errors.Is(err, store.ErrSchemaNotTracked) {
+		c.Status = StatusSkip
+		c.Message = "storage backend has no migrated schema"
+		return c
+	}
+	if err != nil {
+		return fail(c, fmt.Sprintf("reading schema version: %v", err))
+	}
+
+	switch {
+	case dirty:
+		return fail(c, fmt.Sprintf("migration %d is dirty (partially applied); fix the schema and force the version", version))
+	case version < expected:
+		return fail(c, fmt.Sprintf("schema is at version %d, this build expects %d; run the pending migrations", version, expected))
+	case version > expected:
+		return warn(c, fmt.Sprintf("schema is at version %d, newer than this build (%d)", version, expected))
+	default:
+		return pass(c, fmt.Sprintf("schema is at version %d", version))
+	}
+}
+
+func pass(c Check, msg string) Check {
+	c.Status, c.Message = StatusPass, msg
+	return c
+}
+
+func warn(c Check, msg string) Check {
+	c.Status, c.Message = StatusWarn, msg
+	return c
+}
+
+func fail(c Check, msg string) Check {
+	c.Status, c.Message = StatusFail, msg
+	return c
+}
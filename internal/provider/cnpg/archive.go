@@ -0,0 +1,115 @@
+package cnpg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+var backupsGVR = schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "backups"}
+
+var _ provider.Archiver = (*CNPGProvider)(nil)
+
+// archiveBackupName returns the name of the Backup holding a database's
+// archive.
+func archiveBackupName(db provider.ProviderDatabase) string {
+	return db.ClusterName + "-archive"
+}
+
+// Archive takes a barman backup of the Cluster into location. The Cluster's
+// object store, which the blueprint configures along with its credentials,
+// is pointed at <location>/<namespace> first, so the backup lands under
+// <location>/<namespace>/<cluster>. The Backup carries the DAAP labels and
+// is deleted with the other resources; the backup itself stays in the object
+// store. A failed Backup is deleted, so that calling Archive again retries.
+func (p *CNPGProvider) Archive(ctx context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error) {
+	name := archiveBackupName(db)
+	backup, err := p.client.Resource(backupsGVR).Namespace(db.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if k8serrors.IsNotFound(err) {
+		return p.startArchive(ctx, db, location)
+	}
+	if err != nil {
+		return provider.Archive{}, fmt.Errorf("getting backup %s/%s: %w", db.Namespace, name, err)
+	}
+
+	phase, _, _ := unstructured.NestedString(backup.Object, "status", "phase")
+	switch phase {
+	case "completed":
+		return provider.Archive{URL: backupURL(backup), Done: true}, nil
+	case "failed":
+		// The failed Backup is removed so that the next call takes a new one.
+		reason, _, _ := unstructured.NestedString(backup.Object, "status", "error")
+		err := p.client.Resource(backupsGVR).Namespace(db.Namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		if err != nil && !k8serrors.IsNotFound(err) {
+			return provider.Archive{}, fmt.Errorf("backup %s/%s failed: %s; deleting it: %w", db.Namespace, name, reason, err)
+		}
+		return provider.Archive{}, fmt.Errorf("backup %s/%s failed: %s", db.Namespace, name, reason)
+	}
+	destination, _, _ := unstructured.NestedString(backup.Object, "status", "destinationPath")
+	return provider.Archive{URL: destination}, nil
+}
+
+func (p *CNPGProvider) startArchive(ctx context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error) {
+	cluster, err := p.client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	if err != nil {
+		return provider.Archive{}, fmt.Errorf("getting cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	if _, ok, _ := unstructured.NestedMap(cluster.Object, "spec", "backup", "barmanObjectStore"); !ok {
+		return provider.Archive{}, fmt.Errorf("cluster %s/%s has no object store to archive to", db.Namespace, db.ClusterName)
+	}
+
+	destination := strings.TrimSuffix(location, "/") + "/" + db.Namespace
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{
+			"backup": map[string]any{
+				"barmanObjectStore": map[string]any{"destinationPath": destination},
+			},
+		},
+	})
+	if err != nil {
+		return provider.Archive{}, fmt.Errorf("encoding archive destination patch: %w", err)
+	}
+	_, err = p.client.Resource(clustersGVR).Namespace(db.Namespace).Patch(
+		ctx, db.ClusterName, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager},
+	)
+	if err != nil {
+		return provider.Archive{}, fmt.Errorf("pointing cluster %s/%s at %s: %w", db.Namespace, db.ClusterName, destination, err)
+	}
+
+	backup := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Backup",
+		"metadata": map[string]any{
+			"name":      archiveBackupName(db),
+			"namespace": db.Namespace,
+		},
+		"spec": map[string]any{
+			"cluster": map[string]any{"name": db.ClusterName},
+			"method":  "barmanObjectStore",
+		},
+	}}
+	injectLabels(backup, db)
+	_, err = p.client.Resource(backupsGVR).Namespace(db.Namespace).Create(ctx, backup, metav1.CreateOptions{FieldManager: FieldManager})
+	if err != nil && !k8serrors.IsAlreadyExists(err) {
+		return provider.Archive{}, fmt.Errorf("creating backup %s/%s: %w", db.Namespace, archiveBackupName(db), err)
+	}
+	return provider.Archive{URL: destination}, nil
+}
+
+// backupURL returns where barman stored a completed backup:
+// <destinationPath>/<serverName>/base/<backupId>.
+func backupURL(backup *unstructured.Unstructured) string {
+	destination, _, _ := unstructured.NestedString(backup.Object, "status", "destinationPath")
+	server, _, _ := unstructured.NestedString(backup.Object, "status", "serverName")
+	id, _, _ := unstructured.NestedString(backup.Object, "status", "backupId")
+	return strings.TrimSuffix(destination, "/") + "/" + server + "/base/" + id
+}
@@ -0,0 +1,138 @@
+package cnpg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// annotationFencedInstances is the Cluster annotation listing the instances
+// the CNPG operator keeps fenced: shut down and not accepting connections.
+// "*" fences every instance.
+const annotationFencedInstances = "cnpg.io/fencedInstances"
+
+var _ provider.Cloner = (*CNPGProvider)(nil)
+
+// Clone applies db's manifests with its Cluster turned into a replica
+// cluster of source's: bootstrapped with pg_basebackup from source's
+// primary, and streaming from it afterwards, over TLS with source's
+// streaming_replica certificate. Whatever bootstrap the blueprint sets is
+// replaced, keeping the application database and owner its initdb names.
+// Both Clusters are in the same namespace, where the replica reads source's
+// replication and CA secrets.
+func (p *CNPGProvider) Clone(ctx context.Context, db, source provider.ProviderDatabase, manifests string) error {
+	if db.Namespace != source.Namespace {
+		return fmt.Errorf("cloning %s/%s into namespace %s: %w", source.Namespace, source.ClusterName, db.Namespace, provider.ErrNotSupported)
+	}
+	return p.applyWith(ctx, db, manifests, func(obj *unstructured.Unstructured) {
+		if obj.GetKind() == "Cluster" && obj.GroupVersionKind().Group == clustersGVR.Group && obj.GetName() == db.ClusterName {
+			replicateFrom(obj, source)
+		}
+	})
+}
+
+// replicateFrom makes cluster a replica cluster of source.
+func replicateFrom(cluster *unstructured.Unstructured, source provider.ProviderDatabase) {
+	name := source.ClusterName
+	bootstrap(cluster, "pg_basebackup", map[string]any{"source": name})
+	_ = unstructured.SetNestedField(cluster.Object, map[string]any{"enabled": true, "source": name}, "spec", "replica")
+
+	addExternalCluster(cluster, map[string]any{
+		"name": name,
+		"connectionParameters": map[string]any{
+			"host":    fmt.Sprintf("%s-rw.%s.svc", name, source.Namespace),
+			"user":    "streaming_replica",
+			"sslmode": "verify-full",
+			"dbname":  "postgres",
+		},
+		"sslKey":      map[string]any{"name": name + "-replication", "key": "tls.key"},
+		"sslCert":     map[string]any{"name": name + "-replication", "key": "tls.crt"},
+		"sslRootCert": map[string]any{"name": name + "-ca", "key": "ca.crt"},
+	})
+}
+
+// bootstrap replaces the bootstrap of cluster with method, configured by
+// spec, keeping the application database and owner of its initdb.
+func bootstrap(cluster *unstructured.Unstructured, method string, spec map[string]any) {
+	for _, field := range []string{"database", "owner"} {
+		if value, ok, _ := unstructured.NestedString(cluster.Object, "spec", "bootstrap", "initdb", field); ok {
+			spec[field] = value
+		}
+	}
+	_ = unstructured.SetNestedField(cluster.Object, map[string]any{method: spec}, "spec", "bootstrap")
+}
+
+// addExternalCluster adds external to the external clusters of cluster, in
+// place of any of the same name.
+func addExternalCluster(cluster *unstructured.Unstructured, external map[string]any) {
+	clusters, _, _ := unstructured.NestedSlice(cluster.Object, "spec", "externalClusters")
+	kept := []any{external}
+	for _, c := range clusters {
+		if m, ok := c.(map[string]any); ok && m["name"] == external["name"] {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	_ = unstructured.SetNestedSlice(cluster.Object, kept, "spec", "externalClusters")
+}
+
+// Promote fences every instance of source's Cluster, which shuts them down
+// once they have sent their last changes to the replica, then turns db's
+// Cluster from a replica cluster into a primary one. Source stays fenced
+// until it is deleted, or until Unfence lifts the fence.
+func (p *CNPGProvider) Promote(ctx context.Context, db, source provider.ProviderDatabase) error {
+	fence, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{annotationFencedInstances: `["*"]`},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding fencing patch: %w", err)
+	}
+	_, err = p.client.Resource(clustersGVR).Namespace(source.Namespace).Patch(
+		ctx, source.ClusterName, types.MergePatchType, fence, metav1.PatchOptions{FieldManager: FieldManager},
+	)
+	if err != nil {
+		return fmt.Errorf("fencing cluster %s/%s: %w", source.Namespace, source.ClusterName, err)
+	}
+
+	promote, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"replica": map[string]any{"enabled": false}},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding promotion patch: %w", err)
+	}
+	_, err = p.client.Resource(clustersGVR).Namespace(db.Namespace).Patch(
+		ctx, db.ClusterName, types.MergePatchType, promote, metav1.PatchOptions{FieldManager: FieldManager},
+	)
+	if err != nil {
+		return fmt.Errorf("promoting cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	return nil
+}
+
+// Unfence removes the fencing annotation from source's Cluster, so the
+// operator starts its instances again.
+func (p *CNPGProvider) Unfence(ctx context.Context, source provider.ProviderDatabase) error {
+	unfence, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{annotationFencedInstances: nil},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding unfencing patch: %w", err)
+	}
+	_, err = p.client.Resource(clustersGVR).Namespace(source.Namespace).Patch(
+		ctx, source.ClusterName, types.MergePatchType, unfence, metav1.PatchOptions{FieldManager: FieldManager},
+	)
+	if err != nil {
+		return fmt.Errorf("unfencing cluster %s/%s: %w", source.Namespace, source.ClusterName, err)
+	}
+	return nil
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
+	"unicode"
 
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,6 +16,8 @@ import (
 	sigsyaml "sigs.k8s.io/yaml"
 
 	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/requestid"
+	"github.com/daap14/daap/internal/secrets"
 )
 
 // Known CNPG-related GVRs for label-based deletion scanning.
@@ -21,23 +25,54 @@ var knownGVRs = []schema.GroupVersionResource{
 	{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"},
 	{Group: "postgresql.cnpg.io", Version: "v1", Resource: "poolers"},
 	{Group: "postgresql.cnpg.io", Version: "v1", Resource: "scheduledbackups"},
+	{Group: "postgresql.cnpg.io", Version: "v1", Resource: "backups"},
 	{Group: "", Version: "v1", Resource: "configmaps"},
+	{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"},
 }
 
 // CNPGProvider implements the Provider interface for CloudNativePG.
 type CNPGProvider struct {
-	client dynamic.Interface
+	client           dynamic.Interface
+	volumeStats      VolumeStats
+	replicationStats ReplicationStats
+	poolerStats      PoolerStats
+	podLogs          PodLogs
+	secrets          secrets.Store
+
+	internalLBAnnotations map[string]string
 }
 
+// Option configures a CNPGProvider.
+type Option func(*CNPGProvider)
+
 // New creates a new CNPG provider with the given dynamic K8s client.
-func New(client dynamic.Interface) *CNPGProvider {
-	return &CNPGProvider{client: client}
+func New(client dynamic.Interface, opts ...Option) *CNPGProvider {
+	p := &CNPGProvider{client: client}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
 }
 
-// Apply renders the blueprint manifests with the database context,
-// injects mandatory labels, and creates or updates each K8s resource.
+// Apply renders the blueprint manifests with the database context and the
+// secrets they reference, injects mandatory labels, the tier's topology and
+// disruption policy, the database's pinned images, exposure and DNS name and
+// the request ID from ctx, and creates or updates each K8s resource. A
+// Cluster whose tier keeps instances available gets a PodDisruptionBudget
+// next to it, which is deleted once the tier stops. Nothing is applied if
+// the database's exposure cannot be rendered.
 func (p *CNPGProvider) Apply(ctx context.Context, db provider.ProviderDatabase, manifests string) error {
-	rendered, err := renderManifests(manifests, db)
+	return p.applyWith(ctx, db, manifests, nil)
+}
+
+// applyWith applies manifests like Apply, calling mutate, when set, on each
+// object after the database's settings are applied to it.
+func (p *CNPGProvider) applyWith(ctx context.Context, db provider.ProviderDatabase, manifests string, mutate func(*unstructured.Unstructured)) error {
+	secretValues, err := secrets.Resolve(ctx, p.secrets, manifests)
+	if err != nil {
+		return fmt.Errorf("resolving secrets for %s: %w", db.Name, err)
+	}
+	rendered, err := renderManifests(manifests, db, secretValues)
 	if err != nil {
 		return fmt.Errorf("rendering manifests for %s: %w", db.Name, err)
 	}
@@ -47,18 +82,38 @@ func (p *CNPGProvider) Apply(ctx context.Context, db provider.ProviderDatabase,
 		return fmt.Errorf("blueprint manifests for %s produced no documents", db.Name)
 	}
 
+	objs := make([]*unstructured.Unstructured, len(docs))
 	for i, doc := range docs {
 		obj, err := parseUnstructured(doc)
 		if err != nil {
 			return fmt.Errorf("parsing document %d for %s: %w", i, db.Name, err)
 		}
 
-		injectLabels(obj, db.Name)
+		injectLabels(obj, db)
+		applyTopology(obj, db.Topology)
+		applyDisruption(obj, db.Disruption)
+		replaceImages(obj, db.Images)
+		p.applyExposure(obj, db)
+		applyDNSName(obj, db)
+		annotateRequest(obj, requestid.From(ctx))
+		if mutate != nil {
+			mutate(obj)
+		}
+		objs[i] = obj
+	}
+	if err := p.checkExposure(objs, db); err != nil {
+		return err
+	}
 
+	for i, obj := range objs {
 		if err := p.apply(ctx, obj); err != nil {
 			return fmt.Errorf("applying document %d (%s/%s) for %s: %w",
 				i, obj.GetKind(), obj.GetName(), db.Name, err)
 		}
+		if err := p.applyBudget(ctx, obj, db.Disruption); err != nil {
+			return fmt.Errorf("applying document %d (%s/%s) for %s: %w",
+				i, obj.GetKind(), obj.GetName(), db.Name, err)
+		}
 	}
 
 	return nil
@@ -67,6 +122,13 @@ func (p *CNPGProvider) Apply(ctx context.Context, db provider.ProviderDatabase,
 // Delete removes all K8s resources labeled with daap.io/database={name}
 // in the database's namespace, scanning known CNPG GVRs.
 func (p *CNPGProvider) Delete(ctx context.Context, db provider.ProviderDatabase) error {
+	p.deleteLabeled(ctx, db, metav1.DeleteOptions{})
+	return nil
+}
+
+// deleteLabeled deletes the database's labeled resources with opts. Failures
+// are logged and the remaining resources are still deleted.
+func (p *CNPGProvider) deleteLabeled(ctx context.Context, db provider.ProviderDatabase, opts metav1.DeleteOptions) {
 	labelSelector := fmt.Sprintf("%s=%s", labelDatabase, db.Name)
 
 	for _, gvr := range knownGVRs {
@@ -84,7 +146,7 @@ func (p *CNPGProvider) Delete(ctx context.Context, db provider.ProviderDatabase)
 
 		for _, item := range list.Items {
 			err := p.client.Resource(gvr).Namespace(db.Namespace).Delete(
-				ctx, item.GetName(), metav1.DeleteOptions{},
+				ctx, item.GetName(), opts,
 			)
 			if err != nil && !k8serrors.IsNotFound(err) {
 				slog.Warn("cnpg provider: failed to delete resource",
@@ -93,11 +155,11 @@ func (p *CNPGProvider) Delete(ctx context.Context, db provider.ProviderDatabase)
 			}
 		}
 	}
-
-	return nil
 }
 
-// CheckHealth reads the CNPG Cluster status and maps it to a HealthResult.
+// CheckHealth reads the CNPG Cluster status and maps it, with the Cluster's
+// instances and, for an exposed database, the address of its load balancer,
+// to a HealthResult.
 func (p *CNPGProvider) CheckHealth(ctx context.Context, db provider.ProviderDatabase) (provider.HealthResult, error) {
 	clusterGVR := schema.GroupVersionResource{
 		Group:    "postgresql.cnpg.io",
@@ -113,24 +175,53 @@ func (p *CNPGProvider) CheckHealth(ctx context.Context, db provider.ProviderData
 	}
 
 	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	phaseReason, _, _ := unstructured.NestedString(obj.Object, "status", "phaseReason")
+	instances := p.instanceStatus(ctx, obj)
 
 	if phase == "Cluster in healthy state" {
 		host := db.PoolerName + "." + db.Namespace + ".svc.cluster.local"
 		port := 5432
 		secretName := db.ClusterName + "-app"
 		return provider.HealthResult{
-			Status:     "ready",
-			Host:       &host,
-			Port:       &port,
-			SecretName: &secretName,
+			Status:       "ready",
+			Host:         &host,
+			Port:         &port,
+			SecretName:   &secretName,
+			Instances:    instances,
+			ExternalHost: p.externalHost(ctx, db),
 		}, nil
 	}
 
 	if isFailedPhase(phase) {
-		return provider.HealthResult{Status: "error"}, nil
+		return provider.HealthResult{
+			Status:    "error",
+			Instances: instances,
+			Reason:    phaseCode(phase),
+			Message:   phaseMessage(phase, phaseReason),
+		}, nil
 	}
 
-	return provider.HealthResult{Status: "provisioning"}, nil
+	return provider.HealthResult{Status: "provisioning", Instances: instances}, nil
+}
+
+// phaseCode turns a CNPG cluster phase into a status reason, e.g.
+// "Failed to create primary" into FAILED_TO_CREATE_PRIMARY.
+func phaseCode(phase string) string {
+	return strings.ToUpper(strings.Join(strings.FieldsFunc(phase, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "_"))
+}
+
+// phaseMessage describes a CNPG cluster phase with the operator's detail of
+// it, e.g. "Failed to create primary: storageclass not found".
+func phaseMessage(phase, phaseReason string) string {
+	switch {
+	case phaseReason == "":
+		return phase
+	case phase == "":
+		return phaseReason
+	}
+	return phase + ": " + phaseReason
 }
 
 // isFailedPhase determines whether a CNPG cluster phase indicates failure.
@@ -140,7 +231,7 @@ func (p *CNPGProvider) CheckHealth(ctx context.Context, db provider.ProviderData
 func isFailedPhase(phase string) bool {
 	switch phase {
 	// Healthy or transient phases — not failed.
-	case "Setting up primary", "Creating primary", "Cluster in healthy state":
+	case "Setting up primary", "Creating primary", "Cluster in healthy state", phaseSwitchover:
 		return false
 	// Terminal failure phases.
 	case "Failed", "Error",
@@ -168,8 +259,16 @@ func parseUnstructured(yamlDoc string) (*unstructured.Unstructured, error) {
 	return &unstructured.Unstructured{Object: obj}, nil
 }
 
-// apply creates a K8s resource; if it already exists, updates it.
-// This reuses the same pattern as internal/k8s/manager.go:apply().
+// FieldManager identifies DAAP in the managedFields of the resources it
+// applies and patches.
+const FieldManager = "daap"
+
+// apply server-side applies a K8s resource as FieldManager, creating it if it
+// does not exist. Only the fields the blueprint declares are sent, so fields
+// set by the CNPG operator or other managers are left alone and re-applying
+// the same manifests is a no-op. When another manager owns a field the
+// blueprint declares, the blueprint wins: the conflict is logged and the
+// apply is forced.
 func (p *CNPGProvider) apply(ctx context.Context, obj *unstructured.Unstructured) error {
 	gvr, err := gvrFromObject(obj)
 	if err != nil {
@@ -180,27 +279,34 @@ func (p *CNPGProvider) apply(ctx context.Context, obj *unstructured.Unstructured
 	name := obj.GetName()
 	resource := p.client.Resource(gvr).Namespace(namespace)
 
-	_, err = resource.Create(ctx, obj, metav1.CreateOptions{})
+	_, err = resource.Apply(ctx, name, obj, metav1.ApplyOptions{FieldManager: FieldManager})
 	if err == nil {
 		return nil
 	}
-
-	if !k8serrors.IsAlreadyExists(err) {
-		return fmt.Errorf("creating %s %s/%s: %w", gvr.Resource, namespace, name, err)
+	if !k8serrors.IsConflict(err) {
+		return fmt.Errorf("applying %s %s/%s: %w", gvr.Resource, namespace, name, err)
 	}
 
-	existing, err := resource.Get(ctx, name, metav1.GetOptions{})
+	slog.Warn("cnpg provider: taking over fields owned by another manager",
+		"gvr", gvr.Resource, "namespace", namespace, "name", name, "conflicts", conflictingFields(err))
+	_, err = resource.Apply(ctx, name, obj, metav1.ApplyOptions{FieldManager: FieldManager, Force: true})
 	if err != nil {
-		return fmt.Errorf("getting existing %s %s/%s: %w", gvr.Resource, namespace, name, err)
+		return fmt.Errorf("force-applying %s %s/%s: %w", gvr.Resource, namespace, name, err)
 	}
+	return nil
+}
 
-	obj.SetResourceVersion(existing.GetResourceVersion())
-	_, err = resource.Update(ctx, obj, metav1.UpdateOptions{})
-	if err != nil {
-		return fmt.Errorf("updating %s %s/%s: %w", gvr.Resource, namespace, name, err)
+// conflictingFields lists the fields and managers named by an apply conflict.
+func conflictingFields(err error) []string {
+	status, ok := err.(k8serrors.APIStatus)
+	if !ok || status.Status().Details == nil {
+		return nil
 	}
-
-	return nil
+	var fields []string
+	for _, cause := range status.Status().Details.Causes {
+		fields = append(fields, cause.Field+": "+cause.Message)
+	}
+	return fields
 }
 
 // gvrFromObject derives a GroupVersionResource from an unstructured object's
@@ -225,4 +331,5 @@ var kindToGVR = map[string]schema.GroupVersionResource{
 	"v1/ConfigMap":                          {Group: "", Version: "v1", Resource: "configmaps"},
 	"v1/Secret":                             {Group: "", Version: "v1", Resource: "secrets"},
 	"monitoring.coreos.com/v1/PodMonitor":   {Group: "monitoring.coreos.com", Version: "v1", Resource: "podmonitors"},
+	"policy/v1/PodDisruptionBudget":         {Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"},
 }
@@ -0,0 +1,118 @@
+package cnpg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/secrets"
+)
+
+// podMetricsGVR is served by metrics-server.
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+var errNoClusterResources = errors.New("no Cluster with resource requests in manifests")
+
+var _ provider.ComputeReporter = (*CNPGProvider)(nil)
+
+// ComputeUsage returns the Cluster's resource requests and the CPU and memory
+// of its busiest instance, as reported by metrics-server.
+func (p *CNPGProvider) ComputeUsage(ctx context.Context, db provider.ProviderDatabase) (provider.ComputeUsage, error) {
+	cluster, err := p.client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	if err != nil {
+		return provider.ComputeUsage{}, fmt.Errorf("getting cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	requests, _, _ := unstructured.NestedStringMap(cluster.Object, "spec", "resources", "requests")
+
+	metrics, err := p.client.Resource(podMetricsGVR).Namespace(db.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "cnpg.io/cluster=" + db.ClusterName + ",cnpg.io/podRole=instance",
+	})
+	if err != nil {
+		return provider.ComputeUsage{}, fmt.Errorf("listing pod metrics of cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	if len(metrics.Items) == 0 {
+		return provider.ComputeUsage{}, fmt.Errorf("no pod metrics for cluster %s/%s", db.Namespace, db.ClusterName)
+	}
+
+	usage := provider.ComputeUsage{Requests: toComputeResources(requests)}
+	for _, pm := range metrics.Items {
+		var pod provider.ComputeResources
+		containers, _, _ := unstructured.NestedSlice(pm.Object, "containers")
+		for _, c := range containers {
+			m, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			u, _, _ := unstructured.NestedStringMap(m, "usage")
+			cu := toComputeResources(u)
+			pod.CPUMillis += cu.CPUMillis
+			pod.MemoryBytes += cu.MemoryBytes
+		}
+		usage.Used.CPUMillis = max(usage.Used.CPUMillis, pod.CPUMillis)
+		usage.Used.MemoryBytes = max(usage.Used.MemoryBytes, pod.MemoryBytes)
+	}
+	return usage, nil
+}
+
+// ManifestCompute renders blueprint manifests with placeholder values and
+// returns the resource requests of their Cluster.
+func (p *CNPGProvider) ManifestCompute(manifests string) (provider.ComputeResources, error) {
+	cluster, err := manifestCluster(manifests)
+	if err != nil {
+		return provider.ComputeResources{}, err
+	}
+	if cluster == nil {
+		return provider.ComputeResources{}, errNoClusterResources
+	}
+	requests, _, _ := unstructured.NestedStringMap(cluster.Object, "spec", "resources", "requests")
+	if len(requests) == 0 {
+		return provider.ComputeResources{}, errNoClusterResources
+	}
+	return toComputeResources(requests), nil
+}
+
+// manifestCluster renders blueprint manifests with placeholder values and
+// returns their first Cluster, or nil if they have none.
+func manifestCluster(manifests string) (*unstructured.Unstructured, error) {
+	rendered, err := renderManifests(manifests, provider.ProviderDatabase{
+		ID:          uuid.Nil,
+		Name:        "example",
+		Namespace:   "default",
+		ClusterName: "daap-example",
+		PoolerName:  "daap-example-pooler",
+	}, secrets.Placeholders(manifests))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, doc := range splitYAMLDocuments(rendered) {
+		obj, err := parseUnstructured(doc)
+		if err != nil {
+			return nil, err
+		}
+		if obj.GetKind() == "Cluster" && obj.GroupVersionKind().Group == clustersGVR.Group {
+			return obj, nil
+		}
+	}
+	return nil, nil
+}
+
+// toComputeResources reads cpu and memory from a Kubernetes resource list.
+// Missing or malformed quantities count as zero.
+func toComputeResources(list map[string]string) provider.ComputeResources {
+	var r provider.ComputeResources
+	if q, err := resource.ParseQuantity(list["cpu"]); err == nil {
+		r.CPUMillis = q.MilliValue()
+	}
+	if q, err := resource.ParseQuantity(list["memory"]); err == nil {
+		r.MemoryBytes = q.Value()
+	}
+	return r
+}
@@ -0,0 +1,43 @@
+package cnpg
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// defaultMaxConnections is PostgreSQL's max_connections when the Cluster does
+// not set it.
+const defaultMaxConnections = 100
+
+var errNoCluster = errors.New("no Cluster in manifests")
+
+var _ provider.ConnectionReporter = (*CNPGProvider)(nil)
+
+// ManifestConnections renders blueprint manifests with placeholder values and
+// returns the max_connections of their Cluster, from
+// spec.postgresql.parameters or PostgreSQL's default of 100. Replicas accept
+// as many, but only the primary's count: clients of the pooler reach the
+// primary.
+func (p *CNPGProvider) ManifestConnections(manifests string) (int, error) {
+	cluster, err := manifestCluster(manifests)
+	if err != nil {
+		return 0, err
+	}
+	if cluster == nil {
+		return 0, errNoCluster
+	}
+	value, found, _ := unstructured.NestedString(cluster.Object, "spec", "postgresql", "parameters", "max_connections")
+	if !found {
+		return defaultMaxConnections, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("invalid max_connections %q in Cluster", value)
+	}
+	return n, nil
+}
@@ -0,0 +1,69 @@
+package cnpg
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+var _ provider.CredentialRotator = (*CNPGProvider)(nil)
+
+// RotateCredentials writes a new random password to the Cluster's
+// application secret, along with the connection strings CNPG derived from
+// the old one (uri, jdbc-uri, pgpass...). The CNPG operator notices the
+// changed secret and sets the new password on the application role.
+func (p *CNPGProvider) RotateCredentials(ctx context.Context, db provider.ProviderDatabase) error {
+	cluster, err := p.client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("getting cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	name := appSecretName(cluster)
+	secret, err := p.client.Resource(secretsGVR).Namespace(db.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("getting secret %s/%s: %w", db.Namespace, name, err)
+	}
+
+	data, _, _ := unstructured.NestedStringMap(secret.Object, "data")
+	old, err := base64.StdEncoding.DecodeString(data["password"])
+	if err != nil || len(old) == 0 {
+		return fmt.Errorf("secret %s/%s holds no password", db.Namespace, name)
+	}
+	password := rand.Text()
+	for key, value := range data {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			continue
+		}
+		data[key] = base64.StdEncoding.EncodeToString([]byte(strings.ReplaceAll(string(decoded), string(old), password)))
+	}
+	if err := unstructured.SetNestedStringMap(secret.Object, data, "data"); err != nil {
+		return fmt.Errorf("setting secret data: %w", err)
+	}
+
+	_, err = p.client.Resource(secretsGVR).Namespace(db.Namespace).Update(ctx, secret, metav1.UpdateOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("updating secret %s/%s: %w", db.Namespace, name, err)
+	}
+	return nil
+}
+
+// appSecretName returns the secret holding the credentials of a Cluster's
+// application user: the one its bootstrap names, or the one CNPG generates.
+func appSecretName(cluster *unstructured.Unstructured) string {
+	for _, method := range []string{"initdb", "recovery", "pg_basebackup"} {
+		if name, _, _ := unstructured.NestedString(cluster.Object, "spec", "bootstrap", method, "secret", "name"); name != "" {
+			return name
+		}
+	}
+	return cluster.GetName() + "-app"
+}
@@ -0,0 +1,64 @@
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// deletionPollInterval is how often DeleteForeground checks whether the
+// deleted resources are gone.
+const deletionPollInterval = time.Second
+
+// DeleteForeground deletes the database's labeled resources with foreground
+// propagation, so a Cluster is only removed after its instances and volumes,
+// and waits up to wait for all of them to disappear. Resources whose
+// finalizers are still running when wait elapses are reported as
+// provider.DeletionDeleting.
+func (p *CNPGProvider) DeleteForeground(ctx context.Context, db provider.ProviderDatabase, wait time.Duration) (provider.DeletionState, error) {
+	propagation := metav1.DeletePropagationForeground
+	p.deleteLabeled(ctx, db, metav1.DeleteOptions{PropagationPolicy: &propagation})
+
+	deadline := time.Now().Add(wait)
+	for {
+		remaining, err := p.remaining(ctx, db)
+		if err != nil {
+			return "", err
+		}
+		if remaining == 0 {
+			return provider.DeletionGone, nil
+		}
+		if !time.Now().Before(deadline) {
+			return provider.DeletionDeleting, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-time.After(deletionPollInterval):
+		}
+	}
+}
+
+// remaining counts the database's labeled resources that still exist.
+func (p *CNPGProvider) remaining(ctx context.Context, db provider.ProviderDatabase) (int, error) {
+	labelSelector := fmt.Sprintf("%s=%s", labelDatabase, db.Name)
+	n := 0
+	for _, gvr := range knownGVRs {
+		list, err := p.client.Resource(gvr).Namespace(db.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector,
+		})
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				continue
+			}
+			return 0, fmt.Errorf("listing %s of %s: %w", gvr.Resource, db.Name, err)
+		}
+		n += len(list.Items)
+	}
+	return n, nil
+}
@@ -0,0 +1,175 @@
+package cnpg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	sigsyaml "sigs.k8s.io/yaml"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+var eventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+const (
+	// maxEvents is the number of most recent events kept in diagnostics.
+	maxEvents = 100
+	// logTailLines is the number of log lines kept per instance.
+	logTailLines = 500
+	// postgresContainer is the container running PostgreSQL and the CNPG
+	// instance manager in an instance pod.
+	postgresContainer = "postgres"
+)
+
+// PodLogs reads the logs of a pod's container. k8s.Client implements it.
+type PodLogs interface {
+	PodLogs(ctx context.Context, namespace, pod, container string, tailLines int64) ([]byte, error)
+}
+
+// WithPodLogs makes Diagnostics include the last logs of each instance,
+// reading them from pl. Without it, logs are left out.
+func WithPodLogs(pl PodLogs) Option {
+	return func(p *CNPGProvider) {
+		p.podLogs = pl
+	}
+}
+
+var _ provider.Diagnoser = (*CNPGProvider)(nil)
+
+// Diagnostics gathers the Cluster with its status (cluster.yaml), the most
+// recent events about the Cluster and the objects named after it or its
+// Pooler, such as instance pods and their volumes (events.yaml), and the last
+// log lines of each instance (logs/<pod>.log).
+func (p *CNPGProvider) Diagnostics(ctx context.Context, db provider.ProviderDatabase) ([]provider.DiagnosticFile, error) {
+	var files []provider.DiagnosticFile
+	var errs []error
+
+	cluster, err := p.client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	if err != nil {
+		errs = append(errs, fmt.Errorf("getting cluster %s/%s: %w", db.Namespace, db.ClusterName, err))
+	} else {
+		unstructured.RemoveNestedField(cluster.Object, "metadata", "managedFields")
+		content, err := sigsyaml.Marshal(cluster.Object)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("encoding cluster %s/%s: %w", db.Namespace, db.ClusterName, err))
+		} else {
+			files = append(files, provider.DiagnosticFile{Name: "cluster.yaml", Content: content})
+		}
+	}
+
+	if content, err := p.events(ctx, db); err != nil {
+		errs = append(errs, err)
+	} else {
+		files = append(files, provider.DiagnosticFile{Name: "events.yaml", Content: content})
+	}
+
+	if p.podLogs != nil {
+		logs, err := p.instanceLogs(ctx, db)
+		files = append(files, logs...)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return files, errors.Join(errs...)
+}
+
+// events returns the most recent events about the database's objects as a
+// YAML list, oldest first.
+func (p *CNPGProvider) events(ctx context.Context, db provider.ProviderDatabase) ([]byte, error) {
+	list, err := p.client.Resource(eventsGVR).Namespace(db.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("listing events in %s: %w", db.Namespace, err)
+	}
+
+	type event struct {
+		Time    string `json:"time"`
+		Type    string `json:"type"`
+		Reason  string `json:"reason"`
+		Object  string `json:"object"`
+		Message string `json:"message"`
+		Count   int64  `json:"count,omitempty"`
+	}
+	var events []event
+	for _, item := range list.Items {
+		kind, _, _ := unstructured.NestedString(item.Object, "involvedObject", "kind")
+		name, _, _ := unstructured.NestedString(item.Object, "involvedObject", "name")
+		if !clusterObject(name, db.ClusterName) && (db.PoolerName == "" || !poolerObject(name, db.PoolerName)) {
+			continue
+		}
+		e := event{Object: kind + "/" + name, Time: eventTime(&item)}
+		e.Type, _, _ = unstructured.NestedString(item.Object, "type")
+		e.Reason, _, _ = unstructured.NestedString(item.Object, "reason")
+		e.Message, _, _ = unstructured.NestedString(item.Object, "message")
+		e.Count, _, _ = unstructured.NestedInt64(item.Object, "count")
+		events = append(events, e)
+	}
+	// RFC 3339 timestamps in UTC sort chronologically as strings.
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Time < events[j].Time })
+	if len(events) > maxEvents {
+		events = events[len(events)-maxEvents:]
+	}
+	if events == nil {
+		events = []event{}
+	}
+
+	content, err := sigsyaml.Marshal(events)
+	if err != nil {
+		return nil, fmt.Errorf("encoding events: %w", err)
+	}
+	return content, nil
+}
+
+// clusterObject reports whether an object called name is the Cluster or
+// one of its numbered instances' pods, volumes or jobs, such as
+// "<cluster>-1" or "<cluster>-1-initdb". Requiring the number keeps out the
+// Cluster of a database whose name merely starts with this one's.
+func clusterObject(name, cluster string) bool {
+	rest, ok := strings.CutPrefix(name, cluster+"-")
+	return name == cluster || (ok && rest != "" && rest[0] >= '0' && rest[0] <= '9')
+}
+
+// poolerObject reports whether an object called name is the Pooler or one of
+// the objects of its deployment.
+func poolerObject(name, pooler string) bool {
+	return name == pooler || strings.HasPrefix(name, pooler+"-")
+}
+
+// eventTime returns when an event last occurred, in RFC 3339.
+func eventTime(item *unstructured.Unstructured) string {
+	for _, field := range []string{"lastTimestamp", "eventTime", "firstTimestamp"} {
+		if t, _, _ := unstructured.NestedString(item.Object, field); t != "" {
+			return t
+		}
+	}
+	return item.GetCreationTimestamp().UTC().Format("2006-01-02T15:04:05Z")
+}
+
+// instanceLogs returns the last log lines of each instance pod. A pod whose
+// logs cannot be read is reported in the error while the others are kept.
+func (p *CNPGProvider) instanceLogs(ctx context.Context, db provider.ProviderDatabase) ([]provider.DiagnosticFile, error) {
+	pods, err := p.client.Resource(podsGVR).Namespace(db.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "cnpg.io/cluster=" + db.ClusterName + ",cnpg.io/podRole=instance",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing instances of cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].GetName() < pods.Items[j].GetName() })
+
+	var files []provider.DiagnosticFile
+	var errs []error
+	for _, pod := range pods.Items {
+		logs, err := p.podLogs.PodLogs(ctx, db.Namespace, pod.GetName(), postgresContainer, logTailLines)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		files = append(files, provider.DiagnosticFile{Name: "logs/" + pod.GetName() + ".log", Content: logs})
+	}
+	return files, errors.Join(errs...)
+}
@@ -0,0 +1,85 @@
+package cnpg
+
+import (
+	"context"
+	"fmt"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/requestid"
+)
+
+// budgetSuffix names the PodDisruptionBudget DAAP creates for a Cluster,
+// after the Cluster, apart from the "<cluster>" and "<cluster>-primary"
+// budgets of the CNPG operator.
+const budgetSuffix = "-daap"
+
+var budgetsGVR = schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}
+
+// applyDisruption renders the tier's disruption policy into a Cluster, over
+// what the blueprint sets: the priority class of its instances and, when the
+// tier keeps instances available, spec.enablePDB=false, since an instance
+// covered by both the operator's budgets and the tier's could never be
+// evicted. Other objects are left unchanged.
+func applyDisruption(obj *unstructured.Unstructured, disruption provider.Disruption) {
+	if obj.GetKind() != "Cluster" || obj.GroupVersionKind().Group != clustersGVR.Group {
+		return
+	}
+	if disruption.PriorityClassName != "" {
+		_ = unstructured.SetNestedField(obj.Object, disruption.PriorityClassName, "spec", "priorityClassName")
+	}
+	if disruption.MinAvailable > 0 {
+		_ = unstructured.SetNestedField(obj.Object, false, "spec", "enablePDB")
+	}
+}
+
+// disruptionBudget returns the PodDisruptionBudget keeping the tier's
+// minimum of the Cluster's instances available, labeled like the Cluster, or
+// nil if the tier sets no minimum or obj is not a Cluster.
+func disruptionBudget(obj *unstructured.Unstructured, disruption provider.Disruption) *unstructured.Unstructured {
+	if obj.GetKind() != "Cluster" || obj.GroupVersionKind().Group != clustersGVR.Group || disruption.MinAvailable <= 0 {
+		return nil
+	}
+	budget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "policy/v1",
+		"kind":       "PodDisruptionBudget",
+		"metadata": map[string]interface{}{
+			"name":      obj.GetName() + budgetSuffix,
+			"namespace": obj.GetNamespace(),
+		},
+		"spec": map[string]interface{}{
+			"minAvailable": int64(disruption.MinAvailable),
+			"selector": map[string]interface{}{
+				"matchLabels": map[string]interface{}{
+					"cnpg.io/cluster": obj.GetName(),
+					"cnpg.io/podRole": "instance",
+				},
+			},
+		},
+	}}
+	budget.SetLabels(obj.GetLabels())
+	return budget
+}
+
+// applyBudget applies the tier's PodDisruptionBudget for the Cluster obj, or
+// deletes the one applied before once the tier no longer sets a minimum.
+// Other objects have no budget.
+func (p *CNPGProvider) applyBudget(ctx context.Context, obj *unstructured.Unstructured, disruption provider.Disruption) error {
+	if obj.GetKind() != "Cluster" || obj.GroupVersionKind().Group != clustersGVR.Group {
+		return nil
+	}
+	if budget := disruptionBudget(obj, disruption); budget != nil {
+		annotateRequest(budget, requestid.From(ctx))
+		return p.apply(ctx, budget)
+	}
+	name := obj.GetName() + budgetSuffix
+	err := p.client.Resource(budgetsGVR).Namespace(obj.GetNamespace()).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return fmt.Errorf("deleting pod disruption budget %s/%s: %w", obj.GetNamespace(), name, err)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package cnpg
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// externalDNSHostname is the annotation external-dns publishes the DNS
+// records of a Service for.
+const externalDNSHostname = "external-dns.alpha.kubernetes.io/hostname"
+
+// applyDNSName annotates the database's Pooler's spec.serviceTemplate with
+// its friendly hostname, for external-dns to publish a record pointing at the
+// Service: a CNAME to, or the address of, its load balancer when the
+// database is exposed, and its cluster IP otherwise. External-dns removes
+// the record with the Service. Other objects, and the Pooler of a database
+// without a DNS name, are left unchanged.
+func applyDNSName(obj *unstructured.Unstructured, db provider.ProviderDatabase) {
+	if db.DNSName == "" || !isPooler(obj, db) {
+		return
+	}
+	_ = unstructured.SetNestedField(obj.Object, db.DNSName,
+		"spec", "serviceTemplate", "metadata", "annotations", externalDNSHostname)
+}
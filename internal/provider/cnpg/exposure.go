@@ -0,0 +1,116 @@
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+var servicesGVR = schema.GroupVersionResource{Version: "v1", Resource: "services"}
+
+// WithInternalLoadBalancerAnnotations sets the annotations that make the
+// cloud provisioning a Service's load balancer keep it on the internal
+// network, e.g. "networking.gke.io/load-balancer-type: Internal". Without
+// them, databases cannot be exposed internally.
+func WithInternalLoadBalancerAnnotations(annotations map[string]string) Option {
+	return func(p *CNPGProvider) {
+		p.internalLBAnnotations = annotations
+	}
+}
+
+// isPooler reports whether obj is the Pooler the database's clients connect
+// through.
+func isPooler(obj *unstructured.Unstructured, db provider.ProviderDatabase) bool {
+	return obj.GetKind() == "Pooler" && obj.GroupVersionKind().Group == clustersGVR.Group && obj.GetName() == db.PoolerName
+}
+
+// checkExposure returns an error if db is exposed outside the cluster but
+// cannot be: objs render no Pooler to put behind the load balancer, or it is
+// exposed internally without internal load balancer annotations, which
+// would make its load balancer internet-facing.
+func (p *CNPGProvider) checkExposure(objs []*unstructured.Unstructured, db provider.ProviderDatabase) error {
+	if db.Exposure.Type != provider.ExposureInternal && db.Exposure.Type != provider.ExposureExternal {
+		return nil
+	}
+	if db.Exposure.Type == provider.ExposureInternal && len(p.internalLBAnnotations) == 0 {
+		return fmt.Errorf("exposing %s internally: no internal load balancer annotations are configured", db.Name)
+	}
+	for _, obj := range objs {
+		if isPooler(obj, db) {
+			return nil
+		}
+	}
+	return fmt.Errorf("exposing %s: its blueprint renders no Pooler %s", db.Name, db.PoolerName)
+}
+
+// applyExposure renders the database's exposure into its Pooler's
+// spec.serviceTemplate, over what the blueprint sets: an exposed database's
+// Pooler gets a LoadBalancer Service accepting connections from the allowed
+// source ranges, with the internal load balancer annotations if it is
+// exposed internally. Other objects, and the Pooler of a database kept
+// inside the cluster, are left unchanged.
+func (p *CNPGProvider) applyExposure(obj *unstructured.Unstructured, db provider.ProviderDatabase) {
+	if !isPooler(obj, db) {
+		return
+	}
+	switch db.Exposure.Type {
+	case provider.ExposureInternal:
+		annotations, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "serviceTemplate", "metadata", "annotations")
+		values := make(map[string]interface{}, len(annotations)+len(p.internalLBAnnotations))
+		for k, v := range annotations {
+			values[k] = v
+		}
+		for k, v := range p.internalLBAnnotations {
+			values[k] = v
+		}
+		_ = unstructured.SetNestedMap(obj.Object, values, "spec", "serviceTemplate", "metadata", "annotations")
+	case provider.ExposureExternal:
+	default:
+		return
+	}
+	_ = unstructured.SetNestedField(obj.Object, "LoadBalancer", "spec", "serviceTemplate", "spec", "type")
+	if len(db.Exposure.AllowedSourceRanges) > 0 {
+		_ = unstructured.SetNestedStringSlice(obj.Object, db.Exposure.AllowedSourceRanges,
+			"spec", "serviceTemplate", "spec", "loadBalancerSourceRanges")
+	}
+}
+
+// externalHost returns the hostname, or else the address, of the load
+// balancer of an exposed database's Pooler Service, or "" if the database is
+// not exposed or the cloud has not provisioned the load balancer yet. A
+// Service that cannot be read leaves it unknown rather than failing the
+// health check.
+func (p *CNPGProvider) externalHost(ctx context.Context, db provider.ProviderDatabase) string {
+	if db.Exposure.Type != provider.ExposureInternal && db.Exposure.Type != provider.ExposureExternal {
+		return ""
+	}
+	svc, err := p.client.Resource(servicesGVR).Namespace(db.Namespace).Get(ctx, db.PoolerName, metav1.GetOptions{})
+	if err != nil {
+		if !k8serrors.IsNotFound(err) {
+			slog.Warn("cnpg provider: failed to read the pooler service",
+				"database", db.Name, "service", db.PoolerName, "error", err)
+		}
+		return ""
+	}
+	ingress, _, _ := unstructured.NestedSlice(svc.Object, "status", "loadBalancer", "ingress")
+	for _, entry := range ingress {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if hostname, _ := m["hostname"].(string); hostname != "" {
+			return hostname
+		}
+		if ip, _ := m["ip"].(string); ip != "" {
+			return ip
+		}
+	}
+	return ""
+}
@@ -0,0 +1,50 @@
+package cnpg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// hibernationAnnotation is the Cluster annotation that makes the CNPG operator
+// delete the instance pods while keeping their volumes ("on"), or recreate
+// them from those volumes ("off").
+const hibernationAnnotation = "cnpg.io/hibernation"
+
+var _ provider.Freezer = (*CNPGProvider)(nil)
+
+// Freeze hibernates the Cluster the way `kubectl cnpg hibernate on` does.
+func (p *CNPGProvider) Freeze(ctx context.Context, db provider.ProviderDatabase) error {
+	return p.setHibernation(ctx, db, "on")
+}
+
+// Unfreeze ends the Cluster's hibernation the way `kubectl cnpg hibernate
+// off` does.
+func (p *CNPGProvider) Unfreeze(ctx context.Context, db provider.ProviderDatabase) error {
+	return p.setHibernation(ctx, db, "off")
+}
+
+func (p *CNPGProvider) setHibernation(ctx context.Context, db provider.ProviderDatabase, value string) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				hibernationAnnotation: value,
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding hibernation patch: %w", err)
+	}
+	_, err = p.client.Resource(clustersGVR).Namespace(db.Namespace).Patch(
+		ctx, db.ClusterName, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager},
+	)
+	if err != nil {
+		return fmt.Errorf("setting hibernation of cluster %s/%s to %s: %w", db.Namespace, db.ClusterName, value, err)
+	}
+	return nil
+}
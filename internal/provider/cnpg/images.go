@@ -0,0 +1,32 @@
+package cnpg
+
+import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+// replaceImages replaces the image references set anywhere in obj, under
+// "image" or "imageName" keys, by the images they map to in images.
+// References images does not list are left unchanged.
+func replaceImages(obj *unstructured.Unstructured, images map[string]string) {
+	if len(images) == 0 {
+		return
+	}
+	replaceIn(obj.Object, images)
+}
+
+func replaceIn(v interface{}, images map[string]string) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if s, ok := child.(string); ok && (k == "image" || k == "imageName") {
+				if image, ok := images[s]; ok {
+					v[k] = image
+				}
+				continue
+			}
+			replaceIn(child, images)
+		}
+	case []interface{}:
+		for _, child := range v {
+			replaceIn(child, images)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package cnpg
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// ReplicationStats reports how far a CNPG instance lags behind its primary.
+// k8s.Client implements it with the instance manager's metrics.
+type ReplicationStats interface {
+	ReplicationLag(ctx context.Context, namespace, pod string) (time.Duration, error)
+}
+
+// WithReplicationStats makes CheckHealth report replication lag, reading it
+// from rs. Without it, the lag is left unknown.
+func WithReplicationStats(rs ReplicationStats) Option {
+	return func(p *CNPGProvider) {
+		p.replicationStats = rs
+	}
+}
+
+// instanceStatus reads the instances of a Cluster from its status, or
+// returns nil if the operator has not reported them yet. The replication lag
+// is the largest among the replicas; a replica whose lag cannot be read
+// leaves it unknown rather than failing the health check.
+func (p *CNPGProvider) instanceStatus(ctx context.Context, cluster *unstructured.Unstructured) *provider.InstanceStatus {
+	total, found, _ := unstructured.NestedInt64(cluster.Object, "status", "instances")
+	if !found {
+		return nil
+	}
+	ready, _, _ := unstructured.NestedInt64(cluster.Object, "status", "readyInstances")
+	primary, _, _ := unstructured.NestedString(cluster.Object, "status", "currentPrimary")
+	status := &provider.InstanceStatus{Total: int(total), Ready: int(ready), Primary: primary}
+
+	if p.replicationStats == nil || primary == "" {
+		return status
+	}
+	names, _, _ := unstructured.NestedStringSlice(cluster.Object, "status", "instanceNames")
+	var maxLag time.Duration
+	for _, name := range names {
+		if name == primary {
+			continue
+		}
+		lag, err := p.replicationStats.ReplicationLag(ctx, cluster.GetNamespace(), name)
+		if err != nil {
+			slog.Warn("cnpg provider: failed to read replication lag",
+				"cluster", cluster.GetName(), "instance", name, "error", err)
+			return status
+		}
+		maxLag = max(maxLag, lag)
+	}
+	status.ReplicationLag = &maxLag
+	return status
+}
@@ -1,21 +1,76 @@
 package cnpg
 
-import "k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/daap14/daap/internal/provider"
+)
 
 const (
-	labelDatabase       = "daap.io/database"
-	labelManagedBy      = "app.kubernetes.io/managed-by"
-	labelManagedByValue = "daap"
+	labelDatabase       = provider.LabelDatabase
+	labelManagedBy      = provider.LabelManagedBy
+	labelManagedByValue = provider.LabelManagedByValue
 )
 
-// injectLabels adds mandatory DAAP labels to an unstructured K8s object,
-// preserving any existing labels from the blueprint.
-func injectLabels(obj *unstructured.Unstructured, databaseName string) {
-	labels := obj.GetLabels()
-	if labels == nil {
-		labels = make(map[string]string)
-	}
-	labels[labelDatabase] = databaseName
+// injectLabels adds the owner team's default labels and annotations and the
+// mandatory DAAP labels to an unstructured K8s object. Labels and annotations
+// the blueprint sets are kept over the team's defaults; the mandatory labels
+// always win. A Cluster also passes the team's defaults on to its pods and
+// volumes through spec.inheritedMetadata, so tooling that reads them, such as
+// chargeback, sees the same metadata.
+func injectLabels(obj *unstructured.Unstructured, db provider.ProviderDatabase) {
+	labels := withDefaults(obj.GetLabels(), db.Labels)
+	labels[labelDatabase] = db.Name
 	labels[labelManagedBy] = labelManagedByValue
 	obj.SetLabels(labels)
+
+	if len(db.Annotations) > 0 {
+		obj.SetAnnotations(withDefaults(obj.GetAnnotations(), db.Annotations))
+	}
+
+	if obj.GetKind() == "Cluster" && obj.GroupVersionKind().Group == clustersGVR.Group {
+		inheritDefaults(obj, "labels", db.Labels)
+		inheritDefaults(obj, "annotations", db.Annotations)
+	}
+}
+
+// annotateRequest records the request that applies obj, if any, so the
+// resource can be traced back to it.
+func annotateRequest(obj *unstructured.Unstructured, requestID string) {
+	if requestID == "" {
+		return
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[provider.AnnotationRequestID] = requestID
+	obj.SetAnnotations(annotations)
+}
+
+// inheritDefaults adds defaults to the Cluster's spec.inheritedMetadata.<field>.
+func inheritDefaults(obj *unstructured.Unstructured, field string, defaults map[string]string) {
+	if len(defaults) == 0 {
+		return
+	}
+	current, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "inheritedMetadata", field)
+	merged := withDefaults(current, defaults)
+	values := make(map[string]interface{}, len(merged))
+	for k, v := range merged {
+		values[k] = v
+	}
+	_ = unstructured.SetNestedMap(obj.Object, values, "spec", "inheritedMetadata", field)
+}
+
+// withDefaults returns m with the entries of defaults it does not set.
+func withDefaults(m, defaults map[string]string) map[string]string {
+	if m == nil {
+		m = make(map[string]string, len(defaults))
+	}
+	for k, v := range defaults {
+		if _, ok := m[k]; !ok {
+			m[k] = v
+		}
+	}
+	return m
 }
@@ -0,0 +1,38 @@
+package cnpg
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// operatorVersionAnnotation is set by the CNPG operator on the objects it
+// creates, such as instance pods, to the operator's version. Upgrading the
+// operator rolls the instance pods over to new ones carrying the new version.
+const operatorVersionAnnotation = "cnpg.io/operatorVersion"
+
+var _ provider.OperatorVersioner = (*CNPGProvider)(nil)
+
+// OperatorVersion returns the operator version the Cluster's instance pods
+// were created by. It returns "" while there are no pods or they disagree,
+// as they do midway through a rolling update after an operator upgrade.
+func (p *CNPGProvider) OperatorVersion(ctx context.Context, db provider.ProviderDatabase) (string, error) {
+	pods, err := p.client.Resource(podsGVR).Namespace(db.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "cnpg.io/cluster=" + db.ClusterName + ",cnpg.io/podRole=instance",
+	})
+	if err != nil {
+		return "", fmt.Errorf("listing instances of cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	version := ""
+	for i, pod := range pods.Items {
+		v := pod.GetAnnotations()[operatorVersionAnnotation]
+		if v == "" || (i > 0 && v != version) {
+			return "", nil
+		}
+		version = v
+	}
+	return version, nil
+}
@@ -0,0 +1,99 @@
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+var poolersGVR = schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "poolers"}
+
+// pgbouncer's defaults for the limits a Pooler does not set in
+// spec.pgbouncer.parameters.
+const (
+	defaultPoolSize   = 20
+	defaultMaxClients = 100
+)
+
+// PoolerStats reports the pools of a CNPG pooler instance as the columns of
+// pgbouncer's SHOW POOLS, summed over its pools. k8s.Client implements it
+// with the pooler's metrics.
+type PoolerStats interface {
+	PgBouncerPools(ctx context.Context, namespace, pod string) (map[string]float64, error)
+}
+
+// WithPoolerStats enables PoolerUsage, reading the pooler's pools from ps.
+// Without it, PoolerUsage returns provider.ErrNotSupported.
+func WithPoolerStats(ps PoolerStats) Option {
+	return func(p *CNPGProvider) {
+		p.poolerStats = ps
+	}
+}
+
+var _ provider.PoolerReporter = (*CNPGProvider)(nil)
+
+// PoolerUsage returns the connections of the database's Pooler, summed over
+// its instances, and its limits: pgbouncer's max_client_conn and
+// default_pool_size, which apply per instance and per pool, times the
+// instances. A database's clients share one pool, of its application user
+// on its database. A database whose blueprint renders no Pooler returns
+// provider.ErrNotSupported.
+func (p *CNPGProvider) PoolerUsage(ctx context.Context, db provider.ProviderDatabase) (provider.PoolerUsage, error) {
+	if p.poolerStats == nil {
+		return provider.PoolerUsage{}, provider.ErrNotSupported
+	}
+
+	pooler, err := p.client.Resource(poolersGVR).Namespace(db.Namespace).Get(ctx, db.PoolerName, metav1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return provider.PoolerUsage{}, provider.ErrNotSupported
+		}
+		return provider.PoolerUsage{}, fmt.Errorf("getting pooler %s/%s: %w", db.Namespace, db.PoolerName, err)
+	}
+	params, _, _ := unstructured.NestedStringMap(pooler.Object, "spec", "pgbouncer", "parameters")
+
+	pods, err := p.client.Resource(podsGVR).Namespace(db.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "cnpg.io/poolerName=" + db.PoolerName,
+	})
+	if err != nil {
+		return provider.PoolerUsage{}, fmt.Errorf("listing instances of pooler %s/%s: %w", db.Namespace, db.PoolerName, err)
+	}
+	if len(pods.Items) == 0 {
+		return provider.PoolerUsage{}, fmt.Errorf("pooler %s/%s has no instances", db.Namespace, db.PoolerName)
+	}
+
+	instances := int64(len(pods.Items))
+	usage := provider.PoolerUsage{
+		Instances:  len(pods.Items),
+		MaxClients: poolerParam(params, "max_client_conn", defaultMaxClients) * instances,
+		PoolSize:   poolerParam(params, "default_pool_size", defaultPoolSize) * instances,
+	}
+	for _, pod := range pods.Items {
+		pools, err := p.poolerStats.PgBouncerPools(ctx, db.Namespace, pod.GetName())
+		if err != nil {
+			return provider.PoolerUsage{}, err
+		}
+		usage.ActiveClients += int64(pools["cl_active"])
+		usage.WaitingClients += int64(pools["cl_waiting"])
+		usage.ActiveServers += int64(pools["sv_active"])
+		usage.IdleServers += int64(pools["sv_idle"])
+	}
+	return usage, nil
+}
+
+// poolerParam reads a numeric pgbouncer parameter, or returns def if it is
+// unset or malformed.
+func poolerParam(params map[string]string, name string, def int64) int64 {
+	v, err := strconv.ParseInt(params[name], 10, 64)
+	if err != nil || v <= 0 {
+		return def
+	}
+	return v
+}
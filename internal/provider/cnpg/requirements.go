@@ -0,0 +1,202 @@
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// Supported CloudNativePG operator versions: MinOperatorVersion inclusive,
+// MaxOperatorVersion exclusive.
+var (
+	MinOperatorVersion = Version{Major: 1, Minor: 22}
+	MaxOperatorVersion = Version{Major: 1, Minor: 27}
+)
+
+// kindMinVersions lists the postgresql.cnpg.io kinds that need a newer
+// operator than MinOperatorVersion.
+var kindMinVersions = map[string]Version{
+	"Database":     {Major: 1, Minor: 25},
+	"Publication":  {Major: 1, Minor: 25},
+	"Subscription": {Major: 1, Minor: 25},
+}
+
+// operatorSelector matches the operator Deployment in both the release
+// manifest and the Helm chart.
+const operatorSelector = "app.kubernetes.io/name=cloudnative-pg"
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+// Version is a CloudNativePG release version.
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// ParseVersion parses "1.25.1", "v1.25.1" or "1.25.1-rc1"; the patch is
+// optional and pre-release suffixes are ignored.
+func ParseVersion(s string) (Version, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "v")
+	if i := strings.IndexAny(s, "-+"); i >= 0 {
+		s = s[:i]
+	}
+	parts := strings.Split(s, ".")
+	if len(parts) < 2 || len(parts) > 3 {
+		return Version{}, fmt.Errorf("invalid version %q", s)
+	}
+	nums := make([]int, 3)
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return Version{}, fmt.Errorf("invalid version %q", s)
+		}
+		nums[i] = n
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2]}, nil
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Less reports whether v is older than o.
+func (v Version) Less(o Version) bool {
+	if v.Major != o.Major {
+		return v.Major < o.Major
+	}
+	if v.Minor != o.Minor {
+		return v.Minor < o.Minor
+	}
+	return v.Patch < o.Patch
+}
+
+// Supported reports whether DAAP supports operator version v.
+func (v Version) Supported() bool {
+	return !v.Less(MinOperatorVersion) && v.Less(MaxOperatorVersion)
+}
+
+// SupportedRange describes the supported operator versions.
+func SupportedRange() string {
+	return fmt.Sprintf(">=%s <%s", MinOperatorVersion, MaxOperatorVersion)
+}
+
+// Operator describes the CloudNativePG operator found in the cluster.
+type Operator struct {
+	Detected   bool
+	Namespace  string
+	Deployment string
+	Version    string // empty when it could not be determined
+	Chart      string // Helm chart (helm.sh/chart label), empty for manifest installs
+}
+
+// OperatorDetector finds the CloudNativePG operator Deployment.
+type OperatorDetector struct {
+	client    dynamic.Interface
+	namespace string
+}
+
+// NewOperatorDetector creates a detector that looks for the operator in
+// namespace.
+func NewOperatorDetector(client dynamic.Interface, namespace string) *OperatorDetector {
+	return &OperatorDetector{client: client, namespace: namespace}
+}
+
+// Detect returns the operator found in the detector's namespace. The version
+// comes from the app.kubernetes.io/version label, falling back to the image
+// tag of the manager container.
+func (d *OperatorDetector) Detect(ctx context.Context) (Operator, error) {
+	op := Operator{Namespace: d.namespace}
+
+	list, err := d.client.Resource(deploymentsGVR).Namespace(d.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: operatorSelector,
+	})
+	if err != nil {
+		return op, fmt.Errorf("listing operator deployments in %s: %w", d.namespace, err)
+	}
+	if len(list.Items) == 0 {
+		return op, nil
+	}
+
+	// Prefer a deterministic pick if several deployments match.
+	sort.Slice(list.Items, func(i, j int) bool { return list.Items[i].GetName() < list.Items[j].GetName() })
+	dep := list.Items[0]
+
+	op.Detected = true
+	op.Deployment = dep.GetName()
+	labels := dep.GetLabels()
+	op.Chart = labels["helm.sh/chart"]
+	op.Version = labels["app.kubernetes.io/version"]
+	if op.Version == "" {
+		op.Version = imageTag(&dep)
+	}
+	return op, nil
+}
+
+// imageTag returns the tag of the first container image in the deployment.
+func imageTag(dep *unstructured.Unstructured) string {
+	containers, _, _ := unstructured.NestedSlice(dep.Object, "spec", "template", "spec", "containers")
+	for _, c := range containers {
+		m, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		image, _ := m["image"].(string)
+		if i := strings.Index(image, "@"); i >= 0 {
+			image = image[:i]
+		}
+		if i := strings.LastIndex(image, ":"); i >= 0 && !strings.Contains(image[i:], "/") {
+			return image[i+1:]
+		}
+	}
+	return ""
+}
+
+var (
+	documentSep   = regexp.MustCompile(`(?m)^---\s*$`)
+	topAPIVersion = regexp.MustCompile(`(?m)^apiVersion:\s*["']?([^\s"']+)`)
+	topKind       = regexp.MustCompile(`(?m)^kind:\s*["']?([A-Za-z]+)`)
+)
+
+// ManifestKinds returns the postgresql.cnpg.io kinds used by blueprint
+// manifests. It reads the top-level apiVersion and kind of each document, so
+// it works on unrendered templates.
+func ManifestKinds(manifests string) []string {
+	seen := map[string]bool{}
+	var kinds []string
+	for _, doc := range documentSep.Split(manifests, -1) {
+		av := topAPIVersion.FindStringSubmatch(doc)
+		k := topKind.FindStringSubmatch(doc)
+		if av == nil || k == nil || !strings.HasPrefix(av[1], "postgresql.cnpg.io/") {
+			continue
+		}
+		if !seen[k[1]] {
+			seen[k[1]] = true
+			kinds = append(kinds, k[1])
+		}
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// CheckManifests returns the reasons blueprint manifests cannot run on the
+// given operator version: the version itself is unsupported, or a kind needs a
+// newer operator. It returns nil when the manifests are compatible.
+func CheckManifests(operatorVersion Version, manifests string) []string {
+	var issues []string
+	if !operatorVersion.Supported() {
+		issues = append(issues, fmt.Sprintf("CloudNativePG operator %s is not supported (supported: %s)", operatorVersion, SupportedRange()))
+	}
+	for _, kind := range ManifestKinds(manifests) {
+		if min, ok := kindMinVersions[kind]; ok && operatorVersion.Less(min) {
+			issues = append(issues, fmt.Sprintf("kind %s requires CloudNativePG %s or later, found %s", kind, min, operatorVersion))
+		}
+	}
+	return issues
+}
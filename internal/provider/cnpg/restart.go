@@ -0,0 +1,72 @@
+package cnpg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// restartAnnotation is the Cluster annotation whose change makes the CNPG
+// operator restart the instances, replicas first, copying it onto each new
+// instance pod.
+const restartAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+var _ provider.Restarter = (*CNPGProvider)(nil)
+
+// Restart starts a rolling restart the way `kubectl cnpg restart` does: by
+// setting the Cluster's restartedAt annotation to the current time.
+func (p *CNPGProvider) Restart(ctx context.Context, db provider.ProviderDatabase) error {
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]any{
+				restartAnnotation: time.Now().UTC().Format(time.RFC3339Nano),
+			},
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding restart patch: %w", err)
+	}
+	_, err = p.client.Resource(clustersGVR).Namespace(db.Namespace).Patch(
+		ctx, db.ClusterName, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager},
+	)
+	if err != nil {
+		return fmt.Errorf("requesting restart of cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	return nil
+}
+
+// Restarted reports whether every instance pod carries the Cluster's
+// restartedAt annotation and all instances are ready again.
+func (p *CNPGProvider) Restarted(ctx context.Context, db provider.ProviderDatabase) (bool, error) {
+	cluster, err := p.client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("getting cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	restartedAt := cluster.GetAnnotations()[restartAnnotation]
+	if restartedAt == "" {
+		return true, nil
+	}
+
+	pods, err := p.client.Resource(podsGVR).Namespace(db.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "cnpg.io/cluster=" + db.ClusterName + ",cnpg.io/podRole=instance",
+	})
+	if err != nil {
+		return false, fmt.Errorf("listing instances of cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	for _, pod := range pods.Items {
+		if pod.GetAnnotations()[restartAnnotation] != restartedAt {
+			return false, nil
+		}
+	}
+
+	total, _, _ := unstructured.NestedInt64(cluster.Object, "status", "instances")
+	ready, _, _ := unstructured.NestedInt64(cluster.Object, "status", "readyInstances")
+	return len(pods.Items) > 0 && ready == total, nil
+}
@@ -0,0 +1,85 @@
+package cnpg
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+var _ provider.Restorer = (*CNPGProvider)(nil)
+
+// Restore applies db's manifests with its Cluster bootstrapped by recovery
+// from source's backups. A named target Backup must be a completed backup of
+// source's Cluster; without one, the Cluster recovers from the latest backup
+// in source's object store, which it reads as an external cluster with
+// source's object store settings and credentials. Either way, the archived
+// WAL is replayed up to the target time, if any. Both Clusters are in the
+// same namespace, where the Backup and the object store credentials are.
+func (p *CNPGProvider) Restore(ctx context.Context, db, source provider.ProviderDatabase, manifests string, target provider.RestoreTarget) error {
+	if db.Namespace != source.Namespace {
+		return fmt.Errorf("restoring %s/%s into namespace %s: %w", source.Namespace, source.ClusterName, db.Namespace, provider.ErrNotSupported)
+	}
+	recovery, external, err := p.recoveryFrom(ctx, source, target)
+	if err != nil {
+		return err
+	}
+	return p.applyWith(ctx, db, manifests, func(obj *unstructured.Unstructured) {
+		if obj.GetKind() == "Cluster" && obj.GroupVersionKind().Group == clustersGVR.Group && obj.GetName() == db.ClusterName {
+			bootstrap(obj, "recovery", recovery)
+			if external != nil {
+				addExternalCluster(obj, external)
+			}
+		}
+	})
+}
+
+// recoveryFrom returns the recovery bootstrap restoring source as of target,
+// and the external cluster it recovers from, if any.
+func (p *CNPGProvider) recoveryFrom(ctx context.Context, source provider.ProviderDatabase, target provider.RestoreTarget) (map[string]any, map[string]any, error) {
+	var recovery, external map[string]any
+	if target.Backup != "" {
+		backup, err := p.client.Resource(backupsGVR).Namespace(source.Namespace).Get(ctx, target.Backup, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("backup %s/%s: %w", source.Namespace, target.Backup, provider.ErrBackupNotFound)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting backup %s/%s: %w", source.Namespace, target.Backup, err)
+		}
+		if cluster, _, _ := unstructured.NestedString(backup.Object, "spec", "cluster", "name"); cluster != source.ClusterName {
+			return nil, nil, fmt.Errorf("backup %s/%s is not a backup of %s: %w", source.Namespace, target.Backup, source.ClusterName, provider.ErrBackupNotFound)
+		}
+		if phase, _, _ := unstructured.NestedString(backup.Object, "status", "phase"); phase != "completed" {
+			return nil, nil, fmt.Errorf("backup %s/%s has not completed: %w", source.Namespace, target.Backup, provider.ErrBackupNotFound)
+		}
+		recovery = map[string]any{"backup": map[string]any{"name": target.Backup}}
+	} else {
+		cluster, err := p.client.Resource(clustersGVR).Namespace(source.Namespace).Get(ctx, source.ClusterName, metav1.GetOptions{})
+		if k8serrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("cluster %s/%s: %w", source.Namespace, source.ClusterName, provider.ErrBackupNotFound)
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("getting cluster %s/%s: %w", source.Namespace, source.ClusterName, err)
+		}
+		store, ok, _ := unstructured.NestedMap(cluster.Object, "spec", "backup", "barmanObjectStore")
+		if !ok {
+			return nil, nil, fmt.Errorf("cluster %s/%s has no object store: %w", source.Namespace, source.ClusterName, provider.ErrBackupNotFound)
+		}
+		// Barman files the backups of a cluster under its server name,
+		// which defaults to the cluster name.
+		if server, _ := store["serverName"].(string); server == "" {
+			store["serverName"] = source.ClusterName
+		}
+		recovery = map[string]any{"source": source.ClusterName}
+		external = map[string]any{"name": source.ClusterName, "barmanObjectStore": store}
+	}
+	if target.Time != nil {
+		recovery["recoveryTarget"] = map[string]any{"targetTime": target.Time.UTC().Format(time.RFC3339)}
+	}
+	return recovery, external, nil
+}
@@ -0,0 +1,96 @@
+package cnpg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+var (
+	clustersGVR = schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"}
+	podsGVR     = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+)
+
+// VolumeStats reports how much of a PersistentVolumeClaim mounted by a pod is
+// used. k8s.Client implements it with the kubelet stats summary.
+type VolumeStats interface {
+	PVCUsedBytes(ctx context.Context, namespace, pod, pvc string) (int64, error)
+}
+
+// WithVolumeStats enables StorageUsage, reading instance volume usage from
+// vs. Without it, StorageUsage returns provider.ErrNotSupported.
+func WithVolumeStats(vs VolumeStats) Option {
+	return func(p *CNPGProvider) {
+		p.volumeStats = vs
+	}
+}
+
+var _ provider.StorageScaler = (*CNPGProvider)(nil)
+
+// StorageUsage returns the Cluster's requested storage size and the usage of
+// its fullest instance. CNPG names each instance's data PVC after its pod.
+func (p *CNPGProvider) StorageUsage(ctx context.Context, db provider.ProviderDatabase) (provider.StorageUsage, error) {
+	if p.volumeStats == nil {
+		return provider.StorageUsage{}, provider.ErrNotSupported
+	}
+
+	cluster, err := p.client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	if err != nil {
+		return provider.StorageUsage{}, fmt.Errorf("getting cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	size, _, _ := unstructured.NestedString(cluster.Object, "spec", "storage", "size")
+	if size == "" {
+		return provider.StorageUsage{}, fmt.Errorf("cluster %s/%s has no spec.storage.size", db.Namespace, db.ClusterName)
+	}
+	capacity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return provider.StorageUsage{}, fmt.Errorf("parsing storage size of cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+
+	pods, err := p.client.Resource(podsGVR).Namespace(db.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "cnpg.io/cluster=" + db.ClusterName + ",cnpg.io/podRole=instance",
+	})
+	if err != nil {
+		return provider.StorageUsage{}, fmt.Errorf("listing instances of cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	if len(pods.Items) == 0 {
+		return provider.StorageUsage{}, fmt.Errorf("cluster %s/%s has no instances", db.Namespace, db.ClusterName)
+	}
+
+	usage := provider.StorageUsage{CapacityBytes: capacity.Value()}
+	for _, pod := range pods.Items {
+		used, err := p.volumeStats.PVCUsedBytes(ctx, db.Namespace, pod.GetName(), pod.GetName())
+		if err != nil {
+			return provider.StorageUsage{}, err
+		}
+		usage.UsedBytes = max(usage.UsedBytes, used)
+	}
+	return usage, nil
+}
+
+// ResizeStorage sets the Cluster's spec.storage.size. The CNPG operator then
+// expands each instance's PVC, which requires a storage class that allows
+// volume expansion.
+func (p *CNPGProvider) ResizeStorage(ctx context.Context, db provider.ProviderDatabase, sizeBytes int64) error {
+	size := resource.NewQuantity(sizeBytes, resource.BinarySI).String()
+	patch, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"storage": map[string]any{"size": size}},
+	})
+	if err != nil {
+		return fmt.Errorf("building storage patch: %w", err)
+	}
+
+	_, err = p.client.Resource(clustersGVR).Namespace(db.Namespace).Patch(ctx, db.ClusterName, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager})
+	if err != nil {
+		return fmt.Errorf("resizing cluster %s/%s to %s: %w", db.Namespace, db.ClusterName, size, err)
+	}
+	return nil
+}
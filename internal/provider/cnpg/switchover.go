@@ -0,0 +1,69 @@
+package cnpg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// phaseSwitchover is the Cluster phase while the operator promotes the
+// target primary.
+const phaseSwitchover = "Switchover in progress"
+
+var _ provider.PrimarySwitcher = (*CNPGProvider)(nil)
+
+// Switchover asks the CNPG operator to promote target the way `kubectl cnpg
+// promote` does: by setting the Cluster's status.targetPrimary. Without a
+// target, the first healthy replica in name order is promoted. The target must
+// be a healthy instance other than the current primary.
+func (p *CNPGProvider) Switchover(ctx context.Context, db provider.ProviderDatabase, target string) (string, error) {
+	cluster, err := p.client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("getting cluster %s/%s: %w", db.Namespace, db.ClusterName, err)
+	}
+	phase, _, _ := unstructured.NestedString(cluster.Object, "status", "phase")
+	if phase == phaseSwitchover {
+		return "", fmt.Errorf("%w: a switchover is already in progress", provider.ErrInvalidTarget)
+	}
+	primary, _, _ := unstructured.NestedString(cluster.Object, "status", "currentPrimary")
+	healthy, _, _ := unstructured.NestedStringSlice(cluster.Object, "status", "instancesStatus", "healthy")
+	replicas := slices.DeleteFunc(slices.Sorted(slices.Values(healthy)), func(name string) bool { return name == primary })
+
+	switch {
+	case target == "" && len(replicas) == 0:
+		return "", fmt.Errorf("%w: cluster %s has no healthy replica", provider.ErrInvalidTarget, db.ClusterName)
+	case target == "":
+		target = replicas[0]
+	case target == primary:
+		return "", fmt.Errorf("%w: %s is already the primary", provider.ErrInvalidTarget, target)
+	case !slices.Contains(replicas, target):
+		return "", fmt.Errorf("%w: %s is not a healthy instance of cluster %s", provider.ErrInvalidTarget, target, db.ClusterName)
+	}
+
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{
+			"targetPrimary":          target,
+			"targetPrimaryTimestamp": time.Now().UTC().Format("2006-01-02T15:04:05.000000Z07:00"),
+			"phase":                  phaseSwitchover,
+			"phaseReason":            "Switching over to " + target,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("encoding switchover patch: %w", err)
+	}
+	_, err = p.client.Resource(clustersGVR).Namespace(db.Namespace).Patch(
+		ctx, db.ClusterName, types.MergePatchType, patch, metav1.PatchOptions{FieldManager: FieldManager}, "status",
+	)
+	if err != nil {
+		return "", fmt.Errorf("requesting switchover of cluster %s/%s to %s: %w", db.Namespace, db.ClusterName, target, err)
+	}
+	return target, nil
+}
@@ -6,7 +6,11 @@ import (
 	"strings"
 	"text/template"
 
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	sigsyaml "sigs.k8s.io/yaml"
+
 	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/secrets"
 )
 
 // templateContext is the data passed to Go templates in blueprint manifests.
@@ -22,10 +26,14 @@ type templateContext struct {
 	TierID      string
 	Blueprint   string
 	Provider    string
+
+	// Secrets holds the values of the secrets the manifests reference.
+	Secrets map[string]string
 }
 
-// toTemplateContext builds a templateContext from a ProviderDatabase.
-func toTemplateContext(db provider.ProviderDatabase) templateContext {
+// toTemplateContext builds a templateContext from a ProviderDatabase and
+// the resolved secret values.
+func toTemplateContext(db provider.ProviderDatabase, secretValues map[string]string) templateContext {
 	return templateContext{
 		ID:          db.ID.String(),
 		Name:        db.Name,
@@ -38,18 +46,30 @@ func toTemplateContext(db provider.ProviderDatabase) templateContext {
 		TierID:      db.TierID.String(),
 		Blueprint:   db.Blueprint,
 		Provider:    db.Provider,
+
+		Secrets: secretValues,
+	}
+}
+
+// WithSecrets makes Apply resolve the {{ .Secrets.<name> }} references of
+// blueprint manifests from store. Without it, applying manifests that
+// reference secrets fails.
+func WithSecrets(store secrets.Store) Option {
+	return func(p *CNPGProvider) {
+		p.secrets = store
 	}
 }
 
 // renderManifests parses and executes Go templates in the manifests string
-// using the provided ProviderDatabase context. Returns the rendered YAML.
-func renderManifests(manifests string, db provider.ProviderDatabase) (string, error) {
-	tmpl, err := template.New("blueprint").Parse(manifests)
+// using the provided ProviderDatabase context and secret values. Returns the
+// rendered YAML. A secret missing from secretValues is an error.
+func renderManifests(manifests string, db provider.ProviderDatabase, secretValues map[string]string) (string, error) {
+	tmpl, err := template.New("blueprint").Option("missingkey=error").Parse(manifests)
 	if err != nil {
 		return "", fmt.Errorf("parsing blueprint template: %w", err)
 	}
 
-	ctx := toTemplateContext(db)
+	ctx := toTemplateContext(db, secretValues)
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, ctx); err != nil {
@@ -73,3 +93,56 @@ func splitYAMLDocuments(yaml string) []string {
 	}
 	return docs
 }
+
+// RenderManifests renders the blueprint manifests for db and injects the
+// mandatory labels, the tier's topology and disruption policy and the
+// database's pinned images, exposure and DNS name, returning the documents
+// Apply would send to the API server, with the tier's PodDisruptionBudget
+// after its Cluster, as multi-document YAML. Secret references render as
+// "<secret:name>" placeholders, so the output is safe to export.
+func (p *CNPGProvider) RenderManifests(db provider.ProviderDatabase, manifests string) (string, error) {
+	rendered, err := renderManifests(manifests, db, secrets.Placeholders(manifests))
+	if err != nil {
+		return "", fmt.Errorf("rendering manifests for %s: %w", db.Name, err)
+	}
+
+	docs := splitYAMLDocuments(rendered)
+	if len(docs) == 0 {
+		return "", fmt.Errorf("blueprint manifests for %s produced no documents", db.Name)
+	}
+
+	parsed := make([]*unstructured.Unstructured, len(docs))
+	for i, doc := range docs {
+		obj, err := parseUnstructured(doc)
+		if err != nil {
+			return "", fmt.Errorf("parsing document %d for %s: %w", i, db.Name, err)
+		}
+		injectLabels(obj, db)
+		applyTopology(obj, db.Topology)
+		applyDisruption(obj, db.Disruption)
+		replaceImages(obj, db.Images)
+		p.applyExposure(obj, db)
+		applyDNSName(obj, db)
+		parsed[i] = obj
+	}
+	if err := p.checkExposure(parsed, db); err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	for i, obj := range parsed {
+		objs := []*unstructured.Unstructured{obj}
+		if budget := disruptionBudget(obj, db.Disruption); budget != nil {
+			objs = append(objs, budget)
+		}
+		for _, o := range objs {
+			data, err := sigsyaml.Marshal(o.Object)
+			if err != nil {
+				return "", fmt.Errorf("encoding document %d for %s: %w", i, db.Name, err)
+			}
+			out.WriteString("---\n")
+			out.Write(data)
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,36 @@
+package cnpg
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// zoneTopologyKey is the well-known node label holding a node's zone.
+const zoneTopologyKey = "topology.kubernetes.io/zone"
+
+// applyTopology renders the tier's topology into a Cluster's spec.affinity,
+// over what the blueprint sets: a zone spread enables pod anti-affinity
+// across zones, of the spread's type, and the node selector's labels are
+// added to spec.affinity.nodeSelector. Other objects are left unchanged.
+func applyTopology(obj *unstructured.Unstructured, topology provider.Topology) {
+	if obj.GetKind() != "Cluster" || obj.GroupVersionKind().Group != clustersGVR.Group {
+		return
+	}
+	if topology.ZoneSpread != "" {
+		_ = unstructured.SetNestedField(obj.Object, true, "spec", "affinity", "enablePodAntiAffinity")
+		_ = unstructured.SetNestedField(obj.Object, zoneTopologyKey, "spec", "affinity", "topologyKey")
+		_ = unstructured.SetNestedField(obj.Object, topology.ZoneSpread, "spec", "affinity", "podAntiAffinityType")
+	}
+	if len(topology.NodeSelector) > 0 {
+		selector, _, _ := unstructured.NestedStringMap(obj.Object, "spec", "affinity", "nodeSelector")
+		values := make(map[string]interface{}, len(selector)+len(topology.NodeSelector))
+		for k, v := range selector {
+			values[k] = v
+		}
+		for k, v := range topology.NodeSelector {
+			values[k] = v
+		}
+		_ = unstructured.SetNestedMap(obj.Object, values, "spec", "affinity", "nodeSelector")
+	}
+}
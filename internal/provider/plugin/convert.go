@@ -0,0 +1,158 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/pkg/plugin/providerv1"
+)
+
+func toProto(db provider.ProviderDatabase) *providerv1.Database {
+	return &providerv1.Database{
+		Id:          db.ID.String(),
+		Name:        db.Name,
+		Namespace:   db.Namespace,
+		ClusterName: db.ClusterName,
+		PoolerName:  db.PoolerName,
+		OwnerTeam:   db.OwnerTeam,
+		OwnerTeamId: db.OwnerTeamID.String(),
+		Tier:        db.Tier,
+		TierId:      db.TierID.String(),
+		Blueprint:   db.Blueprint,
+		Provider:    db.Provider,
+		Labels:      db.Labels,
+		Annotations: db.Annotations,
+		Topology: &providerv1.Topology{
+			ZoneSpread:   db.Topology.ZoneSpread,
+			NodeSelector: db.Topology.NodeSelector,
+		},
+		Disruption: &providerv1.Disruption{
+			MinAvailable:      int32(db.Disruption.MinAvailable),
+			PriorityClassName: db.Disruption.PriorityClassName,
+		},
+		Images: db.Images,
+		Exposure: &providerv1.Exposure{
+			Type:                db.Exposure.Type,
+			AllowedSourceRanges: db.Exposure.AllowedSourceRanges,
+		},
+		DnsName: db.DNSName,
+	}
+}
+
+func fromProto(db *providerv1.Database) (provider.ProviderDatabase, error) {
+	id, err := uuid.Parse(db.GetId())
+	if err != nil {
+		return provider.ProviderDatabase{}, fmt.Errorf("invalid database id: %w", err)
+	}
+	// Owner team and tier IDs are informational; tolerate them being unset.
+	ownerTeamID, _ := uuid.Parse(db.GetOwnerTeamId())
+	tierID, _ := uuid.Parse(db.GetTierId())
+
+	return provider.ProviderDatabase{
+		ID:          id,
+		Name:        db.GetName(),
+		Namespace:   db.GetNamespace(),
+		ClusterName: db.GetClusterName(),
+		PoolerName:  db.GetPoolerName(),
+		OwnerTeam:   db.GetOwnerTeam(),
+		OwnerTeamID: ownerTeamID,
+		Tier:        db.GetTier(),
+		TierID:      tierID,
+		Blueprint:   db.GetBlueprint(),
+		Provider:    db.GetProvider(),
+		Labels:      db.GetLabels(),
+		Annotations: db.GetAnnotations(),
+		Topology: provider.Topology{
+			ZoneSpread:   db.GetTopology().GetZoneSpread(),
+			NodeSelector: db.GetTopology().GetNodeSelector(),
+		},
+		Disruption: provider.Disruption{
+			MinAvailable:      int(db.GetDisruption().GetMinAvailable()),
+			PriorityClassName: db.GetDisruption().GetPriorityClassName(),
+		},
+		Images: db.GetImages(),
+		Exposure: provider.Exposure{
+			Type:                db.GetExposure().GetType(),
+			AllowedSourceRanges: db.GetExposure().GetAllowedSourceRanges(),
+		},
+		DNSName: db.GetDnsName(),
+	}, nil
+}
+
+func healthToProto(h provider.HealthResult) *providerv1.CheckHealthResponse {
+	resp := &providerv1.CheckHealthResponse{
+		Status:       h.Status,
+		Host:         h.Host,
+		SecretName:   h.SecretName,
+		ExternalHost: h.ExternalHost,
+		Reason:       h.Reason,
+		Message:      h.Message,
+	}
+	if h.Port != nil {
+		port := int32(*h.Port)
+		resp.Port = &port
+	}
+	if h.Instances != nil {
+		resp.Instances = &providerv1.InstanceStatus{
+			Total:   int32(h.Instances.Total),
+			Ready:   int32(h.Instances.Ready),
+			Primary: h.Instances.Primary,
+		}
+		if h.Instances.ReplicationLag != nil {
+			lag := int64(*h.Instances.ReplicationLag)
+			resp.Instances.ReplicationLagNanos = &lag
+		}
+	}
+	return resp
+}
+
+func healthFromProto(resp *providerv1.CheckHealthResponse) provider.HealthResult {
+	h := provider.HealthResult{
+		Status:       resp.GetStatus(),
+		Host:         resp.Host,
+		SecretName:   resp.SecretName,
+		ExternalHost: resp.GetExternalHost(),
+		Reason:       resp.GetReason(),
+		Message:      resp.GetMessage(),
+	}
+	if resp.Port != nil {
+		port := int(resp.GetPort())
+		h.Port = &port
+	}
+	if in := resp.GetInstances(); in != nil {
+		h.Instances = &provider.InstanceStatus{
+			Total:   int(in.GetTotal()),
+			Ready:   int(in.GetReady()),
+			Primary: in.GetPrimary(),
+		}
+		if in.ReplicationLagNanos != nil {
+			lag := time.Duration(in.GetReplicationLagNanos())
+			h.Instances.ReplicationLag = &lag
+		}
+	}
+	return h
+}
+
+// callError converts a gRPC error from a plugin into a provider error.
+// Failures to reach the plugin wrap provider.ErrUnavailable so they are
+// treated as transient.
+func callError(name, method string, err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("plugin %s: %s: %w", name, method, err)
+	}
+	switch st.Code() {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return fmt.Errorf("plugin %s: %s: %s: %w", name, method, st.Message(), provider.ErrUnavailable)
+	case codes.Canceled:
+		return fmt.Errorf("plugin %s: %s: %w", name, method, context.Canceled)
+	default:
+		return fmt.Errorf("plugin %s: %s: %s", name, method, st.Message())
+	}
+}
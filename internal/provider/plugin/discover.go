@@ -0,0 +1,122 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BinaryPrefix is the file name prefix of plugin binaries: a binary named
+// daap-provider-rds provides the "rds" provider.
+const BinaryPrefix = "daap-provider-"
+
+// Discover returns the plugin binaries in dir, keyed by provider name. Files
+// without the BinaryPrefix or without an execute bit are ignored.
+func Discover(dir string) (map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("reading plugin directory: %w", err)
+	}
+
+	found := make(map[string]string)
+	for _, e := range entries {
+		name, ok := strings.CutPrefix(e.Name(), BinaryPrefix)
+		if !ok || name == "" || e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("inspecting %s: %w", e.Name(), err)
+		}
+		if !info.Mode().IsRegular() || info.Mode().Perm()&0o111 == 0 {
+			continue
+		}
+		found[name] = filepath.Join(dir, e.Name())
+	}
+	return found, nil
+}
+
+// ParseAddrs parses "name=host:port" entries into addresses keyed by
+// provider name.
+func ParseAddrs(entries []string) (map[string]string, error) {
+	addrs := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		name, addr, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || name == "" || addr == "" {
+			return nil, fmt.Errorf("invalid plugin address %q: want name=host:port", entry)
+		}
+		if _, dup := addrs[name]; dup {
+			return nil, fmt.Errorf("plugin %q listed twice", name)
+		}
+		addrs[name] = addr
+	}
+	return addrs, nil
+}
+
+// Set is the collection of plugins loaded at startup.
+type Set []*Client
+
+// Load launches every plugin binary found in dir and dials every plugin in
+// addrs ("name=host:port" entries). Either may be empty. If any plugin fails
+// to load, the plugins already started are closed and the error is returned.
+func Load(ctx context.Context, dir string, addrs []string) (Set, error) {
+	remote, err := ParseAddrs(addrs)
+	if err != nil {
+		return nil, err
+	}
+	local := map[string]string{}
+	if dir != "" {
+		if local, err = Discover(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	var set Set
+	fail := func(err error) (Set, error) {
+		_ = set.Close()
+		return nil, err
+	}
+
+	for _, name := range sortedKeys(local) {
+		if _, dup := remote[name]; dup {
+			return fail(fmt.Errorf("plugin %q is both a binary and an address", name))
+		}
+		c, err := Launch(ctx, name, local[name])
+		if err != nil {
+			return fail(err)
+		}
+		set = append(set, c)
+	}
+	for _, name := range sortedKeys(remote) {
+		c, err := Dial(name, remote[name])
+		if err != nil {
+			return fail(err)
+		}
+		set = append(set, c)
+	}
+	return set, nil
+}
+
+// Close closes every plugin in the set.
+func (s Set) Close() error {
+	var errs []error
+	for _, c := range s {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,218 @@
+// Package plugin runs provider plugins: providers shipped as separate binaries
+// or containers that speak the providerv1 gRPC protocol (see pkg/plugin). A
+// Client implements provider.Provider, so a plugin is registered and called
+// exactly like a built-in provider.
+package plugin
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/requestid"
+	sdk "github.com/daap14/daap/pkg/plugin"
+	"github.com/daap14/daap/pkg/plugin/providerv1"
+)
+
+// HandshakeTimeout bounds how long a launched plugin may take to announce
+// its address.
+const HandshakeTimeout = 10 * time.Second
+
+// Client is a provider.Provider backed by a plugin.
+type Client struct {
+	name string
+	conn *grpc.ClientConn
+	rpc  providerv1.ProviderPluginClient
+	cmd  *exec.Cmd // nil for plugins dialed by address
+}
+
+var _ provider.Provider = (*Client)(nil)
+
+// Dial connects to a plugin already listening on addr (host:port), such as a
+// plugin deployed as its own container.
+func Dial(name, addr string) (*Client, error) {
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return nil, fmt.Errorf("dialing plugin %s at %s: %w", name, addr, err)
+	}
+	return newClient(name, conn, nil), nil
+}
+
+// Launch starts the plugin binary at path, waits for its handshake and
+// connects to it. The process is stopped by Close. Its stderr is forwarded to
+// the log.
+func Launch(ctx context.Context, name, path string) (*Client, error) {
+	cmd := exec.Command(path)
+	cmd.Env = append(os.Environ(), sdk.MagicCookieKey+"="+sdk.MagicCookieValue)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, fmt.Errorf("plugin %s: %w", name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting plugin %s: %w", name, err)
+	}
+	go forwardLog(name, stderr)
+
+	target, err := readHandshake(ctx, stdout)
+	if err != nil {
+		stopProcess(cmd)
+		return nil, fmt.Errorf("plugin %s handshake: %w", name, err)
+	}
+	// Keep draining stdout so a chatty plugin never blocks on a full pipe.
+	go forwardLog(name, stdout)
+
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		stopProcess(cmd)
+		return nil, fmt.Errorf("connecting to plugin %s: %w", name, err)
+	}
+	return newClient(name, conn, cmd), nil
+}
+
+// NewClient wraps an existing connection to a plugin.
+func NewClient(name string, conn *grpc.ClientConn) *Client {
+	return newClient(name, conn, nil)
+}
+
+func newClient(name string, conn *grpc.ClientConn, cmd *exec.Cmd) *Client {
+	return &Client{name: name, conn: conn, rpc: providerv1.NewProviderPluginClient(conn), cmd: cmd}
+}
+
+// outgoing returns ctx with the request ID it carries, if any, added to the
+// metadata sent to the plugin.
+func outgoing(ctx context.Context) context.Context {
+	if id := requestid.From(ctx); id != "" {
+		return metadata.AppendToOutgoingContext(ctx, sdk.RequestIDMetadataKey, id)
+	}
+	return ctx
+}
+
+// Name returns the provider name the plugin is registered under.
+func (c *Client) Name() string { return c.name }
+
+// Apply calls the plugin's Apply.
+func (c *Client) Apply(ctx context.Context, db provider.ProviderDatabase, manifests string) error {
+	_, err := c.rpc.Apply(outgoing(ctx), &providerv1.ApplyRequest{Database: toProto(db), Manifests: manifests})
+	if err != nil {
+		return callError(c.name, "apply", err)
+	}
+	return nil
+}
+
+// Delete calls the plugin's Delete.
+func (c *Client) Delete(ctx context.Context, db provider.ProviderDatabase) error {
+	_, err := c.rpc.Delete(outgoing(ctx), &providerv1.DeleteRequest{Database: toProto(db)})
+	if err != nil {
+		return callError(c.name, "delete", err)
+	}
+	return nil
+}
+
+// CheckHealth calls the plugin's CheckHealth.
+func (c *Client) CheckHealth(ctx context.Context, db provider.ProviderDatabase) (provider.HealthResult, error) {
+	resp, err := c.rpc.CheckHealth(outgoing(ctx), &providerv1.CheckHealthRequest{Database: toProto(db)})
+	if err != nil {
+		return provider.HealthResult{}, callError(c.name, "check health", err)
+	}
+	return healthFromProto(resp), nil
+}
+
+// Close closes the connection and stops the plugin process, if it was
+// launched by Launch.
+func (c *Client) Close() error {
+	err := c.conn.Close()
+	if c.cmd != nil {
+		stopProcess(c.cmd)
+	}
+	return err
+}
+
+// readHandshake reads the "<version>|<network>|<address>" line a launched
+// plugin prints on stdout and returns the gRPC target to dial.
+func readHandshake(ctx context.Context, r io.Reader) (string, error) {
+	type result struct {
+		line string
+		err  error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		line, err := bufio.NewReader(r).ReadString('\n')
+		ch <- result{line, err}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, HandshakeTimeout)
+	defer cancel()
+
+	var res result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return "", errors.New("timed out waiting for handshake")
+	}
+	if res.err != nil {
+		return "", fmt.Errorf("reading handshake: %w", res.err)
+	}
+	return ParseHandshake(res.line)
+}
+
+// ParseHandshake parses a handshake line and returns the gRPC target to dial.
+func ParseHandshake(line string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(line), "|")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed handshake %q", line)
+	}
+	version, err := strconv.Atoi(parts[0])
+	if err != nil || version != sdk.ProtocolVersion {
+		return "", fmt.Errorf("unsupported plugin protocol version %q (want %d)", parts[0], sdk.ProtocolVersion)
+	}
+	switch parts[1] {
+	case "unix":
+		return "unix://" + parts[2], nil
+	case "tcp":
+		return parts[2], nil
+	default:
+		return "", fmt.Errorf("unsupported plugin network %q", parts[1])
+	}
+}
+
+func forwardLog(name string, r io.Reader) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		slog.Info("provider plugin output", "plugin", name, "line", scanner.Text())
+	}
+}
+
+// stopProcess asks the plugin to exit and kills it if it does not do so
+// promptly.
+func stopProcess(cmd *exec.Cmd) {
+	done := make(chan struct{})
+	go func() {
+		_ = cmd.Wait()
+		close(done)
+	}()
+	_ = cmd.Process.Signal(os.Interrupt)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		_ = cmd.Process.Kill()
+		<-done
+	}
+}
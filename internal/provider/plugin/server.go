@@ -0,0 +1,79 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/requestid"
+	sdk "github.com/daap14/daap/pkg/plugin"
+	"github.com/daap14/daap/pkg/plugin/providerv1"
+)
+
+// Server exposes an in-process provider.Provider over the plugin protocol,
+// so a built-in provider can also run out of process.
+type Server struct {
+	providerv1.UnimplementedProviderPluginServer
+	p provider.Provider
+}
+
+// NewServer returns a plugin server backed by p.
+func NewServer(p provider.Provider) *Server {
+	return &Server{p: p}
+}
+
+// incoming returns ctx carrying the request ID DAAP sent with the call, so
+// the wrapped provider sees it as it would in process.
+func incoming(ctx context.Context) context.Context {
+	if id := sdk.RequestID(ctx); id != "" {
+		return requestid.With(ctx, id)
+	}
+	return ctx
+}
+
+// Apply implements providerv1.ProviderPluginServer.
+func (s *Server) Apply(ctx context.Context, req *providerv1.ApplyRequest) (*providerv1.ApplyResponse, error) {
+	db, err := fromProto(req.GetDatabase())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.p.Apply(incoming(ctx), db, req.GetManifests()); err != nil {
+		return nil, serverError(err)
+	}
+	return &providerv1.ApplyResponse{}, nil
+}
+
+// Delete implements providerv1.ProviderPluginServer.
+func (s *Server) Delete(ctx context.Context, req *providerv1.DeleteRequest) (*providerv1.DeleteResponse, error) {
+	db, err := fromProto(req.GetDatabase())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := s.p.Delete(incoming(ctx), db); err != nil {
+		return nil, serverError(err)
+	}
+	return &providerv1.DeleteResponse{}, nil
+}
+
+// CheckHealth implements providerv1.ProviderPluginServer.
+func (s *Server) CheckHealth(ctx context.Context, req *providerv1.CheckHealthRequest) (*providerv1.CheckHealthResponse, error) {
+	db, err := fromProto(req.GetDatabase())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	h, err := s.p.CheckHealth(incoming(ctx), db)
+	if err != nil {
+		return nil, serverError(err)
+	}
+	return healthToProto(h), nil
+}
+
+func serverError(err error) error {
+	if errors.Is(err, provider.ErrUnavailable) {
+		return status.Error(codes.Unavailable, err.Error())
+	}
+	return status.Error(codes.Unknown, err.Error())
+}
@@ -2,10 +2,59 @@ package provider
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/google/uuid"
 )
 
+// ErrUnavailable indicates that a provider's backend could not be reached
+// (for example a plugin process that exited). Callers treat it as transient.
+var ErrUnavailable = errors.New("provider unavailable")
+
+// ErrNotSupported is returned by optional provider operations that a provider
+// does not implement. Wrappers (see Wrapper) implement every optional
+// interface and return it from those the wrapped provider lacks, so a
+// successful type assertion does not mean an operation is supported: callers
+// handle ErrNotSupported whatever the assertion said, and use Supports to
+// check a capability before calling it.
+var ErrNotSupported = errors.New("operation not supported by provider")
+
+// Wrapper is implemented by providers that wrap another one to add behaviour
+// to its calls, such as a circuit breaker or fault injection.
+type Wrapper interface {
+	// Unwrap returns the wrapped provider.
+	Unwrap() Provider
+}
+
+// Supports reports whether p, or the provider it wraps, implements the
+// optional interface C.
+func Supports[C any](p Provider) bool {
+	for {
+		w, ok := p.(Wrapper)
+		if !ok {
+			break
+		}
+		p = w.Unwrap()
+	}
+	_, ok := p.(C)
+	return ok
+}
+
+// Labels every provider must set on the resources it creates for a database.
+const (
+	LabelDatabase       = "daap.io/database"
+	LabelManagedBy      = "app.kubernetes.io/managed-by"
+	LabelManagedByValue = "daap"
+)
+
+// AnnotationRequestID is set by providers on the resources they apply to the
+// ID of the API request, or background operation, that applied them last, as
+// carried by the context (see package requestid). An annotation rather than
+// a per-request field manager keeps DAAP the single owner of the fields it
+// applies.
+const AnnotationRequestID = "daap.io/request-id"
+
 // Provider abstracts infrastructure backends (CNPG, RDS, etc.).
 type Provider interface {
 	// Apply templates the blueprint manifests and creates/updates all resources.
@@ -31,6 +80,70 @@ type ProviderDatabase struct {
 	TierID      uuid.UUID
 	Blueprint   string
 	Provider    string
+	// Labels and Annotations are the owner team's defaults for the
+	// resources of the database. Providers add them to every resource they
+	// create, without overriding the ones the blueprint sets.
+	Labels      map[string]string
+	Annotations map[string]string
+	// Topology constrains where the database's instances run, from its
+	// tier. Providers that schedule instances enforce it over what the
+	// blueprint sets.
+	Topology Topology
+	// Disruption is how the database weathers node drains and evictions,
+	// from its tier.
+	Disruption Disruption
+	// Images maps image references of the manifests to the images to run
+	// instead, mirrored and pinned by the platform's image policy when the
+	// database was provisioned. Providers replace them in the resources
+	// they create.
+	Images map[string]string
+	// Exposure is how the database is reachable from outside the cluster.
+	// Providers put it behind a load balancer of the kind it names.
+	Exposure Exposure
+	// DNSName is the friendly hostname to publish for the database, or
+	// empty for none. Providers publish it for the address clients connect
+	// to.
+	DNSName string
+}
+
+// Zone spread modes of a Topology.
+const (
+	ZoneSpreadPreferred = "preferred"
+	ZoneSpreadRequired  = "required"
+)
+
+// Topology constrains where a database's instances run. ZoneSpread keeps
+// them in different zones, when possible (ZoneSpreadPreferred) or always
+// (ZoneSpreadRequired); empty leaves it to the blueprint. NodeSelector
+// restricts them to nodes with all of its labels.
+type Topology struct {
+	ZoneSpread   string
+	NodeSelector map[string]string
+}
+
+// Disruption sets how a database weathers voluntary disruptions. MinAvailable,
+// when positive, is the number of instances evictions must leave running;
+// zero leaves it to the provider. PriorityClassName, when set, is the
+// Kubernetes PriorityClass of the instances.
+type Disruption struct {
+	MinAvailable      int
+	PriorityClassName string
+}
+
+// Exposure types of an Exposure.
+const (
+	ExposureCluster  = "cluster"
+	ExposureInternal = "internal"
+	ExposureExternal = "external"
+)
+
+// Exposure sets how a database is reachable. Type ExposureCluster, or empty,
+// keeps it inside the cluster; ExposureInternal and ExposureExternal put it
+// behind an internal or internet-facing load balancer that accepts
+// connections from AllowedSourceRanges, or from anywhere if it is empty.
+type Exposure struct {
+	Type                string
+	AllowedSourceRanges []string
 }
 
 // HealthResult represents the health status returned by a provider.
@@ -39,4 +152,288 @@ type HealthResult struct {
 	Host       *string
 	Port       *int
 	SecretName *string
+	Instances  *InstanceStatus // nil if the provider does not report instances
+	// Reason is a machine-readable cause of the status, e.g.
+	// FAILED_TO_CREATE_PRIMARY, and Message a human-readable detail of it, e.g.
+	// why the database failed. Both are empty if the provider has nothing
+	// to add.
+	Reason  string
+	Message string
+	// ExternalHost is the hostname or address of the load balancer of a
+	// database exposed outside the cluster, or empty until it has one.
+	ExternalHost string
+}
+
+// InstanceStatus reports the instances backing a database, so a database
+// that is ready but running with fewer replicas than requested is visible.
+type InstanceStatus struct {
+	Total   int    // instances requested
+	Ready   int    // instances ready to serve
+	Primary string // name of the current primary; empty if none is elected
+	// ReplicationLag is the largest lag of a replica behind the primary, or
+	// nil if it is unknown.
+	ReplicationLag *time.Duration
+}
+
+// StorageUsage reports a database's storage.
+type StorageUsage struct {
+	CapacityBytes int64 // currently requested storage size per instance
+	UsedBytes     int64 // bytes used on the fullest instance
+}
+
+// StorageScaler is implemented by providers that can report and grow a
+// database's storage. It is optional: callers type-assert a Provider and treat
+// ErrNotSupported as "cannot autoscale".
+type StorageScaler interface {
+	// StorageUsage returns the current storage capacity and usage.
+	StorageUsage(ctx context.Context, db ProviderDatabase) (StorageUsage, error)
+
+	// ResizeStorage grows the database's storage to sizeBytes per instance.
+	ResizeStorage(ctx context.Context, db ProviderDatabase, sizeBytes int64) error
+}
+
+// ComputeResources is an amount of CPU and memory per instance.
+type ComputeResources struct {
+	CPUMillis   int64
+	MemoryBytes int64
+}
+
+// ComputeUsage reports a database's compute requests and usage.
+type ComputeUsage struct {
+	Requests ComputeResources // currently requested per instance
+	Used     ComputeResources // usage of the busiest instance
+}
+
+// ComputeReporter is implemented by providers that can report a database's
+// CPU and memory usage. It is optional: callers type-assert a Provider and
+// treat ErrNotSupported as "no usage data".
+type ComputeReporter interface {
+	// ComputeUsage returns the current compute requests and usage.
+	ComputeUsage(ctx context.Context, db ProviderDatabase) (ComputeUsage, error)
+
+	// ManifestCompute returns the per-instance compute requested by blueprint
+	// manifests, so that tiers can be compared by size.
+	ManifestCompute(manifests string) (ComputeResources, error)
+}
+
+// PoolerUsage reports the connections of a database's pooler, summed over
+// its instances.
+type PoolerUsage struct {
+	Instances      int   // pooler instances reporting
+	ActiveClients  int64 // client connections paired with a server connection
+	WaitingClients int64 // client connections queued for a server connection
+	ActiveServers  int64 // server connections serving a client
+	IdleServers    int64 // server connections open but unused
+	MaxClients     int64 // client connections the pooler accepts
+	PoolSize       int64 // server connections the pooler may open
+}
+
+// PoolerReporter is implemented by providers that can report a database's
+// pooler connections. It is optional: callers type-assert a Provider and
+// treat ErrNotSupported as "no pooler data", which includes databases
+// without a pooler.
+type PoolerReporter interface {
+	// PoolerUsage returns the current pooler connections.
+	PoolerUsage(ctx context.Context, db ProviderDatabase) (PoolerUsage, error)
+}
+
+// ConnectionReporter is implemented by providers that can tell how many
+// connections databases provisioned from blueprint manifests accept, so that
+// team connection budgets can be enforced. It is optional: callers type-assert
+// a Provider and treat ErrNotSupported as "uncounted".
+type ConnectionReporter interface {
+	// ManifestConnections returns the max_connections of a database
+	// provisioned from blueprint manifests.
+	ManifestConnections(manifests string) (int, error)
+}
+
+// ManifestRenderer is implemented by providers that can render blueprint
+// manifests without applying them. It is optional: callers type-assert a
+// Provider and treat ErrNotSupported as "cannot render".
+type ManifestRenderer interface {
+	// RenderManifests returns the resources Apply would create or update for
+	// the database, including the labels the provider injects, as
+	// multi-document YAML.
+	RenderManifests(db ProviderDatabase, manifests string) (string, error)
+}
+
+// DeletionState reports how far the deletion of a database's resources got.
+type DeletionState string
+
+const (
+	// DeletionDeleting means some resources still exist, typically because
+	// finalizers have not completed yet.
+	DeletionDeleting DeletionState = "deleting"
+	// DeletionGone means all resources have been removed.
+	DeletionGone DeletionState = "gone"
+)
+
+// DeletionConfirmer is implemented by providers that can delete a database's
+// resources with foreground propagation and confirm their removal. It is
+// optional: callers type-assert a Provider and treat ErrNotSupported as "use
+// Delete and assume the resources are gone".
+type DeletionConfirmer interface {
+	// DeleteForeground deletes the database's resources, dependents first,
+	// and waits up to wait for them to disappear. With a zero wait it only
+	// checks once, so calling it again reports whether an earlier deletion
+	// finished. Resources still present when wait elapses keep being deleted.
+	DeleteForeground(ctx context.Context, db ProviderDatabase, wait time.Duration) (DeletionState, error)
+}
+
+// ErrInvalidTarget is returned by Switchover when the requested instance, or
+// any instance if none was requested, cannot become the primary.
+var ErrInvalidTarget = errors.New("no eligible switchover target")
+
+// PrimarySwitcher is implemented by providers that can move a database's
+// primary to another instance. It is optional: callers type-assert a Provider
+// and treat ErrNotSupported as "cannot fail over".
+type PrimarySwitcher interface {
+	// Switchover starts a supervised switchover to target, a healthy replica,
+	// or to one the provider picks when target is empty, and returns the
+	// instance being promoted. It returns once the switchover is requested;
+	// the provider then reports the new primary in its health results.
+	Switchover(ctx context.Context, db ProviderDatabase, target string) (string, error)
+}
+
+// Restarter is implemented by providers that can restart a database's
+// instances one at a time, e.g. to pick up parameter changes that need a
+// restart. It is optional: callers type-assert a Provider and treat
+// ErrNotSupported as "cannot restart".
+type Restarter interface {
+	// Restart starts a rolling restart of the database's instances and
+	// returns once it is requested.
+	Restart(ctx context.Context, db ProviderDatabase) error
+	// Restarted reports whether every instance has restarted since the last
+	// Restart and is running again.
+	Restarted(ctx context.Context, db ProviderDatabase) (bool, error)
+}
+
+// CredentialRotator is implemented by providers that can regenerate the
+// password of a database's application user. It is optional: callers
+// type-assert a Provider and treat ErrNotSupported as "cannot rotate".
+type CredentialRotator interface {
+	// RotateCredentials sets a new random password for the application
+	// user in the secret holding its credentials, which the database then
+	// picks up. Connections opened with the old password keep working until
+	// they close.
+	RotateCredentials(ctx context.Context, db ProviderDatabase) error
+}
+
+// Freezer is implemented by providers that can scale a database down to no
+// running instances while keeping its storage, and bring it back. It is
+// optional: callers type-assert a Provider and treat ErrNotSupported as
+// "cannot freeze".
+type Freezer interface {
+	// Freeze stops the database's instances, keeping its data, and returns
+	// once it is requested.
+	Freeze(ctx context.Context, db ProviderDatabase) error
+	// Unfreeze starts the instances of a frozen database again and returns
+	// once it is requested; the database reports ready once they run.
+	Unfreeze(ctx context.Context, db ProviderDatabase) error
+}
+
+// OperatorVersioner is implemented by providers whose resources are run by a
+// Kubernetes operator, to tell which operator version a database runs under.
+// It is optional: callers type-assert a Provider and treat ErrNotSupported as
+// "unknown".
+type OperatorVersioner interface {
+	// OperatorVersion returns the version of the operator running the
+	// database's resources, or "" if it cannot tell yet, e.g. while they are
+	// being rolled over to a new operator version.
+	OperatorVersion(ctx context.Context, db ProviderDatabase) (string, error)
+}
+
+// DiagnosticFile is a file of diagnostics about a database's resources.
+type DiagnosticFile struct {
+	Name    string // slash-separated path, e.g. "logs/daap-orders-1.log"
+	Content []byte
+}
+
+// Diagnoser is implemented by providers that can gather diagnostics about a
+// database's resources, such as their status, events and logs, for a support
+// bundle. It is optional: callers type-assert a Provider and treat
+// ErrNotSupported as "no diagnostics".
+type Diagnoser interface {
+	// Diagnostics gathers what it can. When some of it cannot be read, it
+	// returns the files it did gather along with an error describing the
+	// rest.
+	Diagnostics(ctx context.Context, db ProviderDatabase) ([]DiagnosticFile, error)
+}
+
+// Archive is the progress of a database's final backup.
+type Archive struct {
+	URL  string // where the backup is stored, once known
+	Done bool   // whether the backup has completed
+}
+
+// Archiver is implemented by providers that can take a final backup of a
+// database to an object store before its resources are deleted, for tiers
+// whose destruction strategy is "archive". It is optional: callers
+// type-assert a Provider and treat ErrNotSupported as "cannot archive".
+type Archiver interface {
+	// Archive starts a backup of the database to location, e.g.
+	// "s3://backups/checkout", unless one was already started, and reports
+	// its progress. Calling it again reports the progress of the same
+	// backup. It fails if the backup failed.
+	Archive(ctx context.Context, db ProviderDatabase, location string) (Archive, error)
+}
+
+// Cloner is implemented by providers that can copy a database into new
+// resources while it keeps serving, to rename it. It is optional: callers
+// type-assert a Provider and treat ErrNotSupported as "cannot rename".
+type Cloner interface {
+	// Clone creates db's resources from manifests as a replica of source,
+	// an existing database, that streams its changes until promoted. It
+	// returns once the resources are requested; the provider reports db
+	// ready once the replica has caught up.
+	Clone(ctx context.Context, db, source ProviderDatabase, manifests string) error
+	// Promote stops source from accepting writes and makes db, a clone of
+	// it, a primary of its own. It returns once the promotion is requested;
+	// the provider reports db ready once it accepts writes.
+	Promote(ctx context.Context, db, source ProviderDatabase) error
+	// Unfence lets source accept writes again after Promote, when its
+	// promoted clone is abandoned. It is a no-op when source was never
+	// stopped.
+	Unfence(ctx context.Context, source ProviderDatabase) error
+}
+
+// ErrBackupNotFound is returned by Restore when there is no backup of the
+// source database to restore from.
+var ErrBackupNotFound = errors.New("backup not found")
+
+// RestoreTarget is the state a restore recovers a database to: that of
+// Backup, or of the latest backup when it is empty, replayed up to Time, or
+// as far as the archived changes go when Time is nil.
+type RestoreTarget struct {
+	Backup string
+	Time   *time.Time
+}
+
+// Restorer is implemented by providers that can provision a database from
+// the backups of another. It is optional: callers type-assert a Provider
+// and treat ErrNotSupported as "cannot restore".
+type Restorer interface {
+	// Restore creates db's resources from manifests, with its data
+	// recovered from the backups of source, an existing database, as of
+	// target. It returns ErrBackupNotFound if source has no such backup. It
+	// returns once the resources are requested; the provider reports db
+	// ready once the recovery is done.
+	Restore(ctx context.Context, db, source ProviderDatabase, manifests string, target RestoreTarget) error
+}
+
+// ConfirmDeletion deletes the database's resources through p and reports
+// whether they are gone, waiting up to wait when p is a DeletionConfirmer.
+// Providers that cannot confirm deletions are assumed to remove everything in
+// Delete.
+func ConfirmDeletion(ctx context.Context, p Provider, db ProviderDatabase, wait time.Duration) (DeletionState, error) {
+	if confirmer, ok := p.(DeletionConfirmer); ok {
+		state, err := confirmer.DeleteForeground(ctx, db, wait)
+		if !errors.Is(err, ErrNotSupported) {
+			return state, err
+		}
+	}
+	if err := p.Delete(ctx, db); err != nil {
+		return "", err
+	}
+	return DeletionGone, nil
 }
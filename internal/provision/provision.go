@@ -0,0 +1,186 @@
+// Package provision applies the blueprint of newly created databases. POST
+// /databases enqueues a provision job for each one, which a jobs.Worker runs
+// with a Provisioner, retrying failed applies.
+package provision
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// Create failure policies, selected with ?onFailure= on POST /databases and
+// carried by provision jobs as their "onFailure" parameter.
+const (
+	OnFailureError    = "error"    // keep the record in status error (default)
+	OnFailureRollback = "rollback" // delete the record and what was applied
+)
+
+// Provisioner runs provision jobs: it applies the blueprint of the job's
+// database through its provider and reports on the create operation. Once
+// the blueprint is applied, the reconciler takes over and completes the
+// operation when the database becomes ready.
+type Provisioner struct {
+	repo       database.Repository
+	tiers      tier.Repository
+	blueprints blueprint.Repository
+	registry   *provider.Registry
+	specs      database.SpecRepository
+	ops        *operation.Tracker
+	signer     *blueprint.Signer
+}
+
+// Option configures a Provisioner.
+type Option func(*Provisioner)
+
+// WithSpecs records the spec each database is provisioned with in specs.
+func WithSpecs(specs database.SpecRepository) Option {
+	return func(p *Provisioner) {
+		p.specs = specs
+	}
+}
+
+// WithOperations reports progress and failures on the operations of ops.
+func WithOperations(ops *operation.Tracker) Option {
+	return func(p *Provisioner) {
+		p.ops = ops
+	}
+}
+
+// WithSigner refuses to provision from blueprints that do not match their
+// signature.
+func WithSigner(signer *blueprint.Signer) Option {
+	return func(p *Provisioner) {
+		p.signer = signer
+	}
+}
+
+// New creates a Provisioner.
+func New(repo database.Repository, tiers tier.Repository, blueprints blueprint.Repository, registry *provider.Registry, opts ...Option) *Provisioner {
+	p := &Provisioner{repo: repo, tiers: tiers, blueprints: blueprints, registry: registry}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p
+}
+
+// Run is the jobs.Handler of provision jobs. A database that is gone or no
+// longer provisioning, e.g. because it was deleted while its job was
+// queued, needs nothing. When the job fails for good, the database is
+// compensated as its onFailure parameter says and the operation fails.
+func (p *Provisioner) Run(ctx context.Context, job *jobs.Job) error {
+	db, err := p.repo.GetByID(ctx, job.DatabaseID)
+	if errors.Is(err, database.ErrNotFound) {
+		slog.Info("database of provision job is gone; nothing to provision", "database", job.DatabaseID)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("getting database: %w", err)
+	}
+	if db.Status != "provisioning" || db.TierID == nil {
+		return nil
+	}
+	op := p.operation(ctx, job)
+	rollback := job.Params["onFailure"] == OnFailureRollback
+
+	// Nothing has been applied until Apply is called.
+	fail := func(code, message string, err error) error {
+		return p.failed(ctx, job, db, op, nil, provider.ProviderDatabase{}, rollback, code, message, err)
+	}
+	t, err := p.tiers.GetByID(ctx, *db.TierID)
+	if err != nil {
+		return fail("INTERNAL_ERROR", "Failed to look up the tier", fmt.Errorf("getting tier: %w", err))
+	}
+	if t.BlueprintID == nil {
+		return nil
+	}
+	bp, err := p.blueprints.GetByID(ctx, *t.BlueprintID)
+	if err != nil {
+		return fail("INTERNAL_ERROR", "Failed to look up the blueprint", fmt.Errorf("getting blueprint: %w", err))
+	}
+	if err := p.signer.Verify(bp); err != nil {
+		return fail("BLUEPRINT_SIGNATURE_INVALID", fmt.Sprintf("Refusing to provision from blueprint %s: %v", bp.Name, errors.Unwrap(err)), jobs.Permanent(err))
+	}
+	prov, ok := p.registry.Get(bp.Provider)
+	if !ok {
+		return fail("PROVIDER_NOT_REGISTERED", fmt.Sprintf("Provider %q is not registered", bp.Provider),
+			jobs.Permanent(fmt.Errorf("provider %q is not registered", bp.Provider)))
+	}
+
+	pdb := db.ProviderDatabase(t, bp)
+	if err := prov.Apply(ctx, pdb, bp.Manifests); err != nil {
+		slog.Error("provider.Apply failed", "error", err, "database", db.Name, "provider", bp.Provider, "attempt", job.Attempts)
+		return p.failed(ctx, job, db, op, prov, pdb, rollback, "APPLY_FAILED",
+			fmt.Sprintf("Applying blueprint %s failed: %v", bp.Name, err), err)
+	}
+	database.RecordSpec(ctx, p.specs, db, t, bp)
+	p.ops.Progress(ctx, op, 50, "Waiting for the database to become ready")
+	return nil
+}
+
+// failed reports a failed attempt, described by message, and returns err for
+// the worker. While the job will be retried, the operation only says so; on
+// the last attempt, or for a Permanent err, the create is compensated and
+// the operation fails with code.
+func (p *Provisioner) failed(ctx context.Context, job *jobs.Job, db *database.Database, op *operation.Operation,
+	prov provider.Provider, pdb provider.ProviderDatabase, rollback bool, code, message string, err error) error {
+	if !job.LastAttempt() && !jobs.IsPermanent(err) {
+		p.ops.Progress(ctx, op, 0, fmt.Sprintf("%s; retrying (attempt %d of %d)", message, job.Attempts, job.MaxAttempts))
+		return err
+	}
+	if Fail(ctx, p.repo, db, prov, pdb, rollback) {
+		message += "; the database was rolled back"
+	}
+	p.ops.Fail(ctx, op, code, message)
+	return err
+}
+
+// operation returns the operation the job reports to, or nil if it has none
+// or it cannot be read.
+func (p *Provisioner) operation(ctx context.Context, job *jobs.Job) *operation.Operation {
+	if p.ops == nil || job.OperationID == nil {
+		return nil
+	}
+	op, err := p.ops.Get(ctx, *job.OperationID)
+	if err != nil {
+		slog.Error("failed to get operation of provision job", "error", err, "operation", job.OperationID)
+		return nil
+	}
+	return op
+}
+
+// Fail compensates a create whose provisioning failed. By default the record
+// is kept in status error, so the failure can be inspected. With rollback,
+// the resources p applied so far are deleted and the record is soft-deleted,
+// freeing its name; p is nil when nothing was applied. If the resources
+// cannot be deleted, the record is kept in error rather than orphaning them.
+// It reports whether the database was rolled back.
+func Fail(ctx context.Context, repo database.Repository, db *database.Database, p provider.Provider, pdb provider.ProviderDatabase, rollback bool) bool {
+	if rollback {
+		var err error
+		if p != nil {
+			err = p.Delete(ctx, pdb)
+		}
+		if err == nil {
+			err = repo.SoftDelete(ctx, db.ID)
+		}
+		if err == nil {
+			slog.Info("rolled back failed create", "database", db.Name)
+			return true
+		}
+		slog.Error("failed to roll back create, leaving database in error", "error", err, "database", db.Name)
+	}
+	if _, err := repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "error"}); err != nil {
+		slog.Error("failed to mark database as error", "error", err, "database", db.Name)
+	}
+	db.Status = "error"
+	return false
+}
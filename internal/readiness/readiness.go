@@ -0,0 +1,134 @@
+// Package readiness verifies that a database reported healthy by its provider
+// can actually serve traffic: it opens connections through the pooler with the
+// application credentials and runs a query on each.
+package readiness
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// DefaultQuery is the readiness query used when none is configured.
+const DefaultQuery = "SELECT 1"
+
+var secretsGVR = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+// Gate opens Connections connections to a database and runs Query on each.
+// All connections are held open at the same time, so a pooler that cannot
+// hand out that many server connections fails the gate.
+type Gate struct {
+	client      dynamic.Interface
+	connections int
+	query       string
+	timeout     time.Duration
+}
+
+// New creates a Gate that reads credentials with client. A query of "" uses
+// DefaultQuery; connections below 1 are raised to 1.
+func New(client dynamic.Interface, connections int, query string, timeout time.Duration) *Gate {
+	if connections < 1 {
+		connections = 1
+	}
+	if query == "" {
+		query = DefaultQuery
+	}
+	return &Gate{client: client, connections: connections, query: query, timeout: timeout}
+}
+
+// Check verifies the database described by db and its health result. It
+// fails if the health result has no connection details, the credentials
+// secret cannot be read, or any connection or query fails within the timeout.
+func (g *Gate) Check(ctx context.Context, db provider.ProviderDatabase, health provider.HealthResult) error {
+	if health.Host == nil || health.Port == nil || health.SecretName == nil {
+		return errors.New("provider reported no connection details")
+	}
+
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	cfg, err := ConnConfig(ctx, g.client, db.Namespace, *health.SecretName, *health.Host, *health.Port)
+	if err != nil {
+		return err
+	}
+
+	conns := make([]*pgx.Conn, 0, g.connections)
+	defer func() {
+		for _, c := range conns {
+			_ = c.Close(context.Background())
+		}
+	}()
+	for i := range g.connections {
+		conn, err := pgx.ConnectConfig(ctx, cfg.Copy())
+		if err != nil {
+			return fmt.Errorf("opening connection %d of %d: %w", i+1, g.connections, err)
+		}
+		conns = append(conns, conn)
+	}
+	for i, conn := range conns {
+		if _, err := conn.Exec(ctx, g.query); err != nil {
+			return fmt.Errorf("readiness query on connection %d of %d: %w", i+1, g.connections, err)
+		}
+	}
+	return nil
+}
+
+// ConnConfig builds a config for connecting to host:port with the
+// credentials in the secret secretName, read with client. CNPG application
+// secrets hold username, password and dbname keys.
+func ConnConfig(ctx context.Context, client dynamic.Interface, namespace, secretName, host string, port int) (*pgx.ConnConfig, error) {
+	secret, err := client.Resource(secretsGVR).Namespace(namespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("reading secret %s/%s: %w", namespace, secretName, err)
+	}
+	user, err := secretValue(secret, "username")
+	if err != nil {
+		return nil, err
+	}
+	password, err := secretValue(secret, "password")
+	if err != nil {
+		return nil, err
+	}
+	dbname, err := secretValue(secret, "dbname")
+	if err != nil {
+		return nil, err
+	}
+
+	// Build through ParseConfig so pgx fills in its defaults; credentials are
+	// set on the struct to avoid quoting them into the DSN.
+	cfg, err := pgx.ParseConfig("postgres://" + net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return nil, fmt.Errorf("building connection config: %w", err)
+	}
+	cfg.User = user
+	cfg.Password = password
+	cfg.Database = dbname
+	return cfg, nil
+}
+
+func secretValue(secret *unstructured.Unstructured, key string) (string, error) {
+	encoded, found, _ := unstructured.NestedString(secret.Object, "data", key)
+	if !found {
+		return "", fmt.Errorf("secret %s has no %q key", secret.GetName(), key)
+	}
+	value, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("decoding %q in secret %s: %w", key, secret.GetName(), err)
+	}
+	return string(value), nil
+}
@@ -0,0 +1,574 @@
+// Package recommend suggests compute tier changes for databases. It samples
+// CPU and memory usage through the provider, compares it with the compute
+// each tier's blueprint requests, and can move databases to the recommended
+// tier automatically during a maintenance window.
+package recommend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/budget"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/metrics"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// Actor is recorded on tier changes applied by the recommender, and as who
+// last updated the databases it moves.
+const Actor = "system:recommender"
+
+// DefaultMinSamples is the number of samples needed before recommending.
+const DefaultMinSamples = 12
+
+// Actions of a Recommendation.
+const (
+	ActionUpsize   = "upsize"
+	ActionDownsize = "downsize"
+)
+
+// Sizing thresholds, as percentages of the tier's requested compute. A tier
+// fits when CPU p95 stays under cpuTargetPercent and peak memory under
+// memTargetPercent of its requests. A database is oversized when both CPU
+// and memory stay under the downsize thresholds.
+const (
+	cpuTargetPercent   = 70
+	memTargetPercent   = 80
+	cpuDownsizePercent = 25
+	memDownsizePercent = 40
+)
+
+var (
+	tierChanges = metrics.NewCounter(
+		"daap_database_tier_changes_total",
+		"Number of tier changes applied by the recommender.",
+	)
+	tierChangeFailures = metrics.NewCounter(
+		"daap_database_tier_change_failures_total",
+		"Number of tier changes the recommender failed to apply.",
+	)
+)
+
+// Usage summarizes a database's sampled compute usage.
+type Usage struct {
+	CPUP95Millis       int64
+	CPURequestMillis   int64
+	MemoryMaxBytes     int64
+	MemoryRequestBytes int64
+}
+
+// Recommendation is a suggested move to another tier.
+type Recommendation struct {
+	Action string // ActionUpsize or ActionDownsize
+	Tier   string
+	Reason string
+}
+
+// Result is the recommender's assessment of one database.
+type Result struct {
+	CurrentTier     string
+	SampleCount     int
+	MinSamples      int
+	Usage           Usage
+	Recommendations []Recommendation
+	AutoApply       bool
+	Window          string
+}
+
+// Recommender samples compute usage of ready databases and recommends tier
+// changes.
+type Recommender struct {
+	repo     database.Repository
+	tierRepo tier.Repository
+	bpRepo   blueprint.Repository
+	registry *provider.Registry
+	samples  database.UsageSampleRepository
+	changes  database.TierChangeRepository
+	interval time.Duration
+	lookback time.Duration
+
+	minSamples int
+	autoApply  bool
+	window     Window
+	freezes    freeze.Gate
+	locker     *database.Locker
+	specs      database.SpecRepository
+	signer     *blueprint.Signer
+	budgets    budget.Gate
+	now        func() time.Time
+}
+
+// Option configures a Recommender.
+type Option func(*Recommender)
+
+// WithAutoApply enables applying recommendations automatically while the
+// current time is within w.
+func WithAutoApply(w Window) Option {
+	return func(r *Recommender) {
+		r.autoApply = true
+		r.window = w
+	}
+}
+
+// WithFreezes defers automatic tier changes of databases whose team is under
+// a change freeze.
+func WithFreezes(g freeze.Gate) Option {
+	return func(r *Recommender) {
+		r.freezes = g
+	}
+}
+
+// WithLocker takes the database's mutation lock around automatic tier
+// changes. A database locked by another operation is left for a later pass.
+func WithLocker(l *database.Locker) Option {
+	return func(r *Recommender) {
+		r.locker = l
+	}
+}
+
+// WithSpecs records the spec a database is applied with by an automatic tier
+// change in specs.
+func WithSpecs(specs database.SpecRepository) Option {
+	return func(r *Recommender) {
+		r.specs = specs
+	}
+}
+
+// WithSigner only moves databases automatically to tiers whose blueprint
+// signer verifies.
+func WithSigner(signer *blueprint.Signer) Option {
+	return func(r *Recommender) {
+		r.signer = signer
+	}
+}
+
+// WithBudgets only moves databases automatically to tiers their team's
+// connection budget leaves room for.
+func WithBudgets(g budget.Gate) Option {
+	return func(r *Recommender) {
+		r.budgets = g
+	}
+}
+
+// WithMinSamples sets the number of samples needed before recommending. The
+// default is DefaultMinSamples.
+func WithMinSamples(n int) Option {
+	return func(r *Recommender) {
+		r.minSamples = n
+	}
+}
+
+// WithClock sets the function used to read the current time.
+func WithClock(now func() time.Time) Option {
+	return func(r *Recommender) {
+		r.now = now
+	}
+}
+
+// New creates a new Recommender. Samples older than lookback are discarded.
+func New(repo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, samples database.UsageSampleRepository, changes database.TierChangeRepository, interval, lookback time.Duration, opts ...Option) *Recommender {
+	r := &Recommender{
+		repo:       repo,
+		tierRepo:   tierRepo,
+		bpRepo:     bpRepo,
+		registry:   registry,
+		samples:    samples,
+		changes:    changes,
+		interval:   interval,
+		lookback:   lookback,
+		minSamples: DefaultMinSamples,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Start begins the sampling loop. It blocks until ctx is cancelled.
+func (r *Recommender) Start(ctx context.Context) {
+	slog.Info("tier recommender started", "interval", r.interval.String(), "lookback", r.lookback.String(),
+		"autoApply", r.autoApply, "window", r.window.String())
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("tier recommender stopped")
+			return
+		case <-ticker.C:
+			r.collect(ctx)
+		}
+	}
+}
+
+// RunOnce performs a single pass over all ready databases.
+func (r *Recommender) RunOnce(ctx context.Context) {
+	r.collect(ctx)
+}
+
+func (r *Recommender) collect(ctx context.Context) {
+	status := "ready"
+	result, err := r.repo.List(ctx, database.ListFilter{
+		Status: &status,
+		Page:   1,
+		Limit:  100,
+	})
+	if err != nil {
+		slog.Error("recommend: failed to list databases", "error", err)
+		return
+	}
+
+	for _, db := range result.Databases {
+		if ctx.Err() != nil {
+			return
+		}
+		r.sample(ctx, &db)
+	}
+
+	if _, err := r.samples.DeleteBefore(ctx, r.now().Add(-r.lookback)); err != nil {
+		slog.Error("recommend: failed to prune usage samples", "error", err)
+	}
+
+	if !r.autoApply || !r.window.Contains(r.now()) {
+		return
+	}
+	for _, db := range result.Databases {
+		if ctx.Err() != nil {
+			return
+		}
+		r.apply(ctx, &db)
+	}
+}
+
+func (r *Recommender) sample(ctx context.Context, db *database.Database) {
+	if db.TierID == nil {
+		return
+	}
+	t, bp, reporter, err := r.resolve(ctx, *db.TierID)
+	if err != nil {
+		slog.Warn("recommend: failed to resolve tier", "database", db.Name, "tierID", db.TierID, "error", err)
+		return
+	}
+	if reporter == nil {
+		return
+	}
+
+	usage, err := reporter.ComputeUsage(ctx, db.ProviderDatabase(t, bp))
+	if errors.Is(err, provider.ErrNotSupported) {
+		return
+	}
+	if err != nil {
+		slog.Warn("recommend: failed to read compute usage", "database", db.Name, "provider", bp.Provider, "error", err)
+		return
+	}
+
+	s := &database.UsageSample{
+		DatabaseID:         db.ID,
+		CPURequestMillis:   usage.Requests.CPUMillis,
+		CPUUsedMillis:      usage.Used.CPUMillis,
+		MemoryRequestBytes: usage.Requests.MemoryBytes,
+		MemoryUsedBytes:    usage.Used.MemoryBytes,
+	}
+	if err := r.samples.Record(ctx, s); err != nil {
+		slog.Error("recommend: failed to record usage sample", "database", db.Name, "error", err)
+	}
+}
+
+// resolve returns a tier, its blueprint and the compute reporter of the
+// blueprint's provider. The reporter is nil when the tier has no blueprint or
+// its provider cannot report compute usage.
+func (r *Recommender) resolve(ctx context.Context, tierID uuid.UUID) (*tier.Tier, *blueprint.Blueprint, provider.ComputeReporter, error) {
+	t, err := r.tierRepo.GetByID(ctx, tierID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if t.BlueprintID == nil {
+		return t, nil, nil, nil
+	}
+	bp, err := r.bpRepo.GetByID(ctx, *t.BlueprintID)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("getting blueprint: %w", err)
+	}
+	p, ok := r.registry.Get(bp.Provider)
+	if !ok {
+		return t, bp, nil, nil
+	}
+	reporter, _ := p.(provider.ComputeReporter)
+	return t, bp, reporter, nil
+}
+
+// candidate is a tier with the compute its blueprint requests.
+type candidate struct {
+	tier      *tier.Tier
+	blueprint *blueprint.Blueprint
+	compute   provider.ComputeResources
+}
+
+// Recommend assesses a database's sampled usage against the available tiers.
+func (r *Recommender) Recommend(ctx context.Context, db *database.Database) (*Result, error) {
+	result, _, err := r.recommend(ctx, db)
+	return result, err
+}
+
+// recommend returns the assessment and the candidate of its first
+// recommendation, if any.
+func (r *Recommender) recommend(ctx context.Context, db *database.Database) (*Result, *candidate, error) {
+	result := &Result{
+		MinSamples:      r.minSamples,
+		Recommendations: []Recommendation{},
+		AutoApply:       r.autoApply,
+		Window:          r.window.String(),
+	}
+	if db.TierID == nil {
+		return result, nil, nil
+	}
+	t, bp, reporter, err := r.resolve(ctx, *db.TierID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("resolving tier: %w", err)
+	}
+	result.CurrentTier = t.Name
+
+	// Samples taken before the last tier change describe the old tier.
+	since := r.now().Add(-r.lookback)
+	changes, err := r.changes.ListByDatabase(ctx, db.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing tier changes: %w", err)
+	}
+	if n := len(changes); n > 0 && changes[n-1].CreatedAt.After(since) {
+		since = changes[n-1].CreatedAt
+	}
+	samples, err := r.samples.ListByDatabase(ctx, db.ID, since)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing usage samples: %w", err)
+	}
+	result.SampleCount = len(samples)
+	result.Usage = summarize(samples)
+	if len(samples) < r.minSamples || reporter == nil {
+		return result, nil, nil
+	}
+
+	current, err := reporter.ManifestCompute(bp.Manifests)
+	if err != nil {
+		current = provider.ComputeResources{
+			CPUMillis:   result.Usage.CPURequestMillis,
+			MemoryBytes: result.Usage.MemoryRequestBytes,
+		}
+	}
+	if current.CPUMillis <= 0 || current.MemoryBytes <= 0 {
+		return result, nil, nil
+	}
+
+	candidates, err := r.candidates(ctx, t, bp.Provider, db.DataClassification, reporter)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	u := result.Usage
+	need := provider.ComputeResources{
+		CPUMillis:   ceilDiv(u.CPUP95Millis*100, cpuTargetPercent),
+		MemoryBytes: ceilDiv(u.MemoryMaxBytes*100, memTargetPercent),
+	}
+
+	if !covers(current, need) {
+		for _, c := range candidates {
+			if covers(c.compute, need) && covers(c.compute, current) {
+				result.Recommendations = append(result.Recommendations, Recommendation{
+					Action: ActionUpsize,
+					Tier:   c.tier.Name,
+					Reason: fmt.Sprintf("CPU p95 %dm and peak memory %s need %dm and %s at %d%%/%d%% utilization; tier %s requests %dm and %s",
+						u.CPUP95Millis, formatBytes(u.MemoryMaxBytes), need.CPUMillis, formatBytes(need.MemoryBytes),
+						cpuTargetPercent, memTargetPercent, t.Name, current.CPUMillis, formatBytes(current.MemoryBytes)),
+				})
+				return result, &c, nil
+			}
+		}
+		return result, nil, nil
+	}
+
+	if u.CPUP95Millis*100 < cpuDownsizePercent*current.CPUMillis && u.MemoryMaxBytes*100 < memDownsizePercent*current.MemoryBytes {
+		for _, c := range candidates {
+			if covers(c.compute, need) && covers(current, c.compute) && c.compute != current {
+				result.Recommendations = append(result.Recommendations, Recommendation{
+					Action: ActionDownsize,
+					Tier:   c.tier.Name,
+					Reason: fmt.Sprintf("CPU p95 %dm and peak memory %s are below %d%%/%d%% of the %dm and %s tier %s requests",
+						u.CPUP95Millis, formatBytes(u.MemoryMaxBytes), cpuDownsizePercent, memDownsizePercent,
+						current.CPUMillis, formatBytes(current.MemoryBytes), t.Name),
+				})
+				return result, &c, nil
+			}
+		}
+	}
+	return result, nil, nil
+}
+
+// candidates returns the other tiers served by the same provider that may
+// host the database's data classification and whose compute is known,
+// smallest first.
+func (r *Recommender) candidates(ctx context.Context, current *tier.Tier, providerName, classification string, reporter provider.ComputeReporter) ([]candidate, error) {
+	tiers, err := r.tierRepo.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing tiers: %w", err)
+	}
+	var out []candidate
+	for i := range tiers {
+		t := &tiers[i]
+		if t.ID == current.ID || t.BlueprintID == nil || !t.AllowsClassification(classification) {
+			continue
+		}
+		bp, err := r.bpRepo.GetByID(ctx, *t.BlueprintID)
+		if err != nil {
+			slog.Warn("recommend: failed to get blueprint", "tier", t.Name, "blueprintID", t.BlueprintID, "error", err)
+			continue
+		}
+		if bp.Provider != providerName {
+			continue
+		}
+		compute, err := reporter.ManifestCompute(bp.Manifests)
+		if err != nil || compute.CPUMillis <= 0 || compute.MemoryBytes <= 0 {
+			continue
+		}
+		out = append(out, candidate{tier: t, blueprint: bp, compute: compute})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].compute, out[j].compute
+		if a.CPUMillis != b.CPUMillis {
+			return a.CPUMillis < b.CPUMillis
+		}
+		if a.MemoryBytes != b.MemoryBytes {
+			return a.MemoryBytes < b.MemoryBytes
+		}
+		return out[i].tier.Name < out[j].tier.Name
+	})
+	return out, nil
+}
+
+// apply moves a database to its recommended tier, if any, and records the
+// attempt in the tier change audit trail.
+func (r *Recommender) apply(ctx context.Context, db *database.Database) {
+	result, target, err := r.recommend(ctx, db)
+	if err != nil {
+		slog.Warn("recommend: failed to assess database", "database", db.Name, "error", err)
+		return
+	}
+	if target == nil {
+		return
+	}
+	if r.freezes != nil {
+		window, err := r.freezes.Frozen(ctx, db.OwnerTeamID)
+		if err != nil {
+			slog.Warn("recommend: failed to check change freezes", "database", db.Name, "error", err)
+			return
+		}
+		if window != nil {
+			slog.Info("recommend: tier change deferred by change freeze", "database", db.Name, "freeze", window.ID, "until", window.EndsAt)
+			return
+		}
+	}
+	if r.locker != nil {
+		lock, err := r.locker.Acquire(ctx, db.ID, "tier-change", Actor, "")
+		if err != nil {
+			slog.Info("recommend: tier change deferred", "database", db.Name, "reason", err)
+			return
+		}
+		defer func() {
+			if err := r.locker.Release(ctx, lock); err != nil {
+				slog.Warn("recommend: failed to release database lock", "database", db.Name, "error", err)
+			}
+		}()
+	}
+	rec := result.Recommendations[0]
+	change := &database.TierChange{
+		DatabaseID: db.ID,
+		FromTier:   result.CurrentTier,
+		ToTier:     target.tier.Name,
+		Actor:      Actor,
+		Reason:     rec.Action + ": " + rec.Reason,
+		Status:     database.TierChangeApplied,
+	}
+
+	if err := r.moveTo(ctx, db, target); err != nil {
+		tierChangeFailures.Inc()
+		slog.Error("recommend: failed to change tier", "database", db.Name, "from", change.FromTier, "to", change.ToTier, "error", err)
+		change.Status = database.TierChangeFailed
+		change.Error = err.Error()
+	} else {
+		tierChanges.Inc()
+		slog.Info("recommend: tier changed", "database", db.Name, "from", change.FromTier, "to", change.ToTier, "action", rec.Action)
+	}
+
+	if err := r.changes.Record(ctx, change); err != nil {
+		slog.Error("recommend: failed to record tier change", "database", db.Name, "error", err)
+	}
+}
+
+// moveTo applies the target tier's blueprint, then points the database at
+// the target tier.
+func (r *Recommender) moveTo(ctx context.Context, db *database.Database, target *candidate) error {
+	if err := r.signer.Verify(target.blueprint); err != nil {
+		return err
+	}
+	if r.budgets != nil {
+		if err := r.budgets.CheckConnections(ctx, db.OwnerTeamID, &target.tier.ID, db.ID); err != nil {
+			return err
+		}
+	}
+	p, ok := r.registry.Get(target.blueprint.Provider)
+	if !ok {
+		return fmt.Errorf("provider %q not registered", target.blueprint.Provider)
+	}
+	if err := p.Apply(ctx, db.ProviderDatabase(target.tier, target.blueprint), target.blueprint.Manifests); err != nil {
+		return fmt.Errorf("applying blueprint %s: %w", target.blueprint.Name, err)
+	}
+	database.RecordSpec(ctx, r.specs, db, target.tier, target.blueprint)
+	if _, err := r.repo.Update(ctx, db.ID, database.UpdateFields{TierID: &target.tier.ID, UpdatedBy: Actor}); err != nil {
+		return fmt.Errorf("updating database tier: %w", err)
+	}
+	return nil
+}
+
+// summarize computes CPU p95, peak memory and the latest requests.
+func summarize(samples []database.UsageSample) Usage {
+	if len(samples) == 0 {
+		return Usage{}
+	}
+	cpu := make([]int64, len(samples))
+	var u Usage
+	for i, s := range samples {
+		cpu[i] = s.CPUUsedMillis
+		if s.MemoryUsedBytes > u.MemoryMaxBytes {
+			u.MemoryMaxBytes = s.MemoryUsedBytes
+		}
+	}
+	sort.Slice(cpu, func(i, j int) bool { return cpu[i] < cpu[j] })
+	u.CPUP95Millis = cpu[(len(cpu)*95+99)/100-1]
+	last := samples[len(samples)-1]
+	u.CPURequestMillis = last.CPURequestMillis
+	u.MemoryRequestBytes = last.MemoryRequestBytes
+	return u
+}
+
+// covers reports whether a provides at least as much CPU and memory as b.
+func covers(a, b provider.ComputeResources) bool {
+	return a.CPUMillis >= b.CPUMillis && a.MemoryBytes >= b.MemoryBytes
+}
+
+func ceilDiv(a, b int64) int64 {
+	return (a + b - 1) / b
+}
+
+func formatBytes(b int64) string {
+	return resource.NewQuantity(b, resource.BinarySI).String()
+}
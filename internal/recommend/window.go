@@ -0,0 +1,102 @@
+package recommend
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	minutesPerDay  = 24 * 60
+	minutesPerWeek = 7 * minutesPerDay
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Window is a recurring UTC time window during which tier changes may be
+// applied automatically, either daily ("02:00-04:00") or weekly
+// ("Sun 02:00-04:00"). A window whose end is before its start wraps past
+// midnight.
+type Window struct {
+	spec     string
+	weekly   bool
+	start    int // minutes since the start of the day, or of the week when weekly
+	duration int // minutes
+}
+
+// ParseWindow parses a window specification such as "02:00-04:00" or
+// "Sun 02:00-04:00".
+func ParseWindow(spec string) (Window, error) {
+	w := Window{spec: spec}
+	fields := strings.Fields(spec)
+	var span string
+	switch len(fields) {
+	case 1:
+		span = fields[0]
+	case 2:
+		day, ok := weekdays[strings.ToLower(fields[0])]
+		if !ok {
+			return Window{}, fmt.Errorf("invalid window %q: unknown day %q", spec, fields[0])
+		}
+		w.weekly = true
+		w.start = int(day) * minutesPerDay
+		span = fields[1]
+	default:
+		return Window{}, fmt.Errorf("invalid window %q: expected [Day] HH:MM-HH:MM", spec)
+	}
+
+	from, to, ok := strings.Cut(span, "-")
+	if !ok {
+		return Window{}, fmt.Errorf("invalid window %q: expected [Day] HH:MM-HH:MM", spec)
+	}
+	startMin, err := parseClock(from)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window %q: %w", spec, err)
+	}
+	endMin, err := parseClock(to)
+	if err != nil {
+		return Window{}, fmt.Errorf("invalid window %q: %w", spec, err)
+	}
+	if startMin == endMin {
+		return Window{}, fmt.Errorf("invalid window %q: start and end are equal", spec)
+	}
+	w.start += startMin
+	w.duration = (endMin - startMin + minutesPerDay) % minutesPerDay
+	return w, nil
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: expected HH:MM", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Contains reports whether t falls within the window.
+func (w Window) Contains(t time.Time) bool {
+	if w.duration == 0 {
+		return false
+	}
+	t = t.UTC()
+	m := t.Hour()*60 + t.Minute()
+	period := minutesPerDay
+	if w.weekly {
+		m += int(t.Weekday()) * minutesPerDay
+		period = minutesPerWeek
+	}
+	return (m-w.start+period)%period < w.duration
+}
+
+// String returns the window specification it was parsed from.
+func (w Window) String() string {
+	return w.spec
+}
@@ -2,17 +2,61 @@ package reconciler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"sync"
 	"time"
 
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/archive"
+	"github.com/daap14/daap/internal/audit"
 	"github.com/daap14/daap/internal/blueprint"
 	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/metrics"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/operation"
 	"github.com/daap14/daap/internal/provider"
 	"github.com/daap14/daap/internal/tier"
 )
 
+// pageSize is the number of databases listed per List call.
+const pageSize = 100
+
 // watchedStatuses are the database statuses the reconciler monitors.
-var watchedStatuses = []string{"provisioning", "ready", "error"}
+var watchedStatuses = []string{"provisioning", "ready", "error", "deprovisioning", "failing_over", "restarting", "renaming", "unfreezing"}
+
+var (
+	provisioningDuration = metrics.NewHistogram(
+		"daap_database_provisioning_duration_seconds",
+		"Time from database creation to first becoming ready.",
+		[]float64{30, 60, 120, 300, 600, 900, 1800, 3600},
+	)
+	provisioningSLOBreaches = metrics.NewCounter(
+		"daap_database_provisioning_slo_breaches_total",
+		"Number of databases that exceeded the provisioning SLO while still provisioning.",
+	)
+	provisioningTimeouts = metrics.NewCounter(
+		"daap_database_provisioning_timeouts_total",
+		"Number of databases moved to error after exceeding the provisioning timeout.",
+	)
+	readinessGateFailures = metrics.NewCounter(
+		"daap_database_readiness_gate_failures_total",
+		"Number of readiness gate checks that failed for databases reported healthy by their provider.",
+	)
+)
+
+// ReadinessGate verifies that a database reported healthy by its provider can
+// serve traffic before it is marked ready.
+type ReadinessGate interface {
+	Check(ctx context.Context, db provider.ProviderDatabase, health provider.HealthResult) error
+}
+
+// AuditRecorder receives audit events; *audit.Dispatcher implements it.
+type AuditRecorder interface {
+	Record(ctx context.Context, e audit.Event) error
+}
 
 // Reconciler polls databases and reconciles their state with provider health checks.
 type Reconciler struct {
@@ -21,23 +65,158 @@ type Reconciler struct {
 	bpRepo   blueprint.Repository
 	registry *provider.Registry
 	interval time.Duration
+
+	provisioningSLO     time.Duration
+	provisioningTimeout time.Duration
+	notifier            notify.Notifier
+	readinessGate       ReadinessGate
+	ops                 *operation.Tracker
+	auditor             AuditRecorder
+	archiver            *archive.Archiver
+	renames             database.RenameRepository
+
+	// sloWarned records databases already reported as over the provisioning
+	// SLO, so each breach is reported once. mu also guards the interval, the
+	// write rate and the state reported by State.
+	mu              sync.Mutex
+	sloWarned       map[uuid.UUID]bool
+	started         time.Time
+	lastSuccess     time.Time
+	lastPass        passStats
+	intervalChanged chan struct{}
+
+	// pass counts what the running pass does.
+	pass passStats
+
+	// Status updates queued during a pass, written by flush.
+	writeBatchSize int
+	writeRate      float64
+	nextWrite      time.Time
+	pending        []*pendingWrite
+	pendingByID    map[uuid.UUID]*pendingWrite
+}
+
+// Option configures a Reconciler.
+type Option func(*Reconciler)
+
+// WithProvisioningSLO sets how long a database may stay in provisioning
+// before a warning event is emitted. Zero disables the check.
+func WithProvisioningSLO(d time.Duration) Option {
+	return func(r *Reconciler) {
+		r.provisioningSLO = d
+	}
+}
+
+// WithProvisioningTimeout sets how long a database may stay in provisioning
+// before it is moved to error with reason PROVISIONING_TIMEOUT. Zero disables
+// the check.
+func WithProvisioningTimeout(d time.Duration) Option {
+	return func(r *Reconciler) {
+		r.provisioningTimeout = d
+	}
+}
+
+// WithNotifier sets the notifier used for provisioning timeouts. The default
+// logs notifications.
+func WithNotifier(n notify.Notifier) Option {
+	return func(r *Reconciler) {
+		r.notifier = n
+	}
+}
+
+// WithReadinessGate sets a gate a healthy database must pass before it moves
+// to ready. Until it passes, the database keeps its status with reason
+// READINESS_GATE_FAILED. Databases already ready are not re-checked.
+func WithReadinessGate(g ReadinessGate) Option {
+	return func(r *Reconciler) {
+		r.readinessGate = g
+	}
+}
+
+// WithOperations completes the running operations on a database, such as its
+// provisioning, once the reconciler records a settled status for the
+// database's current generation: ready completes them successfully, error
+// fails them.
+func WithOperations(t *operation.Tracker) Option {
+	return func(r *Reconciler) {
+		r.ops = t
+	}
+}
+
+// WithWriteBatchSize sets how many database status updates are written per
+// statement. The default is 50; 1 writes them one at a time.
+func WithWriteBatchSize(n int) Option {
+	return func(r *Reconciler) {
+		if n > 0 {
+			r.writeBatchSize = n
+		}
+	}
+}
+
+// WithWriteRate limits database status updates to perSecond on average, so
+// that many databases changing at once do not flood the platform database.
+// Zero, the default, does not limit them.
+func WithWriteRate(perSecond float64) Option {
+	return func(r *Reconciler) {
+		r.writeRate = perSecond
+	}
+}
+
+// WithAudit records an audit event with actor audit.ActorReconciler for
+// every change the reconciler writes to a database: status updates and the
+// deletion of deprovisioned databases.
+func WithAudit(rec AuditRecorder) Option {
+	return func(r *Reconciler) {
+		r.auditor = rec
+	}
+}
+
+// WithArchiver archives deprovisioning databases whose tier archives them
+// before deleting their resources. Without it, the teardown of such
+// databases does not complete.
+func WithArchiver(a *archive.Archiver) Option {
+	return func(r *Reconciler) {
+		r.archiver = a
+	}
+}
+
+// WithRenames moves renaming databases through their renames, recorded in
+// repo. Without it, renames do not progress.
+func WithRenames(repo database.RenameRepository) Option {
+	return func(r *Reconciler) {
+		r.renames = repo
+	}
 }
 
 // New creates a new Reconciler.
-func New(repo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, interval time.Duration) *Reconciler {
-	return &Reconciler{
-		repo:     repo,
-		tierRepo: tierRepo,
-		bpRepo:   bpRepo,
-		registry: registry,
-		interval: interval,
+func New(repo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, interval time.Duration, opts ...Option) *Reconciler {
+	r := &Reconciler{
+		repo:      repo,
+		tierRepo:  tierRepo,
+		bpRepo:    bpRepo,
+		registry:  registry,
+		interval:  interval,
+		notifier:  notify.LogNotifier{},
+		sloWarned: make(map[uuid.UUID]bool),
+
+		writeBatchSize:  defaultWriteBatchSize,
+		intervalChanged: make(chan struct{}, 1),
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // Start begins the reconciliation loop. It blocks until ctx is cancelled.
+// The loop picks up interval changes made with SetInterval.
 func (r *Reconciler) Start(ctx context.Context) {
-	slog.Info("reconciler started", "interval", r.interval.String())
-	ticker := time.NewTicker(r.interval)
+	r.mu.Lock()
+	r.started = time.Now()
+	interval := r.interval
+	r.mu.Unlock()
+	slog.Info("reconciler started", "interval", interval.String())
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -45,42 +224,85 @@ func (r *Reconciler) Start(ctx context.Context) {
 		case <-ctx.Done():
 			slog.Info("reconciler stopped")
 			return
+		case <-r.intervalChanged:
+			ticker.Reset(r.Interval())
 		case <-ticker.C:
 			r.reconcile(ctx)
 		}
 	}
 }
 
+// RunOnce performs a single reconciliation pass over all watched statuses.
+func (r *Reconciler) RunOnce(ctx context.Context) {
+	r.reconcile(ctx)
+}
+
 func (r *Reconciler) reconcile(ctx context.Context) {
+	r.pass = passStats{startedAt: time.Now(), providerErrors: map[string]int{}}
+	succeeded := true
 	for _, status := range watchedStatuses {
 		if ctx.Err() != nil {
 			return
 		}
-		r.reconcileByStatus(ctx, status)
+		succeeded = r.reconcileByStatus(ctx, status) && succeeded
+	}
+	if ctx.Err() == nil {
+		r.finishPass(succeeded)
 	}
 }
 
-func (r *Reconciler) reconcileByStatus(ctx context.Context, status string) {
+// reconcileByStatus reconciles every database with status, a page at a time,
+// and reports whether every page could be listed. Status updates are written
+// once all pages have been listed, so that they do not shift the pages.
+func (r *Reconciler) reconcileByStatus(ctx context.Context, status string) bool {
+	defer r.flush(ctx)
+
 	s := status
-	result, err := r.repo.List(ctx, database.ListFilter{
-		Status: &s,
-		Page:   1,
-		Limit:  100,
-	})
-	if err != nil {
-		slog.Error("reconciler: failed to list databases", "status", status, "error", err)
+	seen := 0
+	for page := 1; ; page++ {
+		result, err := r.repo.List(ctx, database.ListFilter{
+			Status: &s,
+			Page:   page,
+			Limit:  pageSize,
+		})
+		if err != nil {
+			slog.Error("reconciler: failed to list databases", "status", status, "error", err)
+			return false
+		}
+
+		for _, db := range result.Databases {
+			if ctx.Err() != nil {
+				return false
+			}
+			r.reconcileOne(ctx, &db)
+		}
+		seen += len(result.Databases)
+		if len(result.Databases) < pageSize || seen >= result.Total {
+			return true
+		}
+	}
+}
+
+func (r *Reconciler) reconcileOne(ctx context.Context, db *database.Database) {
+	r.pass.processed++
+	if db.Status != "ready" && db.Status != "error" || db.ObservedGeneration < db.Generation {
+		r.pass.backlog++
+	}
+
+	// A paused database is left alone, except for finishing a teardown the
+	// user asked for.
+	if db.Status != "deprovisioning" && db.ReconciliationPaused(time.Now()) {
+		slog.Debug("reconciler: reconciliation paused, skipping", "database", db.Name, "until", db.ReconciliationPause.Until)
 		return
 	}
 
-	for _, db := range result.Databases {
-		if ctx.Err() != nil {
+	if db.Status == "provisioning" {
+		if r.reapProvisioning(ctx, db) {
 			return
 		}
-		r.reconcileOne(ctx, &db)
+		r.checkProvisioningSLO(db)
 	}
-}
 
-func (r *Reconciler) reconcileOne(ctx context.Context, db *database.Database) {
 	if db.TierID == nil {
 		slog.Warn("reconciler: database has no tier, skipping", "database", db.Name)
 		return
@@ -109,10 +331,20 @@ func (r *Reconciler) reconcileOne(ctx context.Context, db *database.Database) {
 		return
 	}
 
-	pdb := toProviderDatabase(db, t, bp)
+	pdb := db.ProviderDatabase(t, bp)
+
+	if db.Status == "deprovisioning" {
+		r.confirmDeprovisioned(ctx, db, t, p, pdb)
+		return
+	}
+	if db.Status == "renaming" {
+		r.advanceRename(ctx, db, p, pdb)
+		return
+	}
 
 	healthResult, err := p.CheckHealth(ctx, pdb)
 	if err != nil {
+		r.pass.providerErrors[bp.Provider]++
 		slog.Warn("reconciler: health check failed",
 			"database", db.Name,
 			"provider", bp.Provider,
@@ -121,50 +353,421 @@ func (r *Reconciler) reconcileOne(ctx context.Context, db *database.Database) {
 		return
 	}
 
+	// A settled health result (ready or error) means the current generation
+	// has been acted upon.
+	generation := db.Generation
+	observed := db.ObservedGeneration == generation
+	instances := toInstances(healthResult.Instances)
+	updated := false
+
 	switch healthResult.Status {
 	case "ready":
-		if db.Status != "ready" {
+		if db.Status == "failing_over" && !switchedOver(db.Instances, instances) {
+			// The provider may still report the old primary as healthy
+			// before the switchover starts.
+			return
+		}
+		if db.Status == "restarting" && !r.restarted(ctx, db, p, pdb) {
+			return
+		}
+		if db.Status != "ready" && !r.passesReadinessGate(ctx, db, pdb, healthResult) {
+			return
+		}
+		if db.Status != "ready" || !observed {
 			su := database.StatusUpdate{
-				Status:     "ready",
-				Host:       healthResult.Host,
-				Port:       healthResult.Port,
-				SecretName: healthResult.SecretName,
+				Status:             "ready",
+				Host:               healthResult.Host,
+				Port:               healthResult.Port,
+				SecretName:         healthResult.SecretName,
+				ObservedGeneration: &generation,
+				Instances:          instances,
 			}
-			if _, err := r.repo.UpdateStatus(ctx, db.ID, su); err != nil {
-				slog.Error("reconciler: failed to update database to ready",
-					"database", db.Name, "error", err)
-				return
+			if healthResult.ExternalHost != "" {
+				su.ExternalHost = &healthResult.ExternalHost
 			}
-			slog.Info("reconciler: database is ready", "database", db.Name)
+			r.queue(db, su, func() {
+				r.ops.Settle(ctx, db.ID, readyResult(db, healthResult), nil)
+				if db.Status == "provisioning" {
+					r.recordProvisioned(db)
+				}
+				if db.Status != "ready" {
+					slog.Info("reconciler: database is ready", "database", db.Name)
+				}
+			})
+			updated = true
 		}
 	case "error":
-		if db.Status != "error" {
-			su := database.StatusUpdate{Status: "error"}
-			if _, err := r.repo.UpdateStatus(ctx, db.ID, su); err != nil {
-				slog.Error("reconciler: failed to update database to error",
-					"database", db.Name, "error", err)
-				return
+		// The provider's detail of the failure may change while the
+		// database stays in error, e.g. once a different cause surfaces.
+		if db.Status != "error" || !observed || !sameDetail(db, healthResult) {
+			su := database.StatusUpdate{
+				Status:             "error",
+				Reason:             healthResult.Reason,
+				Message:            healthResult.Message,
+				ObservedGeneration: &generation,
+				Instances:          instances,
 			}
-			slog.Warn("reconciler: database marked as error", "database", db.Name)
+			r.queue(db, su, func() {
+				message := fmt.Sprintf("Provider %s reported database %s as failed", bp.Provider, db.Name)
+				if healthResult.Message != "" {
+					message += ": " + healthResult.Message
+				}
+				r.ops.Settle(ctx, db.ID, nil, &operation.Error{Code: "DATABASE_ERROR", Message: message})
+				if db.Status == "provisioning" {
+					r.forgetSLO(db.ID)
+				}
+				if db.Status != "error" {
+					slog.Warn("reconciler: database marked as error", "database", db.Name, "reason", healthResult.Reason, "message", healthResult.Message)
+				}
+			})
+			updated = true
 		}
 	default:
 		// "provisioning" or unknown — no status change needed
 	}
+
+	// A failing-over database keeps the primary it had when the switchover
+	// was requested until it is ready again, to tell when it has moved.
+	if !updated && db.Status != "failing_over" && instances != nil && !sameInstances(db.Instances, instances) {
+		r.recordInstances(ctx, db, instances)
+	}
+	if !updated && db.Status == "ready" && healthResult.Status == "ready" {
+		r.checkOperatorVersion(ctx, db, p, pdb)
+		if healthResult.ExternalHost != "" && (db.ExternalHost == nil || *db.ExternalHost != healthResult.ExternalHost) {
+			r.recordExternalHost(db, healthResult.ExternalHost)
+		}
+	}
+}
+
+// sameDetail reports whether the database's recorded status reason and
+// message are the ones in health.
+func sameDetail(db *database.Database, health provider.HealthResult) bool {
+	var reason, message string
+	if db.StatusReason != nil {
+		reason = *db.StatusReason
+	}
+	if db.StatusMessage != nil {
+		message = *db.StatusMessage
+	}
+	return reason == health.Reason && message == health.Message
+}
+
+// recordInstances records a change in a database's instances, such as a
+// replica going down or a failover, without changing its status.
+func (r *Reconciler) recordInstances(ctx context.Context, db *database.Database, instances *database.Instances) {
+	su := database.StatusUpdate{Status: db.Status, Instances: instances}
+	if db.StatusReason != nil {
+		su.Reason = *db.StatusReason
+	}
+	if db.StatusMessage != nil {
+		su.Message = *db.StatusMessage
+	}
+	r.queue(db, su, func() {
+		if db.Instances != nil && instances.Ready < db.Instances.Ready {
+			slog.Warn("reconciler: database lost ready instances", "database", db.Name,
+				"ready", instances.Ready, "total", instances.Total)
+		}
+	})
+}
+
+// recordExternalHost records the address of an exposed database's load
+// balancer, which the cloud usually provisions after the database is ready,
+// without changing its status.
+func (r *Reconciler) recordExternalHost(db *database.Database, host string) {
+	su := database.StatusUpdate{Status: db.Status, ExternalHost: &host}
+	if db.StatusReason != nil {
+		su.Reason = *db.StatusReason
+	}
+	if db.StatusMessage != nil {
+		su.Message = *db.StatusMessage
+	}
+	r.queue(db, su, func() {
+		slog.Info("reconciler: database is reachable through its load balancer", "database", db.Name, "host", host)
+	})
+}
+
+// checkOperatorVersion records the operator version a ready database runs
+// under the first time it is known, and flags the database as needing review
+// while it runs under a different one. The flag is lifted if it goes back to
+// the recorded version, or when the database is marked reviewed, which clears
+// the recorded version so the current one is recorded next.
+func (r *Reconciler) checkOperatorVersion(ctx context.Context, db *database.Database, p provider.Provider, pdb provider.ProviderDatabase) {
+	versioner, ok := p.(provider.OperatorVersioner)
+	if !ok {
+		return
+	}
+	current, err := versioner.OperatorVersion(ctx, pdb)
+	if errors.Is(err, provider.ErrNotSupported) {
+		return
+	}
+	if err != nil {
+		r.pass.providerErrors[pdb.Provider]++
+		slog.Warn("reconciler: failed to get operator version", "database", db.Name, "error", err)
+		return
+	}
+	if current == "" {
+		return
+	}
+
+	su := database.StatusUpdate{Status: db.Status}
+	if db.StatusReason != nil {
+		su.Reason = *db.StatusReason
+	}
+	if db.StatusMessage != nil {
+		su.Message = *db.StatusMessage
+	}
+	flagged := db.Condition(database.ConditionNeedsReview)
+	switch {
+	case db.OperatorVersion == "":
+		su.OperatorVersion = &current
+	case db.OperatorVersion == current:
+		if flagged == nil || flagged.Reason != database.ReasonOperatorVersionChanged {
+			return
+		}
+		su.Conditions = db.WithoutCondition(database.ConditionNeedsReview)
+	default:
+		message := fmt.Sprintf("Provisioned under %s operator %s, now running under %s", pdb.Provider, db.OperatorVersion, current)
+		if flagged != nil && flagged.Message == message {
+			return
+		}
+		su.Conditions = db.WithCondition(database.Condition{
+			Type:    database.ConditionNeedsReview,
+			Reason:  database.ReasonOperatorVersionChanged,
+			Message: message,
+			Since:   time.Now().UTC(),
+		})
+		slog.Warn("reconciler: database runs under a different operator version", "database", db.Name,
+			"provisioned", db.OperatorVersion, "current", current)
+	}
+	r.queue(db, su, nil)
+}
+
+func toInstances(status *provider.InstanceStatus) *database.Instances {
+	if status == nil {
+		return nil
+	}
+	return &database.Instances{
+		Total:          status.Total,
+		Ready:          status.Ready,
+		Primary:        status.Primary,
+		ReplicationLag: status.ReplicationLag,
+	}
+}
+
+// sameInstances reports whether a and b describe the same instances.
+// Replication lag moves constantly, so it is compared to the second to avoid
+// a write on every pass.
+func sameInstances(a, b *database.Instances) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Total == b.Total && a.Ready == b.Ready && a.Primary == b.Primary &&
+		lagSeconds(a.ReplicationLag) == lagSeconds(b.ReplicationLag)
+}
+
+// lagSeconds returns lag in whole seconds, or -1 if it is unknown.
+func lagSeconds(lag *time.Duration) int64 {
+	if lag == nil {
+		return -1
+	}
+	return int64(lag.Seconds())
+}
+
+// confirmDeprovisioned deletes the record of a deprovisioning database once
+// its provider confirms the resources are gone, and completes the teardown
+// operation. A database whose tier archives it is deleted only once its
+// final backup has completed. Archiving and deleting again are harmless, so
+// a teardown that failed or was cut short by a restart is retried on every
+// pass.
+func (r *Reconciler) confirmDeprovisioned(ctx context.Context, db *database.Database, t *tier.Tier, p provider.Provider, pdb provider.ProviderDatabase) {
+	archived, err := r.archiver.Archive(ctx, db, t, p, pdb)
+	if err != nil {
+		r.pass.providerErrors[pdb.Provider]++
+		slog.Warn("reconciler: archive failed", "database", db.Name, "provider", pdb.Provider, "error", err)
+		return
+	}
+	if !archived {
+		return
+	}
+	state, err := provider.ConfirmDeletion(ctx, p, pdb, 0)
+	if err != nil {
+		r.pass.providerErrors[pdb.Provider]++
+		slog.Warn("reconciler: deletion check failed", "database", db.Name, "provider", pdb.Provider, "error", err)
+		return
+	}
+	if state != provider.DeletionGone {
+		return
+	}
+	if err := r.repo.SoftDelete(ctx, db.ID); err != nil {
+		slog.Error("reconciler: failed to delete deprovisioned database", "database", db.Name, "error", err)
+		return
+	}
+	r.recordAudit(ctx, db, "database.delete", "")
+	result := map[string]any{"databaseId": db.ID.String()}
+	if db.ArchiveURL != nil {
+		result["archiveUrl"] = *db.ArchiveURL
+	}
+	r.ops.Settle(ctx, db.ID, result, nil)
+	slog.Info("reconciler: database deprovisioned", "database", db.Name)
+}
+
+// checkProvisioningSLO emits a warning event the first time a database is
+// seen provisioning for longer than the configured SLO.
+func (r *Reconciler) checkProvisioningSLO(db *database.Database) {
+	if r.provisioningSLO <= 0 {
+		return
+	}
+	elapsed := time.Since(db.CreatedAt)
+	if elapsed <= r.provisioningSLO {
+		return
+	}
+
+	r.mu.Lock()
+	warned := r.sloWarned[db.ID]
+	r.sloWarned[db.ID] = true
+	r.mu.Unlock()
+	if warned {
+		return
+	}
+
+	provisioningSLOBreaches.Inc()
+	slog.Warn("reconciler: database exceeded provisioning SLO",
+		"event", "ProvisioningSLOExceeded",
+		"database", db.Name,
+		"id", db.ID,
+		"elapsed", elapsed.Round(time.Second).String(),
+		"slo", r.provisioningSLO.String(),
+	)
+}
+
+// reapProvisioning moves a database that has been provisioning for longer than
+// the provisioning timeout to error and notifies operators. It reports whether
+// the database was reaped. A reaped database that later turns healthy is moved
+// to ready by the regular error-status reconciliation.
+func (r *Reconciler) reapProvisioning(ctx context.Context, db *database.Database) bool {
+	if r.provisioningTimeout <= 0 {
+		return false
+	}
+	elapsed := time.Since(db.CreatedAt)
+	if elapsed <= r.provisioningTimeout {
+		return false
+	}
+
+	su := database.StatusUpdate{Status: "error", Reason: database.ReasonProvisioningTimeout}
+	r.queue(db, su, func() { r.timedOut(ctx, db, elapsed) })
+	return true
+}
+
+// timedOut completes the reaping of a database that timed out provisioning
+// once it has been marked as error.
+func (r *Reconciler) timedOut(ctx context.Context, db *database.Database, elapsed time.Duration) {
+	r.forgetSLO(db.ID)
+	r.ops.Settle(ctx, db.ID, nil, &operation.Error{
+		Code:    database.ReasonProvisioningTimeout,
+		Message: fmt.Sprintf("Database still provisioning after %s", elapsed.Round(time.Second)),
+	})
+	provisioningTimeouts.Inc()
+	slog.Warn("reconciler: database provisioning timed out",
+		"database", db.Name,
+		"elapsed", elapsed.Round(time.Second).String(),
+		"timeout", r.provisioningTimeout.String(),
+	)
+
+	n := notify.Notification{
+		Event:      "ProvisioningTimeout",
+		Message:    fmt.Sprintf("database %s still provisioning after %s; marked as error", db.Name, elapsed.Round(time.Second)),
+		DatabaseID: db.ID,
+		Database:   db.Name,
+		OwnerTeam:  db.OwnerTeamName,
+		Reason:     database.ReasonProvisioningTimeout,
+		Time:       time.Now().UTC(),
+	}
+	if err := r.notifier.Notify(ctx, n); err != nil {
+		slog.Error("reconciler: failed to send provisioning timeout notification",
+			"database", db.Name, "error", err)
+	}
 }
 
-// toProviderDatabase builds a ProviderDatabase from domain models.
-func toProviderDatabase(db *database.Database, t *tier.Tier, bp *blueprint.Blueprint) provider.ProviderDatabase {
-	return provider.ProviderDatabase{
-		ID:          db.ID,
-		Name:        db.Name,
-		Namespace:   db.Namespace,
-		ClusterName: db.ClusterName,
-		PoolerName:  db.PoolerName,
-		OwnerTeam:   db.OwnerTeamName,
-		OwnerTeamID: db.OwnerTeamID,
-		Tier:        t.Name,
-		TierID:      t.ID,
-		Blueprint:   bp.Name,
-		Provider:    bp.Provider,
+// passesReadinessGate runs the readiness gate, if any, for a database about to
+// become ready. On failure it records the reason on the database, leaving its
+// status unchanged, and reports false.
+func (r *Reconciler) passesReadinessGate(ctx context.Context, db *database.Database, pdb provider.ProviderDatabase, health provider.HealthResult) bool {
+	if r.readinessGate == nil {
+		return true
 	}
+	err := r.readinessGate.Check(ctx, pdb, health)
+	if err == nil {
+		return true
+	}
+
+	readinessGateFailures.Inc()
+	slog.Warn("reconciler: database failed readiness gate",
+		"database", db.Name, "status", db.Status, "error", err)
+
+	if db.StatusReason == nil || *db.StatusReason != database.ReasonReadinessGateFailed {
+		su := database.StatusUpdate{Status: db.Status, Reason: database.ReasonReadinessGateFailed}
+		r.queue(db, su, nil)
+	}
+	return false
+}
+
+// restarted reports whether a restarting database's instances have all
+// restarted. Providers that cannot tell are taken at their word once they
+// report the database ready.
+func (r *Reconciler) restarted(ctx context.Context, db *database.Database, p provider.Provider, pdb provider.ProviderDatabase) bool {
+	restarter, ok := p.(provider.Restarter)
+	if !ok {
+		return true
+	}
+	done, err := restarter.Restarted(ctx, pdb)
+	if errors.Is(err, provider.ErrNotSupported) {
+		return true
+	}
+	if err != nil {
+		r.pass.providerErrors[pdb.Provider]++
+		slog.Warn("reconciler: failed to check restart progress", "database", db.Name, "error", err)
+		return false
+	}
+	return done
+}
+
+// switchedOver reports whether the primary has moved away from the one
+// recorded when a failover was requested. Without a recorded or reported
+// primary there is nothing to wait for.
+func switchedOver(recorded, current *database.Instances) bool {
+	if recorded == nil || recorded.Primary == "" || current == nil {
+		return true
+	}
+	return current.Primary != recorded.Primary
+}
+
+// readyResult is the result of the operations completed by a database
+// becoming ready: where to connect to it.
+func readyResult(db *database.Database, health provider.HealthResult) map[string]any {
+	result := map[string]any{"databaseId": db.ID.String()}
+	if health.Host != nil {
+		result["host"] = *health.Host
+	}
+	if health.Port != nil {
+		result["port"] = *health.Port
+	}
+	if health.Instances != nil && health.Instances.Primary != "" {
+		result["primary"] = health.Instances.Primary
+	}
+	return result
+}
+
+// recordProvisioned observes the time from creation to ready for a database
+// leaving provisioning.
+func (r *Reconciler) recordProvisioned(db *database.Database) {
+	elapsed := time.Since(db.CreatedAt)
+	provisioningDuration.Observe(elapsed.Seconds())
+	r.forgetSLO(db.ID)
+	slog.Info("reconciler: database provisioned", "database", db.Name, "duration", elapsed.Round(time.Second).String())
+}
+
+func (r *Reconciler) forgetSLO(id uuid.UUID) {
+	r.mu.Lock()
+	delete(r.sloWarned, id)
+	r.mu.Unlock()
 }
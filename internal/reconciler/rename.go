@@ -0,0 +1,186 @@
+package reconciler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// advanceRename moves a renaming database one step through its rename. While
+// copying, the copy under the new name is promoted once it has caught up;
+// once the promoted copy is ready, the database takes the new name, and the
+// resources under the old one are deleted. A copy that fails, before or
+// after its promotion, is deleted instead, and the database keeps its name,
+// serving again if the promotion had stopped it. Either way the database then
+// returns to ready and the rename operation completes. Every step is safe to
+// repeat, so one cut short is retried on the next pass.
+func (r *Reconciler) advanceRename(ctx context.Context, db *database.Database, p provider.Provider, pdb provider.ProviderDatabase) {
+	if db.Rename == nil {
+		// The rename was cleared, but the status update that followed did
+		// not make it.
+		r.queue(db, database.StatusUpdate{Status: "ready"}, nil)
+		return
+	}
+	if r.renames == nil {
+		slog.Warn("reconciler: renames are not configured, skipping", "database", db.Name)
+		return
+	}
+	cloner, ok := p.(provider.Cloner)
+	if !ok {
+		slog.Warn("reconciler: provider cannot rename databases, skipping", "database", db.Name, "provider", pdb.Provider)
+		return
+	}
+
+	switch db.Rename.Phase {
+	case database.RenameCopying:
+		r.promoteCopy(ctx, db, p, cloner, pdb)
+	case database.RenameCutover:
+		r.cutOver(ctx, db, p, pdb)
+	case database.RenameRetiring:
+		r.retire(ctx, db, p, pdb, renamedFrom(pdb, db.Rename), nil)
+	case database.RenameAbandoning:
+		// The copy may have been promoted, which stopped the database from
+		// accepting writes: it serves again before the copy is deleted.
+		// A provider that cannot unfence never promoted the copy.
+		if err := cloner.Unfence(ctx, pdb); err != nil && !errors.Is(err, provider.ErrNotSupported) {
+			r.pass.providerErrors[pdb.Provider]++
+			slog.Warn("reconciler: unfencing database failed", "database", db.Name, "error", err)
+			return
+		}
+		r.retire(ctx, db, p, pdb, renamedTo(pdb, db.Rename), &operation.Error{
+			Code:    "RENAME_FAILED",
+			Message: fmt.Sprintf("The copy of database %s under name %s failed; it keeps its name", db.Name, db.Rename.To),
+		})
+	}
+}
+
+// promoteCopy promotes the copy of a database under its new name once the
+// provider reports it ready, and abandons the rename if it reports the copy
+// failed or cannot promote it.
+func (r *Reconciler) promoteCopy(ctx context.Context, db *database.Database, p provider.Provider, cloner provider.Cloner, pdb provider.ProviderDatabase) {
+	target := renamedTo(pdb, db.Rename)
+	health, err := p.CheckHealth(ctx, target)
+	if err != nil {
+		r.pass.providerErrors[pdb.Provider]++
+		slog.Warn("reconciler: health check of renamed copy failed", "database", db.Name, "to", db.Rename.To, "error", err)
+		return
+	}
+
+	phase := database.RenameCutover
+	switch health.Status {
+	case "ready":
+		err = cloner.Promote(ctx, target, pdb)
+		if errors.Is(err, provider.ErrNotSupported) {
+			phase = database.RenameAbandoning
+			slog.Warn("reconciler: provider cannot promote renamed copy, abandoning rename",
+				"database", db.Name, "to", db.Rename.To, "provider", pdb.Provider)
+			break
+		}
+		if err != nil {
+			r.pass.providerErrors[pdb.Provider]++
+			slog.Warn("reconciler: promoting renamed copy failed", "database", db.Name, "to", db.Rename.To, "error", err)
+			return
+		}
+		slog.Info("reconciler: renamed copy caught up, cutting over", "database", db.Name, "to", db.Rename.To)
+	case "error":
+		phase = database.RenameAbandoning
+		slog.Warn("reconciler: renamed copy failed, abandoning rename",
+			"database", db.Name, "to", db.Rename.To, "reason", health.Reason, "message", health.Message)
+	default:
+		return
+	}
+	if _, err := r.renames.SetRenamePhase(ctx, db.ID, phase); err != nil {
+		slog.Error("reconciler: failed to record rename phase", "database", db.Name, "phase", phase, "error", err)
+	}
+}
+
+// cutOver gives a database its new name, and the connection details of its
+// promoted copy, once the copy is ready, and abandons the rename if the
+// provider reports the copy failed.
+func (r *Reconciler) cutOver(ctx context.Context, db *database.Database, p provider.Provider, pdb provider.ProviderDatabase) {
+	health, err := p.CheckHealth(ctx, renamedTo(pdb, db.Rename))
+	if err != nil {
+		r.pass.providerErrors[pdb.Provider]++
+		slog.Warn("reconciler: health check of renamed copy failed", "database", db.Name, "to", db.Rename.To, "error", err)
+		return
+	}
+	if health.Status == "error" {
+		slog.Warn("reconciler: promoted copy failed, abandoning rename",
+			"database", db.Name, "to", db.Rename.To, "reason", health.Reason, "message", health.Message)
+		if _, err := r.renames.SetRenamePhase(ctx, db.ID, database.RenameAbandoning); err != nil {
+			slog.Error("reconciler: failed to record rename phase", "database", db.Name, "phase", database.RenameAbandoning, "error", err)
+		}
+		return
+	}
+	if health.Status != "ready" {
+		return
+	}
+	from := db.Name
+	updated, err := r.renames.CompleteRename(ctx, db.ID, health.Host, health.Port, health.SecretName, audit.ActorReconciler)
+	if err != nil {
+		slog.Error("reconciler: failed to rename database", "database", db.Name, "to", db.Rename.To, "error", err)
+		return
+	}
+	r.recordAudit(ctx, updated, "database.rename", from+" -> "+updated.Name)
+	slog.Info("reconciler: database renamed, retiring old resources", "database", updated.Name, "from", from)
+}
+
+// retire deletes the resources of retired, the database's resources under
+// the name it no longer uses, then forgets the rename and returns the
+// database to ready, completing the rename operation: as failed with opErr
+// when it is set.
+func (r *Reconciler) retire(ctx context.Context, db *database.Database, p provider.Provider, pdb, retired provider.ProviderDatabase, opErr *operation.Error) {
+	state, err := provider.ConfirmDeletion(ctx, p, retired, 0)
+	if err != nil {
+		r.pass.providerErrors[pdb.Provider]++
+		slog.Warn("reconciler: deletion check failed", "database", db.Name, "resources", retired.Name, "error", err)
+		return
+	}
+	if state != provider.DeletionGone {
+		return
+	}
+	if _, err := r.renames.ClearRename(ctx, db.ID); err != nil {
+		slog.Error("reconciler: failed to clear rename", "database", db.Name, "error", err)
+		return
+	}
+	r.queue(db, database.StatusUpdate{Status: "ready"}, func() {
+		if opErr != nil {
+			r.ops.Settle(ctx, db.ID, nil, opErr)
+			return
+		}
+		result := map[string]any{"databaseId": db.ID.String(), "name": db.Name}
+		if db.Host != nil {
+			result["host"] = *db.Host
+		}
+		if db.Port != nil {
+			result["port"] = *db.Port
+		}
+		r.ops.Settle(ctx, db.ID, result, nil)
+	})
+}
+
+// renamedTo returns the ProviderDatabase of pdb's copy under the new name of
+// rename.
+func renamedTo(pdb provider.ProviderDatabase, rename *database.Rename) provider.ProviderDatabase {
+	pdb.Name = rename.To
+	pdb.ClusterName = database.ClusterName(rename.To)
+	pdb.PoolerName = database.PoolerName(rename.To)
+	pdb.DNSName = rename.DNSName
+	return pdb
+}
+
+// renamedFrom returns the ProviderDatabase of pdb's resources under the old
+// name of rename. Their DNS name goes with them.
+func renamedFrom(pdb provider.ProviderDatabase, rename *database.Rename) provider.ProviderDatabase {
+	pdb.Name = rename.From
+	pdb.ClusterName = database.ClusterName(rename.From)
+	pdb.PoolerName = database.PoolerName(rename.From)
+	pdb.DNSName = ""
+	return pdb
+}
@@ -0,0 +1,113 @@
+package reconciler
+
+import (
+	"errors"
+	"maps"
+	"time"
+)
+
+// State is what the reconciler reports about itself: its interval and what
+// its last completed pass did.
+type State struct {
+	Interval        time.Duration
+	LastRunAt       time.Time
+	LastRunDuration time.Duration
+	LastSuccessAt   time.Time
+	// Processed is the number of databases the last pass reconciled.
+	Processed int
+	// Errors counts the failed provider calls of the last pass, by provider.
+	Errors map[string]int
+	// Backlog is the number of databases the last pass found short of a
+	// steady state: in a transitional status, or with a spec not yet
+	// observed.
+	Backlog int
+}
+
+// passStats counts what one pass does.
+type passStats struct {
+	startedAt      time.Time
+	duration       time.Duration
+	processed      int
+	backlog        int
+	providerErrors map[string]int
+}
+
+// finishPass publishes the counts of the running pass, recording it as a
+// success if it listed every watched database.
+func (r *Reconciler) finishPass(succeeded bool) {
+	r.pass.duration = time.Since(r.pass.startedAt)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastPass = r.pass
+	if succeeded {
+		r.lastSuccess = r.pass.startedAt.Add(r.pass.duration)
+	}
+}
+
+// State returns the reconciler's interval and the counts of its last
+// completed pass.
+func (r *Reconciler) State() State {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return State{
+		Interval:        r.interval,
+		LastRunAt:       r.lastPass.startedAt,
+		LastRunDuration: r.lastPass.duration,
+		LastSuccessAt:   r.lastSuccess,
+		Processed:       r.lastPass.processed,
+		Errors:          maps.Clone(r.lastPass.providerErrors),
+		Backlog:         r.lastPass.backlog,
+	}
+}
+
+// Interval returns the time between passes.
+func (r *Reconciler) Interval() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.interval
+}
+
+// SetInterval changes the time between passes. A running loop picks the new
+// interval up right away, counting from the change.
+func (r *Reconciler) SetInterval(d time.Duration) error {
+	if d <= 0 {
+		return errors.New("interval must be positive")
+	}
+	r.mu.Lock()
+	r.interval = d
+	r.mu.Unlock()
+	select {
+	case r.intervalChanged <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// SetWriteRate changes the status write rate set with WithWriteRate,
+// starting with the next batch. Zero or less removes the limit.
+func (r *Reconciler) SetWriteRate(perSecond float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.writeRate = perSecond
+}
+
+// LastSuccess returns when the reconciler last completed a pass that listed
+// every watched database, or the zero time if none has yet.
+func (r *Reconciler) LastSuccess() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lastSuccess
+}
+
+// Stale reports whether no pass has succeeded at now for three intervals
+// since the last success, or since the reconciler started if none has. A
+// reconciler that was never started is not stale.
+func (r *Reconciler) Stale(now time.Time) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	since := r.lastSuccess
+	if since.IsZero() {
+		since = r.started
+	}
+	return !since.IsZero() && now.Sub(since) > 3*r.interval
+}
@@ -0,0 +1,174 @@
+package reconciler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/metrics"
+)
+
+// defaultWriteBatchSize is the number of status updates written per
+// statement unless WithWriteBatchSize says otherwise.
+const defaultWriteBatchSize = 50
+
+var statusWriteBatches = metrics.NewCounter(
+	"daap_reconciler_status_write_batches_total",
+	"Number of batches of database status updates written by the reconciler.",
+)
+
+// pendingWrite is a status update queued until the end of a pass, with what
+// to do once it has been written.
+type pendingWrite struct {
+	db     *database.Database
+	update database.StatusUpdate
+	done   []func()
+}
+
+// queue queues a status update of db, to be written with the other updates
+// of the pass; done, if not nil, runs once it has been written. Updates of
+// the same database are merged, later fields taking precedence. They are
+// recorded as made by audit.ActorReconciler, and only written if db still
+// has the status it was read with: a change made meanwhile, such as a
+// deletion or a restart, wins over the reconciler's stale view.
+func (r *Reconciler) queue(db *database.Database, su database.StatusUpdate, done func()) {
+	su.UpdatedBy = audit.ActorReconciler
+	if r.pendingByID == nil {
+		r.pendingByID = make(map[uuid.UUID]*pendingWrite)
+	}
+	w, ok := r.pendingByID[db.ID]
+	if ok {
+		w.update = mergeStatusUpdates(w.update, su)
+	} else {
+		w = &pendingWrite{db: db, update: su}
+		r.pendingByID[db.ID] = w
+		r.pending = append(r.pending, w)
+	}
+	if done != nil {
+		w.done = append(w.done, done)
+	}
+}
+
+// flush writes the queued status updates in batches, within the write rate,
+// and runs the follow-ups of those written. Updates that fail are dropped;
+// the next pass finds the databases unchanged and tries again. Updates of
+// databases whose status changed since they were read are dropped with
+// their follow-ups: the next pass acts on the new status.
+func (r *Reconciler) flush(ctx context.Context) {
+	pending := r.pending
+	r.pending, r.pendingByID = nil, nil
+
+	for start := 0; start < len(pending); start += r.writeBatchSize {
+		batch := pending[start:min(start+r.writeBatchSize, len(pending))]
+		if err := r.throttle(ctx, len(batch)); err != nil {
+			return
+		}
+
+		writes := make([]database.StatusWrite, len(batch))
+		for i, w := range batch {
+			writes[i] = database.StatusWrite{ID: w.db.ID, Expected: w.db.Status, Update: w.update}
+		}
+		updated, err := database.UpdateStatuses(ctx, r.repo, writes)
+		statusWriteBatches.Inc()
+		written := make(map[uuid.UUID]bool, len(updated))
+		for _, id := range updated {
+			written[id] = true
+		}
+		for _, w := range batch {
+			switch {
+			case written[w.db.ID]:
+				r.recordAudit(ctx, w.db, "database.status_update", w.update.Status)
+				for _, done := range w.done {
+					done()
+				}
+			case err != nil:
+				slog.Error("reconciler: failed to update database status",
+					"database", w.db.Name, "status", w.update.Status, "error", err)
+			default:
+				slog.Info("reconciler: database changed since it was read; status update dropped",
+					"database", w.db.Name, "read", w.db.Status, "status", w.update.Status)
+			}
+		}
+	}
+}
+
+// recordAudit records that the reconciler applied action to db. Its writes
+// are already made, so a failure can only be logged.
+func (r *Reconciler) recordAudit(ctx context.Context, db *database.Database, action, detail string) {
+	if r.auditor == nil {
+		return
+	}
+	e := audit.Event{
+		Time:               time.Now().UTC(),
+		Actor:              audit.ActorReconciler,
+		Team:               db.OwnerTeamName,
+		Action:             action,
+		DatabaseID:         db.ID.String(),
+		Detail:             detail,
+		DataClassification: db.DataClassification,
+	}
+	if err := r.auditor.Record(context.WithoutCancel(ctx), e); err != nil {
+		slog.Error("reconciler: failed to record audit event", "database", db.Name, "action", action, "error", err)
+	}
+}
+
+// throttle waits until n more status updates fit within the write rate.
+func (r *Reconciler) throttle(ctx context.Context, n int) error {
+	r.mu.Lock()
+	rate := r.writeRate
+	r.mu.Unlock()
+	if rate <= 0 {
+		return nil
+	}
+	now := time.Now()
+	if r.nextWrite.Before(now) {
+		r.nextWrite = now
+	}
+	wait := r.nextWrite.Sub(now)
+	r.nextWrite = r.nextWrite.Add(time.Duration(float64(n) / rate * float64(time.Second)))
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// mergeStatusUpdates returns the update applying a and then b.
+func mergeStatusUpdates(a, b database.StatusUpdate) database.StatusUpdate {
+	if b.Host == nil {
+		b.Host = a.Host
+	}
+	if b.Port == nil {
+		b.Port = a.Port
+	}
+	if b.SecretName == nil {
+		b.SecretName = a.SecretName
+	}
+	if b.ObservedGeneration == nil {
+		b.ObservedGeneration = a.ObservedGeneration
+	}
+	if b.Instances == nil {
+		b.Instances = a.Instances
+	}
+	if b.OperatorVersion == nil {
+		b.OperatorVersion = a.OperatorVersion
+	}
+	if b.ExternalHost == nil {
+		b.ExternalHost = a.ExternalHost
+	}
+	if b.Conditions == nil {
+		b.Conditions = a.Conditions
+	}
+	return b
+}
@@ -0,0 +1,19 @@
+// Package requestid carries the ID of the API request, or of the background
+// operation, that work is done for, from the HTTP middleware down to
+// provider and Kubernetes calls.
+package requestid
+
+import "context"
+
+type contextKey struct{}
+
+// With returns a copy of ctx carrying id.
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// From returns the ID carried by ctx, or "" if there is none.
+func From(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,136 @@
+package retention
+
+import (
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// HistoryRotator keeps the monthly partitions of the history tables
+// (database.HistoryTables): it creates the partitions of the current and
+// next months ahead of their rows, and drops, or archives, the partitions of
+// months past the retention.
+type HistoryRotator struct {
+	repo     database.HistoryRepository
+	interval time.Duration
+	// months is how many months before the current one are kept; 0 keeps
+	// every month.
+	months  int
+	archive bool
+	now     func() time.Time
+}
+
+// HistoryOption configures a HistoryRotator.
+type HistoryOption func(*HistoryRotator)
+
+// WithArchive archives partitions past the retention to
+// database.HistoryArchiveSchema instead of dropping them.
+func WithArchive() HistoryOption {
+	return func(r *HistoryRotator) {
+		r.archive = true
+	}
+}
+
+// WithHistoryClock sets the function used to read the current time.
+func WithHistoryClock(now func() time.Time) HistoryOption {
+	return func(r *HistoryRotator) {
+		r.now = now
+	}
+}
+
+// NewHistoryRotator creates a new HistoryRotator running every interval. It
+// keeps the current month and the given number of months before it; 0 keeps
+// every month.
+func NewHistoryRotator(repo database.HistoryRepository, interval time.Duration, months int, opts ...HistoryOption) *HistoryRotator {
+	r := &HistoryRotator{
+		repo:     repo,
+		interval: interval,
+		months:   months,
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Start runs the rotation right away, so the partitions of the current month
+// exist, then every interval. It blocks until ctx is cancelled.
+func (r *HistoryRotator) Start(ctx context.Context) {
+	slog.Info("history rotator started", "interval", r.interval.String(), "retentionMonths", r.months, "archive", r.archive)
+	r.RunOnce(ctx)
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("history rotator stopped")
+			return
+		case <-ticker.C:
+			r.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce creates the missing partitions, then removes those past the
+// retention, and returns how many it removed.
+func (r *HistoryRotator) RunOnce(ctx context.Context) int {
+	current := database.HistoryMonth(r.now())
+
+	for _, table := range slices.Sorted(maps.Keys(database.HistoryTables)) {
+		// Rows in the default partition are moved to partitions of their
+		// own, so months past the retention are removed with the rest.
+		months, err := r.repo.DefaultMonths(ctx, table)
+		if err != nil {
+			slog.Error("history rotation: failed to list default partition months", "table", table, "error", err)
+			months = nil
+		}
+		for _, month := range []time.Time{current, current.AddDate(0, 1, 0)} {
+			if !slices.ContainsFunc(months, month.Equal) {
+				months = append(months, month)
+			}
+		}
+		for _, month := range months {
+			created, err := r.repo.EnsurePartition(ctx, table, month)
+			if err != nil {
+				slog.Error("history rotation: failed to create partition", "table", table, "month", month.Format("2006-01"), "error", err)
+				continue
+			}
+			if created {
+				slog.Info("history rotation: partition created", "table", table, "month", month.Format("2006-01"))
+			}
+		}
+	}
+
+	if r.months <= 0 {
+		return 0
+	}
+	cutoff := current.AddDate(0, -r.months, 0)
+	partitions, err := r.repo.ListPartitions(ctx)
+	if err != nil {
+		slog.Error("history rotation: failed to list partitions", "error", err)
+		return 0
+	}
+	removed := 0
+	for _, p := range partitions {
+		if !p.Month.Before(cutoff) || ctx.Err() != nil {
+			continue
+		}
+		remove := r.repo.DropPartition
+		if r.archive {
+			remove = r.repo.ArchivePartition
+		}
+		if err := remove(ctx, p); err != nil {
+			slog.Error("history rotation: failed to remove partition", "partition", p.Name, "archive", r.archive, "error", err)
+			continue
+		}
+		removed++
+		slog.Info("history rotation: partition removed", "table", p.Table, "month", p.Month.Format("2006-01"), "archived", r.archive)
+	}
+	return removed
+}
@@ -0,0 +1,204 @@
+// Package retention permanently removes the databases soft-deleted longer
+// ago than the retention period, with their history, so the platform
+// database does not grow without bound. Databases under legal hold are kept
+// however long ago they were deleted. It also rotates the monthly partitions
+// of the history tables, which would otherwise grow with every status change.
+package retention
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/database"
+)
+
+// AuditRecorder receives audit events; *audit.Dispatcher implements it.
+type AuditRecorder interface {
+	Record(ctx context.Context, e audit.Event) error
+}
+
+// Report describes what the next purge will remove.
+type Report struct {
+	Retention time.Duration
+	Interval  time.Duration
+	NextRunAt time.Time
+	// Cutoff is the deletion time before which the next run purges a
+	// database: NextRunAt less the retention.
+	Cutoff time.Time
+	// Purgeable lists the databases the next run will purge, and Held those
+	// past the cutoff that it will keep because they are under legal hold.
+	Purgeable []database.DeletedDatabase
+	Held      []database.DeletedDatabase
+	// LastRunAt and LastPurged describe the last run; LastRunAt is nil
+	// until the first one.
+	LastRunAt  *time.Time
+	LastPurged int
+}
+
+// Purger purges expired soft-deleted databases on an interval.
+type Purger struct {
+	repo      database.PurgeRepository
+	interval  time.Duration
+	retention time.Duration
+
+	auditor AuditRecorder
+	now     func() time.Time
+
+	mu         sync.Mutex
+	started    time.Time
+	lastRunAt  *time.Time
+	lastPurged int
+}
+
+// Option configures a Purger.
+type Option func(*Purger)
+
+// WithAudit records an audit event with actor audit.ActorRetention for every
+// database purged.
+func WithAudit(rec AuditRecorder) Option {
+	return func(p *Purger) {
+		p.auditor = rec
+	}
+}
+
+// WithClock sets the function used to read the current time.
+func WithClock(now func() time.Time) Option {
+	return func(p *Purger) {
+		p.now = now
+	}
+}
+
+// New creates a new Purger. Databases soft-deleted more than retention ago
+// are purged every interval.
+func New(repo database.PurgeRepository, interval, retention time.Duration, opts ...Option) *Purger {
+	p := &Purger{
+		repo:      repo,
+		interval:  interval,
+		retention: retention,
+		now:       time.Now,
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	p.started = p.now()
+	return p
+}
+
+// Start begins the purge loop. It blocks until ctx is cancelled.
+func (p *Purger) Start(ctx context.Context) {
+	slog.Info("retention purger started", "interval", p.interval.String(), "retention", p.retention.String())
+	p.mu.Lock()
+	p.started = p.now()
+	p.mu.Unlock()
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("retention purger stopped")
+			return
+		case <-ticker.C:
+			p.RunOnce(ctx)
+		}
+	}
+}
+
+// RunOnce purges the databases soft-deleted more than the retention ago and
+// returns how many it purged.
+func (p *Purger) RunOnce(ctx context.Context) int {
+	now := p.now()
+	cutoff := now.Add(-p.retention)
+	expired, err := p.repo.ListDeletedBefore(ctx, cutoff)
+	if err != nil {
+		slog.Error("retention: failed to list deleted databases", "error", err)
+		return 0
+	}
+
+	purged := 0
+	for _, d := range expired {
+		if ctx.Err() != nil {
+			break
+		}
+		if d.LegalHold {
+			continue
+		}
+		if err := p.repo.Purge(ctx, d.ID, cutoff); err != nil {
+			// A database restored or placed under legal hold since it was
+			// listed is no longer purgeable.
+			if !errors.Is(err, database.ErrNotFound) {
+				slog.Error("retention: failed to purge database", "database", d.Name, "error", err)
+			}
+			continue
+		}
+		purged++
+		p.recordAudit(ctx, d)
+		slog.Info("retention: database purged", "database", d.Name, "id", d.ID, "deletedAt", d.DeletedAt)
+	}
+
+	p.mu.Lock()
+	p.lastRunAt = &now
+	p.lastPurged = purged
+	p.mu.Unlock()
+	return purged
+}
+
+// Report lists what the next run will purge.
+func (p *Purger) Report(ctx context.Context) (*Report, error) {
+	p.mu.Lock()
+	report := &Report{Retention: p.retention, Interval: p.interval, LastPurged: p.lastPurged}
+	last := p.started
+	if p.lastRunAt != nil {
+		at := *p.lastRunAt
+		report.LastRunAt = &at
+		last = at
+	}
+	p.mu.Unlock()
+
+	// Runs are due every interval from the start; one overdue runs as
+	// soon as the loop gets to it.
+	report.NextRunAt = last.Add(p.interval)
+	if now := p.now(); report.NextRunAt.Before(now) {
+		report.NextRunAt = now
+	}
+	report.Cutoff = report.NextRunAt.Add(-p.retention)
+
+	expired, err := p.repo.ListDeletedBefore(ctx, report.Cutoff)
+	if err != nil {
+		return nil, err
+	}
+	report.Purgeable = []database.DeletedDatabase{}
+	report.Held = []database.DeletedDatabase{}
+	for _, d := range expired {
+		if d.LegalHold {
+			report.Held = append(report.Held, d)
+		} else {
+			report.Purgeable = append(report.Purgeable, d)
+		}
+	}
+	return report, nil
+}
+
+// recordAudit records that d was purged. The purge is already made, so a
+// failure can only be logged.
+func (p *Purger) recordAudit(ctx context.Context, d database.DeletedDatabase) {
+	if p.auditor == nil {
+		return
+	}
+	e := audit.Event{
+		Time:               p.now().UTC(),
+		Actor:              audit.ActorRetention,
+		Team:               d.OwnerTeamName,
+		Action:             "database.purge",
+		DatabaseID:         d.ID.String(),
+		Detail:             "deleted " + d.DeletedAt.UTC().Format(time.RFC3339),
+		DataClassification: d.DataClassification,
+	}
+	if err := p.auditor.Record(context.WithoutCancel(ctx), e); err != nil {
+		slog.Error("retention: failed to record audit event", "database", d.Name, "error", err)
+	}
+}
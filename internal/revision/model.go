@@ -0,0 +1,67 @@
+// Package revision keeps the history of databases and tiers: a snapshot of
+// the row after every change, so their state at any point in time can be
+// reviewed, and what changed between two revisions listed.
+package revision
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of resources whose revisions are kept.
+const (
+	KindDatabase = "database"
+	KindTier     = "tier"
+)
+
+// Operations recorded on a revision. A baseline is the state of a resource
+// when its history started to be kept.
+const (
+	OperationCreate   = "create"
+	OperationUpdate   = "update"
+	OperationDelete   = "delete"
+	OperationBaseline = "baseline"
+)
+
+// Revision is the state of a resource after one change, a row of the
+// revisions table.
+type Revision struct {
+	Kind       string
+	ResourceID uuid.UUID
+	Number     int // starts at 1 for each resource
+	Operation  string
+	// Snapshot holds the resource's columns after the change, keyed by
+	// column name, as JSON values.
+	Snapshot  map[string]any
+	ChangedBy string // updated_by of the row; empty for deletes and when unknown
+	ChangedAt time.Time
+}
+
+// Change is a column whose value differs between two revisions. From is nil
+// when the column was absent before, To when it is absent after.
+type Change struct {
+	Field string
+	From  any
+	To    any
+}
+
+// Diff lists the columns that differ from prev to next, sorted by name. A
+// nil prev lists every column of next.
+func Diff(prev, next map[string]any) []Change {
+	changes := []Change{}
+	for field, to := range next {
+		if from, ok := prev[field]; !ok || !reflect.DeepEqual(from, to) {
+			changes = append(changes, Change{Field: field, From: from, To: to})
+		}
+	}
+	for field, from := range prev {
+		if _, ok := next[field]; !ok {
+			changes = append(changes, Change{Field: field, From: from})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Field < changes[j].Field })
+	return changes
+}
@@ -0,0 +1,52 @@
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRepository implements Repository using pgxpool.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewRepository creates a new Repository backed by the given connection pool.
+func NewRepository(pool *pgxpool.Pool) Repository {
+	return &PostgresRepository{pool: pool}
+}
+
+// List returns the revisions of a resource, oldest first.
+func (r *PostgresRepository) List(ctx context.Context, kind string, id uuid.UUID) ([]Revision, error) {
+	query := `
+		SELECT revision, operation, snapshot, changed_by, changed_at
+		FROM revisions
+		WHERE resource_type = $1 AND resource_id = $2
+		ORDER BY revision ASC`
+
+	rows, err := r.pool.Query(ctx, query, kind, id)
+	if err != nil {
+		return nil, fmt.Errorf("listing revisions: %w", err)
+	}
+	defer rows.Close()
+
+	revisions := []Revision{}
+	for rows.Next() {
+		rev := Revision{Kind: kind, ResourceID: id}
+		var snapshot []byte
+		if err := rows.Scan(&rev.Number, &rev.Operation, &snapshot, &rev.ChangedBy, &rev.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scanning revision row: %w", err)
+		}
+		if err := json.Unmarshal(snapshot, &rev.Snapshot); err != nil {
+			return nil, fmt.Errorf("decoding revision snapshot: %w", err)
+		}
+		revisions = append(revisions, rev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating revision rows: %w", err)
+	}
+	return revisions, nil
+}
@@ -0,0 +1,16 @@
+package revision
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Repository reads the revisions table. Revisions are written by triggers
+// on the tables they track, and never change once written.
+type Repository interface {
+	// List returns the revisions of a resource of the given kind, oldest
+	// first. A resource without revisions, because it never existed, has
+	// none.
+	List(ctx context.Context, kind string, id uuid.UUID) ([]Revision, error)
+}
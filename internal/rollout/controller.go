@@ -0,0 +1,660 @@
+// Package rollout re-applies a tier's new blueprint to the tier's existing
+// databases in stages: a canary batch first, then fixed-size batches, each
+// verified healthy before the next starts. Rollouts can be paused, resumed
+// and rolled back to the previous blueprint.
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/metrics"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/requestid"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// Defaults for batch sizing and health verification.
+const (
+	DefaultCanarySize    = 1
+	DefaultBatchSize     = 5
+	DefaultVerifyTimeout = 10 * time.Minute
+)
+
+// Actor holds the database mutation lock while a rollout applies a blueprint,
+// and is recorded as who changed a tier a rollback restores.
+const Actor = "system:rollout"
+
+var (
+	rolloutApplies = metrics.NewCounter(
+		"daap_rollout_applies_total",
+		"Number of databases a rollout applied a new blueprint to.",
+	)
+	rolloutFailures = metrics.NewCounter(
+		"daap_rollout_failures_total",
+		"Number of rollout targets that failed to apply or become healthy.",
+	)
+)
+
+// Controller starts rollouts and drives them to completion.
+type Controller struct {
+	repo     Repository
+	dbRepo   database.Repository
+	tierRepo tier.Repository
+	bpRepo   blueprint.Repository
+	registry *provider.Registry
+	interval time.Duration
+
+	canarySize    int
+	batchSize     int
+	verifyTimeout time.Duration
+	notifier      notify.Notifier
+	freezes       freeze.Gate
+	locker        *database.Locker
+	specs         database.SpecRepository
+	signer        *blueprint.Signer
+	now           func() time.Time
+}
+
+// Option configures a Controller.
+type Option func(*Controller)
+
+// WithCanarySize sets how many databases the canary batch holds. The default
+// is DefaultCanarySize.
+func WithCanarySize(n int) Option {
+	return func(c *Controller) {
+		c.canarySize = n
+	}
+}
+
+// WithBatchSize sets how many databases each batch after the canary holds.
+// The default is DefaultBatchSize.
+func WithBatchSize(n int) Option {
+	return func(c *Controller) {
+		c.batchSize = n
+	}
+}
+
+// WithVerifyTimeout sets how long an applied database may take to report
+// healthy before the rollout pauses. The default is DefaultVerifyTimeout.
+func WithVerifyTimeout(d time.Duration) Option {
+	return func(c *Controller) {
+		c.verifyTimeout = d
+	}
+}
+
+// WithNotifier sets the notifier used when a rollout pauses on a failure.
+// The default logs notifications.
+func WithNotifier(n notify.Notifier) Option {
+	return func(c *Controller) {
+		c.notifier = n
+	}
+}
+
+// WithFreezes holds back databases whose team is under a change freeze:
+// their batch waits until the freeze ends. Rollbacks are not held back.
+func WithFreezes(g freeze.Gate) Option {
+	return func(c *Controller) {
+		c.freezes = g
+	}
+}
+
+// WithLocker takes each database's mutation lock while applying a blueprint
+// to it. A locked database makes its batch, or the rollback, wait for the
+// next pass.
+func WithLocker(l *database.Locker) Option {
+	return func(c *Controller) {
+		c.locker = l
+	}
+}
+
+// WithSpecs records the spec each database is applied with, and re-applied
+// with on rollback, in specs.
+func WithSpecs(specs database.SpecRepository) Option {
+	return func(c *Controller) {
+		c.specs = specs
+	}
+}
+
+// WithSigner only applies blueprints that signer verifies. A blueprint that
+// does not match its signature pauses the rollout, or leaves its rollback
+// stuck.
+func WithSigner(signer *blueprint.Signer) Option {
+	return func(c *Controller) {
+		c.signer = signer
+	}
+}
+
+// WithClock sets the function used to read the current time.
+func WithClock(now func() time.Time) Option {
+	return func(c *Controller) {
+		c.now = now
+	}
+}
+
+// New creates a new Controller.
+func New(repo Repository, dbRepo database.Repository, tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, interval time.Duration, opts ...Option) *Controller {
+	c := &Controller{
+		repo:          repo,
+		dbRepo:        dbRepo,
+		tierRepo:      tierRepo,
+		bpRepo:        bpRepo,
+		registry:      registry,
+		interval:      interval,
+		canarySize:    DefaultCanarySize,
+		batchSize:     DefaultBatchSize,
+		verifyTimeout: DefaultVerifyTimeout,
+		notifier:      notify.LogNotifier{},
+		now:           time.Now,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Active returns the tier's active rollout, or nil if it has none.
+func (c *Controller) Active(ctx context.Context, tierID uuid.UUID) (*Rollout, error) {
+	rollouts, err := c.repo.List(ctx, ListFilter{TierID: &tierID, Statuses: ActiveStatuses})
+	if err != nil {
+		return nil, err
+	}
+	if len(rollouts) == 0 {
+		return nil, nil
+	}
+	return &rollouts[0], nil
+}
+
+// Begin starts rolling t's blueprint out to the databases of t, which
+// previously used the blueprint from. It returns ErrRolloutActive if the
+// tier already has an active rollout. A tier without databases gets a
+// completed rollout, so that the change still shows in its history.
+func (c *Controller) Begin(ctx context.Context, t *tier.Tier, from uuid.UUID) (*Rollout, error) {
+	if t.BlueprintID == nil {
+		return nil, errors.New("tier has no blueprint")
+	}
+	active, err := c.Active(ctx, t.ID)
+	if err != nil {
+		return nil, fmt.Errorf("checking active rollouts: %w", err)
+	}
+	if active != nil {
+		return nil, ErrRolloutActive
+	}
+
+	fromBP, err := c.bpRepo.GetByID(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("getting previous blueprint: %w", err)
+	}
+	toBP, err := c.bpRepo.GetByID(ctx, *t.BlueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("getting blueprint: %w", err)
+	}
+
+	dbs, err := c.tierDatabases(ctx, t.ID)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]Target, len(dbs))
+	batchCount := 0
+	for i, db := range dbs {
+		batch := 0
+		if i >= c.canarySize {
+			batch = 1 + (i-c.canarySize)/c.batchSize
+		}
+		targets[i] = Target{DatabaseID: db.ID, DatabaseName: db.Name, Batch: batch, Status: TargetPending}
+		batchCount = batch + 1
+	}
+
+	r := &Rollout{
+		TierID:            t.ID,
+		FromBlueprintID:   fromBP.ID,
+		FromBlueprintName: fromBP.Name,
+		ToBlueprintID:     toBP.ID,
+		ToBlueprintName:   toBP.Name,
+		Status:            StatusInProgress,
+		BatchCount:        batchCount,
+	}
+	if len(targets) == 0 {
+		r.Status = StatusCompleted
+	}
+	if err := c.repo.Create(ctx, r, targets); err != nil {
+		return nil, err
+	}
+	slog.Info("rollout started", "rollout", r.ID, "tier", t.Name, "from", fromBP.Name, "to", toBP.Name,
+		"databases", len(targets), "batches", batchCount)
+	return r, nil
+}
+
+// tierDatabases returns the tier's databases, oldest first.
+func (c *Controller) tierDatabases(ctx context.Context, tierID uuid.UUID) ([]database.Database, error) {
+	var dbs []database.Database
+	for page := 1; ; page++ {
+		result, err := c.dbRepo.List(ctx, database.ListFilter{TierID: &tierID, Page: page, Limit: 100})
+		if err != nil {
+			return nil, fmt.Errorf("listing tier databases: %w", err)
+		}
+		dbs = append(dbs, result.Databases...)
+		if len(result.Databases) < 100 || len(dbs) >= result.Total {
+			break
+		}
+	}
+	slices.Reverse(dbs)
+	return dbs, nil
+}
+
+// Pause stops a rollout from starting new batches.
+func (c *Controller) Pause(ctx context.Context, id uuid.UUID) (*Rollout, error) {
+	return c.repo.Transition(ctx, id, []string{StatusInProgress}, StatusPaused, "")
+}
+
+// Resume continues a paused rollout, retrying the failed databases of its
+// current batch.
+func (c *Controller) Resume(ctx context.Context, id uuid.UUID) (*Rollout, error) {
+	r, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if r.Status != StatusPaused {
+		return nil, ErrInvalidTransition
+	}
+	targets, err := c.repo.ListTargets(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range targets {
+		if t.Batch == r.CurrentBatch && t.Status == TargetFailed {
+			t.Status = TargetPending
+			t.Error = ""
+			t.AppliedAt = nil
+			if err := c.repo.UpdateTarget(ctx, &t); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c.repo.Transition(ctx, id, []string{StatusPaused}, StatusInProgress, "")
+}
+
+// Rollback points the tier back at the previous blueprint and re-applies it
+// to every database the rollout touched.
+func (c *Controller) Rollback(ctx context.Context, id uuid.UUID) (*Rollout, error) {
+	r, err := c.repo.Transition(ctx, id, []string{StatusInProgress, StatusPaused, StatusCompleted}, StatusRollingBack, "")
+	if err != nil {
+		return nil, err
+	}
+	t, err := c.tierRepo.GetByID(ctx, r.TierID)
+	if err != nil {
+		return nil, fmt.Errorf("getting tier: %w", err)
+	}
+	// Leave the tier alone if it was pointed at yet another blueprint since.
+	if t.BlueprintID != nil && *t.BlueprintID == r.ToBlueprintID {
+		from := r.FromBlueprintID
+		if _, err := c.tierRepo.Update(ctx, t.ID, tier.UpdateFields{BlueprintID: &from, UpdatedBy: Actor}); err != nil {
+			return nil, fmt.Errorf("restoring tier blueprint: %w", err)
+		}
+	}
+	slog.Info("rollout rolling back", "rollout", r.ID, "tier", r.TierName, "to", r.FromBlueprintName)
+	return r, nil
+}
+
+// Start begins the rollout loop. It blocks until ctx is cancelled.
+func (c *Controller) Start(ctx context.Context) {
+	slog.Info("rollout controller started", "interval", c.interval.String())
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.Info("rollout controller stopped")
+			return
+		case <-ticker.C:
+			c.progress(ctx)
+		}
+	}
+}
+
+// RunOnce advances every running rollout by one step.
+func (c *Controller) RunOnce(ctx context.Context) {
+	c.progress(ctx)
+}
+
+func (c *Controller) progress(ctx context.Context) {
+	rollouts, err := c.repo.List(ctx, ListFilter{Statuses: []string{StatusInProgress, StatusRollingBack}})
+	if err != nil {
+		slog.Error("rollout: failed to list rollouts", "error", err)
+		return
+	}
+	for _, r := range rollouts {
+		if ctx.Err() != nil {
+			return
+		}
+		switch r.Status {
+		case StatusInProgress:
+			c.advance(ctx, &r)
+		case StatusRollingBack:
+			c.revert(ctx, &r)
+		}
+	}
+}
+
+// advance applies the current batch, verifies it and moves to the next one.
+func (c *Controller) advance(ctx context.Context, r *Rollout) {
+	targets, err := c.repo.ListTargets(ctx, r.ID)
+	if err != nil {
+		slog.Error("rollout: failed to list targets", "rollout", r.ID, "error", err)
+		return
+	}
+	if r.CurrentBatch >= r.BatchCount {
+		if _, err := c.repo.Transition(ctx, r.ID, []string{StatusInProgress}, StatusCompleted, ""); err == nil {
+			slog.Info("rollout completed", "rollout", r.ID, "tier", r.TierName, "blueprint", r.ToBlueprintName)
+		}
+		return
+	}
+
+	bp, p, err := c.resolve(ctx, r.ToBlueprintID)
+	if err != nil {
+		c.pause(ctx, r, nil, err)
+		return
+	}
+
+	done := true
+	for _, t := range targets {
+		if t.Batch != r.CurrentBatch {
+			continue
+		}
+		switch t.Status {
+		case TargetPending:
+			if !c.applyTarget(ctx, r, &t, bp, p) {
+				return
+			}
+			done = false
+		case TargetApplied:
+			healthy, ok := c.verifyTarget(ctx, r, &t, bp, p)
+			if !ok {
+				return
+			}
+			done = done && healthy
+		case TargetFailed:
+			// Left over from before a resume that did not reset it; wait
+			// for an operator.
+			return
+		}
+	}
+	if done {
+		if err := c.repo.SetCurrentBatch(ctx, r.ID, r.CurrentBatch+1); err != nil {
+			slog.Error("rollout: failed to advance batch", "rollout", r.ID, "error", err)
+			return
+		}
+		slog.Info("rollout batch healthy", "rollout", r.ID, "tier", r.TierName, "batch", r.CurrentBatch, "batches", r.BatchCount)
+	}
+}
+
+// applyTarget applies the blueprint to one database. It returns false if
+// the rollout paused or must wait for the next pass.
+func (c *Controller) applyTarget(ctx context.Context, r *Rollout, t *Target, bp *blueprint.Blueprint, p provider.Provider) bool {
+	db, err := c.dbRepo.GetByID(ctx, t.DatabaseID)
+	if errors.Is(err, database.ErrNotFound) || (err == nil && db.DeletedAt != nil) {
+		t.Status = TargetSkipped
+		c.saveTarget(ctx, t)
+		return true
+	}
+	if err != nil {
+		slog.Error("rollout: failed to get database", "rollout", r.ID, "database", t.DatabaseName, "error", err)
+		return false
+	}
+
+	if c.freezes != nil {
+		window, err := c.freezes.Frozen(ctx, db.OwnerTeamID)
+		if err != nil {
+			slog.Warn("rollout: failed to check change freezes", "rollout", r.ID, "database", db.Name, "error", err)
+			return false
+		}
+		if window != nil {
+			slog.Info("rollout: apply deferred by change freeze", "rollout", r.ID, "database", db.Name, "freeze", window.ID, "until", window.EndsAt)
+			return false
+		}
+	}
+
+	if db.ReconciliationPaused(time.Now()) {
+		slog.Info("rollout: apply deferred by reconciliation pause", "rollout", r.ID, "database", db.Name, "until", db.ReconciliationPause.Until)
+		return false
+	}
+
+	rt, err := c.tier(ctx, r)
+	if err != nil {
+		slog.Error("rollout: failed to get tier", "rollout", r.ID, "database", db.Name, "error", err)
+		return false
+	}
+
+	release, ok := c.lock(ctx, r, db)
+	if !ok {
+		return false
+	}
+	defer release()
+
+	if err := p.Apply(applyContext(ctx, r), db.ProviderDatabase(rt, bp), bp.Manifests); err != nil {
+		rolloutFailures.Inc()
+		t.Status = TargetFailed
+		t.Error = err.Error()
+		c.saveTarget(ctx, t)
+		c.pause(ctx, r, db, fmt.Errorf("applying %s to %s: %w", bp.Name, db.Name, err))
+		return false
+	}
+	rolloutApplies.Inc()
+	c.recordSpec(ctx, r, db, bp)
+	applied := c.now().UTC()
+	t.Status = TargetApplied
+	t.AppliedAt = &applied
+	c.saveTarget(ctx, t)
+	return true
+}
+
+// verifyTarget checks an applied database's health. It returns whether the
+// database is healthy, and false for ok if the rollout paused.
+func (c *Controller) verifyTarget(ctx context.Context, r *Rollout, t *Target, bp *blueprint.Blueprint, p provider.Provider) (healthy, ok bool) {
+	db, err := c.dbRepo.GetByID(ctx, t.DatabaseID)
+	if errors.Is(err, database.ErrNotFound) || (err == nil && db.DeletedAt != nil) {
+		t.Status = TargetSkipped
+		c.saveTarget(ctx, t)
+		return true, true
+	}
+	if err != nil {
+		slog.Error("rollout: failed to get database", "rollout", r.ID, "database", t.DatabaseName, "error", err)
+		return false, false
+	}
+
+	rt, err := c.tier(ctx, r)
+	if err != nil {
+		slog.Error("rollout: failed to get tier", "rollout", r.ID, "database", db.Name, "error", err)
+		return false, true
+	}
+
+	health, err := p.CheckHealth(ctx, db.ProviderDatabase(rt, bp))
+	if err == nil && health.Status == "ready" {
+		t.Status = TargetHealthy
+		c.saveTarget(ctx, t)
+		return true, true
+	}
+
+	var reason error
+	switch {
+	case err == nil && health.Status == "error":
+		reason = fmt.Errorf("%s reported error after applying %s", db.Name, bp.Name)
+	case t.AppliedAt != nil && c.now().Sub(*t.AppliedAt) > c.verifyTimeout:
+		reason = fmt.Errorf("%s not healthy %s after applying %s", db.Name, c.verifyTimeout, bp.Name)
+	default:
+		return false, true
+	}
+	rolloutFailures.Inc()
+	t.Status = TargetFailed
+	t.Error = reason.Error()
+	c.saveTarget(ctx, t)
+	c.pause(ctx, r, db, reason)
+	return false, false
+}
+
+// revert re-applies the previous blueprint to every touched database and
+// finishes the rollback once none is left.
+func (c *Controller) revert(ctx context.Context, r *Rollout) {
+	targets, err := c.repo.ListTargets(ctx, r.ID)
+	if err != nil {
+		slog.Error("rollout: failed to list targets", "rollout", r.ID, "error", err)
+		return
+	}
+	bp, p, err := c.resolve(ctx, r.FromBlueprintID)
+	if err != nil {
+		c.stuck(ctx, r, err)
+		return
+	}
+	rt, err := c.tier(ctx, r)
+	if err != nil {
+		slog.Error("rollout: failed to get tier", "rollout", r.ID, "error", err)
+		return
+	}
+
+	for _, t := range targets {
+		if t.Status != TargetApplied && t.Status != TargetHealthy && t.Status != TargetFailed {
+			continue
+		}
+		db, err := c.dbRepo.GetByID(ctx, t.DatabaseID)
+		if errors.Is(err, database.ErrNotFound) || (err == nil && db.DeletedAt != nil) {
+			t.Status = TargetSkipped
+			c.saveTarget(ctx, &t)
+			continue
+		}
+		if err != nil {
+			slog.Error("rollout: failed to get database", "rollout", r.ID, "database", t.DatabaseName, "error", err)
+			return
+		}
+		release, ok := c.lock(ctx, r, db)
+		if !ok {
+			return
+		}
+		err = p.Apply(applyContext(ctx, r), db.ProviderDatabase(rt, bp), bp.Manifests)
+		release()
+		if err != nil {
+			c.stuck(ctx, r, fmt.Errorf("re-applying %s to %s: %w", bp.Name, db.Name, err))
+			return
+		}
+		c.recordSpec(ctx, r, db, bp)
+		t.Status = TargetRolledBack
+		t.Error = ""
+		c.saveTarget(ctx, &t)
+	}
+
+	if _, err := c.repo.Transition(ctx, r.ID, []string{StatusRollingBack}, StatusRolledBack, ""); err == nil {
+		slog.Info("rollout rolled back", "rollout", r.ID, "tier", r.TierName, "blueprint", r.FromBlueprintName)
+	}
+}
+
+// lock takes db's mutation lock for r. It returns false, after logging, when
+// the database is locked by another operation.
+func (c *Controller) lock(ctx context.Context, r *Rollout, db *database.Database) (release func(), ok bool) {
+	if c.locker == nil {
+		return func() {}, true
+	}
+	lock, err := c.locker.Acquire(ctx, db.ID, "rollout", Actor, "")
+	if err != nil {
+		slog.Info("rollout: apply deferred", "rollout", r.ID, "database", db.Name, "reason", err)
+		return nil, false
+	}
+	return func() {
+		if err := c.locker.Release(ctx, lock); err != nil {
+			slog.Warn("rollout: failed to release database lock", "rollout", r.ID, "database", db.Name, "error", err)
+		}
+	}, true
+}
+
+func (c *Controller) resolve(ctx context.Context, blueprintID uuid.UUID) (*blueprint.Blueprint, provider.Provider, error) {
+	bp, err := c.bpRepo.GetByID(ctx, blueprintID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting blueprint %s: %w", blueprintID, err)
+	}
+	if err := c.signer.Verify(bp); err != nil {
+		return nil, nil, err
+	}
+	p, ok := c.registry.Get(bp.Provider)
+	if !ok {
+		return nil, nil, fmt.Errorf("provider %q not registered", bp.Provider)
+	}
+	return bp, p, nil
+}
+
+// pause stops a rollout on a failure and notifies. db is the failed
+// database, if any.
+func (c *Controller) pause(ctx context.Context, r *Rollout, db *database.Database, reason error) {
+	if _, err := c.repo.Transition(ctx, r.ID, []string{StatusInProgress}, StatusPaused, reason.Error()); err != nil {
+		if !errors.Is(err, ErrInvalidTransition) {
+			slog.Error("rollout: failed to pause", "rollout", r.ID, "error", err)
+		}
+		return
+	}
+	slog.Warn("rollout paused", "rollout", r.ID, "tier", r.TierName, "batch", r.CurrentBatch, "reason", reason)
+
+	n := notify.Notification{
+		Event:   "RolloutPaused",
+		Message: fmt.Sprintf("rollout of blueprint %s to tier %s paused in batch %d: %s", r.ToBlueprintName, r.TierName, r.CurrentBatch, reason),
+		Reason:  reason.Error(),
+		Time:    c.now().UTC(),
+	}
+	if db != nil {
+		n.DatabaseID = db.ID
+		n.Database = db.Name
+		n.OwnerTeam = db.OwnerTeamName
+	}
+	if err := c.notifier.Notify(ctx, n); err != nil {
+		slog.Error("rollout: failed to send pause notification", "rollout", r.ID, "error", err)
+	}
+}
+
+// stuck records why a rollback cannot make progress; it is retried on the
+// next pass.
+func (c *Controller) stuck(ctx context.Context, r *Rollout, reason error) {
+	slog.Error("rollout: rollback failed", "rollout", r.ID, "tier", r.TierName, "error", reason)
+	if _, err := c.repo.Transition(ctx, r.ID, []string{StatusRollingBack}, StatusRollingBack, reason.Error()); err != nil {
+		slog.Error("rollout: failed to record rollback error", "rollout", r.ID, "error", err)
+	}
+}
+
+func (c *Controller) saveTarget(ctx context.Context, t *Target) {
+	if err := c.repo.UpdateTarget(ctx, t); err != nil {
+		slog.Error("rollout: failed to update target", "rollout", t.RolloutID, "database", t.DatabaseName, "error", err)
+	}
+}
+
+// recordSpec records that db was applied with bp on the rollout's tier.
+func (c *Controller) recordSpec(ctx context.Context, r *Rollout, db *database.Database, bp *blueprint.Blueprint) {
+	if c.specs == nil {
+		return
+	}
+	t, err := c.tierRepo.GetByID(ctx, r.TierID)
+	if err != nil {
+		slog.Error("rollout: failed to get tier to record spec", "rollout", r.ID, "database", db.Name, "error", err)
+		return
+	}
+	database.RecordSpec(ctx, c.specs, db, t, bp)
+}
+
+// tier returns the rollout's tier, which provider calls for its targets
+// carry: applies without its topology and disruption policy would drop the
+// constraints the tier sets.
+func (c *Controller) tier(ctx context.Context, r *Rollout) (*tier.Tier, error) {
+	return c.tierRepo.GetByID(ctx, r.TierID)
+}
+
+// applyContext returns ctx identifying the rollout as the origin of the
+// provider calls made with it, so applied resources can be traced back to
+// it.
+func applyContext(ctx context.Context, r *Rollout) context.Context {
+	return requestid.With(ctx, "rollout:"+r.ID.String())
+}
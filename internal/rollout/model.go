@@ -0,0 +1,68 @@
+package rollout
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Rollout statuses.
+const (
+	StatusInProgress  = "in_progress"
+	StatusPaused      = "paused"
+	StatusCompleted   = "completed"
+	StatusRollingBack = "rolling_back"
+	StatusRolledBack  = "rolled_back"
+)
+
+// ActiveStatuses are the statuses of a rollout that still owns its tier's
+// databases. A tier has at most one active rollout.
+var ActiveStatuses = []string{StatusInProgress, StatusPaused, StatusRollingBack}
+
+// Target statuses.
+const (
+	TargetPending    = "pending"
+	TargetApplied    = "applied" // manifests applied, waiting for the database to be healthy
+	TargetHealthy    = "healthy"
+	TargetFailed     = "failed"
+	TargetSkipped    = "skipped" // database deleted before its batch
+	TargetRolledBack = "rolled_back"
+)
+
+// Rollout represents a row in the rollouts table: the re-application of a
+// tier's new blueprint to the tier's existing databases, batch by batch.
+// Batch 0 is the canary batch.
+type Rollout struct {
+	ID                uuid.UUID
+	TierID            uuid.UUID
+	TierName          string // transient, populated via JOIN
+	FromBlueprintID   uuid.UUID
+	FromBlueprintName string
+	ToBlueprintID     uuid.UUID
+	ToBlueprintName   string
+	Status            string
+	CurrentBatch      int
+	BatchCount        int
+	Error             string // why the rollout paused, or why rollback is stuck
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Target represents a row in the rollout_targets table: one database of a
+// rollout.
+type Target struct {
+	RolloutID    uuid.UUID
+	DatabaseID   uuid.UUID
+	DatabaseName string
+	Batch        int
+	Status       string
+	Error        string
+	AppliedAt    *time.Time
+	UpdatedAt    time.Time
+}
+
+// ListFilter holds optional filters for listing rollouts.
+type ListFilter struct {
+	TierID   *uuid.UUID
+	Statuses []string // any of; empty means all
+}
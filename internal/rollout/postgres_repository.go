@@ -0,0 +1,205 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PostgresRepository implements Repository using pgxpool.
+type PostgresRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPostgresRepository creates a new Repository backed by the given connection pool.
+func NewPostgresRepository(pool *pgxpool.Pool) Repository {
+	return &PostgresRepository{pool: pool}
+}
+
+// allColumns is the ordered list of columns scanned from the rollouts table
+// with a JOIN on tiers for the transient TierName field.
+const allColumns = `r.id, r.tier_id, t.name, r.from_blueprint_id, r.from_blueprint_name,
+	r.to_blueprint_id, r.to_blueprint_name, r.status, r.current_batch, r.batch_count,
+	r.error, r.created_at, r.updated_at`
+
+const fromClause = `FROM rollouts r JOIN tiers t ON r.tier_id = t.id`
+
+func scanRollout(row pgx.Row) (*Rollout, error) {
+	var r Rollout
+	err := row.Scan(
+		&r.ID, &r.TierID, &r.TierName, &r.FromBlueprintID, &r.FromBlueprintName,
+		&r.ToBlueprintID, &r.ToBlueprintName, &r.Status, &r.CurrentBatch, &r.BatchCount,
+		&r.Error, &r.CreatedAt, &r.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrRolloutNotFound
+		}
+		return nil, fmt.Errorf("scanning rollout row: %w", err)
+	}
+	return &r, nil
+}
+
+// Create inserts a rollout and its targets in one transaction.
+func (p *PostgresRepository) Create(ctx context.Context, r *Rollout, targets []Target) error {
+	tx, err := p.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO rollouts (tier_id, from_blueprint_id, from_blueprint_name, to_blueprint_id,
+			to_blueprint_name, status, batch_count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at, updated_at`,
+		r.TierID, r.FromBlueprintID, r.FromBlueprintName, r.ToBlueprintID,
+		r.ToBlueprintName, r.Status, r.BatchCount,
+	).Scan(&r.ID, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting rollout: %w", err)
+	}
+
+	for i := range targets {
+		t := &targets[i]
+		t.RolloutID = r.ID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO rollout_targets (rollout_id, database_id, database_name, batch, status)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING updated_at`,
+			t.RolloutID, t.DatabaseID, t.DatabaseName, t.Batch, t.Status,
+		).Scan(&t.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("inserting rollout target: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("committing rollout: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a rollout by ID.
+func (p *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Rollout, error) {
+	query := fmt.Sprintf(`SELECT %s %s WHERE r.id = $1`, allColumns, fromClause)
+	return scanRollout(p.pool.QueryRow(ctx, query, id))
+}
+
+// List returns rollouts matching the filter, newest first.
+func (p *PostgresRepository) List(ctx context.Context, filter ListFilter) ([]Rollout, error) {
+	var conditions []string
+	var args []any
+	if filter.TierID != nil {
+		args = append(args, *filter.TierID)
+		conditions = append(conditions, fmt.Sprintf("r.tier_id = $%d", len(args)))
+	}
+	if len(filter.Statuses) > 0 {
+		args = append(args, filter.Statuses)
+		conditions = append(conditions, fmt.Sprintf("r.status = ANY($%d)", len(args)))
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`SELECT %s %s %s ORDER BY r.created_at DESC`, allColumns, fromClause, where)
+	rows, err := p.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing rollouts: %w", err)
+	}
+	defer rows.Close()
+
+	rollouts := []Rollout{}
+	for rows.Next() {
+		r, err := scanRollout(rows)
+		if err != nil {
+			return nil, err
+		}
+		rollouts = append(rollouts, *r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating rollout rows: %w", err)
+	}
+	return rollouts, nil
+}
+
+// ListTargets returns a rollout's targets ordered by batch and database name.
+func (p *PostgresRepository) ListTargets(ctx context.Context, rolloutID uuid.UUID) ([]Target, error) {
+	rows, err := p.pool.Query(ctx, `
+		SELECT rollout_id, database_id, database_name, batch, status, error, applied_at, updated_at
+		FROM rollout_targets
+		WHERE rollout_id = $1
+		ORDER BY batch, database_name`, rolloutID)
+	if err != nil {
+		return nil, fmt.Errorf("listing rollout targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets := []Target{}
+	for rows.Next() {
+		var t Target
+		if err := rows.Scan(&t.RolloutID, &t.DatabaseID, &t.DatabaseName, &t.Batch,
+			&t.Status, &t.Error, &t.AppliedAt, &t.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning rollout target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating rollout targets: %w", err)
+	}
+	return targets, nil
+}
+
+// Transition moves a rollout between statuses atomically.
+func (p *PostgresRepository) Transition(ctx context.Context, id uuid.UUID, from []string, to string, errMsg string) (*Rollout, error) {
+	tag, err := p.pool.Exec(ctx, `
+		UPDATE rollouts SET status = $2, error = $3, updated_at = NOW()
+		WHERE id = $1 AND status = ANY($4)`, id, to, errMsg, from)
+	if err != nil {
+		return nil, fmt.Errorf("updating rollout status: %w", err)
+	}
+	r, err := p.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrInvalidTransition
+	}
+	return r, nil
+}
+
+// SetCurrentBatch records the batch a rollout is working on.
+func (p *PostgresRepository) SetCurrentBatch(ctx context.Context, id uuid.UUID, batch int) error {
+	tag, err := p.pool.Exec(ctx, `
+		UPDATE rollouts SET current_batch = $2, updated_at = NOW() WHERE id = $1`, id, batch)
+	if err != nil {
+		return fmt.Errorf("updating rollout batch: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrRolloutNotFound
+	}
+	return nil
+}
+
+// UpdateTarget saves a target's status, error and applied time.
+func (p *PostgresRepository) UpdateTarget(ctx context.Context, t *Target) error {
+	err := p.pool.QueryRow(ctx, `
+		UPDATE rollout_targets SET status = $3, error = $4, applied_at = $5, updated_at = NOW()
+		WHERE rollout_id = $1 AND database_id = $2
+		RETURNING updated_at`,
+		t.RolloutID, t.DatabaseID, t.Status, t.Error, t.AppliedAt,
+	).Scan(&t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrRolloutNotFound
+		}
+		return fmt.Errorf("updating rollout target: %w", err)
+	}
+	return nil
+}
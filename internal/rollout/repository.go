@@ -0,0 +1,36 @@
+package rollout
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrRolloutNotFound is returned when a rollout record is not found.
+var ErrRolloutNotFound = errors.New("rollout not found")
+
+// ErrInvalidTransition is returned when a rollout is not in a status the
+// requested transition starts from.
+var ErrInvalidTransition = errors.New("invalid rollout status transition")
+
+// ErrRolloutActive is returned when starting a rollout on a tier that already
+// has an active one.
+var ErrRolloutActive = errors.New("tier has an active rollout")
+
+// Repository provides persistence for rollouts and their targets.
+type Repository interface {
+	// Create inserts a rollout and its targets.
+	Create(ctx context.Context, r *Rollout, targets []Target) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Rollout, error)
+	// List returns rollouts matching the filter, newest first.
+	List(ctx context.Context, filter ListFilter) ([]Rollout, error)
+	// ListTargets returns a rollout's targets ordered by batch and database name.
+	ListTargets(ctx context.Context, rolloutID uuid.UUID) ([]Target, error)
+	// Transition moves a rollout to status to if its status is one of from,
+	// and sets its error message. It returns ErrInvalidTransition otherwise.
+	Transition(ctx context.Context, id uuid.UUID, from []string, to string, errMsg string) (*Rollout, error)
+	SetCurrentBatch(ctx context.Context, id uuid.UUID, batch int) error
+	// UpdateTarget saves a target's status, error and applied time.
+	UpdateTarget(ctx context.Context, t *Target) error
+}
@@ -0,0 +1,36 @@
+package secrets
+
+import "context"
+
+// SecretReader reads the data of a Kubernetes Secret; *k8s.Client
+// implements it.
+type SecretReader interface {
+	SecretData(ctx context.Context, namespace, name string) (map[string][]byte, error)
+}
+
+// KubernetesStore serves the keys of one Kubernetes Secret. The Secret is
+// read on every lookup, so rotated values are picked up on the next apply.
+type KubernetesStore struct {
+	reader    SecretReader
+	namespace string
+	name      string
+}
+
+// NewKubernetesStore creates a KubernetesStore over the Secret
+// namespace/name.
+func NewKubernetesStore(reader SecretReader, namespace, name string) *KubernetesStore {
+	return &KubernetesStore{reader: reader, namespace: namespace, name: name}
+}
+
+// Lookup returns the value of the Secret's key name.
+func (s *KubernetesStore) Lookup(ctx context.Context, name string) (string, error) {
+	data, err := s.reader.SecretData(ctx, s.namespace, s.name)
+	if err != nil {
+		return "", err
+	}
+	v, ok := data[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	return string(v), nil
+}
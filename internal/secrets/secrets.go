@@ -0,0 +1,87 @@
+// Package secrets resolves the secret references of blueprint manifests,
+// written {{ .Secrets.<name> }}, from a value store (a Kubernetes Secret or
+// Vault) when the manifests are rendered, so credentials never live in the
+// blueprint text DAAP stores.
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ErrNotFound is returned by a Store that holds no value under a name.
+var ErrNotFound = errors.New("secret not found")
+
+// ErrNoStore is returned when manifests reference secrets but no store is
+// configured.
+var ErrNoStore = errors.New("blueprint references secrets but no secret store is configured")
+
+// Store looks up secret values by name.
+type Store interface {
+	// Lookup returns the value stored under name, or ErrNotFound.
+	Lookup(ctx context.Context, name string) (string, error)
+}
+
+// referencePattern matches {{ .Secrets.<name> }} references. Names are Go
+// template identifiers, since the template engine resolves them as fields.
+var referencePattern = regexp.MustCompile(`\.Secrets\.([A-Za-z_][A-Za-z0-9_]*)`)
+
+// References returns the secret names manifests reference, sorted and
+// without duplicates.
+func References(manifests string) []string {
+	var names []string
+	for _, m := range referencePattern.FindAllStringSubmatch(manifests, -1) {
+		names = append(names, m[1])
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+// Resolve looks up every secret manifests reference. It returns nil when
+// there are none, and ErrNoStore when there are some but store is nil.
+// Errors name the missing secrets, never their values.
+func Resolve(ctx context.Context, store Store, manifests string) (map[string]string, error) {
+	names := References(manifests)
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if store == nil {
+		return nil, fmt.Errorf("%w: %s", ErrNoStore, strings.Join(names, ", "))
+	}
+
+	values := make(map[string]string, len(names))
+	var missing []string
+	for _, name := range names {
+		v, err := store.Lookup(ctx, name)
+		if errors.Is(err, ErrNotFound) {
+			missing = append(missing, name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("looking up secret %s: %w", name, err)
+		}
+		values[name] = v
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, strings.Join(missing, ", "))
+	}
+	return values, nil
+}
+
+// Placeholders maps every secret manifests reference to "<secret:name>", for
+// renderings that are shown or exported rather than applied.
+func Placeholders(manifests string) map[string]string {
+	names := References(manifests)
+	if len(names) == 0 {
+		return nil
+	}
+	values := make(map[string]string, len(names))
+	for _, name := range names {
+		values[name] = "<secret:" + name + ">"
+	}
+	return values
+}
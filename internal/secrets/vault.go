@@ -0,0 +1,66 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultStore serves the keys of one secret of a Vault KV version 2 engine,
+// read over Vault's HTTP API.
+type VaultStore struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewVaultStore creates a VaultStore reading the secret at path, the API
+// path of a KV v2 secret below /v1/ such as "secret/data/daap/blueprints",
+// from the Vault server at addr.
+func NewVaultStore(addr, token, path string, timeout time.Duration) *VaultStore {
+	return &VaultStore{
+		url:    strings.TrimRight(addr, "/") + "/v1/" + strings.TrimLeft(path, "/"),
+		token:  token,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Lookup returns the value of the secret's key name. Non-string values are
+// returned as JSON.
+func (s *VaultStore) Lookup(ctx context.Context, name string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return "", fmt.Errorf("building vault request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("reading vault secret: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("reading vault secret: unexpected status %d", resp.StatusCode)
+	}
+
+	var body struct {
+		Data struct {
+			Data map[string]json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("decoding vault secret: %w", err)
+	}
+	raw, ok := body.Data.Data[name]
+	if !ok {
+		return "", ErrNotFound
+	}
+	var v string
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw), nil
+	}
+	return v, nil
+}
@@ -0,0 +1,21 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// GetArchived retrieves a soft-deleted database with an archive URL.
+func (r *DatabaseRepository) GetArchived(_ context.Context, id uuid.UUID) (*database.Database, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt == nil || d.ArchiveURL == nil {
+		return nil, database.ErrNotFound
+	}
+	return r.withJoins(d), nil
+}
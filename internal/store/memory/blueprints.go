@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/blueprint"
+)
+
+// BlueprintRepository implements blueprint.Repository in memory.
+type BlueprintRepository struct {
+	db *DB
+}
+
+// Create inserts a new blueprint record and its first version.
+func (r *BlueprintRepository) Create(_ context.Context, bp *blueprint.Blueprint) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.blueprints {
+		if existing.Name == bp.Name {
+			return blueprint.ErrDuplicateBlueprintName
+		}
+	}
+	if bp.TeamID != nil && bp.OrganizationID != nil {
+		return fmt.Errorf("inserting blueprint: blueprints cannot be scoped to both a team and an organization")
+	}
+	if bp.TeamID != nil {
+		if _, ok := r.db.teams[*bp.TeamID]; !ok {
+			return fmt.Errorf("inserting blueprint: team %s does not exist", bp.TeamID)
+		}
+	}
+	if bp.OrganizationID != nil {
+		if _, ok := r.db.organizations[*bp.OrganizationID]; !ok {
+			return fmt.Errorf("inserting blueprint: organization %s does not exist", bp.OrganizationID)
+		}
+	}
+
+	bp.ID = r.db.nextID()
+	bp.Documents = blueprint.ParseDocuments(bp.Manifests)
+	bp.Version = 1
+	bp.CreatedAt = now()
+	bp.UpdatedAt = bp.CreatedAt
+	bp.UpdatedBy = bp.CreatedBy
+
+	stored := *bp
+	r.db.blueprints[bp.ID] = &stored
+	r.addVersion(&stored)
+	return nil
+}
+
+// addVersion records bp's current manifests as its current version. The
+// caller must hold the write lock.
+func (r *BlueprintRepository) addVersion(bp *blueprint.Blueprint) {
+	r.db.blueprintVersions[bp.ID] = append(r.db.blueprintVersions[bp.ID], blueprint.Version{
+		BlueprintID: bp.ID,
+		Version:     bp.Version,
+		Manifests:   bp.Manifests,
+		CreatedAt:   bp.UpdatedAt,
+	})
+}
+
+// Update applies the non-nil fields. When the manifests change, the version
+// is incremented and the new manifests recorded; a new signature alone does
+// not make a new version.
+func (r *BlueprintRepository) Update(_ context.Context, id uuid.UUID, fields blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	bp, ok := r.db.blueprints[id]
+	if !ok {
+		return nil, blueprint.ErrBlueprintNotFound
+	}
+
+	changed := false
+	if fields.Description != nil && *fields.Description != bp.Description {
+		bp.Description = *fields.Description
+		changed = true
+	}
+	if fields.Signature != nil && *fields.Signature != bp.Signature {
+		bp.Signature = *fields.Signature
+		changed = true
+	}
+	manifestsChanged := fields.Manifests != nil && *fields.Manifests != bp.Manifests
+	if manifestsChanged {
+		bp.Manifests = *fields.Manifests
+		bp.Documents = blueprint.ParseDocuments(bp.Manifests)
+		bp.Version++
+		changed = true
+	}
+	if changed {
+		bp.UpdatedAt = now()
+		if fields.UpdatedBy != "" {
+			bp.UpdatedBy = fields.UpdatedBy
+		}
+	}
+	if manifestsChanged {
+		r.addVersion(bp)
+	}
+
+	out := *bp
+	return &out, nil
+}
+
+// ListVersions returns the versions of a blueprint, newest first.
+func (r *BlueprintRepository) ListVersions(_ context.Context, id uuid.UUID) ([]blueprint.Version, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	if _, ok := r.db.blueprints[id]; !ok {
+		return nil, blueprint.ErrBlueprintNotFound
+	}
+	stored := r.db.blueprintVersions[id]
+	versions := make([]blueprint.Version, len(stored))
+	for i, v := range stored {
+		versions[len(stored)-1-i] = v
+	}
+	return versions, nil
+}
+
+// GetByID retrieves a single blueprint by its UUID.
+func (r *BlueprintRepository) GetByID(_ context.Context, id uuid.UUID) (*blueprint.Blueprint, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	bp, ok := r.db.blueprints[id]
+	if !ok {
+		return nil, blueprint.ErrBlueprintNotFound
+	}
+	out := *bp
+	return &out, nil
+}
+
+// GetByName retrieves a single blueprint by its name.
+func (r *BlueprintRepository) GetByName(_ context.Context, name string) (*blueprint.Blueprint, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, bp := range r.db.blueprints {
+		if bp.Name == name {
+			out := *bp
+			return &out, nil
+		}
+	}
+	return nil, blueprint.ErrBlueprintNotFound
+}
+
+// List retrieves all blueprints ordered by creation time.
+func (r *BlueprintRepository) List(_ context.Context) ([]blueprint.Blueprint, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	blueprints := make([]blueprint.Blueprint, 0, len(r.db.blueprints))
+	for _, bp := range r.db.blueprints {
+		blueprints = append(blueprints, *bp)
+	}
+	sort.Slice(blueprints, func(i, j int) bool {
+		return r.db.order[blueprints[i].ID] < r.db.order[blueprints[j].ID]
+	})
+	return blueprints, nil
+}
+
+// Delete removes a blueprint by its UUID. Returns ErrBlueprintHasTiers if the
+// blueprint is referenced by any tier.
+func (r *BlueprintRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.blueprints[id]; !ok {
+		return blueprint.ErrBlueprintNotFound
+	}
+	for _, t := range r.db.tiers {
+		if t.BlueprintID != nil && *t.BlueprintID == id {
+			return blueprint.ErrBlueprintHasTiers
+		}
+	}
+
+	delete(r.db.blueprints, id)
+	delete(r.db.blueprintVersions, id)
+	delete(r.db.order, id)
+	return nil
+}
@@ -0,0 +1,523 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/revision"
+)
+
+// DatabaseRepository implements database.Repository in memory.
+type DatabaseRepository struct {
+	db *DB
+}
+
+// Create inserts a new database record. Like the Postgres implementation it
+// derives cluster_name and pooler_name and defaults status to "provisioning".
+func (r *DatabaseRepository) Create(_ context.Context, d *database.Database) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d.ClusterName = database.ClusterName(d.Name)
+	d.PoolerName = database.PoolerName(d.Name)
+	if d.Status == "" {
+		d.Status = "provisioning"
+	}
+	if d.DataClassification == "" {
+		d.DataClassification = database.DefaultClassification
+	}
+	if d.Exposure.Type == "" {
+		d.Exposure.Type = database.ExposureCluster
+	}
+	if d.Exposure.AllowedSourceRanges == nil {
+		d.Exposure.AllowedSourceRanges = []string{}
+	}
+
+	for _, existing := range r.db.databases {
+		if existing.DeletedAt == nil && (existing.Name == d.Name || existing.Rename != nil && existing.Rename.To == d.Name) {
+			return database.ErrDuplicateName
+		}
+	}
+	owner, ok := r.db.teams[d.OwnerTeamID]
+	if !ok {
+		return database.ErrInvalidOwnerTeam
+	}
+	if d.TierID != nil {
+		if _, ok := r.db.tiers[*d.TierID]; !ok {
+			return database.ErrInvalidTier
+		}
+	}
+
+	d.ID = r.db.nextID()
+	d.Generation = 1
+	d.ObservedGeneration = 0
+	d.CreatedAt = now()
+	d.UpdatedAt = d.CreatedAt
+	d.UpdatedBy = d.CreatedBy
+	d.OwnerTeamLabels = copyMap(owner.Labels)
+	d.OwnerTeamAnnotations = copyMap(owner.Annotations)
+
+	stored := *d
+	stored.Images = slices.Clone(d.Images)
+	stored.Exposure.AllowedSourceRanges = slices.Clone(d.Exposure.AllowedSourceRanges)
+	r.db.databases[d.ID] = &stored
+	r.db.recordStatus(d.ID, "", d.Status, d.CreatedAt)
+	r.db.recordDatabaseRevision(&stored, revision.OperationCreate, d.CreatedAt)
+	return nil
+}
+
+// GetByID retrieves a single non-deleted database by its UUID.
+func (r *DatabaseRepository) GetByID(_ context.Context, id uuid.UUID) (*database.Database, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil {
+		return nil, database.ErrNotFound
+	}
+	return r.withJoins(d), nil
+}
+
+// GetByName retrieves the non-deleted database with exactly this name.
+func (r *DatabaseRepository) GetByName(_ context.Context, name string) (*database.Database, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, d := range r.db.databases {
+		if d.DeletedAt == nil && d.Name == name {
+			return r.withJoins(d), nil
+		}
+	}
+	return nil, database.ErrNotFound
+}
+
+// List retrieves a paginated, filtered list of non-deleted databases,
+// newest first.
+func (r *DatabaseRepository) List(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var matched []*database.Database
+	for _, d := range r.db.databases {
+		if d.DeletedAt != nil {
+			continue
+		}
+		if filter.OwnerTeamID != nil && d.OwnerTeamID != *filter.OwnerTeamID {
+			continue
+		}
+		if filter.TierID != nil && (d.TierID == nil || *d.TierID != *filter.TierID) {
+			continue
+		}
+		if filter.Status != nil && d.Status != *filter.Status {
+			continue
+		}
+		if filter.Name != nil && !strings.Contains(strings.ToLower(d.Name), strings.ToLower(*filter.Name)) {
+			continue
+		}
+		if filter.Environment != nil && d.Environment != *filter.Environment {
+			continue
+		}
+		if filter.Namespace != nil && d.Namespace != *filter.Namespace {
+			continue
+		}
+		if filter.PromotedFromID != nil && (d.PromotedFromID == nil || *d.PromotedFromID != *filter.PromotedFromID) {
+			continue
+		}
+		matched = append(matched, d)
+	}
+
+	sort.Slice(matched, func(i, j int) bool {
+		return r.db.order[matched[i].ID] > r.db.order[matched[j].ID]
+	})
+
+	databases := []database.Database{}
+	offset := (filter.Page - 1) * filter.Limit
+	for i := offset; i < len(matched) && i < offset+filter.Limit; i++ {
+		databases = append(databases, *r.withJoins(matched[i]))
+	}
+
+	return &database.ListResult{
+		Databases: databases,
+		Total:     len(matched),
+		Page:      filter.Page,
+		Limit:     filter.Limit,
+	}, nil
+}
+
+// Update modifies user-updatable fields (owner_team_id, purpose) on a non-deleted database.
+// Changing the owner team increments generation.
+func (r *DatabaseRepository) Update(_ context.Context, id uuid.UUID, fields database.UpdateFields) (*database.Database, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil {
+		return nil, database.ErrNotFound
+	}
+
+	if fields.OwnerTeamID == nil && fields.TierID == nil && fields.Purpose == nil && fields.DataClassification == nil &&
+		fields.Images == nil && fields.QueryInsights == nil && fields.ArchiveURL == nil && fields.LegalHold == nil && fields.CredentialsRotatedAt == nil && fields.ReconciliationPause == nil && !fields.ResumeReconciliation {
+		return r.withJoins(d), nil
+	}
+
+	if fields.OwnerTeamID != nil {
+		if _, ok := r.db.teams[*fields.OwnerTeamID]; !ok {
+			return nil, database.ErrInvalidOwnerTeam
+		}
+	}
+	if fields.TierID != nil {
+		if _, ok := r.db.tiers[*fields.TierID]; !ok {
+			return nil, database.ErrInvalidTier
+		}
+	}
+
+	// Spec-affecting changes bump the generation once per update.
+	specChanged := false
+	if fields.OwnerTeamID != nil {
+		specChanged = specChanged || d.OwnerTeamID != *fields.OwnerTeamID
+		d.OwnerTeamID = *fields.OwnerTeamID
+	}
+	if fields.TierID != nil {
+		specChanged = specChanged || d.TierID == nil || *d.TierID != *fields.TierID
+		id := *fields.TierID
+		d.TierID = &id
+	}
+	if specChanged {
+		d.Generation++
+	}
+	if fields.Purpose != nil {
+		d.Purpose = *fields.Purpose
+	}
+	if fields.DataClassification != nil {
+		d.DataClassification = *fields.DataClassification
+	}
+	if fields.Images != nil {
+		d.Images = slices.Clone(fields.Images)
+	}
+	if fields.QueryInsights != nil {
+		d.QueryInsights = *fields.QueryInsights
+	}
+	if fields.ArchiveURL != nil {
+		url := *fields.ArchiveURL
+		d.ArchiveURL = &url
+	}
+	if fields.LegalHold != nil {
+		d.LegalHold = *fields.LegalHold
+	}
+	if fields.CredentialsRotatedAt != nil {
+		at := fields.CredentialsRotatedAt.Truncate(time.Microsecond)
+		d.CredentialsRotatedAt = &at
+	}
+	if fields.ReconciliationPause != nil {
+		pause := *fields.ReconciliationPause
+		d.ReconciliationPause = &pause
+	} else if fields.ResumeReconciliation {
+		d.ReconciliationPause = nil
+	}
+	if fields.UpdatedBy != "" {
+		d.UpdatedBy = fields.UpdatedBy
+	}
+	d.UpdatedAt = now()
+	r.db.recordDatabaseRevision(d, revision.OperationUpdate, d.UpdatedAt)
+
+	return r.withJoins(d), nil
+}
+
+// UpdateStatus updates the status and connection details of a database record.
+func (r *DatabaseRepository) UpdateStatus(_ context.Context, id uuid.UUID, su database.StatusUpdate) (*database.Database, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil || (su.IfStatus != "" && d.Status != su.IfStatus) {
+		return nil, database.ErrNotFound
+	}
+
+	changedAt := now()
+	r.db.recordStatus(id, d.Status, su.Status, changedAt)
+	if d.Status != su.Status {
+		d.Ack = nil
+	}
+	d.Status = su.Status
+	d.StatusReason = nil
+	if su.Reason != "" {
+		reason := su.Reason
+		d.StatusReason = &reason
+	}
+	d.StatusMessage = nil
+	if su.Message != "" {
+		message := su.Message
+		d.StatusMessage = &message
+	}
+	if su.Host != nil {
+		host := *su.Host
+		d.Host = &host
+	}
+	if su.Port != nil {
+		port := *su.Port
+		d.Port = &port
+	}
+	if su.SecretName != nil {
+		secretName := *su.SecretName
+		d.SecretName = &secretName
+	}
+	if su.ObservedGeneration != nil {
+		d.ObservedGeneration = *su.ObservedGeneration
+	}
+	if su.Instances != nil {
+		d.Instances = copyInstances(su.Instances)
+	}
+	if su.OperatorVersion != nil {
+		d.OperatorVersion = *su.OperatorVersion
+	}
+	if su.ExternalHost != nil {
+		d.ExternalHost = nil
+		if *su.ExternalHost != "" {
+			host := *su.ExternalHost
+			d.ExternalHost = &host
+		}
+	}
+	if su.Conditions != nil {
+		d.Conditions = append([]database.Condition{}, su.Conditions...)
+	}
+	if su.UpdatedBy != "" {
+		d.UpdatedBy = su.UpdatedBy
+	}
+	d.UpdatedAt = changedAt
+	r.db.recordDatabaseRevision(d, revision.OperationUpdate, changedAt)
+
+	return r.withJoins(d), nil
+}
+
+// Acknowledge records an acknowledgement of the database's current status,
+// or clears it when ack is nil.
+func (r *DatabaseRepository) Acknowledge(_ context.Context, id uuid.UUID, ack *database.Acknowledgement) (*database.Database, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil {
+		return nil, database.ErrNotFound
+	}
+
+	d.Ack = nil
+	if ack != nil {
+		stored := *ack
+		stored.At = stored.At.UTC().Truncate(time.Microsecond)
+		d.Ack = &stored
+	}
+	d.UpdatedAt = now()
+	r.db.recordDatabaseRevision(d, revision.OperationUpdate, d.UpdatedAt)
+	return r.withJoins(d), nil
+}
+
+// SoftDelete marks a database as deleted by setting deleted_at and status to 'deleted'.
+func (r *DatabaseRepository) SoftDelete(_ context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil {
+		return database.ErrNotFound
+	}
+
+	deletedAt := now()
+	r.db.recordStatus(id, d.Status, "deleted", deletedAt)
+	d.DeletedAt = &deletedAt
+	d.Status = "deleted"
+	d.UpdatedAt = deletedAt
+	r.db.recordDatabaseRevision(d, revision.OperationDelete, deletedAt)
+	return nil
+}
+
+// Stats returns counts of non-deleted databases by status, tier and team, and
+// the p50/p95 time from creation to first becoming ready.
+func (r *DatabaseRepository) Stats(_ context.Context, filter database.StatsFilter) (*database.Stats, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	stats := &database.Stats{
+		ByStatus: map[string]int{},
+		ByTier:   map[string]int{},
+		ByTeam:   map[string]int{},
+	}
+	for _, d := range r.db.databases {
+		if d.DeletedAt != nil {
+			continue
+		}
+		if filter.OwnerTeamID != nil && d.OwnerTeamID != *filter.OwnerTeamID {
+			continue
+		}
+		joined := r.withJoins(d)
+		stats.Total++
+		stats.ByStatus[d.Status]++
+		if joined.TierName != "" {
+			stats.ByTier[joined.TierName]++
+		}
+		stats.ByTeam[joined.OwnerTeamName]++
+	}
+
+	var durations []time.Duration
+	for _, pd := range r.provisioningDurations(filter.OwnerTeamID) {
+		durations = append(durations, pd.Duration)
+	}
+	stats.Provisioning = database.NewDurationStats(durations)
+
+	return stats, nil
+}
+
+// ProvisioningDurations returns the time from creation to first becoming
+// ready for every database that has reached ready, most recently ready first.
+func (r *DatabaseRepository) ProvisioningDurations(_ context.Context, filter database.ProvisioningDurationFilter) (*database.ProvisioningDurationResult, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 {
+		filter.Limit = 20
+	}
+	if filter.Limit > 100 {
+		filter.Limit = 100
+	}
+
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	all := r.provisioningDurations(filter.OwnerTeamID)
+	sort.Slice(all, func(i, j int) bool { return all[i].ReadyAt.After(all[j].ReadyAt) })
+
+	items := []database.ProvisioningDuration{}
+	offset := (filter.Page - 1) * filter.Limit
+	for i := offset; i < len(all) && i < offset+filter.Limit; i++ {
+		items = append(items, all[i])
+	}
+
+	return &database.ProvisioningDurationResult{
+		Items: items,
+		Total: len(all),
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// StatusHistory returns the status changes of a database, oldest first.
+func (r *DatabaseRepository) StatusHistory(_ context.Context, databaseID uuid.UUID) ([]database.StatusChange, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	changes := []database.StatusChange{}
+	for _, c := range r.db.statusHistory {
+		if c.DatabaseID == databaseID {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// provisioningDurations derives per-database provisioning durations from the
+// status history. Callers must hold at least the read lock.
+func (r *DatabaseRepository) provisioningDurations(ownerTeamID *uuid.UUID) []database.ProvisioningDuration {
+	readyAt := make(map[uuid.UUID]time.Time)
+	for _, c := range r.db.statusHistory {
+		if c.ToStatus != "ready" {
+			continue
+		}
+		if at, ok := readyAt[c.DatabaseID]; !ok || c.ChangedAt.Before(at) {
+			readyAt[c.DatabaseID] = c.ChangedAt
+		}
+	}
+
+	var out []database.ProvisioningDuration
+	for id, at := range readyAt {
+		d, ok := r.db.databases[id]
+		if !ok {
+			continue
+		}
+		if ownerTeamID != nil && d.OwnerTeamID != *ownerTeamID {
+			continue
+		}
+		joined := r.withJoins(d)
+		out = append(out, database.ProvisioningDuration{
+			DatabaseID:    id,
+			Name:          d.Name,
+			OwnerTeamName: joined.OwnerTeamName,
+			TierName:      joined.TierName,
+			CreatedAt:     d.CreatedAt,
+			ReadyAt:       at,
+			Duration:      at.Sub(d.CreatedAt),
+		})
+	}
+	return out
+}
+
+// withJoins returns a copy of d with the transient owner team and tier
+// names and the owner team's labels and annotations populated, mirroring the LEFT JOINs of the Postgres queries.
+// Callers must hold at least the read lock.
+func (r *DatabaseRepository) withJoins(d *database.Database) *database.Database {
+	out := *d
+	if d.Ack != nil {
+		ack := *d.Ack
+		out.Ack = &ack
+	}
+	if d.Instances != nil {
+		out.Instances = copyInstances(d.Instances)
+	}
+	if d.Conditions != nil {
+		out.Conditions = append([]database.Condition{}, d.Conditions...)
+	}
+	out.Images = slices.Clone(d.Images)
+	if d.Rename != nil {
+		rename := *d.Rename
+		out.Rename = &rename
+	}
+	out.Exposure.AllowedSourceRanges = slices.Clone(d.Exposure.AllowedSourceRanges)
+	out.OwnerTeamName = ""
+	out.OwnerTeamLabels = map[string]string{}
+	out.OwnerTeamAnnotations = map[string]string{}
+	out.TierName = ""
+	if t, ok := r.db.teams[d.OwnerTeamID]; ok {
+		out.OwnerTeamName = t.Name
+		out.OwnerTeamLabels = copyMap(t.Labels)
+		out.OwnerTeamAnnotations = copyMap(t.Annotations)
+	}
+	if d.TierID != nil {
+		if t, ok := r.db.tiers[*d.TierID]; ok {
+			out.TierName = t.Name
+		}
+	}
+	return &out
+}
+
+func copyInstances(in *database.Instances) *database.Instances {
+	out := *in
+	if in.ReplicationLag != nil {
+		lag := *in.ReplicationLag
+		out.ReplicationLag = &lag
+	}
+	return &out
+}
+
+// copyMap returns a copy of m that is never nil, like the {} the Postgres
+// JSONB columns default to.
+func copyMap(m map[string]string) map[string]string {
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// DependentRepository implements database.DependentRepository in memory.
+type DependentRepository struct {
+	db *DB
+}
+
+// Create inserts a dependent. Like the constraints in Postgres, the database
+// must exist and a service may be declared once per database.
+func (r *DependentRepository) Create(_ context.Context, d *database.Dependent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[d.DatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	for _, existing := range r.db.dependents {
+		if existing.DatabaseID == d.DatabaseID && existing.Service == d.Service {
+			return database.ErrDuplicateDependent
+		}
+	}
+
+	d.ID = r.db.nextID()
+	d.CreatedAt = now()
+	stored := *d
+	r.db.dependents[d.ID] = &stored
+	return nil
+}
+
+// ListByDatabase returns the dependents of a database ordered by service.
+func (r *DependentRepository) ListByDatabase(_ context.Context, databaseID uuid.UUID) ([]database.Dependent, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	dependents := []database.Dependent{}
+	for _, d := range r.db.dependents {
+		if d.DatabaseID == databaseID {
+			dependents = append(dependents, *d)
+		}
+	}
+	sort.Slice(dependents, func(i, j int) bool { return dependents[i].Service < dependents[j].Service })
+	return dependents, nil
+}
+
+// Delete removes a dependent of the given database.
+func (r *DependentRepository) Delete(_ context.Context, databaseID, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.dependents[id]
+	if !ok || d.DatabaseID != databaseID {
+		return database.ErrDependentNotFound
+	}
+	delete(r.db.dependents, id)
+	return nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/freeze"
+)
+
+// FreezeRepository implements freeze.Repository in memory.
+type FreezeRepository struct {
+	db *DB
+}
+
+// Create inserts a new freeze window. Like the foreign key in Postgres, the
+// team must exist.
+func (r *FreezeRepository) Create(_ context.Context, w *freeze.Window) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if w.TeamID != nil {
+		if _, ok := r.db.teams[*w.TeamID]; !ok {
+			return fmt.Errorf("inserting freeze window: team %s does not exist", w.TeamID)
+		}
+	}
+	if !w.EndsAt.After(w.StartsAt) {
+		return fmt.Errorf("inserting freeze window: ends_at must be after starts_at")
+	}
+
+	w.ID = r.db.nextID()
+	w.CreatedAt = now()
+	stored := *w
+	stored.TeamName = nil
+	r.db.freezes[w.ID] = &stored
+	*w = *r.withTeam(&stored)
+	return nil
+}
+
+// GetByID retrieves a single freeze window by its UUID.
+func (r *FreezeRepository) GetByID(_ context.Context, id uuid.UUID) (*freeze.Window, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	w, ok := r.db.freezes[id]
+	if !ok {
+		return nil, freeze.ErrWindowNotFound
+	}
+	return r.withTeam(w), nil
+}
+
+// List returns all freeze windows ordered by start time.
+func (r *FreezeRepository) List(_ context.Context) ([]freeze.Window, error) {
+	return r.list(func(*freeze.Window) bool { return true }), nil
+}
+
+// ListActive returns the freeze windows that cover at.
+func (r *FreezeRepository) ListActive(_ context.Context, at time.Time) ([]freeze.Window, error) {
+	return r.list(func(w *freeze.Window) bool { return w.Covers(at) }), nil
+}
+
+func (r *FreezeRepository) list(keep func(*freeze.Window) bool) []freeze.Window {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	windows := []freeze.Window{}
+	for _, w := range r.db.freezes {
+		if keep(w) {
+			windows = append(windows, *r.withTeam(w))
+		}
+	}
+	sort.Slice(windows, func(i, j int) bool {
+		if !windows[i].StartsAt.Equal(windows[j].StartsAt) {
+			return windows[i].StartsAt.Before(windows[j].StartsAt)
+		}
+		return r.db.order[windows[i].ID] < r.db.order[windows[j].ID]
+	})
+	return windows
+}
+
+// Delete removes a freeze window.
+func (r *FreezeRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.freezes[id]; !ok {
+		return freeze.ErrWindowNotFound
+	}
+	delete(r.db.freezes, id)
+	delete(r.db.order, id)
+	return nil
+}
+
+// withTeam returns a copy of w with the team name joined in. Callers must
+// hold the lock.
+func (r *FreezeRepository) withTeam(w *freeze.Window) *freeze.Window {
+	out := *w
+	if w.TeamID != nil {
+		if t, ok := r.db.teams[*w.TeamID]; ok {
+			name := t.Name
+			out.TeamName = &name
+		}
+	}
+	return &out
+}
@@ -0,0 +1,165 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/revision"
+)
+
+// HistoryRepository implements database.HistoryRepository in memory. The
+// history tables are not partitioned in memory: a partition stands for the
+// rows of its month, and every month a row was written to has one.
+type HistoryRepository struct {
+	db *DB
+}
+
+// EnsurePartition registers the partition of the month.
+func (r *HistoryRepository) EnsurePartition(_ context.Context, table string, month time.Time) (bool, error) {
+	if _, ok := database.HistoryTables[table]; !ok {
+		return false, fmt.Errorf("%s is not a history table", table)
+	}
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	p := historyPartition(table, month)
+	if r.db.historyPartitions[p] || slices.Contains(r.db.historyMonths(table), p.Month) {
+		return false, nil
+	}
+	r.db.historyPartitions[p] = true
+	return true, nil
+}
+
+// DefaultMonths returns nothing: there is no default partition in memory.
+func (r *HistoryRepository) DefaultMonths(_ context.Context, table string) ([]time.Time, error) {
+	if _, ok := database.HistoryTables[table]; !ok {
+		return nil, fmt.Errorf("%s is not a history table", table)
+	}
+	return nil, nil
+}
+
+// ListPartitions lists the registered partitions and those of the months
+// rows were written to.
+func (r *HistoryRepository) ListPartitions(_ context.Context) ([]database.HistoryPartition, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	seen := make(map[database.HistoryPartition]bool)
+	for p := range r.db.historyPartitions {
+		seen[p] = true
+	}
+	for table := range database.HistoryTables {
+		for _, month := range r.db.historyMonths(table) {
+			seen[historyPartition(table, month)] = true
+		}
+	}
+	partitions := make([]database.HistoryPartition, 0, len(seen))
+	for p := range seen {
+		partitions = append(partitions, p)
+	}
+	slices.SortFunc(partitions, func(a, b database.HistoryPartition) int {
+		if c := a.Month.Compare(b.Month); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Table, b.Table)
+	})
+	return partitions, nil
+}
+
+// DropPartition removes the rows of the partition's month.
+func (r *HistoryRepository) DropPartition(_ context.Context, p database.HistoryPartition) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	inMonth := func(t time.Time) bool { return database.HistoryMonth(t).Equal(p.Month) }
+	switch p.Table {
+	case database.HistoryStatus:
+		r.db.statusHistory = slices.DeleteFunc(r.db.statusHistory, func(c database.StatusChange) bool { return inMonth(c.ChangedAt) })
+	case database.HistoryResize:
+		r.db.resizeEvents = slices.DeleteFunc(r.db.resizeEvents, func(e database.ResizeEvent) bool { return inMonth(e.CreatedAt) })
+	case database.HistoryTierChanges:
+		r.db.tierChanges = slices.DeleteFunc(r.db.tierChanges, func(c database.TierChange) bool { return inMonth(c.CreatedAt) })
+	case database.HistoryPromotions:
+		r.db.promotions = slices.DeleteFunc(r.db.promotions, func(p database.Promotion) bool { return inMonth(p.CreatedAt) })
+	case database.HistoryUsageSamples:
+		r.db.usageSamples = slices.DeleteFunc(r.db.usageSamples, func(s database.UsageSample) bool { return inMonth(s.CollectedAt) })
+	case database.HistoryQuerySnapshots:
+		r.db.querySnapshots = slices.DeleteFunc(r.db.querySnapshots, func(s database.QuerySnapshot) bool { return inMonth(s.CollectedAt) })
+	case database.HistoryOperations:
+		maps.DeleteFunc(r.db.operations, func(_ uuid.UUID, op *operation.Operation) bool { return inMonth(op.CreatedAt) })
+	case database.HistoryRevisions:
+		for key, history := range r.db.revisions {
+			r.db.revisions[key] = slices.DeleteFunc(history, func(rev revision.Revision) bool { return inMonth(rev.ChangedAt) })
+		}
+	}
+	delete(r.db.historyPartitions, historyPartition(p.Table, p.Month))
+	return nil
+}
+
+// ArchivePartition drops the partition: nothing outlives the in-memory
+// backend, so there is nowhere to archive it to.
+func (r *HistoryRepository) ArchivePartition(ctx context.Context, p database.HistoryPartition) error {
+	return r.DropPartition(ctx, p)
+}
+
+// historyMonths returns the months rows of table were written to. The caller
+// must hold db.mu.
+func (db *DB) historyMonths(table string) []time.Time {
+	var times []time.Time
+	switch table {
+	case database.HistoryStatus:
+		for _, c := range db.statusHistory {
+			times = append(times, c.ChangedAt)
+		}
+	case database.HistoryResize:
+		for _, e := range db.resizeEvents {
+			times = append(times, e.CreatedAt)
+		}
+	case database.HistoryTierChanges:
+		for _, c := range db.tierChanges {
+			times = append(times, c.CreatedAt)
+		}
+	case database.HistoryPromotions:
+		for _, p := range db.promotions {
+			times = append(times, p.CreatedAt)
+		}
+	case database.HistoryUsageSamples:
+		for _, s := range db.usageSamples {
+			times = append(times, s.CollectedAt)
+		}
+	case database.HistoryQuerySnapshots:
+		for _, s := range db.querySnapshots {
+			times = append(times, s.CollectedAt)
+		}
+	case database.HistoryOperations:
+		for _, op := range db.operations {
+			times = append(times, op.CreatedAt)
+		}
+	case database.HistoryRevisions:
+		for _, history := range db.revisions {
+			for _, rev := range history {
+				times = append(times, rev.ChangedAt)
+			}
+		}
+	}
+	var months []time.Time
+	for _, t := range times {
+		if month := database.HistoryMonth(t); !slices.Contains(months, month) {
+			months = append(months, month)
+		}
+	}
+	return months
+}
+
+func historyPartition(table string, month time.Time) database.HistoryPartition {
+	month = database.HistoryMonth(month)
+	return database.HistoryPartition{Table: table, Name: database.HistoryPartitionName(table, month), Month: month}
+}
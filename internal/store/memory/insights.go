@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// QuerySnapshotRepository implements database.QuerySnapshotRepository in
+// memory.
+type QuerySnapshotRepository struct {
+	db *DB
+}
+
+// Record appends a snapshot and sets its ID and CollectedAt. Like the foreign
+// key in Postgres, the database must exist.
+func (r *QuerySnapshotRepository) Record(_ context.Context, s *database.QuerySnapshot) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[s.DatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	r.db.querySnapshotSeq++
+	s.ID = r.db.querySnapshotSeq
+	s.CollectedAt = now()
+	stored := *s
+	stored.Statements = slices.Clone(s.Statements)
+	if stored.Statements == nil {
+		stored.Statements = []database.QueryStat{}
+	}
+	r.db.querySnapshots = append(r.db.querySnapshots, stored)
+	return nil
+}
+
+// Latest returns a database's most recent snapshot.
+func (r *QuerySnapshotRepository) Latest(_ context.Context, databaseID uuid.UUID) (*database.QuerySnapshot, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for i := len(r.db.querySnapshots) - 1; i >= 0; i-- {
+		if s := r.db.querySnapshots[i]; s.DatabaseID == databaseID {
+			s.Statements = slices.Clone(s.Statements)
+			return &s, nil
+		}
+	}
+	return nil, database.ErrQuerySnapshotNotFound
+}
+
+// DeleteBefore removes snapshots collected before the given time.
+func (r *QuerySnapshotRepository) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	kept := r.db.querySnapshots[:0]
+	for _, s := range r.db.querySnapshots {
+		if !s.CollectedAt.Before(before) {
+			kept = append(kept, s)
+		}
+	}
+	deleted := int64(len(r.db.querySnapshots) - len(kept))
+	r.db.querySnapshots = kept
+	return deleted, nil
+}
@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/auth"
+)
+
+// InvitationRepository implements auth.InvitationRepository in memory.
+type InvitationRepository struct {
+	db *DB
+}
+
+// Create inserts an invitation. Like the constraints in Postgres, the team
+// must exist and token hashes are unique.
+func (r *InvitationRepository) Create(_ context.Context, inv *auth.Invitation) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.teams[inv.TeamID]; !ok {
+		return fmt.Errorf("inserting invitation: team %s does not exist", inv.TeamID)
+	}
+	for _, existing := range r.db.invitations {
+		if existing.TokenHash == inv.TokenHash {
+			return fmt.Errorf("inserting invitation: duplicate token hash")
+		}
+	}
+
+	inv.ID = r.db.nextID()
+	inv.CreatedAt = now()
+	stored := *inv
+	r.db.invitations[inv.ID] = &stored
+	return nil
+}
+
+// GetByTokenHash retrieves the invitation whose token hashes to tokenHash.
+func (r *InvitationRepository) GetByTokenHash(_ context.Context, tokenHash string) (*auth.Invitation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, inv := range r.db.invitations {
+		if inv.TokenHash == tokenHash {
+			out := *inv
+			return &out, nil
+		}
+	}
+	return nil, auth.ErrInvitationNotFound
+}
+
+// Accept inserts the user and records the acceptance under one lock.
+func (r *InvitationRepository) Accept(_ context.Context, id uuid.UUID, u *auth.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	inv, ok := r.db.invitations[id]
+	if !ok {
+		return auth.ErrInvitationNotFound
+	}
+	if inv.AcceptedAt != nil {
+		return auth.ErrInvitationUsed
+	}
+	acceptedAt := now()
+	if inv.Expired(acceptedAt) {
+		return auth.ErrInvitationExpired
+	}
+
+	if err := r.db.insertUser(u); err != nil {
+		return err
+	}
+	userID := u.ID
+	inv.AcceptedAt = &acceptedAt
+	inv.UserID = &userID
+	return nil
+}
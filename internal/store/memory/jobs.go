@@ -0,0 +1,154 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/jobs"
+)
+
+// JobRepository implements jobs.Repository in memory.
+type JobRepository struct {
+	db *DB
+}
+
+// Enqueue inserts a queued job. Like the foreign key in Postgres, the
+// database must exist.
+func (r *JobRepository) Enqueue(_ context.Context, job *jobs.Job) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[job.DatabaseID]; !ok {
+		return fmt.Errorf("inserting job: database %s does not exist", job.DatabaseID)
+	}
+
+	job.ID = r.db.nextID()
+	job.Status = jobs.StatusQueued
+	if job.MaxAttempts <= 0 {
+		job.MaxAttempts = jobs.DefaultMaxAttempts
+	}
+	if job.Params == nil {
+		job.Params = map[string]string{}
+	}
+	job.CreatedAt = now()
+	job.UpdatedAt = job.CreatedAt
+	if job.RunAfter.IsZero() {
+		job.RunAfter = job.CreatedAt
+	}
+	job.Attempts, job.LastError, job.StartedAt, job.CompletedAt = 0, "", nil, nil
+	r.db.jobs[job.ID] = copyJob(job)
+	return nil
+}
+
+// Claim claims the job that has been due the longest.
+func (r *JobRepository) Claim(_ context.Context, at time.Time) (*jobs.Job, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var next *jobs.Job
+	for _, j := range r.db.jobs {
+		if j.Status != jobs.StatusQueued || j.RunAfter.After(at) {
+			continue
+		}
+		if next == nil || j.RunAfter.Before(next.RunAfter) ||
+			(j.RunAfter.Equal(next.RunAfter) && r.db.order[j.ID] < r.db.order[next.ID]) {
+			next = j
+		}
+	}
+	if next == nil {
+		return nil, jobs.ErrNoJob
+	}
+	t := now()
+	next.Status = jobs.StatusRunning
+	next.Attempts++
+	next.StartedAt = &t
+	next.UpdatedAt = t
+	return copyJob(next), nil
+}
+
+// Succeed completes a running job successfully.
+func (r *JobRepository) Succeed(_ context.Context, id uuid.UUID) error {
+	return r.finish(id, func(j *jobs.Job, t time.Time) {
+		j.Status = jobs.StatusSucceeded
+		j.CompletedAt = &t
+	})
+}
+
+// Retry requeues a running job after a failed attempt.
+func (r *JobRepository) Retry(_ context.Context, id uuid.UUID, lastErr string, runAfter time.Time) error {
+	return r.finish(id, func(j *jobs.Job, _ time.Time) {
+		j.Status = jobs.StatusQueued
+		j.LastError = lastErr
+		j.RunAfter = runAfter
+	})
+}
+
+// Fail completes a running job as failed.
+func (r *JobRepository) Fail(_ context.Context, id uuid.UUID, lastErr string) error {
+	return r.finish(id, func(j *jobs.Job, t time.Time) {
+		j.Status = jobs.StatusFailed
+		j.LastError = lastErr
+		j.CompletedAt = &t
+	})
+}
+
+func (r *JobRepository) finish(id uuid.UUID, apply func(j *jobs.Job, t time.Time)) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	j, ok := r.db.jobs[id]
+	if !ok || j.Status != jobs.StatusRunning {
+		return jobs.ErrJobNotRunning
+	}
+	t := now()
+	apply(j, t)
+	j.UpdatedAt = t
+	return nil
+}
+
+// Requeue moves jobs that started running before startedBefore back to
+// queued.
+func (r *JobRepository) Requeue(_ context.Context, startedBefore time.Time) (int, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	n := 0
+	t := now()
+	for _, j := range r.db.jobs {
+		if j.Status == jobs.StatusRunning && j.StartedAt != nil && j.StartedAt.Before(startedBefore) {
+			j.Status = jobs.StatusQueued
+			j.RunAfter = t
+			j.UpdatedAt = t
+			n++
+		}
+	}
+	return n, nil
+}
+
+// ListByDatabase returns the jobs on a database, newest first.
+func (r *JobRepository) ListByDatabase(_ context.Context, databaseID uuid.UUID) ([]jobs.Job, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	list := []jobs.Job{}
+	for _, j := range r.db.jobs {
+		if j.DatabaseID == databaseID {
+			list = append(list, *copyJob(j))
+		}
+	}
+	sort.Slice(list, func(i, k int) bool {
+		return r.db.order[list[i].ID] > r.db.order[list[k].ID]
+	})
+	return list, nil
+}
+
+func copyJob(j *jobs.Job) *jobs.Job {
+	c := *j
+	c.Params = maps.Clone(j.Params)
+	return &c
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// LockRepository implements database.LockRepository in memory.
+type LockRepository struct {
+	db *DB
+}
+
+// Acquire takes the lock on l.DatabaseID unless an unexpired lock is held.
+func (r *LockRepository) Acquire(_ context.Context, l *database.Lock, ttl time.Duration) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[l.DatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	t := now()
+	if held, ok := r.db.locks[l.DatabaseID]; ok && held.ExpiresAt.After(t) {
+		return &database.LockedError{Lock: *held}
+	}
+
+	l.ID = uuid.New()
+	l.AcquiredAt = t
+	l.ExpiresAt = t.Add(ttl)
+	l.RenewedAt = t
+	stored := *l
+	r.db.locks[l.DatabaseID] = &stored
+	return nil
+}
+
+// Release drops the lock if it is still the acquisition identified by lockID.
+func (r *LockRepository) Release(_ context.Context, databaseID, lockID uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if held, ok := r.db.locks[databaseID]; ok && held.ID == lockID {
+		delete(r.db.locks, databaseID)
+	}
+	return nil
+}
+
+// Renew sets RenewedAt on the locks identified by lockIDs.
+func (r *LockRepository) Renew(_ context.Context, lockIDs []uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	t := now()
+	for _, held := range r.db.locks {
+		if slices.Contains(lockIDs, held.ID) {
+			held.RenewedAt = t
+		}
+	}
+	return nil
+}
+
+// Get returns the unexpired lock on a database, or nil.
+func (r *LockRepository) Get(_ context.Context, databaseID uuid.UUID) (*database.Lock, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	held, ok := r.db.locks[databaseID]
+	if !ok || !held.ExpiresAt.After(now()) {
+		return nil, nil
+	}
+	l := *held
+	return &l, nil
+}
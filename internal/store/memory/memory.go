@@ -0,0 +1,294 @@
+// Package memory provides an embedded, in-process storage backend that
+// implements every platform repository without a PostgreSQL dependency.
+// It is intended for local development and demos: all data is lost when
+// the process exits.
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// DB holds all tables in memory behind a single lock, so cross-table
+// invariants (foreign keys, joins) are checked atomically.
+type DB struct {
+	mu sync.RWMutex
+
+	databases  map[uuid.UUID]*database.Database
+	teams      map[uuid.UUID]*team.Team
+	tiers      map[uuid.UUID]*tier.Tier
+	blueprints map[uuid.UUID]*blueprint.Blueprint
+	users      map[uuid.UUID]*auth.User
+
+	// organizations mirrors the organizations table.
+	organizations map[uuid.UUID]*organization.Organization
+
+	// blueprintVersions mirrors the blueprint_versions table, keyed by
+	// blueprint ID, oldest first.
+	blueprintVersions map[uuid.UUID][]blueprint.Version
+
+	// invitations mirrors the user_invitations table.
+	invitations map[uuid.UUID]*auth.Invitation
+
+	// statusHistory mirrors the database_status_history table.
+	statusHistory []database.StatusChange
+
+	// resizeEvents mirrors the database_resize_events table.
+	resizeEvents []database.ResizeEvent
+	resizeSeq    int64
+
+	// usageSamples and tierChanges mirror the database_usage_samples and
+	// database_tier_changes tables.
+	usageSamples  []database.UsageSample
+	tierChanges   []database.TierChange
+	tierChangeSeq int64
+
+	// historyPartitions records the partitions of the history tables
+	// created ahead of their first row.
+	historyPartitions map[database.HistoryPartition]bool
+
+	// querySnapshots mirrors the database_query_snapshots table.
+	querySnapshots   []database.QuerySnapshot
+	querySnapshotSeq int64
+
+	// promotions mirrors the database_promotions table.
+	promotions   []database.Promotion
+	promotionSeq int64
+
+	// dependents mirrors the database_dependents table.
+	dependents map[uuid.UUID]*database.Dependent
+
+	// specs mirrors the database_specs table, keyed by database ID.
+	specs map[uuid.UUID]*database.Spec
+
+	// locks mirrors the database_locks table, keyed by database ID.
+	locks map[uuid.UUID]*database.Lock
+
+	// operations mirrors the operations table.
+	operations map[uuid.UUID]*operation.Operation
+
+	// jobs mirrors the jobs table.
+	jobs map[uuid.UUID]*jobs.Job
+
+	// rollouts and rolloutTargets mirror the rollouts and rollout_targets
+	// tables; targets are keyed by rollout ID.
+	rollouts       map[uuid.UUID]*rollout.Rollout
+	rolloutTargets map[uuid.UUID][]rollout.Target
+
+	// freezes mirrors the change_freezes table.
+	freezes map[uuid.UUID]*freeze.Window
+
+	// revisions mirrors the revisions table, keyed by resource, oldest
+	// first.
+	revisions map[revisionKey][]revision.Revision
+	// revisionHeads mirrors the revision_heads table: the latest revision
+	// number of each resource.
+	revisionHeads map[revisionKey]int
+
+	// seq records insertion order so list queries are stable even when
+	// two rows share a created_at timestamp.
+	seq   int64
+	order map[uuid.UUID]int64
+}
+
+// New creates an empty in-memory database.
+func New() *DB {
+	return &DB{
+		databases:     make(map[uuid.UUID]*database.Database),
+		teams:         make(map[uuid.UUID]*team.Team),
+		tiers:         make(map[uuid.UUID]*tier.Tier),
+		blueprints:    make(map[uuid.UUID]*blueprint.Blueprint),
+		users:         make(map[uuid.UUID]*auth.User),
+		organizations: make(map[uuid.UUID]*organization.Organization),
+		invitations:   make(map[uuid.UUID]*auth.Invitation),
+		order:         make(map[uuid.UUID]int64),
+
+		rollouts:       make(map[uuid.UUID]*rollout.Rollout),
+		rolloutTargets: make(map[uuid.UUID][]rollout.Target),
+		freezes:        make(map[uuid.UUID]*freeze.Window),
+		dependents:     make(map[uuid.UUID]*database.Dependent),
+		specs:          make(map[uuid.UUID]*database.Spec),
+		locks:          make(map[uuid.UUID]*database.Lock),
+		operations:     make(map[uuid.UUID]*operation.Operation),
+		jobs:           make(map[uuid.UUID]*jobs.Job),
+		revisions:      make(map[revisionKey][]revision.Revision),
+		revisionHeads:  make(map[revisionKey]int),
+
+		blueprintVersions: make(map[uuid.UUID][]blueprint.Version),
+		historyPartitions: make(map[database.HistoryPartition]bool),
+	}
+}
+
+// Ping always succeeds; the embedded backend has no connection to lose.
+func (db *DB) Ping(_ context.Context) error {
+	return nil
+}
+
+// Databases returns a database.Repository backed by this DB.
+func (db *DB) Databases() database.Repository {
+	return &DatabaseRepository{db: db}
+}
+
+// Stats returns a database.StatsReader backed by this DB.
+func (db *DB) Stats() database.StatsReader {
+	return &DatabaseRepository{db: db}
+}
+
+// Purges returns a database.PurgeRepository backed by this DB.
+func (db *DB) Purges() database.PurgeRepository {
+	return &DatabaseRepository{db: db}
+}
+
+// Archives returns a database.ArchiveRepository backed by this DB.
+func (db *DB) Archives() database.ArchiveRepository {
+	return &DatabaseRepository{db: db}
+}
+
+// Renames returns a database.RenameRepository backed by this DB.
+func (db *DB) Renames() database.RenameRepository {
+	return &DatabaseRepository{db: db}
+}
+
+// History returns a database.HistoryRepository backed by this DB.
+func (db *DB) History() database.HistoryRepository {
+	return &HistoryRepository{db: db}
+}
+
+// ResizeEvents returns a database.ResizeEventRepository backed by this DB.
+func (db *DB) ResizeEvents() database.ResizeEventRepository {
+	return &ResizeEventRepository{db: db}
+}
+
+// UsageSamples returns a database.UsageSampleRepository backed by this DB.
+func (db *DB) UsageSamples() database.UsageSampleRepository {
+	return &UsageSampleRepository{db: db}
+}
+
+// QuerySnapshots returns a database.QuerySnapshotRepository backed by this DB.
+func (db *DB) QuerySnapshots() database.QuerySnapshotRepository {
+	return &QuerySnapshotRepository{db: db}
+}
+
+// TierChanges returns a database.TierChangeRepository backed by this DB.
+func (db *DB) TierChanges() database.TierChangeRepository {
+	return &TierChangeRepository{db: db}
+}
+
+// Promotions returns a database.PromotionRepository backed by this DB.
+func (db *DB) Promotions() database.PromotionRepository {
+	return &PromotionRepository{db: db}
+}
+
+// Dependents returns a database.DependentRepository backed by this DB.
+func (db *DB) Dependents() database.DependentRepository {
+	return &DependentRepository{db: db}
+}
+
+// Specs returns a database.SpecRepository backed by this DB.
+func (db *DB) Specs() database.SpecRepository {
+	return &SpecRepository{db: db}
+}
+
+// Locks returns a database.LockRepository backed by this DB.
+func (db *DB) Locks() database.LockRepository {
+	return &LockRepository{db: db}
+}
+
+// Operations returns an operation.Repository backed by this DB.
+func (db *DB) Operations() operation.Repository {
+	return &OperationRepository{db: db}
+}
+
+// Jobs returns a jobs.Repository backed by this DB.
+func (db *DB) Jobs() jobs.Repository {
+	return &JobRepository{db: db}
+}
+
+// Rollouts returns a rollout.Repository backed by this DB.
+func (db *DB) Rollouts() rollout.Repository {
+	return &RolloutRepository{db: db}
+}
+
+// Freezes returns a freeze.Repository backed by this DB.
+func (db *DB) Freezes() freeze.Repository {
+	return &FreezeRepository{db: db}
+}
+
+// Revisions returns a revision.Repository backed by this DB.
+func (db *DB) Revisions() revision.Repository {
+	return &RevisionRepository{db: db}
+}
+
+// Organizations returns an organization.Repository backed by this DB.
+func (db *DB) Organizations() organization.Repository {
+	return &OrganizationRepository{db: db}
+}
+
+// Teams returns a team.Repository backed by this DB.
+func (db *DB) Teams() team.Repository {
+	return &TeamRepository{db: db}
+}
+
+// Tiers returns a tier.Repository backed by this DB.
+func (db *DB) Tiers() tier.Repository {
+	return &TierRepository{db: db}
+}
+
+// Blueprints returns a blueprint.Repository backed by this DB.
+func (db *DB) Blueprints() blueprint.Repository {
+	return &BlueprintRepository{db: db}
+}
+
+// Users returns an auth.UserRepository backed by this DB.
+func (db *DB) Users() auth.UserRepository {
+	return &UserRepository{db: db}
+}
+
+// Invitations returns an auth.InvitationRepository backed by this DB.
+func (db *DB) Invitations() auth.InvitationRepository {
+	return &InvitationRepository{db: db}
+}
+
+// nextID allocates a new primary key and records its insertion order.
+// Callers must hold the write lock.
+func (db *DB) nextID() uuid.UUID {
+	id := uuid.New()
+	db.seq++
+	db.order[id] = db.seq
+	return id
+}
+
+// recordStatus appends a status transition to the history when the status
+// changes. Callers must hold the write lock.
+func (db *DB) recordStatus(id uuid.UUID, from, to string, at time.Time) {
+	if from == to {
+		return
+	}
+	db.statusHistory = append(db.statusHistory, database.StatusChange{
+		DatabaseID: id,
+		FromStatus: from,
+		ToStatus:   to,
+		ChangedAt:  at,
+	})
+}
+
+// now returns the current time truncated to microseconds, matching
+// PostgreSQL TIMESTAMPTZ precision.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Microsecond)
+}
@@ -0,0 +1,150 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/operation"
+)
+
+// OperationRepository implements operation.Repository in memory.
+type OperationRepository struct {
+	db *DB
+}
+
+// Create inserts a running operation. Like the foreign key in Postgres, the
+// database must exist.
+func (r *OperationRepository) Create(_ context.Context, op *operation.Operation) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[op.DatabaseID]; !ok {
+		return fmt.Errorf("inserting operation: database %s does not exist", op.DatabaseID)
+	}
+
+	op.ID = r.db.nextID()
+	op.Status = operation.StatusRunning
+	op.CreatedAt = now()
+	op.UpdatedAt = op.CreatedAt
+	op.RenewedAt = op.CreatedAt
+	op.Result, op.Error, op.CompletedAt = nil, nil, nil
+	stored := *op
+	r.db.operations[op.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a single operation by its UUID.
+func (r *OperationRepository) GetByID(_ context.Context, id uuid.UUID) (*operation.Operation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	op, ok := r.db.operations[id]
+	if !ok {
+		return nil, operation.ErrOperationNotFound
+	}
+	return copyOperation(op), nil
+}
+
+// List returns operations matching the filter, newest first.
+func (r *OperationRepository) List(_ context.Context, filter operation.ListFilter) ([]operation.Operation, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	ops := []operation.Operation{}
+	for _, op := range r.db.operations {
+		if filter.DatabaseID != nil && op.DatabaseID != *filter.DatabaseID {
+			continue
+		}
+		if filter.Status != nil && op.Status != *filter.Status {
+			continue
+		}
+		ops = append(ops, *copyOperation(op))
+	}
+	sort.Slice(ops, func(i, j int) bool {
+		return r.db.order[ops[i].ID] > r.db.order[ops[j].ID]
+	})
+	return ops, nil
+}
+
+// SetProgress records the progress of a running operation.
+func (r *OperationRepository) SetProgress(_ context.Context, id uuid.UUID, progress int, message string) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	op, err := r.running(id)
+	if err != nil {
+		return err
+	}
+	op.Progress = progress
+	op.Message = message
+	op.UpdatedAt = now()
+	return nil
+}
+
+// Complete moves a running operation to a final status.
+func (r *OperationRepository) Complete(_ context.Context, id uuid.UUID, status string, result map[string]any, opErr *operation.Error) (*operation.Operation, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	op, err := r.running(id)
+	if err != nil {
+		return nil, err
+	}
+	t := now()
+	op.Status = status
+	op.Result = maps.Clone(result)
+	op.Error = nil
+	if opErr != nil {
+		e := *opErr
+		op.Error = &e
+	}
+	if status == operation.StatusSucceeded {
+		op.Progress = 100
+	}
+	op.UpdatedAt = t
+	op.CompletedAt = &t
+	return copyOperation(op), nil
+}
+
+// Renew records a heartbeat on the running operations among ids.
+func (r *OperationRepository) Renew(_ context.Context, ids []uuid.UUID) ([]uuid.UUID, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	var renewed []uuid.UUID
+	t := now()
+	for _, id := range ids {
+		if op, err := r.running(id); err == nil {
+			op.RenewedAt = t
+			renewed = append(renewed, id)
+		}
+	}
+	return renewed, nil
+}
+
+// running returns the stored running operation. Callers must hold the write
+// lock.
+func (r *OperationRepository) running(id uuid.UUID) (*operation.Operation, error) {
+	op, ok := r.db.operations[id]
+	if !ok {
+		return nil, operation.ErrOperationNotFound
+	}
+	if op.Done() {
+		return nil, operation.ErrOperationDone
+	}
+	return op, nil
+}
+
+func copyOperation(op *operation.Operation) *operation.Operation {
+	c := *op
+	c.Result = maps.Clone(op.Result)
+	if op.Error != nil {
+		e := *op.Error
+		c.Error = &e
+	}
+	return &c
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/organization"
+)
+
+// OrganizationRepository implements organization.Repository in memory.
+type OrganizationRepository struct {
+	db *DB
+}
+
+// Create inserts a new organization record.
+func (r *OrganizationRepository) Create(_ context.Context, o *organization.Organization) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.organizations {
+		if existing.Name == o.Name {
+			return organization.ErrDuplicateName
+		}
+	}
+
+	o.ID = r.db.nextID()
+	o.CreatedAt = now()
+	o.UpdatedAt = o.CreatedAt
+	o.UpdatedBy = o.CreatedBy
+
+	stored := *o
+	r.db.organizations[o.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a single organization by its UUID.
+func (r *OrganizationRepository) GetByID(_ context.Context, id uuid.UUID) (*organization.Organization, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	o, ok := r.db.organizations[id]
+	if !ok {
+		return nil, organization.ErrNotFound
+	}
+	out := *o
+	return &out, nil
+}
+
+// GetByName retrieves a single organization by its name.
+func (r *OrganizationRepository) GetByName(_ context.Context, name string) (*organization.Organization, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, o := range r.db.organizations {
+		if o.Name == name {
+			out := *o
+			return &out, nil
+		}
+	}
+	return nil, organization.ErrNotFound
+}
+
+// List retrieves all organizations ordered by creation time.
+func (r *OrganizationRepository) List(_ context.Context) ([]organization.Organization, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	orgs := make([]organization.Organization, 0, len(r.db.organizations))
+	for _, o := range r.db.organizations {
+		orgs = append(orgs, *o)
+	}
+	sort.Slice(orgs, func(i, j int) bool {
+		return r.db.order[orgs[i].ID] < r.db.order[orgs[j].ID]
+	})
+	return orgs, nil
+}
+
+// Update applies the non-nil fields to an organization and returns the
+// updated record.
+func (r *OrganizationRepository) Update(_ context.Context, id uuid.UUID, fields organization.UpdateFields) (*organization.Organization, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	o, ok := r.db.organizations[id]
+	if !ok {
+		return nil, organization.ErrNotFound
+	}
+	if fields.MaxDatabases == nil && fields.QuotaWarningPercent == nil {
+		out := *o
+		return &out, nil
+	}
+	if fields.MaxDatabases != nil {
+		o.MaxDatabases = *fields.MaxDatabases
+	}
+	if fields.QuotaWarningPercent != nil {
+		o.QuotaWarningPercent = *fields.QuotaWarningPercent
+	}
+	if fields.UpdatedBy != "" {
+		o.UpdatedBy = fields.UpdatedBy
+	}
+	o.UpdatedAt = now()
+	out := *o
+	return &out, nil
+}
+
+// Delete removes an organization by its UUID, and its superadmins with it.
+// Returns ErrHasTeams if teams or blueprints still belong to it, mirroring the
+// foreign keys: ON DELETE RESTRICT for teams and blueprints, CASCADE for users.
+func (r *OrganizationRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.organizations[id]; !ok {
+		return organization.ErrNotFound
+	}
+	for _, t := range r.db.teams {
+		if t.OrganizationID != nil && *t.OrganizationID == id {
+			return organization.ErrHasTeams
+		}
+	}
+	for _, bp := range r.db.blueprints {
+		if bp.OrganizationID != nil && *bp.OrganizationID == id {
+			return organization.ErrHasTeams
+		}
+	}
+
+	for uid, u := range r.db.users {
+		if u.OrganizationID != nil && *u.OrganizationID == id {
+			delete(r.db.users, uid)
+			delete(r.db.order, uid)
+		}
+	}
+	delete(r.db.organizations, id)
+	delete(r.db.order, id)
+	return nil
+}
@@ -0,0 +1,95 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/rollout"
+)
+
+// ListDeletedBefore lists the databases soft-deleted before the given time.
+func (r *DatabaseRepository) ListDeletedBefore(_ context.Context, before time.Time) ([]database.DeletedDatabase, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	var out []database.DeletedDatabase
+	for _, d := range r.db.databases {
+		if d.DeletedAt == nil || !d.DeletedAt.Before(before) {
+			continue
+		}
+		joined := r.withJoins(d)
+		out = append(out, database.DeletedDatabase{
+			ID:                 d.ID,
+			Name:               d.Name,
+			OwnerTeamName:      joined.OwnerTeamName,
+			DataClassification: d.DataClassification,
+			LegalHold:          d.LegalHold,
+			DeletedAt:          *d.DeletedAt,
+		})
+	}
+	slices.SortFunc(out, func(a, b database.DeletedDatabase) int {
+		if c := a.DeletedAt.Compare(b.DeletedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID.String(), b.ID.String())
+	})
+	return out, nil
+}
+
+// Purge removes the database and, mirroring the foreign keys of the Postgres
+// schema, every row that belongs to it.
+func (r *DatabaseRepository) Purge(_ context.Context, id uuid.UUID, deletedBefore time.Time) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt == nil || !d.DeletedAt.Before(deletedBefore) || d.LegalHold {
+		return database.ErrNotFound
+	}
+
+	delete(r.db.databases, id)
+	delete(r.db.order, id)
+	delete(r.db.specs, id)
+	delete(r.db.locks, id)
+	for _, other := range r.db.databases {
+		if other.PromotedFromID != nil && *other.PromotedFromID == id {
+			other.PromotedFromID = nil
+		}
+		if other.SourceDatabaseID != nil && *other.SourceDatabaseID == id {
+			other.SourceDatabaseID = nil
+		}
+	}
+
+	r.db.statusHistory = slices.DeleteFunc(r.db.statusHistory, func(c database.StatusChange) bool { return c.DatabaseID == id })
+	r.db.resizeEvents = slices.DeleteFunc(r.db.resizeEvents, func(e database.ResizeEvent) bool { return e.DatabaseID == id })
+	r.db.usageSamples = slices.DeleteFunc(r.db.usageSamples, func(s database.UsageSample) bool { return s.DatabaseID == id })
+	r.db.tierChanges = slices.DeleteFunc(r.db.tierChanges, func(c database.TierChange) bool { return c.DatabaseID == id })
+	r.db.querySnapshots = slices.DeleteFunc(r.db.querySnapshots, func(s database.QuerySnapshot) bool { return s.DatabaseID == id })
+	r.db.promotions = slices.DeleteFunc(r.db.promotions, func(p database.Promotion) bool {
+		return p.SourceDatabaseID == id || p.TargetDatabaseID == id
+	})
+	for rolloutID, targets := range r.db.rolloutTargets {
+		r.db.rolloutTargets[rolloutID] = slices.DeleteFunc(targets, func(t rollout.Target) bool { return t.DatabaseID == id })
+	}
+	for depID, dep := range r.db.dependents {
+		if dep.DatabaseID == id {
+			delete(r.db.dependents, depID)
+		}
+	}
+	for opID, op := range r.db.operations {
+		if op.DatabaseID == id {
+			delete(r.db.operations, opID)
+		}
+	}
+	for jobID, job := range r.db.jobs {
+		if job.DatabaseID == id {
+			delete(r.db.jobs, jobID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/revision"
+)
+
+// StartRename records rename on a database unless the new name is taken or
+// reserved.
+func (r *DatabaseRepository) StartRename(_ context.Context, id uuid.UUID, rename database.Rename) (*database.Database, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil {
+		return nil, database.ErrNotFound
+	}
+	if d.Rename != nil {
+		return nil, database.ErrRenameInProgress
+	}
+	for _, other := range r.db.databases {
+		if other.DeletedAt == nil && (other.Name == rename.To || other.Rename != nil && other.Rename.To == rename.To) {
+			return nil, database.ErrDuplicateName
+		}
+	}
+
+	rename.StartedAt = rename.StartedAt.UTC().Truncate(time.Microsecond)
+	d.Rename = &rename
+	d.UpdatedBy = rename.RequestedBy
+	d.UpdatedAt = now()
+	r.db.recordDatabaseRevision(d, revision.OperationUpdate, d.UpdatedAt)
+	return r.withJoins(d), nil
+}
+
+// SetRenamePhase moves the rename of a database to phase.
+func (r *DatabaseRepository) SetRenamePhase(_ context.Context, id uuid.UUID, phase string) (*database.Database, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil || d.Rename == nil {
+		return nil, database.ErrNotFound
+	}
+	rename := *d.Rename
+	rename.Phase = phase
+	d.Rename = &rename
+	d.UpdatedAt = now()
+	r.db.recordDatabaseRevision(d, revision.OperationUpdate, d.UpdatedAt)
+	return r.withJoins(d), nil
+}
+
+// CompleteRename switches a database to the names of its rename.
+func (r *DatabaseRepository) CompleteRename(_ context.Context, id uuid.UUID, host *string, port *int, secretName *string, by string) (*database.Database, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil || d.Rename == nil {
+		return nil, database.ErrNotFound
+	}
+	for _, other := range r.db.databases {
+		if other.ID != id && other.DeletedAt == nil && other.Name == d.Rename.To {
+			return nil, database.ErrDuplicateName
+		}
+	}
+
+	rename := *d.Rename
+	rename.Phase = database.RenameRetiring
+	d.Name = rename.To
+	d.ClusterName = database.ClusterName(rename.To)
+	d.PoolerName = database.PoolerName(rename.To)
+	d.DNSName = rename.DNSName
+	d.Rename = &rename
+	if host != nil {
+		h := *host
+		d.Host = &h
+	}
+	if port != nil {
+		p := *port
+		d.Port = &p
+	}
+	if secretName != nil {
+		s := *secretName
+		d.SecretName = &s
+	}
+	if by != "" {
+		d.UpdatedBy = by
+	}
+	d.UpdatedAt = now()
+	r.db.recordDatabaseRevision(d, revision.OperationUpdate, d.UpdatedAt)
+	return r.withJoins(d), nil
+}
+
+// ClearRename forgets the rename of a database.
+func (r *DatabaseRepository) ClearRename(_ context.Context, id uuid.UUID) (*database.Database, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	d, ok := r.db.databases[id]
+	if !ok || d.DeletedAt != nil {
+		return nil, database.ErrNotFound
+	}
+	d.Rename = nil
+	d.UpdatedAt = now()
+	r.db.recordDatabaseRevision(d, revision.OperationUpdate, d.UpdatedAt)
+	return r.withJoins(d), nil
+}
@@ -0,0 +1,44 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// ResizeEventRepository implements database.ResizeEventRepository in memory.
+type ResizeEventRepository struct {
+	db *DB
+}
+
+// Record appends a resize event and sets its ID and CreatedAt. Like the
+// foreign key in Postgres, the database must exist.
+func (r *ResizeEventRepository) Record(_ context.Context, e *database.ResizeEvent) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[e.DatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	r.db.resizeSeq++
+	e.ID = r.db.resizeSeq
+	e.CreatedAt = now()
+	r.db.resizeEvents = append(r.db.resizeEvents, *e)
+	return nil
+}
+
+// ListByDatabase returns a database's resize events, oldest first.
+func (r *ResizeEventRepository) ListByDatabase(_ context.Context, databaseID uuid.UUID) ([]database.ResizeEvent, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	events := []database.ResizeEvent{}
+	for _, e := range r.db.resizeEvents {
+		if e.DatabaseID == databaseID {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
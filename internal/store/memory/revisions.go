@@ -0,0 +1,189 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// RevisionRepository implements revision.Repository in memory.
+type RevisionRepository struct {
+	db *DB
+}
+
+// List returns the revisions of a resource, oldest first.
+func (r *RevisionRepository) List(_ context.Context, kind string, id uuid.UUID) ([]revision.Revision, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	revisions := []revision.Revision{}
+	for _, rev := range r.db.revisions[revisionKey{kind, id}] {
+		rev.Snapshot = maps.Clone(rev.Snapshot)
+		revisions = append(revisions, rev)
+	}
+	return revisions, nil
+}
+
+type revisionKey struct {
+	kind string
+	id   uuid.UUID
+}
+
+// recordRevision appends a revision of a resource, as the record_revision
+// trigger does in Postgres: an update that leaves the snapshot as it was
+// records nothing. Callers must hold the write lock.
+func (db *DB) recordRevision(kind string, id uuid.UUID, op string, snapshot map[string]any, actor string, at time.Time) {
+	key := revisionKey{kind, id}
+	history := db.revisions[key]
+	if op == revision.OperationUpdate && len(history) > 0 &&
+		reflect.DeepEqual(history[len(history)-1].Snapshot, snapshot) {
+		return
+	}
+	db.revisionHeads[key]++
+	db.revisions[key] = append(history, revision.Revision{
+		Kind:       kind,
+		ResourceID: id,
+		Number:     db.revisionHeads[key],
+		Operation:  op,
+		Snapshot:   snapshot,
+		ChangedBy:  actor,
+		ChangedAt:  at,
+	})
+}
+
+// recordDatabaseRevision records the state of d after a change. Like the
+// trigger, it leaves out the columns copied from the owner team and tier,
+// updated_at and the replication lag.
+func (db *DB) recordDatabaseRevision(d *database.Database, op string, at time.Time) {
+	snapshot := map[string]any{
+		"id":                          d.ID,
+		"name":                        d.Name,
+		"owner_team_id":               d.OwnerTeamID,
+		"tier_id":                     d.TierID,
+		"purpose":                     d.Purpose,
+		"data_classification":         d.DataClassification,
+		"namespace":                   d.Namespace,
+		"environment":                 d.Environment,
+		"promoted_from_id":            d.PromotedFromID,
+		"source_database_id":          d.SourceDatabaseID,
+		"cluster_name":                d.ClusterName,
+		"pooler_name":                 d.PoolerName,
+		"status":                      d.Status,
+		"status_reason":               d.StatusReason,
+		"status_message":              d.StatusMessage,
+		"host":                        d.Host,
+		"port":                        d.Port,
+		"secret_name":                 d.SecretName,
+		"generation":                  d.Generation,
+		"observed_generation":         d.ObservedGeneration,
+		"ack_by":                      nil,
+		"ack_comment":                 nil,
+		"acked_at":                    nil,
+		"ack_until":                   nil,
+		"instances_total":             nil,
+		"instances_ready":             nil,
+		"current_primary":             nil,
+		"operator_version":            nil,
+		"conditions":                  d.Conditions,
+		"reconciliation_paused_by":    nil,
+		"reconciliation_paused_until": nil,
+		"placement":                   d.Placement,
+		"images":                      d.Images,
+		"exposure":                    d.Exposure.Type,
+		"exposure_allowed_ranges":     d.Exposure.AllowedSourceRanges,
+		"external_host":               d.ExternalHost,
+		"dns_name":                    d.DNSName,
+		"query_insights":              d.QueryInsights,
+		"archive_url":                 d.ArchiveURL,
+		"legal_hold":                  d.LegalHold,
+		"rename":                      d.Rename,
+		"credentials_rotated_at":      d.CredentialsRotatedAt,
+		"created_by":                  d.CreatedBy,
+		"updated_by":                  d.UpdatedBy,
+		"created_at":                  d.CreatedAt,
+		"deleted_at":                  d.DeletedAt,
+	}
+	if d.Conditions == nil {
+		snapshot["conditions"] = []database.Condition{}
+	}
+	if d.Images == nil {
+		snapshot["images"] = []database.ImagePin{}
+	}
+	if d.Ack != nil {
+		snapshot["ack_by"] = d.Ack.By
+		snapshot["ack_comment"] = d.Ack.Comment
+		snapshot["acked_at"] = d.Ack.At
+		snapshot["ack_until"] = d.Ack.Until
+	}
+	if d.Instances != nil {
+		snapshot["instances_total"] = d.Instances.Total
+		snapshot["instances_ready"] = d.Instances.Ready
+		if d.Instances.Primary != "" {
+			snapshot["current_primary"] = d.Instances.Primary
+		}
+	}
+	if d.OperatorVersion != "" {
+		snapshot["operator_version"] = d.OperatorVersion
+	}
+	if d.ReconciliationPause != nil {
+		snapshot["reconciliation_paused_by"] = d.ReconciliationPause.By
+		snapshot["reconciliation_paused_until"] = d.ReconciliationPause.Until
+	}
+	db.recordRevision(revision.KindDatabase, d.ID, op, jsonValues(snapshot), d.UpdatedBy, at)
+}
+
+// recordTierRevision records the state of t after a change, or before it is
+// deleted. Deletes carry no actor, as in Postgres.
+func (db *DB) recordTierRevision(t *tier.Tier, op string, at time.Time) {
+	snapshot := map[string]any{
+		"id":                          t.ID,
+		"name":                        t.Name,
+		"description":                 t.Description,
+		"blueprint_id":                t.BlueprintID,
+		"destruction_strategy":        t.DestructionStrategy,
+		"backup_enabled":              t.BackupEnabled,
+		"namespace":                   t.Namespace,
+		"storage_autoscale_enabled":   t.StorageAutoscaling.Enabled,
+		"storage_autoscale_threshold": t.StorageAutoscaling.ThresholdPercent,
+		"storage_autoscale_increment": t.StorageAutoscaling.IncrementPercent,
+		"storage_autoscale_max_size":  t.StorageAutoscaling.MaxSize,
+		"zone_spread":                 t.Topology.ZoneSpread,
+		"node_selector":               t.Topology.NodeSelector,
+		"pdb_min_available":           t.Disruption.MinAvailable,
+		"priority_class_name":         t.Disruption.PriorityClassName,
+		"data_classifications":        t.DataClassifications,
+		"created_by":                  t.CreatedBy,
+		"updated_by":                  t.UpdatedBy,
+		"created_at":                  t.CreatedAt,
+	}
+	if t.DataClassifications == nil {
+		snapshot["data_classifications"] = []string{}
+	}
+	if t.Topology.NodeSelector == nil {
+		snapshot["node_selector"] = map[string]string{}
+	}
+	actor := t.UpdatedBy
+	if op == revision.OperationDelete {
+		actor = ""
+	}
+	db.recordRevision(revision.KindTier, t.ID, op, jsonValues(snapshot), actor, at)
+}
+
+// jsonValues converts a snapshot to the JSON values a JSONB snapshot decodes
+// to, so snapshots of both backends compare and serialize alike. Snapshots
+// hold only strings, numbers, times, UUIDs and slices, maps and structs of
+// them, which always encode.
+func jsonValues(snapshot map[string]any) map[string]any {
+	b, _ := json.Marshal(snapshot)
+	var out map[string]any
+	_ = json.Unmarshal(b, &out)
+	return out
+}
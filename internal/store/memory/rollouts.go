@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/rollout"
+)
+
+// RolloutRepository implements rollout.Repository in memory.
+type RolloutRepository struct {
+	db *DB
+}
+
+// Create inserts a rollout and its targets.
+func (r *RolloutRepository) Create(_ context.Context, ro *rollout.Rollout, targets []rollout.Target) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.tiers[ro.TierID]; !ok {
+		return fmt.Errorf("inserting rollout: tier %s does not exist", ro.TierID)
+	}
+	for _, t := range targets {
+		if _, ok := r.db.databases[t.DatabaseID]; !ok {
+			return fmt.Errorf("inserting rollout target: database %s does not exist", t.DatabaseID)
+		}
+	}
+
+	ro.ID = r.db.nextID()
+	ro.CreatedAt = now()
+	ro.UpdatedAt = ro.CreatedAt
+	stored := *ro
+	r.db.rollouts[ro.ID] = &stored
+
+	for i := range targets {
+		targets[i].RolloutID = ro.ID
+		targets[i].UpdatedAt = ro.CreatedAt
+	}
+	r.db.rolloutTargets[ro.ID] = slices.Clone(targets)
+	*ro = *r.withJoins(&stored)
+	return nil
+}
+
+// GetByID retrieves a rollout by ID.
+func (r *RolloutRepository) GetByID(_ context.Context, id uuid.UUID) (*rollout.Rollout, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	ro, ok := r.db.rollouts[id]
+	if !ok {
+		return nil, rollout.ErrRolloutNotFound
+	}
+	return r.withJoins(ro), nil
+}
+
+// List returns rollouts matching the filter, newest first.
+func (r *RolloutRepository) List(_ context.Context, filter rollout.ListFilter) ([]rollout.Rollout, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	rollouts := []rollout.Rollout{}
+	for _, ro := range r.db.rollouts {
+		if filter.TierID != nil && ro.TierID != *filter.TierID {
+			continue
+		}
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, ro.Status) {
+			continue
+		}
+		rollouts = append(rollouts, *r.withJoins(ro))
+	}
+	sort.Slice(rollouts, func(i, j int) bool {
+		return r.db.order[rollouts[i].ID] > r.db.order[rollouts[j].ID]
+	})
+	return rollouts, nil
+}
+
+// ListTargets returns a rollout's targets ordered by batch and database name.
+func (r *RolloutRepository) ListTargets(_ context.Context, rolloutID uuid.UUID) ([]rollout.Target, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	targets := slices.Clone(r.db.rolloutTargets[rolloutID])
+	if targets == nil {
+		targets = []rollout.Target{}
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		if targets[i].Batch != targets[j].Batch {
+			return targets[i].Batch < targets[j].Batch
+		}
+		return targets[i].DatabaseName < targets[j].DatabaseName
+	})
+	return targets, nil
+}
+
+// Transition moves a rollout between statuses atomically.
+func (r *RolloutRepository) Transition(_ context.Context, id uuid.UUID, from []string, to string, errMsg string) (*rollout.Rollout, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	ro, ok := r.db.rollouts[id]
+	if !ok {
+		return nil, rollout.ErrRolloutNotFound
+	}
+	if !slices.Contains(from, ro.Status) {
+		return nil, rollout.ErrInvalidTransition
+	}
+	ro.Status = to
+	ro.Error = errMsg
+	ro.UpdatedAt = now()
+	return r.withJoins(ro), nil
+}
+
+// SetCurrentBatch records the batch a rollout is working on.
+func (r *RolloutRepository) SetCurrentBatch(_ context.Context, id uuid.UUID, batch int) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	ro, ok := r.db.rollouts[id]
+	if !ok {
+		return rollout.ErrRolloutNotFound
+	}
+	ro.CurrentBatch = batch
+	ro.UpdatedAt = now()
+	return nil
+}
+
+// UpdateTarget saves a target's status, error and applied time.
+func (r *RolloutRepository) UpdateTarget(_ context.Context, t *rollout.Target) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	targets := r.db.rolloutTargets[t.RolloutID]
+	for i := range targets {
+		if targets[i].DatabaseID == t.DatabaseID {
+			targets[i].Status = t.Status
+			targets[i].Error = t.Error
+			targets[i].AppliedAt = t.AppliedAt
+			targets[i].UpdatedAt = now()
+			t.UpdatedAt = targets[i].UpdatedAt
+			return nil
+		}
+	}
+	return rollout.ErrRolloutNotFound
+}
+
+// withJoins returns a copy of ro with the transient tier name populated.
+// Callers must hold at least the read lock.
+func (r *RolloutRepository) withJoins(ro *rollout.Rollout) *rollout.Rollout {
+	out := *ro
+	if t, ok := r.db.tiers[ro.TierID]; ok {
+		out.TierName = t.Name
+	}
+	return &out
+}
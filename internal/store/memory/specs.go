@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// SpecRepository implements database.SpecRepository in memory.
+type SpecRepository struct {
+	db *DB
+}
+
+// Put records the spec of its database, replacing any earlier one. Like the
+// foreign key in Postgres, the database must exist.
+func (r *SpecRepository) Put(_ context.Context, s *database.Spec) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[s.DatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	s.AppliedAt = now()
+	stored := *s
+	r.db.specs[s.DatabaseID] = &stored
+	return nil
+}
+
+// Get returns the spec a database was last applied with.
+func (r *SpecRepository) Get(_ context.Context, databaseID uuid.UUID) (*database.Spec, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	s, ok := r.db.specs[databaseID]
+	if !ok {
+		return nil, database.ErrSpecNotFound
+	}
+	spec := *s
+	return &spec, nil
+}
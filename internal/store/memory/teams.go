@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/team"
+)
+
+// TeamRepository implements team.Repository in memory.
+type TeamRepository struct {
+	db *DB
+}
+
+// Create inserts a new team record.
+func (r *TeamRepository) Create(_ context.Context, t *team.Team) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.teams {
+		if existing.Name == t.Name {
+			return team.ErrDuplicateTeamName
+		}
+	}
+
+	t.ID = r.db.nextID()
+	t.CreatedAt = now()
+	t.UpdatedAt = t.CreatedAt
+	t.UpdatedBy = t.CreatedBy
+	t.Labels = copyMap(t.Labels)
+	t.Annotations = copyMap(t.Annotations)
+	if t.OrganizationID != nil {
+		if _, ok := r.db.organizations[*t.OrganizationID]; !ok {
+			return fmt.Errorf("inserting team: organization %s does not exist", t.OrganizationID)
+		}
+	}
+
+	stored := copyTeam(t)
+	r.db.teams[t.ID] = stored
+	return nil
+}
+
+// GetByID retrieves a single team by its UUID.
+func (r *TeamRepository) GetByID(_ context.Context, id uuid.UUID) (*team.Team, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	t, ok := r.db.teams[id]
+	if !ok {
+		return nil, team.ErrTeamNotFound
+	}
+	return copyTeam(t), nil
+}
+
+// GetByName retrieves a single team by its name.
+func (r *TeamRepository) GetByName(_ context.Context, name string) (*team.Team, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, t := range r.db.teams {
+		if t.Name == name {
+			return copyTeam(t), nil
+		}
+	}
+	return nil, team.ErrTeamNotFound
+}
+
+// List retrieves all teams ordered by creation time.
+func (r *TeamRepository) List(_ context.Context) ([]team.Team, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	teams := make([]team.Team, 0, len(r.db.teams))
+	for _, t := range r.db.teams {
+		teams = append(teams, *copyTeam(t))
+	}
+	sort.Slice(teams, func(i, j int) bool {
+		return r.db.order[teams[i].ID] < r.db.order[teams[j].ID]
+	})
+	return teams, nil
+}
+
+// Update applies the non-nil fields to a team and returns the updated record.
+func (r *TeamRepository) Update(_ context.Context, id uuid.UUID, fields team.UpdateFields) (*team.Team, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	t, ok := r.db.teams[id]
+	if !ok {
+		return nil, team.ErrTeamNotFound
+	}
+	if fields.Labels == nil && fields.Annotations == nil && fields.OrganizationID == nil && fields.MaxConnections == nil && fields.ArchiveLocation == nil {
+		return copyTeam(t), nil
+	}
+	if fields.OrganizationID != nil {
+		if _, ok := r.db.organizations[*fields.OrganizationID]; !ok {
+			return nil, fmt.Errorf("updating team: organization %s does not exist", fields.OrganizationID)
+		}
+	}
+	if fields.Labels != nil {
+		t.Labels = copyMap(fields.Labels)
+	}
+	if fields.Annotations != nil {
+		t.Annotations = copyMap(fields.Annotations)
+	}
+	if fields.OrganizationID != nil {
+		id := *fields.OrganizationID
+		t.OrganizationID = &id
+	}
+	if fields.MaxConnections != nil {
+		t.MaxConnections = *fields.MaxConnections
+	}
+	if fields.ArchiveLocation != nil {
+		t.ArchiveLocation = *fields.ArchiveLocation
+	}
+	if fields.UpdatedBy != "" {
+		t.UpdatedBy = fields.UpdatedBy
+	}
+	t.UpdatedAt = now()
+	return copyTeam(t), nil
+}
+
+// Delete removes a team by its UUID. Returns ErrTeamHasUsers if users,
+// databases or blueprints still reference it, mirroring the ON DELETE
+// RESTRICT foreign keys.
+func (r *TeamRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.teams[id]; !ok {
+		return team.ErrTeamNotFound
+	}
+	for _, u := range r.db.users {
+		if u.TeamID != nil && *u.TeamID == id {
+			return team.ErrTeamHasUsers
+		}
+	}
+	for _, d := range r.db.databases {
+		if d.OwnerTeamID == id {
+			return team.ErrTeamHasUsers
+		}
+	}
+	for _, bp := range r.db.blueprints {
+		if bp.TeamID != nil && *bp.TeamID == id {
+			return team.ErrTeamHasUsers
+		}
+	}
+
+	for fid, w := range r.db.freezes {
+		if w.TeamID != nil && *w.TeamID == id {
+			delete(r.db.freezes, fid)
+			delete(r.db.order, fid)
+		}
+	}
+
+	delete(r.db.teams, id)
+	delete(r.db.order, id)
+	return nil
+}
+
+func copyTeam(t *team.Team) *team.Team {
+	out := *t
+	out.Labels = copyMap(t.Labels)
+	out.Annotations = copyMap(t.Annotations)
+	if t.OrganizationID != nil {
+		id := *t.OrganizationID
+		out.OrganizationID = &id
+	}
+	return &out
+}
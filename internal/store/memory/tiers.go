@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// TierRepository implements tier.Repository in memory.
+type TierRepository struct {
+	db *DB
+}
+
+// Create inserts a new tier record.
+func (r *TierRepository) Create(_ context.Context, t *tier.Tier) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	for _, existing := range r.db.tiers {
+		if existing.Name == t.Name {
+			return tier.ErrDuplicateTierName
+		}
+	}
+	if t.BlueprintID != nil {
+		if _, ok := r.db.blueprints[*t.BlueprintID]; !ok {
+			return fmt.Errorf("inserting tier: blueprint %s does not exist", t.BlueprintID)
+		}
+	}
+
+	t.ID = r.db.nextID()
+	t.CreatedAt = now()
+	t.UpdatedAt = t.CreatedAt
+	t.UpdatedBy = t.CreatedBy
+
+	stored := *t
+	stored.DataClassifications = slices.Clone(t.DataClassifications)
+	stored.Topology.NodeSelector = maps.Clone(t.Topology.NodeSelector)
+	r.db.tiers[t.ID] = &stored
+	r.db.recordTierRevision(&stored, revision.OperationCreate, stored.CreatedAt)
+	*t = *r.withJoins(&stored)
+	return nil
+}
+
+// GetByID retrieves a single tier by its UUID.
+func (r *TierRepository) GetByID(_ context.Context, id uuid.UUID) (*tier.Tier, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	t, ok := r.db.tiers[id]
+	if !ok {
+		return nil, tier.ErrTierNotFound
+	}
+	return r.withJoins(t), nil
+}
+
+// GetByName retrieves a single tier by its name.
+func (r *TierRepository) GetByName(_ context.Context, name string) (*tier.Tier, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	for _, t := range r.db.tiers {
+		if t.Name == name {
+			return r.withJoins(t), nil
+		}
+	}
+	return nil, tier.ErrTierNotFound
+}
+
+// List retrieves all tiers ordered by creation time.
+func (r *TierRepository) List(_ context.Context) ([]tier.Tier, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	tiers := make([]tier.Tier, 0, len(r.db.tiers))
+	for _, t := range r.db.tiers {
+		tiers = append(tiers, *r.withJoins(t))
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		return r.db.order[tiers[i].ID] < r.db.order[tiers[j].ID]
+	})
+	return tiers, nil
+}
+
+// Update modifies non-nil fields on a tier. Returns the updated tier.
+func (r *TierRepository) Update(_ context.Context, id uuid.UUID, fields tier.UpdateFields) (*tier.Tier, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	t, ok := r.db.tiers[id]
+	if !ok {
+		return nil, tier.ErrTierNotFound
+	}
+
+	if fields.Description == nil && fields.BlueprintID == nil &&
+		fields.DestructionStrategy == nil && fields.BackupEnabled == nil && fields.Namespace == nil &&
+		fields.StorageAutoscaling == nil && fields.Topology == nil && fields.Disruption == nil &&
+		fields.DataClassifications == nil {
+		return r.withJoins(t), nil
+	}
+
+	if fields.BlueprintID != nil {
+		if _, ok := r.db.blueprints[*fields.BlueprintID]; !ok {
+			return nil, fmt.Errorf("updating tier: blueprint %s does not exist", fields.BlueprintID)
+		}
+		blueprintID := *fields.BlueprintID
+		t.BlueprintID = &blueprintID
+	}
+	if fields.Description != nil {
+		t.Description = *fields.Description
+	}
+	if fields.DestructionStrategy != nil {
+		t.DestructionStrategy = *fields.DestructionStrategy
+	}
+	if fields.BackupEnabled != nil {
+		t.BackupEnabled = *fields.BackupEnabled
+	}
+	if fields.Namespace != nil {
+		t.Namespace = *fields.Namespace
+	}
+	if fields.StorageAutoscaling != nil {
+		t.StorageAutoscaling = *fields.StorageAutoscaling
+	}
+	if fields.Topology != nil {
+		t.Topology = tier.Topology{ZoneSpread: fields.Topology.ZoneSpread, NodeSelector: maps.Clone(fields.Topology.NodeSelector)}
+	}
+	if fields.Disruption != nil {
+		t.Disruption = *fields.Disruption
+	}
+	if fields.DataClassifications != nil {
+		t.DataClassifications = slices.Clone(fields.DataClassifications)
+	}
+	if fields.UpdatedBy != "" {
+		t.UpdatedBy = fields.UpdatedBy
+	}
+	t.UpdatedAt = now()
+	r.db.recordTierRevision(t, revision.OperationUpdate, t.UpdatedAt)
+
+	return r.withJoins(t), nil
+}
+
+// Delete removes a tier by its UUID. Returns ErrTierHasDatabases if the tier
+// still has active databases; soft-deleted databases have their tier_id
+// cleared, matching ON DELETE SET NULL.
+func (r *TierRepository) Delete(_ context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	t, ok := r.db.tiers[id]
+	if !ok {
+		return tier.ErrTierNotFound
+	}
+	for _, d := range r.db.databases {
+		if d.TierID != nil && *d.TierID == id && d.DeletedAt == nil {
+			return tier.ErrTierHasDatabases
+		}
+	}
+
+	deletedAt := now()
+	for _, d := range r.db.databases {
+		if d.TierID != nil && *d.TierID == id {
+			d.TierID = nil
+			r.db.recordDatabaseRevision(d, revision.OperationUpdate, deletedAt)
+		}
+	}
+	// Rollouts of the tier cascade, as in Postgres.
+	for rid, ro := range r.db.rollouts {
+		if ro.TierID == id {
+			delete(r.db.rollouts, rid)
+			delete(r.db.rolloutTargets, rid)
+			delete(r.db.order, rid)
+		}
+	}
+	r.db.recordTierRevision(t, revision.OperationDelete, deletedAt)
+	delete(r.db.tiers, id)
+	delete(r.db.order, id)
+	return nil
+}
+
+// withJoins returns a copy of t with the transient blueprint name populated.
+// Callers must hold at least the read lock.
+func (r *TierRepository) withJoins(t *tier.Tier) *tier.Tier {
+	out := *t
+	out.DataClassifications = slices.Clone(t.DataClassifications)
+	out.Topology.NodeSelector = maps.Clone(t.Topology.NodeSelector)
+	out.BlueprintName = ""
+	if t.BlueprintID != nil {
+		if bp, ok := r.db.blueprints[*t.BlueprintID]; ok {
+			out.BlueprintName = bp.Name
+		}
+	}
+	return &out
+}
@@ -0,0 +1,134 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// UsageSampleRepository implements database.UsageSampleRepository in memory.
+type UsageSampleRepository struct {
+	db *DB
+}
+
+// Record appends a sample and sets its CollectedAt. Like the foreign key in
+// Postgres, the database must exist.
+func (r *UsageSampleRepository) Record(_ context.Context, s *database.UsageSample) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[s.DatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	s.CollectedAt = now()
+	r.db.usageSamples = append(r.db.usageSamples, *s)
+	return nil
+}
+
+// ListByDatabase returns a database's samples collected at or after since,
+// oldest first.
+func (r *UsageSampleRepository) ListByDatabase(_ context.Context, databaseID uuid.UUID, since time.Time) ([]database.UsageSample, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	samples := []database.UsageSample{}
+	for _, s := range r.db.usageSamples {
+		if s.DatabaseID == databaseID && !s.CollectedAt.Before(since) {
+			samples = append(samples, s)
+		}
+	}
+	return samples, nil
+}
+
+// DeleteBefore removes samples collected before the given time.
+func (r *UsageSampleRepository) DeleteBefore(_ context.Context, before time.Time) (int64, error) {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	kept := r.db.usageSamples[:0]
+	for _, s := range r.db.usageSamples {
+		if !s.CollectedAt.Before(before) {
+			kept = append(kept, s)
+		}
+	}
+	deleted := int64(len(r.db.usageSamples) - len(kept))
+	r.db.usageSamples = kept
+	return deleted, nil
+}
+
+// TierChangeRepository implements database.TierChangeRepository in memory.
+type TierChangeRepository struct {
+	db *DB
+}
+
+// Record appends a tier change and sets its ID and CreatedAt. Like the
+// foreign key in Postgres, the database must exist.
+func (r *TierChangeRepository) Record(_ context.Context, c *database.TierChange) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[c.DatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	r.db.tierChangeSeq++
+	c.ID = r.db.tierChangeSeq
+	c.CreatedAt = now()
+	r.db.tierChanges = append(r.db.tierChanges, *c)
+	return nil
+}
+
+// ListByDatabase returns a database's tier changes, oldest first.
+func (r *TierChangeRepository) ListByDatabase(_ context.Context, databaseID uuid.UUID) ([]database.TierChange, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	changes := []database.TierChange{}
+	for _, c := range r.db.tierChanges {
+		if c.DatabaseID == databaseID {
+			changes = append(changes, c)
+		}
+	}
+	return changes, nil
+}
+
+// PromotionRepository implements database.PromotionRepository in memory.
+type PromotionRepository struct {
+	db *DB
+}
+
+// Record appends a promotion and sets its ID and CreatedAt. Like the foreign
+// keys in Postgres, both databases must exist.
+func (r *PromotionRepository) Record(_ context.Context, p *database.Promotion) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	if _, ok := r.db.databases[p.SourceDatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	if _, ok := r.db.databases[p.TargetDatabaseID]; !ok {
+		return database.ErrNotFound
+	}
+	r.db.promotionSeq++
+	p.ID = r.db.promotionSeq
+	p.CreatedAt = now()
+	r.db.promotions = append(r.db.promotions, *p)
+	return nil
+}
+
+// ListByDatabase returns the promotions a database was the source or the
+// target of, oldest first.
+func (r *PromotionRepository) ListByDatabase(_ context.Context, databaseID uuid.UUID) ([]database.Promotion, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	promotions := []database.Promotion{}
+	for _, p := range r.db.promotions {
+		if p.SourceDatabaseID == databaseID || p.TargetDatabaseID == databaseID {
+			promotions = append(promotions, p)
+		}
+	}
+	return promotions, nil
+}
@@ -0,0 +1,140 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/auth"
+)
+
+// errDuplicateSuperuser mirrors the idx_users_superuser partial unique index.
+var errDuplicateSuperuser = errors.New("a superuser already exists")
+
+// UserRepository implements auth.UserRepository in memory.
+type UserRepository struct {
+	db *DB
+}
+
+// Create inserts a new user record.
+func (r *UserRepository) Create(_ context.Context, u *auth.User) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	return r.db.insertUser(u)
+}
+
+// insertUser checks the users constraints and stores u. Callers must hold
+// the write lock.
+func (db *DB) insertUser(u *auth.User) error {
+	if u.IsSuperuser {
+		for _, existing := range db.users {
+			if existing.IsSuperuser {
+				return fmt.Errorf("inserting user: %w", errDuplicateSuperuser)
+			}
+		}
+	} else if u.TeamID == nil && u.OrganizationID == nil {
+		return fmt.Errorf("inserting user: non-superusers must have a team or organization")
+	}
+	if u.TeamID != nil && u.OrganizationID != nil {
+		return fmt.Errorf("inserting user: users cannot have both a team and an organization")
+	}
+	if u.TeamID != nil {
+		if _, ok := db.teams[*u.TeamID]; !ok {
+			return fmt.Errorf("inserting user: team %s does not exist", u.TeamID)
+		}
+	}
+	if u.OrganizationID != nil {
+		if _, ok := db.organizations[*u.OrganizationID]; !ok {
+			return fmt.Errorf("inserting user: organization %s does not exist", u.OrganizationID)
+		}
+	}
+
+	u.ID = db.nextID()
+	u.CreatedAt = now()
+
+	stored := *u
+	stored.TeamName = nil
+	stored.TeamRole = nil
+	db.users[u.ID] = &stored
+	return nil
+}
+
+// GetByID retrieves a single user by its UUID.
+func (r *UserRepository) GetByID(_ context.Context, id uuid.UUID) (*auth.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	u, ok := r.db.users[id]
+	if !ok {
+		return nil, auth.ErrUserNotFound
+	}
+	out := *u
+	return &out, nil
+}
+
+// FindByPrefix returns active (non-revoked) users matching the given API key prefix.
+func (r *UserRepository) FindByPrefix(_ context.Context, prefix string) ([]auth.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	users := []auth.User{}
+	for _, u := range r.db.users {
+		if u.ApiKeyPrefix == prefix && u.RevokedAt == nil {
+			users = append(users, *u)
+		}
+	}
+	return users, nil
+}
+
+// List retrieves all users with team info, ordered by creation time.
+func (r *UserRepository) List(_ context.Context) ([]auth.User, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	users := make([]auth.User, 0, len(r.db.users))
+	for _, u := range r.db.users {
+		out := *u
+		if u.TeamID != nil {
+			if t, ok := r.db.teams[*u.TeamID]; ok {
+				name, role := t.Name, t.Role
+				out.TeamName = &name
+				out.TeamRole = &role
+			}
+		}
+		users = append(users, out)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return r.db.order[users[i].ID] < r.db.order[users[j].ID]
+	})
+	return users, nil
+}
+
+// Revoke sets revoked_at on a user. Returns ErrUserNotFound if the user
+// does not exist, and ErrUserRevoked if already revoked.
+func (r *UserRepository) Revoke(_ context.Context, id uuid.UUID) error {
+	r.db.mu.Lock()
+	defer r.db.mu.Unlock()
+
+	u, ok := r.db.users[id]
+	if !ok {
+		return auth.ErrUserNotFound
+	}
+	if u.RevokedAt != nil {
+		return auth.ErrUserRevoked
+	}
+	revokedAt := now()
+	u.RevokedAt = &revokedAt
+	return nil
+}
+
+// CountAll returns the total number of users (including revoked).
+func (r *UserRepository) CountAll(_ context.Context) (int, error) {
+	r.db.mu.RLock()
+	defer r.db.mu.RUnlock()
+
+	return len(r.db.users), nil
+}
@@ -0,0 +1,209 @@
+// Package store selects and constructs the platform storage backend from the
+// DATABASE_URL scheme. postgres:// and postgresql:// use PostgreSQL via pgx;
+// memory:// uses the embedded in-memory backend for local development and demos.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/store/memory"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// Backend names reported by Store.Backend.
+const (
+	BackendPostgres = "postgres"
+	BackendMemory   = "memory"
+)
+
+// ErrSchemaNotTracked is returned by SchemaVersion for backends that have no
+// migrated schema (the in-memory backend).
+var ErrSchemaNotTracked = errors.New("storage backend does not track schema migrations")
+
+// Store bundles the repositories for a single storage backend.
+type Store struct {
+	Databases     database.Repository
+	Stats         database.StatsReader
+	Purges        database.PurgeRepository
+	Archives      database.ArchiveRepository
+	Renames       database.RenameRepository
+	ResizeEvents  database.ResizeEventRepository
+	History       database.HistoryRepository
+	UsageSamples  database.UsageSampleRepository
+	Queries       database.QuerySnapshotRepository
+	TierChanges   database.TierChangeRepository
+	Promotions    database.PromotionRepository
+	Dependents    database.DependentRepository
+	Specs         database.SpecRepository
+	Locks         database.LockRepository
+	Operations    operation.Repository
+	Jobs          jobs.Repository
+	Organizations organization.Repository
+	Teams         team.Repository
+	Tiers         tier.Repository
+	Blueprints    blueprint.Repository
+	Users         auth.UserRepository
+	Invitations   auth.InvitationRepository
+	Rollouts      rollout.Repository
+	Freezes       freeze.Repository
+	Revisions     revision.Repository
+
+	backend       string
+	ping          func(ctx context.Context) error
+	schemaVersion func(ctx context.Context) (uint, bool, error)
+	close         func()
+}
+
+// Open connects to the backend identified by the scheme of databaseURL.
+func Open(ctx context.Context, databaseURL string) (*Store, error) {
+	u, err := url.Parse(databaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("parsing database URL: %w", err)
+	}
+
+	switch u.Scheme {
+	case "postgres", "postgresql":
+		return openPostgres(ctx, databaseURL)
+	case "memory":
+		return openMemory(), nil
+	default:
+		return nil, fmt.Errorf("unsupported database URL scheme %q (expected postgres, postgresql, or memory)", u.Scheme)
+	}
+}
+
+func openPostgres(ctx context.Context, databaseURL string) (*Store, error) {
+	db, err := database.New(ctx, databaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := db.Pool()
+	return &Store{
+		Databases:     database.NewRepository(pool),
+		Stats:         database.NewStatsReader(pool),
+		Purges:        database.NewPurgeRepository(pool),
+		Archives:      database.NewArchiveRepository(pool),
+		Renames:       database.NewRenameRepository(pool),
+		ResizeEvents:  database.NewResizeEventRepository(pool),
+		History:       database.NewHistoryRepository(pool),
+		UsageSamples:  database.NewUsageSampleRepository(pool),
+		Queries:       database.NewQuerySnapshotRepository(pool),
+		TierChanges:   database.NewTierChangeRepository(pool),
+		Promotions:    database.NewPromotionRepository(pool),
+		Dependents:    database.NewDependentRepository(pool),
+		Specs:         database.NewSpecRepository(pool),
+		Locks:         database.NewLockRepository(pool),
+		Operations:    operation.NewPostgresRepository(pool),
+		Jobs:          jobs.NewPostgresRepository(pool),
+		Organizations: organization.NewRepository(pool),
+		Teams:         team.NewRepository(pool),
+		Tiers:         tier.NewPostgresRepository(pool),
+		Blueprints:    blueprint.NewPostgresRepository(pool),
+		Users:         auth.NewRepository(pool),
+		Invitations:   auth.NewInvitationRepository(pool),
+		Rollouts:      rollout.NewPostgresRepository(pool),
+		Freezes:       freeze.NewRepository(pool),
+		Revisions:     revision.NewRepository(pool),
+		backend:       BackendPostgres,
+		ping:          db.Ping,
+		schemaVersion: func(ctx context.Context) (uint, bool, error) {
+			return postgresSchemaVersion(ctx, pool)
+		},
+		close: db.Close,
+	}, nil
+}
+
+// postgresSchemaVersion reads the golang-migrate bookkeeping table. A missing
+// table or empty table means no migration has been applied.
+func postgresSchemaVersion(ctx context.Context, pool *pgxpool.Pool) (uint, bool, error) {
+	var version int64
+	var dirty bool
+	err := pool.QueryRow(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, false, nil
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "42P01" {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("reading schema_migrations: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+func openMemory() *Store {
+	db := memory.New()
+	return &Store{
+		Databases:     db.Databases(),
+		Stats:         db.Stats(),
+		Purges:        db.Purges(),
+		Archives:      db.Archives(),
+		Renames:       db.Renames(),
+		ResizeEvents:  db.ResizeEvents(),
+		History:       db.History(),
+		UsageSamples:  db.UsageSamples(),
+		Queries:       db.QuerySnapshots(),
+		TierChanges:   db.TierChanges(),
+		Promotions:    db.Promotions(),
+		Dependents:    db.Dependents(),
+		Specs:         db.Specs(),
+		Locks:         db.Locks(),
+		Operations:    db.Operations(),
+		Jobs:          db.Jobs(),
+		Organizations: db.Organizations(),
+		Teams:         db.Teams(),
+		Tiers:         db.Tiers(),
+		Blueprints:    db.Blueprints(),
+		Users:         db.Users(),
+		Invitations:   db.Invitations(),
+		Rollouts:      db.Rollouts(),
+		Freezes:       db.Freezes(),
+		Revisions:     db.Revisions(),
+		backend:       BackendMemory,
+		ping:          db.Ping,
+		schemaVersion: func(context.Context) (uint, bool, error) {
+			return 0, false, ErrSchemaNotTracked
+		},
+		close: func() {},
+	}
+}
+
+// Backend returns the name of the active storage backend.
+func (s *Store) Backend() string {
+	return s.backend
+}
+
+// Ping verifies the backend is reachable.
+func (s *Store) Ping(ctx context.Context) error {
+	return s.ping(ctx)
+}
+
+// SchemaVersion returns the applied migration version and whether the last
+// migration left the schema dirty (partially applied). Version 0 means no
+// migration has been applied.
+func (s *Store) SchemaVersion(ctx context.Context) (uint, bool, error) {
+	return s.schemaVersion(ctx)
+}
+
+// Close releases any resources held by the backend.
+func (s *Store) Close() {
+	s.close()
+}
@@ -0,0 +1,198 @@
+// Package support gathers what is needed to investigate a database into a
+// support bundle, an archive to attach to vendor tickets: DAAP's record of the
+// database, its status history, its rendered manifests and its provider's
+// diagnostics, such as the CNPG Cluster status, recent events and instance
+// logs. It backs GET /databases/{id}/support-bundle.
+package support
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// ErrorsFile is the name of the bundle entry listing what could not be
+// gathered.
+const ErrorsFile = "errors.txt"
+
+// Bundler writes support bundles.
+type Bundler struct {
+	tierRepo tier.Repository
+	bpRepo   blueprint.Repository
+	registry *provider.Registry
+	history  database.StatsReader
+}
+
+// New creates a Bundler.
+func New(tierRepo tier.Repository, bpRepo blueprint.Repository, registry *provider.Registry, history database.StatsReader) *Bundler {
+	return &Bundler{tierRepo: tierRepo, bpRepo: bpRepo, registry: registry, history: history}
+}
+
+type entry struct {
+	path    string
+	content []byte
+}
+
+type statusChange struct {
+	From string    `json:"from,omitempty"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// Write writes a gzip-compressed tarball about db to w, with:
+//
+//	database.json        DAAP's record of the database
+//	status-history.json  its status changes, oldest first
+//	manifests.yaml       its blueprint's manifests as rendered for it
+//	<provider>/...       the diagnostics gathered by its provider
+//	errors.txt           what could not be gathered, if anything
+//
+// Gathering is best effort: only a failure to write the bundle is returned.
+func (b *Bundler) Write(ctx context.Context, w io.Writer, db *database.Database) error {
+	var entries []entry
+	var problems []string
+	add := func(path string, v any) {
+		content, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", path, err))
+			return
+		}
+		entries = append(entries, entry{path: path, content: append(content, '\n')})
+	}
+
+	add("database.json", db)
+
+	changes, err := b.history.StatusHistory(ctx, db.ID)
+	if err != nil {
+		problems = append(problems, "status-history.json: "+err.Error())
+	} else {
+		history := make([]statusChange, 0, len(changes))
+		for _, c := range changes {
+			history = append(history, statusChange{From: c.FromStatus, To: c.ToStatus, At: c.ChangedAt.UTC()})
+		}
+		add("status-history.json", history)
+	}
+
+	p, pdb, bp, err := b.resolve(ctx, db)
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else {
+		entries, problems = b.gather(ctx, p, pdb, bp, entries, problems)
+	}
+
+	if len(problems) > 0 {
+		entries = append(entries, entry{path: ErrorsFile, content: []byte(strings.Join(problems, "\n") + "\n")})
+	}
+	return writeTarball(w, entries, time.Now().UTC())
+}
+
+// gather adds the rendered manifests and the provider's diagnostics.
+func (b *Bundler) gather(ctx context.Context, p provider.Provider, pdb provider.ProviderDatabase, bp *blueprint.Blueprint, entries []entry, problems []string) ([]entry, []string) {
+	manifests, err := "", provider.ErrNotSupported
+	if renderer, ok := p.(provider.ManifestRenderer); ok {
+		manifests, err = renderer.RenderManifests(pdb, bp.Manifests)
+	}
+	switch {
+	case errors.Is(err, provider.ErrNotSupported):
+		problems = append(problems, fmt.Sprintf("manifests.yaml: provider %s does not support rendering", pdb.Provider))
+	case err != nil:
+		problems = append(problems, "manifests.yaml: render failed: "+err.Error())
+	default:
+		entries = append(entries, entry{path: "manifests.yaml", content: []byte(strings.TrimRight(manifests, "\n") + "\n")})
+	}
+
+	diagnoser, ok := p.(provider.Diagnoser)
+	if !ok {
+		return entries, append(problems, fmt.Sprintf("%s: provider does not gather diagnostics", pdb.Provider))
+	}
+	files, err := diagnoser.Diagnostics(ctx, pdb)
+	if errors.Is(err, provider.ErrNotSupported) {
+		return entries, append(problems, fmt.Sprintf("%s: provider does not gather diagnostics", pdb.Provider))
+	}
+	for _, f := range files {
+		entries = append(entries, entry{path: path.Join(pdb.Provider, path.Clean("/"+f.Name)), content: f.Content})
+	}
+	if err != nil {
+		for _, line := range strings.Split(err.Error(), "\n") {
+			problems = append(problems, pdb.Provider+": "+line)
+		}
+	}
+	return entries, problems
+}
+
+// resolve finds the provider of db through its tier and blueprint.
+func (b *Bundler) resolve(ctx context.Context, db *database.Database) (provider.Provider, provider.ProviderDatabase, *blueprint.Blueprint, error) {
+	var pdb provider.ProviderDatabase
+	if db.TierID == nil {
+		return nil, pdb, nil, errors.New("manifests.yaml: database has no tier")
+	}
+	t, err := b.tierRepo.GetByID(ctx, *db.TierID)
+	if err != nil {
+		return nil, pdb, nil, fmt.Errorf("manifests.yaml: fetching tier: %w", err)
+	}
+	if t.BlueprintID == nil {
+		return nil, pdb, nil, fmt.Errorf("manifests.yaml: tier %s has no blueprint", t.Name)
+	}
+	bp, err := b.bpRepo.GetByID(ctx, *t.BlueprintID)
+	if err != nil {
+		return nil, pdb, nil, fmt.Errorf("manifests.yaml: fetching blueprint: %w", err)
+	}
+	p, ok := b.registry.Get(bp.Provider)
+	if !ok {
+		return nil, pdb, nil, fmt.Errorf("manifests.yaml: provider %s is not registered", bp.Provider)
+	}
+	return p, db.ProviderDatabase(t, bp), bp, nil
+}
+
+func writeTarball(w io.Writer, entries []entry, modTime time.Time) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	dirs := make(map[string]bool)
+	for _, en := range entries {
+		parts := strings.Split(en.path, "/")
+		for i := 1; i < len(parts); i++ {
+			dir := strings.Join(parts[:i], "/")
+			if dirs[dir] {
+				continue
+			}
+			dirs[dir] = true
+			if err := tw.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir + "/", Mode: 0o755, ModTime: modTime}); err != nil {
+				return fmt.Errorf("writing %s: %w", dir, err)
+			}
+		}
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     en.path,
+			Mode:     0o644,
+			Size:     int64(len(en.content)),
+			ModTime:  modTime,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("writing %s: %w", en.path, err)
+		}
+		if _, err := tw.Write(en.content); err != nil {
+			return fmt.Errorf("writing %s: %w", en.path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("closing tarball: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("closing gzip stream: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package team
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/cache"
+)
+
+// CachedRepository is a Repository that caches teams looked up by ID or name
+// for a TTL, so handlers resolving the same team on every request do not
+// query the platform database each time. Writes through it clear the cache;
+// writes made by other DAAP instances are seen once cached entries expire.
+// Lookups of missing teams are not cached.
+type CachedRepository struct {
+	Repository
+	byID   *cache.TTL[uuid.UUID, Team]
+	byName *cache.TTL[string, Team]
+}
+
+// NewCachedRepository wraps repo with a lookup cache whose entries expire
+// after ttl.
+func NewCachedRepository(repo Repository, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{
+		Repository: repo,
+		byID:       cache.New[uuid.UUID, Team](ttl),
+		byName:     cache.New[string, Team](ttl),
+	}
+}
+
+// GetByID returns a copy of the cached team, or reads it from the wrapped
+// repository.
+func (r *CachedRepository) GetByID(ctx context.Context, id uuid.UUID) (*Team, error) {
+	t, err := r.byID.Load(id, func() (Team, error) {
+		return deref(r.Repository.GetByID(ctx, id))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(t), nil
+}
+
+// GetByName returns a copy of the cached team, or reads it from the wrapped
+// repository.
+func (r *CachedRepository) GetByName(ctx context.Context, name string) (*Team, error) {
+	t, err := r.byName.Load(name, func() (Team, error) {
+		return deref(r.Repository.GetByName(ctx, name))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(t), nil
+}
+
+// Create inserts a team and clears the cache.
+func (r *CachedRepository) Create(ctx context.Context, t *Team) error {
+	defer r.purge()
+	return r.Repository.Create(ctx, t)
+}
+
+// Update updates a team and clears the cache.
+func (r *CachedRepository) Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Team, error) {
+	defer r.purge()
+	return r.Repository.Update(ctx, id, fields)
+}
+
+// Delete removes a team and clears the cache.
+func (r *CachedRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer r.purge()
+	return r.Repository.Delete(ctx, id)
+}
+
+func (r *CachedRepository) purge() {
+	r.byID.Purge()
+	r.byName.Purge()
+}
+
+// deref copies a team read from the wrapped repository before it is cached,
+// so the cache does not share its maps with the repository.
+func deref(t *Team, err error) (Team, error) {
+	if err != nil {
+		return Team{}, err
+	}
+	return *clone(*t), nil
+}
+
+// clone deep-copies a team so callers cannot modify the cached value through
+// its OrganizationID pointer or its Labels and Annotations maps.
+func clone(t Team) *Team {
+	if t.OrganizationID != nil {
+		id := *t.OrganizationID
+		t.OrganizationID = &id
+	}
+	t.Labels = maps.Clone(t.Labels)
+	t.Annotations = maps.Clone(t.Annotations)
+	return &t
+}
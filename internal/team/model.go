@@ -8,9 +8,30 @@ import (
 
 // Team represents a row in the teams table.
 type Team struct {
-	ID        uuid.UUID
-	Name      string
-	Role      string // "platform" or "product"
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID              uuid.UUID
+	Name            string
+	Role            string            // "platform" or "product"
+	OrganizationID  *uuid.UUID        // organization the team belongs to; nil for none
+	Labels          map[string]string // default labels of the resources of the team's databases
+	Annotations     map[string]string // default annotations of the resources of the team's databases
+	MaxConnections  int               // budget on the summed max_connections of the team's active databases; 0 for no budget
+	ArchiveLocation string            // object store URL the final backups of the team's archived databases go to; empty for none
+	CreatedBy       string            // user name of the creator; empty for teams created before it was recorded
+	UpdatedBy       string            // user name of the last change
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+// UpdateFields holds updatable fields on a team record. Nil fields are not
+// updated; a non-nil map, even empty, replaces the current one.
+type UpdateFields struct {
+	Labels          map[string]string
+	Annotations     map[string]string
+	OrganizationID  *uuid.UUID // moves the team into the organization
+	MaxConnections  *int
+	ArchiveLocation *string
+
+	// UpdatedBy, when set, records who made the update. It is not an update
+	// on its own.
+	UpdatedBy string
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -24,11 +25,11 @@ func NewRepository(pool *pgxpool.Pool) Repository {
 // Create inserts a new team record.
 func (r *PostgresRepository) Create(ctx context.Context, t *Team) error {
 	query := `
-		INSERT INTO teams (name, role)
-		VALUES ($1, $2)
+		INSERT INTO teams (name, role, organization_id, labels, annotations, max_connections, archive_location, created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8)
 		RETURNING id, created_at, updated_at`
 
-	err := r.pool.QueryRow(ctx, query, t.Name, t.Role).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	err := r.pool.QueryRow(ctx, query, t.Name, t.Role, t.OrganizationID, orEmpty(t.Labels), orEmpty(t.Annotations), t.MaxConnections, t.ArchiveLocation, t.CreatedBy).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
@@ -43,12 +44,12 @@ func (r *PostgresRepository) Create(ctx context.Context, t *Team) error {
 // GetByID retrieves a single team by its UUID.
 func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Team, error) {
 	query := `
-		SELECT id, name, role, created_at, updated_at
+		SELECT id, name, role, organization_id, labels, annotations, max_connections, archive_location, created_by, updated_by, created_at, updated_at
 		FROM teams
 		WHERE id = $1`
 
 	var t Team
-	err := r.pool.QueryRow(ctx, query, id).Scan(&t.ID, &t.Name, &t.Role, &t.CreatedAt, &t.UpdatedAt)
+	err := r.pool.QueryRow(ctx, query, id).Scan(&t.ID, &t.Name, &t.Role, &t.OrganizationID, &t.Labels, &t.Annotations, &t.MaxConnections, &t.ArchiveLocation, &t.CreatedBy, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrTeamNotFound
@@ -62,12 +63,12 @@ func (r *PostgresRepository) GetByID(ctx context.Context, id uuid.UUID) (*Team,
 // GetByName retrieves a single team by its name.
 func (r *PostgresRepository) GetByName(ctx context.Context, name string) (*Team, error) {
 	query := `
-		SELECT id, name, role, created_at, updated_at
+		SELECT id, name, role, organization_id, labels, annotations, max_connections, archive_location, created_by, updated_by, created_at, updated_at
 		FROM teams
 		WHERE name = $1`
 
 	var t Team
-	err := r.pool.QueryRow(ctx, query, name).Scan(&t.ID, &t.Name, &t.Role, &t.CreatedAt, &t.UpdatedAt)
+	err := r.pool.QueryRow(ctx, query, name).Scan(&t.ID, &t.Name, &t.Role, &t.OrganizationID, &t.Labels, &t.Annotations, &t.MaxConnections, &t.ArchiveLocation, &t.CreatedBy, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, ErrTeamNotFound
@@ -81,7 +82,7 @@ func (r *PostgresRepository) GetByName(ctx context.Context, name string) (*Team,
 // List retrieves all teams ordered by creation time.
 func (r *PostgresRepository) List(ctx context.Context) ([]Team, error) {
 	query := `
-		SELECT id, name, role, created_at, updated_at
+		SELECT id, name, role, organization_id, labels, annotations, max_connections, archive_location, created_by, updated_by, created_at, updated_at
 		FROM teams
 		ORDER BY created_at ASC`
 
@@ -94,7 +95,7 @@ func (r *PostgresRepository) List(ctx context.Context) ([]Team, error) {
 	var teams []Team
 	for rows.Next() {
 		var t Team
-		err := rows.Scan(&t.ID, &t.Name, &t.Role, &t.CreatedAt, &t.UpdatedAt)
+		err := rows.Scan(&t.ID, &t.Name, &t.Role, &t.OrganizationID, &t.Labels, &t.Annotations, &t.MaxConnections, &t.ArchiveLocation, &t.CreatedBy, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt)
 		if err != nil {
 			return nil, fmt.Errorf("scanning team row: %w", err)
 		}
@@ -111,8 +112,72 @@ func (r *PostgresRepository) List(ctx context.Context) ([]Team, error) {
 	return teams, nil
 }
 
+// Update applies the non-nil fields to a team and returns the updated record.
+// Its databases pick up new labels and annotations through a trigger.
+func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Team, error) {
+	var setClauses []string
+	var args []any
+	argIdx := 1
+
+	if fields.Labels != nil {
+		setClauses = append(setClauses, fmt.Sprintf("labels = $%d", argIdx))
+		args = append(args, fields.Labels)
+		argIdx++
+	}
+	if fields.Annotations != nil {
+		setClauses = append(setClauses, fmt.Sprintf("annotations = $%d", argIdx))
+		args = append(args, fields.Annotations)
+		argIdx++
+	}
+	if fields.OrganizationID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("organization_id = $%d", argIdx))
+		args = append(args, *fields.OrganizationID)
+		argIdx++
+	}
+	if fields.MaxConnections != nil {
+		setClauses = append(setClauses, fmt.Sprintf("max_connections = $%d", argIdx))
+		args = append(args, *fields.MaxConnections)
+		argIdx++
+	}
+	if fields.ArchiveLocation != nil {
+		setClauses = append(setClauses, fmt.Sprintf("archive_location = $%d", argIdx))
+		args = append(args, *fields.ArchiveLocation)
+		argIdx++
+	}
+
+	if len(setClauses) == 0 {
+		return r.GetByID(ctx, id)
+	}
+
+	if fields.UpdatedBy != "" {
+		setClauses = append(setClauses, fmt.Sprintf("updated_by = $%d", argIdx))
+		args = append(args, fields.UpdatedBy)
+		argIdx++
+	}
+	setClauses = append(setClauses, "updated_at = NOW()")
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE teams
+		SET %s
+		WHERE id = $%d
+		RETURNING id, name, role, organization_id, labels, annotations, max_connections, archive_location, created_by, updated_by, created_at, updated_at`,
+		strings.Join(setClauses, ", "), argIdx)
+
+	var t Team
+	err := r.pool.QueryRow(ctx, query, args...).Scan(&t.ID, &t.Name, &t.Role, &t.OrganizationID, &t.Labels, &t.Annotations, &t.MaxConnections, &t.ArchiveLocation, &t.CreatedBy, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, fmt.Errorf("updating team: %w", err)
+	}
+
+	return &t, nil
+}
+
 // Delete removes a team by its UUID. Returns ErrTeamHasUsers if the team
-// still has users referencing it (FK RESTRICT).
+// still has users, databases or blueprints referencing it (FK RESTRICT).
 func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	query := `DELETE FROM teams WHERE id = $1`
 
@@ -131,3 +196,12 @@ func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// orEmpty returns m, or an empty map if m is nil, so JSONB columns hold {}
+// rather than null.
+func orEmpty(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
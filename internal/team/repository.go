@@ -22,5 +22,6 @@ type Repository interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*Team, error)
 	GetByName(ctx context.Context, name string) (*Team, error)
 	List(ctx context.Context) ([]Team, error)
+	Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Team, error)
 	Delete(ctx context.Context, id uuid.UUID) error
 }
@@ -0,0 +1,101 @@
+package tier
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/cache"
+)
+
+// CachedRepository is a Repository that caches tiers looked up by ID or name
+// for a TTL, so handlers and background loops resolving the same tier for
+// every database do not query the platform database each time. Writes
+// through it clear the cache; writes made by other DAAP instances are seen
+// once cached entries expire. Lookups of missing tiers are not cached.
+type CachedRepository struct {
+	Repository
+	byID   *cache.TTL[uuid.UUID, Tier]
+	byName *cache.TTL[string, Tier]
+}
+
+// NewCachedRepository wraps repo with a lookup cache whose entries expire
+// after ttl.
+func NewCachedRepository(repo Repository, ttl time.Duration) *CachedRepository {
+	return &CachedRepository{
+		Repository: repo,
+		byID:       cache.New[uuid.UUID, Tier](ttl),
+		byName:     cache.New[string, Tier](ttl),
+	}
+}
+
+// GetByID returns a copy of the cached tier, or reads it from the wrapped
+// repository.
+func (r *CachedRepository) GetByID(ctx context.Context, id uuid.UUID) (*Tier, error) {
+	t, err := r.byID.Load(id, func() (Tier, error) {
+		return deref(r.Repository.GetByID(ctx, id))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(t), nil
+}
+
+// GetByName returns a copy of the cached tier, or reads it from the wrapped
+// repository.
+func (r *CachedRepository) GetByName(ctx context.Context, name string) (*Tier, error) {
+	t, err := r.byName.Load(name, func() (Tier, error) {
+		return deref(r.Repository.GetByName(ctx, name))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return clone(t), nil
+}
+
+// Create inserts a tier and clears the cache.
+func (r *CachedRepository) Create(ctx context.Context, t *Tier) error {
+	defer r.purge()
+	return r.Repository.Create(ctx, t)
+}
+
+// Update updates a tier and clears the cache.
+func (r *CachedRepository) Update(ctx context.Context, id uuid.UUID, fields UpdateFields) (*Tier, error) {
+	defer r.purge()
+	return r.Repository.Update(ctx, id, fields)
+}
+
+// Delete removes a tier and clears the cache.
+func (r *CachedRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	defer r.purge()
+	return r.Repository.Delete(ctx, id)
+}
+
+func (r *CachedRepository) purge() {
+	r.byID.Purge()
+	r.byName.Purge()
+}
+
+// deref copies a tier read from the wrapped repository before it is cached,
+// so the cache does not share its maps and slices with the repository.
+func deref(t *Tier, err error) (Tier, error) {
+	if err != nil {
+		return Tier{}, err
+	}
+	return *clone(*t), nil
+}
+
+// clone deep-copies a tier so callers cannot modify the cached value through
+// its pointer, map or slice fields.
+func clone(t Tier) *Tier {
+	if t.BlueprintID != nil {
+		id := *t.BlueprintID
+		t.BlueprintID = &id
+	}
+	t.Topology.NodeSelector = maps.Clone(t.Topology.NodeSelector)
+	t.DataClassifications = slices.Clone(t.DataClassifications)
+	return &t
+}
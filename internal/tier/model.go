@@ -1,6 +1,7 @@
 package tier
 
 import (
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -15,10 +16,74 @@ type Tier struct {
 	BlueprintName       string     // transient, populated via JOIN
 	DestructionStrategy string
 	BackupEnabled       bool
+	Namespace           string // namespace or template; empty means the global default
+	StorageAutoscaling  StorageAutoscaling
+	Topology            Topology
+	Disruption          Disruption
+	DataClassifications []string // classifications the tier may host; empty allows all
+	CreatedBy           string   // user name of the creator; empty for tiers created before it was recorded
+	UpdatedBy           string   // user name, or system actor, of the last change
 	CreatedAt           time.Time
 	UpdatedAt           time.Time
 }
 
+// Destruction strategies: what happens to the data of a tier's databases
+// when they are deleted.
+const (
+	DestructionFreeze     = "freeze"      // the data is kept
+	DestructionArchive    = "archive"     // a final backup is taken before the resources are deleted
+	DestructionHardDelete = "hard_delete" // the resources are deleted with the data
+)
+
+// AllowsClassification reports whether the tier may host a database with the
+// given data classification.
+func (t *Tier) AllowsClassification(c string) bool {
+	return len(t.DataClassifications) == 0 || slices.Contains(t.DataClassifications, c)
+}
+
+// Storage autoscaling defaults used when a tier does not set them.
+const (
+	DefaultStorageThresholdPercent = 80
+	DefaultStorageIncrementPercent = 20
+)
+
+// StorageAutoscaling is a tier's policy for growing database storage: when
+// used storage exceeds ThresholdPercent of capacity, capacity grows by
+// IncrementPercent, up to MaxSize.
+type StorageAutoscaling struct {
+	Enabled          bool
+	ThresholdPercent int
+	IncrementPercent int
+	MaxSize          string // Kubernetes quantity, e.g. "500Gi"; empty means no limit
+}
+
+// Zone spread modes of a tier's topology.
+const (
+	ZoneSpreadPreferred = "preferred"
+	ZoneSpreadRequired  = "required"
+)
+
+// Topology constrains where the instances of a tier's databases run, over
+// whatever their blueprint sets. ZoneSpread keeps the instances of a database
+// in different zones: when the scheduler can (ZoneSpreadPreferred) or always,
+// leaving instances pending otherwise (ZoneSpreadRequired). NodeSelector
+// restricts them to nodes with all of its labels. The zero value leaves
+// placement to the blueprint.
+type Topology struct {
+	ZoneSpread   string            // "", ZoneSpreadPreferred or ZoneSpreadRequired
+	NodeSelector map[string]string // node labels; empty allows any node
+}
+
+// Disruption sets how a tier's databases weather node drains and evictions.
+// MinAvailable, when positive, is the number of instances of a database that
+// voluntary disruptions must leave running; zero leaves it to the provider's
+// defaults. PriorityClassName is the priority class of the instances, so they
+// are scheduled, and kept when nodes run short, ahead of lower priority pods.
+type Disruption struct {
+	MinAvailable      int    // instances to keep through voluntary disruptions; 0 means the provider default
+	PriorityClassName string // Kubernetes PriorityClass; empty means the cluster default
+}
+
 // UpdateFields holds optional fields for a partial tier update.
 // Nil fields are not updated.
 type UpdateFields struct {
@@ -26,4 +91,13 @@ type UpdateFields struct {
 	BlueprintID         *uuid.UUID
 	DestructionStrategy *string
 	BackupEnabled       *bool
+	Namespace           *string
+	StorageAutoscaling  *StorageAutoscaling // replaces the whole policy
+	Topology            *Topology           // replaces the whole topology
+	Disruption          *Disruption         // replaces the whole disruption policy
+	DataClassifications []string            // non-nil replaces the list; empty allows all
+
+	// UpdatedBy, when set, records who made the update. It is not an update
+	// on its own.
+	UpdatedBy string
 }
@@ -0,0 +1,51 @@
+package tier
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	k8svalidation "k8s.io/apimachinery/pkg/util/validation"
+)
+
+// NamespaceData is the data available to a tier namespace template.
+type NamespaceData struct {
+	Team        string
+	Tier        string
+	Database    string
+	Environment string // empty when the database has no environment
+}
+
+// RenderNamespace renders a tier namespace, which is either a literal name
+// ("db-prod") or a Go template over NamespaceData ("db-{{ .Team }}").
+// It returns an error if the template is malformed or the result is not a
+// valid Kubernetes namespace name.
+func RenderNamespace(tmpl string, data NamespaceData) (string, error) {
+	t, err := template.New("namespace").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("parsing namespace template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("rendering namespace template: %w", err)
+	}
+
+	ns := strings.TrimSpace(buf.String())
+	if errs := k8svalidation.IsDNS1123Label(ns); len(errs) > 0 {
+		return "", fmt.Errorf("namespace %q is invalid: %s", ns, strings.Join(errs, "; "))
+	}
+	return ns, nil
+}
+
+// ResolveNamespace returns the namespace for the database described by data
+// on this tier, or fallback when the tier does not set a namespace. data.Tier
+// is set to the tier's name.
+func (t *Tier) ResolveNamespace(data NamespaceData, fallback string) (string, error) {
+	if strings.TrimSpace(t.Namespace) == "" {
+		return fallback, nil
+	}
+	data.Tier = t.Name
+	return RenderNamespace(t.Namespace, data)
+}
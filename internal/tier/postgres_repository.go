@@ -26,7 +26,10 @@ func NewPostgresRepository(pool *pgxpool.Pool) Repository {
 // with a LEFT JOIN on blueprints for the transient BlueprintName field.
 const allColumns = `t.id, t.name, t.description, t.blueprint_id,
 	COALESCE(b.name, ''), t.destruction_strategy, t.backup_enabled,
-	t.created_at, t.updated_at`
+	t.namespace, t.storage_autoscale_enabled, t.storage_autoscale_threshold,
+	t.storage_autoscale_increment, t.storage_autoscale_max_size,
+	t.zone_spread, t.node_selector, t.pdb_min_available, t.priority_class_name,
+	t.data_classifications, t.created_by, t.updated_by, t.created_at, t.updated_at`
 
 // fromClause is the common FROM + JOIN clause used by all read queries.
 const fromClause = `FROM tiers t LEFT JOIN blueprints b ON t.blueprint_id = b.id`
@@ -38,7 +41,11 @@ func scanTier(row pgx.Row) (*Tier, error) {
 		&t.ID, &t.Name, &t.Description,
 		&t.BlueprintID, &t.BlueprintName,
 		&t.DestructionStrategy, &t.BackupEnabled,
-		&t.CreatedAt, &t.UpdatedAt,
+		&t.Namespace, &t.StorageAutoscaling.Enabled, &t.StorageAutoscaling.ThresholdPercent,
+		&t.StorageAutoscaling.IncrementPercent, &t.StorageAutoscaling.MaxSize,
+		&t.Topology.ZoneSpread, &t.Topology.NodeSelector,
+		&t.Disruption.MinAvailable, &t.Disruption.PriorityClassName,
+		&t.DataClassifications, &t.CreatedBy, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -52,14 +59,22 @@ func scanTier(row pgx.Row) (*Tier, error) {
 // Create inserts a new tier record.
 func (r *PostgresRepository) Create(ctx context.Context, t *Tier) error {
 	query := `
-		INSERT INTO tiers (name, description, blueprint_id, destruction_strategy, backup_enabled)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO tiers (name, description, blueprint_id, destruction_strategy, backup_enabled, namespace,
+			storage_autoscale_enabled, storage_autoscale_threshold, storage_autoscale_increment, storage_autoscale_max_size,
+			zone_spread, node_selector, pdb_min_available, priority_class_name, data_classifications,
+			created_by, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $16)
 		RETURNING id, created_at, updated_at`
 
 	var id uuid.UUID
 	err := r.pool.QueryRow(ctx, query,
 		t.Name, t.Description, t.BlueprintID,
-		t.DestructionStrategy, t.BackupEnabled,
+		t.DestructionStrategy, t.BackupEnabled, t.Namespace,
+		t.StorageAutoscaling.Enabled, t.StorageAutoscaling.ThresholdPercent,
+		t.StorageAutoscaling.IncrementPercent, t.StorageAutoscaling.MaxSize,
+		t.Topology.ZoneSpread, orEmptyMap(t.Topology.NodeSelector),
+		t.Disruption.MinAvailable, t.Disruption.PriorityClassName,
+		orEmpty(t.DataClassifications), t.CreatedBy,
 	).Scan(&id, &t.CreatedAt, &t.UpdatedAt)
 	if err != nil {
 		var pgErr *pgconn.PgError
@@ -109,7 +124,11 @@ func (r *PostgresRepository) List(ctx context.Context) ([]Tier, error) {
 			&t.ID, &t.Name, &t.Description,
 			&t.BlueprintID, &t.BlueprintName,
 			&t.DestructionStrategy, &t.BackupEnabled,
-			&t.CreatedAt, &t.UpdatedAt,
+			&t.Namespace, &t.StorageAutoscaling.Enabled, &t.StorageAutoscaling.ThresholdPercent,
+			&t.StorageAutoscaling.IncrementPercent, &t.StorageAutoscaling.MaxSize,
+			&t.Topology.ZoneSpread, &t.Topology.NodeSelector,
+			&t.Disruption.MinAvailable, &t.Disruption.PriorityClassName,
+			&t.DataClassifications, &t.CreatedBy, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning tier row: %w", err)
@@ -153,11 +172,49 @@ func (r *PostgresRepository) Update(ctx context.Context, id uuid.UUID, fields Up
 		args = append(args, *fields.BackupEnabled)
 		argIdx++
 	}
+	if fields.Namespace != nil {
+		setClauses = append(setClauses, fmt.Sprintf("namespace = $%d", argIdx))
+		args = append(args, *fields.Namespace)
+		argIdx++
+	}
+	if sa := fields.StorageAutoscaling; sa != nil {
+		setClauses = append(setClauses,
+			fmt.Sprintf("storage_autoscale_enabled = $%d", argIdx),
+			fmt.Sprintf("storage_autoscale_threshold = $%d", argIdx+1),
+			fmt.Sprintf("storage_autoscale_increment = $%d", argIdx+2),
+			fmt.Sprintf("storage_autoscale_max_size = $%d", argIdx+3))
+		args = append(args, sa.Enabled, sa.ThresholdPercent, sa.IncrementPercent, sa.MaxSize)
+		argIdx += 4
+	}
+	if topo := fields.Topology; topo != nil {
+		setClauses = append(setClauses,
+			fmt.Sprintf("zone_spread = $%d", argIdx),
+			fmt.Sprintf("node_selector = $%d", argIdx+1))
+		args = append(args, topo.ZoneSpread, orEmptyMap(topo.NodeSelector))
+		argIdx += 2
+	}
+	if d := fields.Disruption; d != nil {
+		setClauses = append(setClauses,
+			fmt.Sprintf("pdb_min_available = $%d", argIdx),
+			fmt.Sprintf("priority_class_name = $%d", argIdx+1))
+		args = append(args, d.MinAvailable, d.PriorityClassName)
+		argIdx += 2
+	}
+	if fields.DataClassifications != nil {
+		setClauses = append(setClauses, fmt.Sprintf("data_classifications = $%d", argIdx))
+		args = append(args, fields.DataClassifications)
+		argIdx++
+	}
 
 	if len(setClauses) == 0 {
 		return r.GetByID(ctx, id)
 	}
 
+	if fields.UpdatedBy != "" {
+		setClauses = append(setClauses, fmt.Sprintf("updated_by = $%d", argIdx))
+		args = append(args, fields.UpdatedBy)
+		argIdx++
+	}
 	setClauses = append(setClauses, "updated_at = NOW()")
 
 	args = append(args, id)
@@ -207,3 +264,21 @@ func (r *PostgresRepository) Delete(ctx context.Context, id uuid.UUID) error {
 
 	return nil
 }
+
+// orEmpty returns s, or an empty slice when s is nil, so NOT NULL array
+// columns get '{}' rather than NULL.
+func orEmpty(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+// orEmptyMap returns m, or an empty map when m is nil, so NOT NULL JSONB
+// columns get '{}' rather than null.
+func orEmptyMap(m map[string]string) map[string]string {
+	if m == nil {
+		return map[string]string{}
+	}
+	return m
+}
@@ -0,0 +1 @@
+ALTER TABLE tiers DROP COLUMN IF EXISTS namespace;
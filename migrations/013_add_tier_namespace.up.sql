@@ -0,0 +1,2 @@
+ALTER TABLE tiers
+    ADD COLUMN namespace VARCHAR(255) NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS observed_generation,
+    DROP COLUMN IF EXISTS generation;
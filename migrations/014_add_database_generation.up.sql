@@ -0,0 +1,6 @@
+ALTER TABLE databases
+    ADD COLUMN generation BIGINT NOT NULL DEFAULT 1,
+    ADD COLUMN observed_generation BIGINT NOT NULL DEFAULT 0;
+
+-- Databases that already reached a terminal state have been acted upon.
+UPDATE databases SET observed_generation = generation WHERE status IN ('ready', 'error');
@@ -0,0 +1,15 @@
+CREATE TABLE database_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    from_status VARCHAR(20),
+    to_status VARCHAR(20) NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_status_history_database ON database_status_history (database_id, changed_at);
+CREATE INDEX idx_database_status_history_to_status ON database_status_history (to_status);
+
+-- Seed the creation transition for existing databases. Earlier transitions
+-- were never recorded, so they do not contribute to provisioning durations.
+INSERT INTO database_status_history (database_id, from_status, to_status, changed_at)
+SELECT id, NULL, 'provisioning', created_at FROM databases;
@@ -0,0 +1 @@
+ALTER TABLE databases ADD COLUMN status_reason TEXT;
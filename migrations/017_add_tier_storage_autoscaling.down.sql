@@ -0,0 +1,5 @@
+ALTER TABLE tiers
+    DROP COLUMN IF EXISTS storage_autoscale_enabled,
+    DROP COLUMN IF EXISTS storage_autoscale_threshold,
+    DROP COLUMN IF EXISTS storage_autoscale_increment,
+    DROP COLUMN IF EXISTS storage_autoscale_max_size;
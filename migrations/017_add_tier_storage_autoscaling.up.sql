@@ -0,0 +1,5 @@
+ALTER TABLE tiers
+    ADD COLUMN storage_autoscale_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN storage_autoscale_threshold INT NOT NULL DEFAULT 80,
+    ADD COLUMN storage_autoscale_increment INT NOT NULL DEFAULT 20,
+    ADD COLUMN storage_autoscale_max_size TEXT NOT NULL DEFAULT '';
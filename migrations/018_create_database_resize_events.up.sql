@@ -0,0 +1,10 @@
+CREATE TABLE database_resize_events (
+    id BIGSERIAL PRIMARY KEY,
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    from_bytes BIGINT NOT NULL,
+    to_bytes BIGINT NOT NULL,
+    used_bytes BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_resize_events_database ON database_resize_events (database_id, created_at);
@@ -0,0 +1,12 @@
+CREATE TABLE database_usage_samples (
+    id BIGSERIAL PRIMARY KEY,
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    cpu_request_millis BIGINT NOT NULL,
+    cpu_used_millis BIGINT NOT NULL,
+    memory_request_bytes BIGINT NOT NULL,
+    memory_used_bytes BIGINT NOT NULL,
+    collected_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_usage_samples_database ON database_usage_samples (database_id, collected_at);
+CREATE INDEX idx_database_usage_samples_collected_at ON database_usage_samples (collected_at);
@@ -0,0 +1,13 @@
+CREATE TABLE database_tier_changes (
+    id BIGSERIAL PRIMARY KEY,
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    from_tier TEXT NOT NULL,
+    to_tier TEXT NOT NULL,
+    actor TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL CHECK (status IN ('applied', 'failed')),
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_tier_changes_database ON database_tier_changes (database_id, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS rollout_targets;
+DROP TABLE IF EXISTS rollouts;
@@ -0,0 +1,31 @@
+CREATE TABLE rollouts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tier_id UUID NOT NULL REFERENCES tiers(id) ON DELETE CASCADE,
+    from_blueprint_id UUID NOT NULL,
+    from_blueprint_name TEXT NOT NULL,
+    to_blueprint_id UUID NOT NULL,
+    to_blueprint_name TEXT NOT NULL,
+    status TEXT NOT NULL
+        CHECK (status IN ('in_progress', 'paused', 'completed', 'rolling_back', 'rolled_back')),
+    current_batch INT NOT NULL DEFAULT 0,
+    batch_count INT NOT NULL,
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_rollouts_tier ON rollouts (tier_id, created_at);
+CREATE INDEX idx_rollouts_status ON rollouts (status);
+
+CREATE TABLE rollout_targets (
+    rollout_id UUID NOT NULL REFERENCES rollouts(id) ON DELETE CASCADE,
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    database_name TEXT NOT NULL,
+    batch INT NOT NULL,
+    status TEXT NOT NULL
+        CHECK (status IN ('pending', 'applied', 'healthy', 'failed', 'skipped', 'rolled_back')),
+    error TEXT NOT NULL DEFAULT '',
+    applied_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (rollout_id, database_id)
+);
@@ -0,0 +1,3 @@
+ALTER TABLE users DROP COLUMN IF EXISTS freeze_override;
+
+DROP TABLE IF EXISTS change_freezes;
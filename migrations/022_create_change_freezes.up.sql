@@ -0,0 +1,14 @@
+CREATE TABLE change_freezes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    team_id UUID REFERENCES teams(id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_change_freezes_ends_at ON change_freezes (ends_at);
+
+ALTER TABLE users ADD COLUMN freeze_override BOOLEAN NOT NULL DEFAULT false;
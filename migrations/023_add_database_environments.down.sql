@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS database_promotions;
+
+DROP INDEX IF EXISTS idx_databases_promoted_from;
+ALTER TABLE databases DROP COLUMN IF EXISTS promoted_from_id;
+ALTER TABLE databases DROP COLUMN IF EXISTS environment;
@@ -0,0 +1,22 @@
+ALTER TABLE databases ADD COLUMN environment VARCHAR(31) NOT NULL DEFAULT '';
+ALTER TABLE databases ADD COLUMN promoted_from_id UUID REFERENCES databases(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_databases_promoted_from ON databases (promoted_from_id) WHERE promoted_from_id IS NOT NULL;
+
+CREATE TABLE database_promotions (
+    id BIGSERIAL PRIMARY KEY,
+    source_database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    source_name TEXT NOT NULL,
+    target_database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    target_name TEXT NOT NULL,
+    from_environment TEXT NOT NULL,
+    to_environment TEXT NOT NULL,
+    tier TEXT NOT NULL,
+    blueprint TEXT NOT NULL DEFAULT '',
+    action TEXT NOT NULL CHECK (action IN ('created', 'updated')),
+    actor TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_promotions_source ON database_promotions (source_database_id, created_at);
+CREATE INDEX idx_database_promotions_target ON database_promotions (target_database_id, created_at);
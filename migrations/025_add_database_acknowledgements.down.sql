@@ -0,0 +1,5 @@
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS ack_until,
+    DROP COLUMN IF EXISTS acked_at,
+    DROP COLUMN IF EXISTS ack_comment,
+    DROP COLUMN IF EXISTS ack_by;
@@ -0,0 +1,5 @@
+ALTER TABLE databases
+    ADD COLUMN ack_by TEXT,
+    ADD COLUMN ack_comment TEXT,
+    ADD COLUMN acked_at TIMESTAMPTZ,
+    ADD COLUMN ack_until TIMESTAMPTZ;
@@ -0,0 +1,15 @@
+CREATE TABLE user_invitations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    team_id UUID NOT NULL REFERENCES teams(id) ON DELETE CASCADE,
+    email VARCHAR(320) NOT NULL,
+    freeze_override BOOLEAN NOT NULL DEFAULT false,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    accepted_at TIMESTAMPTZ,
+    user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_invitations_team_id ON user_invitations (team_id);
@@ -0,0 +1,10 @@
+CREATE TABLE database_locks (
+    database_id UUID PRIMARY KEY REFERENCES databases(id) ON DELETE CASCADE,
+    id UUID NOT NULL DEFAULT gen_random_uuid(),
+    operation VARCHAR(63) NOT NULL,
+    holder TEXT NOT NULL,
+    instance TEXT NOT NULL,
+    request_id TEXT NOT NULL DEFAULT '',
+    acquired_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
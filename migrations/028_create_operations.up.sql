@@ -0,0 +1,21 @@
+CREATE TABLE operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(63) NOT NULL,
+    status TEXT NOT NULL
+        CHECK (status IN ('running', 'succeeded', 'failed')),
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    team_id UUID NOT NULL,
+    progress INT NOT NULL DEFAULT 0 CHECK (progress BETWEEN 0 AND 100),
+    message TEXT NOT NULL DEFAULT '',
+    result JSONB,
+    error_code VARCHAR(63),
+    error_message TEXT,
+    request_id TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_operations_database ON operations (database_id, created_at);
+CREATE INDEX idx_operations_running ON operations (database_id) WHERE status = 'running';
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_databases_active_created;
+DROP TRIGGER IF EXISTS tiers_propagate_name ON tiers;
+DROP FUNCTION IF EXISTS tiers_propagate_name();
+DROP TRIGGER IF EXISTS teams_propagate_name ON teams;
+DROP FUNCTION IF EXISTS teams_propagate_name();
+DROP TRIGGER IF EXISTS databases_set_names ON databases;
+DROP FUNCTION IF EXISTS databases_set_names();
+ALTER TABLE databases DROP COLUMN IF EXISTS tier_name;
+ALTER TABLE databases DROP COLUMN IF EXISTS owner_team_name;
@@ -0,0 +1,46 @@
+-- The owner team and tier names are copied onto databases so the list and
+-- lookup queries read a single table. Triggers keep the copies current.
+ALTER TABLE databases ADD COLUMN owner_team_name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE databases ADD COLUMN tier_name VARCHAR(255) NOT NULL DEFAULT '';
+
+UPDATE databases d SET owner_team_name = t.name FROM teams t WHERE t.id = d.owner_team_id;
+UPDATE databases d SET tier_name = tr.name FROM tiers tr WHERE tr.id = d.tier_id;
+
+CREATE FUNCTION databases_set_names() RETURNS trigger AS $$
+BEGIN
+    NEW.owner_team_name := COALESCE((SELECT name FROM teams WHERE id = NEW.owner_team_id), '');
+    NEW.tier_name := COALESCE((SELECT name FROM tiers WHERE id = NEW.tier_id), '');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER databases_set_names
+    BEFORE INSERT OR UPDATE OF owner_team_id, tier_id ON databases
+    FOR EACH ROW EXECUTE FUNCTION databases_set_names();
+
+CREATE FUNCTION teams_propagate_name() RETURNS trigger AS $$
+BEGIN
+    UPDATE databases SET owner_team_name = NEW.name WHERE owner_team_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER teams_propagate_name
+    AFTER UPDATE OF name ON teams
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION teams_propagate_name();
+
+CREATE FUNCTION tiers_propagate_name() RETURNS trigger AS $$
+BEGIN
+    UPDATE databases SET tier_name = NEW.name WHERE tier_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tiers_propagate_name
+    AFTER UPDATE OF name ON tiers
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION tiers_propagate_name();
+
+-- Serves the list query's ORDER BY created_at DESC LIMIT without a sort.
+CREATE INDEX idx_databases_active_created ON databases (created_at DESC) WHERE deleted_at IS NULL;
@@ -0,0 +1,5 @@
+-- Deleted databases stay visible as deprovisioning until their provider
+-- confirms their resources are gone.
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'deprovisioning', 'deleting', 'deleted'));
@@ -0,0 +1,5 @@
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS replication_lag_ms,
+    DROP COLUMN IF EXISTS current_primary,
+    DROP COLUMN IF EXISTS instances_ready,
+    DROP COLUMN IF EXISTS instances_total;
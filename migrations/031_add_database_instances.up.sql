@@ -0,0 +1,5 @@
+ALTER TABLE databases
+    ADD COLUMN instances_total INTEGER,
+    ADD COLUMN instances_ready INTEGER,
+    ADD COLUMN current_primary TEXT,
+    ADD COLUMN replication_lag_ms BIGINT;
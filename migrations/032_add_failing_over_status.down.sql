@@ -0,0 +1,4 @@
+UPDATE databases SET status = 'ready' WHERE status = 'failing_over';
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'deprovisioning', 'deleting', 'deleted'));
@@ -0,0 +1,5 @@
+-- A database is failing_over while its provider switches the primary to
+-- another instance.
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'failing_over', 'deprovisioning', 'deleting', 'deleted'));
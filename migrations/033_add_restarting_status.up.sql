@@ -0,0 +1,5 @@
+-- A database is restarting while its provider restarts its instances one at
+-- a time.
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'failing_over', 'restarting', 'deprovisioning', 'deleting', 'deleted'));
@@ -0,0 +1,18 @@
+-- The resolved spec (tier settings and blueprint) each database's resources
+-- were last applied with, to show what a re-apply would change.
+CREATE TABLE database_specs (
+    database_id UUID PRIMARY KEY REFERENCES databases(id) ON DELETE CASCADE,
+    tier_id UUID NOT NULL,
+    tier_name VARCHAR(63) NOT NULL,
+    destruction_strategy VARCHAR(20) NOT NULL,
+    backup_enabled BOOLEAN NOT NULL,
+    storage_autoscale_enabled BOOLEAN NOT NULL,
+    storage_autoscale_threshold INT NOT NULL,
+    storage_autoscale_increment INT NOT NULL,
+    storage_autoscale_max_size TEXT NOT NULL,
+    blueprint_id UUID NOT NULL,
+    blueprint_name VARCHAR(63) NOT NULL,
+    provider VARCHAR(63) NOT NULL,
+    manifests TEXT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,3 @@
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS conditions,
+    DROP COLUMN IF EXISTS operator_version;
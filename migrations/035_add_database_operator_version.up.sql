@@ -0,0 +1,5 @@
+-- The version of the operator a database's resources were provisioned under,
+-- and conditions such as needsReview raised when that version changes.
+ALTER TABLE databases
+    ADD COLUMN operator_version TEXT,
+    ADD COLUMN conditions JSONB NOT NULL DEFAULT '[]';
@@ -0,0 +1,18 @@
+DROP TRIGGER IF EXISTS teams_propagate_metadata ON teams;
+DROP FUNCTION IF EXISTS teams_propagate_metadata();
+
+CREATE OR REPLACE FUNCTION databases_set_names() RETURNS trigger AS $$
+BEGIN
+    NEW.owner_team_name := COALESCE((SELECT name FROM teams WHERE id = NEW.owner_team_id), '');
+    NEW.tier_name := COALESCE((SELECT name FROM tiers WHERE id = NEW.tier_id), '');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS owner_team_annotations,
+    DROP COLUMN IF EXISTS owner_team_labels;
+
+ALTER TABLE teams
+    DROP COLUMN IF EXISTS annotations,
+    DROP COLUMN IF EXISTS labels;
@@ -0,0 +1,37 @@
+-- Default labels and annotations teams set on the resources of their
+-- databases. Like the owner team name, they are copied onto databases so
+-- reads stay on a single table, and triggers keep the copies current.
+ALTER TABLE teams
+    ADD COLUMN labels JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN annotations JSONB NOT NULL DEFAULT '{}';
+
+ALTER TABLE databases
+    ADD COLUMN owner_team_labels JSONB NOT NULL DEFAULT '{}',
+    ADD COLUMN owner_team_annotations JSONB NOT NULL DEFAULT '{}';
+
+CREATE OR REPLACE FUNCTION databases_set_names() RETURNS trigger AS $$
+BEGIN
+    SELECT name, labels, annotations
+      INTO NEW.owner_team_name, NEW.owner_team_labels, NEW.owner_team_annotations
+      FROM teams WHERE id = NEW.owner_team_id;
+    NEW.owner_team_name := COALESCE(NEW.owner_team_name, '');
+    NEW.owner_team_labels := COALESCE(NEW.owner_team_labels, '{}');
+    NEW.owner_team_annotations := COALESCE(NEW.owner_team_annotations, '{}');
+    NEW.tier_name := COALESCE((SELECT name FROM tiers WHERE id = NEW.tier_id), '');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION teams_propagate_metadata() RETURNS trigger AS $$
+BEGIN
+    UPDATE databases
+       SET owner_team_labels = NEW.labels, owner_team_annotations = NEW.annotations
+     WHERE owner_team_id = NEW.id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER teams_propagate_metadata
+    AFTER UPDATE OF labels, annotations ON teams
+    FOR EACH ROW WHEN (OLD.labels IS DISTINCT FROM NEW.labels OR OLD.annotations IS DISTINCT FROM NEW.annotations)
+    EXECUTE FUNCTION teams_propagate_metadata();
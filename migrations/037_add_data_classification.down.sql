@@ -0,0 +1,5 @@
+ALTER TABLE tiers
+    DROP COLUMN IF EXISTS data_classifications;
+
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS data_classification;
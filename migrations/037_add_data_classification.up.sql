@@ -0,0 +1,8 @@
+-- The kind of data a database holds, and the classifications each tier may
+-- host (empty allows all).
+ALTER TABLE databases
+    ADD COLUMN data_classification VARCHAR(20) NOT NULL DEFAULT 'internal'
+        CHECK (data_classification IN ('public', 'internal', 'confidential', 'restricted'));
+
+ALTER TABLE tiers
+    ADD COLUMN data_classifications TEXT[] NOT NULL DEFAULT '{}';
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS blueprint_versions;
+
+ALTER TABLE blueprints
+    DROP COLUMN IF EXISTS version,
+    DROP COLUMN IF EXISTS description;
@@ -0,0 +1,17 @@
+-- Blueprints become editable: a description, and a version number that
+-- grows with every change to the manifests. Every revision of the manifests
+-- is kept, so changes can be reviewed after the fact.
+ALTER TABLE blueprints
+    ADD COLUMN description TEXT NOT NULL DEFAULT '',
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+CREATE TABLE blueprint_versions (
+    blueprint_id UUID NOT NULL REFERENCES blueprints(id) ON DELETE CASCADE,
+    version INTEGER NOT NULL,
+    manifests TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blueprint_id, version)
+);
+
+INSERT INTO blueprint_versions (blueprint_id, version, manifests, created_at)
+SELECT id, 1, manifests, created_at FROM blueprints;
@@ -0,0 +1,3 @@
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS reconciliation_paused_until,
+    DROP COLUMN IF EXISTS reconciliation_paused_by;
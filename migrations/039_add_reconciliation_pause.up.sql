@@ -0,0 +1,6 @@
+-- A platform user may pause the reconciliation of a database, e.g. while its
+-- cluster is adjusted by hand during an incident. The pause expires at
+-- reconciliation_paused_until so it is not forgotten.
+ALTER TABLE databases
+    ADD COLUMN reconciliation_paused_by TEXT,
+    ADD COLUMN reconciliation_paused_until TIMESTAMPTZ;
@@ -0,0 +1,3 @@
+-- Human-readable detail of a database's status as reported by its provider,
+-- e.g. why its cluster failed, alongside the machine-readable status_reason.
+ALTER TABLE databases ADD COLUMN status_message TEXT;
@@ -0,0 +1,4 @@
+ALTER TABLE teams DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
+ALTER TABLE blueprints DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
+ALTER TABLE tiers DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
+ALTER TABLE databases DROP COLUMN IF EXISTS updated_by, DROP COLUMN IF EXISTS created_by;
@@ -0,0 +1,18 @@
+-- Who created and last changed each resource: the user name of the request
+-- identity, or a system actor such as system:reconciler. Rows that predate
+-- these columns are left empty.
+ALTER TABLE databases
+    ADD COLUMN created_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN updated_by TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE tiers
+    ADD COLUMN created_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN updated_by TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE blueprints
+    ADD COLUMN created_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN updated_by TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE teams
+    ADD COLUMN created_by TEXT NOT NULL DEFAULT '',
+    ADD COLUMN updated_by TEXT NOT NULL DEFAULT '';
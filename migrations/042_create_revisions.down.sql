@@ -0,0 +1,5 @@
+DROP TRIGGER IF EXISTS tiers_record_revision ON tiers;
+DROP TRIGGER IF EXISTS databases_record_revision ON databases;
+DROP TABLE IF EXISTS revisions;
+DROP FUNCTION IF EXISTS revisions_immutable();
+DROP FUNCTION IF EXISTS record_revision();
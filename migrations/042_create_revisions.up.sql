@@ -0,0 +1,88 @@
+-- Every change to a database or tier is kept as a revision: a snapshot of
+-- the row after the change, so its state at any point in time can be
+-- answered after the fact. Triggers record the revisions, whichever code
+-- path writes the row, and the table only ever grows.
+CREATE TABLE revisions (
+    resource_type VARCHAR(20) NOT NULL,
+    resource_id UUID NOT NULL,
+    revision INTEGER NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    snapshot JSONB NOT NULL,
+    changed_by TEXT NOT NULL DEFAULT '',
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (resource_type, resource_id, revision)
+);
+
+-- record_revision(kind, excluded columns...) appends a revision of the row.
+-- Updates that only touch excluded columns, such as updated_at, record
+-- nothing. A soft delete is recorded as a delete.
+CREATE FUNCTION record_revision() RETURNS trigger AS $$
+DECLARE
+    row_snapshot JSONB;
+    latest JSONB;
+    op TEXT;
+    actor TEXT := '';
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_snapshot := to_jsonb(OLD) - TG_ARGV[1:];
+        op := 'delete';
+    ELSE
+        row_snapshot := to_jsonb(NEW) - TG_ARGV[1:];
+        actor := COALESCE(to_jsonb(NEW) ->> 'updated_by', '');
+        op := CASE
+            WHEN TG_OP = 'INSERT' THEN 'create'
+            WHEN to_jsonb(OLD) ->> 'deleted_at' IS NULL AND to_jsonb(NEW) ->> 'deleted_at' IS NOT NULL THEN 'delete'
+            ELSE 'update'
+        END;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT snapshot INTO latest FROM revisions
+        WHERE resource_type = TG_ARGV[0] AND resource_id = (row_snapshot ->> 'id')::uuid
+        ORDER BY revision DESC LIMIT 1;
+        IF latest = row_snapshot THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    INSERT INTO revisions (resource_type, resource_id, revision, operation, snapshot, changed_by)
+    SELECT TG_ARGV[0], (row_snapshot ->> 'id')::uuid, COALESCE(MAX(revision), 0) + 1, op, row_snapshot, actor
+    FROM revisions
+    WHERE resource_type = TG_ARGV[0] AND resource_id = (row_snapshot ->> 'id')::uuid;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- The names, labels and annotations copied from the owner team and tier
+-- follow their owner_team_id and tier_id, and the replication lag changes
+-- on every reconciler pass; none of them makes a revision.
+CREATE TRIGGER databases_record_revision
+    AFTER INSERT OR UPDATE ON databases
+    FOR EACH ROW EXECUTE FUNCTION record_revision('database', 'updated_at', 'replication_lag_ms',
+        'owner_team_name', 'owner_team_labels', 'owner_team_annotations', 'tier_name');
+
+CREATE TRIGGER tiers_record_revision
+    AFTER INSERT OR UPDATE OR DELETE ON tiers
+    FOR EACH ROW EXECUTE FUNCTION record_revision('tier', 'updated_at');
+
+CREATE FUNCTION revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'revisions cannot be changed or deleted';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER revisions_immutable
+    BEFORE UPDATE OR DELETE ON revisions
+    FOR EACH ROW EXECUTE FUNCTION revisions_immutable();
+
+-- Rows that exist already start their history with a baseline revision of
+-- their current state; earlier changes were never recorded.
+INSERT INTO revisions (resource_type, resource_id, revision, operation, snapshot, changed_by, changed_at)
+SELECT 'database', id, 1, 'baseline',
+    to_jsonb(d) - ARRAY['updated_at', 'replication_lag_ms', 'owner_team_name', 'owner_team_labels', 'owner_team_annotations', 'tier_name'],
+    updated_by, updated_at
+FROM databases d;
+
+INSERT INTO revisions (resource_type, resource_id, revision, operation, snapshot, changed_by, changed_at)
+SELECT 'tier', id, 1, 'baseline', to_jsonb(t) - 'updated_at', updated_by, updated_at
+FROM tiers t;
@@ -0,0 +1,4 @@
+-- SQL cannot decompress gzip: manifests written compressed are lost.
+ALTER TABLE blueprint_versions DROP COLUMN IF EXISTS manifests_gz;
+
+ALTER TABLE blueprints DROP COLUMN IF EXISTS manifests_gz;
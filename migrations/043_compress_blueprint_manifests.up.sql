@@ -0,0 +1,6 @@
+-- Blueprint manifests are stored gzip-compressed in manifests_gz, with
+-- manifests left empty. Rows written before keep their text in manifests, a
+-- blueprint until it is next updated; the repository reads either.
+ALTER TABLE blueprints ADD COLUMN manifests_gz BYTEA;
+
+ALTER TABLE blueprint_versions ADD COLUMN manifests_gz BYTEA;
@@ -0,0 +1 @@
+ALTER TABLE blueprints DROP COLUMN IF EXISTS documents;
@@ -0,0 +1,4 @@
+-- The documents of a blueprint's manifests, parsed when they are saved: the
+-- index, apiVersion, kind, name and namespace of each. NULL for blueprints
+-- saved before, whose documents the repository parses when reading them.
+ALTER TABLE blueprints ADD COLUMN documents JSONB;
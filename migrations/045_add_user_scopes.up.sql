@@ -0,0 +1,3 @@
+-- Scopes restrict what a user's API key may do, e.g. read or
+-- databases:create; a key without scopes is limited by its team's role only.
+ALTER TABLE users ADD COLUMN scopes TEXT[] NOT NULL DEFAULT '{}';
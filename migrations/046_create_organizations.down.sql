@@ -0,0 +1,10 @@
+DELETE FROM users WHERE organization_id IS NOT NULL;
+
+ALTER TABLE users DROP CONSTRAINT chk_users_team_or_organization;
+ALTER TABLE users DROP CONSTRAINT chk_users_superuser_team;
+ALTER TABLE users ADD CONSTRAINT chk_users_superuser_team
+  CHECK (is_superuser = TRUE OR team_id IS NOT NULL);
+
+ALTER TABLE users DROP COLUMN organization_id;
+ALTER TABLE teams DROP COLUMN organization_id;
+DROP TABLE organizations;
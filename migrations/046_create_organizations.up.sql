@@ -0,0 +1,26 @@
+-- Organizations group teams into business units sharing the deployment.
+-- Each has its own superadmins (users with an organization but no team) and
+-- an optional quota on its active databases (0 for none).
+CREATE TABLE organizations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    max_databases INTEGER NOT NULL DEFAULT 0 CHECK (max_databases >= 0),
+    created_by TEXT NOT NULL DEFAULT '',
+    updated_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE teams ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+CREATE INDEX idx_teams_organization_id ON teams (organization_id);
+
+-- An organization's superadmins go with it.
+ALTER TABLE users ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE CASCADE;
+CREATE INDEX idx_users_organization_id ON users (organization_id);
+
+-- Non-superusers have a team, or administer an organization; never both.
+ALTER TABLE users DROP CONSTRAINT chk_users_superuser_team;
+ALTER TABLE users ADD CONSTRAINT chk_users_superuser_team
+  CHECK (is_superuser = TRUE OR team_id IS NOT NULL OR organization_id IS NOT NULL);
+ALTER TABLE users ADD CONSTRAINT chk_users_team_or_organization
+  CHECK (team_id IS NULL OR organization_id IS NULL);
@@ -0,0 +1,3 @@
+ALTER TABLE blueprints DROP CONSTRAINT chk_blueprints_single_scope;
+ALTER TABLE blueprints DROP COLUMN organization_id;
+ALTER TABLE blueprints DROP COLUMN team_id;
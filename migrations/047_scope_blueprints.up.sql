@@ -0,0 +1,10 @@
+-- Blueprints can be scoped to a team or an organization, so experimental
+-- blueprints are only visible to their members. Unscoped blueprints stay
+-- global. Teams and organizations keep their blueprints: they cannot be
+-- deleted while they have any.
+ALTER TABLE blueprints ADD COLUMN team_id UUID REFERENCES teams(id) ON DELETE RESTRICT;
+ALTER TABLE blueprints ADD COLUMN organization_id UUID REFERENCES organizations(id) ON DELETE RESTRICT;
+ALTER TABLE blueprints ADD CONSTRAINT chk_blueprints_single_scope
+  CHECK (team_id IS NULL OR organization_id IS NULL);
+CREATE INDEX idx_blueprints_team_id ON blueprints (team_id);
+CREATE INDEX idx_blueprints_organization_id ON blueprints (organization_id);
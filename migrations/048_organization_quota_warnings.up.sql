@@ -0,0 +1,4 @@
+-- Creating databases warns once an organization's active databases reach
+-- this share of its quota (0 for no warning).
+ALTER TABLE organizations ADD COLUMN quota_warning_percent INTEGER NOT NULL DEFAULT 80
+  CHECK (quota_warning_percent BETWEEN 0 AND 100);
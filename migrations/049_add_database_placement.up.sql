@@ -0,0 +1,4 @@
+-- How the placement engine chose a database's namespace: the namespaces it
+-- considered, their load and the reason for its pick. NULL when the
+-- namespace came from the request, the tier or NAMESPACE.
+ALTER TABLE databases ADD COLUMN placement JSONB;
@@ -0,0 +1,6 @@
+ALTER TABLE database_specs
+    DROP COLUMN IF EXISTS zone_spread,
+    DROP COLUMN IF EXISTS node_selector;
+ALTER TABLE tiers
+    DROP COLUMN IF EXISTS zone_spread,
+    DROP COLUMN IF EXISTS node_selector;
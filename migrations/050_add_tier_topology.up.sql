@@ -0,0 +1,10 @@
+-- Where the instances of a tier's databases run: spread across zones and
+-- restricted to nodes with the labels of node_selector. The spec each
+-- database was applied with records them too.
+ALTER TABLE tiers
+    ADD COLUMN zone_spread TEXT NOT NULL DEFAULT ''
+        CHECK (zone_spread IN ('', 'preferred', 'required')),
+    ADD COLUMN node_selector JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE database_specs
+    ADD COLUMN zone_spread TEXT NOT NULL DEFAULT '',
+    ADD COLUMN node_selector JSONB NOT NULL DEFAULT '{}';
@@ -0,0 +1,6 @@
+ALTER TABLE database_specs
+    DROP COLUMN IF EXISTS pdb_min_available,
+    DROP COLUMN IF EXISTS priority_class_name;
+ALTER TABLE tiers
+    DROP COLUMN IF EXISTS pdb_min_available,
+    DROP COLUMN IF EXISTS priority_class_name;
@@ -0,0 +1,10 @@
+-- How a tier's databases weather node drains and evictions: the instances a
+-- pod disruption budget keeps running, and their priority class. The spec
+-- each database was applied with records them too.
+ALTER TABLE tiers
+    ADD COLUMN pdb_min_available INTEGER NOT NULL DEFAULT 0
+        CHECK (pdb_min_available >= 0),
+    ADD COLUMN priority_class_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE database_specs
+    ADD COLUMN pdb_min_available INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN priority_class_name TEXT NOT NULL DEFAULT '';
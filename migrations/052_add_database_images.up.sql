@@ -0,0 +1,4 @@
+-- The images a database's blueprint references, as the image policy
+-- resolved them when the database was provisioned: mirrored and pinned to
+-- a digest. Empty for databases provisioned without an image policy.
+ALTER TABLE databases ADD COLUMN images JSONB NOT NULL DEFAULT '[]';
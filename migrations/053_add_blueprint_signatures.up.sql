@@ -0,0 +1,4 @@
+-- The signature DAAP made of a blueprint's content when it was last saved
+-- through the API, so that content changed in this database directly is
+-- refused at provisioning. Empty for blueprints saved without a signing key.
+ALTER TABLE blueprints ADD COLUMN signature TEXT NOT NULL DEFAULT '';
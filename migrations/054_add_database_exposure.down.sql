@@ -0,0 +1,4 @@
+ALTER TABLE databases
+    DROP COLUMN IF EXISTS external_host,
+    DROP COLUMN IF EXISTS exposure_allowed_ranges,
+    DROP COLUMN IF EXISTS exposure;
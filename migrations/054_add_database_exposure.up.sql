@@ -0,0 +1,9 @@
+-- How a database is reachable from outside the cluster: only from inside
+-- it, or through an internal or internet-facing load balancer accepting the
+-- allowed source ranges. external_host is the load balancer's hostname or
+-- address, once its provider reports one.
+ALTER TABLE databases
+    ADD COLUMN exposure TEXT NOT NULL DEFAULT 'cluster'
+        CHECK (exposure IN ('cluster', 'internal', 'external')),
+    ADD COLUMN exposure_allowed_ranges TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN external_host TEXT;
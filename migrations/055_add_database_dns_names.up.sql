@@ -0,0 +1,4 @@
+-- The friendly hostname published for a database in the DNS zone DAAP was
+-- configured with when it was created, e.g. orders-db.db.example.com. Empty
+-- for databases created without a DNS zone.
+ALTER TABLE databases ADD COLUMN dns_name TEXT NOT NULL DEFAULT '';
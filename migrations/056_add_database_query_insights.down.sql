@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS database_query_snapshots;
+ALTER TABLE databases DROP COLUMN IF EXISTS query_insights;
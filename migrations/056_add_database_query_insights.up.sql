@@ -0,0 +1,15 @@
+-- Databases opted in to query insights have their top statements from
+-- pg_stat_statements snapshotted by the insights collector.
+ALTER TABLE databases ADD COLUMN query_insights BOOLEAN NOT NULL DEFAULT false;
+
+-- Each snapshot holds the top statements of a database at one time, with
+-- their literals redacted, as a JSON array.
+CREATE TABLE database_query_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    statements JSONB NOT NULL,
+    collected_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_database_query_snapshots_database ON database_query_snapshots (database_id, collected_at);
+CREATE INDEX idx_database_query_snapshots_collected_at ON database_query_snapshots (collected_at);
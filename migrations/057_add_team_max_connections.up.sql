@@ -0,0 +1,5 @@
+-- The connection budget of a team: the sum of the max_connections of its
+-- active databases may not exceed it. 0 means no budget.
+ALTER TABLE teams
+    ADD COLUMN max_connections INTEGER NOT NULL DEFAULT 0
+        CHECK (max_connections >= 0);
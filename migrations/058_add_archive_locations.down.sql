@@ -0,0 +1,2 @@
+ALTER TABLE databases DROP COLUMN IF EXISTS archive_url;
+ALTER TABLE teams DROP COLUMN IF EXISTS archive_location;
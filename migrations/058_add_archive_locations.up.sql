@@ -0,0 +1,5 @@
+-- Where the final backups of a team's databases go when their tier's
+-- destruction strategy is "archive", and where the backup of each archived
+-- database ended up.
+ALTER TABLE teams ADD COLUMN archive_location TEXT NOT NULL DEFAULT '';
+ALTER TABLE databases ADD COLUMN archive_url TEXT;
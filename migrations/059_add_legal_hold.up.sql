@@ -0,0 +1,3 @@
+-- A database under legal hold is not deleted, expired or purged until a
+-- platform user clears the hold.
+ALTER TABLE databases ADD COLUMN legal_hold BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,25 @@
+-- Background work queued on behalf of requests, such as provisioning a new
+-- database, claimed and retried by the job workers of every instance.
+CREATE TABLE jobs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    type VARCHAR(63) NOT NULL,
+    status TEXT NOT NULL
+        CHECK (status IN ('queued', 'running', 'succeeded', 'failed')),
+    database_id UUID NOT NULL REFERENCES databases(id) ON DELETE CASCADE,
+    operation_id UUID REFERENCES operations(id) ON DELETE SET NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL CHECK (max_attempts > 0),
+    last_error TEXT NOT NULL DEFAULT '',
+    run_after TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    request_id TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMPTZ,
+    completed_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_jobs_database ON jobs (database_id, created_at);
+CREATE INDEX idx_jobs_due ON jobs (run_after) WHERE status = 'queued';
+CREATE INDEX idx_jobs_running ON jobs (started_at) WHERE status = 'running';
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS idx_databases_deleted_at;
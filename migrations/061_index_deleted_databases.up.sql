@@ -0,0 +1,3 @@
+-- The retention purge looks up databases by deletion time; only deleted
+-- ones have one.
+CREATE INDEX idx_databases_deleted_at ON databases (deleted_at) WHERE deleted_at IS NOT NULL;
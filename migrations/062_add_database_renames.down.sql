@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_databases_rename_to;
+ALTER TABLE databases DROP COLUMN IF EXISTS rename;
+
+UPDATE databases SET status = 'ready' WHERE status = 'renaming';
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'failing_over', 'restarting', 'deprovisioning', 'deleting', 'deleted'));
@@ -0,0 +1,10 @@
+-- A database is renaming while a copy of it is provisioned under its new
+-- name and takes over from it. rename records the progress of the rename;
+-- the name it renames to is reserved until it is cleared.
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'failing_over', 'restarting', 'renaming', 'deprovisioning', 'deleting', 'deleted'));
+
+ALTER TABLE databases ADD COLUMN rename JSONB;
+CREATE UNIQUE INDEX idx_databases_rename_to ON databases ((rename->>'to'))
+    WHERE rename IS NOT NULL AND deleted_at IS NULL;
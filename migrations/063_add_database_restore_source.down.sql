@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_databases_source_database;
+ALTER TABLE databases DROP COLUMN IF EXISTS source_database_id;
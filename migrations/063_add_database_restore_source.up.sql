@@ -0,0 +1,5 @@
+-- A database restored from the backups of another references it, like a
+-- promoted database references the one it was promoted from.
+ALTER TABLE databases ADD COLUMN source_database_id UUID REFERENCES databases(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_databases_source_database ON databases (source_database_id) WHERE source_database_id IS NOT NULL;
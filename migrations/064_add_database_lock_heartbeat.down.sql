@@ -0,0 +1 @@
+ALTER TABLE database_locks DROP COLUMN IF EXISTS renewed_at;
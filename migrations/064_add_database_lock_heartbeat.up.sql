@@ -0,0 +1 @@
+ALTER TABLE database_locks ADD COLUMN renewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
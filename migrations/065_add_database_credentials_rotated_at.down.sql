@@ -0,0 +1 @@
+ALTER TABLE databases DROP COLUMN IF EXISTS credentials_rotated_at;
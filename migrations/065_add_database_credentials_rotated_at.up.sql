@@ -0,0 +1 @@
+ALTER TABLE databases ADD COLUMN credentials_rotated_at TIMESTAMPTZ;
@@ -0,0 +1,22 @@
+-- Partitions archived to history_archive are left there.
+DO $$
+DECLARE
+    t TEXT;
+BEGIN
+    FOREACH t IN ARRAY ARRAY['database_status_history', 'database_resize_events', 'database_tier_changes']
+    LOOP
+        EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING CONSTRAINTS)', t || '_unpartitioned', t);
+        EXECUTE format('INSERT INTO %I SELECT * FROM %I', t || '_unpartitioned', t);
+        EXECUTE format('ALTER SEQUENCE %I OWNED BY %I.id', t || '_id_seq', t || '_unpartitioned');
+        EXECUTE format('DROP TABLE %I', t);
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', t || '_unpartitioned', t);
+        EXECUTE format('ALTER TABLE %I ADD PRIMARY KEY (id)', t);
+        EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (database_id) REFERENCES databases(id) ON DELETE CASCADE', t);
+    END LOOP;
+END;
+$$;
+
+CREATE INDEX idx_database_status_history_database ON database_status_history (database_id, changed_at);
+CREATE INDEX idx_database_status_history_to_status ON database_status_history (to_status);
+CREATE INDEX idx_database_resize_events_database ON database_resize_events (database_id, created_at);
+CREATE INDEX idx_database_tier_changes_database ON database_tier_changes (database_id, created_at);
@@ -0,0 +1,41 @@
+-- The history tables grow with every status change, resize and tier change.
+-- They are partitioned by month, so the history rotator can drop or archive
+-- whole months once past HISTORY_RETENTION_MONTHS instead of deleting rows.
+-- The rotator creates the monthly partitions ahead of time; rows written to
+-- a month without one land in the default partition until it does. Existing
+-- rows start out there.
+CREATE SCHEMA IF NOT EXISTS history_archive;
+
+DO $$
+DECLARE
+    t RECORD;
+BEGIN
+    FOR t IN SELECT * FROM (VALUES
+        ('database_status_history', 'changed_at'),
+        ('database_resize_events', 'created_at'),
+        ('database_tier_changes', 'created_at')
+    ) AS history(name, col)
+    LOOP
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', t.name, t.name || '_unpartitioned');
+        EXECUTE format('ALTER TABLE %I RENAME CONSTRAINT %I TO %I',
+            t.name || '_unpartitioned', t.name || '_pkey', t.name || '_unpartitioned_pkey');
+
+        EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING CONSTRAINTS) PARTITION BY RANGE (%I)',
+            t.name, t.name || '_unpartitioned', t.col);
+        -- A primary key of a partitioned table must include its partition
+        -- key.
+        EXECUTE format('ALTER TABLE %I ADD PRIMARY KEY (id, %I)', t.name, t.col);
+        EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (database_id) REFERENCES databases(id) ON DELETE CASCADE', t.name);
+        EXECUTE format('CREATE TABLE %I PARTITION OF %I DEFAULT', t.name || '_default', t.name);
+
+        EXECUTE format('INSERT INTO %I SELECT * FROM %I', t.name, t.name || '_unpartitioned');
+        EXECUTE format('ALTER SEQUENCE %I OWNED BY %I.id', t.name || '_id_seq', t.name);
+        EXECUTE format('DROP TABLE %I', t.name || '_unpartitioned');
+    END LOOP;
+END;
+$$;
+
+CREATE INDEX idx_database_status_history_database ON database_status_history (database_id, changed_at);
+CREATE INDEX idx_database_status_history_to_status ON database_status_history (to_status);
+CREATE INDEX idx_database_resize_events_database ON database_resize_events (database_id, created_at);
+CREATE INDEX idx_database_tier_changes_database ON database_tier_changes (database_id, created_at);
@@ -0,0 +1,4 @@
+UPDATE databases SET status = 'ready' WHERE status IN ('frozen', 'unfreezing');
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'failing_over', 'restarting', 'renaming', 'deprovisioning', 'deleting', 'deleted'));
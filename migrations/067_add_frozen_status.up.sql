@@ -0,0 +1,6 @@
+-- A database is frozen when deleted under a tier whose destruction strategy
+-- is freeze: its instances are stopped but its storage and record are kept.
+-- It is unfreezing while its instances start again.
+ALTER TABLE databases DROP CONSTRAINT chk_databases_status;
+ALTER TABLE databases ADD CONSTRAINT chk_databases_status
+  CHECK (status IN ('provisioning', 'ready', 'error', 'failing_over', 'restarting', 'renaming', 'frozen', 'unfreezing', 'deprovisioning', 'deleting', 'deleted'));
@@ -0,0 +1,2 @@
+ALTER TABLE operations DROP COLUMN IF EXISTS renewed_at;
+ALTER TABLE operations DROP COLUMN IF EXISTS instance;
@@ -0,0 +1,5 @@
+-- Operations record the DAAP process running them, which heartbeats them
+-- while it does, so an administrator can tell an operation still in progress
+-- from one abandoned by a crashed process.
+ALTER TABLE operations ADD COLUMN instance TEXT NOT NULL DEFAULT '';
+ALTER TABLE operations ADD COLUMN renewed_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
@@ -0,0 +1,94 @@
+-- Partitions archived to history_archive are left there, and so are the
+-- monthly partitions of the tables 066 partitioned.
+DO $$
+DECLARE
+    t RECORD;
+    fk TEXT;
+BEGIN
+    FOR t IN SELECT * FROM (VALUES
+        ('database_promotions', 'id', ARRAY['source_database_id', 'target_database_id']),
+        ('database_usage_samples', 'id', ARRAY['database_id']),
+        ('database_query_snapshots', 'id', ARRAY['database_id']),
+        ('operations', 'id', ARRAY['database_id']),
+        ('revisions', 'resource_type, resource_id, revision', ARRAY[]::TEXT[])
+    ) AS history(name, pk, fks)
+    LOOP
+        EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING CONSTRAINTS)', t.name || '_unpartitioned', t.name);
+        EXECUTE format('INSERT INTO %I SELECT * FROM %I', t.name || '_unpartitioned', t.name);
+        IF to_regclass(t.name || '_id_seq') IS NOT NULL THEN
+            EXECUTE format('ALTER SEQUENCE %I OWNED BY %I.id', t.name || '_id_seq', t.name || '_unpartitioned');
+        END IF;
+        EXECUTE format('DROP TABLE %I', t.name);
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', t.name || '_unpartitioned', t.name);
+        EXECUTE format('ALTER TABLE %I ADD PRIMARY KEY (%s)', t.name, t.pk);
+        FOREACH fk IN ARRAY t.fks
+        LOOP
+            EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (%I) REFERENCES databases(id) ON DELETE CASCADE', t.name, fk);
+        END LOOP;
+    END LOOP;
+END;
+$$;
+
+CREATE INDEX idx_database_promotions_source ON database_promotions (source_database_id, created_at);
+CREATE INDEX idx_database_promotions_target ON database_promotions (target_database_id, created_at);
+CREATE INDEX idx_database_usage_samples_database ON database_usage_samples (database_id, collected_at);
+CREATE INDEX idx_database_usage_samples_collected_at ON database_usage_samples (collected_at);
+CREATE INDEX idx_database_query_snapshots_database ON database_query_snapshots (database_id, collected_at);
+CREATE INDEX idx_database_query_snapshots_collected_at ON database_query_snapshots (collected_at);
+CREATE INDEX idx_operations_database ON operations (database_id, created_at);
+CREATE INDEX idx_operations_running ON operations (database_id) WHERE status = 'running';
+
+-- Jobs of operations the rotator dropped lose their operation.
+UPDATE jobs SET operation_id = NULL
+WHERE operation_id IS NOT NULL AND operation_id NOT IN (SELECT id FROM operations);
+ALTER TABLE jobs ADD CONSTRAINT jobs_operation_id_fkey
+    FOREIGN KEY (operation_id) REFERENCES operations(id) ON DELETE SET NULL;
+
+CREATE OR REPLACE FUNCTION record_revision() RETURNS trigger AS $$
+DECLARE
+    row_snapshot JSONB;
+    latest JSONB;
+    op TEXT;
+    actor TEXT := '';
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_snapshot := to_jsonb(OLD) - TG_ARGV[1:];
+        op := 'delete';
+    ELSE
+        row_snapshot := to_jsonb(NEW) - TG_ARGV[1:];
+        actor := COALESCE(to_jsonb(NEW) ->> 'updated_by', '');
+        op := CASE
+            WHEN TG_OP = 'INSERT' THEN 'create'
+            WHEN to_jsonb(OLD) ->> 'deleted_at' IS NULL AND to_jsonb(NEW) ->> 'deleted_at' IS NOT NULL THEN 'delete'
+            ELSE 'update'
+        END;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT snapshot INTO latest FROM revisions
+        WHERE resource_type = TG_ARGV[0] AND resource_id = (row_snapshot ->> 'id')::uuid
+        ORDER BY revision DESC LIMIT 1;
+        IF latest = row_snapshot THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    INSERT INTO revisions (resource_type, resource_id, revision, operation, snapshot, changed_by)
+    SELECT TG_ARGV[0], (row_snapshot ->> 'id')::uuid, COALESCE(MAX(revision), 0) + 1, op, row_snapshot, actor
+    FROM revisions
+    WHERE resource_type = TG_ARGV[0] AND resource_id = (row_snapshot ->> 'id')::uuid;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE revision_heads;
+
+CREATE OR REPLACE FUNCTION revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'revisions cannot be changed or deleted';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER revisions_immutable
+    BEFORE UPDATE OR DELETE ON revisions
+    FOR EACH ROW EXECUTE FUNCTION revisions_immutable();
@@ -0,0 +1,176 @@
+-- The other append-only tables recording what happened to databases are
+-- partitioned by month too, so the history rotator drops or archives their
+-- old months with the rest: promotions, usage samples, query snapshots,
+-- operations and revisions. Audit events are not stored in the platform
+-- database.
+--
+-- Rows, existing ones included, go to the partition of their month, never
+-- to the default partition, which only catches rows of months the rotator
+-- has not created a partition for yet. The rows 066 left in the default
+-- partitions are moved to monthly partitions here.
+--
+-- A partitioned table's primary key must include its partition key, so
+-- operations are no longer unique by id alone: jobs keep their operation_id
+-- without a foreign key.
+ALTER TABLE jobs DROP CONSTRAINT IF EXISTS jobs_operation_id_fkey;
+
+DO $$
+DECLARE
+    t RECORD;
+    fk TEXT;
+    month TIMESTAMP;
+BEGIN
+    -- The default partitions of 066's tables are swapped for empty ones;
+    -- their rows are put back below, like those of the other tables.
+    FOR t IN SELECT * FROM (VALUES
+        ('database_status_history'),
+        ('database_resize_events'),
+        ('database_tier_changes')
+    ) AS history(name)
+    LOOP
+        EXECUTE format('ALTER TABLE %I DETACH PARTITION %I', t.name, t.name || '_default');
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', t.name || '_default', t.name || '_unpartitioned');
+        EXECUTE format('CREATE TABLE %I PARTITION OF %I DEFAULT', t.name || '_default', t.name);
+    END LOOP;
+
+    FOR t IN SELECT * FROM (VALUES
+        ('database_promotions', 'created_at', 'id', ARRAY['source_database_id', 'target_database_id']),
+        ('database_usage_samples', 'collected_at', 'id', ARRAY['database_id']),
+        ('database_query_snapshots', 'collected_at', 'id', ARRAY['database_id']),
+        ('operations', 'created_at', 'id', ARRAY['database_id']),
+        ('revisions', 'changed_at', 'resource_type, resource_id, revision', ARRAY[]::TEXT[])
+    ) AS history(name, col, pk, fks)
+    LOOP
+        EXECUTE format('ALTER TABLE %I RENAME TO %I', t.name, t.name || '_unpartitioned');
+        EXECUTE format('ALTER TABLE %I RENAME CONSTRAINT %I TO %I',
+            t.name || '_unpartitioned', t.name || '_pkey', t.name || '_unpartitioned_pkey');
+
+        EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING DEFAULTS INCLUDING CONSTRAINTS) PARTITION BY RANGE (%I)',
+            t.name, t.name || '_unpartitioned', t.col);
+        EXECUTE format('ALTER TABLE %I ADD PRIMARY KEY (%s, %I)', t.name, t.pk, t.col);
+        FOREACH fk IN ARRAY t.fks
+        LOOP
+            EXECUTE format('ALTER TABLE %I ADD FOREIGN KEY (%I) REFERENCES databases(id) ON DELETE CASCADE', t.name, fk);
+        END LOOP;
+        EXECUTE format('CREATE TABLE %I PARTITION OF %I DEFAULT', t.name || '_default', t.name);
+        IF to_regclass(t.name || '_id_seq') IS NOT NULL THEN
+            EXECUTE format('ALTER SEQUENCE %I OWNED BY %I.id', t.name || '_id_seq', t.name);
+        END IF;
+    END LOOP;
+
+    FOR t IN SELECT * FROM (VALUES
+        ('database_status_history', 'changed_at'),
+        ('database_resize_events', 'created_at'),
+        ('database_tier_changes', 'created_at'),
+        ('database_promotions', 'created_at'),
+        ('database_usage_samples', 'collected_at'),
+        ('database_query_snapshots', 'collected_at'),
+        ('operations', 'created_at'),
+        ('revisions', 'changed_at')
+    ) AS history(name, col)
+    LOOP
+        -- Months are those of UTC, as the rotator names them.
+        FOR month IN EXECUTE format('SELECT DISTINCT date_trunc(''month'', %I AT TIME ZONE ''UTC'') FROM %I',
+            t.col, t.name || '_unpartitioned')
+        LOOP
+            EXECUTE format('CREATE TABLE %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+                t.name || '_' || to_char(month, 'YYYY_MM'), t.name,
+                month AT TIME ZONE 'UTC', (month + INTERVAL '1 month') AT TIME ZONE 'UTC');
+        END LOOP;
+        EXECUTE format('INSERT INTO %I SELECT * FROM %I', t.name, t.name || '_unpartitioned');
+        EXECUTE format('DROP TABLE %I', t.name || '_unpartitioned');
+    END LOOP;
+
+    -- Archived partitions no longer reference databases: deleting a
+    -- database must not reach into the archive, and the archive keeps the
+    -- rows of databases deleted since.
+    FOR t IN
+        SELECT c.conrelid::regclass AS rel, c.conname
+        FROM pg_constraint c
+        JOIN pg_class r ON r.oid = c.conrelid
+        WHERE r.relnamespace = 'history_archive'::regnamespace AND c.contype = 'f'
+    LOOP
+        EXECUTE format('ALTER TABLE %s DROP CONSTRAINT %I', t.rel, t.conname);
+    END LOOP;
+END;
+$$;
+
+CREATE INDEX idx_database_promotions_source ON database_promotions (source_database_id, created_at);
+CREATE INDEX idx_database_promotions_target ON database_promotions (target_database_id, created_at);
+CREATE INDEX idx_database_usage_samples_database ON database_usage_samples (database_id, collected_at);
+CREATE INDEX idx_database_usage_samples_collected_at ON database_usage_samples (collected_at);
+CREATE INDEX idx_database_query_snapshots_database ON database_query_snapshots (database_id, collected_at);
+CREATE INDEX idx_database_query_snapshots_collected_at ON database_query_snapshots (collected_at);
+CREATE INDEX idx_operations_database ON operations (database_id, created_at);
+CREATE INDEX idx_operations_running ON operations (database_id) WHERE status = 'running';
+
+-- Revision numbers are counted apart from the revisions, so a resource
+-- whose revisions are past the retention carries on from its last number
+-- instead of starting over.
+CREATE TABLE revision_heads (
+    resource_type VARCHAR(20) NOT NULL,
+    resource_id UUID NOT NULL,
+    revision INTEGER NOT NULL,
+    PRIMARY KEY (resource_type, resource_id)
+);
+
+INSERT INTO revision_heads (resource_type, resource_id, revision)
+SELECT resource_type, resource_id, MAX(revision)
+FROM revisions
+GROUP BY resource_type, resource_id;
+
+CREATE OR REPLACE FUNCTION record_revision() RETURNS trigger AS $$
+DECLARE
+    row_snapshot JSONB;
+    latest JSONB;
+    op TEXT;
+    actor TEXT := '';
+    next_revision INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        row_snapshot := to_jsonb(OLD) - TG_ARGV[1:];
+        op := 'delete';
+    ELSE
+        row_snapshot := to_jsonb(NEW) - TG_ARGV[1:];
+        actor := COALESCE(to_jsonb(NEW) ->> 'updated_by', '');
+        op := CASE
+            WHEN TG_OP = 'INSERT' THEN 'create'
+            WHEN to_jsonb(OLD) ->> 'deleted_at' IS NULL AND to_jsonb(NEW) ->> 'deleted_at' IS NOT NULL THEN 'delete'
+            ELSE 'update'
+        END;
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT snapshot INTO latest FROM revisions
+        WHERE resource_type = TG_ARGV[0] AND resource_id = (row_snapshot ->> 'id')::uuid
+        ORDER BY revision DESC LIMIT 1;
+        IF latest = row_snapshot THEN
+            RETURN NULL;
+        END IF;
+    END IF;
+
+    INSERT INTO revision_heads AS head (resource_type, resource_id, revision)
+    VALUES (TG_ARGV[0], (row_snapshot ->> 'id')::uuid, 1)
+    ON CONFLICT (resource_type, resource_id) DO UPDATE SET revision = head.revision + 1
+    RETURNING head.revision INTO next_revision;
+
+    INSERT INTO revisions (resource_type, resource_id, revision, operation, snapshot, changed_by)
+    VALUES (TG_ARGV[0], (row_snapshot ->> 'id')::uuid, next_revision, op, row_snapshot, actor);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Revisions stay immutable, except when the rotator moves the rows of a
+-- month out of the default partition into a partition of their own.
+CREATE OR REPLACE FUNCTION revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND current_setting('daap.moving_history', true) = 'on' THEN
+        RETURN OLD;
+    END IF;
+    RAISE EXCEPTION 'revisions cannot be changed or deleted';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER revisions_immutable
+    BEFORE UPDATE OR DELETE ON revisions
+    FOR EACH ROW EXECUTE FUNCTION revisions_immutable();
@@ -0,0 +1,38 @@
+// Package migrations embeds the SQL migration files so the binary knows which
+// schema version it expects.
+package migrations
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"strconv"
+	"strings"
+)
+
+//go:embed *.sql
+var FS embed.FS
+
+// Latest returns the highest migration version in FS.
+func Latest() (uint, error) {
+	entries, err := fs.ReadDir(FS, ".")
+	if err != nil {
+		return 0, fmt.Errorf("reading migrations: %w", err)
+	}
+
+	var latest uint
+	for _, e := range entries {
+		prefix, _, ok := strings.Cut(e.Name(), "_")
+		if !ok || !strings.HasSuffix(e.Name(), ".up.sql") {
+			continue
+		}
+		v, err := strconv.ParseUint(prefix, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("parsing migration version from %s: %w", e.Name(), err)
+		}
+		if uint(v) > latest {
+			latest = uint(v)
+		}
+	}
+	return latest, nil
+}
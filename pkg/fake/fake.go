@@ -0,0 +1,99 @@
+// Package fake provides in-memory implementations of the DAAP repositories
+// and a recording provider.Provider, so that services and tools built on
+// DAAP can write tests without a PostgreSQL instance or Kubernetes cluster.
+//
+// Repositories returned by NewRepositories share one store, so foreign-key
+// behaviour (unknown teams, tiers in use, duplicate names) matches the
+// PostgreSQL implementations.
+package fake
+
+import (
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/store/memory"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// Repositories bundles in-memory repositories backed by a shared store.
+type Repositories struct {
+	Databases     database.Repository
+	Purges        database.PurgeRepository
+	Archives      database.ArchiveRepository
+	Renames       database.RenameRepository
+	ResizeEvents  database.ResizeEventRepository
+	History       database.HistoryRepository
+	UsageSamples  database.UsageSampleRepository
+	Queries       database.QuerySnapshotRepository
+	TierChanges   database.TierChangeRepository
+	Promotions    database.PromotionRepository
+	Dependents    database.DependentRepository
+	Specs         database.SpecRepository
+	Locks         database.LockRepository
+	Operations    operation.Repository
+	Jobs          jobs.Repository
+	Organizations organization.Repository
+	Teams         team.Repository
+	Tiers         tier.Repository
+	Blueprints    blueprint.Repository
+	Users         auth.UserRepository
+	Invitations   auth.InvitationRepository
+	Rollouts      rollout.Repository
+	Freezes       freeze.Repository
+	Revisions     revision.Repository
+}
+
+// NewRepositories creates an empty set of in-memory repositories.
+func NewRepositories() *Repositories {
+	db := memory.New()
+	return &Repositories{
+		Databases:     db.Databases(),
+		Purges:        db.Purges(),
+		Archives:      db.Archives(),
+		Renames:       db.Renames(),
+		ResizeEvents:  db.ResizeEvents(),
+		History:       db.History(),
+		UsageSamples:  db.UsageSamples(),
+		Queries:       db.QuerySnapshots(),
+		TierChanges:   db.TierChanges(),
+		Promotions:    db.Promotions(),
+		Dependents:    db.Dependents(),
+		Specs:         db.Specs(),
+		Locks:         db.Locks(),
+		Operations:    db.Operations(),
+		Jobs:          db.Jobs(),
+		Organizations: db.Organizations(),
+		Teams:         db.Teams(),
+		Tiers:         db.Tiers(),
+		Blueprints:    db.Blueprints(),
+		Users:         db.Users(),
+		Invitations:   db.Invitations(),
+		Rollouts:      db.Rollouts(),
+		Freezes:       db.Freezes(),
+		Revisions:     db.Revisions(),
+	}
+}
+
+// NewDatabaseRepository returns a standalone in-memory database.Repository.
+// Databases created through it must reference teams created through the
+// same store; use NewRepositories when seeding related records.
+func NewDatabaseRepository() database.Repository {
+	return memory.New().Databases()
+}
+
+// NewTeamRepository returns a standalone in-memory team.Repository.
+func NewTeamRepository() team.Repository {
+	return memory.New().Teams()
+}
+
+// NewTierRepository returns a standalone in-memory tier.Repository.
+func NewTierRepository() tier.Repository {
+	return memory.New().Tiers()
+}
@@ -0,0 +1,440 @@
+package fake
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// ApplyCall records a single call to Provider.Apply.
+type ApplyCall struct {
+	Database  provider.ProviderDatabase
+	Manifests string
+}
+
+// CloneCall records a single call to Provider.Clone or Provider.Promote.
+type CloneCall struct {
+	Database  provider.ProviderDatabase
+	Source    provider.ProviderDatabase
+	Manifests string // empty for Promote
+}
+
+// RestoreCall records a single call to Provider.Restore.
+type RestoreCall struct {
+	Database  provider.ProviderDatabase
+	Source    provider.ProviderDatabase
+	Manifests string
+	Target    provider.RestoreTarget
+}
+
+// Provider is a provider.Provider that records every call and returns
+// configurable results. The zero value is ready to use and reports every
+// database as "provisioning".
+type Provider struct {
+	// ApplyFn, DeleteFn and CheckHealthFn, when set, override the default
+	// behaviour. Calls are recorded regardless.
+	ApplyFn       func(ctx context.Context, db provider.ProviderDatabase, manifests string) error
+	DeleteFn      func(ctx context.Context, db provider.ProviderDatabase) error
+	CheckHealthFn func(ctx context.Context, db provider.ProviderDatabase) (provider.HealthResult, error)
+	// DeleteForegroundFn, when set, overrides DeleteForeground, which
+	// otherwise behaves like Delete and reports the resources gone.
+	DeleteForegroundFn func(ctx context.Context, db provider.ProviderDatabase, wait time.Duration) (provider.DeletionState, error)
+	// SwitchoverFn, when set, overrides Switchover, which otherwise promotes
+	// the requested target and fails with provider.ErrInvalidTarget when
+	// none is given.
+	SwitchoverFn func(ctx context.Context, db provider.ProviderDatabase, target string) (string, error)
+	// RestartFn and RestartedFn, when set, override Restart and Restarted,
+	// which otherwise succeed and report every restart finished.
+	RestartFn   func(ctx context.Context, db provider.ProviderDatabase) error
+	RestartedFn func(ctx context.Context, db provider.ProviderDatabase) (bool, error)
+	// OperatorVersionFn, when set, overrides OperatorVersion, which otherwise
+	// reports the version as unknown.
+	OperatorVersionFn func(ctx context.Context, db provider.ProviderDatabase) (string, error)
+	// DiagnosticsFn, when set, overrides Diagnostics, which otherwise
+	// returns a status.txt file with the status CheckHealth would report.
+	DiagnosticsFn func(ctx context.Context, db provider.ProviderDatabase) ([]provider.DiagnosticFile, error)
+	// ArchiveFn, when set, overrides Archive, which otherwise completes the
+	// backup at once, at <location>/<database name>.
+	ArchiveFn func(ctx context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error)
+	// CloneFn, PromoteFn and UnfenceFn, when set, override Clone, Promote
+	// and Unfence, which otherwise succeed. Calls are recorded regardless.
+	CloneFn   func(ctx context.Context, db, source provider.ProviderDatabase, manifests string) error
+	PromoteFn func(ctx context.Context, db, source provider.ProviderDatabase) error
+	UnfenceFn func(ctx context.Context, source provider.ProviderDatabase) error
+	// RestoreFn, when set, overrides Restore, which otherwise succeeds.
+	// Calls are recorded regardless.
+	RestoreFn func(ctx context.Context, db, source provider.ProviderDatabase, manifests string, target provider.RestoreTarget) error
+	// RotateCredentialsFn, when set, overrides RotateCredentials, which
+	// otherwise succeeds. Calls are recorded regardless.
+	RotateCredentialsFn func(ctx context.Context, db provider.ProviderDatabase) error
+	// FreezeFn and UnfreezeFn, when set, override Freeze and Unfreeze,
+	// which otherwise succeed. Calls are recorded regardless.
+	FreezeFn   func(ctx context.Context, db provider.ProviderDatabase) error
+	UnfreezeFn func(ctx context.Context, db provider.ProviderDatabase) error
+
+	mu          sync.Mutex
+	applies     []ApplyCall
+	deletes     []provider.ProviderDatabase
+	checks      []provider.ProviderDatabase
+	switchovers []provider.ProviderDatabase
+	restarts    []provider.ProviderDatabase
+	clones      []CloneCall
+	promotions  []CloneCall
+	unfences    []provider.ProviderDatabase
+	restores    []RestoreCall
+	rotations   []provider.ProviderDatabase
+	freezes     []provider.ProviderDatabase
+	unfreezes   []provider.ProviderDatabase
+	health      map[uuid.UUID]provider.HealthResult
+}
+
+var (
+	_ provider.Provider          = (*Provider)(nil)
+	_ provider.ManifestRenderer  = (*Provider)(nil)
+	_ provider.DeletionConfirmer = (*Provider)(nil)
+	_ provider.PrimarySwitcher   = (*Provider)(nil)
+	_ provider.Restarter         = (*Provider)(nil)
+	_ provider.OperatorVersioner = (*Provider)(nil)
+	_ provider.Diagnoser         = (*Provider)(nil)
+	_ provider.Archiver          = (*Provider)(nil)
+	_ provider.Cloner            = (*Provider)(nil)
+	_ provider.Restorer          = (*Provider)(nil)
+	_ provider.CredentialRotator = (*Provider)(nil)
+	_ provider.Freezer           = (*Provider)(nil)
+)
+
+// NewProvider creates an empty fake provider.
+func NewProvider() *Provider {
+	return &Provider{}
+}
+
+// Apply records the call and returns ApplyFn's result, or nil.
+func (p *Provider) Apply(ctx context.Context, db provider.ProviderDatabase, manifests string) error {
+	p.mu.Lock()
+	p.applies = append(p.applies, ApplyCall{Database: db, Manifests: manifests})
+	p.mu.Unlock()
+
+	if p.ApplyFn != nil {
+		return p.ApplyFn(ctx, db, manifests)
+	}
+	return nil
+}
+
+// Delete records the call and returns DeleteFn's result, or nil.
+func (p *Provider) Delete(ctx context.Context, db provider.ProviderDatabase) error {
+	p.mu.Lock()
+	p.deletes = append(p.deletes, db)
+	p.mu.Unlock()
+
+	if p.DeleteFn != nil {
+		return p.DeleteFn(ctx, db)
+	}
+	return nil
+}
+
+// DeleteForeground records the call as a delete and returns
+// DeleteForegroundFn's result, or Delete's error or provider.DeletionGone.
+func (p *Provider) DeleteForeground(ctx context.Context, db provider.ProviderDatabase, wait time.Duration) (provider.DeletionState, error) {
+	if p.DeleteForegroundFn == nil {
+		if err := p.Delete(ctx, db); err != nil {
+			return "", err
+		}
+		return provider.DeletionGone, nil
+	}
+	p.mu.Lock()
+	p.deletes = append(p.deletes, db)
+	p.mu.Unlock()
+	return p.DeleteForegroundFn(ctx, db, wait)
+}
+
+// CheckHealth records the call and returns CheckHealthFn's result, the
+// result registered with SetHealth, or a "provisioning" status.
+func (p *Provider) CheckHealth(ctx context.Context, db provider.ProviderDatabase) (provider.HealthResult, error) {
+	p.mu.Lock()
+	p.checks = append(p.checks, db)
+	result, ok := p.health[db.ID]
+	p.mu.Unlock()
+
+	if p.CheckHealthFn != nil {
+		return p.CheckHealthFn(ctx, db)
+	}
+	if ok {
+		return result, nil
+	}
+	return provider.HealthResult{Status: "provisioning"}, nil
+}
+
+// Switchover records the call and returns SwitchoverFn's result, or target.
+func (p *Provider) Switchover(ctx context.Context, db provider.ProviderDatabase, target string) (string, error) {
+	p.mu.Lock()
+	p.switchovers = append(p.switchovers, db)
+	p.mu.Unlock()
+
+	if p.SwitchoverFn != nil {
+		return p.SwitchoverFn(ctx, db, target)
+	}
+	if target == "" {
+		return "", provider.ErrInvalidTarget
+	}
+	return target, nil
+}
+
+// Restart records the call and returns RestartFn's result, or nil.
+func (p *Provider) Restart(ctx context.Context, db provider.ProviderDatabase) error {
+	p.mu.Lock()
+	p.restarts = append(p.restarts, db)
+	p.mu.Unlock()
+
+	if p.RestartFn != nil {
+		return p.RestartFn(ctx, db)
+	}
+	return nil
+}
+
+// Restarted returns RestartedFn's result, or true.
+func (p *Provider) Restarted(ctx context.Context, db provider.ProviderDatabase) (bool, error) {
+	if p.RestartedFn != nil {
+		return p.RestartedFn(ctx, db)
+	}
+	return true, nil
+}
+
+// OperatorVersion returns OperatorVersionFn's result, or "".
+func (p *Provider) OperatorVersion(ctx context.Context, db provider.ProviderDatabase) (string, error) {
+	if p.OperatorVersionFn != nil {
+		return p.OperatorVersionFn(ctx, db)
+	}
+	return "", nil
+}
+
+// Diagnostics returns DiagnosticsFn's result, or a status.txt file holding
+// the status registered with SetHealth.
+func (p *Provider) Diagnostics(ctx context.Context, db provider.ProviderDatabase) ([]provider.DiagnosticFile, error) {
+	if p.DiagnosticsFn != nil {
+		return p.DiagnosticsFn(ctx, db)
+	}
+	p.mu.Lock()
+	status := "provisioning"
+	if result, ok := p.health[db.ID]; ok {
+		status = result.Status
+	}
+	p.mu.Unlock()
+	return []provider.DiagnosticFile{{Name: "status.txt", Content: []byte(status + "\n")}}, nil
+}
+
+// Archive returns ArchiveFn's result, or a completed backup at
+// <location>/<database name>.
+func (p *Provider) Archive(ctx context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error) {
+	if p.ArchiveFn != nil {
+		return p.ArchiveFn(ctx, db, location)
+	}
+	return provider.Archive{URL: location + "/" + db.Name, Done: true}, nil
+}
+
+// Clone records the call and returns CloneFn's result, or nil.
+func (p *Provider) Clone(ctx context.Context, db, source provider.ProviderDatabase, manifests string) error {
+	p.mu.Lock()
+	p.clones = append(p.clones, CloneCall{Database: db, Source: source, Manifests: manifests})
+	p.mu.Unlock()
+
+	if p.CloneFn != nil {
+		return p.CloneFn(ctx, db, source, manifests)
+	}
+	return nil
+}
+
+// Promote records the call and returns PromoteFn's result, or nil.
+func (p *Provider) Promote(ctx context.Context, db, source provider.ProviderDatabase) error {
+	p.mu.Lock()
+	p.promotions = append(p.promotions, CloneCall{Database: db, Source: source})
+	p.mu.Unlock()
+
+	if p.PromoteFn != nil {
+		return p.PromoteFn(ctx, db, source)
+	}
+	return nil
+}
+
+// Unfence records the call and returns UnfenceFn's result, or nil.
+func (p *Provider) Unfence(ctx context.Context, source provider.ProviderDatabase) error {
+	p.mu.Lock()
+	p.unfences = append(p.unfences, source)
+	p.mu.Unlock()
+
+	if p.UnfenceFn != nil {
+		return p.UnfenceFn(ctx, source)
+	}
+	return nil
+}
+
+// Restore records the call and returns RestoreFn's result, or nil.
+func (p *Provider) Restore(ctx context.Context, db, source provider.ProviderDatabase, manifests string, target provider.RestoreTarget) error {
+	p.mu.Lock()
+	p.restores = append(p.restores, RestoreCall{Database: db, Source: source, Manifests: manifests, Target: target})
+	p.mu.Unlock()
+
+	if p.RestoreFn != nil {
+		return p.RestoreFn(ctx, db, source, manifests, target)
+	}
+	return nil
+}
+
+// RotateCredentials records the call and returns RotateCredentialsFn's
+// result, or nil.
+func (p *Provider) RotateCredentials(ctx context.Context, db provider.ProviderDatabase) error {
+	p.mu.Lock()
+	p.rotations = append(p.rotations, db)
+	p.mu.Unlock()
+
+	if p.RotateCredentialsFn != nil {
+		return p.RotateCredentialsFn(ctx, db)
+	}
+	return nil
+}
+
+// Freeze records the call and returns FreezeFn's result, or nil.
+func (p *Provider) Freeze(ctx context.Context, db provider.ProviderDatabase) error {
+	p.mu.Lock()
+	p.freezes = append(p.freezes, db)
+	p.mu.Unlock()
+
+	if p.FreezeFn != nil {
+		return p.FreezeFn(ctx, db)
+	}
+	return nil
+}
+
+// Unfreeze records the call and returns UnfreezeFn's result, or nil.
+func (p *Provider) Unfreeze(ctx context.Context, db provider.ProviderDatabase) error {
+	p.mu.Lock()
+	p.unfreezes = append(p.unfreezes, db)
+	p.mu.Unlock()
+
+	if p.UnfreezeFn != nil {
+		return p.UnfreezeFn(ctx, db)
+	}
+	return nil
+}
+
+// RenderManifests returns the manifests unchanged; the fake does not
+// template or label them.
+func (p *Provider) RenderManifests(_ provider.ProviderDatabase, manifests string) (string, error) {
+	return manifests, nil
+}
+
+// SetHealth sets the result CheckHealth returns for the given database.
+func (p *Provider) SetHealth(id uuid.UUID, result provider.HealthResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.health == nil {
+		p.health = make(map[uuid.UUID]provider.HealthResult)
+	}
+	p.health[id] = result
+}
+
+// ApplyCalls returns a copy of all recorded Apply calls.
+func (p *Provider) ApplyCalls() []ApplyCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]ApplyCall(nil), p.applies...)
+}
+
+// DeleteCalls returns a copy of all databases passed to Delete.
+func (p *Provider) DeleteCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.deletes...)
+}
+
+// CheckHealthCalls returns a copy of all databases passed to CheckHealth.
+func (p *Provider) CheckHealthCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.checks...)
+}
+
+// SwitchoverCalls returns a copy of all databases passed to Switchover.
+func (p *Provider) SwitchoverCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.switchovers...)
+}
+
+// RestartCalls returns a copy of all databases passed to Restart.
+func (p *Provider) RestartCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.restarts...)
+}
+
+// CloneCalls returns a copy of all recorded Clone calls.
+func (p *Provider) CloneCalls() []CloneCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]CloneCall(nil), p.clones...)
+}
+
+// PromoteCalls returns a copy of all recorded Promote calls.
+func (p *Provider) PromoteCalls() []CloneCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]CloneCall(nil), p.promotions...)
+}
+
+// UnfenceCalls returns a copy of all recorded Unfence calls.
+func (p *Provider) UnfenceCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.unfences...)
+}
+
+// RestoreCalls returns a copy of all recorded Restore calls.
+func (p *Provider) RestoreCalls() []RestoreCall {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]RestoreCall(nil), p.restores...)
+}
+
+// RotateCredentialsCalls returns a copy of all recorded RotateCredentials
+// calls.
+func (p *Provider) RotateCredentialsCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.rotations...)
+}
+
+// FreezeCalls returns a copy of all databases passed to Freeze.
+func (p *Provider) FreezeCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.freezes...)
+}
+
+// UnfreezeCalls returns a copy of all databases passed to Unfreeze.
+func (p *Provider) UnfreezeCalls() []provider.ProviderDatabase {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]provider.ProviderDatabase(nil), p.unfreezes...)
+}
+
+// Reset clears recorded calls and registered health results.
+func (p *Provider) Reset() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.applies = nil
+	p.deletes = nil
+	p.checks = nil
+	p.switchovers = nil
+	p.restarts = nil
+	p.clones = nil
+	p.promotions = nil
+	p.restores = nil
+	p.rotations = nil
+	p.freezes = nil
+	p.unfreezes = nil
+	p.health = nil
+}
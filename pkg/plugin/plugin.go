@@ -0,0 +1,129 @@
+// Package plugin lets third parties ship DAAP providers as separate binaries
+// or containers. A plugin implements providerv1.ProviderPluginServer and calls
+// Serve from its main function:
+//
+//	func main() {
+//		if err := plugin.Serve(&myProvider{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// DAAP starts plugin binaries it finds in PROVIDER_PLUGIN_DIR. The binary
+// listens on a private Unix socket and announces it on stdout with a single
+// handshake line, "<protocol version>|<network>|<address>". A plugin running
+// as its own container instead sets DAAP_PLUGIN_LISTEN to the address to
+// serve on, and DAAP dials it from PROVIDER_PLUGIN_ADDRS.
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/daap14/daap/pkg/plugin/providerv1"
+)
+
+// ProtocolVersion is the version of the handshake and the providerv1 service.
+// DAAP refuses plugins that announce a different version.
+const ProtocolVersion = 1
+
+// Environment variables shared by DAAP and its plugins.
+const (
+	// MagicCookieKey is set by DAAP when it starts a plugin binary. It keeps
+	// a plugin from being run by hand, where the handshake makes no sense.
+	MagicCookieKey   = "DAAP_PLUGIN_MAGIC_COOKIE"
+	MagicCookieValue = "5c1b2f0e8a9d4c37b6e1a2d3f4c5b6a7"
+
+	// ListenEnv, when set, makes Serve listen on that TCP address without a
+	// handshake, for plugins deployed as their own container.
+	ListenEnv = "DAAP_PLUGIN_LISTEN"
+)
+
+// RequestIDMetadataKey is the gRPC metadata key under which DAAP sends the
+// ID of the API request, or background operation, a call is made for.
+// Plugins should record it on the resources they apply, as the built-in
+// providers do with the daap.io/request-id annotation; see RequestID.
+const RequestIDMetadataKey = "x-daap-request-id"
+
+// RequestID returns the request ID DAAP sent with the call whose context is
+// ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	if ids := metadata.ValueFromIncomingContext(ctx, RequestIDMetadataKey); len(ids) > 0 {
+		return ids[0]
+	}
+	return ""
+}
+
+// ErrNotLaunched is returned by Serve when the binary was neither started by
+// DAAP nor configured to listen on an address.
+var ErrNotLaunched = errors.New("this binary is a DAAP provider plugin: it is started by the DAAP server, or set " + ListenEnv + " to serve on an address")
+
+// NewServer returns a gRPC server exposing impl as a provider plugin.
+func NewServer(impl providerv1.ProviderPluginServer) *grpc.Server {
+	s := grpc.NewServer()
+	providerv1.RegisterProviderPluginServer(s, impl)
+	return s
+}
+
+// Serve runs impl as a provider plugin until SIGINT or SIGTERM.
+func Serve(impl providerv1.ProviderPluginServer) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if addr := os.Getenv(ListenEnv); addr != "" {
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return fmt.Errorf("listening on %s: %w", addr, err)
+		}
+		return serve(ctx, lis, impl)
+	}
+
+	if os.Getenv(MagicCookieKey) != MagicCookieValue {
+		return ErrNotLaunched
+	}
+
+	dir, err := os.MkdirTemp("", "daap-plugin-")
+	if err != nil {
+		return fmt.Errorf("creating socket directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	lis, err := net.Listen("unix", filepath.Join(dir, "plugin.sock"))
+	if err != nil {
+		return fmt.Errorf("listening on socket: %w", err)
+	}
+	if err := WriteHandshake(os.Stdout, lis.Addr()); err != nil {
+		_ = lis.Close()
+		return err
+	}
+	return serve(ctx, lis, impl)
+}
+
+// WriteHandshake writes the handshake line announcing addr.
+func WriteHandshake(w io.Writer, addr net.Addr) error {
+	if _, err := fmt.Fprintf(w, "%d|%s|%s\n", ProtocolVersion, addr.Network(), addr.String()); err != nil {
+		return fmt.Errorf("writing handshake: %w", err)
+	}
+	return nil
+}
+
+func serve(ctx context.Context, lis net.Listener, impl providerv1.ProviderPluginServer) error {
+	s := NewServer(impl)
+	go func() {
+		<-ctx.Done()
+		s.GracefulStop()
+	}()
+	if err := s.Serve(lis); err != nil {
+		return fmt.Errorf("serving plugin: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,948 @@
+// Provider plugin protocol.
+//
+// A provider plugin is a separate binary or container that implements the
+// ProviderPlugin service. DAAP calls it exactly like an in-process provider:
+// Apply creates or updates the resources for a database, Delete removes them
+// and CheckHealth reports whether the database is ready.
+//
+// Breaking changes require a new package version (daap.provider.v2).
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: daap/provider/v1/provider.proto
+
+package providerv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Database holds the database fields a provider needs.
+type Database struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Id          string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name        string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Namespace   string                 `protobuf:"bytes,3,opt,name=namespace,proto3" json:"namespace,omitempty"`
+	ClusterName string                 `protobuf:"bytes,4,opt,name=cluster_name,json=clusterName,proto3" json:"cluster_name,omitempty"`
+	PoolerName  string                 `protobuf:"bytes,5,opt,name=pooler_name,json=poolerName,proto3" json:"pooler_name,omitempty"`
+	OwnerTeam   string                 `protobuf:"bytes,6,opt,name=owner_team,json=ownerTeam,proto3" json:"owner_team,omitempty"`
+	OwnerTeamId string                 `protobuf:"bytes,7,opt,name=owner_team_id,json=ownerTeamId,proto3" json:"owner_team_id,omitempty"`
+	Tier        string                 `protobuf:"bytes,8,opt,name=tier,proto3" json:"tier,omitempty"`
+	TierId      string                 `protobuf:"bytes,9,opt,name=tier_id,json=tierId,proto3" json:"tier_id,omitempty"`
+	Blueprint   string                 `protobuf:"bytes,10,opt,name=blueprint,proto3" json:"blueprint,omitempty"`
+	Provider    string                 `protobuf:"bytes,11,opt,name=provider,proto3" json:"provider,omitempty"`
+	// Owner team defaults for the labels and annotations of every resource
+	// the plugin creates. Blueprint values take precedence.
+	Labels      map[string]string `protobuf:"bytes,12,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Annotations map[string]string `protobuf:"bytes,13,rep,name=annotations,proto3" json:"annotations,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Topology    *Topology         `protobuf:"bytes,14,opt,name=topology,proto3" json:"topology,omitempty"`
+	Disruption  *Disruption       `protobuf:"bytes,15,opt,name=disruption,proto3" json:"disruption,omitempty"`
+	// Image references of the manifests mapped to the mirrored, pinned images
+	// to run instead. Plugins replace them in the resources they create.
+	Images   map[string]string `protobuf:"bytes,16,rep,name=images,proto3" json:"images,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Exposure *Exposure         `protobuf:"bytes,17,opt,name=exposure,proto3" json:"exposure,omitempty"`
+	// Friendly hostname to publish for the address clients connect to, or
+	// empty for none.
+	DnsName       string `protobuf:"bytes,18,opt,name=dns_name,json=dnsName,proto3" json:"dns_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Database) Reset() {
+	*x = Database{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Database) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Database) ProtoMessage() {}
+
+func (x *Database) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Database.ProtoReflect.Descriptor instead.
+func (*Database) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Database) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Database) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Database) GetNamespace() string {
+	if x != nil {
+		return x.Namespace
+	}
+	return ""
+}
+
+func (x *Database) GetClusterName() string {
+	if x != nil {
+		return x.ClusterName
+	}
+	return ""
+}
+
+func (x *Database) GetPoolerName() string {
+	if x != nil {
+		return x.PoolerName
+	}
+	return ""
+}
+
+func (x *Database) GetOwnerTeam() string {
+	if x != nil {
+		return x.OwnerTeam
+	}
+	return ""
+}
+
+func (x *Database) GetOwnerTeamId() string {
+	if x != nil {
+		return x.OwnerTeamId
+	}
+	return ""
+}
+
+func (x *Database) GetTier() string {
+	if x != nil {
+		return x.Tier
+	}
+	return ""
+}
+
+func (x *Database) GetTierId() string {
+	if x != nil {
+		return x.TierId
+	}
+	return ""
+}
+
+func (x *Database) GetBlueprint() string {
+	if x != nil {
+		return x.Blueprint
+	}
+	return ""
+}
+
+func (x *Database) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *Database) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Database) GetAnnotations() map[string]string {
+	if x != nil {
+		return x.Annotations
+	}
+	return nil
+}
+
+func (x *Database) GetTopology() *Topology {
+	if x != nil {
+		return x.Topology
+	}
+	return nil
+}
+
+func (x *Database) GetDisruption() *Disruption {
+	if x != nil {
+		return x.Disruption
+	}
+	return nil
+}
+
+func (x *Database) GetImages() map[string]string {
+	if x != nil {
+		return x.Images
+	}
+	return nil
+}
+
+func (x *Database) GetExposure() *Exposure {
+	if x != nil {
+		return x.Exposure
+	}
+	return nil
+}
+
+func (x *Database) GetDnsName() string {
+	if x != nil {
+		return x.DnsName
+	}
+	return ""
+}
+
+// Topology constrains where a database's instances run, from its tier.
+// Plugins that schedule instances enforce it over what the blueprint sets.
+type Topology struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One of "preferred" or "required"; empty leaves it to the blueprint.
+	ZoneSpread string `protobuf:"bytes,1,opt,name=zone_spread,json=zoneSpread,proto3" json:"zone_spread,omitempty"`
+	// Restricts instances to nodes with all of these labels.
+	NodeSelector  map[string]string `protobuf:"bytes,2,rep,name=node_selector,json=nodeSelector,proto3" json:"node_selector,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Topology) Reset() {
+	*x = Topology{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Topology) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Topology) ProtoMessage() {}
+
+func (x *Topology) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Topology.ProtoReflect.Descriptor instead.
+func (*Topology) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Topology) GetZoneSpread() string {
+	if x != nil {
+		return x.ZoneSpread
+	}
+	return ""
+}
+
+func (x *Topology) GetNodeSelector() map[string]string {
+	if x != nil {
+		return x.NodeSelector
+	}
+	return nil
+}
+
+// Disruption is how a database weathers node drains and evictions, from its
+// tier.
+type Disruption struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Instances evictions must leave running; zero leaves it to the plugin.
+	MinAvailable int32 `protobuf:"varint,1,opt,name=min_available,json=minAvailable,proto3" json:"min_available,omitempty"`
+	// Kubernetes PriorityClass of the instances, if set.
+	PriorityClassName string `protobuf:"bytes,2,opt,name=priority_class_name,json=priorityClassName,proto3" json:"priority_class_name,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *Disruption) Reset() {
+	*x = Disruption{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Disruption) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Disruption) ProtoMessage() {}
+
+func (x *Disruption) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Disruption.ProtoReflect.Descriptor instead.
+func (*Disruption) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Disruption) GetMinAvailable() int32 {
+	if x != nil {
+		return x.MinAvailable
+	}
+	return 0
+}
+
+func (x *Disruption) GetPriorityClassName() string {
+	if x != nil {
+		return x.PriorityClassName
+	}
+	return ""
+}
+
+// Exposure is how a database is reachable from outside the cluster.
+type Exposure struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One of "cluster", "internal" or "external"; empty means "cluster".
+	Type string `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	// Source CIDRs the load balancer accepts connections from; empty allows
+	// any.
+	AllowedSourceRanges []string `protobuf:"bytes,2,rep,name=allowed_source_ranges,json=allowedSourceRanges,proto3" json:"allowed_source_ranges,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *Exposure) Reset() {
+	*x = Exposure{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Exposure) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Exposure) ProtoMessage() {}
+
+func (x *Exposure) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Exposure.ProtoReflect.Descriptor instead.
+func (*Exposure) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Exposure) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Exposure) GetAllowedSourceRanges() []string {
+	if x != nil {
+		return x.AllowedSourceRanges
+	}
+	return nil
+}
+
+type ApplyRequest struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Database *Database              `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	// Blueprint manifests. They are Go templates; the plugin renders them
+	// with the database fields (see ADR 008 for the template variables).
+	Manifests     string `protobuf:"bytes,2,opt,name=manifests,proto3" json:"manifests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyRequest) Reset() {
+	*x = ApplyRequest{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyRequest) ProtoMessage() {}
+
+func (x *ApplyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyRequest.ProtoReflect.Descriptor instead.
+func (*ApplyRequest) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ApplyRequest) GetDatabase() *Database {
+	if x != nil {
+		return x.Database
+	}
+	return nil
+}
+
+func (x *ApplyRequest) GetManifests() string {
+	if x != nil {
+		return x.Manifests
+	}
+	return ""
+}
+
+type ApplyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApplyResponse) Reset() {
+	*x = ApplyResponse{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApplyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApplyResponse) ProtoMessage() {}
+
+func (x *ApplyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApplyResponse.ProtoReflect.Descriptor instead.
+func (*ApplyResponse) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{5}
+}
+
+type DeleteRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Database      *Database              `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteRequest) ProtoMessage() {}
+
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *DeleteRequest) GetDatabase() *Database {
+	if x != nil {
+		return x.Database
+	}
+	return nil
+}
+
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteResponse) ProtoMessage() {}
+
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{7}
+}
+
+type CheckHealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Database      *Database              `protobuf:"bytes,1,opt,name=database,proto3" json:"database,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckHealthRequest) Reset() {
+	*x = CheckHealthRequest{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckHealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckHealthRequest) ProtoMessage() {}
+
+func (x *CheckHealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckHealthRequest.ProtoReflect.Descriptor instead.
+func (*CheckHealthRequest) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CheckHealthRequest) GetDatabase() *Database {
+	if x != nil {
+		return x.Database
+	}
+	return nil
+}
+
+type CheckHealthResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// One of "provisioning", "ready" or "error".
+	Status string `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	// Connection details, set once the database is ready.
+	Host       *string `protobuf:"bytes,2,opt,name=host,proto3,oneof" json:"host,omitempty"`
+	Port       *int32  `protobuf:"varint,3,opt,name=port,proto3,oneof" json:"port,omitempty"`
+	SecretName *string `protobuf:"bytes,4,opt,name=secret_name,json=secretName,proto3,oneof" json:"secret_name,omitempty"`
+	// Load balancer hostname or address of a database exposed outside the
+	// cluster; empty until it has one.
+	ExternalHost string `protobuf:"bytes,5,opt,name=external_host,json=externalHost,proto3" json:"external_host,omitempty"`
+	// Instances backing the database; unset if the plugin does not report
+	// them.
+	Instances *InstanceStatus `protobuf:"bytes,6,opt,name=instances,proto3" json:"instances,omitempty"`
+	// Machine-readable cause of the status, e.g. FAILED_TO_CREATE_PRIMARY,
+	// and a human-readable detail of it. Empty if the plugin has nothing to
+	// add.
+	Reason        string `protobuf:"bytes,7,opt,name=reason,proto3" json:"reason,omitempty"`
+	Message       string `protobuf:"bytes,8,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckHealthResponse) Reset() {
+	*x = CheckHealthResponse{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckHealthResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckHealthResponse) ProtoMessage() {}
+
+func (x *CheckHealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckHealthResponse.ProtoReflect.Descriptor instead.
+func (*CheckHealthResponse) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CheckHealthResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CheckHealthResponse) GetHost() string {
+	if x != nil && x.Host != nil {
+		return *x.Host
+	}
+	return ""
+}
+
+func (x *CheckHealthResponse) GetPort() int32 {
+	if x != nil && x.Port != nil {
+		return *x.Port
+	}
+	return 0
+}
+
+func (x *CheckHealthResponse) GetSecretName() string {
+	if x != nil && x.SecretName != nil {
+		return *x.SecretName
+	}
+	return ""
+}
+
+func (x *CheckHealthResponse) GetExternalHost() string {
+	if x != nil {
+		return x.ExternalHost
+	}
+	return ""
+}
+
+func (x *CheckHealthResponse) GetInstances() *InstanceStatus {
+	if x != nil {
+		return x.Instances
+	}
+	return nil
+}
+
+func (x *CheckHealthResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CheckHealthResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+type InstanceStatus struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Instances requested.
+	Total int32 `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	// Instances ready to serve.
+	Ready int32 `protobuf:"varint,2,opt,name=ready,proto3" json:"ready,omitempty"`
+	// Name of the current primary; empty if none is elected.
+	Primary string `protobuf:"bytes,3,opt,name=primary,proto3" json:"primary,omitempty"`
+	// Largest lag of a replica behind the primary, in nanoseconds; unset if
+	// it is unknown.
+	ReplicationLagNanos *int64 `protobuf:"varint,4,opt,name=replication_lag_nanos,json=replicationLagNanos,proto3,oneof" json:"replication_lag_nanos,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *InstanceStatus) Reset() {
+	*x = InstanceStatus{}
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstanceStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstanceStatus) ProtoMessage() {}
+
+func (x *InstanceStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_daap_provider_v1_provider_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstanceStatus.ProtoReflect.Descriptor instead.
+func (*InstanceStatus) Descriptor() ([]byte, []int) {
+	return file_daap_provider_v1_provider_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *InstanceStatus) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *InstanceStatus) GetReady() int32 {
+	if x != nil {
+		return x.Ready
+	}
+	return 0
+}
+
+func (x *InstanceStatus) GetPrimary() string {
+	if x != nil {
+		return x.Primary
+	}
+	return ""
+}
+
+func (x *InstanceStatus) GetReplicationLagNanos() int64 {
+	if x != nil && x.ReplicationLagNanos != nil {
+		return *x.ReplicationLagNanos
+	}
+	return 0
+}
+
+var File_daap_provider_v1_provider_proto protoreflect.FileDescriptor
+
+const file_daap_provider_v1_provider_proto_rawDesc = "" +
+	"\n" +
+	"\x1fdaap/provider/v1/provider.proto\x12\x10daap.provider.v1\"\x88\a\n" +
+	"\bDatabase\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tnamespace\x18\x03 \x01(\tR\tnamespace\x12!\n" +
+	"\fcluster_name\x18\x04 \x01(\tR\vclusterName\x12\x1f\n" +
+	"\vpooler_name\x18\x05 \x01(\tR\n" +
+	"poolerName\x12\x1d\n" +
+	"\n" +
+	"owner_team\x18\x06 \x01(\tR\townerTeam\x12\"\n" +
+	"\rowner_team_id\x18\a \x01(\tR\vownerTeamId\x12\x12\n" +
+	"\x04tier\x18\b \x01(\tR\x04tier\x12\x17\n" +
+	"\atier_id\x18\t \x01(\tR\x06tierId\x12\x1c\n" +
+	"\tblueprint\x18\n" +
+	" \x01(\tR\tblueprint\x12\x1a\n" +
+	"\bprovider\x18\v \x01(\tR\bprovider\x12>\n" +
+	"\x06labels\x18\f \x03(\v2&.daap.provider.v1.Database.LabelsEntryR\x06labels\x12M\n" +
+	"\vannotations\x18\r \x03(\v2+.daap.provider.v1.Database.AnnotationsEntryR\vannotations\x126\n" +
+	"\btopology\x18\x0e \x01(\v2\x1a.daap.provider.v1.TopologyR\btopology\x12<\n" +
+	"\n" +
+	"disruption\x18\x0f \x01(\v2\x1c.daap.provider.v1.DisruptionR\n" +
+	"disruption\x12>\n" +
+	"\x06images\x18\x10 \x03(\v2&.daap.provider.v1.Database.ImagesEntryR\x06images\x126\n" +
+	"\bexposure\x18\x11 \x01(\v2\x1a.daap.provider.v1.ExposureR\bexposure\x12\x19\n" +
+	"\bdns_name\x18\x12 \x01(\tR\adnsName\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a>\n" +
+	"\x10AnnotationsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a9\n" +
+	"\vImagesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbf\x01\n" +
+	"\bTopology\x12\x1f\n" +
+	"\vzone_spread\x18\x01 \x01(\tR\n" +
+	"zoneSpread\x12Q\n" +
+	"\rnode_selector\x18\x02 \x03(\v2,.daap.provider.v1.Topology.NodeSelectorEntryR\fnodeSelector\x1a?\n" +
+	"\x11NodeSelectorEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"a\n" +
+	"\n" +
+	"Disruption\x12#\n" +
+	"\rmin_available\x18\x01 \x01(\x05R\fminAvailable\x12.\n" +
+	"\x13priority_class_name\x18\x02 \x01(\tR\x11priorityClassName\"R\n" +
+	"\bExposure\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x122\n" +
+	"\x15allowed_source_ranges\x18\x02 \x03(\tR\x13allowedSourceRanges\"d\n" +
+	"\fApplyRequest\x126\n" +
+	"\bdatabase\x18\x01 \x01(\v2\x1a.daap.provider.v1.DatabaseR\bdatabase\x12\x1c\n" +
+	"\tmanifests\x18\x02 \x01(\tR\tmanifests\"\x0f\n" +
+	"\rApplyResponse\"G\n" +
+	"\rDeleteRequest\x126\n" +
+	"\bdatabase\x18\x01 \x01(\v2\x1a.daap.provider.v1.DatabaseR\bdatabase\"\x10\n" +
+	"\x0eDeleteResponse\"L\n" +
+	"\x12CheckHealthRequest\x126\n" +
+	"\bdatabase\x18\x01 \x01(\v2\x1a.daap.provider.v1.DatabaseR\bdatabase\"\xbe\x02\n" +
+	"\x13CheckHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x17\n" +
+	"\x04host\x18\x02 \x01(\tH\x00R\x04host\x88\x01\x01\x12\x17\n" +
+	"\x04port\x18\x03 \x01(\x05H\x01R\x04port\x88\x01\x01\x12$\n" +
+	"\vsecret_name\x18\x04 \x01(\tH\x02R\n" +
+	"secretName\x88\x01\x01\x12#\n" +
+	"\rexternal_host\x18\x05 \x01(\tR\fexternalHost\x12>\n" +
+	"\tinstances\x18\x06 \x01(\v2 .daap.provider.v1.InstanceStatusR\tinstances\x12\x16\n" +
+	"\x06reason\x18\a \x01(\tR\x06reason\x12\x18\n" +
+	"\amessage\x18\b \x01(\tR\amessageB\a\n" +
+	"\x05_hostB\a\n" +
+	"\x05_portB\x0e\n" +
+	"\f_secret_name\"\xa9\x01\n" +
+	"\x0eInstanceStatus\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05ready\x18\x02 \x01(\x05R\x05ready\x12\x18\n" +
+	"\aprimary\x18\x03 \x01(\tR\aprimary\x127\n" +
+	"\x15replication_lag_nanos\x18\x04 \x01(\x03H\x00R\x13replicationLagNanos\x88\x01\x01B\x18\n" +
+	"\x16_replication_lag_nanos2\x83\x02\n" +
+	"\x0eProviderPlugin\x12H\n" +
+	"\x05Apply\x12\x1e.daap.provider.v1.ApplyRequest\x1a\x1f.daap.provider.v1.ApplyResponse\x12K\n" +
+	"\x06Delete\x12\x1f.daap.provider.v1.DeleteRequest\x1a .daap.provider.v1.DeleteResponse\x12Z\n" +
+	"\vCheckHealth\x12$.daap.provider.v1.CheckHealthRequest\x1a%.daap.provider.v1.CheckHealthResponseB9Z7github.com/daap14/daap/pkg/plugin/providerv1;providerv1b\x06proto3"
+
+var (
+	file_daap_provider_v1_provider_proto_rawDescOnce sync.Once
+	file_daap_provider_v1_provider_proto_rawDescData []byte
+)
+
+func file_daap_provider_v1_provider_proto_rawDescGZIP() []byte {
+	file_daap_provider_v1_provider_proto_rawDescOnce.Do(func() {
+		file_daap_provider_v1_provider_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_daap_provider_v1_provider_proto_rawDesc), len(file_daap_provider_v1_provider_proto_rawDesc)))
+	})
+	return file_daap_provider_v1_provider_proto_rawDescData
+}
+
+var file_daap_provider_v1_provider_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_daap_provider_v1_provider_proto_goTypes = []any{
+	(*Database)(nil),            // 0: daap.provider.v1.Database
+	(*Topology)(nil),            // 1: daap.provider.v1.Topology
+	(*Disruption)(nil),          // 2: daap.provider.v1.Disruption
+	(*Exposure)(nil),            // 3: daap.provider.v1.Exposure
+	(*ApplyRequest)(nil),        // 4: daap.provider.v1.ApplyRequest
+	(*ApplyResponse)(nil),       // 5: daap.provider.v1.ApplyResponse
+	(*DeleteRequest)(nil),       // 6: daap.provider.v1.DeleteRequest
+	(*DeleteResponse)(nil),      // 7: daap.provider.v1.DeleteResponse
+	(*CheckHealthRequest)(nil),  // 8: daap.provider.v1.CheckHealthRequest
+	(*CheckHealthResponse)(nil), // 9: daap.provider.v1.CheckHealthResponse
+	(*InstanceStatus)(nil),      // 10: daap.provider.v1.InstanceStatus
+	nil,                         // 11: daap.provider.v1.Database.LabelsEntry
+	nil,                         // 12: daap.provider.v1.Database.AnnotationsEntry
+	nil,                         // 13: daap.provider.v1.Database.ImagesEntry
+	nil,                         // 14: daap.provider.v1.Topology.NodeSelectorEntry
+}
+var file_daap_provider_v1_provider_proto_depIdxs = []int32{
+	11, // 0: daap.provider.v1.Database.labels:type_name -> daap.provider.v1.Database.LabelsEntry
+	12, // 1: daap.provider.v1.Database.annotations:type_name -> daap.provider.v1.Database.AnnotationsEntry
+	1,  // 2: daap.provider.v1.Database.topology:type_name -> daap.provider.v1.Topology
+	2,  // 3: daap.provider.v1.Database.disruption:type_name -> daap.provider.v1.Disruption
+	13, // 4: daap.provider.v1.Database.images:type_name -> daap.provider.v1.Database.ImagesEntry
+	3,  // 5: daap.provider.v1.Database.exposure:type_name -> daap.provider.v1.Exposure
+	14, // 6: daap.provider.v1.Topology.node_selector:type_name -> daap.provider.v1.Topology.NodeSelectorEntry
+	0,  // 7: daap.provider.v1.ApplyRequest.database:type_name -> daap.provider.v1.Database
+	0,  // 8: daap.provider.v1.DeleteRequest.database:type_name -> daap.provider.v1.Database
+	0,  // 9: daap.provider.v1.CheckHealthRequest.database:type_name -> daap.provider.v1.Database
+	10, // 10: daap.provider.v1.CheckHealthResponse.instances:type_name -> daap.provider.v1.InstanceStatus
+	4,  // 11: daap.provider.v1.ProviderPlugin.Apply:input_type -> daap.provider.v1.ApplyRequest
+	6,  // 12: daap.provider.v1.ProviderPlugin.Delete:input_type -> daap.provider.v1.DeleteRequest
+	8,  // 13: daap.provider.v1.ProviderPlugin.CheckHealth:input_type -> daap.provider.v1.CheckHealthRequest
+	5,  // 14: daap.provider.v1.ProviderPlugin.Apply:output_type -> daap.provider.v1.ApplyResponse
+	7,  // 15: daap.provider.v1.ProviderPlugin.Delete:output_type -> daap.provider.v1.DeleteResponse
+	9,  // 16: daap.provider.v1.ProviderPlugin.CheckHealth:output_type -> daap.provider.v1.CheckHealthResponse
+	14, // [14:17] is the sub-list for method output_type
+	11, // [11:14] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
+}
+
+func init() { file_daap_provider_v1_provider_proto_init() }
+func file_daap_provider_v1_provider_proto_init() {
+	if File_daap_provider_v1_provider_proto != nil {
+		return
+	}
+	file_daap_provider_v1_provider_proto_msgTypes[9].OneofWrappers = []any{}
+	file_daap_provider_v1_provider_proto_msgTypes[10].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_daap_provider_v1_provider_proto_rawDesc), len(file_daap_provider_v1_provider_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_daap_provider_v1_provider_proto_goTypes,
+		DependencyIndexes: file_daap_provider_v1_provider_proto_depIdxs,
+		MessageInfos:      file_daap_provider_v1_provider_proto_msgTypes,
+	}.Build()
+	File_daap_provider_v1_provider_proto = out.File
+	file_daap_provider_v1_provider_proto_goTypes = nil
+	file_daap_provider_v1_provider_proto_depIdxs = nil
+}
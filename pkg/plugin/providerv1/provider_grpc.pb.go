@@ -0,0 +1,212 @@
+// Provider plugin protocol.
+//
+// A provider plugin is a separate binary or container that implements the
+// ProviderPlugin service. DAAP calls it exactly like an in-process provider:
+// Apply creates or updates the resources for a database, Delete removes them
+// and CheckHealth reports whether the database is ready.
+//
+// Breaking changes require a new package version (daap.provider.v2).
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: daap/provider/v1/provider.proto
+
+package providerv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ProviderPlugin_Apply_FullMethodName       = "/daap.provider.v1.ProviderPlugin/Apply"
+	ProviderPlugin_Delete_FullMethodName      = "/daap.provider.v1.ProviderPlugin/Delete"
+	ProviderPlugin_CheckHealth_FullMethodName = "/daap.provider.v1.ProviderPlugin/CheckHealth"
+)
+
+// ProviderPluginClient is the client API for ProviderPlugin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type ProviderPluginClient interface {
+	// Apply renders and creates or updates all resources for a database.
+	Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error)
+	// Delete removes all resources associated with a database.
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// CheckHealth returns the current health of a database's resources.
+	CheckHealth(ctx context.Context, in *CheckHealthRequest, opts ...grpc.CallOption) (*CheckHealthResponse, error)
+}
+
+type providerPluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewProviderPluginClient(cc grpc.ClientConnInterface) ProviderPluginClient {
+	return &providerPluginClient{cc}
+}
+
+func (c *providerPluginClient) Apply(ctx context.Context, in *ApplyRequest, opts ...grpc.CallOption) (*ApplyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ApplyResponse)
+	err := c.cc.Invoke(ctx, ProviderPlugin_Apply_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerPluginClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, ProviderPlugin_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *providerPluginClient) CheckHealth(ctx context.Context, in *CheckHealthRequest, opts ...grpc.CallOption) (*CheckHealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckHealthResponse)
+	err := c.cc.Invoke(ctx, ProviderPlugin_CheckHealth_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ProviderPluginServer is the server API for ProviderPlugin service.
+// All implementations must embed UnimplementedProviderPluginServer
+// for forward compatibility.
+type ProviderPluginServer interface {
+	// Apply renders and creates or updates all resources for a database.
+	Apply(context.Context, *ApplyRequest) (*ApplyResponse, error)
+	// Delete removes all resources associated with a database.
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// CheckHealth returns the current health of a database's resources.
+	CheckHealth(context.Context, *CheckHealthRequest) (*CheckHealthResponse, error)
+	mustEmbedUnimplementedProviderPluginServer()
+}
+
+// UnimplementedProviderPluginServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedProviderPluginServer struct{}
+
+func (UnimplementedProviderPluginServer) Apply(context.Context, *ApplyRequest) (*ApplyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Apply not implemented")
+}
+func (UnimplementedProviderPluginServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedProviderPluginServer) CheckHealth(context.Context, *CheckHealthRequest) (*CheckHealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CheckHealth not implemented")
+}
+func (UnimplementedProviderPluginServer) mustEmbedUnimplementedProviderPluginServer() {}
+func (UnimplementedProviderPluginServer) testEmbeddedByValue()                        {}
+
+// UnsafeProviderPluginServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ProviderPluginServer will
+// result in compilation errors.
+type UnsafeProviderPluginServer interface {
+	mustEmbedUnimplementedProviderPluginServer()
+}
+
+func RegisterProviderPluginServer(s grpc.ServiceRegistrar, srv ProviderPluginServer) {
+	// If the following call pancis, it indicates UnimplementedProviderPluginServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ProviderPlugin_ServiceDesc, srv)
+}
+
+func _ProviderPlugin_Apply_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ApplyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderPluginServer).Apply(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProviderPlugin_Apply_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderPluginServer).Apply(ctx, req.(*ApplyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviderPlugin_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderPluginServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProviderPlugin_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderPluginServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ProviderPlugin_CheckHealth_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckHealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ProviderPluginServer).CheckHealth(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ProviderPlugin_CheckHealth_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ProviderPluginServer).CheckHealth(ctx, req.(*CheckHealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// ProviderPlugin_ServiceDesc is the grpc.ServiceDesc for ProviderPlugin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ProviderPlugin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "daap.provider.v1.ProviderPlugin",
+	HandlerType: (*ProviderPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Apply",
+			Handler:    _ProviderPlugin_Apply_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _ProviderPlugin_Delete_Handler,
+		},
+		{
+			MethodName: "CheckHealth",
+			Handler:    _ProviderPlugin_CheckHealth_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "daap/provider/v1/provider.proto",
+}
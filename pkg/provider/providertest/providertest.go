@@ -0,0 +1,201 @@
+// Package providertest is a conformance suite for provider.Provider
+// implementations. A new provider proves compliance by calling Run from its
+// own tests with a Harness describing how to construct and inspect it.
+package providertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/provider"
+)
+
+// Harness adapts a provider implementation to the conformance suite.
+type Harness struct {
+	// New returns a fresh provider whose backend holds no resources.
+	// It is called once per subtest. Required.
+	New func(t *testing.T) provider.Provider
+
+	// Manifests is a valid blueprint for the provider. Required.
+	Manifests string
+
+	// InvalidManifests, if set, is a blueprint the provider must reject.
+	InvalidManifests string
+
+	// Labels returns the labels of every backend resource that currently
+	// exists for db. If nil, label and cleanup checks are skipped.
+	Labels func(t *testing.T, p provider.Provider, db provider.ProviderDatabase) []map[string]string
+
+	// MarkReady simulates the backend finishing provisioning of db. If nil,
+	// the ready-state checks are skipped.
+	MarkReady func(t *testing.T, p provider.Provider, db provider.ProviderDatabase)
+}
+
+// Database returns a sample ProviderDatabase for the given name.
+func Database(name string) provider.ProviderDatabase {
+	return provider.ProviderDatabase{
+		ID:          uuid.New(),
+		Name:        name,
+		Namespace:   "daap-contract",
+		ClusterName: "daap-" + name,
+		PoolerName:  "daap-" + name + "-pooler",
+		OwnerTeam:   "contract",
+		OwnerTeamID: uuid.New(),
+		Tier:        "standard",
+		TierID:      uuid.New(),
+		Blueprint:   "contract-blueprint",
+	}
+}
+
+var validStatuses = []string{"provisioning", "ready", "error"}
+
+// Run executes the conformance suite against the provider described by h.
+func Run(t *testing.T, h Harness) {
+	t.Helper()
+	require.NotNil(t, h.New, "Harness.New is required")
+	require.NotEmpty(t, h.Manifests, "Harness.Manifests is required")
+
+	ctx := context.Background()
+
+	t.Run("ApplySucceeds", func(t *testing.T) {
+		p := h.New(t)
+		require.NoError(t, p.Apply(ctx, Database("apply"), h.Manifests))
+	})
+
+	t.Run("ApplyIsIdempotent", func(t *testing.T) {
+		p := h.New(t)
+		db := Database("idempotent")
+		require.NoError(t, p.Apply(ctx, db, h.Manifests))
+		require.NoError(t, p.Apply(ctx, db, h.Manifests), "re-applying the same manifests must succeed")
+
+		if h.Labels != nil {
+			first := len(h.Labels(t, p, db))
+			require.NoError(t, p.Apply(ctx, db, h.Manifests))
+			assert.Equal(t, first, len(h.Labels(t, p, db)), "re-applying must not duplicate resources")
+		}
+	})
+
+	t.Run("ApplyRejectsInvalidManifests", func(t *testing.T) {
+		if h.InvalidManifests == "" {
+			t.Skip("Harness.InvalidManifests not set")
+		}
+		p := h.New(t)
+		assert.Error(t, p.Apply(ctx, Database("invalid"), h.InvalidManifests))
+	})
+
+	t.Run("ApplyLabelsResources", func(t *testing.T) {
+		if h.Labels == nil {
+			t.Skip("Harness.Labels not set")
+		}
+		p := h.New(t)
+		db := Database("labels")
+		require.NoError(t, p.Apply(ctx, db, h.Manifests))
+
+		resources := h.Labels(t, p, db)
+		require.NotEmpty(t, resources, "Apply must create at least one resource")
+		for _, labels := range resources {
+			assert.Equal(t, db.Name, labels[provider.LabelDatabase])
+			assert.Equal(t, provider.LabelManagedByValue, labels[provider.LabelManagedBy])
+		}
+	})
+
+	t.Run("DeleteRemovesResources", func(t *testing.T) {
+		p := h.New(t)
+		db := Database("delete")
+		require.NoError(t, p.Apply(ctx, db, h.Manifests))
+		require.NoError(t, p.Delete(ctx, db))
+
+		if h.Labels != nil {
+			assert.Empty(t, h.Labels(t, p, db), "Delete must remove every labeled resource")
+		}
+	})
+
+	t.Run("DeleteIsIdempotent", func(t *testing.T) {
+		p := h.New(t)
+		db := Database("delete-twice")
+		require.NoError(t, p.Apply(ctx, db, h.Manifests))
+		require.NoError(t, p.Delete(ctx, db))
+		require.NoError(t, p.Delete(ctx, db), "deleting an already-deleted database must succeed")
+	})
+
+	t.Run("DeleteUnknownDatabaseSucceeds", func(t *testing.T) {
+		p := h.New(t)
+		assert.NoError(t, p.Delete(ctx, Database("never-applied")))
+	})
+
+	t.Run("DeleteLeavesOtherDatabases", func(t *testing.T) {
+		if h.Labels == nil {
+			t.Skip("Harness.Labels not set")
+		}
+		p := h.New(t)
+		keep, drop := Database("keep"), Database("drop")
+		require.NoError(t, p.Apply(ctx, keep, h.Manifests))
+		require.NoError(t, p.Apply(ctx, drop, h.Manifests))
+		require.NoError(t, p.Delete(ctx, drop))
+		assert.NotEmpty(t, h.Labels(t, p, keep), "Delete must only remove resources of its own database")
+	})
+
+	t.Run("DeleteForegroundConfirmsRemoval", func(t *testing.T) {
+		p := h.New(t)
+		confirmer, ok := p.(provider.DeletionConfirmer)
+		if !ok {
+			t.Skip("provider does not implement provider.DeletionConfirmer")
+		}
+		db := Database("delete-foreground")
+		require.NoError(t, p.Apply(ctx, db, h.Manifests))
+
+		state, err := confirmer.DeleteForeground(ctx, db, 0)
+		if errors.Is(err, provider.ErrNotSupported) {
+			t.Skip("provider does not support provider.DeletionConfirmer")
+		}
+		require.NoError(t, err)
+		if state == provider.DeletionGone && h.Labels != nil {
+			assert.Empty(t, h.Labels(t, p, db), "DeleteForeground reported gone with resources left")
+		}
+
+		state, err = confirmer.DeleteForeground(ctx, db, 0)
+		require.NoError(t, err, "deleting an already-deleted database must succeed")
+		assert.Contains(t, []provider.DeletionState{provider.DeletionDeleting, provider.DeletionGone}, state)
+	})
+
+	t.Run("CheckHealthAfterApply", func(t *testing.T) {
+		p := h.New(t)
+		db := Database("health")
+		require.NoError(t, p.Apply(ctx, db, h.Manifests))
+
+		result, err := p.CheckHealth(ctx, db)
+		require.NoError(t, err)
+		assert.Contains(t, validStatuses, result.Status)
+	})
+
+	t.Run("CheckHealthUnknownDatabaseIsNotReady", func(t *testing.T) {
+		p := h.New(t)
+		result, err := p.CheckHealth(ctx, Database("missing"))
+		if err != nil {
+			return
+		}
+		assert.NotEqual(t, "ready", result.Status, "a database with no resources must never report ready")
+	})
+
+	t.Run("CheckHealthReadyHasConnectionDetails", func(t *testing.T) {
+		if h.MarkReady == nil {
+			t.Skip("Harness.MarkReady not set")
+		}
+		p := h.New(t)
+		db := Database("ready")
+		require.NoError(t, p.Apply(ctx, db, h.Manifests))
+		h.MarkReady(t, p, db)
+
+		result, err := p.CheckHealth(ctx, db)
+		require.NoError(t, err)
+		assert.Equal(t, "ready", result.Status)
+		assert.NotNil(t, result.Host, "ready databases must report a host")
+		assert.NotNil(t, result.Port, "ready databases must report a port")
+		assert.NotNil(t, result.SecretName, "ready databases must report a secret name")
+	})
+}
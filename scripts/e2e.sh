@@ -0,0 +1,87 @@
+#!/usr/bin/env bash
+# e2e.sh — Run the end-to-end suite against a kind cluster with real CNPG.
+# Creates the cluster (unless it exists), installs the CNPG operator, starts
+# the DAAP server against it, runs `go test -tags e2e ./tests/e2e/...`, and
+# tears everything down again unless E2E_KEEP=1.
+set -euo pipefail
+
+CLUSTER_NAME="${KIND_CLUSTER:-daap-e2e}"
+CNPG_VERSION="${CNPG_VERSION:-1.25.1}"
+CNPG_RELEASE_BRANCH="$(echo "$CNPG_VERSION" | cut -d. -f1-2)"
+E2E_PORT="${E2E_PORT:-18080}"
+E2E_DATABASE_URL="${E2E_DATABASE_URL:-memory://}"
+NAMESPACE="${NAMESPACE:-default}"
+WORK_DIR="$(mktemp -d)"
+KUBECONFIG_PATH="${WORK_DIR}/kubeconfig"
+SERVER_LOG="${WORK_DIR}/server.log"
+SERVER_PID=""
+
+cleanup() {
+  if [[ -n "${SERVER_PID}" ]]; then
+    kill "${SERVER_PID}" 2>/dev/null || true
+    wait "${SERVER_PID}" 2>/dev/null || true
+  fi
+  if [[ "${E2E_KEEP:-0}" != "1" ]]; then
+    kind delete cluster --name "${CLUSTER_NAME}" >/dev/null 2>&1 || true
+    rm -rf "${WORK_DIR}"
+  else
+    echo "E2E_KEEP=1: leaving cluster '${CLUSTER_NAME}' and ${WORK_DIR} in place."
+  fi
+}
+trap cleanup EXIT
+
+for bin in kind kubectl go; do
+  command -v "${bin}" >/dev/null 2>&1 || { echo "e2e: '${bin}' is required" >&2; exit 1; }
+done
+
+echo "==> Creating kind cluster '${CLUSTER_NAME}'..."
+if ! kind get clusters 2>/dev/null | grep -q "^${CLUSTER_NAME}$"; then
+  kind create cluster --name "${CLUSTER_NAME}" --wait 120s
+fi
+kind get kubeconfig --name "${CLUSTER_NAME}" > "${KUBECONFIG_PATH}"
+export KUBECONFIG="${KUBECONFIG_PATH}"
+
+echo "==> Installing CNPG operator v${CNPG_VERSION}..."
+kubectl apply --server-side -f \
+  "https://raw.githubusercontent.com/cloudnative-pg/cloudnative-pg/release-${CNPG_RELEASE_BRANCH}/releases/cnpg-${CNPG_VERSION}.yaml"
+kubectl rollout status deployment/cnpg-controller-manager -n cnpg-system --timeout=180s
+
+echo "==> Starting DAAP server on :${E2E_PORT} (DATABASE_URL=${E2E_DATABASE_URL%%://*}://...)..."
+go build -o "${WORK_DIR}/daap" ./cmd/server
+PORT="${E2E_PORT}" \
+  DATABASE_URL="${E2E_DATABASE_URL}" \
+  KUBECONFIG_PATH="${KUBECONFIG_PATH}" \
+  NAMESPACE="${NAMESPACE}" \
+  RECONCILER_INTERVAL=5 \
+  BCRYPT_COST=4 \
+  LOG_LEVEL=info \
+  "${WORK_DIR}/daap" > "${SERVER_LOG}" 2>&1 &
+SERVER_PID=$!
+
+for _ in $(seq 1 60); do
+  if curl -fsS "http://127.0.0.1:${E2E_PORT}/health" >/dev/null 2>&1; then
+    break
+  fi
+  sleep 1
+done
+
+SUPERUSER_KEY="$(grep -o '"key":"[^"]*"' "${SERVER_LOG}" | head -1 | cut -d'"' -f4)"
+if [[ -z "${SUPERUSER_KEY}" ]]; then
+  echo "e2e: could not read the bootstrap superuser key from the server log" >&2
+  cat "${SERVER_LOG}" >&2
+  exit 1
+fi
+
+echo "==> Running e2e tests..."
+status=0
+DAAP_E2E_URL="http://127.0.0.1:${E2E_PORT}" \
+  DAAP_E2E_SUPERUSER_KEY="${SUPERUSER_KEY}" \
+  KUBECONFIG_PATH="${KUBECONFIG_PATH}" \
+  NAMESPACE="${NAMESPACE}" \
+  go test -tags=e2e -count=1 -timeout 30m -v ./tests/e2e/... || status=$?
+
+if [[ "${status}" -ne 0 ]]; then
+  echo "==> Server log:"
+  cat "${SERVER_LOG}"
+fi
+exit "${status}"
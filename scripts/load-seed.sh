@@ -0,0 +1,45 @@
+#!/usr/bin/env bash
+# load-seed.sh — Generate a load-test scenario against a running DAAP server.
+# Creates a platform team and user, a ConfigMap-only blueprint (cheap to apply,
+# so thousands of databases fit on a local cluster), a tier, and
+# LOAD_DATABASES databases. Prints the exports needed by the k6 scenarios.
+#
+# Usage: DAAP_URL=http://localhost:8080 SUPERUSER_KEY=... scripts/load-seed.sh
+set -euo pipefail
+
+DAAP_URL="${DAAP_URL:-http://localhost:8080}"
+SUPERUSER_KEY="${SUPERUSER_KEY:?SUPERUSER_KEY is required}"
+LOAD_DATABASES="${LOAD_DATABASES:-1000}"
+LOAD_PREFIX="${LOAD_PREFIX:-load}"
+CONCURRENCY="${CONCURRENCY:-16}"
+
+command -v jq >/dev/null 2>&1 || { echo "load-seed: 'jq' is required" >&2; exit 1; }
+
+api() {
+  local method="$1" path="$2" key="$3" body="${4:-}"
+  curl -fsS -X "${method}" "${DAAP_URL}${path}" \
+    -H "Content-Type: application/json" -H "X-API-Key: ${key}" \
+    ${body:+-d "${body}"}
+}
+
+echo "==> Creating team, user, blueprint and tier (prefix '${LOAD_PREFIX}')..." >&2
+TEAM_ID="$(api POST /teams "${SUPERUSER_KEY}" \
+  "{\"name\":\"${LOAD_PREFIX}-team\",\"role\":\"platform\"}" | jq -r .data.id)"
+API_KEY="$(api POST /users "${SUPERUSER_KEY}" \
+  "{\"name\":\"${LOAD_PREFIX}-user\",\"teamId\":\"${TEAM_ID}\"}" | jq -r .data.apiKey)"
+
+MANIFESTS='apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {{ .ClusterName }}\n  namespace: {{ .Namespace }}\ndata:\n  purpose: load-test\n'
+api POST /blueprints "${API_KEY}" \
+  "{\"name\":\"${LOAD_PREFIX}-bp\",\"provider\":\"cnpg\",\"manifests\":\"${MANIFESTS}\"}" >/dev/null
+api POST /tiers "${API_KEY}" \
+  "{\"name\":\"${LOAD_PREFIX}-tier\",\"blueprintName\":\"${LOAD_PREFIX}-bp\",\"destructionStrategy\":\"hard_delete\"}" >/dev/null
+
+echo "==> Creating ${LOAD_DATABASES} databases (concurrency ${CONCURRENCY})..." >&2
+export -f api
+export DAAP_URL API_KEY LOAD_PREFIX
+seq -f "%05g" 1 "${LOAD_DATABASES}" | xargs -P "${CONCURRENCY}" -I{} bash -c \
+  'api POST /databases "${API_KEY}" "{\"name\":\"${LOAD_PREFIX}-db-{}\",\"ownerTeam\":\"${LOAD_PREFIX}-team\",\"tier\":\"${LOAD_PREFIX}-tier\",\"purpose\":\"load test\"}" >/dev/null'
+
+echo "==> Scenario ready. Export these before running k6:" >&2
+echo "export DAAP_URL=${DAAP_URL}"
+echo "export DAAP_API_KEY=${API_KEY}"
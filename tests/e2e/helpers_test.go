@@ -0,0 +1,115 @@
+//go:build e2e
+
+package e2e_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+// e2eEnv holds the endpoints and credentials of the server under test.
+// scripts/e2e.sh sets these variables after starting the server.
+type e2eEnv struct {
+	baseURL   string
+	superKey  string
+	namespace string
+	dynamic   dynamic.Interface
+	http      *http.Client
+}
+
+func newEnv(t *testing.T) *e2eEnv {
+	t.Helper()
+
+	baseURL := os.Getenv("DAAP_E2E_URL")
+	superKey := os.Getenv("DAAP_E2E_SUPERUSER_KEY")
+	if baseURL == "" || superKey == "" {
+		t.Skip("skipping: DAAP_E2E_URL and DAAP_E2E_SUPERUSER_KEY must be set (run `make test-e2e`)")
+	}
+
+	namespace := os.Getenv("NAMESPACE")
+	if namespace == "" {
+		namespace = "default"
+	}
+
+	var opts []k8s.ClientOption
+	if path := os.Getenv("KUBECONFIG_PATH"); path != "" {
+		opts = append(opts, k8s.WithKubeconfig(path))
+	}
+	client, err := k8s.NewClient(opts...)
+	require.NoError(t, err, "e2e tests need access to the kind cluster")
+
+	return &e2eEnv{
+		baseURL:   baseURL,
+		superKey:  superKey,
+		namespace: namespace,
+		dynamic:   client.DynamicClient(),
+		http:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// envelope is the standard API response envelope.
+type envelope struct {
+	Data  json.RawMessage `json:"data"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// do sends a JSON request with the given API key and decodes the envelope's
+// data into out (if non-nil). It returns the HTTP status code.
+func (e *e2eEnv) do(t *testing.T, method, path, apiKey string, body, out any) int {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		require.NoError(t, err)
+		reader = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), method, e.baseURL+path, reader)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-API-Key", apiKey)
+
+	resp, err := e.http.Do(req)
+	require.NoError(t, err)
+	defer func() { _ = resp.Body.Close() }()
+
+	if out != nil && resp.StatusCode < 300 && resp.StatusCode != http.StatusNoContent {
+		var env envelope
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&env))
+		require.NoError(t, json.Unmarshal(env.Data, out))
+	}
+	return resp.StatusCode
+}
+
+// eventually polls cond every interval until it returns true or timeout elapses.
+func eventually(t *testing.T, timeout, interval time.Duration, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(interval)
+	}
+	t.Fatalf("timed out after %s waiting for %s", timeout, what)
+}
+
+func uniqueName(prefix string) string {
+	return fmt.Sprintf("%s-%d", prefix, time.Now().Unix()%100000)
+}
@@ -0,0 +1,154 @@
+//go:build e2e
+
+package e2e_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var (
+	clusterGVR = schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"}
+	secretGVR  = schema.GroupVersionResource{Group: "", Version: "v1", Resource: "secrets"}
+)
+
+const e2eManifests = `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: {{ .ClusterName }}
+  namespace: {{ .Namespace }}
+spec:
+  instances: 1
+  storage:
+    size: 1Gi
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Pooler
+metadata:
+  name: {{ .PoolerName }}
+  namespace: {{ .Namespace }}
+spec:
+  cluster:
+    name: {{ .ClusterName }}
+  instances: 1
+  type: rw
+  pgbouncer:
+    poolMode: transaction
+`
+
+type idResponse struct {
+	ID string `json:"id"`
+}
+
+type databaseResponse struct {
+	ID                   string  `json:"id"`
+	Name                 string  `json:"name"`
+	Status               string  `json:"status"`
+	ClusterName          string  `json:"clusterName"`
+	Host                 *string `json:"host"`
+	Port                 *int    `json:"port"`
+	SecretName           *string `json:"secretName"`
+	CredentialsRotatedAt *string `json:"credentialsRotatedAt"`
+}
+
+// TestDatabaseLifecycle walks create → ready → rotate credentials → delete
+// against a real CNPG operator and asserts the resulting cluster state.
+func TestDatabaseLifecycle(t *testing.T) {
+	env := newEnv(t)
+	ctx := context.Background()
+
+	// Arrange — platform team, platform user, blueprint and tier.
+	teamName := uniqueName("e2e-team")
+	var tm idResponse
+	require.Equal(t, http.StatusCreated, env.do(t, http.MethodPost, "/teams", env.superKey,
+		map[string]string{"name": teamName, "role": "platform"}, &tm))
+	t.Cleanup(func() { env.do(t, http.MethodDelete, "/teams/"+tm.ID, env.superKey, nil, nil) })
+
+	var user struct {
+		ID     string `json:"id"`
+		ApiKey string `json:"apiKey"`
+	}
+	require.Equal(t, http.StatusCreated, env.do(t, http.MethodPost, "/users", env.superKey,
+		map[string]string{"name": uniqueName("e2e-user"), "teamId": tm.ID}, &user))
+	t.Cleanup(func() { env.do(t, http.MethodDelete, "/users/"+user.ID, env.superKey, nil, nil) })
+	key := user.ApiKey
+
+	bpName := uniqueName("e2e-bp")
+	var bp idResponse
+	require.Equal(t, http.StatusCreated, env.do(t, http.MethodPost, "/blueprints", key,
+		map[string]string{"name": bpName, "provider": "cnpg", "manifests": e2eManifests}, &bp))
+	t.Cleanup(func() { env.do(t, http.MethodDelete, "/blueprints/"+bp.ID, key, nil, nil) })
+
+	tierName := uniqueName("e2e-tier")
+	var tr idResponse
+	require.Equal(t, http.StatusCreated, env.do(t, http.MethodPost, "/tiers", key,
+		map[string]any{"name": tierName, "blueprintName": bpName, "destructionStrategy": "hard_delete"}, &tr))
+	t.Cleanup(func() { env.do(t, http.MethodDelete, "/tiers/"+tr.ID, key, nil, nil) })
+
+	// Act — create the database.
+	dbName := uniqueName("e2e-db")
+	var db databaseResponse
+	require.Equal(t, http.StatusCreated, env.do(t, http.MethodPost, "/databases", key,
+		map[string]string{"name": dbName, "ownerTeam": teamName, "tier": tierName, "purpose": "e2e"}, &db))
+	assert.Equal(t, "provisioning", db.Status)
+
+	// Assert — the CNPG cluster exists with DAAP labels.
+	cluster, err := env.dynamic.Resource(clusterGVR).Namespace(env.namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, dbName, cluster.GetLabels()["daap.io/database"])
+	assert.Equal(t, "daap", cluster.GetLabels()["app.kubernetes.io/managed-by"])
+
+	// Assert — the reconciler moves the database to ready once CNPG is healthy.
+	eventually(t, 10*time.Minute, 5*time.Second, "database to become ready", func() bool {
+		var got databaseResponse
+		if env.do(t, http.MethodGet, "/databases/"+db.ID, key, nil, &got) != http.StatusOK {
+			return false
+		}
+		db = got
+		return got.Status == "ready"
+	})
+	require.NotNil(t, db.Host)
+	require.NotNil(t, db.SecretName)
+
+	cluster, err = env.dynamic.Resource(clusterGVR).Namespace(env.namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	phase, _, _ := unstructured.NestedString(cluster.Object, "status", "phase")
+	assert.Equal(t, "Cluster in healthy state", phase)
+
+	secret, err := env.dynamic.Resource(secretGVR).Namespace(env.namespace).Get(ctx, *db.SecretName, metav1.GetOptions{})
+	require.NoError(t, err, "application credentials secret must exist once ready")
+	password, _, _ := unstructured.NestedString(secret.Object, "data", "password")
+	require.NotEmpty(t, password)
+	assert.Nil(t, db.CredentialsRotatedAt)
+
+	// Act — rotate the application credentials.
+	var rotated databaseResponse
+	require.Equal(t, http.StatusOK, env.do(t, http.MethodPost, "/databases/"+db.ID+"/rotate-credentials", key, nil, &rotated))
+
+	// Assert — the rotation is recorded and the secret holds a new password.
+	require.NotNil(t, rotated.CredentialsRotatedAt)
+	secret, err = env.dynamic.Resource(secretGVR).Namespace(env.namespace).Get(ctx, *db.SecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	rotatedPassword, _, _ := unstructured.NestedString(secret.Object, "data", "password")
+	assert.NotEmpty(t, rotatedPassword)
+	assert.NotEqual(t, password, rotatedPassword, "rotation must change the password in the secret")
+
+	// Act — delete the database.
+	require.Equal(t, http.StatusNoContent, env.do(t, http.MethodDelete, "/databases/"+db.ID, key, nil, nil))
+
+	// Assert — the record is gone and the cluster is removed.
+	assert.Equal(t, http.StatusNotFound, env.do(t, http.MethodGet, "/databases/"+db.ID, key, nil, nil))
+	eventually(t, 5*time.Minute, 5*time.Second, "CNPG cluster to be removed", func() bool {
+		_, err := env.dynamic.Resource(clusterGVR).Namespace(env.namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+		return k8serrors.IsNotFound(err)
+	})
+}
@@ -0,0 +1,38 @@
+// k6 scenario: auth middleware cost, isolated on the cheapest authenticated
+// route (GET /tiers). Compares valid keys against invalid-key rejections.
+import http from 'k6/http';
+import { check } from 'k6';
+
+const BASE_URL = __ENV.DAAP_URL || 'http://localhost:8080';
+const API_KEY = __ENV.DAAP_API_KEY;
+
+export const options = {
+  scenarios: {
+    valid: {
+      executor: 'constant-vus',
+      vus: Number(__ENV.VUS || 10),
+      duration: __ENV.DURATION || '1m',
+      exec: 'validKey',
+    },
+    invalid: {
+      executor: 'constant-vus',
+      vus: 2,
+      duration: __ENV.DURATION || '1m',
+      exec: 'invalidKey',
+    },
+  },
+  thresholds: {
+    'http_req_duration{scenario:valid}': ['p(95)<500'],
+    'http_req_duration{scenario:invalid}': ['p(95)<50'],
+  },
+};
+
+export function validKey() {
+  const res = http.get(`${BASE_URL}/tiers`, { headers: { 'X-API-Key': API_KEY } });
+  check(res, { 'status is 200': (r) => r.status === 200 });
+}
+
+export function invalidKey() {
+  const res = http.get(`${BASE_URL}/tiers`, { headers: { 'X-API-Key': 'daap_invalidinvalidinvalid' } });
+  check(res, { 'status is 401': (r) => r.status === 401 });
+}
@@ -0,0 +1,34 @@
+// k6 scenario: paginated GET /databases under constant arrival rate.
+// Seed first with scripts/load-seed.sh, then: make load-test
+import http from 'k6/http';
+import { check } from 'k6';
+
+const BASE_URL = __ENV.DAAP_URL || 'http://localhost:8080';
+const API_KEY = __ENV.DAAP_API_KEY;
+
+export const options = {
+  scenarios: {
+    list: {
+      executor: 'constant-arrival-rate',
+      rate: Number(__ENV.RATE || 50),
+      timeUnit: '1s',
+      duration: __ENV.DURATION || '1m',
+      preAllocatedVUs: 20,
+      maxVUs: 100,
+    },
+  },
+  thresholds: {
+    http_req_failed: ['rate<0.01'],
+    // Includes one bcrypt comparison per request (see docs/performance/baseline.md).
+    http_req_duration: ['p(95)<500'],
+  },
+};
+
+export default function () {
+  const page = 1 + Math.floor(Math.random() * 10);
+  const res = http.get(`${BASE_URL}/databases?page=${page}&limit=100`, {
+    headers: { 'X-API-Key': API_KEY },
+    tags: { name: 'GET /databases' },
+  });
+  check(res, { 'status is 200': (r) => r.status === 200 });
+}
@@ -0,0 +1,249 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	specpkg "github.com/daap14/daap/api"
+	"github.com/daap14/daap/internal/api"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/catalog"
+	"github.com/daap14/daap/internal/config"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/retention"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// checkFixture is a router with every route registered, backed by in-memory
+// repositories, with API keys for the superuser, a platform user, a product
+// user and a read-only platform user, and a database owned by the product
+// user's team.
+type checkFixture struct {
+	router *chi.Mux
+	keys   map[string]string
+	dbID   uuid.UUID
+}
+
+func newCheckFixture(t *testing.T) *checkFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	authService := auth.NewService(repos.Users, repos.Teams, 4)
+
+	superKey, err := authService.BootstrapSuperuser(ctx)
+	require.NoError(t, err)
+	keys := map[string]string{"superuser": superKey}
+	teams := map[string]uuid.UUID{}
+	for _, role := range []string{"platform", "product"} {
+		tm := &team.Team{Name: role + "-team", Role: role}
+		require.NoError(t, repos.Teams.Create(ctx, tm))
+		key, prefix, hash, err := authService.GenerateKey()
+		require.NoError(t, err)
+		require.NoError(t, repos.Users.Create(ctx, &auth.User{Name: role, TeamID: &tm.ID, ApiKeyPrefix: prefix, ApiKeyHash: hash}))
+		keys[role] = key
+		teams[role] = tm.ID
+	}
+	key, prefix, hash, err := authService.GenerateKey()
+	require.NoError(t, err)
+	platformID := teams["platform"]
+	require.NoError(t, repos.Users.Create(ctx, &auth.User{
+		Name: "dashboard", TeamID: &platformID, Scopes: []string{auth.ScopeRead},
+		ApiKeyPrefix: prefix, ApiKeyHash: hash,
+	}))
+	keys["readonly"] = key
+	org := &organization.Organization{Name: "acme"}
+	require.NoError(t, repos.Organizations.Create(ctx, org))
+	key, prefix, hash, err = authService.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, repos.Users.Create(ctx, &auth.User{
+		Name: "acme-admin", OrganizationID: &org.ID, ApiKeyPrefix: prefix, ApiKeyHash: hash,
+	}))
+	keys["orgadmin"] = key
+	db := &database.Database{Name: "orders", OwnerTeamID: teams["product"], Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+
+	router := api.NewRouter(api.RouterDeps{
+		K8sChecker:     &noopHealthChecker{},
+		OpenAPISpec:    specpkg.OpenAPISpec,
+		Repo:           repos.Databases,
+		Stats:          &noopStats{},
+		ResizeEvents:   repos.ResizeEvents,
+		Recommender:    &noopRecommender{},
+		TierChanges:    repos.TierChanges,
+		Queries:        repos.Queries,
+		Promotions:     repos.Promotions,
+		Dependents:     repos.Dependents,
+		Operations:     operation.NewTracker(repos.Operations, "daap-a:1"),
+		Locker:         database.NewLocker(repos.Locks, time.Minute, "test:1"),
+		Jobs:           repos.Jobs,
+		Renames:        repos.Renames,
+		Environments:   database.Environments{"dev", "prod"},
+		Rollouts:       &noopRollouts{},
+		RolloutRepo:    repos.Rollouts,
+		Freezes:        repos.Freezes,
+		Specs:          repos.Specs,
+		Revisions:      repos.Revisions,
+		AuthService:    authService,
+		TeamRepo:       repos.Teams,
+		TierRepo:       repos.Tiers,
+		BlueprintRepo:  repos.Blueprints,
+		UserRepo:       repos.Users,
+		Organizations:  repos.Organizations,
+		UsageSamples:   repos.UsageSamples,
+		Invitations:    repos.Invitations,
+		Preflight:      &stubPreflight{},
+		GitOps:         &stubGitOps{},
+		SupportBundles: &stubSupportBundler{},
+		CNPGOperator:   &stubOperator{},
+		Reconciler:     &stubReconciler{},
+		Config:         config.NewReloader(config.Config{}),
+		Retention:      retention.New(repos.Purges, time.Hour, 24*time.Hour),
+		Catalog:        catalog.New(repos.Databases, catalog.Config{}),
+	})
+	return &checkFixture{router: router, keys: keys, dbID: db.ID}
+}
+
+// check calls POST /auth/check as the identity with key.
+func (f *checkFixture) check(t *testing.T, key, action, resource string) (int, map[string]interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"action": action, "resource": resource})
+	rec := f.do(key, http.MethodPost, "/auth/check", body)
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	data, _ := env["data"].(map[string]interface{})
+	return rec.Code, data
+}
+
+func (f *checkFixture) do(key, method, path string, body []byte) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, bytes.NewReader(body))
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthCheck(t *testing.T) {
+	t.Parallel()
+	f := newCheckFixture(t)
+	other := uuid.New()
+
+	tests := []struct {
+		name       string
+		identity   string
+		action     string
+		resource   string
+		wantReason string // empty when allowed
+	}{
+		{"superuser manages teams", "superuser", "POST", "/teams", ""},
+		{"product user cannot manage teams", "product", "POST", "/teams", "Superuser access required"},
+		{"platform user manages tiers", "platform", "PATCH", "/tiers/" + other.String(), ""},
+		{"product user cannot manage tiers", "product", "PATCH", "/tiers/" + other.String(), "Insufficient permissions"},
+		{"superuser has no role", "superuser", "GET", "/databases", "Insufficient permissions"},
+		{"product user owns database", "product", "delete", "/databases/" + f.dbID.String(), ""},
+		{"product user owns database by name", "product", "GET", "/databases/by-name/orders", ""},
+		{"other team's database", "product", "GET", "/databases/" + other.String() + "/revisions", "Database not found"},
+		{"platform user sees any database", "platform", "GET", "/databases/" + other.String(), ""},
+		{"HEAD checks GET", "product", "HEAD", "/blueprints?limit=5", ""},
+		{"public route", "product", "GET", "/health", ""},
+		{"read-only key reads", "readonly", "GET", "/tiers", ""},
+		{"read-only key cannot create", "readonly", "POST", "/tiers", "API key scopes do not allow create on tiers"},
+		{"read-only key cannot act", "readonly", "POST", "/databases/" + f.dbID.String() + "/restart", "API key scopes do not allow update on databases"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, data := f.check(t, f.keys[tt.identity], tt.action, tt.resource)
+			require.Equal(t, http.StatusOK, code)
+			assert.Equal(t, tt.wantReason == "", data["allowed"])
+			if tt.wantReason != "" {
+				assert.Equal(t, tt.wantReason, data["reason"])
+			} else {
+				assert.NotContains(t, data, "reason")
+			}
+		})
+	}
+}
+
+func TestAuthCheck_InvalidRequests(t *testing.T) {
+	t.Parallel()
+	f := newCheckFixture(t)
+
+	tests := []struct {
+		name     string
+		action   string
+		resource string
+		field    string
+	}{
+		{"unknown action", "FETCH", "/databases", "action"},
+		{"resource not a path", "GET", "databases", "resource"},
+		{"no such route", "GET", "/nope", "resource"},
+		{"method not routed", "PUT", "/databases", "resource"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(map[string]string{"action": tt.action, "resource": tt.resource})
+			rec := f.do(f.keys["platform"], http.MethodPost, "/auth/check", body)
+			require.Equal(t, http.StatusBadRequest, rec.Code)
+			assert.Contains(t, rec.Body.String(), `"field":"`+tt.field+`"`)
+		})
+	}
+
+	rec := f.do("", http.MethodPost, "/auth/check", []byte(`{"action":"GET","resource":"/databases"}`))
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+}
+
+// TestAuthCheck_MatchesRouter checks that POST /auth/check agrees with the
+// router on every route for every kind of identity: it denies exactly the
+// requests the route groups reject with 403.
+func TestAuthCheck_MatchesRouter(t *testing.T) {
+	t.Parallel()
+
+	var routes []route
+	require.NoError(t, chi.Walk(newCheckFixture(t).router, func(method, pattern string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		routes = append(routes, route{method: method, path: pattern})
+		return nil
+	}))
+
+	for _, rt := range routes {
+		for _, identity := range []string{"superuser", "platform", "product", "orgadmin"} {
+			t.Run(identity+"_"+rt.method+"_"+rt.path, func(t *testing.T) {
+				// Requests change state, so each gets a fresh router.
+				f := newCheckFixture(t)
+				path := rt.path
+				if strings.HasPrefix(path, "/databases/") {
+					path = strings.Replace(path, "{id}", f.dbID.String(), 1)
+				}
+				path = strings.NewReplacer(
+					"{id}", uuid.NewString(), "{dependentId}", uuid.NewString(),
+					"{name}", "orders", "{token}", "token",
+				).Replace(path)
+
+				code, data := f.check(t, f.keys[identity], rt.method, path)
+				require.Equal(t, http.StatusOK, code)
+
+				rec := f.do(f.keys[identity], rt.method, path, []byte("{}"))
+				denied := rec.Code == http.StatusForbidden &&
+					(strings.Contains(rec.Body.String(), "Superuser access required") ||
+						strings.Contains(rec.Body.String(), "Insufficient permissions") ||
+						strings.Contains(rec.Body.String(), "API key scopes do not allow"))
+				assert.Equal(t, !denied, data["allowed"], "%s %s as %s: router answered %d %s",
+					rt.method, path, identity, rec.Code, rec.Body.String())
+			})
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package api_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/provider/cnpg"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type stubOperator struct {
+	op  cnpg.Operator
+	err error
+}
+
+func (s *stubOperator) Detect(context.Context) (cnpg.Operator, error) {
+	return s.op, s.err
+}
+
+const clusterKindManifests = `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: "{{ .Name }}"
+`
+
+const databaseKindManifests = `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: "{{ .Name }}"
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Database
+metadata:
+  name: "{{ .Name }}-app"
+`
+
+func newRequirementsRouter(t *testing.T, op *stubOperator) (http.Handler, blueprint.Repository, string) {
+	t.Helper()
+	bpRepo := fake.NewRepositories().Blueprints
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", nil)
+
+	router, _, platformKey := newAdminRouter(t, func(d *api.RouterDeps) {
+		d.CNPGOperator = op
+		d.BlueprintRepo = bpRepo
+		d.ProviderRegistry = registry
+	})
+	return router, bpRepo, platformKey
+}
+
+func getRequirements(t *testing.T, router http.Handler, key string) (int, map[string]interface{}) {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodGet, "/providers/cnpg/requirements", nil)
+	req.Header.Set("X-API-Key", key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	return rec.Code, env
+}
+
+func TestCNPGRequirements_ReportsBlueprintCompatibility(t *testing.T) {
+	op := &stubOperator{op: cnpg.Operator{
+		Detected: true, Namespace: "cnpg-system", Deployment: "cnpg-cloudnative-pg", Version: "1.24.1", Chart: "cloudnative-pg-0.22.1",
+	}}
+	router, bpRepo, platformKey := newRequirementsRouter(t, op)
+	ctx := context.Background()
+	require.NoError(t, bpRepo.Create(ctx, &blueprint.Blueprint{Name: "standard", Provider: "cnpg", Manifests: clusterKindManifests}))
+	require.NoError(t, bpRepo.Create(ctx, &blueprint.Blueprint{Name: "with-database", Provider: "cnpg", Manifests: databaseKindManifests}))
+	require.NoError(t, bpRepo.Create(ctx, &blueprint.Blueprint{Name: "rds", Provider: "rds", Manifests: "{}"}))
+
+	code, env := getRequirements(t, router, platformKey)
+	require.Equal(t, http.StatusOK, code)
+
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, true, data["supported"])
+	assert.Equal(t, ">=1.22.0 <1.27.0", data["supportedVersions"])
+	assert.Empty(t, data["issues"])
+	operator := data["operator"].(map[string]interface{})
+	assert.Equal(t, "1.24.1", operator["version"])
+	assert.Equal(t, "cloudnative-pg-0.22.1", operator["chart"])
+
+	blueprints := data["blueprints"].([]interface{})
+	require.Len(t, blueprints, 2, "non-cnpg blueprints are not reported")
+	compatible := map[string]bool{}
+	for _, b := range blueprints {
+		item := b.(map[string]interface{})
+		compatible[item["name"].(string)] = item["compatible"].(bool)
+	}
+	assert.Equal(t, map[string]bool{"standard": true, "with-database": false}, compatible)
+}
+
+func TestCNPGRequirements_UnsupportedOrMissingOperator(t *testing.T) {
+	tests := []struct {
+		name string
+		op   cnpg.Operator
+	}{
+		{name: "too old", op: cnpg.Operator{Detected: true, Namespace: "cnpg-system", Version: "1.20.3"}},
+		{name: "not installed", op: cnpg.Operator{Namespace: "cnpg-system"}},
+		{name: "unknown version", op: cnpg.Operator{Detected: true, Namespace: "cnpg-system", Version: "latest"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, platformKey := newRequirementsRouter(t, &stubOperator{op: tt.op})
+
+			code, env := getRequirements(t, router, platformKey)
+			require.Equal(t, http.StatusOK, code)
+			data := env["data"].(map[string]interface{})
+			assert.Equal(t, false, data["supported"])
+			assert.Len(t, data["issues"], 1)
+		})
+	}
+}
+
+func TestCNPGRequirements_DetectionError(t *testing.T) {
+	router, _, platformKey := newRequirementsRouter(t, &stubOperator{err: errors.New("forbidden")})
+
+	code, _ := getRequirements(t, router, platformKey)
+	assert.Equal(t, http.StatusInternalServerError, code)
+}
+
+func TestCreateBlueprint_OperatorVersionGate(t *testing.T) {
+	tests := []struct {
+		name      string
+		op        *stubOperator
+		manifests string
+		wantCode  int
+	}{
+		{name: "supported", op: &stubOperator{op: cnpg.Operator{Detected: true, Version: "1.25.0"}}, manifests: databaseKindManifests, wantCode: http.StatusCreated},
+		{name: "unsupported operator", op: &stubOperator{op: cnpg.Operator{Detected: true, Version: "1.19.0"}}, manifests: clusterKindManifests, wantCode: http.StatusUnprocessableEntity},
+		{name: "kind too new", op: &stubOperator{op: cnpg.Operator{Detected: true, Version: "1.24.0"}}, manifests: databaseKindManifests, wantCode: http.StatusUnprocessableEntity},
+		{name: "operator not installed", op: &stubOperator{op: cnpg.Operator{}}, manifests: databaseKindManifests, wantCode: http.StatusCreated},
+		{name: "detection failed", op: &stubOperator{err: errors.New("timeout")}, manifests: databaseKindManifests, wantCode: http.StatusCreated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, _, platformKey := newRequirementsRouter(t, tt.op)
+
+			body, _ := json.Marshal(map[string]string{"name": "standard", "provider": "cnpg", "manifests": tt.manifests})
+			req := httptest.NewRequest(http.MethodPost, "/blueprints", bytes.NewReader(body))
+			req.Header.Set("X-API-Key", platformKey)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, tt.wantCode, rec.Code, rec.Body.String())
+			if tt.wantCode == http.StatusUnprocessableEntity {
+				var env map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+				errObj := env["error"].(map[string]interface{})
+				assert.Equal(t, "UNSUPPORTED_OPERATOR_VERSION", errObj["code"])
+				assert.NotEmpty(t, errObj["details"])
+			}
+		})
+	}
+}
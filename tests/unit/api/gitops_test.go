@@ -0,0 +1,89 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api"
+	"github.com/daap14/daap/internal/gitops"
+)
+
+type stubGitOps struct {
+	filter gitops.Filter
+}
+
+func (s *stubGitOps) Export(_ context.Context, w io.Writer, filter gitops.Filter) (gitops.Summary, error) {
+	s.filter = filter
+	_, err := io.WriteString(w, "bundle")
+	return gitops.Summary{Databases: 1}, err
+}
+
+func TestGitOpsExport_Access(t *testing.T) {
+	router, superKey, platformKey := newAdminRouter(t, func(d *api.RouterDeps) { d.GitOps = &stubGitOps{} })
+
+	tests := []struct {
+		name     string
+		key      string
+		wantCode int
+	}{
+		{"superuser allowed", superKey, http.StatusOK},
+		{"platform user forbidden", platformKey, http.StatusForbidden},
+		{"unauthenticated rejected", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/gitops-export", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestGitOpsExport_ReturnsBundle(t *testing.T) {
+	exporter := &stubGitOps{}
+	router, superKey, _ := newAdminRouter(t, func(d *api.RouterDeps) { d.GitOps = exporter })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/gitops-export", nil)
+	req.Header.Set("X-API-Key", superKey)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="daap-gitops.tar.gz"`, rec.Header().Get("Content-Disposition"))
+	assert.Equal(t, "bundle", rec.Body.String())
+	assert.Nil(t, exporter.filter.OwnerTeamID)
+}
+
+func TestGitOpsExport_TeamFilter(t *testing.T) {
+	exporter := &stubGitOps{}
+	router, superKey, _ := newAdminRouter(t, func(d *api.RouterDeps) { d.GitOps = exporter })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/gitops-export?team=platform", nil)
+	req.Header.Set("X-API-Key", superKey)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, `attachment; filename="daap-gitops-platform.tar.gz"`, rec.Header().Get("Content-Disposition"))
+	assert.NotNil(t, exporter.filter.OwnerTeamID)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/gitops-export?team=ghost", nil)
+	req.Header.Set("X-API-Key", superKey)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
@@ -0,0 +1,136 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type ackFixture struct {
+	repos *fake.Repositories
+	team  *team.Team
+	db    *database.Database
+	h     *handler.AckHandler
+}
+
+func newAckFixture(t *testing.T, status string) *ackFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &ackFixture{repos: repos, team: &team.Team{Name: "checkout", Role: "product"}}
+	require.NoError(t, repos.Teams.Create(ctx, f.team))
+	f.db = &database.Database{Name: "orders", OwnerTeamID: f.team.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, f.db))
+	_, err := repos.Databases.UpdateStatus(ctx, f.db.ID, database.StatusUpdate{Status: status})
+	require.NoError(t, err)
+	f.h = handler.NewAckHandler(repos.Databases)
+	return f
+}
+
+func (f *ackFixture) ack(t *testing.T, body map[string]string) (int, map[string]interface{}) {
+	t.Helper()
+	var raw []byte
+	if body != nil {
+		raw, _ = json.Marshal(body)
+	}
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+f.db.ID.String()+"/ack", raw,
+		map[string]string{"id": f.db.ID.String()}, productIdentity(f.team.Name, f.team.ID))
+	f.h.Create(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+func TestAck_AcknowledgesError(t *testing.T) {
+	t.Parallel()
+	f := newAckFixture(t, "error")
+	until := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+
+	code, env := f.ack(t, map[string]string{"comment": "known storage incident", "until": until})
+
+	require.Equal(t, http.StatusOK, code, env)
+	ack := env["data"].(map[string]interface{})["acknowledgement"].(map[string]interface{})
+	assert.Equal(t, "product-user", ack["by"])
+	assert.Equal(t, "known storage incident", ack["comment"])
+	assert.Equal(t, until, ack["until"])
+
+	db, err := f.repos.Databases.GetByID(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	assert.True(t, db.Acknowledged(time.Now()))
+	assert.False(t, db.Acknowledged(time.Now().Add(3*time.Hour)))
+}
+
+func TestAck_UntilStatusChange(t *testing.T) {
+	t.Parallel()
+	f := newAckFixture(t, "error")
+	ctx := context.Background()
+
+	code, env := f.ack(t, nil)
+	require.Equal(t, http.StatusOK, code, env)
+	ack := env["data"].(map[string]interface{})["acknowledgement"].(map[string]interface{})
+	assert.Nil(t, ack["until"])
+
+	// Re-reporting the same status keeps the acknowledgement; a change clears it.
+	db, err := f.repos.Databases.UpdateStatus(ctx, f.db.ID, database.StatusUpdate{Status: "error"})
+	require.NoError(t, err)
+	assert.True(t, db.Acknowledged(time.Now()))
+	db, err = f.repos.Databases.UpdateStatus(ctx, f.db.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	assert.Nil(t, db.Ack)
+}
+
+func TestAck_RejectsDatabaseNotInError(t *testing.T) {
+	t.Parallel()
+	f := newAckFixture(t, "ready")
+
+	code, env := f.ack(t, nil)
+
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "NOT_IN_ERROR", env["error"].(map[string]interface{})["code"])
+}
+
+func TestAck_ValidationError(t *testing.T) {
+	t.Parallel()
+	f := newAckFixture(t, "error")
+
+	code, env := f.ack(t, map[string]string{"until": time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)})
+
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "VALIDATION_ERROR", env["error"].(map[string]interface{})["code"])
+}
+
+func TestAck_Delete(t *testing.T) {
+	t.Parallel()
+	f := newAckFixture(t, "error")
+	code, _ := f.ack(t, nil)
+	require.Equal(t, http.StatusOK, code)
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+f.db.ID.String()+"/ack", nil,
+		map[string]string{"id": f.db.ID.String()}, platformIdentity())
+	f.h.Delete(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	db, err := f.repos.Databases.GetByID(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	assert.Nil(t, db.Ack)
+}
+
+func TestAck_OtherTeamNotFound(t *testing.T) {
+	t.Parallel()
+	f := newAckFixture(t, "error")
+	other := &team.Team{Name: "billing", Role: "product"}
+	require.NoError(t, f.repos.Teams.Create(context.Background(), other))
+
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+f.db.ID.String()+"/ack", nil,
+		map[string]string{"id": f.db.ID.String()}, productIdentity(other.Name, other.ID))
+	f.h.Create(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
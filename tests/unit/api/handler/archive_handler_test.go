@@ -0,0 +1,193 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/archive"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/chaos"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// newArchiveFixture is newOperationFixture with an "archived" tier whose
+// databases are archived before deletion.
+func newArchiveFixture(t *testing.T) (*operationFixture, *archive.Archiver) {
+	t.Helper()
+	f := newOperationFixture(t)
+	standard, err := f.repos.Tiers.GetByName(context.Background(), "standard")
+	require.NoError(t, err)
+	require.NoError(t, f.repos.Tiers.Create(context.Background(), &tier.Tier{
+		Name: "archived", BlueprintID: standard.BlueprintID, DestructionStrategy: tier.DestructionArchive,
+	}))
+	archiver := archive.New(f.repos.Teams, f.repos.Databases, f.repos.Archives)
+	f.dbs = handler.NewDatabaseHandler(f.repos.Databases, f.repos.Teams, f.repos.Tiers, f.repos.Blueprints, f.registry, "default", nil, nil, nil, nil, f.ops, 0, nil, nil, nil, nil, nil, "", nil, archiver, nil, nil)
+	return f, archiver
+}
+
+func (f *operationFixture) createArchived(t *testing.T, name string) string {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"name": name, "tier": "archived"})
+	req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(f.team.Name, f.team.ID))
+	f.dbs.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	return parseEnvelope(t, w)["data"].(map[string]interface{})["id"].(string)
+}
+
+func TestDelete_ArchiveLocationRequired(t *testing.T) {
+	t.Parallel()
+	f, _ := newArchiveFixture(t)
+	dbID := f.createArchived(t, "orders")
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.Delete(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "ARCHIVE_LOCATION_REQUIRED", errObj["code"])
+	assert.Contains(t, errObj["message"], "team checkout")
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.NotEqual(t, "deprovisioning", db.Status, "nothing is torn down")
+	assert.Empty(t, f.provider.DeleteCalls())
+}
+
+func TestDelete_ArchiveNotPossible(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, _ := newArchiveFixture(t)
+	location := "s3://archives/checkout"
+	_, err := f.repos.Teams.Update(ctx, f.team.ID, team.UpdateFields{ArchiveLocation: &location})
+	require.NoError(t, err)
+	dbID := f.createArchived(t, "orders")
+	// A provider with none of the optional capabilities cannot archive.
+	f.registry.Register("cnpg", struct{ provider.Provider }{f.provider})
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.Delete(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "ARCHIVE_NOT_POSSIBLE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+	db, err := f.repos.Databases.GetByID(ctx, uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.NotEqual(t, "deprovisioning", db.Status, "nothing is torn down")
+	assert.Empty(t, f.provider.DeleteCalls())
+}
+
+func TestDelete_ArchiveNotPossible_WrappedProvider(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, _ := newArchiveFixture(t)
+	location := "s3://archives/checkout"
+	_, err := f.repos.Teams.Update(ctx, f.team.ID, team.UpdateFields{ArchiveLocation: &location})
+	require.NoError(t, err)
+	dbID := f.createArchived(t, "orders")
+	// The wrapper looks like an Archiver, but what it wraps is not.
+	f.registry.Register("cnpg", chaos.WrapProvider(struct{ provider.Provider }{f.provider}, chaos.NewInjector(chaos.Config{})))
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.Delete(w, req)
+
+	require.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "ARCHIVE_NOT_POSSIBLE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+	assert.Empty(t, f.provider.DeleteCalls())
+}
+
+func TestDelete_ArchivesBeforeTeardown(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, archiver := newArchiveFixture(t)
+	location := "s3://archives/checkout"
+	_, err := f.repos.Teams.Update(ctx, f.team.ID, team.UpdateFields{ArchiveLocation: &location})
+	require.NoError(t, err)
+	done := false
+	f.provider.ArchiveFn = func(_ context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error) {
+		assert.Empty(t, f.provider.DeleteCalls(), "the resources are kept until the backup completes")
+		return provider.Archive{URL: location + "/" + db.Name, Done: done}, nil
+	}
+	dbID := f.createArchived(t, "orders")
+
+	w := f.delete(t, f.dbs, dbID)
+	require.NoError(t, f.ops.Wait(ctx))
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "running", data["status"])
+	assert.Equal(t, "Waiting for the final backup to complete", data["message"])
+
+	// The reconciler completes the teardown once the backup has completed.
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute,
+		reconciler.WithOperations(f.ops), reconciler.WithArchiver(archiver))
+	done = true
+	rec.RunOnce(ctx)
+
+	_, env = f.get(t, opID, platformIdentity())
+	data = env["data"].(map[string]interface{})
+	assert.Equal(t, "succeeded", data["status"])
+	assert.Equal(t, "s3://archives/checkout/orders", data["result"].(map[string]interface{})["archiveUrl"])
+	assert.Len(t, f.provider.DeleteCalls(), 1)
+	_, err = f.repos.Databases.GetByID(ctx, uuid.MustParse(dbID))
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
+
+func TestGetByID_ArchivedDatabase(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, _ := newArchiveFixture(t)
+	location := "s3://archives/checkout"
+	_, err := f.repos.Teams.Update(ctx, f.team.ID, team.UpdateFields{ArchiveLocation: &location})
+	require.NoError(t, err)
+	dbID := f.createArchived(t, "orders")
+	get := func(identity *auth.Identity) (int, map[string]interface{}) {
+		req, w := makeAuthRequest(http.MethodGet, "/databases/"+dbID, nil, map[string]string{"id": dbID}, identity)
+		f.dbs.GetByID(w, req)
+		if w.Code != http.StatusOK {
+			return w.Code, nil
+		}
+		return w.Code, parseEnvelope(t, w)["data"].(map[string]interface{})
+	}
+
+	f.delete(t, f.dbs, dbID)
+	require.NoError(t, f.ops.Wait(ctx))
+
+	code, data := get(platformIdentity())
+	require.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "deleted", data["status"])
+	assert.Equal(t, "s3://archives/checkout/orders", data["archiveUrl"])
+
+	code, _ = get(productIdentity(f.team.Name, f.team.ID))
+	assert.Equal(t, http.StatusNotFound, code, "product users no longer see a deleted database")
+}
+
+func TestGetByID_ArchiveURLPlatformOnly(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f, _ := newArchiveFixture(t)
+	dbID := f.createArchived(t, "orders")
+	url := "s3://archives/checkout/orders"
+	_, err := f.repos.Databases.Update(ctx, uuid.MustParse(dbID), database.UpdateFields{ArchiveURL: &url})
+	require.NoError(t, err)
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.GetByID(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, url, parseEnvelope(t, w)["data"].(map[string]interface{})["archiveUrl"])
+
+	req, w = makeAuthRequest(http.MethodGet, "/databases/"+dbID, nil, map[string]string{"id": dbID}, productIdentity(f.team.Name, f.team.ID))
+	f.dbs.GetByID(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, parseEnvelope(t, w)["data"], "archiveUrl")
+}
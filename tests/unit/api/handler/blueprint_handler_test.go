@@ -4,11 +4,13 @@ import (
 	"context"
 	"encoding/json"
 	"net/http"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/daap14/daap/internal/api/handler"
 	"github.com/daap14/daap/internal/blueprint"
@@ -24,7 +26,7 @@ func testRegistry() *provider.Registry {
 }
 
 func newBlueprintHandler(repo blueprint.Repository) *handler.BlueprintHandler {
-	return handler.NewBlueprintHandler(repo, testRegistry())
+	return handler.NewBlueprintHandler(repo, testRegistry(), nil, blueprint.LintConfig{}, 0, nil)
 }
 
 func sampleBlueprint(id uuid.UUID) *blueprint.Blueprint {
@@ -120,6 +122,34 @@ func TestBlueprintCreate_DuplicateName(t *testing.T) {
 	assert.Equal(t, "DUPLICATE_NAME", errObj["code"])
 }
 
+func TestBlueprintCreate_ManifestsTooLarge(t *testing.T) {
+	t.Parallel()
+
+	// Beyond the default 1 MiB body limit, so the body limit must grow with
+	// the manifest limit for the validation error to be reported.
+	h := handler.NewBlueprintHandler(&mockBlueprintRepo{}, testRegistry(), nil, blueprint.LintConfig{}, 1<<20, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":      "cnpg-standard",
+		"provider":  "cnpg",
+		"manifests": validManifests + "\n#" + strings.Repeat("x", 1<<20),
+	})
+
+	req, w := makeChiRequest(http.MethodPost, "/blueprints", body, "", nil)
+	h.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	env := parseEnvelope(t, w)
+	errObj := env["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", errObj["code"])
+	details := errObj["details"].([]interface{})
+	require.Len(t, details, 1)
+	detail := details[0].(map[string]interface{})
+	assert.Equal(t, "manifests", detail["field"])
+	assert.Contains(t, detail["message"], "at most 1048576 bytes")
+}
+
 func TestBlueprintCreate_UnknownProvider(t *testing.T) {
 	t.Parallel()
 
@@ -263,7 +293,11 @@ func TestBlueprintDelete_Success(t *testing.T) {
 	t.Parallel()
 
 	id := uuid.New()
-	repo := &mockBlueprintRepo{}
+	repo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+			return sampleBlueprint(id), nil
+		},
+	}
 	h := newBlueprintHandler(repo)
 
 	req, w := makeChiRequest(http.MethodDelete, "/blueprints/"+id.String(), nil, "/blueprints/{id}", map[string]string{"id": id.String()})
@@ -298,6 +332,9 @@ func TestBlueprintDelete_HasTiers(t *testing.T) {
 
 	id := uuid.New()
 	repo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+			return sampleBlueprint(id), nil
+		},
 		deleteFn: func(_ context.Context, _ uuid.UUID) error {
 			return blueprint.ErrBlueprintHasTiers
 		},
@@ -329,3 +366,52 @@ func TestBlueprintDelete_InvalidUUID(t *testing.T) {
 	errObj := env["error"].(map[string]interface{})
 	assert.Equal(t, "INVALID_ID", errObj["code"])
 }
+
+// ===== Lint =====
+
+func TestBlueprintCreate_Lint(t *testing.T) {
+	t.Parallel()
+
+	manifests := validManifests + "\n  imageName: ghcr.io/cloudnative-pg/postgresql:latest"
+	tests := []struct {
+		name        string
+		severity    blueprint.Severity
+		wantStatus  int
+		wantWarning bool
+	}{
+		{"warning", blueprint.SeverityWarning, http.StatusCreated, true},
+		{"error", blueprint.SeverityError, http.StatusBadRequest, false},
+		{"off", blueprint.SeverityOff, http.StatusCreated, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewBlueprintHandler(&mockBlueprintRepo{}, testRegistry(), nil, blueprint.LintConfig{
+				Severities: map[string]blueprint.Severity{
+					blueprint.RuleResourceRequests: blueprint.SeverityOff,
+					blueprint.RuleNoLatestTag:      tt.severity,
+				},
+			}, 0, nil)
+
+			body, _ := json.Marshal(map[string]interface{}{
+				"name":      "cnpg-standard",
+				"provider":  "cnpg",
+				"manifests": manifests,
+			})
+			req, w := makeChiRequest(http.MethodPost, "/blueprints", body, "", nil)
+			h.Create(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			if tt.wantWarning {
+				assert.Contains(t, w.Header().Get("Warning"), "[no-latest-tag] document 0")
+			} else {
+				assert.Empty(t, w.Header().Get("Warning"))
+			}
+			if tt.wantStatus == http.StatusBadRequest {
+				env := parseEnvelope(t, w)
+				details := env["error"].(map[string]interface{})["details"].([]interface{})
+				assert.Len(t, details, 1)
+				assert.Equal(t, "manifests", details[0].(map[string]interface{})["field"])
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+)
+
+func TestBlueprintScope_ListAndGet(t *testing.T) {
+	t.Parallel()
+
+	ownTeam, otherTeam := uuid.New(), uuid.New()
+	org, otherOrg := uuid.New(), uuid.New()
+	global := sampleBlueprint(uuid.New())
+	teamScoped := sampleBlueprint(uuid.New())
+	teamScoped.Name, teamScoped.TeamID = "team-scoped", &ownTeam
+	otherTeamScoped := sampleBlueprint(uuid.New())
+	otherTeamScoped.Name, otherTeamScoped.TeamID = "other-team", &otherTeam
+	orgScoped := sampleBlueprint(uuid.New())
+	orgScoped.Name, orgScoped.OrganizationID = "org-scoped", &org
+	otherOrgScoped := sampleBlueprint(uuid.New())
+	otherOrgScoped.Name, otherOrgScoped.OrganizationID = "other-org", &otherOrg
+	all := []*blueprint.Blueprint{global, teamScoped, otherTeamScoped, orgScoped, otherOrgScoped}
+
+	repo := &mockBlueprintRepo{
+		listFn: func(_ context.Context) ([]blueprint.Blueprint, error) {
+			out := make([]blueprint.Blueprint, len(all))
+			for i, bp := range all {
+				out[i] = *bp
+			}
+			return out, nil
+		},
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*blueprint.Blueprint, error) {
+			for _, bp := range all {
+				if bp.ID == id {
+					return bp, nil
+				}
+			}
+			return nil, blueprint.ErrBlueprintNotFound
+		},
+	}
+	h := newBlueprintHandler(repo)
+	identity := productIdentity("checkout", ownTeam)
+	identity.OrganizationID = &org
+
+	req, w := makeAuthRequest(http.MethodGet, "/blueprints", nil, nil, identity)
+	h.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var names []string
+	for _, item := range parseEnvelope(t, w)["data"].([]interface{}) {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	assert.ElementsMatch(t, []string{"cnpg-standard", "team-scoped", "org-scoped"}, names)
+
+	req, w = makeAuthRequest(http.MethodGet, "/blueprints/"+teamScoped.ID.String(), nil,
+		map[string]string{"id": teamScoped.ID.String()}, identity)
+	h.GetByID(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, ownTeam.String(), parseEnvelope(t, w)["data"].(map[string]interface{})["teamId"])
+
+	for _, hidden := range []*blueprint.Blueprint{otherTeamScoped, otherOrgScoped} {
+		req, w = makeAuthRequest(http.MethodGet, "/blueprints/"+hidden.ID.String(), nil,
+			map[string]string{"id": hidden.ID.String()}, identity)
+		h.GetByID(w, req)
+		assert.Equal(t, http.StatusNotFound, w.Code, hidden.Name)
+	}
+}
+
+func TestBlueprintScope_Create(t *testing.T) {
+	t.Parallel()
+
+	identity := platformIdentity()
+	org := uuid.New()
+	identity.OrganizationID = &org
+
+	tests := []struct {
+		name     string
+		scope    map[string]interface{}
+		wantCode int
+		wantTeam bool
+		wantOrg  bool
+	}{
+		{name: "own team", scope: map[string]interface{}{"teamId": identity.TeamID.String()}, wantCode: http.StatusCreated, wantTeam: true},
+		{name: "own organization", scope: map[string]interface{}{"organizationId": org.String()}, wantCode: http.StatusCreated, wantOrg: true},
+		{name: "other team", scope: map[string]interface{}{"teamId": uuid.New().String()}, wantCode: http.StatusForbidden},
+		{name: "other organization", scope: map[string]interface{}{"organizationId": uuid.New().String()}, wantCode: http.StatusForbidden},
+		{name: "both", scope: map[string]interface{}{"teamId": identity.TeamID.String(), "organizationId": org.String()}, wantCode: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var created *blueprint.Blueprint
+			repo := &mockBlueprintRepo{
+				createFn: func(_ context.Context, bp *blueprint.Blueprint) error {
+					bp.ID = uuid.New()
+					created = bp
+					return nil
+				},
+			}
+			h := newBlueprintHandler(repo)
+
+			fields := map[string]interface{}{"name": "cnpg-experimental", "provider": "cnpg", "manifests": validManifests}
+			for k, v := range tt.scope {
+				fields[k] = v
+			}
+			body, _ := json.Marshal(fields)
+			req, w := makeAuthRequest(http.MethodPost, "/blueprints", body, nil, identity)
+			h.Create(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			if tt.wantCode != http.StatusCreated {
+				assert.Nil(t, created)
+				return
+			}
+			assert.Equal(t, tt.wantTeam, created.TeamID != nil)
+			assert.Equal(t, tt.wantOrg, created.OrganizationID != nil)
+		})
+	}
+}
+
+func TestBlueprintScope_UpdateImmutable(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	h := newBlueprintHandler(&mockBlueprintRepo{})
+
+	body, _ := json.Marshal(map[string]interface{}{"teamId": uuid.New().String()})
+	req, w := makeAuthRequest(http.MethodPatch, "/blueprints/"+id.String(), body,
+		map[string]string{"id": id.String()}, platformIdentity())
+	h.Update(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, "IMMUTABLE_FIELD", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+}
+
+func TestBlueprintScope_UpdateHidden(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	otherTeam := uuid.New()
+	updated := false
+	repo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+			bp := sampleBlueprint(id)
+			bp.TeamID = &otherTeam
+			return bp, nil
+		},
+		updateFn: func(_ context.Context, _ uuid.UUID, _ blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+			updated = true
+			return sampleBlueprint(id), nil
+		},
+	}
+	h := newBlueprintHandler(repo)
+
+	body, _ := json.Marshal(map[string]interface{}{"description": "mine now"})
+	req, w := makeAuthRequest(http.MethodPatch, "/blueprints/"+id.String(), body,
+		map[string]string{"id": id.String()}, platformIdentity())
+	h.Update(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.False(t, updated)
+}
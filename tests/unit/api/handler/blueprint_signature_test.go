@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestBlueprintSignature_Provisioning(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	signer := blueprint.NewSigner([]byte("s3cret"))
+	bps := handler.NewBlueprintHandler(repos.Blueprints, renderingRegistry(), nil, blueprint.LintConfig{}, 0, signer)
+	dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, nil, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, signer, "", nil, nil, nil, nil)
+
+	manifests := "apiVersion: postgresql.cnpg.io/v1\nkind: Cluster\nmetadata:\n  name: \"{{ .ClusterName }}\"\nspec:\n  instances: 3"
+	body, _ := json.Marshal(map[string]string{"name": "cnpg-standard", "provider": "cnpg", "manifests": manifests})
+	req, w := makeAuthRequest(http.MethodPost, "/blueprints", body, nil, platformIdentity())
+	bps.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Regexp(t, `^sha256:`, data["contentHash"])
+	assert.Regexp(t, `^hmac-sha256:`, data["signature"], "blueprints are signed on create")
+	id := uuid.MustParse(data["id"].(string))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &id}))
+
+	create := func(name string) (int, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]string{"name": name, "ownerTeam": checkout.Name, "tier": "standard"})
+		req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(checkout.Name, checkout.ID))
+		dbs.Create(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+	code, env := create("orders")
+	require.Equal(t, http.StatusCreated, code, env)
+
+	// Manifests changed outside the API keep the old signature.
+	tampered := manifests + "\n  enableSuperuserAccess: true"
+	_, err := repos.Blueprints.Update(ctx, id, blueprint.UpdateFields{Manifests: &tampered})
+	require.NoError(t, err)
+	code, env = create("carts")
+	require.Equal(t, http.StatusConflict, code)
+	apiErr := env["error"].(map[string]interface{})
+	assert.Equal(t, "BLUEPRINT_SIGNATURE_INVALID", apiErr["code"])
+	assert.Equal(t, "Refusing to provision from blueprint cnpg-standard: blueprint content does not match its signature", apiErr["message"])
+	_, err = repos.Databases.GetByName(ctx, "carts")
+	assert.Error(t, err, "no database is recorded")
+
+	// Once reviewed, the current content can be signed.
+	req, w = makeAuthRequest(http.MethodPost, "/blueprints/"+id.String()+"/sign", nil, map[string]string{"id": id.String()}, platformIdentity())
+	bps.Sign(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	code, env = create("carts")
+	assert.Equal(t, http.StatusCreated, code, env)
+
+	// Updating the manifests through the API signs them.
+	updated := manifests + "\n  instances: 2"
+	body, _ = json.Marshal(map[string]string{"manifests": updated})
+	req, w = makeAuthRequest(http.MethodPatch, "/blueprints/"+id.String(), body, map[string]string{"id": id.String()}, platformIdentity())
+	bps.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	got, err := repos.Blueprints.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.NoError(t, signer.Verify(got))
+}
+
+func TestBlueprintSign_Disabled(t *testing.T) {
+	t.Parallel()
+	h := handler.NewBlueprintHandler(&mockBlueprintRepo{}, testRegistry(), nil, blueprint.LintConfig{}, 0, nil)
+
+	id := uuid.New()
+	req, w := makeAuthRequest(http.MethodPost, "/blueprints/"+id.String()+"/sign", nil, map[string]string{"id": id.String()}, platformIdentity())
+	h.Sign(w, req)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "SIGNING_DISABLED", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+}
@@ -0,0 +1,202 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/provider/cnpg"
+)
+
+// renderingRegistry registers a CNPG provider, which renders manifests
+// without talking to a cluster.
+func renderingRegistry() *provider.Registry {
+	reg := provider.NewRegistry()
+	reg.Register("cnpg", cnpg.New(nil))
+	return reg
+}
+
+func TestBlueprintUpdate_Success(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	var got blueprint.UpdateFields
+	repo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+			bp := sampleBlueprint(id)
+			bp.Version = 1
+			return bp, nil
+		},
+		updateFn: func(_ context.Context, _ uuid.UUID, fields blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+			got = fields
+			bp := sampleBlueprint(id)
+			bp.Description = *fields.Description
+			bp.Manifests = *fields.Manifests
+			bp.Version = 2
+			return bp, nil
+		},
+	}
+	h := handler.NewBlueprintHandler(repo, renderingRegistry(), nil, blueprint.LintConfig{}, 0, nil)
+
+	manifests := "apiVersion: postgresql.cnpg.io/v1\nkind: Cluster\nmetadata:\n  name: \"{{ .ClusterName }}\"\nspec:\n  instances: 3"
+	body, _ := json.Marshal(map[string]interface{}{"description": "three instances", "manifests": manifests})
+	req, w := makeChiRequest(http.MethodPatch, "/blueprints/"+id.String(), body, "/blueprints/{id}", map[string]string{"id": id.String()})
+	h.Update(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, got.Manifests)
+	assert.Equal(t, manifests, *got.Manifests)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "three instances", data["description"])
+	assert.Equal(t, float64(2), data["version"])
+}
+
+func TestBlueprintUpdate_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		fields     map[string]interface{}
+		wantStatus int
+		wantCode   string
+	}{
+		{"name", map[string]interface{}{"name": "other"}, http.StatusBadRequest, "IMMUTABLE_FIELD"},
+		{"provider", map[string]interface{}{"provider": "other"}, http.StatusBadRequest, "IMMUTABLE_FIELD"},
+		{"empty manifests", map[string]interface{}{"manifests": ""}, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{
+			"unknown template field",
+			map[string]interface{}{"manifests": "apiVersion: postgresql.cnpg.io/v1\nkind: Cluster\nmetadata:\n  name: \"{{ .Cluster }}\""},
+			http.StatusBadRequest, "VALIDATION_ERROR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			repo := &mockBlueprintRepo{
+				getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+					return sampleBlueprint(id), nil
+				},
+				updateFn: func(_ context.Context, _ uuid.UUID, _ blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+					t.Fatal("blueprint must not be updated")
+					return nil, nil
+				},
+			}
+			h := handler.NewBlueprintHandler(repo, renderingRegistry(), nil, blueprint.LintConfig{}, 0, nil)
+
+			body, _ := json.Marshal(tt.fields)
+			req, w := makeChiRequest(http.MethodPatch, "/blueprints/"+id.String(), body, "/blueprints/{id}", map[string]string{"id": id.String()})
+			h.Update(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			apiErr := parseEnvelope(t, w)["error"].(map[string]interface{})
+			assert.Equal(t, tt.wantCode, apiErr["code"])
+		})
+	}
+}
+
+func TestBlueprintUpdate_NotFound(t *testing.T) {
+	t.Parallel()
+
+	h := newBlueprintHandler(&mockBlueprintRepo{})
+
+	id := uuid.New()
+	body, _ := json.Marshal(map[string]interface{}{"description": "x"})
+	req, w := makeChiRequest(http.MethodPatch, "/blueprints/"+id.String(), body, "/blueprints/{id}", map[string]string{"id": id.String()})
+	h.Update(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBlueprintVersions(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	now := time.Now().UTC()
+	repo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+			return sampleBlueprint(id), nil
+		},
+		listVersionsFn: func(_ context.Context, _ uuid.UUID) ([]blueprint.Version, error) {
+			return []blueprint.Version{
+				{BlueprintID: id, Version: 2, Manifests: "v2", CreatedAt: now},
+				{BlueprintID: id, Version: 1, Manifests: "v1", CreatedAt: now.Add(-time.Hour)},
+			}, nil
+		},
+	}
+	h := newBlueprintHandler(repo)
+
+	req, w := makeChiRequest(http.MethodGet, "/blueprints/"+id.String()+"/versions", nil, "/blueprints/{id}/versions", map[string]string{"id": id.String()})
+	h.Versions(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, data, 2)
+	assert.Equal(t, float64(2), data[0].(map[string]interface{})["version"])
+	assert.Equal(t, "v1", data[1].(map[string]interface{})["manifests"])
+}
+
+func TestBlueprintVersions_NotFound(t *testing.T) {
+	t.Parallel()
+
+	h := newBlueprintHandler(&mockBlueprintRepo{
+		listVersionsFn: func(_ context.Context, _ uuid.UUID) ([]blueprint.Version, error) {
+			return nil, blueprint.ErrBlueprintNotFound
+		},
+	})
+
+	id := uuid.New()
+	req, w := makeChiRequest(http.MethodGet, "/blueprints/"+id.String()+"/versions", nil, "/blueprints/{id}/versions", map[string]string{"id": id.String()})
+	h.Versions(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestBlueprintDocuments(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	repo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+			bp := sampleBlueprint(id)
+			bp.Documents = []blueprint.Document{
+				{Index: 0, APIVersion: "postgresql.cnpg.io/v1", Kind: "Cluster", Name: "{{ .ClusterName }}"},
+				{Index: 1, APIVersion: "postgresql.cnpg.io/v1", Kind: "Pooler", Name: "{{ .ClusterName }}-pooler"},
+			}
+			return bp, nil
+		},
+	}
+	h := newBlueprintHandler(repo)
+
+	req, w := makeChiRequest(http.MethodGet, "/blueprints/"+id.String()+"/documents", nil, "/blueprints/{id}/documents", map[string]string{"id": id.String()})
+	h.Documents(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, data, 2)
+	second := data[1].(map[string]interface{})
+	assert.Equal(t, float64(1), second["index"])
+	assert.Equal(t, "Pooler", second["kind"])
+	assert.Equal(t, "{{ .ClusterName }}-pooler", second["name"])
+	assert.Equal(t, "", second["namespace"])
+}
+
+func TestBlueprintDocuments_NotFound(t *testing.T) {
+	t.Parallel()
+
+	h := newBlueprintHandler(&mockBlueprintRepo{})
+
+	id := uuid.New()
+	req, w := makeChiRequest(http.MethodGet, "/blueprints/"+id.String()+"/documents", nil, "/blueprints/{id}/documents", map[string]string{"id": id.String()})
+	h.Documents(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,91 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/tier"
+)
+
+func TestBlueprintUsage(t *testing.T) {
+	bpID := uuid.New()
+	otherID := uuid.New()
+	standard := tier.Tier{ID: uuid.New(), Name: "standard", BlueprintID: &bpID}
+	standardEU := tier.Tier{ID: uuid.New(), Name: "standard-eu", BlueprintID: &bpID}
+	counts := map[uuid.UUID]int{standard.ID: 12, standardEU.ID: 3}
+
+	bpRepo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*blueprint.Blueprint, error) {
+			return sampleBlueprint(id), nil
+		},
+	}
+	tierRepo := &mockTierRepo{
+		listFn: func(_ context.Context) ([]tier.Tier, error) {
+			return []tier.Tier{standard, {ID: uuid.New(), Name: "premium", BlueprintID: &otherID}, {ID: uuid.New(), Name: "legacy"}, standardEU}, nil
+		},
+	}
+	repo := &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			require.NotNil(t, filter.TierID)
+			return &database.ListResult{Total: counts[*filter.TierID]}, nil
+		},
+	}
+	h := handler.NewBlueprintUsageHandler(bpRepo, tierRepo, repo)
+
+	req, w := makeChiRequest(http.MethodGet, "/blueprints/"+bpID.String()+"/usage", nil, "/blueprints/{id}/usage", map[string]string{"id": bpID.String()})
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, bpID.String(), data["blueprintId"])
+	assert.Equal(t, "cnpg-standard", data["blueprintName"])
+	assert.Equal(t, float64(15), data["totalDatabases"])
+	tiers := data["tiers"].([]interface{})
+	require.Len(t, tiers, 2)
+	assert.Equal(t, "standard", tiers[0].(map[string]interface{})["name"])
+	assert.Equal(t, float64(12), tiers[0].(map[string]interface{})["databases"])
+	assert.Equal(t, "standard-eu", tiers[1].(map[string]interface{})["name"])
+	assert.Equal(t, float64(3), tiers[1].(map[string]interface{})["databases"])
+}
+
+func TestBlueprintUsage_Unused(t *testing.T) {
+	bpID := uuid.New()
+	bpRepo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*blueprint.Blueprint, error) {
+			return sampleBlueprint(id), nil
+		},
+	}
+	tierRepo := &mockTierRepo{listFn: func(_ context.Context) ([]tier.Tier, error) { return nil, nil }}
+	h := handler.NewBlueprintUsageHandler(bpRepo, tierRepo, &mockRepo{})
+
+	req, w := makeChiRequest(http.MethodGet, "/blueprints/"+bpID.String()+"/usage", nil, "/blueprints/{id}/usage", map[string]string{"id": bpID.String()})
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, []interface{}{}, data["tiers"])
+	assert.Equal(t, float64(0), data["totalDatabases"])
+}
+
+func TestBlueprintUsage_NotFound(t *testing.T) {
+	bpRepo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*blueprint.Blueprint, error) {
+			return nil, blueprint.ErrBlueprintNotFound
+		},
+	}
+	h := handler.NewBlueprintUsageHandler(bpRepo, &mockTierRepo{}, &mockRepo{})
+
+	id := uuid.New().String()
+	req, w := makeChiRequest(http.MethodGet, "/blueprints/"+id+"/usage", nil, "/blueprints/{id}/usage", map[string]string{"id": id})
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,109 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/budget"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// connectionsProvider reports 100 connections for every blueprint.
+type connectionsProvider struct {
+	*fake.Provider
+}
+
+func (p *connectionsProvider) ManifestConnections(string) (int, error) {
+	return 100, nil
+}
+
+func TestTeam_MaxConnections(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	org := &organization.Organization{Name: "acme"}
+	require.NoError(t, repos.Organizations.Create(ctx, org))
+	teams := handler.NewTeamHandler(repos.Teams, repos.Organizations)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "checkout", "role": "product", "maxConnections": 150})
+	req, w := makeAuthRequest(http.MethodPost, "/teams", body, nil, superuserIdentity())
+	teams.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, float64(150), data["maxConnections"])
+	id := data["id"].(string)
+
+	body, _ = json.Marshal(map[string]interface{}{"name": "search", "role": "product", "maxConnections": 1000})
+	req, w = makeAuthRequest(http.MethodPost, "/teams", body, nil, orgAdminIdentity(org.ID))
+	teams.Create(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code, "organization superadmins cannot set budgets")
+
+	body, _ = json.Marshal(map[string]interface{}{"maxConnections": -1})
+	req, w = makeAuthRequest(http.MethodPatch, "/teams/"+id, body, map[string]string{"id": id}, superuserIdentity())
+	teams.Update(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	body, _ = json.Marshal(map[string]interface{}{"maxConnections": 0})
+	req, w = makeAuthRequest(http.MethodPatch, "/teams/"+id, body, map[string]string{"id": id}, superuserIdentity())
+	teams.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, float64(0), parseEnvelope(t, w)["data"].(map[string]interface{})["maxConnections"])
+}
+
+func TestDatabase_ConnectionBudget(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product", MaxConnections: 150}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	search := &team.Team{Name: "search", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, search))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &bp.ID}))
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", &connectionsProvider{Provider: fake.NewProvider()})
+	budgets := budget.New(repos.Teams, repos.Databases, repos.Tiers, repos.Blueprints, registry)
+	dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", budgets, nil, nil, nil)
+
+	create := func(name string, owner *team.Team) (int, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]interface{}{"name": name, "ownerTeam": owner.Name, "tier": "standard"})
+		req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, platformIdentity())
+		dbs.Create(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+
+	code, env := create("orders", checkout)
+	require.Equal(t, http.StatusCreated, code, env)
+
+	code, env = create("carts", checkout)
+	require.Equal(t, http.StatusConflict, code, env)
+	errObj := env["error"].(map[string]interface{})
+	assert.Equal(t, "CONNECTION_BUDGET_EXCEEDED", errObj["code"])
+	assert.Contains(t, errObj["message"], "team checkout uses 100 of 150 connections and the database needs 100")
+
+	code, env = create("index", search)
+	require.Equal(t, http.StatusCreated, code, env)
+	id := env["data"].(map[string]interface{})["id"].(string)
+
+	body, _ := json.Marshal(map[string]interface{}{"ownerTeam": checkout.Name})
+	req, w := makeAuthRequest(http.MethodPatch, "/databases/"+id, body, map[string]string{"id": id}, platformIdentity())
+	dbs.Update(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code, "moving a database to a team counts against its budget")
+
+	body, _ = json.Marshal(map[string]interface{}{"purpose": "search index"})
+	req, w = makeAuthRequest(http.MethodPatch, "/databases/"+id, body, map[string]string{"id": id}, platformIdentity())
+	dbs.Update(w, req)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+}
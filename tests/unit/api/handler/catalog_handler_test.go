@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/catalog"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type catalogFixture struct {
+	checkout *team.Team
+	search   *team.Team
+	h        *handler.CatalogHandler
+}
+
+func newCatalogFixture(t *testing.T) *catalogFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &catalogFixture{
+		checkout: &team.Team{Name: "checkout", Role: "product"},
+		search:   &team.Team{Name: "search", Role: "product"},
+	}
+	require.NoError(t, repos.Teams.Create(ctx, f.checkout))
+	require.NoError(t, repos.Teams.Create(ctx, f.search))
+
+	for _, db := range []*database.Database{
+		{Name: "orders", OwnerTeamID: f.checkout.ID, Purpose: "Order history", Namespace: "default", Environment: "prod"},
+		{Name: "search-index", OwnerTeamID: f.search.ID, Namespace: "default"},
+	} {
+		db.ClusterName, db.PoolerName = "daap-"+db.Name, "daap-"+db.Name+"-pooler"
+		require.NoError(t, repos.Databases.Create(ctx, db))
+	}
+
+	source := catalog.New(repos.Databases, catalog.Config{
+		System: "data-platform",
+		Owners: map[string]string{"checkout": "commerce/checkout-squad"},
+	})
+	f.h = handler.NewCatalogHandler(source)
+	return f
+}
+
+func TestCatalog_ListsDatabasesAsResources(t *testing.T) {
+	t.Parallel()
+	f := newCatalogFixture(t)
+
+	req, w := makeAuthRequest(http.MethodGet, "/catalog/entities", nil, nil, platformIdentity())
+	f.h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 2)
+
+	owners := map[string]string{}
+	for _, item := range items {
+		e := item.(map[string]interface{})
+		assert.Equal(t, "backstage.io/v1alpha1", e["apiVersion"])
+		assert.Equal(t, "Resource", e["kind"])
+		meta := e["metadata"].(map[string]interface{})
+		spec := e["spec"].(map[string]interface{})
+		assert.Equal(t, "default", meta["namespace"])
+		assert.Equal(t, "database", spec["type"])
+		assert.Equal(t, "data-platform", spec["system"])
+		assert.NotEmpty(t, meta["annotations"].(map[string]interface{})[catalog.AnnotationDatabaseID])
+		owners[meta["name"].(string)] = spec["owner"].(string)
+	}
+	assert.Equal(t, map[string]string{
+		"orders":       "group:commerce/checkout-squad",
+		"search-index": "group:default/search",
+	}, owners)
+}
+
+func TestCatalog_ProductUserSeesOwnTeam(t *testing.T) {
+	t.Parallel()
+	f := newCatalogFixture(t)
+
+	req, w := makeAuthRequest(http.MethodGet, "/catalog/entities", nil, nil, productIdentity("search", f.search.ID))
+	f.h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 1)
+	assert.Equal(t, "search-index", items[0].(map[string]interface{})["metadata"].(map[string]interface{})["name"])
+}
+
+func TestCatalog_YAMLFormat(t *testing.T) {
+	t.Parallel()
+	f := newCatalogFixture(t)
+
+	req, w := makeAuthRequest(http.MethodGet, "/catalog/entities?format=yaml", nil, nil, platformIdentity())
+	f.h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/yaml", w.Header().Get("Content-Type"))
+
+	docs := strings.Split(strings.TrimPrefix(w.Body.String(), "---\n"), "---\n")
+	require.Len(t, docs, 2)
+	var e catalog.Entity
+	require.NoError(t, yaml.Unmarshal([]byte(docs[0]), &e))
+	assert.Equal(t, "Resource", e.Kind)
+	assert.NotEmpty(t, e.Metadata.Name)
+}
+
+func TestCatalog_InvalidFormat(t *testing.T) {
+	t.Parallel()
+	f := newCatalogFixture(t)
+
+	req, w := makeAuthRequest(http.MethodGet, "/catalog/entities?format=xml", nil, nil, platformIdentity())
+	f.h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/tier"
+)
+
+func restrictedOnlyTierRepo() *mockTierRepo {
+	lookup := func(name string) *tier.Tier {
+		return &tier.Tier{ID: uuid.New(), Name: name, DestructionStrategy: "hard_delete", DataClassifications: []string{database.ClassificationRestricted}}
+	}
+	return &mockTierRepo{
+		getByNameFn: func(_ context.Context, name string) (*tier.Tier, error) { return lookup(name), nil },
+		getByIDFn:   func(_ context.Context, _ uuid.UUID) (*tier.Tier, error) { return lookup("vault"), nil },
+	}
+}
+
+func TestCreate_DataClassification(t *testing.T) {
+	tests := []struct {
+		name           string
+		classification string
+		want           string
+	}{
+		{"defaults to internal", "", database.ClassificationInternal},
+		{"explicit", "confidential", database.ClassificationConfidential},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var created *database.Database
+			repo := &mockRepo{
+				createFn: func(_ context.Context, db *database.Database) error {
+					created = db
+					db.ID = uuid.New()
+					db.Status = "provisioning"
+					return nil
+				},
+			}
+			h := newTestHandler(repo, &mockDBTeamRepo{})
+
+			body, _ := json.Marshal(map[string]interface{}{
+				"name":               "mydb",
+				"ownerTeam":          "platform",
+				"tier":               "standard",
+				"dataClassification": tt.classification,
+			})
+			req, w := makeChiRequest(http.MethodPost, "/databases", body, "/databases", nil)
+
+			h.Create(w, req)
+
+			require.Equal(t, http.StatusCreated, w.Code)
+			require.NotNil(t, created)
+			assert.Equal(t, tt.want, created.DataClassification)
+			data := parseEnvelope(t, w)["data"].(map[string]interface{})
+			assert.Equal(t, tt.want, data["dataClassification"])
+		})
+	}
+}
+
+func TestCreate_DataClassificationInvalid(t *testing.T) {
+	h := newTestHandler(&mockRepo{}, &mockDBTeamRepo{})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":               "mydb",
+		"ownerTeam":          "platform",
+		"tier":               "standard",
+		"dataClassification": "secret",
+	})
+	req, w := makeChiRequest(http.MethodPost, "/databases", body, "/databases", nil)
+
+	h.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestCreate_DataClassificationNotAllowedByTier(t *testing.T) {
+	repo := &mockRepo{
+		createFn: func(_ context.Context, _ *database.Database) error {
+			t.Fatal("database must not be created")
+			return nil
+		},
+	}
+	h := newTestHandlerWithTierRepo(repo, &mockDBTeamRepo{}, restrictedOnlyTierRepo())
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":      "mydb",
+		"ownerTeam": "platform",
+		"tier":      "vault",
+	})
+	req, w := makeChiRequest(http.MethodPost, "/databases", body, "/databases", nil)
+
+	h.Create(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	apiErr := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "CLASSIFICATION_NOT_ALLOWED", apiErr["code"])
+}
+
+func TestUpdate_DataClassificationCheckedAgainstTier(t *testing.T) {
+	tests := []struct {
+		name           string
+		classification string
+		wantStatus     int
+	}{
+		{"allowed", "restricted", http.StatusOK},
+		{"not allowed", "public", http.StatusUnprocessableEntity},
+		{"invalid", "secret", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			tierID := uuid.New()
+			repo := &mockRepo{
+				getByIDFn: func(_ context.Context, _ uuid.UUID) (*database.Database, error) {
+					db := sampleDB(id, "ready")
+					db.TierID = &tierID
+					db.DataClassification = database.ClassificationRestricted
+					return db, nil
+				},
+				updateFn: func(_ context.Context, _ uuid.UUID, fields database.UpdateFields) (*database.Database, error) {
+					require.NotNil(t, fields.DataClassification)
+					db := sampleDB(id, "ready")
+					db.DataClassification = *fields.DataClassification
+					return db, nil
+				},
+			}
+			h := newTestHandlerWithTierRepo(repo, &mockDBTeamRepo{}, restrictedOnlyTierRepo())
+
+			body, _ := json.Marshal(map[string]interface{}{"dataClassification": tt.classification})
+			req, w := makeChiRequest(http.MethodPatch, "/databases/"+id.String(), body, "/databases/{id}", map[string]string{"id": id.String()})
+
+			h.Update(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+)
+
+// createWith posts a database with the given query string and returns the
+// response code, envelope and Operation-Location.
+func (f *operationFixture) createWith(t *testing.T, name, query string) (int, map[string]interface{}, string) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"name": name, "tier": "standard"})
+	req, w := makeAuthRequest(http.MethodPost, "/databases"+query, body, nil, productIdentity(f.team.Name, f.team.ID))
+	f.dbs.Create(w, req)
+	return w.Code, parseEnvelope(t, w), strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+}
+
+func failingApply(context.Context, provider.ProviderDatabase, string) error {
+	return errors.New("admission webhook denied the request")
+}
+
+func TestCreate_ApplyFailureLeavesDatabaseInError(t *testing.T) {
+	t.Parallel()
+	for _, query := range []string{"", "?onFailure=error"} {
+		f := newOperationFixture(t)
+		f.provider.ApplyFn = failingApply
+
+		code, env, _ := f.createWith(t, "orders", query)
+
+		require.Equal(t, http.StatusCreated, code, query)
+		data := env["data"].(map[string]interface{})
+		assert.Equal(t, "error", data["status"], query)
+		db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(data["id"].(string)))
+		require.NoError(t, err, query)
+		assert.Equal(t, "error", db.Status, query)
+		assert.Empty(t, f.provider.DeleteCalls(), query)
+	}
+}
+
+func TestCreate_ApplyFailureRollsBack(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	f.provider.ApplyFn = failingApply
+
+	code, env, opID := f.createWith(t, "orders", "?onFailure=rollback")
+
+	require.Equal(t, http.StatusBadGateway, code)
+	apiErr := env["error"].(map[string]interface{})
+	assert.Equal(t, "APPLY_FAILED", apiErr["code"])
+	assert.Equal(t, "Applying blueprint cnpg-standard failed; the database was rolled back", apiErr["message"])
+	assert.Equal(t, true, apiErr["retryable"])
+
+	deletes := f.provider.DeleteCalls()
+	require.Len(t, deletes, 1)
+	assert.Equal(t, "orders", deletes[0].Name)
+	name := "orders"
+	list, err := f.repos.Databases.List(context.Background(), database.ListFilter{Name: &name, Page: 1, Limit: 20})
+	require.NoError(t, err)
+	assert.Zero(t, list.Total)
+
+	_, opEnv := f.get(t, opID, platformIdentity())
+	opErr := opEnv["data"].(map[string]interface{})["error"].(map[string]interface{})
+	assert.Equal(t, "APPLY_FAILED", opErr["code"])
+	assert.Contains(t, opErr["message"], "the database was rolled back")
+
+	// The name is free again.
+	f.provider.ApplyFn = nil
+	code, _, _ = f.createWith(t, "orders", "?onFailure=rollback")
+	assert.Equal(t, http.StatusCreated, code)
+}
+
+func TestCreate_RollbackKeepsDatabaseWhenResourcesRemain(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	f.provider.ApplyFn = failingApply
+	f.provider.DeleteFn = func(context.Context, provider.ProviderDatabase) error {
+		return errors.New("connection refused")
+	}
+
+	code, env, _ := f.createWith(t, "orders", "?onFailure=rollback")
+
+	require.Equal(t, http.StatusCreated, code)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "error", data["status"])
+	_, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(data["id"].(string)))
+	assert.NoError(t, err)
+}
+
+func TestCreate_InvalidOnFailure(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+
+	code, env, _ := f.createWith(t, "orders", "?onFailure=ignore")
+
+	require.Equal(t, http.StatusBadRequest, code)
+	apiErr := env["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", apiErr["code"])
+	details := apiErr["details"].([]interface{})
+	require.Len(t, details, 1)
+	assert.Equal(t, "onFailure", details[0].(map[string]interface{})["field"])
+	assert.Empty(t, f.provider.ApplyCalls())
+}
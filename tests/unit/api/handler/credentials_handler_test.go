@@ -0,0 +1,105 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func (f *operationFixture) rotateCredentials(t *testing.T, dbID string, identity *auth.Identity) *httptest.ResponseRecorder {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/rotate-credentials", nil, map[string]string{"id": dbID}, identity)
+	f.dbs.RotateCredentials(w, req)
+	return w
+}
+
+func TestRotateCredentials_RecordsRotation(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+	dbID, _ := f.create(t, "orders")
+	f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+
+	before := time.Now().Add(-time.Second)
+	w := f.rotateCredentials(t, dbID, productIdentity(f.team.Name, f.team.ID))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, f.provider.RotateCredentialsCalls(), 1)
+	assert.Equal(t, "orders", f.provider.RotateCredentialsCalls()[0].Name)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "ready", data["status"])
+	at, err := time.Parse(time.RFC3339, data["credentialsRotatedAt"].(string))
+	require.NoError(t, err)
+	assert.True(t, at.After(before))
+
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	require.NotNil(t, db.CredentialsRotatedAt)
+}
+
+func TestRotateCredentials_Rejected(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not ready", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+
+		w := f.rotateCredentials(t, dbID, platformIdentity())
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "ROTATION_NOT_POSSIBLE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+		assert.Empty(t, f.provider.RotateCredentialsCalls())
+	})
+
+	t.Run("other team", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+
+		w := f.rotateCredentials(t, dbID, productIdentity("payments", uuid.New()))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.Empty(t, f.provider.RotateCredentialsCalls())
+	})
+
+	t.Run("provider cannot rotate", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+		f.registry.Register("cnpg", plainProvider{Provider: fake.NewProvider()})
+
+		w := f.rotateCredentials(t, dbID, platformIdentity())
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, `Provider "cnpg" does not support credential rotation`, parseEnvelope(t, w)["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+		f.provider.RotateCredentialsFn = func(context.Context, provider.ProviderDatabase) error {
+			return errors.New("connection refused")
+		}
+
+		w := f.rotateCredentials(t, dbID, platformIdentity())
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+		require.NoError(t, err)
+		assert.Nil(t, db.CredentialsRotatedAt)
+	})
+}
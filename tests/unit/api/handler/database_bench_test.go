@@ -0,0 +1,75 @@
+package handler_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// seedBenchDatabases creates n databases owned by a single platform team.
+func seedBenchDatabases(b *testing.B, n int) (*fake.Repositories, *team.Team) {
+	b.Helper()
+	repos := fake.NewRepositories()
+	ctx := context.Background()
+
+	tm := &team.Team{Name: "bench", Role: "platform"}
+	if err := repos.Teams.Create(ctx, tm); err != nil {
+		b.Fatal(err)
+	}
+	for i := range n {
+		db := &database.Database{Name: fmt.Sprintf("bench-db-%05d", i), OwnerTeamID: tm.ID, Namespace: "default"}
+		if err := repos.Databases.Create(ctx, db); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repos, tm
+}
+
+// listLatencyBudget is the latency the list endpoint must stay under.
+const listLatencyBudget = 50 * time.Millisecond
+
+func BenchmarkDatabaseList_1k(b *testing.B) {
+	benchmarkDatabaseList(b, 1000)
+}
+
+func BenchmarkDatabaseList_10k(b *testing.B) {
+	benchmarkDatabaseList(b, 10000)
+}
+
+func benchmarkDatabaseList(b *testing.B, n int) {
+	for _, limit := range []int{20, 100} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			repos, tm := seedBenchDatabases(b, n)
+			h := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, nil, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+
+			role := "platform"
+			identity := &auth.Identity{UserName: "bench", TeamID: &tm.ID, TeamName: &tm.Name, Role: &role}
+			url := fmt.Sprintf("/databases?page=2&limit=%d", limit)
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodGet, url, nil)
+				req = req.WithContext(middleware.WithIdentity(req.Context(), identity))
+				rec := httptest.NewRecorder()
+				h.List(rec, req)
+				if rec.Code != http.StatusOK {
+					b.Fatalf("unexpected status %d", rec.Code)
+				}
+			}
+			if perOp := b.Elapsed() / time.Duration(b.N); perOp > listLatencyBudget {
+				b.Errorf("list took %s per request with %d databases, budget is %s", perOp, n, listLatencyBudget)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// newExpandFixture returns a handler whose database references a tier, its
+// blueprint and the owner team, all resolvable through the mock repos.
+func newExpandFixture(t *testing.T, ownerTeamID uuid.UUID) (*handler.DatabaseHandler, uuid.UUID) {
+	t.Helper()
+	dbID := uuid.New()
+	tierID := uuid.New()
+	bpID := uuid.New()
+
+	repo := &mockRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*database.Database, error) {
+			db := sampleDB(dbID, "ready")
+			db.OwnerTeamID = ownerTeamID
+			db.TierID = &tierID
+			db.TierName = "standard"
+			return db, nil
+		},
+	}
+	teamRepo := &mockDBTeamRepo{
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*team.Team, error) {
+			return &team.Team{ID: id, Name: "platform", Role: "platform"}, nil
+		},
+	}
+	tierRepo := &mockTierRepo{
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*tier.Tier, error) {
+			return &tier.Tier{ID: id, Name: "standard", Description: "Standard tier", BlueprintID: &bpID, BlueprintName: "cnpg-standard", DestructionStrategy: "freeze"}, nil
+		},
+	}
+	bpRepo := &mockBlueprintRepo{
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*blueprint.Blueprint, error) {
+			return &blueprint.Blueprint{ID: id, Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster"}, nil
+		},
+	}
+	return handler.NewDatabaseHandler(repo, teamRepo, tierRepo, bpRepo, nil, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil), dbID
+}
+
+func getExpanded(t *testing.T, h *handler.DatabaseHandler, id uuid.UUID, expand string, identity *auth.Identity) map[string]interface{} {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+id.String()+"?expand="+expand, nil, map[string]string{"id": id.String()}, identity)
+	h.GetByID(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return parseEnvelope(t, w)["data"].(map[string]interface{})
+}
+
+func TestGetByID_ExpandAll_PlatformUser(t *testing.T) {
+	t.Parallel()
+	h, id := newExpandFixture(t, platformTeamID)
+
+	data := getExpanded(t, h, id, "tier,blueprint,ownerTeam", platformIdentity())
+
+	assert.Equal(t, id.String(), data["id"])
+	assert.Equal(t, "standard", data["tier"], "flat fields are unchanged")
+
+	expanded := data["expanded"].(map[string]interface{})
+	tierObj := expanded["tier"].(map[string]interface{})
+	assert.Equal(t, "standard", tierObj["name"])
+	assert.Equal(t, "freeze", tierObj["destructionStrategy"])
+
+	bp := expanded["blueprint"].(map[string]interface{})
+	assert.Equal(t, "cnpg-standard", bp["name"])
+	assert.Equal(t, "kind: Cluster", bp["manifests"])
+
+	owner := expanded["ownerTeam"].(map[string]interface{})
+	assert.Equal(t, "platform", owner["name"])
+}
+
+func TestGetByID_Expand_ProductUserRedacted(t *testing.T) {
+	t.Parallel()
+	teamID := uuid.New()
+	h, id := newExpandFixture(t, teamID)
+
+	data := getExpanded(t, h, id, "tier,blueprint", productIdentity("backend", teamID))
+
+	expanded := data["expanded"].(map[string]interface{})
+	tierObj := expanded["tier"].(map[string]interface{})
+	assert.Equal(t, "standard", tierObj["name"])
+	assert.NotContains(t, tierObj, "destructionStrategy")
+	assert.NotContains(t, tierObj, "blueprintName")
+
+	bp := expanded["blueprint"].(map[string]interface{})
+	assert.Equal(t, "cnpg-standard", bp["name"])
+	assert.NotContains(t, bp, "manifests")
+}
+
+func TestGetByID_Expand_OnlyRequestedRelations(t *testing.T) {
+	t.Parallel()
+	h, id := newExpandFixture(t, platformTeamID)
+
+	data := getExpanded(t, h, id, "ownerTeam", platformIdentity())
+
+	expanded := data["expanded"].(map[string]interface{})
+	assert.Contains(t, expanded, "ownerTeam")
+	assert.NotContains(t, expanded, "tier")
+	assert.NotContains(t, expanded, "blueprint")
+}
+
+func TestGetByID_NoExpand_OmitsExpanded(t *testing.T) {
+	t.Parallel()
+	h, id := newExpandFixture(t, platformTeamID)
+
+	data := getExpanded(t, h, id, "", platformIdentity())
+
+	assert.NotContains(t, data, "expanded")
+}
+
+func TestGetByID_Expand_InvalidRelation(t *testing.T) {
+	t.Parallel()
+	h, id := newExpandFixture(t, platformTeamID)
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+id.String()+"?expand=tier,secrets", nil, map[string]string{"id": id.String()}, platformIdentity())
+	h.GetByID(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "INVALID_PARAM", errObj["code"])
+}
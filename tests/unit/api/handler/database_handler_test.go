@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -26,6 +28,7 @@ import (
 type mockRepo struct {
 	createFn       func(ctx context.Context, db *database.Database) error
 	getByIDFn      func(ctx context.Context, id uuid.UUID) (*database.Database, error)
+	getByNameFn    func(ctx context.Context, name string) (*database.Database, error)
 	listFn         func(ctx context.Context, filter database.ListFilter) (*database.ListResult, error)
 	updateFn       func(ctx context.Context, id uuid.UUID, fields database.UpdateFields) (*database.Database, error)
 	updateStatusFn func(ctx context.Context, id uuid.UUID, su database.StatusUpdate) (*database.Database, error)
@@ -52,6 +55,13 @@ func (m *mockRepo) GetByID(ctx context.Context, id uuid.UUID) (*database.Databas
 	return nil, database.ErrNotFound
 }
 
+func (m *mockRepo) GetByName(ctx context.Context, name string) (*database.Database, error) {
+	if m.getByNameFn != nil {
+		return m.getByNameFn(ctx, name)
+	}
+	return nil, database.ErrNotFound
+}
+
 func (m *mockRepo) List(ctx context.Context, filter database.ListFilter) (*database.ListResult, error) {
 	if m.listFn != nil {
 		return m.listFn(ctx, filter)
@@ -80,6 +90,10 @@ func (m *mockRepo) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m *mockRepo) Acknowledge(_ context.Context, _ uuid.UUID, _ *database.Acknowledgement) (*database.Database, error) {
+	return nil, database.ErrNotFound
+}
+
 // --- Mock Team Repository ---
 
 type mockDBTeamRepo struct {
@@ -87,6 +101,7 @@ type mockDBTeamRepo struct {
 	getByIDFn   func(ctx context.Context, id uuid.UUID) (*team.Team, error)
 	createFn    func(ctx context.Context, t *team.Team) error
 	listFn      func(ctx context.Context) ([]team.Team, error)
+	updateFn    func(ctx context.Context, id uuid.UUID, fields team.UpdateFields) (*team.Team, error)
 	deleteFn    func(ctx context.Context, id uuid.UUID) error
 }
 
@@ -122,6 +137,13 @@ func (m *mockDBTeamRepo) List(ctx context.Context) ([]team.Team, error) {
 	return []team.Team{}, nil
 }
 
+func (m *mockDBTeamRepo) Update(ctx context.Context, id uuid.UUID, fields team.UpdateFields) (*team.Team, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, id, fields)
+	}
+	return nil, team.ErrTeamNotFound
+}
+
 func (m *mockDBTeamRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
@@ -192,11 +214,11 @@ func (m *mockTierRepo) Delete(ctx context.Context, id uuid.UUID) error {
 
 func newTestHandler(repo database.Repository, teamRepo team.Repository) *handler.DatabaseHandler {
 	tierRepo := &mockTierRepo{}
-	return handler.NewDatabaseHandler(repo, teamRepo, tierRepo, nil, nil, "default")
+	return handler.NewDatabaseHandler(repo, teamRepo, tierRepo, nil, nil, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
 }
 
 func newTestHandlerWithTierRepo(repo database.Repository, teamRepo team.Repository, tierRepo tier.Repository) *handler.DatabaseHandler {
-	return handler.NewDatabaseHandler(repo, teamRepo, tierRepo, nil, nil, "default")
+	return handler.NewDatabaseHandler(repo, teamRepo, tierRepo, nil, nil, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
 }
 
 func makeChiRequest(method, path string, body []byte, routePattern string, params map[string]string) (*http.Request, *httptest.ResponseRecorder) {
@@ -292,6 +314,83 @@ func TestCreate_Success(t *testing.T) {
 	assert.NotEmpty(t, data["createdAt"])
 }
 
+func TestCreate_NamespaceResolution(t *testing.T) {
+	tests := []struct {
+		name          string
+		tierNamespace string
+		reqNamespace  string
+		wantNamespace string
+	}{
+		{"global default", "", "", "default"},
+		{"tier literal", "db-prod", "", "db-prod"},
+		{"tier template", "db-{{ .Team }}-{{ .Tier }}", "", "db-platform-standard"},
+		{"request overrides tier", "db-prod", "staging", "staging"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var created *database.Database
+			repo := &mockRepo{
+				createFn: func(_ context.Context, db *database.Database) error {
+					created = db
+					db.ID = uuid.New()
+					db.Status = "provisioning"
+					return nil
+				},
+			}
+			tierRepo := &mockTierRepo{
+				getByNameFn: func(_ context.Context, name string) (*tier.Tier, error) {
+					return &tier.Tier{ID: uuid.New(), Name: name, DestructionStrategy: "hard_delete", Namespace: tt.tierNamespace}, nil
+				},
+			}
+			h := newTestHandlerWithTierRepo(repo, &mockDBTeamRepo{}, tierRepo)
+
+			body, _ := json.Marshal(map[string]interface{}{
+				"name":      "mydb",
+				"ownerTeam": "platform",
+				"tier":      "standard",
+				"namespace": tt.reqNamespace,
+			})
+			req, w := makeChiRequest(http.MethodPost, "/databases", body, "/databases", nil)
+
+			// Act
+			h.Create(w, req)
+
+			// Assert
+			require.Equal(t, http.StatusCreated, w.Code)
+			require.NotNil(t, created)
+			assert.Equal(t, tt.wantNamespace, created.Namespace)
+		})
+	}
+}
+
+func TestCreate_TierNamespaceInvalidForDatabase(t *testing.T) {
+	// Arrange
+	tierRepo := &mockTierRepo{
+		getByNameFn: func(_ context.Context, name string) (*tier.Tier, error) {
+			return &tier.Tier{ID: uuid.New(), Name: name, DestructionStrategy: "hard_delete", Namespace: "{{ .Database }}-{{ .Database }}"}, nil
+		},
+	}
+	h := newTestHandlerWithTierRepo(&mockRepo{}, &mockDBTeamRepo{}, tierRepo)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":      "a-very-long-database-name-that-is-fine-alone",
+		"ownerTeam": "platform",
+		"tier":      "standard",
+	})
+	req, w := makeChiRequest(http.MethodPost, "/databases", body, "/databases", nil)
+
+	// Act
+	h.Create(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	env := parseEnvelope(t, w)
+	apiErr := env["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", apiErr["code"])
+}
+
 func TestCreate_ValidationError(t *testing.T) {
 	// Arrange
 	repo := &mockRepo{}
@@ -554,6 +653,56 @@ func TestList_Success(t *testing.T) {
 	assert.NotNil(t, meta["limit"])
 }
 
+func TestList_TransientErrorIsRetryable(t *testing.T) {
+	// Arrange
+	repo := &mockRepo{
+		listFn: func(_ context.Context, _ database.ListFilter) (*database.ListResult, error) {
+			return nil, &pgconn.PgError{Code: "57P01", Message: "terminating connection due to administrator command"}
+		},
+	}
+	h := newTestHandler(repo, &mockDBTeamRepo{})
+
+	req, w := makeChiRequest(http.MethodGet, "/databases", nil, "/databases", nil)
+
+	// Act
+	h.List(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	apiErr := env["error"].(map[string]interface{})
+	assert.Equal(t, "SERVICE_UNAVAILABLE", apiErr["code"])
+	assert.Equal(t, true, apiErr["retryable"])
+}
+
+func TestList_PermanentErrorIsNotRetryable(t *testing.T) {
+	// Arrange
+	repo := &mockRepo{
+		listFn: func(_ context.Context, _ database.ListFilter) (*database.ListResult, error) {
+			return nil, errors.New("scan: unexpected column")
+		},
+	}
+	h := newTestHandler(repo, &mockDBTeamRepo{})
+
+	req, w := makeChiRequest(http.MethodGet, "/databases", nil, "/databases", nil)
+
+	// Act
+	h.List(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Empty(t, w.Header().Get("Retry-After"))
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	apiErr := env["error"].(map[string]interface{})
+	assert.Equal(t, "INTERNAL_ERROR", apiErr["code"])
+	assert.Equal(t, false, apiErr["retryable"])
+}
+
 func TestList_WithFilters(t *testing.T) {
 	// Arrange
 	filterTeamID := uuid.New()
@@ -740,6 +889,47 @@ func TestGetByID_InvalidUUID(t *testing.T) {
 	assert.Equal(t, "INVALID_ID", errObj["code"])
 }
 
+func TestGetByName_Success(t *testing.T) {
+	id := uuid.New()
+	repo := &mockRepo{
+		getByNameFn: func(_ context.Context, name string) (*database.Database, error) {
+			assert.Equal(t, "my-app-db", name)
+			return sampleDB(id, "ready"), nil
+		},
+	}
+	h := newTestHandler(repo, &mockDBTeamRepo{})
+
+	req, w := makeChiRequest(http.MethodGet, "/databases/by-name/my-app-db", nil, "/databases/by-name/{name}", map[string]string{"name": "my-app-db"})
+	h.GetByName(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, id.String(), parseEnvelope(t, w)["data"].(map[string]interface{})["id"])
+}
+
+func TestGetByName_NotFound(t *testing.T) {
+	h := newTestHandler(&mockRepo{}, &mockDBTeamRepo{})
+
+	req, w := makeChiRequest(http.MethodGet, "/databases/by-name/missing", nil, "/databases/by-name/{name}", map[string]string{"name": "missing"})
+	h.GetByName(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "NOT_FOUND", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+}
+
+func TestGetByName_ProductUserOtherTeam(t *testing.T) {
+	repo := &mockRepo{
+		getByNameFn: func(_ context.Context, _ string) (*database.Database, error) {
+			return sampleDB(uuid.New(), "ready"), nil
+		},
+	}
+	h := newTestHandler(repo, &mockDBTeamRepo{})
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/by-name/my-app-db", nil, map[string]string{"name": "my-app-db"}, productIdentity("other", uuid.New()))
+	h.GetByName(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
+
 func TestGetByID_ConnectionDetailsWhenReady(t *testing.T) {
 	// Arrange
 	id := uuid.New()
@@ -769,6 +959,58 @@ func TestGetByID_ConnectionDetailsWhenReady(t *testing.T) {
 	assert.Nil(t, data["password"], "password should not be in response")
 }
 
+func TestGetByID_Instances(t *testing.T) {
+	// Arrange
+	id := uuid.New()
+	lag := 1500 * time.Millisecond
+	repo := &mockRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*database.Database, error) {
+			db := sampleDB(id, "ready")
+			db.Instances = &database.Instances{Total: 3, Ready: 2, Primary: "daap-test-db-1", ReplicationLag: &lag}
+			return db, nil
+		},
+	}
+	h := newTestHandler(repo, &mockDBTeamRepo{})
+
+	req, w := makeChiRequest(http.MethodGet, "/databases/"+id.String(), nil, "/databases/{id}", map[string]string{"id": id.String()})
+
+	// Act
+	h.GetByID(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	instances := parseEnvelope(t, w)["data"].(map[string]interface{})["instances"].(map[string]interface{})
+	assert.Equal(t, float64(3), instances["total"])
+	assert.Equal(t, float64(2), instances["ready"])
+	assert.Equal(t, "daap-test-db-1", instances["primary"])
+	assert.Equal(t, 1.5, instances["replicationLagSeconds"])
+}
+
+func TestGetByID_StatusDetail(t *testing.T) {
+	// Arrange
+	id := uuid.New()
+	reason, message := "FAILED_TO_CREATE_PRIMARY", "Failed to create primary: storageclass not found"
+	repo := &mockRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*database.Database, error) {
+			db := sampleDB(id, "error")
+			db.StatusReason, db.StatusMessage = &reason, &message
+			return db, nil
+		},
+	}
+	h := newTestHandler(repo, &mockDBTeamRepo{})
+
+	req, w := makeChiRequest(http.MethodGet, "/databases/"+id.String(), nil, "/databases/{id}", map[string]string{"id": id.String()})
+
+	// Act
+	h.GetByID(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, reason, data["statusReason"])
+	assert.Equal(t, message, data["statusMessage"])
+}
+
 // ===== PATCH /databases/:id =====
 
 func TestUpdate_Success(t *testing.T) {
@@ -776,6 +1018,9 @@ func TestUpdate_Success(t *testing.T) {
 	id := uuid.New()
 	newTeamID := uuid.New()
 	repo := &mockRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*database.Database, error) {
+			return sampleDB(id, "ready"), nil
+		},
 		updateFn: func(_ context.Context, reqID uuid.UUID, fields database.UpdateFields) (*database.Database, error) {
 			assert.Equal(t, id, reqID)
 			db := sampleDB(id, "provisioning")
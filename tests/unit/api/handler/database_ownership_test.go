@@ -399,6 +399,9 @@ func TestUpdate_PlatformUser_CanChangeOwnerTeam(t *testing.T) {
 	newTeamID := uuid.New()
 	id := uuid.New()
 	repo := &mockRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*database.Database, error) {
+			return sampleDB(id, "ready"), nil
+		},
 		updateFn: func(_ context.Context, _ uuid.UUID, fields database.UpdateFields) (*database.Database, error) {
 			db := sampleDB(id, "provisioning")
 			if fields.OwnerTeamID != nil {
@@ -0,0 +1,138 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type dependentFixture struct {
+	repos *fake.Repositories
+	team  *team.Team
+	db    *database.Database
+	h     *handler.DependentHandler
+	dbs   *handler.DatabaseHandler
+}
+
+func newDependentFixture(t *testing.T) *dependentFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &dependentFixture{repos: repos, team: &team.Team{Name: "checkout", Role: "product"}}
+	require.NoError(t, repos.Teams.Create(ctx, f.team))
+	f.db = &database.Database{Name: "orders", OwnerTeamID: f.team.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, f.db))
+
+	f.h = handler.NewDependentHandler(repos.Databases, repos.Dependents)
+	f.dbs = handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, nil, "default", nil, nil, repos.Dependents, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	return f
+}
+
+func (f *dependentFixture) declare(t *testing.T, service string) (int, map[string]interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"service": service, "description": "reads orders"})
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+f.db.ID.String()+"/dependents", body,
+		map[string]string{"id": f.db.ID.String()}, productIdentity(f.team.Name, f.team.ID))
+	f.h.Create(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+func TestDependentCreateAndList(t *testing.T) {
+	t.Parallel()
+	f := newDependentFixture(t)
+
+	code, env := f.declare(t, "checkout-api")
+	require.Equal(t, http.StatusCreated, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "checkout-api", data["service"])
+	assert.Equal(t, "reads orders", data["description"])
+	assert.Equal(t, "product-user", data["createdBy"])
+
+	code, _ = f.declare(t, "billing-worker")
+	require.Equal(t, http.StatusCreated, code)
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+f.db.ID.String()+"/dependents", nil,
+		map[string]string{"id": f.db.ID.String()}, platformIdentity())
+	f.h.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 2)
+	assert.Equal(t, "billing-worker", items[0].(map[string]interface{})["service"])
+	assert.Equal(t, "checkout-api", items[1].(map[string]interface{})["service"])
+}
+
+func TestDependentCreate_DuplicateAndValidation(t *testing.T) {
+	t.Parallel()
+	f := newDependentFixture(t)
+
+	code, _ := f.declare(t, "checkout-api")
+	require.Equal(t, http.StatusCreated, code)
+
+	code, env := f.declare(t, "checkout-api")
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "DUPLICATE_DEPENDENT", env["error"].(map[string]interface{})["code"])
+
+	code, env = f.declare(t, "checkout api")
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "VALIDATION_ERROR", env["error"].(map[string]interface{})["code"])
+}
+
+func TestDependent_OtherTeamNotFound(t *testing.T) {
+	t.Parallel()
+	f := newDependentFixture(t)
+	other := &team.Team{Name: "billing", Role: "product"}
+	require.NoError(t, f.repos.Teams.Create(context.Background(), other))
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+f.db.ID.String()+"/dependents", nil,
+		map[string]string{"id": f.db.ID.String()}, productIdentity(other.Name, other.ID))
+	f.h.List(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDependentDelete(t *testing.T) {
+	t.Parallel()
+	f := newDependentFixture(t)
+	_, env := f.declare(t, "checkout-api")
+	depID := env["data"].(map[string]interface{})["id"].(string)
+	params := map[string]string{"id": f.db.ID.String(), "dependentId": depID}
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+f.db.ID.String()+"/dependents/"+depID, nil, params, platformIdentity())
+	f.h.Delete(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, w = makeAuthRequest(http.MethodDelete, "/databases/"+f.db.ID.String()+"/dependents/"+depID, nil, params, platformIdentity())
+	f.h.Delete(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDatabaseDelete_BlockedByDependents(t *testing.T) {
+	t.Parallel()
+	f := newDependentFixture(t)
+	code, _ := f.declare(t, "checkout-api")
+	require.Equal(t, http.StatusCreated, code)
+	params := map[string]string{"id": f.db.ID.String()}
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+f.db.ID.String(), nil, params, platformIdentity())
+	f.dbs.Delete(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+	errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "HAS_DEPENDENTS", errObj["code"])
+	assert.Contains(t, errObj["message"], "checkout-api")
+	details := errObj["details"].([]interface{})
+	require.Len(t, details, 1)
+	assert.Equal(t, "checkout-api", details[0].(map[string]interface{})["service"])
+
+	req, w = makeAuthRequest(http.MethodDelete, "/databases/"+f.db.ID.String()+"?force=true", nil, params, platformIdentity())
+	f.dbs.Delete(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Contains(t, w.Header().Get("Warning"), "checkout-api")
+}
@@ -0,0 +1,54 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestDatabaseCreate_DNSName(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &bp.ID}))
+	prov := fake.NewProvider()
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", prov)
+
+	create := func(dbs *handler.DatabaseHandler, name string) map[string]interface{} {
+		data, _ := json.Marshal(map[string]interface{}{"name": name, "ownerTeam": checkout.Name, "tier": "standard"})
+		req, w := makeAuthRequest(http.MethodPost, "/databases", data, nil, productIdentity(checkout.Name, checkout.ID))
+		dbs.Create(w, req)
+		env := parseEnvelope(t, w)
+		require.Equal(t, http.StatusCreated, w.Code, env)
+		return env["data"].(map[string]interface{})
+	}
+
+	dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "db.example.com", nil, nil, nil, nil)
+	data := create(dbs, "orders")
+	assert.Equal(t, "orders.db.example.com", data["dnsName"])
+	require.NotEmpty(t, prov.ApplyCalls())
+	assert.Equal(t, "orders.db.example.com", prov.ApplyCalls()[0].Database.DNSName)
+
+	got, err := repos.Databases.GetByName(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "orders.db.example.com", got.DNSName, "the name is kept when the zone changes")
+
+	dbs = handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	assert.NotContains(t, create(dbs, "carts"), "dnsName", "without a zone databases get no DNS name")
+}
@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestDatabaseCreate_Exposure(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &bp.ID}))
+	prov := fake.NewProvider()
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", prov)
+	dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+
+	create := func(body map[string]interface{}) (int, map[string]interface{}) {
+		data, _ := json.Marshal(body)
+		req, w := makeAuthRequest(http.MethodPost, "/databases", data, nil, productIdentity(checkout.Name, checkout.ID))
+		dbs.Create(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+
+	code, env := create(map[string]interface{}{"name": "orders", "ownerTeam": checkout.Name, "tier": "standard",
+		"exposure": map[string]interface{}{"type": "external", "allowedSourceRanges": []string{" 203.0.113.0/24"}}})
+	require.Equal(t, http.StatusCreated, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "external", "allowedSourceRanges": []interface{}{"203.0.113.0/24"}}, data["exposure"])
+	assert.NotContains(t, data, "externalHost", "the load balancer has no address yet")
+	require.NotEmpty(t, prov.ApplyCalls())
+	assert.Equal(t, provider.Exposure{Type: "external", AllowedSourceRanges: []string{"203.0.113.0/24"}}, prov.ApplyCalls()[0].Database.Exposure)
+
+	got, err := repos.Databases.GetByName(ctx, "orders")
+	require.NoError(t, err)
+	host := "a1b2.elb.amazonaws.com"
+	_, err = repos.Databases.UpdateStatus(ctx, got.ID, database.StatusUpdate{Status: "ready", ExternalHost: &host})
+	require.NoError(t, err)
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+got.ID.String(), nil, map[string]string{"id": got.ID.String()}, productIdentity(checkout.Name, checkout.ID))
+	dbs.GetByID(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, host, parseEnvelope(t, w)["data"].(map[string]interface{})["externalHost"])
+
+	code, env = create(map[string]interface{}{"name": "carts", "ownerTeam": checkout.Name, "tier": "standard"})
+	require.Equal(t, http.StatusCreated, code, env)
+	assert.Equal(t, map[string]interface{}{"type": "cluster", "allowedSourceRanges": []interface{}{}},
+		env["data"].(map[string]interface{})["exposure"], "databases are kept inside the cluster by default")
+
+	code, env = create(map[string]interface{}{"name": "wishlists", "ownerTeam": checkout.Name, "tier": "standard",
+		"exposure": map[string]interface{}{"type": "external"}})
+	assert.Equal(t, http.StatusBadRequest, code)
+	details := env["error"].(map[string]interface{})["details"].([]interface{})
+	require.Len(t, details, 1)
+	assert.Equal(t, "exposure.allowedSourceRanges", details[0].(map[string]interface{})["field"])
+}
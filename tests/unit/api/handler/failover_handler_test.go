@@ -0,0 +1,144 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// plainProvider hides every optional capability of the wrapped provider.
+type plainProvider struct {
+	provider.Provider
+}
+
+// readyWithPrimary makes the database ready with the given primary.
+func (f *operationFixture) readyWithPrimary(t *testing.T, rec *reconciler.Reconciler, dbID, primary string) {
+	t.Helper()
+	host, port := "orders-rw.default.svc", 5432
+	f.provider.SetHealth(uuid.MustParse(dbID), provider.HealthResult{
+		Status: "ready", Host: &host, Port: &port,
+		Instances: &provider.InstanceStatus{Total: 3, Ready: 3, Primary: primary},
+	})
+	rec.RunOnce(context.Background())
+}
+
+func (f *operationFixture) failover(t *testing.T, dbID, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	var body []byte
+	if target != "" {
+		body, _ = json.Marshal(map[string]string{"targetInstance": target})
+	}
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/failover", body, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.Failover(w, req)
+	return w
+}
+
+func TestFailover_SwitchesPrimary(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute,
+		reconciler.WithOperations(f.ops))
+	dbID, _ := f.create(t, "orders")
+	f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+
+	w := f.failover(t, dbID, "daap-orders-3")
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Equal(t, "failing_over", parseEnvelope(t, w)["data"].(map[string]interface{})["status"])
+	require.Len(t, f.provider.SwitchoverCalls(), 1)
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "failover", data["type"])
+	assert.Equal(t, "running", data["status"])
+	assert.Equal(t, "Switching over to daap-orders-3", data["message"])
+
+	// The old primary still reports healthy: the switchover has not happened.
+	rec.RunOnce(context.Background())
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "failing_over", db.Status)
+	assert.Equal(t, "daap-orders-1", db.Instances.Primary)
+
+	f.readyWithPrimary(t, rec, dbID, "daap-orders-3")
+	db, err = f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "ready", db.Status)
+	assert.Equal(t, "daap-orders-3", db.Instances.Primary)
+	_, env = f.get(t, opID, platformIdentity())
+	data = env["data"].(map[string]interface{})
+	assert.Equal(t, "succeeded", data["status"])
+	assert.Equal(t, "daap-orders-3", data["result"].(map[string]interface{})["primary"])
+}
+
+func TestFailover_Rejected(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not ready", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+
+		w := f.failover(t, dbID, "daap-orders-3")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+		assert.Equal(t, "FAILOVER_NOT_POSSIBLE", errObj["code"])
+		assert.Equal(t, "Database must be ready to fail over (status is provisioning)", errObj["message"])
+		assert.Empty(t, f.provider.SwitchoverCalls())
+	})
+
+	t.Run("no eligible target", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+
+		w := f.failover(t, dbID, "")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "FAILOVER_NOT_POSSIBLE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+		db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+		require.NoError(t, err)
+		assert.Equal(t, "ready", db.Status)
+	})
+
+	t.Run("provider cannot switch over", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+		f.registry.Register("cnpg", plainProvider{Provider: fake.NewProvider()})
+
+		w := f.failover(t, dbID, "daap-orders-3")
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, `Provider "cnpg" does not support failover`, parseEnvelope(t, w)["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("invalid JSON", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+		req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/failover", []byte("{"), map[string]string{"id": dbID}, platformIdentity())
+		f.dbs.Failover(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		w := f.failover(t, uuid.NewString(), "daap-orders-3")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,234 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type freezeFixture struct {
+	repos    *fake.Repositories
+	checkout *team.Team
+	billing  *team.Team
+	freezes  *handler.FreezeHandler
+	dbs      *handler.DatabaseHandler
+}
+
+func newFreezeFixture(t *testing.T) *freezeFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &freezeFixture{
+		repos:    repos,
+		checkout: &team.Team{Name: "checkout", Role: "product"},
+		billing:  &team.Team{Name: "billing", Role: "product"},
+	}
+	require.NoError(t, repos.Teams.Create(ctx, f.checkout))
+	require.NoError(t, repos.Teams.Create(ctx, f.billing))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard"}))
+
+	f.freezes = handler.NewFreezeHandler(repos.Freezes, repos.Teams)
+	f.dbs = handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, nil, "default", freeze.NewChecker(repos.Freezes), nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	return f
+}
+
+func (f *freezeFixture) freeze(t *testing.T, teamID *uuid.UUID, startsAt, endsAt time.Time) *freeze.Window {
+	t.Helper()
+	w := &freeze.Window{TeamID: teamID, Reason: "holiday lockdown", StartsAt: startsAt, EndsAt: endsAt, CreatedBy: "admin"}
+	require.NoError(t, f.repos.Freezes.Create(context.Background(), w))
+	return w
+}
+
+func (f *freezeFixture) createDatabase(t *testing.T, tm *team.Team, identity *auth.Identity) (int, map[string]interface{}) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"name": "orders-" + tm.Name, "ownerTeam": tm.Name, "tier": "standard"})
+	req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, identity)
+	f.dbs.Create(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+func superuserIdentity() *auth.Identity {
+	return &auth.Identity{UserID: uuid.New(), UserName: "admin", IsSuperuser: true}
+}
+
+func TestFreezeCreate_OrgWide(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+
+	endsAt := time.Now().Add(2 * time.Hour).UTC().Format(time.RFC3339)
+	body, _ := json.Marshal(map[string]string{"reason": "Black Friday", "endsAt": endsAt})
+	req, w := makeAuthRequest(http.MethodPost, "/freezes", body, nil, superuserIdentity())
+	f.freezes.Create(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Nil(t, data["team"])
+	assert.Equal(t, "Black Friday", data["reason"])
+	assert.Equal(t, endsAt, data["endsAt"])
+	assert.Equal(t, true, data["active"])
+	assert.Equal(t, "admin", data["createdBy"])
+}
+
+func TestFreezeCreate_TeamScopedAndUpcoming(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+
+	startsAt := time.Now().Add(24 * time.Hour).UTC().Format(time.RFC3339)
+	endsAt := time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)
+	body, _ := json.Marshal(map[string]string{"team": "checkout", "reason": "Peak season", "startsAt": startsAt, "endsAt": endsAt})
+	req, w := makeAuthRequest(http.MethodPost, "/freezes", body, nil, superuserIdentity())
+	f.freezes.Create(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "checkout", data["team"])
+	assert.Equal(t, startsAt, data["startsAt"])
+	assert.Equal(t, false, data["active"])
+}
+
+func TestFreezeCreate_UnknownTeam(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+
+	endsAt := time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+	body, _ := json.Marshal(map[string]string{"team": "ghost", "reason": "x", "endsAt": endsAt})
+	req, w := makeAuthRequest(http.MethodPost, "/freezes", body, nil, superuserIdentity())
+	f.freezes.Create(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestFreezeCreate_ValidationError(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+
+	body, _ := json.Marshal(map[string]string{"endsAt": "tomorrow"})
+	req, w := makeAuthRequest(http.MethodPost, "/freezes", body, nil, superuserIdentity())
+	f.freezes.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", errObj["code"])
+	assert.Len(t, errObj["details"], 2)
+}
+
+func TestFreezeList_ActiveFilter(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+	now := time.Now()
+	f.freeze(t, nil, now.Add(-time.Hour), now.Add(time.Hour))
+	f.freeze(t, &f.checkout.ID, now.Add(time.Hour), now.Add(2*time.Hour))
+	f.freeze(t, nil, now.Add(-2*time.Hour), now.Add(-time.Hour))
+
+	req, w := makeAuthRequest(http.MethodGet, "/freezes", nil, nil, superuserIdentity())
+	f.freezes.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, parseEnvelope(t, w)["data"], 3)
+
+	req, w = makeAuthRequest(http.MethodGet, "/freezes?active=true", nil, nil, superuserIdentity())
+	f.freezes.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 1)
+	assert.Equal(t, true, items[0].(map[string]interface{})["active"])
+}
+
+func TestFreezeDelete(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+	window := f.freeze(t, nil, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	params := map[string]string{"id": window.ID.String()}
+
+	req, w := makeAuthRequest(http.MethodDelete, "/freezes/"+window.ID.String(), nil, params, superuserIdentity())
+	f.freezes.Delete(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	req, w = makeAuthRequest(http.MethodDelete, "/freezes/"+window.ID.String(), nil, params, superuserIdentity())
+	f.freezes.Delete(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	req, w = makeAuthRequest(http.MethodDelete, "/freezes/bad", nil, map[string]string{"id": "bad"}, superuserIdentity())
+	f.freezes.Delete(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestDatabaseCreate_RejectedDuringFreeze(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+	f.freeze(t, nil, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+	code, env := f.createDatabase(t, f.checkout, productIdentity(f.checkout.Name, f.checkout.ID))
+
+	assert.Equal(t, http.StatusConflict, code)
+	errObj := env["error"].(map[string]interface{})
+	assert.Equal(t, "CHANGE_FREEZE", errObj["code"])
+	assert.Contains(t, errObj["message"], "frozen for all teams")
+	assert.Contains(t, errObj["message"], "holiday lockdown")
+}
+
+func TestDatabaseCreate_TeamFreezeOnlyAffectsThatTeam(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+	f.freeze(t, &f.checkout.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+	code, env := f.createDatabase(t, f.checkout, productIdentity(f.checkout.Name, f.checkout.ID))
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Contains(t, env["error"].(map[string]interface{})["message"], "team checkout")
+
+	code, _ = f.createDatabase(t, f.billing, productIdentity(f.billing.Name, f.billing.ID))
+	assert.Equal(t, http.StatusCreated, code)
+}
+
+func TestDatabaseCreate_UpcomingFreezeDoesNotBlock(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+	f.freeze(t, nil, time.Now().Add(time.Hour), time.Now().Add(2*time.Hour))
+
+	code, _ := f.createDatabase(t, f.checkout, productIdentity(f.checkout.Name, f.checkout.ID))
+	assert.Equal(t, http.StatusCreated, code)
+}
+
+func TestDatabaseCreate_FreezeOverride(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+	f.freeze(t, nil, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+
+	identity := productIdentity(f.checkout.Name, f.checkout.ID)
+	identity.FreezeOverride = true
+	code, _ := f.createDatabase(t, f.checkout, identity)
+	assert.Equal(t, http.StatusCreated, code)
+}
+
+func TestDatabaseDelete_RejectedDuringFreeze(t *testing.T) {
+	t.Parallel()
+	f := newFreezeFixture(t)
+	db := &database.Database{Name: "orders", OwnerTeamID: f.checkout.ID, Namespace: "default"}
+	require.NoError(t, f.repos.Databases.Create(context.Background(), db))
+	window := f.freeze(t, &f.checkout.ID, time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	params := map[string]string{"id": db.ID.String()}
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+db.ID.String(), nil, params, productIdentity(f.checkout.Name, f.checkout.ID))
+	f.dbs.Delete(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "CHANGE_FREEZE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+	// Lifting the freeze allows the delete.
+	require.NoError(t, f.repos.Freezes.Delete(context.Background(), window.ID))
+	req, w = makeAuthRequest(http.MethodDelete, "/databases/"+db.ID.String(), nil, params, productIdentity(f.checkout.Name, f.checkout.ID))
+	f.dbs.Delete(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -42,7 +43,7 @@ func TestHealthHandler_Healthy(t *testing.T) {
 		},
 	}
 	pinger := &mockDBPinger{err: nil}
-	h := handler.NewHealthHandler(checker, pinger, "0.1.0")
+	h := handler.NewHealthHandler(checker, pinger, "0.1.0", nil, nil, 0)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -80,7 +81,7 @@ func TestHealthHandler_DegradedK8s(t *testing.T) {
 		},
 	}
 	pinger := &mockDBPinger{err: nil}
-	h := handler.NewHealthHandler(checker, pinger, "0.1.0")
+	h := handler.NewHealthHandler(checker, pinger, "0.1.0", nil, nil, 0)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -112,7 +113,7 @@ func TestHealthHandler_DegradedDB(t *testing.T) {
 		},
 	}
 	pinger := &mockDBPinger{err: errors.New("connection refused")}
-	h := handler.NewHealthHandler(checker, pinger, "0.1.0")
+	h := handler.NewHealthHandler(checker, pinger, "0.1.0", nil, nil, 0)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -141,7 +142,7 @@ func TestHealthHandler_DegradedNilPinger(t *testing.T) {
 			Version:   "v1.31.0",
 		},
 	}
-	h := handler.NewHealthHandler(checker, nil, "0.1.0")
+	h := handler.NewHealthHandler(checker, nil, "0.1.0", nil, nil, 0)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -167,7 +168,7 @@ func TestHealthHandler_VersionReflectsConfig(t *testing.T) {
 		status: k8s.ConnectivityStatus{Connected: true, Version: "v1.30.0"},
 	}
 	pinger := &mockDBPinger{err: nil}
-	h := handler.NewHealthHandler(checker, pinger, "2.5.0-beta")
+	h := handler.NewHealthHandler(checker, pinger, "2.5.0-beta", nil, nil, 0)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -187,7 +188,7 @@ func TestHealthHandler_ResponseEnvelopeStructure(t *testing.T) {
 		status: k8s.ConnectivityStatus{Connected: true, Version: "v1.31.0"},
 	}
 	pinger := &mockDBPinger{err: nil}
-	h := handler.NewHealthHandler(checker, pinger, "0.1.0")
+	h := handler.NewHealthHandler(checker, pinger, "0.1.0", nil, nil, 0)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -230,7 +231,7 @@ func TestHealthHandler_DevVersion(t *testing.T) {
 		status: k8s.ConnectivityStatus{Connected: false},
 	}
 	pinger := &mockDBPinger{err: nil}
-	h := handler.NewHealthHandler(checker, pinger, "dev")
+	h := handler.NewHealthHandler(checker, pinger, "dev", nil, nil, 0)
 	req := httptest.NewRequest(http.MethodGet, "/health", nil)
 	w := httptest.NewRecorder()
 
@@ -243,3 +244,102 @@ func TestHealthHandler_DevVersion(t *testing.T) {
 	data := env["data"].(map[string]interface{})
 	assert.Equal(t, "dev", data["version"])
 }
+
+// mockReconcilerStatus implements handler.ReconcilerStatus for testing.
+type mockReconcilerStatus struct {
+	last  time.Time
+	stale bool
+}
+
+func (m *mockReconcilerStatus) LastSuccess() time.Time { return m.last }
+
+func (m *mockReconcilerStatus) Stale(_ time.Time) bool { return m.stale }
+
+// mockSchema implements preflight.SchemaVersioner for testing.
+type mockSchema struct {
+	version uint
+	dirty   bool
+	err     error
+}
+
+func (m *mockSchema) SchemaVersion(_ context.Context) (uint, bool, error) {
+	return m.version, m.dirty, m.err
+}
+
+// healthChecks serves GET /health and returns the overall status and the
+// component checks by name.
+func healthChecks(t *testing.T, h *handler.HealthHandler) (string, map[string]map[string]interface{}) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	data := env["data"].(map[string]interface{})
+	checks := map[string]map[string]interface{}{}
+	for _, c := range data["checks"].([]interface{}) {
+		check := c.(map[string]interface{})
+		checks[check["name"].(string)] = check
+	}
+	return data["status"].(string), checks
+}
+
+func TestHealthHandler_ComponentChecks(t *testing.T) {
+	checker := &mockHealthChecker{status: k8s.ConnectivityStatus{Connected: true, Version: "v1.31.0"}}
+	last := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	h := handler.NewHealthHandler(checker, &mockDBPinger{}, "0.1.0",
+		&mockReconcilerStatus{last: last}, &mockSchema{version: 40}, 40)
+
+	status, checks := healthChecks(t, h)
+
+	assert.Equal(t, "healthy", status)
+	require.Len(t, checks, 4)
+	for name, check := range checks {
+		assert.Equal(t, "pass", check["status"], name)
+		assert.Contains(t, check, "durationMs", name)
+	}
+	assert.Equal(t, "2026-02-01T12:00:00Z", checks["reconciler"]["lastSuccessAt"])
+	assert.Equal(t, "schema is at version 40", checks["migrations"]["message"])
+}
+
+func TestHealthHandler_ComponentChecksDegraded(t *testing.T) {
+	tests := []struct {
+		name       string
+		pinger     *mockDBPinger
+		reconciler *mockReconcilerStatus
+		schema     *mockSchema
+		component  string
+		wantStatus string
+		wantHealth string
+	}{
+		{"database unreachable", &mockDBPinger{err: errors.New("connection refused")}, &mockReconcilerStatus{}, &mockSchema{version: 40}, "database", "fail", "degraded"},
+		{"reconciler stale", &mockDBPinger{}, &mockReconcilerStatus{stale: true}, &mockSchema{version: 40}, "reconciler", "fail", "degraded"},
+		{"pending migrations", &mockDBPinger{}, &mockReconcilerStatus{}, &mockSchema{version: 39}, "migrations", "fail", "degraded"},
+		{"dirty migration", &mockDBPinger{}, &mockReconcilerStatus{}, &mockSchema{version: 40, dirty: true}, "migrations", "fail", "degraded"},
+		{"newer schema", &mockDBPinger{}, &mockReconcilerStatus{}, &mockSchema{version: 41}, "migrations", "warn", "healthy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &mockHealthChecker{status: k8s.ConnectivityStatus{Connected: true, Version: "v1.31.0"}}
+			h := handler.NewHealthHandler(checker, tt.pinger, "0.1.0", tt.reconciler, tt.schema, 40)
+
+			status, checks := healthChecks(t, h)
+
+			assert.Equal(t, tt.wantHealth, status)
+			assert.Equal(t, tt.wantStatus, checks[tt.component]["status"])
+			assert.NotEmpty(t, checks[tt.component]["message"])
+		})
+	}
+}
+
+func TestHealthHandler_ComponentChecksSkipped(t *testing.T) {
+	checker := &mockHealthChecker{status: k8s.ConnectivityStatus{Connected: true, Version: "v1.31.0"}}
+	h := handler.NewHealthHandler(checker, &mockDBPinger{}, "0.1.0", nil, nil, 40)
+
+	status, checks := healthChecks(t, h)
+
+	assert.Equal(t, "healthy", status)
+	assert.Equal(t, "skip", checks["reconciler"]["status"])
+	assert.Equal(t, "skip", checks["migrations"]["status"])
+}
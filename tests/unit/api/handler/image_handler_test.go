@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// fakePinner pins every reference to the image in images, or fails with err.
+type fakePinner struct {
+	images map[string]string
+	err    error
+}
+
+func (f fakePinner) Pin(_ context.Context, refs []string) ([]database.ImagePin, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	pins := make([]database.ImagePin, 0, len(refs))
+	for _, ref := range refs {
+		pins = append(pins, database.ImagePin{Reference: ref, Image: f.images[ref], Digest: "sha256:1111"})
+	}
+	return pins, nil
+}
+
+func TestDatabaseCreate_ImagePins(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg",
+		Manifests: "kind: Cluster\nspec:\n  imageName: ghcr.io/cloudnative-pg/postgresql:16\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &bp.ID}))
+	prov := fake.NewProvider()
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", prov)
+
+	create := func(pinner fakePinner, name string) (int, map[string]interface{}) {
+		dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, pinner, nil, "", nil, nil, nil, nil)
+		body, _ := json.Marshal(map[string]string{"name": name, "ownerTeam": checkout.Name, "tier": "standard"})
+		req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(checkout.Name, checkout.ID))
+		dbs.Create(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+
+	mirrored := "registry.internal/ghcr.io/cloudnative-pg/postgresql:16@sha256:1111"
+	code, env := create(fakePinner{images: map[string]string{"ghcr.io/cloudnative-pg/postgresql:16": mirrored}}, "orders")
+	require.Equal(t, http.StatusCreated, code, env)
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"reference": "ghcr.io/cloudnative-pg/postgresql:16",
+		"image":     mirrored,
+		"digest":    "sha256:1111",
+	}}, env["data"].(map[string]interface{})["images"])
+
+	got, err := repos.Databases.GetByName(ctx, "orders")
+	require.NoError(t, err)
+	require.Len(t, got.Images, 1, "the resolved digest is recorded on the database")
+	assert.Equal(t, "sha256:1111", got.Images[0].Digest)
+	require.NotEmpty(t, prov.ApplyCalls())
+	assert.Equal(t, map[string]string{"ghcr.io/cloudnative-pg/postgresql:16": mirrored}, prov.ApplyCalls()[0].Database.Images)
+
+	code, env = create(fakePinner{err: errors.New("manifest unknown")}, "carts")
+	assert.Equal(t, http.StatusBadGateway, code)
+	assert.Equal(t, "IMAGE_RESOLUTION_FAILED", env["error"].(map[string]interface{})["code"])
+	_, err = repos.Databases.GetByName(ctx, "carts")
+	assert.Error(t, err, "no database is created with unvetted images")
+}
@@ -0,0 +1,82 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestQueryInsights(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	other := &team.Team{Name: "search", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, other))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &bp.ID}))
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", fake.NewProvider())
+	dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	insights := handler.NewInsightHandler(repos.Databases, repos.Queries)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "orders", "ownerTeam": checkout.Name, "tier": "standard", "queryInsights": true})
+	req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(checkout.Name, checkout.ID))
+	dbs.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	created := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, true, created["queryInsights"])
+	id := created["id"].(string)
+
+	get := func(identityTeam *team.Team) (int, map[string]interface{}) {
+		req, w := makeAuthRequest(http.MethodGet, "/databases/"+id+"/insights/queries", nil, map[string]string{"id": id}, productIdentity(identityTeam.Name, identityTeam.ID))
+		insights.ServeHTTP(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+
+	code, env := get(checkout)
+	require.Equal(t, http.StatusOK, code, env)
+	assert.Equal(t, map[string]interface{}{"enabled": true, "collectedAt": nil, "queries": []interface{}{}}, env["data"])
+
+	db, err := repos.Databases.GetByName(ctx, "orders")
+	require.NoError(t, err)
+	require.NoError(t, repos.Queries.Record(ctx, &database.QuerySnapshot{DatabaseID: db.ID, Statements: []database.QueryStat{
+		{QueryID: -5826471098613412376, Query: "SELECT * FROM orders WHERE id = $1", Calls: 3, TotalTimeMs: 1.5, MeanTimeMs: 0.5, Rows: 3},
+	}}))
+
+	body, _ = json.Marshal(map[string]interface{}{"queryInsights": false})
+	req, w = makeAuthRequest(http.MethodPatch, "/databases/"+id, body, map[string]string{"id": id}, productIdentity(checkout.Name, checkout.ID))
+	dbs.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	code, env = get(checkout)
+	require.Equal(t, http.StatusOK, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, false, data["enabled"])
+	assert.NotNil(t, data["collectedAt"])
+	assert.Equal(t, []interface{}{map[string]interface{}{
+		"queryId":     "-5826471098613412376",
+		"query":       "SELECT * FROM orders WHERE id = $1",
+		"calls":       float64(3),
+		"totalTimeMs": 1.5,
+		"meanTimeMs":  0.5,
+		"rows":        float64(3),
+	}}, data["queries"], "the last snapshot is served after opting out")
+
+	code, _ = get(other)
+	assert.Equal(t, http.StatusNotFound, code)
+}
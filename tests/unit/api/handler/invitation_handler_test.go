@@ -0,0 +1,192 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/mail"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type recordingMailer struct {
+	sent []mail.Message
+	err  error
+}
+
+func (m *recordingMailer) Send(_ context.Context, msg mail.Message) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+var activationLink = regexp.MustCompile(`https://daap\.example\.com/invitations/([A-Za-z0-9_-]+)`)
+
+type invitationFixture struct {
+	repos  *fake.Repositories
+	auth   *auth.Service
+	team   *team.Team
+	mailer *recordingMailer
+	h      *handler.InvitationHandler
+}
+
+func newInvitationFixture(t *testing.T, ttl time.Duration) *invitationFixture {
+	t.Helper()
+	repos := fake.NewRepositories()
+	f := &invitationFixture{
+		repos:  repos,
+		auth:   auth.NewService(repos.Users, repos.Teams, 4),
+		team:   &team.Team{Name: "checkout", Role: "product"},
+		mailer: &recordingMailer{},
+	}
+	require.NoError(t, repos.Teams.Create(context.Background(), f.team))
+	f.h = handler.NewInvitationHandler(f.auth, repos.Invitations, repos.Teams, f.mailer, "https://daap.example.com/", ttl)
+	return f
+}
+
+func (f *invitationFixture) invite(t *testing.T, body map[string]interface{}) (int, map[string]interface{}) {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	req, w := makeAuthRequest(http.MethodPost, "/users/invite", raw, nil, superuserIdentity())
+	f.h.Invite(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+// token invites alice and returns the token from the activation email.
+func (f *invitationFixture) token(t *testing.T) string {
+	t.Helper()
+	code, env := f.invite(t, map[string]interface{}{"name": "alice", "teamId": f.team.ID.String(), "email": "alice@example.com"})
+	require.Equal(t, http.StatusCreated, code, env)
+	require.NotEmpty(t, f.mailer.sent)
+	m := activationLink.FindStringSubmatch(f.mailer.sent[len(f.mailer.sent)-1].Body)
+	require.Len(t, m, 2)
+	return m[1]
+}
+
+func (f *invitationFixture) call(t *testing.T, fn http.HandlerFunc, method, path, token string) (int, map[string]interface{}) {
+	t.Helper()
+	req, w := makeAuthRequest(method, path, nil, map[string]string{"token": token}, nil)
+	fn(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+func TestInvite_EmailsActivationLink(t *testing.T) {
+	t.Parallel()
+	f := newInvitationFixture(t, 72*time.Hour)
+
+	code, env := f.invite(t, map[string]interface{}{
+		"name": "alice", "teamId": f.team.ID.String(), "email": "alice@example.com", "freezeOverride": true,
+	})
+
+	require.Equal(t, http.StatusCreated, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "alice", data["name"])
+	assert.Equal(t, "checkout", data["teamName"])
+	assert.Equal(t, "alice@example.com", data["email"])
+	assert.Equal(t, true, data["freezeOverride"])
+	assert.Equal(t, "admin", data["createdBy"])
+	assert.NotContains(t, data, "token")
+
+	require.Len(t, f.mailer.sent, 1)
+	msg := f.mailer.sent[0]
+	assert.Equal(t, "alice@example.com", msg.To)
+	assert.Contains(t, msg.Body, "team checkout")
+	assert.Regexp(t, activationLink, msg.Body)
+
+	// No user exists until the invitation is accepted.
+	users, err := f.repos.Users.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
+
+func TestInvite_ValidationAndUnknownTeam(t *testing.T) {
+	t.Parallel()
+	f := newInvitationFixture(t, time.Hour)
+
+	code, env := f.invite(t, map[string]interface{}{"name": "alice", "teamId": f.team.ID.String(), "email": "Alice <alice@example.com>"})
+	assert.Equal(t, http.StatusBadRequest, code)
+	assert.Equal(t, "VALIDATION_ERROR", env["error"].(map[string]interface{})["code"])
+
+	code, _ = f.invite(t, map[string]interface{}{"name": "alice", "teamId": "00000000-0000-0000-0000-000000000001", "email": "alice@example.com"})
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Empty(t, f.mailer.sent)
+}
+
+func TestInvite_EmailFailure(t *testing.T) {
+	t.Parallel()
+	f := newInvitationFixture(t, time.Hour)
+	f.mailer.err = errors.New("connection refused")
+
+	code, env := f.invite(t, map[string]interface{}{"name": "alice", "teamId": f.team.ID.String(), "email": "alice@example.com"})
+	assert.Equal(t, http.StatusBadGateway, code)
+	assert.Equal(t, "EMAIL_FAILED", env["error"].(map[string]interface{})["code"])
+}
+
+func TestInvitation_GetHasNoSideEffects(t *testing.T) {
+	t.Parallel()
+	f := newInvitationFixture(t, time.Hour)
+	token := f.token(t)
+
+	for range 2 {
+		code, env := f.call(t, f.h.Get, http.MethodGet, "/invitations/"+token, token)
+		require.Equal(t, http.StatusOK, code, env)
+		data := env["data"].(map[string]interface{})
+		assert.Equal(t, "alice", data["name"])
+		assert.Equal(t, "checkout", data["teamName"])
+		assert.Equal(t, "https://daap.example.com/invitations/"+token+"/accept", data["acceptUrl"])
+	}
+
+	code, _ := f.call(t, f.h.Get, http.MethodGet, "/invitations/unknown", "unknown")
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestInvitation_AcceptOnce(t *testing.T) {
+	t.Parallel()
+	f := newInvitationFixture(t, time.Hour)
+	token := f.token(t)
+	path := "/invitations/" + token + "/accept"
+
+	code, env := f.call(t, f.h.Accept, http.MethodPost, path, token)
+	require.Equal(t, http.StatusCreated, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "alice", data["name"])
+	assert.Equal(t, "checkout", data["teamName"])
+	assert.Equal(t, "product", data["role"])
+
+	identity, err := f.auth.Authenticate(context.Background(), data["apiKey"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", identity.UserName)
+
+	code, env = f.call(t, f.h.Accept, http.MethodPost, path, token)
+	assert.Equal(t, http.StatusGone, code)
+	assert.Equal(t, "INVITATION_USED", env["error"].(map[string]interface{})["code"])
+
+	code, _ = f.call(t, f.h.Get, http.MethodGet, "/invitations/"+token, token)
+	assert.Equal(t, http.StatusGone, code)
+}
+
+func TestInvitation_Expired(t *testing.T) {
+	t.Parallel()
+	f := newInvitationFixture(t, -time.Minute)
+	token := f.token(t)
+
+	code, env := f.call(t, f.h.Accept, http.MethodPost, "/invitations/"+token+"/accept", token)
+	assert.Equal(t, http.StatusGone, code)
+	assert.Equal(t, "INVITATION_EXPIRED", env["error"].(map[string]interface{})["code"])
+
+	users, err := f.repos.Users.List(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, users)
+}
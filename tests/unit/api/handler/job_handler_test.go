@@ -0,0 +1,122 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/provision"
+)
+
+// queued switches the fixture's database handler to provision through a job
+// queue, and returns a worker that runs its provision jobs along with the
+// worker's clock.
+func (f *operationFixture) queued(t *testing.T) (*jobs.Worker, *time.Time) {
+	t.Helper()
+	f.dbs = handler.NewDatabaseHandler(f.repos.Databases, f.repos.Teams, f.repos.Tiers, f.repos.Blueprints, f.registry, "default", nil, nil, nil, nil, f.ops, 0, nil, nil, nil, nil, nil, "", nil, nil, f.repos.Jobs, nil)
+	now := time.Now().Add(time.Second)
+	w := jobs.NewWorker(f.repos.Jobs, time.Second, jobs.WithClock(func() time.Time { return now }))
+	w.Handle(jobs.TypeProvision, provision.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry,
+		provision.WithOperations(f.ops)).Run)
+	return w, &now
+}
+
+func (f *operationFixture) listJobs(t *testing.T, dbID string, identity *auth.Identity) (int, map[string]interface{}) {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+dbID+"/jobs", nil, map[string]string{"id": dbID}, identity)
+	handler.NewJobHandler(f.repos.Databases, f.repos.Jobs).ListByDatabase(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+func TestJob_CreateProvisionsInBackground(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	worker, _ := f.queued(t)
+
+	dbID, opID := f.create(t, "orders")
+
+	assert.Empty(t, f.provider.ApplyCalls(), "the blueprint is applied by the worker, not the request")
+	_, env := f.get(t, opID, platformIdentity())
+	assert.Equal(t, "running", env["data"].(map[string]interface{})["status"])
+
+	code, env := f.listJobs(t, dbID, productIdentity(f.team.Name, f.team.ID))
+	require.Equal(t, http.StatusOK, code, env)
+	items := env["data"].([]interface{})
+	require.Len(t, items, 1)
+	job := items[0].(map[string]interface{})
+	assert.Equal(t, "provision", job["type"])
+	assert.Equal(t, "queued", job["status"])
+	assert.Equal(t, opID, job["operationId"])
+	assert.Equal(t, "product-user", job["createdBy"])
+
+	worker.RunOnce(context.Background())
+
+	require.Len(t, f.provider.ApplyCalls(), 1)
+	_, env = f.get(t, opID, platformIdentity())
+	op := env["data"].(map[string]interface{})
+	assert.Equal(t, float64(50), op["progress"])
+	assert.Equal(t, "Waiting for the database to become ready", op["message"])
+
+	_, env = f.listJobs(t, dbID, platformIdentity())
+	job = env["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "succeeded", job["status"])
+	assert.Equal(t, float64(1), job["attempts"])
+	assert.NotNil(t, job["completedAt"])
+}
+
+func TestJob_CreateRetriesThenFails(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	worker, now := f.queued(t)
+	f.provider.ApplyFn = func(context.Context, provider.ProviderDatabase, string) error {
+		return errors.New("connection refused")
+	}
+
+	dbID, opID := f.create(t, "orders")
+
+	worker.RunOnce(context.Background())
+	_, env := f.get(t, opID, platformIdentity())
+	op := env["data"].(map[string]interface{})
+	assert.Equal(t, "running", op["status"])
+	assert.Contains(t, op["message"], "connection refused; retrying (attempt 1 of 3)")
+
+	for range 2 {
+		*now = now.Add(time.Hour)
+		worker.RunOnce(context.Background())
+	}
+
+	assert.Len(t, f.provider.ApplyCalls(), 3)
+	_, env = f.get(t, opID, platformIdentity())
+	op = env["data"].(map[string]interface{})
+	assert.Equal(t, "failed", op["status"])
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "error", db.Status)
+
+	_, env = f.listJobs(t, dbID, platformIdentity())
+	job := env["data"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "failed", job["status"])
+	assert.Equal(t, float64(3), job["attempts"])
+	assert.Contains(t, job["lastError"], "connection refused")
+}
+
+func TestJob_ListOtherTeamNotFound(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	f.queued(t)
+	dbID, _ := f.create(t, "orders")
+
+	code, _ := f.listJobs(t, dbID, productIdentity("payments", uuid.New()))
+
+	assert.Equal(t, http.StatusNotFound, code)
+}
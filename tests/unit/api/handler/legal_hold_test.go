@@ -0,0 +1,87 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/auth"
+)
+
+type auditRecorder struct{ events []audit.Event }
+
+func (a *auditRecorder) Saturated() bool { return false }
+
+func (a *auditRecorder) Record(_ context.Context, e audit.Event) error {
+	a.events = append(a.events, e)
+	return nil
+}
+
+// setLegalHold sends PATCH {"legalHold": hold} through the audit middleware
+// and returns the response status.
+func (f *operationFixture) setLegalHold(t *testing.T, rec *auditRecorder, dbID string, hold bool, identity *auth.Identity) int {
+	t.Helper()
+	body, _ := json.Marshal(map[string]bool{"legalHold": hold})
+	req, w := makeAuthRequest(http.MethodPatch, "/databases/"+dbID, body, map[string]string{"id": dbID}, identity)
+	middleware.Audit(rec)(http.HandlerFunc(f.dbs.Update)).ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestLegalHold_BlocksDeletion(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbID, _ := f.create(t, "orders")
+	rec := &auditRecorder{}
+
+	require.Equal(t, http.StatusOK, f.setLegalHold(t, rec, dbID, true, platformIdentity()))
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.True(t, db.LegalHold)
+
+	for _, identity := range []*auth.Identity{platformIdentity(), productIdentity(f.team.Name, f.team.ID)} {
+		req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, identity)
+		middleware.Audit(rec)(http.HandlerFunc(f.dbs.Delete)).ServeHTTP(w, req)
+
+		require.Equal(t, http.StatusConflict, w.Code)
+		errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+		assert.Equal(t, "LEGAL_HOLD", errObj["code"])
+		assert.Contains(t, errObj["message"], "orders is under legal hold")
+	}
+	assert.Empty(t, f.provider.DeleteCalls())
+
+	require.Equal(t, http.StatusOK, f.setLegalHold(t, rec, dbID, false, platformIdentity()))
+	f.delete(t, f.dbs, dbID)
+
+	require.Len(t, rec.events, 4)
+	assert.Equal(t, []string{"database.legal_hold", "database.delete", "database.delete", "database.legal_hold"},
+		[]string{rec.events[0].Action, rec.events[1].Action, rec.events[2].Action, rec.events[3].Action})
+	assert.Equal(t, "placed", rec.events[0].Detail)
+	assert.Equal(t, "refused: legal hold", rec.events[1].Detail)
+	assert.Equal(t, http.StatusConflict, rec.events[1].Status)
+	assert.Equal(t, "cleared", rec.events[3].Detail)
+	assert.Equal(t, dbID, rec.events[0].DatabaseID)
+}
+
+func TestLegalHold_ProductUserForbidden(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbID, _ := f.create(t, "orders")
+	rec := &auditRecorder{}
+
+	status := f.setLegalHold(t, rec, dbID, true, productIdentity(f.team.Name, f.team.ID))
+
+	assert.Equal(t, http.StatusForbidden, status)
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.False(t, db.LegalHold)
+	require.Len(t, rec.events, 1, "refused attempts are audited too")
+	assert.Equal(t, "database.legal_hold", rec.events[0].Action)
+	assert.Equal(t, http.StatusForbidden, rec.events[0].Status)
+}
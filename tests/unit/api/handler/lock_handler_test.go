@@ -0,0 +1,76 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type lockFixture struct {
+	repos  *fake.Repositories
+	team   *team.Team
+	db     *database.Database
+	locker *database.Locker
+	h      *handler.DatabaseHandler
+}
+
+func newLockFixture(t *testing.T) *lockFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &lockFixture{repos: repos, team: &team.Team{Name: "checkout", Role: "product"}}
+	require.NoError(t, repos.Teams.Create(ctx, f.team))
+	f.db = &database.Database{Name: "orders", OwnerTeamID: f.team.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, f.db))
+
+	f.locker = database.NewLocker(repos.Locks, time.Minute, "test:1")
+	f.h = handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, nil, "default", nil, nil, nil, f.locker, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	return f
+}
+
+func TestDatabaseMutation_OperationInProgress(t *testing.T) {
+	t.Parallel()
+	f := newLockFixture(t)
+	ctx := context.Background()
+
+	held, err := f.locker.Acquire(ctx, f.db.ID, "resize", "system:autoscaler", "")
+	require.NoError(t, err)
+
+	params := map[string]string{"id": f.db.ID.String()}
+	body, _ := json.Marshal(map[string]string{"purpose": "Order storage"})
+	req, w := makeAuthRequest(http.MethodPatch, "/databases/"+f.db.ID.String(), body, params, platformIdentity())
+	f.h.Update(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+	errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "OPERATION_IN_PROGRESS", errObj["code"])
+	assert.Contains(t, errObj["message"], `operation "resize" by system:autoscaler`)
+	details := errObj["details"].(map[string]interface{})
+	assert.Equal(t, "resize", details["operation"])
+	assert.Equal(t, "system:autoscaler", details["holder"])
+	assert.Equal(t, "test:1", details["instance"])
+
+	req, w = makeAuthRequest(http.MethodDelete, "/databases/"+f.db.ID.String(), nil, params, platformIdentity())
+	f.h.Delete(w, req)
+	require.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "OPERATION_IN_PROGRESS", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+	// Once the in-flight operation completes, the update goes through and
+	// releases the lock it took.
+	require.NoError(t, f.locker.Release(ctx, held))
+	req, w = makeAuthRequest(http.MethodPatch, "/databases/"+f.db.ID.String(), body, params, platformIdentity())
+	f.h.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	lock, err := f.locker.Get(ctx, f.db.ID)
+	require.NoError(t, err)
+	assert.Nil(t, lock)
+}
@@ -0,0 +1,57 @@
+package handler_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+)
+
+func TestMe_TeamUser(t *testing.T) {
+	t.Parallel()
+
+	identity := platformIdentity()
+	identity.KeyPrefix = "daap_abc"
+	identity.FreezeOverride = true
+	identity.Scopes = []string{"read"}
+	req, w := makeAuthRequest(http.MethodGet, "/me", nil, nil, identity)
+	handler.NewMeHandler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, identity.UserID.String(), data["id"])
+	assert.Equal(t, "platform-user", data["name"])
+	assert.Equal(t, identity.TeamID.String(), data["teamId"])
+	assert.Equal(t, "platform-ops", data["teamName"])
+	assert.Equal(t, "platform", data["role"])
+	assert.Equal(t, "daap_abc", data["apiKeyPrefix"])
+	assert.Nil(t, data["apiKeyExpiresAt"])
+	assert.Equal(t, false, data["isSuperuser"])
+	assert.Equal(t, true, data["freezeOverride"])
+	assert.Equal(t, []interface{}{"read"}, data["scopes"])
+}
+
+func TestMe_Superuser(t *testing.T) {
+	t.Parallel()
+
+	req, w := makeAuthRequest(http.MethodGet, "/me", nil, nil, superuserIdentity())
+	handler.NewMeHandler().ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, true, data["isSuperuser"])
+	assert.NotContains(t, data, "teamId")
+	assert.NotContains(t, data, "role")
+}
+
+func TestMe_Unauthenticated(t *testing.T) {
+	t.Parallel()
+
+	req, w := makeAuthRequest(http.MethodGet, "/me", nil, nil, nil)
+	handler.NewMeHandler().ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
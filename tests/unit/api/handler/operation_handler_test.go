@@ -0,0 +1,315 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type operationFixture struct {
+	repos    *fake.Repositories
+	provider *fake.Provider
+	registry *provider.Registry
+	team     *team.Team
+	ops      *operation.Tracker
+	dbs      *handler.DatabaseHandler
+	h        *handler.OperationHandler
+}
+
+func newOperationFixture(t *testing.T) *operationFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &operationFixture{repos: repos, provider: fake.NewProvider(), team: &team.Team{Name: "checkout", Role: "product"}}
+	require.NoError(t, repos.Teams.Create(ctx, f.team))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &bp.ID}))
+
+	f.registry = provider.NewRegistry()
+	f.registry.Register("cnpg", f.provider)
+	f.ops = operation.NewTracker(repos.Operations, "daap-a:1")
+	f.dbs = handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, f.registry, "default", nil, nil, nil, nil, f.ops, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	f.h = handler.NewOperationHandler(repos.Databases, f.ops)
+	return f
+}
+
+// create creates a database and returns its ID and the operation ID from the
+// Operation-Location header.
+func (f *operationFixture) create(t *testing.T, name string) (string, string) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"name": name, "tier": "standard"})
+	req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(f.team.Name, f.team.ID))
+	f.dbs.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	location := w.Header().Get("Operation-Location")
+	require.True(t, strings.HasPrefix(location, "/operations/"), location)
+	dbID := parseEnvelope(t, w)["data"].(map[string]interface{})["id"].(string)
+	return dbID, strings.TrimPrefix(location, "/operations/")
+}
+
+func (f *operationFixture) get(t *testing.T, id string, identity *auth.Identity) (int, map[string]interface{}) {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodGet, "/operations/"+id, nil, map[string]string{"id": id}, identity)
+	f.h.GetByID(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+func TestOperation_CreateTrackedUntilReady(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+
+	dbID, opID := f.create(t, "orders")
+
+	code, env := f.get(t, opID, platformIdentity())
+	require.Equal(t, http.StatusOK, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "create", data["type"])
+	assert.Equal(t, "running", data["status"])
+	assert.Equal(t, false, data["done"])
+	assert.Equal(t, dbID, data["databaseId"])
+	assert.Equal(t, float64(50), data["progress"])
+	assert.Equal(t, "Waiting for the database to become ready", data["message"])
+	assert.Equal(t, "product-user", data["createdBy"])
+	assert.Nil(t, data["completedAt"])
+
+	host, port := "orders-rw.default.svc", 5432
+	f.provider.SetHealth(uuid.MustParse(dbID), provider.HealthResult{Status: "ready", Host: &host, Port: &port})
+	reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute,
+		reconciler.WithOperations(f.ops)).RunOnce(context.Background())
+
+	code, env = f.get(t, opID, productIdentity(f.team.Name, f.team.ID))
+	require.Equal(t, http.StatusOK, code, env)
+	data = env["data"].(map[string]interface{})
+	assert.Equal(t, "succeeded", data["status"])
+	assert.Equal(t, true, data["done"])
+	assert.Equal(t, float64(100), data["progress"])
+	assert.NotNil(t, data["completedAt"])
+	result := data["result"].(map[string]interface{})
+	assert.Equal(t, host, result["host"])
+	assert.Equal(t, float64(port), result["port"])
+}
+
+func TestOperation_CreateApplyFailure(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	f.provider.ApplyFn = func(context.Context, provider.ProviderDatabase, string) error {
+		return errors.New("admission webhook denied the request")
+	}
+
+	_, opID := f.create(t, "orders")
+
+	code, env := f.get(t, opID, platformIdentity())
+	require.Equal(t, http.StatusOK, code)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "failed", data["status"])
+	assert.Nil(t, data["result"])
+	opErr := data["error"].(map[string]interface{})
+	assert.Equal(t, "APPLY_FAILED", opErr["code"])
+	assert.Contains(t, opErr["message"], "admission webhook denied the request")
+}
+
+func TestOperation_ScopedToOwnerTeam(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbID, opID := f.create(t, "orders")
+
+	other := &team.Team{Name: "billing", Role: "product"}
+	require.NoError(t, f.repos.Teams.Create(context.Background(), other))
+	code, env := f.get(t, opID, productIdentity(other.Name, other.ID))
+	assert.Equal(t, http.StatusNotFound, code)
+	assert.Equal(t, "NOT_FOUND", env["error"].(map[string]interface{})["code"])
+
+	code, _ = f.get(t, uuid.NewString(), platformIdentity())
+	assert.Equal(t, http.StatusNotFound, code)
+	code, _ = f.get(t, "not-a-uuid", platformIdentity())
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+dbID+"/operations", nil,
+		map[string]string{"id": dbID}, productIdentity(f.team.Name, f.team.ID))
+	f.h.ListByDatabase(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 1)
+	assert.Equal(t, opID, items[0].(map[string]interface{})["id"])
+}
+
+func (f *operationFixture) delete(t *testing.T, dbs *handler.DatabaseHandler, dbID string) *httptest.ResponseRecorder {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	dbs.Delete(w, req)
+	require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+	return w
+}
+
+func TestOperation_DeleteTeardownInBackground(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	const teardown = 200 * time.Millisecond
+	f.provider.DeleteFn = func(context.Context, provider.ProviderDatabase) error {
+		time.Sleep(teardown)
+		return nil
+	}
+
+	// Without operations the request waits for the provider.
+	blocking := handler.NewDatabaseHandler(f.repos.Databases, f.repos.Teams, f.repos.Tiers, f.repos.Blueprints, f.registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	dbID, _ := f.create(t, "orders")
+	start := time.Now()
+	f.delete(t, blocking, dbID)
+	syncLatency := time.Since(start)
+	assert.GreaterOrEqual(t, syncLatency, teardown)
+
+	dbID, _ = f.create(t, "payments")
+	start = time.Now()
+	w := f.delete(t, f.dbs, dbID)
+	asyncLatency := time.Since(start)
+	t.Logf("delete latency: %s waiting for the provider, %s in the background", syncLatency, asyncLatency)
+	assert.Less(t, asyncLatency, teardown)
+
+	location := w.Header().Get("Operation-Location")
+	require.True(t, strings.HasPrefix(location, "/operations/"), location)
+	opID := strings.TrimPrefix(location, "/operations/")
+
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "delete", data["type"])
+	assert.Equal(t, "running", data["status"])
+
+	require.NoError(t, f.ops.Wait(context.Background()))
+	_, env = f.get(t, opID, platformIdentity())
+	data = env["data"].(map[string]interface{})
+	assert.Equal(t, "succeeded", data["status"])
+	assert.Equal(t, dbID, data["result"].(map[string]interface{})["databaseId"])
+	assert.Len(t, f.provider.DeleteCalls(), 2)
+}
+
+func TestOperation_DeleteTeardownFailure(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	f.provider.DeleteFn = func(context.Context, provider.ProviderDatabase) error {
+		return errors.New("finalizer stuck")
+	}
+
+	dbID, _ := f.create(t, "orders")
+	w := f.delete(t, f.dbs, dbID)
+	require.NoError(t, f.ops.Wait(context.Background()))
+
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "failed", data["status"])
+	errObj := data["error"].(map[string]interface{})
+	assert.Equal(t, "DELETE_FAILED", errObj["code"])
+	assert.Contains(t, errObj["message"], "finalizer stuck")
+
+	// The reconciler retries the teardown, so the database stays visible.
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "deprovisioning", db.Status)
+}
+
+func TestOperation_DeleteHoldsLockUntilTeardownEnds(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	ctx := context.Background()
+	tearingDown, finish := make(chan struct{}), make(chan struct{})
+	f.provider.DeleteFn = func(context.Context, provider.ProviderDatabase) error {
+		close(tearingDown)
+		<-finish
+		return nil
+	}
+	locker := database.NewLocker(f.repos.Locks, time.Minute, "daap-a:1")
+	dbs := handler.NewDatabaseHandler(f.repos.Databases, f.repos.Teams, f.repos.Tiers, f.repos.Blueprints, f.registry, "default", nil, nil, nil, locker, f.ops, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+
+	dbID, _ := f.create(t, "orders")
+	f.delete(t, dbs, dbID)
+	<-tearingDown
+
+	// The request has returned, but the teardown still holds the lock.
+	held, err := locker.Get(ctx, uuid.MustParse(dbID))
+	require.NoError(t, err)
+	require.NotNil(t, held)
+	assert.Equal(t, "delete", held.Operation)
+
+	close(finish)
+	require.NoError(t, f.ops.Wait(ctx))
+	held, err = locker.Get(ctx, uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Nil(t, held, "released once the teardown ends")
+}
+
+func TestOperation_DeleteDeprovisioningUntilConfirmed(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	state := provider.DeletionDeleting
+	var waits []time.Duration
+	f.provider.DeleteForegroundFn = func(_ context.Context, _ provider.ProviderDatabase, wait time.Duration) (provider.DeletionState, error) {
+		waits = append(waits, wait)
+		return state, nil
+	}
+	dbs := handler.NewDatabaseHandler(f.repos.Databases, f.repos.Teams, f.repos.Tiers, f.repos.Blueprints, f.registry, "default", nil, nil, nil, nil, f.ops, 30*time.Second, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+
+	dbID, _ := f.create(t, "orders")
+	w := f.delete(t, dbs, dbID)
+	require.NoError(t, f.ops.Wait(context.Background()))
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+
+	// Finalizers outlast the wait: the database stays deprovisioning.
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "running", data["status"])
+	assert.Equal(t, "Waiting for the provider to finish deleting the resources", data["message"])
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "deprovisioning", db.Status)
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	dbs.Delete(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "DEPROVISIONING", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+	body, _ := json.Marshal(map[string]string{"purpose": "Order storage"})
+	req, w = makeAuthRequest(http.MethodPatch, "/databases/"+dbID, body, map[string]string{"id": dbID}, platformIdentity())
+	dbs.Update(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "DEPROVISIONING", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute,
+		reconciler.WithOperations(f.ops))
+	rec.RunOnce(context.Background())
+	_, env = f.get(t, opID, platformIdentity())
+	assert.Equal(t, "running", env["data"].(map[string]interface{})["status"])
+
+	// Once the provider reports the resources gone, the reconciler deletes
+	// the record and completes the operation.
+	state = provider.DeletionGone
+	rec.RunOnce(context.Background())
+	_, env = f.get(t, opID, platformIdentity())
+	data = env["data"].(map[string]interface{})
+	assert.Equal(t, "succeeded", data["status"])
+	assert.Equal(t, dbID, data["result"].(map[string]interface{})["databaseId"])
+	_, err = f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	assert.Equal(t, []time.Duration{30 * time.Second, 0, 0}, waits)
+}
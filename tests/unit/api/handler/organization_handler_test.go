@@ -0,0 +1,316 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// orgFixture has two organizations, acme with the team checkout and globex
+// with the team search, and a team outside organizations.
+type orgFixture struct {
+	repos    *fake.Repositories
+	acme     *organization.Organization
+	globex   *organization.Organization
+	checkout *team.Team
+	search   *team.Team
+	ops      *team.Team
+	orgs     *handler.OrganizationHandler
+	teams    *handler.TeamHandler
+	users    *handler.UserHandler
+}
+
+func newOrgFixture(t *testing.T) *orgFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &orgFixture{
+		repos:  repos,
+		acme:   &organization.Organization{Name: "acme", MaxDatabases: 1},
+		globex: &organization.Organization{Name: "globex"},
+	}
+	require.NoError(t, repos.Organizations.Create(ctx, f.acme))
+	require.NoError(t, repos.Organizations.Create(ctx, f.globex))
+	f.checkout = &team.Team{Name: "checkout", Role: "product", OrganizationID: &f.acme.ID}
+	f.search = &team.Team{Name: "search", Role: "product", OrganizationID: &f.globex.ID}
+	f.ops = &team.Team{Name: "ops", Role: "platform"}
+	for _, tm := range []*team.Team{f.checkout, f.search, f.ops} {
+		require.NoError(t, repos.Teams.Create(ctx, tm))
+	}
+
+	f.orgs = handler.NewOrganizationHandler(repos.Organizations, repos.Teams, repos.Databases, repos.UsageSamples)
+	f.teams = handler.NewTeamHandler(repos.Teams, repos.Organizations)
+	f.users = handler.NewUserHandler(auth.NewService(repos.Users, repos.Teams, 4), repos.Users, repos.Teams, repos.Organizations)
+	return f
+}
+
+func orgAdminIdentity(orgID uuid.UUID) *auth.Identity {
+	return &auth.Identity{UserID: uuid.New(), UserName: "org-admin", OrganizationID: &orgID}
+}
+
+func TestOrganizationCreate(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "initech", "maxDatabases": 20})
+	req, w := makeAuthRequest(http.MethodPost, "/organizations", body, nil, superuserIdentity())
+	f.orgs.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "initech", data["name"])
+	assert.Equal(t, float64(20), data["maxDatabases"])
+	assert.Equal(t, "admin", data["createdBy"])
+	assert.Equal(t, float64(organization.DefaultQuotaWarningPercent), data["quotaWarningPercent"])
+
+	req, w = makeAuthRequest(http.MethodPost, "/organizations", body, nil, superuserIdentity())
+	f.orgs.Create(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "DUPLICATE_NAME", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+	body, _ = json.Marshal(map[string]interface{}{"name": "", "maxDatabases": -1, "quotaWarningPercent": 101})
+	req, w = makeAuthRequest(http.MethodPost, "/organizations", body, nil, superuserIdentity())
+	f.orgs.Create(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	details := parseEnvelope(t, w)["error"].(map[string]interface{})["details"].([]interface{})
+	assert.Len(t, details, 3)
+}
+
+func TestOrganizationList_OrgAdminSeesOwn(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+
+	req, w := makeAuthRequest(http.MethodGet, "/organizations", nil, nil, superuserIdentity())
+	f.orgs.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Len(t, parseEnvelope(t, w)["data"].([]interface{}), 2)
+
+	req, w = makeAuthRequest(http.MethodGet, "/organizations", nil, nil, orgAdminIdentity(f.acme.ID))
+	f.orgs.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 1)
+	assert.Equal(t, "acme", items[0].(map[string]interface{})["name"])
+}
+
+func TestOrganizationGet_OtherOrganizationNotFound(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+
+	params := map[string]string{"id": f.acme.ID.String()}
+	req, w := makeAuthRequest(http.MethodGet, "/organizations/"+f.acme.ID.String(), nil, params, orgAdminIdentity(f.acme.ID))
+	f.orgs.GetByID(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, w = makeAuthRequest(http.MethodGet, "/organizations/"+f.acme.ID.String(), nil, params, orgAdminIdentity(f.globex.ID))
+	f.orgs.GetByID(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOrganizationUpdate(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+
+	params := map[string]string{"id": f.acme.ID.String()}
+	req, w := makeAuthRequest(http.MethodPatch, "/organizations/"+f.acme.ID.String(), []byte(`{"maxDatabases": 5}`), params, superuserIdentity())
+	f.orgs.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, float64(5), data["maxDatabases"])
+	assert.Equal(t, "admin", data["updatedBy"])
+
+	req, w = makeAuthRequest(http.MethodPatch, "/organizations/"+f.acme.ID.String(), []byte(`{"quotaWarningPercent": 90}`), params, superuserIdentity())
+	f.orgs.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	data = parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, float64(90), data["quotaWarningPercent"])
+	assert.Equal(t, float64(5), data["maxDatabases"])
+
+	req, w = makeAuthRequest(http.MethodPatch, "/organizations/"+f.acme.ID.String(), []byte(`{"maxDatabases": -1}`), params, superuserIdentity())
+	f.orgs.Update(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	missing := uuid.NewString()
+	req, w = makeAuthRequest(http.MethodPatch, "/organizations/"+missing, []byte(`{"maxDatabases": 5}`), map[string]string{"id": missing}, superuserIdentity())
+	f.orgs.Update(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestOrganizationDelete_BlockedByTeams(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+
+	params := map[string]string{"id": f.acme.ID.String()}
+	req, w := makeAuthRequest(http.MethodDelete, "/organizations/"+f.acme.ID.String(), nil, params, superuserIdentity())
+	f.orgs.Delete(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "ORGANIZATION_HAS_TEAMS", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+	require.NoError(t, f.repos.Teams.Delete(context.Background(), f.checkout.ID))
+	req, w = makeAuthRequest(http.MethodDelete, "/organizations/"+f.acme.ID.String(), nil, params, superuserIdentity())
+	f.orgs.Delete(w, req)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestOrganizationUsage(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+	ctx := context.Background()
+
+	small := &tier.Tier{Name: "small"}
+	require.NoError(t, f.repos.Tiers.Create(ctx, small))
+	for _, name := range []string{"orders", "carts"} {
+		db := &database.Database{Name: name, OwnerTeamID: f.checkout.ID, TierID: &small.ID, Namespace: "default"}
+		require.NoError(t, f.repos.Databases.Create(ctx, db))
+		require.NoError(t, f.repos.UsageSamples.Record(ctx, &database.UsageSample{
+			DatabaseID: db.ID, CPURequestMillis: 500, MemoryRequestBytes: 1 << 30, CollectedAt: time.Now(),
+		}))
+	}
+	require.NoError(t, f.repos.Databases.Create(ctx, &database.Database{Name: "index", OwnerTeamID: f.search.ID, Namespace: "default"}))
+
+	params := map[string]string{"id": f.acme.ID.String()}
+	req, w := makeAuthRequest(http.MethodGet, "/organizations/"+f.acme.ID.String()+"/usage", nil, params, orgAdminIdentity(f.acme.ID))
+	f.orgs.Usage(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "acme", data["organizationName"])
+	assert.Equal(t, float64(2), data["totalDatabases"])
+	assert.Equal(t, float64(1000), data["cpuRequestMillis"])
+	assert.Equal(t, float64(2<<30), data["memoryRequestBytes"])
+	teams := data["teams"].([]interface{})
+	require.Len(t, teams, 1)
+	checkout := teams[0].(map[string]interface{})
+	assert.Equal(t, "checkout", checkout["name"])
+	assert.Equal(t, map[string]interface{}{"small": float64(2)}, checkout["databasesByTier"])
+
+	req, w = makeAuthRequest(http.MethodGet, "/organizations/"+f.acme.ID.String()+"/usage", nil, params, orgAdminIdentity(f.globex.ID))
+	f.orgs.Usage(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestTeam_OrgAdminManagesOwnOrganization(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+	admin := orgAdminIdentity(f.acme.ID)
+
+	// New teams join the admin's organization.
+	body, _ := json.Marshal(map[string]string{"name": "payments", "role": "product"})
+	req, w := makeAuthRequest(http.MethodPost, "/teams", body, nil, admin)
+	f.teams.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, f.acme.ID.String(), parseEnvelope(t, w)["data"].(map[string]interface{})["organizationId"])
+
+	body, _ = json.Marshal(map[string]string{"name": "ads", "role": "product", "organizationId": f.globex.ID.String()})
+	req, w = makeAuthRequest(http.MethodPost, "/teams", body, nil, admin)
+	f.teams.Create(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = makeAuthRequest(http.MethodGet, "/teams", nil, nil, admin)
+	f.teams.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var names []string
+	for _, item := range parseEnvelope(t, w)["data"].([]interface{}) {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	assert.ElementsMatch(t, []string{"checkout", "payments"}, names)
+
+	params := map[string]string{"id": f.search.ID.String()}
+	req, w = makeAuthRequest(http.MethodDelete, "/teams/"+f.search.ID.String(), nil, params, admin)
+	f.teams.Delete(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Only the superuser moves teams between organizations.
+	body, _ = json.Marshal(map[string]string{"organizationId": f.acme.ID.String()})
+	params = map[string]string{"id": f.ops.ID.String()}
+	req, w = makeAuthRequest(http.MethodPatch, "/teams/"+f.ops.ID.String(), body, params, admin)
+	f.teams.Update(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = makeAuthRequest(http.MethodPatch, "/teams/"+f.ops.ID.String(), body, params, superuserIdentity())
+	f.teams.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, f.acme.ID.String(), parseEnvelope(t, w)["data"].(map[string]interface{})["organizationId"])
+}
+
+func TestUser_OrgAdminManagesOwnOrganization(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+	admin := orgAdminIdentity(f.acme.ID)
+
+	// Only the superuser creates organization superadmins.
+	body, _ := json.Marshal(map[string]string{"name": "acme-admin", "organizationId": f.acme.ID.String()})
+	req, w := makeAuthRequest(http.MethodPost, "/users", body, nil, admin)
+	f.users.Create(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	req, w = makeAuthRequest(http.MethodPost, "/users", body, nil, superuserIdentity())
+	f.users.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, f.acme.ID.String(), data["organizationId"])
+	assert.NotContains(t, data, "teamId")
+	assert.NotEmpty(t, data["apiKey"])
+
+	body, _ = json.Marshal(map[string]string{"name": "bob", "teamId": f.search.ID.String()})
+	req, w = makeAuthRequest(http.MethodPost, "/users", body, nil, admin)
+	f.users.Create(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	body, _ = json.Marshal(map[string]string{"name": "alice", "teamId": f.checkout.ID.String()})
+	req, w = makeAuthRequest(http.MethodPost, "/users", body, nil, admin)
+	f.users.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "checkout", parseEnvelope(t, w)["data"].(map[string]interface{})["teamName"])
+
+	require.NoError(t, f.repos.Users.Create(context.Background(), &auth.User{Name: "carol", TeamID: &f.search.ID, ApiKeyPrefix: "daap_xyz"}))
+	req, w = makeAuthRequest(http.MethodGet, "/users", nil, nil, admin)
+	f.users.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	var names []string
+	for _, item := range parseEnvelope(t, w)["data"].([]interface{}) {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	assert.ElementsMatch(t, []string{"acme-admin", "alice"}, names, "users of other organizations are hidden")
+}
+
+func TestDatabaseCreate_OrganizationQuota(t *testing.T) {
+	t.Parallel()
+	f := newOrgFixture(t)
+	require.NoError(t, f.repos.Tiers.Create(context.Background(), &tier.Tier{Name: "standard"}))
+	full := 100
+	_, err := f.repos.Organizations.Update(context.Background(), f.acme.ID, organization.UpdateFields{QuotaWarningPercent: &full})
+	require.NoError(t, err)
+	quotas := organization.NewQuotas(f.repos.Organizations, f.repos.Teams, f.repos.Databases, nil)
+	dbs := handler.NewDatabaseHandler(f.repos.Databases, f.repos.Teams, f.repos.Tiers, f.repos.Blueprints, nil, "default", nil, nil, nil, nil, nil, 0, nil, quotas, nil, nil, nil, "", nil, nil, nil, nil)
+
+	create := func(name string) (int, map[string]interface{}) {
+		body, _ := json.Marshal(map[string]string{"name": name, "ownerTeam": f.checkout.Name, "tier": "standard"})
+		req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(f.checkout.Name, f.checkout.ID))
+		dbs.Create(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+
+	code, env := create("orders")
+	require.Equal(t, http.StatusCreated, code, env)
+	assert.Equal(t, []interface{}{"organization acme will have 1 of 1 databases; creating databases fails once the quota is reached"},
+		env["meta"].(map[string]interface{})["warnings"])
+
+	code, env = create("carts")
+	assert.Equal(t, http.StatusConflict, code)
+	errObj := env["error"].(map[string]interface{})
+	assert.Equal(t, "QUOTA_EXCEEDED", errObj["code"])
+	assert.Contains(t, errObj["message"], "organization acme has 1 of 1 databases")
+}
@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/placement"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestDatabaseCreate_Placement(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard"}))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "dedicated", Namespace: "db-{{ .Team }}"}))
+	engine, err := placement.New(repos.Databases, placement.Config{Capacities: map[string]int{"db-pool-a": 1, "db-pool-b": 1}})
+	require.NoError(t, err)
+	dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, nil, "default", nil, nil, nil, nil, nil, 0, nil, nil, engine, nil, nil, "", nil, nil, nil, nil)
+
+	create := func(fields map[string]string) (int, map[string]interface{}) {
+		fields["ownerTeam"] = checkout.Name
+		body, _ := json.Marshal(fields)
+		req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(checkout.Name, checkout.ID))
+		dbs.Create(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+
+	code, env := create(map[string]string{"name": "orders", "tier": "standard"})
+	require.Equal(t, http.StatusCreated, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "db-pool-a", data["namespace"])
+	placed := data["placement"].(map[string]interface{})
+	assert.Equal(t, "db-pool-a", placed["namespace"])
+	assert.Equal(t, "least loaded eligible namespace, with 0 of 1 databases", placed["reason"])
+	assert.Len(t, placed["candidates"], 2)
+
+	got, err := repos.Databases.GetByName(ctx, "orders")
+	require.NoError(t, err)
+	require.NotNil(t, got.Placement, "the decision is recorded on the database")
+	assert.Equal(t, "db-pool-a", got.Placement.Namespace)
+
+	// The request's and the tier's namespace take precedence over placement.
+	code, env = create(map[string]string{"name": "carts", "tier": "dedicated"})
+	require.Equal(t, http.StatusCreated, code, env)
+	data = env["data"].(map[string]interface{})
+	assert.Equal(t, "db-checkout", data["namespace"])
+	assert.NotContains(t, data, "placement")
+
+	code, env = create(map[string]string{"name": "search", "tier": "standard", "namespace": "db-search"})
+	require.Equal(t, http.StatusCreated, code, env)
+	assert.NotContains(t, env["data"], "placement")
+
+	code, _ = create(map[string]string{"name": "payments", "tier": "standard"})
+	require.Equal(t, http.StatusCreated, code)
+
+	code, env = create(map[string]string{"name": "reviews", "tier": "standard"})
+	assert.Equal(t, http.StatusConflict, code)
+	errObj := env["error"].(map[string]interface{})
+	assert.Equal(t, "NO_CAPACITY", errObj["code"])
+	assert.Contains(t, errObj["message"], "db-pool-a: at capacity, db-pool-b: at capacity")
+}
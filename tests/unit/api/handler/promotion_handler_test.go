@@ -0,0 +1,209 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+var testEnvironments = database.Environments{"dev", "staging", "prod"}
+
+type promotionFixture struct {
+	repos    *fake.Repositories
+	provider *fake.Provider
+	team     *team.Team
+	tier     *tier.Tier
+	bp       *blueprint.Blueprint
+	h        *handler.PromotionHandler
+	dbs      *handler.DatabaseHandler
+}
+
+func newPromotionFixture(t *testing.T) *promotionFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &promotionFixture{repos: repos, provider: fake.NewProvider(), team: &team.Team{Name: "checkout", Role: "product"}}
+	require.NoError(t, repos.Teams.Create(ctx, f.team))
+	f.bp = &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster"}
+	require.NoError(t, repos.Blueprints.Create(ctx, f.bp))
+	f.tier = &tier.Tier{Name: "standard", BlueprintID: &f.bp.ID}
+	require.NoError(t, repos.Tiers.Create(ctx, f.tier))
+
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", f.provider)
+	f.h = handler.NewPromotionHandler(repos.Databases, repos.Tiers, repos.Blueprints, registry, repos.Promotions, testEnvironments, "default", nil, nil, nil, nil, nil, nil, nil, "", nil)
+	f.dbs = handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, nil, "default", nil, testEnvironments, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	return f
+}
+
+func (f *promotionFixture) seed(t *testing.T, name, env, status string) *database.Database {
+	t.Helper()
+	ctx := context.Background()
+	db := &database.Database{Name: name, OwnerTeamID: f.team.ID, TierID: &f.tier.ID, Purpose: "orders", Namespace: "default", Environment: env}
+	require.NoError(t, f.repos.Databases.Create(ctx, db))
+	_, err := f.repos.Databases.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: status})
+	require.NoError(t, err)
+	return db
+}
+
+func (f *promotionFixture) promote(t *testing.T, db *database.Database, body map[string]string) (int, map[string]interface{}, http.Header) {
+	t.Helper()
+	var raw []byte
+	if body != nil {
+		raw, _ = json.Marshal(body)
+	}
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+db.ID.String()+"/promote", raw,
+		map[string]string{"id": db.ID.String()}, productIdentity(f.team.Name, f.team.ID))
+	f.h.Promote(w, req)
+	return w.Code, parseEnvelope(t, w), w.Header()
+}
+
+func TestPromote_CreatesDatabaseInNextEnvironment(t *testing.T) {
+	t.Parallel()
+	f := newPromotionFixture(t)
+	source := f.seed(t, "orders-dev", "dev", "ready")
+
+	code, env, header := f.promote(t, source, nil)
+
+	require.Equal(t, http.StatusCreated, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "orders-staging", data["name"])
+	assert.Equal(t, "staging", data["environment"])
+	assert.Equal(t, "standard", data["tier"])
+	assert.Equal(t, "orders", data["purpose"])
+	assert.Equal(t, source.ID.String(), data["promotedFromId"])
+	assert.Equal(t, "/databases/"+data["id"].(string), header.Get("Location"))
+
+	calls := f.provider.ApplyCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, "orders-staging", calls[0].Database.Name)
+	assert.Equal(t, f.bp.Manifests, calls[0].Manifests)
+
+	promotions, err := f.repos.Promotions.ListByDatabase(context.Background(), source.ID)
+	require.NoError(t, err)
+	require.Len(t, promotions, 1)
+	assert.Equal(t, database.PromotionCreated, promotions[0].Action)
+	assert.Equal(t, "dev", promotions[0].FromEnvironment)
+	assert.Equal(t, "staging", promotions[0].ToEnvironment)
+	assert.Equal(t, "cnpg-standard", promotions[0].Blueprint)
+	assert.Equal(t, "product-user", promotions[0].Actor)
+}
+
+func TestPromote_UpdatesEarlierTarget(t *testing.T) {
+	t.Parallel()
+	f := newPromotionFixture(t)
+	ctx := context.Background()
+	source := f.seed(t, "orders-dev", "dev", "ready")
+
+	code, _, _ := f.promote(t, source, nil)
+	require.Equal(t, http.StatusCreated, code)
+
+	large := &tier.Tier{Name: "large", BlueprintID: &f.bp.ID}
+	require.NoError(t, f.repos.Tiers.Create(ctx, large))
+	_, err := f.repos.Databases.Update(ctx, source.ID, database.UpdateFields{TierID: &large.ID})
+	require.NoError(t, err)
+
+	code, env, _ := f.promote(t, source, map[string]string{"name": "ignored"})
+	require.Equal(t, http.StatusOK, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "orders-staging", data["name"])
+	assert.Equal(t, "large", data["tier"])
+	assert.Len(t, f.provider.ApplyCalls(), 2)
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+source.ID.String()+"/promotions", nil,
+		map[string]string{"id": source.ID.String()}, platformIdentity())
+	f.h.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 2)
+	assert.Equal(t, "created", items[0].(map[string]interface{})["action"])
+	assert.Equal(t, "updated", items[1].(map[string]interface{})["action"])
+	assert.Equal(t, "large", items[1].(map[string]interface{})["tier"])
+}
+
+func TestPromote_CustomNameAndDuplicate(t *testing.T) {
+	t.Parallel()
+	f := newPromotionFixture(t)
+	source := f.seed(t, "orders", "dev", "ready")
+	f.seed(t, "orders-staging", "staging", "ready")
+
+	code, env, _ := f.promote(t, source, nil)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "DUPLICATE_NAME", env["error"].(map[string]interface{})["code"])
+
+	code, env, _ = f.promote(t, source, map[string]string{"name": "orders-stg"})
+	require.Equal(t, http.StatusCreated, code, env)
+	assert.Equal(t, "orders-stg", env["data"].(map[string]interface{})["name"])
+}
+
+func TestPromote_NotPossible(t *testing.T) {
+	t.Parallel()
+	f := newPromotionFixture(t)
+
+	cases := map[string]*database.Database{
+		"last environment": f.seed(t, "orders-prod", "prod", "ready"),
+		"no environment":   f.seed(t, "legacy", "", "ready"),
+		"not ready":        f.seed(t, "orders-dev", "dev", "provisioning"),
+	}
+	for name, db := range cases {
+		code, env, _ := f.promote(t, db, nil)
+		assert.Equal(t, http.StatusConflict, code, name)
+		assert.Equal(t, "PROMOTION_NOT_POSSIBLE", env["error"].(map[string]interface{})["code"], name)
+	}
+	assert.Empty(t, f.provider.ApplyCalls())
+}
+
+func TestPromote_OtherTeamNotFound(t *testing.T) {
+	t.Parallel()
+	f := newPromotionFixture(t)
+	source := f.seed(t, "orders-dev", "dev", "ready")
+	other := &team.Team{Name: "billing", Role: "product"}
+	require.NoError(t, f.repos.Teams.Create(context.Background(), other))
+
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+source.ID.String()+"/promote", nil,
+		map[string]string{"id": source.ID.String()}, productIdentity(other.Name, other.ID))
+	f.h.Promote(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDatabaseCreate_Environment(t *testing.T) {
+	t.Parallel()
+	f := newPromotionFixture(t)
+	identity := productIdentity(f.team.Name, f.team.ID)
+
+	body, _ := json.Marshal(map[string]string{"name": "orders", "ownerTeam": "checkout", "tier": "standard"})
+	req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, identity)
+	f.dbs.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "dev", parseEnvelope(t, w)["data"].(map[string]interface{})["environment"])
+
+	body, _ = json.Marshal(map[string]string{"name": "reports", "ownerTeam": "checkout", "tier": "standard", "environment": "prod"})
+	req, w = makeAuthRequest(http.MethodPost, "/databases", body, nil, identity)
+	f.dbs.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "prod", parseEnvelope(t, w)["data"].(map[string]interface{})["environment"])
+
+	body, _ = json.Marshal(map[string]string{"name": "scratch", "ownerTeam": "checkout", "tier": "standard", "environment": "qa"})
+	req, w = makeAuthRequest(http.MethodPost, "/databases", body, nil, identity)
+	f.dbs.Create(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, w = makeAuthRequest(http.MethodGet, "/databases?environment=prod", nil, nil, identity)
+	f.dbs.List(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	items := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, items, 1)
+	assert.Equal(t, "reports", items[0].(map[string]interface{})["name"])
+}
@@ -0,0 +1,173 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/recommend"
+)
+
+type stubRecommender struct {
+	result *recommend.Result
+	err    error
+}
+
+func (s *stubRecommender) Recommend(_ context.Context, _ *database.Database) (*recommend.Result, error) {
+	return s.result, s.err
+}
+
+type stubTierChanges struct {
+	changes []database.TierChange
+	err     error
+}
+
+func (s *stubTierChanges) Record(_ context.Context, c *database.TierChange) error {
+	s.changes = append(s.changes, *c)
+	return nil
+}
+
+func (s *stubTierChanges) ListByDatabase(_ context.Context, id uuid.UUID) ([]database.TierChange, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	out := []database.TierChange{}
+	for _, c := range s.changes {
+		if c.DatabaseID == id {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func TestRecommendations_Get(t *testing.T) {
+	t.Parallel()
+	teamID := uuid.New()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: teamID}
+	rec := &stubRecommender{result: &recommend.Result{
+		CurrentTier: "standard",
+		SampleCount: 20,
+		MinSamples:  12,
+		Usage:       recommend.Usage{CPUP95Millis: 1800, CPURequestMillis: 2000, MemoryMaxBytes: 2 << 30, MemoryRequestBytes: 4 << 30},
+		Recommendations: []recommend.Recommendation{
+			{Action: recommend.ActionUpsize, Tier: "large", Reason: "CPU p95 1800m"},
+		},
+		AutoApply: true,
+		Window:    "Sun 02:00-04:00",
+	}}
+	changes := &stubTierChanges{changes: []database.TierChange{
+		{ID: 1, DatabaseID: db.ID, FromTier: "small", ToTier: "standard", Actor: recommend.Actor, Reason: "upsize: busy",
+			Status: database.TierChangeApplied, CreatedAt: time.Date(2026, 3, 1, 2, 5, 0, 0, time.UTC)},
+		{ID: 2, DatabaseID: db.ID, FromTier: "standard", ToTier: "large", Actor: recommend.Actor, Reason: "upsize: busy",
+			Status: database.TierChangeFailed, Error: "apply failed", CreatedAt: time.Date(2026, 3, 8, 2, 5, 0, 0, time.UTC)},
+		{ID: 3, DatabaseID: uuid.New(), FromTier: "a", ToTier: "b"},
+	}}
+	h := handler.NewRecommendationHandler(resizeEventsRepo(db), rec, changes)
+
+	for _, identity := range []string{"platform", "product"} {
+		t.Run(identity, func(t *testing.T) {
+			id := platformIdentity()
+			if identity == "product" {
+				id = productIdentity("orders-team", teamID)
+			}
+			req, w := makeAuthRequest(http.MethodGet, "/databases/"+db.ID.String()+"/recommendations", nil, map[string]string{"id": db.ID.String()}, id)
+
+			h.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			data := parseEnvelope(t, w)["data"].(map[string]interface{})
+			assert.Equal(t, "standard", data["currentTier"])
+			assert.Equal(t, float64(20), data["sampleCount"])
+			assert.Equal(t, float64(12), data["minSamples"])
+			usage := data["usage"].(map[string]interface{})
+			assert.Equal(t, float64(1800), usage["cpuP95Millis"])
+			assert.Equal(t, float64(4<<30), usage["memoryRequestBytes"])
+
+			recs := data["recommendations"].([]interface{})
+			require.Len(t, recs, 1)
+			assert.Equal(t, "upsize", recs[0].(map[string]interface{})["action"])
+			assert.Equal(t, "large", recs[0].(map[string]interface{})["tier"])
+
+			autoApply := data["autoApply"].(map[string]interface{})
+			assert.Equal(t, true, autoApply["enabled"])
+			assert.Equal(t, "Sun 02:00-04:00", autoApply["window"])
+
+			history := data["history"].([]interface{})
+			require.Len(t, history, 2)
+			first := history[0].(map[string]interface{})
+			assert.Equal(t, "small", first["fromTier"])
+			assert.Equal(t, "system:recommender", first["actor"])
+			assert.Equal(t, "applied", first["status"])
+			assert.Nil(t, first["error"])
+			assert.Equal(t, "2026-03-01T02:05:00Z", first["createdAt"])
+			assert.Equal(t, "apply failed", history[1].(map[string]interface{})["error"])
+		})
+	}
+}
+
+func TestRecommendations_NoTierAutoApplyDisabled(t *testing.T) {
+	t.Parallel()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: uuid.New()}
+	rec := &stubRecommender{result: &recommend.Result{MinSamples: 12, Recommendations: []recommend.Recommendation{}}}
+	h := handler.NewRecommendationHandler(resizeEventsRepo(db), rec, &stubTierChanges{})
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+db.ID.String()+"/recommendations", nil, map[string]string{"id": db.ID.String()}, platformIdentity())
+
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Nil(t, data["currentTier"])
+	assert.Equal(t, []interface{}{}, data["recommendations"])
+	assert.Equal(t, []interface{}{}, data["history"])
+	assert.Equal(t, map[string]interface{}{"enabled": false, "window": nil}, data["autoApply"])
+}
+
+func TestRecommendations_Errors(t *testing.T) {
+	t.Parallel()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: uuid.New()}
+	ok := &stubRecommender{result: &recommend.Result{}}
+
+	tests := []struct {
+		name     string
+		id       string
+		rec      *stubRecommender
+		changes  *stubTierChanges
+		wantCode int
+		wantErr  string
+	}{
+		{"invalid id", "not-a-uuid", ok, &stubTierChanges{}, http.StatusBadRequest, "INVALID_ID"},
+		{"unknown database", uuid.New().String(), ok, &stubTierChanges{}, http.StatusNotFound, "NOT_FOUND"},
+		{"recommend failure", db.ID.String(), &stubRecommender{err: errors.New("boom")}, &stubTierChanges{}, http.StatusInternalServerError, "INTERNAL_ERROR"},
+		{"history failure", db.ID.String(), ok, &stubTierChanges{err: errors.New("boom")}, http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewRecommendationHandler(resizeEventsRepo(db), tt.rec, tt.changes)
+			req, w := makeAuthRequest(http.MethodGet, "/databases/"+tt.id+"/recommendations", nil, map[string]string{"id": tt.id}, platformIdentity())
+
+			h.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantErr, parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+		})
+	}
+}
+
+func TestRecommendations_ProductUserOtherTeam(t *testing.T) {
+	t.Parallel()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: uuid.New()}
+	h := handler.NewRecommendationHandler(resizeEventsRepo(db), &stubRecommender{result: &recommend.Result{}}, &stubTierChanges{})
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+db.ID.String()+"/recommendations", nil, map[string]string{"id": db.ID.String()}, productIdentity("other", uuid.New()))
+
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+// pausingRepo returns a mockRepo applying the reconciliation pause of an
+// update to a sample database, recording the fields it was given.
+func pausingRepo(id uuid.UUID, got *database.UpdateFields) *mockRepo {
+	return &mockRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*database.Database, error) {
+			return sampleDB(id, "ready"), nil
+		},
+		updateFn: func(_ context.Context, _ uuid.UUID, fields database.UpdateFields) (*database.Database, error) {
+			*got = fields
+			db := sampleDB(id, "ready")
+			db.ReconciliationPause = fields.ReconciliationPause
+			return db, nil
+		},
+	}
+}
+
+func TestUpdate_PauseReconciliation(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	var got database.UpdateFields
+	h := newTestHandler(pausingRepo(id, &got), &mockDBTeamRepo{})
+
+	until := time.Now().Add(2 * time.Hour).UTC().Truncate(time.Second)
+	body, _ := json.Marshal(map[string]interface{}{
+		"reconciliationPaused":      true,
+		"reconciliationPausedUntil": until.Format(time.RFC3339),
+	})
+	req, w := makeAuthRequest(http.MethodPatch, "/databases/"+id.String(), body, map[string]string{"id": id.String()}, platformIdentity())
+	h.Update(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, got.ReconciliationPause)
+	assert.Equal(t, "platform-user", got.ReconciliationPause.By)
+	assert.True(t, until.Equal(got.ReconciliationPause.Until))
+
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	pause := data["reconciliationPause"].(map[string]interface{})
+	assert.Equal(t, "platform-user", pause["by"])
+	assert.Equal(t, until.Format(time.RFC3339), pause["until"])
+}
+
+func TestUpdate_PauseReconciliation_DefaultExpiry(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	var got database.UpdateFields
+	h := newTestHandler(pausingRepo(id, &got), &mockDBTeamRepo{})
+
+	body, _ := json.Marshal(map[string]interface{}{"reconciliationPaused": true})
+	req, w := makeAuthRequest(http.MethodPatch, "/databases/"+id.String(), body, map[string]string{"id": id.String()}, platformIdentity())
+	h.Update(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NotNil(t, got.ReconciliationPause)
+	assert.WithinDuration(t, time.Now().Add(database.DefaultReconciliationPause), got.ReconciliationPause.Until, time.Minute)
+}
+
+func TestUpdate_ResumeReconciliation(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	var got database.UpdateFields
+	h := newTestHandler(pausingRepo(id, &got), &mockDBTeamRepo{})
+
+	body, _ := json.Marshal(map[string]interface{}{"reconciliationPaused": false})
+	req, w := makeAuthRequest(http.MethodPatch, "/databases/"+id.String(), body, map[string]string{"id": id.String()}, platformIdentity())
+	h.Update(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Nil(t, got.ReconciliationPause)
+	assert.True(t, got.ResumeReconciliation)
+	assert.NotContains(t, parseEnvelope(t, w)["data"], "reconciliationPause")
+}
+
+func TestUpdate_PauseReconciliation_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		fields     map[string]interface{}
+		product    bool
+		wantStatus int
+		wantCode   string
+	}{
+		{"product user", map[string]interface{}{"reconciliationPaused": true}, true, http.StatusForbidden, "FORBIDDEN"},
+		{
+			"expiry in the past",
+			map[string]interface{}{"reconciliationPaused": true, "reconciliationPausedUntil": time.Now().Add(-time.Hour).Format(time.RFC3339)},
+			false, http.StatusBadRequest, "VALIDATION_ERROR",
+		},
+		{
+			"expiry too far",
+			map[string]interface{}{"reconciliationPaused": true, "reconciliationPausedUntil": time.Now().Add(8 * 24 * time.Hour).Format(time.RFC3339)},
+			false, http.StatusBadRequest, "VALIDATION_ERROR",
+		},
+		{
+			"expiry without pause",
+			map[string]interface{}{"reconciliationPausedUntil": time.Now().Add(time.Hour).Format(time.RFC3339)},
+			false, http.StatusBadRequest, "VALIDATION_ERROR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			id := uuid.New()
+			repo := &mockRepo{
+				updateFn: func(_ context.Context, _ uuid.UUID, _ database.UpdateFields) (*database.Database, error) {
+					t.Fatal("database must not be updated")
+					return nil, nil
+				},
+			}
+			h := newTestHandler(repo, &mockDBTeamRepo{})
+
+			identity := platformIdentity()
+			if tt.product {
+				identity = productIdentity("my-team", uuid.New())
+			}
+			body, _ := json.Marshal(tt.fields)
+			req, w := makeAuthRequest(http.MethodPatch, "/databases/"+id.String(), body, map[string]string{"id": id.String()}, identity)
+			h.Update(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			apiErr := parseEnvelope(t, w)["error"].(map[string]interface{})
+			assert.Equal(t, tt.wantCode, apiErr["code"])
+		})
+	}
+}
@@ -0,0 +1,342 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// renaming returns a database handler that renames databases, and a
+// reconciler that carries the renames through.
+func (f *operationFixture) renaming() (*handler.DatabaseHandler, *reconciler.Reconciler) {
+	dbs := handler.NewDatabaseHandler(f.repos.Databases, f.repos.Teams, f.repos.Tiers, f.repos.Blueprints, f.registry, "default", nil, nil, nil, nil, f.ops, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, f.repos.Renames)
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute,
+		reconciler.WithOperations(f.ops), reconciler.WithRenames(f.repos.Renames))
+	return dbs, rec
+}
+
+func rename(t *testing.T, dbs *handler.DatabaseHandler, dbID, name string, identity *auth.Identity) *httptest.ResponseRecorder {
+	t.Helper()
+	body, _ := json.Marshal(map[string]string{"name": name})
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/rename", body, map[string]string{"id": dbID}, identity)
+	dbs.Rename(w, req)
+	return w
+}
+
+func (f *operationFixture) database(t *testing.T, dbID string) *database.Database {
+	t.Helper()
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	return db
+}
+
+func TestRename_CopiesCutsOverAndRetires(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbs, rec := f.renaming()
+	dbID, _ := f.create(t, "ordrs")
+	f.readyWithPrimary(t, rec, dbID, "daap-ordrs-1")
+
+	w := rename(t, dbs, dbID, "orders", productIdentity(f.team.Name, f.team.ID))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "renaming", data["status"])
+	assert.Equal(t, "ordrs", data["name"])
+	renameData := data["rename"].(map[string]interface{})
+	assert.Equal(t, "ordrs", renameData["from"])
+	assert.Equal(t, "orders", renameData["to"])
+	assert.Equal(t, "copying", renameData["phase"])
+	assert.Equal(t, "product-user", renameData["requestedBy"])
+
+	clones := f.provider.CloneCalls()
+	require.Len(t, clones, 1)
+	assert.Equal(t, "daap-orders", clones[0].Database.ClusterName)
+	assert.Equal(t, "daap-orders-pooler", clones[0].Database.PoolerName)
+	assert.Equal(t, "daap-ordrs", clones[0].Source.ClusterName)
+	assert.Equal(t, "kind: Cluster", clones[0].Manifests)
+
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+	_, env := f.get(t, opID, platformIdentity())
+	assert.Equal(t, "rename", env["data"].(map[string]interface{})["type"])
+
+	// The new name is reserved while the rename runs.
+	body, _ := json.Marshal(map[string]string{"name": "orders", "tier": "standard"})
+	req, cw := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(f.team.Name, f.team.ID))
+	f.dbs.Create(cw, req)
+	assert.Equal(t, http.StatusConflict, cw.Code)
+
+	// The copy is ready: it is promoted.
+	rec.RunOnce(context.Background())
+	promotions := f.provider.PromoteCalls()
+	require.Len(t, promotions, 1)
+	assert.Equal(t, "daap-orders", promotions[0].Database.ClusterName)
+	assert.Equal(t, "daap-ordrs", promotions[0].Source.ClusterName)
+	db := f.database(t, dbID)
+	assert.Equal(t, database.RenameCutover, db.Rename.Phase)
+	assert.Equal(t, "ordrs", db.Name)
+
+	// The promoted copy is ready: the database takes the new name.
+	rec.RunOnce(context.Background())
+	db = f.database(t, dbID)
+	assert.Equal(t, "orders", db.Name)
+	assert.Equal(t, "daap-orders", db.ClusterName)
+	assert.Equal(t, "daap-orders-pooler", db.PoolerName)
+	assert.Equal(t, database.RenameRetiring, db.Rename.Phase)
+	assert.Equal(t, "renaming", db.Status)
+
+	// The resources under the old name are deleted.
+	rec.RunOnce(context.Background())
+	deletes := f.provider.DeleteCalls()
+	require.Len(t, deletes, 1)
+	assert.Equal(t, "ordrs", deletes[0].Name)
+	assert.Equal(t, "daap-ordrs", deletes[0].ClusterName)
+	db = f.database(t, dbID)
+	assert.Equal(t, "ready", db.Status)
+	assert.Nil(t, db.Rename)
+
+	_, env = f.get(t, opID, platformIdentity())
+	data = env["data"].(map[string]interface{})
+	assert.Equal(t, "succeeded", data["status"])
+	assert.Equal(t, "orders", data["result"].(map[string]interface{})["name"])
+}
+
+func TestRename_FailedCopyIsAbandoned(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbs, rec := f.renaming()
+	dbID, _ := f.create(t, "ordrs")
+	f.readyWithPrimary(t, rec, dbID, "daap-ordrs-1")
+
+	w := rename(t, dbs, dbID, "orders", platformIdentity())
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+
+	f.provider.SetHealth(uuid.MustParse(dbID), provider.HealthResult{Status: "error", Message: "pg_basebackup failed"})
+	rec.RunOnce(context.Background())
+	assert.Equal(t, database.RenameAbandoning, f.database(t, dbID).Rename.Phase)
+	assert.Empty(t, f.provider.PromoteCalls())
+
+	rec.RunOnce(context.Background())
+	deletes := f.provider.DeleteCalls()
+	require.Len(t, deletes, 1)
+	assert.Equal(t, "daap-orders", deletes[0].ClusterName)
+	db := f.database(t, dbID)
+	assert.Equal(t, "ordrs", db.Name)
+	assert.Equal(t, "ready", db.Status)
+	assert.Nil(t, db.Rename)
+
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "failed", data["status"])
+	assert.Equal(t, "RENAME_FAILED", data["error"].(map[string]interface{})["code"])
+}
+
+func TestRename_CopyFailingAfterPromotionIsAbandoned(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbs, rec := f.renaming()
+	dbID, _ := f.create(t, "ordrs")
+	f.readyWithPrimary(t, rec, dbID, "daap-ordrs-1")
+
+	w := rename(t, dbs, dbID, "orders", platformIdentity())
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+
+	// The copy is promoted, which fences the database, and then fails.
+	rec.RunOnce(context.Background())
+	require.Len(t, f.provider.PromoteCalls(), 1)
+	f.provider.SetHealth(uuid.MustParse(dbID), provider.HealthResult{Status: "error", Message: "crash loop"})
+	rec.RunOnce(context.Background())
+	assert.Equal(t, database.RenameAbandoning, f.database(t, dbID).Rename.Phase)
+
+	// The database is unfenced and the copy deleted.
+	rec.RunOnce(context.Background())
+	unfences := f.provider.UnfenceCalls()
+	require.NotEmpty(t, unfences)
+	assert.Equal(t, "daap-ordrs", unfences[0].ClusterName)
+	deletes := f.provider.DeleteCalls()
+	require.Len(t, deletes, 1)
+	assert.Equal(t, "daap-orders", deletes[0].ClusterName)
+	db := f.database(t, dbID)
+	assert.Equal(t, "ordrs", db.Name)
+	assert.Equal(t, "ready", db.Status)
+	assert.Nil(t, db.Rename)
+
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "failed", data["status"])
+	assert.Equal(t, "RENAME_FAILED", data["error"].(map[string]interface{})["code"])
+}
+
+func TestRename_UnfenceFailureIsRetried(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbs, rec := f.renaming()
+	dbID, _ := f.create(t, "ordrs")
+	f.readyWithPrimary(t, rec, dbID, "daap-ordrs-1")
+	require.Equal(t, http.StatusAccepted, rename(t, dbs, dbID, "orders", platformIdentity()).Code)
+
+	rec.RunOnce(context.Background())
+	f.provider.SetHealth(uuid.MustParse(dbID), provider.HealthResult{Status: "error", Message: "crash loop"})
+	rec.RunOnce(context.Background())
+	f.provider.UnfenceFn = func(context.Context, provider.ProviderDatabase) error {
+		return errors.New("apiserver unavailable")
+	}
+
+	// The copy is kept until the database serves again.
+	rec.RunOnce(context.Background())
+	assert.Empty(t, f.provider.DeleteCalls())
+	assert.Equal(t, database.RenameAbandoning, f.database(t, dbID).Rename.Phase)
+
+	f.provider.UnfenceFn = nil
+	rec.RunOnce(context.Background())
+	require.Len(t, f.provider.DeleteCalls(), 1)
+	assert.Nil(t, f.database(t, dbID).Rename)
+}
+
+func TestRename_CopyThatCannotBePromotedIsAbandoned(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbs, rec := f.renaming()
+	dbID, _ := f.create(t, "ordrs")
+	f.readyWithPrimary(t, rec, dbID, "daap-ordrs-1")
+	require.Equal(t, http.StatusAccepted, rename(t, dbs, dbID, "orders", platformIdentity()).Code)
+	f.provider.PromoteFn = func(context.Context, provider.ProviderDatabase, provider.ProviderDatabase) error {
+		return provider.ErrNotSupported
+	}
+
+	rec.RunOnce(context.Background())
+	assert.Equal(t, database.RenameAbandoning, f.database(t, dbID).Rename.Phase)
+
+	rec.RunOnce(context.Background())
+	require.Len(t, f.provider.DeleteCalls(), 1)
+	assert.Nil(t, f.database(t, dbID).Rename)
+	assert.Equal(t, "ordrs", f.database(t, dbID).Name)
+}
+
+func TestRename_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, f *operationFixture, rec *reconciler.Reconciler, dbID string)
+		newName  string
+		identity func(f *operationFixture) *auth.Identity
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "not ready",
+			newName:  "orders",
+			wantCode: http.StatusConflict,
+			wantErr:  "RENAME_NOT_POSSIBLE",
+		},
+		{
+			name:     "invalid name",
+			setup:    readyOrdrs,
+			newName:  "Orders!",
+			wantCode: http.StatusBadRequest,
+			wantErr:  "VALIDATION_ERROR",
+		},
+		{
+			name:     "same name",
+			setup:    readyOrdrs,
+			newName:  "ordrs",
+			wantCode: http.StatusBadRequest,
+			wantErr:  "VALIDATION_ERROR",
+		},
+		{
+			name: "name taken",
+			setup: func(t *testing.T, f *operationFixture, rec *reconciler.Reconciler, dbID string) {
+				readyOrdrs(t, f, rec, dbID)
+				f.create(t, "orders")
+			},
+			newName:  "orders",
+			wantCode: http.StatusConflict,
+			wantErr:  "DUPLICATE_NAME",
+		},
+		{
+			name:     "other team",
+			setup:    readyOrdrs,
+			newName:  "orders",
+			identity: func(*operationFixture) *auth.Identity { return productIdentity("payments", uuid.New()) },
+			wantCode: http.StatusNotFound,
+			wantErr:  "NOT_FOUND",
+		},
+		{
+			name: "provider cannot rename",
+			setup: func(t *testing.T, f *operationFixture, rec *reconciler.Reconciler, dbID string) {
+				readyOrdrs(t, f, rec, dbID)
+				f.registry.Register("cnpg", plainProvider{Provider: fake.NewProvider()})
+			},
+			newName:  "orders",
+			wantCode: http.StatusConflict,
+			wantErr:  "RENAME_NOT_POSSIBLE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := newOperationFixture(t)
+			dbs, rec := f.renaming()
+			dbID, _ := f.create(t, "ordrs")
+			if tt.setup != nil {
+				tt.setup(t, f, rec, dbID)
+			}
+			identity := platformIdentity()
+			if tt.identity != nil {
+				identity = tt.identity(f)
+			}
+
+			w := rename(t, dbs, dbID, tt.newName, identity)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantErr, parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+			assert.Empty(t, f.provider.CloneCalls())
+			assert.Nil(t, f.database(t, dbID).Rename)
+		})
+	}
+}
+
+func TestRename_CloneFailureReleasesName(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	dbs, rec := f.renaming()
+	dbID, _ := f.create(t, "ordrs")
+	f.readyWithPrimary(t, rec, dbID, "daap-ordrs-1")
+	f.provider.CloneFn = func(context.Context, provider.ProviderDatabase, provider.ProviderDatabase, string) error {
+		return errors.New("admission webhook denied the request")
+	}
+
+	w := rename(t, dbs, dbID, "orders", platformIdentity())
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	db := f.database(t, dbID)
+	assert.Equal(t, "ready", db.Status)
+	assert.Nil(t, db.Rename)
+	deletes := f.provider.DeleteCalls()
+	require.Len(t, deletes, 1)
+	assert.Equal(t, "daap-orders", deletes[0].ClusterName)
+
+	// The name is free again.
+	f.create(t, "orders")
+}
+
+func readyOrdrs(t *testing.T, f *operationFixture, rec *reconciler.Reconciler, dbID string) {
+	t.Helper()
+	f.readyWithPrimary(t, rec, dbID, "daap-ordrs-1")
+}
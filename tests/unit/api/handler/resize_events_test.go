@@ -0,0 +1,133 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+)
+
+type stubResizeEvents struct {
+	events []database.ResizeEvent
+	err    error
+}
+
+func (s *stubResizeEvents) Record(_ context.Context, e *database.ResizeEvent) error {
+	s.events = append(s.events, *e)
+	return nil
+}
+
+func (s *stubResizeEvents) ListByDatabase(_ context.Context, id uuid.UUID) ([]database.ResizeEvent, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	var out []database.ResizeEvent
+	for _, e := range s.events {
+		if e.DatabaseID == id {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func resizeEventsRepo(db *database.Database) *mockRepo {
+	return &mockRepo{
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*database.Database, error) {
+			if id != db.ID {
+				return nil, database.ErrNotFound
+			}
+			return db, nil
+		},
+	}
+}
+
+func TestResizeEvents_List(t *testing.T) {
+	t.Parallel()
+	teamID := uuid.New()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: teamID}
+	events := &stubResizeEvents{events: []database.ResizeEvent{
+		{ID: 1, DatabaseID: db.ID, FromBytes: 10 << 30, ToBytes: 12 << 30, UsedBytes: 9 << 30, CreatedAt: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)},
+		{ID: 2, DatabaseID: uuid.New(), FromBytes: 1, ToBytes: 2, UsedBytes: 1},
+	}}
+	h := handler.NewResizeEventHandler(resizeEventsRepo(db), events)
+
+	for _, identity := range []string{"platform", "product"} {
+		t.Run(identity, func(t *testing.T) {
+			id := platformIdentity()
+			if identity == "product" {
+				id = productIdentity("orders-team", teamID)
+			}
+			req, w := makeAuthRequest(http.MethodGet, "/databases/"+db.ID.String()+"/resize-events", nil, map[string]string{"id": db.ID.String()}, id)
+
+			h.ServeHTTP(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			data := parseEnvelope(t, w)["data"].([]interface{})
+			require.Len(t, data, 1)
+			item := data[0].(map[string]interface{})
+			assert.Equal(t, float64(10<<30), item["fromBytes"])
+			assert.Equal(t, float64(12<<30), item["toBytes"])
+			assert.Equal(t, float64(9<<30), item["usedBytes"])
+			assert.Equal(t, "2026-03-01T12:00:00Z", item["createdAt"])
+		})
+	}
+}
+
+func TestResizeEvents_EmptyList(t *testing.T) {
+	t.Parallel()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: uuid.New()}
+	h := handler.NewResizeEventHandler(resizeEventsRepo(db), &stubResizeEvents{})
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+db.ID.String()+"/resize-events", nil, map[string]string{"id": db.ID.String()}, platformIdentity())
+
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, []interface{}{}, parseEnvelope(t, w)["data"])
+}
+
+func TestResizeEvents_Errors(t *testing.T) {
+	t.Parallel()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: uuid.New()}
+
+	tests := []struct {
+		name     string
+		id       string
+		events   *stubResizeEvents
+		wantCode int
+		wantErr  string
+	}{
+		{"invalid id", "not-a-uuid", &stubResizeEvents{}, http.StatusBadRequest, "INVALID_ID"},
+		{"unknown database", uuid.New().String(), &stubResizeEvents{}, http.StatusNotFound, "NOT_FOUND"},
+		{"list failure", db.ID.String(), &stubResizeEvents{err: errors.New("boom")}, http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := handler.NewResizeEventHandler(resizeEventsRepo(db), tt.events)
+			req, w := makeAuthRequest(http.MethodGet, "/databases/"+tt.id+"/resize-events", nil, map[string]string{"id": tt.id}, platformIdentity())
+
+			h.ServeHTTP(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantErr, parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+		})
+	}
+}
+
+func TestResizeEvents_ProductUserOtherTeam(t *testing.T) {
+	t.Parallel()
+	db := &database.Database{ID: uuid.New(), Name: "orders", OwnerTeamID: uuid.New()}
+	h := handler.NewResizeEventHandler(resizeEventsRepo(db), &stubResizeEvents{})
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+db.ID.String()+"/resize-events", nil, map[string]string{"id": db.ID.String()}, productIdentity("other", uuid.New()))
+
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,118 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func (f *operationFixture) restart(t *testing.T, dbID string, identity *auth.Identity) *httptest.ResponseRecorder {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/restart", nil, map[string]string{"id": dbID}, identity)
+	f.dbs.Restart(w, req)
+	return w
+}
+
+func TestRestart_TrackedUntilRestarted(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute,
+		reconciler.WithOperations(f.ops))
+	dbID, _ := f.create(t, "orders")
+	f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+	restarted := false
+	f.provider.RestartedFn = func(context.Context, provider.ProviderDatabase) (bool, error) {
+		return restarted, nil
+	}
+
+	w := f.restart(t, dbID, productIdentity(f.team.Name, f.team.ID))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Equal(t, "restarting", parseEnvelope(t, w)["data"].(map[string]interface{})["status"])
+	require.Len(t, f.provider.RestartCalls(), 1)
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+	_, env := f.get(t, opID, platformIdentity())
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "restart", data["type"])
+	assert.Equal(t, "running", data["status"])
+
+	// Ready but not every instance has restarted yet.
+	rec.RunOnce(context.Background())
+	db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "restarting", db.Status)
+
+	restarted = true
+	rec.RunOnce(context.Background())
+	db, err = f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "ready", db.Status)
+	_, env = f.get(t, opID, platformIdentity())
+	assert.Equal(t, "succeeded", env["data"].(map[string]interface{})["status"])
+}
+
+func TestRestart_Rejected(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not ready", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+
+		w := f.restart(t, dbID, platformIdentity())
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "RESTART_NOT_POSSIBLE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+		assert.Empty(t, f.provider.RestartCalls())
+	})
+
+	t.Run("other team", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+
+		w := f.restart(t, dbID, productIdentity("payments", uuid.New()))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("provider cannot restart", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+		f.registry.Register("cnpg", plainProvider{Provider: fake.NewProvider()})
+
+		w := f.restart(t, dbID, platformIdentity())
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, `Provider "cnpg" does not support restarts`, parseEnvelope(t, w)["error"].(map[string]interface{})["message"])
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+		f.provider.RestartFn = func(context.Context, provider.ProviderDatabase) error {
+			return errors.New("connection refused")
+		}
+
+		w := f.restart(t, dbID, platformIdentity())
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+		require.NoError(t, err)
+		assert.Equal(t, "ready", db.Status)
+	})
+}
@@ -0,0 +1,225 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func (f *operationFixture) restore(t *testing.T, dbID string, body map[string]string, identity *auth.Identity) *httptest.ResponseRecorder {
+	t.Helper()
+	raw, _ := json.Marshal(body)
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/restore", raw, map[string]string{"id": dbID}, identity)
+	f.dbs.Restore(w, req)
+	return w
+}
+
+func TestRestore_CreatesDatabaseFromBackup(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute, reconciler.WithOperations(f.ops))
+	sourceID, _ := f.create(t, "orders")
+	f.readyWithPrimary(t, rec, sourceID, "daap-orders-1")
+
+	w := f.restore(t, sourceID, map[string]string{
+		"name": "orders-restored", "backup": "daap-orders-nightly", "targetTime": "2026-02-03T08:45:00Z",
+	}, productIdentity(f.team.Name, f.team.ID))
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	restoredID := data["id"].(string)
+	assert.NotEqual(t, sourceID, restoredID)
+	assert.Equal(t, "orders-restored", data["name"])
+	assert.Equal(t, sourceID, data["sourceDatabaseId"])
+	assert.Equal(t, "provisioning", data["status"])
+	assert.Equal(t, "standard", data["tier"])
+	assert.Equal(t, "/databases/"+restoredID, w.Header().Get("Location"))
+
+	restores := f.provider.RestoreCalls()
+	require.Len(t, restores, 1)
+	assert.Equal(t, "daap-orders-restored", restores[0].Database.ClusterName)
+	assert.Equal(t, "daap-orders", restores[0].Source.ClusterName)
+	assert.Equal(t, "kind: Cluster", restores[0].Manifests)
+	assert.Equal(t, "daap-orders-nightly", restores[0].Target.Backup)
+	require.NotNil(t, restores[0].Target.Time)
+	assert.True(t, restores[0].Target.Time.Equal(time.Date(2026, 2, 3, 8, 45, 0, 0, time.UTC)))
+
+	// The source is left alone.
+	source := f.database(t, sourceID)
+	assert.Equal(t, "ready", source.Status)
+	assert.Nil(t, source.SourceDatabaseID)
+
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+	_, env := f.get(t, opID, platformIdentity())
+	op := env["data"].(map[string]interface{})
+	assert.Equal(t, "restore", op["type"])
+	assert.Equal(t, "running", op["status"])
+
+	f.readyWithPrimary(t, rec, restoredID, "daap-orders-restored-1")
+	_, env = f.get(t, opID, platformIdentity())
+	op = env["data"].(map[string]interface{})
+	assert.Equal(t, "succeeded", op["status"])
+	assert.Equal(t, restoredID, op["result"].(map[string]interface{})["databaseId"])
+}
+
+func TestRestore_LatestBackup(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	sourceID, _ := f.create(t, "orders")
+
+	f.provider.SetHealth(uuid.MustParse(sourceID), provider.HealthResult{Status: "error"})
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+	rec.RunOnce(context.Background())
+
+	// A database in error is restored too: that is what backups are for.
+	w := f.restore(t, sourceID, map[string]string{"name": "orders-restored"}, platformIdentity())
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	restores := f.provider.RestoreCalls()
+	require.Len(t, restores, 1)
+	assert.Equal(t, provider.RestoreTarget{}, restores[0].Target)
+}
+
+func TestRestore_ProviderFailureRollsBack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode int
+		wantErr  string
+	}{
+		{"no backup", fmt.Errorf("backup default/nightly: %w", provider.ErrBackupNotFound), http.StatusNotFound, "BACKUP_NOT_FOUND"},
+		{"not supported", provider.ErrNotSupported, http.StatusConflict, "RESTORE_NOT_POSSIBLE"},
+		{"provider error", errors.New("admission webhook denied the request"), http.StatusBadGateway, "RESTORE_FAILED"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := newOperationFixture(t)
+			rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+			sourceID, _ := f.create(t, "orders")
+			f.readyWithPrimary(t, rec, sourceID, "daap-orders-1")
+			f.provider.RestoreFn = func(context.Context, provider.ProviderDatabase, provider.ProviderDatabase, string, provider.RestoreTarget) error {
+				return tt.err
+			}
+
+			w := f.restore(t, sourceID, map[string]string{"name": "orders-restored"}, platformIdentity())
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantErr, parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+			_, err := f.repos.Databases.GetByName(context.Background(), "orders-restored")
+			assert.ErrorIs(t, err, database.ErrNotFound)
+			opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+			_, env := f.get(t, opID, platformIdentity())
+			op := env["data"].(map[string]interface{})
+			assert.Equal(t, "failed", op["status"])
+			assert.Equal(t, tt.wantErr, op["error"].(map[string]interface{})["code"])
+
+			// The name is free again.
+			f.create(t, "orders-restored")
+		})
+	}
+}
+
+func TestRestore_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		setup    func(t *testing.T, f *operationFixture, sourceID string)
+		body     map[string]string
+		identity func(f *operationFixture) *auth.Identity
+		wantCode int
+		wantErr  string
+	}{
+		{
+			name:     "still provisioning",
+			body:     map[string]string{"name": "orders-restored"},
+			wantCode: http.StatusConflict,
+			wantErr:  "RESTORE_NOT_POSSIBLE",
+		},
+		{
+			name:     "missing name",
+			setup:    readyOrders,
+			body:     map[string]string{"backup": "daap-orders-nightly"},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "VALIDATION_ERROR",
+		},
+		{
+			name:     "future target time",
+			setup:    readyOrders,
+			body:     map[string]string{"name": "orders-restored", "targetTime": time.Now().Add(time.Hour).UTC().Format(time.RFC3339)},
+			wantCode: http.StatusBadRequest,
+			wantErr:  "VALIDATION_ERROR",
+		},
+		{
+			name: "name taken",
+			setup: func(t *testing.T, f *operationFixture, sourceID string) {
+				readyOrders(t, f, sourceID)
+				f.create(t, "orders-restored")
+			},
+			body:     map[string]string{"name": "orders-restored"},
+			wantCode: http.StatusConflict,
+			wantErr:  "DUPLICATE_NAME",
+		},
+		{
+			name:     "other team",
+			setup:    readyOrders,
+			body:     map[string]string{"name": "orders-restored"},
+			identity: func(*operationFixture) *auth.Identity { return productIdentity("payments", uuid.New()) },
+			wantCode: http.StatusNotFound,
+			wantErr:  "NOT_FOUND",
+		},
+		{
+			name: "provider cannot restore",
+			setup: func(t *testing.T, f *operationFixture, sourceID string) {
+				readyOrders(t, f, sourceID)
+				f.registry.Register("cnpg", plainProvider{Provider: fake.NewProvider()})
+			},
+			body:     map[string]string{"name": "orders-restored"},
+			wantCode: http.StatusConflict,
+			wantErr:  "RESTORE_NOT_POSSIBLE",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := newOperationFixture(t)
+			sourceID, _ := f.create(t, "orders")
+			if tt.setup != nil {
+				tt.setup(t, f, sourceID)
+			}
+			identity := platformIdentity()
+			if tt.identity != nil {
+				identity = tt.identity(f)
+			}
+
+			w := f.restore(t, sourceID, tt.body, identity)
+			require.Equal(t, tt.wantCode, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantErr, parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+			assert.Empty(t, f.provider.RestoreCalls())
+		})
+	}
+}
+
+func readyOrders(t *testing.T, f *operationFixture, dbID string) {
+	t.Helper()
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+	f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+}
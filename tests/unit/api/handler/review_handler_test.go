@@ -0,0 +1,72 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+)
+
+func (f *operationFixture) getDatabase(t *testing.T, dbID string) map[string]interface{} {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.GetByID(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return parseEnvelope(t, w)["data"].(map[string]interface{})
+}
+
+func (f *operationFixture) review(t *testing.T, dbID string) (int, map[string]interface{}) {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/review", nil, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.Review(w, req)
+	return w.Code, parseEnvelope(t, w)
+}
+
+func TestReview_OperatorVersionChanged(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+	dbID, _ := f.create(t, "orders")
+	f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+	version := "1.24.1"
+	f.provider.OperatorVersionFn = func(context.Context, provider.ProviderDatabase) (string, error) {
+		return version, nil
+	}
+
+	code, env := f.review(t, dbID)
+	assert.Equal(t, http.StatusConflict, code)
+	assert.Equal(t, "REVIEW_NOT_NEEDED", env["error"].(map[string]interface{})["code"])
+
+	rec.RunOnce(context.Background())
+	data := f.getDatabase(t, dbID)
+	assert.Equal(t, "1.24.1", data["operatorVersion"])
+	assert.Nil(t, data["conditions"])
+
+	version = "1.25.0"
+	rec.RunOnce(context.Background())
+	data = f.getDatabase(t, dbID)
+	assert.Equal(t, "ready", data["status"])
+	conditions := data["conditions"].([]interface{})
+	require.Len(t, conditions, 1)
+	condition := conditions[0].(map[string]interface{})
+	assert.Equal(t, "needsReview", condition["type"])
+	assert.Equal(t, "OPERATOR_VERSION_CHANGED", condition["reason"])
+	assert.Equal(t, "Provisioned under cnpg operator 1.24.1, now running under 1.25.0", condition["message"])
+
+	code, env = f.review(t, dbID)
+	require.Equal(t, http.StatusOK, code, env)
+	data = env["data"].(map[string]interface{})
+	assert.Nil(t, data["conditions"])
+	assert.Nil(t, data["operatorVersion"])
+
+	rec.RunOnce(context.Background())
+	data = f.getDatabase(t, dbID)
+	assert.Equal(t, "1.25.0", data["operatorVersion"])
+	assert.Nil(t, data["conditions"])
+}
@@ -0,0 +1,141 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/revision"
+)
+
+type stubRevisions struct {
+	revisions []revision.Revision
+	err       error
+}
+
+func (s *stubRevisions) List(_ context.Context, kind string, id uuid.UUID) ([]revision.Revision, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	out := []revision.Revision{}
+	for _, rev := range s.revisions {
+		if rev.Kind == kind && rev.ResourceID == id {
+			out = append(out, rev)
+		}
+	}
+	return out, nil
+}
+
+func databaseRevisions(id, teamID uuid.UUID) *stubRevisions {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	return &stubRevisions{revisions: []revision.Revision{
+		{
+			Kind: revision.KindDatabase, ResourceID: id, Number: 1, Operation: revision.OperationCreate,
+			Snapshot:  map[string]any{"name": "orders", "owner_team_id": teamID.String(), "purpose": "orders", "updated_by": "alice"},
+			ChangedBy: "alice", ChangedAt: at,
+		},
+		{
+			Kind: revision.KindDatabase, ResourceID: id, Number: 2, Operation: revision.OperationUpdate,
+			Snapshot:  map[string]any{"name": "orders", "owner_team_id": teamID.String(), "purpose": "billing", "updated_by": "bob"},
+			ChangedBy: "bob", ChangedAt: at.Add(time.Hour),
+		},
+	}}
+}
+
+func TestRevisions_Database(t *testing.T) {
+	t.Parallel()
+	id, teamID := uuid.New(), uuid.New()
+	h := handler.NewRevisionHandler(databaseRevisions(id, teamID))
+
+	for _, identity := range []string{"platform", "product"} {
+		t.Run(identity, func(t *testing.T) {
+			caller := platformIdentity()
+			if identity == "product" {
+				caller = productIdentity("orders-team", teamID)
+			}
+			req, w := makeAuthRequest(http.MethodGet, "/databases/"+id.String()+"/revisions", nil, map[string]string{"id": id.String()}, caller)
+
+			h.Database(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			data := parseEnvelope(t, w)["data"].([]interface{})
+			require.Len(t, data, 2)
+
+			first := data[0].(map[string]interface{})
+			assert.Equal(t, float64(1), first["revision"])
+			assert.Equal(t, "create", first["operation"])
+			assert.Len(t, first["changes"], 4, "the first revision changes every field")
+
+			second := data[1].(map[string]interface{})
+			assert.Equal(t, "update", second["operation"])
+			assert.Equal(t, "bob", second["changedBy"])
+			assert.Equal(t, "2026-03-01T13:00:00Z", second["changedAt"])
+			assert.Equal(t, teamID.String(), second["snapshot"].(map[string]interface{})["ownerTeamId"])
+			assert.Equal(t, []interface{}{
+				map[string]interface{}{"field": "purpose", "from": "orders", "to": "billing"},
+				map[string]interface{}{"field": "updatedBy", "from": "alice", "to": "bob"},
+			}, second["changes"])
+		})
+	}
+}
+
+func TestRevisions_Errors(t *testing.T) {
+	t.Parallel()
+	id, teamID := uuid.New(), uuid.New()
+
+	tests := []struct {
+		name      string
+		id        string
+		revisions *stubRevisions
+		caller    string
+		wantCode  int
+		wantErr   string
+	}{
+		{"invalid id", "not-a-uuid", &stubRevisions{}, "platform", http.StatusBadRequest, "INVALID_ID"},
+		{"unknown database", uuid.New().String(), databaseRevisions(id, teamID), "platform", http.StatusNotFound, "NOT_FOUND"},
+		{"other team", id.String(), databaseRevisions(id, teamID), "product", http.StatusNotFound, "NOT_FOUND"},
+		{"list failure", id.String(), &stubRevisions{err: errors.New("boom")}, "platform", http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			caller := platformIdentity()
+			if tt.caller == "product" {
+				caller = productIdentity("other", uuid.New())
+			}
+			h := handler.NewRevisionHandler(tt.revisions)
+			req, w := makeAuthRequest(http.MethodGet, "/databases/"+tt.id+"/revisions", nil, map[string]string{"id": tt.id}, caller)
+
+			h.Database(w, req)
+
+			require.Equal(t, tt.wantCode, w.Code)
+			assert.Equal(t, tt.wantErr, parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+		})
+	}
+}
+
+func TestRevisions_DeletedTier(t *testing.T) {
+	t.Parallel()
+	id := uuid.New()
+	revisions := &stubRevisions{revisions: []revision.Revision{
+		{Kind: revision.KindTier, ResourceID: id, Number: 1, Operation: revision.OperationBaseline, Snapshot: map[string]any{"name": "standard"}},
+		{Kind: revision.KindTier, ResourceID: id, Number: 2, Operation: revision.OperationDelete, Snapshot: map[string]any{"name": "standard"}},
+	}}
+	h := handler.NewRevisionHandler(revisions)
+	req, w := makeAuthRequest(http.MethodGet, "/tiers/"+id.String()+"/revisions", nil, map[string]string{"id": id.String()}, platformIdentity())
+
+	h.Tier(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].([]interface{})
+	require.Len(t, data, 2)
+	last := data[1].(map[string]interface{})
+	assert.Equal(t, "delete", last["operation"])
+	assert.Equal(t, []interface{}{}, last["changes"])
+}
@@ -0,0 +1,226 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type rolloutFixture struct {
+	repos      *fake.Repositories
+	controller *rollout.Controller
+	tier       *tier.Tier
+	v1, v2     *blueprint.Blueprint
+}
+
+// newRolloutFixture seeds a tier on blueprint v1 with one database.
+func newRolloutFixture(t *testing.T) *rolloutFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &rolloutFixture{repos: repos}
+
+	f.v1 = &blueprint.Blueprint{Name: "cnpg-v1", Provider: "cnpg", Manifests: "kind: Cluster # v1"}
+	require.NoError(t, repos.Blueprints.Create(ctx, f.v1))
+	f.v2 = &blueprint.Blueprint{Name: "cnpg-v2", Provider: "cnpg", Manifests: "kind: Cluster # v2"}
+	require.NoError(t, repos.Blueprints.Create(ctx, f.v2))
+	f.tier = &tier.Tier{Name: "standard", BlueprintID: &f.v1.ID}
+	require.NoError(t, repos.Tiers.Create(ctx, f.tier))
+
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	db := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &f.tier.ID, Namespace: "db"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", fake.NewProvider())
+	f.controller = rollout.New(repos.Rollouts, repos.Databases, repos.Tiers, repos.Blueprints, registry, time.Minute)
+	return f
+}
+
+func (f *rolloutFixture) handler() *handler.RolloutHandler {
+	return handler.NewRolloutHandler(f.repos.Rollouts, f.repos.Tiers, f.controller)
+}
+
+func (f *rolloutFixture) patchBlueprint(t *testing.T, bp *blueprint.Blueprint) *httptest.ResponseRecorder {
+	t.Helper()
+	h := handler.NewTierHandler(f.repos.Tiers, f.repos.Blueprints, f.controller)
+	body, _ := json.Marshal(map[string]interface{}{"blueprintId": bp.ID})
+	req, w := makeChiRequest(http.MethodPatch, "/tiers/"+f.tier.ID.String(), body, "/tiers/{id}", map[string]string{"id": f.tier.ID.String()})
+	h.Update(w, req)
+	return w
+}
+
+func TestTierUpdate_BlueprintChangeStartsRollout(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+
+	w := f.patchBlueprint(t, f.v2)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	rollouts, err := f.repos.Rollouts.List(context.Background(), rollout.ListFilter{})
+	require.NoError(t, err)
+	require.Len(t, rollouts, 1)
+	assert.Equal(t, "/rollouts/"+rollouts[0].ID.String(), w.Header().Get("Location"))
+	assert.Equal(t, "cnpg-v1", rollouts[0].FromBlueprintName)
+	assert.Equal(t, "cnpg-v2", rollouts[0].ToBlueprintName)
+}
+
+func TestTierUpdate_RolloutInProgress(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+	require.Equal(t, http.StatusOK, f.patchBlueprint(t, f.v2).Code)
+
+	w := f.patchBlueprint(t, f.v1)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	env := parseEnvelope(t, w)
+	errObj := env["error"].(map[string]interface{})
+	assert.Equal(t, "ROLLOUT_IN_PROGRESS", errObj["code"])
+
+	tr, err := f.repos.Tiers.GetByID(context.Background(), f.tier.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.v2.ID, *tr.BlueprintID)
+}
+
+func TestTierUpdate_SameBlueprintNoRollout(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+
+	w := f.patchBlueprint(t, f.v1)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Location"))
+	rollouts, err := f.repos.Rollouts.List(context.Background(), rollout.ListFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, rollouts)
+}
+
+func TestRolloutList_FilterByTier(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+	require.Equal(t, http.StatusOK, f.patchBlueprint(t, f.v2).Code)
+	h := f.handler()
+
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{"", 1},
+		{"?tier=standard", 1},
+		{"?tier=unknown", 0},
+		{"?status=in_progress", 1},
+		{"?status=completed", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req, w := makeAuthRequest(http.MethodGet, "/rollouts"+tt.query, nil, nil, platformIdentity())
+			h.List(w, req)
+
+			require.Equal(t, http.StatusOK, w.Code)
+			data := parseEnvelope(t, w)["data"].([]interface{})
+			assert.Len(t, data, tt.want)
+		})
+	}
+}
+
+func TestRolloutGetByID(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+	w := f.patchBlueprint(t, f.v2)
+	require.Equal(t, http.StatusOK, w.Code)
+	id := w.Header().Get("Location")[len("/rollouts/"):]
+
+	req, w := makeAuthRequest(http.MethodGet, "/rollouts/"+id, nil, map[string]string{"id": id}, platformIdentity())
+	f.handler().GetByID(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, id, data["id"])
+	assert.Equal(t, "standard", data["tier"])
+	assert.Equal(t, "in_progress", data["status"])
+	assert.Equal(t, float64(1), data["batchCount"])
+	assert.Nil(t, data["error"])
+	targets := data["targets"].([]interface{})
+	require.Len(t, targets, 1)
+	target := targets[0].(map[string]interface{})
+	assert.Equal(t, "orders", target["database"])
+	assert.Equal(t, "pending", target["status"])
+	assert.Nil(t, target["appliedAt"])
+}
+
+func TestRolloutGetByID_NotFound(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+	id := uuid.New().String()
+
+	req, w := makeAuthRequest(http.MethodGet, "/rollouts/"+id, nil, map[string]string{"id": id}, platformIdentity())
+	f.handler().GetByID(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestRolloutGetByID_InvalidUUID(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+
+	req, w := makeAuthRequest(http.MethodGet, "/rollouts/nope", nil, map[string]string{"id": "nope"}, platformIdentity())
+	f.handler().GetByID(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestRolloutTransitions(t *testing.T) {
+	t.Parallel()
+	f := newRolloutFixture(t)
+	w := f.patchBlueprint(t, f.v2)
+	require.Equal(t, http.StatusOK, w.Code)
+	id := w.Header().Get("Location")[len("/rollouts/"):]
+	h := f.handler()
+
+	steps := []struct {
+		name       string
+		action     http.HandlerFunc
+		wantCode   int
+		wantStatus string
+	}{
+		{"resume in progress", h.Resume, http.StatusConflict, ""},
+		{"pause", h.Pause, http.StatusOK, "paused"},
+		{"pause again", h.Pause, http.StatusConflict, ""},
+		{"resume", h.Resume, http.StatusOK, "in_progress"},
+		{"rollback", h.Rollback, http.StatusOK, "rolling_back"},
+		{"pause rolling back", h.Pause, http.StatusConflict, ""},
+	}
+	for _, step := range steps {
+		req, w := makeAuthRequest(http.MethodPost, "/rollouts/"+id, nil, map[string]string{"id": id}, platformIdentity())
+		step.action(w, req)
+
+		require.Equal(t, step.wantCode, w.Code, step.name)
+		env := parseEnvelope(t, w)
+		if step.wantStatus != "" {
+			assert.Equal(t, step.wantStatus, env["data"].(map[string]interface{})["status"], step.name)
+		} else {
+			assert.Equal(t, "INVALID_TRANSITION", env["error"].(map[string]interface{})["code"], step.name)
+		}
+	}
+
+	tr, err := f.repos.Tiers.GetByID(context.Background(), f.tier.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.v1.ID, *tr.BlueprintID)
+}
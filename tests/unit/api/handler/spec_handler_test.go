@@ -0,0 +1,128 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/tier"
+)
+
+// newSpecFixture is an operationFixture whose database handler records specs.
+func newSpecFixture(t *testing.T) (*operationFixture, *handler.SpecHandler) {
+	t.Helper()
+	f := newOperationFixture(t)
+	r := f.repos
+	f.dbs = handler.NewDatabaseHandler(r.Databases, r.Teams, r.Tiers, r.Blueprints, f.registry, "default", nil, nil, nil, nil, f.ops, 0, r.Specs, nil, nil, nil, nil, "", nil, nil, nil, nil)
+	return f, handler.NewSpecHandler(r.Databases, r.Tiers, r.Blueprints, r.Specs)
+}
+
+func specDiff(t *testing.T, h *handler.SpecHandler, dbID string, identity *auth.Identity) map[string]interface{} {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+dbID+"/spec-diff", nil, map[string]string{"id": dbID}, identity)
+	h.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	return parseEnvelope(t, w)["data"].(map[string]interface{})
+}
+
+// changeStandardTier turns backups on for the fixture's tier and points it at
+// a blueprint with an extra manifest line.
+func changeStandardTier(t *testing.T, f *operationFixture) {
+	t.Helper()
+	ctx := context.Background()
+	bp := &blueprint.Blueprint{Name: "cnpg-ha", Provider: "cnpg", Manifests: "kind: Cluster\nspec:\n  instances: 3"}
+	require.NoError(t, f.repos.Blueprints.Create(ctx, bp))
+	standard, err := f.repos.Tiers.GetByName(ctx, "standard")
+	require.NoError(t, err)
+	backup := true
+	_, err = f.repos.Tiers.Update(ctx, standard.ID, tier.UpdateFields{BlueprintID: &bp.ID, BackupEnabled: &backup})
+	require.NoError(t, err)
+}
+
+func TestSpecDiff_RecordedAtCreate(t *testing.T) {
+	t.Parallel()
+	f, h := newSpecFixture(t)
+	dbID, _ := f.create(t, "orders")
+
+	spec, err := f.repos.Specs.Get(context.Background(), uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "standard", spec.TierName)
+	assert.Equal(t, "cnpg-standard", spec.BlueprintName)
+	assert.Equal(t, "kind: Cluster", spec.Manifests)
+
+	data := specDiff(t, h, dbID, platformIdentity())
+	assert.Equal(t, false, data["pending"])
+	assert.Empty(t, data["changes"])
+	assert.Equal(t, data["applied"].(map[string]interface{})["tier"], data["current"].(map[string]interface{})["tier"])
+	assert.Nil(t, data["manifestsDiff"])
+}
+
+func TestSpecDiff_PendingChanges(t *testing.T) {
+	t.Parallel()
+	f, h := newSpecFixture(t)
+	dbID, _ := f.create(t, "orders")
+	changeStandardTier(t, f)
+
+	data := specDiff(t, h, dbID, platformIdentity())
+	assert.Equal(t, true, data["pending"])
+	raw, err := json.Marshal(data["changes"])
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"field": "tier.backupEnabled", "applied": false, "current": true},
+		{"field": "blueprint.name", "applied": "cnpg-standard", "current": "cnpg-ha"},
+		{"field": "blueprint.manifests"}
+	]`, string(raw))
+	assert.Equal(t, "--- applied\n+++ current\n kind: Cluster\n+spec:\n+  instances: 3\n", data["manifestsDiff"])
+	assert.Equal(t, "cnpg-standard", data["applied"].(map[string]interface{})["blueprint"].(map[string]interface{})["name"])
+	assert.Equal(t, "cnpg-ha", data["current"].(map[string]interface{})["blueprint"].(map[string]interface{})["name"])
+}
+
+func TestSpecDiff_RedactedForProductUsers(t *testing.T) {
+	t.Parallel()
+	f, h := newSpecFixture(t)
+	dbID, _ := f.create(t, "orders")
+	changeStandardTier(t, f)
+
+	data := specDiff(t, h, dbID, productIdentity(f.team.Name, f.team.ID))
+	assert.Equal(t, true, data["pending"])
+	changes := data["changes"].([]interface{})
+	require.Len(t, changes, 3)
+	assert.Equal(t, map[string]interface{}{"field": "tier.backupEnabled"}, changes[0])
+	assert.Nil(t, data["manifestsDiff"])
+	applied := data["applied"].(map[string]interface{})
+	assert.NotContains(t, applied["tier"], "backupEnabled")
+	assert.NotContains(t, applied["blueprint"], "manifests")
+}
+
+func TestSpecDiff_NotRecorded(t *testing.T) {
+	t.Parallel()
+	f := newOperationFixture(t)
+	h := handler.NewSpecHandler(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.repos.Specs)
+	dbID, _ := f.create(t, "orders")
+
+	data := specDiff(t, h, dbID, platformIdentity())
+	assert.Nil(t, data["applied"])
+	assert.NotNil(t, data["current"])
+	assert.Equal(t, false, data["pending"])
+	_, err := f.repos.Specs.Get(context.Background(), uuid.MustParse(dbID))
+	assert.ErrorIs(t, err, database.ErrSpecNotFound)
+}
+
+func TestSpecDiff_OtherTeam(t *testing.T) {
+	t.Parallel()
+	f, h := newSpecFixture(t)
+	dbID, _ := f.create(t, "orders")
+
+	req, w := makeAuthRequest(http.MethodGet, "/databases/"+dbID+"/spec-diff", nil, map[string]string{"id": dbID}, productIdentity("payments", uuid.New()))
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,141 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+)
+
+type mockStatsReader struct {
+	statsFn     func(ctx context.Context, filter database.StatsFilter) (*database.Stats, error)
+	durationsFn func(ctx context.Context, filter database.ProvisioningDurationFilter) (*database.ProvisioningDurationResult, error)
+}
+
+func (m *mockStatsReader) Stats(ctx context.Context, filter database.StatsFilter) (*database.Stats, error) {
+	return m.statsFn(ctx, filter)
+}
+
+func (m *mockStatsReader) ProvisioningDurations(ctx context.Context, filter database.ProvisioningDurationFilter) (*database.ProvisioningDurationResult, error) {
+	return m.durationsFn(ctx, filter)
+}
+
+func (m *mockStatsReader) StatusHistory(_ context.Context, _ uuid.UUID) ([]database.StatusChange, error) {
+	return nil, nil
+}
+
+func TestStats_PlatformUser(t *testing.T) {
+	t.Parallel()
+	reader := &mockStatsReader{
+		statsFn: func(_ context.Context, filter database.StatsFilter) (*database.Stats, error) {
+			assert.Nil(t, filter.OwnerTeamID)
+			return &database.Stats{
+				Total:        3,
+				ByStatus:     map[string]int{"ready": 2, "provisioning": 1},
+				ByTier:       map[string]int{"standard": 3},
+				ByTeam:       map[string]int{"backend": 3},
+				Provisioning: database.DurationStats{Count: 2, P50: 90 * time.Second, P95: 1500 * time.Millisecond},
+			}, nil
+		},
+	}
+	h := handler.NewStatsHandler(reader, 0)
+	req, w := makeAuthRequest(http.MethodGet, "/stats", nil, nil, platformIdentity())
+
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, float64(3), data["total"])
+	assert.Equal(t, map[string]interface{}{"ready": float64(2), "provisioning": float64(1)}, data["byStatus"])
+	durations := data["provisioningDuration"].(map[string]interface{})
+	assert.Equal(t, float64(2), durations["count"])
+	assert.Equal(t, 90.0, durations["p50Seconds"])
+	assert.Equal(t, 1.5, durations["p95Seconds"])
+}
+
+func TestStats_ProductUserScopedToTeam(t *testing.T) {
+	t.Parallel()
+	teamID := uuid.New()
+	reader := &mockStatsReader{
+		statsFn: func(_ context.Context, filter database.StatsFilter) (*database.Stats, error) {
+			require.NotNil(t, filter.OwnerTeamID)
+			assert.Equal(t, teamID, *filter.OwnerTeamID)
+			return &database.Stats{ByStatus: map[string]int{}, ByTier: map[string]int{}, ByTeam: map[string]int{}}, nil
+		},
+	}
+	h := handler.NewStatsHandler(reader, 0)
+	req, w := makeAuthRequest(http.MethodGet, "/stats", nil, nil, productIdentity("backend", teamID))
+
+	h.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	durations := parseEnvelope(t, w)["data"].(map[string]interface{})["provisioningDuration"].(map[string]interface{})
+	assert.Equal(t, float64(0), durations["count"])
+	assert.Nil(t, durations["p50Seconds"])
+}
+
+func TestStats_Error(t *testing.T) {
+	t.Parallel()
+	reader := &mockStatsReader{
+		statsFn: func(_ context.Context, _ database.StatsFilter) (*database.Stats, error) {
+			return nil, errors.New("boom")
+		},
+	}
+	h := handler.NewStatsHandler(reader, 0)
+	req, w := makeAuthRequest(http.MethodGet, "/stats", nil, nil, platformIdentity())
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestProvisioningDurations_FlagsSLOBreaches(t *testing.T) {
+	t.Parallel()
+	created := time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC)
+	reader := &mockStatsReader{
+		durationsFn: func(_ context.Context, filter database.ProvisioningDurationFilter) (*database.ProvisioningDurationResult, error) {
+			assert.Equal(t, 2, filter.Page)
+			assert.Equal(t, 5, filter.Limit)
+			return &database.ProvisioningDurationResult{
+				Items: []database.ProvisioningDuration{
+					{DatabaseID: uuid.New(), Name: "slow", OwnerTeamName: "backend", CreatedAt: created, ReadyAt: created.Add(20 * time.Minute), Duration: 20 * time.Minute},
+					{DatabaseID: uuid.New(), Name: "fast", OwnerTeamName: "backend", CreatedAt: created, ReadyAt: created.Add(2 * time.Minute), Duration: 2 * time.Minute},
+				},
+				Total: 7, Page: 2, Limit: 5,
+			}, nil
+		},
+	}
+	h := handler.NewStatsHandler(reader, 15*time.Minute)
+	req, w := makeAuthRequest(http.MethodGet, "/stats/provisioning-durations?page=2&limit=5", nil, nil, platformIdentity())
+
+	h.ProvisioningDurations(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	env := parseEnvelope(t, w)
+	items := env["data"].([]interface{})
+	require.Len(t, items, 2)
+	slow := items[0].(map[string]interface{})
+	assert.Equal(t, "slow", slow["name"])
+	assert.Equal(t, 1200.0, slow["durationSeconds"])
+	assert.Equal(t, true, slow["exceededSlo"])
+	assert.Equal(t, false, items[1].(map[string]interface{})["exceededSlo"])
+	assert.Equal(t, float64(7), env["meta"].(map[string]interface{})["total"])
+}
+
+func TestProvisioningDurations_InvalidLimit(t *testing.T) {
+	t.Parallel()
+	h := handler.NewStatsHandler(&mockStatsReader{}, 0)
+	req, w := makeAuthRequest(http.MethodGet, "/stats/provisioning-durations?limit=0", nil, nil, platformIdentity())
+
+	h.ProvisioningDurations(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -0,0 +1,66 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// stubBundler writes the name of the database it was asked about.
+type stubBundler struct {
+	err error
+}
+
+func (s *stubBundler) Write(_ context.Context, w io.Writer, db *database.Database) error {
+	if s.err != nil {
+		return s.err
+	}
+	_, err := io.WriteString(w, "bundle of "+db.Name)
+	return err
+}
+
+func TestSupportBundle(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	db := &database.Database{Name: "orders", OwnerTeamID: tm.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+
+	get := func(h *handler.SupportBundleHandler, id string) *http.Response {
+		req, w := makeAuthRequest(http.MethodGet, "/databases/"+id+"/support-bundle", nil, map[string]string{"id": id}, platformIdentity())
+		h.ServeHTTP(w, req)
+		return w.Result()
+	}
+
+	h := handler.NewSupportBundleHandler(repos.Databases, &stubBundler{})
+	res := get(h, db.ID.String())
+	require.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "application/gzip", res.Header.Get("Content-Type"))
+	assert.Regexp(t, regexp.MustCompile(`^attachment; filename="daap-support-orders-\d{8}T\d{6}Z\.tar\.gz"$`), res.Header.Get("Content-Disposition"))
+	body, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "bundle of orders", string(body))
+	assert.Equal(t, "16", res.Header.Get("Content-Length"))
+
+	assert.Equal(t, http.StatusBadRequest, get(h, "not-a-uuid").StatusCode)
+	assert.Equal(t, http.StatusNotFound, get(h, uuid.NewString()).StatusCode)
+
+	failing := handler.NewSupportBundleHandler(repos.Databases, &stubBundler{err: errors.New("disk full")})
+	res = get(failing, db.ID.String())
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+}
@@ -22,6 +22,7 @@ type mockTeamRepo struct {
 	getByIDFn   func(ctx context.Context, id uuid.UUID) (*team.Team, error)
 	getByNameFn func(ctx context.Context, name string) (*team.Team, error)
 	listFn      func(ctx context.Context) ([]team.Team, error)
+	updateFn    func(ctx context.Context, id uuid.UUID, fields team.UpdateFields) (*team.Team, error)
 	deleteFn    func(ctx context.Context, id uuid.UUID) error
 }
 
@@ -56,6 +57,13 @@ func (m *mockTeamRepo) List(ctx context.Context) ([]team.Team, error) {
 	return []team.Team{}, nil
 }
 
+func (m *mockTeamRepo) Update(ctx context.Context, id uuid.UUID, fields team.UpdateFields) (*team.Team, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, id, fields)
+	}
+	return nil, team.ErrTeamNotFound
+}
+
 func (m *mockTeamRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	if m.deleteFn != nil {
 		return m.deleteFn(ctx, id)
@@ -66,7 +74,7 @@ func (m *mockTeamRepo) Delete(ctx context.Context, id uuid.UUID) error {
 // --- Helpers ---
 
 func newTeamHandler(repo team.Repository) *handler.TeamHandler {
-	return handler.NewTeamHandler(repo)
+	return handler.NewTeamHandler(repo, nil)
 }
 
 func sampleTeam(id uuid.UUID) *team.Team {
@@ -266,6 +274,33 @@ func TestTeamList_NonEmpty(t *testing.T) {
 
 // ===== DELETE /teams/{id} =====
 
+func TestTeamGetByName(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	repo := &mockTeamRepo{
+		getByNameFn: func(_ context.Context, name string) (*team.Team, error) {
+			if name != "frontend" {
+				return nil, team.ErrTeamNotFound
+			}
+			return sampleTeam(id), nil
+		},
+	}
+	h := newTeamHandler(repo)
+
+	req, w := makeChiRequest(http.MethodGet, "/teams/by-name/frontend", nil, "/teams/by-name/{name}", map[string]string{"name": "frontend"})
+	h.GetByName(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, id.String(), parseEnvelope(t, w)["data"].(map[string]interface{})["id"])
+
+	req, w = makeChiRequest(http.MethodGet, "/teams/by-name/backend", nil, "/teams/by-name/{name}", map[string]string{"name": "backend"})
+	h.GetByName(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "NOT_FOUND", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+}
+
 func TestTeamDelete_Success(t *testing.T) {
 	t.Parallel()
 
@@ -345,3 +380,104 @@ func TestTeamDelete_InvalidID(t *testing.T) {
 	errObj := env["error"].(map[string]interface{})
 	assert.Equal(t, "INVALID_ID", errObj["code"])
 }
+
+// ===== PATCH /teams/{id} =====
+
+func TestTeamUpdate_ReplacesLabels(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	repo := &mockTeamRepo{
+		updateFn: func(_ context.Context, reqID uuid.UUID, fields team.UpdateFields) (*team.Team, error) {
+			assert.Equal(t, id, reqID)
+			assert.Equal(t, map[string]string{"cost-center": "cc-1234"}, fields.Labels)
+			assert.Nil(t, fields.Annotations, "annotations absent from the body are left unchanged")
+			tm := sampleTeam(id)
+			tm.Labels = fields.Labels
+			return tm, nil
+		},
+	}
+	h := newTeamHandler(repo)
+
+	body := []byte(`{"labels":{"cost-center":"cc-1234"}}`)
+	req, w := makeChiRequest(http.MethodPatch, "/teams/"+id.String(), body, "/teams/{id}", map[string]string{"id": id.String()})
+
+	h.Update(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"cost-center": "cc-1234"}, data["labels"])
+	assert.Equal(t, map[string]interface{}{}, data["annotations"])
+}
+
+func TestTeam_RecordsAttribution(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	var created *team.Team
+	var fields team.UpdateFields
+	repo := &mockTeamRepo{
+		createFn: func(_ context.Context, tm *team.Team) error {
+			tm.ID, tm.UpdatedBy = id, tm.CreatedBy
+			created = tm
+			return nil
+		},
+		updateFn: func(_ context.Context, _ uuid.UUID, f team.UpdateFields) (*team.Team, error) {
+			fields = f
+			tm := *created
+			tm.UpdatedBy = f.UpdatedBy
+			return &tm, nil
+		},
+	}
+	h := newTeamHandler(repo)
+
+	body := []byte(`{"name":"ops","role":"platform"}`)
+	req, w := makeAuthRequest(http.MethodPost, "/teams", body, nil, superuserIdentity())
+	h.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "admin", data["createdBy"])
+	assert.Equal(t, "admin", data["updatedBy"])
+
+	identity := superuserIdentity()
+	identity.UserName = "root2"
+	body = []byte(`{"labels":{"cost-center":"cc-1234"}}`)
+	req, w = makeAuthRequest(http.MethodPatch, "/teams/"+id.String(), body, map[string]string{"id": id.String()}, identity)
+	h.Update(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "root2", fields.UpdatedBy)
+	data = parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, "admin", data["createdBy"])
+	assert.Equal(t, "root2", data["updatedBy"])
+}
+
+func TestTeamUpdate_ReservedLabel(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	h := newTeamHandler(&mockTeamRepo{})
+
+	body := []byte(`{"labels":{"app.kubernetes.io/managed-by":"me"}}`)
+	req, w := makeChiRequest(http.MethodPatch, "/teams/"+id.String(), body, "/teams/{id}", map[string]string{"id": id.String()})
+
+	h.Update(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", errObj["code"])
+}
+
+func TestTeamUpdate_NotFound(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	h := newTeamHandler(&mockTeamRepo{})
+
+	req, w := makeChiRequest(http.MethodPatch, "/teams/"+id.String(), []byte(`{"labels":{}}`), "/teams/{id}", map[string]string{"id": id.String()})
+
+	h.Update(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
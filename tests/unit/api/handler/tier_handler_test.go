@@ -20,11 +20,11 @@ import (
 
 func newTierHandler(repo tier.Repository) *handler.TierHandler {
 	bpRepo := &mockBlueprintRepo{}
-	return handler.NewTierHandler(repo, bpRepo)
+	return handler.NewTierHandler(repo, bpRepo, nil)
 }
 
 func newTierHandlerWithBP(repo tier.Repository, bpRepo blueprint.Repository) *handler.TierHandler {
-	return handler.NewTierHandler(repo, bpRepo)
+	return handler.NewTierHandler(repo, bpRepo, nil)
 }
 
 func sampleTier(id uuid.UUID) *tier.Tier {
@@ -51,6 +51,9 @@ type mockBlueprintRepo struct {
 	createFn    func(ctx context.Context, bp *blueprint.Blueprint) error
 	listFn      func(ctx context.Context) ([]blueprint.Blueprint, error)
 	deleteFn    func(ctx context.Context, id uuid.UUID) error
+
+	updateFn       func(ctx context.Context, id uuid.UUID, fields blueprint.UpdateFields) (*blueprint.Blueprint, error)
+	listVersionsFn func(ctx context.Context, id uuid.UUID) ([]blueprint.Version, error)
 }
 
 func (m *mockBlueprintRepo) Create(ctx context.Context, bp *blueprint.Blueprint) error {
@@ -95,6 +98,20 @@ func (m *mockBlueprintRepo) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m *mockBlueprintRepo) Update(ctx context.Context, id uuid.UUID, fields blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+	if m.updateFn != nil {
+		return m.updateFn(ctx, id, fields)
+	}
+	return nil, blueprint.ErrBlueprintNotFound
+}
+
+func (m *mockBlueprintRepo) ListVersions(ctx context.Context, id uuid.UUID) ([]blueprint.Version, error) {
+	if m.listVersionsFn != nil {
+		return m.listVersionsFn(ctx, id)
+	}
+	return nil, blueprint.ErrBlueprintNotFound
+}
+
 // ===== POST /tiers =====
 
 func TestTierCreate_Success(t *testing.T) {
@@ -165,6 +182,43 @@ func TestTierCreate_WithBlueprint(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
+func TestTierCreate_WithNamespace(t *testing.T) {
+	t.Parallel()
+
+	bpRepo := &mockBlueprintRepo{
+		getByNameFn: func(_ context.Context, name string) (*blueprint.Blueprint, error) {
+			return &blueprint.Blueprint{ID: uuid.New(), Name: name, Provider: "cnpg"}, nil
+		},
+	}
+	var created *tier.Tier
+	repo := &mockTierRepo{
+		createFn: func(_ context.Context, t *tier.Tier) error {
+			created = t
+			t.ID = uuid.New()
+			return nil
+		},
+	}
+	h := newTierHandlerWithBP(repo, bpRepo)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":                "prod",
+		"blueprintName":       "cnpg-standard",
+		"destructionStrategy": "freeze",
+		"namespace":           " db-{{ .Team }} ",
+	})
+
+	req, w := makeChiRequest(http.MethodPost, "/tiers", body, "/tiers", nil)
+	h.Create(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, created)
+	assert.Equal(t, "db-{{ .Team }}", created.Namespace)
+
+	env := parseEnvelope(t, w)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, "db-{{ .Team }}", data["namespace"])
+}
+
 func TestTierCreate_BlueprintNotFound(t *testing.T) {
 	t.Parallel()
 
@@ -390,6 +444,33 @@ func TestTierGetByID_NotFound(t *testing.T) {
 	assert.Equal(t, "NOT_FOUND", errObj["code"])
 }
 
+func TestTierGetByName(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	repo := &mockTierRepo{
+		getByNameFn: func(_ context.Context, name string) (*tier.Tier, error) {
+			if name != "standard" {
+				return nil, tier.ErrTierNotFound
+			}
+			return sampleTier(id), nil
+		},
+	}
+	h := newTierHandler(repo)
+
+	req, w := makeChiRequest(http.MethodGet, "/tiers/by-name/standard", nil, "/tiers/by-name/{name}", map[string]string{"name": "standard"})
+	h.GetByName(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, id.String(), parseEnvelope(t, w)["data"].(map[string]interface{})["id"])
+
+	req, w = makeChiRequest(http.MethodGet, "/tiers/by-name/large", nil, "/tiers/by-name/{name}", map[string]string{"name": "large"})
+	h.GetByName(w, req)
+
+	require.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "NOT_FOUND", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+}
+
 func TestTierGetByID_InvalidUUID(t *testing.T) {
 	t.Parallel()
 
@@ -466,6 +547,98 @@ func TestTierUpdate_Success(t *testing.T) {
 	assert.Equal(t, id.String(), data["id"])
 }
 
+func TestTierCreate_StorageAutoscalingDefaults(t *testing.T) {
+	t.Parallel()
+
+	var created tier.Tier
+	repo := &mockTierRepo{
+		createFn: func(_ context.Context, t *tier.Tier) error {
+			t.ID = uuid.New()
+			created = *t
+			return nil
+		},
+	}
+	h := newTierHandler(repo)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":                "standard",
+		"blueprintName":       "cnpg-standard",
+		"destructionStrategy": "hard_delete",
+		"storageAutoscaling":  map[string]interface{}{"enabled": true, "maxSize": "500Gi"},
+	})
+
+	req, w := makeChiRequest(http.MethodPost, "/tiers", body, "/tiers", nil)
+	h.Create(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, tier.StorageAutoscaling{
+		Enabled:          true,
+		ThresholdPercent: tier.DefaultStorageThresholdPercent,
+		IncrementPercent: tier.DefaultStorageIncrementPercent,
+		MaxSize:          "500Gi",
+	}, created.StorageAutoscaling)
+
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"enabled":          true,
+		"thresholdPercent": float64(80),
+		"incrementPercent": float64(20),
+		"maxSize":          "500Gi",
+	}, data["storageAutoscaling"])
+}
+
+func TestTierCreate_StorageAutoscalingValidation(t *testing.T) {
+	t.Parallel()
+
+	h := newTierHandler(&mockTierRepo{})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":                "standard",
+		"blueprintName":       "cnpg-standard",
+		"destructionStrategy": "hard_delete",
+		"storageAutoscaling":  map[string]interface{}{"enabled": true, "thresholdPercent": 100},
+	})
+
+	req, w := makeChiRequest(http.MethodPost, "/tiers", body, "/tiers", nil)
+	h.Create(w, req)
+
+	require.Equal(t, http.StatusBadRequest, w.Code)
+	errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+	assert.Equal(t, "VALIDATION_ERROR", errObj["code"])
+	details := errObj["details"].([]interface{})
+	assert.Equal(t, "storageAutoscaling.thresholdPercent", details[0].(map[string]interface{})["field"])
+}
+
+func TestTierUpdate_StorageAutoscaling(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	repo := &mockTierRepo{
+		updateFn: func(_ context.Context, _ uuid.UUID, fields tier.UpdateFields) (*tier.Tier, error) {
+			require.NotNil(t, fields.StorageAutoscaling)
+			t2 := sampleTier(id)
+			t2.StorageAutoscaling = *fields.StorageAutoscaling
+			return t2, nil
+		},
+	}
+	h := newTierHandler(repo)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"storageAutoscaling": map[string]interface{}{"enabled": true, "thresholdPercent": 90, "incrementPercent": 50},
+	})
+
+	req, w := makeChiRequest(http.MethodPatch, "/tiers/"+id.String(), body, "/tiers/{id}", map[string]string{"id": id.String()})
+	h.Update(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"enabled":          true,
+		"thresholdPercent": float64(90),
+		"incrementPercent": float64(50),
+	}, data["storageAutoscaling"])
+}
+
 func TestTierUpdate_ImmutableName(t *testing.T) {
 	t.Parallel()
 
@@ -626,3 +799,110 @@ func TestTierDelete_InvalidUUID(t *testing.T) {
 	errObj := env["error"].(map[string]interface{})
 	assert.Equal(t, "INVALID_ID", errObj["code"])
 }
+
+// ===== POST /tiers/{id}/clone =====
+
+func TestTierClone_CopiesAndOverrides(t *testing.T) {
+	t.Parallel()
+
+	id := uuid.New()
+	var created *tier.Tier
+	repo := &mockTierRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*tier.Tier, error) {
+			src := sampleTier(id)
+			src.BackupEnabled = true
+			src.Namespace = "db-{{ .Team }}"
+			src.StorageAutoscaling = tier.StorageAutoscaling{Enabled: true, ThresholdPercent: 90, IncrementPercent: 10, MaxSize: "200Gi"}
+			src.DataClassifications = []string{"confidential"}
+			return src, nil
+		},
+		createFn: func(_ context.Context, t *tier.Tier) error {
+			created = t
+			t.ID = uuid.New()
+			return nil
+		},
+	}
+	var blueprintName string
+	h := newTierHandlerWithBP(repo, &mockBlueprintRepo{
+		getByNameFn: func(_ context.Context, name string) (*blueprint.Blueprint, error) {
+			blueprintName = name
+			return &blueprint.Blueprint{ID: uuid.New(), Name: name, Provider: "cnpg"}, nil
+		},
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":                "standard-prod",
+		"destructionStrategy": "archive",
+	})
+	req, w := makeChiRequest(http.MethodPost, "/tiers/"+id.String()+"/clone", body, "/tiers/{id}/clone", map[string]string{"id": id.String()})
+	h.Clone(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, created)
+	assert.Equal(t, "standard-prod", created.Name)
+	assert.Equal(t, "archive", created.DestructionStrategy)
+	assert.Equal(t, "Standard tier", created.Description)
+	assert.Equal(t, "cnpg-standard", blueprintName)
+	assert.True(t, created.BackupEnabled)
+	assert.Equal(t, "db-{{ .Team }}", created.Namespace)
+	assert.Equal(t, tier.StorageAutoscaling{Enabled: true, ThresholdPercent: 90, IncrementPercent: 10, MaxSize: "200Gi"}, created.StorageAutoscaling)
+	assert.Equal(t, []string{"confidential"}, created.DataClassifications)
+}
+
+func TestTierClone_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       map[string]interface{}
+		createErr  error
+		wantStatus int
+		wantCode   string
+	}{
+		{"missing name", map[string]interface{}{}, nil, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"invalid override", map[string]interface{}{"name": "copy", "destructionStrategy": "shred"}, nil, http.StatusBadRequest, "VALIDATION_ERROR"},
+		{"duplicate name", map[string]interface{}{"name": "standard"}, tier.ErrDuplicateTierName, http.StatusConflict, "DUPLICATE_NAME"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			repo := &mockTierRepo{
+				getByIDFn: func(_ context.Context, _ uuid.UUID) (*tier.Tier, error) {
+					src := sampleTier(id)
+					src.StorageAutoscaling = tier.StorageAutoscaling{ThresholdPercent: 80, IncrementPercent: 20}
+					return src, nil
+				},
+				createFn: func(_ context.Context, _ *tier.Tier) error {
+					return tt.createErr
+				},
+			}
+			h := newTierHandler(repo)
+
+			body, _ := json.Marshal(tt.body)
+			req, w := makeChiRequest(http.MethodPost, "/tiers/"+id.String()+"/clone", body, "/tiers/{id}/clone", map[string]string{"id": id.String()})
+			h.Clone(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			errObj := parseEnvelope(t, w)["error"].(map[string]interface{})
+			assert.Equal(t, tt.wantCode, errObj["code"])
+		})
+	}
+}
+
+func TestTierClone_SourceNotFound(t *testing.T) {
+	t.Parallel()
+
+	repo := &mockTierRepo{
+		getByIDFn: func(_ context.Context, _ uuid.UUID) (*tier.Tier, error) {
+			return nil, tier.ErrTierNotFound
+		},
+	}
+	h := newTierHandler(repo)
+
+	id := uuid.New()
+	body, _ := json.Marshal(map[string]interface{}{"name": "copy"})
+	req, w := makeChiRequest(http.MethodPost, "/tiers/"+id.String()+"/clone", body, "/tiers/{id}/clone", map[string]string{"id": id.String()})
+	h.Clone(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,145 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// freezing gives the fixture's standard tier the freeze destruction strategy.
+func (f *operationFixture) freezing(t *testing.T) {
+	t.Helper()
+	ctx := context.Background()
+	standard, err := f.repos.Tiers.GetByName(ctx, "standard")
+	require.NoError(t, err)
+	strategy := tier.DestructionFreeze
+	_, err = f.repos.Tiers.Update(ctx, standard.ID, tier.UpdateFields{DestructionStrategy: &strategy})
+	require.NoError(t, err)
+}
+
+func (f *operationFixture) unfreeze(t *testing.T, dbID string, identity *auth.Identity) *httptest.ResponseRecorder {
+	t.Helper()
+	req, w := makeAuthRequest(http.MethodPost, "/databases/"+dbID+"/unfreeze", nil, map[string]string{"id": dbID}, identity)
+	f.dbs.Unfreeze(w, req)
+	return w
+}
+
+func TestDelete_FreezesUntilUnfrozen(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	f := newOperationFixture(t)
+	f.freezing(t)
+	rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute,
+		reconciler.WithOperations(f.ops))
+	dbID, _ := f.create(t, "orders")
+	f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+
+	req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, productIdentity(f.team.Name, f.team.ID))
+	f.dbs.Delete(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "frozen", parseEnvelope(t, w)["data"].(map[string]interface{})["status"])
+	require.Len(t, f.provider.FreezeCalls(), 1)
+	assert.Empty(t, f.provider.DeleteCalls(), "the resources are kept")
+
+	// The reconciler leaves a frozen database alone.
+	rec.RunOnce(ctx)
+	db, err := f.repos.Databases.GetByID(ctx, uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "frozen", db.Status)
+
+	req, w = makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+	f.dbs.Delete(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "FROZEN", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+	w = f.unfreeze(t, dbID, productIdentity(f.team.Name, f.team.ID))
+	require.Equal(t, http.StatusAccepted, w.Code, w.Body.String())
+	assert.Equal(t, "unfreezing", parseEnvelope(t, w)["data"].(map[string]interface{})["status"])
+	require.Len(t, f.provider.UnfreezeCalls(), 1)
+	opID := strings.TrimPrefix(w.Header().Get("Operation-Location"), "/operations/")
+	_, env := f.get(t, opID, platformIdentity())
+	assert.Equal(t, "unfreeze", env["data"].(map[string]interface{})["type"])
+
+	rec.RunOnce(ctx)
+	db, err = f.repos.Databases.GetByID(ctx, uuid.MustParse(dbID))
+	require.NoError(t, err)
+	assert.Equal(t, "ready", db.Status)
+	_, env = f.get(t, opID, platformIdentity())
+	assert.Equal(t, "succeeded", env["data"].(map[string]interface{})["status"])
+}
+
+func TestDelete_FreezeRejected(t *testing.T) {
+	t.Parallel()
+
+	t.Run("provider cannot freeze", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		f.freezing(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+		f.registry.Register("cnpg", plainProvider{Provider: fake.NewProvider()})
+
+		req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+		f.dbs.Delete(w, req)
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "FREEZE_NOT_POSSIBLE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+	})
+
+	t.Run("provider failure", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		f.freezing(t)
+		rec := reconciler.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, f.registry, time.Minute)
+		dbID, _ := f.create(t, "orders")
+		f.readyWithPrimary(t, rec, dbID, "daap-orders-1")
+		f.provider.FreezeFn = func(context.Context, provider.ProviderDatabase) error {
+			return errors.New("connection refused")
+		}
+
+		req, w := makeAuthRequest(http.MethodDelete, "/databases/"+dbID, nil, map[string]string{"id": dbID}, platformIdentity())
+		f.dbs.Delete(w, req)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		db, err := f.repos.Databases.GetByID(context.Background(), uuid.MustParse(dbID))
+		require.NoError(t, err)
+		assert.Equal(t, "ready", db.Status)
+	})
+}
+
+func TestUnfreeze_Rejected(t *testing.T) {
+	t.Parallel()
+
+	t.Run("not frozen", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+
+		w := f.unfreeze(t, dbID, platformIdentity())
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Equal(t, "UNFREEZE_NOT_POSSIBLE", parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+		assert.Empty(t, f.provider.UnfreezeCalls())
+	})
+
+	t.Run("other team", func(t *testing.T) {
+		t.Parallel()
+		f := newOperationFixture(t)
+		dbID, _ := f.create(t, "orders")
+
+		w := f.unfreeze(t, dbID, productIdentity("payments", uuid.New()))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
@@ -0,0 +1,206 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type unlockFixture struct {
+	repos  *fake.Repositories
+	db     *database.Database
+	ops    *operation.Tracker
+	locker *database.Locker
+	h      *handler.UnlockHandler
+}
+
+// newUnlockFixture serves unlocks from the process "daap-a:1".
+func newUnlockFixture(t *testing.T) *unlockFixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	f := &unlockFixture{repos: repos, db: &database.Database{Name: "orders", OwnerTeamID: tm.ID, Namespace: "default"}}
+	require.NoError(t, repos.Databases.Create(ctx, f.db))
+
+	f.ops = operation.NewTracker(repos.Operations, "daap-a:1")
+	f.locker = database.NewLocker(repos.Locks, time.Minute, "daap-a:1")
+	f.h = handler.NewUnlockHandler(repos.Databases, f.locker, f.ops, repos.Jobs)
+	return f
+}
+
+// abandonedOperation starts an operation on behalf of an earlier process of
+// this instance, which crashed before completing it.
+func (f *unlockFixture) abandonedOperation(t *testing.T) *operation.Operation {
+	t.Helper()
+	op := operation.NewTracker(f.repos.Operations, "daap-a:1").Start(context.Background(),
+		operation.Operation{Type: operation.TypeRename, DatabaseID: f.db.ID, TeamID: f.db.OwnerTeamID})
+	require.NotNil(t, op)
+	return op
+}
+
+func (f *unlockFixture) unlock(t *testing.T) *httptest.ResponseRecorder {
+	t.Helper()
+	id := f.db.ID.String()
+	req, w := makeAuthRequest(http.MethodPost, "/admin/databases/"+id+"/unlock", nil, map[string]string{"id": id}, superuserIdentity())
+	f.h.Unlock(w, req)
+	return w
+}
+
+func TestUnlock_ClearsOrphanedLockAndOperations(t *testing.T) {
+	t.Parallel()
+	f := newUnlockFixture(t)
+	ctx := context.Background()
+
+	// An earlier process of this instance crashed mid-rename, leaving its
+	// lock and operation behind.
+	crashed := database.NewLocker(f.repos.Locks, time.Minute, "daap-a:1")
+	_, err := crashed.Acquire(ctx, f.db.ID, "rename", "alice", "req-1")
+	require.NoError(t, err)
+	op := f.abandonedOperation(t)
+
+	w := f.unlock(t)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	lock := data["lock"].(map[string]interface{})
+	assert.Equal(t, "rename", lock["operation"])
+	assert.Equal(t, "alice", lock["holder"])
+	assert.Equal(t, []interface{}{op.ID.String()}, data["operations"])
+
+	held, err := f.locker.Get(ctx, f.db.ID)
+	require.NoError(t, err)
+	assert.Nil(t, held)
+	failed, err := f.ops.Get(ctx, op.ID)
+	require.NoError(t, err)
+	assert.Equal(t, operation.StatusFailed, failed.Status)
+	assert.Equal(t, "ABANDONED", failed.Error.Code)
+
+	// Once cleared, a new operation goes through, and unlocking again has
+	// nothing to clear.
+	next, err := f.locker.Acquire(ctx, f.db.ID, "delete", "bob", "")
+	require.NoError(t, err)
+	require.NoError(t, f.locker.Release(ctx, next))
+	w = f.unlock(t)
+	require.Equal(t, http.StatusOK, w.Code)
+	data = parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Nil(t, data["lock"])
+	assert.Empty(t, data["operations"])
+}
+
+func TestUnlock_Refused(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, f *unlockFixture)
+		wantErr string
+	}{
+		{
+			name: "held by this process",
+			setup: func(t *testing.T, f *unlockFixture) {
+				_, err := f.locker.Acquire(context.Background(), f.db.ID, "rename", "alice", "")
+				require.NoError(t, err)
+			},
+			wantErr: "LOCK_HELD",
+		},
+		{
+			name: "held by a live process",
+			setup: func(t *testing.T, f *unlockFixture) {
+				_, err := database.NewLocker(f.repos.Locks, time.Minute, "daap-b:1").Acquire(context.Background(), f.db.ID, "rename", "alice", "")
+				require.NoError(t, err)
+			},
+			wantErr: "LOCK_HELD",
+		},
+		{
+			name: "job running",
+			setup: func(t *testing.T, f *unlockFixture) {
+				ctx := context.Background()
+				require.NoError(t, f.repos.Jobs.Enqueue(ctx, &jobs.Job{Type: jobs.TypeProvision, DatabaseID: f.db.ID}))
+				_, err := f.repos.Jobs.Claim(ctx, time.Now().Add(time.Second))
+				require.NoError(t, err)
+			},
+			wantErr: "JOB_RUNNING",
+		},
+		{
+			name: "operation run by this process",
+			setup: func(t *testing.T, f *unlockFixture) {
+				f.ops.Start(context.Background(), operation.Operation{Type: operation.TypeRestart, DatabaseID: f.db.ID, TeamID: f.db.OwnerTeamID})
+			},
+			wantErr: "OPERATION_RUNNING",
+		},
+		{
+			name: "operation run by a live process",
+			setup: func(t *testing.T, f *unlockFixture) {
+				operation.NewTracker(f.repos.Operations, "daap-b:1").Start(context.Background(),
+					operation.Operation{Type: operation.TypeRestart, DatabaseID: f.db.ID, TeamID: f.db.OwnerTeamID})
+			},
+			wantErr: "OPERATION_RUNNING",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := newUnlockFixture(t)
+			ctx := context.Background()
+			tt.setup(t, f)
+			op := f.abandonedOperation(t)
+
+			w := f.unlock(t)
+			require.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+			assert.Equal(t, tt.wantErr, parseEnvelope(t, w)["error"].(map[string]interface{})["code"])
+
+			running, err := f.ops.Get(ctx, op.ID)
+			require.NoError(t, err)
+			assert.Equal(t, operation.StatusRunning, running.Status, "nothing is cleared")
+		})
+	}
+}
+
+func TestLocker_LiveUntilHeartbeatsStop(t *testing.T) {
+	t.Parallel()
+	locker := database.NewLocker(fake.NewRepositories().Locks, time.Hour, "daap-a:1")
+	lock := &database.Lock{Instance: "daap-b:1", RenewedAt: time.Now()}
+
+	assert.True(t, locker.Live(lock, lock.RenewedAt.Add(2*database.LockHeartbeatInterval)))
+	assert.False(t, locker.Live(lock, lock.RenewedAt.Add(3*database.LockHeartbeatInterval)))
+}
+
+func TestTracker_LiveUntilHeartbeatsStop(t *testing.T) {
+	t.Parallel()
+	repos := fake.NewRepositories()
+	ctx := context.Background()
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	db := &database.Database{Name: "orders", OwnerTeamID: tm.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+	tracker := operation.NewTracker(repos.Operations, "daap-a:1")
+
+	op := tracker.Start(ctx, operation.Operation{Type: operation.TypeRestart, DatabaseID: db.ID, TeamID: tm.ID})
+	require.NotNil(t, op)
+	assert.True(t, tracker.Live(op, time.Now()), "run by this process")
+	tracker.Succeed(ctx, op, map[string]any{})
+	assert.False(t, tracker.Live(op, time.Now()), "completed")
+
+	settled := tracker.Start(ctx, operation.Operation{Type: operation.TypeCreate, DatabaseID: db.ID, TeamID: tm.ID})
+	require.NotNil(t, settled)
+	operation.NewTracker(repos.Operations, "daap-b:1").Settle(ctx, db.ID, map[string]any{}, nil)
+	require.NoError(t, tracker.Heartbeat(ctx))
+	assert.False(t, tracker.Live(settled, time.Now()), "settled by another process")
+
+	other := &operation.Operation{Instance: "daap-b:1", RenewedAt: time.Now()}
+	assert.True(t, tracker.Live(other, other.RenewedAt.Add(2*operation.HeartbeatInterval)))
+	assert.False(t, tracker.Live(other, other.RenewedAt.Add(3*operation.HeartbeatInterval)))
+}
@@ -0,0 +1,93 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// usageProvider reports pooler usage and fails to report compute usage.
+type usageProvider struct {
+	*fake.Provider
+	pooler    provider.PoolerUsage
+	poolerErr error
+}
+
+func (p *usageProvider) PoolerUsage(context.Context, provider.ProviderDatabase) (provider.PoolerUsage, error) {
+	return p.pooler, p.poolerErr
+}
+
+func (p *usageProvider) ComputeUsage(context.Context, provider.ProviderDatabase) (provider.ComputeUsage, error) {
+	return provider.ComputeUsage{}, errors.New("metrics-server unavailable")
+}
+
+func (p *usageProvider) ManifestCompute(string) (provider.ComputeResources, error) {
+	return provider.ComputeResources{}, provider.ErrNotSupported
+}
+
+func TestDatabaseUsage_Pooler(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	checkout := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, checkout))
+	other := &team.Team{Name: "search", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, other))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	require.NoError(t, repos.Tiers.Create(ctx, &tier.Tier{Name: "standard", BlueprintID: &bp.ID}))
+	prov := &usageProvider{Provider: fake.NewProvider(), pooler: provider.PoolerUsage{
+		Instances: 2, ActiveClients: 65, WaitingClients: 3, ActiveServers: 15, IdleServers: 1, MaxClients: 200, PoolSize: 20,
+	}}
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", prov)
+	dbs := handler.NewDatabaseHandler(repos.Databases, repos.Teams, repos.Tiers, repos.Blueprints, registry, "default", nil, nil, nil, nil, nil, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"name": "orders", "ownerTeam": checkout.Name, "tier": "standard"})
+	req, w := makeAuthRequest(http.MethodPost, "/databases", body, nil, productIdentity(checkout.Name, checkout.ID))
+	dbs.Create(w, req)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	id := parseEnvelope(t, w)["data"].(map[string]interface{})["id"].(string)
+
+	usage := func(identityTeam *team.Team) (int, map[string]interface{}) {
+		req, w := makeAuthRequest(http.MethodGet, "/databases/"+id+"/usage", nil, map[string]string{"id": id}, productIdentity(identityTeam.Name, identityTeam.ID))
+		dbs.Usage(w, req)
+		return w.Code, parseEnvelope(t, w)
+	}
+
+	code, env := usage(checkout)
+	require.Equal(t, http.StatusOK, code, env)
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"instances":             float64(2),
+		"activeClients":         float64(65),
+		"waitingClients":        float64(3),
+		"maxClients":            float64(200),
+		"activeServers":         float64(15),
+		"idleServers":           float64(1),
+		"poolSize":              float64(20),
+		"poolSaturationPercent": float64(75),
+	}, data["pooler"])
+	assert.Nil(t, data["compute"], "compute usage that cannot be read does not hide the pooler's")
+	assert.Nil(t, data["storage"], "the provider cannot report storage")
+
+	prov.poolerErr = provider.ErrNotSupported
+	code, env = usage(checkout)
+	require.Equal(t, http.StatusOK, code, env)
+	assert.Nil(t, env["data"].(map[string]interface{})["pooler"], "a database without a pooler")
+
+	code, _ = usage(other)
+	assert.Equal(t, http.StatusNotFound, code)
+}
@@ -74,7 +74,7 @@ func (m *mockUserRepo) CountAll(ctx context.Context) (int, error) {
 // --- Helpers ---
 
 func newUserHandler(authSvc *auth.Service, userRepo auth.UserRepository, teamRepo team.Repository) *handler.UserHandler {
-	return handler.NewUserHandler(authSvc, userRepo, teamRepo)
+	return handler.NewUserHandler(authSvc, userRepo, teamRepo, nil)
 }
 
 func sampleUser(id uuid.UUID, teamID *uuid.UUID, isSuperuser bool) *auth.User {
@@ -142,6 +142,61 @@ func TestUserCreate_Success(t *testing.T) {
 	assert.Equal(t, "daap_", apiKey[:5])
 }
 
+func TestUserCreate_WithScopes(t *testing.T) {
+	t.Parallel()
+
+	teamID := uuid.New()
+	teamRepo := &mockTeamRepo{
+		getByIDFn: func(_ context.Context, id uuid.UUID) (*team.Team, error) {
+			return &team.Team{ID: teamID, Name: "ops", Role: "platform"}, nil
+		},
+	}
+	var created *auth.User
+	userRepo := &mockUserRepo{
+		createFn: func(_ context.Context, u *auth.User) error {
+			u.ID = uuid.New()
+			created = u
+			return nil
+		},
+	}
+	authSvc := auth.NewService(userRepo, teamRepo, 4)
+	h := newUserHandler(authSvc, userRepo, teamRepo)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":   "dashboard",
+		"teamId": teamID.String(),
+		"scopes": []string{"read"},
+	})
+	req, w := makeChiRequest(http.MethodPost, "/users", body, "/users", nil)
+	h.Create(w, req)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NotNil(t, created)
+	assert.Equal(t, []string{"read"}, created.Scopes)
+	data := parseEnvelope(t, w)["data"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"read"}, data["scopes"])
+}
+
+func TestUserCreate_ValidationError_InvalidScope(t *testing.T) {
+	t.Parallel()
+
+	teamRepo := &mockTeamRepo{}
+	userRepo := &mockUserRepo{}
+	authSvc := auth.NewService(userRepo, teamRepo, 4)
+	h := newUserHandler(authSvc, userRepo, teamRepo)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"name":   "ci",
+		"teamId": uuid.NewString(),
+		"scopes": []string{"databases:write"},
+	})
+	req, w := makeChiRequest(http.MethodPost, "/users", body, "/users", nil)
+	h.Create(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"scopes"`)
+}
+
 func TestUserCreate_ValidationError_MissingFields(t *testing.T) {
 	t.Parallel()
 
@@ -305,6 +360,7 @@ func TestUserList_WithTeamInfo(t *testing.T) {
 	assert.Equal(t, "platform", user["role"])
 	assert.Equal(t, "daap_abc", user["apiKeyPrefix"])
 	assert.Equal(t, false, user["isSuperuser"])
+	assert.Equal(t, []interface{}{}, user["scopes"])
 	assert.NotEmpty(t, user["createdAt"])
 
 	// Should NOT have apiKey or apiKeyHash
@@ -0,0 +1,53 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/buildinfo"
+)
+
+func TestVersionHandler_ReturnsBuildInfo(t *testing.T) {
+	info := buildinfo.Info{
+		Version:   "1.4.0",
+		GitCommit: "abc123",
+		BuildDate: "2026-02-10T09:00:00Z",
+		GoVersion: "go1.25.0",
+		Features:  []string{"pprof"},
+	}
+	h := handler.NewVersionHandler(info)
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body struct {
+		Data  buildinfo.Info `json:"data"`
+		Error any            `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, info, body.Data)
+	assert.Nil(t, body.Error)
+}
+
+func TestVersionHandler_EmptyFeaturesSerializeAsArray(t *testing.T) {
+	h := handler.NewVersionHandler(buildinfo.Info{Version: "dev"})
+	req := httptest.NewRequest(http.MethodGet, "/version", nil)
+	w := httptest.NewRecorder()
+
+	h.ServeHTTP(w, req)
+
+	var body struct {
+		Data map[string]any `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, []any{}, body.Data["features"])
+}
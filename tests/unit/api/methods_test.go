@@ -0,0 +1,74 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions_ListsAllowedMethods(t *testing.T) {
+	router, _, _ := newAdminRouter(t, nil)
+
+	tests := []struct {
+		path      string
+		wantAllow string
+	}{
+		{"/teams", "GET, HEAD, POST, OPTIONS"},
+		{"/teams/3f0c6a1e-8a7e-4c1e-9a43-2b6f3f0c6a1e", "PATCH, DELETE, OPTIONS"},
+		{"/health", "GET, HEAD, OPTIONS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			// No API key: preflight requests carry no credentials.
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			require.Equal(t, http.StatusNoContent, rec.Code)
+			assert.Equal(t, tt.wantAllow, rec.Header().Get("Allow"))
+		})
+	}
+}
+
+func TestOptions_UnknownPath(t *testing.T) {
+	router, _, _ := newAdminRouter(t, nil)
+
+	req := httptest.NewRequest(http.MethodOptions, "/nowhere", nil)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Empty(t, rec.Header().Get("Allow"))
+}
+
+func TestHead_ServedByGetRoutes(t *testing.T) {
+	router, superKey, _ := newAdminRouter(t, nil)
+
+	req := httptest.NewRequest(http.MethodHead, "/teams", nil)
+	req.Header.Set("X-API-Key", superKey)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	req = httptest.NewRequest(http.MethodHead, "/teams", nil)
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, "HEAD is authenticated like GET")
+}
+
+func TestMethodNotAllowed_ListsAllowedMethods(t *testing.T) {
+	router, superKey, _ := newAdminRouter(t, nil)
+
+	req := httptest.NewRequest(http.MethodPut, "/teams", nil)
+	req.Header.Set("X-API-Key", superKey)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "GET, HEAD, POST, OPTIONS", rec.Header().Get("Allow"))
+	assert.Contains(t, rec.Body.String(), "METHOD_NOT_ALLOWED")
+}
@@ -0,0 +1,131 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/auth"
+)
+
+type recordingAuditor struct {
+	saturated bool
+	events    []audit.Event
+}
+
+func (a *recordingAuditor) Saturated() bool { return a.saturated }
+
+func (a *recordingAuditor) Record(_ context.Context, e audit.Event) error {
+	a.events = append(a.events, e)
+	return nil
+}
+
+func newAuditedRouter(rec middleware.AuditRecorder, identity *auth.Identity) *chi.Mux {
+	r := chi.NewRouter()
+	r.Use(middleware.RequestID)
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if identity != nil {
+				req = req.WithContext(middleware.WithIdentity(req.Context(), identity))
+			}
+			next.ServeHTTP(w, req)
+		})
+	})
+	r.Use(middleware.Audit(rec))
+	r.Get("/databases", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) })
+	r.Delete("/databases/{id}", func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusNoContent) })
+	r.Patch("/databases/{id}", func(w http.ResponseWriter, req *http.Request) {
+		middleware.SetAuditClassification(req.Context(), "restricted")
+		middleware.SetAuditAction(req.Context(), "database.legal_hold", chi.URLParam(req, "id"), "placed")
+		w.WriteHeader(http.StatusOK)
+	})
+	return r
+}
+
+func TestAudit_RecordsMutatingRequests(t *testing.T) {
+	teamName := "checkout"
+	rec := &recordingAuditor{}
+	router := newAuditedRouter(rec, &auth.Identity{UserID: uuid.New(), UserName: "alice", TeamName: &teamName})
+
+	for _, req := range []*http.Request{
+		httptest.NewRequest(http.MethodGet, "/databases", nil),
+		httptest.NewRequest(http.MethodDelete, "/databases/42", nil),
+	} {
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	require.Len(t, rec.events, 1)
+	e := rec.events[0]
+	assert.Equal(t, "alice", e.Actor)
+	assert.Equal(t, "checkout", e.Team)
+	assert.Equal(t, http.MethodDelete, e.Method)
+	assert.Equal(t, "/databases/42", e.Path)
+	assert.Equal(t, "/databases/{id}", e.Route)
+	assert.Equal(t, http.StatusNoContent, e.Status)
+	assert.NotEmpty(t, e.RequestID)
+}
+
+func TestAudit_AnonymousActor(t *testing.T) {
+	rec := &recordingAuditor{}
+	router := newAuditedRouter(rec, nil)
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/databases/42", nil))
+
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, "anonymous", rec.events[0].Actor)
+}
+
+func TestAudit_SaturatedRejectsChanges(t *testing.T) {
+	rec := &recordingAuditor{saturated: true}
+	router := newAuditedRouter(rec, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/databases/42", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "5", w.Header().Get("Retry-After"))
+	assert.Contains(t, w.Body.String(), "AUDIT_UNAVAILABLE")
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/databases", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, rec.events)
+}
+
+func TestAudit_RecordsDataClassification(t *testing.T) {
+	rec := &recordingAuditor{}
+	router := newAuditedRouter(rec, &auth.Identity{UserID: uuid.New(), UserName: "alice"})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPatch, "/databases/42", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/databases/42", nil))
+
+	require.Len(t, rec.events, 2)
+	assert.Equal(t, "restricted", rec.events[0].DataClassification)
+	assert.Equal(t, "/databases/{id}", rec.events[0].Route)
+	assert.Empty(t, rec.events[1].DataClassification)
+}
+
+func TestAudit_RecordsAction(t *testing.T) {
+	rec := &recordingAuditor{}
+	router := newAuditedRouter(rec, &auth.Identity{UserID: uuid.New(), UserName: "alice"})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPatch, "/databases/42", nil))
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/databases/42", nil))
+
+	require.Len(t, rec.events, 2)
+	assert.Equal(t, "database.legal_hold", rec.events[0].Action)
+	assert.Equal(t, "42", rec.events[0].DatabaseID)
+	assert.Equal(t, "placed", rec.events[0].Detail)
+	assert.Empty(t, rec.events[1].Action)
+}
+
+func TestSetAuditClassification_UnauditedRequest(t *testing.T) {
+	assert.NotPanics(t, func() { middleware.SetAuditClassification(context.Background(), "public") })
+}
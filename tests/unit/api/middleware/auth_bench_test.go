@@ -0,0 +1,67 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// BenchmarkAuth measures the auth middleware for a valid product-user key
+// among 100 active users. Cost is dominated by the bcrypt comparison, so the
+// benchmark reports both the test cost (4) and the production default (12).
+func BenchmarkAuth(b *testing.B) {
+	for _, cost := range []int{testBcryptCost, 12} {
+		b.Run(map[int]string{testBcryptCost: "bcrypt=4", 12: "bcrypt=12"}[cost], func(b *testing.B) {
+			repos := fake.NewRepositories()
+			svc := auth.NewService(repos.Users, repos.Teams, cost)
+			filler := auth.NewService(repos.Users, repos.Teams, testBcryptCost)
+			ctx := context.Background()
+
+			tm := &team.Team{Name: "bench", Role: "product"}
+			if err := repos.Teams.Create(ctx, tm); err != nil {
+				b.Fatal(err)
+			}
+
+			var rawKey string
+			for i := range 100 {
+				gen := filler
+				if i == 0 {
+					gen = svc
+				}
+				key, prefix, hash, err := gen.GenerateKey()
+				if err != nil {
+					b.Fatal(err)
+				}
+				u := &auth.User{Name: "bench", TeamID: &tm.ID, ApiKeyPrefix: prefix, ApiKeyHash: hash}
+				if err := repos.Users.Create(ctx, u); err != nil {
+					b.Fatal(err)
+				}
+				if i == 0 {
+					rawKey = key
+				}
+			}
+
+			h := middleware.Auth(svc)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for b.Loop() {
+				req := httptest.NewRequest(http.MethodGet, "/databases", nil)
+				req.Header.Set("X-API-Key", rawKey)
+				rec := httptest.NewRecorder()
+				h.ServeHTTP(rec, req)
+				if rec.Code != http.StatusOK {
+					b.Fatalf("unexpected status %d", rec.Code)
+				}
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -178,3 +179,74 @@ func TestRequireRole_NoIdentity(t *testing.T) {
 
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
 }
+
+// --- RequireScopes Tests ---
+
+func TestRequireScopes(t *testing.T) {
+	tests := []struct {
+		name     string
+		scopes   []string
+		method   string
+		path     string
+		wantCode int
+	}{
+		{"unscoped key", nil, http.MethodPost, "/databases", http.StatusOK},
+		{"read-only key reads", []string{"read"}, http.MethodGet, "/databases", http.StatusOK},
+		{"read-only key cannot create", []string{"read"}, http.MethodPost, "/databases", http.StatusForbidden},
+		{"create-only key creates", []string{"databases:create"}, http.MethodPost, "/databases", http.StatusOK},
+		{"create-only key cannot delete", []string{"databases:create"}, http.MethodDelete, "/databases/abc", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.RequireScopes()(okHandler())
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			req = req.WithContext(middleware.WithIdentity(req.Context(), &auth.Identity{Scopes: tt.scopes}))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			if tt.wantCode == http.StatusForbidden {
+				env := parseErrorResponse(t, w)
+				apiErr := env["error"].(map[string]interface{})
+				assert.Equal(t, "FORBIDDEN", apiErr["code"])
+				assert.Contains(t, apiErr["message"], "API key scopes do not allow")
+			}
+		})
+	}
+}
+
+func TestScopeDenial(t *testing.T) {
+	identity := &auth.Identity{Scopes: []string{"read"}}
+	assert.Equal(t, "", middleware.ScopeDenial(identity, http.MethodGet, "/tiers"))
+	assert.Equal(t, "API key scopes do not allow update on databases",
+		middleware.ScopeDenial(identity, http.MethodPost, "/databases/abc/restart"))
+}
+
+func TestRequireSuperuserOrOrgAdmin(t *testing.T) {
+	orgID := uuid.New()
+	teamID := uuid.New()
+	role := "platform"
+	tests := []struct {
+		name     string
+		identity *auth.Identity
+		wantCode int
+	}{
+		{"superuser", &auth.Identity{IsSuperuser: true}, http.StatusOK},
+		{"organization superadmin", &auth.Identity{OrganizationID: &orgID}, http.StatusOK},
+		{"team member of an organization", &auth.Identity{TeamID: &teamID, Role: &role, OrganizationID: &orgID}, http.StatusForbidden},
+		{"team member", &auth.Identity{TeamID: &teamID, Role: &role}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := middleware.RequireSuperuserOrOrgAdmin()(okHandler())
+			req := httptest.NewRequest(http.MethodGet, "/teams", nil)
+			req = req.WithContext(middleware.WithIdentity(req.Context(), tt.identity))
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/middleware"
+)
+
+// deadlineOf serves a request with the given X-Request-Timeout through
+// Deadline, returning the response and how long the handler had left.
+func deadlineOf(t *testing.T, maxTimeout time.Duration, header string) (*httptest.ResponseRecorder, time.Duration, bool) {
+	t.Helper()
+	var (
+		remaining time.Duration
+		bounded   bool
+	)
+	handler := middleware.Deadline(maxTimeout)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var deadline time.Time
+		deadline, bounded = r.Context().Deadline()
+		remaining = time.Until(deadline)
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if header != "" {
+		req.Header.Set(middleware.RequestTimeoutHeader, header)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	return w, remaining, bounded
+}
+
+func TestDeadline_BoundsRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		header string
+		want   time.Duration
+	}{
+		{"duration", "2s", 2 * time.Second},
+		{"milliseconds", "500ms", 500 * time.Millisecond},
+		{"seconds", "30", 30 * time.Second},
+		{"fractional seconds", "1.5", 1500 * time.Millisecond},
+		{"capped", "1h", time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			w, remaining, bounded := deadlineOf(t, time.Minute, tt.header)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			require.True(t, bounded)
+			assert.InDelta(t, tt.want, remaining, float64(time.Second))
+			assert.LessOrEqual(t, remaining, tt.want)
+		})
+	}
+}
+
+func TestDeadline_Unbounded(t *testing.T) {
+	t.Parallel()
+
+	t.Run("no header", func(t *testing.T) {
+		t.Parallel()
+		w, _, bounded := deadlineOf(t, time.Minute, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, bounded)
+	})
+
+	t.Run("header ignored", func(t *testing.T) {
+		t.Parallel()
+		w, _, bounded := deadlineOf(t, 0, "not a timeout")
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.False(t, bounded)
+	})
+}
+
+func TestDeadline_InvalidHeader(t *testing.T) {
+	t.Parallel()
+
+	for _, header := range []string{"soon", "0", "-5s", "1e300"} {
+		t.Run(header, func(t *testing.T) {
+			t.Parallel()
+			w, _, _ := deadlineOf(t, time.Minute, header)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, "INVALID_HEADER", body["error"].(map[string]interface{})["code"])
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/logging"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	levels := logging.NewLevels(slog.LevelInfo, nil)
+	prev := slog.Default()
+	slog.SetDefault(slog.New(logging.NewHandler(slog.NewJSONHandler(&buf, nil), levels, nil)))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	h := middleware.RequestID(middleware.Logger(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = w.Write([]byte("short and stout"))
+	})))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "request served", entry["msg"])
+	assert.Equal(t, "http", entry["module"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/health", entry["path"])
+	assert.Equal(t, float64(http.StatusTeapot), entry["status"])
+	assert.Equal(t, float64(15), entry["bytes"])
+	assert.NotEmpty(t, entry["requestId"])
+
+	buf.Reset()
+	levels.Set(slog.LevelInfo, map[string]slog.Level{"http": slog.LevelWarn})
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Empty(t, buf.String(), "the http module's level silences request logs")
+}
@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
 	"testing"
 
+	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/metrics"
 )
 
 func TestRecovery_NoPanic(t *testing.T) {
@@ -92,3 +96,57 @@ func TestRecovery_HandlesPanicWithNilValue(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 }
+
+func TestRecovery_ReturnsErrorID(t *testing.T) {
+	handler := middleware.Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	apiErr := env["error"].(map[string]interface{})
+	details, ok := apiErr["details"].(map[string]interface{})
+	require.True(t, ok, "panic responses must include details")
+	_, err := uuid.Parse(details["errorId"].(string))
+	assert.NoError(t, err, "errorId must be a UUID")
+}
+
+func TestRecovery_IncrementsPanicMetric(t *testing.T) {
+	handler := middleware.Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	before := panicCount(t)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, before+1, panicCount(t))
+}
+
+func TestRecovery_RepanicsOnErrAbortHandler(t *testing.T) {
+	handler := middleware.Recovery(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+// panicCount reads daap_http_panics_total from the default metrics registry.
+func panicCount(t *testing.T) int {
+	t.Helper()
+	var buf strings.Builder
+	metrics.Default.WriteText(&buf)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if v, ok := strings.CutPrefix(line, "daap_http_panics_total "); ok {
+			n, err := strconv.Atoi(v)
+			require.NoError(t, err)
+			return n
+		}
+	}
+	t.Fatal("daap_http_panics_total not found")
+	return 0
+}
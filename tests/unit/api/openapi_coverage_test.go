@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -18,10 +19,17 @@ import (
 	"github.com/daap14/daap/internal/api"
 	"github.com/daap14/daap/internal/auth"
 	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/catalog"
+	"github.com/daap14/daap/internal/config"
 	"github.com/daap14/daap/internal/database"
 	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/recommend"
+	"github.com/daap14/daap/internal/retention"
+	"github.com/daap14/daap/internal/rollout"
 	"github.com/daap14/daap/internal/team"
 	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
 )
 
 // openAPISpec is the minimal structure needed to extract paths from the spec.
@@ -43,6 +51,9 @@ func (n *noopRepo) Create(_ context.Context, _ *database.Database) error { retur
 func (n *noopRepo) GetByID(_ context.Context, _ uuid.UUID) (*database.Database, error) {
 	return nil, nil
 }
+func (n *noopRepo) GetByName(_ context.Context, _ string) (*database.Database, error) {
+	return nil, nil
+}
 func (n *noopRepo) List(_ context.Context, _ database.ListFilter) (*database.ListResult, error) {
 	return nil, nil
 }
@@ -53,6 +64,59 @@ func (n *noopRepo) UpdateStatus(_ context.Context, _ uuid.UUID, _ database.Statu
 	return nil, nil
 }
 func (n *noopRepo) SoftDelete(_ context.Context, _ uuid.UUID) error { return nil }
+func (n *noopRepo) Acknowledge(_ context.Context, _ uuid.UUID, _ *database.Acknowledgement) (*database.Database, error) {
+	return nil, nil
+}
+
+type noopStats struct{}
+
+func (n *noopStats) Stats(_ context.Context, _ database.StatsFilter) (*database.Stats, error) {
+	return &database.Stats{}, nil
+}
+func (n *noopStats) ProvisioningDurations(_ context.Context, _ database.ProvisioningDurationFilter) (*database.ProvisioningDurationResult, error) {
+	return &database.ProvisioningDurationResult{}, nil
+}
+func (n *noopStats) StatusHistory(_ context.Context, _ uuid.UUID) ([]database.StatusChange, error) {
+	return nil, nil
+}
+
+type noopResizeEvents struct{}
+
+func (n *noopResizeEvents) Record(_ context.Context, _ *database.ResizeEvent) error { return nil }
+func (n *noopResizeEvents) ListByDatabase(_ context.Context, _ uuid.UUID) ([]database.ResizeEvent, error) {
+	return nil, nil
+}
+
+type noopRecommender struct{}
+
+func (n *noopRecommender) Recommend(_ context.Context, _ *database.Database) (*recommend.Result, error) {
+	return &recommend.Result{}, nil
+}
+
+type noopTierChanges struct{}
+
+func (n *noopTierChanges) Record(_ context.Context, _ *database.TierChange) error { return nil }
+func (n *noopTierChanges) ListByDatabase(_ context.Context, _ uuid.UUID) ([]database.TierChange, error) {
+	return nil, nil
+}
+
+type noopRollouts struct{}
+
+func (n *noopRollouts) Active(_ context.Context, _ uuid.UUID) (*rollout.Rollout, error) {
+	return nil, nil
+}
+func (n *noopRollouts) Begin(_ context.Context, _ *tier.Tier, _ uuid.UUID) (*rollout.Rollout, error) {
+	return nil, nil
+}
+func (n *noopRollouts) Pause(_ context.Context, _ uuid.UUID) (*rollout.Rollout, error) {
+	return nil, nil
+}
+func (n *noopRollouts) Resume(_ context.Context, _ uuid.UUID) (*rollout.Rollout, error) {
+	return nil, nil
+}
+func (n *noopRollouts) Rollback(_ context.Context, _ uuid.UUID) (*rollout.Rollout, error) {
+	return nil, nil
+}
 
 type noopBlueprintRepo struct{}
 
@@ -65,6 +129,12 @@ func (n *noopBlueprintRepo) GetByName(_ context.Context, _ string) (*blueprint.B
 }
 func (n *noopBlueprintRepo) List(_ context.Context) ([]blueprint.Blueprint, error) { return nil, nil }
 func (n *noopBlueprintRepo) Delete(_ context.Context, _ uuid.UUID) error           { return nil }
+func (n *noopBlueprintRepo) Update(_ context.Context, _ uuid.UUID, _ blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+	return nil, nil
+}
+func (n *noopBlueprintRepo) ListVersions(_ context.Context, _ uuid.UUID) ([]blueprint.Version, error) {
+	return nil, nil
+}
 
 type noopTeamRepo struct{}
 
@@ -73,6 +143,9 @@ func (n *noopTeamRepo) GetByID(_ context.Context, _ uuid.UUID) (*team.Team, erro
 func (n *noopTeamRepo) GetByName(_ context.Context, _ string) (*team.Team, error)  { return nil, nil }
 func (n *noopTeamRepo) List(_ context.Context) ([]team.Team, error)                { return nil, nil }
 func (n *noopTeamRepo) Delete(_ context.Context, _ uuid.UUID) error                { return nil }
+func (n *noopTeamRepo) Update(_ context.Context, _ uuid.UUID, _ team.UpdateFields) (*team.Team, error) {
+	return nil, nil
+}
 
 type noopTierRepo struct{}
 
@@ -118,14 +191,42 @@ func TestOpenAPISpec_RoutesCoverAllPaths(t *testing.T) {
 	authService := auth.NewService(userRepo, teamRepo, 4)
 
 	router := api.NewRouter(api.RouterDeps{
-		K8sChecker:    &noopHealthChecker{},
-		OpenAPISpec:   specpkg.OpenAPISpec,
-		Repo:          &noopRepo{},
-		AuthService:   authService,
-		TeamRepo:      teamRepo,
-		TierRepo:      &noopTierRepo{},
-		BlueprintRepo: &noopBlueprintRepo{},
-		UserRepo:      userRepo,
+		K8sChecker:     &noopHealthChecker{},
+		OpenAPISpec:    specpkg.OpenAPISpec,
+		Repo:           &noopRepo{},
+		Stats:          &noopStats{},
+		ResizeEvents:   &noopResizeEvents{},
+		Recommender:    &noopRecommender{},
+		TierChanges:    &noopTierChanges{},
+		Queries:        fake.NewRepositories().Queries,
+		Promotions:     fake.NewRepositories().Promotions,
+		Dependents:     fake.NewRepositories().Dependents,
+		Operations:     operation.NewTracker(fake.NewRepositories().Operations, "daap-a:1"),
+		Locker:         database.NewLocker(fake.NewRepositories().Locks, time.Minute, "test:1"),
+		Jobs:           fake.NewRepositories().Jobs,
+		Renames:        fake.NewRepositories().Renames,
+		Environments:   database.Environments{"dev", "prod"},
+		Rollouts:       &noopRollouts{},
+		RolloutRepo:    fake.NewRepositories().Rollouts,
+		Freezes:        fake.NewRepositories().Freezes,
+		Specs:          fake.NewRepositories().Specs,
+		Revisions:      fake.NewRepositories().Revisions,
+		AuthService:    authService,
+		TeamRepo:       teamRepo,
+		TierRepo:       &noopTierRepo{},
+		BlueprintRepo:  &noopBlueprintRepo{},
+		UserRepo:       userRepo,
+		Organizations:  fake.NewRepositories().Organizations,
+		UsageSamples:   fake.NewRepositories().UsageSamples,
+		Invitations:    fake.NewRepositories().Invitations,
+		Preflight:      &stubPreflight{},
+		GitOps:         &stubGitOps{},
+		SupportBundles: &stubSupportBundler{},
+		CNPGOperator:   &stubOperator{},
+		Reconciler:     &stubReconciler{},
+		Config:         config.NewReloader(config.Config{}),
+		Retention:      retention.New(fake.NewRepositories().Purges, time.Hour, 24*time.Hour),
+		Catalog:        catalog.New(&noopRepo{}, catalog.Config{}),
 	})
 
 	chiRoutes := extractChiRoutes(t, router)
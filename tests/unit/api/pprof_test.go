@@ -0,0 +1,74 @@
+package api_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// newAdminRouter returns a router backed by in-memory repositories, plus a
+// superuser key and a platform user key. configure may set extra deps.
+func newAdminRouter(t *testing.T, configure func(*api.RouterDeps)) (http.Handler, string, string) {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	authService := auth.NewService(repos.Users, repos.Teams, 4)
+
+	superKey, err := authService.BootstrapSuperuser(ctx)
+	require.NoError(t, err)
+
+	tm := &team.Team{Name: "platform", Role: "platform"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	platformKey, prefix, hash, err := authService.GenerateKey()
+	require.NoError(t, err)
+	require.NoError(t, repos.Users.Create(ctx, &auth.User{Name: "ops", TeamID: &tm.ID, ApiKeyPrefix: prefix, ApiKeyHash: hash}))
+
+	deps := api.RouterDeps{
+		K8sChecker:  &noopHealthChecker{},
+		AuthService: authService,
+		TeamRepo:    repos.Teams,
+		UserRepo:    repos.Users,
+	}
+	if configure != nil {
+		configure(&deps)
+	}
+	return api.NewRouter(deps), superKey, platformKey
+}
+
+func TestPprof_Access(t *testing.T) {
+	tests := []struct {
+		name     string
+		enabled  bool
+		key      func(superKey, platformKey string) string
+		wantCode int
+	}{
+		{"superuser allowed when enabled", true, func(s, _ string) string { return s }, http.StatusOK},
+		{"platform user forbidden", true, func(_, p string) string { return p }, http.StatusForbidden},
+		{"unauthenticated rejected", true, func(_, _ string) string { return "" }, http.StatusUnauthorized},
+		{"not routed when disabled", false, func(s, _ string) string { return s }, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router, superKey, platformKey := newAdminRouter(t, func(d *api.RouterDeps) { d.PprofEnabled = tt.enabled })
+
+			req := httptest.NewRequest(http.MethodGet, "/debug/pprof/cmdline", nil)
+			if key := tt.key(superKey, platformKey); key != "" {
+				req.Header.Set("X-API-Key", key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
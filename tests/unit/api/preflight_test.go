@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api"
+	"github.com/daap14/daap/internal/preflight"
+)
+
+type stubPreflight struct {
+	report preflight.Report
+}
+
+func (s *stubPreflight) Run(context.Context) preflight.Report {
+	return s.report
+}
+
+func TestPreflight_Access(t *testing.T) {
+	runner := &stubPreflight{report: preflight.Report{Passed: true}}
+	router, superKey, platformKey := newAdminRouter(t, func(d *api.RouterDeps) { d.Preflight = runner })
+
+	tests := []struct {
+		name     string
+		key      string
+		wantCode int
+	}{
+		{"superuser allowed", superKey, http.StatusOK},
+		{"platform user forbidden", platformKey, http.StatusForbidden},
+		{"unauthenticated rejected", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/admin/preflight", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestPreflight_ReportsFailures(t *testing.T) {
+	runner := &stubPreflight{report: preflight.Report{
+		Passed: false,
+		Checks: []preflight.Check{
+			{Name: "cnpg-crds", Status: preflight.StatusPass, Message: "CloudNativePG CRDs are installed"},
+			{Name: "rbac", Status: preflight.StatusFail, Message: "1 of 42 required permissions are missing",
+				Details: []string{"delete postgresql.cnpg.io/clusters in default"}},
+		},
+	}}
+	router, superKey, _ := newAdminRouter(t, func(d *api.RouterDeps) { d.Preflight = runner })
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/preflight", nil)
+	req.Header.Set("X-API-Key", superKey)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, false, data["passed"])
+
+	checks := data["checks"].([]interface{})
+	require.Len(t, checks, 2)
+	rbac := checks[1].(map[string]interface{})
+	assert.Equal(t, "fail", rbac["status"])
+	assert.Equal(t, []interface{}{"delete postgresql.cnpg.io/clusters in default"}, rbac["details"])
+}
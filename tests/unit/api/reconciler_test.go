@@ -0,0 +1,115 @@
+package api_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api"
+	"github.com/daap14/daap/internal/reconciler"
+)
+
+type stubReconciler struct {
+	state reconciler.State
+}
+
+func (s *stubReconciler) LastSuccess() time.Time  { return s.state.LastSuccessAt }
+func (s *stubReconciler) Stale(time.Time) bool    { return false }
+func (s *stubReconciler) State() reconciler.State { return s.state }
+func (s *stubReconciler) SetInterval(d time.Duration) error {
+	s.state.Interval = d
+	return nil
+}
+
+func reconcilerRequest(t *testing.T, router http.Handler, method, key string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+	req := httptest.NewRequest(method, "/admin/reconciler", &buf)
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestReconcilerState_Access(t *testing.T) {
+	router, superKey, platformKey := newAdminRouter(t, func(d *api.RouterDeps) {
+		d.Reconciler = &stubReconciler{state: reconciler.State{Interval: 10 * time.Second}}
+	})
+
+	tests := []struct {
+		name     string
+		method   string
+		key      string
+		wantCode int
+	}{
+		{"superuser reads", http.MethodGet, superKey, http.StatusOK},
+		{"platform user cannot read", http.MethodGet, platformKey, http.StatusForbidden},
+		{"platform user cannot update", http.MethodPatch, platformKey, http.StatusForbidden},
+		{"unauthenticated rejected", http.MethodGet, "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := reconcilerRequest(t, router, tt.method, tt.key, map[string]int{"intervalSeconds": 30})
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
+
+func TestReconcilerState_Get(t *testing.T) {
+	lastRun := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	router, superKey, _ := newAdminRouter(t, func(d *api.RouterDeps) {
+		d.Reconciler = &stubReconciler{state: reconciler.State{
+			Interval:        10 * time.Second,
+			LastRunAt:       lastRun,
+			LastRunDuration: 1500 * time.Millisecond,
+			Processed:       12,
+			Errors:          map[string]int{"cnpg": 2},
+			Backlog:         3,
+		}}
+	})
+
+	rec := reconcilerRequest(t, router, http.MethodGet, superKey, nil)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	data := env["data"].(map[string]interface{})
+	assert.Equal(t, float64(10), data["intervalSeconds"])
+	assert.Equal(t, "2026-03-01T12:00:00Z", data["lastRunAt"])
+	assert.Equal(t, float64(1500), data["lastRunDurationMs"])
+	assert.Nil(t, data["lastSuccessAt"], "no pass has succeeded")
+	assert.Equal(t, float64(12), data["processed"])
+	assert.Equal(t, map[string]interface{}{"cnpg": float64(2)}, data["errors"])
+	assert.Equal(t, float64(3), data["backlog"])
+}
+
+func TestReconcilerState_UpdateInterval(t *testing.T) {
+	stub := &stubReconciler{state: reconciler.State{Interval: 10 * time.Second}}
+	router, superKey, _ := newAdminRouter(t, func(d *api.RouterDeps) { d.Reconciler = stub })
+
+	rec := reconcilerRequest(t, router, http.MethodPatch, superKey, map[string]int{"intervalSeconds": 60})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, time.Minute, stub.state.Interval)
+
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &env))
+	assert.Equal(t, float64(60), env["data"].(map[string]interface{})["intervalSeconds"])
+
+	for _, body := range []any{map[string]int{"intervalSeconds": 0}, map[string]int{}} {
+		rec = reconcilerRequest(t, router, http.MethodPatch, superKey, body)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	}
+	assert.Equal(t, time.Minute, stub.state.Interval, "rejected updates leave the interval alone")
+}
@@ -1,17 +1,24 @@
 package response_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 
 	"github.com/daap14/daap/internal/api/response"
+	"github.com/daap14/daap/internal/breaker"
+	"github.com/daap14/daap/internal/provider"
 )
 
 func TestNewMeta_GeneratesUUID(t *testing.T) {
@@ -76,6 +83,23 @@ func TestSuccess_Status201(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, w.Code)
 }
 
+func TestSuccessWithWarnings(t *testing.T) {
+	w := httptest.NewRecorder()
+	response.SuccessWithWarnings(w, http.StatusCreated, "created", []string{"nearly full"}, "req-1")
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var env map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	meta := env["meta"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"nearly full"}, meta["warnings"])
+
+	// Without warnings the field is left out, as in Success.
+	w = httptest.NewRecorder()
+	response.SuccessWithWarnings(w, http.StatusCreated, "created", nil, "req-1")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+	assert.NotContains(t, env["meta"], "warnings")
+}
+
 func TestErr_WritesErrorEnvelope(t *testing.T) {
 	// Arrange
 	w := httptest.NewRecorder()
@@ -98,6 +122,7 @@ func TestErr_WritesErrorEnvelope(t *testing.T) {
 	apiErr := env["error"].(map[string]interface{})
 	assert.Equal(t, "VALIDATION_ERROR", apiErr["code"])
 	assert.Equal(t, "invalid input", apiErr["message"])
+	assert.Equal(t, false, apiErr["retryable"])
 
 	meta := env["meta"].(map[string]interface{})
 	assert.Equal(t, requestID, meta["requestId"])
@@ -126,6 +151,73 @@ func TestErrWithDetails_IncludesDetails(t *testing.T) {
 	assert.Equal(t, "required", det["reason"])
 }
 
+func TestErr_RetryableByStatus(t *testing.T) {
+	tests := []struct {
+		status int
+		want   bool
+	}{
+		{http.StatusBadRequest, false},
+		{http.StatusConflict, false},
+		{http.StatusInternalServerError, false},
+		{http.StatusTooManyRequests, true},
+		{http.StatusBadGateway, true},
+		{http.StatusServiceUnavailable, true},
+		{http.StatusGatewayTimeout, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			w := httptest.NewRecorder()
+			response.Err(w, tt.status, "CODE", "message", "req")
+
+			var env map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			apiErr := env["error"].(map[string]interface{})
+			assert.Equal(t, tt.want, apiErr["retryable"])
+		})
+	}
+}
+
+func TestServerErr_ClassifiesCause(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantCode   string
+	}{
+		{"breaker open", fmt.Errorf("apply: %w", breaker.ErrOpen), http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE"},
+		{"provider plugin unavailable", fmt.Errorf("plugin rds: apply: %w", provider.ErrUnavailable), http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE"},
+		{"deadline exceeded", context.DeadlineExceeded, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE"},
+		{"connection failure", &pgconn.PgError{Code: "08006"}, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE"},
+		{"serialization failure", &pgconn.PgError{Code: "40001"}, http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE"},
+		{"kubernetes unavailable", k8serrors.NewServiceUnavailable("down"), http.StatusServiceUnavailable, "SERVICE_UNAVAILABLE"},
+		{"constraint violation", &pgconn.PgError{Code: "23505"}, http.StatusInternalServerError, "INTERNAL_ERROR"},
+		{"client canceled", context.Canceled, http.StatusInternalServerError, "INTERNAL_ERROR"},
+		{"unknown", errors.New("boom"), http.StatusInternalServerError, "INTERNAL_ERROR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			response.ServerErr(w, tt.err, "Failed", "req")
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			var env map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &env))
+			apiErr := env["error"].(map[string]interface{})
+			assert.Equal(t, tt.wantCode, apiErr["code"])
+
+			if tt.wantStatus == http.StatusServiceUnavailable {
+				assert.Equal(t, "5", w.Header().Get("Retry-After"))
+				assert.Equal(t, true, apiErr["retryable"])
+			} else {
+				assert.Empty(t, w.Header().Get("Retry-After"))
+				assert.Equal(t, false, apiErr["retryable"])
+			}
+		})
+	}
+}
+
 func TestJSON_SetsContentTypeAndStatus(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -176,3 +268,36 @@ func TestSuccess_NilErrorOnSuccess(t *testing.T) {
 	require.NoError(t, err)
 	assert.Nil(t, env["error"])
 }
+
+func TestStreamList_MatchesSuccessList(t *testing.T) {
+	type item struct {
+		Name   string            `json:"name"`
+		Labels map[string]string `json:"labels"`
+	}
+	items := []item{
+		{Name: "orders", Labels: map[string]string{"app": "<checkout>"}},
+		{Name: "payments"},
+		{Name: "users", Labels: map[string]string{}},
+	}
+
+	for _, n := range []int{0, 1, len(items)} {
+		t.Run(fmt.Sprintf("items=%d", n), func(t *testing.T) {
+			buffered := httptest.NewRecorder()
+			response.SuccessList(buffered, http.StatusOK, items[:n], 42, 3, 20, "req-1")
+
+			streamed := httptest.NewRecorder()
+			response.StreamList(streamed, http.StatusOK, n, func(i int) item { return items[i] }, 42, 3, 20, "req-1")
+
+			assert.Equal(t, http.StatusOK, streamed.Code)
+			assert.Equal(t, "application/json", streamed.Header().Get("Content-Type"))
+
+			var want, got map[string]interface{}
+			require.NoError(t, json.Unmarshal(buffered.Body.Bytes(), &want))
+			require.NoError(t, json.Unmarshal(streamed.Body.Bytes(), &got), streamed.Body.String())
+			delete(want["meta"].(map[string]interface{}), "timestamp")
+			delete(got["meta"].(map[string]interface{}), "timestamp")
+			assert.Equal(t, want, got)
+			assert.NotNil(t, got["data"], "an empty page is [], not null")
+		})
+	}
+}
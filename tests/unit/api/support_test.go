@@ -0,0 +1,53 @@
+package api_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type stubSupportBundler struct{}
+
+func (s *stubSupportBundler) Write(_ context.Context, w io.Writer, _ *database.Database) error {
+	_, err := io.WriteString(w, "bundle")
+	return err
+}
+
+func TestSupportBundle_Access(t *testing.T) {
+	router, superKey, platformKey := newAdminRouter(t, func(d *api.RouterDeps) {
+		d.Repo = fake.NewDatabaseRepository()
+		d.SupportBundles = &stubSupportBundler{}
+	})
+
+	tests := []struct {
+		name     string
+		key      string
+		wantCode int
+	}{
+		{"platform user allowed", platformKey, http.StatusNotFound},
+		{"superuser forbidden", superKey, http.StatusForbidden},
+		{"unauthenticated rejected", "", http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/databases/"+uuid.NewString()+"/support-bundle", nil)
+			if tt.key != "" {
+				req.Header.Set("X-API-Key", tt.key)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.wantCode, rec.Code)
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package validation_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api/validation"
+)
+
+func TestAck_Valid(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	assert.Empty(t, validation.ValidateAckRequest(validation.AckDatabaseRequest{Now: now}))
+	assert.Empty(t, validation.ValidateAckRequest(validation.AckDatabaseRequest{Comment: "known incident", Until: "2026-03-01T18:00:00Z", Now: now}))
+}
+
+func TestAck_Invalid(t *testing.T) {
+	t.Parallel()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		req      validation.AckDatabaseRequest
+		field    string
+		contains string
+	}{
+		{"until format", validation.AckDatabaseRequest{Until: "tonight", Now: now}, "until", "RFC 3339"},
+		{"until in the past", validation.AckDatabaseRequest{Until: "2026-03-01T11:00:00Z", Now: now}, "until", "future"},
+		{"comment too long", validation.AckDatabaseRequest{Comment: strings.Repeat("a", 1001), Now: now}, "comment", "1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assertFieldError(t, validation.ValidateAckRequest(tt.req), tt.field, tt.contains)
+		})
+	}
+}
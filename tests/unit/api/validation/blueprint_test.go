@@ -266,3 +266,94 @@ func TestValidateCreateBlueprintRequest_WhitespaceTrimmingOnName(t *testing.T) {
 
 	assert.Empty(t, errs)
 }
+
+func TestValidateCreateBlueprintRequest_ManifestsTooLarge(t *testing.T) {
+	t.Parallel()
+
+	errs := validation.ValidateCreateBlueprintRequest(validation.CreateBlueprintRequest{
+		Name:             "cnpg-standard",
+		Provider:         "cnpg",
+		Manifests:        validManifests,
+		Registry:         registryWith("cnpg"),
+		MaxManifestBytes: 32,
+	})
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "manifests", errs[0].Field)
+		assert.Contains(t, errs[0].Message, "at most 32 bytes")
+	}
+}
+
+func TestValidateBlueprintRequest_ManifestsAtLimit(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, validation.ValidateCreateBlueprintRequest(validation.CreateBlueprintRequest{
+		Name:             "cnpg-standard",
+		Provider:         "cnpg",
+		Manifests:        validManifests,
+		Registry:         registryWith("cnpg"),
+		MaxManifestBytes: len(validManifests),
+	}))
+
+	manifests := validManifests
+	assert.Empty(t, validation.ValidateUpdateBlueprintRequest(validation.UpdateBlueprintRequest{
+		Manifests:        &manifests,
+		MaxManifestBytes: len(validManifests),
+	}))
+}
+
+func TestValidateUpdateBlueprintRequest_ManifestsTooLarge(t *testing.T) {
+	t.Parallel()
+
+	manifests := validManifests
+	errs := validation.ValidateUpdateBlueprintRequest(validation.UpdateBlueprintRequest{
+		Manifests:        &manifests,
+		MaxManifestBytes: 32,
+	})
+
+	if assert.Len(t, errs, 1) {
+		assert.Equal(t, "manifests", errs[0].Field)
+	}
+}
+
+func TestValidateCreateBlueprintRequest_Scope(t *testing.T) {
+	t.Parallel()
+
+	id := "550e8400-e29b-41d4-a716-446655440000"
+	tests := []struct {
+		name      string
+		teamID    string
+		orgID     string
+		wantField string
+	}{
+		{name: "global"},
+		{name: "team", teamID: id},
+		{name: "organization", orgID: id},
+		{name: "invalid team", teamID: "checkout", wantField: "teamId"},
+		{name: "invalid organization", orgID: "acme", wantField: "organizationId"},
+		{name: "both", teamID: id, orgID: id, wantField: "organizationId"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := validation.ValidateCreateBlueprintRequest(validation.CreateBlueprintRequest{
+				Name:           "cnpg-standard",
+				Provider:       "cnpg",
+				Manifests:      validManifests,
+				Registry:       registryWith("cnpg"),
+				TeamID:         tt.teamID,
+				OrganizationID: tt.orgID,
+			})
+
+			if tt.wantField == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			if assert.Len(t, errs, 1) {
+				assert.Equal(t, tt.wantField, errs[0].Field)
+			}
+		})
+	}
+}
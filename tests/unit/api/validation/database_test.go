@@ -2,10 +2,13 @@ package validation_test
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/database"
 )
 
 func TestValidateName_Valid(t *testing.T) {
@@ -185,3 +188,109 @@ func TestValidateAll_MultipleErrors(t *testing.T) {
 	assert.True(t, fields["tier"], "expected tier error")
 	assert.GreaterOrEqual(t, len(errs), 3, "expected at least 3 field errors")
 }
+
+func TestValidateEnvironment(t *testing.T) {
+	base := validation.CreateDatabaseRequest{Name: "mydb", OwnerTeam: "team-a", Tier: "standard"}
+
+	req := base
+	req.Environment = "staging"
+	req.Environments = []string{"dev", "staging", "prod"}
+	assert.Empty(t, validation.ValidateCreateRequest(req))
+
+	req.Environment = "qa"
+	errs := validation.ValidateCreateRequest(req)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "environment", errs[0].Field)
+	assert.Contains(t, errs[0].Message, "dev, staging, prod")
+
+	req.Environments = nil
+	errs = validation.ValidateCreateRequest(req)
+	require.Len(t, errs, 1)
+	assert.Contains(t, errs[0].Message, "not configured")
+}
+
+func TestValidateDataClassification(t *testing.T) {
+	req := validation.CreateDatabaseRequest{Name: "mydb", OwnerTeam: "team-a", Tier: "standard", DataClassification: "restricted"}
+	assert.Empty(t, validation.ValidateCreateRequest(req))
+
+	req.DataClassification = "top-secret"
+	errs := validation.ValidateCreateRequest(req)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "dataClassification", errs[0].Field)
+	assert.Contains(t, errs[0].Message, "public, internal, confidential, restricted")
+
+	invalid := "Public"
+	errs = validation.ValidateUpdateRequest(validation.UpdateDatabaseRequest{DataClassification: &invalid})
+	require.Len(t, errs, 1)
+	assert.Empty(t, validation.ValidateUpdateRequest(validation.UpdateDatabaseRequest{}))
+}
+
+func TestValidateExposure(t *testing.T) {
+	base := validation.CreateDatabaseRequest{Name: "mydb", OwnerTeam: "team-a", Tier: "standard"}
+
+	tests := []struct {
+		name     string
+		exposure database.Exposure
+		field    string // empty if valid
+	}{
+		{"unset", database.Exposure{}, ""},
+		{"cluster", database.Exposure{Type: "cluster"}, ""},
+		{"internal without ranges", database.Exposure{Type: "internal"}, ""},
+		{"external with ranges", database.Exposure{Type: "external", AllowedSourceRanges: []string{"203.0.113.0/24", "2001:db8::/32"}}, ""},
+		{"unknown type", database.Exposure{Type: "public"}, "exposure.type"},
+		{"external without ranges", database.Exposure{Type: "external"}, "exposure.allowedSourceRanges"},
+		{"cluster with ranges", database.Exposure{AllowedSourceRanges: []string{"10.0.0.0/8"}}, "exposure.allowedSourceRanges"},
+		{"invalid range", database.Exposure{Type: "internal", AllowedSourceRanges: []string{"10.0.0.1"}}, "exposure.allowedSourceRanges[0]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := base
+			req.Exposure = tt.exposure
+			errs := validation.ValidateCreateRequest(req)
+			if tt.field == "" {
+				assert.Empty(t, errs)
+				return
+			}
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.field, errs[0].Field)
+		})
+	}
+}
+
+func TestValidatePromoteRequest(t *testing.T) {
+	assert.Empty(t, validation.ValidatePromoteRequest(validation.PromoteDatabaseRequest{}))
+	assert.Empty(t, validation.ValidatePromoteRequest(validation.PromoteDatabaseRequest{Name: "orders-staging"}))
+	errs := validation.ValidatePromoteRequest(validation.PromoteDatabaseRequest{Name: "Orders"})
+	require.Len(t, errs, 1)
+	assert.Equal(t, "name", errs[0].Field)
+}
+
+func TestValidateRestoreRequest(t *testing.T) {
+	now := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	past, future, garbled := "2026-02-03T08:45:00Z", "2026-02-03T09:15:00Z", "yesterday"
+
+	assert.Empty(t, validation.ValidateRestoreRequest(validation.RestoreDatabaseRequest{Name: "orders-restored", Now: now}))
+	assert.Empty(t, validation.ValidateRestoreRequest(validation.RestoreDatabaseRequest{
+		Name: "orders-restored", Backup: "daap-orders-20260203", TargetTime: &past, Now: now,
+	}))
+
+	tests := []struct {
+		name  string
+		req   validation.RestoreDatabaseRequest
+		field string
+	}{
+		{"missing name", validation.RestoreDatabaseRequest{}, "name"},
+		{"invalid name", validation.RestoreDatabaseRequest{Name: "Orders"}, "name"},
+		{"invalid backup", validation.RestoreDatabaseRequest{Name: "orders-restored", Backup: "Nightly Backup"}, "backup"},
+		{"invalid target time", validation.RestoreDatabaseRequest{Name: "orders-restored", TargetTime: &garbled}, "targetTime"},
+		{"future target time", validation.RestoreDatabaseRequest{Name: "orders-restored", TargetTime: &future}, "targetTime"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Now = now
+			errs := validation.ValidateRestoreRequest(tt.req)
+			require.Len(t, errs, 1)
+			assert.Equal(t, tt.field, errs[0].Field)
+		})
+	}
+}
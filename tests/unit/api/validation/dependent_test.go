@@ -0,0 +1,38 @@
+package validation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api/validation"
+)
+
+func TestCreateDependent_Valid(t *testing.T) {
+	t.Parallel()
+	for _, service := range []string{"checkout-api", "github.com/acme/orders", "orders.worker@prod"} {
+		assert.Empty(t, validation.ValidateCreateDependentRequest(validation.CreateDependentRequest{Service: service}), service)
+	}
+}
+
+func TestCreateDependent_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		req      validation.CreateDependentRequest
+		field    string
+		contains string
+	}{
+		{"service required", validation.CreateDependentRequest{}, "service", "required"},
+		{"service too long", validation.CreateDependentRequest{Service: strings.Repeat("a", 256)}, "service", "255"},
+		{"service whitespace", validation.CreateDependentRequest{Service: "checkout api"}, "service", "whitespace"},
+		{"description too long", validation.CreateDependentRequest{Service: "api", Description: strings.Repeat("a", 1001)}, "description", "1000"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assertFieldError(t, validation.ValidateCreateDependentRequest(tt.req), tt.field, tt.contains)
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package validation_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api/validation"
+)
+
+var freezeNow = time.Date(2026, 12, 20, 12, 0, 0, 0, time.UTC)
+
+func validCreateFreezeRequest() validation.CreateFreezeRequest {
+	return validation.CreateFreezeRequest{
+		Reason: "Holiday lockdown",
+		EndsAt: "2027-01-04T09:00:00Z",
+		Now:    freezeNow,
+	}
+}
+
+func TestCreateFreeze_Valid(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, validation.ValidateCreateFreezeRequest(validCreateFreezeRequest()))
+
+	req := validCreateFreezeRequest()
+	req.StartsAt = "2026-12-23T18:00:00Z"
+	assert.Empty(t, validation.ValidateCreateFreezeRequest(req))
+}
+
+func TestCreateFreeze_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		modify   func(*validation.CreateFreezeRequest)
+		field    string
+		contains string
+	}{
+		{"reason required", func(r *validation.CreateFreezeRequest) { r.Reason = "  " }, "reason", "required"},
+		{"endsAt required", func(r *validation.CreateFreezeRequest) { r.EndsAt = "" }, "endsAt", "required"},
+		{"endsAt format", func(r *validation.CreateFreezeRequest) { r.EndsAt = "next monday" }, "endsAt", "RFC 3339"},
+		{"startsAt format", func(r *validation.CreateFreezeRequest) { r.StartsAt = "2026-12-23" }, "startsAt", "RFC 3339"},
+		{"endsAt before startsAt", func(r *validation.CreateFreezeRequest) { r.StartsAt = "2027-01-05T00:00:00Z" }, "endsAt", "after startsAt"},
+		{"endsAt in the past", func(r *validation.CreateFreezeRequest) {
+			r.StartsAt = "2026-12-01T00:00:00Z"
+			r.EndsAt = "2026-12-02T00:00:00Z"
+		}, "endsAt", "future"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := validCreateFreezeRequest()
+			tt.modify(&req)
+			assertFieldError(t, validation.ValidateCreateFreezeRequest(req), tt.field, tt.contains)
+		})
+	}
+}
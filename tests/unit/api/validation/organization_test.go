@@ -0,0 +1,42 @@
+package validation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api/validation"
+)
+
+func TestCreateOrganization(t *testing.T) {
+	t.Parallel()
+	assert.Empty(t, validation.ValidateCreateOrganizationRequest(validation.CreateOrganizationRequest{Name: "acme"}))
+	assert.Empty(t, validation.ValidateCreateOrganizationRequest(validation.CreateOrganizationRequest{Name: "acme", MaxDatabases: 50}))
+
+	tests := []struct {
+		name     string
+		req      validation.CreateOrganizationRequest
+		field    string
+		contains string
+	}{
+		{"missing name", validation.CreateOrganizationRequest{Name: "  "}, "name", "required"},
+		{"long name", validation.CreateOrganizationRequest{Name: strings.Repeat("a", 256)}, "name", "at most 255"},
+		{"negative quota", validation.CreateOrganizationRequest{Name: "acme", MaxDatabases: -1}, "maxDatabases", "0 (no quota) or more"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assertFieldError(t, validation.ValidateCreateOrganizationRequest(tt.req), tt.field, tt.contains)
+		})
+	}
+}
+
+func TestUpdateOrganization(t *testing.T) {
+	t.Parallel()
+	zero, negative := 0, -5
+	assert.Empty(t, validation.ValidateUpdateOrganizationRequest(validation.UpdateOrganizationRequest{}))
+	assert.Empty(t, validation.ValidateUpdateOrganizationRequest(validation.UpdateOrganizationRequest{MaxDatabases: &zero}))
+	assertFieldError(t, validation.ValidateUpdateOrganizationRequest(validation.UpdateOrganizationRequest{MaxDatabases: &negative}),
+		"maxDatabases", "0 (no quota) or more")
+}
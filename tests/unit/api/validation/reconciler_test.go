@@ -0,0 +1,36 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api/validation"
+)
+
+func TestUpdateReconciler_Valid(t *testing.T) {
+	t.Parallel()
+	for _, n := range []int{1, 30, 3600} {
+		assert.Empty(t, validation.ValidateUpdateReconcilerRequest(validation.UpdateReconcilerRequest{IntervalSeconds: &n}))
+	}
+}
+
+func TestUpdateReconciler_Invalid(t *testing.T) {
+	t.Parallel()
+	zero, tooLong := 0, 3601
+	tests := []struct {
+		name     string
+		req      validation.UpdateReconcilerRequest
+		contains string
+	}{
+		{"missing", validation.UpdateReconcilerRequest{}, "required"},
+		{"zero", validation.UpdateReconcilerRequest{IntervalSeconds: &zero}, "between"},
+		{"too long", validation.UpdateReconcilerRequest{IntervalSeconds: &tooLong}, "between"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assertFieldError(t, validation.ValidateUpdateReconcilerRequest(tt.req), "intervalSeconds", tt.contains)
+		})
+	}
+}
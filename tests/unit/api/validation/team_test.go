@@ -0,0 +1,75 @@
+package validation_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api/validation"
+)
+
+func TestCreateTeam_ValidMetadata(t *testing.T) {
+	t.Parallel()
+	req := validation.CreateTeamRequest{
+		Name:        "checkout",
+		Role:        "product",
+		Labels:      map[string]string{"cost-center": "cc-1234", "example.com/tier": ""},
+		Annotations: map[string]string{"finance.example.com/owner": "Checkout team, floor 3"},
+	}
+	assert.Empty(t, validation.ValidateCreateTeamRequest(req))
+}
+
+func TestUpdateTeam_InvalidMetadata(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		req      validation.UpdateTeamRequest
+		field    string
+		contains string
+	}{
+		{"reserved label", validation.UpdateTeamRequest{Labels: map[string]string{"daap.io/database": "x"}}, "labels.daap.io/database", "set by DAAP"},
+		{"invalid label key", validation.UpdateTeamRequest{Labels: map[string]string{"cost center": "x"}}, "labels.cost center", "name part"},
+		{"invalid label value", validation.UpdateTeamRequest{Labels: map[string]string{"owner": "a b"}}, "labels.owner", "valid label"},
+		{"invalid annotation key", validation.UpdateTeamRequest{Annotations: map[string]string{"-bad": "x"}}, "annotations.-bad", "name part"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assertFieldError(t, validation.ValidateUpdateTeamRequest(tt.req), tt.field, tt.contains)
+		})
+	}
+}
+
+func TestTeam_OrganizationID(t *testing.T) {
+	t.Parallel()
+	req := validation.CreateTeamRequest{Name: "checkout", Role: "product", OrganizationID: "a0b1c2d3-e4f5-6789-abcd-ef0123456789"}
+	assert.Empty(t, validation.ValidateCreateTeamRequest(req))
+
+	req.OrganizationID = "acme"
+	assertFieldError(t, validation.ValidateCreateTeamRequest(req), "organizationId", "valid UUID")
+	assertFieldError(t, validation.ValidateUpdateTeamRequest(validation.UpdateTeamRequest{OrganizationID: "acme"}), "organizationId", "valid UUID")
+}
+
+func TestTeam_MaxConnections(t *testing.T) {
+	t.Parallel()
+	budget := 500
+	req := validation.CreateTeamRequest{Name: "checkout", Role: "product", MaxConnections: &budget}
+	assert.Empty(t, validation.ValidateCreateTeamRequest(req))
+
+	negative := -1
+	req.MaxConnections = &negative
+	assertFieldError(t, validation.ValidateCreateTeamRequest(req), "maxConnections", "0 (no budget) or more")
+	assertFieldError(t, validation.ValidateUpdateTeamRequest(validation.UpdateTeamRequest{MaxConnections: &negative}), "maxConnections", "0 (no budget) or more")
+}
+
+func TestTeam_ArchiveLocation(t *testing.T) {
+	t.Parallel()
+	for _, location := range []string{"", "s3://archives/checkout", "gs://archives", "https://acct.blob.core.windows.net/archives"} {
+		req := validation.CreateTeamRequest{Name: "checkout", Role: "product", ArchiveLocation: &location}
+		assert.Empty(t, validation.ValidateCreateTeamRequest(req), location)
+	}
+	for _, location := range []string{"archives/checkout", "file:///var/archives", "s3://"} {
+		req := validation.UpdateTeamRequest{ArchiveLocation: &location}
+		assertFieldError(t, validation.ValidateUpdateTeamRequest(req), "archiveLocation", "object store URL")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/daap14/daap/internal/api/validation"
+	"github.com/daap14/daap/internal/tier"
 )
 
 // --- ValidateCreateTierRequest ---
@@ -165,6 +166,165 @@ func TestUpdateTier_DescriptionTooLong(t *testing.T) {
 	assertHasFieldError(t, errs, "description")
 }
 
+func TestCreateTier_Namespace(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		namespace string
+		wantErr   bool
+	}{
+		{"empty uses default", "", false},
+		{"literal", "db-prod", false},
+		{"template", "db-{{ .Team }}", false},
+		{"uppercase", "DB-Prod", true},
+		{"malformed template", "db-{{ .Team", true},
+		{"unknown field", "db-{{ .Owner }}", true},
+		{"too long", strings.Repeat("a", 256), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := validCreateTierRequest()
+			req.Namespace = tt.namespace
+			errs := validation.ValidateCreateTierRequest(req)
+			if tt.wantErr {
+				assertHasFieldError(t, errs, "namespace")
+			} else {
+				assert.Empty(t, errs)
+			}
+		})
+	}
+}
+
+func TestUpdateTier_InvalidNamespace(t *testing.T) {
+	t.Parallel()
+	val := "db_prod"
+	errs := validation.ValidateUpdateTierRequest(validation.UpdateTierRequest{
+		Namespace: &val,
+	})
+	assertHasFieldError(t, errs, "namespace")
+}
+
+func TestTier_StorageAutoscaling(t *testing.T) {
+	t.Parallel()
+	valid := tier.StorageAutoscaling{Enabled: true, ThresholdPercent: 80, IncrementPercent: 20, MaxSize: "500Gi"}
+	tests := []struct {
+		name      string
+		mutate    func(p *tier.StorageAutoscaling)
+		wantField string
+	}{
+		{"valid", func(*tier.StorageAutoscaling) {}, ""},
+		{"no max size", func(p *tier.StorageAutoscaling) { p.MaxSize = "" }, ""},
+		{"threshold zero", func(p *tier.StorageAutoscaling) { p.ThresholdPercent = 0 }, "storageAutoscaling.thresholdPercent"},
+		{"threshold 100", func(p *tier.StorageAutoscaling) { p.ThresholdPercent = 100 }, "storageAutoscaling.thresholdPercent"},
+		{"increment zero", func(p *tier.StorageAutoscaling) { p.IncrementPercent = 0 }, "storageAutoscaling.incrementPercent"},
+		{"increment too large", func(p *tier.StorageAutoscaling) { p.IncrementPercent = 1001 }, "storageAutoscaling.incrementPercent"},
+		{"max size not a quantity", func(p *tier.StorageAutoscaling) { p.MaxSize = "lots" }, "storageAutoscaling.maxSize"},
+		{"max size negative", func(p *tier.StorageAutoscaling) { p.MaxSize = "-1Gi" }, "storageAutoscaling.maxSize"},
+		{"disabled policy still validated", func(p *tier.StorageAutoscaling) { p.Enabled = false; p.ThresholdPercent = 0 }, "storageAutoscaling.thresholdPercent"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			policy := valid
+			tt.mutate(&policy)
+
+			create := validCreateTierRequest()
+			create.StorageAutoscaling = &policy
+			createErrs := validation.ValidateCreateTierRequest(create)
+			updateErrs := validation.ValidateUpdateTierRequest(validation.UpdateTierRequest{StorageAutoscaling: &policy})
+
+			if tt.wantField == "" {
+				assert.Empty(t, createErrs)
+				assert.Empty(t, updateErrs)
+			} else {
+				assertHasFieldError(t, createErrs, tt.wantField)
+				assertHasFieldError(t, updateErrs, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestTier_Topology(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		topology  tier.Topology
+		wantField string
+	}{
+		{"empty", tier.Topology{}, ""},
+		{"preferred", tier.Topology{ZoneSpread: tier.ZoneSpreadPreferred}, ""},
+		{"required with selector", tier.Topology{ZoneSpread: tier.ZoneSpreadRequired, NodeSelector: map[string]string{"node.example.com/pool": "db"}}, ""},
+		{"unknown spread", tier.Topology{ZoneSpread: "always"}, "topology.zoneSpread"},
+		{"invalid selector key", tier.Topology{NodeSelector: map[string]string{"not a key": "db"}}, "topology.nodeSelector.not a key"},
+		{"invalid selector value", tier.Topology{NodeSelector: map[string]string{"pool": "db pool"}}, "topology.nodeSelector.pool"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			topology := tt.topology
+
+			create := validCreateTierRequest()
+			create.Topology = &topology
+			createErrs := validation.ValidateCreateTierRequest(create)
+			updateErrs := validation.ValidateUpdateTierRequest(validation.UpdateTierRequest{Topology: &topology})
+
+			if tt.wantField == "" {
+				assert.Empty(t, createErrs)
+				assert.Empty(t, updateErrs)
+			} else {
+				assertHasFieldError(t, createErrs, tt.wantField)
+				assertHasFieldError(t, updateErrs, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestTier_Disruption(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		disruption tier.Disruption
+		wantField  string
+	}{
+		{"empty", tier.Disruption{}, ""},
+		{"min available and priority class", tier.Disruption{MinAvailable: 2, PriorityClassName: "db-critical"}, ""},
+		{"negative min available", tier.Disruption{MinAvailable: -1}, "disruption.minAvailable"},
+		{"invalid priority class", tier.Disruption{PriorityClassName: "DB_Critical"}, "disruption.priorityClassName"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			disruption := tt.disruption
+
+			create := validCreateTierRequest()
+			create.Disruption = &disruption
+			createErrs := validation.ValidateCreateTierRequest(create)
+			updateErrs := validation.ValidateUpdateTierRequest(validation.UpdateTierRequest{Disruption: &disruption})
+
+			if tt.wantField == "" {
+				assert.Empty(t, createErrs)
+				assert.Empty(t, updateErrs)
+			} else {
+				assertHasFieldError(t, createErrs, tt.wantField)
+				assertHasFieldError(t, updateErrs, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestTier_DataClassifications(t *testing.T) {
+	t.Parallel()
+	create := validCreateTierRequest()
+	create.DataClassifications = []string{"confidential", "restricted"}
+	assert.Empty(t, validation.ValidateCreateTierRequest(create))
+
+	errs := validation.ValidateUpdateTierRequest(validation.UpdateTierRequest{DataClassifications: []string{"restricted", "secret", "restricted"}})
+	assertFieldError(t, errs, "dataClassifications[1]", "must be one of")
+	assertFieldError(t, errs, "dataClassifications[2]", "listed twice")
+}
+
 // --- Test helpers ---
 
 func assertFieldError(t *testing.T, errs []validation.FieldError, field, contains string) {
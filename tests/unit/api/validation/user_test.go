@@ -0,0 +1,68 @@
+package validation_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/api/validation"
+)
+
+const validTeamID = "b1c2d3e4-f5a6-7890-bcde-f12345678901"
+
+func TestInviteUser_Valid(t *testing.T) {
+	t.Parallel()
+	for _, email := range []string{"alice@example.com", "alice+db@mail.example.co.uk"} {
+		req := validation.InviteUserRequest{Name: "alice", TeamID: validTeamID, Email: email}
+		assert.Empty(t, validation.ValidateInviteUserRequest(req), email)
+	}
+}
+
+func TestInviteUser_Invalid(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		req      validation.InviteUserRequest
+		field    string
+		contains string
+	}{
+		{"name required", validation.InviteUserRequest{TeamID: validTeamID, Email: "a@example.com"}, "name", "required"},
+		{"team invalid", validation.InviteUserRequest{Name: "alice", TeamID: "ops", Email: "a@example.com"}, "teamId", "UUID"},
+		{"email required", validation.InviteUserRequest{Name: "alice", TeamID: validTeamID}, "email", "required"},
+		{"email malformed", validation.InviteUserRequest{Name: "alice", TeamID: validTeamID, Email: "alice"}, "email", "valid"},
+		{"display name", validation.InviteUserRequest{Name: "alice", TeamID: validTeamID, Email: "Alice <a@example.com>"}, "email", "valid"},
+		{"header injection", validation.InviteUserRequest{Name: "alice", TeamID: validTeamID, Email: "a@example.com\r\nBcc: b@example.com"}, "email", "valid"},
+		{"email too long", validation.InviteUserRequest{Name: "alice", TeamID: validTeamID, Email: strings.Repeat("a", 320) + "@example.com"}, "email", "320"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assertFieldError(t, validation.ValidateInviteUserRequest(tt.req), tt.field, tt.contains)
+		})
+	}
+}
+
+func TestCreateUser_Scopes(t *testing.T) {
+	t.Parallel()
+	req := validation.CreateUserRequest{Name: "ci", TeamID: validTeamID, Scopes: []string{"read", "databases:create"}}
+	assert.Empty(t, validation.ValidateCreateUserRequest(req))
+
+	req.Scopes = []string{"databases:write"}
+	assertFieldError(t, validation.ValidateCreateUserRequest(req), "scopes", "unknown verb")
+	req.Scopes = []string{"widgets:read"}
+	assertFieldError(t, validation.ValidateCreateUserRequest(req), "scopes", "unknown resource")
+}
+
+func TestCreateUser_Organization(t *testing.T) {
+	t.Parallel()
+	orgID := "a0b1c2d3-e4f5-6789-abcd-ef0123456789"
+	assert.Empty(t, validation.ValidateCreateUserRequest(validation.CreateUserRequest{Name: "admin", OrganizationID: orgID}))
+
+	errs := validation.ValidateCreateUserRequest(validation.CreateUserRequest{Name: "admin", OrganizationID: orgID, TeamID: validTeamID})
+	assertFieldError(t, errs, "organizationId", "cannot be combined with teamId")
+	errs = validation.ValidateCreateUserRequest(validation.CreateUserRequest{Name: "admin", OrganizationID: "acme"})
+	assertFieldError(t, errs, "organizationId", "valid UUID")
+	errs = validation.ValidateCreateUserRequest(validation.CreateUserRequest{Name: "admin"})
+	assertFieldError(t, errs, "teamId", "required")
+}
@@ -0,0 +1,145 @@
+package archive_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/archive"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type fixture struct {
+	archiver *archive.Archiver
+	repos    *fake.Repositories
+	db       *database.Database
+}
+
+func newFixture(t *testing.T, location string) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	owner := &team.Team{Name: "checkout", Role: "product", ArchiveLocation: location}
+	require.NoError(t, repos.Teams.Create(ctx, owner))
+	db := &database.Database{Name: "orders", OwnerTeamID: owner.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+	return &fixture{archiver: archive.New(repos.Teams, repos.Databases, repos.Archives), repos: repos, db: db}
+}
+
+var archiving = &tier.Tier{Name: "production", DestructionStrategy: tier.DestructionArchive}
+
+func TestArchive_NotRequired(t *testing.T) {
+	f := newFixture(t, "")
+	p := fake.NewProvider()
+	p.ArchiveFn = func(context.Context, provider.ProviderDatabase, string) (provider.Archive, error) {
+		t.Fatal("databases of tiers that do not archive them are not archived")
+		return provider.Archive{}, nil
+	}
+
+	done, err := f.archiver.Archive(context.Background(), f.db, &tier.Tier{DestructionStrategy: tier.DestructionHardDelete}, p, provider.ProviderDatabase{})
+
+	require.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestArchive_NoLocation(t *testing.T) {
+	f := newFixture(t, "")
+
+	done, err := f.archiver.Archive(context.Background(), f.db, archiving, fake.NewProvider(), provider.ProviderDatabase{})
+
+	assert.ErrorIs(t, err, archive.ErrNoLocation)
+	assert.False(t, done)
+}
+
+func TestArchive_WaitsForBackup(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, "s3://archives/checkout")
+	p := fake.NewProvider()
+	running := true
+	p.ArchiveFn = func(_ context.Context, db provider.ProviderDatabase, location string) (provider.Archive, error) {
+		assert.Equal(t, "s3://archives/checkout", location)
+		return provider.Archive{URL: location + "/" + db.Name, Done: !running}, nil
+	}
+	pdb := provider.ProviderDatabase{ID: f.db.ID, Name: f.db.Name}
+
+	done, err := f.archiver.Archive(ctx, f.db, archiving, p, pdb)
+	require.NoError(t, err)
+	assert.False(t, done, "the resources are kept while the backup runs")
+	assert.Nil(t, f.db.ArchiveURL)
+
+	running = false
+	done, err = f.archiver.Archive(ctx, f.db, archiving, p, pdb)
+	require.NoError(t, err)
+	assert.True(t, done)
+	require.NotNil(t, f.db.ArchiveURL)
+	assert.Equal(t, "s3://archives/checkout/orders", *f.db.ArchiveURL)
+
+	stored, err := f.repos.Databases.GetByID(ctx, f.db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.db.ArchiveURL, stored.ArchiveURL, "the archive URL is recorded on the database")
+
+	p.ArchiveFn = func(context.Context, provider.ProviderDatabase, string) (provider.Archive, error) {
+		t.Fatal("an archived database is not archived again")
+		return provider.Archive{}, nil
+	}
+	done, err = f.archiver.Archive(ctx, f.db, archiving, p, pdb)
+	require.NoError(t, err)
+	assert.True(t, done)
+}
+
+func TestArchive_Failed(t *testing.T) {
+	f := newFixture(t, "s3://archives/checkout")
+	p := fake.NewProvider()
+	p.ArchiveFn = func(context.Context, provider.ProviderDatabase, string) (provider.Archive, error) {
+		return provider.Archive{}, errors.New("backup failed")
+	}
+
+	done, err := f.archiver.Archive(context.Background(), f.db, archiving, p, provider.ProviderDatabase{})
+
+	assert.EqualError(t, err, "backup failed")
+	assert.False(t, done)
+}
+
+func TestArchive_NotConfigured(t *testing.T) {
+	var archiver *archive.Archiver
+
+	done, err := archiver.Archive(context.Background(), &database.Database{}, archiving, fake.NewProvider(), provider.ProviderDatabase{})
+
+	assert.Error(t, err, "a database to archive is not deleted without an archiver")
+	assert.False(t, done)
+}
+
+func TestArchived(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, "s3://archives/checkout")
+
+	require.NoError(t, f.repos.Databases.SoftDelete(ctx, f.db.ID))
+	_, err := f.archiver.Archived(ctx, f.db.ID)
+	assert.ErrorIs(t, err, database.ErrNotFound, "a database deleted without an archive is not found")
+
+	other := &database.Database{Name: "payments", OwnerTeamID: f.db.OwnerTeamID, Namespace: "default"}
+	require.NoError(t, f.repos.Databases.Create(ctx, other))
+	url := "s3://archives/checkout/payments"
+	_, err = f.repos.Databases.Update(ctx, other.ID, database.UpdateFields{ArchiveURL: &url})
+	require.NoError(t, err)
+	_, err = f.archiver.Archived(ctx, other.ID)
+	assert.ErrorIs(t, err, database.ErrNotFound, "a database not deleted yet is not found")
+
+	require.NoError(t, f.repos.Databases.SoftDelete(ctx, other.ID))
+	archived, err := f.archiver.Archived(ctx, other.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "deleted", archived.Status)
+	require.NotNil(t, archived.ArchiveURL)
+	assert.Equal(t, url, *archived.ArchiveURL)
+
+	var unconfigured *archive.Archiver
+	_, err = unconfigured.Archived(ctx, other.ID)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+}
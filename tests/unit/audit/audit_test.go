@@ -0,0 +1,152 @@
+package audit_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/audit"
+)
+
+// flakySink fails the first failures writes, then records every batch.
+type flakySink struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	events   []audit.Event
+	block    chan struct{} // when set, Write waits on it
+}
+
+func (s *flakySink) Name() string { return "flaky" }
+
+func (s *flakySink) Write(ctx context.Context, events []audit.Event) error {
+	if s.block != nil {
+		select {
+		case <-s.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attempts++
+	if s.failures > 0 {
+		s.failures--
+		return errors.New("collector unavailable")
+	}
+	s.events = append(s.events, events...)
+	return nil
+}
+
+func (s *flakySink) delivered() []audit.Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]audit.Event(nil), s.events...)
+}
+
+func TestDispatcher_RetriesUntilDelivered(t *testing.T) {
+	sink := &flakySink{failures: 2}
+	d := audit.New([]audit.Sink{sink}, audit.WithRetryBackoff(time.Millisecond, 5*time.Millisecond))
+
+	require.NoError(t, d.Record(context.Background(), audit.Event{Actor: "alice", Method: http.MethodPost, Path: "/databases"}))
+	require.NoError(t, d.Close(context.Background()))
+
+	events := sink.delivered()
+	require.Len(t, events, 1)
+	assert.Equal(t, "alice", events[0].Actor)
+	assert.NotEqual(t, uuid.Nil, events[0].ID)
+	assert.False(t, events[0].Time.IsZero())
+	assert.Equal(t, 3, sink.attempts)
+}
+
+func TestDispatcher_BackpressureWhenQueueFull(t *testing.T) {
+	sink := &flakySink{block: make(chan struct{})}
+	d := audit.New([]audit.Sink{sink}, audit.WithQueueSize(1), audit.WithBatchSize(1), audit.WithEnqueueTimeout(20*time.Millisecond))
+	ctx := context.Background()
+
+	// The first event is picked up by the blocked worker, the second fills the queue.
+	require.NoError(t, d.Record(ctx, audit.Event{Path: "/1"}))
+	require.Eventually(t, func() bool { return !d.Saturated() }, time.Second, time.Millisecond)
+	require.NoError(t, d.Record(ctx, audit.Event{Path: "/2"}))
+	assert.True(t, d.Saturated())
+	assert.ErrorIs(t, d.Record(ctx, audit.Event{Path: "/3"}), audit.ErrBackpressure)
+
+	close(sink.block)
+	require.NoError(t, d.Close(ctx))
+	assert.Len(t, sink.delivered(), 2)
+	assert.False(t, d.Saturated())
+}
+
+func TestDispatcher_CloseGivesUpAfterDeadline(t *testing.T) {
+	sink := &flakySink{failures: 1 << 30}
+	d := audit.New([]audit.Sink{sink}, audit.WithRetryBackoff(time.Millisecond, time.Millisecond))
+	require.NoError(t, d.Record(context.Background(), audit.Event{Path: "/1"}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, d.Close(ctx), context.DeadlineExceeded)
+	assert.Error(t, d.Record(context.Background(), audit.Event{Path: "/2"}))
+}
+
+func TestHTTPSink_PostsBatch(t *testing.T) {
+	var got []audit.Event
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	sink := audit.NewHTTPSink(srv.URL, time.Second)
+	events := []audit.Event{{ID: uuid.New(), Actor: "alice"}, {ID: uuid.New(), Actor: "bob"}}
+	assert.Error(t, sink.Write(context.Background(), events))
+	require.NoError(t, sink.Write(context.Background(), events))
+	require.Len(t, got, 2)
+	assert.Equal(t, "bob", got[1].Actor)
+}
+
+func TestKafkaSink_ProducesThroughRESTProxy(t *testing.T) {
+	var body struct {
+		Records []struct {
+			Key   string      `json:"key"`
+			Value audit.Event `json:"value"`
+		} `json:"records"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/topics/daap-audit", r.URL.Path)
+		assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+	}))
+	defer srv.Close()
+
+	e := audit.Event{ID: uuid.New(), Actor: "alice"}
+	require.NoError(t, audit.NewKafkaSink(srv.URL+"/", "daap-audit", time.Second).Write(context.Background(), []audit.Event{e}))
+	require.Len(t, body.Records, 1)
+	assert.Equal(t, e.ID.String(), body.Records[0].Key)
+	assert.Equal(t, "alice", body.Records[0].Value.Actor)
+}
+
+func TestNewSyslogSink_Addresses(t *testing.T) {
+	for _, addr := range []string{"udp://siem.example.com:514", "tcp://10.0.0.5:601", "unix:///dev/log"} {
+		_, err := audit.NewSyslogSink(addr)
+		assert.NoError(t, err, addr)
+	}
+	for _, addr := range []string{"siem.example.com:514", "udp://", "http://siem.example.com"} {
+		_, err := audit.NewSyslogSink(addr)
+		assert.Error(t, err, addr)
+	}
+}
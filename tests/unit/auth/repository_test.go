@@ -147,6 +147,28 @@ func TestGetByID_NotFound(t *testing.T) {
 	assert.ErrorIs(t, err, auth.ErrUserNotFound)
 }
 
+func TestGetByID_Scopes(t *testing.T) {
+	repo, pool, cleanup := setupUserRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	teamID := createTestTeam(t, pool, "ci", "platform")
+	scoped := newTestUser("ci", &teamID, false)
+	scoped.Scopes = []string{"read", "databases:create"}
+	require.NoError(t, repo.Create(ctx, scoped))
+	unscoped := newTestUser("dave", &teamID, false)
+	unscoped.ApiKeyPrefix = "daap_dav"
+	require.NoError(t, repo.Create(ctx, unscoped))
+
+	found, err := repo.GetByID(ctx, scoped.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"read", "databases:create"}, found.Scopes)
+
+	found, err = repo.GetByID(ctx, unscoped.ID)
+	require.NoError(t, err)
+	assert.Empty(t, found.Scopes)
+}
+
 // --- FindByPrefix Tests ---
 
 func TestFindByPrefix_ReturnsActiveUsers(t *testing.T) {
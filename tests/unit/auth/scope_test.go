@@ -0,0 +1,81 @@
+package auth_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/auth"
+)
+
+func TestValidateScope(t *testing.T) {
+	t.Parallel()
+
+	for _, scope := range []string{"read", "databases:*", "tiers:read", "databases:create", "freezes:delete", "teams:update"} {
+		assert.NoError(t, auth.ValidateScope(scope), scope)
+	}
+	for _, scope := range []string{"", "write", "databases", "widgets:read", "databases:write", "databases:", ":read"} {
+		assert.Error(t, auth.ValidateScope(scope), scope)
+	}
+}
+
+func TestRequestScope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method, path   string
+		resource, verb string
+	}{
+		{http.MethodGet, "/databases", "databases", "read"},
+		{http.MethodHead, "/tiers/abc", "tiers", "read"},
+		{http.MethodOptions, "/databases/abc", "databases", "read"},
+		{http.MethodPost, "/databases", "databases", "create"},
+		{http.MethodPost, "/databases/abc/restart", "databases", "update"},
+		{http.MethodPost, "/databases/abc/promote", "databases", "create"},
+		{http.MethodPost, "/tiers/abc/clone", "tiers", "create"},
+		{http.MethodPost, "/databases/abc/restore", "databases", "create"},
+		{http.MethodPost, "/databases/abc/dependents", "databases", "create"},
+		{http.MethodPost, "/users/invite", "users", "create"},
+		{http.MethodPost, "/databases/abc/ack", "databases", "update"},
+		{http.MethodPatch, "/tiers/abc", "tiers", "update"},
+		{http.MethodPut, "/teams/abc/members", "teams", "update"},
+		{http.MethodDelete, "/databases/abc", "databases", "delete"},
+	}
+	for _, tt := range tests {
+		resource, verb := auth.RequestScope(tt.method, tt.path)
+		assert.Equal(t, tt.resource, resource, "%s %s", tt.method, tt.path)
+		assert.Equal(t, tt.verb, verb, "%s %s", tt.method, tt.path)
+	}
+}
+
+func TestScopesAllow(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		scopes []string
+		method string
+		path   string
+		want   bool
+	}{
+		{"unscoped key", nil, http.MethodDelete, "/databases/abc", true},
+		{"read-only reads", []string{"read"}, http.MethodGet, "/databases/abc", true},
+		{"read-only cannot create", []string{"read"}, http.MethodPost, "/databases", false},
+		{"resource wildcard", []string{"databases:*"}, http.MethodDelete, "/databases/abc", true},
+		{"resource wildcard other resource", []string{"databases:*"}, http.MethodGet, "/tiers", false},
+		{"create-only creates", []string{"databases:create"}, http.MethodPost, "/databases", true},
+		{"create-only cannot act", []string{"databases:create"}, http.MethodPost, "/databases/abc/restart", false},
+		{"create-only promotes", []string{"databases:create"}, http.MethodPost, "/databases/abc/promote", true},
+		{"update-only cannot restore", []string{"databases:update"}, http.MethodPost, "/databases/abc/restore", false},
+		{"update-only cannot clone", []string{"tiers:update"}, http.MethodPost, "/tiers/abc/clone", false},
+		{"one of several", []string{"tiers:read", "databases:update"}, http.MethodPatch, "/databases/abc", true},
+		{"me is always allowed", []string{"tiers:read"}, http.MethodGet, "/me", true},
+		{"auth check is always allowed", []string{"tiers:read"}, http.MethodPost, "/auth/check", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, auth.ScopesAllow(tt.scopes, tt.method, tt.path))
+		})
+	}
+}
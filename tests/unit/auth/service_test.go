@@ -93,6 +93,7 @@ func TestAuthenticate_ValidKey(t *testing.T) {
 	assert.Equal(t, "authteam", *identity.TeamName)
 	assert.Equal(t, "platform", *identity.Role)
 	assert.False(t, identity.IsSuperuser)
+	assert.Equal(t, prefix, identity.KeyPrefix)
 }
 
 func TestAuthenticate_SuperuserKey(t *testing.T) {
@@ -0,0 +1,264 @@
+package autoscale_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/autoscale"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/metrics"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+const gib = int64(1 << 30)
+
+// scalingProvider is a fake provider that reports a fixed storage usage and
+// records resize requests.
+type scalingProvider struct {
+	*fake.Provider
+
+	mu        sync.Mutex
+	usage     provider.StorageUsage
+	usageErr  error
+	resizeErr error
+	resizes   []int64
+}
+
+func (p *scalingProvider) StorageUsage(_ context.Context, _ provider.ProviderDatabase) (provider.StorageUsage, error) {
+	return p.usage, p.usageErr
+}
+
+func (p *scalingProvider) ResizeStorage(_ context.Context, _ provider.ProviderDatabase, sizeBytes int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.resizes = append(p.resizes, sizeBytes)
+	return p.resizeErr
+}
+
+func (p *scalingProvider) getResizes() []int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]int64(nil), p.resizes...)
+}
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *recordingNotifier) get() []notify.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notify.Notification(nil), n.notifications...)
+}
+
+// metricValue reads a sample from the Default metrics registry.
+func metricValue(t *testing.T, name string) float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	metrics.Default.WriteText(&buf)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if v, ok := strings.CutPrefix(line, name+" "); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			require.NoError(t, err)
+			return f
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
+
+type fixture struct {
+	repos    *fake.Repositories
+	db       *database.Database
+	provider *scalingProvider
+	notifier *recordingNotifier
+}
+
+// setup seeds a ready database on a tier with the given policy, backed by a
+// provider reporting usage.
+func setup(t *testing.T, policy tier.StorageAutoscaling, usage provider.StorageUsage) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	bp := &blueprint.Blueprint{Name: "scalable", Provider: "scalable", Manifests: "kind: Cluster"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	tr := &tier.Tier{Name: "standard", BlueprintID: &bp.ID, StorageAutoscaling: policy}
+	require.NoError(t, repos.Tiers.Create(ctx, tr))
+	db := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID, Namespace: "db"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+	_, err := repos.Databases.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+
+	return &fixture{
+		repos:    repos,
+		db:       db,
+		provider: &scalingProvider{Provider: fake.NewProvider(), usage: usage},
+		notifier: &recordingNotifier{},
+	}
+}
+
+func (f *fixture) run(t *testing.T, passes int) {
+	t.Helper()
+	registry := provider.NewRegistry()
+	registry.Register("scalable", f.provider)
+	c := autoscale.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, registry, f.repos.ResizeEvents,
+		time.Minute, autoscale.WithNotifier(f.notifier))
+	for range passes {
+		c.RunOnce(context.Background())
+	}
+}
+
+func (f *fixture) events(t *testing.T) []database.ResizeEvent {
+	t.Helper()
+	events, err := f.repos.ResizeEvents.ListByDatabase(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	return events
+}
+
+func enabledPolicy(maxSize string) tier.StorageAutoscaling {
+	return tier.StorageAutoscaling{Enabled: true, ThresholdPercent: 80, IncrementPercent: 20, MaxSize: maxSize}
+}
+
+func TestCollector_BelowThreshold_NoResize(t *testing.T) {
+	f := setup(t, enabledPolicy(""), provider.StorageUsage{CapacityBytes: 10 * gib, UsedBytes: 7 * gib})
+
+	f.run(t, 1)
+
+	assert.Empty(t, f.provider.getResizes())
+	assert.Empty(t, f.events(t))
+}
+
+func TestCollector_OverThreshold_ResizesAndRecordsEvent(t *testing.T) {
+	before := metricValue(t, "daap_database_storage_resizes_total")
+	f := setup(t, enabledPolicy(""), provider.StorageUsage{CapacityBytes: 10 * gib, UsedBytes: 8 * gib})
+
+	f.run(t, 1)
+
+	assert.Equal(t, []int64{12 * gib}, f.provider.getResizes())
+	events := f.events(t)
+	require.Len(t, events, 1)
+	assert.Equal(t, f.db.ID, events[0].DatabaseID)
+	assert.Equal(t, 10*gib, events[0].FromBytes)
+	assert.Equal(t, 12*gib, events[0].ToBytes)
+	assert.Equal(t, 8*gib, events[0].UsedBytes)
+	assert.False(t, events[0].CreatedAt.IsZero())
+	assert.Equal(t, before+1, metricValue(t, "daap_database_storage_resizes_total"))
+}
+
+func TestCollector_CapsAtMaxSize(t *testing.T) {
+	f := setup(t, enabledPolicy("11Gi"), provider.StorageUsage{CapacityBytes: 10 * gib, UsedBytes: 9 * gib})
+
+	f.run(t, 1)
+
+	assert.Equal(t, []int64{11 * gib}, f.provider.getResizes())
+	assert.Empty(t, f.notifier.get())
+}
+
+func TestCollector_AtMaxSize_NotifiesOnce(t *testing.T) {
+	f := setup(t, enabledPolicy("10Gi"), provider.StorageUsage{CapacityBytes: 10 * gib, UsedBytes: 9 * gib})
+
+	f.run(t, 2)
+
+	assert.Empty(t, f.provider.getResizes())
+	assert.Empty(t, f.events(t))
+	notifications := f.notifier.get()
+	require.Len(t, notifications, 1)
+	assert.Equal(t, "StorageLimitReached", notifications[0].Event)
+	assert.Equal(t, f.db.ID, notifications[0].DatabaseID)
+	assert.Equal(t, "checkout", notifications[0].OwnerTeam)
+	assert.Contains(t, notifications[0].Message, "90%")
+}
+
+func TestCollector_PolicyDisabled_Skipped(t *testing.T) {
+	f := setup(t, tier.StorageAutoscaling{ThresholdPercent: 80, IncrementPercent: 20},
+		provider.StorageUsage{CapacityBytes: 10 * gib, UsedBytes: 10 * gib})
+
+	f.run(t, 1)
+
+	assert.Empty(t, f.provider.getResizes())
+}
+
+func TestCollector_ReconciliationPaused_Skipped(t *testing.T) {
+	f := setup(t, enabledPolicy(""), provider.StorageUsage{CapacityBytes: 10 * gib, UsedBytes: 9 * gib})
+	pause := &database.ReconciliationPause{By: "alice", Until: time.Now().Add(time.Hour)}
+	_, err := f.repos.Databases.Update(context.Background(), f.db.ID, database.UpdateFields{ReconciliationPause: pause})
+	require.NoError(t, err)
+
+	f.run(t, 1)
+
+	assert.Empty(t, f.provider.getResizes())
+}
+
+func TestCollector_UsageUnavailable_NoResize(t *testing.T) {
+	for name, err := range map[string]error{
+		"not supported": provider.ErrNotSupported,
+		"usage failure": errors.New("kubelet unreachable"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			f := setup(t, enabledPolicy(""), provider.StorageUsage{})
+			f.provider.usageErr = err
+
+			f.run(t, 1)
+
+			assert.Empty(t, f.provider.getResizes())
+		})
+	}
+}
+
+func TestCollector_ResizeFailure_NoEvent(t *testing.T) {
+	before := metricValue(t, "daap_database_storage_resize_failures_total")
+	f := setup(t, enabledPolicy(""), provider.StorageUsage{CapacityBytes: 10 * gib, UsedBytes: 9 * gib})
+	f.provider.resizeErr = errors.New("storage class does not allow expansion")
+
+	f.run(t, 1)
+
+	assert.Len(t, f.provider.getResizes(), 1)
+	assert.Empty(t, f.events(t))
+	assert.Equal(t, before+1, metricValue(t, "daap_database_storage_resize_failures_total"))
+}
+
+func TestNextSize(t *testing.T) {
+	tests := []struct {
+		name      string
+		capacity  int64
+		increment int
+		max       int64
+		want      int64
+	}{
+		{"grows by increment", 10 * gib, 20, 0, 12 * gib},
+		{"rounds up to a GiB", 1 * gib, 20, 0, 2 * gib},
+		{"doubles", 50 * gib, 100, 0, 100 * gib},
+		{"capped at max", 10 * gib, 50, 12 * gib, 12 * gib},
+		{"already at max", 12 * gib, 50, 12 * gib, 12 * gib},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, autoscale.NextSize(tt.capacity, tt.increment, tt.max))
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package blueprint_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+)
+
+func TestCompressManifests_RoundTrip(t *testing.T) {
+	manifests := strings.Repeat(lintManifests+"---\n", 50)
+
+	compressed, err := blueprint.CompressManifests(manifests)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(manifests)/10)
+
+	got, err := blueprint.DecompressManifests("", compressed)
+	require.NoError(t, err)
+	assert.Equal(t, manifests, got)
+}
+
+func TestDecompressManifests_UncompressedRow(t *testing.T) {
+	got, err := blueprint.DecompressManifests(lintManifests, nil)
+	require.NoError(t, err)
+	assert.Equal(t, lintManifests, got)
+}
+
+func TestDecompressManifests_Corrupt(t *testing.T) {
+	_, err := blueprint.DecompressManifests("", []byte("not gzip"))
+	assert.Error(t, err)
+}
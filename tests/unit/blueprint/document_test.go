@@ -0,0 +1,39 @@
+package blueprint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/blueprint"
+)
+
+func TestParseDocuments(t *testing.T) {
+	manifests := `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: {{ .ClusterName }}
+  namespace: "{{ .Namespace }}"
+spec:
+  instances: 3
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Pooler
+metadata:
+  name: daap-{{ .Name }}-pooler
+---
+{{ if .Secrets.license }}
+apiVersion: v1
+kind: Secret
+{{ end }}`
+
+	assert.Equal(t, []blueprint.Document{
+		{Index: 0, APIVersion: "postgresql.cnpg.io/v1", Kind: "Cluster", Name: "{{ .ClusterName }}", Namespace: "{{ .Namespace }}"},
+		{Index: 1, APIVersion: "postgresql.cnpg.io/v1", Kind: "Pooler", Name: "daap-{{ .Name }}-pooler"},
+		{Index: 2},
+	}, blueprint.ParseDocuments(manifests))
+}
+
+func TestParseDocuments_Empty(t *testing.T) {
+	assert.Equal(t, []blueprint.Document{}, blueprint.ParseDocuments("---\n"))
+}
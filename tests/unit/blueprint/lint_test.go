@@ -0,0 +1,122 @@
+package blueprint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+)
+
+const lintManifests = `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: daap-{{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    team: "{{ .OwnerTeam }}"
+spec:
+  instances: 3
+  imageName: ghcr.io/cloudnative-pg/postgresql:latest
+  resources:
+    requests:
+      cpu: "1"
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Pooler
+metadata:
+  name: daap-{{ .Name }}-pooler
+spec:
+  template:
+    spec:
+      containers:
+        - name: pgbouncer
+          image: ghcr.io/cloudnative-pg/pgbouncer
+          resources:
+            requests:
+              cpu: 100m
+              memory: 64Mi
+        - name: exporter
+          image: "{{ .Secrets.exporterImage }}"
+`
+
+func TestLint(t *testing.T) {
+	findings := blueprint.Lint(lintManifests, blueprint.LintConfig{RequiredLabels: []string{"team"}})
+
+	var got []string
+	for _, f := range findings {
+		assert.Equal(t, blueprint.SeverityWarning, f.Severity)
+		got = append(got, f.String())
+	}
+	assert.Equal(t, []string{
+		"[resource-requests] document 0: Cluster: spec.resources.requests.memory is not set",
+		"[no-latest-tag] document 0: Cluster: image ghcr.io/cloudnative-pg/postgresql:latest must be pinned to a tag other than latest or a digest",
+		"[resource-requests] document 1: Pooler: spec.template.spec.containers[1].resources.requests.cpu is not set",
+		"[resource-requests] document 1: Pooler: spec.template.spec.containers[1].resources.requests.memory is not set",
+		"[no-latest-tag] document 1: Pooler: image ghcr.io/cloudnative-pg/pgbouncer must be pinned to a tag other than latest or a digest",
+		"[required-labels] document 1: Pooler: metadata.labels is missing team",
+	}, got)
+}
+
+func TestLint_Severities(t *testing.T) {
+	findings := blueprint.Lint(lintManifests, blueprint.LintConfig{Severities: map[string]blueprint.Severity{
+		blueprint.RuleResourceRequests: blueprint.SeverityOff,
+		blueprint.RuleNoLatestTag:      blueprint.SeverityError,
+	}})
+
+	require.Len(t, findings, 2)
+	for _, f := range findings {
+		assert.Equal(t, blueprint.RuleNoLatestTag, f.Rule)
+		assert.Equal(t, blueprint.SeverityError, f.Severity)
+	}
+}
+
+func TestLint_PinnedImages(t *testing.T) {
+	manifests := `apiVersion: v1
+kind: Pod
+metadata:
+  name: x
+spec:
+  containers:
+    - image: registry.example.com:5000/postgres:16.4
+    - image: postgres@sha256:0123456789abcdef
+`
+	assert.Empty(t, blueprint.Lint(manifests, blueprint.LintConfig{}))
+}
+
+func TestImages(t *testing.T) {
+	manifests := `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: x
+spec:
+  imageName: ghcr.io/cloudnative-pg/postgresql:16
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: y
+spec:
+  containers:
+    - image: postgres:16
+    - image: ghcr.io/cloudnative-pg/postgresql:16
+    - image: "{{ .Image }}"
+`
+	assert.Equal(t, []string{"ghcr.io/cloudnative-pg/postgresql:16", "postgres:16"}, blueprint.Images(manifests))
+}
+
+func TestParseSeverities(t *testing.T) {
+	got, err := blueprint.ParseSeverities(map[string]string{"no-latest-tag": "error", "required-labels": "off"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]blueprint.Severity{
+		blueprint.RuleNoLatestTag:    blueprint.SeverityError,
+		blueprint.RuleRequiredLabels: blueprint.SeverityOff,
+	}, got)
+
+	_, err = blueprint.ParseSeverities(map[string]string{"no-root": "error"})
+	assert.ErrorContains(t, err, "unknown lint rule")
+
+	_, err = blueprint.ParseSeverities(map[string]string{"no-latest-tag": "fatal"})
+	assert.ErrorContains(t, err, "unknown severity")
+}
@@ -0,0 +1,30 @@
+package blueprint_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/blueprint"
+)
+
+func TestBlueprint_VisibleTo(t *testing.T) {
+	team, otherTeam := uuid.New(), uuid.New()
+	org, otherOrg := uuid.New(), uuid.New()
+
+	global := &blueprint.Blueprint{}
+	assert.True(t, global.VisibleTo(nil, nil))
+	assert.True(t, global.VisibleTo(&team, &org))
+
+	teamScoped := &blueprint.Blueprint{TeamID: &team}
+	assert.True(t, teamScoped.VisibleTo(&team, nil))
+	assert.False(t, teamScoped.VisibleTo(&otherTeam, &org))
+	assert.False(t, teamScoped.VisibleTo(nil, &org))
+
+	orgScoped := &blueprint.Blueprint{OrganizationID: &org}
+	assert.True(t, orgScoped.VisibleTo(&otherTeam, &org))
+	assert.True(t, orgScoped.VisibleTo(nil, &org), "organization superadmins")
+	assert.False(t, orgScoped.VisibleTo(&team, &otherOrg))
+	assert.False(t, orgScoped.VisibleTo(&team, nil))
+}
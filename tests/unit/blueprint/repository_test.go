@@ -109,6 +109,31 @@ func TestGetByID_Success(t *testing.T) {
 	assert.Equal(t, "get-by-id", found.Name)
 	assert.Equal(t, "cnpg", found.Provider)
 	assert.Equal(t, bp.Manifests, found.Manifests)
+	assert.Equal(t, []blueprint.Document{{
+		Index: 0, APIVersion: "postgresql.cnpg.io/v1", Kind: "Cluster",
+		Name: "daap-{{ .Name }}", Namespace: "{{ .Namespace }}",
+	}}, found.Documents)
+}
+
+func TestGetByID_RowSavedBeforeDocuments(t *testing.T) {
+	repo, pool, cleanup := setupBlueprintRepo(t)
+	defer cleanup()
+
+	// Written as by earlier releases: uncompressed, without documents.
+	ctx := context.Background()
+	manifests := newTestBlueprint("legacy").Manifests
+	var id uuid.UUID
+	err := pool.QueryRow(ctx, `
+		INSERT INTO blueprints (name, provider, manifests)
+		VALUES ('legacy', 'cnpg', $1)
+		RETURNING id`, manifests).Scan(&id)
+	require.NoError(t, err)
+
+	found, err := repo.GetByID(ctx, id)
+	require.NoError(t, err)
+	assert.Equal(t, manifests, found.Manifests)
+	require.Len(t, found.Documents, 1)
+	assert.Equal(t, "Cluster", found.Documents[0].Kind)
 }
 
 func TestGetByID_NotFound(t *testing.T) {
@@ -0,0 +1,45 @@
+package blueprint_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/blueprint"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	signer := blueprint.NewSigner([]byte("s3cret"))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster"}
+	assert.ErrorIs(t, signer.Verify(bp), blueprint.ErrUnsigned)
+
+	bp.Signature = signer.Sign(bp)
+	assert.Regexp(t, `^hmac-sha256:[0-9a-f]{64}$`, bp.Signature)
+	assert.NoError(t, signer.Verify(bp))
+
+	tampered := *bp
+	tampered.Manifests = "kind: Cluster\nspec:\n  instances: 1"
+	assert.ErrorIs(t, signer.Verify(&tampered), blueprint.ErrSignatureMismatch)
+	renamed := *bp
+	renamed.Name = "cnpg-other"
+	assert.ErrorIs(t, signer.Verify(&renamed), blueprint.ErrSignatureMismatch, "the signature covers the name")
+
+	assert.ErrorIs(t, blueprint.NewSigner([]byte("other")).Verify(bp), blueprint.ErrSignatureMismatch)
+}
+
+func TestSigner_Disabled(t *testing.T) {
+	signer := blueprint.NewSigner(nil)
+	assert.Nil(t, signer)
+
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster"}
+	assert.Empty(t, signer.Sign(bp))
+	assert.NoError(t, signer.Verify(bp), "a nil signer verifies unsigned blueprints")
+}
+
+func TestContentHash(t *testing.T) {
+	a := &blueprint.Blueprint{Name: "ab", Provider: "c", Manifests: "kind: Cluster"}
+	b := &blueprint.Blueprint{Name: "a", Provider: "bc", Manifests: "kind: Cluster"}
+	assert.Regexp(t, `^sha256:[0-9a-f]{64}$`, blueprint.ContentHash(a))
+	assert.NotEqual(t, blueprint.ContentHash(a), blueprint.ContentHash(b), "fields are delimited")
+	assert.Equal(t, blueprint.ContentHash(a), blueprint.ContentHash(&blueprint.Blueprint{Name: "ab", Provider: "c", Manifests: "kind: Cluster", Description: "x"}))
+}
@@ -0,0 +1,274 @@
+package breaker_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/breaker"
+	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+var errBoom = errors.New("boom")
+
+func fail() error { return errBoom }
+func ok() error   { return nil }
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	b := breaker.New(breaker.Config{Name: "test", FailureThreshold: 3, Cooldown: time.Hour})
+
+	for range 2 {
+		assert.ErrorIs(t, b.Do(fail), errBoom)
+	}
+	assert.Equal(t, breaker.Closed, b.State())
+
+	assert.ErrorIs(t, b.Do(fail), errBoom)
+	assert.Equal(t, breaker.Open, b.State())
+
+	called := false
+	err := b.Do(func() error { called = true; return nil })
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+	assert.Contains(t, err.Error(), "test")
+	assert.False(t, called, "open breaker must not call through")
+}
+
+func TestBreaker_SuccessResetsFailureCount(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 2, Cooldown: time.Hour})
+
+	_ = b.Do(fail)
+	require.NoError(t, b.Do(ok))
+	_ = b.Do(fail)
+	assert.Equal(t, breaker.Closed, b.State())
+}
+
+func TestBreaker_HalfOpenAllowsSingleProbe(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: 10 * time.Millisecond})
+	_ = b.Do(fail)
+	require.Equal(t, breaker.Open, b.State())
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, breaker.HalfOpen, b.State())
+
+	require.NoError(t, b.Allow(), "first call after cooldown is the probe")
+	assert.ErrorIs(t, b.Allow(), breaker.ErrOpen, "concurrent calls are rejected while probing")
+
+	b.Success()
+	assert.Equal(t, breaker.Closed, b.State())
+	assert.NoError(t, b.Allow())
+}
+
+func TestBreaker_FailedProbeReopens(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 5, Cooldown: 10 * time.Millisecond})
+	for range 5 {
+		_ = b.Do(fail)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	assert.ErrorIs(t, b.Do(fail), errBoom)
+	assert.Equal(t, breaker.Open, b.State(), "a single failed probe reopens the breaker")
+	assert.ErrorIs(t, b.Do(ok), breaker.ErrOpen)
+}
+
+func TestBreaker_IsFailureFiltersErrors(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: time.Hour, IsFailure: k8s.IsTransient})
+
+	assert.ErrorIs(t, b.Do(fail), errBoom)
+	assert.Equal(t, breaker.Closed, b.State(), "permanent errors do not trip the breaker")
+
+	_ = b.Do(func() error { return context.DeadlineExceeded })
+	assert.Equal(t, breaker.Open, b.State())
+}
+
+func TestBreaker_CanceledContextIsNotAFailure(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: time.Hour})
+
+	_ = b.Do(func() error { return context.Canceled })
+	assert.Equal(t, breaker.Closed, b.State())
+}
+
+func TestWrapProvider_FailsFastWhenOpen(t *testing.T) {
+	fp := fake.NewProvider()
+	fp.CheckHealthFn = func(context.Context, provider.ProviderDatabase) (provider.HealthResult, error) {
+		return provider.HealthResult{}, context.DeadlineExceeded
+	}
+	b := breaker.New(breaker.Config{FailureThreshold: 2, Cooldown: time.Hour, IsFailure: k8s.IsTransient})
+	p := breaker.WrapProvider(fp, b)
+
+	db := provider.ProviderDatabase{Name: "orders"}
+	for range 2 {
+		_, err := p.CheckHealth(context.Background(), db)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	}
+
+	assert.ErrorIs(t, p.Apply(context.Background(), db, "kind: Cluster"), breaker.ErrOpen)
+	assert.ErrorIs(t, p.Delete(context.Background(), db), breaker.ErrOpen)
+	assert.Len(t, fp.CheckHealthCalls(), 2)
+	assert.Empty(t, fp.ApplyCalls())
+	assert.Empty(t, fp.DeleteCalls())
+}
+
+type scalingProvider struct {
+	*fake.Provider
+	usage provider.StorageUsage
+	err   error
+}
+
+func (p *scalingProvider) StorageUsage(context.Context, provider.ProviderDatabase) (provider.StorageUsage, error) {
+	return p.usage, p.err
+}
+
+func (p *scalingProvider) ResizeStorage(context.Context, provider.ProviderDatabase, int64) error {
+	return p.err
+}
+
+func TestWrapProvider_StorageScaler(t *testing.T) {
+	db := provider.ProviderDatabase{Name: "orders"}
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: time.Hour, IsFailure: k8s.IsTransient})
+
+	plain := breaker.WrapProvider(fake.NewProvider(), b)
+	_, err := plain.StorageUsage(context.Background(), db)
+	assert.ErrorIs(t, err, provider.ErrNotSupported)
+	assert.ErrorIs(t, plain.ResizeStorage(context.Background(), db, 1), provider.ErrNotSupported)
+
+	sp := &scalingProvider{Provider: fake.NewProvider(), usage: provider.StorageUsage{CapacityBytes: 10, UsedBytes: 9}}
+	scaling := breaker.WrapProvider(sp, b)
+	usage, err := scaling.StorageUsage(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, sp.usage, usage)
+
+	sp.err = context.DeadlineExceeded
+	assert.ErrorIs(t, scaling.ResizeStorage(context.Background(), db, 20), context.DeadlineExceeded)
+	_, err = scaling.StorageUsage(context.Background(), db)
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+}
+
+type computeProvider struct {
+	*fake.Provider
+	usage provider.ComputeUsage
+	err   error
+}
+
+func (p *computeProvider) ComputeUsage(context.Context, provider.ProviderDatabase) (provider.ComputeUsage, error) {
+	return p.usage, p.err
+}
+
+func (p *computeProvider) ManifestCompute(string) (provider.ComputeResources, error) {
+	return provider.ComputeResources{CPUMillis: 1000}, nil
+}
+
+func TestWrapProvider_ComputeReporter(t *testing.T) {
+	db := provider.ProviderDatabase{Name: "orders"}
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: time.Hour, IsFailure: k8s.IsTransient})
+
+	plain := breaker.WrapProvider(fake.NewProvider(), b)
+	_, err := plain.ComputeUsage(context.Background(), db)
+	assert.ErrorIs(t, err, provider.ErrNotSupported)
+	_, err = plain.ManifestCompute("kind: Cluster")
+	assert.ErrorIs(t, err, provider.ErrNotSupported)
+
+	cp := &computeProvider{Provider: fake.NewProvider(), usage: provider.ComputeUsage{Used: provider.ComputeResources{CPUMillis: 500}}}
+	reporting := breaker.WrapProvider(cp, b)
+	usage, err := reporting.ComputeUsage(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, cp.usage, usage)
+
+	cp.err = context.DeadlineExceeded
+	_, err = reporting.ComputeUsage(context.Background(), db)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = reporting.ComputeUsage(context.Background(), db)
+	assert.ErrorIs(t, err, breaker.ErrOpen)
+
+	// ManifestCompute does not call the API server, so an open breaker does
+	// not block it.
+	compute, err := reporting.ManifestCompute("kind: Cluster")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), compute.CPUMillis)
+}
+
+type mockChecker struct {
+	calls  int
+	status k8s.ConnectivityStatus
+}
+
+func (m *mockChecker) CheckConnectivity(context.Context) k8s.ConnectivityStatus {
+	m.calls++
+	return m.status
+}
+
+func TestWrapHealthChecker_TripsOnDisconnect(t *testing.T) {
+	mc := &mockChecker{status: k8s.ConnectivityStatus{Connected: false}}
+	b := breaker.New(breaker.Config{FailureThreshold: 2, Cooldown: time.Hour})
+	hc := breaker.WrapHealthChecker(mc, b)
+
+	for range 3 {
+		assert.False(t, hc.CheckConnectivity(context.Background()).Connected)
+	}
+	assert.Equal(t, 2, mc.calls, "open breaker reports disconnected without contacting the API server")
+	assert.Equal(t, breaker.Open, b.State())
+}
+
+func TestWrapHealthChecker_PassesThroughWhenConnected(t *testing.T) {
+	mc := &mockChecker{status: k8s.ConnectivityStatus{Connected: true, Version: "v1.30.0"}}
+	hc := breaker.WrapHealthChecker(mc, breaker.New(breaker.Config{}))
+
+	status := hc.CheckConnectivity(context.Background())
+	assert.True(t, status.Connected)
+	assert.Equal(t, "v1.30.0", status.Version)
+}
+
+func TestWrapProvider_DeletionConfirmer(t *testing.T) {
+	db := provider.ProviderDatabase{Name: "orders"}
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: time.Hour, IsFailure: k8s.IsTransient})
+
+	// Embedding the interface hides the fake's DeleteForeground.
+	fp := fake.NewProvider()
+	plain := breaker.WrapProvider(struct{ provider.Provider }{fp}, b)
+	_, err := plain.DeleteForeground(context.Background(), db, 0)
+	assert.ErrorIs(t, err, provider.ErrNotSupported)
+	state, err := provider.ConfirmDeletion(context.Background(), plain, db, 0)
+	require.NoError(t, err)
+	assert.Equal(t, provider.DeletionGone, state, "Delete is assumed to remove everything")
+	assert.Len(t, fp.DeleteCalls(), 1)
+
+	cp := fake.NewProvider()
+	cp.DeleteForegroundFn = func(context.Context, provider.ProviderDatabase, time.Duration) (provider.DeletionState, error) {
+		return provider.DeletionDeleting, nil
+	}
+	state, err = provider.ConfirmDeletion(context.Background(), breaker.WrapProvider(cp, b), db, time.Second)
+	require.NoError(t, err)
+	assert.Equal(t, provider.DeletionDeleting, state)
+}
+
+func TestWrapProvider_PrimarySwitcher(t *testing.T) {
+	db := provider.ProviderDatabase{Name: "orders"}
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: time.Hour, IsFailure: k8s.IsTransient})
+
+	plain := breaker.WrapProvider(struct{ provider.Provider }{fake.NewProvider()}, b)
+	_, err := plain.Switchover(context.Background(), db, "orders-2")
+	assert.ErrorIs(t, err, provider.ErrNotSupported)
+
+	fp := fake.NewProvider()
+	promoted, err := breaker.WrapProvider(fp, b).Switchover(context.Background(), db, "orders-2")
+	require.NoError(t, err)
+	assert.Equal(t, "orders-2", promoted)
+	assert.Len(t, fp.SwitchoverCalls(), 1)
+}
+
+func TestWrapProvider_SupportsWhatTheWrappedProviderDoes(t *testing.T) {
+	b := breaker.New(breaker.Config{FailureThreshold: 1, Cooldown: time.Hour, IsFailure: k8s.IsTransient})
+
+	// The wrapper implements every optional interface, whatever it wraps.
+	plain := breaker.WrapProvider(fake.NewProvider(), b)
+	var _ provider.StorageScaler = plain
+	assert.False(t, provider.Supports[provider.StorageScaler](plain))
+	assert.True(t, provider.Supports[provider.Archiver](plain))
+
+	scaling := breaker.WrapProvider(&scalingProvider{Provider: fake.NewProvider()}, b)
+	assert.True(t, provider.Supports[provider.StorageScaler](scaling))
+}
@@ -0,0 +1,112 @@
+package budget_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/budget"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// connectionsProvider reports the connections of blueprint manifests from a
+// fixed table.
+type connectionsProvider struct {
+	*fake.Provider
+	connections map[string]int
+}
+
+func (p *connectionsProvider) ManifestConnections(manifests string) (int, error) {
+	n, ok := p.connections[manifests]
+	if !ok {
+		return 0, provider.ErrNotSupported
+	}
+	return n, nil
+}
+
+type fixture struct {
+	repos   *fake.Repositories
+	budgets *budget.Budgets
+	team    *team.Team
+	tiers   map[string]*tier.Tier
+}
+
+// setup seeds a team with a budget of 500 connections and tiers of 100
+// (small), 200 (large) and unknown (legacy) connections.
+func setup(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	p := &connectionsProvider{Provider: fake.NewProvider(), connections: map[string]int{
+		"kind: Cluster # small": 100,
+		"kind: Cluster # large": 200,
+	}}
+	registry := provider.NewRegistry()
+	registry.Register("sized", p)
+
+	f := &fixture{repos: repos, tiers: map[string]*tier.Tier{}}
+	for _, name := range []string{"small", "large", "legacy"} {
+		bp := &blueprint.Blueprint{Name: "cnpg-" + name, Provider: "sized", Manifests: "kind: Cluster # " + name}
+		require.NoError(t, repos.Blueprints.Create(ctx, bp))
+		tr := &tier.Tier{Name: name, BlueprintID: &bp.ID}
+		require.NoError(t, repos.Tiers.Create(ctx, tr))
+		f.tiers[name] = tr
+	}
+	f.team = &team.Team{Name: "checkout", Role: "product", MaxConnections: 500}
+	require.NoError(t, repos.Teams.Create(ctx, f.team))
+	f.budgets = budget.New(repos.Teams, repos.Databases, repos.Tiers, repos.Blueprints, registry)
+	return f
+}
+
+func (f *fixture) createDatabase(t *testing.T, name, tierName string, owner *team.Team) *database.Database {
+	t.Helper()
+	db := &database.Database{Name: name, OwnerTeamID: owner.ID, TierID: &f.tiers[tierName].ID, Namespace: "db"}
+	require.NoError(t, f.repos.Databases.Create(context.Background(), db))
+	return db
+}
+
+func TestCheckConnections(t *testing.T) {
+	ctx := context.Background()
+	f := setup(t)
+	f.createDatabase(t, "orders", "large", f.team)
+	f.createDatabase(t, "carts", "small", f.team)
+	f.createDatabase(t, "sessions", "legacy", f.team)
+	other := &team.Team{Name: "search", Role: "product"}
+	require.NoError(t, f.repos.Teams.Create(ctx, other))
+	f.createDatabase(t, "index", "large", other)
+
+	assert.NoError(t, f.budgets.CheckConnections(ctx, f.team.ID, &f.tiers["large"].ID, uuid.Nil),
+		"300 used and 200 more reach the budget")
+	assert.NoError(t, f.budgets.CheckConnections(ctx, f.team.ID, &f.tiers["legacy"].ID, uuid.Nil),
+		"tiers whose provider cannot report connections do not count")
+
+	f.createDatabase(t, "payments", "small", f.team)
+	err := f.budgets.CheckConnections(ctx, f.team.ID, &f.tiers["large"].ID, uuid.Nil)
+	require.ErrorIs(t, err, budget.ErrExceeded)
+	assert.Contains(t, err.Error(), "team checkout uses 400 of 500 connections and the database needs 200")
+
+	assert.NoError(t, f.budgets.CheckConnections(ctx, other.ID, &f.tiers["large"].ID, uuid.Nil),
+		"a team without a budget")
+}
+
+func TestCheckConnections_Replacing(t *testing.T) {
+	ctx := context.Background()
+	f := setup(t)
+	f.createDatabase(t, "orders", "large", f.team)
+	carts := f.createDatabase(t, "carts", "small", f.team)
+	f.createDatabase(t, "payments", "small", f.team)
+
+	assert.NoError(t, f.budgets.CheckConnections(ctx, f.team.ID, &f.tiers["large"].ID, carts.ID),
+		"moving carts from small to large replaces its connections")
+
+	f.createDatabase(t, "sessions", "small", f.team)
+	assert.ErrorIs(t, f.budgets.CheckConnections(ctx, f.team.ID, &f.tiers["large"].ID, carts.ID), budget.ErrExceeded)
+}
@@ -0,0 +1,46 @@
+package buildinfo_test
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/buildinfo"
+)
+
+func TestGet_UsesStampedValues(t *testing.T) {
+	orig := [4]string{buildinfo.Version, buildinfo.Commit, buildinfo.Date, buildinfo.Features}
+	t.Cleanup(func() {
+		buildinfo.Version, buildinfo.Commit, buildinfo.Date, buildinfo.Features = orig[0], orig[1], orig[2], orig[3]
+	})
+
+	buildinfo.Version = "1.4.0"
+	buildinfo.Commit = "abc123"
+	buildinfo.Date = "2026-02-10T09:00:00Z"
+	buildinfo.Features = " otel, pprof ,,otel"
+
+	info := buildinfo.Get()
+
+	assert.Equal(t, "1.4.0", info.Version)
+	assert.Equal(t, "abc123", info.GitCommit)
+	assert.Equal(t, "2026-02-10T09:00:00Z", info.BuildDate)
+	assert.Equal(t, runtime.Version(), info.GoVersion)
+	assert.Equal(t, []string{"otel", "pprof"}, info.Features)
+}
+
+func TestGet_DefaultsToDev(t *testing.T) {
+	info := buildinfo.Get()
+
+	assert.Equal(t, "dev", info.Version)
+	assert.NotNil(t, info.Features)
+}
+
+func TestWithFeatures_MergesSortedAndDeduplicated(t *testing.T) {
+	info := buildinfo.Info{Features: []string{"pprof"}}
+
+	got := info.WithFeatures("storage-memory", "pprof", "")
+
+	assert.Equal(t, []string{"pprof", "storage-memory"}, got.Features)
+	assert.Equal(t, []string{"pprof"}, info.Features, "receiver must not be modified")
+}
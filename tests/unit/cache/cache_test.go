@@ -0,0 +1,64 @@
+package cache_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/cache"
+)
+
+func TestTTL_LoadCachesUntilExpiry(t *testing.T) {
+	c := cache.New[string, int](50 * time.Millisecond)
+	calls := 0
+	load := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	v, err := c.Load("a", load)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	v, err = c.Load("a", load)
+	require.NoError(t, err)
+	assert.Equal(t, 1, v, "served from cache")
+
+	time.Sleep(60 * time.Millisecond)
+	_, ok := c.Get("a")
+	assert.False(t, ok)
+	v, err = c.Load("a", load)
+	require.NoError(t, err)
+	assert.Equal(t, 2, v, "reloaded after expiry")
+}
+
+func TestTTL_ErrorsAreNotCached(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+	errMissing := errors.New("missing")
+
+	_, err := c.Load("a", func() (int, error) { return 0, errMissing })
+	assert.ErrorIs(t, err, errMissing)
+	v, err := c.Load("a", func() (int, error) { return 7, nil })
+	require.NoError(t, err)
+	assert.Equal(t, 7, v)
+}
+
+func TestTTL_PurgeDiscardsInFlightLoad(t *testing.T) {
+	c := cache.New[string, int](time.Minute)
+
+	v, err := c.Load("a", func() (int, error) {
+		c.Purge() // a write lands while the stale value is being read
+		return 1, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+	_, ok := c.Get("a")
+	assert.False(t, ok, "value read before the purge must not be cached")
+
+	_, _ = c.Load("a", func() (int, error) { return 2, nil })
+	c.Purge()
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+}
@@ -0,0 +1,137 @@
+package chaos_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/chaos"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestInject_NoFaultsByDefault(t *testing.T) {
+	inj := chaos.NewInjector(chaos.Config{})
+	for range 100 {
+		require.NoError(t, inj.Inject(context.Background(), "provider.Apply"))
+	}
+}
+
+func TestInject_AlwaysFails(t *testing.T) {
+	inj := chaos.NewInjector(chaos.Config{FailureRate: 1})
+	err := inj.Inject(context.Background(), "provider.Apply")
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+	assert.Contains(t, err.Error(), "provider.Apply")
+}
+
+func TestInject_OperationFilter(t *testing.T) {
+	inj := chaos.NewInjector(chaos.Config{FailureRate: 1, Operations: []string{"provider."}})
+	assert.ErrorIs(t, inj.Inject(context.Background(), "provider.CheckHealth"), chaos.ErrInjected)
+	assert.NoError(t, inj.Inject(context.Background(), "database.List"))
+}
+
+func TestInject_SeedIsReproducible(t *testing.T) {
+	run := func() []bool {
+		inj := chaos.NewInjector(chaos.Config{FailureRate: 0.5, Seed: 42})
+		out := make([]bool, 50)
+		for i := range out {
+			out[i] = inj.Inject(context.Background(), "op") != nil
+		}
+		return out
+	}
+	assert.Equal(t, run(), run())
+}
+
+func TestInject_LatencyRespectsContext(t *testing.T) {
+	inj := chaos.NewInjector(chaos.Config{Latency: time.Minute})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := inj.Inject(ctx, "database.List")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("CHAOS_LATENCY", "25ms")
+	t.Setenv("CHAOS_FAILURE_RATE", "0.3")
+	t.Setenv("CHAOS_OPERATIONS", "provider.,database.UpdateStatus")
+	t.Setenv("CHAOS_SEED", "7")
+
+	cfg, err := chaos.ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 25*time.Millisecond, cfg.Latency)
+	assert.InDelta(t, 0.3, cfg.FailureRate, 1e-9)
+	assert.Equal(t, []string{"provider.", "database.UpdateStatus"}, cfg.Operations)
+	assert.Equal(t, uint64(7), cfg.Seed)
+}
+
+func TestConfigFromEnv_InvalidFailureRate(t *testing.T) {
+	t.Setenv("CHAOS_FAILURE_RATE", "1.5")
+	_, err := chaos.ConfigFromEnv()
+	assert.Error(t, err)
+}
+
+func TestWrapProvider_InjectsBeforeDelegating(t *testing.T) {
+	inner := fake.NewProvider()
+	p := chaos.WrapProvider(inner, chaos.NewInjector(chaos.Config{FailureRate: 1}))
+
+	err := p.Apply(context.Background(), provider.ProviderDatabase{Name: "orders"}, "")
+	assert.ErrorIs(t, err, chaos.ErrInjected)
+	assert.Empty(t, inner.ApplyCalls(), "failed operations must not reach the wrapped provider")
+}
+
+// TestReconciler_SurvivesProviderOutage runs the reconciler against a
+// provider that always fails and checks that the database is left untouched,
+// then lifts the outage and checks that it converges.
+func TestReconciler_SurvivesProviderOutage(t *testing.T) {
+	repos := fake.NewRepositories()
+	ctx := context.Background()
+
+	tm := &team.Team{Name: "backend", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	bp := &blueprint.Blueprint{Name: "cnpg-small", Provider: "cnpg", Manifests: "kind: Cluster"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	tr := &tier.Tier{Name: "standard", BlueprintID: &bp.ID, DestructionStrategy: "hard_delete"}
+	require.NoError(t, repos.Tiers.Create(ctx, tr))
+	db := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+
+	inner := fake.NewProvider()
+	host, port, secret := "orders", 5432, "orders-app"
+	inner.SetHealth(db.ID, provider.HealthResult{Status: "ready", Host: &host, Port: &port, SecretName: &secret})
+
+	runFor := func(cfg chaos.Config) {
+		inj := chaos.NewInjector(cfg)
+		registry := provider.NewRegistry()
+		registry.Register("cnpg", chaos.WrapProvider(inner, inj))
+		rec := reconciler.New(
+			chaos.WrapDatabaseRepository(repos.Databases, inj),
+			chaos.WrapTierRepository(repos.Tiers, inj),
+			chaos.WrapBlueprintRepository(repos.Blueprints, inj),
+			registry, 10*time.Millisecond,
+		)
+		runCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+		defer cancel()
+		rec.Start(runCtx)
+	}
+
+	runFor(chaos.Config{FailureRate: 1, Operations: []string{"provider."}})
+	got, err := repos.Databases.GetByID(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "provisioning", got.Status)
+
+	runFor(chaos.Config{Jitter: time.Millisecond})
+	got, err = repos.Databases.GetByID(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ready", got.Status)
+}
@@ -0,0 +1,210 @@
+package chaos_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/api/middleware"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/chaos"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// harness is a platform on pkg/fake whose repositories and provider fail
+// and slow down as a chaos.Config says. The config comes from the CHAOS_*
+// variables when any is set, so that a failing run can be replayed from its
+// seed, or the resilience tests run under harsher faults:
+//
+//	CHAOS_FAILURE_RATE=0.8 CHAOS_SEED=7 go test ./tests/unit/chaos/ -run Resilience
+//
+// and from the config each test passes otherwise.
+type harness struct {
+	repos    *fake.Repositories
+	provider *fake.Provider
+	team     *team.Team
+	tier     *tier.Tier
+	cfg      chaos.Config
+}
+
+func newHarness(t *testing.T, cfg chaos.Config) *harness {
+	t.Helper()
+	ctx := context.Background()
+	h := &harness{repos: fake.NewRepositories(), provider: fake.NewProvider(), cfg: cfg}
+
+	for _, name := range []string{"CHAOS_LATENCY", "CHAOS_JITTER", "CHAOS_FAILURE_RATE", "CHAOS_OPERATIONS", "CHAOS_SEED"} {
+		if _, ok := os.LookupEnv(name); ok {
+			env, err := chaos.ConfigFromEnv()
+			require.NoError(t, err)
+			h.cfg = env
+			break
+		}
+	}
+	if h.cfg.Seed == 0 {
+		h.cfg.Seed = uint64(time.Now().UnixNano())
+	}
+	t.Logf("chaos: %+v", h.cfg)
+
+	h.team = &team.Team{Name: "backend", Role: "product"}
+	require.NoError(t, h.repos.Teams.Create(ctx, h.team))
+	bp := &blueprint.Blueprint{Name: "cnpg-small", Provider: "cnpg", Manifests: "kind: Cluster"}
+	require.NoError(t, h.repos.Blueprints.Create(ctx, bp))
+	h.tier = &tier.Tier{Name: "standard", BlueprintID: &bp.ID, DestructionStrategy: "hard_delete"}
+	require.NoError(t, h.repos.Tiers.Create(ctx, h.tier))
+	return h
+}
+
+// injector returns an injector applying the harness's faults, or none when
+// calm is set.
+func (h *harness) injector(calm bool) *chaos.Injector {
+	if calm {
+		return chaos.NewInjector(chaos.Config{})
+	}
+	return chaos.NewInjector(h.cfg)
+}
+
+// registry registers the harness's provider, with faults injected by inj.
+func (h *harness) registry(inj *chaos.Injector) *provider.Registry {
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", chaos.WrapProvider(h.provider, inj))
+	return registry
+}
+
+// create creates a database with status, whose resources the provider
+// reports ready.
+func (h *harness) create(t *testing.T, name, status string) *database.Database {
+	t.Helper()
+	ctx := context.Background()
+	db := &database.Database{Name: name, OwnerTeamID: h.team.ID, TierID: &h.tier.ID}
+	require.NoError(t, h.repos.Databases.Create(ctx, db))
+	if status != db.Status {
+		updated, err := h.repos.Databases.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: status})
+		require.NoError(t, err)
+		db = updated
+	}
+	host, port, secret := name, 5432, name+"-app"
+	h.provider.SetHealth(db.ID, provider.HealthResult{Status: "ready", Host: &host, Port: &port, SecretName: &secret})
+	return db
+}
+
+// reconcile runs a reconciler over the harness for d, with faults injected
+// by inj.
+func (h *harness) reconcile(inj *chaos.Injector, d time.Duration) {
+	rec := reconciler.New(
+		chaos.WrapDatabaseRepository(h.repos.Databases, inj),
+		chaos.WrapTierRepository(h.repos.Tiers, inj),
+		chaos.WrapBlueprintRepository(h.repos.Blueprints, inj),
+		h.registry(inj), 10*time.Millisecond,
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	rec.Start(ctx)
+}
+
+// handler returns a database handler over the harness, with faults injected
+// by inj.
+func (h *harness) handler(inj *chaos.Injector, ops *operation.Tracker) *handler.DatabaseHandler {
+	return handler.NewDatabaseHandler(
+		chaos.WrapDatabaseRepository(h.repos.Databases, inj),
+		chaos.WrapTeamRepository(h.repos.Teams, inj),
+		chaos.WrapTierRepository(h.repos.Tiers, inj),
+		chaos.WrapBlueprintRepository(h.repos.Blueprints, inj),
+		h.registry(inj), "default", nil, nil, nil, nil, ops, 0, nil, nil, nil, nil, nil, "", nil, nil, nil, nil)
+}
+
+// platformRequest returns a request of a platform user to path, routed with
+// the given URL parameters.
+func platformRequest(method, path string, params map[string]string) (*http.Request, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, path, nil)
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	role, teamName, teamID := "platform", "platform-ops", uuid.New()
+	ctx = middleware.WithIdentity(ctx, &auth.Identity{UserID: uuid.New(), UserName: "platform-user", TeamID: &teamID, TeamName: &teamName, Role: &role})
+	return req.WithContext(ctx), httptest.NewRecorder()
+}
+
+// TestResilience_ReconcilerConvergesAfterFaults reconciles databases while
+// their repository and provider calls fail at random, and checks that no
+// database is left in a state it should not reach, then that every one
+// converges once the faults stop.
+func TestResilience_ReconcilerConvergesAfterFaults(t *testing.T) {
+	h := newHarness(t, chaos.Config{FailureRate: 0.5, Jitter: time.Millisecond})
+	ctx := context.Background()
+
+	var dbs []*database.Database
+	for i := range 10 {
+		dbs = append(dbs, h.create(t, fmt.Sprintf("orders-%d", i), "provisioning"))
+	}
+
+	h.reconcile(h.injector(false), 200*time.Millisecond)
+	for _, db := range dbs {
+		got, err := h.repos.Databases.GetByID(ctx, db.ID)
+		require.NoError(t, err)
+		assert.Contains(t, []string{"provisioning", "ready"}, got.Status, "database %s", db.Name)
+		if got.Status == "ready" {
+			assert.NotNil(t, got.Host, "database %s is ready without its connection details", db.Name)
+		}
+	}
+
+	h.reconcile(h.injector(true), 200*time.Millisecond)
+	for _, db := range dbs {
+		got, err := h.repos.Databases.GetByID(ctx, db.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "ready", got.Status, "database %s", db.Name)
+	}
+}
+
+// TestResilience_DeleteSurvivesProviderOutage deletes a database while its
+// provider is down: the database must outlive its resources rather than the
+// other way round, and the reconciler must finish the teardown once the
+// provider is back.
+func TestResilience_DeleteSurvivesProviderOutage(t *testing.T) {
+	h := newHarness(t, chaos.Config{FailureRate: 1, Operations: []string{"provider."}})
+	ctx := context.Background()
+	db := h.create(t, "orders", "ready")
+
+	ops := operation.NewTracker(h.repos.Operations, "daap-a:1")
+	req, w := platformRequest(http.MethodDelete, "/databases/"+db.ID.String(), map[string]string{"id": db.ID.String()})
+	h.handler(h.injector(false), ops).Delete(w, req)
+	require.NoError(t, ops.Wait(ctx))
+	require.Contains(t, []int{http.StatusNoContent, http.StatusInternalServerError}, w.Code, w.Body.String())
+
+	got, err := h.repos.Databases.GetByID(ctx, db.ID)
+	if err == nil {
+		assert.Contains(t, []string{"ready", "deprovisioning"}, got.Status)
+	} else {
+		require.ErrorIs(t, err, database.ErrNotFound)
+		assert.NotEmpty(t, h.provider.DeleteCalls(), "the record is deleted only after its resources")
+	}
+
+	h.reconcile(h.injector(true), 100*time.Millisecond)
+	if w.Code != http.StatusNoContent {
+		got, err := h.repos.Databases.GetByID(ctx, db.ID)
+		require.NoError(t, err, "a refused deletion keeps the database")
+		assert.Equal(t, "ready", got.Status)
+		return
+	}
+	_, err = h.repos.Databases.GetByID(ctx, db.ID)
+	assert.ErrorIs(t, err, database.ErrNotFound, "the reconciler finishes the teardown")
+	assert.NotEmpty(t, h.provider.DeleteCalls())
+}
@@ -31,7 +31,70 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.Equal(t, testDatabaseURL, cfg.DatabaseURL)
 	assert.Equal(t, "", cfg.KubeconfigPath)
 	assert.Equal(t, "default", cfg.Namespace)
+	assert.Equal(t, "cnpg-system", cfg.CNPGOperatorNamespace)
 	assert.Equal(t, "dev", cfg.Version)
+	assert.False(t, cfg.PprofEnabled)
+	assert.Equal(t, 5, cfg.BreakerFailureThreshold)
+	assert.Equal(t, 30, cfg.BreakerCooldown)
+	assert.Equal(t, 50, cfg.ReconcilerWriteBatchSize)
+	assert.Equal(t, 0, cfg.ReconcilerWriteRate)
+	assert.Equal(t, 900, cfg.ProvisioningSLO)
+	assert.Equal(t, 3600, cfg.ProvisioningTimeout)
+	assert.Equal(t, 60, cfg.DeprovisionWait)
+	assert.Empty(t, cfg.NotifyWebhookURL)
+	assert.Equal(t, 0, cfg.ReadinessGateConnections)
+	assert.Equal(t, "SELECT 1", cfg.ReadinessGateQuery)
+	assert.Equal(t, 10, cfg.ReadinessGateTimeout)
+	assert.Equal(t, 60, cfg.StorageAutoscaleInterval)
+	assert.Equal(t, 300, cfg.RecommenderInterval)
+	assert.Equal(t, 168, cfg.RecommenderLookback)
+	assert.False(t, cfg.RecommenderAutoApply)
+	assert.Equal(t, "Sun 02:00-04:00", cfg.RecommenderApplyWindow)
+	assert.Equal(t, 30, cfg.RolloutInterval)
+	assert.Equal(t, 1, cfg.RolloutCanarySize)
+	assert.Equal(t, 5, cfg.RolloutBatchSize)
+	assert.Equal(t, 600, cfg.RolloutVerifyTimeout)
+	assert.Equal(t, 2, cfg.JobWorkerInterval)
+	assert.Equal(t, 10, cfg.JobRetryBackoff)
+	assert.Equal(t, 0, cfg.RetentionDays)
+	assert.Equal(t, 3600, cfg.RetentionInterval)
+	assert.Equal(t, 0, cfg.HistoryRetentionMonths)
+	assert.Equal(t, 3600, cfg.HistoryRotationInterval)
+	assert.False(t, cfg.HistoryArchive)
+	assert.Equal(t, []string{"dev", "staging", "prod"}, cfg.Environments)
+	assert.Empty(t, cfg.K8sImpersonateUser)
+	assert.Empty(t, cfg.K8sImpersonateGroups)
+	assert.Empty(t, cfg.K8sNamespaceServiceAccounts)
+	assert.Empty(t, cfg.ProviderPluginDir)
+	assert.Empty(t, cfg.ProviderPluginAddrs)
+	assert.Equal(t, "http://localhost:8080", cfg.PublicURL)
+	assert.Equal(t, 72, cfg.InvitationTTL)
+	assert.Empty(t, cfg.SMTPAddr)
+	assert.Equal(t, "daap@localhost", cfg.SMTPFrom)
+	assert.Empty(t, cfg.SMTPUsername)
+	assert.Empty(t, cfg.SMTPPassword)
+	assert.Empty(t, cfg.AuditSyslogAddr)
+	assert.Empty(t, cfg.AuditHTTPURL)
+	assert.Empty(t, cfg.AuditKafkaRESTURL)
+	assert.Equal(t, "daap-audit", cfg.AuditKafkaTopic)
+	assert.Equal(t, 10000, cfg.AuditQueueSize)
+	assert.Equal(t, 100, cfg.AuditBatchSize)
+	assert.Equal(t, 5, cfg.AuditEnqueueTimeout)
+	assert.Empty(t, cfg.EventsNATSURL)
+	assert.Equal(t, "daap.events", cfg.EventsNATSSubject)
+	assert.Empty(t, cfg.EventsKafkaRESTURL)
+	assert.Equal(t, "daap.events", cfg.EventsKafkaTopic)
+	assert.Equal(t, 10000, cfg.EventsQueueSize)
+	assert.Equal(t, "default", cfg.CatalogNamespace)
+	assert.Empty(t, cfg.CatalogSystem)
+	assert.Empty(t, cfg.CatalogOwners)
+	assert.Equal(t, 900, cfg.MutationLockTTL)
+	assert.Equal(t, 30, cfg.LookupCacheTTL)
+	assert.Empty(t, cfg.SecretsK8sSecret)
+	assert.Empty(t, cfg.BlueprintLintRules)
+	assert.Empty(t, cfg.BlueprintLintRequiredLabels)
+	assert.Equal(t, 262144, cfg.BlueprintMaxManifestBytes)
+	assert.Empty(t, cfg.SecretsVaultAddr)
 }
 
 func TestLoad_EnvVarOverrides(t *testing.T) {
@@ -75,6 +138,286 @@ func TestLoad_EnvVarOverrides(t *testing.T) {
 				assert.Equal(t, "1.2.3", cfg.Version)
 			},
 		},
+		{
+			name:    "custom breaker settings",
+			envVars: map[string]string{"BREAKER_FAILURE_THRESHOLD": "3", "BREAKER_COOLDOWN": "10"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 3, cfg.BreakerFailureThreshold)
+				assert.Equal(t, 10, cfg.BreakerCooldown)
+			},
+		},
+		{
+			name:    "custom provisioning SLO",
+			envVars: map[string]string{"PROVISIONING_SLO": "0"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 0, cfg.ProvisioningSLO)
+			},
+		},
+		{
+			name: "provisioning timeout with webhook",
+			envVars: map[string]string{
+				"PROVISIONING_TIMEOUT": "1800",
+				"NOTIFY_WEBHOOK_URL":   "https://hooks.example.com/daap",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 1800, cfg.ProvisioningTimeout)
+				assert.Equal(t, "https://hooks.example.com/daap", cfg.NotifyWebhookURL)
+			},
+		},
+		{
+			name: "kubernetes impersonation",
+			envVars: map[string]string{
+				"K8S_IMPERSONATE_USER":           "daap-operator",
+				"K8S_IMPERSONATE_GROUPS":         "daap,ops",
+				"K8S_NAMESPACE_SERVICE_ACCOUNTS": "db-prod:daap-prod,db-dev:daap-dev",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "daap-operator", cfg.K8sImpersonateUser)
+				assert.Equal(t, []string{"daap", "ops"}, cfg.K8sImpersonateGroups)
+				assert.Equal(t, map[string]string{"db-prod": "daap-prod", "db-dev": "daap-dev"}, cfg.K8sNamespaceServiceAccounts)
+			},
+		},
+		{
+			name: "provider plugins",
+			envVars: map[string]string{
+				"PROVIDER_PLUGIN_DIR":   "/opt/daap/plugins",
+				"PROVIDER_PLUGIN_ADDRS": "rds=daap-provider-rds:7000,gcp=daap-provider-gcp:7000",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "/opt/daap/plugins", cfg.ProviderPluginDir)
+				assert.Equal(t, []string{"rds=daap-provider-rds:7000", "gcp=daap-provider-gcp:7000"}, cfg.ProviderPluginAddrs)
+			},
+		},
+		{
+			name: "readiness gate",
+			envVars: map[string]string{
+				"READINESS_GATE_CONNECTIONS": "5",
+				"READINESS_GATE_QUERY":       "SELECT count(*) FROM pg_stat_activity",
+				"READINESS_GATE_TIMEOUT":     "30",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 5, cfg.ReadinessGateConnections)
+				assert.Equal(t, "SELECT count(*) FROM pg_stat_activity", cfg.ReadinessGateQuery)
+				assert.Equal(t, 30, cfg.ReadinessGateTimeout)
+			},
+		},
+		{
+			name: "invitation email",
+			envVars: map[string]string{
+				"PUBLIC_URL":     "https://daap.example.com",
+				"INVITATION_TTL": "24",
+				"SMTP_ADDR":      "smtp.example.com:587",
+				"SMTP_FROM":      "daap@example.com",
+				"SMTP_USERNAME":  "daap",
+				"SMTP_PASSWORD":  "secret",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "https://daap.example.com", cfg.PublicURL)
+				assert.Equal(t, 24, cfg.InvitationTTL)
+				assert.Equal(t, "smtp.example.com:587", cfg.SMTPAddr)
+				assert.Equal(t, "daap@example.com", cfg.SMTPFrom)
+				assert.Equal(t, "daap", cfg.SMTPUsername)
+				assert.Equal(t, "secret", cfg.SMTPPassword)
+			},
+		},
+		{
+			name: "audit sinks",
+			envVars: map[string]string{
+				"AUDIT_SYSLOG_ADDR":     "udp://siem.example.com:514",
+				"AUDIT_HTTP_URL":        "https://collector.example.com/audit",
+				"AUDIT_KAFKA_REST_URL":  "http://kafka-rest:8082",
+				"AUDIT_KAFKA_TOPIC":     "soc2-audit",
+				"AUDIT_QUEUE_SIZE":      "500",
+				"AUDIT_BATCH_SIZE":      "10",
+				"AUDIT_ENQUEUE_TIMEOUT": "1",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "udp://siem.example.com:514", cfg.AuditSyslogAddr)
+				assert.Equal(t, "https://collector.example.com/audit", cfg.AuditHTTPURL)
+				assert.Equal(t, "http://kafka-rest:8082", cfg.AuditKafkaRESTURL)
+				assert.Equal(t, "soc2-audit", cfg.AuditKafkaTopic)
+				assert.Equal(t, 500, cfg.AuditQueueSize)
+				assert.Equal(t, 10, cfg.AuditBatchSize)
+				assert.Equal(t, 1, cfg.AuditEnqueueTimeout)
+			},
+		},
+		{
+			name: "event buses",
+			envVars: map[string]string{
+				"EVENTS_NATS_URL":       "nats://nats:4222",
+				"EVENTS_NATS_SUBJECT":   "platform.daap",
+				"EVENTS_KAFKA_REST_URL": "http://kafka-rest:8082",
+				"EVENTS_KAFKA_TOPIC":    "daap-lifecycle",
+				"EVENTS_QUEUE_SIZE":     "50",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "nats://nats:4222", cfg.EventsNATSURL)
+				assert.Equal(t, "platform.daap", cfg.EventsNATSSubject)
+				assert.Equal(t, "http://kafka-rest:8082", cfg.EventsKafkaRESTURL)
+				assert.Equal(t, "daap-lifecycle", cfg.EventsKafkaTopic)
+				assert.Equal(t, 50, cfg.EventsQueueSize)
+			},
+		},
+		{
+			name: "backstage catalog",
+			envVars: map[string]string{
+				"CATALOG_NAMESPACE": "data",
+				"CATALOG_SYSTEM":    "databases",
+				"CATALOG_OWNERS":    "checkout:commerce/checkout-squad,search:search-team",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "data", cfg.CatalogNamespace)
+				assert.Equal(t, "databases", cfg.CatalogSystem)
+				assert.Equal(t, map[string]string{"checkout": "commerce/checkout-squad", "search": "search-team"}, cfg.CatalogOwners)
+			},
+		},
+		{
+			name:    "deprovision wait",
+			envVars: map[string]string{"DEPROVISION_WAIT": "0"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 0, cfg.DeprovisionWait)
+			},
+		},
+		{
+			name:    "mutation lock ttl",
+			envVars: map[string]string{"MUTATION_LOCK_TTL": "60"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 60, cfg.MutationLockTTL)
+			},
+		},
+		{
+			name: "blueprint lint",
+			envVars: map[string]string{
+				"BLUEPRINT_LINT_RULES":           "no-latest-tag:error,required-labels:off",
+				"BLUEPRINT_LINT_REQUIRED_LABELS": "team,cost-center",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, map[string]string{"no-latest-tag": "error", "required-labels": "off"}, cfg.BlueprintLintRules)
+				assert.Equal(t, []string{"team", "cost-center"}, cfg.BlueprintLintRequiredLabels)
+			},
+		},
+		{
+			name:    "blueprint max manifest bytes",
+			envVars: map[string]string{"BLUEPRINT_MAX_MANIFEST_BYTES": "0"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 0, cfg.BlueprintMaxManifestBytes)
+			},
+		},
+		{
+			name: "vault secret store",
+			envVars: map[string]string{
+				"SECRETS_VAULT_ADDR":  "https://vault.example.com:8200",
+				"SECRETS_VAULT_TOKEN": "s.token",
+				"SECRETS_VAULT_PATH":  "secret/data/daap/blueprints",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "https://vault.example.com:8200", cfg.SecretsVaultAddr)
+				assert.Equal(t, "s.token", cfg.SecretsVaultToken)
+				assert.Equal(t, "secret/data/daap/blueprints", cfg.SecretsVaultPath)
+			},
+		},
+		{
+			name:    "lookup cache disabled",
+			envVars: map[string]string{"LOOKUP_CACHE_TTL": "0"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 0, cfg.LookupCacheTTL)
+			},
+		},
+		{
+			name:    "storage autoscale disabled",
+			envVars: map[string]string{"STORAGE_AUTOSCALE_INTERVAL": "0"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 0, cfg.StorageAutoscaleInterval)
+			},
+		},
+		{
+			name: "recommender auto-apply",
+			envVars: map[string]string{
+				"RECOMMENDER_INTERVAL":     "60",
+				"RECOMMENDER_LOOKBACK":     "24",
+				"RECOMMENDER_AUTO_APPLY":   "true",
+				"RECOMMENDER_APPLY_WINDOW": "01:00-03:00",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 60, cfg.RecommenderInterval)
+				assert.Equal(t, 24, cfg.RecommenderLookback)
+				assert.True(t, cfg.RecommenderAutoApply)
+				assert.Equal(t, "01:00-03:00", cfg.RecommenderApplyWindow)
+			},
+		},
+		{
+			name: "query insights",
+			envVars: map[string]string{
+				"INSIGHTS_INTERVAL":  "600",
+				"INSIGHTS_TOP":       "50",
+				"INSIGHTS_RETENTION": "24",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 600, cfg.InsightsInterval)
+				assert.Equal(t, 50, cfg.InsightsTop)
+				assert.Equal(t, 24, cfg.InsightsRetention)
+			},
+		},
+		{
+			name: "history rotation",
+			envVars: map[string]string{
+				"HISTORY_RETENTION_MONTHS":  "12",
+				"HISTORY_ROTATION_INTERVAL": "600",
+				"HISTORY_ARCHIVE":           "true",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 12, cfg.HistoryRetentionMonths)
+				assert.Equal(t, 600, cfg.HistoryRotationInterval)
+				assert.True(t, cfg.HistoryArchive)
+			},
+		},
+		{
+			name: "reconciler writes",
+			envVars: map[string]string{
+				"RECONCILER_WRITE_BATCH_SIZE": "200",
+				"RECONCILER_WRITE_RATE":       "100",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 200, cfg.ReconcilerWriteBatchSize)
+				assert.Equal(t, 100, cfg.ReconcilerWriteRate)
+			},
+		},
+		{
+			name: "rollout batching",
+			envVars: map[string]string{
+				"ROLLOUT_INTERVAL":       "10",
+				"ROLLOUT_CANARY_SIZE":    "2",
+				"ROLLOUT_BATCH_SIZE":     "20",
+				"ROLLOUT_VERIFY_TIMEOUT": "120",
+			},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, 10, cfg.RolloutInterval)
+				assert.Equal(t, 2, cfg.RolloutCanarySize)
+				assert.Equal(t, 20, cfg.RolloutBatchSize)
+				assert.Equal(t, 120, cfg.RolloutVerifyTimeout)
+			},
+		},
+		{
+			name:    "environments",
+			envVars: map[string]string{"ENVIRONMENTS": "test,prod"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, []string{"test", "prod"}, cfg.Environments)
+			},
+		},
+		{
+			name:    "cnpg operator namespace",
+			envVars: map[string]string{"CNPG_OPERATOR_NAMESPACE": "postgres-operator"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.Equal(t, "postgres-operator", cfg.CNPGOperatorNamespace)
+			},
+		},
+		{
+			name:    "pprof enabled",
+			envVars: map[string]string{"PPROF_ENABLED": "true"},
+			assertFn: func(t *testing.T, cfg *config.Config) {
+				assert.True(t, cfg.PprofEnabled)
+			},
+		},
 		{
 			name: "all overrides at once",
 			envVars: map[string]string{
@@ -0,0 +1,120 @@
+package config_test
+
+import (
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/config"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]slog.Level{"debug": slog.LevelDebug, "INFO": slog.LevelInfo, " warn ": slog.LevelWarn, "error": slog.LevelError}
+	for in, want := range tests {
+		got, err := config.ParseLogLevel(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+	_, err := config.ParseLogLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestReloader_Set(t *testing.T) {
+	var calls [][2]config.Config
+	r := config.NewReloader(config.Config{LogLevel: "info", ReconcilerInterval: 10}, func(prev, next config.Config) {
+		calls = append(calls, [2]config.Config{prev, next})
+	})
+
+	require.NoError(t, r.Set(map[string]string{"LOG_LEVEL": "DEBUG", "RECONCILER_INTERVAL": "30"}))
+
+	require.Len(t, calls, 1)
+	assert.Equal(t, "info", calls[0][0].LogLevel)
+	assert.Equal(t, "debug", calls[0][1].LogLevel)
+	assert.Equal(t, 30, calls[0][1].ReconcilerInterval)
+	got := map[string]string{}
+	for _, s := range r.Effective() {
+		got[s.Name] = s.Value
+	}
+	assert.Equal(t, "debug", got["LOG_LEVEL"])
+	assert.Equal(t, "30", got["RECONCILER_INTERVAL"])
+
+	changed := config.Changed(&calls[0][0], &calls[0][1])
+	assert.Equal(t, []config.Setting{{Name: "LOG_LEVEL", Value: "debug"}, {Name: "RECONCILER_INTERVAL", Value: "30"}}, changed)
+}
+
+func TestReloader_SetRejectsAll(t *testing.T) {
+	called := false
+	r := config.NewReloader(config.Config{LogLevel: "info", ReconcilerInterval: 10}, func(_, _ config.Config) { called = true })
+
+	err := r.Set(map[string]string{
+		"LOG_LEVEL":             "debug",
+		"PORT":                  "9090",
+		"RECONCILER_INTERVAL":   "0",
+		"RECONCILER_WRITE_RATE": "fast",
+	})
+
+	var settingErrs config.SettingErrors
+	require.True(t, errors.As(err, &settingErrs))
+	require.Len(t, settingErrs, 3)
+	assert.Equal(t, "PORT", settingErrs[0].Name)
+	assert.Contains(t, settingErrs[0].Message, "restart")
+	assert.Equal(t, "RECONCILER_INTERVAL", settingErrs[1].Name)
+	assert.Equal(t, "RECONCILER_WRITE_RATE", settingErrs[2].Name)
+	assert.False(t, called, "nothing is applied when any setting is rejected")
+	for _, s := range r.Effective() {
+		if s.Name == "LOG_LEVEL" {
+			assert.Equal(t, "info", s.Value)
+		}
+	}
+}
+
+func TestReloader_ReloadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "daap.env")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		"# mounted from the daap-config ConfigMap",
+		"",
+		`LOG_LEVEL="warn"`,
+		"RECONCILER_WRITE_RATE=200",
+		"NAMESPACE=databases",
+	}, "\n")), 0o600))
+
+	var next config.Config
+	r := config.NewReloader(config.Config{LogLevel: "info"}, func(_, n config.Config) { next = n })
+
+	ignored, err := r.ReloadFile(path)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"NAMESPACE"}, ignored)
+	assert.Equal(t, "warn", next.LogLevel)
+	assert.Equal(t, 200, next.ReconcilerWriteRate)
+	assert.Empty(t, next.Namespace, "settings requiring a restart are not applied")
+}
+
+func TestParseEnvFile_Invalid(t *testing.T) {
+	_, err := config.ParseEnvFile(strings.NewReader("LOG_LEVEL=debug\nnot a setting\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestReloader_ModuleLevels(t *testing.T) {
+	var next config.Config
+	r := config.NewReloader(config.Config{LogLevel: "info"}, func(_, n config.Config) { next = n })
+
+	require.NoError(t, r.Set(map[string]string{"LOG_MODULE_LEVELS": "reconciler:debug, http:warn", "LOG_DEBUG_SAMPLING": "5"}))
+	assert.Equal(t, map[string]string{"reconciler": "debug", "http": "warn"}, next.LogModuleLevels)
+	assert.Equal(t, 5, next.LogDebugSampling)
+
+	levels, err := config.ParseModuleLevels(next.LogModuleLevels)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]slog.Level{"reconciler": slog.LevelDebug, "http": slog.LevelWarn}, levels)
+
+	var settingErrs config.SettingErrors
+	require.ErrorAs(t, r.Set(map[string]string{"LOG_MODULE_LEVELS": "reconciler:loud"}), &settingErrs)
+	assert.Contains(t, settingErrs[0].Message, "reconciler")
+	require.ErrorAs(t, r.Set(map[string]string{"LOG_MODULE_LEVELS": "reconciler"}), &settingErrs)
+}
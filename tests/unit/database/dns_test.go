@@ -0,0 +1,29 @@
+package database_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+func TestParseDNSZone(t *testing.T) {
+	zone, err := database.ParseDNSZone(" db.example.com. ")
+	require.NoError(t, err)
+	assert.Equal(t, database.DNSZone("db.example.com"), zone)
+	assert.Equal(t, "orders-db.db.example.com", zone.Hostname("orders-db"))
+
+	zone, err = database.ParseDNSZone("")
+	require.NoError(t, err)
+	assert.Empty(t, zone.Hostname("orders-db"), "without a zone databases get no DNS name")
+
+	_, err = database.ParseDNSZone("DB.example.com")
+	assert.Error(t, err)
+	_, err = database.ParseDNSZone("db_example.com")
+	assert.Error(t, err)
+	_, err = database.ParseDNSZone(strings.Repeat("a.", 95) + "com")
+	assert.ErrorContains(t, err, "must be at most 189 characters")
+}
@@ -0,0 +1,47 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+func TestParseEnvironments(t *testing.T) {
+	envs, err := database.ParseEnvironments([]string{"dev", " staging ", "prod", ""})
+	require.NoError(t, err)
+	assert.Equal(t, database.Environments{"dev", "staging", "prod"}, envs)
+	assert.Equal(t, "dev", envs.Default())
+
+	_, err = database.ParseEnvironments([]string{"dev", "Prod"})
+	assert.Error(t, err)
+	_, err = database.ParseEnvironments([]string{"dev", "dev"})
+	assert.Error(t, err)
+
+	envs, err = database.ParseEnvironments(nil)
+	require.NoError(t, err)
+	assert.Empty(t, envs.Default())
+}
+
+func TestEnvironments_Next(t *testing.T) {
+	envs := database.Environments{"dev", "staging", "prod"}
+
+	next, ok := envs.Next("dev")
+	assert.True(t, ok)
+	assert.Equal(t, "staging", next)
+
+	_, ok = envs.Next("prod")
+	assert.False(t, ok)
+	_, ok = envs.Next("qa")
+	assert.False(t, ok)
+	assert.True(t, envs.Contains("prod"))
+	assert.False(t, envs.Contains("qa"))
+}
+
+func TestPromotedName(t *testing.T) {
+	assert.Equal(t, "orders-staging", database.PromotedName("orders-dev", "dev", "staging"))
+	assert.Equal(t, "orders-staging", database.PromotedName("orders", "dev", "staging"))
+	assert.Equal(t, "dev-tools-staging", database.PromotedName("dev-tools", "dev", "staging"))
+}
@@ -0,0 +1,58 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/tier"
+)
+
+func TestProviderDatabase(t *testing.T) {
+	db := &database.Database{
+		ID:                   uuid.New(),
+		Name:                 "orders",
+		Namespace:            "team-orders",
+		ClusterName:          "daap-orders",
+		PoolerName:           "daap-orders-pooler",
+		OwnerTeamID:          uuid.New(),
+		OwnerTeamName:        "orders",
+		OwnerTeamLabels:      map[string]string{"cost-center": "cc-42"},
+		OwnerTeamAnnotations: map[string]string{"owner": "orders@example.com"},
+		Images:               []database.ImagePin{{Reference: "postgres:16", Image: "registry.internal/postgres@sha256:abc"}},
+		Exposure:             database.Exposure{Type: database.ExposureInternal, AllowedSourceRanges: []string{"10.0.0.0/8"}},
+		DNSName:              "orders.db.example.com",
+	}
+	tr := &tier.Tier{
+		ID:         uuid.New(),
+		Name:       "standard",
+		Topology:   tier.Topology{ZoneSpread: tier.ZoneSpreadRequired, NodeSelector: map[string]string{"pool": "databases"}},
+		Disruption: tier.Disruption{MinAvailable: 1, PriorityClassName: "databases"},
+	}
+	bp := &blueprint.Blueprint{ID: uuid.New(), Name: "cnpg-standard", Provider: "cnpg"}
+
+	assert.Equal(t, provider.ProviderDatabase{
+		ID:          db.ID,
+		Name:        "orders",
+		Namespace:   "team-orders",
+		ClusterName: "daap-orders",
+		PoolerName:  "daap-orders-pooler",
+		OwnerTeam:   "orders",
+		OwnerTeamID: db.OwnerTeamID,
+		Tier:        "standard",
+		TierID:      tr.ID,
+		Blueprint:   "cnpg-standard",
+		Provider:    "cnpg",
+		Labels:      map[string]string{"cost-center": "cc-42"},
+		Annotations: map[string]string{"owner": "orders@example.com"},
+		Topology:    provider.Topology{ZoneSpread: provider.ZoneSpreadRequired, NodeSelector: map[string]string{"pool": "databases"}},
+		Disruption:  provider.Disruption{MinAvailable: 1, PriorityClassName: "databases"},
+		Images:      map[string]string{"postgres:16": "registry.internal/postgres@sha256:abc"},
+		Exposure:    provider.Exposure{Type: provider.ExposureInternal, AllowedSourceRanges: []string{"10.0.0.0/8"}},
+		DNSName:     "orders.db.example.com",
+	}, db.ProviderDatabase(tr, bp))
+}
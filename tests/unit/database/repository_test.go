@@ -2,6 +2,7 @@ package database_test
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"testing"
 	"time"
@@ -24,7 +25,7 @@ var (
 	infraTeamID    uuid.UUID
 )
 
-func setupRepo(t *testing.T) (database.Repository, func()) {
+func setupRepo(t testing.TB) (database.Repository, func()) {
 	t.Helper()
 
 	dbURL := os.Getenv("TEST_DATABASE_URL")
@@ -418,6 +419,29 @@ func TestUpdate_Purpose(t *testing.T) {
 	assert.Equal(t, platformTeamID, updated.OwnerTeamID) // unchanged
 }
 
+func TestUpdate_ReconciliationPause(t *testing.T) {
+	repo, cleanup := setupRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	db := newTestDB("update-pause", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, db))
+
+	until := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	updated, err := repo.Update(ctx, db.ID, database.UpdateFields{
+		ReconciliationPause: &database.ReconciliationPause{By: "alice", Until: until},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, updated.ReconciliationPause)
+	assert.Equal(t, "alice", updated.ReconciliationPause.By)
+	assert.True(t, until.Equal(updated.ReconciliationPause.Until))
+	assert.True(t, updated.ReconciliationPaused(time.Now()))
+
+	resumed, err := repo.Update(ctx, db.ID, database.UpdateFields{ResumeReconciliation: true})
+	require.NoError(t, err)
+	assert.Nil(t, resumed.ReconciliationPause)
+}
+
 func TestUpdate_BothFields(t *testing.T) {
 	repo, cleanup := setupRepo(t)
 	defer cleanup()
@@ -487,6 +511,179 @@ func TestUpdate_SoftDeletedRecord(t *testing.T) {
 
 // --- SoftDelete Tests ---
 
+func TestUpdateStatus_Instances(t *testing.T) {
+	repo, cleanup := setupRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	db := newTestDB("instances", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, db))
+
+	lag := 1500 * time.Millisecond
+	got, err := repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{
+		Status:    "ready",
+		Instances: &database.Instances{Total: 3, Ready: 2, Primary: "daap-instances-1", ReplicationLag: &lag},
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got.Instances)
+	assert.Equal(t, 3, got.Instances.Total)
+	assert.Equal(t, 2, got.Instances.Ready)
+	assert.Equal(t, "daap-instances-1", got.Instances.Primary)
+	require.NotNil(t, got.Instances.ReplicationLag)
+	assert.Equal(t, lag, *got.Instances.ReplicationLag)
+
+	// No primary and an unknown lag are stored as such; an update without
+	// instances keeps them.
+	_, err = repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{
+		Status:    "ready",
+		Instances: &database.Instances{Total: 3, Ready: 0},
+	})
+	require.NoError(t, err)
+	_, err = repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "error"})
+	require.NoError(t, err)
+	got, err = repo.GetByID(ctx, db.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.Instances)
+	assert.Equal(t, 0, got.Instances.Ready)
+	assert.Empty(t, got.Instances.Primary)
+	assert.Nil(t, got.Instances.ReplicationLag)
+}
+
+func TestUpdateStatus_Message(t *testing.T) {
+	repo, cleanup := setupRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	db := newTestDB("status-message", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, db))
+
+	got, err := repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{
+		Status:  "error",
+		Reason:  "FAILED_TO_CREATE_PRIMARY",
+		Message: "Failed to create primary: storageclass not found",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got.StatusMessage)
+	assert.Equal(t, "Failed to create primary: storageclass not found", *got.StatusMessage)
+
+	got, err = repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	assert.Nil(t, got.StatusReason)
+	assert.Nil(t, got.StatusMessage)
+}
+
+func TestUpdateStatus_OperatorVersionAndConditions(t *testing.T) {
+	repo, cleanup := setupRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	db := newTestDB("versioned", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, db))
+
+	version := "1.24.1"
+	since := time.Date(2026, 2, 3, 9, 0, 0, 0, time.UTC)
+	condition := database.Condition{
+		Type:    database.ConditionNeedsReview,
+		Reason:  database.ReasonOperatorVersionChanged,
+		Message: "Provisioned under cnpg operator 1.24.1, now running under 1.25.0",
+		Since:   since,
+	}
+	_, err := repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{
+		Status:          "ready",
+		OperatorVersion: &version,
+		Conditions:      []database.Condition{condition},
+	})
+	require.NoError(t, err)
+
+	// An update without them keeps them.
+	_, err = repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	got, err := repo.GetByID(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "1.24.1", got.OperatorVersion)
+	require.Len(t, got.Conditions, 1)
+	assert.Equal(t, condition.Message, got.Conditions[0].Message)
+	assert.True(t, since.Equal(got.Conditions[0].Since))
+
+	none := ""
+	got, err = repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{
+		Status:          "ready",
+		OperatorVersion: &none,
+		Conditions:      got.WithoutCondition(database.ConditionNeedsReview),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, got.OperatorVersion)
+	assert.Empty(t, got.Conditions)
+}
+
+func TestUpdateStatuses_Batch(t *testing.T) {
+	repo, cleanup := setupRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	first := newTestDB("batch-first", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, first))
+	second := newTestDB("batch-second", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, second))
+	host, port := "daap-batch-first-pooler.default.svc", 5432
+	_, err := repo.UpdateStatus(ctx, second.ID, database.StatusUpdate{Status: "ready", Host: &host, Port: &port})
+	require.NoError(t, err)
+
+	batcher, ok := repo.(database.StatusBatchUpdater)
+	require.True(t, ok)
+	generation := int64(1)
+	applied, err := batcher.UpdateStatuses(ctx, []database.StatusWrite{
+		{ID: first.ID, Update: database.StatusUpdate{Status: "ready", Host: &host, Port: &port, ObservedGeneration: &generation}},
+		{ID: second.ID, Update: database.StatusUpdate{Status: "error", Reason: database.ReasonReadinessGateFailed,
+			Instances: &database.Instances{Total: 2, Ready: 1}}},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []uuid.UUID{first.ID, second.ID}, applied)
+
+	got, err := repo.GetByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "ready", got.Status)
+	require.NotNil(t, got.Host)
+	assert.Equal(t, host, *got.Host)
+	assert.Equal(t, int64(1), got.ObservedGeneration)
+
+	// Fields not given are kept.
+	got, err = repo.GetByID(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "error", got.Status)
+	require.NotNil(t, got.StatusReason)
+	assert.Equal(t, database.ReasonReadinessGateFailed, *got.StatusReason)
+	require.NotNil(t, got.Port)
+	assert.Equal(t, 5432, *got.Port)
+	require.NotNil(t, got.Instances)
+	assert.Equal(t, 1, got.Instances.Ready)
+}
+
+func TestUpdateStatuses_SkipsChangedStatus(t *testing.T) {
+	repo, cleanup := setupRepo(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	stale := newTestDB("batch-stale", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, stale))
+	current := newTestDB("batch-current", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, current))
+	// Deleted after the writer read it as provisioning.
+	_, err := repo.UpdateStatus(ctx, stale.ID, database.StatusUpdate{Status: "deprovisioning"})
+	require.NoError(t, err)
+
+	updated, err := database.UpdateStatuses(ctx, repo, []database.StatusWrite{
+		{ID: stale.ID, Expected: "provisioning", Update: database.StatusUpdate{Status: "ready"}},
+		{ID: current.ID, Expected: "provisioning", Update: database.StatusUpdate{Status: "ready"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{current.ID}, updated)
+
+	got, err := repo.GetByID(ctx, stale.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "deprovisioning", got.Status)
+}
+
 func TestSoftDelete_Success(t *testing.T) {
 	repo, cleanup := setupRepo(t)
 	defer cleanup()
@@ -548,3 +745,42 @@ func TestSoftDelete_AllowsNameReuse(t *testing.T) {
 	assert.NoError(t, err, "should allow name reuse after soft delete")
 	assert.NotEqual(t, db1.ID, db2.ID)
 }
+
+// --- List Benchmarks ---
+
+// BenchmarkList_10k checks the list query against the 50ms budget of the list
+// endpoint with 10k databases across two teams.
+func BenchmarkList_10k(b *testing.B) {
+	repo, cleanup := setupRepo(b)
+	defer cleanup()
+
+	ctx := context.Background()
+	for i := range 10000 {
+		owner := platformTeamID
+		if i%2 == 1 {
+			owner = backendTeamID
+		}
+		if err := repo.Create(ctx, newTestDB(fmt.Sprintf("bench-db-%05d", i), owner, "default")); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		filter database.ListFilter
+	}{
+		{"all", database.ListFilter{Page: 2, Limit: 100}},
+		{"team", database.ListFilter{Page: 2, Limit: 100, OwnerTeamID: uuidPtr(backendTeamID)}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			for b.Loop() {
+				if _, err := repo.List(ctx, tc.filter); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if perOp := b.Elapsed() / time.Duration(b.N); perOp > 50*time.Millisecond {
+				b.Errorf("list took %s per query, budget is 50ms", perOp)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/tier"
+)
+
+func sampleSpec() *database.Spec {
+	t := &tier.Tier{ID: uuid.New(), Name: "standard", DestructionStrategy: "hard_delete", BackupEnabled: true}
+	bp := &blueprint.Blueprint{ID: uuid.New(), Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\nspec:\n  instances: 2\n"}
+	return database.NewSpec(uuid.New(), t, bp)
+}
+
+func TestSpecDiff(t *testing.T) {
+	applied := sampleSpec()
+
+	same := *applied
+	assert.Empty(t, applied.Diff(&same))
+
+	current := *applied
+	current.BackupEnabled = false
+	current.StorageAutoscaling.MaxSize = "100Gi"
+	current.Manifests = "kind: Cluster\nspec:\n  instances: 3\n"
+	changes := applied.Diff(&current)
+	require.Len(t, changes, 3)
+	assert.Equal(t, database.SpecChange{Field: "tier.backupEnabled", Applied: true, Current: false}, changes[0])
+	assert.Equal(t, database.SpecChange{Field: "tier.storageAutoscaling.maxSize", Applied: "", Current: "100Gi"}, changes[1])
+	assert.Equal(t, database.SpecChange{Field: "blueprint.manifests"}, changes[2])
+}
+
+func TestSpecDiff_Topology(t *testing.T) {
+	applied := sampleSpec()
+	applied.Topology.NodeSelector = map[string]string{"workload": "postgres", "disk": "ssd"}
+
+	same := *applied
+	same.Topology.NodeSelector = map[string]string{"disk": "ssd", "workload": "postgres"}
+	assert.Empty(t, applied.Diff(&same))
+
+	current := *applied
+	current.Topology = tier.Topology{ZoneSpread: tier.ZoneSpreadRequired, NodeSelector: map[string]string{"disk": "ssd"}}
+	assert.Equal(t, []database.SpecChange{
+		{Field: "tier.topology.zoneSpread", Applied: "", Current: "required"},
+		{Field: "tier.topology.nodeSelector", Applied: "disk=ssd,workload=postgres", Current: "disk=ssd"},
+	}, applied.Diff(&current))
+}
+
+func TestSpecManifestsDiff(t *testing.T) {
+	applied := sampleSpec()
+
+	same := *applied
+	assert.Empty(t, applied.ManifestsDiff(&same))
+
+	current := *applied
+	current.Manifests = "kind: Cluster\nspec:\n  instances: 3\n  storage:\n    size: 10Gi\n"
+	assert.Equal(t, "--- applied\n+++ current\n"+
+		" kind: Cluster\n"+
+		" spec:\n"+
+		"-  instances: 2\n"+
+		"+  instances: 3\n"+
+		"+  storage:\n"+
+		"+    size: 10Gi\n", applied.ManifestsDiff(&current))
+}
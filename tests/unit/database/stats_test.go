@@ -0,0 +1,70 @@
+package database_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+)
+
+func TestNewDurationStats(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []time.Duration
+		want      database.DurationStats
+	}{
+		{
+			name: "empty",
+			want: database.DurationStats{},
+		},
+		{
+			name:      "single sample",
+			durations: []time.Duration{90 * time.Second},
+			want:      database.DurationStats{Count: 1, P50: 90 * time.Second, P95: 90 * time.Second},
+		},
+		{
+			name:      "interpolates between ranks",
+			durations: []time.Duration{40 * time.Second, 10 * time.Second, 30 * time.Second, 20 * time.Second},
+			want:      database.DurationStats{Count: 4, P50: 25 * time.Second, P95: 38500 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, database.NewDurationStats(tt.durations))
+		})
+	}
+}
+
+func TestStats_CountsAndDurations(t *testing.T) {
+	repo, cleanup := setupRepo(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	stats, ok := repo.(database.StatsReader)
+	require.True(t, ok, "postgres repository must implement StatsReader")
+
+	ready := newTestDB("stats-ready", platformTeamID, "default")
+	require.NoError(t, repo.Create(ctx, ready))
+	_, err := repo.UpdateStatus(ctx, ready.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+
+	pending := newTestDB("stats-pending", backendTeamID, "default")
+	require.NoError(t, repo.Create(ctx, pending))
+
+	got, err := stats.Stats(ctx, database.StatsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, got.Total)
+	assert.Equal(t, map[string]int{"ready": 1, "provisioning": 1}, got.ByStatus)
+	assert.Equal(t, map[string]int{"platform": 1, "backend": 1}, got.ByTeam)
+	assert.Equal(t, 1, got.Provisioning.Count)
+
+	scoped, err := stats.Stats(ctx, database.StatsFilter{OwnerTeamID: uuidPtr(backendTeamID)})
+	require.NoError(t, err)
+	assert.Equal(t, 1, scoped.Total)
+	assert.Equal(t, 0, scoped.Provisioning.Count)
+}
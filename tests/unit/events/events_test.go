@@ -0,0 +1,320 @@
+package events_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/events"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// flakyTransport fails the first failures sends, then records every event.
+type flakyTransport struct {
+	mu       sync.Mutex
+	failures int
+	attempts int
+	events   []events.Event
+	block    chan struct{} // when set, Send waits on it
+}
+
+func (t *flakyTransport) Name() string { return "flaky" }
+
+func (t *flakyTransport) Send(ctx context.Context, batch []events.Event) error {
+	if t.block != nil {
+		select {
+		case <-t.block:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.attempts++
+	if t.failures > 0 {
+		t.failures--
+		return errors.New("bus unavailable")
+	}
+	t.events = append(t.events, batch...)
+	return nil
+}
+
+func (t *flakyTransport) published() []events.Event {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]events.Event(nil), t.events...)
+}
+
+// recorder collects published events synchronously.
+type recorder struct{ events []events.Event }
+
+func (r *recorder) Publish(e events.Event) { r.events = append(r.events, e) }
+
+func (r *recorder) types() []string {
+	var types []string
+	for _, e := range r.events {
+		types = append(types, e.Type)
+	}
+	return types
+}
+
+func TestBus_RetriesUntilPublished(t *testing.T) {
+	tr := &flakyTransport{failures: 2}
+	bus := events.NewBus([]events.Transport{tr}, events.WithRetryBackoff(time.Millisecond, 5*time.Millisecond))
+
+	bus.Publish(events.Event{Type: events.TypeDatabaseCreated, Subject: "db-1"})
+	require.NoError(t, bus.Close(context.Background()))
+
+	published := tr.published()
+	require.Len(t, published, 1)
+	e := published[0]
+	assert.Equal(t, "1.0", e.SpecVersion)
+	assert.Equal(t, "daap", e.Source)
+	assert.Equal(t, "application/json", e.DataContentType)
+	assert.NotEmpty(t, e.ID)
+	assert.False(t, e.Time.IsZero())
+	assert.Equal(t, 3, tr.attempts)
+}
+
+func TestBus_DropsWhenQueueFull(t *testing.T) {
+	tr := &flakyTransport{block: make(chan struct{})}
+	bus := events.NewBus([]events.Transport{tr}, events.WithQueueSize(1))
+
+	// The blocked worker holds at most one event and the queue one more, so
+	// at least one of three is dropped, without blocking the caller.
+	done := make(chan struct{})
+	go func() {
+		for _, s := range []string{"1", "2", "3"} {
+			bus.Publish(events.Event{Subject: s})
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Publish blocked on a full queue")
+	}
+
+	close(tr.block)
+	require.NoError(t, bus.Close(context.Background()))
+	assert.LessOrEqual(t, len(tr.published()), 2)
+}
+
+func TestDatabaseRepository_PublishesLifecycle(t *testing.T) {
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	owner := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, owner))
+
+	rec := &recorder{}
+	repo := events.WrapDatabaseRepository(repos.Databases, rec)
+
+	db := &database.Database{Name: "orders", OwnerTeamID: owner.ID, Namespace: "default", ClusterName: "daap-orders", PoolerName: "daap-orders-pooler"}
+	require.NoError(t, repo.Create(ctx, db))
+
+	purpose := "order history"
+	_, err := repo.Update(ctx, db.ID, database.UpdateFields{Purpose: &purpose})
+	require.NoError(t, err)
+
+	_, err = repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	// Same status again: connection details only, no event.
+	_, err = repo.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+
+	require.NoError(t, repo.SoftDelete(ctx, db.ID))
+
+	assert.Equal(t, []string{
+		events.TypeDatabaseCreated,
+		events.TypeDatabaseUpdated,
+		events.TypeDatabaseStatusChanged,
+		events.TypeDatabaseDeleted,
+	}, rec.types())
+
+	created := rec.events[0]
+	assert.Equal(t, db.ID.String(), created.Subject)
+	assert.Equal(t, "orders", created.Data.Name)
+	assert.Equal(t, "checkout", created.Data.OwnerTeam)
+
+	changed := rec.events[2].Data
+	assert.Equal(t, "ready", changed.Status)
+	assert.Equal(t, db.Status, changed.PreviousStatus)
+
+	deleted := rec.events[3].Data
+	assert.Equal(t, "deleted", deleted.Status)
+	assert.Equal(t, "ready", deleted.PreviousStatus)
+}
+
+func TestDatabaseRepository_UpdateStatusesPublishesStatusChanges(t *testing.T) {
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	owner := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, owner))
+
+	rec := &recorder{}
+	repo := events.WrapDatabaseRepository(repos.Databases, rec)
+
+	var ids []uuid.UUID
+	for _, name := range []string{"orders", "carts"} {
+		db := &database.Database{Name: name, OwnerTeamID: owner.ID, Namespace: "default", ClusterName: "daap-" + name, PoolerName: "daap-" + name + "-pooler"}
+		require.NoError(t, repos.Databases.Create(ctx, db))
+		ids = append(ids, db.ID)
+	}
+
+	applied, err := repo.UpdateStatuses(ctx, []database.StatusWrite{
+		{ID: ids[0], Update: database.StatusUpdate{Status: "ready"}},
+		{ID: ids[1], Update: database.StatusUpdate{Status: "provisioning"}},
+	})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, ids, applied)
+
+	require.Len(t, rec.events, 1, "only the status that changed is an event")
+	assert.Equal(t, events.TypeDatabaseStatusChanged, rec.events[0].Type)
+	assert.Equal(t, "orders", rec.events[0].Data.Name)
+	assert.Equal(t, "provisioning", rec.events[0].Data.PreviousStatus)
+}
+
+func TestDatabaseRepository_FailedWritePublishesNothing(t *testing.T) {
+	rec := &recorder{}
+	repo := events.WrapDatabaseRepository(fake.NewDatabaseRepository(), rec)
+
+	err := repo.SoftDelete(context.Background(), [16]byte{1})
+	require.Error(t, err)
+	assert.Empty(t, rec.events)
+}
+
+func TestNotifier_PublishesAndDelegates(t *testing.T) {
+	rec := &recorder{}
+	var delivered []notify.Notification
+	next := notifierFunc(func(_ context.Context, n notify.Notification) error {
+		delivered = append(delivered, n)
+		return nil
+	})
+
+	n := notify.Notification{Event: "ProvisioningTimeout", Message: "stuck", Database: "orders", OwnerTeam: "checkout", Reason: database.ReasonProvisioningTimeout}
+	require.NoError(t, events.WrapNotifier(next, rec).Notify(context.Background(), n))
+
+	require.Len(t, delivered, 1)
+	require.Len(t, rec.events, 1)
+	data := rec.events[0].Data
+	assert.Equal(t, events.TypeDatabaseNotification, rec.events[0].Type)
+	assert.Equal(t, "ProvisioningTimeout", data.Notification)
+	assert.Equal(t, "orders", data.Name)
+	assert.Equal(t, database.ReasonProvisioningTimeout, data.Reason)
+}
+
+type notifierFunc func(ctx context.Context, n notify.Notification) error
+
+func (f notifierFunc) Notify(ctx context.Context, n notify.Notification) error { return f(ctx, n) }
+
+func TestKafkaTransport_ProducesKeyedRecords(t *testing.T) {
+	var gotPath, gotType string
+	var body struct {
+		Records []struct {
+			Key   string       `json:"key"`
+			Value events.Event `json:"value"`
+		} `json:"records"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotType = r.URL.Path, r.Header.Get("Content-Type")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer srv.Close()
+
+	tr := events.NewKafkaTransport(srv.URL+"/", "daap.events", time.Second)
+	require.NoError(t, tr.Send(context.Background(), []events.Event{{Type: events.TypeDatabaseDeleted, Subject: "db-1"}}))
+
+	assert.Equal(t, "/topics/daap.events", gotPath)
+	assert.Equal(t, "application/vnd.kafka.json.v2+json", gotType)
+	require.Len(t, body.Records, 1)
+	assert.Equal(t, "db-1", body.Records[0].Key)
+	assert.Equal(t, events.TypeDatabaseDeleted, body.Records[0].Value.Type)
+}
+
+// fakeNATS accepts one connection and records the subjects published to it.
+// It answers every PING with PONG, or with -ERR when reject is set.
+func fakeNATS(t *testing.T, reject bool) (addr string, subjects chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = ln.Close() })
+
+	subjects = make(chan string, 10)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = io.WriteString(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		r := bufio.NewReader(conn)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) == 0:
+			case fields[0] == "PUB" && len(fields) == 3:
+				n, _ := strconv.Atoi(fields[2])
+				if _, err := io.ReadFull(r, make([]byte, n+2)); err != nil {
+					return
+				}
+				subjects <- fields[1]
+			case fields[0] == "PING":
+				if reject {
+					_, _ = io.WriteString(conn, "-ERR 'Authorization Violation'\r\n")
+				} else {
+					_, _ = io.WriteString(conn, "PONG\r\n")
+				}
+			}
+		}
+	}()
+	return ln.Addr().String(), subjects
+}
+
+func TestNATSTransport_PublishesPerTypeSubjects(t *testing.T) {
+	addr, subjects := fakeNATS(t, false)
+	tr, err := events.NewNATSTransport("nats://"+addr, "daap.events", time.Second)
+	require.NoError(t, err)
+
+	require.NoError(t, tr.Send(context.Background(), []events.Event{
+		{Type: events.TypeDatabaseCreated},
+		{Type: events.TypeDatabaseStatusChanged},
+	}))
+	assert.Equal(t, "daap.events.database.created", <-subjects)
+	assert.Equal(t, "daap.events.database.status_changed", <-subjects)
+}
+
+func TestNATSTransport_ServerError(t *testing.T) {
+	addr, _ := fakeNATS(t, true)
+	tr, err := events.NewNATSTransport("nats://"+addr, "daap.events", time.Second)
+	require.NoError(t, err)
+
+	err = tr.Send(context.Background(), []events.Event{{Type: events.TypeDatabaseCreated}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Authorization Violation")
+}
+
+func TestNewNATSTransport_RejectsOtherSchemes(t *testing.T) {
+	_, err := events.NewNATSTransport("tls://nats:4222", "daap.events", time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,84 @@
+package fake_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+	"github.com/daap14/daap/pkg/provider/providertest"
+)
+
+func TestRepositories_SharedStore(t *testing.T) {
+	repos := fake.NewRepositories()
+	ctx := context.Background()
+
+	tm := &team.Team{Name: "backend", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, repos.Databases.Create(ctx, d))
+
+	got, err := repos.Databases.GetByID(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "backend", got.OwnerTeamName)
+
+	assert.ErrorIs(t, repos.Teams.Delete(ctx, tm.ID), team.ErrTeamHasUsers)
+}
+
+func TestProvider_RecordsCalls(t *testing.T) {
+	p := fake.NewProvider()
+	ctx := context.Background()
+	db := provider.ProviderDatabase{ID: uuid.New(), Name: "orders"}
+
+	require.NoError(t, p.Apply(ctx, db, "kind: Cluster"))
+	require.NoError(t, p.Delete(ctx, db))
+
+	health, err := p.CheckHealth(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, "provisioning", health.Status)
+
+	require.Len(t, p.ApplyCalls(), 1)
+	assert.Equal(t, "kind: Cluster", p.ApplyCalls()[0].Manifests)
+	assert.Len(t, p.DeleteCalls(), 1)
+	assert.Len(t, p.CheckHealthCalls(), 1)
+
+	p.Reset()
+	assert.Empty(t, p.ApplyCalls())
+}
+
+func TestProvider_SetHealthAndOverrides(t *testing.T) {
+	p := fake.NewProvider()
+	ctx := context.Background()
+	db := provider.ProviderDatabase{ID: uuid.New(), Name: "orders"}
+
+	p.SetHealth(db.ID, provider.HealthResult{Status: "ready"})
+	health, err := p.CheckHealth(ctx, db)
+	require.NoError(t, err)
+	assert.Equal(t, "ready", health.Status)
+
+	applyErr := errors.New("boom")
+	p.ApplyFn = func(_ context.Context, _ provider.ProviderDatabase, _ string) error { return applyErr }
+	assert.ErrorIs(t, p.Apply(ctx, db, ""), applyErr)
+	assert.Len(t, p.ApplyCalls(), 1)
+}
+
+func TestProvider_Contract(t *testing.T) {
+	providertest.Run(t, providertest.Harness{
+		New:       func(_ *testing.T) provider.Provider { return fake.NewProvider() },
+		Manifests: "kind: Cluster",
+		MarkReady: func(_ *testing.T, p provider.Provider, db provider.ProviderDatabase) {
+			host, port, secret := db.PoolerName, 5432, db.ClusterName+"-app"
+			p.(*fake.Provider).SetHealth(db.ID, provider.HealthResult{
+				Status: "ready", Host: &host, Port: &port, SecretName: &secret,
+			})
+		},
+	})
+}
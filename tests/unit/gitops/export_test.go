@@ -0,0 +1,159 @@
+package gitops_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/gitops"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type fixture struct {
+	repos    *fake.Repositories
+	checkout *team.Team
+	billing  *team.Team
+	tier     *tier.Tier
+	exporter *gitops.Exporter
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &fixture{
+		repos:    repos,
+		checkout: &team.Team{Name: "checkout", Role: "product"},
+		billing:  &team.Team{Name: "billing", Role: "product"},
+	}
+	require.NoError(t, repos.Teams.Create(ctx, f.checkout))
+	require.NoError(t, repos.Teams.Create(ctx, f.billing))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	f.tier = &tier.Tier{Name: "standard", BlueprintID: &bp.ID}
+	require.NoError(t, repos.Tiers.Create(ctx, f.tier))
+
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", fake.NewProvider())
+	f.exporter = gitops.New(repos.Databases, repos.Tiers, repos.Blueprints, registry)
+	return f
+}
+
+func (f *fixture) seed(t *testing.T, name string, owner *team.Team, tierID *uuid.UUID) {
+	t.Helper()
+	db := &database.Database{Name: name, OwnerTeamID: owner.ID, TierID: tierID, Namespace: "default", Environment: "dev"}
+	require.NoError(t, f.repos.Databases.Create(context.Background(), db))
+}
+
+// readBundle returns the regular files of a tar.gz bundle in archive order.
+func readBundle(t *testing.T, data []byte) ([]string, map[string]string) {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var names []string
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+		files[hdr.Name] = string(content)
+	}
+	return names, files
+}
+
+func TestExport_OneFilePerDatabaseByTeam(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	f.seed(t, "orders", f.checkout, &f.tier.ID)
+	f.seed(t, "carts", f.checkout, &f.tier.ID)
+	f.seed(t, "invoices", f.billing, &f.tier.ID)
+
+	var buf bytes.Buffer
+	summary, err := f.exporter.Export(context.Background(), &buf, gitops.Filter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, summary.Databases)
+	assert.Empty(t, summary.Skipped)
+	names, files := readBundle(t, buf.Bytes())
+	assert.Equal(t, []string{"billing/invoices.yaml", "checkout/carts.yaml", "checkout/orders.yaml"}, names)
+
+	orders := files["checkout/orders.yaml"]
+	assert.Contains(t, orders, "# database: orders\n")
+	assert.Contains(t, orders, "# team: checkout\n")
+	assert.Contains(t, orders, "# environment: dev\n")
+	assert.Contains(t, orders, "# tier: standard\n")
+	assert.Contains(t, orders, "# blueprint: cnpg-standard (provider cnpg)\n")
+	assert.Contains(t, orders, "kind: Cluster\n")
+}
+
+func TestExport_IsDeterministic(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	f.seed(t, "orders", f.checkout, &f.tier.ID)
+	f.seed(t, "invoices", f.billing, &f.tier.ID)
+
+	var first, second bytes.Buffer
+	_, err := f.exporter.Export(context.Background(), &first, gitops.Filter{})
+	require.NoError(t, err)
+	_, err = f.exporter.Export(context.Background(), &second, gitops.Filter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, first.Bytes(), second.Bytes())
+}
+
+func TestExport_TeamFilter(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	f.seed(t, "orders", f.checkout, &f.tier.ID)
+	f.seed(t, "invoices", f.billing, &f.tier.ID)
+
+	var buf bytes.Buffer
+	_, err := f.exporter.Export(context.Background(), &buf, gitops.Filter{OwnerTeamID: &f.billing.ID})
+	require.NoError(t, err)
+
+	names, _ := readBundle(t, buf.Bytes())
+	assert.Equal(t, []string{"billing/invoices.yaml"}, names)
+}
+
+func TestExport_SkipsUnrenderableDatabases(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	ctx := context.Background()
+	bare := &tier.Tier{Name: "bare"}
+	require.NoError(t, f.repos.Tiers.Create(ctx, bare))
+	f.seed(t, "orders", f.checkout, &f.tier.ID)
+	f.seed(t, "legacy", f.checkout, nil)
+	f.seed(t, "scratch", f.billing, &bare.ID)
+
+	var buf bytes.Buffer
+	summary, err := f.exporter.Export(ctx, &buf, gitops.Filter{})
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, summary.Databases)
+	assert.Equal(t, []string{"billing/scratch: tier bare has no blueprint", "checkout/legacy: no tier"}, summary.Skipped)
+	names, files := readBundle(t, buf.Bytes())
+	assert.Equal(t, []string{"checkout/orders.yaml", gitops.SkippedFile}, names)
+	assert.Equal(t, "billing/scratch: tier bare has no blueprint\ncheckout/legacy: no tier\n", files[gitops.SkippedFile])
+}
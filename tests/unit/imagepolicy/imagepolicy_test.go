@@ -0,0 +1,125 @@
+package imagepolicy_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/imagepolicy"
+)
+
+// fakeResolver resolves references to digests from a map, recording the
+// references it was asked for.
+type fakeResolver struct {
+	digests map[string]string
+	asked   []string
+}
+
+func (f *fakeResolver) Digest(_ context.Context, ref string) (string, error) {
+	f.asked = append(f.asked, ref)
+	if d, ok := f.digests[ref]; ok {
+		return d, nil
+	}
+	return "", errors.New("manifest unknown")
+}
+
+func TestPolicy_PinMirrors(t *testing.T) {
+	t.Parallel()
+	policy, err := imagepolicy.New("registry.internal/mirror/", nil)
+	require.NoError(t, err)
+
+	pins, err := policy.Pin(context.Background(), []string{
+		"ghcr.io/cloudnative-pg/postgresql:16.4",
+		"postgres:16",
+		"bitnami/pgbouncer:1.23",
+		"localhost:5000/tools/backup@sha256:abc",
+		"registry.internal/mirror/quay.io/prometheus/exporter:v1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []database.ImagePin{
+		{Reference: "ghcr.io/cloudnative-pg/postgresql:16.4", Image: "registry.internal/mirror/ghcr.io/cloudnative-pg/postgresql:16.4"},
+		{Reference: "postgres:16", Image: "registry.internal/mirror/docker.io/library/postgres:16"},
+		{Reference: "bitnami/pgbouncer:1.23", Image: "registry.internal/mirror/docker.io/bitnami/pgbouncer:1.23"},
+		{Reference: "localhost:5000/tools/backup@sha256:abc", Image: "registry.internal/mirror/localhost:5000/tools/backup@sha256:abc", Digest: "sha256:abc"},
+		{Reference: "registry.internal/mirror/quay.io/prometheus/exporter:v1", Image: "registry.internal/mirror/quay.io/prometheus/exporter:v1"},
+	}, pins)
+}
+
+func TestPolicy_PinDigests(t *testing.T) {
+	t.Parallel()
+	resolver := &fakeResolver{digests: map[string]string{
+		"registry.internal/ghcr.io/cloudnative-pg/postgresql:16.4": "sha256:1111",
+	}}
+	policy, err := imagepolicy.New("registry.internal", resolver)
+	require.NoError(t, err)
+
+	pins, err := policy.Pin(context.Background(), []string{
+		"ghcr.io/cloudnative-pg/postgresql:16.4",
+		"ghcr.io/cloudnative-pg/pgbouncer@sha256:2222",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []database.ImagePin{
+		{
+			Reference: "ghcr.io/cloudnative-pg/postgresql:16.4",
+			Image:     "registry.internal/ghcr.io/cloudnative-pg/postgresql:16.4@sha256:1111",
+			Digest:    "sha256:1111",
+		},
+		{
+			Reference: "ghcr.io/cloudnative-pg/pgbouncer@sha256:2222",
+			Image:     "registry.internal/ghcr.io/cloudnative-pg/pgbouncer@sha256:2222",
+			Digest:    "sha256:2222",
+		},
+	}, pins)
+	assert.Equal(t, []string{"registry.internal/ghcr.io/cloudnative-pg/postgresql:16.4"}, resolver.asked,
+		"tags resolve on the mirror; digests are kept")
+
+	_, err = policy.Pin(context.Background(), []string{"ghcr.io/cloudnative-pg/postgresql:17"})
+	assert.ErrorContains(t, err, "resolving image ghcr.io/cloudnative-pg/postgresql:17: manifest unknown")
+}
+
+func TestNew_InvalidMirror(t *testing.T) {
+	t.Parallel()
+	for _, mirror := range []string{"https://registry.internal", "registry.internal/mirror@sha256:abc"} {
+		_, err := imagepolicy.New(mirror, nil)
+		assert.Error(t, err, mirror)
+	}
+}
+
+func TestRegistryResolver_Digest(t *testing.T) {
+	t.Parallel()
+	var srv *httptest.Server
+	srv = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			assert.Equal(t, "registry", r.URL.Query().Get("service"))
+			assert.Equal(t, "repository:cnpg/postgresql:pull", r.URL.Query().Get("scope"))
+			_, _ = w.Write([]byte(`{"token": "t0ken"}`))
+		case r.Header.Get("Authorization") != "Bearer t0ken":
+			w.Header().Set("WWW-Authenticate",
+				`Bearer realm="`+srv.URL+`/token",service="registry",scope="repository:cnpg/postgresql:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.Method == http.MethodHead && r.URL.Path == "/v2/cnpg/postgresql/manifests/16.4":
+			assert.Contains(t, r.Header.Get("Accept"), "application/vnd.oci.image.index.v1+json")
+			w.Header().Set("Docker-Content-Digest", "sha256:feed")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	host := strings.TrimPrefix(srv.URL, "https://")
+	resolver := imagepolicy.NewRegistryResolver(srv.Client())
+
+	digest, err := resolver.Digest(context.Background(), host+"/cnpg/postgresql:16.4")
+	require.NoError(t, err)
+	assert.Equal(t, "sha256:feed", digest)
+
+	_, err = resolver.Digest(context.Background(), host+"/cnpg/postgresql:99")
+	assert.ErrorContains(t, err, "404 Not Found")
+}
@@ -0,0 +1,108 @@
+package insights_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/insights"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// stubReader returns fixed statements per database name and records the
+// limits it was asked for.
+type stubReader struct {
+	mu     sync.Mutex
+	stats  map[string][]database.QueryStat
+	errs   map[string]error
+	limits []int
+}
+
+func (r *stubReader) TopStatements(_ context.Context, db *database.Database, limit int) ([]database.QueryStat, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = append(r.limits, limit)
+	if err := r.errs[db.Name]; err != nil {
+		return nil, err
+	}
+	return append([]database.QueryStat(nil), r.stats[db.Name]...), nil
+}
+
+func createDatabase(t *testing.T, repos *fake.Repositories, owner team.Team, name, status string, optedIn bool) *database.Database {
+	t.Helper()
+	ctx := context.Background()
+	db := &database.Database{Name: name, OwnerTeamID: owner.ID, Namespace: "db", QueryInsights: optedIn}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+	_, err := repos.Databases.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: status})
+	require.NoError(t, err)
+	return db
+}
+
+func TestCollector_RunOnce(t *testing.T) {
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	tm := team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, &tm))
+
+	orders := createDatabase(t, repos, tm, "orders", "ready", true)
+	optedOut := createDatabase(t, repos, tm, "search", "ready", false)
+	provisioning := createDatabase(t, repos, tm, "billing", "provisioning", true)
+	broken := createDatabase(t, repos, tm, "carts", "ready", true)
+
+	reader := &stubReader{
+		stats: map[string][]database.QueryStat{
+			"orders": {
+				{QueryID: -42, Query: "SELECT * FROM orders WHERE email = 'alice@example.com' AND id = $1", Calls: 10, TotalTimeMs: 50, MeanTimeMs: 5, Rows: 10},
+				{QueryID: 7, Query: "SET application_name = 'worker-3'", Calls: 2, TotalTimeMs: 0.5, MeanTimeMs: 0.25},
+			},
+			"search":  {{QueryID: 1, Query: "SELECT 1"}},
+			"billing": {{QueryID: 1, Query: "SELECT 1"}},
+		},
+		errs: map[string]error{"carts": errors.New("pg_stat_statements is not installed")},
+	}
+	collector := insights.New(repos.Databases, repos.Queries, reader, time.Minute, time.Hour, insights.WithTop(5))
+
+	collector.RunOnce(ctx)
+
+	snapshot, err := repos.Queries.Latest(ctx, orders.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []database.QueryStat{
+		{QueryID: -42, Query: "SELECT * FROM orders WHERE email = ? AND id = $1", Calls: 10, TotalTimeMs: 50, MeanTimeMs: 5, Rows: 10},
+		{QueryID: 7, Query: "SET application_name = ?", Calls: 2, TotalTimeMs: 0.5, MeanTimeMs: 0.25},
+	}, snapshot.Statements, "literals are redacted before the snapshot is stored")
+	assert.Equal(t, []int{5, 5}, reader.limits, "only ready, opted-in databases are read")
+
+	for _, db := range []*database.Database{optedOut, provisioning, broken} {
+		_, err := repos.Queries.Latest(ctx, db.ID)
+		assert.ErrorIs(t, err, database.ErrQuerySnapshotNotFound, db.Name)
+	}
+}
+
+func TestCollector_PrunesExpiredSnapshots(t *testing.T) {
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	tm := team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, &tm))
+	orders := createDatabase(t, repos, tm, "orders", "ready", false)
+	require.NoError(t, repos.Queries.Record(ctx, &database.QuerySnapshot{DatabaseID: orders.ID}))
+
+	now := time.Now()
+	keep := insights.New(repos.Databases, repos.Queries, &stubReader{}, time.Minute, time.Hour,
+		insights.WithClock(func() time.Time { return now }))
+	keep.RunOnce(ctx)
+	_, err := repos.Queries.Latest(ctx, orders.ID)
+	require.NoError(t, err, "a snapshot within the retention is kept, even after opting out")
+
+	expire := insights.New(repos.Databases, repos.Queries, &stubReader{}, time.Minute, time.Hour,
+		insights.WithClock(func() time.Time { return now.Add(2 * time.Hour) }))
+	expire.RunOnce(ctx)
+	_, err = repos.Queries.Latest(ctx, orders.ID)
+	assert.ErrorIs(t, err, database.ErrQuerySnapshotNotFound)
+}
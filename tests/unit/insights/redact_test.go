@@ -0,0 +1,38 @@
+package insights_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/insights"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"parameters kept", "SELECT * FROM orders WHERE id = $1 AND status = $2", "SELECT * FROM orders WHERE id = $1 AND status = $2"},
+		{"strings", "SELECT * FROM users WHERE email = 'alice@example.com'", "SELECT * FROM users WHERE email = ?"},
+		{"doubled quote", "INSERT INTO notes VALUES ('it''s secret', 'x')", "INSERT INTO notes VALUES (?, ?)"},
+		{"escape string", `SELECT E'a\'b', 'c'`, "SELECT ?, ?"},
+		{"prefixed strings", "SELECT B'1010', X'1F', N'name'", "SELECT ?, ?, ?"},
+		{"prefix letter ending an identifier", "SELECT name'x'", "SELECT name?"},
+		{"numbers", "SELECT 42, 3.14, .5, 1e-3, 1_000 FROM t LIMIT 10", "SELECT ?, ?, ?, ?, ? FROM t LIMIT ?"},
+		{"digits in identifiers", "SELECT col1 FROM table2 t3", "SELECT col1 FROM table2 t3"},
+		{"dollar quoted", "DO $$BEGIN PERFORM 'x'; END$$", "DO ?"},
+		{"tagged dollar quoted", "SELECT $fn$ it's $$ secret $fn$ || 'a'", "SELECT ? || ?"},
+		{"quoted identifiers", `SELECT "Weird 'name'" FROM "t"`, `SELECT "Weird 'name'" FROM "t"`},
+		{"utility statement", "ALTER ROLE app PASSWORD 'hunter2'", "ALTER ROLE app PASSWORD ?"},
+		{"comments kept", "SELECT 1 -- it's\n/* don't */ FROM t", "SELECT ? -- it's\n/* don't */ FROM t"},
+		{"unterminated string", "SELECT 'secret", "SELECT ?"},
+		{"cast", "SELECT '2026-01-01'::date", "SELECT ?::date"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, insights.Redact(tt.query))
+		})
+	}
+}
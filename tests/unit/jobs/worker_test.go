@@ -0,0 +1,183 @@
+package jobs_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type fixture struct {
+	repo jobs.Repository
+	db   *database.Database
+	now  time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	owner := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, owner))
+	db := &database.Database{Name: "orders", OwnerTeamID: owner.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+	// Jobs are due from the time they are enqueued, so the worker's clock
+	// starts just ahead of it.
+	return &fixture{repo: repos.Jobs, db: db, now: time.Now().Add(time.Second)}
+}
+
+func (f *fixture) worker(opts ...jobs.Option) *jobs.Worker {
+	opts = append(opts, jobs.WithBackoff(time.Minute), jobs.WithClock(func() time.Time { return f.now }))
+	return jobs.NewWorker(f.repo, time.Second, opts...)
+}
+
+func (f *fixture) enqueue(t *testing.T, jobType string) *jobs.Job {
+	t.Helper()
+	job := &jobs.Job{Type: jobType, DatabaseID: f.db.ID, Params: map[string]string{"onFailure": "error"}}
+	require.NoError(t, f.repo.Enqueue(context.Background(), job))
+	assert.Equal(t, jobs.StatusQueued, job.Status)
+	assert.Equal(t, jobs.DefaultMaxAttempts, job.MaxAttempts)
+	return job
+}
+
+func (f *fixture) get(t *testing.T, id uuid.UUID) jobs.Job {
+	t.Helper()
+	list, err := f.repo.ListByDatabase(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	for _, j := range list {
+		if j.ID == id {
+			return j
+		}
+	}
+	t.Fatalf("job %s not found", id)
+	return jobs.Job{}
+}
+
+func TestWorker_RunsJob(t *testing.T) {
+	f := newFixture(t)
+	job := f.enqueue(t, jobs.TypeProvision)
+	w := f.worker()
+	var ran []string
+	w.Handle(jobs.TypeProvision, func(_ context.Context, j *jobs.Job) error {
+		assert.Equal(t, jobs.StatusRunning, j.Status)
+		ran = append(ran, j.Params["onFailure"])
+		return nil
+	})
+
+	w.RunOnce(context.Background())
+	w.RunOnce(context.Background())
+
+	assert.Equal(t, []string{"error"}, ran, "a succeeded job is not run again")
+	got := f.get(t, job.ID)
+	assert.Equal(t, jobs.StatusSucceeded, got.Status)
+	assert.Equal(t, 1, got.Attempts)
+	assert.NotNil(t, got.CompletedAt)
+}
+
+func TestWorker_RetriesWithBackoff(t *testing.T) {
+	f := newFixture(t)
+	job := f.enqueue(t, jobs.TypeProvision)
+	w := f.worker()
+	calls := 0
+	w.Handle(jobs.TypeProvision, func(context.Context, *jobs.Job) error {
+		calls++
+		return errors.New("connection refused")
+	})
+
+	w.RunOnce(context.Background())
+	got := f.get(t, job.ID)
+	assert.Equal(t, jobs.StatusQueued, got.Status)
+	assert.Equal(t, 1, got.Attempts)
+	assert.Equal(t, "connection refused", got.LastError)
+	assert.WithinDuration(t, f.now.Add(time.Minute), got.RunAfter, time.Second)
+
+	w.RunOnce(context.Background())
+	assert.Equal(t, 1, calls, "the job waits for its backoff")
+
+	f.now = f.now.Add(time.Minute)
+	w.RunOnce(context.Background())
+	got = f.get(t, job.ID)
+	assert.Equal(t, 2, got.Attempts)
+	assert.WithinDuration(t, f.now.Add(2*time.Minute), got.RunAfter, time.Second, "the backoff doubles")
+
+	f.now = f.now.Add(2 * time.Minute)
+	w.RunOnce(context.Background())
+	got = f.get(t, job.ID)
+	assert.Equal(t, jobs.StatusFailed, got.Status, "the job fails after its last attempt")
+	assert.Equal(t, 3, got.Attempts)
+	assert.Equal(t, 3, calls)
+}
+
+func TestWorker_PermanentError(t *testing.T) {
+	f := newFixture(t)
+	job := f.enqueue(t, jobs.TypeProvision)
+	w := f.worker()
+	w.Handle(jobs.TypeProvision, func(context.Context, *jobs.Job) error {
+		return jobs.Permanent(errors.New("provider \"aws\" is not registered"))
+	})
+
+	w.RunOnce(context.Background())
+
+	got := f.get(t, job.ID)
+	assert.Equal(t, jobs.StatusFailed, got.Status)
+	assert.Equal(t, 1, got.Attempts)
+	assert.Equal(t, `provider "aws" is not registered`, got.LastError)
+}
+
+func TestWorker_UnknownType(t *testing.T) {
+	f := newFixture(t)
+	job := f.enqueue(t, "vacuum")
+
+	f.worker().RunOnce(context.Background())
+
+	got := f.get(t, job.ID)
+	assert.Equal(t, jobs.StatusFailed, got.Status)
+	assert.Contains(t, got.LastError, `no handler for job type "vacuum"`)
+}
+
+func TestWorker_RequeuesAbandonedJobs(t *testing.T) {
+	f := newFixture(t)
+	job := f.enqueue(t, jobs.TypeProvision)
+	// A worker that died mid-attempt leaves the job running.
+	_, err := f.repo.Claim(context.Background(), f.now)
+	require.NoError(t, err)
+
+	w := f.worker(jobs.WithTimeout(time.Minute))
+	ran := 0
+	w.Handle(jobs.TypeProvision, func(context.Context, *jobs.Job) error {
+		ran++
+		return nil
+	})
+	w.RunOnce(context.Background())
+	assert.Zero(t, ran, "a running job is left alone within its timeout")
+
+	f.now = f.now.Add(time.Minute + jobs.RequeueGrace/2)
+	w.RunOnce(context.Background())
+	assert.Zero(t, ran, "a job that timed out is left to its worker to record")
+
+	f.now = f.now.Add(jobs.RequeueGrace)
+	w.RunOnce(context.Background())
+	assert.Equal(t, 1, ran)
+	got := f.get(t, job.ID)
+	assert.Equal(t, jobs.StatusSucceeded, got.Status)
+	assert.Equal(t, 2, got.Attempts, "the abandoned attempt counts")
+}
+
+func TestRepository_ClaimOnce(t *testing.T) {
+	f := newFixture(t)
+	f.enqueue(t, jobs.TypeProvision)
+
+	_, err := f.repo.Claim(context.Background(), f.now)
+	require.NoError(t, err)
+	_, err = f.repo.Claim(context.Background(), f.now)
+	assert.ErrorIs(t, err, jobs.ErrNoJob)
+}
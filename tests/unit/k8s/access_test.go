@@ -0,0 +1,80 @@
+package k8s_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	authorizationfake "k8s.io/client-go/kubernetes/typed/authorization/v1/fake"
+	clientgotesting "k8s.io/client-go/testing"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+// newFakeReviews returns a SelfSubjectAccessReview client that allows a
+// request unless deny returns true for it.
+func newFakeReviews(deny func(*authorizationv1.ResourceAttributes) bool) *authorizationfake.FakeAuthorizationV1 {
+	f := &clientgotesting.Fake{}
+	f.AddReactor("create", "selfsubjectaccessreviews", func(action clientgotesting.Action) (bool, runtime.Object, error) {
+		review := action.(clientgotesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = !deny(attrs)
+		if !review.Status.Allowed {
+			review.Status.Reason = "RBAC: access denied"
+		}
+		return true, review, nil
+	})
+	return &authorizationfake.FakeAuthorizationV1{Fake: f}
+}
+
+func TestRequiredAccess_CoversManagedResources(t *testing.T) {
+	checks := k8s.RequiredAccess([]string{"db-prod", "db-dev"})
+
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-prod", Group: "postgresql.cnpg.io", Resource: "clusters", Verb: "create"})
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-dev", Group: "postgresql.cnpg.io", Resource: "poolers", Verb: "delete"})
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-prod", Resource: "configmaps", Verb: "patch"})
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-dev", Resource: "secrets", Verb: "get"})
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-dev", Resource: "secrets", Verb: "update"})
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-dev", Group: "policy", Resource: "poddisruptionbudgets", Verb: "delete"})
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-prod", Resource: "services", Verb: "get"})
+	assert.Contains(t, checks, k8s.AccessCheck{Namespace: "db-prod", Group: "postgresql.cnpg.io", Resource: "backups", Verb: "create"})
+	assert.Len(t, checks, 66, "6 resources x 5 verbs + secrets get and update + services get, per namespace")
+}
+
+func TestReviewAccess_ReportsMissing(t *testing.T) {
+	reviews := newFakeReviews(func(a *authorizationv1.ResourceAttributes) bool {
+		return a.Resource == "poolers" && a.Verb == "delete"
+	})
+
+	results, err := k8s.ReviewAccess(context.Background(), reviews, k8s.RequiredAccess([]string{"default"}))
+	require.NoError(t, err)
+
+	missing := k8s.MissingAccess(results)
+	require.Len(t, missing, 1)
+	assert.Equal(t, "delete postgresql.cnpg.io/poolers in default", missing[0].String())
+	assert.Equal(t, "RBAC: access denied", missing[0].Reason)
+}
+
+func TestReviewAccess_AllAllowed(t *testing.T) {
+	reviews := newFakeReviews(func(*authorizationv1.ResourceAttributes) bool { return false })
+
+	results, err := k8s.ReviewAccess(context.Background(), reviews, k8s.RequiredAccess([]string{"default"}))
+	require.NoError(t, err)
+	assert.NotEmpty(t, results)
+	assert.Empty(t, k8s.MissingAccess(results))
+}
+
+func TestReviewAccess_APIError(t *testing.T) {
+	f := &clientgotesting.Fake{}
+	f.AddReactor("create", "selfsubjectaccessreviews", func(clientgotesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	_, err := k8s.ReviewAccess(context.Background(), &authorizationfake.FakeAuthorizationV1{Fake: f}, k8s.RequiredAccess([]string{"default"}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+}
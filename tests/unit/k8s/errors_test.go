@@ -0,0 +1,43 @@
+package k8s_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+func TestIsTransient(t *testing.T) {
+	gr := schema.GroupResource{Group: "postgresql.cnpg.io", Resource: "clusters"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"plain error", errors.New("boom"), false},
+		{"deadline exceeded", fmt.Errorf("get: %w", context.DeadlineExceeded), true},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"server timeout", k8serrors.NewServerTimeout(gr, "get", 1), true},
+		{"service unavailable", k8serrors.NewServiceUnavailable("down"), true},
+		{"internal error", k8serrors.NewInternalError(errors.New("etcd")), true},
+		{"too many requests", k8serrors.NewTooManyRequests("slow down", 1), true},
+		{"not found", k8serrors.NewNotFound(gr, "daap-orders"), false},
+		{"forbidden", k8serrors.NewForbidden(gr, "daap-orders", errors.New("rbac")), false},
+		{"invalid", k8serrors.NewBadRequest("bad manifest"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, k8s.IsTransient(tt.err))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package k8s_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+var configMapGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func configMap(namespace, name string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	return obj
+}
+
+func TestNamespaceRouter_RoutesByNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	def := dynamicfake.NewSimpleDynamicClient(scheme)
+	prod := dynamicfake.NewSimpleDynamicClient(scheme)
+	router := k8s.NewNamespaceRouter(def, map[string]dynamic.Interface{"db-prod": prod})
+	ctx := context.Background()
+
+	_, err := router.Resource(configMapGVR).Namespace("db-prod").Create(ctx, configMap("db-prod", "a"), metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = router.Resource(configMapGVR).Namespace("default").Create(ctx, configMap("default", "b"), metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	_, err = prod.Resource(configMapGVR).Namespace("db-prod").Get(ctx, "a", metav1.GetOptions{})
+	assert.NoError(t, err, "mapped namespace must use its own client")
+	_, err = def.Resource(configMapGVR).Namespace("db-prod").Get(ctx, "a", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	_, err = def.Resource(configMapGVR).Namespace("default").Get(ctx, "b", metav1.GetOptions{})
+	assert.NoError(t, err, "unmapped namespace must use the default client")
+}
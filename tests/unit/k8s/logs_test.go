@@ -0,0 +1,38 @@
+package k8s_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+func TestPodLogs(t *testing.T) {
+	var query string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/namespaces/db/pods/daap-orders-1/log", func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		_, _ = w.Write([]byte("database system is ready\n"))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	core, err := corev1client.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+
+	logs, err := k8s.PodLogs(context.Background(), core, "db", "daap-orders-1", "postgres", 500)
+
+	require.NoError(t, err)
+	assert.Equal(t, "database system is ready\n", string(logs))
+	assert.Contains(t, query, "container=postgres")
+	assert.Contains(t, query, "tailLines=500")
+
+	_, err = k8s.PodLogs(context.Background(), core, "db", "daap-orders-2", "postgres", 500)
+	assert.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package k8s_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+// newPoolerMetricsServer serves metrics for pod daap-orders-pooler-a through
+// the pod proxy.
+func newPoolerMetricsServer(t *testing.T, metrics string) corev1client.CoreV1Interface {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/namespaces/db/pods/http:daap-orders-pooler-a:9127/proxy/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(metrics))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	core, err := corev1client.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+	return core
+}
+
+func TestPgBouncerPools(t *testing.T) {
+	core := newPoolerMetricsServer(t, `# HELP cnpg_pgbouncer_pools_cl_active Client connections that are linked to server connection and can process queries.
+# TYPE cnpg_pgbouncer_pools_cl_active gauge
+cnpg_pgbouncer_pools_cl_active{database="app",user="app"} 12
+cnpg_pgbouncer_pools_cl_active{database="app",user="reporting team"} 3
+cnpg_pgbouncer_pools_cl_active{database="pgbouncer",user="pgbouncer"} 1
+cnpg_pgbouncer_pools_cl_waiting{database="app",user="app"} 4 1700000000000
+cnpg_pgbouncer_pools_sv_active{database="app",user="app"} 20
+cnpg_pgbouncer_lists_databases 2
+`)
+
+	pools, err := k8s.PgBouncerPools(context.Background(), core, "db", "daap-orders-pooler-a")
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"cl_active": 15, "cl_waiting": 4, "sv_active": 20}, pools)
+}
+
+func TestPgBouncerPools_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     string
+		metrics string
+	}{
+		{"metrics unavailable", "daap-orders-pooler-b", ""},
+		{"metrics not exported", "daap-orders-pooler-a", "cnpg_pgbouncer_lists_databases 2\n"},
+		{"malformed value", "daap-orders-pooler-a", `cnpg_pgbouncer_pools_cl_active{database="app",user="app"} many` + "\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := newPoolerMetricsServer(t, tt.metrics)
+
+			_, err := k8s.PgBouncerPools(context.Background(), core, "db", tt.pod)
+
+			assert.Error(t, err)
+		})
+	}
+}
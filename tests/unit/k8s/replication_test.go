@@ -0,0 +1,67 @@
+package k8s_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+// newMetricsServer serves metrics for pod daap-orders-2 through the pod proxy.
+func newMetricsServer(t *testing.T, metrics string) corev1client.CoreV1Interface {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/namespaces/db/pods/http:daap-orders-2:9187/proxy/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_, _ = w.Write([]byte(metrics))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	core, err := corev1client.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+	return core
+}
+
+func TestReplicationLag(t *testing.T) {
+	core := newMetricsServer(t, `# HELP cnpg_pg_replication_lag_seconds_total unrelated metric sharing the prefix
+cnpg_pg_replication_lag_seconds_total 99
+# HELP cnpg_pg_replication_lag Replication lag behind primary in seconds
+# TYPE cnpg_pg_replication_lag gauge
+cnpg_pg_replication_lag 1.5
+`)
+
+	lag, err := k8s.ReplicationLag(context.Background(), core, "db", "daap-orders-2")
+
+	require.NoError(t, err)
+	assert.Equal(t, 1500*time.Millisecond, lag)
+}
+
+func TestReplicationLag_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     string
+		metrics string
+	}{
+		{"metrics unavailable", "daap-orders-3", ""},
+		{"metric not exported", "daap-orders-2", "cnpg_collector_up 1\n"},
+		{"malformed value", "daap-orders-2", "cnpg_pg_replication_lag NaN-ish\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := newMetricsServer(t, tt.metrics)
+
+			_, err := k8s.ReplicationLag(context.Background(), core, "db", tt.pod)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package k8s_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+
+	"github.com/daap14/daap/internal/k8s"
+)
+
+const statsSummary = `{
+  "node": {"nodeName": "node-a"},
+  "pods": [
+    {"podRef": {"name": "other", "namespace": "db"}, "volume": [
+      {"name": "pgdata", "usedBytes": 1, "pvcRef": {"name": "other", "namespace": "db"}}
+    ]},
+    {"podRef": {"name": "daap-orders-1", "namespace": "db"}, "volume": [
+      {"name": "kube-api-access", "usedBytes": 4096},
+      {"name": "pgdata", "usedBytes": 8589934592, "pvcRef": {"name": "daap-orders-1", "namespace": "db"}}
+    ]}
+  ]
+}`
+
+// newStatsServer serves a pod on node-a and node-a's stats summary.
+func newStatsServer(t *testing.T, nodeName string) corev1client.CoreV1Interface {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/namespaces/db/pods/daap-orders-1", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"daap-orders-1","namespace":"db"},"spec":{"nodeName":"` + nodeName + `"}}`))
+	})
+	mux.HandleFunc("/api/v1/nodes/node-a/proxy/stats/summary", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(statsSummary))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	core, err := corev1client.NewForConfig(&rest.Config{Host: srv.URL})
+	require.NoError(t, err)
+	return core
+}
+
+func TestPVCUsedBytes(t *testing.T) {
+	core := newStatsServer(t, "node-a")
+
+	used, err := k8s.PVCUsedBytes(context.Background(), core, "db", "daap-orders-1", "daap-orders-1")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(8<<30), used)
+}
+
+func TestPVCUsedBytes_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		node string
+		pod  string
+		pvc  string
+	}{
+		{"pod not found", "node-a", "missing", "missing"},
+		{"pod not scheduled", "", "daap-orders-1", "daap-orders-1"},
+		{"claim not reported", "node-a", "daap-orders-1", "daap-orders-wal-1"},
+		{"node stats unavailable", "node-b", "daap-orders-1", "daap-orders-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core := newStatsServer(t, tt.node)
+
+			_, err := k8s.PVCUsedBytes(context.Background(), core, "db", tt.pod, tt.pvc)
+
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package logging_test
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/api/handler"
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/logging"
+	"github.com/daap14/daap/internal/provider/cnpg"
+	"github.com/daap14/daap/internal/reconciler"
+)
+
+// recorder is a slog.Handler keeping every record it is given.
+type recorder struct {
+	mu      sync.Mutex
+	records []slog.Record
+}
+
+func (r *recorder) Enabled(context.Context, slog.Level) bool { return true }
+func (r *recorder) Handle(_ context.Context, rec slog.Record) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.records = append(r.records, rec)
+	return nil
+}
+func (r *recorder) WithAttrs([]slog.Attr) slog.Handler { return r }
+func (r *recorder) WithGroup(string) slog.Handler      { return r }
+
+func attrs(rec slog.Record) map[string]any {
+	m := map[string]any{}
+	rec.Attrs(func(a slog.Attr) bool {
+		m[a.Key] = a.Value.Any()
+		return true
+	})
+	return m
+}
+
+func pcOf(fn any) uintptr { return reflect.ValueOf(fn).Pointer() }
+
+func TestModule(t *testing.T) {
+	assert.Equal(t, "reconciler", logging.Module(pcOf(reconciler.New)))
+	assert.Equal(t, "http", logging.Module(pcOf(handler.NewHealthHandler)))
+	assert.Equal(t, "provider", logging.Module(pcOf(cnpg.New)))
+	assert.Equal(t, "auth", logging.Module(pcOf(auth.NewService)))
+	assert.Equal(t, "", logging.Module(pcOf(TestModule)), "code outside internal has no module")
+	assert.Equal(t, "", logging.Module(0))
+}
+
+func TestHandler_ModuleLevels(t *testing.T) {
+	rec := &recorder{}
+	levels := logging.NewLevels(slog.LevelInfo, map[string]slog.Level{"reconciler": slog.LevelDebug, "http": slog.LevelWarn})
+	h := logging.NewHandler(rec, levels, nil)
+	ctx := context.Background()
+
+	assert.True(t, h.Enabled(ctx, slog.LevelDebug), "a module logs at debug")
+	for _, r := range []slog.Record{
+		slog.NewRecord(time.Now(), slog.LevelDebug, "reconciling", pcOf(reconciler.New)),
+		slog.NewRecord(time.Now(), slog.LevelDebug, "checking", pcOf(auth.NewService)),
+		slog.NewRecord(time.Now(), slog.LevelInfo, "request served", pcOf(handler.NewHealthHandler)),
+		slog.NewRecord(time.Now(), slog.LevelWarn, "slow request", pcOf(handler.NewHealthHandler)),
+		slog.NewRecord(time.Now(), slog.LevelInfo, "started", pcOf(auth.NewService)),
+	} {
+		require.NoError(t, h.Handle(ctx, r))
+	}
+
+	require.Len(t, rec.records, 3)
+	assert.Equal(t, "reconciling", rec.records[0].Message)
+	assert.Equal(t, "reconciler", attrs(rec.records[0])["module"])
+	assert.Equal(t, "slow request", rec.records[1].Message)
+	assert.Equal(t, "started", rec.records[2].Message)
+
+	levels.Set(slog.LevelWarn, nil)
+	assert.False(t, h.Enabled(ctx, slog.LevelInfo), "levels change at runtime")
+}
+
+func TestHandler_SamplesDebug(t *testing.T) {
+	rec := &recorder{}
+	sampler := logging.NewSampler(2)
+	h := logging.NewHandler(rec, logging.NewLevels(slog.LevelDebug, nil), sampler)
+	ctx := context.Background()
+	pc := pcOf(reconciler.New)
+
+	start := time.Now()
+	for i := range 5 {
+		require.NoError(t, h.Handle(ctx, slog.NewRecord(start.Add(time.Duration(i)*time.Millisecond), slog.LevelDebug, "reconciling", pc)))
+	}
+	require.NoError(t, h.Handle(ctx, slog.NewRecord(start, slog.LevelDebug, "other message", pc)))
+	require.NoError(t, h.Handle(ctx, slog.NewRecord(start, slog.LevelInfo, "reconciling", pc)), "info is never sampled")
+	require.Len(t, rec.records, 4, "2 of 5 debug records, the other message and the info record")
+
+	require.NoError(t, h.Handle(ctx, slog.NewRecord(start.Add(time.Second), slog.LevelDebug, "reconciling", pc)))
+	require.Len(t, rec.records, 5)
+	assert.Equal(t, int64(3), attrs(rec.records[4])["sampledOut"], "the next logged record counts those dropped")
+
+	sampler.SetRate(0)
+	for range 5 {
+		require.NoError(t, h.Handle(ctx, slog.NewRecord(start.Add(time.Second), slog.LevelDebug, "reconciling", pc)))
+	}
+	assert.Len(t, rec.records, 10, "a rate of zero logs every record")
+}
@@ -0,0 +1,66 @@
+package metrics_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/metrics"
+)
+
+func TestCounter_WriteText(t *testing.T) {
+	reg := metrics.NewRegistry()
+	c := reg.NewCounter("daap_test_total", "A test counter.")
+	c.Inc()
+	c.Add(2)
+
+	var buf strings.Builder
+	reg.WriteText(&buf)
+
+	assert.Equal(t, "# HELP daap_test_total A test counter.\n# TYPE daap_test_total counter\ndaap_test_total 3\n", buf.String())
+}
+
+func TestHistogram_WriteText(t *testing.T) {
+	reg := metrics.NewRegistry()
+	h := reg.NewHistogram("daap_test_seconds", "A test histogram.", []float64{1, 2.5})
+	h.Observe(0.5)
+	h.Observe(1)
+	h.Observe(2)
+	h.Observe(10)
+
+	var buf strings.Builder
+	reg.WriteText(&buf)
+
+	want := "# HELP daap_test_seconds A test histogram.\n" +
+		"# TYPE daap_test_seconds histogram\n" +
+		"daap_test_seconds_bucket{le=\"1\"} 2\n" +
+		"daap_test_seconds_bucket{le=\"2.5\"} 3\n" +
+		"daap_test_seconds_bucket{le=\"+Inf\"} 4\n" +
+		"daap_test_seconds_sum 13.5\n" +
+		"daap_test_seconds_count 4\n"
+	assert.Equal(t, want, buf.String())
+	assert.Equal(t, uint64(4), h.Count())
+}
+
+func TestRegistry_DuplicateNamePanics(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.NewCounter("daap_dup_total", "first")
+	assert.Panics(t, func() { reg.NewCounter("daap_dup_total", "second") })
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	reg := metrics.NewRegistry()
+	reg.NewCounter("daap_b_total", "b")
+	reg.NewCounter("daap_a_total", "a")
+
+	rec := httptest.NewRecorder()
+	reg.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+	assert.Less(t, strings.Index(rec.Body.String(), "daap_a_total"), strings.Index(rec.Body.String(), "daap_b_total"),
+		"metrics must be sorted by name")
+}
@@ -0,0 +1,95 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/notify"
+)
+
+func TestWebhookNotifier_PostsJSON(t *testing.T) {
+	var got notify.Notification
+	var contentType string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		contentType = r.Header.Get("Content-Type")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	n := notify.Notification{
+		Event:      "ProvisioningTimeout",
+		Message:    "database orders still provisioning after 1h0m0s; marked as error",
+		DatabaseID: uuid.New(),
+		Database:   "orders",
+		OwnerTeam:  "backend",
+		Reason:     "PROVISIONING_TIMEOUT",
+		Time:       time.Date(2026, 2, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	err := notify.NewWebhookNotifier(srv.URL, time.Second).Notify(context.Background(), n)
+	require.NoError(t, err)
+	assert.Equal(t, "application/json", contentType)
+	assert.Equal(t, n, got)
+}
+
+func TestWebhookNotifier_Non2xxIsError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+
+	err := notify.NewWebhookNotifier(srv.URL, time.Second).Notify(context.Background(), notify.Notification{Event: "ProvisioningTimeout"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
+
+type recordingNotifier struct {
+	got []notify.Notification
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, n notify.Notification) error {
+	r.got = append(r.got, n)
+	return nil
+}
+
+type stubSilencer struct {
+	silenced map[uuid.UUID]bool
+	err      error
+}
+
+func (s *stubSilencer) Silenced(_ context.Context, id uuid.UUID) (bool, error) {
+	return s.silenced[id], s.err
+}
+
+func TestSilencingNotifier(t *testing.T) {
+	acked, other := uuid.New(), uuid.New()
+	next := &recordingNotifier{}
+	n := notify.NewSilencingNotifier(next, &stubSilencer{silenced: map[uuid.UUID]bool{acked: true}})
+
+	require.NoError(t, n.Notify(context.Background(), notify.Notification{Event: "StorageLimitReached", DatabaseID: acked}))
+	require.NoError(t, n.Notify(context.Background(), notify.Notification{Event: "StorageLimitReached", DatabaseID: other}))
+	require.NoError(t, n.Notify(context.Background(), notify.Notification{Event: "RolloutPaused"}))
+
+	require.Len(t, next.got, 2)
+	assert.Equal(t, other, next.got[0].DatabaseID)
+	assert.Equal(t, "RolloutPaused", next.got[1].Event)
+}
+
+func TestSilencingNotifier_DeliversWhenSilencerFails(t *testing.T) {
+	next := &recordingNotifier{}
+	n := notify.NewSilencingNotifier(next, &stubSilencer{err: assert.AnError})
+
+	require.NoError(t, n.Notify(context.Background(), notify.Notification{Event: "ProvisioningTimeout", DatabaseID: uuid.New()}))
+
+	assert.Len(t, next.got, 1)
+}
@@ -0,0 +1,138 @@
+package organization_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestQuotas_CheckDatabase(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	quotas := organization.NewQuotas(repos.Organizations, repos.Teams, repos.Databases, nil)
+
+	org := &organization.Organization{Name: "acme", MaxDatabases: 2}
+	require.NoError(t, repos.Organizations.Create(ctx, org))
+	var teams []*team.Team
+	for _, name := range []string{"checkout", "search"} {
+		tm := &team.Team{Name: name, Role: "product", OrganizationID: &org.ID}
+		require.NoError(t, repos.Teams.Create(ctx, tm))
+		teams = append(teams, tm)
+	}
+	outside := &team.Team{Name: "ops", Role: "platform"}
+	require.NoError(t, repos.Teams.Create(ctx, outside))
+
+	// The quota counts the databases of every team of the organization.
+	_, err := quotas.CheckDatabase(ctx, teams[0].ID)
+	require.NoError(t, err)
+	require.NoError(t, repos.Databases.Create(ctx, &database.Database{Name: "orders", OwnerTeamID: teams[0].ID}))
+	_, err = quotas.CheckDatabase(ctx, teams[1].ID)
+	require.NoError(t, err)
+	require.NoError(t, repos.Databases.Create(ctx, &database.Database{Name: "catalog", OwnerTeamID: teams[1].ID}))
+
+	_, err = quotas.CheckDatabase(ctx, teams[0].ID)
+	assert.ErrorIs(t, err, organization.ErrQuotaExceeded)
+	assert.Contains(t, err.Error(), "organization acme has 2 of 2 databases")
+
+	count, err := quotas.CountDatabases(ctx, org.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Teams outside organizations have no quota.
+	require.NoError(t, repos.Databases.Create(ctx, &database.Database{Name: "metrics", OwnerTeamID: outside.ID}))
+	_, err = quotas.CheckDatabase(ctx, outside.ID)
+	assert.NoError(t, err)
+
+	// A quota of 0 means no quota.
+	unlimited := 0
+	_, err = repos.Organizations.Update(ctx, org.ID, organization.UpdateFields{MaxDatabases: &unlimited})
+	require.NoError(t, err)
+	_, err = quotas.CheckDatabase(ctx, teams[0].ID)
+	assert.NoError(t, err)
+}
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func TestQuotas_Warning(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	notifier := &recordingNotifier{}
+	quotas := organization.NewQuotas(repos.Organizations, repos.Teams, repos.Databases, notifier)
+
+	org := &organization.Organization{Name: "acme", MaxDatabases: 5, QuotaWarningPercent: 60}
+	require.NoError(t, repos.Organizations.Create(ctx, org))
+	tm := &team.Team{Name: "checkout", Role: "product", OrganizationID: &org.ID}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+
+	// 60% of 5 is 3: the third, fourth and fifth databases warn, and only
+	// the third, crossing the threshold, notifies.
+	var warnings []string
+	for i, name := range []string{"a", "b", "c", "d", "e"} {
+		warning, err := quotas.CheckDatabase(ctx, tm.ID)
+		require.NoError(t, err, name)
+		if i < 2 {
+			assert.Empty(t, warning, name)
+		} else {
+			assert.NotEmpty(t, warning, name)
+		}
+		warnings = append(warnings, warning)
+		require.NoError(t, repos.Databases.Create(ctx, &database.Database{Name: name, OwnerTeamID: tm.ID}))
+	}
+	assert.Equal(t, "organization acme will have 3 of 5 databases; creating databases fails once the quota is reached", warnings[2])
+
+	require.Len(t, notifier.notifications, 1)
+	n := notifier.notifications[0]
+	assert.Equal(t, "QuotaWarning", n.Event)
+	assert.Equal(t, "checkout", n.OwnerTeam)
+	assert.Equal(t, warnings[2], n.Message)
+
+	_, err := quotas.CheckDatabase(ctx, tm.ID)
+	assert.ErrorIs(t, err, organization.ErrQuotaExceeded)
+
+	// A percentage of 0 turns warnings off.
+	off, larger := 0, 10
+	_, err = repos.Organizations.Update(ctx, org.ID, organization.UpdateFields{MaxDatabases: &larger, QuotaWarningPercent: &off})
+	require.NoError(t, err)
+	warning, err := quotas.CheckDatabase(ctx, tm.ID)
+	require.NoError(t, err)
+	assert.Empty(t, warning)
+}
+
+func TestOrganization_WarningThreshold(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		max, percent, want int
+	}{
+		{max: 10, percent: 80, want: 8},
+		{max: 3, percent: 80, want: 3},
+		{max: 1, percent: 50, want: 1},
+		{max: 0, percent: 80, want: 0},
+		{max: 10, percent: 0, want: 0},
+	}
+	for _, tt := range tests {
+		o := organization.Organization{MaxDatabases: tt.max, QuotaWarningPercent: tt.percent}
+		assert.Equal(t, tt.want, o.WarningThreshold(), "%d%% of %d", tt.percent, tt.max)
+	}
+}
@@ -0,0 +1,138 @@
+package placement_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/placement"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// fill creates n databases of tm in namespace ns.
+func fill(t *testing.T, repos *fake.Repositories, tm *team.Team, ns string, n int) {
+	t.Helper()
+	for i := range n {
+		db := &database.Database{Name: fmt.Sprintf("%s-%s-%d", tm.Name, ns, i), OwnerTeamID: tm.ID, Namespace: ns}
+		require.NoError(t, repos.Databases.Create(context.Background(), db))
+	}
+}
+
+func TestEngine_Place(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+
+	engine, err := placement.New(repos.Databases, placement.Config{
+		Capacities:   map[string]int{"db-pool-a": 4, "db-pool-b": 10, "db-premium": 2},
+		TierAffinity: map[string][]string{"premium": {"db-premium"}},
+		TeamPins:     map[string][]string{"payments": {"db-pool-b", "db-premium"}},
+	})
+	require.NoError(t, err)
+	fill(t, repos, tm, "db-pool-a", 2)
+	fill(t, repos, tm, "db-pool-b", 3)
+	fill(t, repos, tm, "db-premium", 2)
+
+	// The lowest share of capacity in use wins, not the fewest databases.
+	p, err := engine.Place(ctx, "checkout", "standard")
+	require.NoError(t, err)
+	assert.Equal(t, "db-pool-b", p.Namespace)
+	assert.Equal(t, "least loaded eligible namespace, with 3 of 10 databases", p.Reason)
+	assert.False(t, p.DecidedAt.IsZero())
+	assert.Equal(t, []database.PlacementCandidate{
+		{Namespace: "db-pool-a", Databases: 2, Capacity: 4},
+		{Namespace: "db-pool-b", Databases: 3, Capacity: 10},
+		{Namespace: "db-premium", Databases: 2, Capacity: 2, Excluded: placement.ExcludedFull},
+	}, p.Candidates)
+
+	// Tier affinity leaves only the full premium namespace.
+	_, err = engine.Place(ctx, "checkout", "premium")
+	assert.ErrorIs(t, err, placement.ErrNoCapacity)
+	assert.Contains(t, err.Error(), "db-pool-a: tier affinity, db-pool-b: tier affinity, db-premium: at capacity")
+
+	// Team pins exclude the other namespaces.
+	payments := &team.Team{Name: "payments", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, payments))
+	fill(t, repos, payments, "db-pool-b", 5)
+	p, err = engine.Place(ctx, "payments", "standard")
+	require.NoError(t, err)
+	assert.Equal(t, "db-pool-b", p.Namespace)
+	assert.Equal(t, placement.ExcludedTeamPin, p.Candidates[0].Excluded)
+}
+
+func TestEngine_PlaceTie(t *testing.T) {
+	t.Parallel()
+	repos := fake.NewRepositories()
+	engine, err := placement.New(repos.Databases, placement.Config{
+		Capacities: map[string]int{"db-b": 5, "db-a": 5},
+	})
+	require.NoError(t, err)
+
+	p, err := engine.Place(context.Background(), "checkout", "standard")
+	require.NoError(t, err)
+	assert.Equal(t, "db-a", p.Namespace)
+}
+
+func TestNew_InvalidConfig(t *testing.T) {
+	t.Parallel()
+	repos := fake.NewRepositories()
+	tests := []struct {
+		name string
+		cfg  placement.Config
+		want string
+	}{
+		{
+			name: "invalid namespace",
+			cfg:  placement.Config{Capacities: map[string]int{"DB_Pool": 5}},
+			want: `namespace "DB_Pool" is invalid`,
+		},
+		{
+			name: "no capacity",
+			cfg:  placement.Config{Capacities: map[string]int{"db-a": 0}},
+			want: "namespace db-a must take at least one database, not 0",
+		},
+		{
+			name: "unknown tier namespace",
+			cfg: placement.Config{
+				Capacities:   map[string]int{"db-a": 5},
+				TierAffinity: map[string][]string{"premium": {"db-premium"}},
+			},
+			want: "tier premium is constrained to namespace db-premium, which is not a placement namespace",
+		},
+		{
+			name: "unknown team namespace",
+			cfg: placement.Config{
+				Capacities: map[string]int{"db-a": 5},
+				TeamPins:   map[string][]string{"payments": {"db-b"}},
+			},
+			want: "team payments is constrained to namespace db-b, which is not a placement namespace",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := placement.New(repos.Databases, tt.cfg)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.want)
+		})
+	}
+}
+
+func TestParseConstraints(t *testing.T) {
+	t.Parallel()
+	got := placement.ParseConstraints(map[string]string{
+		"premium":  "db-premium",
+		"payments": "db-pool-b | db-premium|",
+	})
+	assert.Equal(t, map[string][]string{
+		"premium":  {"db-premium"},
+		"payments": {"db-pool-b", "db-premium"},
+	}, got)
+}
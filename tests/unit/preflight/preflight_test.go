@@ -0,0 +1,230 @@
+package preflight_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/k8s"
+	"github.com/daap14/daap/internal/preflight"
+	"github.com/daap14/daap/internal/store"
+	"github.com/daap14/daap/internal/store/memory"
+	"github.com/daap14/daap/migrations"
+)
+
+// --- Fakes ---
+
+type fakeDiscovery struct {
+	resources []string
+	err       error
+}
+
+func (f *fakeDiscovery) ServerResourcesForGroupVersion(gv string) (*metav1.APIResourceList, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	list := &metav1.APIResourceList{GroupVersion: gv}
+	for _, r := range f.resources {
+		list.APIResources = append(list.APIResources, metav1.APIResource{Name: r})
+	}
+	return list, nil
+}
+
+type fakeAccess struct {
+	denied []k8s.AccessCheck
+}
+
+func (f *fakeAccess) CheckAccess(_ context.Context, namespaces []string) ([]k8s.AccessResult, error) {
+	var results []k8s.AccessResult
+	for _, c := range k8s.RequiredAccess(namespaces) {
+		allowed := true
+		for _, d := range f.denied {
+			if d == c {
+				allowed = false
+			}
+		}
+		results = append(results, k8s.AccessResult{AccessCheck: c, Allowed: allowed})
+	}
+	return results, nil
+}
+
+type fakeSchema struct {
+	version uint
+	dirty   bool
+	err     error
+}
+
+func (f *fakeSchema) SchemaVersion(context.Context) (uint, bool, error) {
+	return f.version, f.dirty, f.err
+}
+
+func storageClass(name string, isDefault bool) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("storage.k8s.io/v1")
+	obj.SetKind("StorageClass")
+	obj.SetName(name)
+	if isDefault {
+		obj.SetAnnotations(map[string]string{"storageclass.kubernetes.io/is-default-class": "true"})
+	}
+	return obj
+}
+
+func newDynamic(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	gvr := schema.GroupVersionResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses"}
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{gvr: "StorageClassList"}, objects...)
+}
+
+// healthyRunner returns a Runner whose every check passes.
+func healthyRunner(t *testing.T) *preflight.Runner {
+	t.Helper()
+	return &preflight.Runner{
+		Discovery:             &fakeDiscovery{resources: []string{"clusters", "poolers", "scheduledbackups"}},
+		Access:                &fakeAccess{},
+		Dynamic:               newDynamic(storageClass("standard", true)),
+		Blueprints:            memory.New().Blueprints(),
+		Schema:                &fakeSchema{version: 13},
+		Namespaces:            []string{"default"},
+		ExpectedSchemaVersion: 13,
+	}
+}
+
+func findCheck(t *testing.T, report preflight.Report, name string) preflight.Check {
+	t.Helper()
+	for _, c := range report.Checks {
+		if c.Name == name {
+			return c
+		}
+	}
+	t.Fatalf("check %q not in report", name)
+	return preflight.Check{}
+}
+
+// --- Tests ---
+
+func TestRun_AllPass(t *testing.T) {
+	report := healthyRunner(t).Run(context.Background())
+
+	assert.True(t, report.Passed)
+	require.Len(t, report.Checks, 4)
+	for _, c := range report.Checks {
+		assert.Equal(t, preflight.StatusPass, c.Status, c.Name)
+	}
+}
+
+func TestRun_NoKubernetesClient(t *testing.T) {
+	r := healthyRunner(t)
+	r.Discovery, r.Access, r.Dynamic = nil, nil, nil
+
+	report := r.Run(context.Background())
+
+	assert.False(t, report.Passed)
+	assert.Equal(t, preflight.StatusFail, findCheck(t, report, "cnpg-crds").Status)
+	assert.Equal(t, preflight.StatusFail, findCheck(t, report, "rbac").Status)
+	assert.Equal(t, preflight.StatusFail, findCheck(t, report, "storage-classes").Status)
+}
+
+func TestCheckCRDs(t *testing.T) {
+	gr := schema.GroupResource{Group: "postgresql.cnpg.io", Resource: "v1"}
+
+	r := healthyRunner(t)
+	r.Discovery = &fakeDiscovery{err: k8serrors.NewNotFound(gr, "")}
+	c := findCheck(t, r.Run(context.Background()), "cnpg-crds")
+	assert.Equal(t, preflight.StatusFail, c.Status)
+	assert.Contains(t, c.Message, "not installed")
+
+	r.Discovery = &fakeDiscovery{resources: []string{"clusters"}}
+	c = findCheck(t, r.Run(context.Background()), "cnpg-crds")
+	assert.Equal(t, preflight.StatusFail, c.Status)
+	assert.Equal(t, []string{"poolers.postgresql.cnpg.io", "scheduledbackups.postgresql.cnpg.io"}, c.Details)
+}
+
+func TestCheckRBAC_MissingPermissions(t *testing.T) {
+	r := healthyRunner(t)
+	r.Access = &fakeAccess{denied: []k8s.AccessCheck{
+		{Namespace: "default", Group: "postgresql.cnpg.io", Resource: "clusters", Verb: "delete"},
+	}}
+
+	report := r.Run(context.Background())
+
+	assert.False(t, report.Passed)
+	c := findCheck(t, report, "rbac")
+	assert.Equal(t, preflight.StatusFail, c.Status)
+	assert.Equal(t, []string{"delete postgresql.cnpg.io/clusters in default"}, c.Details)
+}
+
+func TestCheckStorageClasses(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("none exist", func(t *testing.T) {
+		r := healthyRunner(t)
+		r.Dynamic = newDynamic()
+		assert.Equal(t, preflight.StatusFail, findCheck(t, r.Run(ctx), "storage-classes").Status)
+	})
+
+	t.Run("no default warns", func(t *testing.T) {
+		r := healthyRunner(t)
+		r.Dynamic = newDynamic(storageClass("fast", false))
+		report := r.Run(ctx)
+		assert.Equal(t, preflight.StatusWarn, findCheck(t, report, "storage-classes").Status)
+		assert.True(t, report.Passed, "warnings do not fail the report")
+	})
+
+	t.Run("blueprint references missing class", func(t *testing.T) {
+		r := healthyRunner(t)
+		db := memory.New()
+		require.NoError(t, db.Blueprints().Create(ctx, &blueprint.Blueprint{
+			Name: "ssd", Provider: "cnpg",
+			Manifests: "kind: Cluster\nspec:\n  storage:\n    storageClass: premium-ssd\n    size: 10Gi\n",
+		}))
+		require.NoError(t, db.Blueprints().Create(ctx, &blueprint.Blueprint{
+			Name: "templated", Provider: "cnpg",
+			Manifests: "kind: Cluster\nspec:\n  storage:\n    storageClass: {{ .Tier }}\n",
+		}))
+		r.Blueprints = db.Blueprints()
+
+		c := findCheck(t, r.Run(ctx), "storage-classes")
+		assert.Equal(t, preflight.StatusFail, c.Status)
+		assert.Equal(t, []string{"blueprint ssd references missing storage class premium-ssd"}, c.Details)
+	})
+}
+
+func TestCheckMigrations(t *testing.T) {
+	tests := []struct {
+		name   string
+		schema *fakeSchema
+		want   preflight.Status
+	}{
+		{"current", &fakeSchema{version: 13}, preflight.StatusPass},
+		{"behind", &fakeSchema{version: 12}, preflight.StatusFail},
+		{"never migrated", &fakeSchema{version: 0}, preflight.StatusFail},
+		{"dirty", &fakeSchema{version: 13, dirty: true}, preflight.StatusFail},
+		{"newer than build", &fakeSchema{version: 14}, preflight.StatusWarn},
+		{"memory backend", &fakeSchema{err: store.ErrSchemaNotTracked}, preflight.StatusSkip},
+		{"query error", &fakeSchema{err: errors.New("connection refused")}, preflight.StatusFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := healthyRunner(t)
+			r.Schema = tt.schema
+			assert.Equal(t, tt.want, findCheck(t, r.Run(context.Background()), "migrations").Status)
+		})
+	}
+}
+
+func TestMigrationsLatest(t *testing.T) {
+	latest, err := migrations.Latest()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, latest, uint(13))
+}
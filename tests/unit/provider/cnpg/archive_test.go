@@ -0,0 +1,133 @@
+package cnpg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+var backupsGVR = schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "backups"}
+
+// archivableCluster is storageCluster with an object store configured.
+func archivableCluster() *unstructured.Unstructured {
+	cluster := storageCluster("10Gi")
+	_ = unstructured.SetNestedMap(cluster.Object, map[string]interface{}{
+		"destinationPath": "s3://wal/daap-system",
+		"s3Credentials":   map[string]interface{}{"inheritFromIAMRole": true},
+	}, "spec", "backup", "barmanObjectStore")
+	return cluster
+}
+
+// archiveBackup is the archive Backup of sampleDB with the given status.
+func archiveBackup(status map[string]interface{}) *unstructured.Unstructured {
+	db := sampleDB()
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Backup",
+		"metadata":   map[string]interface{}{"name": db.ClusterName + "-archive", "namespace": db.Namespace},
+		"spec":       map[string]interface{}{"cluster": map[string]interface{}{"name": db.ClusterName}},
+		"status":     status,
+	}}
+}
+
+func TestArchive_StartsBackup(t *testing.T) {
+	ctx := context.Background()
+	db := sampleDB()
+	client := newFakeClient(archivableCluster())
+	p := cnpgprovider.New(client)
+
+	archive, err := p.Archive(ctx, db, "s3://archives/checkout/")
+	require.NoError(t, err)
+	assert.Equal(t, provider.Archive{URL: "s3://archives/checkout/daap-system"}, archive)
+
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	destination, _, _ := unstructured.NestedString(cluster.Object, "spec", "backup", "barmanObjectStore", "destinationPath")
+	assert.Equal(t, "s3://archives/checkout/daap-system", destination)
+	_, kept, _ := unstructured.NestedMap(cluster.Object, "spec", "backup", "barmanObjectStore", "s3Credentials")
+	assert.True(t, kept, "the blueprint's credentials are kept")
+
+	backup, err := client.Resource(backupsGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName+"-archive", metav1.GetOptions{})
+	require.NoError(t, err)
+	owner, _, _ := unstructured.NestedString(backup.Object, "spec", "cluster", "name")
+	assert.Equal(t, db.ClusterName, owner)
+	method, _, _ := unstructured.NestedString(backup.Object, "spec", "method")
+	assert.Equal(t, "barmanObjectStore", method)
+	assert.Equal(t, db.Name, backup.GetLabels()["daap.io/database"], "the backup is deleted with the database's resources")
+}
+
+func TestArchive_Progress(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  map[string]interface{}
+		want    provider.Archive
+		wantErr string
+	}{
+		{
+			name:   "running",
+			status: map[string]interface{}{"phase": "running", "destinationPath": "s3://archives/checkout/daap-system"},
+			want:   provider.Archive{URL: "s3://archives/checkout/daap-system"},
+		},
+		{
+			name: "completed",
+			status: map[string]interface{}{
+				"phase": "completed", "destinationPath": "s3://archives/checkout/daap-system",
+				"serverName": "daap-orders-db", "backupId": "20261017T120000",
+			},
+			want: provider.Archive{URL: "s3://archives/checkout/daap-system/daap-orders-db/base/20261017T120000", Done: true},
+		},
+		{
+			name:    "failed",
+			status:  map[string]interface{}{"phase": "failed", "error": "access denied"},
+			wantErr: "access denied",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := cnpgprovider.New(newFakeClient(archivableCluster(), archiveBackup(tt.status)))
+
+			archive, err := p.Archive(context.Background(), sampleDB(), "s3://archives/checkout")
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, archive)
+		})
+	}
+}
+
+func TestArchive_FailedBackupRetried(t *testing.T) {
+	ctx := context.Background()
+	db := sampleDB()
+	client := newFakeClient(archivableCluster(), archiveBackup(map[string]interface{}{"phase": "failed", "error": "access denied"}))
+	p := cnpgprovider.New(client)
+
+	_, err := p.Archive(ctx, db, "s3://archives/checkout")
+	require.Error(t, err)
+
+	archive, err := p.Archive(ctx, db, "s3://archives/checkout")
+	require.NoError(t, err)
+	assert.False(t, archive.Done)
+	backup, err := client.Resource(backupsGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName+"-archive", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, hasStatus, _ := unstructured.NestedMap(backup.Object, "status")
+	assert.False(t, hasStatus, "a new backup is taken")
+}
+
+func TestArchive_NoObjectStore(t *testing.T) {
+	p := cnpgprovider.New(newFakeClient(storageCluster("10Gi")))
+
+	_, err := p.Archive(context.Background(), sampleDB(), "s3://archives/checkout")
+
+	assert.ErrorContains(t, err, "has no object store")
+}
@@ -0,0 +1,133 @@
+package cnpg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+const initdbManifest = `---
+apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: daap-{{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  instances: 2
+  bootstrap:
+    initdb:
+      database: app
+      owner: app
+`
+
+// renamedDB is sampleDB under the name "orders".
+func renamedDB() provider.ProviderDatabase {
+	db := sampleDB()
+	db.Name = "orders"
+	db.ClusterName = "daap-orders"
+	db.PoolerName = "daap-orders-pooler"
+	return db
+}
+
+func TestClone_ReplicatesFromSource(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient(storageCluster("10Gi"))
+	p := cnpgprovider.New(client)
+	source, db := sampleDB(), renamedDB()
+
+	require.NoError(t, p.Clone(context.Background(), db, source, initdbManifest))
+
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(context.Background(), db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "orders", cluster.GetLabels()["daap.io/database"])
+
+	basebackup, _, _ := unstructured.NestedMap(cluster.Object, "spec", "bootstrap", "pg_basebackup")
+	assert.Equal(t, map[string]any{"source": "daap-orders-db", "database": "app", "owner": "app"}, basebackup)
+	_, hasInitdb, _ := unstructured.NestedMap(cluster.Object, "spec", "bootstrap", "initdb")
+	assert.False(t, hasInitdb)
+
+	replica, _, _ := unstructured.NestedMap(cluster.Object, "spec", "replica")
+	assert.Equal(t, map[string]any{"enabled": true, "source": "daap-orders-db"}, replica)
+
+	externals, _, _ := unstructured.NestedSlice(cluster.Object, "spec", "externalClusters")
+	require.Len(t, externals, 1)
+	external := externals[0].(map[string]any)
+	assert.Equal(t, "daap-orders-db", external["name"])
+	host, _, _ := unstructured.NestedString(external, "connectionParameters", "host")
+	assert.Equal(t, "daap-orders-db-rw.daap-system.svc", host)
+	user, _, _ := unstructured.NestedString(external, "connectionParameters", "user")
+	assert.Equal(t, "streaming_replica", user)
+	cert, _, _ := unstructured.NestedString(external, "sslCert", "name")
+	assert.Equal(t, "daap-orders-db-replication", cert)
+	ca, _, _ := unstructured.NestedString(external, "sslRootCert", "name")
+	assert.Equal(t, "daap-orders-db-ca", ca)
+
+	// The source is untouched.
+	original, err := client.Resource(clustersGVR).Namespace(source.Namespace).Get(context.Background(), source.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	_, hasReplica, _ := unstructured.NestedMap(original.Object, "spec", "replica")
+	assert.False(t, hasReplica)
+}
+
+func TestClone_OtherNamespaceNotSupported(t *testing.T) {
+	t.Parallel()
+	p := cnpgprovider.New(newFakeClient(storageCluster("10Gi")))
+	db := renamedDB()
+	db.Namespace = "payments"
+
+	err := p.Clone(context.Background(), db, sampleDB(), initdbManifest)
+	assert.True(t, errors.Is(err, provider.ErrNotSupported))
+}
+
+func TestPromote_FencesSourceAndPromotesCopy(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient(storageCluster("10Gi"))
+	p := cnpgprovider.New(client)
+	source, db := sampleDB(), renamedDB()
+	require.NoError(t, p.Clone(context.Background(), db, source, initdbManifest))
+
+	require.NoError(t, p.Promote(context.Background(), db, source))
+
+	original, err := client.Resource(clustersGVR).Namespace(source.Namespace).Get(context.Background(), source.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, `["*"]`, original.GetAnnotations()["cnpg.io/fencedInstances"])
+
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(context.Background(), db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	enabled, _, _ := unstructured.NestedBool(cluster.Object, "spec", "replica", "enabled")
+	assert.False(t, enabled)
+}
+
+func TestUnfence_LiftsFence(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient(storageCluster("10Gi"))
+	p := cnpgprovider.New(client)
+	source, db := sampleDB(), renamedDB()
+	require.NoError(t, p.Clone(context.Background(), db, source, initdbManifest))
+	require.NoError(t, p.Promote(context.Background(), db, source))
+
+	require.NoError(t, p.Unfence(context.Background(), source))
+
+	original, err := client.Resource(clustersGVR).Namespace(source.Namespace).Get(context.Background(), source.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, original.GetAnnotations(), "cnpg.io/fencedInstances")
+	// Lifting a fence that is gone is a no-op.
+	require.NoError(t, p.Unfence(context.Background(), source))
+}
+
+func TestPromote_SourceMissing(t *testing.T) {
+	t.Parallel()
+	p := cnpgprovider.New(newFakeClient())
+
+	err := p.Promote(context.Background(), renamedDB(), sampleDB())
+	assert.Error(t, err)
+}
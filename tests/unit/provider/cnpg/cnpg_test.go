@@ -8,17 +8,24 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/managedfields"
 	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
 
 	"github.com/daap14/daap/internal/provider"
 	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+	"github.com/daap14/daap/internal/requestid"
 )
 
-// newFakeClient creates a fake dynamic client with CNPG types registered.
+// newFakeClient creates a fake dynamic client with CNPG types registered. Its
+// tracker records managedFields, so server-side apply behaves like the API
+// server's: it creates missing objects and reports field conflicts.
 func newFakeClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
 	scheme := runtime.NewScheme()
 	// Register CNPG types
@@ -29,8 +36,10 @@ func newFakeClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
 		{Group: "postgresql.cnpg.io", Version: "v1", Kind: "PoolerList"},
 		{Group: "postgresql.cnpg.io", Version: "v1", Kind: "ScheduledBackup"},
 		{Group: "postgresql.cnpg.io", Version: "v1", Kind: "ScheduledBackupList"},
+		{Group: "postgresql.cnpg.io", Version: "v1", Kind: "Backup"},
+		{Group: "postgresql.cnpg.io", Version: "v1", Kind: "BackupList"},
 	} {
-		if gvk.Kind == "ClusterList" || gvk.Kind == "PoolerList" || gvk.Kind == "ScheduledBackupList" {
+		if gvk.Kind == "ClusterList" || gvk.Kind == "PoolerList" || gvk.Kind == "ScheduledBackupList" || gvk.Kind == "BackupList" {
 			scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
 		} else {
 			scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
@@ -40,14 +49,27 @@ func newFakeClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
 	for _, gvk := range []schema.GroupVersionKind{
 		{Group: "", Version: "v1", Kind: "ConfigMap"},
 		{Group: "", Version: "v1", Kind: "ConfigMapList"},
+		{Group: "", Version: "v1", Kind: "Service"},
+		{Group: "", Version: "v1", Kind: "ServiceList"},
+		{Group: "policy", Version: "v1", Kind: "PodDisruptionBudget"},
+		{Group: "policy", Version: "v1", Kind: "PodDisruptionBudgetList"},
 	} {
-		if gvk.Kind == "ConfigMapList" {
+		if gvk.Kind == "ConfigMapList" || gvk.Kind == "ServiceList" || gvk.Kind == "PodDisruptionBudgetList" {
 			scheme.AddKnownTypeWithName(gvk, &unstructured.UnstructuredList{})
 		} else {
 			scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
 		}
 	}
-	return dynamicfake.NewSimpleDynamicClient(scheme, objects...)
+	tracker := clienttesting.NewFieldManagedObjectTracker(scheme,
+		serializer.NewCodecFactory(scheme).UniversalDecoder(), managedfields.NewDeducedTypeConverter())
+	for _, obj := range objects {
+		if err := tracker.Add(obj); err != nil {
+			panic(err)
+		}
+	}
+	client := dynamicfake.NewSimpleDynamicClient(scheme)
+	client.PrependReactor("*", "*", clienttesting.ObjectReaction(tracker))
+	return client
 }
 
 func sampleDB() provider.ProviderDatabase {
@@ -182,6 +204,26 @@ spec:
 	assert.Equal(t, "cnpg", annotations["provider"])
 }
 
+func TestApply_AnnotatesRequestID(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient()
+	p := cnpgprovider.New(client)
+	gvr := schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"}
+
+	ctx := requestid.With(context.Background(), "req-123")
+	require.NoError(t, p.Apply(ctx, sampleDB(), singleDocManifest))
+
+	obj, err := client.Resource(gvr).Namespace("daap-system").Get(context.Background(), "daap-orders-db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "req-123", obj.GetAnnotations()[provider.AnnotationRequestID])
+
+	other := newFakeClient()
+	require.NoError(t, cnpgprovider.New(other).Apply(context.Background(), sampleDB(), singleDocManifest))
+	obj, err = other.Resource(gvr).Namespace("daap-system").Get(context.Background(), "daap-orders-db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, obj.GetAnnotations(), provider.AnnotationRequestID, "no annotation without a request ID")
+}
+
 func TestApply_PreservesExistingLabels(t *testing.T) {
 	t.Parallel()
 	client := newFakeClient()
@@ -215,6 +257,97 @@ spec:
 	assert.Equal(t, "daap", labels["app.kubernetes.io/managed-by"])
 }
 
+// applyAs server-side applies a Cluster patch as another field manager.
+func applyAs(t *testing.T, client *dynamicfake.FakeDynamicClient, manager string, spec map[string]any) {
+	t.Helper()
+	gvr := schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"}
+	obj := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Cluster",
+		"metadata":   map[string]any{"name": "daap-orders-db", "namespace": "daap-system"},
+		"spec":       spec,
+	}}
+	_, err := client.Resource(gvr).Namespace("daap-system").Apply(context.Background(), "daap-orders-db", obj,
+		metav1.ApplyOptions{FieldManager: manager, Force: true})
+	require.NoError(t, err)
+}
+
+func getCluster(t *testing.T, client *dynamicfake.FakeDynamicClient) *unstructured.Unstructured {
+	t.Helper()
+	gvr := schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"}
+	obj, err := client.Resource(gvr).Namespace("daap-system").Get(context.Background(), "daap-orders-db", metav1.GetOptions{})
+	require.NoError(t, err)
+	return obj
+}
+
+func TestApply_ServerSideApplyKeepsOtherManagersFields(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient()
+	p := cnpgprovider.New(client)
+	db := sampleDB()
+
+	require.NoError(t, p.Apply(context.Background(), db, singleDocManifest))
+	applyAs(t, client, "cnpg-operator", map[string]any{"enableSuperuserAccess": false})
+
+	// Re-applying, e.g. after a partial failure, leaves fields the blueprint
+	// does not declare alone.
+	require.NoError(t, p.Apply(context.Background(), db, singleDocManifest))
+	require.NoError(t, p.Apply(context.Background(), db, singleDocManifest))
+
+	obj := getCluster(t, client)
+	superuser, found, _ := unstructured.NestedBool(obj.Object, "spec", "enableSuperuserAccess")
+	assert.True(t, found, "field set by the operator was removed")
+	assert.False(t, superuser)
+	instances, _, _ := unstructured.NestedInt64(obj.Object, "spec", "instances")
+	assert.Equal(t, int64(1), instances)
+
+	managers := map[string]bool{}
+	for _, entry := range obj.GetManagedFields() {
+		managers[entry.Manager] = true
+	}
+	assert.True(t, managers[cnpgprovider.FieldManager], "managedFields: %v", managers)
+	assert.True(t, managers["cnpg-operator"], "managedFields: %v", managers)
+}
+
+func TestApply_ConflictingFieldsTakenOver(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient()
+	p := cnpgprovider.New(client)
+	db := sampleDB()
+
+	require.NoError(t, p.Apply(context.Background(), db, singleDocManifest))
+	applyAs(t, client, "kubectl-edit", map[string]any{"instances": int64(5)})
+
+	require.NoError(t, p.Apply(context.Background(), db, singleDocManifest))
+
+	instances, _, _ := unstructured.NestedInt64(getCluster(t, client).Object, "spec", "instances")
+	assert.Equal(t, int64(1), instances, "the blueprint's value should win")
+}
+
+func TestApply_DisruptionBudget(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient()
+	p := cnpgprovider.New(client)
+	db := sampleDB()
+	db.Disruption = provider.Disruption{MinAvailable: 2}
+	budgets := client.Resource(schema.GroupVersionResource{Group: "policy", Version: "v1", Resource: "poddisruptionbudgets"}).
+		Namespace("daap-system")
+
+	require.NoError(t, p.Apply(requestid.With(context.Background(), "req-1"), db, multiDocManifest))
+	budget, err := budgets.Get(context.Background(), "daap-orders-db-daap", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "orders-db", budget.GetLabels()["daap.io/database"])
+	assert.Equal(t, "req-1", budget.GetAnnotations()[provider.AnnotationRequestID])
+	minAvailable, _, _ := unstructured.NestedInt64(budget.Object, "spec", "minAvailable")
+	assert.Equal(t, int64(2), minAvailable)
+
+	// Dropping the minimum deletes the budget.
+	db.Disruption = provider.Disruption{}
+	require.NoError(t, p.Apply(context.Background(), db, multiDocManifest))
+	_, err = budgets.Get(context.Background(), "daap-orders-db-daap", metav1.GetOptions{})
+	assert.True(t, k8serrors.IsNotFound(err), "got %v", err)
+}
+
 func TestApply_InvalidTemplate(t *testing.T) {
 	t.Parallel()
 	client := newFakeClient()
@@ -281,6 +414,56 @@ func TestDelete_NoResources(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func labeledCluster() *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      "daap-orders-db",
+				"namespace": "daap-system",
+				"labels": map[string]any{
+					"daap.io/database":             "orders-db",
+					"app.kubernetes.io/managed-by": "daap",
+				},
+			},
+		},
+	}
+}
+
+func TestDeleteForeground_Gone(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient(labeledCluster())
+	var propagation []metav1.DeletionPropagation
+	client.PrependReactor("delete", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		if opts := action.(clienttesting.DeleteActionImpl).DeleteOptions; opts.PropagationPolicy != nil {
+			propagation = append(propagation, *opts.PropagationPolicy)
+		}
+		return false, nil, nil
+	})
+	p := cnpgprovider.New(client)
+
+	state, err := p.DeleteForeground(context.Background(), sampleDB(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, provider.DeletionGone, state)
+	assert.Equal(t, []metav1.DeletionPropagation{metav1.DeletePropagationForeground}, propagation)
+}
+
+func TestDeleteForeground_FinalizersPending(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient(labeledCluster())
+	// Accept the deletion but keep the object, as the API server does while
+	// finalizers run.
+	client.PrependReactor("delete", "*", func(clienttesting.Action) (bool, runtime.Object, error) {
+		return true, nil, nil
+	})
+	p := cnpgprovider.New(client)
+
+	state, err := p.DeleteForeground(context.Background(), sampleDB(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, provider.DeletionDeleting, state)
+}
+
 // --- CheckHealth Tests ---
 
 func TestCheckHealth_Healthy(t *testing.T) {
@@ -389,6 +572,33 @@ func TestCheckHealth_Error(t *testing.T) {
 	}
 }
 
+func TestCheckHealth_ErrorDetail(t *testing.T) {
+	t.Parallel()
+
+	cluster := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      "daap-orders-db",
+				"namespace": "daap-system",
+			},
+			"status": map[string]any{
+				"phase":       "Failed to create primary",
+				"phaseReason": "storageclass not found",
+			},
+		},
+	}
+
+	p := cnpgprovider.New(newFakeClient(cluster))
+
+	result, err := p.CheckHealth(context.Background(), sampleDB())
+	require.NoError(t, err)
+	assert.Equal(t, "error", result.Status)
+	assert.Equal(t, "FAILED_TO_CREATE_PRIMARY", result.Reason)
+	assert.Equal(t, "Failed to create primary: storageclass not found", result.Message)
+}
+
 func TestCheckHealth_ClusterNotFound(t *testing.T) {
 	t.Parallel()
 	client := newFakeClient()
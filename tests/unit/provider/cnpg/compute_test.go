@@ -0,0 +1,147 @@
+package cnpg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+const sizedManifest = `---
+apiVersion: postgresql.cnpg.io/v1
+kind: Pooler
+metadata:
+  name: daap-{{ .Name }}-pooler
+  namespace: {{ .Namespace }}
+spec:
+  cluster:
+    name: daap-{{ .Name }}
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: daap-{{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  instances: 2
+  resources:
+    requests:
+      cpu: "1500m"
+      memory: 4Gi
+`
+
+func computeCluster() *unstructured.Unstructured {
+	db := sampleDB()
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": db.ClusterName, "namespace": db.Namespace},
+		"spec": map[string]interface{}{
+			"instances": int64(2),
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{"cpu": "2", "memory": "4Gi"},
+			},
+		},
+	}}
+}
+
+func podMetrics(name, cluster string, usage ...[2]string) *unstructured.Unstructured {
+	db := sampleDB()
+	containers := make([]interface{}, len(usage))
+	for i, u := range usage {
+		containers[i] = map[string]interface{}{
+			"name":  "c",
+			"usage": map[string]interface{}{"cpu": u[0], "memory": u[1]},
+		}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": db.Namespace,
+			"labels":    map[string]interface{}{"cnpg.io/cluster": cluster, "cnpg.io/podRole": "instance"},
+		},
+		"containers": containers,
+	}}
+}
+
+func newComputeClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{clustersGVR: "ClusterList", podMetricsGVR: "PodMetricsList"},
+		objects...)
+}
+
+// addPodMetrics creates PodMetrics through the client: the fake tracker
+// cannot infer the "pods" resource from the PodMetrics kind.
+func addPodMetrics(t *testing.T, client *dynamicfake.FakeDynamicClient, metrics ...*unstructured.Unstructured) {
+	t.Helper()
+	for _, m := range metrics {
+		_, err := client.Resource(podMetricsGVR).Namespace(m.GetNamespace()).Create(context.Background(), m, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+}
+
+func TestComputeUsage_BusiestInstance(t *testing.T) {
+	db := sampleDB()
+	client := newComputeClient(computeCluster())
+	addPodMetrics(t, client,
+		podMetrics("daap-orders-db-1", db.ClusterName, [2]string{"250m", "1Gi"}, [2]string{"50m", "128Mi"}),
+		podMetrics("daap-orders-db-2", db.ClusterName, [2]string{"900m", "512Mi"}),
+		podMetrics("daap-other-1", "daap-other", [2]string{"4", "8Gi"}),
+	)
+	p := cnpgprovider.New(client)
+
+	usage, err := p.ComputeUsage(context.Background(), db)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), usage.Requests.CPUMillis)
+	assert.Equal(t, int64(4<<30), usage.Requests.MemoryBytes)
+	assert.Equal(t, int64(900), usage.Used.CPUMillis)
+	assert.Equal(t, int64(1<<30+128<<20), usage.Used.MemoryBytes)
+}
+
+func TestComputeUsage_NoMetrics(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient(computeCluster()))
+
+	_, err := p.ComputeUsage(context.Background(), sampleDB())
+
+	assert.ErrorContains(t, err, "no pod metrics")
+}
+
+func TestComputeUsage_ClusterMissing(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+
+	_, err := p.ComputeUsage(context.Background(), sampleDB())
+
+	assert.Error(t, err)
+}
+
+func TestManifestCompute(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+
+	compute, err := p.ManifestCompute(sizedManifest)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500), compute.CPUMillis)
+	assert.Equal(t, int64(4<<30), compute.MemoryBytes)
+}
+
+func TestManifestCompute_NoRequests(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+
+	_, err := p.ManifestCompute(singleDocManifest)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,55 @@
+package cnpg_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+func TestManifestConnections(t *testing.T) {
+	withParameter := func(value string) string {
+		return `---
+apiVersion: postgresql.cnpg.io/v1
+kind: Pooler
+metadata:
+  name: daap-{{ .Name }}-pooler
+spec:
+  pgbouncer:
+    parameters:
+      max_client_conn: "1000"
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: daap-{{ .Name }}
+spec:
+  instances: 3
+  postgresql:
+    parameters:
+      max_connections: "` + value + `"
+`
+	}
+	p := cnpgprovider.New(newComputeClient())
+
+	n, err := p.ManifestConnections(withParameter("250"))
+	require.NoError(t, err)
+	assert.Equal(t, 250, n, "the Cluster's max_connections, not the pooler's, for the primary only")
+
+	n, err = p.ManifestConnections(singleDocManifest)
+	require.NoError(t, err)
+	assert.Equal(t, 100, n, "PostgreSQL's default")
+
+	_, err = p.ManifestConnections(withParameter("lots"))
+	assert.Error(t, err)
+
+	_, err = p.ManifestConnections(`---
+apiVersion: postgresql.cnpg.io/v1
+kind: Pooler
+metadata:
+  name: daap-{{ .Name }}-pooler
+`)
+	assert.Error(t, err, "no Cluster")
+}
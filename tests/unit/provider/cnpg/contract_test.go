@@ -0,0 +1,63 @@
+package cnpg_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+	"github.com/daap14/daap/pkg/provider/providertest"
+)
+
+var contractGVRs = []schema.GroupVersionResource{
+	{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"},
+	{Group: "postgresql.cnpg.io", Version: "v1", Resource: "poolers"},
+	{Group: "postgresql.cnpg.io", Version: "v1", Resource: "scheduledbackups"},
+	{Group: "", Version: "v1", Resource: "configmaps"},
+}
+
+func TestCNPGProvider_Contract(t *testing.T) {
+	var client *dynamicfake.FakeDynamicClient
+
+	providertest.Run(t, providertest.Harness{
+		New: func(_ *testing.T) provider.Provider {
+			client = newFakeClient()
+			return cnpgprovider.New(client)
+		},
+		Manifests:        multiDocManifest,
+		InvalidManifests: "{{ .Unclosed",
+		Labels: func(t *testing.T, _ provider.Provider, db provider.ProviderDatabase) []map[string]string {
+			var out []map[string]string
+			selector := fmt.Sprintf("%s=%s", provider.LabelDatabase, db.Name)
+			for _, gvr := range contractGVRs {
+				list, err := client.Resource(gvr).Namespace(db.Namespace).List(
+					context.Background(), metav1.ListOptions{LabelSelector: selector},
+				)
+				require.NoError(t, err)
+				for _, item := range list.Items {
+					out = append(out, item.GetLabels())
+				}
+			}
+			return out
+		},
+		MarkReady: func(t *testing.T, _ provider.Provider, db provider.ProviderDatabase) {
+			gvr := contractGVRs[0]
+			obj, err := client.Resource(gvr).Namespace(db.Namespace).Get(
+				context.Background(), db.ClusterName, metav1.GetOptions{},
+			)
+			require.NoError(t, err)
+			require.NoError(t, unstructured.SetNestedField(obj.Object, "Cluster in healthy state", "status", "phase"))
+			_, err = client.Resource(gvr).Namespace(db.Namespace).Update(
+				context.Background(), obj, metav1.UpdateOptions{},
+			)
+			require.NoError(t, err)
+		},
+	})
+}
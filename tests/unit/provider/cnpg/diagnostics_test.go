@@ -0,0 +1,114 @@
+package cnpg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+var eventsGVR = schema.GroupVersionResource{Version: "v1", Resource: "events"}
+
+// stubPodLogs returns logs keyed by pod name and fails for the others.
+type stubPodLogs struct {
+	logs map[string]string
+}
+
+func (s *stubPodLogs) PodLogs(_ context.Context, _, pod, container string, _ int64) ([]byte, error) {
+	logs, ok := s.logs[pod]
+	if !ok || container != "postgres" {
+		return nil, errors.New("no logs for " + pod + "/" + container)
+	}
+	return []byte(logs), nil
+}
+
+func newDiagnosticsClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{clustersGVR: "ClusterList", podsGVR: "PodList", eventsGVR: "EventList"},
+		objects...)
+}
+
+func clusterEvent(name, kind, object, reason, at string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion":     "v1",
+		"kind":           "Event",
+		"metadata":       map[string]interface{}{"name": name, "namespace": sampleDB().Namespace},
+		"involvedObject": map[string]interface{}{"kind": kind, "name": object},
+		"type":           "Normal",
+		"reason":         reason,
+		"message":        reason + " " + object,
+		"lastTimestamp":  at,
+	}}
+}
+
+func diagnosticFiles(files []provider.DiagnosticFile) map[string]string {
+	out := make(map[string]string)
+	for _, f := range files {
+		out[f.Name] = string(f.Content)
+	}
+	return out
+}
+
+func TestDiagnostics(t *testing.T) {
+	db := sampleDB()
+	cluster := storageCluster("10Gi")
+	cluster.SetManagedFields(nil)
+	require.NoError(t, unstructured.SetNestedField(cluster.Object, "Cluster in healthy state", "status", "phase"))
+	client := newDiagnosticsClient(
+		cluster,
+		instancePod("daap-orders-db-1", db.ClusterName, "instance"),
+		instancePod("daap-orders-db-2", db.ClusterName, "instance"),
+		clusterEvent("e1", "Pod", "daap-orders-db-2", "Started", "2026-02-03T09:02:00Z"),
+		clusterEvent("e2", "Cluster", "daap-orders-db", "CreatingInstance", "2026-02-03T09:00:00Z"),
+		clusterEvent("e3", "Deployment", "daap-orders-db-pooler", "ScalingReplicaSet", "2026-02-03T09:01:00Z"),
+		clusterEvent("e4", "Cluster", "daap-orders-db-archive", "CreatingInstance", "2026-02-03T09:00:00Z"),
+		clusterEvent("e5", "Pod", "daap-orders-db-archive-1", "Started", "2026-02-03T09:00:00Z"),
+	)
+	logs := &stubPodLogs{logs: map[string]string{"daap-orders-db-1": "database system is ready\n"}}
+	p := cnpgprovider.New(client, cnpgprovider.WithPodLogs(logs))
+
+	files, err := p.Diagnostics(context.Background(), db)
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "daap-orders-db-2")
+	got := diagnosticFiles(files)
+	assert.Contains(t, got["cluster.yaml"], "phase: Cluster in healthy state")
+	assert.Equal(t, `- message: CreatingInstance daap-orders-db
+  object: Cluster/daap-orders-db
+  reason: CreatingInstance
+  time: "2026-02-03T09:00:00Z"
+  type: Normal
+- message: ScalingReplicaSet daap-orders-db-pooler
+  object: Deployment/daap-orders-db-pooler
+  reason: ScalingReplicaSet
+  time: "2026-02-03T09:01:00Z"
+  type: Normal
+- message: Started daap-orders-db-2
+  object: Pod/daap-orders-db-2
+  reason: Started
+  time: "2026-02-03T09:02:00Z"
+  type: Normal
+`, got["events.yaml"])
+	assert.Equal(t, "database system is ready\n", got["logs/daap-orders-db-1.log"])
+	assert.NotContains(t, got, "logs/daap-orders-db-2.log")
+}
+
+func TestDiagnostics_ClusterMissingWithoutLogs(t *testing.T) {
+	p := cnpgprovider.New(newDiagnosticsClient())
+
+	files, err := p.Diagnostics(context.Background(), sampleDB())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "getting cluster daap-system/daap-orders-db")
+	assert.Equal(t, map[string]string{"events.yaml": "[]\n"}, diagnosticFiles(files))
+}
@@ -0,0 +1,35 @@
+package cnpg_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+func TestRenderManifests_DNSName(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+	db := sampleDB()
+
+	pooler := renderPooler(t, p, db)
+	assert.NotContains(t, pooler["spec"], "serviceTemplate", "a database without a DNS name gets no record")
+
+	db.DNSName = "orders-db.db.example.com"
+	pooler = renderPooler(t, p, db)
+	template := pooler["spec"].(map[string]interface{})["serviceTemplate"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"external-dns.alpha.kubernetes.io/hostname": "orders-db.db.example.com"},
+		template["metadata"].(map[string]interface{})["annotations"])
+	assert.NotContains(t, template, "spec", "a database kept inside the cluster keeps its ClusterIP Service")
+
+	p = cnpgprovider.New(newComputeClient(), cnpgprovider.WithInternalLoadBalancerAnnotations(internalLB))
+	db.Exposure = provider.Exposure{Type: provider.ExposureInternal}
+	pooler = renderPooler(t, p, db)
+	template = pooler["spec"].(map[string]interface{})["serviceTemplate"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"networking.gke.io/load-balancer-type":      "Internal",
+		"external-dns.alpha.kubernetes.io/hostname": "orders-db.db.example.com",
+	}, template["metadata"].(map[string]interface{})["annotations"])
+	assert.Equal(t, map[string]interface{}{"type": "LoadBalancer"}, template["spec"])
+}
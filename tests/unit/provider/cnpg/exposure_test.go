@@ -0,0 +1,126 @@
+package cnpg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+var internalLB = map[string]string{"networking.gke.io/load-balancer-type": "Internal"}
+
+func renderPooler(t *testing.T, p *cnpgprovider.CNPGProvider, db provider.ProviderDatabase) map[string]interface{} {
+	t.Helper()
+	out, err := p.RenderManifests(db, multiDocManifest)
+	require.NoError(t, err)
+	docs := strings.Split(strings.TrimPrefix(out, "---\n"), "---\n")
+	require.Len(t, docs, 2)
+	var pooler map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &pooler))
+	require.Equal(t, "Pooler", pooler["kind"])
+	return pooler
+}
+
+func TestRenderManifests_Exposure(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient(), cnpgprovider.WithInternalLoadBalancerAnnotations(internalLB))
+	db := sampleDB()
+
+	pooler := renderPooler(t, p, db)
+	assert.NotContains(t, pooler["spec"], "serviceTemplate", "a database kept inside the cluster keeps its ClusterIP Service")
+
+	db.Exposure = provider.Exposure{Type: provider.ExposureExternal, AllowedSourceRanges: []string{"203.0.113.0/24"}}
+	pooler = renderPooler(t, p, db)
+	template := pooler["spec"].(map[string]interface{})["serviceTemplate"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"type":                     "LoadBalancer",
+		"loadBalancerSourceRanges": []interface{}{"203.0.113.0/24"},
+	}, template["spec"])
+	assert.NotContains(t, template, "metadata", "an external load balancer gets no internal annotations")
+
+	db.Exposure = provider.Exposure{Type: provider.ExposureInternal}
+	pooler = renderPooler(t, p, db)
+	template = pooler["spec"].(map[string]interface{})["serviceTemplate"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "LoadBalancer"}, template["spec"])
+	assert.Equal(t, map[string]interface{}{"networking.gke.io/load-balancer-type": "Internal"},
+		template["metadata"].(map[string]interface{})["annotations"])
+}
+
+func TestRenderManifests_ExposureUnavailable(t *testing.T) {
+	db := sampleDB()
+
+	db.Exposure = provider.Exposure{Type: provider.ExposureInternal}
+	_, err := cnpgprovider.New(newComputeClient()).RenderManifests(db, multiDocManifest)
+	assert.ErrorContains(t, err, "no internal load balancer annotations are configured",
+		"an internal load balancer without them would face the internet")
+
+	db.Exposure = provider.Exposure{Type: provider.ExposureExternal, AllowedSourceRanges: []string{"203.0.113.0/24"}}
+	_, err = cnpgprovider.New(newComputeClient()).RenderManifests(db, singleDocManifest)
+	assert.ErrorContains(t, err, "its blueprint renders no Pooler daap-orders-db-pooler")
+}
+
+func TestApply_ExposureUnavailableAppliesNothing(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient()
+	p := cnpgprovider.New(client)
+	db := sampleDB()
+	db.Exposure = provider.Exposure{Type: provider.ExposureExternal, AllowedSourceRanges: []string{"203.0.113.0/24"}}
+
+	err := p.Apply(context.Background(), db, singleDocManifest)
+	require.Error(t, err)
+	assert.Empty(t, client.Actions(), "the Cluster is not created without the exposure it was asked for")
+}
+
+func TestCheckHealth_ExternalHost(t *testing.T) {
+	t.Parallel()
+	cluster := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Cluster",
+		"metadata":   map[string]any{"name": "daap-orders-db", "namespace": "daap-system"},
+		"status":     map[string]any{"phase": "Cluster in healthy state"},
+	}}
+	service := func(ingress ...any) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]any{
+			"apiVersion": "v1",
+			"kind":       "Service",
+			"metadata":   map[string]any{"name": "daap-orders-db-pooler", "namespace": "daap-system"},
+			"spec":       map[string]any{"type": "LoadBalancer"},
+			"status":     map[string]any{"loadBalancer": map[string]any{"ingress": ingress}},
+		}}
+	}
+	exposed := sampleDB()
+	exposed.Exposure = provider.Exposure{Type: provider.ExposureExternal, AllowedSourceRanges: []string{"203.0.113.0/24"}}
+
+	tests := []struct {
+		name string
+		db   provider.ProviderDatabase
+		svc  *unstructured.Unstructured
+		want string
+	}{
+		{"hostname", exposed, service(map[string]any{"hostname": "a1b2.elb.amazonaws.com"}), "a1b2.elb.amazonaws.com"},
+		{"address", exposed, service(map[string]any{"ip": "198.51.100.7"}), "198.51.100.7"},
+		{"not provisioned yet", exposed, service(), ""},
+		{"no service yet", exposed, nil, ""},
+		{"kept inside the cluster", sampleDB(), service(map[string]any{"ip": "198.51.100.7"}), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := newFakeClient(cluster.DeepCopy())
+			if tt.svc != nil {
+				client = newFakeClient(cluster.DeepCopy(), tt.svc)
+			}
+
+			result, err := cnpgprovider.New(client).CheckHealth(context.Background(), tt.db)
+			require.NoError(t, err)
+			assert.Equal(t, "ready", result.Status)
+			assert.Equal(t, tt.want, result.ExternalHost)
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package cnpg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+func TestFreeze_HibernatesCluster(t *testing.T) {
+	ctx := context.Background()
+	db := sampleDB()
+	client := newStorageClient(storageCluster("10Gi"))
+	p := cnpgprovider.New(client)
+
+	require.NoError(t, p.Freeze(ctx, db))
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "on", cluster.GetAnnotations()["cnpg.io/hibernation"])
+
+	require.NoError(t, p.Unfreeze(ctx, db))
+	cluster, err = client.Resource(clustersGVR).Namespace(db.Namespace).Get(ctx, db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "off", cluster.GetAnnotations()["cnpg.io/hibernation"])
+}
+
+func TestFreeze_ClusterNotFound(t *testing.T) {
+	p := cnpgprovider.New(newStorageClient())
+	assert.Error(t, p.Freeze(context.Background(), sampleDB()))
+	assert.Error(t, p.Unfreeze(context.Background(), sampleDB()))
+}
@@ -0,0 +1,105 @@
+package cnpg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+// stubReplicationStats returns lag keyed by pod name.
+type stubReplicationStats struct {
+	lag   map[string]time.Duration
+	err   error
+	calls []string
+}
+
+func (s *stubReplicationStats) ReplicationLag(_ context.Context, _, pod string) (time.Duration, error) {
+	s.calls = append(s.calls, pod)
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.lag[pod], nil
+}
+
+func haCluster(phase string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": "postgresql.cnpg.io/v1",
+			"kind":       "Cluster",
+			"metadata": map[string]any{
+				"name":      "daap-orders-db",
+				"namespace": "daap-system",
+			},
+			"status": map[string]any{
+				"phase":          phase,
+				"instances":      int64(3),
+				"readyInstances": int64(2),
+				"currentPrimary": "daap-orders-db-2",
+				"instanceNames":  []any{"daap-orders-db-1", "daap-orders-db-2", "daap-orders-db-3"},
+			},
+		},
+	}
+}
+
+func TestCheckHealth_Instances(t *testing.T) {
+	t.Parallel()
+	stats := &stubReplicationStats{lag: map[string]time.Duration{
+		"daap-orders-db-1": 200 * time.Millisecond,
+		"daap-orders-db-3": 3 * time.Second,
+	}}
+	p := cnpgprovider.New(newFakeClient(haCluster("Cluster in healthy state")), cnpgprovider.WithReplicationStats(stats))
+
+	result, err := p.CheckHealth(context.Background(), sampleDB())
+	require.NoError(t, err)
+
+	assert.Equal(t, "ready", result.Status)
+	require.NotNil(t, result.Instances)
+	assert.Equal(t, 3, result.Instances.Total)
+	assert.Equal(t, 2, result.Instances.Ready)
+	assert.Equal(t, "daap-orders-db-2", result.Instances.Primary)
+	require.NotNil(t, result.Instances.ReplicationLag)
+	assert.Equal(t, 3*time.Second, *result.Instances.ReplicationLag)
+	assert.ElementsMatch(t, []string{"daap-orders-db-1", "daap-orders-db-3"}, stats.calls, "the primary has no lag to read")
+}
+
+func TestCheckHealth_InstancesLagUnknown(t *testing.T) {
+	t.Parallel()
+
+	t.Run("without replication stats", func(t *testing.T) {
+		p := cnpgprovider.New(newFakeClient(haCluster("Setting up primary")))
+		result, err := p.CheckHealth(context.Background(), sampleDB())
+		require.NoError(t, err)
+		assert.Equal(t, "provisioning", result.Status)
+		require.NotNil(t, result.Instances)
+		assert.Equal(t, 2, result.Instances.Ready)
+		assert.Nil(t, result.Instances.ReplicationLag)
+	})
+
+	t.Run("metrics unreachable", func(t *testing.T) {
+		stats := &stubReplicationStats{err: errors.New("connection refused")}
+		p := cnpgprovider.New(newFakeClient(haCluster("Cluster in healthy state")), cnpgprovider.WithReplicationStats(stats))
+		result, err := p.CheckHealth(context.Background(), sampleDB())
+		require.NoError(t, err, "an unreadable lag must not fail the health check")
+		require.NotNil(t, result.Instances)
+		assert.Nil(t, result.Instances.ReplicationLag)
+	})
+}
+
+func TestCheckHealth_NoInstancesReported(t *testing.T) {
+	t.Parallel()
+	cluster := haCluster("Setting up primary")
+	unstructured.RemoveNestedField(cluster.Object, "status", "instances")
+	p := cnpgprovider.New(newFakeClient(cluster))
+
+	result, err := p.CheckHealth(context.Background(), sampleDB())
+	require.NoError(t, err)
+	assert.Nil(t, result.Instances)
+}
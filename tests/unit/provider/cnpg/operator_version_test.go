@@ -0,0 +1,60 @@
+package cnpg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+// versionedPod is an instance pod created by the given operator version, if
+// any.
+func versionedPod(name, version string) *unstructured.Unstructured {
+	pod := instancePod(name, sampleDB().ClusterName, "instance")
+	if version != "" {
+		pod.SetAnnotations(map[string]string{"cnpg.io/operatorVersion": version})
+	}
+	return pod
+}
+
+func TestOperatorVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		pods []*unstructured.Unstructured
+		want string
+	}{
+		{name: "no pods"},
+		{
+			name: "all instances agree",
+			pods: []*unstructured.Unstructured{versionedPod("daap-orders-db-1", "1.25.0"), versionedPod("daap-orders-db-2", "1.25.0")},
+			want: "1.25.0",
+		},
+		{
+			name: "rolling update",
+			pods: []*unstructured.Unstructured{versionedPod("daap-orders-db-1", "1.25.0"), versionedPod("daap-orders-db-2", "1.24.1")},
+		},
+		{
+			name: "not annotated",
+			pods: []*unstructured.Unstructured{versionedPod("daap-orders-db-1", "")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{}
+			for _, pod := range tt.pods {
+				objects = append(objects, pod)
+			}
+			p := cnpgprovider.New(newStorageClient(objects...))
+
+			version, err := p.OperatorVersion(context.Background(), sampleDB())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, version)
+		})
+	}
+}
@@ -0,0 +1,140 @@
+package cnpg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+var poolersGVR = schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "poolers"}
+
+// stubPoolerStats returns pools keyed by pod name.
+type stubPoolerStats struct {
+	pools map[string]map[string]float64
+	err   error
+}
+
+func (s *stubPoolerStats) PgBouncerPools(_ context.Context, _, pod string) (map[string]float64, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.pools[pod], nil
+}
+
+func poolerObject(parameters map[string]interface{}) *unstructured.Unstructured {
+	db := sampleDB()
+	spec := map[string]interface{}{"cluster": map[string]interface{}{"name": db.ClusterName}, "type": "rw"}
+	if parameters != nil {
+		spec["pgbouncer"] = map[string]interface{}{"parameters": parameters}
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Pooler",
+		"metadata":   map[string]interface{}{"name": db.PoolerName, "namespace": db.Namespace},
+		"spec":       spec,
+	}}
+}
+
+func poolerPod(name, pooler string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": sampleDB().Namespace,
+			"labels":    map[string]interface{}{"cnpg.io/poolerName": pooler},
+		},
+	}}
+}
+
+func newPoolerClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{poolersGVR: "PoolerList", podsGVR: "PodList"},
+		objects...)
+}
+
+func TestPoolerUsage_SumsInstances(t *testing.T) {
+	db := sampleDB()
+	client := newPoolerClient(
+		poolerObject(map[string]interface{}{"default_pool_size": "10", "max_client_conn": "500"}),
+		poolerPod("daap-orders-db-pooler-a", db.PoolerName),
+		poolerPod("daap-orders-db-pooler-b", db.PoolerName),
+		poolerPod("daap-other-pooler-a", "daap-other-pooler"),
+	)
+	stats := &stubPoolerStats{pools: map[string]map[string]float64{
+		"daap-orders-db-pooler-a": {"cl_active": 40, "cl_waiting": 3, "sv_active": 10, "sv_idle": 0},
+		"daap-orders-db-pooler-b": {"cl_active": 25, "cl_waiting": 0, "sv_active": 6, "sv_idle": 2},
+	}}
+	p := cnpgprovider.New(client, cnpgprovider.WithPoolerStats(stats))
+
+	usage, err := p.PoolerUsage(context.Background(), db)
+
+	require.NoError(t, err)
+	assert.Equal(t, provider.PoolerUsage{
+		Instances:      2,
+		ActiveClients:  65,
+		WaitingClients: 3,
+		ActiveServers:  16,
+		IdleServers:    2,
+		MaxClients:     1000,
+		PoolSize:       20,
+	}, usage)
+}
+
+func TestPoolerUsage_DefaultLimits(t *testing.T) {
+	db := sampleDB()
+	client := newPoolerClient(poolerObject(nil), poolerPod("daap-orders-db-pooler-a", db.PoolerName))
+	p := cnpgprovider.New(client, cnpgprovider.WithPoolerStats(&stubPoolerStats{}))
+
+	usage, err := p.PoolerUsage(context.Background(), db)
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(100), usage.MaxClients, "pgbouncer's default max_client_conn")
+	assert.Equal(t, int64(20), usage.PoolSize, "pgbouncer's default default_pool_size")
+}
+
+func TestPoolerUsage_NotSupported(t *testing.T) {
+	db := sampleDB()
+
+	_, err := cnpgprovider.New(newPoolerClient(poolerObject(nil))).PoolerUsage(context.Background(), db)
+	assert.ErrorIs(t, err, provider.ErrNotSupported, "without pooler stats")
+
+	_, err = cnpgprovider.New(newPoolerClient(), cnpgprovider.WithPoolerStats(&stubPoolerStats{})).PoolerUsage(context.Background(), db)
+	assert.ErrorIs(t, err, provider.ErrNotSupported, "a database without a Pooler")
+}
+
+func TestPoolerUsage_Errors(t *testing.T) {
+	db := sampleDB()
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		stats   *stubPoolerStats
+	}{
+		{"no instances", []runtime.Object{poolerObject(nil)}, &stubPoolerStats{}},
+		{"stats failure", []runtime.Object{
+			poolerObject(nil),
+			poolerPod("daap-orders-db-pooler-a", db.PoolerName),
+		}, &stubPoolerStats{err: errors.New("pooler metrics unreachable")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := cnpgprovider.New(newPoolerClient(tt.objects...), cnpgprovider.WithPoolerStats(tt.stats))
+
+			_, err := p.PoolerUsage(context.Background(), db)
+
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, provider.ErrNotSupported)
+		})
+	}
+}
@@ -0,0 +1,168 @@
+package cnpg_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+func TestRenderManifests_TemplatesAndLabels(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+
+	out, err := p.RenderManifests(sampleDB(), sizedManifest)
+	require.NoError(t, err)
+
+	docs := strings.Split(strings.TrimPrefix(out, "---\n"), "---\n")
+	require.Len(t, docs, 2)
+
+	var cluster map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &cluster))
+	metadata := cluster["metadata"].(map[string]interface{})
+	assert.Equal(t, "daap-orders-db", metadata["name"])
+	assert.Equal(t, "daap-system", metadata["namespace"])
+	labels := metadata["labels"].(map[string]interface{})
+	assert.Equal(t, "orders-db", labels[provider.LabelDatabase])
+	assert.Equal(t, provider.LabelManagedByValue, labels[provider.LabelManagedBy])
+}
+
+func TestRenderManifests_TeamDefaults(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+	db := sampleDB()
+	db.Labels = map[string]string{"cost-center": "cc-1234", provider.LabelManagedBy: "someone-else"}
+	db.Annotations = map[string]string{"finance.example.com/owner": "checkout"}
+	manifest := `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: daap-{{ .Name }}
+  namespace: {{ .Namespace }}
+  labels:
+    cost-center: from-blueprint
+spec:
+  instances: 1
+`
+
+	out, err := p.RenderManifests(db, manifest)
+	require.NoError(t, err)
+
+	var cluster map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(strings.TrimPrefix(out, "---\n")), &cluster))
+	metadata := cluster["metadata"].(map[string]interface{})
+	labels := metadata["labels"].(map[string]interface{})
+	assert.Equal(t, "from-blueprint", labels["cost-center"], "blueprint labels win over team defaults")
+	assert.Equal(t, provider.LabelManagedByValue, labels[provider.LabelManagedBy])
+	annotations := metadata["annotations"].(map[string]interface{})
+	assert.Equal(t, "checkout", annotations["finance.example.com/owner"])
+
+	inherited := cluster["spec"].(map[string]interface{})["inheritedMetadata"].(map[string]interface{})
+	assert.Equal(t, "cc-1234", inherited["labels"].(map[string]interface{})["cost-center"])
+	assert.Equal(t, "checkout", inherited["annotations"].(map[string]interface{})["finance.example.com/owner"])
+}
+
+func TestRenderManifests_Topology(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+	db := sampleDB()
+	db.Topology = provider.Topology{
+		ZoneSpread:   provider.ZoneSpreadRequired,
+		NodeSelector: map[string]string{"workload": "postgres"},
+	}
+	manifest := `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: daap-{{ .Name }}
+spec:
+  instances: 3
+  affinity:
+    enablePodAntiAffinity: false
+    podAntiAffinityType: preferred
+    nodeSelector:
+      workload: anything
+      disk: ssd
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Pooler
+metadata:
+  name: daap-{{ .Name }}-pooler
+spec:
+  instances: 1
+`
+
+	out, err := p.RenderManifests(db, manifest)
+	require.NoError(t, err)
+	docs := strings.Split(strings.TrimPrefix(out, "---\n"), "---\n")
+	require.Len(t, docs, 2)
+
+	var cluster map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[0]), &cluster))
+	affinity := cluster["spec"].(map[string]interface{})["affinity"].(map[string]interface{})
+	assert.Equal(t, true, affinity["enablePodAntiAffinity"], "the tier wins over the blueprint")
+	assert.Equal(t, "topology.kubernetes.io/zone", affinity["topologyKey"])
+	assert.Equal(t, "required", affinity["podAntiAffinityType"])
+	assert.Equal(t, map[string]interface{}{"workload": "postgres", "disk": "ssd"}, affinity["nodeSelector"])
+
+	var pooler map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &pooler))
+	assert.NotContains(t, pooler["spec"], "affinity", "only Clusters get the topology")
+}
+
+func TestRenderManifests_Disruption(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+	db := sampleDB()
+	db.Disruption = provider.Disruption{MinAvailable: 2, PriorityClassName: "db-critical"}
+
+	out, err := p.RenderManifests(db, multiDocManifest)
+	require.NoError(t, err)
+	docs := strings.Split(strings.TrimPrefix(out, "---\n"), "---\n")
+	require.Len(t, docs, 3, "the budget follows its Cluster")
+
+	var cluster map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[0]), &cluster))
+	spec := cluster["spec"].(map[string]interface{})
+	assert.Equal(t, "db-critical", spec["priorityClassName"])
+	assert.Equal(t, false, spec["enablePDB"], "the operator's budgets would block evictions")
+
+	var budget map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &budget))
+	assert.Equal(t, "PodDisruptionBudget", budget["kind"])
+	metadata := budget["metadata"].(map[string]interface{})
+	assert.Equal(t, "daap-orders-db-daap", metadata["name"])
+	assert.Equal(t, "orders-db", metadata["labels"].(map[string]interface{})["daap.io/database"])
+	budgetSpec := budget["spec"].(map[string]interface{})
+	assert.EqualValues(t, 2, budgetSpec["minAvailable"])
+	assert.Equal(t, map[string]interface{}{"cnpg.io/cluster": "daap-orders-db", "cnpg.io/podRole": "instance"},
+		budgetSpec["selector"].(map[string]interface{})["matchLabels"])
+
+	var pooler map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(docs[2]), &pooler))
+	assert.Equal(t, "Pooler", pooler["kind"])
+	assert.NotContains(t, pooler["spec"], "priorityClassName", "only Clusters get the priority class")
+}
+
+func TestRenderManifests_Images(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+	db := sampleDB()
+	db.Images = map[string]string{
+		"ghcr.io/cloudnative-pg/postgresql:16": "registry.internal/ghcr.io/cloudnative-pg/postgresql:16@sha256:1111",
+	}
+
+	out, err := p.RenderManifests(db, singleDocManifest)
+	require.NoError(t, err)
+
+	var cluster map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(strings.TrimPrefix(out, "---\n")), &cluster))
+	assert.Equal(t, "registry.internal/ghcr.io/cloudnative-pg/postgresql:16@sha256:1111",
+		cluster["spec"].(map[string]interface{})["imageName"])
+}
+
+func TestRenderManifests_InvalidTemplate(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient())
+
+	_, err := p.RenderManifests(sampleDB(), "kind: Cluster\nmetadata:\n  name: {{ .Missing }")
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,170 @@
+package cnpg_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+func operatorDeployment(namespace, name string, labels map[string]interface{}, image string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    labels,
+		},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "manager", "image": image},
+					},
+				},
+			},
+		},
+	}}
+}
+
+func newDeploymentClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+		}, objects...)
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    cnpgprovider.Version
+		wantErr bool
+	}{
+		{in: "1.25.1", want: cnpgprovider.Version{Major: 1, Minor: 25, Patch: 1}},
+		{in: "v1.24.0", want: cnpgprovider.Version{Major: 1, Minor: 24}},
+		{in: "1.26.0-rc1", want: cnpgprovider.Version{Major: 1, Minor: 26}},
+		{in: "1.23", want: cnpgprovider.Version{Major: 1, Minor: 23}},
+		{in: "latest", wantErr: true},
+		{in: "1", wantErr: true},
+		{in: "1.x.0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := cnpgprovider.ParseVersion(tt.in)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestVersion_Supported(t *testing.T) {
+	assert.False(t, cnpgprovider.Version{Major: 1, Minor: 21, Patch: 9}.Supported())
+	assert.True(t, cnpgprovider.MinOperatorVersion.Supported())
+	assert.True(t, cnpgprovider.Version{Major: 1, Minor: 26, Patch: 3}.Supported())
+	assert.False(t, cnpgprovider.MaxOperatorVersion.Supported())
+}
+
+const clusterManifests = `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: "{{ .Name }}"
+`
+
+const multiKindManifests = `apiVersion: postgresql.cnpg.io/v1
+kind: Cluster
+metadata:
+  name: "{{ .Name }}"
+spec:
+  instances: 1
+---
+apiVersion: postgresql.cnpg.io/v1
+kind: Database
+metadata:
+  name: "{{ .Name }}-app"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+`
+
+func TestManifestKinds(t *testing.T) {
+	assert.Equal(t, []string{"Cluster", "Database"}, cnpgprovider.ManifestKinds(multiKindManifests))
+	assert.Empty(t, cnpgprovider.ManifestKinds("apiVersion: v1\nkind: ConfigMap\n"))
+}
+
+func TestCheckManifests(t *testing.T) {
+	tests := []struct {
+		name      string
+		version   cnpgprovider.Version
+		manifests string
+		wantLen   int
+	}{
+		{name: "supported version, baseline kinds", version: cnpgprovider.Version{Major: 1, Minor: 24}, manifests: clusterManifests, wantLen: 0},
+		{name: "supported version, newer kind", version: cnpgprovider.Version{Major: 1, Minor: 24}, manifests: multiKindManifests, wantLen: 1},
+		{name: "version with newer kind", version: cnpgprovider.Version{Major: 1, Minor: 25}, manifests: multiKindManifests, wantLen: 0},
+		{name: "unsupported version", version: cnpgprovider.Version{Major: 1, Minor: 20}, manifests: clusterManifests, wantLen: 1},
+		{name: "unsupported version and newer kind", version: cnpgprovider.Version{Major: 1, Minor: 20}, manifests: multiKindManifests, wantLen: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Len(t, cnpgprovider.CheckManifests(tt.version, tt.manifests), tt.wantLen)
+		})
+	}
+}
+
+func TestOperatorDetector_VersionLabel(t *testing.T) {
+	client := newDeploymentClient(operatorDeployment("cnpg-system", "cnpg-cloudnative-pg",
+		map[string]interface{}{
+			"app.kubernetes.io/name":    "cloudnative-pg",
+			"app.kubernetes.io/version": "1.25.1",
+			"helm.sh/chart":             "cloudnative-pg-0.23.0",
+		}, "ghcr.io/cloudnative-pg/cloudnative-pg:1.25.1"))
+
+	op, err := cnpgprovider.NewOperatorDetector(client, "cnpg-system").Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, cnpgprovider.Operator{
+		Detected:   true,
+		Namespace:  "cnpg-system",
+		Deployment: "cnpg-cloudnative-pg",
+		Version:    "1.25.1",
+		Chart:      "cloudnative-pg-0.23.0",
+	}, op)
+}
+
+func TestOperatorDetector_ImageTagFallback(t *testing.T) {
+	client := newDeploymentClient(operatorDeployment("cnpg-system", "cnpg-controller-manager",
+		map[string]interface{}{"app.kubernetes.io/name": "cloudnative-pg"},
+		"registry.local:5000/cloudnative-pg/cloudnative-pg:1.24.2@sha256:abc"))
+
+	op, err := cnpgprovider.NewOperatorDetector(client, "cnpg-system").Detect(context.Background())
+	require.NoError(t, err)
+	assert.True(t, op.Detected)
+	assert.Equal(t, "1.24.2", op.Version)
+	assert.Empty(t, op.Chart)
+}
+
+func TestOperatorDetector_NotInstalled(t *testing.T) {
+	client := newDeploymentClient(
+		operatorDeployment("cnpg-system", "other", map[string]interface{}{"app.kubernetes.io/name": "other"}, "other:1.0"),
+		operatorDeployment("default", "cnpg", map[string]interface{}{"app.kubernetes.io/name": "cloudnative-pg"}, "cnpg:1.25.0"),
+	)
+
+	op, err := cnpgprovider.NewOperatorDetector(client, "cnpg-system").Detect(context.Background())
+	require.NoError(t, err)
+	assert.False(t, op.Detected)
+	assert.Equal(t, "cnpg-system", op.Namespace)
+}
@@ -0,0 +1,107 @@
+package cnpg_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+const restartAnnotation = "kubectl.kubernetes.io/restartedAt"
+
+// restartingCluster is storageCluster with readyInstances of its 2 instances
+// ready and the given restartedAt annotation, if any.
+func restartingCluster(restartedAt string, readyInstances int64) *unstructured.Unstructured {
+	cluster := storageCluster("10Gi")
+	if restartedAt != "" {
+		cluster.SetAnnotations(map[string]string{restartAnnotation: restartedAt})
+	}
+	_ = unstructured.SetNestedField(cluster.Object, int64(2), "status", "instances")
+	_ = unstructured.SetNestedField(cluster.Object, readyInstances, "status", "readyInstances")
+	return cluster
+}
+
+// restartedPod is an instance pod carrying the given restartedAt annotation.
+func restartedPod(name, restartedAt string) *unstructured.Unstructured {
+	pod := instancePod(name, sampleDB().ClusterName, "instance")
+	if restartedAt != "" {
+		pod.SetAnnotations(map[string]string{restartAnnotation: restartedAt})
+	}
+	return pod
+}
+
+func TestRestart_AnnotatesCluster(t *testing.T) {
+	db := sampleDB()
+	client := newStorageClient(restartingCluster("", 2))
+	p := cnpgprovider.New(client)
+
+	before := time.Now().UTC().Add(-time.Second)
+	require.NoError(t, p.Restart(context.Background(), db))
+
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(context.Background(), db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	at, err := time.Parse(time.RFC3339Nano, cluster.GetAnnotations()[restartAnnotation])
+	require.NoError(t, err)
+	assert.True(t, at.After(before))
+}
+
+func TestRestart_ClusterNotFound(t *testing.T) {
+	p := cnpgprovider.New(newStorageClient())
+	assert.Error(t, p.Restart(context.Background(), sampleDB()))
+}
+
+func TestRestarted(t *testing.T) {
+	const at = "2026-02-03T09:00:00Z"
+	tests := []struct {
+		name    string
+		cluster *unstructured.Unstructured
+		pods    []*unstructured.Unstructured
+		want    bool
+	}{
+		{
+			name:    "never restarted",
+			cluster: restartingCluster("", 2),
+			pods:    []*unstructured.Unstructured{restartedPod("daap-orders-db-1", ""), restartedPod("daap-orders-db-2", "")},
+			want:    true,
+		},
+		{
+			name:    "instance not restarted yet",
+			cluster: restartingCluster(at, 2),
+			pods:    []*unstructured.Unstructured{restartedPod("daap-orders-db-1", at), restartedPod("daap-orders-db-2", "2026-01-01T00:00:00Z")},
+			want:    false,
+		},
+		{
+			name:    "restarted instance not ready",
+			cluster: restartingCluster(at, 1),
+			pods:    []*unstructured.Unstructured{restartedPod("daap-orders-db-1", at), restartedPod("daap-orders-db-2", at)},
+			want:    false,
+		},
+		{
+			name:    "all instances restarted",
+			cluster: restartingCluster(at, 2),
+			pods:    []*unstructured.Unstructured{restartedPod("daap-orders-db-1", at), restartedPod("daap-orders-db-2", at)},
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := []runtime.Object{tt.cluster}
+			for _, pod := range tt.pods {
+				objects = append(objects, pod)
+			}
+			p := cnpgprovider.New(newStorageClient(objects...))
+
+			done, err := p.Restarted(context.Background(), sampleDB())
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, done)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package cnpg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+// restoredDB is sampleDB restored under the name "orders-restored".
+func restoredDB() provider.ProviderDatabase {
+	db := sampleDB()
+	db.Name = "orders-restored"
+	db.ClusterName = "daap-orders-restored"
+	db.PoolerName = "daap-orders-restored-pooler"
+	return db
+}
+
+func namedBackup(cluster, phase string) *unstructured.Unstructured {
+	db := sampleDB()
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Backup",
+		"metadata":   map[string]interface{}{"name": "nightly", "namespace": db.Namespace},
+		"spec":       map[string]interface{}{"cluster": map[string]interface{}{"name": cluster}},
+		"status":     map[string]interface{}{"phase": phase},
+	}}
+}
+
+func TestRestore_NamedBackup(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient(storageCluster("10Gi"), namedBackup("daap-orders-db", "completed"))
+	p := cnpgprovider.New(client)
+	db := restoredDB()
+	at := time.Date(2026, 2, 3, 8, 45, 0, 0, time.UTC)
+
+	err := p.Restore(context.Background(), db, sampleDB(), initdbManifest, provider.RestoreTarget{Backup: "nightly", Time: &at})
+	require.NoError(t, err)
+
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(context.Background(), db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "orders-restored", cluster.GetLabels()["daap.io/database"])
+	recovery, _, _ := unstructured.NestedMap(cluster.Object, "spec", "bootstrap", "recovery")
+	assert.Equal(t, map[string]any{
+		"backup":         map[string]any{"name": "nightly"},
+		"recoveryTarget": map[string]any{"targetTime": "2026-02-03T08:45:00Z"},
+		"database":       "app",
+		"owner":          "app",
+	}, recovery)
+	_, hasExternal, _ := unstructured.NestedSlice(cluster.Object, "spec", "externalClusters")
+	assert.False(t, hasExternal)
+}
+
+func TestRestore_LatestFromObjectStore(t *testing.T) {
+	t.Parallel()
+	source := storageCluster("10Gi")
+	store := map[string]interface{}{
+		"destinationPath": "s3://backups/daap-system",
+		"s3Credentials":   map[string]interface{}{"accessKeyId": map[string]interface{}{"name": "backup-creds", "key": "ACCESS_KEY_ID"}},
+	}
+	require.NoError(t, unstructured.SetNestedField(source.Object, store, "spec", "backup", "barmanObjectStore"))
+	client := newFakeClient(source)
+	p := cnpgprovider.New(client)
+	db := restoredDB()
+
+	require.NoError(t, p.Restore(context.Background(), db, sampleDB(), initdbManifest, provider.RestoreTarget{}))
+
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(context.Background(), db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	recovery, _, _ := unstructured.NestedMap(cluster.Object, "spec", "bootstrap", "recovery")
+	assert.Equal(t, map[string]any{"source": "daap-orders-db", "database": "app", "owner": "app"}, recovery)
+
+	externals, _, _ := unstructured.NestedSlice(cluster.Object, "spec", "externalClusters")
+	require.Len(t, externals, 1)
+	external := externals[0].(map[string]any)
+	assert.Equal(t, "daap-orders-db", external["name"])
+	destination, _, _ := unstructured.NestedString(external, "barmanObjectStore", "destinationPath")
+	assert.Equal(t, "s3://backups/daap-system", destination)
+	server, _, _ := unstructured.NestedString(external, "barmanObjectStore", "serverName")
+	assert.Equal(t, "daap-orders-db", server)
+	creds, _, _ := unstructured.NestedString(external, "barmanObjectStore", "s3Credentials", "accessKeyId", "name")
+	assert.Equal(t, "backup-creds", creds)
+}
+
+func TestRestore_NoBackup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		target  provider.RestoreTarget
+	}{
+		{"backup missing", []runtime.Object{storageCluster("10Gi")}, provider.RestoreTarget{Backup: "nightly"}},
+		{"backup of another cluster", []runtime.Object{namedBackup("daap-payments", "completed")}, provider.RestoreTarget{Backup: "nightly"}},
+		{"backup running", []runtime.Object{namedBackup("daap-orders-db", "running")}, provider.RestoreTarget{Backup: "nightly"}},
+		{"no object store", []runtime.Object{storageCluster("10Gi")}, provider.RestoreTarget{}},
+		{"source missing", nil, provider.RestoreTarget{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := newFakeClient(tt.objects...)
+			p := cnpgprovider.New(client)
+			db := restoredDB()
+
+			err := p.Restore(context.Background(), db, sampleDB(), initdbManifest, tt.target)
+			assert.True(t, errors.Is(err, provider.ErrBackupNotFound), err)
+			_, err = client.Resource(clustersGVR).Namespace(db.Namespace).Get(context.Background(), db.ClusterName, metav1.GetOptions{})
+			assert.Error(t, err, "nothing is applied")
+		})
+	}
+}
+
+func TestRestore_OtherNamespaceNotSupported(t *testing.T) {
+	t.Parallel()
+	p := cnpgprovider.New(newFakeClient(storageCluster("10Gi")))
+	db := restoredDB()
+	db.Namespace = "payments"
+
+	err := p.Restore(context.Background(), db, sampleDB(), initdbManifest, provider.RestoreTarget{})
+	assert.True(t, errors.Is(err, provider.ErrNotSupported))
+}
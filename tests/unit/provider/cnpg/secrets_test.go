@@ -0,0 +1,82 @@
+package cnpg_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+	"github.com/daap14/daap/internal/secrets"
+)
+
+type mapStore map[string]string
+
+func (m mapStore) Lookup(_ context.Context, name string) (string, error) {
+	v, ok := m[name]
+	if !ok {
+		return "", secrets.ErrNotFound
+	}
+	return v, nil
+}
+
+const secretManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: daap-{{ .Name }}-license
+  namespace: {{ .Namespace }}
+data:
+  key: "{{ .Secrets.licenseKey }}"
+`
+
+func TestApply_ResolvesSecrets(t *testing.T) {
+	t.Parallel()
+	client := newFakeClient()
+	p := cnpgprovider.New(client, cnpgprovider.WithSecrets(mapStore{"licenseKey": "s3cr3t"}))
+	db := sampleDB()
+
+	require.NoError(t, p.Apply(context.Background(), db, secretManifest))
+
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	cm, err := client.Resource(gvr).Namespace(db.Namespace).Get(context.Background(), "daap-orders-db-license", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, _ := unstructured.NestedString(cm.Object, "data", "key")
+	assert.Equal(t, "s3cr3t", value)
+}
+
+func TestApply_SecretErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		opts    []cnpgprovider.Option
+		wantErr error
+	}{
+		{"no store", nil, secrets.ErrNoStore},
+		{"missing secret", []cnpgprovider.Option{cnpgprovider.WithSecrets(mapStore{})}, secrets.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := cnpgprovider.New(newFakeClient(), tt.opts...)
+
+			err := p.Apply(context.Background(), sampleDB(), secretManifest)
+
+			require.ErrorIs(t, err, tt.wantErr)
+			assert.Contains(t, err.Error(), "licenseKey")
+		})
+	}
+}
+
+func TestRenderManifests_SecretPlaceholders(t *testing.T) {
+	p := cnpgprovider.New(newComputeClient(), cnpgprovider.WithSecrets(mapStore{"licenseKey": "s3cr3t"}))
+
+	out, err := p.RenderManifests(sampleDB(), secretManifest)
+
+	require.NoError(t, err)
+	assert.Contains(t, out, "<secret:licenseKey>")
+	assert.False(t, strings.Contains(out, "s3cr3t"), "rendered manifests must not carry secret values")
+}
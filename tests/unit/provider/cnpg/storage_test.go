@@ -0,0 +1,152 @@
+package cnpg_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+var (
+	clustersGVR = schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"}
+	podsGVR     = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+)
+
+// stubVolumeStats returns usage keyed by pod name.
+type stubVolumeStats struct {
+	used map[string]int64
+	err  error
+}
+
+func (s *stubVolumeStats) PVCUsedBytes(_ context.Context, _, pod, pvc string) (int64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	if pod != pvc {
+		return 0, errors.New("unexpected claim " + pvc)
+	}
+	return s.used[pod], nil
+}
+
+func storageCluster(size string) *unstructured.Unstructured {
+	db := sampleDB()
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "postgresql.cnpg.io/v1",
+		"kind":       "Cluster",
+		"metadata":   map[string]interface{}{"name": db.ClusterName, "namespace": db.Namespace},
+		"spec":       map[string]interface{}{"instances": int64(2), "storage": map[string]interface{}{"size": size}},
+	}}
+}
+
+func instancePod(name, cluster, role string) *unstructured.Unstructured {
+	db := sampleDB()
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": db.Namespace,
+			"labels":    map[string]interface{}{"cnpg.io/cluster": cluster, "cnpg.io/podRole": role},
+		},
+	}}
+}
+
+func newStorageClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{clustersGVR: "ClusterList", podsGVR: "PodList"},
+		objects...)
+}
+
+func TestStorageUsage_FullestInstance(t *testing.T) {
+	db := sampleDB()
+	client := newStorageClient(
+		storageCluster("10Gi"),
+		instancePod("daap-orders-db-1", db.ClusterName, "instance"),
+		instancePod("daap-orders-db-2", db.ClusterName, "instance"),
+		instancePod("daap-orders-db-1-initdb", db.ClusterName, "job"),
+		instancePod("daap-other-1", "daap-other", "instance"),
+	)
+	stats := &stubVolumeStats{used: map[string]int64{
+		"daap-orders-db-1":        6 << 30,
+		"daap-orders-db-2":        8 << 30,
+		"daap-orders-db-1-initdb": 9 << 30,
+		"daap-other-1":            10 << 30,
+	}}
+	p := cnpgprovider.New(client, cnpgprovider.WithVolumeStats(stats))
+
+	usage, err := p.StorageUsage(context.Background(), db)
+
+	require.NoError(t, err)
+	assert.Equal(t, provider.StorageUsage{CapacityBytes: 10 << 30, UsedBytes: 8 << 30}, usage)
+}
+
+func TestStorageUsage_WithoutVolumeStats(t *testing.T) {
+	p := cnpgprovider.New(newStorageClient(storageCluster("10Gi")))
+
+	_, err := p.StorageUsage(context.Background(), sampleDB())
+
+	assert.ErrorIs(t, err, provider.ErrNotSupported)
+}
+
+func TestStorageUsage_Errors(t *testing.T) {
+	db := sampleDB()
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		stats   *stubVolumeStats
+	}{
+		{"cluster not found", nil, &stubVolumeStats{}},
+		{"no storage size", []runtime.Object{storageCluster("")}, &stubVolumeStats{}},
+		{"invalid storage size", []runtime.Object{storageCluster("ten gigs")}, &stubVolumeStats{}},
+		{"no instances", []runtime.Object{storageCluster("10Gi")}, &stubVolumeStats{}},
+		{"stats failure", []runtime.Object{
+			storageCluster("10Gi"),
+			instancePod("daap-orders-db-1", db.ClusterName, "instance"),
+		}, &stubVolumeStats{err: errors.New("kubelet unreachable")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := cnpgprovider.New(newStorageClient(tt.objects...), cnpgprovider.WithVolumeStats(tt.stats))
+
+			_, err := p.StorageUsage(context.Background(), db)
+
+			require.Error(t, err)
+			assert.NotErrorIs(t, err, provider.ErrNotSupported)
+		})
+	}
+}
+
+func TestResizeStorage_PatchesClusterSize(t *testing.T) {
+	db := sampleDB()
+	client := newStorageClient(storageCluster("10Gi"))
+	p := cnpgprovider.New(client)
+
+	err := p.ResizeStorage(context.Background(), db, 12<<30)
+
+	require.NoError(t, err)
+	cluster, err := client.Resource(clustersGVR).Namespace(db.Namespace).Get(context.Background(), db.ClusterName, metav1.GetOptions{})
+	require.NoError(t, err)
+	size, _, _ := unstructured.NestedString(cluster.Object, "spec", "storage", "size")
+	assert.Equal(t, "12Gi", size)
+	instances, _, _ := unstructured.NestedInt64(cluster.Object, "spec", "instances")
+	assert.Equal(t, int64(2), instances, "other spec fields are preserved")
+}
+
+func TestResizeStorage_ClusterNotFound(t *testing.T) {
+	p := cnpgprovider.New(newStorageClient())
+
+	err := p.ResizeStorage(context.Background(), sampleDB(), 12<<30)
+
+	assert.Error(t, err)
+}
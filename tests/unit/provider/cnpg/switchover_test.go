@@ -0,0 +1,98 @@
+package cnpg_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/daap14/daap/internal/provider"
+	cnpgprovider "github.com/daap14/daap/internal/provider/cnpg"
+)
+
+// healthyCluster is haCluster with all three instances healthy.
+func healthyCluster(phase string) *unstructured.Unstructured {
+	cluster := haCluster(phase)
+	_ = unstructured.SetNestedStringSlice(cluster.Object,
+		[]string{"daap-orders-db-3", "daap-orders-db-1", "daap-orders-db-2"}, "status", "instancesStatus", "healthy")
+	return cluster
+}
+
+func TestSwitchover(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		target string
+		want   string
+	}{
+		{name: "named replica", target: "daap-orders-db-3", want: "daap-orders-db-3"},
+		{name: "first healthy replica", target: "", want: "daap-orders-db-1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client := newFakeClient(healthyCluster("Cluster in healthy state"))
+			p := cnpgprovider.New(client)
+
+			before := time.Now().UTC().Add(-time.Second)
+			promoted, err := p.Switchover(context.Background(), sampleDB(), tt.target)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, promoted)
+
+			gvr := schema.GroupVersionResource{Group: "postgresql.cnpg.io", Version: "v1", Resource: "clusters"}
+			cluster, err := client.Resource(gvr).Namespace("daap-system").Get(context.Background(), "daap-orders-db", metav1.GetOptions{})
+			require.NoError(t, err)
+			targetPrimary, _, _ := unstructured.NestedString(cluster.Object, "status", "targetPrimary")
+			assert.Equal(t, tt.want, targetPrimary)
+			phase, _, _ := unstructured.NestedString(cluster.Object, "status", "phase")
+			assert.Equal(t, "Switchover in progress", phase)
+			stamp, _, _ := unstructured.NestedString(cluster.Object, "status", "targetPrimaryTimestamp")
+			at, err := time.Parse(time.RFC3339Nano, stamp)
+			require.NoError(t, err)
+			assert.True(t, at.After(before))
+			currentPrimary, _, _ := unstructured.NestedString(cluster.Object, "status", "currentPrimary")
+			assert.Equal(t, "daap-orders-db-2", currentPrimary, "the operator moves the primary, not DAAP")
+		})
+	}
+}
+
+func TestSwitchover_InvalidTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		cluster *unstructured.Unstructured
+		target  string
+	}{
+		{name: "current primary", cluster: healthyCluster("Cluster in healthy state"), target: "daap-orders-db-2"},
+		{name: "unknown instance", cluster: healthyCluster("Cluster in healthy state"), target: "daap-orders-db-9"},
+		{name: "unhealthy instance", cluster: haCluster("Cluster in healthy state"), target: "daap-orders-db-3"},
+		{name: "no healthy replica", cluster: haCluster("Cluster in healthy state"), target: ""},
+		{name: "switchover in progress", cluster: healthyCluster("Switchover in progress"), target: "daap-orders-db-3"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p := cnpgprovider.New(newFakeClient(tt.cluster))
+
+			_, err := p.Switchover(context.Background(), sampleDB(), tt.target)
+			assert.ErrorIs(t, err, provider.ErrInvalidTarget)
+		})
+	}
+}
+
+func TestSwitchover_ClusterNotFound(t *testing.T) {
+	t.Parallel()
+	p := cnpgprovider.New(newFakeClient())
+
+	_, err := p.Switchover(context.Background(), sampleDB(), "daap-orders-db-3")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, provider.ErrInvalidTarget)
+}
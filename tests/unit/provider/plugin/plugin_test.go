@@ -0,0 +1,280 @@
+package plugin_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/provider/plugin"
+	"github.com/daap14/daap/internal/requestid"
+	"github.com/daap14/daap/pkg/fake"
+	sdk "github.com/daap14/daap/pkg/plugin"
+	"github.com/daap14/daap/pkg/plugin/providerv1"
+	"github.com/daap14/daap/pkg/provider/providertest"
+)
+
+// TestMain doubles as a plugin binary: when started by plugin.Launch (the
+// magic cookie is set) it serves a fake provider instead of running tests.
+func TestMain(m *testing.M) {
+	if os.Getenv(sdk.MagicCookieKey) == sdk.MagicCookieValue {
+		if err := sdk.Serve(plugin.NewServer(fake.NewProvider())); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// servePlugin serves backend over the plugin protocol on a local port and
+// returns a client connected to it.
+func servePlugin(t *testing.T, backend provider.Provider) (*plugin.Client, *grpc.Server) {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := sdk.NewServer(plugin.NewServer(backend))
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	c, err := plugin.Dial("fake", lis.Addr().String())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = c.Close() })
+	return c, srv
+}
+
+func TestClient_Contract(t *testing.T) {
+	var backend *fake.Provider
+
+	providertest.Run(t, providertest.Harness{
+		New: func(t *testing.T) provider.Provider {
+			backend = fake.NewProvider()
+			c, _ := servePlugin(t, backend)
+			return c
+		},
+		Manifests: "kind: Cluster",
+		MarkReady: func(_ *testing.T, _ provider.Provider, db provider.ProviderDatabase) {
+			host, port, secret := db.PoolerName, 5432, db.ClusterName+"-app"
+			backend.SetHealth(db.ID, provider.HealthResult{
+				Status: "ready", Host: &host, Port: &port, SecretName: &secret,
+			})
+		},
+	})
+}
+
+func TestClient_PassesDatabaseAndManifests(t *testing.T) {
+	backend := fake.NewProvider()
+	c, _ := servePlugin(t, backend)
+	db := providertest.Database("orders")
+	db.Provider = "fake"
+
+	require.NoError(t, c.Apply(context.Background(), db, "kind: Cluster"))
+
+	calls := backend.ApplyCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, db, calls[0].Database)
+	assert.Equal(t, "kind: Cluster", calls[0].Manifests)
+}
+
+func TestClient_PassesTeamAndTierSettings(t *testing.T) {
+	backend := fake.NewProvider()
+	c, _ := servePlugin(t, backend)
+	db := providertest.Database("orders")
+	db.Labels = map[string]string{"cost-center": "cc-42"}
+	db.Annotations = map[string]string{"owner": "orders@example.com"}
+	db.Topology = provider.Topology{
+		ZoneSpread:   provider.ZoneSpreadRequired,
+		NodeSelector: map[string]string{"pool": "databases"},
+	}
+	db.Disruption = provider.Disruption{MinAvailable: 2, PriorityClassName: "databases-critical"}
+	db.Images = map[string]string{
+		"ghcr.io/cloudnative-pg/postgresql:16": "registry.internal/postgresql@sha256:abc",
+	}
+	db.Exposure = provider.Exposure{
+		Type:                provider.ExposureInternal,
+		AllowedSourceRanges: []string{"10.0.0.0/8"},
+	}
+	db.DNSName = "orders.db.example.com"
+
+	require.NoError(t, c.Apply(context.Background(), db, "kind: Cluster"))
+
+	calls := backend.ApplyCalls()
+	require.Len(t, calls, 1)
+	assert.Equal(t, db, calls[0].Database)
+}
+
+func TestClient_ReturnsHealthDetails(t *testing.T) {
+	backend := fake.NewProvider()
+	c, _ := servePlugin(t, backend)
+	db := providertest.Database("orders")
+	host, port, secret := db.PoolerName, 5432, db.ClusterName+"-app"
+	lag := 1500 * time.Millisecond
+	want := provider.HealthResult{
+		Status:       "ready",
+		Host:         &host,
+		Port:         &port,
+		SecretName:   &secret,
+		ExternalHost: "orders.lb.example.com",
+		Instances: &provider.InstanceStatus{
+			Total: 3, Ready: 2, Primary: db.ClusterName + "-1", ReplicationLag: &lag,
+		},
+	}
+	backend.SetHealth(db.ID, want)
+
+	got, err := c.CheckHealth(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestClient_ReturnsHealthReason(t *testing.T) {
+	backend := fake.NewProvider()
+	c, _ := servePlugin(t, backend)
+	db := providertest.Database("orders")
+	want := provider.HealthResult{
+		Status:  "error",
+		Reason:  "FAILED_TO_CREATE_PRIMARY",
+		Message: "primary pod is not scheduled",
+	}
+	backend.SetHealth(db.ID, want)
+
+	got, err := c.CheckHealth(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestClient_PassesRequestID(t *testing.T) {
+	backend := fake.NewProvider()
+	var got string
+	backend.ApplyFn = func(ctx context.Context, _ provider.ProviderDatabase, _ string) error {
+		got = requestid.From(ctx)
+		return nil
+	}
+	c, _ := servePlugin(t, backend)
+
+	ctx := requestid.With(context.Background(), "req-123")
+	require.NoError(t, c.Apply(ctx, providertest.Database("orders"), "kind: Cluster"))
+
+	assert.Equal(t, "req-123", got)
+}
+
+func TestClient_ErrorMapping(t *testing.T) {
+	backend := fake.NewProvider()
+	c, srv := servePlugin(t, backend)
+	db := providertest.Database("orders")
+	ctx := context.Background()
+
+	backend.ApplyFn = func(context.Context, provider.ProviderDatabase, string) error {
+		return errors.New("quota exceeded")
+	}
+	err := c.Apply(ctx, db, "kind: Cluster")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "quota exceeded")
+	assert.NotErrorIs(t, err, provider.ErrUnavailable)
+
+	backend.DeleteFn = func(context.Context, provider.ProviderDatabase) error {
+		return provider.ErrUnavailable
+	}
+	assert.ErrorIs(t, c.Delete(ctx, db), provider.ErrUnavailable)
+
+	// A plugin that went away is unavailable, not broken.
+	srv.Stop()
+	_, err = c.CheckHealth(ctx, db)
+	assert.ErrorIs(t, err, provider.ErrUnavailable)
+}
+
+func TestServer_RejectsInvalidDatabaseID(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := sdk.NewServer(plugin.NewServer(fake.NewProvider()))
+	go func() { _ = srv.Serve(lis) }()
+	defer srv.Stop()
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	defer func() { _ = conn.Close() }()
+
+	rpc := providerv1.NewProviderPluginClient(conn)
+	_, err = rpc.CheckHealth(context.Background(), &providerv1.CheckHealthRequest{
+		Database: &providerv1.Database{Id: "not-a-uuid", Name: "orders"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestLaunch_HandshakeAndCalls(t *testing.T) {
+	exe, err := os.Executable()
+	require.NoError(t, err)
+
+	c, err := plugin.Launch(context.Background(), "fake", exe)
+	require.NoError(t, err)
+	defer func() { _ = c.Close() }()
+
+	db := providertest.Database("launched")
+	require.NoError(t, c.Apply(context.Background(), db, "kind: Cluster"))
+	health, err := c.CheckHealth(context.Background(), db)
+	require.NoError(t, err)
+	assert.Equal(t, "provisioning", health.Status)
+}
+
+func TestParseHandshake(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    string
+		wantErr bool
+	}{
+		{name: "unix socket", line: "1|unix|/tmp/daap-plugin-1/plugin.sock\n", want: "unix:///tmp/daap-plugin-1/plugin.sock"},
+		{name: "tcp", line: "1|tcp|127.0.0.1:4000", want: "127.0.0.1:4000"},
+		{name: "wrong version", line: "2|tcp|127.0.0.1:4000", wantErr: true},
+		{name: "unknown network", line: "1|udp|127.0.0.1:4000", wantErr: true},
+		{name: "malformed", line: "hello", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := plugin.ParseHandshake(tt.line)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDiscover(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, mode os.FileMode) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), mode))
+	}
+	write("daap-provider-rds", 0o755)
+	write("daap-provider-notes", 0o644) // not executable
+	write("other-binary", 0o755)
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "daap-provider-dir"), 0o755))
+
+	found, err := plugin.Discover(dir)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"rds": filepath.Join(dir, "daap-provider-rds")}, found)
+}
+
+func TestParseAddrs(t *testing.T) {
+	addrs, err := plugin.ParseAddrs([]string{"rds=daap-provider-rds:7000", " gcp=10.0.0.5:7000 "})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"rds": "daap-provider-rds:7000", "gcp": "10.0.0.5:7000"}, addrs)
+
+	_, err = plugin.ParseAddrs([]string{"rds"})
+	assert.Error(t, err)
+
+	_, err = plugin.ParseAddrs([]string{"rds=a:1", "rds=b:2"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,228 @@
+package readiness_test
+
+import (
+	"context"
+	"encoding/base64"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgproto3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/readiness"
+)
+
+// fakePostgres speaks just enough of the PostgreSQL wire protocol for the
+// gate: it accepts any startup, answers every simple query and records what
+// it saw.
+type fakePostgres struct {
+	addr string
+
+	mu        sync.Mutex
+	users     []string
+	queries   []string
+	open      int
+	maxOpen   int
+	failQuery bool
+}
+
+func startFakePostgres(t *testing.T) *fakePostgres {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lis.Close() })
+
+	fp := &fakePostgres{addr: lis.Addr().String()}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go fp.serve(conn)
+		}
+	}()
+	return fp
+}
+
+func (fp *fakePostgres) serve(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	backend := pgproto3.NewBackend(conn, conn)
+
+	for {
+		msg, err := backend.ReceiveStartupMessage()
+		if err != nil {
+			return
+		}
+		if _, ok := msg.(*pgproto3.SSLRequest); ok {
+			if _, err := conn.Write([]byte("N")); err != nil {
+				return
+			}
+			continue
+		}
+		if startup, ok := msg.(*pgproto3.StartupMessage); ok {
+			fp.mu.Lock()
+			fp.users = append(fp.users, startup.Parameters["user"])
+			fp.open++
+			fp.maxOpen = max(fp.maxOpen, fp.open)
+			fp.mu.Unlock()
+			defer func() {
+				fp.mu.Lock()
+				fp.open--
+				fp.mu.Unlock()
+			}()
+			break
+		}
+		return
+	}
+
+	backend.Send(&pgproto3.AuthenticationOk{})
+	backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+	if err := backend.Flush(); err != nil {
+		return
+	}
+
+	for {
+		msg, err := backend.Receive()
+		if err != nil {
+			return
+		}
+		switch m := msg.(type) {
+		case *pgproto3.Query:
+			fp.mu.Lock()
+			fp.queries = append(fp.queries, m.String)
+			fail := fp.failQuery
+			fp.mu.Unlock()
+			if fail {
+				backend.Send(&pgproto3.ErrorResponse{Severity: "ERROR", Code: "57P03", Message: "pooler is not ready"})
+			} else {
+				backend.Send(&pgproto3.CommandComplete{CommandTag: []byte("SELECT 1")})
+			}
+			backend.Send(&pgproto3.ReadyForQuery{TxStatus: 'I'})
+			if err := backend.Flush(); err != nil {
+				return
+			}
+		case *pgproto3.Terminate:
+			return
+		}
+	}
+}
+
+func appSecret(namespace, name string, data map[string]string) *unstructured.Unstructured {
+	encoded := map[string]interface{}{}
+	for k, v := range data {
+		encoded[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+		"data":       encoded,
+	}}
+}
+
+func newSecretClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Version: "v1", Resource: "secrets"}: "SecretList"}, objects...)
+}
+
+func target(addr string) (provider.ProviderDatabase, provider.HealthResult) {
+	host, portStr, _ := net.SplitHostPort(addr)
+	port, _ := strconv.Atoi(portStr)
+	secret := "daap-orders-app"
+	return provider.ProviderDatabase{Name: "orders", Namespace: "default"},
+		provider.HealthResult{Status: "ready", Host: &host, Port: &port, SecretName: &secret}
+}
+
+var validSecret = map[string]string{"username": "app", "password": "s3cret", "dbname": "app"}
+
+func TestGate_OpensConnectionsAndRunsQuery(t *testing.T) {
+	fp := startFakePostgres(t)
+	client := newSecretClient(appSecret("default", "daap-orders-app", validSecret))
+	gate := readiness.New(client, 3, "SELECT count(*) FROM pg_stat_activity", 5*time.Second)
+
+	db, health := target(fp.addr)
+	require.NoError(t, gate.Check(context.Background(), db, health))
+
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	assert.Equal(t, 3, fp.maxOpen, "connections are held open together")
+	assert.Equal(t, []string{"app", "app", "app"}, fp.users)
+	assert.Equal(t, []string{
+		"SELECT count(*) FROM pg_stat_activity",
+		"SELECT count(*) FROM pg_stat_activity",
+		"SELECT count(*) FROM pg_stat_activity",
+	}, fp.queries)
+}
+
+func TestGate_DefaultsQueryAndConnections(t *testing.T) {
+	fp := startFakePostgres(t)
+	client := newSecretClient(appSecret("default", "daap-orders-app", validSecret))
+	gate := readiness.New(client, 0, "", 5*time.Second)
+
+	db, health := target(fp.addr)
+	require.NoError(t, gate.Check(context.Background(), db, health))
+
+	fp.mu.Lock()
+	defer fp.mu.Unlock()
+	assert.Equal(t, []string{readiness.DefaultQuery}, fp.queries)
+}
+
+func TestGate_QueryFails(t *testing.T) {
+	fp := startFakePostgres(t)
+	fp.failQuery = true
+	client := newSecretClient(appSecret("default", "daap-orders-app", validSecret))
+	gate := readiness.New(client, 2, "", 5*time.Second)
+
+	db, health := target(fp.addr)
+	err := gate.Check(context.Background(), db, health)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pooler is not ready")
+}
+
+func TestGate_Unreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	client := newSecretClient(appSecret("default", "daap-orders-app", validSecret))
+	gate := readiness.New(client, 2, "", 5*time.Second)
+
+	db, health := target(addr)
+	err = gate.Check(context.Background(), db, health)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "opening connection 1 of 2")
+}
+
+func TestGate_CredentialErrors(t *testing.T) {
+	fp := startFakePostgres(t)
+	db, health := target(fp.addr)
+
+	tests := []struct {
+		name    string
+		client  *dynamicfake.FakeDynamicClient
+		health  provider.HealthResult
+		wantErr string
+	}{
+		{name: "no connection details", client: newSecretClient(), health: provider.HealthResult{Status: "ready"}, wantErr: "no connection details"},
+		{name: "secret missing", client: newSecretClient(), health: health, wantErr: "reading secret default/daap-orders-app"},
+		{name: "key missing", client: newSecretClient(appSecret("default", "daap-orders-app", map[string]string{"username": "app"})), health: health, wantErr: `no "password" key`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := readiness.New(tt.client, 1, "", time.Second).Check(context.Background(), db, tt.health)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,320 @@
+package recommend_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/recommend"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+const mib = int64(1 << 20)
+
+// computeProvider is a fake provider that reports a fixed compute usage and
+// sizes blueprints from a table keyed by manifests.
+type computeProvider struct {
+	*fake.Provider
+
+	usage    provider.ComputeUsage
+	usageErr error
+	sizes    map[string]provider.ComputeResources
+}
+
+func (p *computeProvider) ComputeUsage(_ context.Context, _ provider.ProviderDatabase) (provider.ComputeUsage, error) {
+	return p.usage, p.usageErr
+}
+
+func (p *computeProvider) ManifestCompute(manifests string) (provider.ComputeResources, error) {
+	c, ok := p.sizes[manifests]
+	if !ok {
+		return provider.ComputeResources{}, errors.New("no compute in manifests")
+	}
+	return c, nil
+}
+
+type fixture struct {
+	repos    *fake.Repositories
+	db       *database.Database
+	tiers    map[string]*tier.Tier
+	provider *computeProvider
+}
+
+// setup seeds three tiers served by one provider and a ready database on the
+// standard tier.
+func setup(t *testing.T, used provider.ComputeResources) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+
+	p := &computeProvider{
+		Provider: fake.NewProvider(),
+		sizes:    map[string]provider.ComputeResources{},
+	}
+	f := &fixture{repos: repos, tiers: map[string]*tier.Tier{}, provider: p}
+
+	sizes := []struct {
+		name   string
+		cpu    int64
+		memory int64
+	}{
+		{"small", 1000, 2048 * mib},
+		{"standard", 2000, 4096 * mib},
+		{"large", 4000, 8192 * mib},
+	}
+	for _, s := range sizes {
+		manifests := "kind: Cluster # " + s.name
+		bp := &blueprint.Blueprint{Name: "cnpg-" + s.name, Provider: "sized", Manifests: manifests}
+		require.NoError(t, repos.Blueprints.Create(ctx, bp))
+		tr := &tier.Tier{Name: s.name, BlueprintID: &bp.ID}
+		require.NoError(t, repos.Tiers.Create(ctx, tr))
+		f.tiers[s.name] = tr
+		p.sizes[manifests] = provider.ComputeResources{CPUMillis: s.cpu, MemoryBytes: s.memory}
+	}
+	p.usage = provider.ComputeUsage{
+		Requests: p.sizes["kind: Cluster # standard"],
+		Used:     used,
+	}
+
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	db := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &f.tiers["standard"].ID, Namespace: "db"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+	_, err := repos.Databases.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	f.db = db
+	return f
+}
+
+func (f *fixture) recommender(opts ...recommend.Option) *recommend.Recommender {
+	registry := provider.NewRegistry()
+	registry.Register("sized", f.provider)
+	opts = append([]recommend.Option{recommend.WithMinSamples(3)}, opts...)
+	return recommend.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, registry,
+		f.repos.UsageSamples, f.repos.TierChanges, time.Minute, 24*time.Hour, opts...)
+}
+
+func (f *fixture) run(t *testing.T, r *recommend.Recommender, passes int) {
+	t.Helper()
+	for range passes {
+		r.RunOnce(context.Background())
+	}
+}
+
+func (f *fixture) recommend(t *testing.T, r *recommend.Recommender) *recommend.Result {
+	t.Helper()
+	db, err := f.repos.Databases.GetByID(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	result, err := r.Recommend(context.Background(), db)
+	require.NoError(t, err)
+	return result
+}
+
+func (f *fixture) changes(t *testing.T) []database.TierChange {
+	t.Helper()
+	changes, err := f.repos.TierChanges.ListByDatabase(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	return changes
+}
+
+// currentWindow returns a daily window around the current time.
+func currentWindow(t *testing.T) recommend.Window {
+	t.Helper()
+	h := time.Now().UTC().Hour()
+	w, err := recommend.ParseWindow(fmt.Sprintf("%02d:00-%02d:00", h, (h+2)%24))
+	require.NoError(t, err)
+	return w
+}
+
+func TestRecommend_InsufficientSamples(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1900, MemoryBytes: 3900 * mib})
+	r := f.recommender()
+
+	f.run(t, r, 2)
+
+	result := f.recommend(t, r)
+	assert.Equal(t, "standard", result.CurrentTier)
+	assert.Equal(t, 2, result.SampleCount)
+	assert.Equal(t, 3, result.MinSamples)
+	assert.Empty(t, result.Recommendations)
+	assert.Equal(t, int64(1900), result.Usage.CPUP95Millis)
+	assert.Equal(t, int64(2000), result.Usage.CPURequestMillis)
+}
+
+func TestRecommend_Upsize(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1800, MemoryBytes: 2048 * mib})
+	r := f.recommender()
+
+	f.run(t, r, 3)
+
+	result := f.recommend(t, r)
+	require.Len(t, result.Recommendations, 1)
+	assert.Equal(t, recommend.ActionUpsize, result.Recommendations[0].Action)
+	assert.Equal(t, "large", result.Recommendations[0].Tier)
+	assert.Contains(t, result.Recommendations[0].Reason, "1800m")
+}
+
+func TestRecommend_Downsize(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 200, MemoryBytes: 512 * mib})
+	r := f.recommender()
+
+	f.run(t, r, 3)
+
+	result := f.recommend(t, r)
+	require.Len(t, result.Recommendations, 1)
+	assert.Equal(t, recommend.ActionDownsize, result.Recommendations[0].Action)
+	assert.Equal(t, "small", result.Recommendations[0].Tier)
+}
+
+func TestRecommend_SkipsTiersNotAllowingClassification(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 200, MemoryBytes: 512 * mib})
+	_, err := f.repos.Tiers.Update(context.Background(), f.tiers["small"].ID,
+		tier.UpdateFields{DataClassifications: []string{database.ClassificationRestricted}})
+	require.NoError(t, err)
+	r := f.recommender()
+
+	f.run(t, r, 3)
+
+	result := f.recommend(t, r)
+	assert.Empty(t, result.Recommendations, "the internal database may not move to a restricted-only tier")
+}
+
+func TestRecommend_RightSized_NoRecommendation(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1000, MemoryBytes: 2048 * mib})
+	r := f.recommender()
+
+	f.run(t, r, 3)
+
+	result := f.recommend(t, r)
+	assert.Equal(t, 3, result.SampleCount)
+	assert.Empty(t, result.Recommendations)
+}
+
+func TestRecommend_UsesCPUP95(t *testing.T) {
+	f := setup(t, provider.ComputeResources{})
+	ctx := context.Background()
+	for i := int64(1); i <= 20; i++ {
+		require.NoError(t, f.repos.UsageSamples.Record(ctx, &database.UsageSample{
+			DatabaseID:         f.db.ID,
+			CPURequestMillis:   2000,
+			CPUUsedMillis:      i * 100,
+			MemoryRequestBytes: 4096 * mib,
+			MemoryUsedBytes:    i * 100 * mib,
+		}))
+	}
+
+	result := f.recommend(t, f.recommender())
+
+	assert.Equal(t, int64(1900), result.Usage.CPUP95Millis)
+	assert.Equal(t, 2000*mib, result.Usage.MemoryMaxBytes)
+	require.Len(t, result.Recommendations, 1)
+	assert.Equal(t, "large", result.Recommendations[0].Tier)
+}
+
+func TestRecommend_ProviderWithoutCompute_NoSamples(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1800})
+	registry := provider.NewRegistry()
+	registry.Register("sized", fake.NewProvider())
+	r := recommend.New(f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, registry,
+		f.repos.UsageSamples, f.repos.TierChanges, time.Minute, 24*time.Hour)
+
+	r.RunOnce(context.Background())
+
+	result := f.recommend(t, r)
+	assert.Equal(t, 0, result.SampleCount)
+	assert.Empty(t, result.Recommendations)
+}
+
+func TestRecommender_AutoApply_InWindow(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1800, MemoryBytes: 2048 * mib})
+	r := f.recommender(recommend.WithAutoApply(currentWindow(t)))
+
+	f.run(t, r, 3)
+
+	applies := f.provider.ApplyCalls()
+	require.Len(t, applies, 1)
+	assert.Equal(t, "large", applies[0].Database.Tier)
+	assert.Equal(t, "kind: Cluster # large", applies[0].Manifests)
+
+	db, err := f.repos.Databases.GetByID(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.tiers["large"].ID, *db.TierID)
+	assert.Equal(t, int64(2), db.Generation)
+
+	changes := f.changes(t)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "standard", changes[0].FromTier)
+	assert.Equal(t, "large", changes[0].ToTier)
+	assert.Equal(t, recommend.Actor, changes[0].Actor)
+	assert.Equal(t, database.TierChangeApplied, changes[0].Status)
+	assert.Contains(t, changes[0].Reason, "upsize")
+}
+
+func TestRecommender_AutoApply_WaitsForSamplesAfterChange(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1800, MemoryBytes: 2048 * mib})
+	r := f.recommender(recommend.WithAutoApply(currentWindow(t)))
+
+	f.run(t, r, 3)
+	// The provider keeps reporting the same usage; the database must not be
+	// moved again until enough samples of the new tier are collected.
+	f.run(t, r, 1)
+
+	assert.Len(t, f.provider.ApplyCalls(), 1)
+	assert.Len(t, f.changes(t), 1)
+}
+
+func TestRecommender_AutoApply_Failure_Recorded(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1800, MemoryBytes: 2048 * mib})
+	f.provider.ApplyFn = func(_ context.Context, _ provider.ProviderDatabase, _ string) error {
+		return errors.New("admission webhook denied the request")
+	}
+	r := f.recommender(recommend.WithAutoApply(currentWindow(t)))
+
+	f.run(t, r, 3)
+
+	db, err := f.repos.Databases.GetByID(context.Background(), f.db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.tiers["standard"].ID, *db.TierID)
+
+	changes := f.changes(t)
+	require.Len(t, changes, 1)
+	assert.Equal(t, database.TierChangeFailed, changes[0].Status)
+	assert.Contains(t, changes[0].Error, "admission webhook denied")
+}
+
+func TestRecommender_AutoApply_OutsideWindow(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1800, MemoryBytes: 2048 * mib})
+	h := time.Now().UTC().Hour()
+	w, err := recommend.ParseWindow(fmt.Sprintf("%02d:00-%02d:00", (h+2)%24, (h+3)%24))
+	require.NoError(t, err)
+	r := f.recommender(recommend.WithAutoApply(w))
+
+	f.run(t, r, 3)
+
+	assert.Empty(t, f.provider.ApplyCalls())
+	assert.Empty(t, f.changes(t))
+	assert.Len(t, f.recommend(t, r).Recommendations, 1)
+}
+
+func TestRecommender_PrunesSamplesOutsideLookback(t *testing.T) {
+	f := setup(t, provider.ComputeResources{CPUMillis: 1000, MemoryBytes: 2048 * mib})
+	r := f.recommender(recommend.WithClock(func() time.Time { return time.Now().Add(48 * time.Hour) }))
+	ctx := context.Background()
+	require.NoError(t, f.repos.UsageSamples.Record(ctx, &database.UsageSample{DatabaseID: f.db.ID, CPUUsedMillis: 100}))
+
+	r.RunOnce(ctx)
+
+	samples, err := f.repos.UsageSamples.ListByDatabase(ctx, f.db.ID, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, samples)
+}
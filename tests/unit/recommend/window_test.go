@@ -0,0 +1,56 @@
+package recommend_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/recommend"
+)
+
+func TestParseWindow_Invalid(t *testing.T) {
+	for _, spec := range []string{"", "02:00", "25:00-26:00", "Someday 02:00-04:00", "02:00-02:00", "Sun 02:00 04:00"} {
+		t.Run(spec, func(t *testing.T) {
+			_, err := recommend.ParseWindow(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestWindow_Contains(t *testing.T) {
+	// 2026-02-01 is a Sunday.
+	sunday := func(hour, minute int) time.Time {
+		return time.Date(2026, 2, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		spec string
+		at   time.Time
+		want bool
+	}{
+		{"02:00-04:00", sunday(2, 0), true},
+		{"02:00-04:00", sunday(3, 59), true},
+		{"02:00-04:00", sunday(4, 0), false},
+		{"02:00-04:00", sunday(1, 59), false},
+		{"02:00-04:00", sunday(3, 0).AddDate(0, 0, 3), true},
+		{"23:00-01:00", sunday(23, 30), true},
+		{"23:00-01:00", sunday(0, 30), true},
+		{"23:00-01:00", sunday(1, 0), false},
+		{"Sun 02:00-04:00", sunday(3, 0), true},
+		{"sun 02:00-04:00", sunday(3, 0), true},
+		{"Sun 02:00-04:00", sunday(3, 0).AddDate(0, 0, 1), false},
+		{"Sat 23:00-01:00", sunday(0, 30), true},
+		{"Sat 23:00-01:00", sunday(23, 30), false},
+		{"02:00-04:00", time.Date(2026, 2, 1, 4, 30, 0, 0, time.FixedZone("CET", 3600)), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec+" "+tt.at.Format(time.RFC3339), func(t *testing.T) {
+			w, err := recommend.ParseWindow(tt.spec)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, w.Contains(tt.at))
+			assert.Equal(t, tt.spec, w.String())
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package reconciler_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// BenchmarkReconcilerTick_1k measures a single reconciliation pass over 1000
+// databases whose provider reports them as steady (no status writes).
+func BenchmarkReconcilerTick_1k(b *testing.B) {
+	prev := slog.Default()
+	slog.SetDefault(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	b.Cleanup(func() { slog.SetDefault(prev) })
+
+	repos := fake.NewRepositories()
+	ctx := context.Background()
+
+	tm := &team.Team{Name: "bench", Role: "platform"}
+	bp := &blueprint.Blueprint{Name: "bench-bp", Provider: "cnpg", Manifests: "kind: Cluster"}
+	if err := repos.Teams.Create(ctx, tm); err != nil {
+		b.Fatal(err)
+	}
+	if err := repos.Blueprints.Create(ctx, bp); err != nil {
+		b.Fatal(err)
+	}
+	tr := &tier.Tier{Name: "bench", BlueprintID: &bp.ID, DestructionStrategy: "hard_delete"}
+	if err := repos.Tiers.Create(ctx, tr); err != nil {
+		b.Fatal(err)
+	}
+	for i := range 1000 {
+		db := &database.Database{Name: fmt.Sprintf("bench-db-%04d", i), OwnerTeamID: tm.ID, TierID: &tr.ID}
+		if err := repos.Databases.Create(ctx, db); err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	p := fake.NewProvider()
+	p.CheckHealthFn = func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+		return provider.HealthResult{Status: "provisioning"}, nil
+	}
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", p)
+	rec := reconciler.New(repos.Databases, repos.Tiers, repos.Blueprints, registry, time.Hour)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for b.Loop() {
+		rec.RunOnce(ctx)
+	}
+}
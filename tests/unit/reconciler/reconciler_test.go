@@ -1,7 +1,11 @@
 package reconciler_test
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -12,6 +16,8 @@ import (
 
 	"github.com/daap14/daap/internal/blueprint"
 	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/metrics"
+	"github.com/daap14/daap/internal/notify"
 	"github.com/daap14/daap/internal/provider"
 	"github.com/daap14/daap/internal/reconciler"
 	"github.com/daap14/daap/internal/tier"
@@ -23,6 +29,7 @@ type mockRepo struct {
 	mu             sync.Mutex
 	createFn       func(ctx context.Context, db *database.Database) error
 	getByIDFn      func(ctx context.Context, id uuid.UUID) (*database.Database, error)
+	getByNameFn    func(ctx context.Context, name string) (*database.Database, error)
 	listFn         func(ctx context.Context, filter database.ListFilter) (*database.ListResult, error)
 	updateFn       func(ctx context.Context, id uuid.UUID, fields database.UpdateFields) (*database.Database, error)
 	updateStatusFn func(ctx context.Context, id uuid.UUID, su database.StatusUpdate) (*database.Database, error)
@@ -45,6 +52,13 @@ func (m *mockRepo) GetByID(ctx context.Context, id uuid.UUID) (*database.Databas
 	return nil, database.ErrNotFound
 }
 
+func (m *mockRepo) GetByName(ctx context.Context, name string) (*database.Database, error) {
+	if m.getByNameFn != nil {
+		return m.getByNameFn(ctx, name)
+	}
+	return nil, database.ErrNotFound
+}
+
 func (m *mockRepo) List(ctx context.Context, filter database.ListFilter) (*database.ListResult, error) {
 	if m.listFn != nil {
 		return m.listFn(ctx, filter)
@@ -76,6 +90,10 @@ func (m *mockRepo) SoftDelete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+func (m *mockRepo) Acknowledge(_ context.Context, _ uuid.UUID, _ *database.Acknowledgement) (*database.Database, error) {
+	return nil, database.ErrNotFound
+}
+
 func (m *mockRepo) getStatusUpdates() []database.StatusUpdate {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -124,6 +142,12 @@ func (m *mockBPRepo) GetByName(_ context.Context, _ string) (*blueprint.Blueprin
 }
 func (m *mockBPRepo) List(_ context.Context) ([]blueprint.Blueprint, error) { return nil, nil }
 func (m *mockBPRepo) Delete(_ context.Context, _ uuid.UUID) error           { return nil }
+func (m *mockBPRepo) Update(_ context.Context, _ uuid.UUID, _ blueprint.UpdateFields) (*blueprint.Blueprint, error) {
+	return nil, blueprint.ErrBlueprintNotFound
+}
+func (m *mockBPRepo) ListVersions(_ context.Context, _ uuid.UUID) ([]blueprint.Version, error) {
+	return nil, blueprint.ErrBlueprintNotFound
+}
 
 // --- Mock Provider ---
 
@@ -380,6 +404,256 @@ func TestReconcile_ReadyToError(t *testing.T) {
 	assert.Equal(t, "error", lastUpdate.Status)
 }
 
+func TestReconcile_RecordsObservedGeneration(t *testing.T) {
+	// Arrange: a ready database whose spec changed since the last reconcile
+	id := uuid.New()
+	repo := &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			if filter.Status != nil && *filter.Status == "ready" {
+				db := provisioningDB(id, "changed-db")
+				db.Status = "ready"
+				db.Generation = 3
+				db.ObservedGeneration = 2
+				return &database.ListResult{
+					Databases: []database.Database{db},
+					Total:     1, Page: 1, Limit: 100,
+				}, nil
+			}
+			return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+		},
+	}
+
+	p := &mockProvider{
+		checkHealthFn: func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+			return provider.HealthResult{Status: "ready"}, nil
+		},
+	}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Minute)
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert: status is unchanged but the new generation is recorded
+	updates := repo.getStatusUpdates()
+	require.Len(t, updates, 1)
+	assert.Equal(t, "ready", updates[0].Status)
+	require.NotNil(t, updates[0].ObservedGeneration)
+	assert.Equal(t, int64(3), *updates[0].ObservedGeneration)
+}
+
+func TestReconcile_ObservedGenerationCurrent_NoUpdate(t *testing.T) {
+	// Arrange: a ready database already reconciled at its current generation
+	repo := &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			if filter.Status != nil && *filter.Status == "ready" {
+				db := provisioningDB(uuid.New(), "steady-db")
+				db.Status = "ready"
+				db.Generation = 2
+				db.ObservedGeneration = 2
+				return &database.ListResult{
+					Databases: []database.Database{db},
+					Total:     1, Page: 1, Limit: 100,
+				}, nil
+			}
+			return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+		},
+	}
+
+	p := &mockProvider{
+		checkHealthFn: func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+			return provider.HealthResult{Status: "ready"}, nil
+		},
+	}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Minute)
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert
+	assert.Empty(t, repo.getStatusUpdates())
+}
+
+func TestReconcile_RecordsInstanceChanges(t *testing.T) {
+	lag := 2 * time.Second
+	current := &database.Instances{Total: 3, Ready: 3, Primary: "daap-steady-db-1", ReplicationLag: &lag}
+	reason := database.ReasonReadinessGateFailed
+
+	tests := []struct {
+		name     string
+		reported *provider.InstanceStatus
+		want     *database.Instances
+	}{
+		{"unchanged", &provider.InstanceStatus{Total: 3, Ready: 3, Primary: "daap-steady-db-1", ReplicationLag: &lag}, nil},
+		{"lag within the same second", &provider.InstanceStatus{Total: 3, Ready: 3, Primary: "daap-steady-db-1", ReplicationLag: ptrDuration(2400 * time.Millisecond)}, nil},
+		{"not reported", nil, nil},
+		{"replica down", &provider.InstanceStatus{Total: 3, Ready: 2, Primary: "daap-steady-db-1", ReplicationLag: &lag},
+			&database.Instances{Total: 3, Ready: 2, Primary: "daap-steady-db-1", ReplicationLag: &lag}},
+		{"failover", &provider.InstanceStatus{Total: 3, Ready: 3, Primary: "daap-steady-db-2"},
+			&database.Instances{Total: 3, Ready: 3, Primary: "daap-steady-db-2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+					if filter.Status != nil && *filter.Status == "ready" {
+						db := provisioningDB(uuid.New(), "steady-db")
+						db.Status = "ready"
+						db.StatusReason = &reason
+						db.Instances = current
+						return &database.ListResult{Databases: []database.Database{db}, Total: 1, Page: 1, Limit: 100}, nil
+					}
+					return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+				},
+			}
+			p := &mockProvider{
+				checkHealthFn: func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+					return provider.HealthResult{Status: "ready", Instances: tt.reported}, nil
+				},
+			}
+
+			reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Minute).RunOnce(context.Background())
+
+			updates := repo.getStatusUpdates()
+			if tt.want == nil {
+				assert.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+			assert.Equal(t, "ready", updates[0].Status)
+			assert.Equal(t, reason, updates[0].Reason, "recording instances keeps the status reason")
+			assert.Equal(t, tt.want, updates[0].Instances)
+		})
+	}
+}
+
+func TestReconcile_RecordsExternalHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		recorded *string
+		reported string
+		want     *string // nil if nothing is recorded
+	}{
+		{"load balancer provisioned", nil, "a1b2.elb.amazonaws.com", ptrString("a1b2.elb.amazonaws.com")},
+		{"load balancer replaced", ptrString("a1b2.elb.amazonaws.com"), "198.51.100.7", ptrString("198.51.100.7")},
+		{"unchanged", ptrString("198.51.100.7"), "198.51.100.7", nil},
+		{"not reported", ptrString("198.51.100.7"), "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+					if filter.Status != nil && *filter.Status == "ready" {
+						db := provisioningDB(uuid.New(), "exposed-db")
+						db.Status = "ready"
+						db.Exposure = database.Exposure{Type: database.ExposureExternal, AllowedSourceRanges: []string{"203.0.113.0/24"}}
+						db.ExternalHost = tt.recorded
+						return &database.ListResult{Databases: []database.Database{db}, Total: 1, Page: 1, Limit: 100}, nil
+					}
+					return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+				},
+			}
+			var exposure provider.Exposure
+			p := &mockProvider{
+				checkHealthFn: func(_ context.Context, pdb provider.ProviderDatabase) (provider.HealthResult, error) {
+					exposure = pdb.Exposure
+					return provider.HealthResult{Status: "ready", ExternalHost: tt.reported}, nil
+				},
+			}
+
+			reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Minute).RunOnce(context.Background())
+
+			assert.Equal(t, provider.Exposure{Type: provider.ExposureExternal, AllowedSourceRanges: []string{"203.0.113.0/24"}}, exposure)
+			updates := repo.getStatusUpdates()
+			if tt.want == nil {
+				assert.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+			assert.Equal(t, "ready", updates[0].Status)
+			assert.Equal(t, tt.want, updates[0].ExternalHost)
+		})
+	}
+}
+
+func ptrDuration(d time.Duration) *time.Duration { return &d }
+
+func ptrString(s string) *string { return &s }
+
+// versionedProvider is a mockProvider reporting the operator version of
+// every database as version.
+type versionedProvider struct {
+	mockProvider
+	version string
+}
+
+func (p *versionedProvider) OperatorVersion(_ context.Context, _ provider.ProviderDatabase) (string, error) {
+	return p.version, nil
+}
+
+func TestReconcile_OperatorVersion(t *testing.T) {
+	changed := database.Condition{
+		Type:    database.ConditionNeedsReview,
+		Reason:  database.ReasonOperatorVersionChanged,
+		Message: "Provisioned under cnpg operator 1.24.1, now running under 1.25.0",
+	}
+
+	tests := []struct {
+		name           string
+		recorded       string
+		conditions     []database.Condition
+		current        string
+		wantUpdate     bool
+		wantVersion    *string
+		wantConditions []database.Condition
+	}{
+		{name: "unknown", current: ""},
+		{name: "first seen", current: "1.24.1", wantUpdate: true, wantVersion: ptrString("1.24.1")},
+		{name: "unchanged", recorded: "1.24.1", current: "1.24.1"},
+		{name: "changed", recorded: "1.24.1", current: "1.25.0", wantUpdate: true, wantConditions: []database.Condition{changed}},
+		{name: "already flagged", recorded: "1.24.1", conditions: []database.Condition{changed}, current: "1.25.0"},
+		{name: "changed back", recorded: "1.24.1", conditions: []database.Condition{changed}, current: "1.24.1",
+			wantUpdate: true, wantConditions: []database.Condition{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &mockRepo{
+				listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+					if filter.Status != nil && *filter.Status == "ready" {
+						db := provisioningDB(uuid.New(), "steady-db")
+						db.Status = "ready"
+						db.OperatorVersion = tt.recorded
+						db.Conditions = tt.conditions
+						return &database.ListResult{Databases: []database.Database{db}, Total: 1, Page: 1, Limit: 100}, nil
+					}
+					return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+				},
+			}
+			p := &versionedProvider{version: tt.current}
+			p.checkHealthFn = func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+				return provider.HealthResult{Status: "ready"}, nil
+			}
+
+			reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Minute).RunOnce(context.Background())
+
+			updates := repo.getStatusUpdates()
+			if !tt.wantUpdate {
+				assert.Empty(t, updates)
+				return
+			}
+			require.Len(t, updates, 1)
+			assert.Equal(t, "ready", updates[0].Status)
+			assert.Equal(t, tt.wantVersion, updates[0].OperatorVersion)
+			for i := range updates[0].Conditions {
+				assert.False(t, updates[0].Conditions[i].Since.IsZero())
+				updates[0].Conditions[i].Since = time.Time{}
+			}
+			assert.Equal(t, tt.wantConditions, updates[0].Conditions)
+		})
+	}
+}
+
 func TestReconcile_NoDatabases(t *testing.T) {
 	// Arrange: empty list returned
 	checkHealthCalled := false
@@ -480,3 +754,426 @@ func TestReconcile_DatabaseWithoutTier_Skipped(t *testing.T) {
 	updates := repo.getStatusUpdates()
 	assert.Empty(t, updates, "expected no status updates for tier-less database")
 }
+
+// metricValue reads a sample from the Default metrics registry.
+func metricValue(t *testing.T, name string) float64 {
+	t.Helper()
+	var buf bytes.Buffer
+	metrics.Default.WriteText(&buf)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if v, ok := strings.CutPrefix(line, name+" "); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			require.NoError(t, err)
+			return f
+		}
+	}
+	t.Fatalf("metric %s not found", name)
+	return 0
+}
+
+func TestReconcile_ProvisioningSLOExceeded_WarnsOnce(t *testing.T) {
+	// Arrange: database created two hours ago, still provisioning
+	id := uuid.New()
+	repo := &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			if filter.Status != nil && *filter.Status == "provisioning" {
+				db := provisioningDB(id, "slowdb")
+				db.CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+				return &database.ListResult{
+					Databases: []database.Database{db},
+					Total:     1, Page: 1, Limit: 100,
+				}, nil
+			}
+			return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+		},
+	}
+
+	before := metricValue(t, "daap_database_provisioning_slo_breaches_total")
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(&mockProvider{}), 50*time.Millisecond,
+		reconciler.WithProvisioningSLO(time.Minute))
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act: several ticks
+	go r.Start(ctx)
+	time.Sleep(250 * time.Millisecond)
+	cancel()
+
+	// Assert: counted once despite repeated ticks
+	after := metricValue(t, "daap_database_provisioning_slo_breaches_total")
+	assert.Equal(t, float64(1), after-before)
+}
+
+func TestReconcile_ProvisioningToReady_ObservesDuration(t *testing.T) {
+	// Arrange
+	id := uuid.New()
+	repo := &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			if filter.Status != nil && *filter.Status == "provisioning" {
+				db := provisioningDB(id, "fastdb")
+				return &database.ListResult{
+					Databases: []database.Database{db},
+					Total:     1, Page: 1, Limit: 100,
+				}, nil
+			}
+			return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+		},
+	}
+	p := &mockProvider{
+		checkHealthFn: func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+			return provider.HealthResult{Status: "ready"}, nil
+		},
+	}
+
+	before := metricValue(t, "daap_database_provisioning_duration_seconds_count")
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), 50*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	go r.Start(ctx)
+	time.Sleep(150 * time.Millisecond)
+	cancel()
+
+	// Assert
+	after := metricValue(t, "daap_database_provisioning_duration_seconds_count")
+	assert.GreaterOrEqual(t, after-before, float64(1))
+}
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *recordingNotifier) get() []notify.Notification {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return append([]notify.Notification(nil), n.notifications...)
+}
+
+func TestReconcile_ProvisioningTimeout_MarksErrorAndNotifies(t *testing.T) {
+	// Arrange: database stuck in provisioning for two hours
+	id := uuid.New()
+	healthChecked := false
+	repo := &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			if filter.Status != nil && *filter.Status == "provisioning" {
+				db := provisioningDB(id, "stuckdb")
+				db.CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+				return &database.ListResult{
+					Databases: []database.Database{db},
+					Total:     1, Page: 1, Limit: 100,
+				}, nil
+			}
+			return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+		},
+	}
+	p := &mockProvider{
+		checkHealthFn: func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+			healthChecked = true
+			return provider.HealthResult{Status: "provisioning"}, nil
+		},
+	}
+	notifier := &recordingNotifier{}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Second,
+		reconciler.WithProvisioningTimeout(time.Hour),
+		reconciler.WithNotifier(notifier))
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert
+	updates := repo.getStatusUpdates()
+	require.Len(t, updates, 1)
+	assert.Equal(t, "error", updates[0].Status)
+	assert.Equal(t, database.ReasonProvisioningTimeout, updates[0].Reason)
+	assert.False(t, healthChecked, "expected no health check for a reaped database")
+
+	sent := notifier.get()
+	require.Len(t, sent, 1)
+	assert.Equal(t, "ProvisioningTimeout", sent[0].Event)
+	assert.Equal(t, id, sent[0].DatabaseID)
+	assert.Equal(t, "stuckdb", sent[0].Database)
+	assert.Equal(t, database.ReasonProvisioningTimeout, sent[0].Reason)
+}
+
+func TestReconcile_ProvisioningWithinTimeout_NotReaped(t *testing.T) {
+	// Arrange
+	id := uuid.New()
+	repo := &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			if filter.Status != nil && *filter.Status == "provisioning" {
+				db := provisioningDB(id, "newdb")
+				return &database.ListResult{
+					Databases: []database.Database{db},
+					Total:     1, Page: 1, Limit: 100,
+				}, nil
+			}
+			return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+		},
+	}
+	notifier := &recordingNotifier{}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(&mockProvider{}), time.Second,
+		reconciler.WithProvisioningTimeout(time.Hour),
+		reconciler.WithNotifier(notifier))
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert
+	assert.Empty(t, repo.getStatusUpdates())
+	assert.Empty(t, notifier.get())
+}
+
+// --- Readiness gate ---
+
+type stubGate struct {
+	mu    sync.Mutex
+	err   error
+	calls int
+}
+
+func (g *stubGate) Check(_ context.Context, _ provider.ProviderDatabase, _ provider.HealthResult) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.calls++
+	return g.err
+}
+
+func readyProvider() *mockProvider {
+	host, port, secret := "daap-gated-pooler.default.svc.cluster.local", 5432, "daap-gated-app"
+	return &mockProvider{
+		checkHealthFn: func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+			return provider.HealthResult{Status: "ready", Host: &host, Port: &port, SecretName: &secret}, nil
+		},
+	}
+}
+
+func listOnly(status string, db database.Database) func(context.Context, database.ListFilter) (*database.ListResult, error) {
+	return func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+		if filter.Status != nil && *filter.Status == status {
+			return &database.ListResult{Databases: []database.Database{db}, Total: 1, Page: 1, Limit: 100}, nil
+		}
+		return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+	}
+}
+
+func TestReconcile_ReadinessGateFails_StaysProvisioning(t *testing.T) {
+	// Arrange
+	db := provisioningDB(uuid.New(), "gated")
+	repo := &mockRepo{listFn: listOnly("provisioning", db)}
+	gate := &stubGate{err: errors.New("pooler refused connection 3 of 5")}
+	before := metricValue(t, "daap_database_readiness_gate_failures_total")
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Second,
+		reconciler.WithReadinessGate(gate))
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert: the reason is recorded but the status does not change
+	updates := repo.getStatusUpdates()
+	require.Len(t, updates, 1)
+	assert.Equal(t, "provisioning", updates[0].Status)
+	assert.Equal(t, database.ReasonReadinessGateFailed, updates[0].Reason)
+	assert.Equal(t, before+1, metricValue(t, "daap_database_readiness_gate_failures_total"))
+}
+
+func TestReconcile_ReadinessGateFails_ReasonRecordedOnce(t *testing.T) {
+	// Arrange: the failure was already recorded on a previous pass
+	db := provisioningDB(uuid.New(), "gated")
+	reason := database.ReasonReadinessGateFailed
+	db.StatusReason = &reason
+	repo := &mockRepo{listFn: listOnly("provisioning", db)}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Second,
+		reconciler.WithReadinessGate(&stubGate{err: errors.New("timeout")}))
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert
+	assert.Empty(t, repo.getStatusUpdates())
+}
+
+func TestReconcile_ReadinessGatePasses_MarksReady(t *testing.T) {
+	// Arrange
+	db := provisioningDB(uuid.New(), "gated")
+	reason := database.ReasonReadinessGateFailed
+	db.StatusReason = &reason
+	repo := &mockRepo{listFn: listOnly("provisioning", db)}
+	gate := &stubGate{}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Second,
+		reconciler.WithReadinessGate(gate))
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert
+	updates := repo.getStatusUpdates()
+	require.Len(t, updates, 1)
+	assert.Equal(t, "ready", updates[0].Status)
+	assert.Empty(t, updates[0].Reason, "moving to ready clears the gate failure reason")
+	assert.Equal(t, 1, gate.calls)
+}
+
+func TestReconcile_ReadinessGate_SkippedForReadyDatabase(t *testing.T) {
+	// Arrange
+	db := provisioningDB(uuid.New(), "gated")
+	db.Status = "ready"
+	repo := &mockRepo{listFn: listOnly("ready", db)}
+	gate := &stubGate{err: errors.New("should not be called")}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Second,
+		reconciler.WithReadinessGate(gate))
+
+	// Act
+	r.RunOnce(context.Background())
+
+	// Assert
+	assert.Equal(t, 0, gate.calls)
+}
+
+func TestReconcile_SkipsPausedDatabases(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     string
+		until      time.Time
+		wantWrites int // status updates and deletions
+	}{
+		{"paused", "provisioning", time.Now().Add(time.Hour), 0},
+		{"pause expired", "provisioning", time.Now().Add(-time.Minute), 1},
+		{"deprovisioning", "deprovisioning", time.Now().Add(time.Hour), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := provisioningDB(uuid.New(), "paused-db")
+			db.Status = tt.status
+			db.ReconciliationPause = &database.ReconciliationPause{By: "alice", Until: tt.until}
+			deletes := 0
+			repo := &mockRepo{
+				listFn: listOnly(tt.status, db),
+				softDeleteFn: func(_ context.Context, _ uuid.UUID) error {
+					deletes++
+					return nil
+				},
+			}
+
+			reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute).RunOnce(context.Background())
+
+			assert.Equal(t, tt.wantWrites, len(repo.getStatusUpdates())+deletes)
+		})
+	}
+}
+
+func TestReconcile_RecordsErrorDetail(t *testing.T) {
+	failed := func(message string) *mockProvider {
+		return &mockProvider{
+			checkHealthFn: func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+				return provider.HealthResult{Status: "error", Reason: "FAILED_TO_CREATE_PRIMARY", Message: message}, nil
+			},
+		}
+	}
+
+	// A database entering error records the provider's detail.
+	repo := &mockRepo{listFn: listOnly("provisioning", provisioningDB(uuid.New(), "faildb"))}
+	reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(failed("Failed to create primary: storageclass not found")), time.Minute).RunOnce(context.Background())
+
+	updates := repo.getStatusUpdates()
+	require.Len(t, updates, 1)
+	assert.Equal(t, "FAILED_TO_CREATE_PRIMARY", updates[0].Reason)
+	assert.Equal(t, "Failed to create primary: storageclass not found", updates[0].Message)
+
+	// A database already in error is only updated when the detail changes.
+	db := provisioningDB(uuid.New(), "faildb")
+	db.Status = "error"
+	db.StatusReason = ptrString("FAILED_TO_CREATE_PRIMARY")
+	db.StatusMessage = ptrString("Failed to create primary: storageclass not found")
+	for message, want := range map[string]int{
+		"Failed to create primary: storageclass not found": 0,
+		"Failed to create primary: quota exceeded":         1,
+	} {
+		repo := &mockRepo{listFn: listOnly("error", db)}
+		reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(failed(message)), time.Minute).RunOnce(context.Background())
+		assert.Len(t, repo.getStatusUpdates(), want, message)
+	}
+}
+
+func TestReconcile_LastSuccess(t *testing.T) {
+	failing := &mockRepo{listFn: func(_ context.Context, _ database.ListFilter) (*database.ListResult, error) {
+		return nil, errors.New("connection refused")
+	}}
+	r := reconciler.New(failing, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute)
+	r.RunOnce(context.Background())
+	assert.True(t, r.LastSuccess().IsZero(), "a pass that could not list databases is not a success")
+	assert.False(t, r.Stale(time.Now().Add(time.Hour)), "a reconciler that was never started is not stale")
+
+	r = reconciler.New(&mockRepo{}, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute)
+	before := time.Now()
+	r.RunOnce(context.Background())
+	assert.False(t, r.LastSuccess().Before(before))
+	assert.False(t, r.Stale(time.Now().Add(2*time.Minute)))
+	assert.True(t, r.Stale(time.Now().Add(4*time.Minute)))
+}
+
+func TestReconcile_State(t *testing.T) {
+	steady := provisioningDB(uuid.New(), "steady-db")
+	steady.Status = "ready"
+	pending := provisioningDB(uuid.New(), "pending-db")
+	repo := &mockRepo{listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+		var dbs []database.Database
+		switch *filter.Status {
+		case "ready":
+			dbs = []database.Database{steady}
+		case "provisioning":
+			dbs = []database.Database{pending}
+		}
+		return &database.ListResult{Databases: dbs, Total: len(dbs), Page: 1, Limit: 100}, nil
+	}}
+	p := &mockProvider{checkHealthFn: func(_ context.Context, db provider.ProviderDatabase) (provider.HealthResult, error) {
+		if db.Name == "pending-db" {
+			return provider.HealthResult{}, errors.New("connection refused")
+		}
+		return provider.HealthResult{Status: "ready"}, nil
+	}}
+
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Minute)
+	assert.True(t, r.State().LastRunAt.IsZero())
+
+	before := time.Now()
+	r.RunOnce(context.Background())
+
+	state := r.State()
+	assert.Equal(t, time.Minute, state.Interval)
+	assert.False(t, state.LastRunAt.Before(before))
+	assert.Equal(t, 2, state.Processed)
+	assert.Equal(t, 1, state.Backlog, "only the provisioning database is pending")
+	assert.Equal(t, map[string]int{"cnpg": 1}, state.Errors)
+	assert.False(t, state.LastSuccessAt.IsZero())
+}
+
+func TestSetInterval(t *testing.T) {
+	r := reconciler.New(&mockRepo{}, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Hour)
+	require.Error(t, r.SetInterval(0))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.Start(ctx)
+
+	require.NoError(t, r.SetInterval(10*time.Millisecond))
+	assert.Equal(t, 10*time.Millisecond, r.Interval())
+	assert.Eventually(t, func() bool { return !r.State().LastRunAt.IsZero() }, time.Second, 5*time.Millisecond,
+		"the running loop picks up the shorter interval")
+}
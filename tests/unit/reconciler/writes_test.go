@@ -0,0 +1,237 @@
+package reconciler_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/reconciler"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// batchingRepo is a mockRepo that applies status updates in batches.
+type batchingRepo struct {
+	*mockRepo
+	mu      sync.Mutex
+	batches [][]database.StatusWrite
+	err     error
+}
+
+func (r *batchingRepo) UpdateStatuses(_ context.Context, writes []database.StatusWrite) ([]uuid.UUID, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, writes)
+	if r.err != nil {
+		return nil, r.err
+	}
+	updated := make([]uuid.UUID, len(writes))
+	for i, w := range writes {
+		updated[i] = w.ID
+	}
+	return updated, nil
+}
+
+// pagedProvisioning lists n provisioning databases, a page at a time.
+func pagedProvisioning(n int) func(context.Context, database.ListFilter) (*database.ListResult, error) {
+	dbs := make([]database.Database, n)
+	for i := range dbs {
+		dbs[i] = provisioningDB(uuid.New(), fmt.Sprintf("db-%d", i))
+	}
+	return func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+		if filter.Status == nil || *filter.Status != "provisioning" {
+			return &database.ListResult{Databases: []database.Database{}, Page: filter.Page, Limit: filter.Limit}, nil
+		}
+		start := min((filter.Page-1)*filter.Limit, n)
+		end := min(start+filter.Limit, n)
+		return &database.ListResult{Databases: dbs[start:end], Total: n, Page: filter.Page, Limit: filter.Limit}, nil
+	}
+}
+
+func TestReconcile_BatchesStatusWrites(t *testing.T) {
+	repo := &batchingRepo{mockRepo: &mockRepo{listFn: pagedProvisioning(250)}}
+
+	reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute,
+		reconciler.WithWriteBatchSize(100)).RunOnce(context.Background())
+
+	require.Len(t, repo.batches, 3, "every page is reconciled and written in batches")
+	assert.Len(t, repo.batches[0], 100)
+	assert.Len(t, repo.batches[1], 100)
+	assert.Len(t, repo.batches[2], 50)
+	seen := map[uuid.UUID]bool{}
+	for _, batch := range repo.batches {
+		for _, w := range batch {
+			assert.Equal(t, "ready", w.Update.Status)
+			assert.Equal(t, "provisioning", w.Expected, "written only if still as read")
+			assert.Equal(t, audit.ActorReconciler, w.Update.UpdatedBy)
+			seen[w.ID] = true
+		}
+	}
+	assert.Len(t, seen, 250)
+	assert.Empty(t, repo.getStatusUpdates(), "no row-by-row updates")
+}
+
+func TestReconcile_MergesWritesOfOneDatabase(t *testing.T) {
+	repo := &batchingRepo{mockRepo: &mockRepo{
+		listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			if filter.Status != nil && *filter.Status == "ready" {
+				db := provisioningDB(uuid.New(), "steady-db")
+				db.Status = "ready"
+				db.Instances = &database.Instances{Total: 3, Ready: 3, Primary: "daap-steady-db-1"}
+				return &database.ListResult{Databases: []database.Database{db}, Total: 1, Page: 1, Limit: 100}, nil
+			}
+			return &database.ListResult{Databases: []database.Database{}, Total: 0, Page: 1, Limit: 100}, nil
+		},
+	}}
+	p := &versionedProvider{version: "1.25.0"}
+	p.checkHealthFn = func(_ context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+		return provider.HealthResult{Status: "ready", Instances: &provider.InstanceStatus{Total: 3, Ready: 2, Primary: "daap-steady-db-1"}}, nil
+	}
+
+	reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(p), time.Minute).RunOnce(context.Background())
+
+	require.Len(t, repo.batches, 1)
+	require.Len(t, repo.batches[0], 1)
+	su := repo.batches[0][0].Update
+	require.NotNil(t, su.Instances)
+	assert.Equal(t, 2, su.Instances.Ready)
+	assert.Equal(t, ptrString("1.25.0"), su.OperatorVersion)
+}
+
+func TestReconcile_FailedWritesSkipFollowUps(t *testing.T) {
+	repo := &batchingRepo{
+		mockRepo: &mockRepo{listFn: func(_ context.Context, filter database.ListFilter) (*database.ListResult, error) {
+			db := provisioningDB(uuid.New(), "stuckdb")
+			db.CreatedAt = time.Now().UTC().Add(-2 * time.Hour)
+			return listOnly("provisioning", db)(context.Background(), filter)
+		}},
+		err: errors.New("connection reset"),
+	}
+	notifier := &recordingNotifier{}
+
+	reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute,
+		reconciler.WithProvisioningTimeout(time.Hour),
+		reconciler.WithNotifier(notifier)).RunOnce(context.Background())
+
+	require.Len(t, repo.batches, 1)
+	assert.Empty(t, notifier.get(), "no notification for a timeout that was not recorded")
+}
+
+func TestReconcile_WriteRate(t *testing.T) {
+	repo := &batchingRepo{mockRepo: &mockRepo{listFn: pagedProvisioning(30)}}
+
+	start := time.Now()
+	reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute,
+		reconciler.WithWriteBatchSize(10),
+		reconciler.WithWriteRate(200)).RunOnce(context.Background())
+
+	require.Len(t, repo.batches, 3)
+	// The first batch is written right away, each of the others 10/200s later.
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
+
+func TestSetWriteRate(t *testing.T) {
+	repo := &batchingRepo{mockRepo: &mockRepo{listFn: pagedProvisioning(30)}}
+	r := reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute,
+		reconciler.WithWriteBatchSize(10))
+	r.SetWriteRate(200)
+
+	start := time.Now()
+	r.RunOnce(context.Background())
+
+	require.Len(t, repo.batches, 3)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond, "the rate set at runtime applies")
+}
+
+// auditLog records audit events.
+type auditLog struct {
+	mu     sync.Mutex
+	events []audit.Event
+}
+
+func (a *auditLog) Record(_ context.Context, e audit.Event) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, e)
+	return nil
+}
+
+func TestReconcile_AuditsWritesAsReconciler(t *testing.T) {
+	id := uuid.New()
+	db := provisioningDB(id, "audited-db")
+	db.DataClassification = "confidential"
+	repo := &batchingRepo{mockRepo: &mockRepo{listFn: listOnly("provisioning", db)}}
+	log := &auditLog{}
+
+	reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute,
+		reconciler.WithAudit(log)).RunOnce(context.Background())
+
+	require.Len(t, log.events, 1)
+	e := log.events[0]
+	assert.Equal(t, audit.ActorReconciler, e.Actor)
+	assert.Equal(t, "database.status_update", e.Action)
+	assert.Equal(t, id.String(), e.DatabaseID)
+	assert.Equal(t, "ready", e.Detail)
+	assert.Equal(t, "platform", e.Team)
+	assert.Equal(t, "confidential", e.DataClassification)
+	assert.Empty(t, e.Method, "not an API request")
+}
+
+func TestReconcile_DoesNotAuditFailedWrites(t *testing.T) {
+	repo := &batchingRepo{
+		mockRepo: &mockRepo{listFn: listOnly("provisioning", provisioningDB(uuid.New(), "failing-db"))},
+		err:      errors.New("connection refused"),
+	}
+	log := &auditLog{}
+
+	reconciler.New(repo, defaultTierRepo(), defaultBPRepo(), registryWith(readyProvider()), time.Minute,
+		reconciler.WithAudit(log)).RunOnce(context.Background())
+
+	assert.Empty(t, log.events)
+}
+
+func TestReconcile_DropsWritesOfDatabasesChangedSinceRead(t *testing.T) {
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	tr := &tier.Tier{Name: "standard", BlueprintID: &bp.ID, DestructionStrategy: tier.DestructionHardDelete}
+	require.NoError(t, repos.Tiers.Create(ctx, tr))
+	db := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID, Namespace: "default"}
+	require.NoError(t, repos.Databases.Create(ctx, db))
+
+	// The database is frozen by a deletion while the pass that found it
+	// provisioning is still running: the reconciler's write is queued on a
+	// stale read.
+	p := fake.NewProvider()
+	p.CheckHealthFn = func(ctx context.Context, _ provider.ProviderDatabase) (provider.HealthResult, error) {
+		_, err := repos.Databases.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "frozen"})
+		require.NoError(t, err)
+		return provider.HealthResult{Status: "ready"}, nil
+	}
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", p)
+	log := &auditLog{}
+
+	reconciler.New(repos.Databases, repos.Tiers, repos.Blueprints, registry, time.Minute,
+		reconciler.WithAudit(log)).RunOnce(ctx)
+
+	got, err := repos.Databases.GetByID(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "frozen", got.Status, "the freeze is not overwritten")
+	assert.Empty(t, log.events, "follow-ups of the dropped write do not run")
+}
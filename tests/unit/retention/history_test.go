@@ -0,0 +1,97 @@
+package retention_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/retention"
+	"github.com/daap14/daap/internal/revision"
+)
+
+func (f *fixture) rotator(months int, opts ...retention.HistoryOption) *retention.HistoryRotator {
+	opts = append(opts, retention.WithHistoryClock(func() time.Time { return f.now }))
+	return retention.NewHistoryRotator(f.repos.History, time.Hour, months, opts...)
+}
+
+func TestHistoryRotator_CreatesPartitionsAhead(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	f.now = time.Date(2026, 12, 15, 0, 0, 0, 0, time.UTC)
+
+	assert.Zero(t, f.rotator(0).RunOnce(ctx))
+
+	partitions, err := f.repos.History.ListPartitions(ctx)
+	require.NoError(t, err)
+	var names []string
+	for _, p := range partitions {
+		names = append(names, p.Name)
+	}
+	for _, name := range []string{
+		"database_status_history_2026_12", "database_status_history_2027_01",
+		"database_resize_events_2026_12", "database_resize_events_2027_01",
+		"database_tier_changes_2026_12", "database_tier_changes_2027_01",
+	} {
+		assert.Contains(t, names, name)
+	}
+}
+
+func TestHistoryRotator_RemovesMonthsPastRetention(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	db := &database.Database{Name: "orders", OwnerTeamID: f.team.ID, Namespace: "default", DataClassification: "internal"}
+	require.NoError(t, f.repos.Databases.Create(ctx, db))
+	require.NoError(t, f.repos.ResizeEvents.Record(ctx, &database.ResizeEvent{DatabaseID: db.ID, FromBytes: 1, ToBytes: 2}))
+	require.NoError(t, f.repos.TierChanges.Record(ctx, &database.TierChange{DatabaseID: db.ID, FromTier: "small", ToTier: "large", Status: database.TierChangeApplied}))
+	stats := f.repos.Databases.(database.StatsReader)
+
+	f.now = database.HistoryMonth(time.Now()).AddDate(0, 1, 0)
+	assert.Zero(t, f.rotator(1).RunOnce(ctx), "last month is within the retention")
+	history, err := stats.StatusHistory(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	f.now = database.HistoryMonth(time.Now()).AddDate(0, 2, 0)
+	assert.Equal(t, 4, f.rotator(1, retention.WithArchive()).RunOnce(ctx),
+		"one partition per history table written to: status history, resize events, tier changes and revisions")
+
+	history, err = stats.StatusHistory(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+	events, err := f.repos.ResizeEvents.ListByDatabase(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Empty(t, events)
+	changes, err := f.repos.TierChanges.ListByDatabase(ctx, db.ID)
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+	revisions, err := f.repos.Revisions.List(ctx, revision.KindDatabase, db.ID)
+	require.NoError(t, err)
+	assert.Empty(t, revisions)
+	_, err = f.repos.Databases.GetByID(ctx, db.ID)
+	assert.NoError(t, err, "the database itself is kept")
+}
+
+func TestHistoryRotator_RevisionNumbersContinuePastRetention(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	db := &database.Database{Name: "orders", OwnerTeamID: f.team.ID, Namespace: "default", DataClassification: "internal"}
+	require.NoError(t, f.repos.Databases.Create(ctx, db))
+	hold := true
+	_, err := f.repos.Databases.Update(ctx, db.ID, database.UpdateFields{LegalHold: &hold})
+	require.NoError(t, err)
+
+	f.now = database.HistoryMonth(time.Now()).AddDate(0, 2, 0)
+	f.rotator(1).RunOnce(ctx)
+
+	hold = false
+	_, err = f.repos.Databases.Update(ctx, db.ID, database.UpdateFields{LegalHold: &hold})
+	require.NoError(t, err)
+	revisions, err := f.repos.Revisions.List(ctx, revision.KindDatabase, db.ID)
+	require.NoError(t, err)
+	require.Len(t, revisions, 1)
+	assert.Equal(t, 3, revisions[0].Number)
+}
@@ -0,0 +1,155 @@
+package retention_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/audit"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/jobs"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/retention"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+const retentionPeriod = 30 * 24 * time.Hour
+
+type auditRecorder struct{ events []audit.Event }
+
+func (a *auditRecorder) Record(_ context.Context, e audit.Event) error {
+	a.events = append(a.events, e)
+	return nil
+}
+
+type fixture struct {
+	repos *fake.Repositories
+	team  *team.Team
+	now   time.Time
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	repos := fake.NewRepositories()
+	owner := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(context.Background(), owner))
+	return &fixture{repos: repos, team: owner, now: time.Now()}
+}
+
+func (f *fixture) purger(opts ...retention.Option) *retention.Purger {
+	opts = append(opts, retention.WithClock(func() time.Time { return f.now }))
+	return retention.New(f.repos.Purges, time.Hour, retentionPeriod, opts...)
+}
+
+// deleted creates a database and soft-deletes it now, optionally under
+// legal hold.
+func (f *fixture) deleted(t *testing.T, name string, hold bool) *database.Database {
+	t.Helper()
+	ctx := context.Background()
+	db := &database.Database{Name: name, OwnerTeamID: f.team.ID, Namespace: "default", DataClassification: "internal"}
+	require.NoError(t, f.repos.Databases.Create(ctx, db))
+	if hold {
+		_, err := f.repos.Databases.Update(ctx, db.ID, database.UpdateFields{LegalHold: &hold})
+		require.NoError(t, err)
+	}
+	require.NoError(t, f.repos.Databases.SoftDelete(ctx, db.ID))
+	return db
+}
+
+func TestPurger_PurgesExpiredDatabases(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	expired := f.deleted(t, "orders", false)
+	require.NoError(t, f.repos.Jobs.Enqueue(ctx, &jobs.Job{Type: jobs.TypeProvision, DatabaseID: expired.ID}))
+	op := &operation.Operation{Type: operation.TypeCreate, DatabaseID: expired.ID, TeamID: f.team.ID}
+	require.NoError(t, f.repos.Operations.Create(ctx, op))
+	held := f.deleted(t, "ledger", true)
+	rec := &auditRecorder{}
+	p := f.purger(retention.WithAudit(rec))
+
+	assert.Zero(t, p.RunOnce(ctx), "nothing is purged within the retention")
+
+	f.now = f.now.Add(retentionPeriod + time.Minute)
+	assert.Equal(t, 1, p.RunOnce(ctx))
+
+	remaining, err := f.repos.Purges.ListDeletedBefore(ctx, f.now.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, remaining, 1)
+	assert.Equal(t, held.ID, remaining[0].ID, "a legal hold keeps it")
+
+	list, err := f.repos.Jobs.ListByDatabase(ctx, expired.ID)
+	require.NoError(t, err)
+	assert.Empty(t, list, "the purged database's jobs go with it")
+	_, err = f.repos.Operations.GetByID(ctx, op.ID)
+	assert.ErrorIs(t, err, operation.ErrOperationNotFound, "and so do its operations")
+
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, audit.ActorRetention, rec.events[0].Actor)
+	assert.Equal(t, "database.purge", rec.events[0].Action)
+	assert.Equal(t, expired.ID.String(), rec.events[0].DatabaseID)
+	assert.Equal(t, "checkout", rec.events[0].Team)
+	assert.Equal(t, "internal", rec.events[0].DataClassification)
+}
+
+func TestPurger_Report(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	expired := f.deleted(t, "orders", false)
+	held := f.deleted(t, "ledger", true)
+	p := f.purger()
+
+	report, err := p.Report(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, f.now.Add(time.Hour), report.NextRunAt)
+	assert.Equal(t, report.NextRunAt.Add(-retentionPeriod), report.Cutoff)
+	assert.Empty(t, report.Purgeable)
+	assert.Empty(t, report.Held)
+	assert.Nil(t, report.LastRunAt)
+
+	// Within an hour of its retention, a database is purged by the next run.
+	f.now = f.now.Add(retentionPeriod - 30*time.Minute)
+	assert.Zero(t, p.RunOnce(ctx))
+	report, err = p.Report(ctx)
+	require.NoError(t, err)
+	require.Len(t, report.Purgeable, 1)
+	assert.Equal(t, expired.ID, report.Purgeable[0].ID)
+	assert.Equal(t, "checkout", report.Purgeable[0].OwnerTeamName)
+	require.Len(t, report.Held, 1)
+	assert.Equal(t, held.ID, report.Held[0].ID)
+
+	f.now = f.now.Add(time.Hour)
+	assert.Equal(t, 1, p.RunOnce(ctx))
+	report, err = p.Report(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, report.Purgeable)
+	assert.Len(t, report.Held, 1)
+	require.NotNil(t, report.LastRunAt)
+	assert.Equal(t, f.now, *report.LastRunAt)
+	assert.Equal(t, 1, report.LastPurged)
+	assert.Equal(t, f.now.Add(time.Hour), report.NextRunAt)
+}
+
+func TestPurgeRepository_Purge(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t)
+	held := f.deleted(t, "ledger", true)
+	active := &database.Database{Name: "orders", OwnerTeamID: f.team.ID, Namespace: "default"}
+	require.NoError(t, f.repos.Databases.Create(ctx, active))
+	later := time.Now().Add(time.Hour)
+
+	assert.ErrorIs(t, f.repos.Purges.Purge(ctx, held.ID, later), database.ErrNotFound, "a legal hold keeps it")
+	assert.ErrorIs(t, f.repos.Purges.Purge(ctx, active.ID, later), database.ErrNotFound, "an active database is never purged")
+	assert.ErrorIs(t, f.repos.Purges.Purge(ctx, uuid.New(), later), database.ErrNotFound)
+
+	expired := f.deleted(t, "carts", false)
+	assert.ErrorIs(t, f.repos.Purges.Purge(ctx, expired.ID, time.Now().Add(-time.Hour)), database.ErrNotFound,
+		"a database deleted after the cutoff is kept")
+	require.NoError(t, f.repos.Purges.Purge(ctx, expired.ID, later))
+	_, err := f.repos.Databases.GetByID(ctx, active.ID)
+	assert.NoError(t, err)
+}
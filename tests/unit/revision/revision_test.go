@@ -0,0 +1,51 @@
+package revision_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/daap14/daap/internal/revision"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	prev := map[string]any{
+		"name":       "orders",
+		"purpose":    "orders",
+		"port":       float64(5432),
+		"conditions": []any{},
+		"host":       "orders.db",
+	}
+	next := map[string]any{
+		"name":       "orders",
+		"purpose":    "billing",
+		"port":       float64(5432),
+		"conditions": []any{map[string]any{"type": "needsReview"}},
+		"tier_id":    "b1",
+	}
+
+	assert.Equal(t, []revision.Change{
+		{Field: "conditions", From: []any{}, To: []any{map[string]any{"type": "needsReview"}}},
+		{Field: "host", From: "orders.db"},
+		{Field: "purpose", From: "orders", To: "billing"},
+		{Field: "tier_id", To: "b1"},
+	}, revision.Diff(prev, next))
+}
+
+func TestDiff_FirstRevision(t *testing.T) {
+	t.Parallel()
+	changes := revision.Diff(nil, map[string]any{"name": "orders", "host": nil})
+
+	assert.Equal(t, []revision.Change{
+		{Field: "host"},
+		{Field: "name", To: "orders"},
+	}, changes)
+}
+
+func TestDiff_Unchanged(t *testing.T) {
+	t.Parallel()
+	snapshot := map[string]any{"name": "orders", "labels": map[string]any{"app": "shop"}}
+
+	assert.Empty(t, revision.Diff(snapshot, map[string]any{"name": "orders", "labels": map[string]any{"app": "shop"}}))
+}
@@ -0,0 +1,433 @@
+package rollout_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/notify"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type recordingNotifier struct {
+	mu            sync.Mutex
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Notify(_ context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+type fixture struct {
+	repos    *fake.Repositories
+	provider *fake.Provider
+	notifier *recordingNotifier
+	tier     *tier.Tier
+	oldBP    *blueprint.Blueprint
+	newBP    *blueprint.Blueprint
+	dbs      []*database.Database
+}
+
+// setup seeds a tier on blueprint v1 with n ready databases, then points the
+// tier at blueprint v2.
+func setup(t *testing.T, n int) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &fixture{repos: repos, provider: fake.NewProvider(), notifier: &recordingNotifier{}}
+
+	f.oldBP = &blueprint.Blueprint{Name: "cnpg-v1", Provider: "cnpg", Manifests: "kind: Cluster # v1"}
+	require.NoError(t, repos.Blueprints.Create(ctx, f.oldBP))
+	f.newBP = &blueprint.Blueprint{Name: "cnpg-v2", Provider: "cnpg", Manifests: "kind: Cluster # v2"}
+	require.NoError(t, repos.Blueprints.Create(ctx, f.newBP))
+
+	f.tier = &tier.Tier{Name: "standard", BlueprintID: &f.oldBP.ID}
+	require.NoError(t, repos.Tiers.Create(ctx, f.tier))
+
+	tm := &team.Team{Name: "checkout", Role: "product"}
+	require.NoError(t, repos.Teams.Create(ctx, tm))
+	for i := range n {
+		db := &database.Database{Name: fmt.Sprintf("db%d", i), OwnerTeamID: tm.ID, TierID: &f.tier.ID, Namespace: "db"}
+		require.NoError(t, repos.Databases.Create(ctx, db))
+		_, err := repos.Databases.UpdateStatus(ctx, db.ID, database.StatusUpdate{Status: "ready"})
+		require.NoError(t, err)
+		f.dbs = append(f.dbs, db)
+	}
+
+	updated, err := repos.Tiers.Update(ctx, f.tier.ID, tier.UpdateFields{BlueprintID: &f.newBP.ID})
+	require.NoError(t, err)
+	f.tier = updated
+	return f
+}
+
+func (f *fixture) controller(opts ...rollout.Option) *rollout.Controller {
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", f.provider)
+	opts = append([]rollout.Option{
+		rollout.WithCanarySize(1),
+		rollout.WithBatchSize(2),
+		rollout.WithNotifier(f.notifier),
+	}, opts...)
+	return rollout.New(f.repos.Rollouts, f.repos.Databases, f.repos.Tiers, f.repos.Blueprints, registry, time.Minute, opts...)
+}
+
+func (f *fixture) begin(t *testing.T, c *rollout.Controller) *rollout.Rollout {
+	t.Helper()
+	r, err := c.Begin(context.Background(), f.tier, f.oldBP.ID)
+	require.NoError(t, err)
+	return r
+}
+
+func (f *fixture) get(t *testing.T, id uuid.UUID) *rollout.Rollout {
+	t.Helper()
+	r, err := f.repos.Rollouts.GetByID(context.Background(), id)
+	require.NoError(t, err)
+	return r
+}
+
+func (f *fixture) targets(t *testing.T, r *rollout.Rollout) map[string]rollout.Target {
+	t.Helper()
+	targets, err := f.repos.Rollouts.ListTargets(context.Background(), r.ID)
+	require.NoError(t, err)
+	byName := make(map[string]rollout.Target, len(targets))
+	for _, tg := range targets {
+		byName[tg.DatabaseName] = tg
+	}
+	return byName
+}
+
+func (f *fixture) healthy(names ...string) {
+	for _, db := range f.dbs {
+		for _, name := range names {
+			if db.Name == name {
+				f.provider.SetHealth(db.ID, provider.HealthResult{Status: "ready"})
+			}
+		}
+	}
+}
+
+func (f *fixture) appliedNames(manifests string) []string {
+	var names []string
+	for _, call := range f.provider.ApplyCalls() {
+		if call.Manifests == manifests {
+			names = append(names, call.Database.Name)
+		}
+	}
+	return names
+}
+
+func TestBegin_BatchesOldestFirst(t *testing.T) {
+	f := setup(t, 4)
+	r := f.begin(t, f.controller())
+
+	assert.Equal(t, rollout.StatusInProgress, r.Status)
+	assert.Equal(t, 3, r.BatchCount)
+	assert.Equal(t, "cnpg-v1", r.FromBlueprintName)
+	assert.Equal(t, "cnpg-v2", r.ToBlueprintName)
+
+	targets := f.targets(t, r)
+	assert.Equal(t, 0, targets["db0"].Batch)
+	assert.Equal(t, 1, targets["db1"].Batch)
+	assert.Equal(t, 1, targets["db2"].Batch)
+	assert.Equal(t, 2, targets["db3"].Batch)
+	for _, tg := range targets {
+		assert.Equal(t, rollout.TargetPending, tg.Status)
+	}
+}
+
+func TestBegin_RejectsSecondActiveRollout(t *testing.T) {
+	f := setup(t, 1)
+	c := f.controller()
+	f.begin(t, c)
+
+	_, err := c.Begin(context.Background(), f.tier, f.oldBP.ID)
+	assert.ErrorIs(t, err, rollout.ErrRolloutActive)
+}
+
+func TestBegin_NoDatabasesCompletes(t *testing.T) {
+	f := setup(t, 0)
+	r := f.begin(t, f.controller())
+
+	assert.Equal(t, rollout.StatusCompleted, r.Status)
+	assert.Equal(t, 0, r.BatchCount)
+}
+
+func TestRunOnce_CanaryThenBatches(t *testing.T) {
+	f := setup(t, 3)
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	// Pass 1 applies only the canary.
+	c.RunOnce(ctx)
+	assert.Equal(t, []string{"db0"}, f.appliedNames(f.newBP.Manifests))
+
+	// The canary is not healthy yet, so the batch does not advance.
+	c.RunOnce(ctx)
+	assert.Equal(t, 0, f.get(t, r.ID).CurrentBatch)
+	assert.Len(t, f.appliedNames(f.newBP.Manifests), 1)
+
+	f.healthy("db0")
+	c.RunOnce(ctx)
+	assert.Equal(t, 1, f.get(t, r.ID).CurrentBatch)
+
+	c.RunOnce(ctx)
+	assert.ElementsMatch(t, []string{"db0", "db1", "db2"}, f.appliedNames(f.newBP.Manifests))
+
+	f.healthy("db1", "db2")
+	c.RunOnce(ctx)
+	c.RunOnce(ctx)
+
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.StatusCompleted, got.Status)
+	for _, tg := range f.targets(t, got) {
+		assert.Equal(t, rollout.TargetHealthy, tg.Status)
+		assert.NotNil(t, tg.AppliedAt)
+	}
+	assert.Empty(t, f.notifier.notifications)
+}
+
+func TestRunOnce_ApplyFailurePausesAndNotifies(t *testing.T) {
+	f := setup(t, 2)
+	f.provider.ApplyFn = func(_ context.Context, _ provider.ProviderDatabase, _ string) error {
+		return errors.New("admission webhook denied")
+	}
+	c := f.controller()
+	r := f.begin(t, c)
+
+	c.RunOnce(context.Background())
+
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.StatusPaused, got.Status)
+	assert.Contains(t, got.Error, "admission webhook denied")
+	assert.Equal(t, rollout.TargetFailed, f.targets(t, got)["db0"].Status)
+	assert.Equal(t, rollout.TargetPending, f.targets(t, got)["db1"].Status)
+
+	require.Len(t, f.notifier.notifications, 1)
+	assert.Equal(t, "RolloutPaused", f.notifier.notifications[0].Event)
+	assert.Equal(t, "db0", f.notifier.notifications[0].Database)
+}
+
+func TestRunOnce_UnsignedBlueprintPauses(t *testing.T) {
+	f := setup(t, 2)
+	c := f.controller(rollout.WithSigner(blueprint.NewSigner([]byte("s3cret"))))
+	r := f.begin(t, c)
+
+	c.RunOnce(context.Background())
+
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.StatusPaused, got.Status)
+	assert.Contains(t, got.Error, "blueprint cnpg-v2: blueprint is not signed")
+	assert.Empty(t, f.provider.ApplyCalls(), "nothing is applied from the unsigned blueprint")
+}
+
+func TestRunOnce_UnhealthyCanaryPauses(t *testing.T) {
+	f := setup(t, 2)
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	c.RunOnce(ctx)
+	f.provider.SetHealth(f.dbs[0].ID, provider.HealthResult{Status: "error"})
+	c.RunOnce(ctx)
+
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.StatusPaused, got.Status)
+	assert.Equal(t, rollout.TargetFailed, f.targets(t, got)["db0"].Status)
+	assert.Equal(t, []string{"db0"}, f.appliedNames(f.newBP.Manifests))
+}
+
+func TestRunOnce_VerifyTimeoutPauses(t *testing.T) {
+	f := setup(t, 1)
+	now := time.Now()
+	c := f.controller(rollout.WithVerifyTimeout(time.Minute), rollout.WithClock(func() time.Time { return now }))
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	c.RunOnce(ctx)
+	c.RunOnce(ctx)
+	assert.Equal(t, rollout.StatusInProgress, f.get(t, r.ID).Status)
+
+	now = now.Add(2 * time.Minute)
+	c.RunOnce(ctx)
+
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.StatusPaused, got.Status)
+	assert.Contains(t, got.Error, "not healthy")
+}
+
+func TestResume_RetriesFailedTargets(t *testing.T) {
+	f := setup(t, 1)
+	fail := true
+	f.provider.ApplyFn = func(_ context.Context, _ provider.ProviderDatabase, _ string) error {
+		if fail {
+			return errors.New("boom")
+		}
+		return nil
+	}
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	c.RunOnce(ctx)
+	require.Equal(t, rollout.StatusPaused, f.get(t, r.ID).Status)
+
+	fail = false
+	resumed, err := c.Resume(ctx, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rollout.StatusInProgress, resumed.Status)
+	assert.Empty(t, resumed.Error)
+	assert.Equal(t, rollout.TargetPending, f.targets(t, resumed)["db0"].Status)
+
+	f.healthy("db0")
+	c.RunOnce(ctx)
+	c.RunOnce(ctx)
+	c.RunOnce(ctx)
+	assert.Equal(t, rollout.StatusCompleted, f.get(t, r.ID).Status)
+}
+
+func TestPauseResume_InvalidTransitions(t *testing.T) {
+	f := setup(t, 1)
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	_, err := c.Resume(ctx, r.ID)
+	assert.ErrorIs(t, err, rollout.ErrInvalidTransition)
+
+	paused, err := c.Pause(ctx, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rollout.StatusPaused, paused.Status)
+
+	_, err = c.Pause(ctx, r.ID)
+	assert.ErrorIs(t, err, rollout.ErrInvalidTransition)
+
+	// A paused rollout applies nothing.
+	c.RunOnce(ctx)
+	assert.Empty(t, f.provider.ApplyCalls())
+}
+
+func TestRollback_RevertsTierAndTouchedDatabases(t *testing.T) {
+	f := setup(t, 3)
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	c.RunOnce(ctx)
+	f.healthy("db0")
+	c.RunOnce(ctx)
+	c.RunOnce(ctx)
+
+	rb, err := c.Rollback(ctx, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rollout.StatusRollingBack, rb.Status)
+
+	tr, err := f.repos.Tiers.GetByID(ctx, f.tier.ID)
+	require.NoError(t, err)
+	assert.Equal(t, f.oldBP.ID, *tr.BlueprintID)
+
+	c.RunOnce(ctx)
+
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.StatusRolledBack, got.Status)
+	assert.ElementsMatch(t, []string{"db0", "db1", "db2"}, f.appliedNames(f.oldBP.Manifests))
+	for _, tg := range f.targets(t, got) {
+		assert.Equal(t, rollout.TargetRolledBack, tg.Status)
+	}
+
+	_, err = c.Rollback(ctx, r.ID)
+	assert.ErrorIs(t, err, rollout.ErrInvalidTransition)
+}
+
+func TestRollback_LeavesTierOnNewerBlueprint(t *testing.T) {
+	f := setup(t, 0)
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	other := &blueprint.Blueprint{Name: "cnpg-v3", Provider: "cnpg", Manifests: "kind: Cluster # v3"}
+	require.NoError(t, f.repos.Blueprints.Create(ctx, other))
+	_, err := f.repos.Tiers.Update(ctx, f.tier.ID, tier.UpdateFields{BlueprintID: &other.ID})
+	require.NoError(t, err)
+
+	_, err = c.Rollback(ctx, r.ID)
+	require.NoError(t, err)
+
+	tr, err := f.repos.Tiers.GetByID(ctx, f.tier.ID)
+	require.NoError(t, err)
+	assert.Equal(t, other.ID, *tr.BlueprintID)
+}
+
+func TestRunOnce_SkipsDeletedDatabases(t *testing.T) {
+	f := setup(t, 2)
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	require.NoError(t, f.repos.Databases.SoftDelete(ctx, f.dbs[0].ID))
+	c.RunOnce(ctx)
+
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.TargetSkipped, f.targets(t, got)["db0"].Status)
+	assert.Empty(t, f.provider.ApplyCalls())
+
+	c.RunOnce(ctx)
+	assert.Equal(t, 1, f.get(t, r.ID).CurrentBatch)
+}
+
+func TestRunOnce_ChangeFreezeDefersApply(t *testing.T) {
+	f := setup(t, 2)
+	c := f.controller(rollout.WithFreezes(freeze.NewChecker(f.repos.Freezes)))
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	window := &freeze.Window{Reason: "holidays", StartsAt: time.Now().Add(-time.Hour), EndsAt: time.Now().Add(time.Hour)}
+	require.NoError(t, f.repos.Freezes.Create(ctx, window))
+
+	c.RunOnce(ctx)
+	assert.Empty(t, f.provider.ApplyCalls())
+	got := f.get(t, r.ID)
+	assert.Equal(t, rollout.StatusInProgress, got.Status)
+	assert.Equal(t, rollout.TargetPending, f.targets(t, got)["db0"].Status)
+
+	require.NoError(t, f.repos.Freezes.Delete(ctx, window.ID))
+	c.RunOnce(ctx)
+	assert.Equal(t, []string{"db0"}, f.appliedNames(f.newBP.Manifests))
+}
+
+func TestRunOnce_ReconciliationPauseDefersApply(t *testing.T) {
+	f := setup(t, 2)
+	c := f.controller()
+	r := f.begin(t, c)
+	ctx := context.Background()
+
+	pause := &database.ReconciliationPause{By: "alice", Until: time.Now().Add(time.Hour)}
+	_, err := f.repos.Databases.Update(ctx, f.dbs[0].ID, database.UpdateFields{ReconciliationPause: pause})
+	require.NoError(t, err)
+
+	c.RunOnce(ctx)
+	assert.Empty(t, f.provider.ApplyCalls())
+	assert.Equal(t, rollout.TargetPending, f.targets(t, f.get(t, r.ID))["db0"].Status)
+
+	_, err = f.repos.Databases.Update(ctx, f.dbs[0].ID, database.UpdateFields{ResumeReconciliation: true})
+	require.NoError(t, err)
+	c.RunOnce(ctx)
+	assert.Equal(t, []string{"db0"}, f.appliedNames(f.newBP.Manifests))
+}
@@ -0,0 +1,101 @@
+package secrets_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/secrets"
+)
+
+type fakeReader struct {
+	data map[string][]byte
+	err  error
+}
+
+func (f *fakeReader) SecretData(_ context.Context, _, _ string) (map[string][]byte, error) {
+	return f.data, f.err
+}
+
+func TestReferences(t *testing.T) {
+	manifests := `password: {{ .Secrets.dbPassword }}
+pull: {{ .Secrets.registry_token }}
+again: {{.Secrets.dbPassword}}
+name: {{ .Name }}`
+
+	assert.Equal(t, []string{"dbPassword", "registry_token"}, secrets.References(manifests))
+	assert.Empty(t, secrets.References("name: {{ .Name }}"))
+}
+
+func TestResolve(t *testing.T) {
+	store := secrets.NewKubernetesStore(&fakeReader{data: map[string][]byte{"a": []byte("1"), "b": []byte("2")}}, "daap-system", "blueprint-secrets")
+
+	values, err := secrets.Resolve(context.Background(), store, "{{ .Secrets.a }} {{ .Secrets.b }}")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, values)
+
+	_, err = secrets.Resolve(context.Background(), store, "{{ .Secrets.a }} {{ .Secrets.c }} {{ .Secrets.d }}")
+	require.ErrorIs(t, err, secrets.ErrNotFound)
+	assert.Contains(t, err.Error(), "c, d")
+	assert.NotContains(t, err.Error(), "1")
+}
+
+func TestResolve_NoReferences(t *testing.T) {
+	values, err := secrets.Resolve(context.Background(), nil, "name: {{ .Name }}")
+	require.NoError(t, err)
+	assert.Nil(t, values)
+}
+
+func TestResolve_NoStore(t *testing.T) {
+	_, err := secrets.Resolve(context.Background(), nil, "{{ .Secrets.a }}")
+	assert.ErrorIs(t, err, secrets.ErrNoStore)
+}
+
+func TestKubernetesStore_ReadError(t *testing.T) {
+	readErr := errors.New("forbidden")
+	store := secrets.NewKubernetesStore(&fakeReader{err: readErr}, "daap-system", "blueprint-secrets")
+
+	_, err := store.Lookup(context.Background(), "a")
+	assert.ErrorIs(t, err, readErr)
+}
+
+func TestPlaceholders(t *testing.T) {
+	assert.Equal(t, map[string]string{"a": "<secret:a>"}, secrets.Placeholders("{{ .Secrets.a }}"))
+	assert.Nil(t, secrets.Placeholders("name: {{ .Name }}"))
+}
+
+func TestVaultStore(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/daap/blueprints", r.URL.Path)
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"data":{"data":{"dbPassword":"hunter2","port":5432},"metadata":{"version":3}}}`))
+	}))
+	defer srv.Close()
+
+	store := secrets.NewVaultStore(srv.URL+"/", "root", "/secret/data/daap/blueprints", time.Second)
+
+	v, err := store.Lookup(context.Background(), "dbPassword")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", v)
+
+	v, err = store.Lookup(context.Background(), "port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", v)
+
+	_, err = store.Lookup(context.Background(), "missing")
+	assert.ErrorIs(t, err, secrets.ErrNotFound)
+
+	denied := secrets.NewVaultStore(srv.URL, "wrong", "secret/data/daap/blueprints", time.Second)
+	_, err = denied.Lookup(context.Background(), "dbPassword")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
@@ -0,0 +1,1042 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/auth"
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/freeze"
+	"github.com/daap14/daap/internal/operation"
+	"github.com/daap14/daap/internal/organization"
+	"github.com/daap14/daap/internal/revision"
+	"github.com/daap14/daap/internal/rollout"
+	"github.com/daap14/daap/internal/store/memory"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+)
+
+func seedTeam(t *testing.T, db *memory.DB, name, role string) *team.Team {
+	t.Helper()
+	tm := &team.Team{Name: name, Role: role}
+	require.NoError(t, db.Teams().Create(context.Background(), tm))
+	return tm
+}
+
+func seedTier(t *testing.T, db *memory.DB, name string) *tier.Tier {
+	t.Helper()
+	ctx := context.Background()
+	bp := &blueprint.Blueprint{Name: name + "-bp", Provider: "cnpg", Manifests: "kind: Cluster"}
+	require.NoError(t, db.Blueprints().Create(ctx, bp))
+	tr := &tier.Tier{Name: name, BlueprintID: &bp.ID, DestructionStrategy: "hard_delete"}
+	require.NoError(t, db.Tiers().Create(ctx, tr))
+	return tr
+}
+
+// --- Databases ---
+
+func TestMemoryDatabases_CreateAndGet(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	tr := seedTier(t, db, "standard")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	assert.NotEqual(t, "", d.ID.String())
+	assert.Equal(t, "daap-orders", d.ClusterName)
+	assert.Equal(t, "daap-orders-pooler", d.PoolerName)
+	assert.Equal(t, "provisioning", d.Status)
+
+	got, err := db.Databases().GetByID(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "backend", got.OwnerTeamName)
+	assert.Equal(t, "standard", got.TierName)
+}
+
+func TestMemoryDatabases_GetByName(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	old := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, old))
+	require.NoError(t, db.Databases().SoftDelete(ctx, old.ID))
+	_, err := db.Databases().GetByName(ctx, "orders")
+	assert.ErrorIs(t, err, database.ErrNotFound, "deleted databases are not found by name")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	got, err := db.Databases().GetByName(ctx, "orders")
+	require.NoError(t, err)
+	assert.Equal(t, d.ID, got.ID)
+	assert.Equal(t, "backend", got.OwnerTeamName)
+
+	_, err = db.Databases().GetByName(ctx, "order")
+	assert.ErrorIs(t, err, database.ErrNotFound, "names match exactly")
+}
+
+func TestMemoryDatabases_CreateDuplicateName(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	require.NoError(t, db.Databases().Create(ctx, &database.Database{Name: "orders", OwnerTeamID: tm.ID}))
+	err := db.Databases().Create(ctx, &database.Database{Name: "orders", OwnerTeamID: tm.ID})
+	assert.ErrorIs(t, err, database.ErrDuplicateName)
+}
+
+func TestMemoryDatabases_CreateInvalidReferences(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	err := db.Databases().Create(ctx, &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tm.ID})
+	assert.ErrorIs(t, err, database.ErrInvalidTier)
+
+	err = db.Databases().Create(ctx, &database.Database{Name: "orders", OwnerTeamID: uuid.New()})
+	assert.ErrorIs(t, err, database.ErrInvalidOwnerTeam)
+}
+
+func TestMemoryDatabases_SoftDeleteFreesName(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	require.NoError(t, db.Databases().SoftDelete(ctx, d.ID))
+
+	_, err := db.Databases().GetByID(ctx, d.ID)
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	assert.ErrorIs(t, db.Databases().SoftDelete(ctx, d.ID), database.ErrNotFound)
+
+	require.NoError(t, db.Databases().Create(ctx, &database.Database{Name: "orders", OwnerTeamID: tm.ID}))
+}
+
+func TestMemoryDatabases_ListFiltersAndPaginates(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	a := seedTeam(t, db, "alpha", "product")
+	b := seedTeam(t, db, "beta", "product")
+
+	for _, name := range []string{"one", "two", "three"} {
+		require.NoError(t, db.Databases().Create(ctx, &database.Database{Name: name, OwnerTeamID: a.ID}))
+	}
+	require.NoError(t, db.Databases().Create(ctx, &database.Database{Name: "other", OwnerTeamID: b.ID}))
+
+	result, err := db.Databases().List(ctx, database.ListFilter{OwnerTeamID: &a.ID, Page: 1, Limit: 2})
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Total)
+	require.Len(t, result.Databases, 2)
+	assert.Equal(t, "three", result.Databases[0].Name)
+	assert.Equal(t, "two", result.Databases[1].Name)
+
+	name := "THR"
+	result, err = db.Databases().List(ctx, database.ListFilter{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Total)
+	assert.Equal(t, 20, result.Limit)
+}
+
+func TestMemoryDatabases_ListFiltersByTier(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "alpha", "product")
+	standard := seedTier(t, db, "standard")
+	large := seedTier(t, db, "large")
+
+	require.NoError(t, db.Databases().Create(ctx, &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &standard.ID}))
+	require.NoError(t, db.Databases().Create(ctx, &database.Database{Name: "events", OwnerTeamID: tm.ID, TierID: &large.ID}))
+
+	result, err := db.Databases().List(ctx, database.ListFilter{TierID: &standard.ID})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Total)
+	assert.Equal(t, "orders", result.Databases[0].Name)
+}
+
+func TestMemoryDatabases_UpdateStatus(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	host, port := "daap-orders-pooler.default.svc.cluster.local", 5432
+	got, err := db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{Status: "ready", Host: &host, Port: &port})
+	require.NoError(t, err)
+	assert.Equal(t, "ready", got.Status)
+	require.NotNil(t, got.Host)
+	assert.Equal(t, host, *got.Host)
+}
+
+func TestMemoryDatabases_Attribution(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, CreatedBy: "alice"}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	assert.Equal(t, "alice", d.UpdatedBy, "the creator is the first updater")
+
+	purpose := "billing"
+	got, err := db.Databases().Update(ctx, d.ID, database.UpdateFields{Purpose: &purpose, UpdatedBy: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.CreatedBy)
+	assert.Equal(t, "bob", got.UpdatedBy)
+
+	got, err = db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{Status: "ready", UpdatedBy: "system:reconciler"})
+	require.NoError(t, err)
+	assert.Equal(t, "system:reconciler", got.UpdatedBy)
+
+	got, err = db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	assert.Equal(t, "system:reconciler", got.UpdatedBy, "an update without an actor keeps the last one")
+}
+
+func TestMemoryDatabases_GenerationTracking(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	backend := seedTeam(t, db, "backend", "product")
+	frontend := seedTeam(t, db, "frontend", "product")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: backend.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	assert.Equal(t, int64(1), d.Generation)
+	assert.Equal(t, int64(0), d.ObservedGeneration)
+
+	// Purpose is not spec-affecting.
+	purpose := "billing"
+	got, err := db.Databases().Update(ctx, d.ID, database.UpdateFields{Purpose: &purpose})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.Generation)
+
+	// Re-setting the same owner is a no-op.
+	got, err = db.Databases().Update(ctx, d.ID, database.UpdateFields{OwnerTeamID: &backend.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), got.Generation)
+
+	got, err = db.Databases().Update(ctx, d.ID, database.UpdateFields{OwnerTeamID: &frontend.ID})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.Generation)
+
+	observed := got.Generation
+	got, err = db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{Status: "ready", ObservedGeneration: &observed})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), got.ObservedGeneration)
+	assert.Equal(t, int64(2), got.Generation)
+}
+
+func TestMemoryDatabases_StatusReason(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	owner := seedTeam(t, db, "backend", "product")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: owner.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	got, err := db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{
+		Status: "error",
+		Reason: database.ReasonProvisioningTimeout,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, got.StatusReason)
+	assert.Equal(t, database.ReasonProvisioningTimeout, *got.StatusReason)
+
+	// A later status update without a reason clears it.
+	got, err = db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	assert.Nil(t, got.StatusReason)
+}
+
+func TestMemoryDatabases_Instances(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	owner := seedTeam(t, db, "backend", "product")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: owner.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	assert.Nil(t, d.Instances)
+
+	lag := time.Second
+	instances := &database.Instances{Total: 3, Ready: 2, Primary: "daap-orders-1", ReplicationLag: &lag}
+	_, err := db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{Status: "ready", Instances: instances})
+	require.NoError(t, err)
+	lag = time.Hour // the store keeps its own copy
+
+	// Updates without instances leave them as they were.
+	got, err := db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{Status: "error"})
+	require.NoError(t, err)
+	require.NotNil(t, got.Instances)
+	assert.Equal(t, 2, got.Instances.Ready)
+	assert.Equal(t, "daap-orders-1", got.Instances.Primary)
+	require.NotNil(t, got.Instances.ReplicationLag)
+	assert.Equal(t, time.Second, *got.Instances.ReplicationLag)
+}
+
+func TestMemorySpecs_PutReplacesAndGet(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	owner := seedTeam(t, db, "backend", "product")
+	tr := seedTier(t, db, "standard")
+	bp, err := db.Blueprints().GetByID(ctx, *tr.BlueprintID)
+	require.NoError(t, err)
+
+	_, err = db.Specs().Get(ctx, uuid.New())
+	assert.ErrorIs(t, err, database.ErrSpecNotFound)
+	assert.ErrorIs(t, db.Specs().Put(ctx, database.NewSpec(uuid.New(), tr, bp)), database.ErrNotFound)
+
+	d := &database.Database{Name: "orders", OwnerTeamID: owner.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	spec := database.NewSpec(d.ID, tr, bp)
+	require.NoError(t, db.Specs().Put(ctx, spec))
+	assert.False(t, spec.AppliedAt.IsZero())
+
+	tr.BackupEnabled = true
+	require.NoError(t, db.Specs().Put(ctx, database.NewSpec(d.ID, tr, bp)))
+	got, err := db.Specs().Get(ctx, d.ID)
+	require.NoError(t, err)
+	assert.True(t, got.BackupEnabled)
+	assert.Equal(t, "standard-bp", got.BlueprintName)
+}
+
+func TestMemoryDatabases_Stats(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	backend := seedTeam(t, db, "backend", "product")
+	frontend := seedTeam(t, db, "frontend", "product")
+	tr := seedTier(t, db, "standard")
+
+	ready := &database.Database{Name: "orders", OwnerTeamID: backend.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, ready))
+	_, err := db.Databases().UpdateStatus(ctx, ready.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+
+	pending := &database.Database{Name: "search", OwnerTeamID: frontend.ID}
+	require.NoError(t, db.Databases().Create(ctx, pending))
+
+	gone := &database.Database{Name: "legacy", OwnerTeamID: backend.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, gone))
+	_, err = db.Databases().UpdateStatus(ctx, gone.ID, database.StatusUpdate{Status: "ready"})
+	require.NoError(t, err)
+	require.NoError(t, db.Databases().SoftDelete(ctx, gone.ID))
+
+	stats, err := db.Stats().Stats(ctx, database.StatsFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, stats.Total)
+	assert.Equal(t, map[string]int{"ready": 1, "provisioning": 1}, stats.ByStatus)
+	assert.Equal(t, map[string]int{"standard": 1}, stats.ByTier)
+	assert.Equal(t, map[string]int{"backend": 1, "frontend": 1}, stats.ByTeam)
+	assert.Equal(t, 2, stats.Provisioning.Count, "deleted databases still count towards provisioning durations")
+
+	scoped, err := db.Stats().Stats(ctx, database.StatsFilter{OwnerTeamID: &frontend.ID})
+	require.NoError(t, err)
+	assert.Equal(t, 1, scoped.Total)
+	assert.Equal(t, 0, scoped.Provisioning.Count)
+}
+
+// --- Teams, tiers, blueprints ---
+
+func TestMemoryTeams_DeleteBlockedByUsers(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	require.NoError(t, db.Users().Create(ctx, &auth.User{Name: "alice", TeamID: &tm.ID, ApiKeyPrefix: "abc"}))
+	assert.ErrorIs(t, db.Teams().Delete(ctx, tm.ID), team.ErrTeamHasUsers)
+
+	err := db.Teams().Create(ctx, &team.Team{Name: "backend", Role: "product"})
+	assert.ErrorIs(t, err, team.ErrDuplicateTeamName)
+}
+
+func TestMemoryTeams_UpdatePropagatesMetadata(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	tr := seedTier(t, db, "standard")
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	updated, err := db.Teams().Update(ctx, tm.ID, team.UpdateFields{Labels: map[string]string{"cost-center": "cc-1"}})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cost-center": "cc-1"}, updated.Labels)
+	assert.Empty(t, updated.Annotations)
+
+	got, err := db.Databases().GetByID(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cost-center": "cc-1"}, got.OwnerTeamLabels)
+
+	_, err = db.Teams().Update(ctx, uuid.New(), team.UpdateFields{})
+	assert.ErrorIs(t, err, team.ErrTeamNotFound)
+}
+
+func TestMemoryOrganizations_DeleteBlockedByTeams(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	org := &organization.Organization{Name: "acme", MaxDatabases: 5}
+	require.NoError(t, db.Organizations().Create(ctx, org))
+	assert.ErrorIs(t, db.Organizations().Create(ctx, &organization.Organization{Name: "acme"}), organization.ErrDuplicateName)
+
+	tm := &team.Team{Name: "backend", Role: "product", OrganizationID: &org.ID}
+	require.NoError(t, db.Teams().Create(ctx, tm))
+	admin := &auth.User{Name: "acme-admin", OrganizationID: &org.ID, ApiKeyPrefix: "abc"}
+	require.NoError(t, db.Users().Create(ctx, admin))
+	assert.ErrorIs(t, db.Organizations().Delete(ctx, org.ID), organization.ErrHasTeams)
+
+	require.NoError(t, db.Teams().Delete(ctx, tm.ID))
+	require.NoError(t, db.Organizations().Delete(ctx, org.ID))
+	_, err := db.Users().GetByID(ctx, admin.ID)
+	assert.Error(t, err, "organization superadmins are deleted with their organization")
+	assert.ErrorIs(t, db.Organizations().Delete(ctx, org.ID), organization.ErrNotFound)
+}
+
+func TestMemoryTeams_UnknownOrganization(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	missing := uuid.New()
+
+	assert.Error(t, db.Teams().Create(ctx, &team.Team{Name: "backend", Role: "product", OrganizationID: &missing}))
+	assert.Error(t, db.Users().Create(ctx, &auth.User{Name: "admin", OrganizationID: &missing, ApiKeyPrefix: "abc"}))
+}
+
+func TestMemoryDatabases_DataClassification(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	tr := seedTier(t, db, "standard")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	assert.Equal(t, database.DefaultClassification, d.DataClassification)
+
+	restricted := database.ClassificationRestricted
+	updated, err := db.Databases().Update(ctx, d.ID, database.UpdateFields{DataClassification: &restricted})
+	require.NoError(t, err)
+	assert.Equal(t, restricted, updated.DataClassification)
+	assert.Equal(t, d.Generation, updated.Generation, "classification does not change the rendered spec")
+
+	got, err := db.Tiers().Update(ctx, tr.ID, tier.UpdateFields{DataClassifications: []string{restricted}})
+	require.NoError(t, err)
+	assert.True(t, got.AllowsClassification(restricted))
+	assert.False(t, got.AllowsClassification(database.ClassificationPublic))
+}
+
+func TestMemoryTiers_DeleteBlockedByActiveDatabases(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	tr := seedTier(t, db, "standard")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+	assert.ErrorIs(t, db.Tiers().Delete(ctx, tr.ID), tier.ErrTierHasDatabases)
+
+	require.NoError(t, db.Databases().SoftDelete(ctx, d.ID))
+	require.NoError(t, db.Tiers().Delete(ctx, tr.ID))
+}
+
+func TestMemoryTiers_UpdateNamespace(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tr := seedTier(t, db, "standard")
+
+	ns := "db-{{ .Team }}"
+	got, err := db.Tiers().Update(ctx, tr.ID, tier.UpdateFields{Namespace: &ns})
+	require.NoError(t, err)
+	assert.Equal(t, ns, got.Namespace)
+
+	resolved, err := got.ResolveNamespace(tier.NamespaceData{Team: "backend", Database: "orders"}, "default")
+	require.NoError(t, err)
+	assert.Equal(t, "db-backend", resolved)
+}
+
+func TestMemoryTiers_Attribution(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+
+	tr := &tier.Tier{Name: "standard", DestructionStrategy: "hard_delete", CreatedBy: "alice"}
+	require.NoError(t, db.Tiers().Create(ctx, tr))
+	assert.Equal(t, "alice", tr.UpdatedBy)
+
+	got, err := db.Tiers().Update(ctx, tr.ID, tier.UpdateFields{UpdatedBy: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.UpdatedBy, "recording who updated is not an update on its own")
+
+	description := "General purpose"
+	got, err = db.Tiers().Update(ctx, tr.ID, tier.UpdateFields{Description: &description, UpdatedBy: "bob"})
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.CreatedBy)
+	assert.Equal(t, "bob", got.UpdatedBy)
+}
+
+func TestMemoryTiers_UpdateStorageAutoscaling(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tr := seedTier(t, db, "standard")
+	assert.False(t, tr.StorageAutoscaling.Enabled)
+
+	policy := tier.StorageAutoscaling{Enabled: true, ThresholdPercent: 85, IncrementPercent: 50, MaxSize: "1Ti"}
+	got, err := db.Tiers().Update(ctx, tr.ID, tier.UpdateFields{StorageAutoscaling: &policy})
+	require.NoError(t, err)
+	assert.Equal(t, policy, got.StorageAutoscaling)
+
+	got, err = db.Tiers().GetByID(ctx, tr.ID)
+	require.NoError(t, err)
+	assert.Equal(t, policy, got.StorageAutoscaling)
+}
+
+// --- Revisions ---
+
+func TestMemoryRevisions_Database(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, Purpose: "orders", CreatedBy: "alice"}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	purpose := "billing"
+	_, err := db.Databases().Update(ctx, d.ID, database.UpdateFields{Purpose: &purpose, UpdatedBy: "bob"})
+	require.NoError(t, err)
+
+	lag := 2 * time.Second
+	_, err = db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{
+		Status: "provisioning", Instances: &database.Instances{ReplicationLag: &lag},
+	})
+	require.NoError(t, err)
+	revs, err := db.Revisions().List(ctx, revision.KindDatabase, d.ID)
+	require.NoError(t, err)
+	require.Len(t, revs, 3, "instances changed, the replication lag is left out")
+
+	_, err = db.Databases().UpdateStatus(ctx, d.ID, database.StatusUpdate{
+		Status: "provisioning", Instances: &database.Instances{ReplicationLag: &lag},
+	})
+	require.NoError(t, err)
+	require.NoError(t, db.Databases().SoftDelete(ctx, d.ID))
+
+	revs, err = db.Revisions().List(ctx, revision.KindDatabase, d.ID)
+	require.NoError(t, err)
+	require.Len(t, revs, 4, "an update that changes nothing is not a revision")
+
+	ops := make([]string, len(revs))
+	for i, rev := range revs {
+		ops[i] = rev.Operation
+		assert.Equal(t, i+1, rev.Number)
+	}
+	assert.Equal(t, []string{"create", "update", "update", "delete"}, ops)
+	assert.Equal(t, "alice", revs[0].ChangedBy)
+	assert.Equal(t, "bob", revs[1].ChangedBy)
+	assert.Equal(t, "orders", revs[0].Snapshot["purpose"])
+	assert.Equal(t, tm.ID.String(), revs[0].Snapshot["owner_team_id"])
+	assert.NotContains(t, revs[0].Snapshot, "owner_team_name")
+	assert.Equal(t, "deleted", revs[3].Snapshot["status"])
+
+	changes := revision.Diff(revs[0].Snapshot, revs[1].Snapshot)
+	assert.Equal(t, []revision.Change{
+		{Field: "purpose", From: "orders", To: "billing"},
+		{Field: "updated_by", From: "alice", To: "bob"},
+	}, changes)
+}
+
+func TestMemoryRevisions_TierOutlivesDelete(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tr := seedTier(t, db, "standard")
+
+	backup := true
+	_, err := db.Tiers().Update(ctx, tr.ID, tier.UpdateFields{BackupEnabled: &backup, UpdatedBy: "bob"})
+	require.NoError(t, err)
+	require.NoError(t, db.Tiers().Delete(ctx, tr.ID))
+
+	revs, err := db.Revisions().List(ctx, revision.KindTier, tr.ID)
+	require.NoError(t, err)
+	require.Len(t, revs, 3)
+	assert.Equal(t, "update", revs[1].Operation)
+	assert.Equal(t, true, revs[1].Snapshot["backup_enabled"])
+	assert.Equal(t, "delete", revs[2].Operation)
+	assert.Empty(t, revs[2].ChangedBy)
+
+	revs, err = db.Revisions().List(ctx, revision.KindDatabase, tr.ID)
+	require.NoError(t, err)
+	assert.Empty(t, revs, "revisions are kept per kind")
+}
+
+// --- Resize events ---
+
+func TestMemoryResizeEvents_RecordAndList(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	first := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, first))
+	second := &database.Database{Name: "payments", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, second))
+
+	events := db.ResizeEvents()
+	e1 := &database.ResizeEvent{DatabaseID: first.ID, FromBytes: 10, ToBytes: 12, UsedBytes: 9}
+	require.NoError(t, events.Record(ctx, e1))
+	require.NoError(t, events.Record(ctx, &database.ResizeEvent{DatabaseID: second.ID, FromBytes: 1, ToBytes: 2, UsedBytes: 1}))
+	e2 := &database.ResizeEvent{DatabaseID: first.ID, FromBytes: 12, ToBytes: 15, UsedBytes: 11}
+	require.NoError(t, events.Record(ctx, e2))
+	assert.NotZero(t, e1.ID)
+	assert.False(t, e1.CreatedAt.IsZero())
+
+	got, err := events.ListByDatabase(ctx, first.ID)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	assert.Equal(t, *e1, got[0])
+	assert.Equal(t, *e2, got[1])
+
+	assert.ErrorIs(t, events.Record(ctx, &database.ResizeEvent{DatabaseID: uuid.New()}), database.ErrNotFound)
+}
+
+func TestMemoryDatabases_UpdateTier(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	standard := seedTier(t, db, "standard")
+	large := seedTier(t, db, "large")
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &standard.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	got, err := db.Databases().Update(ctx, d.ID, database.UpdateFields{TierID: &large.ID})
+	require.NoError(t, err)
+	assert.Equal(t, large.ID, *got.TierID)
+	assert.Equal(t, "large", got.TierName)
+	assert.Equal(t, int64(2), got.Generation)
+
+	unknown := uuid.New()
+	_, err = db.Databases().Update(ctx, d.ID, database.UpdateFields{TierID: &unknown})
+	assert.ErrorIs(t, err, database.ErrInvalidTier)
+}
+
+// --- Usage samples and tier changes ---
+
+func TestMemoryUsageSamples_ListAndPrune(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	samples := db.UsageSamples()
+	s1 := &database.UsageSample{DatabaseID: d.ID, CPUUsedMillis: 100, MemoryUsedBytes: 1 << 20}
+	require.NoError(t, samples.Record(ctx, s1))
+	assert.False(t, s1.CollectedAt.IsZero())
+	// Stored timestamps are truncated to microseconds: the cutoff is taken
+	// from s1's, and s2 is recorded a millisecond later.
+	cutoff := s1.CollectedAt.Add(time.Microsecond)
+	time.Sleep(time.Millisecond)
+	s2 := &database.UsageSample{DatabaseID: d.ID, CPUUsedMillis: 200, MemoryUsedBytes: 2 << 20}
+	require.NoError(t, samples.Record(ctx, s2))
+
+	got, err := samples.ListByDatabase(ctx, d.ID, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []database.UsageSample{*s1, *s2}, got)
+
+	got, err = samples.ListByDatabase(ctx, d.ID, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, []database.UsageSample{*s2}, got)
+
+	deleted, err := samples.DeleteBefore(ctx, cutoff)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	got, err = samples.ListByDatabase(ctx, d.ID, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, []database.UsageSample{*s2}, got)
+
+	assert.ErrorIs(t, samples.Record(ctx, &database.UsageSample{DatabaseID: uuid.New()}), database.ErrNotFound)
+}
+
+func TestMemoryTierChanges_RecordAndList(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	changes := db.TierChanges()
+	c := &database.TierChange{DatabaseID: d.ID, FromTier: "small", ToTier: "standard", Actor: "system:recommender",
+		Reason: "upsize", Status: database.TierChangeApplied}
+	require.NoError(t, changes.Record(ctx, c))
+	assert.NotZero(t, c.ID)
+	assert.False(t, c.CreatedAt.IsZero())
+
+	got, err := changes.ListByDatabase(ctx, d.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []database.TierChange{*c}, got)
+
+	got, err = changes.ListByDatabase(ctx, uuid.New())
+	require.NoError(t, err)
+	assert.Empty(t, got)
+
+	assert.ErrorIs(t, changes.Record(ctx, &database.TierChange{DatabaseID: uuid.New()}), database.ErrNotFound)
+}
+
+func TestMemoryRollouts_TransitionsAndTargets(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	tr := seedTier(t, db, "standard")
+	d := &database.Database{Name: "orders", OwnerTeamID: tm.ID, TierID: &tr.ID}
+	require.NoError(t, db.Databases().Create(ctx, d))
+
+	repo := db.Rollouts()
+	r := &rollout.Rollout{TierID: tr.ID, FromBlueprintID: *tr.BlueprintID, FromBlueprintName: "standard-bp",
+		ToBlueprintID: *tr.BlueprintID, ToBlueprintName: "standard-bp", Status: rollout.StatusInProgress, BatchCount: 1}
+	require.NoError(t, repo.Create(ctx, r, []rollout.Target{{DatabaseID: d.ID, DatabaseName: d.Name, Status: rollout.TargetPending}}))
+	assert.Equal(t, "standard", r.TierName)
+
+	active, err := repo.List(ctx, rollout.ListFilter{TierID: &tr.ID, Statuses: rollout.ActiveStatuses})
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+
+	_, err = repo.Transition(ctx, r.ID, []string{rollout.StatusPaused}, rollout.StatusInProgress, "")
+	assert.ErrorIs(t, err, rollout.ErrInvalidTransition)
+	paused, err := repo.Transition(ctx, r.ID, []string{rollout.StatusInProgress}, rollout.StatusPaused, "canary unhealthy")
+	require.NoError(t, err)
+	assert.Equal(t, rollout.StatusPaused, paused.Status)
+	assert.Equal(t, "canary unhealthy", paused.Error)
+
+	targets, err := repo.ListTargets(ctx, r.ID)
+	require.NoError(t, err)
+	require.Len(t, targets, 1)
+	applied := time.Now().UTC()
+	targets[0].Status = rollout.TargetApplied
+	targets[0].AppliedAt = &applied
+	require.NoError(t, repo.UpdateTarget(ctx, &targets[0]))
+	targets, err = repo.ListTargets(ctx, r.ID)
+	require.NoError(t, err)
+	assert.Equal(t, rollout.TargetApplied, targets[0].Status)
+
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, rollout.ErrRolloutNotFound)
+}
+
+func TestMemoryFreezes_ListActiveAndTeamCascade(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	now := time.Now()
+
+	repo := db.Freezes()
+	orgWide := &freeze.Window{Reason: "holidays", StartsAt: now.Add(-time.Hour), EndsAt: now.Add(time.Hour)}
+	require.NoError(t, repo.Create(ctx, orgWide))
+	teamScoped := &freeze.Window{TeamID: &tm.ID, Reason: "migration", StartsAt: now.Add(-time.Minute), EndsAt: now.Add(time.Minute)}
+	require.NoError(t, repo.Create(ctx, teamScoped))
+	require.NoError(t, repo.Create(ctx, &freeze.Window{Reason: "later", StartsAt: now.Add(time.Hour), EndsAt: now.Add(2 * time.Hour)}))
+
+	assert.Error(t, repo.Create(ctx, &freeze.Window{Reason: "bad", StartsAt: now, EndsAt: now}))
+	missing := uuid.New()
+	assert.Error(t, repo.Create(ctx, &freeze.Window{TeamID: &missing, Reason: "x", StartsAt: now, EndsAt: now.Add(time.Hour)}))
+
+	active, err := repo.ListActive(ctx, now)
+	require.NoError(t, err)
+	require.Len(t, active, 2)
+	got, err := repo.GetByID(ctx, teamScoped.ID)
+	require.NoError(t, err)
+	require.NotNil(t, got.TeamName)
+	assert.Equal(t, "backend", *got.TeamName)
+
+	require.NoError(t, db.Teams().Delete(ctx, tm.ID))
+	_, err = repo.GetByID(ctx, teamScoped.ID)
+	assert.ErrorIs(t, err, freeze.ErrWindowNotFound)
+	all, err := repo.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestMemoryBlueprints_DeleteBlockedByTiers(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tr := seedTier(t, db, "standard")
+
+	assert.ErrorIs(t, db.Blueprints().Delete(ctx, *tr.BlueprintID), blueprint.ErrBlueprintHasTiers)
+	assert.Equal(t, "standard-bp", tr.BlueprintName)
+}
+
+func TestMemoryBlueprints_UpdateRecordsVersions(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	repo := db.Blueprints()
+
+	bp := &blueprint.Blueprint{Name: "std", Provider: "cnpg", Manifests: "v1"}
+	require.NoError(t, repo.Create(ctx, bp))
+	assert.Equal(t, 1, bp.Version)
+
+	desc := "standard"
+	updated, err := repo.Update(ctx, bp.ID, blueprint.UpdateFields{Description: &desc})
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated.Version, "a description change is not a new version")
+
+	manifests := "v2"
+	updated, err = repo.Update(ctx, bp.ID, blueprint.UpdateFields{Manifests: &manifests})
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated.Version)
+	assert.Equal(t, "standard", updated.Description)
+
+	versions, err := repo.ListVersions(ctx, bp.ID)
+	require.NoError(t, err)
+	require.Len(t, versions, 2)
+	assert.Equal(t, "v2", versions[0].Manifests)
+	assert.Equal(t, 1, versions[1].Version)
+
+	_, err = repo.ListVersions(ctx, uuid.New())
+	assert.ErrorIs(t, err, blueprint.ErrBlueprintNotFound)
+}
+
+func TestMemoryBlueprints_Documents(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	repo := db.Blueprints()
+
+	bp := &blueprint.Blueprint{Name: "std", Provider: "cnpg", Manifests: "kind: Cluster\nmetadata:\n  name: pg\n"}
+	require.NoError(t, repo.Create(ctx, bp))
+	require.Len(t, bp.Documents, 1)
+	assert.Equal(t, "Cluster", bp.Documents[0].Kind)
+
+	manifests := bp.Manifests + "---\nkind: Pooler\nmetadata:\n  name: pg-pooler\n"
+	_, err := repo.Update(ctx, bp.ID, blueprint.UpdateFields{Manifests: &manifests})
+	require.NoError(t, err)
+
+	got, err := repo.GetByID(ctx, bp.ID)
+	require.NoError(t, err)
+	require.Len(t, got.Documents, 2)
+	assert.Equal(t, "pg-pooler", got.Documents[1].Name)
+}
+
+// --- Users ---
+
+func TestMemoryUsers_RevokeAndFindByPrefix(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	u := &auth.User{Name: "alice", TeamID: &tm.ID, ApiKeyPrefix: "abc"}
+	require.NoError(t, db.Users().Create(ctx, u))
+
+	found, err := db.Users().FindByPrefix(ctx, "abc")
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+
+	require.NoError(t, db.Users().Revoke(ctx, u.ID))
+	assert.ErrorIs(t, db.Users().Revoke(ctx, u.ID), auth.ErrUserRevoked)
+
+	found, err = db.Users().FindByPrefix(ctx, "abc")
+	require.NoError(t, err)
+	assert.Empty(t, found)
+
+	users, err := db.Users().List(ctx)
+	require.NoError(t, err)
+	require.Len(t, users, 1)
+	require.NotNil(t, users[0].TeamName)
+	assert.Equal(t, "backend", *users[0].TeamName)
+
+	count, err := db.Users().CountAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMemoryPromotions_RecordAndFilter(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	source := &database.Database{Name: "orders-dev", OwnerTeamID: tm.ID, Environment: "dev"}
+	require.NoError(t, db.Databases().Create(ctx, source))
+	target := &database.Database{Name: "orders-staging", OwnerTeamID: tm.ID, Environment: "staging", PromotedFromID: &source.ID}
+	require.NoError(t, db.Databases().Create(ctx, target))
+
+	env := "staging"
+	result, err := db.Databases().List(ctx, database.ListFilter{PromotedFromID: &source.ID, Environment: &env})
+	require.NoError(t, err)
+	require.Len(t, result.Databases, 1)
+	assert.Equal(t, target.ID, result.Databases[0].ID)
+
+	repo := db.Promotions()
+	p := &database.Promotion{SourceDatabaseID: source.ID, TargetDatabaseID: target.ID, FromEnvironment: "dev", ToEnvironment: "staging", Action: database.PromotionCreated}
+	require.NoError(t, repo.Record(ctx, p))
+	assert.NotZero(t, p.ID)
+	assert.ErrorIs(t, repo.Record(ctx, &database.Promotion{SourceDatabaseID: uuid.New(), TargetDatabaseID: target.ID}), database.ErrNotFound)
+
+	for _, id := range []uuid.UUID{source.ID, target.ID} {
+		promotions, err := repo.ListByDatabase(ctx, id)
+		require.NoError(t, err)
+		assert.Len(t, promotions, 1)
+	}
+}
+
+func TestMemoryDependents_UniqueAndScopedDelete(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	orders := &database.Database{Name: "orders", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, orders))
+	carts := &database.Database{Name: "carts", OwnerTeamID: tm.ID}
+	require.NoError(t, db.Databases().Create(ctx, carts))
+
+	repo := db.Dependents()
+	d := &database.Dependent{DatabaseID: orders.ID, Service: "checkout-api"}
+	require.NoError(t, repo.Create(ctx, d))
+	assert.NotEqual(t, uuid.Nil, d.ID)
+	assert.ErrorIs(t, repo.Create(ctx, &database.Dependent{DatabaseID: orders.ID, Service: "checkout-api"}), database.ErrDuplicateDependent)
+	require.NoError(t, repo.Create(ctx, &database.Dependent{DatabaseID: carts.ID, Service: "checkout-api"}))
+	assert.ErrorIs(t, repo.Create(ctx, &database.Dependent{DatabaseID: uuid.New(), Service: "x"}), database.ErrNotFound)
+
+	assert.ErrorIs(t, repo.Delete(ctx, carts.ID, d.ID), database.ErrDependentNotFound)
+	require.NoError(t, repo.Delete(ctx, orders.ID, d.ID))
+
+	remaining, err := repo.ListByDatabase(ctx, orders.ID)
+	require.NoError(t, err)
+	assert.Empty(t, remaining)
+}
+
+func TestMemoryInvitations_AcceptOnce(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+
+	repo := db.Invitations()
+	inv := &auth.Invitation{Name: "alice", TeamID: tm.ID, Email: "alice@example.com", TokenHash: auth.HashInvitationToken("t1"), ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.Create(ctx, inv))
+	assert.Error(t, repo.Create(ctx, &auth.Invitation{Name: "bob", TeamID: uuid.New(), TokenHash: auth.HashInvitationToken("t2")}))
+
+	found, err := repo.GetByTokenHash(ctx, auth.HashInvitationToken("t1"))
+	require.NoError(t, err)
+	assert.Equal(t, inv.ID, found.ID)
+	_, err = repo.GetByTokenHash(ctx, auth.HashInvitationToken("nope"))
+	assert.ErrorIs(t, err, auth.ErrInvitationNotFound)
+
+	u := &auth.User{Name: "alice", TeamID: &tm.ID, ApiKeyPrefix: "daap_abc"}
+	require.NoError(t, repo.Accept(ctx, inv.ID, u))
+	assert.ErrorIs(t, repo.Accept(ctx, inv.ID, &auth.User{Name: "alice", TeamID: &tm.ID}), auth.ErrInvitationUsed)
+
+	found, err = repo.GetByTokenHash(ctx, auth.HashInvitationToken("t1"))
+	require.NoError(t, err)
+	require.NotNil(t, found.UserID)
+	assert.Equal(t, u.ID, *found.UserID)
+	assert.NotNil(t, found.AcceptedAt)
+
+	expired := &auth.Invitation{Name: "carol", TeamID: tm.ID, TokenHash: auth.HashInvitationToken("t3"), ExpiresAt: time.Now().Add(-time.Minute)}
+	require.NoError(t, repo.Create(ctx, expired))
+	assert.ErrorIs(t, repo.Accept(ctx, expired.ID, &auth.User{Name: "carol", TeamID: &tm.ID}), auth.ErrInvitationExpired)
+
+	count, err := db.Users().CountAll(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestMemoryLocks_AcquireReleaseAndTakeover(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	orders := &database.Database{Name: "orders", OwnerTeamID: tm.ID, Namespace: "default"}
+	require.NoError(t, db.Databases().Create(ctx, orders))
+
+	locker := database.NewLocker(db.Locks(), time.Minute, "host-a:1")
+	lock, err := locker.Acquire(ctx, orders.ID, "resize", "system:autoscaler", "")
+	require.NoError(t, err)
+	assert.Equal(t, "host-a:1", lock.Instance)
+
+	_, err = locker.Acquire(ctx, orders.ID, "delete", "alice", "req-1")
+	require.ErrorIs(t, err, database.ErrLocked)
+	var locked *database.LockedError
+	require.ErrorAs(t, err, &locked)
+	assert.Equal(t, "resize", locked.Lock.Operation)
+	assert.Equal(t, "system:autoscaler", locked.Lock.Holder)
+
+	_, err = locker.Acquire(ctx, uuid.New(), "delete", "alice", "")
+	assert.ErrorIs(t, err, database.ErrNotFound)
+
+	renewedAt := lock.RenewedAt
+	time.Sleep(time.Millisecond)
+	require.NoError(t, locker.Heartbeat(ctx))
+	held, err := locker.Get(ctx, orders.ID)
+	require.NoError(t, err)
+	assert.True(t, held.RenewedAt.After(renewedAt))
+
+	require.NoError(t, locker.Release(ctx, lock))
+	held, err = locker.Get(ctx, orders.ID)
+	require.NoError(t, err)
+	assert.Nil(t, held)
+
+	// An expired lock is taken over, and its stale release is a no-op.
+	expiring := database.NewLocker(db.Locks(), -time.Second, "host-b:2")
+	stale, err := expiring.Acquire(ctx, orders.ID, "rollout", "system:rollout", "")
+	require.NoError(t, err)
+	taken, err := locker.Acquire(ctx, orders.ID, "delete", "alice", "req-2")
+	require.NoError(t, err)
+	require.NoError(t, expiring.Release(ctx, stale))
+	held, err = locker.Get(ctx, orders.ID)
+	require.NoError(t, err)
+	require.NotNil(t, held)
+	assert.Equal(t, taken.ID, held.ID)
+}
+
+func TestMemoryOperations_ProgressAndCompleteOnce(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	orders := &database.Database{Name: "orders", OwnerTeamID: tm.ID, Namespace: "default"}
+	require.NoError(t, db.Databases().Create(ctx, orders))
+
+	repo := db.Operations()
+	assert.Error(t, repo.Create(ctx, &operation.Operation{Type: operation.TypeCreate, DatabaseID: uuid.New()}))
+
+	first := &operation.Operation{Type: operation.TypeCreate, DatabaseID: orders.ID, TeamID: tm.ID}
+	require.NoError(t, repo.Create(ctx, first))
+	assert.Equal(t, operation.StatusRunning, first.Status)
+	second := &operation.Operation{Type: operation.TypePromote, DatabaseID: orders.ID, TeamID: tm.ID}
+	require.NoError(t, repo.Create(ctx, second))
+
+	require.NoError(t, repo.SetProgress(ctx, first.ID, 50, "Waiting"))
+	done, err := repo.Complete(ctx, first.ID, operation.StatusSucceeded, map[string]any{"host": "orders"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 100, done.Progress)
+	assert.Equal(t, "Waiting", done.Message)
+	require.NotNil(t, done.CompletedAt)
+
+	assert.ErrorIs(t, repo.SetProgress(ctx, first.ID, 60, "Again"), operation.ErrOperationDone)
+	_, err = repo.Complete(ctx, first.ID, operation.StatusFailed, nil, &operation.Error{Code: "X"})
+	assert.ErrorIs(t, err, operation.ErrOperationDone)
+	_, err = repo.GetByID(ctx, uuid.New())
+	assert.ErrorIs(t, err, operation.ErrOperationNotFound)
+
+	running := operation.StatusRunning
+	ops, err := repo.List(ctx, operation.ListFilter{DatabaseID: &orders.ID, Status: &running})
+	require.NoError(t, err)
+	require.Len(t, ops, 1)
+	assert.Equal(t, second.ID, ops[0].ID)
+
+	all, err := repo.List(ctx, operation.ListFilter{DatabaseID: &orders.ID})
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, second.ID, all[0].ID, "newest first")
+}
+
+func TestMemoryBlueprints_Scope(t *testing.T) {
+	db := memory.New()
+	ctx := context.Background()
+	tm := seedTeam(t, db, "backend", "product")
+	missing := uuid.New()
+
+	assert.Error(t, db.Blueprints().Create(ctx, &blueprint.Blueprint{Name: "orphan", Provider: "cnpg", TeamID: &missing}))
+	assert.Error(t, db.Blueprints().Create(ctx, &blueprint.Blueprint{Name: "unknown-org", Provider: "cnpg", OrganizationID: &missing}))
+
+	bp := &blueprint.Blueprint{Name: "experimental", Provider: "cnpg", TeamID: &tm.ID}
+	require.NoError(t, db.Blueprints().Create(ctx, bp))
+	got, err := db.Blueprints().GetByID(ctx, bp.ID)
+	require.NoError(t, err)
+	assert.Equal(t, tm.ID, *got.TeamID)
+
+	assert.ErrorIs(t, db.Teams().Delete(ctx, tm.ID), team.ErrTeamHasUsers)
+	require.NoError(t, db.Blueprints().Delete(ctx, bp.ID))
+	require.NoError(t, db.Teams().Delete(ctx, tm.ID))
+}
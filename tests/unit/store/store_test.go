@@ -0,0 +1,36 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/store"
+)
+
+func TestOpen_Memory(t *testing.T) {
+	st, err := store.Open(context.Background(), "memory://")
+	require.NoError(t, err)
+	defer st.Close()
+
+	assert.Equal(t, store.BackendMemory, st.Backend())
+	assert.NoError(t, st.Ping(context.Background()))
+	assert.NotNil(t, st.Databases)
+	assert.NotNil(t, st.Teams)
+	assert.NotNil(t, st.Tiers)
+	assert.NotNil(t, st.Blueprints)
+	assert.NotNil(t, st.Users)
+}
+
+func TestOpen_UnsupportedScheme(t *testing.T) {
+	_, err := store.Open(context.Background(), "mysql://localhost/daap")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported database URL scheme")
+}
+
+func TestOpen_InvalidURL(t *testing.T) {
+	_, err := store.Open(context.Background(), "://bad")
+	require.Error(t, err)
+}
@@ -0,0 +1,146 @@
+package support_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/blueprint"
+	"github.com/daap14/daap/internal/database"
+	"github.com/daap14/daap/internal/provider"
+	"github.com/daap14/daap/internal/support"
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+type fixture struct {
+	repos    *fake.Repositories
+	team     *team.Team
+	tier     *tier.Tier
+	provider *fake.Provider
+	bundler  *support.Bundler
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	repos := fake.NewRepositories()
+	f := &fixture{repos: repos, team: &team.Team{Name: "checkout", Role: "product"}, provider: fake.NewProvider()}
+	require.NoError(t, repos.Teams.Create(ctx, f.team))
+	bp := &blueprint.Blueprint{Name: "cnpg-standard", Provider: "cnpg", Manifests: "kind: Cluster\n"}
+	require.NoError(t, repos.Blueprints.Create(ctx, bp))
+	f.tier = &tier.Tier{Name: "standard", BlueprintID: &bp.ID}
+	require.NoError(t, repos.Tiers.Create(ctx, f.tier))
+
+	registry := provider.NewRegistry()
+	registry.Register("cnpg", f.provider)
+	f.bundler = support.New(repos.Tiers, repos.Blueprints, registry, repos.Databases.(database.StatsReader))
+	return f
+}
+
+func (f *fixture) seed(t *testing.T, name string, withTier bool) *database.Database {
+	t.Helper()
+	db := &database.Database{Name: name, OwnerTeamID: f.team.ID, Namespace: "default", ClusterName: "daap-" + name, Status: "provisioning"}
+	if withTier {
+		db.TierID = &f.tier.ID
+	}
+	require.NoError(t, f.repos.Databases.Create(context.Background(), db))
+	return db
+}
+
+// readBundle returns the regular files of a tar.gz bundle in archive order,
+// and its directories.
+func readBundle(t *testing.T, data []byte) ([]string, map[string]string, []string) {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	tr := tar.NewReader(gz)
+
+	var names, dirs []string
+	files := make(map[string]string)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if hdr.Typeflag == tar.TypeDir {
+			dirs = append(dirs, hdr.Name)
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		require.NoError(t, err)
+		names = append(names, hdr.Name)
+		files[hdr.Name] = string(content)
+	}
+	return names, files, dirs
+}
+
+func TestWrite_GathersEverything(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	db := f.seed(t, "orders", true)
+	f.provider.DiagnosticsFn = func(context.Context, provider.ProviderDatabase) ([]provider.DiagnosticFile, error) {
+		return []provider.DiagnosticFile{
+			{Name: "cluster.yaml", Content: []byte("kind: Cluster\n")},
+			{Name: "logs/daap-orders-1.log", Content: []byte("ready\n")},
+		}, nil
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, f.bundler.Write(context.Background(), &buf, db))
+
+	names, files, dirs := readBundle(t, buf.Bytes())
+	assert.Equal(t, []string{"database.json", "status-history.json", "manifests.yaml", "cnpg/cluster.yaml", "cnpg/logs/daap-orders-1.log"}, names)
+	assert.Equal(t, []string{"cnpg/", "cnpg/logs/"}, dirs)
+	assert.Equal(t, "kind: Cluster\n", files["manifests.yaml"])
+	assert.Equal(t, "ready\n", files["cnpg/logs/daap-orders-1.log"])
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(files["database.json"]), &record))
+	assert.Equal(t, "orders", record["Name"])
+
+	var history []map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(files["status-history.json"]), &history))
+	require.Len(t, history, 1)
+	assert.Equal(t, "provisioning", history[0]["to"])
+}
+
+func TestWrite_ListsWhatCouldNotBeGathered(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	db := f.seed(t, "orders", true)
+	f.provider.DiagnosticsFn = func(context.Context, provider.ProviderDatabase) ([]provider.DiagnosticFile, error) {
+		return []provider.DiagnosticFile{{Name: "events.yaml", Content: []byte("[]\n")}},
+			errors.Join(errors.New("getting cluster default/daap-orders: not found"), errors.New("reading logs: forbidden"))
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, f.bundler.Write(context.Background(), &buf, db))
+
+	names, files, _ := readBundle(t, buf.Bytes())
+	assert.Equal(t, []string{"database.json", "status-history.json", "manifests.yaml", "cnpg/events.yaml", support.ErrorsFile}, names)
+	assert.Equal(t, "cnpg: getting cluster default/daap-orders: not found\ncnpg: reading logs: forbidden\n", files[support.ErrorsFile])
+}
+
+func TestWrite_DatabaseWithoutTier(t *testing.T) {
+	t.Parallel()
+	f := newFixture(t)
+	db := f.seed(t, "legacy", false)
+
+	var buf bytes.Buffer
+	require.NoError(t, f.bundler.Write(context.Background(), &buf, db))
+
+	names, files, _ := readBundle(t, buf.Bytes())
+	assert.Equal(t, []string{"database.json", "status-history.json", support.ErrorsFile}, names)
+	assert.Equal(t, "manifests.yaml: database has no tier\n", files[support.ErrorsFile])
+}
@@ -0,0 +1,43 @@
+package team_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/team"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+func TestCachedRepository_ReturnsCopies(t *testing.T) {
+	ctx := context.Background()
+	inner := fake.NewRepositories().Teams
+	repo := team.NewCachedRepository(inner, time.Minute)
+
+	checkout := &team.Team{
+		Name:        "checkout",
+		Role:        "product",
+		Labels:      map[string]string{"cost-center": "cc-42"},
+		Annotations: map[string]string{"owner": "checkout@example.com"},
+	}
+	require.NoError(t, repo.Create(ctx, checkout))
+
+	// Changing a team read through the cache changes neither the cache nor
+	// the wrapped repository.
+	got, err := repo.GetByID(ctx, checkout.ID)
+	require.NoError(t, err)
+	got.Labels["cost-center"] = "changed"
+	got.Annotations["owner"] = "changed"
+
+	got, err = repo.GetByID(ctx, checkout.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cost-center": "cc-42"}, got.Labels)
+	assert.Equal(t, map[string]string{"owner": "checkout@example.com"}, got.Annotations)
+
+	stored, err := inner.GetByID(ctx, checkout.ID)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"cost-center": "cc-42"}, stored.Labels)
+}
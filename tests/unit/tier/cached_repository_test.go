@@ -0,0 +1,75 @@
+package tier_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/daap14/daap/internal/tier"
+	"github.com/daap14/daap/pkg/fake"
+)
+
+// countingTierRepo counts lookups reaching the wrapped repository.
+type countingTierRepo struct {
+	tier.Repository
+	lookups int
+}
+
+func (r *countingTierRepo) GetByID(ctx context.Context, id uuid.UUID) (*tier.Tier, error) {
+	r.lookups++
+	return r.Repository.GetByID(ctx, id)
+}
+
+func (r *countingTierRepo) GetByName(ctx context.Context, name string) (*tier.Tier, error) {
+	r.lookups++
+	return r.Repository.GetByName(ctx, name)
+}
+
+func TestCachedRepository_CachesLookupsAndClearsOnWrite(t *testing.T) {
+	ctx := context.Background()
+	inner := &countingTierRepo{Repository: fake.NewRepositories().Tiers}
+	repo := tier.NewCachedRepository(inner, time.Minute)
+
+	standard := &tier.Tier{
+		Name:                "standard",
+		Description:         "Standard",
+		Topology:            tier.Topology{NodeSelector: map[string]string{"pool": "databases"}},
+		DataClassifications: []string{"internal"},
+	}
+	require.NoError(t, repo.Create(ctx, standard))
+
+	for i := 0; i < 3; i++ {
+		got, err := repo.GetByName(ctx, "standard")
+		require.NoError(t, err)
+		assert.Equal(t, standard.ID, got.ID)
+		_, err = repo.GetByID(ctx, standard.ID)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, inner.lookups, "one lookup per key")
+
+	// Callers get copies: changing one does not change the cache.
+	got, _ := repo.GetByName(ctx, "standard")
+	got.Description = "changed"
+	got.Topology.NodeSelector["pool"] = "changed"
+	got.DataClassifications[0] = "changed"
+	got, _ = repo.GetByName(ctx, "standard")
+	assert.Equal(t, "Standard", got.Description)
+	assert.Equal(t, map[string]string{"pool": "databases"}, got.Topology.NodeSelector)
+	assert.Equal(t, []string{"internal"}, got.DataClassifications)
+
+	description := "Updated"
+	_, err := repo.Update(ctx, standard.ID, tier.UpdateFields{Description: &description})
+	require.NoError(t, err)
+	got, err = repo.GetByName(ctx, "standard")
+	require.NoError(t, err)
+	assert.Equal(t, "Updated", got.Description)
+	assert.Equal(t, 3, inner.lookups)
+
+	require.NoError(t, repo.Delete(ctx, standard.ID))
+	_, err = repo.GetByID(ctx, standard.ID)
+	assert.ErrorIs(t, err, tier.ErrTierNotFound)
+}